@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"novastream/config"
+)
+
+// adminPathPrefixes are the routes a listener serves only when admin is enabled.
+// Everything else (client API, streams, WebDAV) is streaming.
+var adminPathPrefixes = []string{"/admin", "/account", "/api/admin"}
+
+// IsAdminPath reports whether path belongs to the admin and account UIs or the admin API.
+func IsAdminPath(path string) bool {
+	if path == "/" {
+		// Root redirects to the admin dashboard
+		return true
+	}
+	for _, prefix := range adminPathPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenerScope restricts next to the route groups enabled on a listener.
+// Requests for a disabled group get a 404, as if the route didn't exist.
+func ListenerScope(next http.Handler, listener config.ListenerSettings) http.Handler {
+	if !listener.DisableAdmin && !listener.DisableStreaming {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := IsAdminPath(r.URL.Path)
+		if (admin && listener.DisableAdmin) || (!admin && listener.DisableStreaming) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Listen binds a listener's address. Unix socket addresses replace a stale
+// socket file left by an unclean shutdown.
+func Listen(listener config.ListenerSettings) (net.Listener, error) {
+	if path, ok := listener.UnixSocket(); ok {
+		if path == "" {
+			return nil, fmt.Errorf("unix listener has no socket path")
+		}
+		if info, err := os.Lstat(path); err == nil {
+			if info.Mode()&fs.ModeSocket == 0 {
+				return nil, fmt.Errorf("%s exists and is not a socket", path)
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("remove stale socket %s: %w", path, err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("stat socket %s: %w", path, err)
+		}
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		// Let a reverse proxy running as another user connect
+		if err := os.Chmod(path, 0o666); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("chmod socket %s: %w", path, err)
+		}
+		return ln, nil
+	}
+	return net.Listen("tcp", strings.TrimSpace(listener.Address))
+}
@@ -3,14 +3,19 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/gorilla/mux"
 
 	"novastream/internal/auth"
+	"novastream/services/maintenance"
 	"novastream/services/sessions"
+	"novastream/services/stream_binding"
 	"novastream/services/users"
+	"novastream/utils"
 )
 
 // Re-export from auth package for backward compatibility
@@ -84,6 +89,135 @@ func MasterOnlyMiddleware() mux.MiddlewareFunc {
 	}
 }
 
+// maintenanceBlockedRoutes lists the requests that start new playback. They
+// are refused while maintenance mode is on; requests for streams that are
+// already playing (segments, playlists, keepalives) keep working. A path
+// ending in a slash matches everything under it. Direct streams are only
+// blocked when a request opens them, not for the range requests of a stream
+// that is already playing.
+var maintenanceBlockedRoutes = []struct {
+	method  string
+	path    string
+	opening bool
+}{
+	{method: http.MethodPost, path: "/api/playback/resolve"},
+	{method: http.MethodPost, path: "/api/playback/prequeue"},
+	{method: http.MethodPost, path: "/api/playback/up-next"},
+	{method: http.MethodPost, path: "/api/playback/queue-next"},
+	{method: http.MethodGet, path: "/api/video/hls/start"},
+	{method: http.MethodGet, path: "/api/live/hls/start"},
+	{method: http.MethodGet, path: "/api/video/stream", opening: true},
+	{method: http.MethodGet, path: "/api/live/stream", opening: true},
+	{method: http.MethodGet, path: "/api/share/", opening: true},
+}
+
+// startsPlayback reports whether the request starts new playback.
+func startsPlayback(r *http.Request) bool {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	for _, route := range maintenanceBlockedRoutes {
+		if r.Method != route.method {
+			continue
+		}
+		if strings.HasSuffix(route.path, "/") {
+			if !strings.HasPrefix(path, route.path) {
+				continue
+			}
+		} else if path != route.path {
+			continue
+		}
+		if route.opening && !opensStream(r) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// opensStream reports whether a stream request starts from the beginning
+// rather than continuing a stream that is already playing.
+func opensStream(r *http.Request) bool {
+	rangeHeader := strings.TrimSpace(r.Header.Get("Range"))
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// MaintenanceMiddleware creates middleware that refuses new playback while
+// maintenance mode is enabled so active streams can drain before a restart.
+func MaintenanceMiddleware(maintenanceSvc *maintenance.Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || !maintenanceSvc.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if startsPlayback(r) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "300")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]string{"error": "server is in maintenance mode"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StreamBindingMiddleware creates middleware that binds stream URLs to the
+// session's devices. Requests carrying the token in a header are the app
+// itself and record where the session is used; requests carrying it only in
+// the ?token= query parameter are refused from anywhere else.
+func StreamBindingMiddleware(bindingSvc *stream_binding.Service, sessionsSvc *sessions.Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip, forwarded := utils.ResolveClientIP(r)
+			clientID := strings.TrimSpace(r.Header.Get("X-Client-ID"))
+			if clientID == "" {
+				clientID = strings.TrimSpace(r.URL.Query().Get("clientId"))
+			}
+
+			if token := headerToken(r); token != "" {
+				// Only real sessions are remembered
+				if _, err := sessionsSvc.Validate(token); err == nil {
+					bindingSvc.Observe(token, ip, clientID)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			token := strings.TrimSpace(r.URL.Query().Get("token"))
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The server fetching its own streams (e.g. ffmpeg) connects over
+			// loopback; proxied requests are judged by the client behind the proxy
+			if !forwarded && isLoopback(ip) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := bindingSvc.Check(token, ip, clientID); err != nil {
+				message := "stream is bound to another network"
+				if errors.Is(err, stream_binding.ErrClientNotBound) {
+					message = "stream is bound to another device"
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]string{"error": message})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // ProfileOwnershipMiddleware creates middleware that verifies profile ownership.
 // Master accounts can access any profile; regular accounts can only access their own.
 func ProfileOwnershipMiddleware(usersSvc *users.Service) mux.MiddlewareFunc {
@@ -125,6 +259,17 @@ func ProfileOwnershipMiddleware(usersSvc *users.Service) mux.MiddlewareFunc {
 // extractToken extracts the session token from headers or query param.
 // Priority: Authorization header > X-PIN header > ?token= query param
 func extractToken(r *http.Request) string {
+	if token := headerToken(r); token != "" {
+		return token
+	}
+
+	// Fall back to query parameter for streaming URLs (video players can't set headers)
+	return strings.TrimSpace(r.URL.Query().Get("token"))
+}
+
+// headerToken extracts the session token from the Authorization or X-PIN
+// header, which only the app itself sends.
+func headerToken(r *http.Request) string {
 	// First try Authorization header
 	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" {
@@ -141,10 +286,10 @@ func extractToken(r *http.Request) string {
 		return token
 	}
 
-	// Fall back to query parameter for streaming URLs (video players can't set headers)
-	if token := strings.TrimSpace(r.URL.Query().Get("token")); token != "" {
-		return token
-	}
-
 	return ""
 }
+
+func isLoopback(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.IsLoopback()
+}
@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"novastream/api"
+	"novastream/services/maintenance"
+)
+
+func TestMaintenanceMiddlewareBlocksNewPlayback(t *testing.T) {
+	svc := maintenance.NewService(func() int { return 1 })
+	svc.Enable(10*time.Minute, "")
+	defer svc.Disable()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := api.MaintenanceMiddleware(svc)(next)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		rangeHdr   string
+		wantStatus int
+	}{
+		{"resolve", http.MethodPost, "/api/playback/resolve", "", http.StatusServiceUnavailable},
+		{"hls start", http.MethodGet, "/api/video/hls/start", "", http.StatusServiceUnavailable},
+		{"hls segment", http.MethodGet, "/api/video/hls/abc/segment0.ts", "", http.StatusOK},
+		{"direct stream open", http.MethodGet, "/api/video/stream?path=x", "", http.StatusServiceUnavailable},
+		{"direct stream continue", http.MethodGet, "/api/video/stream?path=x", "bytes=1024-", http.StatusOK},
+		{"live stream open", http.MethodGet, "/api/live/stream?url=x", "", http.StatusServiceUnavailable},
+		{"live stream open from start", http.MethodGet, "/api/live/stream?url=x", "bytes=0-", http.StatusServiceUnavailable},
+		{"live stream continue", http.MethodGet, "/api/live/stream?url=x", "bytes=4096-", http.StatusOK},
+		{"share link", http.MethodGet, "/api/share/abc", "", http.StatusServiceUnavailable},
+		{"preflight", http.MethodOptions, "/api/live/stream", "", http.StatusOK},
+		{"settings", http.MethodGet, "/api/settings", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.rangeHdr != "" {
+				req.Header.Set("Range", tt.rangeHdr)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMaintenanceMiddlewareDisabled(t *testing.T) {
+	svc := maintenance.NewService(func() int { return 0 })
+	handler := api.MaintenanceMiddleware(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/live/stream?url=x", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
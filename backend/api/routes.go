@@ -10,6 +10,7 @@ import (
 	"novastream/services/accounts"
 	"novastream/services/sessions"
 	"novastream/services/users"
+	"novastream/utils"
 
 	"github.com/gorilla/mux"
 )
@@ -61,20 +62,7 @@ func devOnlyMiddleware(next http.Handler) http.Handler {
 
 // corsMiddleware handles CORS for API routes
 func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+	return utils.CORSMiddleware(next)
 }
 
 // handleOptions handles OPTIONS requests for CORS preflight
@@ -105,6 +93,8 @@ func Register(
 	clientsHandler *handlers.ClientsHandler,
 	contentPreferencesHandler *handlers.ContentPreferencesHandler,
 	imageHandler *handlers.ImageHandler,
+	syncHandler *handlers.SyncHandler,
+	releaseVersionsHandler *handlers.ReleaseVersionsHandler,
 	accountsSvc *accounts.Service,
 	sessionsSvc *sessions.Service,
 	usersSvc *users.Service,
@@ -173,10 +163,16 @@ func Register(
 	settingsWriteRouter.HandleFunc("", settingsHandler.PutSettings).Methods(http.MethodPut)
 	settingsWriteRouter.HandleFunc("/cache/clear", settingsHandler.ClearMetadataCache).Methods(http.MethodPost)
 	settingsWriteRouter.HandleFunc("/cache/clear", handleOptions).Methods(http.MethodOptions)
+	settingsWriteRouter.HandleFunc("/backups", settingsHandler.ListConfigBackups).Methods(http.MethodGet)
+	settingsWriteRouter.HandleFunc("/backups", handleOptions).Methods(http.MethodOptions)
+	settingsWriteRouter.HandleFunc("/backups/{backupID}/restore", settingsHandler.RestoreConfigBackup).Methods(http.MethodPost)
+	settingsWriteRouter.HandleFunc("/backups/{backupID}/restore", handleOptions).Methods(http.MethodOptions)
 
 	// Content discovery and metadata (all authenticated users)
 	protected.HandleFunc("/discover/new", metadataHandler.DiscoverNew).Methods(http.MethodGet)
 	protected.HandleFunc("/discover/new", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/discover/hero", metadataHandler.HeroRotation).Methods(http.MethodGet)
+	protected.HandleFunc("/discover/hero", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/lists/custom", metadataHandler.CustomList).Methods(http.MethodGet)
 	protected.HandleFunc("/lists/custom", handleOptions).Methods(http.MethodOptions)
 
@@ -191,6 +187,8 @@ func Register(
 	protected.HandleFunc("/metadata/movies/details", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/metadata/movies/releases", metadataHandler.BatchMovieReleases).Methods(http.MethodPost)
 	protected.HandleFunc("/metadata/movies/releases", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/metadata/translations", metadataHandler.Translations).Methods(http.MethodGet)
+	protected.HandleFunc("/metadata/translations", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/metadata/collection", metadataHandler.CollectionDetails).Methods(http.MethodGet)
 	protected.HandleFunc("/metadata/collection", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/metadata/similar", metadataHandler.Similar).Methods(http.MethodGet)
@@ -227,6 +225,15 @@ func Register(
 		// Lazy subtitle extraction - called when user plays with known offset
 		protected.HandleFunc("/playback/prequeue/{prequeueID}/start-subtitles", prequeueHandler.StartSubtitles).Methods(http.MethodPost)
 		protected.HandleFunc("/playback/prequeue/{prequeueID}/start-subtitles", prequeueHandler.Options).Methods(http.MethodOptions)
+		// Up Next bundle - next episode metadata plus its resolved prequeue, near the end of playback
+		protected.HandleFunc("/playback/up-next", prequeueHandler.UpNext).Methods(http.MethodPost)
+		protected.HandleFunc("/playback/up-next", prequeueHandler.Options).Methods(http.MethodOptions)
+		// Now-playing title, artwork and duration for a stream, e.g. for AirPlay handoff
+		protected.HandleFunc("/playback/now-playing/{token}", prequeueHandler.NowPlaying).Methods(http.MethodGet)
+		protected.HandleFunc("/playback/now-playing/{token}", prequeueHandler.Options).Methods(http.MethodOptions)
+		// Next playback queue item, resolved just in time near the end of playback
+		protected.HandleFunc("/playback/queue-next", prequeueHandler.QueueNext).Methods(http.MethodPost)
+		protected.HandleFunc("/playback/queue-next", prequeueHandler.Options).Methods(http.MethodOptions)
 	}
 
 	protected.HandleFunc("/usenet/health", usenetHandler.CheckHealth).Methods(http.MethodPost)
@@ -249,6 +256,20 @@ func Register(
 	protected.HandleFunc("/live/stream", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/live/hls/start", videoHandler.StartLiveHLSSession).Methods(http.MethodGet, http.MethodOptions)
 
+	// Scheduled live event recordings (timeshift while recording, VOD afterwards)
+	protected.HandleFunc("/live/events", liveHandler.ListEvents).Methods(http.MethodGet)
+	protected.HandleFunc("/live/events", liveHandler.CreateEvent).Methods(http.MethodPost)
+	protected.HandleFunc("/live/events", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/events/{eventID}", liveHandler.GetEvent).Methods(http.MethodGet)
+	protected.HandleFunc("/live/events/{eventID}", liveHandler.DeleteEvent).Methods(http.MethodDelete)
+	protected.HandleFunc("/live/events/{eventID}", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/events/{eventID}/finish", liveHandler.FinishEvent).Methods(http.MethodPost)
+	protected.HandleFunc("/live/events/{eventID}/finish", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/events/{eventID}/recording/{file}", liveHandler.ServeEventRecording).Methods(http.MethodGet)
+	protected.HandleFunc("/live/events/{eventID}/recording/{file}", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/timeshift/{bufferID}/{file}", liveHandler.ServeTimeshift).Methods(http.MethodGet)
+	protected.HandleFunc("/live/timeshift/{bufferID}/{file}", handleOptions).Methods(http.MethodOptions)
+
 	// EPG (Electronic Program Guide) endpoints
 	if epgHandler != nil {
 		protected.HandleFunc("/live/epg/now", epgHandler.GetNowPlaying).Methods(http.MethodGet)
@@ -274,15 +295,21 @@ func Register(
 	protected.HandleFunc("/video/hls/start", videoHandler.StartHLSSession).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/stream.m3u8", videoHandler.ServeHLSPlaylist).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/subtitles.vtt", videoHandler.ServeHLSSubtitles).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/video/hls/{sessionID}/subtitles/offset", videoHandler.AdjustSubtitleOffset).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/video/hls/{sessionID}/audio/offset", videoHandler.AdjustAudioOffset).Methods(http.MethodPost, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/keepalive", videoHandler.KeepAliveHLSSession).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/video/hls/{sessionID}/pause", videoHandler.PauseHLSSession).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/video/hls/{sessionID}/resume", videoHandler.ResumeHLSSession).Methods(http.MethodPost, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/status", videoHandler.GetHLSSessionStatus).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/seek", videoHandler.SeekHLSSession).Methods(http.MethodPost, http.MethodOptions)
+	protected.HandleFunc("/video/hls/{sessionID}/quality", videoHandler.AdjustHLSQuality).Methods(http.MethodPost, http.MethodOptions)
 	protected.HandleFunc("/video/hls/{sessionID}/{segment}", videoHandler.ServeHLSSegment).Methods(http.MethodGet, http.MethodOptions)
 
 	// Standalone subtitle extraction endpoints (for non-HLS streams)
 	protected.HandleFunc("/video/subtitles/tracks", videoHandler.ProbeSubtitleTracks).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/video/subtitles/start", videoHandler.StartSubtitleExtract).Methods(http.MethodGet, http.MethodOptions)
 	protected.HandleFunc("/video/subtitles/{sessionID}/subtitles.vtt", videoHandler.ServeExtractedSubtitles).Methods(http.MethodGet, http.MethodOptions)
+	protected.HandleFunc("/video/subtitles/{sessionID}/offset", videoHandler.AdjustSubtitleOffset).Methods(http.MethodPost, http.MethodOptions)
 
 	// Subtitle search endpoints (using subliminal)
 	protected.HandleFunc("/subtitles/search", subtitlesHandler.Search).Methods(http.MethodGet)
@@ -393,9 +420,16 @@ func Register(
 	profileProtected.HandleFunc("", usersHandler.List).Methods(http.MethodGet)
 	profileProtected.HandleFunc("", usersHandler.Create).Methods(http.MethodPost)
 	profileProtected.HandleFunc("", usersHandler.Options).Methods(http.MethodOptions)
+	// Deleted profiles are looked up by profileID; the handler checks ownership
+	profileProtected.HandleFunc("/deleted", usersHandler.ListDeleted).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/deleted", usersHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/deleted/{profileID}/restore", usersHandler.Restore).Methods(http.MethodPost)
+	profileProtected.HandleFunc("/deleted/{profileID}/restore", usersHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}", usersHandler.Rename).Methods(http.MethodPatch)
 	profileProtected.HandleFunc("/{userID}", usersHandler.Delete).Methods(http.MethodDelete)
 	profileProtected.HandleFunc("/{userID}", usersHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/export", usersHandler.Export).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/export", usersHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/color", usersHandler.SetColor).Methods(http.MethodPut)
 	profileProtected.HandleFunc("/{userID}/color", usersHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/icon", usersHandler.SetIconURL).Methods(http.MethodPut)
@@ -418,6 +452,9 @@ func Register(
 	profileProtected.HandleFunc("/{userID}/settings", userSettingsHandler.GetSettings).Methods(http.MethodGet)
 	profileProtected.HandleFunc("/{userID}/settings", userSettingsHandler.PutSettings).Methods(http.MethodPut)
 	profileProtected.HandleFunc("/{userID}/settings", userSettingsHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/settings/preferences", userSettingsHandler.GetPreferences).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/settings/preferences", userSettingsHandler.PatchPreferences).Methods(http.MethodPatch)
+	profileProtected.HandleFunc("/{userID}/settings/preferences", userSettingsHandler.Options).Methods(http.MethodOptions)
 
 	// Client device management routes
 	if clientsHandler != nil {
@@ -446,6 +483,14 @@ func Register(
 	profileProtected.HandleFunc("/{userID}/watchlist", watchlistHandler.List).Methods(http.MethodGet)
 	profileProtected.HandleFunc("/{userID}/watchlist", watchlistHandler.Add).Methods(http.MethodPost)
 	profileProtected.HandleFunc("/{userID}/watchlist", watchlistHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/watchlist/availability", watchlistHandler.Availability).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/watchlist/availability", watchlistHandler.Options).Methods(http.MethodOptions)
+	// Registered before /{mediaType}/{id}, which would match these paths too
+	profileProtected.HandleFunc("/{userID}/watchlist/shares", watchlistHandler.Shares).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/watchlist/shares", watchlistHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/watchlist/shares/{profileID}", watchlistHandler.Share).Methods(http.MethodPut)
+	profileProtected.HandleFunc("/{userID}/watchlist/shares/{profileID}", watchlistHandler.Unshare).Methods(http.MethodDelete)
+	profileProtected.HandleFunc("/{userID}/watchlist/shares/{profileID}", watchlistHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/watchlist/{mediaType}/{id}", watchlistHandler.UpdateState).Methods(http.MethodPatch)
 	profileProtected.HandleFunc("/{userID}/watchlist/{mediaType}/{id}", watchlistHandler.Remove).Methods(http.MethodDelete)
 	profileProtected.HandleFunc("/{userID}/watchlist/{mediaType}/{id}", watchlistHandler.Options).Methods(http.MethodOptions)
@@ -454,8 +499,18 @@ func Register(
 	profileProtected.HandleFunc("/{userID}/history/continue", historyHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/history/continue/{seriesID}/hide", historyHandler.HideFromContinueWatching).Methods(http.MethodPost)
 	profileProtected.HandleFunc("/{userID}/history/continue/{seriesID}/hide", historyHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/history/continue/archive", historyHandler.ListContinueWatchingArchive).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/history/continue/archive", historyHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/history/continue/archive/{seriesID}/restore", historyHandler.RestoreToContinueWatching).Methods(http.MethodPost)
+	profileProtected.HandleFunc("/{userID}/history/continue/archive/{seriesID}/restore", historyHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/history/series/{seriesID}", historyHandler.GetSeriesWatchState).Methods(http.MethodGet)
 	profileProtected.HandleFunc("/{userID}/history/series/{seriesID}", historyHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/history/series/{seriesID}/watched", historyHandler.SetSeriesWatched).Methods(http.MethodPost)
+	profileProtected.HandleFunc("/{userID}/history/series/{seriesID}/watched", historyHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/history/missing", historyHandler.ListMissingEpisodes).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/history/missing", historyHandler.Options).Methods(http.MethodOptions)
+	profileProtected.HandleFunc("/{userID}/history/missing/{seriesID}", historyHandler.GetMissingEpisodes).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/history/missing/{seriesID}", historyHandler.Options).Methods(http.MethodOptions)
 	profileProtected.HandleFunc("/{userID}/history/episodes", historyHandler.RecordEpisode).Methods(http.MethodPost)
 	profileProtected.HandleFunc("/{userID}/history/episodes", historyHandler.Options).Methods(http.MethodOptions)
 
@@ -479,6 +534,17 @@ func Register(
 	profileProtected.HandleFunc("/{userID}/history/progress/{mediaType}/{id}", historyHandler.DeletePlaybackProgress).Methods(http.MethodDelete)
 	profileProtected.HandleFunc("/{userID}/history/progress/{mediaType}/{id}", historyHandler.Options).Methods(http.MethodOptions)
 
+	// Watch-together: profiles credited alongside the user for playback started on this device
+	profileProtected.HandleFunc("/{userID}/history/viewers", historyHandler.GetCoViewers).Methods(http.MethodGet)
+	profileProtected.HandleFunc("/{userID}/history/viewers", historyHandler.SetCoViewers).Methods(http.MethodPut, http.MethodDelete)
+	profileProtected.HandleFunc("/{userID}/history/viewers", historyHandler.Options).Methods(http.MethodOptions)
+
+	// Delta sync (incremental watchlist/history/progress changes since a cursor)
+	if syncHandler != nil {
+		profileProtected.HandleFunc("/{userID}/sync", syncHandler.Sync).Methods(http.MethodGet)
+		profileProtected.HandleFunc("/{userID}/sync", syncHandler.Options).Methods(http.MethodOptions)
+	}
+
 	// Content Preferences endpoints (per-content audio/subtitle preferences)
 	if contentPreferencesHandler != nil {
 		profileProtected.HandleFunc("/{userID}/preferences/content", contentPreferencesHandler.ListPreferences).Methods(http.MethodGet)
@@ -488,6 +554,19 @@ func Register(
 		profileProtected.HandleFunc("/{userID}/preferences/content/{contentID}", contentPreferencesHandler.DeletePreference).Methods(http.MethodDelete)
 		profileProtected.HandleFunc("/{userID}/preferences/content/{contentID}", contentPreferencesHandler.Options).Methods(http.MethodOptions)
 	}
+
+	// Pinned release versions (per-title version selector)
+	if releaseVersionsHandler != nil {
+		profileProtected.HandleFunc("/{userID}/versions", releaseVersionsHandler.ListAll).Methods(http.MethodGet)
+		profileProtected.HandleFunc("/{userID}/versions", releaseVersionsHandler.Options).Methods(http.MethodOptions)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}", releaseVersionsHandler.List).Methods(http.MethodGet)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}", releaseVersionsHandler.Pin).Methods(http.MethodPost)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}", releaseVersionsHandler.Options).Methods(http.MethodOptions)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}/{versionID}", releaseVersionsHandler.Remove).Methods(http.MethodDelete)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}/{versionID}", releaseVersionsHandler.Options).Methods(http.MethodOptions)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}/{versionID}/default", releaseVersionsHandler.SetDefault).Methods(http.MethodPut)
+		profileProtected.HandleFunc("/{userID}/versions/{contentID}/{versionID}/default", releaseVersionsHandler.Options).Methods(http.MethodOptions)
+	}
 }
 
 // RegisterTraktRoutes registers Trakt account management API endpoints.
@@ -515,3 +594,413 @@ func RegisterTraktRoutes(r *mux.Router, traktHandler *handlers.TraktAccountsHand
 	api.HandleFunc("/accounts/{accountID}/history", traktHandler.GetHistory).Methods(http.MethodGet)
 	api.HandleFunc("/accounts/{accountID}/history", handleOptions).Methods(http.MethodOptions)
 }
+
+// RegisterMediaFailureRoutes registers the admin probe/transcode failure metrics endpoints.
+func RegisterMediaFailureRoutes(r *mux.Router, mediaFailuresHandler *handlers.MediaFailuresHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/media-failures").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", mediaFailuresHandler.Summary).Methods(http.MethodGet)
+	api.HandleFunc("", mediaFailuresHandler.Reset).Methods(http.MethodDelete)
+	api.HandleFunc("", mediaFailuresHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterFFmpegLogRoutes registers the admin endpoints exposing captured ffmpeg output.
+func RegisterFFmpegLogRoutes(r *mux.Router, logsHandler *handlers.FFmpegLogsHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/ffmpeg-logs").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", logsHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", logsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{id}", logsHandler.Get).Methods(http.MethodGet)
+	api.HandleFunc("/{id}", logsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterSelectionStatsRoutes registers the admin release selection outcome endpoints.
+func RegisterSelectionStatsRoutes(r *mux.Router, selectionStatsHandler *handlers.SelectionStatsHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/selection-stats").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", selectionStatsHandler.Summary).Methods(http.MethodGet)
+	api.HandleFunc("", selectionStatsHandler.Reset).Methods(http.MethodDelete)
+	api.HandleFunc("", selectionStatsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterPlaybackTimingRoutes registers the admin playback start latency breakdown endpoints.
+func RegisterPlaybackTimingRoutes(r *mux.Router, timingHandler *handlers.PlaybackTimingHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/playback-timings").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", timingHandler.Summary).Methods(http.MethodGet)
+	api.HandleFunc("", timingHandler.Reset).Methods(http.MethodDelete)
+	api.HandleFunc("", timingHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterExternalSubtitleRoutes registers the endpoints for subtitles users
+// upload for a title or episode.
+func RegisterExternalSubtitleRoutes(r *mux.Router, subtitlesHandler *handlers.ExternalSubtitlesHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/subtitles/external").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.HandleFunc("", subtitlesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", subtitlesHandler.Upload).Methods(http.MethodPost)
+	api.HandleFunc("", subtitlesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{id}", subtitlesHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{id}", subtitlesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{id}/subtitles.vtt", subtitlesHandler.Serve).Methods(http.MethodGet)
+	api.HandleFunc("/{id}/subtitles.vtt", subtitlesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterDeviceRoutes registers the device registry endpoints. Any account may
+// register its device and run the playback compatibility suite on it; listing
+// and managing devices requires the master account.
+func RegisterDeviceRoutes(r *mux.Router, devicesHandler *handlers.DevicesHandler, compatHandler *handlers.PlaybackCompatHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/devices").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.HandleFunc("/register", devicesHandler.Register).Methods(http.MethodPost)
+	api.HandleFunc("/register", devicesHandler.Options).Methods(http.MethodOptions)
+
+	// Playback compatibility suite; registered before /{deviceID} so the admin routes don't shadow it
+	api.HandleFunc("/compatibility", compatHandler.Suite).Methods(http.MethodGet)
+	api.HandleFunc("/compatibility", compatHandler.Report).Methods(http.MethodPost)
+	api.HandleFunc("/compatibility", compatHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/compatibility/clips/{clipID}", compatHandler.ServeClip).Methods(http.MethodGet, http.MethodHead)
+	api.HandleFunc("/compatibility/clips/{clipID}", compatHandler.Options).Methods(http.MethodOptions)
+
+	admin := api.PathPrefix("").Subrouter()
+	admin.Use(MasterOnlyMiddleware())
+	admin.HandleFunc("", devicesHandler.List).Methods(http.MethodGet)
+	admin.HandleFunc("", devicesHandler.Options).Methods(http.MethodOptions)
+	admin.HandleFunc("/{deviceID}", devicesHandler.Get).Methods(http.MethodGet)
+	admin.HandleFunc("/{deviceID}", devicesHandler.Update).Methods(http.MethodPatch)
+	admin.HandleFunc("/{deviceID}", devicesHandler.Delete).Methods(http.MethodDelete)
+	admin.HandleFunc("/{deviceID}", devicesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterMaintenanceRoutes registers the admin maintenance mode and drain status endpoints.
+func RegisterMaintenanceRoutes(r *mux.Router, maintenanceHandler *handlers.MaintenanceHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/maintenance").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", maintenanceHandler.Status).Methods(http.MethodGet)
+	api.HandleFunc("", maintenanceHandler.Enable).Methods(http.MethodPost)
+	api.HandleFunc("", maintenanceHandler.Disable).Methods(http.MethodDelete)
+	api.HandleFunc("", maintenanceHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterJobRoutes registers the admin endpoints for listing, running and rescheduling background maintenance jobs.
+func RegisterJobRoutes(r *mux.Router, jobsHandler *handlers.JobsHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/jobs").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", jobsHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", jobsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{jobID}", jobsHandler.Get).Methods(http.MethodGet)
+	api.HandleFunc("/{jobID}", jobsHandler.Update).Methods(http.MethodPatch)
+	api.HandleFunc("/{jobID}", jobsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{jobID}/run", jobsHandler.RunNow).Methods(http.MethodPost)
+	api.HandleFunc("/{jobID}/run", jobsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{jobID}/enable", jobsHandler.Enable).Methods(http.MethodPost)
+	api.HandleFunc("/{jobID}/enable", jobsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{jobID}/disable", jobsHandler.Disable).Methods(http.MethodPost)
+	api.HandleFunc("/{jobID}/disable", jobsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterScrobbleOutboxRoutes registers the admin endpoints for inspecting and flushing queued Trakt scrobbles.
+func RegisterScrobbleOutboxRoutes(r *mux.Router, outboxHandler *handlers.ScrobbleOutboxHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/scrobble-outbox").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", outboxHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", outboxHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/flush", outboxHandler.Flush).Methods(http.MethodPost)
+	api.HandleFunc("/flush", outboxHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{entryID}", outboxHandler.Discard).Methods(http.MethodDelete)
+	api.HandleFunc("/{entryID}", outboxHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterProviderUsageRoutes registers the admin endpoint reporting traffic and quota usage per provider.
+func RegisterProviderUsageRoutes(r *mux.Router, usageHandler *handlers.ProviderUsageHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/provider-usage").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", usageHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", usageHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterOrphanGCRoutes registers the admin endpoints for the orphaned record garbage collector.
+func RegisterOrphanGCRoutes(r *mux.Router, gcHandler *handlers.OrphanGCHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/orphan-gc").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", gcHandler.Report).Methods(http.MethodGet)
+	api.HandleFunc("", gcHandler.Run).Methods(http.MethodPost)
+	api.HandleFunc("", gcHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterMatchOverrideRoutes registers the admin endpoints for manually correcting metadata matches.
+func RegisterMatchOverrideRoutes(r *mux.Router, overridesHandler *handlers.MatchOverridesHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/admin/metadata-overrides").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(MasterOnlyMiddleware())
+
+	api.HandleFunc("", overridesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", overridesHandler.Set).Methods(http.MethodPut)
+	api.HandleFunc("", overridesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{overrideID}", overridesHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{overrideID}", overridesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterSpoilerHoldRoutes registers the per-profile "don't spoil" hold endpoints.
+func RegisterSpoilerHoldRoutes(r *mux.Router, holdsHandler *handlers.SpoilerHoldsHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/spoiler-holds", holdsHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/spoiler-holds", holdsHandler.Create).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/spoiler-holds", holdsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/spoiler-holds/{holdID}", holdsHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/spoiler-holds/{holdID}", holdsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterHiddenTitleRoutes registers the per-profile "not interested" title endpoints.
+func RegisterHiddenTitleRoutes(r *mux.Router, hiddenHandler *handlers.HiddenTitlesHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/hidden-titles", hiddenHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/hidden-titles", hiddenHandler.Hide).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/hidden-titles", hiddenHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/hidden-titles/{titleID}", hiddenHandler.Unhide).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/hidden-titles/{titleID}", hiddenHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterFavoriteRoutes registers the per-profile favorites endpoints.
+func RegisterFavoriteRoutes(r *mux.Router, favoritesHandler *handlers.FavoritesHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/favorites", favoritesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/favorites", favoritesHandler.Add).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/favorites", favoritesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/favorites/export", favoritesHandler.Export).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/favorites/export", favoritesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/favorites/import-watchlist", favoritesHandler.ImportFromWatchlist).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/favorites/import-watchlist", favoritesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/favorites/{mediaType}/{id}", favoritesHandler.Remove).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/favorites/{mediaType}/{id}", favoritesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterTitleNoteRoutes registers the household-shared title note endpoints.
+func RegisterTitleNoteRoutes(r *mux.Router, notesHandler *handlers.TitleNotesHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/title-notes", notesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/title-notes", notesHandler.Create).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/title-notes", notesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/title-notes/{noteID}", notesHandler.Update).Methods(http.MethodPatch)
+	api.HandleFunc("/{userID}/title-notes/{noteID}", notesHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/title-notes/{noteID}", notesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterPlaybackQueueRoutes registers the per-profile playback queue endpoints.
+func RegisterPlaybackQueueRoutes(r *mux.Router, queueHandler *handlers.PlaybackQueueHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/playback-queue", queueHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/playback-queue", queueHandler.Add).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/playback-queue", queueHandler.Clear).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/playback-queue", queueHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/playback-queue/order", queueHandler.Reorder).Methods(http.MethodPut)
+	api.HandleFunc("/{userID}/playback-queue/order", queueHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/playback-queue/{itemID}", queueHandler.Remove).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/playback-queue/{itemID}", queueHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterSearchRetryRoutes registers the per-profile endpoints listing
+// background searches for just-aired episodes.
+func RegisterSearchRetryRoutes(r *mux.Router, retriesHandler *handlers.SearchRetriesHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/search-retries", retriesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/search-retries", retriesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/search-retries/{retryID}", retriesHandler.Dismiss).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/search-retries/{retryID}", retriesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterAudioDownloadRoutes registers the per-profile endpoints extracting
+// the audio of a title into the downloads area.
+func RegisterAudioDownloadRoutes(r *mux.Router, downloadsHandler *handlers.AudioDownloadsHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/audio-downloads", downloadsHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/audio-downloads", downloadsHandler.Create).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/audio-downloads", downloadsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/audio-downloads/{downloadID}", downloadsHandler.Get).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/audio-downloads/{downloadID}", downloadsHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/audio-downloads/{downloadID}", downloadsHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/audio-downloads/{downloadID}/file", downloadsHandler.File).Methods(http.MethodGet, http.MethodHead)
+	api.HandleFunc("/{userID}/audio-downloads/{downloadID}/file", downloadsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterCalendarPrefetchRoutes registers the per-profile endpoint listing
+// episodes prefetched from the profile's Trakt calendar.
+func RegisterCalendarPrefetchRoutes(r *mux.Router, prefetchHandler *handlers.CalendarPrefetchHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/calendar-prefetch", prefetchHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/calendar-prefetch", prefetchHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterSavedSearchRoutes registers the per-profile endpoints managing
+// saved release searches and acknowledging the new releases they found.
+func RegisterSavedSearchRoutes(r *mux.Router, savedSearchesHandler *handlers.SavedSearchesHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/saved-searches", savedSearchesHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/saved-searches", savedSearchesHandler.Create).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/saved-searches", savedSearchesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/saved-searches/{searchID}", savedSearchesHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/saved-searches/{searchID}", savedSearchesHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/saved-searches/{searchID}/acknowledge", savedSearchesHandler.Acknowledge).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/saved-searches/{searchID}/acknowledge", savedSearchesHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterShareLinkRoutes registers the per-profile endpoints managing stream
+// share links and the public endpoint serving shared streams, which
+// authenticates with the link's token instead of a session.
+func RegisterShareLinkRoutes(r *mux.Router, shareLinksHandler *handlers.ShareLinksHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	public := r.PathPrefix("/api/share").Subrouter()
+	public.Use(corsMiddleware)
+
+	public.HandleFunc("/{token}", shareLinksHandler.Stream).Methods(http.MethodGet, http.MethodHead)
+	public.HandleFunc("/{token}", shareLinksHandler.Options).Methods(http.MethodOptions)
+
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/share-links", shareLinksHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/share-links", shareLinksHandler.Create).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/share-links", shareLinksHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/share-links/{linkID}", shareLinksHandler.Revoke).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/share-links/{linkID}", shareLinksHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterFederationRoutes registers the endpoints federated strmr servers pull
+// profile changes from, which authenticate with signed requests instead of a
+// session, and the admin endpoints for managing peers.
+func RegisterFederationRoutes(r *mux.Router, federationHandler *handlers.FederationHandler, sessionsSvc *sessions.Service) {
+	peerAPI := r.PathPrefix("/api/federation").Subrouter()
+	peerAPI.Use(corsMiddleware)
+
+	peerAPI.HandleFunc("/profiles", federationHandler.Profiles).Methods(http.MethodGet)
+	peerAPI.HandleFunc("/profiles/{profileID}/changes", federationHandler.Changes).Methods(http.MethodGet)
+
+	admin := r.PathPrefix("/api/admin/federation").Subrouter()
+	admin.Use(corsMiddleware)
+	admin.Use(AccountAuthMiddleware(sessionsSvc))
+	admin.Use(MasterOnlyMiddleware())
+
+	admin.HandleFunc("/peers", federationHandler.ListPeers).Methods(http.MethodGet)
+	admin.HandleFunc("/peers", federationHandler.CreatePeer).Methods(http.MethodPost)
+	admin.HandleFunc("/peers", federationHandler.Options).Methods(http.MethodOptions)
+	admin.HandleFunc("/peers/{peerID}", federationHandler.UpdatePeer).Methods(http.MethodPut)
+	admin.HandleFunc("/peers/{peerID}", federationHandler.DeletePeer).Methods(http.MethodDelete)
+	admin.HandleFunc("/peers/{peerID}", federationHandler.Options).Methods(http.MethodOptions)
+	admin.HandleFunc("/peers/{peerID}/sync", federationHandler.SyncPeer).Methods(http.MethodPost)
+	admin.HandleFunc("/peers/{peerID}/sync", federationHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterRemoteRoutes registers the companion remote-control API (pairing, commands and WebSocket relay).
+func RegisterRemoteRoutes(r *mux.Router, remoteHandler *handlers.RemoteHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/remote").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.HandleFunc("/pairing-codes", remoteHandler.CreatePairingCode).Methods(http.MethodPost)
+	api.HandleFunc("/pairing-codes", remoteHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/pair", remoteHandler.Pair).Methods(http.MethodPost)
+	api.HandleFunc("/pair", remoteHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/pairings", remoteHandler.ListPairings).Methods(http.MethodGet)
+	api.HandleFunc("/pairings", remoteHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/pairings/{pairingID}", remoteHandler.Unpair).Methods(http.MethodDelete)
+	api.HandleFunc("/pairings/{pairingID}", remoteHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/pairings/{pairingID}/commands", remoteHandler.SendCommand).Methods(http.MethodPost)
+	api.HandleFunc("/pairings/{pairingID}/commands", remoteHandler.Options).Methods(http.MethodOptions)
+	api.HandleFunc("/pairings/{pairingID}/ws", remoteHandler.ControllerSocket).Methods(http.MethodGet)
+	api.HandleFunc("/targets/{clientID}/ws", remoteHandler.TargetSocket).Methods(http.MethodGet)
+}
+
+// RegisterReleaseHintRoutes registers the "likely available" hint endpoint for
+// browse screens.
+func RegisterReleaseHintRoutes(r *mux.Router, hintsHandler *handlers.ReleaseHintsHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/release-hints").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.HandleFunc("", hintsHandler.Check).Methods(http.MethodPost)
+	api.HandleFunc("", hintsHandler.Options).Methods(http.MethodOptions)
+}
+
+// RegisterProxyCheckRoutes registers the reverse-proxy compatibility check and
+// the synthetic stream and payload it fetches back through the proxy.
+func RegisterProxyCheckRoutes(r *mux.Router, proxyCheckHandler *handlers.ProxyCheckHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/health/proxy-check").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.Handle("", MasterOnlyMiddleware()(http.HandlerFunc(proxyCheckHandler.Check))).Methods(http.MethodGet)
+	api.HandleFunc("", handleOptions).Methods(http.MethodOptions)
+	api.HandleFunc("/stream", proxyCheckHandler.Stream).Methods(http.MethodGet)
+	api.HandleFunc("/stream", handleOptions).Methods(http.MethodOptions)
+	api.HandleFunc("/payload", proxyCheckHandler.Payload).Methods(http.MethodGet, http.MethodHead)
+	api.HandleFunc("/payload", handleOptions).Methods(http.MethodOptions)
+}
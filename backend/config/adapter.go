@@ -1,6 +1,9 @@
 package config
 
 import (
+	"net"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/javi11/nntppool"
@@ -154,3 +157,17 @@ func ToNNTPProviders(settings []UsenetSettings) []nntppool.UsenetProviderConfig
 
 	return providers
 }
+
+// NNTPBindings maps each enabled provider with a bind address to that address,
+// keyed by "host:port" as the pool dialer sees it.
+func NNTPBindings(settings []UsenetSettings) map[string]string {
+	bindings := make(map[string]string)
+	for _, s := range settings {
+		bind := strings.TrimSpace(s.BindAddress)
+		if !s.Enabled || s.Host == "" || bind == "" {
+			continue
+		}
+		bindings[net.JoinHostPort(s.Host, strconv.Itoa(s.Port))] = bind
+	}
+	return bindings
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxConfigBackups is how many shadow copies of the settings file are kept.
+	maxConfigBackups = 10
+	// configBackupLayout names shadow copies after the time they were taken.
+	configBackupLayout = "20060102T150405.000000000Z"
+)
+
+// NoticeRestored reports that an unreadable settings file was replaced by
+// its newest readable shadow copy.
+const NoticeRestored = "restored"
+
+// ErrBackupNotFound is returned when restoring a shadow copy that doesn't exist.
+var ErrBackupNotFound = errors.New("config backup not found")
+
+var configBackupID = regexp.MustCompile(`^\d{8}T\d{6}\.\d{9}Z$`)
+
+// ConfigBackup describes a shadow copy of the settings file.
+type ConfigBackup struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Size      int64     `json:"size"`
+}
+
+// configParseError marks a settings file that exists but can't be decoded.
+type configParseError struct {
+	err error
+}
+
+func (e *configParseError) Error() string { return "parse settings: " + e.err.Error() }
+func (e *configParseError) Unwrap() error { return e.err }
+
+func (m *Manager) backupDir() string {
+	return filepath.Join(filepath.Dir(m.path), "config-backups")
+}
+
+// ListBackups returns the shadow copies of the settings file, newest first.
+func (m *Manager) ListBackups() ([]ConfigBackup, error) {
+	entries, err := os.ReadDir(m.backupDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []ConfigBackup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]ConfigBackup, 0, len(entries))
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !configBackupID.MatchString(id) {
+			continue
+		}
+		createdAt, err := time.Parse(configBackupLayout, id)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, ConfigBackup{ID: id, CreatedAt: createdAt, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID > backups[j].ID })
+	return backups, nil
+}
+
+// RestoreBackup replaces the settings file with the shadow copy id and
+// returns the restored settings.
+func (m *Manager) RestoreBackup(id string) (Settings, error) {
+	if !configBackupID.MatchString(id) {
+		return Settings{}, ErrBackupNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(m.backupDir(), id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return Settings{}, ErrBackupNotFound
+	}
+	if err != nil {
+		return Settings{}, err
+	}
+	if err := validSettingsJSON(data); err != nil {
+		return Settings{}, fmt.Errorf("backup %s is unreadable: %w", id, err)
+	}
+
+	if err := writeFileAtomic(m.path, data); err != nil {
+		return Settings{}, err
+	}
+	m.shadowCopy(data)
+	log.Printf("[config] restored settings from backup %s", id)
+	return m.Load()
+}
+
+// rollback replaces an unreadable settings file with the newest shadow copy
+// that parses, keeping the broken file next to it. It returns the backup used.
+func (m *Manager) rollback() (ConfigBackup, bool) {
+	backups, err := m.ListBackups()
+	if err != nil {
+		return ConfigBackup{}, false
+	}
+	for _, backup := range backups {
+		data, err := os.ReadFile(filepath.Join(m.backupDir(), backup.ID+".json"))
+		if err != nil || validSettingsJSON(data) != nil {
+			continue
+		}
+		corrupt := m.path + ".corrupt-" + time.Now().UTC().Format(configBackupLayout)
+		if err := os.Rename(m.path, corrupt); err != nil {
+			log.Printf("[config] could not keep the unreadable settings file: %v", err)
+		}
+		if err := writeFileAtomic(m.path, data); err != nil {
+			log.Printf("[config] rollback to backup %s failed: %v", backup.ID, err)
+			return ConfigBackup{}, false
+		}
+		log.Printf("[config] settings file was unreadable; rolled back to backup %s (broken file kept as %s)", backup.ID, corrupt)
+		return backup, true
+	}
+	return ConfigBackup{}, false
+}
+
+// shadowCopy stores data as the newest backup, unless it matches the newest
+// one, and prunes backups beyond maxConfigBackups. Failures are logged only:
+// the settings themselves were already saved.
+func (m *Manager) shadowCopy(data []byte) {
+	dir := m.backupDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("[config] failed to create backup directory: %v", err)
+		return
+	}
+
+	backups, err := m.ListBackups()
+	if err != nil {
+		log.Printf("[config] failed to list backups: %v", err)
+		return
+	}
+	if len(backups) > 0 {
+		if newest, err := os.ReadFile(filepath.Join(dir, backups[0].ID+".json")); err == nil && bytes.Equal(newest, data) {
+			return
+		}
+	}
+
+	id := time.Now().UTC().Format(configBackupLayout)
+	if err := writeFileAtomic(filepath.Join(dir, id+".json"), data); err != nil {
+		log.Printf("[config] failed to write backup %s: %v", id, err)
+		return
+	}
+
+	backups = append([]ConfigBackup{{ID: id}}, backups...)
+	for _, old := range backups[min(len(backups), maxConfigBackups):] {
+		_ = os.Remove(filepath.Join(dir, old.ID+".json"))
+	}
+}
+
+// validSettingsJSON reports whether data decodes as settings.
+func validSettingsJSON(data []byte) error {
+	var s Settings
+	return json.Unmarshal(data, &s)
+}
+
+// writeFileAtomic replaces path with data through a synced temporary file, so
+// a crash leaves either the old or the new contents, never a partial write.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
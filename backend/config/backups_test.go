@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRollsBackUnreadableSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	m := NewManager(path)
+
+	s := DefaultSettings()
+	s.Server.Port = 7777
+	if err := m.Save(s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A partial write leaves truncated JSON behind
+	if err := os.WriteFile(path, []byte(`{"server": {"port": 77`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, notices, err := m.LoadWithNotices()
+	if err != nil {
+		t.Fatalf("expected rollback, got error %v", err)
+	}
+	if loaded.Server.Port != 7777 {
+		t.Fatalf("expected the backed up port, got %d", loaded.Server.Port)
+	}
+	if len(notices) == 0 || notices[0].Kind != NoticeRestored {
+		t.Fatalf("expected a restored notice, got %+v", notices)
+	}
+	if matches, _ := filepath.Glob(path + ".corrupt-*"); len(matches) != 1 {
+		t.Fatalf("expected the broken file to be kept, got %v", matches)
+	}
+}
+
+func TestBackupsArePrunedAndRestorable(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "settings.json"))
+
+	s := DefaultSettings()
+	for port := 1; port <= maxConfigBackups+3; port++ {
+		s.Server.Port = port
+		if err := m.Save(s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	// Saving unchanged settings doesn't add a copy
+	if err := m.Save(s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	backups, err := m.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != maxConfigBackups {
+		t.Fatalf("expected %d backups, got %d", maxConfigBackups, len(backups))
+	}
+
+	restored, err := m.RestoreBackup(backups[1].ID)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if restored.Server.Port != maxConfigBackups+2 {
+		t.Fatalf("expected the previous version, got port %d", restored.Server.Port)
+	}
+	if _, err := m.RestoreBackup("../settings"); err != ErrBackupNotFound {
+		t.Fatalf("expected ErrBackupNotFound, got %v", err)
+	}
+}
@@ -1,13 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -27,6 +30,10 @@ type Settings struct {
 	SABnzbd         SABnzbdSettings        `json:"sabnzbd"`
 	AltMount        *AltMountSettings      `json:"altmount,omitempty"`
 	Transmux        TransmuxSettings       `json:"transmux"`
+	Sandbox         SandboxSettings        `json:"sandbox"`
+	DiskSpace       DiskSpaceSettings      `json:"diskSpace"`
+	SearchRetry     SearchRetrySettings    `json:"searchRetry"`
+	Kids            KidsSettings           `json:"kids"`
 	Playback        PlaybackSettings       `json:"playback"`
 	Live            LiveSettings           `json:"live"`
 	HomeShelves     HomeShelvesSettings    `json:"homeShelves"`
@@ -38,14 +45,78 @@ type Settings struct {
 	Trakt           TraktSettings          `json:"trakt,omitempty"`
 	Plex            PlexSettings           `json:"plex,omitempty"`
 	Log             LogConfig              `json:"log"`
+	AccessLog       AccessLogSettings      `json:"accessLog"`
 	ScheduledTasks  ScheduledTasksSettings `json:"scheduledTasks,omitempty"`
 	Network         NetworkSettings        `json:"network,omitempty"`
 	Ranking         RankingSettings        `json:"ranking,omitempty"`
+	CORS            CORSSettings           `json:"cors"`
+	Developer       DeveloperSettings      `json:"developer"`
 }
 
 type ServerSettings struct {
 	Host string `json:"host"`
 	Port int    `json:"port"`
+	// Listeners binds several addresses at once (e.g. IPv4 and IPv6, or a unix
+	// socket for a reverse proxy). When set, Host and Port are ignored.
+	Listeners []ListenerSettings `json:"listeners,omitempty"`
+	// BaseURL is the externally reachable URL of the server, e.g.
+	// "https://media.example.com". It is used when generating absolute URLs
+	// (invitation links, HLS playlists, direct links); when empty they are
+	// derived from the request.
+	BaseURL string `json:"baseUrl,omitempty"`
+	// TrustedProxies lists the reverse proxies (addresses or CIDR networks)
+	// whose X-Forwarded-For and X-Real-IP headers are believed. Empty trusts
+	// loopback and private networks.
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+}
+
+// ListenerSettings is one address the server listens on and which routes it serves.
+type ListenerSettings struct {
+	// Address is "host:port" (use "[::]:7777" for IPv6) or "unix:/path/to.sock"
+	Address string `json:"address"`
+	// DisableAdmin stops this listener serving the admin and account UIs and the
+	// /api/admin endpoints, e.g. on an internet-facing address
+	DisableAdmin bool `json:"disableAdmin,omitempty"`
+	// DisableStreaming stops this listener serving everything else: the client
+	// API, streams and WebDAV
+	DisableStreaming bool `json:"disableStreaming,omitempty"`
+}
+
+// UnixSocket returns the socket path when the listener binds a unix socket.
+func (l ListenerSettings) UnixSocket() (string, bool) {
+	path, ok := strings.CutPrefix(strings.TrimSpace(l.Address), "unix:")
+	return path, ok
+}
+
+// EffectiveListeners returns the configured listeners, or a single listener on
+// Host:Port serving every route.
+func (s ServerSettings) EffectiveListeners() []ListenerSettings {
+	if len(s.Listeners) > 0 {
+		return s.Listeners
+	}
+	return []ListenerSettings{{Address: net.JoinHostPort(s.Host, strconv.Itoa(s.Port))}}
+}
+
+// LocalBaseURL returns a loopback URL the server can use to reach its own
+// streaming routes (e.g. WebDAV for ffprobe), or "" if no TCP listener serves them.
+func (s ServerSettings) LocalBaseURL() string {
+	for _, listener := range s.EffectiveListeners() {
+		if _, isUnix := listener.UnixSocket(); isUnix || listener.DisableStreaming {
+			continue
+		}
+		host, port, err := net.SplitHostPort(strings.TrimSpace(listener.Address))
+		if err != nil {
+			continue
+		}
+		switch host {
+		case "", "0.0.0.0":
+			host = "127.0.0.1"
+		case "::":
+			host = "::1"
+		}
+		return "http://" + net.JoinHostPort(host, port)
+	}
+	return ""
 }
 
 type UsenetSettings struct {
@@ -57,6 +128,21 @@ type UsenetSettings struct {
 	Password    string `json:"password"`
 	Connections int    `json:"connections"`
 	Enabled     bool   `json:"enabled"`
+	// MonthlyQuotaGB caps the traffic fetched from this provider per calendar
+	// month (0 = unlimited). Useful for block accounts.
+	MonthlyQuotaGB int `json:"monthlyQuotaGb,omitempty"`
+	// BindAddress is the local IP or network interface (e.g. "wg0") that
+	// connections to this provider are made from. Empty uses the default route.
+	BindAddress string `json:"bindAddress,omitempty"`
+}
+
+// AccountName identifies the provider in usage tracking: its name, or its host
+// when unnamed.
+func (u UsenetSettings) AccountName() string {
+	if name := strings.TrimSpace(u.Name); name != "" {
+		return name
+	}
+	return u.Host
 }
 
 type IndexerConfig struct {
@@ -65,7 +151,15 @@ type IndexerConfig struct {
 	APIKey     string `json:"apiKey"`
 	Type       string `json:"type"`       // newznab | torznab
 	Categories string `json:"categories"` // Comma-separated newznab category IDs (e.g., "2000,2010,2020" for movies, "5000,5010,5020" for TV)
-	Enabled    bool   `json:"enabled"`
+	// Per-media-type category mapping; when set, used instead of Categories for that media type
+	MovieCategories string `json:"movieCategories,omitempty"`
+	TVCategories    string `json:"tvCategories,omitempty"`
+	// Query templates rewrite search queries for indexers that expect a different format.
+	// Placeholders: {title}, {year}, {season}, {episode}, {ss}, {ee} (zero-padded).
+	MovieQueryTemplate   string `json:"movieQueryTemplate,omitempty"`   // e.g. "{title} {year}"
+	EpisodeQueryTemplate string `json:"episodeQueryTemplate,omitempty"` // e.g. "{title} S{ss}E{ee}" or "{title} {season}x{episode}"
+	SeasonQueryTemplate  string `json:"seasonQueryTemplate,omitempty"`  // e.g. "{title} S{ss}" or "{title} Season {season}"
+	Enabled              bool   `json:"enabled"`
 }
 
 type TorrentScraperConfig struct {
@@ -99,12 +193,140 @@ type LogConfig struct {
 	Compress   bool   `json:"compress"`
 }
 
+// AccessLogSettings configures the structured HTTP access log. It is written
+// to its own file with its own rotation so it can be kept for less time than
+// the app log, and can be turned on and off without a restart.
+type AccessLogSettings struct {
+	Enabled    bool   `json:"enabled"`
+	File       string `json:"file"`
+	MaxSize    int    `json:"maxSize"`
+	MaxAge     int    `json:"maxAge"`
+	MaxBackups int    `json:"maxBackups"`
+	Compress   bool   `json:"compress"`
+	// RedactIPs logs client addresses truncated to their /24 (IPv4) or /48
+	// (IPv6) network.
+	RedactIPs bool `json:"redactIps"`
+	// RedactTokens blanks credentials (tokens, API keys, PINs) in logged
+	// query strings.
+	RedactTokens bool `json:"redactTokens"`
+}
+
 // TransmuxSettings describes optional container conversion for browser playback
 type TransmuxSettings struct {
 	Enabled          bool   `json:"enabled"`
 	FFmpegPath       string `json:"ffmpegPath"`
 	FFprobePath      string `json:"ffprobePath"`
 	HLSTempDirectory string `json:"hlsTempDirectory"` // Directory for HLS segment storage (default: /tmp/novastream-hls)
+	// TranscodeCacheMB is the disk budget for completed video transcodes that
+	// later sessions of the same release and settings reuse. 0 = disabled
+	TranscodeCacheMB int `json:"transcodeCacheMb"`
+	// Probe tunes ffprobe per source type; zero values keep the defaults
+	Probe ProbeSettings `json:"probe"`
+}
+
+// ProbeSettings holds the ffprobe strategy for each kind of source.
+type ProbeSettings struct {
+	// Debrid covers debrid direct URLs and pre-resolved external URLs
+	Debrid ProbeStrategy `json:"debrid"`
+	// Usenet covers usenet files read over WebDAV or piped from the provider
+	Usenet ProbeStrategy `json:"usenet"`
+}
+
+// ProbeStrategy tunes how deeply ffprobe inspects a source. Zero values use
+// the defaults below.
+type ProbeStrategy struct {
+	// SampleSizeMB is how much of the file is piped to ffprobe when the source
+	// can't be read by URL (default 16)
+	SampleSizeMB int `json:"sampleSizeMb,omitempty"`
+	// ProbeSizeKB is ffprobe's -probesize (default 1000)
+	ProbeSizeKB int `json:"probeSizeKb,omitempty"`
+	// AnalyzeDurationMs is ffprobe's -analyzeduration (default 500)
+	AnalyzeDurationMs int `json:"analyzeDurationMs,omitempty"`
+	// FastPath skips the frame sampling pass for release groups and
+	// containers whose recent probes never needed it
+	FastPath bool `json:"fastPath,omitempty"`
+}
+
+// Default probe strategy values, matching ffprobe's previous fixed flags.
+const (
+	DefaultProbeSampleSizeMB      = 16
+	DefaultProbeSizeKB            = 1000
+	DefaultProbeAnalyzeDurationMs = 500
+)
+
+// WithDefaults fills unset values with the defaults.
+func (p ProbeStrategy) WithDefaults() ProbeStrategy {
+	if p.SampleSizeMB <= 0 {
+		p.SampleSizeMB = DefaultProbeSampleSizeMB
+	}
+	if p.ProbeSizeKB <= 0 {
+		p.ProbeSizeKB = DefaultProbeSizeKB
+	}
+	if p.AnalyzeDurationMs <= 0 {
+		p.AnalyzeDurationMs = DefaultProbeAnalyzeDurationMs
+	}
+	return p
+}
+
+// Sandbox modes for spawned ffmpeg, ffprobe and yt-dlp processes.
+const (
+	SandboxModeNone     = "none"     // run binaries directly (default)
+	SandboxModeLimits   = "limits"   // CPU rlimit, memory watchdog, network namespace via unshare
+	SandboxModeFirejail = "firejail" // run inside firejail
+	SandboxModeNsjail   = "nsjail"   // run inside nsjail
+)
+
+// SandboxSettings confines the external binaries the backend spawns, which
+// otherwise run with its full privileges.
+type SandboxSettings struct {
+	Mode string `json:"mode"`
+	// WrapperPath overrides the firejail/nsjail binary; by default it is looked up on PATH
+	WrapperPath string `json:"wrapperPath,omitempty"`
+	// MemoryLimitMB caps the resident memory of a process and its children;
+	// the whole tree is killed when it is exceeded. 0 = unlimited
+	MemoryLimitMB int `json:"memoryLimitMb,omitempty"`
+	// CPUTimeLimitSeconds caps the CPU time of each process. Long transcodes
+	// legitimately use a lot, so size it generously. 0 = unlimited
+	CPUTimeLimitSeconds int `json:"cpuTimeLimitSeconds,omitempty"`
+	// NoNetwork runs ffmpeg and ffprobe without network access when they read
+	// from a pipe or local file. Invocations with URL inputs (e.g. the local
+	// WebDAV server) keep network access, as does yt-dlp
+	NoNetwork bool `json:"noNetwork,omitempty"`
+	// ExtraArgs are passed to the firejail/nsjail wrapper before the command
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+// DiskSpaceSettings sets how much free space must remain on a disk after a
+// new download or HLS session is admitted. When a disk is below its reserve,
+// idle HLS sessions and cached stream files are evicted before refusing.
+type DiskSpaceSettings struct {
+	// NZBReserveMB is kept free on the cache and NZB temp disks. 0 = no check
+	NZBReserveMB int `json:"nzbReserveMb"`
+	// HLSReserveMB is kept free on the HLS temp disk. 0 = no check
+	HLSReserveMB int `json:"hlsReserveMb"`
+}
+
+// SearchRetrySettings controls retrying episode searches that found nothing
+// because the episode has only just aired and releases haven't appeared yet.
+type SearchRetrySettings struct {
+	Enabled bool `json:"enabled"`
+	// AiredWithinHours is how recently an episode must have aired for an empty
+	// search to be retried
+	AiredWithinHours int `json:"airedWithinHours"`
+	// GiveUpAfterHours stops retrying a search this long after the first miss
+	GiveUpAfterHours int `json:"giveUpAfterHours"`
+}
+
+// KidsSettings limits what kids profiles find through free-text search.
+type KidsSettings struct {
+	// MaxMovieCertification is the highest US movie certification shown
+	// (G, PG, PG-13, R, NC-17)
+	MaxMovieCertification string `json:"maxMovieCertification"`
+	// MaxTVRating is the highest US TV parental guideline shown
+	// (TV-Y, TV-Y7, TV-G, TV-PG, TV-14, TV-MA)
+	MaxTVRating string `json:"maxTvRating"`
+	// AllowUnrated keeps results whose certification couldn't be determined
+	AllowUnrated bool `json:"allowUnrated"`
 }
 
 // WebDAVSettings defines WebDAV server configuration
@@ -131,6 +353,28 @@ type StreamingSettings struct {
 	MultiProviderMode           MultiProviderMode        `json:"multiProviderMode,omitempty"`     // How to select provider when multiple are enabled
 	UsenetResolutionTimeoutSec  int                      `json:"usenetResolutionTimeoutSec"`      // Timeout for usenet content resolution in seconds (0 = no limit)
 	IndexerTimeoutSec           int                      `json:"indexerTimeoutSec"`               // Timeout for indexer/scraper searches in seconds (default: 5)
+	DebridFailover              bool                     `json:"debridFailover"`                  // Switch degraded usenet playback to a cached debrid release (hybrid mode)
+	Binding                     StreamBindingSettings    `json:"binding"`                         // Tie stream URLs to the devices and networks using the session
+}
+
+// Stream binding match modes.
+const (
+	StreamBindingNetwork = "network" // The request must come from a network the session's app is using
+	StreamBindingClient  = "client"  // The request must carry a client ID the session's app is using
+	StreamBindingBoth    = "both"
+)
+
+// StreamBindingSettings ties stream URLs (requests authenticated with the
+// ?token= query parameter, which players copy around) to the devices and
+// networks the session's app has recently made authenticated API calls from,
+// so a leaked URL can't be replayed elsewhere.
+type StreamBindingSettings struct {
+	Enabled bool   `json:"enabled"`
+	Match   string `json:"match,omitempty"` // network (default), client or both
+	// CGNAT relaxes network matching for households behind carrier-grade NAT,
+	// whose public address changes between connections: addresses match on
+	// their /16 (IPv4) or /48 (IPv6) instead of their /24 or /64.
+	CGNAT bool `json:"cgnat,omitempty"`
 }
 
 // SearchMode determines how scraper/indexer results are aggregated
@@ -165,6 +409,18 @@ type DebridProviderSettings struct {
 	APIKey   string            `json:"apiKey"`
 	Enabled  bool              `json:"enabled"`
 	Config   map[string]string `json:"config,omitempty"` // Provider-specific settings (e.g., "autoClearQueue": "true" for Torbox)
+	// MonthlyQuotaGB caps the traffic streamed from this account per calendar
+	// month (0 = unlimited)
+	MonthlyQuotaGB int `json:"monthlyQuotaGb,omitempty"`
+}
+
+// AccountName identifies the account in usage tracking: its name, or its
+// provider type when unnamed.
+func (d DebridProviderSettings) AccountName() string {
+	if name := strings.TrimSpace(d.Name); name != "" {
+		return name
+	}
+	return d.Provider
 }
 
 // MultiProviderMode determines how multiple debrid providers are used
@@ -267,7 +523,7 @@ func (ls *LiveSettings) GetEffectivePlaylistURL() string {
 
 // ShelfConfig represents a configurable home screen shelf.
 type ShelfConfig struct {
-	ID             string `json:"id"`                       // Unique identifier (e.g., "continue-watching", "watchlist", "trending-movies")
+	ID             string `json:"id"`                       // Unique identifier (e.g., "continue-watching", "watchlist", "favorites", "trending-movies")
 	Name           string `json:"name"`                     // Display name
 	Enabled        bool   `json:"enabled"`                  // Whether the shelf is visible
 	Order          int    `json:"order"`                    // Sort order (lower numbers appear first)
@@ -352,6 +608,47 @@ type MDBListSettings struct {
 	APIKey         string   `json:"apiKey"`
 	Enabled        bool     `json:"enabled"`
 	EnabledRatings []string `json:"enabledRatings"` // Which rating sources to display: trakt, imdb, tmdb, letterboxd, tomatoes, audience, metacritic
+	// CompositeWeights weights each source in the composite 0-100 score
+	CompositeWeights RatingWeights `json:"compositeWeights"`
+}
+
+// RatingWeights is the relative weight of each MDBList rating source in the
+// composite score. A weight of 0 leaves the source out.
+type RatingWeights struct {
+	IMDB       float64 `json:"imdb"`
+	TMDB       float64 `json:"tmdb"`
+	Trakt      float64 `json:"trakt"`
+	Letterboxd float64 `json:"letterboxd"`
+	Tomatoes   float64 `json:"tomatoes"`
+	Audience   float64 `json:"audience"`
+	Metacritic float64 `json:"metacritic"`
+}
+
+// DefaultRatingWeights favours critic and audience aggregates over the
+// single-site user scores, which tend to cluster around 6-8/10.
+func DefaultRatingWeights() RatingWeights {
+	return RatingWeights{IMDB: 1, TMDB: 0.5, Trakt: 0.5, Letterboxd: 0.5, Tomatoes: 1, Audience: 1, Metacritic: 1}
+}
+
+// ForSource returns the weight for an internal rating source name.
+func (w RatingWeights) ForSource(source string) float64 {
+	switch source {
+	case "imdb":
+		return w.IMDB
+	case "tmdb":
+		return w.TMDB
+	case "trakt":
+		return w.Trakt
+	case "letterboxd":
+		return w.Letterboxd
+	case "tomatoes":
+		return w.Tomatoes
+	case "audience", "popcorn":
+		return w.Audience
+	case "metacritic":
+		return w.Metacritic
+	}
+	return 0
 }
 
 // TraktAccount represents a registered Trakt account with its own credentials and OAuth tokens.
@@ -366,6 +663,13 @@ type TraktAccount struct {
 	ExpiresAt         int64  `json:"expiresAt,omitempty"`         // Unix timestamp when access token expires
 	Username          string `json:"username,omitempty"`          // Trakt username (populated after OAuth)
 	ScrobblingEnabled bool   `json:"scrobblingEnabled,omitempty"` // Whether to scrobble for profiles using this account
+
+	// CalendarPrefetchEnabled resolves new episodes from the account's "my shows"
+	// calendar shortly after they air, for every profile using this account
+	CalendarPrefetchEnabled bool `json:"calendarPrefetchEnabled,omitempty"`
+	// CalendarPrefetchWarmup runs a full prequeue (probe, subtitles, HLS) instead
+	// of only resolving the release
+	CalendarPrefetchWarmup bool `json:"calendarPrefetchWarmup,omitempty"`
 }
 
 // TraktSettings defines Trakt integration configuration.
@@ -474,6 +778,8 @@ const (
 	ScheduledTaskTypeTraktListSync     ScheduledTaskType = "trakt_list_sync"
 	ScheduledTaskTypeEPGRefresh        ScheduledTaskType = "epg_refresh"
 	ScheduledTaskTypePlaylistRefresh   ScheduledTaskType = "playlist_refresh"
+	ScheduledTaskTypeCustomListRefresh ScheduledTaskType = "custom_list_refresh"
+	ScheduledTaskTypeArtworkWarm       ScheduledTaskType = "artwork_warm"
 )
 
 // ScheduledTaskFrequency defines how often a task runs
@@ -545,6 +851,43 @@ type NetworkSettings struct {
 	RemoteBackendUrl string `json:"remoteBackendUrl"` // Backend URL when on mobile/other networks (e.g., "https://myserver.com:7777/api")
 }
 
+// DeveloperSettings holds QA tooling that degrades the server on purpose.
+// Leave it disabled on installs people watch on.
+type DeveloperSettings struct {
+	NetworkSimulation NetworkSimulationSettings `json:"networkSimulation"`
+}
+
+// NetworkSimulationSettings injects bad network conditions into the
+// streaming provider path, so client buffering and resume logic can be
+// tested without a bad network. Enabled takes effect on restart; the other
+// values apply to new reads immediately.
+type NetworkSimulationSettings struct {
+	Enabled        bool    `json:"enabled"`
+	LatencyMs      int     `json:"latencyMs"`      // Delay before each stream request is answered
+	JitterMs       int     `json:"jitterMs"`       // Random extra delay, up to this much
+	ThroughputKbps int     `json:"throughputKbps"` // Read rate cap per stream (0 = unlimited)
+	ReadErrorRate  float64 `json:"readErrorRate"`  // Chance (0-1) that reading each MB fails the stream
+}
+
+// CORSSettings controls which browser origins may call the API.
+// An empty AllowedOrigins list (or one containing "*") allows every origin,
+// which matches the historical behaviour; AllowCredentials is ignored in that
+// mode. Origins may use a leading wildcard for subdomains, e.g.
+// "https://*.example.com".
+type CORSSettings struct {
+	AllowedOrigins   []string            `json:"allowedOrigins"`
+	AllowCredentials bool                `json:"allowCredentials"`
+	RouteOverrides   []CORSRouteOverride `json:"routeOverrides,omitempty"`
+}
+
+// CORSRouteOverride replaces the global CORS policy for requests whose path
+// starts with PathPrefix. The longest matching prefix wins.
+type CORSRouteOverride struct {
+	PathPrefix       string   `json:"pathPrefix"` // e.g. "/api/video/"
+	AllowedOrigins   []string `json:"allowedOrigins"`
+	AllowCredentials bool     `json:"allowCredentials"`
+}
+
 // RankingCriterionID identifies a ranking criterion.
 type RankingCriterionID string
 
@@ -565,9 +908,41 @@ type RankingCriterion struct {
 	Order   int                `json:"order"`
 }
 
+// Release selection strategies decide the order in which search results are
+// tried when a release is picked automatically.
+const (
+	SelectionStrategyHeuristic   = "heuristic"    // Ranking criteria in order
+	SelectionStrategySizeFirst   = "size-first"   // Largest release first
+	SelectionStrategyBitrateFit  = "bitrate-fit"  // Closest to the bitrate band first
+	SelectionStrategyCachedFirst = "cached-first" // Instantly available debrid releases first
+)
+
 // RankingSettings holds the ordered list of ranking criteria.
 type RankingSettings struct {
-	Criteria []RankingCriterion `json:"criteria"`
+	Criteria     []RankingCriterion `json:"criteria"`
+	BitrateBands []BitrateBand      `json:"bitrateBands,omitempty"` // Target bitrates used by the size criterion
+	Strategy     string             `json:"strategy,omitempty"`     // Release selection strategy; empty = heuristic
+	// ExperimentStrategies splits profiles without their own strategy evenly
+	// across these strategies, to compare their outcomes. Empty disables it.
+	ExperimentStrategies []string `json:"experimentStrategies,omitempty"`
+}
+
+// BitrateBand is the preferred estimated bitrate range for one resolution.
+// Releases inside the band rank above releases that are starved or bloated.
+type BitrateBand struct {
+	Resolution int `json:"resolution"` // Vertical resolution, e.g. 2160, 1080, 720
+	MinKbps    int `json:"minKbps"`
+	MaxKbps    int `json:"maxKbps"`
+}
+
+// DefaultBitrateBands returns the default target bitrate bands.
+func DefaultBitrateBands() []BitrateBand {
+	return []BitrateBand{
+		{Resolution: 2160, MinKbps: 15000, MaxKbps: 60000},
+		{Resolution: 1080, MinKbps: 5000, MaxKbps: 20000},
+		{Resolution: 720, MinKbps: 2500, MaxKbps: 8000},
+		{Resolution: 480, MinKbps: 1000, MaxKbps: 3500},
+	}
 }
 
 // DefaultRankingCriteria returns the default ranking criteria in their default order.
@@ -578,7 +953,7 @@ func DefaultRankingCriteria() []RankingCriterion {
 		{ID: RankingResolution, Name: "Resolution", Enabled: true, Order: 2},
 		{ID: RankingHDR, Name: "HDR/Dolby Vision", Enabled: true, Order: 3},
 		{ID: RankingLanguage, Name: "Language", Enabled: true, Order: 4},
-		{ID: RankingSize, Name: "File Size", Enabled: true, Order: 5},
+		{ID: RankingSize, Name: "File Size / Bitrate", Enabled: true, Order: 5},
 	}
 }
 
@@ -600,15 +975,20 @@ func DefaultSettings() Settings {
 		Import:    ImportSettings{QueueProcessingIntervalSeconds: 1, RarMaxWorkers: 40, RarMaxCacheSizeMB: 128, RarEnableMemoryPreload: true, RarMaxMemoryGB: 8},
 		SABnzbd:   SABnzbdSettings{Enabled: &sabnzbdEnabled, FallbackHost: "", FallbackAPIKey: ""},
 		AltMount:  nil,
-		Transmux:  TransmuxSettings{Enabled: true, FFmpegPath: "ffmpeg", FFprobePath: "ffprobe", HLSTempDirectory: "/tmp/novastream-hls"},
+		Transmux:  TransmuxSettings{Enabled: true, FFmpegPath: "ffmpeg", FFprobePath: "ffprobe", HLSTempDirectory: "/tmp/novastream-hls", TranscodeCacheMB: 10240},
+		Sandbox:   SandboxSettings{Mode: SandboxModeNone},
+		DiskSpace: DiskSpaceSettings{NZBReserveMB: 1024, HLSReserveMB: 2048},
+		SearchRetry: SearchRetrySettings{Enabled: true, AiredWithinHours: 48, GiveUpAfterHours: 24},
+		Kids:        KidsSettings{MaxMovieCertification: "PG", MaxTVRating: "TV-PG"},
 		Playback:  PlaybackSettings{PreferredPlayer: "native", UseLoadingScreen: false, SubtitleSize: 1.0, SeekForwardSeconds: 30, SeekBackwardSeconds: 10},
 		Live:      LiveSettings{Mode: "m3u", PlaylistURL: "", PlaylistCacheTTLHours: 24},
 		HomeShelves: HomeShelvesSettings{
 			Shelves: []ShelfConfig{
 				{ID: "continue-watching", Name: "Continue Watching", Enabled: true, Order: 0},
 				{ID: "watchlist", Name: "Your Watchlist", Enabled: true, Order: 1},
-				{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 2},
-				{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 3},
+				{ID: "favorites", Name: "Your Favorites", Enabled: true, Order: 2},
+				{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 3},
+				{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 4},
 			},
 			TrendingMovieSource: TrendingMovieSourceReleased, // Default to released-only (MDBList)
 		},
@@ -630,9 +1010,10 @@ func DefaultSettings() Settings {
 			OpenSubtitlesPassword: "",
 		},
 		MDBList: MDBListSettings{
-			APIKey:         "",
-			Enabled:        false,
-			EnabledRatings: []string{"imdb", "tomatoes", "audience"}, // Default to IMDB and Rotten Tomatoes
+			APIKey:           "",
+			Enabled:          false,
+			EnabledRatings:   []string{"imdb", "tomatoes", "audience"}, // Default to IMDB and Rotten Tomatoes
+			CompositeWeights: DefaultRatingWeights(),
 		},
 		Trakt: TraktSettings{},
 		Plex:  PlexSettings{},
@@ -644,6 +1025,15 @@ func DefaultSettings() Settings {
 			MaxAge:     7,    // 7 days
 			Compress:   true, // compress old files
 		},
+		AccessLog: AccessLogSettings{
+			File:         "cache/logs/access.log",
+			MaxSize:      50,
+			MaxBackups:   3,
+			MaxAge:       7,
+			Compress:     true,
+			RedactIPs:    true,
+			RedactTokens: true,
+		},
 		ScheduledTasks: ScheduledTasksSettings{
 			Tasks:                []ScheduledTask{},
 			CheckIntervalSeconds: 60, // Check every 60 seconds
@@ -653,8 +1043,14 @@ func DefaultSettings() Settings {
 			HomeBackendUrl:   "",
 			RemoteBackendUrl: "",
 		},
+		CORS: CORSSettings{
+			AllowedOrigins:   []string{"*"},
+			AllowCredentials: false,
+		},
 		Ranking: RankingSettings{
-			Criteria: DefaultRankingCriteria(),
+			Strategy:     SelectionStrategyHeuristic,
+			Criteria:     DefaultRankingCriteria(),
+			BitrateBands: DefaultBitrateBands(),
 		},
 	}
 }
@@ -711,8 +1107,49 @@ func (m *Manager) EnsureDir() error {
 	return os.MkdirAll(dir, 0o755)
 }
 
+// Notice kinds reported by LoadWithNotices.
+const (
+	NoticeMigrated   = "migrated"   // a legacy value was converted to its current form
+	NoticeDeprecated = "deprecated" // a legacy value is no longer used and was dropped
+)
+
+// LoadNotice describes a legacy setting that Load rewrote or ignored.
+type LoadNotice struct {
+	Kind    string `json:"kind"`
+	Setting string `json:"setting"`
+	Message string `json:"message"`
+}
+
 // Load reads settings.json from disk or creates defaults if missing.
 func (m *Manager) Load() (Settings, error) {
+	return m.load(func(LoadNotice) {})
+}
+
+// LoadWithNotices is Load that also reports the legacy settings it migrated
+// or dropped. Migrations are only visible until the settings are next saved.
+func (m *Manager) LoadWithNotices() (Settings, []LoadNotice, error) {
+	var notices []LoadNotice
+	s, err := m.load(func(n LoadNotice) { notices = append(notices, n) })
+	return s, notices, err
+}
+
+// load reads the settings file, rolling back to the newest readable shadow
+// copy when the file exists but can't be parsed.
+func (m *Manager) load(notice func(LoadNotice)) (Settings, error) {
+	s, err := m.loadFile(notice)
+	var parseErr *configParseError
+	if !errors.As(err, &parseErr) {
+		return s, err
+	}
+	backup, ok := m.rollback()
+	if !ok {
+		return Settings{}, err
+	}
+	notice(LoadNotice{Kind: NoticeRestored, Setting: "settings", Message: fmt.Sprintf("settings file was unreadable (%v); restored the backup from %s", parseErr.err, backup.CreatedAt.Format(time.RFC3339))})
+	return m.loadFile(notice)
+}
+
+func (m *Manager) loadFile(notice func(LoadNotice)) (Settings, error) {
 	if m.path == "" {
 		return Settings{}, errors.New("config path not set")
 	}
@@ -734,13 +1171,14 @@ func (m *Manager) Load() (Settings, error) {
 	var raw map[string]interface{}
 	dec := json.NewDecoder(f)
 	if err := dec.Decode(&raw); err != nil {
-		return Settings{}, err
+		return Settings{}, &configParseError{err: err}
 	}
 
 	// Check if usenet is an object (old format) instead of array
 	if usenetRaw, ok := raw["usenet"].(map[string]interface{}); ok {
 		// Migrate old single usenet config to array format
 		if host, _ := usenetRaw["host"].(string); strings.TrimSpace(host) != "" {
+			notice(LoadNotice{Kind: NoticeMigrated, Setting: "usenet", Message: "single usenet server converted to a provider list"})
 			// Convert to array format
 			raw["usenet"] = []interface{}{usenetRaw}
 			// Add Name field if not present
@@ -778,6 +1216,17 @@ func (m *Manager) Load() (Settings, error) {
 		raw["ui"] = map[string]interface{}{"loadingAnimationEnabled": true}
 	}
 
+	// Redact the access log unless the config says otherwise
+	if accessLogRaw, ok := raw["accessLog"].(map[string]interface{}); ok {
+		for _, key := range []string{"redactIps", "redactTokens"} {
+			if _, has := accessLogRaw[key]; !has {
+				accessLogRaw[key] = true
+			}
+		}
+	} else {
+		raw["accessLog"] = map[string]interface{}{"redactIps": true, "redactTokens": true}
+	}
+
 	// Migrate servicePriority from filtering to streaming
 	if filteringRaw, ok := raw["filtering"].(map[string]interface{}); ok {
 		if servicePriority, hasPriority := filteringRaw["servicePriority"]; hasPriority {
@@ -789,6 +1238,7 @@ func (m *Manager) Load() (Settings, error) {
 			}
 			// Remove from filtering
 			delete(filteringRaw, "servicePriority")
+			notice(LoadNotice{Kind: NoticeMigrated, Setting: "filtering.servicePriority", Message: "moved to streaming.servicePriority"})
 		}
 	}
 
@@ -809,6 +1259,7 @@ func (m *Manager) Load() (Settings, error) {
 					filteringRaw["hdrDvPolicy"] = "none"
 				}
 				delete(filteringRaw, "excludeHdr")
+				notice(LoadNotice{Kind: NoticeMigrated, Setting: "filtering.excludeHdr", Message: "replaced by filtering.hdrDvPolicy \"none\"; review the HDR/DV policy"})
 			}
 		}
 	}
@@ -821,12 +1272,12 @@ func (m *Manager) Load() (Settings, error) {
 
 	var s Settings
 	if err := json.Unmarshal(rawJSON, &s); err != nil {
-		return Settings{}, err
+		return Settings{}, &configParseError{err: err}
 	}
 
 	// Backfill defaults for newly introduced settings when config predates them
 	if !s.Transmux.Enabled && strings.TrimSpace(s.Transmux.FFmpegPath) == "" && strings.TrimSpace(s.Transmux.FFprobePath) == "" {
-		s.Transmux = TransmuxSettings{Enabled: true, FFmpegPath: "ffmpeg", FFprobePath: "ffprobe", HLSTempDirectory: "/tmp/novastream-hls"}
+		s.Transmux = TransmuxSettings{Enabled: true, FFmpegPath: "ffmpeg", FFprobePath: "ffprobe", HLSTempDirectory: "/tmp/novastream-hls", TranscodeCacheMB: 10240}
 	} else {
 		if strings.TrimSpace(s.Transmux.FFmpegPath) == "" {
 			s.Transmux.FFmpegPath = "ffmpeg"
@@ -903,6 +1354,22 @@ func (m *Manager) Load() (Settings, error) {
 		s.Streaming.IndexerTimeoutSec = 5
 	}
 
+	// Backfill SearchRetry settings when config predates them
+	if s.SearchRetry.AiredWithinHours == 0 && s.SearchRetry.GiveUpAfterHours == 0 {
+		s.SearchRetry = SearchRetrySettings{Enabled: true, AiredWithinHours: 48, GiveUpAfterHours: 24}
+	}
+
+	// Backfill composite rating weights when config predates them
+	if s.MDBList.CompositeWeights == (RatingWeights{}) {
+		s.MDBList.CompositeWeights = DefaultRatingWeights()
+	}
+
+	// Backfill kids search limits when config predates them
+	if s.Kids.MaxMovieCertification == "" && s.Kids.MaxTVRating == "" {
+		s.Kids.MaxMovieCertification = "PG"
+		s.Kids.MaxTVRating = "TV-PG"
+	}
+
 	// Backfill Import settings
 	if s.Import.QueueProcessingIntervalSeconds == 0 {
 		s.Import.QueueProcessingIntervalSeconds = 1
@@ -943,6 +1410,7 @@ func (m *Manager) Load() (Settings, error) {
 	for i := range s.Indexers {
 		if strings.ToLower(s.Indexers[i].Type) == "torznab" {
 			s.Indexers[i].Type = "newznab"
+			notice(LoadNotice{Kind: NoticeMigrated, Setting: fmt.Sprintf("indexers[%d].type", i), Message: fmt.Sprintf("indexer %q changed from torznab to newznab", s.Indexers[i].Name)})
 		}
 	}
 
@@ -958,11 +1426,27 @@ func (m *Manager) Load() (Settings, error) {
 		s.HomeShelves.Shelves = []ShelfConfig{
 			{ID: "continue-watching", Name: "Continue Watching", Enabled: true, Order: 0},
 			{ID: "watchlist", Name: "Your Watchlist", Enabled: true, Order: 1},
-			{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 2},
-			{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 3},
+			{ID: "favorites", Name: "Your Favorites", Enabled: true, Order: 2},
+			{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 3},
+			{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 4},
 		}
 	}
 
+	// Backfill the favorites shelf for configs saved before favorites existed
+	hasFavoritesShelf := false
+	maxShelfOrder := -1
+	for _, shelf := range s.HomeShelves.Shelves {
+		if shelf.ID == "favorites" {
+			hasFavoritesShelf = true
+		}
+		if shelf.Order > maxShelfOrder {
+			maxShelfOrder = shelf.Order
+		}
+	}
+	if !hasFavoritesShelf {
+		s.HomeShelves.Shelves = append(s.HomeShelves.Shelves, ShelfConfig{ID: "favorites", Name: "Your Favorites", Enabled: true, Order: maxShelfOrder + 1})
+	}
+
 	// Backfill TrendingMovieSource if empty (default to released-only)
 	if s.HomeShelves.TrendingMovieSource == "" {
 		s.HomeShelves.TrendingMovieSource = TrendingMovieSourceReleased
@@ -997,6 +1481,20 @@ func (m *Manager) Load() (Settings, error) {
 		s.Log.MaxAge = 7
 	}
 
+	// Backfill AccessLog settings
+	if strings.TrimSpace(s.AccessLog.File) == "" {
+		s.AccessLog.File = "cache/logs/access.log"
+	}
+	if s.AccessLog.MaxSize == 0 {
+		s.AccessLog.MaxSize = 50
+	}
+	if s.AccessLog.MaxBackups == 0 {
+		s.AccessLog.MaxBackups = 3
+	}
+	if s.AccessLog.MaxAge == 0 {
+		s.AccessLog.MaxAge = 7
+	}
+
 	// Backfill ScheduledTasks settings
 	if s.ScheduledTasks.CheckIntervalSeconds == 0 {
 		s.ScheduledTasks.CheckIntervalSeconds = 60
@@ -1014,6 +1512,9 @@ func (m *Manager) Load() (Settings, error) {
 	}
 
 	// Legacy AltMount configuration is ignored going forward.
+	if s.AltMount != nil {
+		notice(LoadNotice{Kind: NoticeDeprecated, Setting: "altmount", Message: "AltMount configuration is no longer used and was ignored"})
+	}
 	s.AltMount = nil
 
 	// Migrate legacy Trakt settings to new Accounts array
@@ -1034,6 +1535,7 @@ func (m *Manager) Load() (Settings, error) {
 			migratedAccount.Name = "Default Trakt Account"
 		}
 		s.Trakt.Accounts = []TraktAccount{migratedAccount}
+		notice(LoadNotice{Kind: NoticeMigrated, Setting: "trakt", Message: "single Trakt login converted to account \"" + migratedAccount.Name + "\""})
 		// Clear legacy fields after migration
 		s.Trakt.ClientID = ""
 		s.Trakt.ClientSecret = ""
@@ -1047,7 +1549,8 @@ func (m *Manager) Load() (Settings, error) {
 	return s, nil
 }
 
-// Save writes the provided settings to disk atomically.
+// Save writes the provided settings to disk atomically and keeps a
+// timestamped shadow copy for rollback.
 func (m *Manager) Save(s Settings) error {
 	if m.path == "" {
 		return errors.New("config path not set")
@@ -1055,26 +1558,15 @@ func (m *Manager) Save(s Settings) error {
 	if err := m.EnsureDir(); err != nil {
 		return err
 	}
-	tmp := m.path + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		return err
-	}
-	enc := json.NewEncoder(f)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(s); err != nil {
-		f.Close()
-		_ = os.Remove(tmp)
 		return err
 	}
-	if err := f.Sync(); err != nil {
-		f.Close()
-		_ = os.Remove(tmp)
+	if err := writeFileAtomic(m.path, buf.Bytes()); err != nil {
 		return err
 	}
-	if err := f.Close(); err != nil {
-		_ = os.Remove(tmp)
-		return err
-	}
-	return os.Rename(tmp, m.path)
+	m.shadowCopy(buf.Bytes())
+	return nil
 }
@@ -67,6 +67,7 @@ type StreamInfo struct {
 	DVProfile     string    `json:"dv_profile,omitempty"`
 	Segments      int       `json:"segments,omitempty"`
 	UserAgent     string    `json:"user_agent,omitempty"`
+	FFmpegLogID   string    `json:"ffmpeg_log_id,omitempty"` // Captured ffmpeg stderr at /api/admin/ffmpeg-logs/{id}
 	// Progress tracking
 	StartOffset     float64 `json:"start_offset,omitempty"`     // Where playback started (seconds)
 	CurrentPosition float64 `json:"current_position,omitempty"` // Estimated current playback position (seconds)
@@ -153,6 +154,7 @@ func (h *AdminHandler) GetActiveStreams(w http.ResponseWriter, r *http.Request)
 				DVProfile:    session.DVProfile,
 				Segments:     session.SegmentsCreated,
 				StartOffset:  session.StartOffset,
+				FFmpegLogID:  session.ID,
 			}
 
 			session.mu.RUnlock()
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/devices"
+)
+
+// maxBulkItems bounds how many profiles or devices one bulk request may touch
+const maxBulkItems = 100
+
+type bulkDevicesService interface {
+	Get(id string) (*models.Device, error)
+	List(profileID string) ([]models.Device, error)
+	Update(id string, update models.DeviceUpdate) (*models.Device, error)
+}
+
+var _ bulkDevicesService = (*devices.Service)(nil)
+
+// SetDevicesService sets the device registry used by bulk device templates
+func (h *AdminUIHandler) SetDevicesService(ds bulkDevicesService) {
+	h.devicesService = ds
+}
+
+// BulkResult is the outcome of one item of a bulk operation
+type BulkResult struct {
+	ID      string                `json:"id,omitempty"`
+	Name    string                `json:"name,omitempty"`
+	Success bool                  `json:"success"`
+	Error   string                `json:"error,omitempty"`
+	Profile *ProfileWithPinStatus `json:"profile,omitempty"`
+}
+
+// BulkResponse summarises a bulk operation. Items are applied independently,
+// so some may fail while the rest succeed.
+type BulkResponse struct {
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
+
+func (b *BulkResponse) add(result BulkResult) {
+	if result.Success {
+		b.Succeeded++
+	} else {
+		b.Failed++
+	}
+	b.Results = append(b.Results, result)
+}
+
+// BulkCreateProfilesRequest creates several profiles at once. AccountId is
+// used for entries that don't name their own account.
+type BulkCreateProfilesRequest struct {
+	AccountId string                 `json:"accountId,omitempty"`
+	Profiles  []CreateProfileRequest `json:"profiles"`
+}
+
+// BulkCreateProfiles creates several profiles in one request
+// POST /admin/api/bulk/profiles
+func (h *AdminUIHandler) BulkCreateProfiles(w http.ResponseWriter, r *http.Request) {
+	if h.usersService == nil {
+		http.Error(w, "Users service not available", http.StatusInternalServerError)
+		return
+	}
+
+	var req BulkCreateProfilesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Profiles) == 0 {
+		http.Error(w, "profiles is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Profiles) > maxBulkItems {
+		http.Error(w, fmt.Sprintf("at most %d profiles per request", maxBulkItems), http.StatusBadRequest)
+		return
+	}
+
+	resp := BulkResponse{Results: make([]BulkResult, 0, len(req.Profiles))}
+	for _, p := range req.Profiles {
+		accountID := strings.TrimSpace(p.AccountId)
+		if accountID == "" {
+			accountID = strings.TrimSpace(req.AccountId)
+		}
+		result := BulkResult{Name: p.Name}
+		if accountID != "" && h.accountsService != nil && !h.accountsService.Exists(accountID) {
+			result.Error = "account not found"
+			resp.add(result)
+			continue
+		}
+
+		user, err := h.usersService.CreateForAccount(accountID, p.Name)
+		if err != nil {
+			result.Error = err.Error()
+			resp.add(result)
+			continue
+		}
+		if p.Color != "" {
+			if updated, err := h.usersService.SetColor(user.ID, p.Color); err != nil {
+				log.Printf("[admin] failed to set color for new profile %s: %v", user.ID, err)
+			} else {
+				user = updated
+			}
+		}
+		if p.IsKidsProfile {
+			if updated, err := h.usersService.SetKidsProfile(user.ID, true); err != nil {
+				log.Printf("[admin] failed to set kids profile for new profile %s: %v", user.ID, err)
+			} else {
+				user = updated
+			}
+		}
+
+		result.ID = user.ID
+		result.Success = true
+		result.Profile = &ProfileWithPinStatus{
+			ID:            user.ID,
+			AccountID:     user.AccountID,
+			Name:          user.Name,
+			Color:         user.Color,
+			IconURL:       user.IconURL,
+			HasPin:        user.HasPin(),
+			HasIcon:       user.HasIcon(),
+			IsKidsProfile: user.IsKidsProfile,
+			CreatedAt:     user.CreatedAt,
+			UpdatedAt:     user.UpdatedAt,
+		}
+		resp.add(result)
+	}
+
+	log.Printf("[admin] bulk created %d profiles (%d failed)", resp.Succeeded, resp.Failed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CopyProfileSettingsRequest copies one profile's settings to others. With
+// AllProfiles every other profile is a target.
+type CopyProfileSettingsRequest struct {
+	SourceProfileID  string   `json:"sourceProfileId"`
+	TargetProfileIDs []string `json:"targetProfileIds,omitempty"`
+	AllProfiles      bool     `json:"allProfiles,omitempty"`
+}
+
+// CopyProfileSettings copies a profile's settings overrides to other profiles.
+// A source without overrides resets the targets to the global defaults.
+// POST /admin/api/bulk/profiles/copy-settings
+func (h *AdminUIHandler) CopyProfileSettings(w http.ResponseWriter, r *http.Request) {
+	if h.usersService == nil || h.userSettingsService == nil {
+		http.Error(w, "User settings service not available", http.StatusInternalServerError)
+		return
+	}
+
+	var req CopyProfileSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	sourceID := strings.TrimSpace(req.SourceProfileID)
+	if sourceID == "" {
+		http.Error(w, "sourceProfileId is required", http.StatusBadRequest)
+		return
+	}
+	if !h.usersService.Exists(sourceID) {
+		http.Error(w, "source profile not found", http.StatusNotFound)
+		return
+	}
+
+	targets := req.TargetProfileIDs
+	if req.AllProfiles {
+		targets = nil
+		for _, user := range h.usersService.ListAll() {
+			targets = append(targets, user.ID)
+		}
+	}
+	if len(targets) == 0 {
+		http.Error(w, "targetProfileIds or allProfiles is required", http.StatusBadRequest)
+		return
+	}
+	if !req.AllProfiles && len(targets) > maxBulkItems {
+		http.Error(w, fmt.Sprintf("at most %d profiles per request", maxBulkItems), http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.userSettingsService.Get(sourceID)
+	if err != nil {
+		http.Error(w, "Failed to get profile settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := BulkResponse{Results: make([]BulkResult, 0, len(targets))}
+	seen := make(map[string]bool, len(targets))
+	for _, id := range targets {
+		id = strings.TrimSpace(id)
+		if id == "" || id == sourceID || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := BulkResult{ID: id}
+		user, ok := h.usersService.Get(id)
+		switch {
+		case !ok:
+			result.Error = "profile not found"
+		case source == nil:
+			err = h.userSettingsService.Delete(id)
+		default:
+			err = h.userSettingsService.Update(id, *source)
+		}
+		if ok {
+			result.Name = user.Name
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+		}
+		resp.add(result)
+	}
+
+	log.Printf("[admin] copied settings of profile %s to %d profiles (%d failed)", sourceID, resp.Succeeded, resp.Failed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DeviceTemplateRequest applies the same settings to many devices. Targets
+// are DeviceIDs when given, otherwise every known client and registered
+// device, limited to one profile's when ProfileID is set. Nil settings are
+// left unchanged on each device.
+type DeviceTemplateRequest struct {
+	DeviceIDs []string `json:"deviceIds,omitempty"`
+	ProfileID string   `json:"profileId,omitempty"`
+
+	// Filtering replaces the client's filter overrides and enables them
+	Filtering       *models.ClientFilterSettings  `json:"filtering,omitempty"`
+	TranscodePolicy *models.DeviceTranscodePolicy `json:"transcodePolicy,omitempty"`
+	MaxBitrateKbps  *int                          `json:"maxBitrateKbps,omitempty"`
+	MaxResolution   *string                       `json:"maxResolution,omitempty"`
+	SDROnly         *bool                         `json:"sdrOnly,omitempty"`
+}
+
+// ApplyDeviceTemplate applies filter settings and playback overrides to many
+// devices at once
+// POST /admin/api/bulk/devices/apply-template
+func (h *AdminUIHandler) ApplyDeviceTemplate(w http.ResponseWriter, r *http.Request) {
+	var req DeviceTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	applyFiltering := req.Filtering != nil
+	applyOverrides := req.TranscodePolicy != nil || req.MaxBitrateKbps != nil || req.MaxResolution != nil || req.SDROnly != nil
+	if !applyFiltering && !applyOverrides {
+		http.Error(w, "filtering, transcodePolicy, maxBitrateKbps, maxResolution or sdrOnly is required", http.StatusBadRequest)
+		return
+	}
+	if applyFiltering && (h.clientsService == nil || h.clientSettingsService == nil) {
+		http.Error(w, "Client settings service not available", http.StatusInternalServerError)
+		return
+	}
+	if applyOverrides && h.devicesService == nil {
+		http.Error(w, "Device registry not available", http.StatusInternalServerError)
+		return
+	}
+	if len(req.DeviceIDs) > maxBulkItems {
+		http.Error(w, fmt.Sprintf("at most %d devices per request", maxBulkItems), http.StatusBadRequest)
+		return
+	}
+
+	targets, err := h.deviceTemplateTargets(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := BulkResponse{Results: make([]BulkResult, 0, len(targets))}
+	for _, id := range targets {
+		result := BulkResult{ID: id}
+		applied := false
+		var errs []string
+
+		if applyFiltering {
+			if client, err := h.clientsService.Get(id); err == nil && client != nil {
+				result.Name = client.Name
+				if err := h.clientSettingsService.Update(id, *req.Filtering); err != nil {
+					errs = append(errs, err.Error())
+				} else if _, err := h.clientsService.SetFilterEnabled(id, true); err != nil {
+					errs = append(errs, err.Error())
+				} else {
+					applied = true
+				}
+			}
+		}
+		if applyOverrides {
+			if device, err := h.devicesService.Get(id); err == nil && device != nil {
+				if result.Name == "" {
+					result.Name = device.Name
+				}
+				update := models.DeviceUpdate{
+					TranscodePolicy: req.TranscodePolicy,
+					MaxBitrateKbps:  req.MaxBitrateKbps,
+					MaxResolution:   req.MaxResolution,
+					SDROnly:         req.SDROnly,
+				}
+				if _, err := h.devicesService.Update(id, update); err != nil {
+					errs = append(errs, err.Error())
+				} else {
+					applied = true
+				}
+			}
+		}
+
+		switch {
+		case len(errs) > 0:
+			result.Error = strings.Join(errs, "; ")
+		case !applied:
+			result.Error = "device not found"
+		default:
+			result.Success = true
+		}
+		resp.add(result)
+	}
+
+	log.Printf("[admin] applied device template to %d devices (%d failed)", resp.Succeeded, resp.Failed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// deviceTemplateTargets returns the device IDs a template applies to, in
+// request order or clients first, without duplicates.
+func (h *AdminUIHandler) deviceTemplateTargets(req DeviceTemplateRequest) ([]string, error) {
+	var ids []string
+	if len(req.DeviceIDs) > 0 {
+		ids = req.DeviceIDs
+	} else {
+		profileID := strings.TrimSpace(req.ProfileID)
+		if h.clientsService != nil {
+			clients := h.clientsService.List()
+			if profileID != "" {
+				clients = h.clientsService.ListByUser(profileID)
+			}
+			for _, client := range clients {
+				ids = append(ids, client.ID)
+			}
+		}
+		if h.devicesService != nil {
+			list, err := h.devicesService.List(profileID)
+			if err != nil {
+				return nil, fmt.Errorf("list devices: %w", err)
+			}
+			for _, device := range list {
+				ids = append(ids, device.ID)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(ids))
+	targets := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		targets = append(targets, id)
+	}
+	return targets, nil
+}
@@ -3,10 +3,8 @@ package handlers
 import (
 	"bufio"
 	"context"
-	"crypto/rand"
 	"crypto/tls"
 	"embed"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -19,7 +17,6 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"novastream/config"
@@ -35,6 +32,7 @@ import (
 	"novastream/services/watchlist"
 	user_settings "novastream/services/user_settings"
 	"novastream/services/users"
+	"novastream/utils"
 )
 
 //go:embed admin_templates/*
@@ -44,8 +42,6 @@ const (
 	adminSessionCookieName         = "strmr_admin_session"
 	adminSessionDuration           = 24 * time.Hour
 	adminSessionDurationRememberMe = 30 * 24 * time.Hour // 30 days
-	adminSessionsDir               = "cache/sessions"
-	adminSessionsFile              = "cache/sessions/admin.json"
 )
 
 // sessionContextKey is used to store session in request context
@@ -59,125 +55,6 @@ func adminSessionFromContext(ctx context.Context) *models.Session {
 	return nil
 }
 
-// adminSessionStore manages admin session tokens with file persistence
-type adminSessionStore struct {
-	mu       sync.RWMutex
-	sessions map[string]time.Time // token -> expiry
-}
-
-var adminSessions = &adminSessionStore{
-	sessions: make(map[string]time.Time),
-}
-
-func init() {
-	adminSessions.load()
-}
-
-// sessionData is the JSON structure for persisted sessions
-type sessionData struct {
-	Token  string    `json:"token"`
-	Expiry time.Time `json:"expiry"`
-}
-
-func (s *adminSessionStore) load() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	data, err := os.ReadFile(adminSessionsFile)
-	if err != nil {
-		// File doesn't exist yet, that's fine
-		return
-	}
-
-	var sessions []sessionData
-	if err := json.Unmarshal(data, &sessions); err != nil {
-		log.Printf("[admin-session] failed to parse sessions file: %v", err)
-		return
-	}
-
-	now := time.Now()
-	loaded := 0
-	for _, sess := range sessions {
-		if sess.Expiry.After(now) {
-			s.sessions[sess.Token] = sess.Expiry
-			loaded++
-		}
-	}
-	if loaded > 0 {
-		log.Printf("[admin-session] loaded %d valid sessions from disk", loaded)
-	}
-}
-
-func (s *adminSessionStore) save() {
-	// Ensure directory exists
-	if err := os.MkdirAll(adminSessionsDir, 0700); err != nil {
-		log.Printf("[admin-session] failed to create sessions directory: %v", err)
-		return
-	}
-
-	s.mu.RLock()
-	var sessions []sessionData
-	now := time.Now()
-	for token, expiry := range s.sessions {
-		if expiry.After(now) {
-			sessions = append(sessions, sessionData{Token: token, Expiry: expiry})
-		}
-	}
-	s.mu.RUnlock()
-
-	data, err := json.Marshal(sessions)
-	if err != nil {
-		log.Printf("[admin-session] failed to marshal sessions: %v", err)
-		return
-	}
-
-	if err := os.WriteFile(adminSessionsFile, data, 0600); err != nil {
-		log.Printf("[admin-session] failed to write sessions file: %v", err)
-	}
-}
-
-func (s *adminSessionStore) create(duration time.Duration) string {
-	s.mu.Lock()
-
-	// Generate random token
-	b := make([]byte, 32)
-	rand.Read(b)
-	token := hex.EncodeToString(b)
-
-	s.sessions[token] = time.Now().Add(duration)
-
-	// Cleanup expired sessions
-	now := time.Now()
-	for t, exp := range s.sessions {
-		if exp.Before(now) {
-			delete(s.sessions, t)
-		}
-	}
-
-	s.mu.Unlock()
-	s.save()
-
-	return token
-}
-
-func (s *adminSessionStore) validate(token string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	exp, ok := s.sessions[token]
-	if !ok {
-		return false
-	}
-	return exp.After(time.Now())
-}
-
-func (s *adminSessionStore) revoke(token string) {
-	s.mu.Lock()
-	delete(s.sessions, token)
-	s.mu.Unlock()
-	s.save()
-}
-
 // SettingsGroups defines the order and labels for settings groups
 var SettingsGroups = []map[string]string{
 	{"id": "server", "label": "Server"},
@@ -195,8 +72,10 @@ var SettingsSchema = map[string]interface{}{
 		"group": "server",
 		"order": 0,
 		"fields": map[string]interface{}{
-			"host": map[string]interface{}{"type": "text", "label": "Host", "description": "Server bind address"},
-			"port": map[string]interface{}{"type": "number", "label": "Port", "description": "Server port"},
+			"host":           map[string]interface{}{"type": "text", "label": "Host", "description": "Server bind address"},
+			"port":           map[string]interface{}{"type": "number", "label": "Port", "description": "Server port"},
+			"baseUrl":        map[string]interface{}{"type": "text", "label": "Base URL", "description": "External URL of this server (e.g. https://media.example.com), used for invitation links, HLS playlists and direct links. Leave empty to derive it from each request."},
+			"trustedProxies": map[string]interface{}{"type": "tags", "label": "Trusted Proxies", "description": "Addresses or networks (e.g. 172.18.0.0/16) of reverse proxies whose X-Forwarded-For header is believed. Leave empty to trust loopback and private networks."},
 		},
 	},
 	"network": map[string]interface{}{
@@ -228,6 +107,35 @@ var SettingsSchema = map[string]interface{}{
 			},
 		},
 	},
+	"accessLog": map[string]interface{}{
+		"label": "Access Log",
+		"icon":  "list",
+		"group": "server",
+		"order": 2,
+		"fields": map[string]interface{}{
+			"enabled":      map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Write one JSON line per HTTP request (method, route, status, duration, bytes, client)", "order": 0},
+			"file":         map[string]interface{}{"type": "text", "label": "Log File", "description": "Access log path, rotated separately from the backend log", "order": 1},
+			"maxSize":      map[string]interface{}{"type": "number", "label": "Max Size (MB)", "description": "Rotate the file once it reaches this size", "order": 2},
+			"maxBackups":   map[string]interface{}{"type": "number", "label": "Max Backups", "description": "Rotated files to keep", "order": 3},
+			"maxAge":       map[string]interface{}{"type": "number", "label": "Max Age (days)", "description": "Delete rotated files older than this", "order": 4},
+			"compress":     map[string]interface{}{"type": "boolean", "label": "Compress", "description": "Gzip rotated files", "order": 5},
+			"redactIps":    map[string]interface{}{"type": "boolean", "label": "Redact IPs", "description": "Log client addresses truncated to their /24 (IPv4) or /48 (IPv6) network", "order": 6},
+			"redactTokens": map[string]interface{}{"type": "boolean", "label": "Redact Tokens", "description": "Blank tokens, API keys and PINs in logged query strings", "order": 7},
+		},
+	},
+	"developer": map[string]interface{}{
+		"label": "Developer",
+		"icon":  "wrench",
+		"group": "server",
+		"order": 3,
+		"fields": map[string]interface{}{
+			"networkSimulation.enabled":        map[string]interface{}{"type": "boolean", "label": "Simulate Bad Network", "description": "QA only: inject latency, throughput caps and read errors into every stream; applied on restart", "order": 0},
+			"networkSimulation.latencyMs":      map[string]interface{}{"type": "number", "label": "Latency (ms)", "description": "Delay before each stream request is answered", "order": 1},
+			"networkSimulation.jitterMs":       map[string]interface{}{"type": "number", "label": "Jitter (ms)", "description": "Random extra delay added to the latency, up to this much", "order": 2},
+			"networkSimulation.throughputKbps": map[string]interface{}{"type": "number", "label": "Throughput (kbps)", "description": "Read rate cap per stream (0 = unlimited)", "order": 3},
+			"networkSimulation.readErrorRate":  map[string]interface{}{"type": "number", "label": "Read Error Rate", "description": "Chance (0-1) that reading each MB fails the stream", "order": 4},
+		},
+	},
 	"streaming": map[string]interface{}{
 		"label": "Streaming",
 		"icon":  "play-circle",
@@ -265,6 +173,27 @@ var SettingsSchema = map[string]interface{}{
 				"label":       "Search Resolution Timeout (seconds)",
 				"description": "Maximum time to wait for indexer/scraper searches (default: 5). Increase if using Aiostreams, which may need more time to respond.",
 			},
+			"debridFailover": map[string]interface{}{
+				"type":        "boolean",
+				"label":       "Debrid Failover",
+				"description": "When a usenet stream hits missing articles mid-playback, switch to a cached debrid release of the same title at the same position (hybrid mode)",
+			},
+			"binding.enabled": map[string]interface{}{
+				"type":        "boolean",
+				"label":       "Bind Stream URLs",
+				"description": "Refuse stream URLs replayed from a network or device the session's app hasn't made API calls from in the last day",
+			},
+			"binding.match": map[string]interface{}{
+				"type":        "select",
+				"label":       "Stream Binding Match",
+				"description": "What a stream request must share with the app: its network, its client ID, or both",
+				"options":     []string{"network", "client", "both"},
+			},
+			"binding.cgnat": map[string]interface{}{
+				"type":        "boolean",
+				"label":       "Stream Binding: CGNAT",
+				"description": "Match networks on /16 (IPv4) and /48 (IPv6) for households whose public address changes between connections",
+			},
 		},
 	},
 	"debridProviders": map[string]interface{}{
@@ -287,6 +216,7 @@ var SettingsSchema = map[string]interface{}{
 				"order":       5,
 				"showWhen":    "provider=torbox",
 			},
+			"monthlyQuotaGb": map[string]interface{}{"type": "number", "label": "Monthly Quota (GB)", "description": "Traffic allowed per calendar month; streaming is refused once reached (0 = unlimited)", "order": 6},
 		},
 	},
 	"usenet": map[string]interface{}{
@@ -296,14 +226,38 @@ var SettingsSchema = map[string]interface{}{
 		"order":    2,
 		"is_array": true,
 		"fields": map[string]interface{}{
-			"name":        map[string]interface{}{"type": "text", "label": "Name", "description": "Provider name"},
-			"host":        map[string]interface{}{"type": "text", "label": "Host", "description": "NNTP server hostname"},
-			"port":        map[string]interface{}{"type": "number", "label": "Port", "description": "NNTP port (usually 119 or 563)"},
-			"ssl":         map[string]interface{}{"type": "boolean", "label": "SSL", "description": "Use SSL/TLS connection"},
-			"username":    map[string]interface{}{"type": "text", "label": "Username", "description": "NNTP username"},
-			"password":    map[string]interface{}{"type": "password", "label": "Password", "description": "NNTP password"},
-			"connections": map[string]interface{}{"type": "number", "label": "Connections", "description": "Max connections"},
-			"enabled":     map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this provider"},
+			"name":           map[string]interface{}{"type": "text", "label": "Name", "description": "Provider name"},
+			"host":           map[string]interface{}{"type": "text", "label": "Host", "description": "NNTP server hostname"},
+			"port":           map[string]interface{}{"type": "number", "label": "Port", "description": "NNTP port (usually 119 or 563)"},
+			"ssl":            map[string]interface{}{"type": "boolean", "label": "SSL", "description": "Use SSL/TLS connection"},
+			"username":       map[string]interface{}{"type": "text", "label": "Username", "description": "NNTP username"},
+			"password":       map[string]interface{}{"type": "password", "label": "Password", "description": "NNTP password"},
+			"connections":    map[string]interface{}{"type": "number", "label": "Connections", "description": "Max connections"},
+			"enabled":        map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this provider"},
+			"monthlyQuotaGb": map[string]interface{}{"type": "number", "label": "Monthly Quota (GB)", "description": "Traffic allowed per calendar month; the provider is taken out of the pool once reached (0 = unlimited)"},
+			"bindAddress":    map[string]interface{}{"type": "text", "label": "Bind Address", "description": "Local IP or network interface (e.g. wg0) to connect from, to route usenet over a VPN. Connections fail rather than fall back if it is unavailable (empty = default route)", "placeholder": "wg0"},
+		},
+	},
+	"searchRetry": map[string]interface{}{
+		"label": "New Episode Retries",
+		"icon":  "refresh-cw",
+		"group": "providers",
+		"order": 3,
+		"fields": map[string]interface{}{
+			"enabled":          map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Keep searching in the background when a just-aired episode has no releases yet", "order": 0},
+			"airedWithinHours": map[string]interface{}{"type": "number", "label": "Aired Within (hours)", "description": "Only retry episodes that aired this recently", "order": 1},
+			"giveUpAfterHours": map[string]interface{}{"type": "number", "label": "Give Up After (hours)", "description": "Stop retrying this long after the first empty search", "order": 2},
+		},
+	},
+	"kids": map[string]interface{}{
+		"label": "Kids Profiles",
+		"icon":  "shield",
+		"group": "experience",
+		"order": 3,
+		"fields": map[string]interface{}{
+			"maxMovieCertification": map[string]interface{}{"type": "select", "label": "Max Movie Certification", "options": []string{"G", "PG", "PG-13", "R", "NC-17"}, "description": "Movies rated above this are hidden from kids profile searches", "order": 0},
+			"maxTvRating":           map[string]interface{}{"type": "select", "label": "Max TV Rating", "options": []string{"TV-Y", "TV-Y7", "TV-G", "TV-PG", "TV-14", "TV-MA"}, "description": "Series rated above this are hidden from kids profile searches", "order": 1},
+			"allowUnrated":          map[string]interface{}{"type": "boolean", "label": "Allow Unrated", "description": "Show search results with no known US certification", "order": 2},
 		},
 	},
 	"filtering": map[string]interface{}{
@@ -332,11 +286,14 @@ var SettingsSchema = map[string]interface{}{
 		},
 	},
 	"ranking": map[string]interface{}{
-		"label":  "Result Ranking",
-		"icon":   "list",
-		"group":  "sources",
-		"order":  1,
-		"fields": map[string]interface{}{},
+		"label": "Result Ranking",
+		"icon":  "list",
+		"group": "sources",
+		"order": 1,
+		"fields": map[string]interface{}{
+			"strategy":             map[string]interface{}{"type": "select", "label": "Selection Strategy", "options": []map[string]string{{"value": "heuristic", "label": "Ranking criteria"}, {"value": "size-first", "label": "Largest first"}, {"value": "bitrate-fit", "label": "Best bitrate fit first"}, {"value": "cached-first", "label": "Cached debrid first"}}, "description": "Order in which results are tried when a release is picked automatically. Profiles can override it.", "order": 0},
+			"experimentStrategies": map[string]interface{}{"type": "tags", "label": "Experiment Strategies", "description": "Split profiles without their own strategy evenly across these strategies and compare failure rates under /api/admin/selection-stats (empty = off)", "order": 1},
+		},
 	},
 	"ranking.criteria": map[string]interface{}{
 		"label":    "Ranking Criteria",
@@ -382,12 +339,17 @@ var SettingsSchema = map[string]interface{}{
 		"order":    3,
 		"is_array": true,
 		"fields": map[string]interface{}{
-			"name":       map[string]interface{}{"type": "text", "label": "Name", "description": "Indexer name", "order": 0},
-			"url":        map[string]interface{}{"type": "text", "label": "URL", "description": "Indexer API URL", "order": 1},
-			"apiKey":     map[string]interface{}{"type": "password", "label": "API Key", "description": "Indexer API key", "order": 2},
-			"type":       map[string]interface{}{"type": "select", "label": "Type", "options": []string{"newznab"}, "description": "Indexer type", "order": 3},
-			"categories": map[string]interface{}{"type": "text", "label": "Categories", "description": "Comma-separated newznab category IDs to filter results (e.g., 2000,2010,2020 for movies, 5000,5010,5020 for TV). Leave empty to search all categories.", "placeholder": "2000,5000", "order": 4},
-			"enabled":    map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this indexer", "order": 5},
+			"name":                 map[string]interface{}{"type": "text", "label": "Name", "description": "Indexer name", "order": 0},
+			"url":                  map[string]interface{}{"type": "text", "label": "URL", "description": "Indexer API URL", "order": 1},
+			"apiKey":               map[string]interface{}{"type": "password", "label": "API Key", "description": "Indexer API key", "order": 2},
+			"type":                 map[string]interface{}{"type": "select", "label": "Type", "options": []string{"newznab"}, "description": "Indexer type", "order": 3},
+			"categories":           map[string]interface{}{"type": "text", "label": "Categories", "description": "Comma-separated newznab category IDs to filter results (e.g., 2000,2010,2020 for movies, 5000,5010,5020 for TV). Leave empty to search all categories.", "placeholder": "2000,5000", "order": 4},
+			"movieCategories":      map[string]interface{}{"type": "text", "label": "Movie Categories", "description": "Category IDs used for movie searches (overrides Categories)", "placeholder": "2000,2040,2045", "order": 5},
+			"tvCategories":         map[string]interface{}{"type": "text", "label": "TV Categories", "description": "Category IDs used for TV searches (overrides Categories)", "placeholder": "5000,5040,5045", "order": 6},
+			"movieQueryTemplate":   map[string]interface{}{"type": "text", "label": "Movie Query Template", "description": "Custom movie query format. Placeholders: {title}, {year}. Leave empty for the default.", "placeholder": "{title} {year}", "order": 7},
+			"episodeQueryTemplate": map[string]interface{}{"type": "text", "label": "Episode Query Template", "description": "Custom episode query format. Placeholders: {title}, {year}, {season}, {episode}, {ss}, {ee} (zero-padded). Leave empty for the default.", "placeholder": "{title} S{ss}E{ee}", "order": 8},
+			"seasonQueryTemplate":  map[string]interface{}{"type": "text", "label": "Season Query Template", "description": "Custom season pack query format. Placeholders: {title}, {year}, {season}, {ss}. Leave empty for the default.", "placeholder": "{title} S{ss}", "order": 9},
+			"enabled":              map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this indexer", "order": 10},
 		},
 	},
 	"torrentScrapers": map[string]interface{}{
@@ -577,6 +539,39 @@ var SettingsSchema = map[string]interface{}{
 			"ffmpegPath":       map[string]interface{}{"type": "text", "label": "FFmpeg Path", "description": "Path to ffmpeg binary"},
 			"ffprobePath":      map[string]interface{}{"type": "text", "label": "FFprobe Path", "description": "Path to ffprobe binary"},
 			"hlsTempDirectory": map[string]interface{}{"type": "text", "label": "HLS Temp Directory", "description": "Directory for HLS segment storage (default: /tmp/novastream-hls)"},
+			"transcodeCacheMb": map[string]interface{}{"type": "number", "label": "Transcode Cache (MB)", "description": "Disk space for finished video transcodes reused when the same release is played with the same settings; applied on restart (0 = disabled)"},
+			// ffprobe strategy per source type
+			"probe.debrid.sampleSizeMb":      map[string]interface{}{"type": "number", "label": "Debrid Probe Sample (MB)", "description": "How much of a debrid file is piped to ffprobe when its URL can't be probed directly (0 = default 16)"},
+			"probe.debrid.probeSizeKb":       map[string]interface{}{"type": "number", "label": "Debrid Probe Size (KB)", "description": "ffprobe probesize for debrid and external URLs (0 = default 1000)"},
+			"probe.debrid.analyzeDurationMs": map[string]interface{}{"type": "number", "label": "Debrid Analyze Duration (ms)", "description": "ffprobe analyzeduration for debrid and external URLs (0 = default 500)"},
+			"probe.debrid.fastPath":          map[string]interface{}{"type": "boolean", "label": "Debrid Probe Fast Path", "description": "Skip frame sampling for release groups and containers that never needed it"},
+			"probe.usenet.sampleSizeMb":      map[string]interface{}{"type": "number", "label": "Usenet Probe Sample (MB)", "description": "How much of a usenet file is piped to ffprobe when it can't be read over WebDAV (0 = default 16)"},
+			"probe.usenet.probeSizeKb":       map[string]interface{}{"type": "number", "label": "Usenet Probe Size (KB)", "description": "ffprobe probesize for usenet files (0 = default 1000)"},
+			"probe.usenet.analyzeDurationMs": map[string]interface{}{"type": "number", "label": "Usenet Analyze Duration (ms)", "description": "ffprobe analyzeduration for usenet files (0 = default 500)"},
+			"probe.usenet.fastPath":          map[string]interface{}{"type": "boolean", "label": "Usenet Probe Fast Path", "description": "Skip frame sampling for release groups and containers that never needed it"},
+		},
+	},
+	"sandbox": map[string]interface{}{
+		"label": "Process Sandbox",
+		"icon":  "shield",
+		"group": "storage",
+		"order": 4,
+		"fields": map[string]interface{}{
+			"mode":                map[string]interface{}{"type": "select", "label": "Mode", "options": []string{"none", "limits", "firejail", "nsjail"}, "description": "How ffmpeg, ffprobe and yt-dlp are confined: resource limits only, or a firejail/nsjail sandbox", "order": 0},
+			"wrapperPath":         map[string]interface{}{"type": "text", "label": "Wrapper Path", "description": "Path to the firejail or nsjail binary (default: looked up on PATH)", "order": 1},
+			"memoryLimitMb":       map[string]interface{}{"type": "number", "label": "Memory Limit (MB)", "description": "Kill a process tree whose resident memory exceeds this (0 = unlimited)", "order": 2},
+			"cpuTimeLimitSeconds": map[string]interface{}{"type": "number", "label": "CPU Time Limit (seconds)", "description": "Maximum CPU time per process; long transcodes need a generous value (0 = unlimited)", "order": 3},
+			"noNetwork":           map[string]interface{}{"type": "boolean", "label": "No Network for FFmpeg", "description": "Run ffmpeg/ffprobe without network access when they read from a pipe or local file", "order": 4},
+		},
+	},
+	"diskSpace": map[string]interface{}{
+		"label": "Disk Space",
+		"icon":  "hard-drive",
+		"group": "storage",
+		"order": 5,
+		"fields": map[string]interface{}{
+			"nzbReserveMb": map[string]interface{}{"type": "number", "label": "NZB Reserve (MB)", "description": "Free space to keep on the cache disk; new NZBs are refused below it after evicting cached streams (0 = no check)", "order": 0},
+			"hlsReserveMb": map[string]interface{}{"type": "number", "label": "HLS Reserve (MB)", "description": "Free space to keep on the HLS temp disk; new sessions are refused below it after evicting idle sessions (0 = no check)", "order": 1},
 		},
 	},
 	"subtitles": map[string]interface{}{
@@ -613,6 +608,13 @@ var SettingsSchema = map[string]interface{}{
 					{"value": "metacritic", "label": "Metacritic"},
 				},
 			},
+			"compositeWeights.imdb":       map[string]interface{}{"type": "number", "label": "Composite Weight: IMDB", "description": "Weight of IMDB in the composite score used for sorting by rating (0 = ignore)", "order": 3},
+			"compositeWeights.tmdb":       map[string]interface{}{"type": "number", "label": "Composite Weight: TMDB", "description": "Weight of TMDB in the composite score (0 = ignore)", "order": 4},
+			"compositeWeights.trakt":      map[string]interface{}{"type": "number", "label": "Composite Weight: Trakt", "description": "Weight of Trakt in the composite score (0 = ignore)", "order": 5},
+			"compositeWeights.letterboxd": map[string]interface{}{"type": "number", "label": "Composite Weight: Letterboxd", "description": "Weight of Letterboxd in the composite score (0 = ignore)", "order": 6},
+			"compositeWeights.tomatoes":   map[string]interface{}{"type": "number", "label": "Composite Weight: RT Critics", "description": "Weight of the Rotten Tomatoes critic score in the composite score (0 = ignore)", "order": 7},
+			"compositeWeights.audience":   map[string]interface{}{"type": "number", "label": "Composite Weight: RT Audience", "description": "Weight of the Rotten Tomatoes audience score in the composite score (0 = ignore)", "order": 8},
+			"compositeWeights.metacritic": map[string]interface{}{"type": "number", "label": "Composite Weight: Metacritic", "description": "Weight of Metacritic in the composite score (0 = ignore)", "order": 9},
 		},
 	},
 }
@@ -642,6 +644,10 @@ type AdminUIHandler struct {
 	metadataService       MetadataService
 	clientsService        clientsService
 	clientSettingsService clientSettingsService
+	maintenanceService    maintenanceService
+	devicesService        bulkDevicesService
+	diagnosticsService    diagnosticsService
+	subsystems            subsystemStatus
 }
 
 // MetadataService interface for metadata operations
@@ -691,6 +697,11 @@ func (h *AdminUIHandler) SetClientSettingsService(css clientSettingsService) {
 	h.clientSettingsService = css
 }
 
+// SetMaintenanceService sets the maintenance service for drain progress in status
+func (h *AdminUIHandler) SetMaintenanceService(ms maintenanceService) {
+	h.maintenanceService = ms
+}
+
 // NewAdminUIHandler creates a new admin UI handler
 func NewAdminUIHandler(settingsPath string, hlsManager *HLSManager, usersService *users.Service, userSettingsService *user_settings.Service, configManager *config.Manager) *AdminUIHandler {
 	funcMap := template.FuncMap{
@@ -856,6 +867,8 @@ type AdminStatus struct {
 	Timestamp        time.Time `json:"timestamp"`
 	UsenetTotal      int       `json:"usenet_total"`
 	DebridStatus     string    `json:"debrid_status"`
+	// Maintenance reports maintenance mode and stream drain progress
+	Maintenance *models.MaintenanceStatus `json:"maintenance,omitempty"`
 }
 
 // SettingsPage serves the settings management page
@@ -1282,6 +1295,11 @@ func (h *AdminUIHandler) getStatus(settings config.Settings) AdminStatus {
 		status.DebridStatus = "No providers enabled"
 	}
 
+	if h.maintenanceService != nil {
+		maintenanceStatus := h.maintenanceService.Status()
+		status.Maintenance = &maintenanceStatus
+	}
+
 	return status
 }
 
@@ -1841,8 +1859,8 @@ func (h *AdminUIHandler) LoginSubmit(w http.ResponseWriter, r *http.Request) {
 
 	// Create session with appropriate duration
 	userAgent := r.Header.Get("User-Agent")
-	ipAddress := getClientIPAddress(r)
-	session, err := h.sessionsService.CreateWithDuration(account.ID, account.IsMaster, userAgent, ipAddress, sessionDuration)
+	ipAddress := utils.ClientIP(r)
+	session, err := h.sessionsService.CreateAdminUI(account.ID, account.IsMaster, userAgent, ipAddress, sessionDuration, rememberMe)
 	if err != nil {
 		h.renderLoginError(w, "Failed to create session")
 		return
@@ -3320,19 +3338,7 @@ func (h *AdminUIHandler) ListInvitations(w http.ResponseWriter, r *http.Request)
 	invs := h.invitationsService.List()
 	result := make([]InvitationResponse, len(invs))
 
-	// Build base URL from request
-	scheme := "https"
-	if r.TLS == nil {
-		scheme = "http"
-	}
-	if fwdProto := r.Header.Get("X-Forwarded-Proto"); fwdProto != "" {
-		scheme = fwdProto
-	}
-	host := r.Host
-	if fwdHost := r.Header.Get("X-Forwarded-Host"); fwdHost != "" {
-		host = fwdHost
-	}
-	baseURL := fmt.Sprintf("%s://%s", scheme, host)
+	baseURL := utils.ExternalBaseURL(r)
 
 	for i, inv := range invs {
 		result[i] = InvitationResponse{
@@ -3382,18 +3388,7 @@ func (h *AdminUIHandler) CreateInvitation(w http.ResponseWriter, r *http.Request
 	}
 
 	// Build URL
-	scheme := "https"
-	if r.TLS == nil {
-		scheme = "http"
-	}
-	if fwdProto := r.Header.Get("X-Forwarded-Proto"); fwdProto != "" {
-		scheme = fwdProto
-	}
-	host := r.Host
-	if fwdHost := r.Header.Get("X-Forwarded-Host"); fwdHost != "" {
-		host = fwdHost
-	}
-	baseURL := fmt.Sprintf("%s://%s", scheme, host)
+	baseURL := utils.ExternalBaseURL(r)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -3529,6 +3524,111 @@ func (h *AdminUIHandler) RegisterWithInvitation(w http.ResponseWriter, r *http.R
 	})
 }
 
+// AdminSessionResponse describes an active admin UI session. The token is
+// never returned; sessions are identified by their derived ID.
+type AdminSessionResponse struct {
+	ID         string    `json:"id"`
+	AccountID  string    `json:"accountId"`
+	Username   string    `json:"username,omitempty"`
+	IsMaster   bool      `json:"isMaster"`
+	RememberMe bool      `json:"rememberMe"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	Current    bool      `json:"current"`
+}
+
+// ListAdminSessions returns the active admin UI sessions. Master accounts see
+// every session; other accounts only see their own.
+func (h *AdminUIHandler) ListAdminSessions(w http.ResponseWriter, r *http.Request) {
+	if h.sessionsService == nil {
+		http.Error(w, "Sessions service not available", http.StatusInternalServerError)
+		return
+	}
+
+	current := adminSessionFromContext(r.Context())
+	if current == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result := make([]AdminSessionResponse, 0)
+	for _, session := range h.sessionsService.ListBySource(sessions.SourceAdminUI) {
+		if !current.IsMaster && session.AccountID != current.AccountID {
+			continue
+		}
+		resp := AdminSessionResponse{
+			ID:         session.ID(),
+			AccountID:  session.AccountID,
+			IsMaster:   session.IsMaster,
+			RememberMe: session.RememberMe,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.Token == current.Token,
+		}
+		if h.accountsService != nil {
+			if account, ok := h.accountsService.Get(session.AccountID); ok {
+				resp.Username = account.Username
+			}
+		}
+		result = append(result, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": result,
+	})
+}
+
+// RevokeAdminSession revokes an admin UI session by ID. Accounts other than
+// the master can only revoke their own sessions.
+func (h *AdminUIHandler) RevokeAdminSession(w http.ResponseWriter, r *http.Request) {
+	if h.sessionsService == nil {
+		http.Error(w, "Sessions service not available", http.StatusInternalServerError)
+		return
+	}
+
+	current := adminSessionFromContext(r.Context())
+	if current == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("sessionId")
+	if sessionID == "" {
+		http.Error(w, "sessionId parameter required", http.StatusBadRequest)
+		return
+	}
+
+	if !current.IsMaster {
+		owned := false
+		for _, session := range h.sessionsService.ListBySource(sessions.SourceAdminUI) {
+			if session.ID() == sessionID && session.AccountID == current.AccountID {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			http.Error(w, sessions.ErrSessionNotFound.Error(), http.StatusNotFound)
+			return
+		}
+	}
+
+	if _, err := h.sessionsService.RevokeByID(sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if err == sessions.ErrSessionNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // ClearMetadataCache clears all cached metadata files
 func (h *AdminUIHandler) ClearMetadataCache(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
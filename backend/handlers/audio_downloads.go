@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/audio_downloads"
+
+	"github.com/gorilla/mux"
+)
+
+type audioDownloadService interface {
+	Create(userID string, req models.AudioDownloadRequest) (models.AudioDownload, error)
+	ListForUser(userID string) []models.AudioDownload
+	Get(userID, id string) (models.AudioDownload, error)
+	Open(userID, id string) (models.AudioDownload, *os.File, error)
+	Delete(userID, id string) error
+}
+
+var _ audioDownloadService = (*audio_downloads.Service)(nil)
+
+// AudioDownloadsHandler lets profiles extract the audio of a title into the
+// downloads area and fetch the file for offline listening
+type AudioDownloadsHandler struct {
+	svc   audioDownloadService
+	users userService
+}
+
+// NewAudioDownloadsHandler creates a new audio downloads handler
+func NewAudioDownloadsHandler(svc audioDownloadService, users userService) *AudioDownloadsHandler {
+	return &AudioDownloadsHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/audio-downloads
+func (h *AudioDownloadsHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.ListForUser(userID))
+}
+
+// Create handles POST /api/users/{userID}/audio-downloads
+// Body: {"path": "/webdav/...", "title": "...", "format": "m4a", "bitrateKbps": 128, "audioTrack": 1}
+func (h *AudioDownloadsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AudioDownloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	download, err := h.svc.Create(userID, req)
+	if err != nil {
+		writeJSONError(w, err.Error(), audioDownloadErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(download)
+}
+
+// Get handles GET /api/users/{userID}/audio-downloads/{downloadID}
+// Clients poll this for the progress of a running extraction.
+func (h *AudioDownloadsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	download, err := h.svc.Get(userID, mux.Vars(r)["downloadID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), audioDownloadErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(download)
+}
+
+// File handles GET/HEAD /api/users/{userID}/audio-downloads/{downloadID}/file
+func (h *AudioDownloadsHandler) File(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	download, file, err := h.svc.Open(userID, mux.Vars(r)["downloadID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), audioDownloadErrorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	contentType := "audio/mp4"
+	if download.Format == models.AudioDownloadFormatOpus {
+		contentType = "audio/ogg"
+	}
+	name := audioDownloadFileName(download)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, *download.CompletedAt, file)
+}
+
+// Delete handles DELETE /api/users/{userID}/audio-downloads/{downloadID}
+func (h *AudioDownloadsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(userID, mux.Vars(r)["downloadID"]); err != nil {
+		writeJSONError(w, err.Error(), audioDownloadErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *AudioDownloadsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AudioDownloadsHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func audioDownloadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, audio_downloads.ErrDownloadNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, audio_downloads.ErrNotCompleted):
+		return http.StatusConflict
+	case errors.Is(err, audio_downloads.ErrPathRequired),
+		errors.Is(err, audio_downloads.ErrInvalidFormat),
+		errors.Is(err, audio_downloads.ErrTooManyDownloads):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// audioDownloadFileName names the file after the title, keeping only
+// characters that are safe in a Content-Disposition header and on any disk.
+func audioDownloadFileName(download models.AudioDownload) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ' ':
+			return r
+		default:
+			return -1
+		}
+	}, download.Title)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = download.ID
+	}
+	return name + "." + download.Format
+}
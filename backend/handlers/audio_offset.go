@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"novastream/services/audio_offsets"
+)
+
+// AudioOffsetStore remembers audio sync corrections per release path
+type AudioOffsetStore interface {
+	Get(releasePath string) int64
+	Set(releasePath string, offsetMs int64) (int64, error)
+}
+
+var _ AudioOffsetStore = (*audio_offsets.Service)(nil)
+
+// SetAudioOffsetStore enables persisted audio delay corrections for transmuxed
+// and HLS streams
+func (h *VideoHandler) SetAudioOffsetStore(store AudioOffsetStore) {
+	h.audioOffsets = store
+	if h.hlsManager != nil {
+		h.hlsManager.audioOffsets = store
+	}
+}
+
+// AudioOffsetResponse reports the restarted session and the offset now applied
+type AudioOffsetResponse struct {
+	SeekResponse
+	OffsetMs int64 `json:"offsetMs"`
+}
+
+// AdjustAudioOffset shifts audio for the release behind an HLS session and
+// restarts transcoding at the current position so it takes effect. Body:
+// {"deltaMs": N} to nudge relative to the current offset, or {"offsetMs": N}
+// to set it outright, plus an optional "time" with the playback position.
+// Positive values delay the audio, so "audio is 300ms late" is deltaMs -300.
+// The offset is remembered per release for subsequent plays.
+// POST /api/video/hls/{sessionID}/audio/offset
+func (h *VideoHandler) AdjustAudioOffset(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		h.HandleOptions(w, r)
+		return
+	}
+
+	if h.audioOffsets == nil {
+		http.Error(w, "audio offsets not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if h.hlsManager == nil {
+		http.Error(w, "HLS not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		DeltaMs  *int64   `json:"deltaMs,omitempty"`
+		OffsetMs *int64   `json:"offsetMs,omitempty"`
+		Time     *float64 `json:"time,omitempty"` // Current playback position in absolute media time
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if (req.DeltaMs == nil) == (req.OffsetMs == nil) {
+		http.Error(w, "exactly one of deltaMs or offsetMs is required", http.StatusBadRequest)
+		return
+	}
+	if req.Time != nil && *req.Time < 0 {
+		http.Error(w, "invalid time", http.StatusBadRequest)
+		return
+	}
+
+	sessionID := mux.Vars(r)["sessionID"]
+	session, ok := h.hlsManager.GetSession(sessionID)
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	session.mu.RLock()
+	isLive := session.IsLive
+	releasePath := session.Path
+	duration := session.Duration
+	// Fall back to the last keepalive-reported segment when the client doesn't send its position
+	targetTime := session.StartOffset
+	if session.LastPlaybackSegment > 0 {
+		targetTime += float64(session.LastPlaybackSegment) * hlsSegmentDuration
+	}
+	session.mu.RUnlock()
+
+	if isLive {
+		http.Error(w, "audio offsets are not supported for live streams", http.StatusConflict)
+		return
+	}
+
+	offsetMs := h.audioOffsets.Get(releasePath)
+	if req.DeltaMs != nil {
+		offsetMs += *req.DeltaMs
+	} else {
+		offsetMs = *req.OffsetMs
+	}
+
+	stored, err := h.audioOffsets.Set(releasePath, offsetMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[video] audio offset for %s set to %dms", releasePath, stored)
+
+	if req.Time != nil {
+		targetTime = *req.Time
+	}
+	if duration > 0 && targetTime >= duration {
+		targetTime = duration - 1
+	}
+	if targetTime < 0 {
+		targetTime = 0
+	}
+
+	actualStart := h.hlsManager.restartTranscodingAt(session, targetTime, "audio offset change")
+	h.hlsManager.waitForPlaylist(session)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AudioOffsetResponse{
+		SeekResponse: SeekResponse{
+			SessionID:         sessionID,
+			StartOffset:       targetTime,
+			ActualStartOffset: actualStart,
+			KeyframeDelta:     actualStart - targetTime,
+			Duration:          duration,
+			PlaylistURL:       fmt.Sprintf("/video/hls/%s/stream.m3u8", sessionID),
+		},
+		OffsetMs: stored,
+	})
+}
+
+// releaseAudioOffset returns the stored offset for a release, or 0 when no store is configured
+func releaseAudioOffset(store AudioOffsetStore, releasePath string) int64 {
+	if store == nil {
+		return 0
+	}
+	return store.Get(releasePath)
+}
+
+// withAudioOffsetInput rewrites transmux args to read audio from a second,
+// time-shifted input at inputURL. Audio maps on input 0 move to input 1.
+func withAudioOffsetInput(args []string, inputURL string, offsetMs int64) []string {
+	out := make([]string, 0, len(args)+4)
+	inserted := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-map" && i+1 < len(args) {
+			target := args[i+1]
+			switch {
+			case strings.HasPrefix(target, "0:a"):
+				target = "1" + target[1:]
+			case strings.HasPrefix(target, "-0:a"):
+				target = "-1" + target[2:]
+			}
+			out = append(out, arg, target)
+			i++
+			continue
+		}
+		out = append(out, arg)
+		if arg == "-i" && !inserted && i+1 < len(args) {
+			out = append(out, args[i+1], "-itsoffset", fmt.Sprintf("%.3f", float64(offsetMs)/1000), "-i", inputURL)
+			inserted = true
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithAudioOffsetInput(t *testing.T) {
+	args := []string{"-nostdin", "-i", "pipe:0", "-map", "0:v:0", "-map", "0:a", "-map", "-0:a:m:codec_name:dts", "-map", "0:s:m:codec_name:subrip?", "-c:a", "copy"}
+
+	got := strings.Join(withAudioOffsetInput(args, "http://127.0.0.1/webdav/a.mkv", -300), " ")
+	want := "-nostdin -i pipe:0 -itsoffset -0.300 -i http://127.0.0.1/webdav/a.mkv -map 0:v:0 -map 1:a -map -1:a:m:codec_name:dts -map 0:s:m:codec_name:subrip? -c:a copy"
+	if got != want {
+		t.Fatalf("unexpected args:\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"novastream/models"
 	"novastream/services/accounts"
 	"novastream/services/sessions"
+	"novastream/utils"
 )
 
 // AuthHandler handles authentication endpoints.
@@ -38,6 +41,11 @@ type LoginResponse struct {
 	AccountID string `json:"accountId"`
 	Username  string `json:"username"`
 	IsMaster  bool   `json:"isMaster"`
+
+	// Clock-independent expiry for clients whose clock is off
+	ExpiresIn       int64  `json:"expiresIn"`                 // Seconds until the session expires
+	ServerTime      string `json:"serverTime"`                // Server clock when the response was built
+	ClientExpiresAt string `json:"clientExpiresAt,omitempty"` // ExpiresAt on the client's clock, when it sent X-Client-Time
 }
 
 // AccountResponse represents account info response.
@@ -65,7 +73,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Create session
 	userAgent := r.Header.Get("User-Agent")
-	ipAddress := getClientIPAddress(r)
+	ipAddress := utils.ClientIP(r)
 	var session models.Session
 	if req.RememberMe {
 		session, err = h.sessions.CreatePersistent(account.ID, account.IsMaster, userAgent, ipAddress)
@@ -77,18 +85,34 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := LoginResponse{
-		Token:     session.Token,
-		ExpiresAt: session.ExpiresAt.Format("2006-01-02T15:04:05Z"),
-		AccountID: account.ID,
-		Username:  account.Username,
-		IsMaster:  account.IsMaster,
-	}
+	resp := newLoginResponse(r, session, account)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// newLoginResponse describes a session, with its expiry also given relative to
+// now and, when the client sent its clock, on the client's clock.
+func newLoginResponse(r *http.Request, session models.Session, account models.Account) LoginResponse {
+	now := time.Now().UTC()
+	resp := LoginResponse{
+		Token:      session.Token,
+		ExpiresAt:  session.ExpiresAt.Format("2006-01-02T15:04:05Z"),
+		AccountID:  account.ID,
+		Username:   account.Username,
+		IsMaster:   account.IsMaster,
+		ExpiresIn:  int64(session.ExpiresAt.Sub(now).Seconds()),
+		ServerTime: now.Format(time.RFC3339),
+	}
+	if skew, ok := utils.ClientClockSkew(r, now); ok {
+		resp.ClientExpiresAt = session.ExpiresAt.Add(skew).Format("2006-01-02T15:04:05Z")
+		if skew > time.Minute || skew < -time.Minute {
+			log.Printf("[auth] client clock for account %s is off by %s", account.ID, skew.Round(time.Second))
+		}
+	}
+	return resp
+}
+
 // Logout invalidates the current session.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	token := extractBearerToken(r)
@@ -163,13 +187,7 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := LoginResponse{
-		Token:     session.Token,
-		ExpiresAt: session.ExpiresAt.Format("2006-01-02T15:04:05Z"),
-		AccountID: account.ID,
-		Username:  account.Username,
-		IsMaster:  account.IsMaster,
-	}
+	resp := newLoginResponse(r, session, account)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -246,29 +264,3 @@ func extractBearerToken(r *http.Request) string {
 
 	return strings.TrimSpace(parts[1])
 }
-
-// getClientIPAddress extracts the client IP address from the request.
-func getClientIPAddress(r *http.Request) string {
-	// Check X-Forwarded-For header first
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// Take the first IP in the chain
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
-		}
-		return strings.TrimSpace(xff)
-	}
-
-	// Check X-Real-IP header
-	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
-		return strings.TrimSpace(xri)
-	}
-
-	// Fall back to RemoteAddr
-	addr := r.RemoteAddr
-	if idx := strings.LastIndex(addr, ":"); idx != -1 {
-		return addr[:idx]
-	}
-	return addr
-}
@@ -214,6 +214,7 @@ func TestLogin_CapturesMetadata(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "TestBrowser/1.0")
 	req.Header.Set("X-Forwarded-For", "192.168.1.100")
+	req.RemoteAddr = "127.0.0.1:12345" // forwarding headers are only trusted from a proxy
 	rec := httptest.NewRecorder()
 
 	handler.Login(rec, req)
@@ -603,6 +604,7 @@ func TestGetClientIPAddress_XRealIP(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewReader(body))
 	req.Header.Set("X-Real-IP", "172.16.0.1")
+	req.RemoteAddr = "127.0.0.1:12345"
 	rec := httptest.NewRecorder()
 
 	handler.Login(rec, req)
@@ -620,6 +622,36 @@ func TestGetClientIPAddress_XRealIP(t *testing.T) {
 	}
 }
 
+func TestGetClientIPAddress_IgnoresHeadersFromUntrustedPeer(t *testing.T) {
+	handler, _, sessionsSvc := setupAuthHandler(t)
+
+	reqBody := handlers.LoginRequest{
+		Username: "admin",
+		Password: "admin",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewReader(body))
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-Real-IP", "172.16.0.1")
+	req.RemoteAddr = "203.0.113.7:12345"
+	rec := httptest.NewRecorder()
+
+	handler.Login(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var resp handlers.LoginResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	session, _ := sessionsSvc.Validate(resp.Token)
+	if session.IPAddress != "203.0.113.7" {
+		t.Errorf("expected forwarding headers from an untrusted peer to be ignored, got IP %q", session.IPAddress)
+	}
+}
+
 func TestGetClientIPAddress_RemoteAddr(t *testing.T) {
 	handler, _, sessionsSvc := setupAuthHandler(t)
 
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/calendar_prefetch"
+
+	"github.com/gorilla/mux"
+)
+
+type calendarPrefetchService interface {
+	ListForUser(userID string) []models.CalendarPrefetch
+}
+
+var _ calendarPrefetchService = (*calendar_prefetch.Service)(nil)
+
+var _ calendar_prefetch.Warmer = (*PrequeueHandler)(nil)
+
+// CalendarPrefetchHandler lists the episodes from a profile's Trakt calendar
+// that are prefetched after they air
+type CalendarPrefetchHandler struct {
+	svc   calendarPrefetchService
+	users userService
+}
+
+// NewCalendarPrefetchHandler creates a new calendar prefetch handler
+func NewCalendarPrefetchHandler(svc calendarPrefetchService, users userService) *CalendarPrefetchHandler {
+	return &CalendarPrefetchHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/calendar-prefetch
+func (h *CalendarPrefetchHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.ListForUser(userID))
+}
+
+// Options handles CORS preflight requests
+func (h *CalendarPrefetchHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/devices"
+
+	"github.com/gorilla/mux"
+)
+
+// DeviceProvider looks up registered devices for per-device playback overrides
+type DeviceProvider interface {
+	Get(id string) (*models.Device, error)
+}
+
+type devicesService interface {
+	DeviceProvider
+	Register(accountID string, reg models.DeviceRegistration) (*models.Device, error)
+	List(profileID string) ([]models.Device, error)
+	Update(id string, update models.DeviceUpdate) (*models.Device, error)
+	Delete(id string) error
+}
+
+var _ devicesService = (*devices.Service)(nil)
+
+// DevicesHandler exposes the persistent device registry
+type DevicesHandler struct {
+	svc devicesService
+}
+
+// NewDevicesHandler creates a new devices handler
+func NewDevicesHandler(svc devicesService) *DevicesHandler {
+	return &DevicesHandler{svc: svc}
+}
+
+// Register handles POST /api/devices/register
+// Apps call this on launch to report their platform and codec capabilities.
+// The device ID falls back to the X-Client-ID header. A device stays with the
+// account that first registered it.
+func (h *DevicesHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req models.DeviceRegistration
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.ID) == "" {
+		req.ID = r.Header.Get("X-Client-ID")
+	}
+
+	device, err := h.svc.Register(auth.GetAccountID(r), req)
+	if err != nil {
+		writeJSONError(w, err.Error(), deviceErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// List handles GET /api/devices
+// Optional query param: profileId to list devices assigned to one profile
+func (h *DevicesHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.URL.Query().Get("profileId"))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// Get handles GET /api/devices/{deviceID}
+func (h *DevicesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	device, err := h.svc.Get(mux.Vars(r)["deviceID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), deviceErrorStatus(err))
+		return
+	}
+	if device == nil {
+		writeJSONError(w, devices.ErrDeviceNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// Update handles PATCH /api/devices/{deviceID}
+// Sets the device name, assigned profile, transcode policy, bitrate cap,
+// resolution cap or SDR-only flag
+func (h *DevicesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var update models.DeviceUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	device, err := h.svc.Update(mux.Vars(r)["deviceID"], update)
+	if err != nil {
+		writeJSONError(w, err.Error(), deviceErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// Delete handles DELETE /api/devices/{deviceID}
+func (h *DevicesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(mux.Vars(r)["deviceID"]); err != nil {
+		writeJSONError(w, err.Error(), deviceErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *DevicesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func deviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, devices.ErrDeviceNotFound),
+		errors.Is(err, devices.ErrProfileNotOwned):
+		return http.StatusNotFound
+	case errors.Is(err, devices.ErrDeviceNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, devices.ErrDeviceIDRequired),
+		errors.Is(err, devices.ErrInvalidTranscodePolicy),
+		errors.Is(err, devices.ErrInvalidBitrateCap),
+		errors.Is(err, devices.ErrInvalidResolutionCap):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
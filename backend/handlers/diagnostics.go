@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/diagnostics"
+)
+
+type diagnosticsService interface {
+	Latest() (report models.DiagnosticsReport, running bool, ok bool)
+}
+
+var _ diagnosticsService = (*diagnostics.Service)(nil)
+
+// SetDiagnosticsService sets the service holding the startup diagnostics report
+func (h *AdminUIHandler) SetDiagnosticsService(ds diagnosticsService) {
+	h.diagnosticsService = ds
+}
+
+// DiagnosticsResponse is the startup diagnostics report. Report is nil while
+// the first run since startup is still in progress.
+type DiagnosticsResponse struct {
+	Running bool                      `json:"running"`
+	Report  *models.DiagnosticsReport `json:"report"`
+}
+
+// GetDiagnostics returns the startup self-diagnostics: invalid settings,
+// missing binaries, unreachable providers and legacy settings, with the
+// feature each one disables
+// GET /admin/api/diagnostics
+func (h *AdminUIHandler) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if h.diagnosticsService == nil {
+		http.Error(w, "Diagnostics service not available", http.StatusInternalServerError)
+		return
+	}
+
+	var resp DiagnosticsResponse
+	report, running, ok := h.diagnosticsService.Latest()
+	resp.Running = running
+	if ok {
+		resp.Report = &report
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"novastream/utils/diskspace"
+)
+
+// hlsEvictIdleAfter is how long a session must go unrequested before it may
+// be evicted to make room for a new one.
+const hlsEvictIdleAfter = 2 * time.Minute
+
+// writeDiskSpaceError writes a 507 with the disk space error code when err
+// was caused by a full disk, and reports whether it did.
+func writeDiskSpaceError(w http.ResponseWriter, err error) bool {
+	var spaceErr *diskspace.InsufficientSpaceError
+	if !errors.As(err, &spaceErr) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInsufficientStorage)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":         err.Error(),
+		"code":          diskspace.ErrorCode,
+		"freeBytes":     spaceErr.FreeBytes,
+		"requiredBytes": spaceErr.RequiredBytes,
+	})
+	return true
+}
+
+// evictIdleSessions removes completed or idle HLS sessions, least recently
+// used first, until about needBytes of segments have been removed. Sessions
+// that are still being watched are left alone.
+func (m *HLSManager) evictIdleSessions(ctx context.Context, needBytes int64) int64 {
+	type candidate struct {
+		id         string
+		dir        string
+		lastAccess time.Time
+	}
+
+	now := time.Now()
+	var candidates []candidate
+	m.mu.RLock()
+	for id, session := range m.sessions {
+		session.mu.RLock()
+		idle := now.Sub(session.LastAccess) > hlsEvictIdleAfter
+		if idle || session.Completed {
+			candidates = append(candidates, candidate{id: id, dir: session.OutputDir, lastAccess: session.LastAccess})
+		}
+		session.mu.RUnlock()
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].lastAccess.Before(candidates[j].lastAccess) })
+
+	var freed int64
+	for _, c := range candidates {
+		if freed >= needBytes || ctx.Err() != nil {
+			break
+		}
+		size := dirSize(c.dir)
+		log.Printf("[hls] evicting idle session %s to free disk space (%d MB)", c.id, size>>20)
+		m.CleanupSession(c.id)
+		freed += size
+	}
+	return freed
+}
+
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/external_subtitles"
+	"novastream/services/playback"
+
+	"github.com/gorilla/mux"
+)
+
+// externalSubtitleLister lists the subtitles uploaded for a title or episode
+type externalSubtitleLister interface {
+	List(titleID string, seasonNumber, episodeNumber int) []models.ExternalSubtitle
+}
+
+type externalSubtitleService interface {
+	externalSubtitleLister
+	Upload(upload external_subtitles.Upload) (models.ExternalSubtitle, error)
+	Open(id string) (models.ExternalSubtitle, *os.File, error)
+	Delete(id, accountID string, isMaster bool) error
+}
+
+var _ externalSubtitleService = (*external_subtitles.Service)(nil)
+
+// ExternalSubtitlesHandler lets users upload their own SRT/ASS subtitles for a
+// title or episode and serves them as WebVTT.
+type ExternalSubtitlesHandler struct {
+	svc externalSubtitleService
+}
+
+// NewExternalSubtitlesHandler creates a new external subtitles handler
+func NewExternalSubtitlesHandler(svc externalSubtitleService) *ExternalSubtitlesHandler {
+	return &ExternalSubtitlesHandler{svc: svc}
+}
+
+// Upload handles POST /api/subtitles/external. The multipart form carries the
+// subtitle in "file" along with titleId and optionally seasonNumber,
+// episodeNumber, language and label.
+func (h *ExternalSubtitlesHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, external_subtitles.MaxUploadBytes+(1<<20))
+	if err := r.ParseMultipartForm(external_subtitles.MaxUploadBytes); err != nil {
+		writeJSONError(w, "file too large or invalid form", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSONError(w, "subtitle file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeJSONError(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	season, episode, err := parseEpisodeParams(r.FormValue("seasonNumber"), r.FormValue("episodeNumber"))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.svc.Upload(external_subtitles.Upload{
+		AccountID:     auth.GetAccountID(r),
+		TitleID:       r.FormValue("titleId"),
+		SeasonNumber:  season,
+		EpisodeNumber: episode,
+		Language:      r.FormValue("language"),
+		Label:         r.FormValue("label"),
+		Filename:      header.Filename,
+		Data:          data,
+	})
+	if err != nil {
+		writeJSONError(w, err.Error(), externalSubtitleErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// List handles GET /api/subtitles/external?titleId=&seasonNumber=&episodeNumber=
+func (h *ExternalSubtitlesHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	titleID := strings.TrimSpace(query.Get("titleId"))
+	if titleID == "" {
+		writeJSONError(w, external_subtitles.ErrTitleIDRequired.Error(), http.StatusBadRequest)
+		return
+	}
+	season, episode, err := parseEpisodeParams(query.Get("seasonNumber"), query.Get("episodeNumber"))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List(titleID, season, episode))
+}
+
+// Serve handles GET /api/subtitles/external/{id}/subtitles.vtt
+func (h *ExternalSubtitlesHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	sub, file, err := h.svc.Open(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, err.Error(), externalSubtitleErrorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, sub.ID+".vtt", sub.UploadedAt, file)
+}
+
+// Delete handles DELETE /api/subtitles/external/{id}. Only the uploading
+// account or the master account may remove a subtitle.
+func (h *ExternalSubtitlesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(mux.Vars(r)["id"], auth.GetAccountID(r), auth.IsMaster(r)); err != nil {
+		writeJSONError(w, err.Error(), externalSubtitleErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *ExternalSubtitlesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseEpisodeParams parses optional season and episode numbers; both are 0
+// for movies and whole-title uploads.
+func parseEpisodeParams(rawSeason, rawEpisode string) (int, int, error) {
+	parse := func(name, raw string) (int, error) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return 0, nil
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		return value, nil
+	}
+
+	season, err := parse("seasonNumber", rawSeason)
+	if err != nil {
+		return 0, 0, err
+	}
+	episode, err := parse("episodeNumber", rawEpisode)
+	if err != nil {
+		return 0, 0, err
+	}
+	return season, episode, nil
+}
+
+func externalSubtitleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, external_subtitles.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, external_subtitles.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, external_subtitles.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, external_subtitles.ErrTooManySubtitles):
+		return http.StatusConflict
+	case errors.Is(err, external_subtitles.ErrTitleIDRequired),
+		errors.Is(err, external_subtitles.ErrInvalidEpisode),
+		errors.Is(err, external_subtitles.ErrEmptyFile),
+		errors.Is(err, external_subtitles.ErrUnsupportedFormat):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// attachExternalSubtitles adds the subtitles uploaded for the prequeued title
+// or episode to its status, whichever release was selected.
+func (h *PrequeueHandler) attachExternalSubtitles(resp *playback.PrequeueStatusResponse, titleID string) {
+	if h.externalSubtitles == nil || resp == nil || titleID == "" {
+		return
+	}
+
+	var season, episode int
+	if resp.TargetEpisode != nil {
+		season, episode = resp.TargetEpisode.SeasonNumber, resp.TargetEpisode.EpisodeNumber
+	}
+	for _, sub := range h.externalSubtitles.List(titleID, season, episode) {
+		resp.ExternalSubtitles = append(resp.ExternalSubtitles, models.ExternalSubtitleTrack{
+			ID:       sub.ID,
+			Language: sub.Language,
+			Label:    sub.Label,
+			// Without the /api/ prefix, like HLS playlist URLs - the frontend adds it
+			URL: fmt.Sprintf("/subtitles/external/%s/subtitles.vtt", sub.ID),
+		})
+	}
+}
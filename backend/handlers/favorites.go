@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/favorites"
+	"novastream/services/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+type favoritesService interface {
+	List(userID string) ([]models.FavoriteItem, error)
+	AddOrUpdate(userID string, input models.FavoriteUpsert) (models.FavoriteItem, error)
+	Remove(userID, mediaType, id string) (bool, error)
+	ImportFromWatchlist(userID string, items []models.WatchlistItem) (models.FavoriteImportResult, error)
+}
+
+var _ favoritesService = (*favorites.Service)(nil)
+
+// favoritesWatchlistSource is the watchlist that favorites can be imported from
+type favoritesWatchlistSource interface {
+	List(userID string) ([]models.WatchlistItem, error)
+	Remove(userID, mediaType, id string) (bool, error)
+}
+
+var _ favoritesWatchlistSource = (*watchlist.Service)(nil)
+
+// FavoritesHandler exposes each profile's favorites
+type FavoritesHandler struct {
+	Service   favoritesService
+	Watchlist favoritesWatchlistSource
+	Users     userService
+}
+
+// NewFavoritesHandler creates a favorites handler; watchlist may be nil to
+// disable importing from it
+func NewFavoritesHandler(service favoritesService, watchlist favoritesWatchlistSource, users userService) *FavoritesHandler {
+	return &FavoritesHandler{Service: service, Watchlist: watchlist, Users: users}
+}
+
+// List handles GET /api/users/{userID}/favorites
+func (h *FavoritesHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Service.List(userID)
+	if err != nil {
+		http.Error(w, err.Error(), favoritesErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// Add handles POST /api/users/{userID}/favorites
+func (h *FavoritesHandler) Add(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var body models.FavoriteUpsert
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.Service.AddOrUpdate(userID, body)
+	if err != nil {
+		http.Error(w, err.Error(), favoritesErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// Remove handles DELETE /api/users/{userID}/favorites/{mediaType}/{id}
+func (h *FavoritesHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	removed, err := h.Service.Remove(userID, vars["mediaType"], vars["id"])
+	if err != nil {
+		http.Error(w, err.Error(), favoritesErrorStatus(err))
+		return
+	}
+	if !removed {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportFromWatchlist handles POST /api/users/{userID}/favorites/import-watchlist
+// Copies the watchlist items named by keys, or by default those synced from
+// a Trakt favorites list, into favorites. removeFromWatchlist moves them instead.
+func (h *FavoritesHandler) ImportFromWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+	if h.Watchlist == nil {
+		http.Error(w, "watchlist unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req models.FavoriteImportRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	items, err := h.Watchlist.List(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	selected := selectWatchlistForFavorites(items, req.Keys)
+
+	result, err := h.Service.ImportFromWatchlist(userID, selected)
+	if err != nil {
+		http.Error(w, err.Error(), favoritesErrorStatus(err))
+		return
+	}
+
+	if req.RemoveFromWatchlist {
+		for _, item := range selected {
+			removed, err := h.Watchlist.Remove(userID, item.MediaType, item.ID)
+			if err != nil {
+				log.Printf("[favorites] failed to remove %s from watchlist of %s: %v", item.Key(), userID, err)
+				continue
+			}
+			if removed {
+				result.Removed++
+			}
+		}
+	}
+
+	log.Printf("[favorites] imported %d watchlist item(s) for %s (%d already favorites, %d removed from watchlist)",
+		result.Imported, userID, result.Skipped, result.Removed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Export handles GET /api/users/{userID}/favorites/export
+// Returns the profile's favorites as a JSON file download.
+func (h *FavoritesHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Service.List(userID)
+	if err != nil {
+		http.Error(w, err.Error(), favoritesErrorStatus(err))
+		return
+	}
+
+	export := models.FavoritesExport{ExportedAt: time.Now().UTC(), ProfileID: userID, Favorites: items}
+	name := fmt.Sprintf("favorites-%s-%s.json", userID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(export)
+}
+
+// Options handles CORS preflight requests
+func (h *FavoritesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *FavoritesHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.Users != nil && !h.Users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+// selectWatchlistForFavorites picks the watchlist items named by keys, or the
+// items synced from a Trakt favorites list when no keys are given.
+func selectWatchlistForFavorites(items []models.WatchlistItem, keys []string) []models.WatchlistItem {
+	var selected []models.WatchlistItem
+	if len(keys) > 0 {
+		wanted := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			wanted[strings.ToLower(strings.TrimSpace(key))] = true
+		}
+		for _, item := range items {
+			if wanted[strings.ToLower(item.Key())] {
+				selected = append(selected, item)
+			}
+		}
+		return selected
+	}
+
+	for _, item := range items {
+		if favorites.IsFavoritesSyncSource(item.SyncSource) {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
+
+func favoritesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, favorites.ErrUserIDRequired),
+		errors.Is(err, favorites.ErrIDRequired),
+		errors.Is(err, favorites.ErrMediaTypeRequired),
+		errors.Is(err, favorites.ErrIdentifierRequired):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
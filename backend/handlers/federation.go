@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"novastream/models"
+	"novastream/services/federation"
+
+	"github.com/gorilla/mux"
+)
+
+type federationService interface {
+	ListPeers() []models.FederationPeer
+	CreatePeer(req models.FederationPeerRequest) (models.FederationPeer, error)
+	UpdatePeer(peerID string, req models.FederationPeerRequest) (models.FederationPeer, error)
+	DeletePeer(peerID string) error
+	SyncPeer(ctx context.Context, peerID string) (models.FederationSyncResult, error)
+
+	Authenticate(r *http.Request) (models.FederationPeer, error)
+	SharedProfiles(peer models.FederationPeer) []models.FederationProfile
+	Changes(peer models.FederationPeer, profileID string, since int64) (*models.FederationDelta, error)
+}
+
+var _ federationService = (*federation.Service)(nil)
+
+// FederationHandler serves profile data to federated strmr servers and lets
+// the master account manage them.
+type FederationHandler struct {
+	svc federationService
+}
+
+// NewFederationHandler creates a new federation handler
+func NewFederationHandler(svc federationService) *FederationHandler {
+	return &FederationHandler{svc: svc}
+}
+
+// Profiles handles GET /api/federation/profiles (peer-signed)
+func (h *FederationHandler) Profiles(w http.ResponseWriter, r *http.Request) {
+	peer, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.SharedProfiles(peer))
+}
+
+// Changes handles GET /api/federation/profiles/{profileID}/changes?since= (peer-signed)
+func (h *FederationHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	peer, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var since int64
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			writeJSONError(w, "invalid since cursor", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	delta, err := h.svc.Changes(peer, mux.Vars(r)["profileID"], since)
+	if err != nil {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delta)
+}
+
+// ListPeers handles GET /api/admin/federation/peers
+func (h *FederationHandler) ListPeers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.ListPeers())
+}
+
+// CreatePeer handles POST /api/admin/federation/peers. The response carries
+// the shared secret, which is not returned again.
+func (h *FederationHandler) CreatePeer(w http.ResponseWriter, r *http.Request) {
+	var req models.FederationPeerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	peer, err := h.svc.CreatePeer(req)
+	if err != nil {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(peer)
+}
+
+// UpdatePeer handles PUT /api/admin/federation/peers/{peerID}
+func (h *FederationHandler) UpdatePeer(w http.ResponseWriter, r *http.Request) {
+	var req models.FederationPeerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	peer, err := h.svc.UpdatePeer(mux.Vars(r)["peerID"], req)
+	if err != nil {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(peer)
+}
+
+// DeletePeer handles DELETE /api/admin/federation/peers/{peerID}
+func (h *FederationHandler) DeletePeer(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeletePeer(mux.Vars(r)["peerID"]); err != nil {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SyncPeer handles POST /api/admin/federation/peers/{peerID}/sync, pulling
+// the peer's changes now.
+func (h *FederationHandler) SyncPeer(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.SyncPeer(r.Context(), mux.Vars(r)["peerID"])
+	if err != nil && result.PeerID == "" {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadGateway
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Options handles CORS preflight requests
+func (h *FederationHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *FederationHandler) authenticate(w http.ResponseWriter, r *http.Request) (models.FederationPeer, bool) {
+	peer, err := h.svc.Authenticate(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), federationErrorStatus(err))
+		return models.FederationPeer{}, false
+	}
+	return peer, true
+}
+
+func federationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, federation.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, federation.ErrPeerDisabled),
+		errors.Is(err, federation.ErrProfileNotShared):
+		return http.StatusForbidden
+	case errors.Is(err, federation.ErrPeerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, federation.ErrDuplicateSecret):
+		return http.StatusConflict
+	case errors.Is(err, federation.ErrNameRequired),
+		errors.Is(err, federation.ErrInvalidURL),
+		errors.Is(err, federation.ErrAccountRequired),
+		errors.Is(err, federation.ErrSecretTooShort):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
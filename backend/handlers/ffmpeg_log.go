@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// ffmpegLogLimit is how much stderr is kept per stream; older output is dropped
+	ffmpegLogLimit = 64 * 1024
+	// ffmpegLogRetain is how many finished stream logs are kept for diagnosis
+	ffmpegLogRetain = 50
+)
+
+// ffmpegLogSecret matches URL credentials and API keys in ffmpeg command lines
+var ffmpegLogSecret = regexp.MustCompile(`(://)[^/@\s]+@|((?:api_?key|apikey|token|password)=)[^&\s]+`)
+
+// FFmpegLogSummary describes one stream's captured ffmpeg output
+type FFmpegLogSummary struct {
+	ID        string     `json:"id"`
+	Kind      string     `json:"kind"` // hls | live | transmux
+	Path      string     `json:"path"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Runs      int        `json:"runs"`                // ffmpeg processes started for the stream (restarts after seeks and recoveries)
+	ExitError string     `json:"exitError,omitempty"` // Error from the last process, if it failed
+	Bytes     int        `json:"bytes"`
+	Truncated bool       `json:"truncated"` // Older output was dropped to stay within the limit
+}
+
+// ffmpegLog keeps the last ffmpegLogLimit bytes of a stream's ffmpeg stderr.
+// HLS sessions restart ffmpeg on seeks and recoveries; every run appends to
+// the same log.
+type ffmpegLog struct {
+	mu      sync.Mutex
+	summary FFmpegLogSummary
+	buf     []byte
+}
+
+// Write appends ffmpeg output, dropping the oldest bytes past the limit
+func (l *ffmpegLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	if over := len(l.buf) - ffmpegLogLimit; over > 0 {
+		l.buf = append(l.buf[:0], l.buf[over:]...)
+		l.summary.Truncated = true
+	}
+	return len(p), nil
+}
+
+// begin marks the start of an ffmpeg run with its redacted command line
+func (l *ffmpegLog) begin(args []string) {
+	l.mu.Lock()
+	l.summary.Runs++
+	l.summary.EndedAt = nil
+	l.summary.ExitError = ""
+	run := l.summary.Runs
+	l.mu.Unlock()
+
+	command := ffmpegLogSecret.ReplaceAllString(strings.Join(args, " "), "$1$2***")
+	fmt.Fprintf(l, "--- run %d at %s ---\nffmpeg %s\n", run, time.Now().UTC().Format(time.RFC3339), command)
+}
+
+// finish records how the current ffmpeg run ended
+func (l *ffmpegLog) finish(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now().UTC()
+	l.summary.EndedAt = &now
+	if err != nil {
+		l.summary.ExitError = err.Error()
+	}
+}
+
+func (l *ffmpegLog) endedAt() *time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.summary.EndedAt
+}
+
+func (l *ffmpegLog) snapshot() (FFmpegLogSummary, []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	summary := l.summary
+	summary.Bytes = len(l.buf)
+	return summary, append([]byte(nil), l.buf...)
+}
+
+// ffmpegLogStore holds stderr captures for active streams and the most
+// recently finished ones
+type ffmpegLogStore struct {
+	mu   sync.Mutex
+	logs map[string]*ffmpegLog
+}
+
+func newFFmpegLogStore() *ffmpegLogStore {
+	return &ffmpegLogStore{logs: make(map[string]*ffmpegLog)}
+}
+
+// open returns the log for a stream, creating it on first use, and starts a
+// new run in it
+func (s *ffmpegLogStore) open(id, kind, path string, args []string) *ffmpegLog {
+	s.mu.Lock()
+	l, ok := s.logs[id]
+	if !ok {
+		l = &ffmpegLog{summary: FFmpegLogSummary{ID: id, Kind: kind, Path: path, StartedAt: time.Now().UTC()}}
+		s.logs[id] = l
+		s.pruneLocked()
+	}
+	s.mu.Unlock()
+
+	l.begin(args)
+	return l
+}
+
+// pruneLocked drops the oldest finished logs beyond ffmpegLogRetain.
+// Must be called with s.mu held.
+func (s *ffmpegLogStore) pruneLocked() {
+	type finishedLog struct {
+		id      string
+		endedAt time.Time
+	}
+	var finished []finishedLog
+	for id, l := range s.logs {
+		if endedAt := l.endedAt(); endedAt != nil {
+			finished = append(finished, finishedLog{id, *endedAt})
+		}
+	}
+	if len(finished) <= ffmpegLogRetain {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].endedAt.Before(finished[j].endedAt)
+	})
+	for _, f := range finished[:len(finished)-ffmpegLogRetain] {
+		delete(s.logs, f.id)
+	}
+}
+
+// release marks a stream's log finished if its last run never reported an
+// exit, so it becomes eligible for pruning
+func (s *ffmpegLogStore) release(id string) {
+	if l, ok := s.get(id); ok && l.endedAt() == nil {
+		l.finish(nil)
+	}
+}
+
+func (s *ffmpegLogStore) list() []FFmpegLogSummary {
+	s.mu.Lock()
+	logs := make([]*ffmpegLog, 0, len(s.logs))
+	for _, l := range s.logs {
+		logs = append(logs, l)
+	}
+	s.mu.Unlock()
+
+	summaries := make([]FFmpegLogSummary, 0, len(logs))
+	for _, l := range logs {
+		summary, _ := l.snapshot()
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StartedAt.After(summaries[j].StartedAt)
+	})
+	return summaries
+}
+
+func (s *ffmpegLogStore) get(id string) (*ffmpegLog, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.logs[id]
+	return l, ok
+}
+
+// FFmpegLogsHandler exposes captured ffmpeg stderr to admins so transcode
+// failures can be diagnosed without rerunning with debug flags
+type FFmpegLogsHandler struct {
+	store *ffmpegLogStore
+}
+
+// NewFFmpegLogsHandler creates a handler for the video handler's transmux and HLS logs
+func NewFFmpegLogsHandler(video *VideoHandler) *FFmpegLogsHandler {
+	return &FFmpegLogsHandler{store: video.ffmpegLogs}
+}
+
+// List returns the captured logs, newest first
+func (h *FFmpegLogsHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.list())
+}
+
+// Get returns one stream's captured output as plain text. For HLS streams the
+// ID is the session ID.
+func (h *FFmpegLogsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	l, ok := h.store.get(mux.Vars(r)["id"])
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "log not found"})
+		return
+	}
+
+	summary, output := l.snapshot()
+	if strings.EqualFold(r.URL.Query().Get("format"), "json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			FFmpegLogSummary
+			Output string `json:"output"`
+		}{summary, string(output)})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(output)
+}
+
+// Options handles CORS preflight requests
+func (h *FFmpegLogsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
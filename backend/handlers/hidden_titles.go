@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/hidden_titles"
+
+	"github.com/gorilla/mux"
+)
+
+type hiddenTitlesService interface {
+	List(profileID string) []models.HiddenTitle
+	Hide(profileID string, req models.HiddenTitleRequest) (*models.HiddenTitle, error)
+	Unhide(profileID, titleID string) error
+}
+
+var _ hiddenTitlesService = (*hidden_titles.Service)(nil)
+
+// hiddenTitleMatcher reports which titles a profile marked "not interested".
+type hiddenTitleMatcher interface {
+	Matcher(profileID string) func(models.Title) bool
+}
+
+var _ hiddenTitleMatcher = (*hidden_titles.Service)(nil)
+
+// HiddenTitlesHandler manages a profile's "not interested" titles
+type HiddenTitlesHandler struct {
+	svc   hiddenTitlesService
+	users userService
+}
+
+// NewHiddenTitlesHandler creates a new hidden titles handler
+func NewHiddenTitlesHandler(svc hiddenTitlesService, users userService) *HiddenTitlesHandler {
+	return &HiddenTitlesHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/hidden-titles
+func (h *HiddenTitlesHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List(userID))
+}
+
+// Hide handles POST /api/users/{userID}/hidden-titles
+func (h *HiddenTitlesHandler) Hide(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.HiddenTitleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	title, err := h.svc.Hide(userID, req)
+	if err != nil {
+		writeJSONError(w, err.Error(), hiddenTitleErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(title)
+}
+
+// Unhide handles DELETE /api/users/{userID}/hidden-titles/{titleID}
+func (h *HiddenTitlesHandler) Unhide(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Unhide(userID, mux.Vars(r)["titleID"]); err != nil {
+		writeJSONError(w, err.Error(), hiddenTitleErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *HiddenTitlesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *HiddenTitlesHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func hiddenTitleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, hidden_titles.ErrTitleNotHidden):
+		return http.StatusNotFound
+	case errors.Is(err, hidden_titles.ErrTooManyHidden):
+		return http.StatusConflict
+	case errors.Is(err, hidden_titles.ErrProfileIDRequired),
+		errors.Is(err, hidden_titles.ErrTitleIDRequired),
+		errors.Is(err, hidden_titles.ErrInvalidMediaType):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// hiddenMatcher returns the hidden title matcher for userID, or nil when
+// there is nothing to filter.
+func (h *MetadataHandler) hiddenMatcher(userID string) func(models.Title) bool {
+	if h.HiddenTitles == nil || userID == "" {
+		return nil
+	}
+	return h.HiddenTitles.Matcher(userID)
+}
+
+// filterHiddenItems removes items the profile marked "not interested".
+func filterHiddenItems(items []models.TrendingItem, hidden func(models.Title) bool) []models.TrendingItem {
+	if hidden == nil {
+		return items
+	}
+	result := make([]models.TrendingItem, 0, len(items))
+	for _, item := range items {
+		if !hidden(item.Title) {
+			result = append(result, item)
+		}
+	}
+	if filtered := len(items) - len(result); filtered > 0 {
+		log.Printf("[hidden] filtered %d/%d hidden items", filtered, len(items))
+	}
+	return result
+}
+
+// filterHiddenTitles removes titles the profile marked "not interested".
+func filterHiddenTitles(titles []models.Title, hidden func(models.Title) bool) []models.Title {
+	if hidden == nil {
+		return titles
+	}
+	result := make([]models.Title, 0, len(titles))
+	for _, title := range titles {
+		if !hidden(title) {
+			result = append(result, title)
+		}
+	}
+	return result
+}
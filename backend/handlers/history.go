@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"novastream/internal/auth"
 	"novastream/models"
+	"novastream/services/availability"
 	"novastream/services/history"
+	"novastream/services/playback_queue"
 
 	"github.com/gorilla/mux"
 )
@@ -17,6 +22,10 @@ type historyService interface {
 	ListContinueWatching(userID string) ([]models.SeriesWatchState, error)
 	GetSeriesWatchState(userID, seriesID string) (*models.SeriesWatchState, error)
 	HideFromContinueWatching(userID, seriesID string) error
+	ListContinueWatchingArchive(userID string) ([]models.PlaybackProgress, error)
+	RestoreToContinueWatching(userID, seriesID string) error
+	ListMissingEpisodes(userID string) ([]models.SeriesMissingEpisodes, error)
+	GetMissingEpisodes(userID, seriesID string) (*models.SeriesMissingEpisodes, error)
 
 	// Watch History methods
 	ListWatchHistory(userID string) ([]models.WatchHistoryItem, error)
@@ -24,6 +33,7 @@ type historyService interface {
 	ToggleWatched(userID string, update models.WatchHistoryUpdate) (models.WatchHistoryItem, error)
 	UpdateWatchHistory(userID string, update models.WatchHistoryUpdate) (models.WatchHistoryItem, error)
 	BulkUpdateWatchHistory(userID string, updates []models.WatchHistoryUpdate) ([]models.WatchHistoryItem, error)
+	SetSeriesWatched(ctx context.Context, userID string, update models.SeriesWatchedUpdate) (models.SeriesWatchedResult, error)
 	IsWatched(userID, mediaType, itemID string) (bool, error)
 
 	// Playback Progress methods
@@ -32,20 +42,60 @@ type historyService interface {
 	ListPlaybackProgress(userID string) ([]models.PlaybackProgress, error)
 	DeletePlaybackProgress(userID, mediaType, itemID string) error
 	ListAllPlaybackProgress() map[string][]models.PlaybackProgress // For admin dashboard
+
+	// Watch-together methods
+	SetCoViewers(userID string, viewerIDs []string) ([]string, error)
+	CoViewers(userID string) ([]string, error)
+}
+
+// profileAccountChecker is implemented by user services that can verify which
+// account a profile belongs to.
+type profileAccountChecker interface {
+	BelongsToAccount(profileID, accountID string) bool
 }
 
 var _ historyService = (*history.Service)(nil)
 
+// playbackQueueCards builds the continue watching card for a profile's playback queue.
+type playbackQueueCards interface {
+	ContinueWatchingCard(profileID string) *models.SeriesWatchState
+}
+
+var _ playbackQueueCards = (*playback_queue.Service)(nil)
+
+// episodeAvailabilityService checks whether episodes have a healthy stream.
+type episodeAvailabilityService interface {
+	CheckEpisodes(userID string, series models.WatchlistItem, episodes []availability.Episode, refresh bool) []models.WatchlistAvailability
+}
+
+var _ episodeAvailabilityService = (*availability.Service)(nil)
+
+// maxAvailabilityEpisodes bounds the missing episodes checked per series,
+// since every check runs an indexer search.
+const maxAvailabilityEpisodes = 10
+
 type HistoryHandler struct {
-	Service  historyService
-	Users    userService
-	DemoMode bool
+	Service      historyService
+	Users        userService
+	Queue        playbackQueueCards         // Optional; adds the playback queue card to continue watching
+	Availability episodeAvailabilityService // Optional; checks missing episodes for a healthy stream
+	DemoMode     bool
 }
 
 func NewHistoryHandler(service historyService, users userService, demoMode bool) *HistoryHandler {
 	return &HistoryHandler{Service: service, Users: users, DemoMode: demoMode}
 }
 
+// SetPlaybackQueue sets the queue surfaced as a card in continue watching.
+func (h *HistoryHandler) SetPlaybackQueue(queue playbackQueueCards) {
+	h.Queue = queue
+}
+
+// SetAvailabilityService enables availability checks in missing episode reports.
+func (h *HistoryHandler) SetAvailabilityService(svc episodeAvailabilityService) {
+	h.Availability = svc
+}
+
 func (h *HistoryHandler) ListContinueWatching(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.requireUser(w, r)
 	if !ok {
@@ -62,6 +112,13 @@ func (h *HistoryHandler) ListContinueWatching(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// The queue was lined up deliberately, so its card leads the row
+	if h.Queue != nil {
+		if card := h.Queue.ContinueWatchingCard(userID); card != nil {
+			items = append([]models.SeriesWatchState{*card}, items...)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(items)
 }
@@ -101,6 +158,106 @@ func (h *HistoryHandler) GetSeriesWatchState(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(state)
 }
 
+// ListMissingEpisodes returns aired but unwatched episodes for every series the user watches.
+// With ?availability=true the first few missing episodes of each series carry
+// whether they have a healthy stream; see attachEpisodeAvailability.
+func (h *HistoryHandler) ListMissingEpisodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := h.Service.ListMissingEpisodes(userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, history.ErrUserIDRequired) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	for i := range reports {
+		h.attachEpisodeAvailability(r, userID, &reports[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// GetMissingEpisodes returns aired but unwatched episodes for a single series.
+// Accepts ?availability=true like ListMissingEpisodes.
+func (h *HistoryHandler) GetMissingEpisodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	seriesID := strings.TrimSpace(vars["seriesID"])
+	if seriesID == "" {
+		http.Error(w, "series id is required", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Service.GetMissingEpisodes(userID, seriesID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, history.ErrUserIDRequired):
+			status = http.StatusBadRequest
+		case errors.Is(err, history.ErrSeriesIDRequired):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if report == nil {
+		http.Error(w, "series not in watch history", http.StatusNotFound)
+		return
+	}
+	h.attachEpisodeAvailability(r, userID, report)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// attachEpisodeAvailability sets the availability of the report's first
+// missing episodes when the request asks for it. Results come from the
+// availability cache; episodes still being checked are marked pending and
+// clients poll until none are. ?refresh=true forces a recheck.
+func (h *HistoryHandler) attachEpisodeAvailability(r *http.Request, userID string, report *models.SeriesMissingEpisodes) {
+	if h.Availability == nil || len(report.MissingEpisodes) == 0 {
+		return
+	}
+	if want, _ := strconv.ParseBool(r.URL.Query().Get("availability")); !want {
+		return
+	}
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+
+	missing := report.MissingEpisodes
+	if len(missing) > maxAvailabilityEpisodes {
+		missing = missing[:maxAvailabilityEpisodes]
+	}
+	episodes := make([]availability.Episode, len(missing))
+	for i, ep := range missing {
+		episodes[i] = availability.Episode{ID: ep.EpisodeID, SeasonNumber: ep.SeasonNumber, EpisodeNumber: ep.EpisodeNumber}
+	}
+	series := models.WatchlistItem{
+		ID:          report.SeriesID,
+		MediaType:   "series",
+		Name:        report.SeriesTitle,
+		Year:        report.Year,
+		ExternalIDs: report.ExternalIDs,
+	}
+
+	results := h.Availability.CheckEpisodes(userID, series, episodes, refresh)
+	for i := range results {
+		result := results[i]
+		missing[i].Availability = &result
+	}
+}
+
 // HideFromContinueWatching hides a series/movie from the continue watching list
 func (h *HistoryHandler) HideFromContinueWatching(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.requireUser(w, r)
@@ -131,6 +288,57 @@ func (h *HistoryHandler) HideFromContinueWatching(w http.ResponseWriter, r *http
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ListContinueWatchingArchive lists items the retention policy archived from continue watching
+func (h *HistoryHandler) ListContinueWatchingArchive(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Service.ListContinueWatchingArchive(userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, history.ErrUserIDRequired) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// RestoreToContinueWatching returns an archived series/movie to the continue watching list
+func (h *HistoryHandler) RestoreToContinueWatching(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	seriesID := strings.TrimSpace(vars["seriesID"])
+	if seriesID == "" {
+		http.Error(w, "series id is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Service.RestoreToContinueWatching(userID, seriesID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, history.ErrUserIDRequired):
+			status = http.StatusBadRequest
+		case errors.Is(err, history.ErrSeriesIDRequired):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *HistoryHandler) RecordEpisode(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.requireUser(w, r)
 	if !ok {
@@ -315,6 +523,39 @@ func (h *HistoryHandler) BulkUpdateWatchHistory(w http.ResponseWriter, r *http.R
 	json.NewEncoder(w).Encode(items)
 }
 
+// SetSeriesWatched marks a whole series, or one season of it, watched or
+// unwatched in one call.
+func (h *HistoryHandler) SetSeriesWatched(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var update models.SeriesWatchedUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	update.SeriesID = strings.TrimSpace(mux.Vars(r)["seriesID"])
+
+	result, err := h.Service.SetSeriesWatched(r.Context(), userID, update)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, history.ErrUserIDRequired),
+			errors.Is(err, history.ErrSeriesIDRequired):
+			status = http.StatusBadRequest
+		case errors.Is(err, history.ErrSeasonNotFound):
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // UpdatePlaybackProgress updates the playback progress for a media item
 func (h *HistoryHandler) UpdatePlaybackProgress(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.requireUser(w, r)
@@ -427,6 +668,76 @@ func (h *HistoryHandler) DeletePlaybackProgress(w http.ResponseWriter, r *http.R
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetCoViewers returns the profiles currently watching together with the user
+func (h *HistoryHandler) GetCoViewers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	viewers, err := h.Service.CoViewers(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"profileIds": viewers})
+}
+
+// SetCoViewers selects the profiles watching together with the user. Called at
+// playback start so progress and history are credited to every viewer; an
+// empty list (or DELETE) returns the user to watching alone.
+func (h *HistoryHandler) SetCoViewers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		ProfileIDs []string `json:"profileIds"`
+	}
+	if r.Method != http.MethodDelete {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	checker, checkAccount := h.Users.(profileAccountChecker)
+	checkAccount = checkAccount && !auth.IsMaster(r)
+	accountID := auth.GetAccountID(r)
+	for _, profileID := range body.ProfileIDs {
+		profileID = strings.TrimSpace(profileID)
+		if profileID == "" {
+			continue
+		}
+		if h.Users != nil && !h.Users.Exists(profileID) {
+			http.Error(w, "profile not found: "+profileID, http.StatusNotFound)
+			return
+		}
+		if checkAccount && !checker.BelongsToAccount(profileID, accountID) {
+			http.Error(w, "profile not found: "+profileID, http.StatusNotFound)
+			return
+		}
+	}
+
+	viewers, err := h.Service.SetCoViewers(userID, body.ProfileIDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, history.ErrUserIDRequired) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"profileIds": viewers})
+}
+
 func (h *HistoryHandler) Options(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
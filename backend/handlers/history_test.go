@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,13 +12,17 @@ import (
 	"github.com/gorilla/mux"
 
 	"novastream/handlers"
+	"novastream/internal/auth"
 	"novastream/models"
+	"novastream/services/availability"
 )
 
 type fakeHistoryService struct {
-	state models.SeriesWatchState
-	items []models.SeriesWatchState
-	err   error
+	state   models.SeriesWatchState
+	items   []models.SeriesWatchState
+	missing []models.SeriesMissingEpisodes
+	viewers []string
+	err     error
 }
 
 func (f *fakeHistoryService) RecordEpisode(userID string, payload models.EpisodeWatchPayload) (models.SeriesWatchState, error) {
@@ -55,6 +60,10 @@ func (f *fakeHistoryService) BulkUpdateWatchHistory(userID string, updates []mod
 	return nil, f.err
 }
 
+func (f *fakeHistoryService) SetSeriesWatched(ctx context.Context, userID string, update models.SeriesWatchedUpdate) (models.SeriesWatchedResult, error) {
+	return models.SeriesWatchedResult{}, f.err
+}
+
 func (f *fakeHistoryService) IsWatched(userID, mediaType, itemID string) (bool, error) {
 	return false, f.err
 }
@@ -86,10 +95,55 @@ func (f *fakeHistoryService) HideFromContinueWatching(userID, seriesID string) e
 	return f.err
 }
 
+func (f *fakeHistoryService) ListContinueWatchingArchive(userID string) ([]models.PlaybackProgress, error) {
+	return nil, f.err
+}
+
+func (f *fakeHistoryService) RestoreToContinueWatching(userID, seriesID string) error {
+	return f.err
+}
+
+func (f *fakeHistoryService) ListMissingEpisodes(userID string) ([]models.SeriesMissingEpisodes, error) {
+	return f.missing, f.err
+}
+
+func (f *fakeHistoryService) GetMissingEpisodes(userID, seriesID string) (*models.SeriesMissingEpisodes, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for i := range f.missing {
+		if f.missing[i].SeriesID == seriesID {
+			return &f.missing[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeHistoryService) SetCoViewers(userID string, viewerIDs []string) ([]string, error) {
+	f.viewers = viewerIDs
+	return viewerIDs, f.err
+}
+
+func (f *fakeHistoryService) CoViewers(userID string) ([]string, error) {
+	return f.viewers, f.err
+}
+
 type fakeUserService struct{}
 
 func (fakeUserService) Exists(id string) bool { return true }
 
+// fakeAccountUserService maps profile IDs to their owning account.
+type fakeAccountUserService map[string]string
+
+func (f fakeAccountUserService) Exists(id string) bool {
+	_, ok := f[id]
+	return ok
+}
+
+func (f fakeAccountUserService) BelongsToAccount(profileID, accountID string) bool {
+	return f[profileID] == accountID
+}
+
 func TestHistoryHandler_RecordEpisode(t *testing.T) {
 	svc := &fakeHistoryService{
 		state: models.SeriesWatchState{
@@ -148,3 +202,137 @@ func TestHistoryHandler_ListContinueWatching(t *testing.T) {
 		t.Fatalf("unexpected response %+v", response)
 	}
 }
+
+func TestHistoryHandler_GetMissingEpisodes(t *testing.T) {
+	svc := &fakeHistoryService{missing: []models.SeriesMissingEpisodes{{
+		SeriesID:        "s1",
+		GapCount:        1,
+		MissingEpisodes: []models.MissingEpisode{{SeasonNumber: 1, EpisodeNumber: 2, Gap: true}},
+	}}}
+	handler := handlers.NewHistoryHandler(svc, fakeUserService{}, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/user/history/missing/s1", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": "user", "seriesID": "s1"})
+	rec := httptest.NewRecorder()
+
+	handler.GetMissingEpisodes(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	var response models.SeriesMissingEpisodes
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.GapCount != 1 || len(response.MissingEpisodes) != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/user/history/missing/unknown", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": "user", "seriesID": "unknown"})
+	rec = httptest.NewRecorder()
+
+	handler.GetMissingEpisodes(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unwatched series, got %d", rec.Code)
+	}
+}
+
+type fakeEpisodeAvailability struct {
+	series   models.WatchlistItem
+	episodes []availability.Episode
+}
+
+func (f *fakeEpisodeAvailability) CheckEpisodes(userID string, series models.WatchlistItem, episodes []availability.Episode, refresh bool) []models.WatchlistAvailability {
+	f.series, f.episodes = series, episodes
+	results := make([]models.WatchlistAvailability, len(episodes))
+	for i, ep := range episodes {
+		results[i] = models.WatchlistAvailability{ID: ep.ID, MediaType: "episode", Ready: ep.EpisodeNumber == 2}
+	}
+	return results
+}
+
+func TestHistoryHandler_GetMissingEpisodesAvailability(t *testing.T) {
+	svc := &fakeHistoryService{missing: []models.SeriesMissingEpisodes{{
+		SeriesID:    "s1",
+		SeriesTitle: "Show",
+		MissingEpisodes: []models.MissingEpisode{
+			{SeasonNumber: 1, EpisodeNumber: 2, EpisodeID: "e2"},
+			{SeasonNumber: 1, EpisodeNumber: 3, EpisodeID: "e3"},
+		},
+	}}}
+	checker := &fakeEpisodeAvailability{}
+	handler := handlers.NewHistoryHandler(svc, fakeUserService{}, false)
+	handler.SetAvailabilityService(checker)
+
+	// Availability is only checked when asked for
+	req := httptest.NewRequest(http.MethodGet, "/users/user/history/missing/s1", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": "user", "seriesID": "s1"})
+	handler.GetMissingEpisodes(httptest.NewRecorder(), req)
+	if checker.episodes != nil {
+		t.Fatalf("expected no availability check without ?availability=true")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/user/history/missing/s1?availability=true", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": "user", "seriesID": "s1"})
+	rec := httptest.NewRecorder()
+	handler.GetMissingEpisodes(rec, req)
+
+	var response models.SeriesMissingEpisodes
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if checker.series.Name != "Show" || len(checker.episodes) != 2 {
+		t.Fatalf("unexpected check of %+v for %+v", checker.series, checker.episodes)
+	}
+	got := response.MissingEpisodes
+	if got[0].Availability == nil || !got[0].Availability.Ready || got[1].Availability == nil || got[1].Availability.Ready {
+		t.Fatalf("unexpected availability %+v", got)
+	}
+}
+
+func TestHistoryHandler_SetCoViewers(t *testing.T) {
+	svc := &fakeHistoryService{}
+	users := fakeAccountUserService{"user": "acct-1", "partner": "acct-1", "stranger": "acct-2"}
+	handler := handlers.NewHistoryHandler(svc, users, false)
+
+	setViewers := func(ids ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string][]string{"profileIds": ids})
+		req := httptest.NewRequest(http.MethodPut, "/users/user/history/viewers", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"userID": "user"})
+		req = req.WithContext(context.WithValue(req.Context(), auth.ContextKeyAccountID, "acct-1"))
+		rec := httptest.NewRecorder()
+		handler.SetCoViewers(rec, req)
+		return rec
+	}
+
+	if rec := setViewers("partner"); rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(svc.viewers) != 1 || svc.viewers[0] != "partner" {
+		t.Fatalf("unexpected co-viewers %v", svc.viewers)
+	}
+
+	for _, id := range []string{"stranger", "missing"} {
+		svc.viewers = nil
+		if rec := setViewers(id); rec.Code != http.StatusNotFound {
+			t.Fatalf("profile %q: expected 404, got %d", id, rec.Code)
+		}
+		if svc.viewers != nil {
+			t.Fatalf("profile %q: co-viewers should not be updated, got %v", id, svc.viewers)
+		}
+	}
+
+	svc.viewers = []string{"partner"}
+	req := httptest.NewRequest(http.MethodDelete, "/users/user/history/viewers", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": "user"})
+	rec := httptest.NewRecorder()
+	handler.SetCoViewers(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	if len(svc.viewers) != 0 {
+		t.Fatalf("expected co-viewers to be cleared, got %v", svc.viewers)
+	}
+}
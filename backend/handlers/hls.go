@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -25,8 +26,12 @@ import (
 	"syscall"
 	"time"
 
+	"novastream/models"
 	"novastream/services/streaming"
 	"novastream/utils"
+	"novastream/utils/diskspace"
+	"novastream/utils/mediatools"
+	"novastream/utils/sandbox"
 )
 
 // cdnClient is a shared HTTP client optimized for CDN connections.
@@ -303,6 +308,161 @@ func (t *throttledReader) Read(p []byte) (n int, err error) {
 	return t.r.Read(p)
 }
 
+// ErrHDRExceedsDeviceCaps is returned when an HDR or Dolby Vision source can't
+// be played within a device's SDR-only or resolution cap
+var ErrHDRExceedsDeviceCaps = errors.New("HDR source exceeds the device's playback caps")
+
+// HLSVideoPolicy carries per-device overrides for how a session handles video
+type HLSVideoPolicy struct {
+	ForceTranscode bool     // Re-encode SDR video even when the device could play the source
+	NeverTranscode bool     // Copy video even when the codec would normally be re-encoded
+	MaxBitrateKbps int      // Caps the re-encoded video bitrate (0 = no cap)
+	MaxHeight      int      // Re-encodes SDR video taller than this and scales it down to it (0 = source resolution)
+	SDROnly        bool     // The device can't display HDR or Dolby Vision
+	VideoCodecs    []string // Codecs the device decodes natively (empty = H.264/HEVC defaults)
+}
+
+// refusesHDR reports whether an HDR/DV source at the given height (0 =
+// unknown) breaks the device's caps. The pipeline can't tone map, so HDR is
+// never converted to SDR or downscaled; the session is refused instead. A
+// device that never transcodes accepts sources above its resolution cap.
+func (p HLSVideoPolicy) refusesHDR(height int, isHDR bool) bool {
+	if !isHDR {
+		return false
+	}
+	if p.SDROnly {
+		return true
+	}
+	return !p.NeverTranscode && p.MaxHeight > 0 && height > p.MaxHeight
+}
+
+// needsVideoTranscode decides whether video in codec at the given height (0 =
+// unknown) must be re-encoded. HDR/DV sources are never force-transcoded or
+// downscaled since the pipeline has no tone mapping.
+func (p HLSVideoPolicy) needsVideoTranscode(codec string, height int, isHDR bool) bool {
+	if p.NeverTranscode {
+		return false
+	}
+	if IsIncompatibleVideoCodec(codec) {
+		return true
+	}
+	if isHDR {
+		return false
+	}
+	if p.ForceTranscode {
+		return true
+	}
+	if p.MaxHeight > 0 && height > p.MaxHeight {
+		return true
+	}
+	if len(p.VideoCodecs) == 0 || codec == "" {
+		return false
+	}
+	codec = canonicalVideoCodec(codec)
+	for _, supported := range p.VideoCodecs {
+		if canonicalVideoCodec(supported) == codec {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalVideoCodec maps codec aliases reported by devices to ffprobe codec names
+func canonicalVideoCodec(codec string) string {
+	switch c := strings.ToLower(strings.TrimSpace(codec)); c {
+	case "avc", "avc1", "h.264":
+		return "h264"
+	case "h265", "h.265", "hvc1", "hev1":
+		return "hevc"
+	default:
+		return c
+	}
+}
+
+// HLSQualityRung is one step of the re-encode ladder a session can switch to mid-playback
+type HLSQualityRung struct {
+	Height         int `json:"height"`
+	MaxBitrateKbps int `json:"maxBitrateKbps"`
+}
+
+// hlsQualityLadder lists the rungs available for mid-session quality changes, highest first
+var hlsQualityLadder = []HLSQualityRung{
+	{Height: 2160, MaxBitrateKbps: 20000},
+	{Height: 1080, MaxBitrateKbps: 8000},
+	{Height: 720, MaxBitrateKbps: 4000},
+	{Height: 480, MaxBitrateKbps: 1500},
+	{Height: 360, MaxBitrateKbps: 800},
+}
+
+// nextQualityRung returns the rung one step down or up from current (zero rung = source quality).
+// Stepping up to or past the source height returns the zero rung. ok is false when
+// there is nowhere to go in that direction.
+func nextQualityRung(current HLSQualityRung, sourceHeight int, direction string) (HLSQualityRung, bool) {
+	switch direction {
+	case "down":
+		ceiling := current.Height
+		if ceiling == 0 {
+			ceiling = sourceHeight
+		}
+		if ceiling == 0 {
+			// Unknown source resolution - treat it as 1080p so the first step lands on 720p
+			ceiling = 1080
+		}
+		for _, rung := range hlsQualityLadder {
+			if rung.Height < ceiling {
+				return rung, true
+			}
+		}
+		return HLSQualityRung{}, false
+	case "up":
+		if current.Height == 0 {
+			return HLSQualityRung{}, false
+		}
+		for i := len(hlsQualityLadder) - 1; i >= 0; i-- {
+			rung := hlsQualityLadder[i]
+			if rung.Height <= current.Height {
+				continue
+			}
+			if sourceHeight > 0 && rung.Height >= sourceHeight {
+				break
+			}
+			return rung, true
+		}
+		return HLSQualityRung{}, true
+	}
+	return HLSQualityRung{}, false
+}
+
+// qualityRungForHeight returns the highest rung that fits within height.
+// Heights at or above the source resolution select source quality.
+func qualityRungForHeight(height, sourceHeight int) (HLSQualityRung, bool) {
+	if sourceHeight > 0 && height >= sourceHeight {
+		return HLSQualityRung{}, true
+	}
+	for _, rung := range hlsQualityLadder {
+		if rung.Height <= height {
+			return rung, true
+		}
+	}
+	return HLSQualityRung{}, false
+}
+
+// withQualityRung forces a re-encode at the rung's resolution and bitrate.
+// Lower per-device resolution and bitrate caps still win. The zero rung leaves the policy unchanged.
+func (p HLSVideoPolicy) withQualityRung(rung HLSQualityRung) HLSVideoPolicy {
+	if rung.Height == 0 {
+		return p
+	}
+	p.ForceTranscode = true
+	if p.MaxHeight == 0 || rung.Height < p.MaxHeight {
+		p.MaxHeight = rung.Height
+	}
+	if p.MaxBitrateKbps == 0 || rung.MaxBitrateKbps < p.MaxBitrateKbps {
+		p.MaxBitrateKbps = rung.MaxBitrateKbps
+	}
+	return p
+}
+
 // HLSSession represents an active HLS transcoding session
 type HLSSession struct {
 	ID           string
@@ -311,7 +471,7 @@ type HLSSession struct {
 	OutputDir    string
 	CreatedAt    time.Time
 	LastAccess   time.Time
-	FFmpegCmd    *exec.Cmd
+	FFmpegCmd    *sandbox.Cmd
 	Cancel       context.CancelFunc
 	mu           sync.RWMutex
 	Completed    bool
@@ -320,6 +480,11 @@ type HLSSession struct {
 	DVDisabled          bool // Set to true if DV metadata parsing fails and we fallback to non-DV
 	HasHDR              bool // HDR10 content (needs fMP4 segments for iOS compatibility)
 	HDRMetadataDisabled bool // Set to true if hevc_metadata filter fails (malformed SEI data)
+	HasHDR10Plus         bool    // Source carries HDR10+ dynamic metadata (SMPTE 2094-40 SEI)
+	HDR10PlusPassthrough bool    // Client decodes HDR10+; copy the bitstream untouched instead of HDR10 fallback
+	VideoPolicy          HLSVideoPolicy // Per-device video transcode overrides
+	QualityRung          HLSQualityRung // Active quality ladder rung (zero = source quality)
+	devicePolicy         HLSVideoPolicy // Policy the session was created with, restored at source quality
 	Duration          float64 // Total duration in seconds from ffprobe
 	StartOffset        float64 // Requested start offset in seconds for session warm starts (never changes, for frontend)
 	TranscodingOffset  float64 // Current transcoding position (updated on recovery restarts)
@@ -353,6 +518,8 @@ type HLSSession struct {
 	LastSegmentServed        int // Last segment number successfully served to client (-1 = none yet)
 	EarliestBufferedSegment  int // Earliest segment still in player's buffer from keepalive (-1 = unknown)
 	Paused                   bool // True if FFmpeg is paused (SIGSTOP) waiting for player to catch up
+	ClientPaused             bool      // True while the client reports playback paused
+	ClientPausedAt           time.Time // When the client paused (or last fetched a segment while paused)
 
 	// Input error recovery (for usenet disconnections)
 	InputErrorDetected bool // Set to true when FFmpeg input stream fails (usenet disconnect)
@@ -360,6 +527,10 @@ type HLSSession struct {
 	forceAAC           bool // Cached forceAAC setting for recovery restarts
 	SeekInProgress     bool // Set to true during user-initiated seek to prevent recovery logic
 
+	// Source failover (degraded usenet release replaced by a cached debrid one)
+	SourceSwitch   *models.SourceSwitch // Most recent switch, nil if none
+	SourceSwitches int                  // Number of switches, so pollers can spot a new one
+
 	// Fatal error tracking (unplayable streams)
 	FatalError       string // Set when stream is determined to be unplayable (persistent bitstream errors)
 
@@ -374,9 +545,15 @@ type HLSSession struct {
 
 	// Live TV session fields
 	IsLive bool // True for live TV streams (no duration, no seeking)
+	LiveAudioTracks []LiveAudioTrack // Audio group renditions of a multi-audio live channel (nil = first audio stream only)
 
 	// Prequeue tracking
 	PrequeueType string // "", "details" (details page), or "next_episode" (auto-play next)
+
+	// Transcode cache
+	FromCache       bool                 // Segments were restored from the transcode cache, no FFmpeg runs
+	cacheCapture    *transcodeCacheEntry // Set while the segments are captured for the transcode cache
+	videoTranscoded bool                 // FFmpeg re-encodes the video (only such sessions are cached)
 }
 
 const (
@@ -430,6 +607,24 @@ type HLSManager struct {
 	// Global probe cache - shared between prequeue (ProbeVideoFull) and HLS (probeAllMetadata)
 	probeCache   map[string]*cachedProbeEntry
 	probeCacheMu sync.RWMutex
+	// Identifies the file behind each cached path so re-cached sources are re-probed
+	fingerprints *sourceFingerprints
+	// Video keyframe times per source, for snapping resume positions
+	keyframes *keyframeIndexCache
+	// Optional recorder for probe/transcode failure metrics
+	failureRecorder MediaFailureRecorder
+	// Optional per-release subtitle delay corrections applied to sidecar VTTs
+	subtitleOffsets SubtitleOffsetStore
+	// Optional per-release audio delay corrections applied with -itsoffset
+	audioOffsets AudioOffsetStore
+	// Optional recorder timing the first segment of prequeued sessions
+	firstSegmentRecorder FirstSegmentRecorder
+	// Optional switch to a cached debrid release when a usenet source degrades
+	failover StreamFailover
+	// Tail of each session's ffmpeg stderr, shared with the video handler
+	ffmpegLogs *ffmpegLogStore
+	// Completed transcodes reused by later sessions of the same release
+	transcodeCache *transcodeCache
 }
 
 // NewHLSManager creates a new HLS session manager
@@ -445,24 +640,57 @@ func NewHLSManager(baseDir, ffmpegPath, ffprobePath string, streamer streaming.P
 	}
 
 	manager := &HLSManager{
-		sessions:    make(map[string]*HLSSession),
-		baseDir:     baseDir,
-		ffmpegPath:  ffmpegPath,
-		ffprobePath: ffprobePath,
-		streamer:    streamer,
-		cleanupDone: make(chan struct{}),
-		probeCache:  make(map[string]*cachedProbeEntry),
-	}
+		sessions:     make(map[string]*HLSSession),
+		baseDir:      baseDir,
+		ffmpegPath:   ffmpegPath,
+		ffprobePath:  ffprobePath,
+		streamer:     streamer,
+		cleanupDone:  make(chan struct{}),
+		probeCache:   make(map[string]*cachedProbeEntry),
+		fingerprints: newSourceFingerprints(streamer),
+		keyframes:    newKeyframeIndexCache(),
+		ffmpegLogs:   newFFmpegLogStore(),
+	}
+	manager.transcodeCache = newTranscodeCache(filepath.Join(baseDir, transcodeCacheDirName))
 
 	// Clean up any orphaned directories from previous runs
 	manager.cleanupOrphanedDirectories()
 
+	// Let disk space checks reclaim idle sessions before refusing new work
+	diskspace.RegisterEvictor("hls-sessions", manager.evictIdleSessions)
+	diskspace.RegisterEvictor("hls-transcode-cache", manager.transcodeCache.evictForSpace)
+
 	// Start cleanup goroutine
 	go manager.cleanupLoop()
 
 	return manager
 }
 
+// SetFailureRecorder sets the recorder used to aggregate probe/transcode failures
+func (m *HLSManager) SetFailureRecorder(recorder MediaFailureRecorder) {
+	m.failureRecorder = recorder
+}
+
+// SetFirstSegmentRecorder sets the recorder notified when a session serves its first segment
+func (m *HLSManager) SetFirstSegmentRecorder(recorder FirstSegmentRecorder) {
+	m.firstSegmentRecorder = recorder
+}
+
+// recordTranscodeFailure records an FFmpeg failure for a session using its cached probe data
+func (m *HLSManager) recordTranscodeFailure(session *HLSSession, err error) {
+	if m.failureRecorder == nil {
+		return
+	}
+	session.mu.RLock()
+	streamPath := session.Path
+	videoCodec := ""
+	if session.ProbeData != nil {
+		videoCodec = session.ProbeData.VideoCodec
+	}
+	session.mu.RUnlock()
+	recordMediaFailure(m.failureRecorder, models.MediaFailureStageTranscode, streamPath, videoCodec, err)
+}
+
 // ConfigureLocalWebDAVAccess allows the manager to build direct URLs against the local WebDAV server.
 // baseURL should be something like http://127.0.0.1:7777. prefix is the configured WebDAV prefix (e.g., /webdav).
 func (m *HLSManager) ConfigureLocalWebDAVAccess(baseURL, prefix, username, password string) {
@@ -699,10 +927,13 @@ func (m *HLSManager) buildLocalWebDAVURLFromPath(path string) (string, bool) {
 }
 
 // CreateSession starts a new HLS transcoding session
-func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPath string, hasDV bool, dvProfile string, hasHDR bool, forceAAC bool, startOffset float64, transcodingOffset float64, audioTrackIndex int, subtitleTrackIndex int, profileID string, profileName string, clientIP string, prequeueType string) (*HLSSession, error) {
+func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPath string, hasDV bool, dvProfile string, hasHDR bool, hdr10PlusPassthrough bool, forceAAC bool, videoPolicy HLSVideoPolicy, startOffset float64, transcodingOffset float64, audioTrackIndex int, subtitleTrackIndex int, profileID string, profileName string, clientIP string, prequeueType string) (*HLSSession, error) {
 	sessionID := generateSessionID()
 	outputDir := filepath.Join(m.baseDir, sessionID)
 
+	if err := diskspace.Check(ctx, diskspace.PurposeHLS, m.baseDir, 0); err != nil {
+		return nil, err
+	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return nil, fmt.Errorf("create session directory: %w", err)
 	}
@@ -753,9 +984,22 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 			}
 		} else if err != nil {
 			log.Printf("[hls] failed unified probe for session %s: %v", sessionID, err)
+			recordMediaFailure(m.failureRecorder, models.MediaFailureStageProbe, path, "", err)
 		}
 	}
 
+	videoHeight := 0
+	if probeData != nil {
+		videoHeight = probeData.VideoHeight
+	}
+	if videoPolicy.refusesHDR(videoHeight, hasDV || hasHDR) {
+		log.Printf("[hls] session %s: refusing HDR source (height=%d) for device with sdrOnly=%v maxHeight=%d",
+			sessionID, videoHeight, videoPolicy.SDROnly, videoPolicy.MaxHeight)
+		cancel()
+		_ = os.RemoveAll(outputDir)
+		return nil, ErrHDRExceedsDeviceCaps
+	}
+
 	if math.IsNaN(startOffset) || math.IsInf(startOffset, 0) || startOffset < 0 {
 		startOffset = 0
 	}
@@ -769,6 +1013,17 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 	actualTranscodingOffset := startOffset
 	if transcodingOffset > 0 {
 		actualTranscodingOffset = transcodingOffset
+	} else if startOffset > 0 {
+		// Resume on the preceding keyframe so copied video never starts mid-GOP
+		if kf, ok := m.snapToKeyframe(ctx, path, startOffset); ok {
+			actualTranscodingOffset = kf
+		}
+	}
+
+	// Turn off what the installed ffmpeg can't do instead of failing mid-transcode
+	dvUnsupported := hasDV && !mediatools.DolbyVisionMuxing()
+	if dvUnsupported {
+		log.Printf("[hls] session %s: ffmpeg %s cannot write Dolby Vision tracks, playing the HDR10 base layer", sessionID, mediatools.Current().FFmpegVersion)
 	}
 
 	session := &HLSSession{
@@ -782,6 +1037,8 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 		HasDV:               hasDV,
 		DVProfile:           dvProfile,
 		HasHDR:              hasHDR,
+		DVDisabled:          dvUnsupported,
+		HDRMetadataDisabled: !mediatools.HEVCMetadata(),
 		Duration:            duration,
 		StartOffset:         startOffset,
 		TranscodingOffset:   actualTranscodingOffset, // May differ from StartOffset if keyframe-aligned
@@ -799,9 +1056,22 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 		LastSegmentServed:       -1,  // Initialize to -1 (no segments served yet)
 		EarliestBufferedSegment: -1,  // Initialize to -1 (no buffer info reported yet)
 		ProbeData:               probeData, // Cache unified probe results for startTranscoding
+		HasHDR10Plus:            probeData != nil && probeData.HasHDR10Plus,
+		HDR10PlusPassthrough:    hdr10PlusPassthrough && probeData != nil && probeData.HasHDR10Plus,
+		VideoPolicy:             videoPolicy,
+		devicePolicy:            videoPolicy,
 		PrequeueType:            prequeueType, // "", "details", or "next_episode"
 	}
 
+	// A release transcoded before with the same settings is served from the cache
+	if m.restoreCachedTranscode(ctx, session, forceAAC) {
+		cancel()
+		m.mu.Lock()
+		m.sessions[sessionID] = session
+		m.mu.Unlock()
+		return session, nil
+	}
+
 	m.mu.Lock()
 	m.sessions[sessionID] = session
 	m.mu.Unlock()
@@ -810,6 +1080,7 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 	go func() {
 		if err := m.startTranscoding(bgCtx, session, forceAAC); err != nil {
 			log.Printf("[hls] session %s transcoding failed: %v", sessionID, err)
+			m.recordTranscodeFailure(session, err)
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
@@ -834,6 +1105,9 @@ func (m *HLSManager) CreateLiveSession(ctx context.Context, liveURL string) (*HL
 	sessionID := generateSessionID()
 	outputDir := filepath.Join(m.baseDir, sessionID)
 
+	if err := diskspace.Check(ctx, diskspace.PurposeHLS, m.baseDir, 0); err != nil {
+		return nil, err
+	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return nil, fmt.Errorf("create session directory: %w", err)
 	}
@@ -872,6 +1146,7 @@ func (m *HLSManager) CreateLiveSession(ctx context.Context, liveURL string) (*HL
 	go func() {
 		if err := m.startLiveTranscoding(bgCtx, session); err != nil {
 			log.Printf("[hls] live session %s transcoding failed: %v", sessionID, err)
+			recordMediaFailure(m.failureRecorder, models.MediaFailureStageTranscode, liveURL, "", err)
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
@@ -889,6 +1164,13 @@ func (m *HLSManager) startLiveTranscoding(ctx context.Context, session *HLSSessi
 	playlistPath := filepath.Join(session.OutputDir, "stream.m3u8")
 	segmentPattern := filepath.Join(session.OutputDir, "segment%d.ts")
 
+	// Multi-audio channels (e.g. multi-language sports feeds) get an HLS audio
+	// group the player can switch between; others keep the first audio stream
+	audioTracks := m.probeLiveAudioTracks(ctx, session.Path)
+	session.mu.Lock()
+	session.LiveAudioTracks = audioTracks
+	session.mu.Unlock()
+
 	// Build FFmpeg args optimized for live input
 	args := []string{
 		"-nostdin",
@@ -900,24 +1182,31 @@ func (m *HLSManager) startLiveTranscoding(ctx context.Context, session *HLSSessi
 		"-reconnect_streamed", "1",
 		"-reconnect_delay_max", "3",
 		"-i", session.Path,
-		// Output options - copy video, transcode audio to AAC for compatibility
-		"-c:v", "copy",
-		"-c:a", "aac",
-		"-ac", "2",
-		"-b:a", "128k",
-		"-ar", "48000",
-		// HLS output
-		"-f", "hls",
-		"-hls_time", "2",
-		"-hls_list_size", "10", // Keep last 10 segments for live
-		"-hls_flags", "delete_segments+append_list",
-		"-hls_segment_filename", segmentPattern,
-		playlistPath,
+	}
+	if len(audioTracks) > 0 {
+		log.Printf("[hls] live session %s: exposing %d audio tracks as an HLS audio group", session.ID, len(audioTracks))
+		args = append(args, liveAudioGroupArgs(session.OutputDir, audioTracks)...)
+	} else {
+		args = append(args,
+			// Output options - copy video, transcode audio to AAC for compatibility
+			"-c:v", "copy",
+			"-c:a", "aac",
+			"-ac", "2",
+			"-b:a", "128k",
+			"-ar", "48000",
+			// HLS output
+			"-f", "hls",
+			"-hls_time", "2",
+			"-hls_list_size", "10", // Keep last 10 segments for live
+			"-hls_flags", "delete_segments+append_list",
+			"-hls_segment_filename", segmentPattern,
+			playlistPath,
+		)
 	}
 
 	log.Printf("[hls] live session %s: starting FFmpeg with args: %v", session.ID, args)
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, m.ffmpegPath, args...)
 	cmd.Dir = session.OutputDir
 
 	// Capture stderr for logging
@@ -939,6 +1228,7 @@ func (m *HLSManager) startLiveTranscoding(ctx context.Context, session *HLSSessi
 	session.mu.Unlock()
 
 	log.Printf("[hls] live session %s: FFmpeg started (PID=%d)", session.ID, cmd.Process.Pid)
+	ffmpegLog := m.ffmpegLogs.open(session.ID, "live", session.Path, args)
 
 	// Log stderr in background
 	go func() {
@@ -946,6 +1236,7 @@ func (m *HLSManager) startLiveTranscoding(ctx context.Context, session *HLSSessi
 		for {
 			n, err := stderr.Read(buf)
 			if n > 0 {
+				ffmpegLog.Write(buf[:n])
 				log.Printf("[hls] live session %s: FFmpeg: %s", session.ID, string(buf[:n]))
 			}
 			if err != nil {
@@ -1005,6 +1296,7 @@ func (m *HLSManager) startLiveTranscoding(ctx context.Context, session *HLSSessi
 
 	// Wait for FFmpeg to complete
 	err = cmd.Wait()
+	ffmpegLog.finish(err)
 	if err != nil {
 		if ctx.Err() != nil {
 			log.Printf("[hls] live session %s: FFmpeg stopped (context cancelled)", session.ID)
@@ -1376,6 +1668,22 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		log.Printf("[hls] session %s: FFmpeg input set to pipe:0", session.ID)
 	}
 
+	// A remembered audio delay for this release reads audio from a second,
+	// time-shifted input of the same source. Pipes can only be read once.
+	audioInput := "0"
+	if offsetMs := releaseAudioOffset(m.audioOffsets, session.Path); offsetMs != 0 {
+		if proxyURL != "" {
+			if session.TranscodingOffset >= outputSeekThreshold {
+				args = append(args, "-noaccurate_seek", "-ss", fmt.Sprintf("%.3f", session.TranscodingOffset))
+			}
+			args = append(args, "-itsoffset", fmt.Sprintf("%.3f", float64(offsetMs)/1000), "-i", proxyURL)
+			audioInput = "1"
+			log.Printf("[hls] session %s: shifting audio by %dms", session.ID, offsetMs)
+		} else {
+			log.Printf("[hls] session %s: audio offset %dms not applied to piped input", session.ID, offsetMs)
+		}
+	}
+
 	// For OUTPUT seeking, add -ss after -i
 	if useOutputSeeking {
 		args = append(args, "-ss", fmt.Sprintf("%.3f", session.TranscodingOffset))
@@ -1422,13 +1730,13 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 				// Incompatible codec selected - we need to transcode it
 				log.Printf("[hls] session %s: requested audio track %d is %s (incompatible); will transcode to AAC", session.ID, session.AudioTrackIndex, selectedStream.Codec)
 				// Map by absolute stream index and transcode
-				audioMap := fmt.Sprintf("0:%d", selectedStream.Index)
+				audioMap := fmt.Sprintf("%s:%d", audioInput, selectedStream.Index)
 				args = append(args, "-map", audioMap)
 				mappedSpecificAudio = true
 			} else {
 				// Compatible codec selected - map it directly by absolute stream index
 				// This avoids issues with TrueHD filtering affecting relative indices
-				audioMap := fmt.Sprintf("0:%d", selectedStream.Index)
+				audioMap := fmt.Sprintf("%s:%d", audioInput, selectedStream.Index)
 				args = append(args, "-map", audioMap)
 				mappedSpecificAudio = true
 				log.Printf("[hls] session %s: mapping specific audio stream (streamIndex=%d codec=%s)",
@@ -1460,7 +1768,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// First pass: find compatible non-commentary track
 			for _, stream := range audioStreams {
 				if compatibleCodecs[stream.Codec] && !isHLSCommentaryTrack(stream.Title) {
-					audioMap := fmt.Sprintf("0:%d", stream.Index)
+					audioMap := fmt.Sprintf("%s:%d", audioInput, stream.Index)
 					args = append(args, "-map", audioMap)
 					log.Printf("[hls] session %s: mapped first compatible audio stream %d (codec=%s)",
 						session.ID, stream.Index, stream.Codec)
@@ -1472,7 +1780,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			if !mappedAudio {
 				for _, stream := range audioStreams {
 					if compatibleCodecs[stream.Codec] {
-						audioMap := fmt.Sprintf("0:%d", stream.Index)
+						audioMap := fmt.Sprintf("%s:%d", audioInput, stream.Index)
 						args = append(args, "-map", audioMap)
 						log.Printf("[hls] session %s: mapped compatible audio stream %d (codec=%s, fallback including commentary)",
 							session.ID, stream.Index, stream.Codec)
@@ -1482,7 +1790,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			}
 		} else {
 			// Map only the first audio stream
-			args = append(args, "-map", "0:a:0")
+			args = append(args, "-map", audioInput+":a:0")
 			log.Printf("[hls] session %s: no specific audio track selected, mapped first audio stream", session.ID)
 		}
 	}
@@ -1493,14 +1801,21 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	videoCodec := ""
 	if session.ProbeData != nil {
 		videoCodec = session.ProbeData.VideoCodec
-		needsVideoTranscode = IsIncompatibleVideoCodec(videoCodec)
+		videoHeight := session.ProbeData.VideoHeight
+		needsVideoTranscode = session.VideoPolicy.needsVideoTranscode(videoCodec, videoHeight, session.HasDV || session.HasHDR)
+		session.mu.Lock()
+		session.videoTranscoded = needsVideoTranscode
+		session.mu.Unlock()
+		if maxHeight := session.VideoPolicy.MaxHeight; maxHeight > 0 && videoHeight > maxHeight && !needsVideoTranscode {
+			log.Printf("[hls] session %s: %dp source exceeds the device's %dp cap but the device never transcodes, copying as is", session.ID, videoHeight, maxHeight)
+		}
 	}
 
 	if needsVideoTranscode {
 		// Transcode incompatible video codec to H.264
 		// Use ultrafast preset + zerolatency tune for fastest possible startup
 		// Quality is slightly lower than veryfast but startup is significantly faster
-		log.Printf("[hls] session %s: incompatible video codec %q detected, transcoding to H.264 (ultrafast)", session.ID, videoCodec)
+		log.Printf("[hls] session %s: video codec %q needs transcoding for this device, transcoding to H.264 (ultrafast)", session.ID, videoCodec)
 		args = append(args,
 			"-c:v", "libx264",
 			"-preset", "ultrafast",
@@ -1510,6 +1825,19 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			"-level", "4.1",
 			"-threads", "0", // Use all available CPU cores
 		)
+		if maxKbps := session.VideoPolicy.MaxBitrateKbps; maxKbps > 0 {
+			// Constrained CRF: quality-based encoding that never exceeds the device's cap
+			args = append(args,
+				"-maxrate", fmt.Sprintf("%dk", maxKbps),
+				"-bufsize", fmt.Sprintf("%dk", maxKbps*2),
+			)
+			log.Printf("[hls] session %s: capping video bitrate at %dkbps", session.ID, maxKbps)
+		}
+		if maxHeight := session.VideoPolicy.MaxHeight; maxHeight > 0 {
+			// Never upscale: sources already below the rung keep their resolution
+			args = append(args, "-vf", fmt.Sprintf("scale=-2:'min(ih,%d)'", maxHeight))
+			log.Printf("[hls] session %s: scaling video to at most %dp", session.ID, maxHeight)
+		}
 		// When transcoding video for fMP4, also check if audio needs transcoding
 		// MP3 audio doesn't work well in fMP4 containers on iOS - must use AAC
 		if len(audioStreams) > 0 && audioStreams[0].Codec == "mp3" {
@@ -1542,13 +1870,21 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		// and does NOT interfere with dvcC box generation (tested). This fixes sources with
 		// incorrect color metadata (e.g., bt709 instead of bt2020/PQ) which cause saturated colors.
 		// Do NOT use dovi_rpu filter as it DOES break dvcC generation.
-		args = append(args, "-strict", "unofficial", "-tag:v", dvTag, "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+		args = append(args, "-strict", "unofficial", "-tag:v", dvTag)
+		if mediatools.HEVCMetadata() {
+			args = append(args, "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+		}
 		log.Printf("[hls] session %s: using %s tag with fMP4 segments for Dolby Vision (profile: %s)", session.ID, dvTag, session.DVProfile)
 	} else if session.HasHDR || (session.HasDV && session.DVDisabled) {
 		// Also handles DV fallback - DV Profile 8 has HDR10 base layer that plays fine without DV metadata
 		segmentExt = ".m4s"
 		// Use hevc_metadata to ensure proper BT.2020/PQ color signaling for HDR10 content
-		if session.HDRMetadataDisabled {
+		if session.HDR10PlusPassthrough && !session.DVDisabled {
+			// HDR10+ capable client: copy the bitstream untouched so the SMPTE 2094-40
+			// SEI reaches the decoder byte-for-byte (source already signals BT.2020/PQ)
+			args = append(args, "-tag:v", "hvc1")
+			log.Printf("[hls] session %s: using hvc1 tag with fMP4 segments, HDR10+ passthrough", session.ID)
+		} else if session.HDRMetadataDisabled {
 			// Skip hevc_metadata filter if it failed previously (malformed SEI data)
 			// Stream will still play, just without explicit HDR color signaling in fMP4
 			args = append(args, "-tag:v", "hvc1")
@@ -1587,7 +1923,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 					log.Printf("[hls] session %s: transcoding selected %s track to AAC", session.ID, audioStreams[i].Codec)
 					args = append(args,
 						"-af", "aresample=async=1000",
-						"-c:a", "aac", "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
+						"-c:a", mediatools.AACEncoder(), "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
 					audioCodecHandled = true
 				}
 				break
@@ -1602,7 +1938,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// Use aresample filter with async for proper A/V sync during transcoding
 			args = append(args,
 				"-af", "aresample=async=1000",
-				"-c:a:0", "aac", "-ac:a:0", "6", "-ar:a:0", "48000", "-channel_layout:a:0", "5.1", "-b:a:0", "192k",
+				"-c:a:0", mediatools.AACEncoder(), "-ac:a:0", "6", "-ar:a:0", "48000", "-channel_layout:a:0", "5.1", "-b:a:0", "192k",
 				"-c:a:1", "copy")
 		} else if hasTrueHD && !hasCompatibleAudio {
 			// If only TrueHD exists, we must transcode it
@@ -1611,7 +1947,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			log.Printf("[hls] session %s: transcoding TrueHD to AAC (no compatible alternative)", session.ID)
 			args = append(args,
 				"-af", "aresample=async=1000",
-				"-c:a", "aac", "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
+				"-c:a", mediatools.AACEncoder(), "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
 		} else {
 			// Copy compatible audio
 			args = append(args, "-c:a", "copy")
@@ -1760,7 +2096,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	ffmpegSetupStart := time.Now()
 	log.Printf("[hls] session %s: starting FFmpeg with args: %v", session.ID, args)
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, m.ffmpegPath, args...)
 	if usingPipe {
 		pipeReader := io.Reader(resp.Body)
 
@@ -1808,9 +2144,11 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	session.mu.Lock()
 	session.FFmpegCmd = cmd
 	session.FFmpegPID = cmd.Process.Pid
+	session.Paused = false // A new process starts running even if the previous one was suspended
 	session.mu.Unlock()
 
 	log.Printf("[hls] session %s: FFmpeg started (PID=%d) in %v", session.ID, cmd.Process.Pid, time.Since(ffmpegSetupStart))
+	ffmpegLog := m.ffmpegLogs.open(session.ID, "hls", session.Path, args)
 
 	// Channel to signal DV metadata parsing errors (only used when DV is enabled)
 	dvErrorCh := make(chan struct{}, 1)
@@ -1835,6 +2173,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			n, err := stderr.Read(buf)
 			if n > 0 {
 				msg := string(buf[:n])
+				ffmpegLog.Write(buf[:n])
 				log.Printf("[hls] session %s ffmpeg stderr (t+%.1fs): %s",
 					session.ID, time.Since(startTime).Seconds(), msg)
 
@@ -2167,6 +2506,9 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		for {
 			select {
 			case <-ticker.C:
+				// Suspend FFmpeg while the client has been paused for a while
+				suspendIfClientPaused(session)
+
 				session.mu.RLock()
 				maxRequested := session.MaxSegmentRequested
 				completed := session.Completed
@@ -2229,7 +2571,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 				pid := session.FFmpegPID
 				session.mu.RUnlock()
 				if paused && pid > 0 {
-					_ = syscall.Kill(pid, syscall.SIGCONT)
+					_ = sandbox.SignalTree(pid, syscall.SIGCONT)
 					session.mu.Lock()
 					session.Paused = false
 					session.mu.Unlock()
@@ -2245,6 +2587,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	waitStart := time.Now()
 	err = cmd.Wait()
 	waitDuration := time.Since(waitStart)
+	ffmpegLog.finish(err)
 
 	// Clean up throttling proxy if we used one
 	if proxy != nil {
@@ -2325,6 +2668,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 
 		// Restart transcoding - DVDisabled is already set to true
 		log.Printf("[hls] session %s: restarting transcoding with DV disabled (will use HDR10 base layer)", session.ID)
+		m.abandonTranscodeCapture(session)
 		return m.startTranscoding(ctx, session, forceAAC)
 	}
 
@@ -2356,6 +2700,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 
 		// Restart transcoding - HDRMetadataDisabled is already set to true
 		log.Printf("[hls] session %s: restarting transcoding without hevc_metadata filter (stream will still play, but may lack proper HDR color signaling)", session.ID)
+		m.abandonTranscodeCapture(session)
 		return m.startTranscoding(ctx, session, forceAAC)
 	}
 
@@ -2366,6 +2711,11 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	cachedForceAAC := session.forceAAC
 	session.mu.RUnlock()
 
+	// A usenet release that keeps failing is likely missing articles; move to debrid if possible
+	if inputErrorDetected && inputWasErrored && m.failoverToDebrid(session) {
+		return nil
+	}
+
 	if inputErrorDetected && inputWasErrored && recoveryAttempts < hlsMaxRecoveryAttempts {
 		// Find the highest segment number to calculate where to resume
 		highestSegment := m.findHighestSegmentNumber(session)
@@ -2424,6 +2774,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		// Subtitles will be re-extracted from TranscodingOffset (same as seek behavior)
 		log.Printf("[hls] session %s: restarting transcoding from %.2fs after input error (recovery attempt %d/%d)",
 			session.ID, newTranscodingOffset, recoveryAttempts+1, hlsMaxRecoveryAttempts)
+		m.abandonTranscodeCapture(session)
 		return m.startTranscoding(newCtx, session, cachedForceAAC)
 	}
 
@@ -2523,6 +2874,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// Subtitles will be re-extracted from TranscodingOffset (same as seek behavior)
 			log.Printf("[hls] session %s: restarting transcoding from %.2fs after premature completion (recovery attempt %d/%d)",
 				session.ID, newTranscodingOffset, recoveryAttempts+1, hlsMaxRecoveryAttempts)
+			m.abandonTranscodeCapture(session)
 			return m.startTranscoding(newCtx, session, cachedForceAAC)
 		}
 		log.Printf("[hls] session %s: premature completion recovery exhausted (%d/%d attempts)",
@@ -2535,6 +2887,11 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		log.Printf("[hls] session %s: transcoding completed successfully in %v (bytes streamed: %d, segments: %d)",
 			session.ID, completionTime, session.BytesStreamed, session.SegmentsCreated)
 	}
+
+	// A clean run over the whole release can serve later sessions
+	if err == nil && !idleTriggered {
+		m.finishTranscodeCapture(session)
+	}
 	return nil
 }
 
@@ -2616,6 +2973,27 @@ func (m *HLSManager) GetSession(sessionID string) (*HLSSession, bool) {
 	return session, exists
 }
 
+// ActiveSessionCount returns the number of sessions accessed within idle.
+// Used by maintenance mode to decide when HLS playback has drained.
+func (m *HLSManager) ActiveSessionCount(idle time.Duration) int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cutoff := time.Now().Add(-idle)
+	count := 0
+	for _, session := range m.sessions {
+		session.mu.RLock()
+		if session.LastAccess.After(cutoff) {
+			count++
+		}
+		session.mu.RUnlock()
+	}
+	return count
+}
+
 // KeepAlive updates the last activity time for a session to prevent idle timeout
 // This is used by the frontend to keep paused streams alive
 // Optional query param: time=<seconds> to report current playback position for rate limiting
@@ -2696,7 +3074,6 @@ func (m *HLSManager) KeepAlive(w http.ResponseWriter, r *http.Request, sessionID
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
@@ -2748,11 +3125,189 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 
 	log.Printf("[hls] session %s: seek requested to %.2fs (current offset: %.2fs)", sessionID, targetTime, session.StartOffset)
 
+	actualStart := m.restartTranscodingAt(session, targetTime, "seek")
+	m.waitForPlaylist(session)
+
+	// Build playlist URL (without /api/ prefix - frontend adds it)
+	playlistURL := fmt.Sprintf("/video/hls/%s/stream.m3u8", sessionID)
+
+	// NOTE: Since we use -start_at_zero, the fMP4 tfdt box contains 0 (not the actual
+	// keyframe position), so parsing it would give wrong results. Instead, we report the
+	// keyframe the restart was snapped to, or targetTime when no keyframe was found within
+	// the bounded scan; FFmpeg's own keyframe may then differ by a few hundred milliseconds.
+	log.Printf("[hls] session %s: seek completed, requested=%.2fs transcoding from %.2fs",
+		sessionID, targetTime, actualStart)
+
+	response := SeekResponse{
+		SessionID:         sessionID,
+		StartOffset:       targetTime,
+		ActualStartOffset: actualStart,
+		KeyframeDelta:     actualStart - targetTime,
+		Duration:          duration,
+		PlaylistURL:       playlistURL,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// QualityRequest is the body of a mid-session quality change.
+// Either Direction ("down"/"up") or Height selects the new rung.
+type QualityRequest struct {
+	Direction string   `json:"direction,omitempty"`
+	Height    int      `json:"height,omitempty"`
+	Time      *float64 `json:"time,omitempty"` // Current playback position in absolute media time
+}
+
+// QualityResponse reports the restarted session and the rung it now plays at
+type QualityResponse struct {
+	SeekResponse
+	Height         int  `json:"height"`         // 0 = source quality
+	MaxBitrateKbps int  `json:"maxBitrateKbps"` // 0 = uncapped
+	Transcoding    bool `json:"transcoding"`
+}
+
+// AdjustQuality restarts an HLS session at a different quality ladder rung, resuming from
+// the current playback position. Clients call this when they detect sustained buffering
+// instead of tearing down the session and starting a new one.
+func (m *HLSManager) AdjustQuality(w http.ResponseWriter, r *http.Request, sessionID string) {
+	session, exists := m.GetSession(sessionID)
+	if !exists {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	var req QualityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Direction = strings.ToLower(strings.TrimSpace(req.Direction))
+	if req.Direction == "" && req.Height <= 0 {
+		http.Error(w, "direction or height is required", http.StatusBadRequest)
+		return
+	}
+	if req.Height <= 0 && req.Direction != "down" && req.Direction != "up" {
+		http.Error(w, `direction must be "down" or "up"`, http.StatusBadRequest)
+		return
+	}
+	if req.Time != nil && *req.Time < 0 {
+		http.Error(w, "invalid time", http.StatusBadRequest)
+		return
+	}
+
+	session.mu.RLock()
+	isLive := session.IsLive
+	isHDR := session.HasDV || session.HasHDR
+	devicePolicy := session.devicePolicy
+	current := session.QualityRung
+	duration := session.Duration
+	// Fall back to the last keepalive-reported segment when the client doesn't send its position
+	targetTime := session.StartOffset
+	if session.LastPlaybackSegment > 0 {
+		targetTime += float64(session.LastPlaybackSegment) * hlsSegmentDuration
+	}
+	sourceHeight := 0
+	if session.ProbeData != nil {
+		sourceHeight = session.ProbeData.VideoHeight
+	}
+	session.mu.RUnlock()
+
+	if isLive {
+		http.Error(w, "quality changes are not supported for live streams", http.StatusConflict)
+		return
+	}
+
+	var rung HLSQualityRung
+	var ok bool
+	if req.Height > 0 {
+		rung, ok = qualityRungForHeight(req.Height, sourceHeight)
+	} else {
+		rung, ok = nextQualityRung(current, sourceHeight, req.Direction)
+	}
+	if !ok {
+		http.Error(w, "no quality rung available in that direction", http.StatusConflict)
+		return
+	}
+	if rung.Height > 0 {
+		// Re-encoding is the only way to lower quality; the pipeline can't tone map HDR
+		// and devices set to never transcode must keep the source bitstream
+		if isHDR {
+			http.Error(w, "quality changes are not available for HDR sources", http.StatusConflict)
+			return
+		}
+		if devicePolicy.NeverTranscode {
+			http.Error(w, "transcoding is disabled for this device", http.StatusConflict)
+			return
+		}
+	}
+
+	if req.Time != nil {
+		targetTime = *req.Time
+	}
+	if duration > 0 && targetTime >= duration {
+		targetTime = duration - 1
+	}
+	if targetTime < 0 {
+		targetTime = 0
+	}
+
+	policy := devicePolicy.withQualityRung(rung)
+	log.Printf("[hls] session %s: quality change requested (direction=%q height=%d): %dp -> %dp at %.2fs, max bitrate %dkbps",
+		sessionID, req.Direction, req.Height, current.Height, rung.Height, targetTime, policy.MaxBitrateKbps)
+
+	session.mu.Lock()
+	session.QualityRung = rung
+	session.VideoPolicy = policy
+	session.mu.Unlock()
+
+	actualStart := m.restartTranscodingAt(session, targetTime, "quality change")
+	m.waitForPlaylist(session)
+
+	videoCodec := ""
+	if session.ProbeData != nil {
+		videoCodec = session.ProbeData.VideoCodec
+	}
+
+	response := QualityResponse{
+		SeekResponse: SeekResponse{
+			SessionID:         sessionID,
+			StartOffset:       targetTime,
+			ActualStartOffset: actualStart,
+			KeyframeDelta:     actualStart - targetTime,
+			Duration:          duration,
+			PlaylistURL:       fmt.Sprintf("/video/hls/%s/stream.m3u8", sessionID),
+		},
+		Height:         rung.Height,
+		MaxBitrateKbps: policy.MaxBitrateKbps,
+		Transcoding:    policy.needsVideoTranscode(videoCodec, sourceHeight, isHDR),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// restartTranscodingAt stops the session's FFmpeg process, clears its segments and
+// starts transcoding again from targetTime. Shared by seeks and quality changes.
+// Returns the offset transcoding restarted from: the keyframe preceding
+// targetTime when one is known, otherwise targetTime.
+func (m *HLSManager) restartTranscodingAt(session *HLSSession, targetTime float64, reason string) float64 {
+	sessionID := session.ID
+
+	// Snap to the preceding keyframe while the old stream keeps playing, so
+	// the restart doesn't begin mid-GOP
+	session.mu.RLock()
+	snapPath := session.Path
+	session.mu.RUnlock()
+	transcodingOffset, snapped := m.snapToKeyframe(context.Background(), snapPath, targetTime)
+
 	// Mark seek in progress to prevent recovery logic from triggering
 	session.mu.Lock()
 	session.SeekInProgress = true
 	if session.Cancel != nil {
-		log.Printf("[hls] session %s: cancelling current transcoding for seek", sessionID)
+		log.Printf("[hls] session %s: cancelling current transcoding for %s", sessionID, reason)
 		session.Cancel()
 	}
 	session.mu.Unlock()
@@ -2762,22 +3317,23 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 
 	// Clear all existing segments since they're at the old time offset
 	if err := m.clearSessionSegments(session); err != nil {
-		log.Printf("[hls] session %s: warning: failed to clear segments for seek: %v", sessionID, err)
+		log.Printf("[hls] session %s: warning: failed to clear segments for %s: %v", sessionID, reason, err)
 	}
+	m.abandonTranscodeCapture(session)
 
-	// Skip keyframe probing - FFmpeg will find the nearest keyframe automatically with -noaccurate_seek
+	// Without a known keyframe, FFmpeg finds the nearest one itself with -noaccurate_seek.
 	// Since subtitles are extracted in the same FFmpeg pipeline with the same -ss, they'll be in sync.
 	// Actual start offset will be parsed from fMP4 tfdt box after first segment is ready.
-	log.Printf("[hls] session %s: seek to %.3fs (skipping keyframe probe for faster seek)", sessionID, targetTime)
+	log.Printf("[hls] session %s: %s to %.3fs (transcoding from %.3fs, keyframe snapped=%v)", sessionID, reason, targetTime, transcodingOffset, snapped)
 
-	// Reset session state for the new seek position
+	// Reset session state for the new position
 	session.mu.Lock()
 	session.FFmpegCmd = nil
 	session.FFmpegPID = 0
 	session.Completed = false
-	session.StartOffset = targetTime       // User's new position (for frontend display)
-	session.TranscodingOffset = targetTime // FFmpeg will seek to nearest keyframe
-	session.ActualStartOffset = targetTime // Will be updated from fMP4 tfdt after first segment
+	session.StartOffset = targetTime              // User's new position (for frontend display)
+	session.TranscodingOffset = transcodingOffset // Preceding keyframe when known, else FFmpeg seeks to the nearest one
+	session.ActualStartOffset = transcodingOffset // Will be updated from fMP4 tfdt after first segment
 	session.CreatedAt = time.Now()
 	session.LastSegmentRequest = time.Now()
 	session.SegmentsCreated = 0
@@ -2787,6 +3343,10 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 	session.EarliestBufferedSegment = 0
 	session.RecoveryAttempts = 0 // Reset recovery attempts for new seek position
 	session.SeekInProgress = false // Clear seek flag now that we're starting fresh
+	if session.ClientPaused {
+		// Seeking while paused: let the new position buffer before suspending again
+		session.ClientPausedAt = time.Now()
+	}
 	cachedForceAAC := session.forceAAC
 	session.mu.Unlock()
 
@@ -2799,25 +3359,26 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 	// Start transcoding from the new offset in background
 	go func() {
 		if err := m.startTranscoding(newCtx, session, cachedForceAAC); err != nil {
-			log.Printf("[hls] session %s: seek transcoding failed: %v", sessionID, err)
+			log.Printf("[hls] session %s: %s transcoding failed: %v", sessionID, reason, err)
+			m.recordTranscodeFailure(session, err)
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
 		}
 	}()
 
+	if snapped {
+		return transcodingOffset
+	}
+
 	// Start background keyframe probe for subtitle sync correction
 	// This runs in parallel and updates ActualStartOffset when done
 	// Frontend will pick up the correction on next keepalive poll
-	session.mu.RLock()
-	sessionPath := session.Path
-	session.mu.RUnlock()
-
 	go func() {
 		probeCtx, probeCancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer probeCancel()
 
-		keyframePos := m.probeKeyframePosition(probeCtx, sessionPath, targetTime)
+		keyframePos := m.probeKeyframePosition(probeCtx, snapPath, targetTime)
 		delta := keyframePos - targetTime
 
 		session.mu.Lock()
@@ -2828,6 +3389,13 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 			sessionID, targetTime, keyframePos, delta)
 	}()
 
+	return transcodingOffset
+}
+
+// waitForPlaylist blocks until a restarted session has written a non-empty playlist
+func (m *HLSManager) waitForPlaylist(session *HLSSession) {
+	sessionID := session.ID
+
 	// Wait for the playlist file to be created before returning
 	// This prevents the player from trying to load a non-existent playlist
 	session.mu.RLock()
@@ -2855,31 +3423,6 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 
 		time.Sleep(pollInterval)
 	}
-
-	// Build playlist URL (without /api/ prefix - frontend adds it)
-	playlistURL := fmt.Sprintf("/video/hls/%s/stream.m3u8", sessionID)
-
-	// NOTE: We skip keyframe probing for faster seeks. Since we use -start_at_zero,
-	// the fMP4 tfdt box contains 0 (not the actual keyframe position), so parsing it
-	// would give wrong results. Instead, we report ActualStartOffset = targetTime
-	// and KeyframeDelta = 0. The actual keyframe position may differ by a few hundred
-	// milliseconds, but this is acceptable for subtitle sync and much faster than probing.
-	log.Printf("[hls] session %s: seek completed, requested=%.2fs (keyframe probe skipped for speed)",
-		sessionID, targetTime)
-
-	response := SeekResponse{
-		SessionID:         sessionID,
-		StartOffset:       targetTime,
-		ActualStartOffset: targetTime, // Use requested time (no probe = no exact keyframe info)
-		KeyframeDelta:     0,          // Unknown without probe, assume negligible
-		Duration:          duration,
-		PlaylistURL:       playlistURL,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
 }
 
 // clearSessionSegments removes all segment files from a session's output directory
@@ -2925,11 +3468,19 @@ type HLSSessionStatus struct {
 	Duration            float64 `json:"duration,omitempty"`
 	SegmentsCreated     int     `json:"segmentsCreated"`
 	MaxSegmentRequested int     `json:"maxSegmentRequested"` // Highest segment requested by player
-	Paused              bool    `json:"paused"`              // True if FFmpeg is paused (rate limited)
+	Paused              bool    `json:"paused"`              // True if FFmpeg is paused (rate limited or client idle)
+	ClientPaused        bool    `json:"clientPaused"`        // True while the client reports playback paused
 	BitstreamErrors     int     `json:"bitstreamErrors"`
 	HDRMetadataDisabled bool    `json:"hdrMetadataDisabled"`
 	DVDisabled          bool    `json:"dvDisabled"`
 	RecoveryAttempts    int     `json:"recoveryAttempts"`
+	SubtitleOffsetMs    int64   `json:"subtitleOffsetMs"` // Remembered subtitle delay for this release
+	AudioOffsetMs       int64   `json:"audioOffsetMs"`    // Remembered audio delay for this release
+	QualityHeight       int     `json:"qualityHeight"`    // Active quality rung height (0 = source quality)
+	SourceSwitch        *models.SourceSwitch `json:"sourceSwitch,omitempty"` // Set once playback moved to another source
+	SourceSwitches      int                  `json:"sourceSwitches"`         // Increments on every source switch
+	AudioTracks         []LiveAudioTrack     `json:"audioTracks,omitempty"`  // Audio group renditions of a multi-audio live session
+	FromCache           bool                 `json:"fromCache"`              // Served from the transcode cache instead of FFmpeg
 }
 
 // GetSessionStatus returns the current status of an HLS session
@@ -2947,10 +3498,18 @@ func (m *HLSManager) GetSessionStatus(w http.ResponseWriter, r *http.Request, se
 		SegmentsCreated:     session.SegmentsCreated,
 		MaxSegmentRequested: session.MaxSegmentRequested,
 		Paused:              session.Paused,
+		ClientPaused:        session.ClientPaused,
 		BitstreamErrors:     session.BitstreamErrors,
 		HDRMetadataDisabled: session.HDRMetadataDisabled,
 		DVDisabled:          session.DVDisabled,
 		RecoveryAttempts:    session.RecoveryAttempts,
+		SubtitleOffsetMs:    releaseSubtitleOffset(m.subtitleOffsets, session.Path),
+		AudioOffsetMs:       releaseAudioOffset(m.audioOffsets, session.Path),
+		QualityHeight:       session.QualityRung.Height,
+		SourceSwitch:        session.SourceSwitch,
+		SourceSwitches:      session.SourceSwitches,
+		AudioTracks:         session.LiveAudioTracks,
+		FromCache:           session.FromCache,
 	}
 
 	if session.FatalError != "" {
@@ -2965,7 +3524,6 @@ func (m *HLSManager) GetSessionStatus(w http.ResponseWriter, r *http.Request, se
 	session.mu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	if err := json.NewEncoder(w).Encode(status); err != nil {
 		log.Printf("[hls] session %s: failed to encode status response: %v", sessionID, err)
@@ -3021,19 +3579,19 @@ func (m *HLSManager) ServePlaylist(w http.ResponseWriter, r *http.Request, sessi
 	// Since we only delete segments the player has already watched (based on keepalive reports),
 	// the player won't request them anyway. If it does (e.g., seek back), it gets a 404 which is fine.
 
-	// Get auth token from request
-	authToken := r.URL.Query().Get("token")
-	if authToken == "" {
-		// Try Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			authToken = strings.TrimPrefix(authHeader, "Bearer ")
-		}
-	}
+	authToken := playlistAuthToken(r)
 
 	// Rewrite segment URLs to include auth token and inject HLS tags
 	playlistContent := string(content)
 
+	// Multi-audio live sessions serve a master playlist; name its renditions
+	session.mu.RLock()
+	liveAudioTracks := session.LiveAudioTracks
+	session.mu.RUnlock()
+	if len(liveAudioTracks) > 0 {
+		playlistContent = labelLiveAudioRenditions(playlistContent, liveAudioTracks)
+	}
+
 	// Build header tags to inject after #EXTM3U
 	var headerTags []string
 
@@ -3111,32 +3669,16 @@ func (m *HLSManager) ServePlaylist(w http.ResponseWriter, r *http.Request, sessi
 		playlistContent = strings.TrimRight(playlistContent, "\n") + "\n#EXT-X-ENDLIST\n"
 	}
 
-	if authToken != "" {
-		lines := strings.Split(playlistContent, "\n")
-		for i, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			// If line is a segment file (ends with .ts, .m4s, .vtt, or .webvtt)
-			if strings.HasSuffix(trimmed, ".ts") || strings.HasSuffix(trimmed, ".m4s") ||
-				strings.HasSuffix(trimmed, ".vtt") || strings.HasSuffix(trimmed, ".webvtt") {
-				// Append auth token as query parameter
-				lines[i] = line + "?token=" + authToken
-			} else if strings.Contains(line, "#EXT-X-MAP:URI=") {
-				// Rewrite init segment URL in EXT-X-MAP tag
-				// Format: #EXT-X-MAP:URI="init.mp4"
-				lines[i] = strings.Replace(line, `"init.mp4"`, `"init.mp4?token=`+authToken+`"`, 1)
-			} else if strings.Contains(line, "URI=") && (strings.Contains(line, ".vtt") || strings.Contains(line, ".webvtt")) {
-				// Rewrite subtitle URLs in #EXT-X-MEDIA tags
-				// Format: #EXT-X-MEDIA:TYPE=SUBTITLES,...,URI="subtitle.webvtt"
-				lines[i] = strings.ReplaceAll(line, ".vtt\"", ".vtt?token="+authToken+"\"")
-				lines[i] = strings.ReplaceAll(lines[i], ".webvtt\"", ".webvtt?token="+authToken+"\"")
-			}
-		}
-		playlistContent = strings.Join(lines, "\n")
+	playlistContent = addPlaylistAuthToken(playlistContent, authToken)
+
+	// With a configured base URL, point segments at it so the playlist still
+	// works when handed to an external player or fetched through another listener
+	if base := utils.ConfiguredBaseURL(); base != "" {
+		playlistContent = absolutePlaylistURIs(playlistContent, fmt.Sprintf("%s/api/video/hls/%s/", base, sessionID))
 	}
 
 	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
 	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Range, Content-Type")
 	w.Write([]byte(playlistContent))
@@ -3148,6 +3690,74 @@ func (m *HLSManager) ServePlaylist(w http.ResponseWriter, r *http.Request, sessi
 	log.Printf("[hls] served playlist for session %s, VIDEO-RANGE=%s, auth token=%v", sessionID, videoRange, authToken != "")
 }
 
+// playlistAuthToken returns the auth token of a playlist request, from the
+// token query parameter or the Authorization header.
+func playlistAuthToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	return ""
+}
+
+// addPlaylistAuthToken appends the auth token to the segment, init, subtitle
+// and variant playlist URIs of an HLS playlist.
+func addPlaylistAuthToken(playlist, authToken string) string {
+	if authToken == "" {
+		return playlist
+	}
+	lines := strings.Split(playlist, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		// If line is a segment file or variant playlist (ends with .ts, .m4s, .vtt, .webvtt or .m3u8)
+		if strings.HasSuffix(trimmed, ".ts") || strings.HasSuffix(trimmed, ".m4s") ||
+			strings.HasSuffix(trimmed, ".vtt") || strings.HasSuffix(trimmed, ".webvtt") ||
+			strings.HasSuffix(trimmed, ".m3u8") {
+			// Append auth token as query parameter
+			lines[i] = line + "?token=" + authToken
+		} else if strings.Contains(line, "#EXT-X-MAP:URI=") {
+			// Rewrite init segment URL in EXT-X-MAP tag
+			// Format: #EXT-X-MAP:URI="init.mp4"
+			lines[i] = strings.Replace(line, `"init.mp4"`, `"init.mp4?token=`+authToken+`"`, 1)
+		} else if strings.Contains(line, "URI=") && (strings.Contains(line, ".vtt") || strings.Contains(line, ".webvtt") || strings.Contains(line, ".m3u8")) {
+			// Rewrite subtitle and audio rendition URLs in #EXT-X-MEDIA tags
+			// Format: #EXT-X-MEDIA:TYPE=SUBTITLES,...,URI="subtitle.webvtt"
+			lines[i] = strings.ReplaceAll(line, ".vtt\"", ".vtt?token="+authToken+"\"")
+			lines[i] = strings.ReplaceAll(lines[i], ".webvtt\"", ".webvtt?token="+authToken+"\"")
+			lines[i] = strings.ReplaceAll(lines[i], ".m3u8\"", ".m3u8?token="+authToken+"\"")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// absolutePlaylistURIs prefixes the relative segment, init and rendition URIs
+// of an HLS playlist with prefix.
+func absolutePlaylistURIs(playlist, prefix string) string {
+	isRelative := func(uri string) bool {
+		return uri != "" && !strings.Contains(uri, "://") && !strings.HasPrefix(uri, "/")
+	}
+
+	lines := strings.Split(playlist, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			if isRelative(trimmed) {
+				lines[i] = prefix + trimmed
+			}
+			continue
+		}
+		if start := strings.Index(line, `URI="`); start != -1 {
+			start += len(`URI="`)
+			if end := strings.Index(line[start:], `"`); end != -1 && isRelative(line[start:start+end]) {
+				lines[i] = line[:start] + prefix + line[start:]
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ServeSegment serves an HLS segment file
 func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessionID, segmentName string) {
 	requestStart := time.Now()
@@ -3160,6 +3770,12 @@ func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessio
 		return
 	}
 
+	// Rendition playlists of a multi-audio live session share the segment route
+	if strings.HasSuffix(segmentName, ".m3u8") {
+		m.serveLiveVariantPlaylist(w, r, session, segmentName)
+		return
+	}
+
 	// Parse segment number from filename (e.g., "segment123.ts" -> 123)
 	var segmentNum int
 	if _, err := fmt.Sscanf(segmentName, "segment%d.", &segmentNum); err == nil {
@@ -3181,6 +3797,12 @@ func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessio
 	session.LastSegmentRequest = time.Now()
 	session.SegmentRequestCount++
 	requestCount := session.SegmentRequestCount
+	// A segment request means the player needs more data: resume instantly, and
+	// if it's only buffering while paused, restart the suspend delay
+	resumeTranscodeLocked(session, "segment requested")
+	if session.ClientPaused {
+		session.ClientPausedAt = time.Now()
+	}
 	session.mu.Unlock()
 
 	log.Printf("[hls] segment request #%d: session=%s segment=%s", requestCount, sessionID, segmentName)
@@ -3238,7 +3860,6 @@ func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessio
 
 	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Cache-Control", "public, max-age=31536000")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Accept-Ranges", "bytes")
 
 	// Set Content-Length explicitly for fMP4 segments (required by iOS/tvOS)
@@ -3261,6 +3882,10 @@ func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessio
 			session.LastSegmentServed = servedSegmentNum
 		}
 		session.mu.Unlock()
+
+		if m.firstSegmentRecorder != nil {
+			m.firstSegmentRecorder.FirstSegmentServed(sessionID)
+		}
 	}
 
 	totalDuration := time.Since(requestStart)
@@ -3324,7 +3949,6 @@ func (m *HLSManager) ServeSubtitles(w http.ResponseWriter, r *http.Request, sess
 				// Still not ready, return empty VTT
 				w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 				w.Header().Set("Cache-Control", "no-cache")
-				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Write([]byte("WEBVTT\n\n"))
 				return
 			}
@@ -3343,7 +3967,6 @@ func (m *HLSManager) ServeSubtitles(w http.ResponseWriter, r *http.Request, sess
 				// Return empty VTT instead of error to avoid breaking playback
 				w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 				w.Header().Set("Cache-Control", "no-cache")
-				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Write([]byte("WEBVTT\n\n"))
 				return
 			}
@@ -3360,7 +3983,6 @@ func (m *HLSManager) ServeSubtitles(w http.ResponseWriter, r *http.Request, sess
 		// This allows the frontend to poll without errors
 		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Write([]byte("WEBVTT\n\n"))
 		return
 	} else if err != nil {
@@ -3385,10 +4007,10 @@ func (m *HLSManager) ServeSubtitles(w http.ResponseWriter, r *http.Request, sess
 
 	// Post-process VTT to merge karaoke character cues (from ASS conversion)
 	processedContent := mergeKaraokeCues(string(content))
+	processedContent = shiftVTTCues(processedContent, releaseSubtitleOffset(m.subtitleOffsets, session.Path))
 
 	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache") // Don't cache since file is growing
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Length", strconv.Itoa(len(processedContent)))
 
 	w.Write([]byte(processedContent))
@@ -3490,7 +4112,7 @@ func (m *HLSManager) extractSubtitleTrackToVTT(session *HLSSession, trackIndex i
 		outputPath,
 	)
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, m.ffmpegPath, args...)
 
 	// Run extraction synchronously (should be fast for most subtitle tracks)
 	output, err := cmd.CombinedOutput()
@@ -3614,6 +4236,7 @@ func (m *HLSManager) CleanupSession(sessionID string) {
 	}
 	delete(m.sessions, sessionID)
 	m.mu.Unlock()
+	m.ffmpegLogs.release(sessionID)
 
 	// Log session summary
 	session.mu.RLock()
@@ -3656,6 +4279,8 @@ func (m *HLSManager) CleanupSession(sessionID string) {
 		session.Cancel()
 	}
 
+	m.abandonTranscodeCapture(session)
+
 	// Remove session directory with retry logic
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
@@ -3765,6 +4390,7 @@ func (m *HLSManager) deleteOldSegments(session *HLSSession, justServedSegment st
 	sessionID := session.ID
 	earliestBuffered := session.EarliestBufferedSegment
 	lastServedSegment := session.LastSegmentServed
+	capturing := session.cacheCapture != nil
 	session.mu.RUnlock()
 
 	// Use the minimum of EarliestBufferedSegment (from frontend) and LastSegmentServed (from backend)
@@ -3805,6 +4431,10 @@ func (m *HLSManager) deleteOldSegments(session *HLSSession, justServedSegment st
 	newMinAvailable := cutoff + 1
 	for i := 0; i <= cutoff; i++ {
 		oldSegment := filepath.Join(outputDir, fmt.Sprintf("segment%d%s", i, segmentExt))
+		if capturing {
+			// Keep the segment for the transcode cache
+			m.transcodeCache.capture(sessionID, oldSegment)
+		}
 		if err := os.Remove(oldSegment); err == nil {
 			deletedCount++
 		}
@@ -3835,7 +4465,7 @@ func (m *HLSManager) cleanupOrphanedDirectories() {
 
 	cleaned := 0
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || entry.Name() == transcodeCacheDirName {
 			continue
 		}
 
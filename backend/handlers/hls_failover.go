@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"novastream/models"
+	"novastream/services/indexer"
+	"novastream/services/stream_failover"
+)
+
+// hlsFailoverAfterRecoveries is how many usenet recoveries a session gets
+// before its release is treated as degraded. The first input error is often
+// a dropped connection that a plain restart fixes; a second one on the same
+// release usually means missing articles.
+const hlsFailoverAfterRecoveries = 1
+
+// StreamFailover finds a cached debrid release to replace a degraded usenet stream
+type StreamFailover interface {
+	Failover(ctx context.Context, streamPath string) (*stream_failover.Result, error)
+}
+
+var _ StreamFailover = (*stream_failover.Service)(nil)
+
+// streamFailoverRegistry remembers which title a usenet stream was resolved for
+type streamFailoverRegistry interface {
+	Register(streamPath string, opts indexer.SearchOptions, candidates []models.NZBResult, release string)
+}
+
+var _ streamFailoverRegistry = (*stream_failover.Service)(nil)
+
+// SetStreamFailover records the titles behind resolved usenet streams so
+// playback can later fail over to debrid
+func (h *PrequeueHandler) SetStreamFailover(registry streamFailoverRegistry) {
+	h.failoverTargets = registry
+}
+
+// SetStreamFailover enables switching degraded usenet HLS sessions to debrid
+func (h *VideoHandler) SetStreamFailover(failover StreamFailover) {
+	if h.hlsManager != nil {
+		h.hlsManager.failover = failover
+	}
+}
+
+// failoverToDebrid switches a usenet session whose input keeps failing to a
+// cached debrid release of the same title, resuming at the player's current
+// position. The switch is reported through the session status so clients can
+// reload the playlist the same way they do after a seek. It returns false
+// when the session isn't eligible or no cached release was found, leaving the
+// caller to fall back to usenet recovery.
+func (m *HLSManager) failoverToDebrid(session *HLSSession) bool {
+	if m.failover == nil {
+		return false
+	}
+
+	session.mu.RLock()
+	fromPath := session.Path
+	eligible := !session.IsLive && session.RecoveryAttempts >= hlsFailoverAfterRecoveries
+	position := session.StartOffset
+	if session.LastPlaybackSegment > 0 {
+		position += float64(session.LastPlaybackSegment) * hlsSegmentDuration
+	}
+	duration := session.Duration
+	session.mu.RUnlock()
+
+	if !eligible || mediaFailureProvider(fromPath) != "usenet" {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
+	result, err := m.failover.Failover(ctx, fromPath)
+	if err != nil {
+		if !errors.Is(err, stream_failover.ErrDisabled) && !errors.Is(err, stream_failover.ErrUnknownStream) {
+			log.Printf("[hls] session %s: debrid failover unavailable: %v", session.ID, err)
+		}
+		return false
+	}
+
+	if duration > 0 && position >= duration {
+		position = duration - 1
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	log.Printf("[hls] session %s: usenet release degraded, switching to debrid %q at %.2fs", session.ID, result.Release, position)
+
+	session.mu.Lock()
+	session.Path = result.Path
+	// The new release has its own streams; probe it again and let track
+	// selection fall back to its defaults
+	session.ProbeData = nil
+	session.AudioTrackIndex = -1
+	session.SubtitleTrackIndex = -1
+	session.InputErrorDetected = false
+	session.SourceSwitch = &models.SourceSwitch{
+		FromProvider: "usenet",
+		ToProvider:   mediaFailureProvider(result.Path),
+		FromRelease:  result.FromRelease,
+		ToRelease:    result.Release,
+		Position:     position,
+		SwitchedAt:   time.Now().UTC(),
+	}
+	session.SourceSwitches++
+	session.mu.Unlock()
+
+	m.restartTranscodingAt(session, position, "source switch")
+	return true
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/services/streaming"
+	"novastream/utils/sandbox"
+)
+
+const (
+	// keyframeScanWindow is how far before a resume position the packet scan
+	// looks for a keyframe; GOPs longer than this are left to ffmpeg
+	keyframeScanWindow = 12.0
+	// keyframeSnapTimeout bounds the scan so resumes and seeks stay fast
+	keyframeSnapTimeout = 5 * time.Second
+	// keyframeIndexMaxPaths bounds how many sources keep a keyframe index
+	keyframeIndexMaxPaths = 200
+)
+
+// keyframeIndex holds the video keyframe times found so far for one source and
+// the time ranges that were fully scanned, so a position inside a scanned range
+// snaps without another ffprobe run.
+type keyframeIndex struct {
+	times     []float64
+	spans     [][2]float64
+	updatedAt time.Time
+}
+
+// preceding returns the last keyframe at or before t when the range between
+// that keyframe and t has been scanned.
+func (idx *keyframeIndex) preceding(t float64) (float64, bool) {
+	i := sort.SearchFloat64s(idx.times, t+0.0005)
+	if i == 0 {
+		return 0, false
+	}
+	kf := idx.times[i-1]
+	for _, span := range idx.spans {
+		if span[0] <= kf && t <= span[1] {
+			return kf, true
+		}
+	}
+	return 0, false
+}
+
+func (idx *keyframeIndex) add(times []float64, start, end float64) {
+	merged := append(idx.times, times...)
+	sort.Float64s(merged)
+	idx.times = merged[:0]
+	for _, t := range merged {
+		if n := len(idx.times); n > 0 && t-idx.times[n-1] < 0.0005 {
+			continue
+		}
+		idx.times = append(idx.times, t)
+	}
+	idx.spans = append(idx.spans, [2]float64{start, end})
+	idx.updatedAt = time.Now()
+}
+
+// keyframeIndexCache keeps keyframe indexes per source path.
+type keyframeIndexCache struct {
+	mu      sync.Mutex
+	entries map[string]*keyframeIndex
+}
+
+func newKeyframeIndexCache() *keyframeIndexCache {
+	return &keyframeIndexCache{entries: make(map[string]*keyframeIndex)}
+}
+
+func (c *keyframeIndexCache) lookup(path string, t float64) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx, ok := c.entries[path]
+	if !ok || time.Since(idx.updatedAt) > probeCacheTTL {
+		return 0, false
+	}
+	return idx.preceding(t)
+}
+
+func (c *keyframeIndexCache) store(path string, times []float64, start, end float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx, ok := c.entries[path]
+	if !ok || time.Since(idx.updatedAt) > probeCacheTTL {
+		if !ok && len(c.entries) >= keyframeIndexMaxPaths {
+			c.evictOldestLocked()
+		}
+		idx = &keyframeIndex{}
+		c.entries[path] = idx
+	}
+	idx.add(times, start, end)
+}
+
+func (c *keyframeIndexCache) evictOldestLocked() {
+	var oldestPath string
+	var oldest time.Time
+	for path, idx := range c.entries {
+		if oldestPath == "" || idx.updatedAt.Before(oldest) {
+			oldestPath, oldest = path, idx.updatedAt
+		}
+	}
+	delete(c.entries, oldestPath)
+}
+
+// snapToKeyframe returns the last video keyframe at or before t, so sessions
+// and restarts begin on a clean GOP instead of mid-GOP. The keyframe comes from
+// the source's index, or from a packet scan of the window before t that is
+// added to the index. Reports false when no keyframe could be found.
+func (m *HLSManager) snapToKeyframe(ctx context.Context, path string, t float64) (float64, bool) {
+	if t <= 0 || m.keyframes == nil {
+		return t, false
+	}
+	if kf, ok := m.keyframes.lookup(path, t); ok {
+		return kf, true
+	}
+	if m.ffprobePath == "" {
+		return t, false
+	}
+
+	url := m.keyframeProbeURL(ctx, path)
+	if url == "" {
+		return t, false
+	}
+
+	scanCtx, cancel := context.WithTimeout(ctx, keyframeSnapTimeout)
+	defer cancel()
+
+	start := t - keyframeScanWindow
+	if start < 0 {
+		start = 0
+	}
+	times, err := m.scanKeyframes(scanCtx, url, start, t)
+	if err != nil {
+		log.Printf("[hls] keyframe scan %.3f-%.3fs failed for %s: %v", start, t, path, err)
+		return t, false
+	}
+
+	if len(times) == 0 {
+		return t, false
+	}
+	// The scanned range starts at the first keyframe found, since ffprobe
+	// seeks to the keyframe before the window start
+	m.keyframes.store(path, times, times[0], t)
+
+	if kf, ok := m.keyframes.lookup(path, t); ok {
+		log.Printf("[hls] snapped %.3fs to keyframe %.3fs for %s", t, kf, path)
+		return kf, true
+	}
+	return t, false
+}
+
+// scanKeyframes lists video keyframe times between start and end by reading
+// packet flags, which needs no decoding.
+func (m *HLSManager) scanKeyframes(ctx context.Context, url string, start, end float64) ([]float64, error) {
+	args := []string{
+		"-v", "error",
+		"-probesize", "1000000",
+		"-analyzeduration", "500000",
+		"-select_streams", "v:0",
+		"-read_intervals", fmt.Sprintf("%.3f%%%.3f", start, end+0.001),
+		"-show_entries", "packet=pts_time,flags",
+		"-of", "csv=p=0",
+		"-i", url,
+	}
+
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFprobe, m.ffprobePath, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyframePackets(string(output), end), nil
+}
+
+// parseKeyframePackets extracts keyframe times at or before end from ffprobe
+// "pts_time,flags" CSV lines, e.g. "12.345000,K__".
+func parseKeyframePackets(output string, end float64) []float64 {
+	var times []float64
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ",")
+		if len(parts) < 2 || !strings.HasPrefix(parts[1], "K") {
+			continue
+		}
+		pts, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil || pts > end+0.0005 {
+			continue
+		}
+		times = append(times, pts)
+	}
+	sort.Float64s(times)
+	return times
+}
+
+// keyframeProbeURL returns a seekable URL ffprobe can read for path, or "" if
+// the source can only be piped.
+func (m *HLSManager) keyframeProbeURL(ctx context.Context, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	if m.streamer != nil {
+		if directProvider, ok := m.streamer.(streaming.DirectURLProvider); ok {
+			if directURL, err := directProvider.GetDirectURL(ctx, path); err == nil && directURL != "" {
+				return directURL
+			}
+		}
+	}
+
+	if webdavURL, ok := m.buildLocalWebDAVURLFromPath(path); ok {
+		return webdavURL
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyframePackets(t *testing.T) {
+	output := "95.012000,K__\n95.054000,___\n97.014000,K_\n\n99.016000,K__\nbad,K__\n101.018000,K__\n"
+	got := parseKeyframePackets(output, 100)
+	want := []float64{95.012, 97.014, 99.016}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseKeyframePackets() = %v, want %v", got, want)
+	}
+}
+
+func TestKeyframeIndexSnapsWithinScannedRanges(t *testing.T) {
+	cache := newKeyframeIndexCache()
+	const path = "/usenet/movie.mkv"
+
+	if _, ok := cache.lookup(path, 100); ok {
+		t.Fatal("empty index snapped a position")
+	}
+
+	cache.store(path, []float64{95.012, 97.014, 99.016}, 95.012, 100)
+	tests := []struct {
+		t      float64
+		want   float64
+		wantOK bool
+	}{
+		{100, 99.016, true},
+		{99.016, 99.016, true},
+		{96.5, 95.012, true},
+		// Past the scanned range a later keyframe may exist
+		{104, 0, false},
+		// Before the first keyframe found
+		{94, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := cache.lookup(path, tt.t)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("lookup(%v) = %v, %v; want %v, %v", tt.t, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// A later scan extends the index and merges duplicate keyframes
+	cache.store(path, []float64{99.016, 101.018, 103.02}, 99.016, 104)
+	if got, ok := cache.lookup(path, 104); !ok || got != 103.02 {
+		t.Errorf("lookup(104) = %v, %v after extending the index", got, ok)
+	}
+	if n := len(cache.entries[path].times); n != 5 {
+		t.Errorf("index has %d keyframes, want 5", n)
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"novastream/utils"
+	"novastream/utils/sandbox"
+)
+
+const (
+	// liveAudioProbeTimeout bounds the audio probe of a live channel; when it
+	// fails the session falls back to the first audio stream.
+	liveAudioProbeTimeout = 15 * time.Second
+	// maxLiveAudioTracks caps the renditions encoded for one live channel, since
+	// each one is a separate AAC encode.
+	maxLiveAudioTracks = 8
+	// liveAudioGroup is the var_stream_map agroup; ffmpeg names it "group_audio".
+	liveAudioGroup = "audio"
+)
+
+// LiveAudioTrack is an audio rendition in a live session's HLS audio group.
+type LiveAudioTrack struct {
+	Index       int    `json:"index"`       // Rendition number in the audio group
+	StreamIndex int    `json:"streamIndex"` // ffprobe stream index in the live source
+	Language    string `json:"language,omitempty"`
+	Name        string `json:"name"`
+	Codec       string `json:"codec,omitempty"`
+	Default     bool   `json:"default"`
+}
+
+// probeLiveAudioTracks lists the audio streams of a live source. It returns
+// nil when the source has fewer than two audio streams or no video, in which
+// case the single-rendition output is used.
+func (m *HLSManager) probeLiveAudioTracks(ctx context.Context, url string) []LiveAudioTrack {
+	if m.ffprobePath == "" {
+		return nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, liveAudioProbeTimeout)
+	defer cancel()
+
+	args := []string{
+		"-v", "error",
+		"-probesize", "5000000",
+		"-analyzeduration", "2000000",
+		"-show_entries", "stream=index,codec_type,codec_name:stream_tags=language,title",
+		"-of", "json",
+		"-i", url,
+	}
+	output, err := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...).Output()
+	if err != nil {
+		log.Printf("[hls] live audio probe failed, using the first audio stream: %v", err)
+		return nil
+	}
+
+	var result struct {
+		Streams []struct {
+			Index     int               `json:"index"`
+			CodecType string            `json:"codec_type"`
+			CodecName string            `json:"codec_name"`
+			Tags      map[string]string `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		log.Printf("[hls] failed to parse live audio probe output: %v", err)
+		return nil
+	}
+
+	hasVideo := false
+	var streams []audioStreamInfo
+	for _, stream := range result.Streams {
+		switch stream.CodecType {
+		case "video":
+			hasVideo = true
+		case "audio":
+			streams = append(streams, audioStreamInfo{
+				Index:    stream.Index,
+				Codec:    strings.ToLower(strings.TrimSpace(stream.CodecName)),
+				Language: strings.TrimSpace(stream.Tags["language"]),
+				Title:    strings.TrimSpace(stream.Tags["title"]),
+			})
+		}
+	}
+	if !hasVideo {
+		return nil
+	}
+	return liveAudioTracks(streams)
+}
+
+// liveAudioTracks turns probed audio streams into uniquely named renditions,
+// the first one being the default. It returns nil for fewer than two streams.
+func liveAudioTracks(streams []audioStreamInfo) []LiveAudioTrack {
+	if len(streams) < 2 {
+		return nil
+	}
+	if len(streams) > maxLiveAudioTracks {
+		streams = streams[:maxLiveAudioTracks]
+	}
+
+	tracks := make([]LiveAudioTrack, 0, len(streams))
+	used := make(map[string]int, len(streams))
+	for i, stream := range streams {
+		language := strings.ToLower(stream.Language)
+		if language == "und" {
+			language = ""
+		}
+
+		name := stream.Title
+		if name == "" && language != "" {
+			name = strings.ToUpper(language)
+		}
+		if name == "" {
+			name = fmt.Sprintf("Audio %d", i+1)
+		}
+		// NAME must be unique within the group
+		name = strings.ReplaceAll(name, `"`, "'")
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, used[name])
+		}
+
+		tracks = append(tracks, LiveAudioTrack{
+			Index:       i,
+			StreamIndex: stream.Index,
+			Language:    language,
+			Name:        name,
+			Codec:       stream.Codec,
+			Default:     i == 0,
+		})
+	}
+	return tracks
+}
+
+// liveAudioGroupArgs returns the ffmpeg mapping and HLS output arguments that
+// write the video as one variant with every track as an audio rendition, plus
+// a stream.m3u8 master playlist tying them together.
+func liveAudioGroupArgs(outputDir string, tracks []LiveAudioTrack) []string {
+	args := []string{"-map", "0:v:0"}
+	for _, track := range tracks {
+		args = append(args, "-map", fmt.Sprintf("0:%d", track.StreamIndex))
+	}
+
+	// var_stream_map splits on spaces, commas and colons, so renditions get
+	// placeholder names that ServePlaylist replaces with the track names
+	streamMap := []string{"v:0,agroup:" + liveAudioGroup}
+	for _, track := range tracks {
+		entry := fmt.Sprintf("a:%d,agroup:%s,name:%s", track.Index, liveAudioGroup, liveAudioPlaceholder(track.Index))
+		if track.Language != "" {
+			entry += ",language:" + track.Language
+		}
+		if track.Default {
+			entry += ",default:yes"
+		}
+		streamMap = append(streamMap, entry)
+	}
+
+	return append(args,
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-ac", "2",
+		"-b:a", "128k",
+		"-ar", "48000",
+		"-f", "hls",
+		"-hls_time", "2",
+		"-hls_list_size", "10",
+		"-hls_flags", "delete_segments+append_list",
+		"-master_pl_name", "stream.m3u8",
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-hls_segment_filename", filepath.Join(outputDir, "track%v_segment%d.ts"),
+		filepath.Join(outputDir, "track%v.m3u8"),
+	)
+}
+
+func liveAudioPlaceholder(index int) string {
+	return fmt.Sprintf("audio_%d", index)
+}
+
+// labelLiveAudioRenditions swaps the placeholder rendition names in a master
+// playlist for the track names shown in players' audio menus.
+func labelLiveAudioRenditions(playlist string, tracks []LiveAudioTrack) string {
+	for _, track := range tracks {
+		playlist = strings.Replace(playlist,
+			`NAME="`+liveAudioPlaceholder(track.Index)+`"`,
+			`NAME="`+track.Name+`"`, 1)
+	}
+	return playlist
+}
+
+// serveLiveVariantPlaylist serves a per-rendition media playlist of a
+// multi-audio live session.
+func (m *HLSManager) serveLiveVariantPlaylist(w http.ResponseWriter, r *http.Request, session *HLSSession, name string) {
+	if strings.Contains(name, "..") || strings.Contains(name, "/") {
+		http.Error(w, "invalid playlist name", http.StatusBadRequest)
+		return
+	}
+
+	session.mu.Lock()
+	session.LastSegmentRequest = time.Now()
+	session.mu.Unlock()
+
+	content, err := os.ReadFile(filepath.Join(session.OutputDir, name))
+	if err != nil {
+		http.Error(w, "playlist not ready", http.StatusNotFound)
+		return
+	}
+
+	playlist := addPlaylistAuthToken(string(content), playlistAuthToken(r))
+	if base := utils.ConfiguredBaseURL(); base != "" {
+		playlist = absolutePlaylistURIs(playlist, fmt.Sprintf("%s/api/video/hls/%s/", base, session.ID))
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(playlist))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiveAudioTracksNamesRenditions(t *testing.T) {
+	if tracks := liveAudioTracks([]audioStreamInfo{{Index: 1, Codec: "aac"}}); tracks != nil {
+		t.Fatalf("expected no audio group for a single stream, got %+v", tracks)
+	}
+
+	tracks := liveAudioTracks([]audioStreamInfo{
+		{Index: 1, Codec: "mp2", Language: "eng"},
+		{Index: 2, Codec: "ac3", Language: "spa", Title: "Español"},
+		{Index: 3, Codec: "aac", Language: "eng"},
+		{Index: 4, Codec: "aac", Language: "und"},
+	})
+	if len(tracks) != 4 {
+		t.Fatalf("expected 4 tracks, got %+v", tracks)
+	}
+	names := []string{tracks[0].Name, tracks[1].Name, tracks[2].Name, tracks[3].Name}
+	if strings.Join(names, "|") != "ENG|Español|ENG (2)|Audio 4" {
+		t.Fatalf("unexpected rendition names %q", names)
+	}
+	if !tracks[0].Default || tracks[1].Default || tracks[3].Language != "" || tracks[2].StreamIndex != 3 {
+		t.Fatalf("unexpected tracks %+v", tracks)
+	}
+}
+
+func TestLiveAudioGroupArgs(t *testing.T) {
+	tracks := liveAudioTracks([]audioStreamInfo{
+		{Index: 1, Language: "eng"},
+		{Index: 3, Language: "fra", Title: "Français, commentaire"},
+	})
+	args := strings.Join(liveAudioGroupArgs("/tmp/live", tracks), " ")
+
+	for _, want := range []string{
+		"-map 0:v:0 -map 0:1 -map 0:3",
+		"-master_pl_name stream.m3u8",
+		"v:0,agroup:audio a:0,agroup:audio,name:audio_0,language:eng,default:yes a:1,agroup:audio,name:audio_1,language:fra",
+		"/tmp/live/track%v.m3u8",
+	} {
+		if !strings.Contains(args, want) {
+			t.Fatalf("expected %q in %s", want, args)
+		}
+	}
+
+	master := "#EXTM3U\n" +
+		`#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="group_audio",NAME="audio_0",LANGUAGE="eng",DEFAULT=YES,URI="track1.m3u8"` + "\n" +
+		`#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="group_audio",NAME="audio_1",LANGUAGE="fra",DEFAULT=NO,URI="track2.m3u8"` + "\n" +
+		`#EXT-X-STREAM-INF:BANDWIDTH=140800,AUDIO="group_audio"` + "\n" +
+		"track0.m3u8\n"
+	got := addPlaylistAuthToken(labelLiveAudioRenditions(master, tracks), "abc")
+	for _, want := range []string{`NAME="ENG"`, `NAME="Français, commentaire"`, `URI="track2.m3u8?token=abc"`, "track0.m3u8?token=abc"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"syscall"
+	"time"
+
+	"novastream/utils/sandbox"
+)
+
+// hlsClientPauseSuspendDelay is how long a client must stay paused before its
+// FFmpeg process is suspended. Short pauses keep transcoding so the buffer is
+// full when playback resumes.
+const hlsClientPauseSuspendDelay = 30 * time.Second
+
+// PausePlayback records that the client paused playback. The transcode keeps
+// running for hlsClientPauseSuspendDelay and is then suspended with SIGSTOP.
+func (m *HLSManager) PausePlayback(w http.ResponseWriter, r *http.Request, sessionID string) {
+	m.setClientPaused(w, sessionID, true)
+}
+
+// ResumePlayback records that the client resumed playback and continues a
+// suspended transcode immediately.
+func (m *HLSManager) ResumePlayback(w http.ResponseWriter, r *http.Request, sessionID string) {
+	m.setClientPaused(w, sessionID, false)
+}
+
+func (m *HLSManager) setClientPaused(w http.ResponseWriter, sessionID string, paused bool) {
+	session, exists := m.GetSession(sessionID)
+	if !exists {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	session.mu.Lock()
+	session.LastSegmentRequest = time.Now()
+	if paused {
+		if !session.ClientPaused {
+			session.ClientPaused = true
+			session.ClientPausedAt = time.Now()
+			log.Printf("[hls] session %s: client paused, FFmpeg will be suspended after %v", sessionID, hlsClientPauseSuspendDelay)
+		}
+	} else {
+		session.ClientPaused = false
+		resumeTranscodeLocked(session, "client resumed playback")
+	}
+	suspended := session.Paused
+	session.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "ok",
+		"paused":    paused,
+		"suspended": suspended,
+	})
+}
+
+// suspendIfClientPaused suspends FFmpeg once the client has been paused for
+// hlsClientPauseSuspendDelay. Live sessions are never suspended since the
+// source can't be paused. A suspended process may lose its upstream
+// connection; the input error recovery restarts it at the current position.
+func suspendIfClientPaused(session *HLSSession) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !session.ClientPaused || session.Paused || session.Completed || session.IsLive || session.FFmpegPID == 0 {
+		return
+	}
+	pausedFor := time.Since(session.ClientPausedAt)
+	if pausedFor < hlsClientPauseSuspendDelay {
+		return
+	}
+
+	if err := sandbox.SignalTree(session.FFmpegPID, syscall.SIGSTOP); err != nil {
+		log.Printf("[hls] session %s: failed to suspend FFmpeg (PID=%d): %v", session.ID, session.FFmpegPID, err)
+		return
+	}
+	session.Paused = true
+	log.Printf("[hls] session %s: AUTO_PAUSE - suspended FFmpeg (PID=%d) after client paused for %v",
+		session.ID, session.FFmpegPID, pausedFor.Round(time.Second))
+}
+
+// resumeTranscodeLocked continues a suspended FFmpeg process.
+// Must be called with session.mu held.
+func resumeTranscodeLocked(session *HLSSession, reason string) {
+	if !session.Paused {
+		return
+	}
+	session.Paused = false
+	if session.FFmpegPID == 0 {
+		return
+	}
+	if err := sandbox.SignalTree(session.FFmpegPID, syscall.SIGCONT); err != nil {
+		log.Printf("[hls] session %s: failed to resume FFmpeg (PID=%d): %v", session.ID, session.FFmpegPID, err)
+		return
+	}
+	log.Printf("[hls] session %s: AUTO_RESUME - resumed FFmpeg (PID=%d): %s", session.ID, session.FFmpegPID, reason)
+}
@@ -7,20 +7,22 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
 	"novastream/services/streaming"
+	"novastream/utils/accessibility"
+	"novastream/utils/sandbox"
 )
 
 // audioStreamInfo holds metadata for an audio stream
 type audioStreamInfo struct {
-	Index    int
-	Codec    string
-	Language string
-	Title    string
+	Index              int
+	Codec              string
+	Language           string
+	Title              string
+	IsAudioDescription bool
 }
 
 // subtitleStreamInfo holds metadata for a subtitle stream
@@ -31,6 +33,7 @@ type subtitleStreamInfo struct {
 	Title     string
 	IsForced  bool
 	IsDefault bool
+	IsSDH     bool
 }
 
 // isHLSCommentaryTrack checks if an audio track is a commentary track based on its title
@@ -60,6 +63,7 @@ type UnifiedProbeResult struct {
 	Duration           float64
 	ColorTransfer      string // e.g., "smpte2084" for HDR, "bt709" for SDR
 	VideoCodec         string // e.g., "h264", "hevc", "mpeg4" - used to detect incompatible codecs
+	VideoHeight        int    // Height of the first video stream in pixels (0 = unknown)
 	AudioStreams       []audioStreamInfo
 	SubtitleStreams    []subtitleStreamInfo
 	HasTrueHD          bool
@@ -67,13 +71,18 @@ type UnifiedProbeResult struct {
 	// Extended fields for VideoFullResult compatibility
 	HasDolbyVision     bool
 	HasHDR10           bool
+	HasHDR10Plus       bool
 	DolbyVisionProfile string
+
+	videoStreamIndex int // Index of the first video stream, for frame sampling
 }
 
-// cachedProbeEntry stores a probe result with expiration time
+// cachedProbeEntry stores a probe result with expiration time and the
+// fingerprint of the file it was probed from
 type cachedProbeEntry struct {
-	result    *UnifiedProbeResult
-	expiresAt time.Time
+	result      *UnifiedProbeResult
+	fingerprint string
+	expiresAt   time.Time
 }
 
 const (
@@ -82,12 +91,12 @@ const (
 	probeCacheTTL = 2 * time.Hour
 )
 
-// GetCachedProbe retrieves a cached probe result if available and not expired
-func (m *HLSManager) GetCachedProbe(path string) *UnifiedProbeResult {
+// GetCachedProbe retrieves a cached probe result if available and not expired.
+// The result is dropped if the file behind path has changed since it was probed.
+func (m *HLSManager) GetCachedProbe(ctx context.Context, path string) *UnifiedProbeResult {
 	m.probeCacheMu.RLock()
-	defer m.probeCacheMu.RUnlock()
-
 	entry, exists := m.probeCache[path]
+	m.probeCacheMu.RUnlock()
 	if !exists {
 		return nil
 	}
@@ -96,18 +105,34 @@ func (m *HLSManager) GetCachedProbe(path string) *UnifiedProbeResult {
 		return nil // expired
 	}
 
+	if entry.fingerprint != "" {
+		if current := m.fingerprints.get(ctx, path); fingerprintChanged(entry.fingerprint, current) {
+			log.Printf("[hls] probe cache invalidated for path: %s (source changed %s -> %s)", path, entry.fingerprint, current)
+			m.probeCacheMu.Lock()
+			if m.probeCache[path] == entry {
+				delete(m.probeCache, path)
+			}
+			m.probeCacheMu.Unlock()
+			return nil
+		}
+	}
+
 	log.Printf("[hls] probe cache HIT for path: %s", path)
 	return entry.result
 }
 
-// CacheProbe stores a probe result in the cache with TTL
-func (m *HLSManager) CacheProbe(path string, result *UnifiedProbeResult) {
+// CacheProbe stores a probe result in the cache with TTL, keyed by path and
+// the fingerprint of the file currently behind it
+func (m *HLSManager) CacheProbe(ctx context.Context, path string, result *UnifiedProbeResult) {
+	fingerprint := m.fingerprints.get(ctx, path)
+
 	m.probeCacheMu.Lock()
 	defer m.probeCacheMu.Unlock()
 
 	m.probeCache[path] = &cachedProbeEntry{
-		result:    result,
-		expiresAt: time.Now().Add(probeCacheTTL),
+		result:      result,
+		fingerprint: fingerprint,
+		expiresAt:   time.Now().Add(probeCacheTTL),
 	}
 	log.Printf("[hls] probe cached for path: %s (expires in %v)", path, probeCacheTTL)
 }
@@ -133,10 +158,23 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 	}
 
 	// Check cache first
-	if cached := m.GetCachedProbe(path); cached != nil {
+	if cached := m.GetCachedProbe(ctx, path); cached != nil {
 		return cached, nil
 	}
 
+	// Deduplicate concurrent probes of the same path and share the worker pool
+	// with VideoHandler probes
+	result, err := sharedProbePool.do(ctx, "unified:"+path, func(ctx context.Context) (interface{}, error) {
+		return m.probeAllMetadataActual(ctx, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	probe, _ := result.(*UnifiedProbeResult)
+	return probe, nil
+}
+
+func (m *HLSManager) probeAllMetadataActual(ctx context.Context, path string) (*UnifiedProbeResult, error) {
 	isExternalURL := strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
 
 	var result *UnifiedProbeResult
@@ -146,7 +184,7 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 	if isExternalURL {
 		result, err = m.probeAllMetadataFromURL(ctx, path)
 		if err == nil && result != nil {
-			m.CacheProbe(path, result)
+			m.CacheProbe(ctx, path, result)
 		}
 		return result, err
 	}
@@ -158,7 +196,7 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 				log.Printf("[hls] probing all metadata using direct URL for path: %s", path)
 				result, err = m.probeAllMetadataFromURL(ctx, directURL)
 				if err == nil && result != nil {
-					m.CacheProbe(path, result) // Cache by original path, not direct URL
+					m.CacheProbe(ctx, path, result) // Cache by original path, not direct URL
 				}
 				return result, err
 			}
@@ -170,7 +208,7 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 		log.Printf("[hls] probing all metadata using local WebDAV URL for path: %s", path)
 		result, err = m.probeAllMetadataFromURL(ctx, webdavURL)
 		if err == nil && result != nil {
-			m.CacheProbe(path, result)
+			m.CacheProbe(ctx, path, result)
 		}
 		return result, err
 	}
@@ -214,10 +252,11 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 		"-print_format", "json",
 		"-show_format",
 		"-show_streams",
-		"-i", "pipe:0",
 	}
+	args = append(args, ffprobeFrameSampleArgs...)
+	args = append(args, "-i", "pipe:0")
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 	output, err := cmd.Output()
 	if err != nil {
@@ -227,7 +266,7 @@ func (m *HLSManager) probeAllMetadata(ctx context.Context, path string) (*Unifie
 
 	result, parseErr := m.parseUnifiedProbeOutput(output)
 	if parseErr == nil && result != nil {
-		m.CacheProbe(path, result)
+		m.CacheProbe(ctx, path, result)
 	}
 	return result, parseErr
 }
@@ -249,13 +288,26 @@ func (m *HLSManager) probeAllMetadataFromURL(ctx context.Context, url string) (*
 		"-i", url,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("ffprobe execution: %w", err)
 	}
 
-	return m.parseUnifiedProbeOutput(output)
+	result, err := m.parseUnifiedProbeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	// Only PQ sources can be HDR10+, so only they pay for decoding frames
+	video := &ffprobeStream{Index: result.videoStreamIndex, ColorTransfer: result.ColorTransfer}
+	if result.VideoCodec != "" && !result.HasHDR10Plus && mayCarryHDR10Plus(video) {
+		frames, err := sampleVideoFrames(probeCtx, m.ffprobePath, url)
+		if err != nil {
+			log.Printf("[hls] %v", err)
+		}
+		result.HasHDR10Plus = detectHDR10Plus(video, frames)
+	}
+	return result, nil
 }
 
 // parseUnifiedProbeOutput parses the JSON output from ffprobe -show_format -show_streams
@@ -268,10 +320,13 @@ func (m *HLSManager) parseUnifiedProbeOutput(output []byte) (*UnifiedProbeResult
 			Index         int               `json:"index"`
 			CodecType     string            `json:"codec_type"`
 			CodecName     string            `json:"codec_name"`
+			Height        int               `json:"height"`
 			ColorTransfer string            `json:"color_transfer"`
 			Tags          map[string]string `json:"tags"`
 			Disposition   map[string]int    `json:"disposition"`
+			SideDataList  []ffprobeSideData `json:"side_data_list"`
 		} `json:"streams"`
+		Frames []ffprobeFrame `json:"frames"`
 	}
 
 	if err := json.Unmarshal(output, &probeData); err != nil {
@@ -312,6 +367,9 @@ func (m *HLSManager) parseUnifiedProbeOutput(output []byte) (*UnifiedProbeResult
 			// Get video codec and color transfer from first video stream
 			if result.VideoCodec == "" {
 				result.VideoCodec = codec
+				result.VideoHeight = stream.Height
+				result.videoStreamIndex = stream.Index
+				result.HasHDR10Plus = detectHDR10Plus(&ffprobeStream{Index: stream.Index, SideDataList: stream.SideDataList}, probeData.Frames)
 			}
 			if result.ColorTransfer == "" {
 				result.ColorTransfer = stream.ColorTransfer
@@ -324,10 +382,11 @@ func (m *HLSManager) parseUnifiedProbeOutput(output []byte) (*UnifiedProbeResult
 				title = stream.Tags["title"]
 			}
 			result.AudioStreams = append(result.AudioStreams, audioStreamInfo{
-				Index:    stream.Index,
-				Codec:    codec,
-				Language: lang,
-				Title:    title,
+				Index:              stream.Index,
+				Codec:              codec,
+				Language:           lang,
+				Title:              title,
+				IsAudioDescription: accessibility.IsAudioDescription(stream.Disposition, title),
 			})
 			if IsIncompatibleAudioCodec(codec) {
 				result.HasTrueHD = true
@@ -359,6 +418,7 @@ func (m *HLSManager) parseUnifiedProbeOutput(output []byte) (*UnifiedProbeResult
 				Title:     title,
 				IsForced:  isForced,
 				IsDefault: isDefault,
+				IsSDH:     accessibility.IsSDH(stream.Disposition, title),
 			})
 		}
 	}
@@ -444,7 +504,7 @@ func (m *HLSManager) probeAudioStreams(ctx context.Context, path string) (stream
 		"-i", "pipe:0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 
 	output, err := cmd.Output()
@@ -507,7 +567,7 @@ func (m *HLSManager) probeAudioStreamsFromURL(ctx context.Context, url string) (
 		"-i", url,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("[hls] ffprobe audio from URL failed: %v", err)
@@ -622,7 +682,7 @@ func (m *HLSManager) probeSubtitleStreams(ctx context.Context, path string) (str
 		"-i", "pipe:0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 
 	output, err := cmd.Output()
@@ -682,7 +742,7 @@ func (m *HLSManager) probeSubtitleStreamsFromURL(ctx context.Context, url string
 		"-i", url,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("[hls] ffprobe subtitle from URL failed: %v", err)
@@ -777,7 +837,7 @@ func (m *HLSManager) probeDuration(ctx context.Context, cleanPath string) (float
 		"-i", "pipe:0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 
 	output, err := cmd.Output()
@@ -811,7 +871,7 @@ func (m *HLSManager) probeDurationFromURL(ctx context.Context, url string) (floa
 		"-i", url,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, fmt.Errorf("ffprobe execution: %w", err)
@@ -881,7 +941,7 @@ func (m *HLSManager) probeColorMetadata(ctx context.Context, cleanPath string) (
 		"-i", "pipe:0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 
 	output, err := cmd.Output()
@@ -909,7 +969,7 @@ func (m *HLSManager) probeColorMetadataFromURL(ctx context.Context, url string)
 		"-i", url,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("ffprobe execution: %w", err)
@@ -930,25 +990,10 @@ func (m *HLSManager) probeKeyframePosition(ctx context.Context, path string, see
 		return seekTime
 	}
 
-	// For external URLs, probe directly
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		return m.probeKeyframePositionFromURL(ctx, path, seekTime)
-	}
-
-	// Try to get a direct URL for probing
-	if m.streamer != nil {
-		if directProvider, ok := m.streamer.(streaming.DirectURLProvider); ok {
-			if directURL, err := directProvider.GetDirectURL(ctx, path); err == nil && directURL != "" {
-				log.Printf("[hls] probing keyframe position using direct URL for path: %s seekTime: %.3f", path, seekTime)
-				return m.probeKeyframePositionFromURL(ctx, directURL, seekTime)
-			}
-		}
-	}
-
-	// Try local WebDAV URL as fallback
-	if webdavURL, ok := m.buildLocalWebDAVURLFromPath(path); ok {
-		log.Printf("[hls] probing keyframe position using local WebDAV URL for path: %s seekTime: %.3f", path, seekTime)
-		return m.probeKeyframePositionFromURL(ctx, webdavURL, seekTime)
+	// External URLs are probed directly, others through a direct or local WebDAV URL
+	if url := m.keyframeProbeURL(ctx, path); url != "" {
+		log.Printf("[hls] probing keyframe position for path: %s seekTime: %.3f", path, seekTime)
+		return m.probeKeyframePositionFromURL(ctx, url, seekTime)
 	}
 
 	log.Printf("[hls] no direct URL available for keyframe probe, using requested time: %.3f", seekTime)
@@ -981,7 +1026,7 @@ func (m *HLSManager) probeKeyframePositionFromURL(ctx context.Context, url strin
 		"-of", "csv=p=0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		// Try alternative approach without -skip_frame (some formats like HEVC may not support it well)
@@ -997,7 +1042,7 @@ func (m *HLSManager) probeKeyframePositionFromURL(ctx context.Context, url strin
 			"-read_intervals", fmt.Sprintf("%.3f%%+#5", seekTime),
 			"-of", "csv=p=0",
 		}
-		cmd = exec.CommandContext(probeCtx, m.ffprobePath, args...)
+		cmd = sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 		output, err = cmd.Output()
 		if err != nil {
 			log.Printf("[hls] keyframe probe failed: %v, using requested time: %.3f", err, seekTime)
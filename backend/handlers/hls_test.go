@@ -3,10 +3,12 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -574,3 +576,194 @@ func TestHLSManager_FindHighestSegmentNumber(t *testing.T) {
 		t.Errorf("expected 10 as highest segment, got %d", result)
 	}
 }
+
+func TestHLSVideoPolicyNeedsVideoTranscode(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy HLSVideoPolicy
+		codec  string
+		height int
+		hdr    bool
+		want   bool
+	}{
+		{"default copies hevc", HLSVideoPolicy{}, "hevc", 0, false, false},
+		{"default transcodes mpeg4", HLSVideoPolicy{}, "mpeg4", 0, false, true},
+		{"never copies mpeg4", HLSVideoPolicy{NeverTranscode: true}, "mpeg4", 0, false, false},
+		{"force transcodes sdr", HLSVideoPolicy{ForceTranscode: true}, "h264", 0, false, true},
+		{"force leaves hdr alone", HLSVideoPolicy{ForceTranscode: true}, "hevc", 0, true, false},
+		{"device without hevc", HLSVideoPolicy{VideoCodecs: []string{"h264"}}, "hevc", 0, false, true},
+		{"device alias h265", HLSVideoPolicy{VideoCodecs: []string{"h265"}}, "hevc", 0, false, false},
+		{"sdr above device cap", HLSVideoPolicy{MaxHeight: 1080}, "hevc", 2160, false, true},
+		{"sdr within device cap", HLSVideoPolicy{MaxHeight: 1080}, "hevc", 1080, false, false},
+		{"hdr above device cap", HLSVideoPolicy{MaxHeight: 1080}, "hevc", 2160, true, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.needsVideoTranscode(tt.codec, tt.height, tt.hdr); got != tt.want {
+			t.Errorf("%s: needsVideoTranscode(%q) = %v, want %v", tt.name, tt.codec, got, tt.want)
+		}
+	}
+}
+
+func TestHLSVideoPolicyRefusesHDR(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy HLSVideoPolicy
+		height int
+		hdr    bool
+		want   bool
+	}{
+		{"no caps", HLSVideoPolicy{}, 2160, true, false},
+		{"sdr source on sdr-only device", HLSVideoPolicy{SDROnly: true}, 2160, false, false},
+		{"hdr on sdr-only device", HLSVideoPolicy{SDROnly: true}, 1080, true, true},
+		{"hdr of unknown height on sdr-only device", HLSVideoPolicy{SDROnly: true}, 0, true, true},
+		{"hdr above device cap", HLSVideoPolicy{MaxHeight: 1080}, 2160, true, true},
+		{"hdr within device cap", HLSVideoPolicy{MaxHeight: 1080}, 1080, true, false},
+		{"sdr above device cap is transcoded", HLSVideoPolicy{MaxHeight: 1080}, 2160, false, false},
+		{"never transcode accepts hdr above cap", HLSVideoPolicy{MaxHeight: 1080, NeverTranscode: true}, 2160, true, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.refusesHDR(tt.height, tt.hdr); got != tt.want {
+			t.Errorf("%s: refusesHDR(%d, %v) = %v, want %v", tt.name, tt.height, tt.hdr, got, tt.want)
+		}
+	}
+}
+
+func TestCreateSessionRefusesHDROverDeviceCaps(t *testing.T) {
+	manager := NewHLSManager(t.TempDir(), "", "", nil)
+	defer manager.Shutdown()
+
+	_, err := manager.CreateSession(context.Background(), "/movie.mkv", "/movie.mkv", false, "", true, false, false,
+		HLSVideoPolicy{SDROnly: true}, 0, 0, -1, -1, "", "", "", "")
+	if !errors.Is(err, ErrHDRExceedsDeviceCaps) {
+		t.Fatalf("expected ErrHDRExceedsDeviceCaps, got %v", err)
+	}
+	if entries, _ := os.ReadDir(manager.baseDir); len(entries) != 0 {
+		t.Fatalf("expected the session directory to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestNextQualityRung(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      HLSQualityRung
+		sourceHeight int
+		direction    string
+		wantHeight   int
+		wantOK       bool
+	}{
+		{"down from 1080p source", HLSQualityRung{}, 1080, "down", 720, true},
+		{"down from 4k source", HLSQualityRung{}, 2160, "down", 1080, true},
+		{"down from unknown source", HLSQualityRung{}, 0, "down", 720, true},
+		{"down from 720p rung", HLSQualityRung{Height: 720}, 1080, "down", 480, true},
+		{"down from lowest rung", HLSQualityRung{Height: 360}, 1080, "down", 0, false},
+		{"up from 480p rung", HLSQualityRung{Height: 480}, 2160, "up", 720, true},
+		{"up to source quality", HLSQualityRung{Height: 720}, 1080, "up", 0, true},
+		{"up at source quality", HLSQualityRung{}, 1080, "up", 0, false},
+		{"unknown direction", HLSQualityRung{}, 1080, "sideways", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := nextQualityRung(tt.current, tt.sourceHeight, tt.direction)
+		if ok != tt.wantOK || got.Height != tt.wantHeight {
+			t.Errorf("%s: nextQualityRung() = %dp, %v, want %dp, %v", tt.name, got.Height, ok, tt.wantHeight, tt.wantOK)
+		}
+	}
+}
+
+func TestHLSVideoPolicyWithQualityRung(t *testing.T) {
+	policy := HLSVideoPolicy{MaxBitrateKbps: 3000}.withQualityRung(HLSQualityRung{Height: 720, MaxBitrateKbps: 4000})
+	if !policy.ForceTranscode || policy.MaxHeight != 720 || policy.MaxBitrateKbps != 3000 {
+		t.Fatalf("expected forced 720p transcode keeping the lower device cap, got %+v", policy)
+	}
+
+	policy = HLSVideoPolicy{}.withQualityRung(HLSQualityRung{Height: 480, MaxBitrateKbps: 1500})
+	if policy.MaxBitrateKbps != 1500 {
+		t.Fatalf("expected rung bitrate when the device has no cap, got %+v", policy)
+	}
+
+	policy = HLSVideoPolicy{MaxHeight: 720}.withQualityRung(HLSQualityRung{Height: 1080, MaxBitrateKbps: 8000})
+	if policy.MaxHeight != 720 {
+		t.Fatalf("expected the device resolution cap to win over a higher rung, got %+v", policy)
+	}
+
+	if policy := (HLSVideoPolicy{}).withQualityRung(HLSQualityRung{}); policy.ForceTranscode {
+		t.Fatalf("source quality must not force a transcode, got %+v", policy)
+	}
+}
+
+func TestHLSManager_AdjustQuality_NotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewHLSManager(tmpDir, "", "", nil)
+	defer manager.Shutdown()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/video/hls/missing/quality", strings.NewReader(`{"direction":"down"}`))
+	rr := httptest.NewRecorder()
+
+	manager.AdjustQuality(rr, req, "nonexistent-session")
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestHLSManager_AdjustQuality_InvalidDirection(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewHLSManager(tmpDir, "", "", nil)
+	defer manager.Shutdown()
+
+	manager.mu.Lock()
+	manager.sessions["quality-test"] = &HLSSession{ID: "quality-test", OutputDir: tmpDir}
+	manager.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/video/hls/quality-test/quality", strings.NewReader(`{"direction":"sideways"}`))
+	rr := httptest.NewRecorder()
+
+	manager.AdjustQuality(rr, req, "quality-test")
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+// --- client pause suspend/resume tests ---
+
+func TestSuspendIfClientPaused(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	session := &HLSSession{ID: "pause-test", FFmpegPID: cmd.Process.Pid, ClientPaused: true, ClientPausedAt: time.Now()}
+
+	// Paused too recently: keep transcoding
+	suspendIfClientPaused(session)
+	if session.Paused {
+		t.Fatal("suspended before the pause delay elapsed")
+	}
+
+	session.ClientPausedAt = time.Now().Add(-hlsClientPauseSuspendDelay)
+	suspendIfClientPaused(session)
+	if !session.Paused {
+		t.Fatal("not suspended after the pause delay")
+	}
+
+	session.mu.Lock()
+	resumeTranscodeLocked(session, "test")
+	session.mu.Unlock()
+	if session.Paused {
+		t.Fatal("still marked paused after resume")
+	}
+
+	// Live sessions are never suspended
+	live := &HLSSession{ID: "live-test", FFmpegPID: cmd.Process.Pid, IsLive: true, ClientPaused: true, ClientPausedAt: time.Now().Add(-time.Hour)}
+	suspendIfClientPaused(live)
+	if live.Paused {
+		t.Fatal("live session was suspended")
+	}
+}
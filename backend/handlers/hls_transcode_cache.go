@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// transcodeCacheDirName holds completed transcodes under the HLS base
+	// directory, so restoring one into a session directory is a hard link
+	transcodeCacheDirName = "transcode-cache"
+	transcodeCacheMeta    = "entry.json"
+	transcodeCachePending = "pending"
+)
+
+// transcodeCacheEntry describes a completed HLS segment set.
+type transcodeCacheEntry struct {
+	Key       string    `json:"key"`
+	Source    string    `json:"source"`  // Source path, for logs
+	Profile   string    `json:"profile"` // Transcode settings the segments were made with
+	Size      int64     `json:"size"`
+	Duration  float64   `json:"duration"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// transcodeCache keeps the segment sets of sessions that transcoded a whole
+// release, keyed by (release, transcode profile), so a later session for the
+// same release and settings is served from disk instead of re-transcoding.
+// Segments are captured as a session goes: each one is hard linked into a
+// pending directory before playback cleanup deletes it, and the set is kept
+// only when FFmpeg finishes the release in one uninterrupted run.
+type transcodeCache struct {
+	dir string
+
+	mu       sync.Mutex
+	maxBytes int64
+	entries  map[string]*transcodeCacheEntry
+}
+
+// newTranscodeCache loads the entries under dir and drops unfinished captures
+// left by a previous run.
+func newTranscodeCache(dir string) *transcodeCache {
+	c := &transcodeCache{dir: dir, entries: make(map[string]*transcodeCacheEntry)}
+	_ = os.RemoveAll(filepath.Join(dir, transcodeCachePending))
+
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return c
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		entryDir := filepath.Join(dir, d.Name())
+		data, err := os.ReadFile(filepath.Join(entryDir, transcodeCacheMeta))
+		var entry transcodeCacheEntry
+		if err != nil || json.Unmarshal(data, &entry) != nil || entry.Key != d.Name() {
+			_ = os.RemoveAll(entryDir)
+			continue
+		}
+		c.entries[entry.Key] = &entry
+	}
+	if len(c.entries) > 0 {
+		log.Printf("[hls] transcode cache: loaded %d cached transcodes from %s", len(c.entries), dir)
+	}
+	return c
+}
+
+func (c *transcodeCache) enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxBytes > 0
+}
+
+// setLimit changes the disk budget, evicting entries beyond it. 0 disables
+// the cache and removes every entry.
+func (c *transcodeCache) setLimit(maxBytes int64) {
+	c.mu.Lock()
+	c.maxBytes = max(maxBytes, 0)
+	c.mu.Unlock()
+	c.evict(0)
+}
+
+func (c *transcodeCache) pendingDir(sessionID string) string {
+	return filepath.Join(c.dir, transcodeCachePending, sessionID)
+}
+
+// restore links the cached segment set for key into outputDir and reports
+// whether there was one.
+func (c *transcodeCache) restore(key, outputDir string) (*transcodeCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entryDir := filepath.Join(c.dir, key)
+	files, err := os.ReadDir(entryDir)
+	if err != nil {
+		c.removeLocked(key)
+		return nil, false
+	}
+	var restored []string
+	for _, f := range files {
+		if f.IsDir() || f.Name() == transcodeCacheMeta {
+			continue
+		}
+		target := filepath.Join(outputDir, f.Name())
+		if err := linkOrCopy(filepath.Join(entryDir, f.Name()), target); err != nil {
+			log.Printf("[hls] transcode cache: failed to restore %s: %v", key, err)
+			for _, path := range restored {
+				_ = os.Remove(path)
+			}
+			c.removeLocked(key)
+			return nil, false
+		}
+		restored = append(restored, target)
+	}
+
+	entry.LastUsed = time.Now()
+	c.writeMetaLocked(entry)
+	hit := *entry
+	return &hit, true
+}
+
+// capture hard links a finished file of a capturing session into its pending
+// set, before the session deletes it.
+func (c *transcodeCache) capture(sessionID, path string) {
+	pending := c.pendingDir(sessionID)
+	if err := os.MkdirAll(pending, 0755); err != nil {
+		return
+	}
+	// Files already captured, or deleted before, are skipped
+	err := linkOrCopy(path, filepath.Join(pending, filepath.Base(path)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrExist) {
+		log.Printf("[hls] transcode cache: failed to capture %s for session %s: %v", filepath.Base(path), sessionID, err)
+	}
+}
+
+// discard drops the pending set of a session.
+func (c *transcodeCache) discard(sessionID string) {
+	_ = os.RemoveAll(c.pendingDir(sessionID))
+}
+
+// finish completes the pending set of a session with the files still in
+// outputDir and stores it under key, evicting least recently used entries
+// to stay within the budget.
+func (c *transcodeCache) finish(sessionID, outputDir string, entry transcodeCacheEntry) error {
+	pending := c.pendingDir(sessionID)
+	defer c.discard(sessionID)
+
+	files, err := os.ReadDir(outputDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		c.capture(sessionID, filepath.Join(outputDir, name))
+	}
+
+	playlist, err := os.ReadFile(filepath.Join(pending, "stream.m3u8"))
+	if err != nil {
+		return fmt.Errorf("read playlist: %w", err)
+	}
+	if !strings.Contains(string(playlist), "#EXT-X-ENDLIST") {
+		return errors.New("playlist is not complete")
+	}
+	for _, line := range strings.Split(string(playlist), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(pending, line)); err != nil {
+			return fmt.Errorf("segment %s was not captured", line)
+		}
+	}
+
+	entry.Size = dirSize(pending)
+	entry.CreatedAt = time.Now()
+	entry.LastUsed = entry.CreatedAt
+
+	c.mu.Lock()
+	if entry.Size > c.maxBytes {
+		c.mu.Unlock()
+		return fmt.Errorf("%d MB exceeds the cache size", entry.Size>>20)
+	}
+	if _, exists := c.entries[entry.Key]; exists {
+		c.mu.Unlock()
+		return nil
+	}
+	if err := os.Rename(pending, filepath.Join(c.dir, entry.Key)); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.entries[entry.Key] = &entry
+	c.writeMetaLocked(&entry)
+	c.mu.Unlock()
+
+	log.Printf("[hls] transcode cache: stored %s (%s, %d MB)", entry.Source, entry.Profile, entry.Size>>20)
+	c.evict(0)
+	return nil
+}
+
+// evict removes least recently used entries until the cache is within its
+// budget and at least needBytes were freed. It returns the bytes freed.
+func (c *transcodeCache) evict(needBytes int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	entries := make([]*transcodeCacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		total += entry.Size
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+
+	var freed int64
+	for _, entry := range entries {
+		if total <= c.maxBytes && freed >= needBytes {
+			break
+		}
+		log.Printf("[hls] transcode cache: evicting %s (%s, %d MB)", entry.Source, entry.Profile, entry.Size>>20)
+		c.removeLocked(entry.Key)
+		total -= entry.Size
+		freed += entry.Size
+	}
+	return freed
+}
+
+// evictForSpace is registered as a disk space evictor.
+func (c *transcodeCache) evictForSpace(_ context.Context, needBytes int64) int64 {
+	return c.evict(needBytes)
+}
+
+func (c *transcodeCache) removeLocked(key string) {
+	delete(c.entries, key)
+	_ = os.RemoveAll(filepath.Join(c.dir, key))
+}
+
+func (c *transcodeCache) writeMetaLocked(entry *transcodeCacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, entry.Key, transcodeCacheMeta), data, 0644); err != nil {
+		log.Printf("[hls] transcode cache: failed to write %s metadata: %v", entry.Key, err)
+	}
+}
+
+// transcodeCacheKey identifies a release and the settings it is transcoded
+// with.
+func transcodeCacheKey(release, profile string) string {
+	sum := sha256.Sum256([]byte(release + "\n" + profile))
+	return hex.EncodeToString(sum[:16])
+}
+
+// transcodeProfile describes everything besides the source that shapes a
+// session's segments.
+func transcodeProfile(session *HLSSession, forceAAC bool, audioOffsetMs int64) string {
+	policy := session.VideoPolicy
+	codecs := make([]string, 0, len(policy.VideoCodecs))
+	for _, codec := range policy.VideoCodecs {
+		codecs = append(codecs, canonicalVideoCodec(codec))
+	}
+	sort.Strings(codecs)
+
+	return fmt.Sprintf("force=%t never=%t kbps=%d height=%d codecs=%s dv=%t/%t hdr=%t/%t hdr10plus=%t audio=%d aac=%t audioOffset=%d",
+		policy.ForceTranscode, policy.NeverTranscode, policy.MaxBitrateKbps, policy.MaxHeight, strings.Join(codecs, ","),
+		session.HasDV, session.DVDisabled, session.HasHDR, session.HDRMetadataDisabled, session.HDR10PlusPassthrough,
+		session.AudioTrackIndex, forceAAC, audioOffsetMs)
+}
+
+// transcodeRelease identifies the file behind a session: its path without
+// query (which may carry per-link tokens) and its fingerprint. It returns ""
+// for external URLs without a fingerprint, whose content can't be told apart.
+func (m *HLSManager) transcodeRelease(ctx context.Context, path string) string {
+	fingerprint := m.fingerprints.get(ctx, path)
+	isExternalURL := strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+	if isExternalURL {
+		if fingerprint == "" {
+			return ""
+		}
+		if parsed, err := url.Parse(path); err == nil {
+			parsed.RawQuery = ""
+			parsed.Fragment = ""
+			path = parsed.String()
+		}
+	}
+	return path + "|" + fingerprint
+}
+
+// SetTranscodeCacheSize sets the disk budget for completed transcodes reused
+// by later sessions. 0 disables the cache.
+func (m *HLSManager) SetTranscodeCacheSize(maxBytes int64) {
+	m.transcodeCache.setLimit(maxBytes)
+}
+
+// restoreCachedTranscode fills a new session from the cache when the same
+// release was transcoded with the same settings, and otherwise marks the
+// session to capture its segments. It reports whether the session was
+// restored.
+func (m *HLSManager) restoreCachedTranscode(ctx context.Context, session *HLSSession, forceAAC bool) bool {
+	if !m.transcodeCache.enabled() || session.IsLive || session.TranscodingOffset > 0 {
+		return false
+	}
+	release := m.transcodeRelease(ctx, session.Path)
+	if release == "" {
+		return false
+	}
+	profile := transcodeProfile(session, forceAAC, releaseAudioOffset(m.audioOffsets, session.Path))
+	key := transcodeCacheKey(release, profile)
+
+	if entry, ok := m.transcodeCache.restore(key, session.OutputDir); ok {
+		session.Completed = true
+		session.FromCache = true
+		if session.Duration <= 0 {
+			session.Duration = entry.Duration
+		}
+		log.Printf("[hls] session %s: serving cached transcode of %q (%s)", session.ID, session.Path, profile)
+		return true
+	}
+
+	session.cacheCapture = &transcodeCacheEntry{Key: key, Source: session.Path, Profile: profile}
+	return false
+}
+
+// abandonTranscodeCapture stops capturing a session whose output no longer
+// covers the release from the start in one run (seek, restart, recovery).
+func (m *HLSManager) abandonTranscodeCapture(session *HLSSession) {
+	session.mu.Lock()
+	capturing := session.cacheCapture != nil
+	session.cacheCapture = nil
+	session.FromCache = false
+	session.mu.Unlock()
+	if capturing {
+		m.transcodeCache.discard(session.ID)
+	}
+}
+
+// finishTranscodeCapture stores the segments of a session whose FFmpeg run
+// transcoded the whole release. Sessions that only copied video are cheap
+// to recreate and aren't kept.
+func (m *HLSManager) finishTranscodeCapture(session *HLSSession) {
+	session.mu.Lock()
+	capture := session.cacheCapture
+	session.cacheCapture = nil
+	videoTranscoded := session.videoTranscoded
+	outputDir := session.OutputDir
+	duration := session.Duration
+	session.mu.Unlock()
+	if capture == nil {
+		return
+	}
+	if !videoTranscoded {
+		m.transcodeCache.discard(session.ID)
+		return
+	}
+
+	entry := *capture
+	entry.Duration = duration
+	if err := m.transcodeCache.finish(session.ID, outputDir, entry); err != nil {
+		log.Printf("[hls] session %s: transcode not cached: %v", session.ID, err)
+	}
+}
+
+// linkOrCopy hard links src to dst, copying when linking isn't possible.
+// A missing src or existing dst is returned as is.
+func linkOrCopy(src, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil || errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrExist) {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTranscodeOutput writes a finished fMP4 HLS output with n segments.
+func writeTranscodeOutput(t *testing.T, dir string, n int) {
+	t.Helper()
+	var playlist strings.Builder
+	playlist.WriteString("#EXTM3U\n#EXT-X-PLAYLIST-TYPE:EVENT\n#EXT-X-MAP:URI=\"init.mp4\"\n")
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("segment%d.m4s", i)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", 1024)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&playlist, "#EXTINF:2.000000,\n%s\n", name)
+	}
+	playlist.WriteString("#EXT-X-ENDLIST\n")
+	for name, content := range map[string]string{"stream.m3u8": playlist.String(), "init.mp4": "init"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTranscodeCacheStoresAndRestoresSegmentSet(t *testing.T) {
+	base := t.TempDir()
+	cache := newTranscodeCache(filepath.Join(base, transcodeCacheDirName))
+	cache.setLimit(1 << 20)
+
+	session := filepath.Join(base, "session1")
+	os.MkdirAll(session, 0755)
+	writeTranscodeOutput(t, session, 4)
+
+	// Playback cleanup deletes watched segments after they are captured
+	for i := 0; i < 2; i++ {
+		segment := filepath.Join(session, fmt.Sprintf("segment%d.m4s", i))
+		cache.capture("session1", segment)
+		os.Remove(segment)
+	}
+
+	key := transcodeCacheKey("/movies/film.mkv|1000", "height=1080")
+	if err := cache.finish("session1", session, transcodeCacheEntry{Key: key, Source: "/movies/film.mkv"}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	restored := filepath.Join(base, "session2")
+	os.MkdirAll(restored, 0755)
+	if _, ok := cache.restore(key, restored); !ok {
+		t.Fatal("expected a cache hit")
+	}
+	for _, name := range []string{"stream.m3u8", "init.mp4", "segment0.m4s", "segment3.m4s"} {
+		if _, err := os.Stat(filepath.Join(restored, name)); err != nil {
+			t.Errorf("expected %s to be restored: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(restored, transcodeCacheMeta)); err == nil {
+		t.Error("metadata file should not be restored into the session")
+	}
+
+	// Entries survive a restart
+	if reloaded := newTranscodeCache(filepath.Join(base, transcodeCacheDirName)); len(reloaded.entries) != 1 {
+		t.Errorf("expected 1 entry after reload, got %d", len(reloaded.entries))
+	}
+}
+
+func TestTranscodeCacheRejectsIncompleteCapture(t *testing.T) {
+	base := t.TempDir()
+	cache := newTranscodeCache(filepath.Join(base, transcodeCacheDirName))
+	cache.setLimit(1 << 20)
+
+	session := filepath.Join(base, "session1")
+	os.MkdirAll(session, 0755)
+	writeTranscodeOutput(t, session, 3)
+	// Deleted without being captured, e.g. before capturing started
+	os.Remove(filepath.Join(session, "segment0.m4s"))
+
+	key := transcodeCacheKey("release", "profile")
+	if err := cache.finish("session1", session, transcodeCacheEntry{Key: key}); err == nil {
+		t.Fatal("expected an incomplete segment set to be rejected")
+	}
+	if _, ok := cache.restore(key, t.TempDir()); ok {
+		t.Fatal("incomplete segment set should not be cached")
+	}
+	if _, err := os.Stat(cache.pendingDir("session1")); !os.IsNotExist(err) {
+		t.Errorf("pending capture should be removed, stat err=%v", err)
+	}
+}
+
+func TestTranscodeCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	base := t.TempDir()
+	cache := newTranscodeCache(filepath.Join(base, transcodeCacheDirName))
+	// Room for two sets of 4 KiB segments plus playlist and init
+	cache.setLimit(10 * 1024)
+
+	keys := make([]string, 3)
+	for i := range keys {
+		session := filepath.Join(base, fmt.Sprintf("session%d", i))
+		os.MkdirAll(session, 0755)
+		writeTranscodeOutput(t, session, 4)
+		keys[i] = transcodeCacheKey(fmt.Sprintf("release%d", i), "profile")
+		if err := cache.finish(fmt.Sprintf("session%d", i), session, transcodeCacheEntry{Key: keys[i]}); err != nil {
+			t.Fatalf("finish %d: %v", i, err)
+		}
+		if i == 1 {
+			// Using the first entry makes the second the least recently used
+			if _, ok := cache.restore(keys[0], t.TempDir()); !ok {
+				t.Fatal("expected the first entry to be cached")
+			}
+		}
+	}
+
+	if _, ok := cache.entries[keys[1]]; ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{keys[0], keys[2]} {
+		if _, ok := cache.entries[key]; !ok {
+			t.Errorf("expected entry %s to be kept", key)
+		}
+	}
+
+	cache.setLimit(0)
+	if len(cache.entries) != 0 {
+		t.Errorf("disabling the cache should remove every entry, %d left", len(cache.entries))
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -17,15 +19,37 @@ import (
 	"sync"
 	"time"
 
+	"novastream/services/artwork"
+	"novastream/services/image_placeholders"
+
 	"golang.org/x/image/draw"
 )
 
+// artworkFetcher fetches artwork with retries and falls back to alternate
+// URLs when the primary is dead.
+type artworkFetcher interface {
+	Fetch(ctx context.Context, primary string, fallbacks []string) (*http.Response, string, error)
+}
+
+var _ artworkFetcher = (*artwork.Service)(nil)
+
+// imagePlaceholderRecorder stores placeholders for artwork as it is cached.
+type imagePlaceholderRecorder interface {
+	Has(url string) bool
+	Record(img image.Image, urls ...string)
+}
+
+var _ imagePlaceholderRecorder = (*image_placeholders.Service)(nil)
+
 // ImageHandler handles image proxying with resize and caching
 type ImageHandler struct {
-	cacheDir   string
-	httpc      *http.Client
-	mu         sync.RWMutex
-	inProgress map[string]chan struct{} // Prevent duplicate fetches
+	cacheDir     string
+	httpc        *http.Client
+	artwork      artworkFetcher
+	placeholders imagePlaceholderRecorder
+	mu           sync.RWMutex
+	inProgress   map[string]chan struct{} // Prevent duplicate fetches
+	variants     map[imageVariant]int     // Requests per size and quality, for warming
 }
 
 // NewImageHandler creates a new image proxy handler
@@ -42,12 +66,31 @@ func NewImageHandler(cacheDir string) *ImageHandler {
 			Timeout: 30 * time.Second,
 		},
 		inProgress: make(map[string]chan struct{}),
+		variants:   make(map[imageVariant]int),
 	}
 }
 
+// SetArtworkService enables retries and fallback substitution for dead artwork.
+func (h *ImageHandler) SetArtworkService(svc artworkFetcher) {
+	h.artwork = svc
+}
+
+// SetPlaceholderService enables computing placeholders for cached artwork.
+func (h *ImageHandler) SetPlaceholderService(svc imagePlaceholderRecorder) {
+	h.placeholders = svc
+}
+
+// allowedImageURL reports whether the proxy may fetch from the URL's source
+func allowedImageURL(sourceURL string) bool {
+	return strings.Contains(sourceURL, "image.tmdb.org") ||
+		strings.Contains(sourceURL, "img.youtube.com") ||
+		strings.Contains(sourceURL, "artworks.thetvdb.com")
+}
+
 // Proxy handles image proxy requests
 // Query params:
 //   - url: source image URL (required)
+//   - fallback: alternate URL used if the source is dead (optional, repeatable)
 //   - w: target width (optional, default: original)
 //   - q: JPEG quality 1-100 (optional, default: 80)
 func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
@@ -58,12 +101,19 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate URL is from allowed sources (TMDB for now)
-	if !strings.Contains(sourceURL, "image.tmdb.org") && !strings.Contains(sourceURL, "img.youtube.com") {
+	// Validate URL is from allowed sources
+	if !allowedImageURL(sourceURL) {
 		http.Error(w, "URL not allowed", http.StatusForbidden)
 		return
 	}
 
+	var fallbacks []string
+	for _, fallback := range r.URL.Query()["fallback"] {
+		if fallback != "" && allowedImageURL(fallback) {
+			fallbacks = append(fallbacks, fallback)
+		}
+	}
+
 	// Parse target width (0 = original size)
 	targetWidth := 0
 	if wStr := r.URL.Query().Get("w"); wStr != "" {
@@ -80,6 +130,8 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.recordVariant(targetWidth, quality)
+
 	// Generate cache key from URL + width + quality
 	cacheKey := h.cacheKey(sourceURL, targetWidth, quality)
 	cachePath := filepath.Join(h.cacheDir, cacheKey+".jpg")
@@ -90,6 +142,7 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Cache-Control", "public, max-age=2592000") // 30 days
 		w.Header().Set("X-Cache", "HIT")
 		w.Write(data)
+		h.backfillPlaceholder(sourceURL, data)
 		return
 	}
 
@@ -123,7 +176,8 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	// Fetch the image
-	resp, err := h.httpc.Get(sourceURL)
+	// Other requests may be waiting on this fetch, so don't abort it if this client goes away
+	resp, usedURL, err := h.fetch(context.WithoutCancel(r.Context()), sourceURL, fallbacks)
 	if err != nil {
 		log.Printf("[ImageProxy] Fetch error for %s: %v", sourceURL, err)
 		http.Error(w, "Failed to fetch image", http.StatusBadGateway)
@@ -136,6 +190,9 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Image source error", resp.StatusCode)
 		return
 	}
+	if usedURL != sourceURL {
+		w.Header().Set("X-Image-Source", usedURL)
+	}
 
 	// Decode the image
 	img, _, err := image.Decode(resp.Body)
@@ -144,26 +201,12 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to decode image", http.StatusInternalServerError)
 		return
 	}
+	if h.placeholders != nil && !h.placeholders.Has(usedURL) {
+		h.placeholders.Record(img, sourceURL, usedURL)
+	}
 
 	// Resize if requested
-	if targetWidth > 0 {
-		bounds := img.Bounds()
-		origWidth := bounds.Dx()
-		origHeight := bounds.Dy()
-
-		// Only resize if target is smaller than original
-		if targetWidth < origWidth {
-			ratio := float64(targetWidth) / float64(origWidth)
-			targetHeight := int(float64(origHeight) * ratio)
-
-			// Create new image with target dimensions
-			dst := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
-
-			// Use CatmullRom for high quality downscaling
-			draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
-			img = dst
-		}
-	}
+	img = resizeImage(img, targetWidth)
 
 	// Encode as JPEG for consistent output and better compression
 	tmpPath := cachePath + ".tmp"
@@ -206,6 +249,56 @@ func (h *ImageHandler) Proxy(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// resizeImage scales img down to targetWidth, keeping its aspect ratio.
+// Images already narrower than that, or a targetWidth of 0, are returned as is.
+func resizeImage(img image.Image, targetWidth int) image.Image {
+	bounds := img.Bounds()
+	origWidth := bounds.Dx()
+	origHeight := bounds.Dy()
+
+	// Only resize if target is smaller than original
+	if targetWidth <= 0 || targetWidth >= origWidth {
+		return img
+	}
+	ratio := float64(targetWidth) / float64(origWidth)
+	targetHeight := int(float64(origHeight) * ratio)
+
+	// Create new image with target dimensions
+	dst := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+
+	// Use CatmullRom for high quality downscaling
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// fetch retrieves the source image, going through the artwork service when
+// one is configured so dead URLs are retried and substituted.
+// It returns the response and the URL that produced it.
+func (h *ImageHandler) fetch(ctx context.Context, sourceURL string, fallbacks []string) (*http.Response, string, error) {
+	if h.artwork != nil {
+		return h.artwork.Fetch(ctx, sourceURL, fallbacks)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := h.httpc.Do(req)
+	return resp, sourceURL, err
+}
+
+// backfillPlaceholder computes the placeholder for artwork cached before
+// placeholders were recorded, from the cached copy.
+func (h *ImageHandler) backfillPlaceholder(sourceURL string, data []byte) {
+	if h.placeholders == nil || h.placeholders.Has(sourceURL) {
+		return
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	h.placeholders.Record(img, sourceURL)
+}
+
 // cacheKey generates a unique cache key for the image
 func (h *ImageHandler) cacheKey(url string, width, quality int) string {
 	data := fmt.Sprintf("%s|%d|%d", url, width, quality)
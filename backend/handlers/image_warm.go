@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// Only the most requested sizes are warmed
+	maxWarmVariants = 3
+
+	// Warmed until clients have asked for anything: the app's default
+	// poster width and quality
+	defaultWarmWidth   = 780
+	defaultWarmQuality = 80
+)
+
+// imageVariant is a size and quality the proxy serves images at.
+type imageVariant struct {
+	width   int
+	quality int
+}
+
+// recordVariant counts a request for the variant so warming covers the sizes
+// clients actually ask for.
+func (h *ImageHandler) recordVariant(width, quality int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.variants[imageVariant{width: width, quality: quality}]++
+}
+
+// warmVariants returns the most requested variants.
+func (h *ImageHandler) warmVariants() []imageVariant {
+	h.mu.RLock()
+	variants := make([]imageVariant, 0, len(h.variants))
+	counts := make(map[imageVariant]int, len(h.variants))
+	for variant, count := range h.variants {
+		variants = append(variants, variant)
+		counts[variant] = count
+	}
+	h.mu.RUnlock()
+
+	if len(variants) == 0 {
+		return []imageVariant{{width: defaultWarmWidth, quality: defaultWarmQuality}}
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		if counts[variants[i]] != counts[variants[j]] {
+			return counts[variants[i]] > counts[variants[j]]
+		}
+		return variants[i].width < variants[j].width
+	})
+	if len(variants) > maxWarmVariants {
+		variants = variants[:maxWarmVariants]
+	}
+	return variants
+}
+
+// Warm caches sourceURL at the most requested sizes so the next proxy request
+// is a cache hit. It reports whether anything had to be fetched; URLs the
+// proxy doesn't serve are skipped.
+func (h *ImageHandler) Warm(ctx context.Context, sourceURL string) (bool, error) {
+	if sourceURL == "" || !allowedImageURL(sourceURL) {
+		return false, nil
+	}
+
+	var missing []imageVariant
+	for _, variant := range h.warmVariants() {
+		if _, err := os.Stat(h.cachePath(sourceURL, variant)); err != nil {
+			missing = append(missing, variant)
+		}
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	resp, usedURL, err := h.fetch(ctx, sourceURL, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("image source returned %d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("decode image: %w", err)
+	}
+	if h.placeholders != nil && !h.placeholders.Has(usedURL) {
+		h.placeholders.Record(img, sourceURL, usedURL)
+	}
+
+	for _, variant := range missing {
+		if err := writeCachedImage(h.cachePath(sourceURL, variant), resizeImage(img, variant.width), variant.quality); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func (h *ImageHandler) cachePath(sourceURL string, variant imageVariant) string {
+	return filepath.Join(h.cacheDir, h.cacheKey(sourceURL, variant.width, variant.quality)+".jpg")
+}
+
+// writeCachedImage encodes img to path through a temporary file so
+// concurrent proxy requests never read a partial image.
+func writeCachedImage(path string, img image.Image, quality int) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache file: %w", err)
+	}
+	tmpPath := f.Name()
+
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: quality}); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("encode image: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write cache file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename cache file: %w", err)
+	}
+	return nil
+}
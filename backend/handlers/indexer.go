@@ -24,9 +24,11 @@ type indexerService interface {
 var _ indexerService = (*indexer.Service)(nil)
 
 type IndexerHandler struct {
-	Service     indexerService
-	MetadataSvc SeriesDetailsProvider
-	DemoMode    bool
+	Service        indexerService
+	MetadataSvc    SeriesDetailsProvider
+	MatchOverrides matchOverrideLookup
+	KidsProfiles   kidsProfileLookup
+	DemoMode       bool
 }
 
 func NewIndexerHandler(s indexerService, demoMode bool) *IndexerHandler {
@@ -38,6 +40,11 @@ func (h *IndexerHandler) SetMetadataService(svc SeriesDetailsProvider) {
 	h.MetadataSvc = svc
 }
 
+// SetMatchOverrides applies manual metadata match corrections to searches
+func (h *IndexerHandler) SetMatchOverrides(lookup matchOverrideLookup) {
+	h.MatchOverrides = lookup
+}
+
 func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 	query := strings.TrimSpace(r.URL.Query().Get("q"))
 	categories := r.URL.Query()["cat"]
@@ -55,6 +62,17 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 			year = parsed
 		}
 	}
+	// Runtime in minutes of the movie or episode, used to estimate release bitrates
+	runtimeMinutes := 0
+	if rawRuntime := r.URL.Query().Get("runtime"); rawRuntime != "" {
+		if parsed, err := strconv.Atoi(rawRuntime); err == nil && parsed > 0 {
+			runtimeMinutes = parsed
+		}
+	}
+	if mediaType != "" {
+		titleName := strings.TrimSpace(debrid.ParseQuery(query).Title)
+		imdbID = overrideIMDBID(h.MatchOverrides, mediaType, "", titleName, year, imdbID)
+	}
 	max := 5
 	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
 		if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed > 0 {
@@ -72,6 +90,9 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 			episodeResolver = seriesMeta.EpisodeResolver
 			isDaily = seriesMeta.IsDaily
 			targetAirDate = seriesMeta.TargetAirDate
+			if runtimeMinutes == 0 {
+				runtimeMinutes = seriesMeta.RuntimeMinutes
+			}
 			if episodeResolver != nil {
 				log.Printf("[indexer] Episode resolver created: %d total episodes, %d seasons",
 					episodeResolver.TotalEpisodes, len(episodeResolver.SeasonEpisodeCounts))
@@ -94,6 +115,7 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 		EpisodeResolver: episodeResolver,
 		IsDaily:         isDaily,
 		TargetAirDate:   targetAirDate,
+		RuntimeMinutes:  runtimeMinutes,
 	}
 
 	results, err := h.Service.Search(r.Context(), opts)
@@ -105,6 +127,12 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Kids profiles can't choose a release themselves: only the one that
+	// would be picked automatically is returned
+	if isKidsProfile(h.KidsProfiles, userID) && len(results) > 1 {
+		results = results[:1]
+	}
+
 	// In demo mode, mask actual filenames with the search query info
 	if h.DemoMode {
 		maskedTitle := buildMaskedTitle(query, year, mediaType)
@@ -187,6 +215,7 @@ type seriesSearchMetadata struct {
 	EpisodeResolver *filter.SeriesEpisodeResolver
 	IsDaily         bool
 	TargetAirDate   string // YYYY-MM-DD format for daily shows
+	RuntimeMinutes  int    // Runtime of the searched episode (or a typical episode)
 }
 
 // getSeriesSearchMetadata fetches series metadata for search, including episode resolver
@@ -246,6 +275,8 @@ func (h *IndexerHandler) getSeriesSearchMetadata(ctx context.Context, query stri
 		result.EpisodeResolver = filter.NewSeriesEpisodeResolver(seasonCounts)
 	}
 
+	result.RuntimeMinutes = episodeRuntime(details.Seasons, parsed.Season, parsed.Episode)
+
 	// For daily shows, find the air date of the target episode
 	if result.IsDaily && parsed.Season > 0 && parsed.Episode > 0 {
 		log.Printf("[indexer] Series %q is a daily show, looking up air date for S%02dE%02d",
@@ -268,6 +299,26 @@ func (h *IndexerHandler) getSeriesSearchMetadata(ctx context.Context, query stri
 	return result
 }
 
+// episodeRuntime returns the runtime of the given episode, falling back to the
+// first episode with a known runtime when the episode isn't found.
+func episodeRuntime(seasons []models.SeriesSeason, seasonNumber, episodeNumber int) int {
+	fallback := 0
+	for _, season := range seasons {
+		for _, ep := range season.Episodes {
+			if ep.Runtime <= 0 {
+				continue
+			}
+			if season.Number == seasonNumber && ep.EpisodeNumber == episodeNumber {
+				return ep.Runtime
+			}
+			if fallback == 0 && season.Number > 0 {
+				fallback = ep.Runtime
+			}
+		}
+	}
+	return fallback
+}
+
 // createEpisodeResolver is a convenience wrapper for backward compatibility
 func (h *IndexerHandler) createEpisodeResolver(ctx context.Context, query string, year int) *filter.SeriesEpisodeResolver {
 	meta := h.getSeriesSearchMetadata(ctx, query, year)
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/jobs"
+
+	"github.com/gorilla/mux"
+)
+
+type jobsService interface {
+	List() []models.JobStatus
+	Get(id string) (models.JobStatus, error)
+	RunNow(id string) (models.JobStatus, error)
+	SetEnabled(id string, enabled bool) (models.JobStatus, error)
+	SetSchedule(id, expr string) (models.JobStatus, error)
+}
+
+var _ jobsService = (*jobs.Service)(nil)
+
+// JobsHandler lets admins inspect, trigger and reschedule background maintenance jobs
+type JobsHandler struct {
+	svc jobsService
+}
+
+// NewJobsHandler creates a new jobs handler
+func NewJobsHandler(svc jobsService) *JobsHandler {
+	return &JobsHandler{svc: svc}
+}
+
+// List handles GET /api/admin/jobs
+func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List())
+}
+
+// Get handles GET /api/admin/jobs/{jobID}
+func (h *JobsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.Get(mux.Vars(r)["jobID"])
+	h.respond(w, status, err, http.StatusOK)
+}
+
+// Update handles PATCH /api/admin/jobs/{jobID}
+// Body: {"schedule": "0 4 * * *", "enabled": true}; both fields are optional
+// and an empty schedule restores the job's default.
+func (h *JobsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Schedule *string `json:"schedule"`
+		Enabled  *bool   `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["jobID"]
+	status, err := h.svc.Get(id)
+	if err == nil && req.Schedule != nil {
+		status, err = h.svc.SetSchedule(id, *req.Schedule)
+	}
+	if err == nil && req.Enabled != nil {
+		status, err = h.svc.SetEnabled(id, *req.Enabled)
+	}
+	h.respond(w, status, err, http.StatusOK)
+}
+
+// RunNow handles POST /api/admin/jobs/{jobID}/run
+// Starts the job in the background; poll the job to follow it.
+func (h *JobsHandler) RunNow(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.RunNow(mux.Vars(r)["jobID"])
+	h.respond(w, status, err, http.StatusAccepted)
+}
+
+// Disable handles POST /api/admin/jobs/{jobID}/disable
+func (h *JobsHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.SetEnabled(mux.Vars(r)["jobID"], false)
+	h.respond(w, status, err, http.StatusOK)
+}
+
+// Enable handles POST /api/admin/jobs/{jobID}/enable
+func (h *JobsHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	status, err := h.svc.SetEnabled(mux.Vars(r)["jobID"], true)
+	h.respond(w, status, err, http.StatusOK)
+}
+
+// Options handles CORS preflight requests
+func (h *JobsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *JobsHandler) respond(w http.ResponseWriter, status models.JobStatus, err error, code int) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, jobs.ErrJobNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, jobs.ErrJobRunning):
+			code = http.StatusConflict
+		case errors.Is(err, jobs.ErrInvalidSchedule):
+			code = http.StatusBadRequest
+		}
+		writeJSONError(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
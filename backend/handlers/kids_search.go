@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/users"
+)
+
+// kidsProfileLookup resolves profiles so searches can tell kids profiles apart.
+type kidsProfileLookup interface {
+	Get(id string) (models.User, bool)
+}
+
+var _ kidsProfileLookup = (*users.Service)(nil)
+
+// US movie certifications and TV parental guidelines, least to most mature
+var (
+	movieCertificationOrder = []string{"G", "PG", "PG-13", "R", "NC-17"}
+	tvRatingOrder           = []string{"TV-Y", "TV-Y7", "TV-G", "TV-PG", "TV-14", "TV-MA"}
+)
+
+// matureOverviewKeywords are stripped from overviews shown to kids profiles.
+var matureOverviewKeywords = regexp.MustCompile(`(?i)\b(` + strings.Join([]string{
+	"sex", "sexual", "sexually", "sexy", "nude", "nudity", "naked", "erotic", "seduce", "seduces", "seduction",
+	"affair", "prostitute", "prostitution", "stripper", "rape", "raped",
+	"murder", "murders", "murdered", "murderer", "murderous", "kill", "kills", "killed", "killer", "killing",
+	"slaughter", "massacre", "brutal", "brutally", "bloody", "gore", "gory", "torture", "tortured",
+	"drug", "drugs", "cocaine", "heroin", "meth", "overdose", "addict", "addiction",
+	"suicide", "serial killer",
+}, "|") + `)\b`)
+
+var (
+	overviewExtraSpaces = regexp.MustCompile(`[ \t]{2,}`)
+	overviewSpaceBefore = regexp.MustCompile(`\s+([,.;:!?])`)
+)
+
+// SetKidsProfiles enables certification filtering and overview sanitizing of
+// free-text searches made by kids profiles.
+func (h *MetadataHandler) SetKidsProfiles(lookup kidsProfileLookup) {
+	h.KidsProfiles = lookup
+}
+
+// SetKidsProfiles limits kids profiles to the automatically picked release.
+func (h *IndexerHandler) SetKidsProfiles(lookup kidsProfileLookup) {
+	h.KidsProfiles = lookup
+}
+
+// isKidsProfile reports whether userID names a kids profile.
+func isKidsProfile(lookup kidsProfileLookup, userID string) bool {
+	if lookup == nil || userID == "" {
+		return false
+	}
+	user, ok := lookup.Get(userID)
+	return ok && user.IsKidsProfile
+}
+
+// kidsSettings returns the configured kids limits, falling back to defaults.
+func (h *MetadataHandler) kidsSettings() config.KidsSettings {
+	if h.CfgManager != nil {
+		if settings, err := h.CfgManager.Load(); err == nil {
+			return settings.Kids
+		}
+	}
+	return config.DefaultSettings().Kids
+}
+
+// sanitizeKidsSearch drops results rated above the kids limits and strips
+// mature keywords from the remaining overviews. Results are copied so the
+// metadata cache isn't modified.
+func (h *MetadataHandler) sanitizeKidsSearch(ctx context.Context, results []models.SearchResult) []models.SearchResult {
+	limits := h.kidsSettings()
+	results = slices.Clone(results)
+	h.Service.AttachCertifications(ctx, results)
+
+	kept := make([]models.SearchResult, 0, len(results))
+	for _, result := range results {
+		if !kidsCertificationAllowed(result.Title, limits) {
+			continue
+		}
+		result.Title.Overview = sanitizeKidsOverview(result.Title.Overview)
+		kept = append(kept, result)
+	}
+	if filtered := len(results) - len(kept); filtered > 0 {
+		log.Printf("[kids] filtered %d/%d search results above the allowed certification", filtered, len(results))
+	}
+	return kept
+}
+
+// kidsCertificationAllowed reports whether a title's certification is at or
+// below the limit for its media type. Certifications outside the US scales
+// count as unrated.
+func kidsCertificationAllowed(title models.Title, limits config.KidsSettings) bool {
+	order, limit := tvRatingOrder, limits.MaxTVRating
+	if title.MediaType == "movie" {
+		order, limit = movieCertificationOrder, limits.MaxMovieCertification
+	}
+
+	rank := slices.Index(order, strings.ToUpper(strings.TrimSpace(title.Certification)))
+	if rank < 0 {
+		return limits.AllowUnrated
+	}
+	max := slices.Index(order, strings.ToUpper(strings.TrimSpace(limit)))
+	if max < 0 {
+		// An unrecognised limit allows nothing rather than everything
+		return false
+	}
+	return rank <= max
+}
+
+// sanitizeKidsOverview removes mature keywords from an overview and tidies
+// the whitespace and punctuation left behind.
+func sanitizeKidsOverview(overview string) string {
+	if overview == "" {
+		return overview
+	}
+	cleaned := matureOverviewKeywords.ReplaceAllString(overview, "")
+	cleaned = overviewExtraSpaces.ReplaceAllString(cleaned, " ")
+	cleaned = overviewSpaceBefore.ReplaceAllString(cleaned, "$1")
+	return strings.TrimSpace(cleaned)
+}
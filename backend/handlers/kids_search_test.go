@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+type fakeKidsProfiles map[string]bool
+
+func (f fakeKidsProfiles) Get(id string) (models.User, bool) {
+	isKids, ok := f[id]
+	return models.User{ID: id, IsKidsProfile: isKids}, ok
+}
+
+func TestMetadataHandler_SearchKidsProfile(t *testing.T) {
+	fake := &fakeMetadataService{
+		searchResp: []models.SearchResult{
+			{Title: models.Title{ID: "a", Name: "Cartoon", MediaType: "series", Overview: "A dog solves a murder , then naps."}},
+			{Title: models.Title{ID: "b", Name: "Crime Drama", MediaType: "series"}},
+			{Title: models.Title{ID: "c", Name: "Family Movie", MediaType: "movie"}},
+			{Title: models.Title{ID: "d", Name: "Unrated", MediaType: "movie"}},
+		},
+		certifications: map[string]string{"a": "TV-Y7", "b": "TV-MA", "c": "pg"},
+	}
+	handler := NewMetadataHandler(fake, testConfigManager(t))
+	handler.SetKidsProfiles(fakeKidsProfiles{"kid": true, "adult": false})
+
+	search := func(userID string) []models.SearchResult {
+		req := httptest.NewRequest(http.MethodGet, "/api/search?q=x&userId="+userID, nil)
+		rec := httptest.NewRecorder()
+		handler.Search(rec, req)
+		var payload []models.SearchResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		return payload
+	}
+
+	if got := search("adult"); len(got) != 4 {
+		t.Fatalf("adult profile got %d results, want 4", len(got))
+	}
+
+	got := search("kid")
+	if len(got) != 2 || got[0].Title.Name != "Cartoon" || got[1].Title.Name != "Family Movie" {
+		t.Fatalf("unexpected kids results: %+v", got)
+	}
+	if got[0].Title.Overview != "A dog solves a, then naps." {
+		t.Errorf("overview not sanitized: %q", got[0].Title.Overview)
+	}
+	if fake.searchResp[0].Title.Overview != "A dog solves a murder , then naps." {
+		t.Error("sanitizing modified the service's results")
+	}
+}
+
+func TestKidsCertificationAllowed(t *testing.T) {
+	limits := config.KidsSettings{MaxMovieCertification: "PG-13", MaxTVRating: "TV-G"}
+	cases := []struct {
+		mediaType, cert string
+		want            bool
+	}{
+		{"movie", "PG-13", true},
+		{"movie", "R", false},
+		{"series", "TV-G", true},
+		{"series", "TV-PG", false},
+		{"series", "PG", false}, // movie scale on a series counts as unrated
+		{"movie", "", false},
+	}
+	for _, c := range cases {
+		if got := kidsCertificationAllowed(models.Title{MediaType: c.mediaType, Certification: c.cert}, limits); got != c.want {
+			t.Errorf("%s %q: got %v, want %v", c.mediaType, c.cert, got, c.want)
+		}
+	}
+
+	limits.AllowUnrated = true
+	if !kidsCertificationAllowed(models.Title{MediaType: "movie"}, limits) {
+		t.Error("unrated movie rejected with AllowUnrated")
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -21,6 +20,7 @@ import (
 	"time"
 
 	"novastream/config"
+	"novastream/utils/sandbox"
 )
 
 const (
@@ -97,6 +97,7 @@ type LiveHandler struct {
 	analyzeDurationSec int  // FFmpeg analyzeduration in seconds (0 = default)
 	lowLatency         bool // Enable low-latency mode
 	cfgManager         *config.Manager
+	events             liveEventsService // Scheduled event recordings (optional)
 }
 
 // NewLiveHandler creates a handler capable of fetching remote playlists.
@@ -265,7 +266,7 @@ func (h *LiveHandler) StreamChannel(w http.ResponseWriter, r *http.Request) {
 		"pipe:1",
 	)
 
-	cmd := exec.CommandContext(ctx, h.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, h.ffmpegPath, args...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		http.Error(w, "failed to prepare live stream", http.StatusInternalServerError)
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/live_events"
+
+	"github.com/gorilla/mux"
+)
+
+type liveEventsService interface {
+	Create(accountID string, req models.LiveEventRequest) (*models.LiveEvent, error)
+	List() []models.LiveEvent
+	Get(id string) (*models.LiveEvent, error)
+	Finish(id string) error
+	Delete(id string) error
+	RecordingDir(id string) (string, error)
+	TimeshiftDir(id string) (string, error)
+}
+
+var _ liveEventsService = (*live_events.Service)(nil)
+
+// SetEventsService enables scheduled live event recordings.
+func (h *LiveHandler) SetEventsService(svc liveEventsService) {
+	h.events = svc
+}
+
+// ListEvents handles GET /api/live/events
+// Lists the events the calling account scheduled; the master account sees all.
+func (h *LiveHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	events := []models.LiveEvent{}
+	for _, event := range h.events.List() {
+		if ownsEvent(r, event) {
+			events = append(events, event)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events": events,
+	})
+}
+
+// CreateEvent handles POST /api/live/events
+// Schedules a channel to be recorded between startAt and endAt.
+func (h *LiveHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+	if !h.transmuxEnabled || h.ffmpegPath == "" {
+		writeJSONError(w, "live recording requires transmuxing with ffmpeg", http.StatusNotImplemented)
+		return
+	}
+
+	var req models.LiveEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.parseRemoteURL(req.StreamURL); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.events.Create(auth.GetAccountID(r), req)
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(event)
+}
+
+// GetEvent handles GET /api/live/events/{eventID}
+func (h *LiveHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	event, err := h.events.Get(mux.Vars(r)["eventID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+	if !ownsEvent(r, *event) {
+		writeJSONError(w, live_events.ErrEventNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// FinishEvent handles POST /api/live/events/{eventID}/finish
+// Stops an in-progress recording early and keeps it.
+func (h *LiveHandler) FinishEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	id := mux.Vars(r)["eventID"]
+	if !h.canManageEvent(w, r, id) {
+		return
+	}
+	if err := h.events.Finish(id); err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+
+	event, err := h.events.Get(id)
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// DeleteEvent handles DELETE /api/live/events/{eventID}
+// Cancels a scheduled event or deletes its recording.
+func (h *LiveHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	id := mux.Vars(r)["eventID"]
+	if !h.canManageEvent(w, r, id) {
+		return
+	}
+	if err := h.events.Delete(id); err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeEventRecording handles GET /api/live/events/{eventID}/recording/{file}
+// Serves the event's HLS playlist and segments. While the event is still
+// recording the playlist is an EVENT playlist, so players can seek back
+// (timeshift); once it ends the playlist is closed and behaves like VOD.
+func (h *LiveHandler) ServeEventRecording(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	event, err := h.events.Get(vars["eventID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+	if !ownsEvent(r, *event) {
+		writeJSONError(w, live_events.ErrEventNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	dir, err := h.events.RecordingDir(event.ID)
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+	serveRecordingFile(w, r, dir, vars["file"])
+}
+
+// ServeTimeshift handles GET /api/live/timeshift/{bufferID}/{file}
+// Serves a channel buffer kept while the channel is watched, which is what a
+// start from the beginning of the current program plays from.
+func (h *LiveHandler) ServeTimeshift(w http.ResponseWriter, r *http.Request) {
+	if !h.requireEvents(w) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	dir, err := h.events.TimeshiftDir(vars["bufferID"])
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return
+	}
+	serveRecordingFile(w, r, dir, vars["file"])
+}
+
+// serveRecordingFile serves a playlist or segment of an HLS recording. A
+// positive start query parameter asks players to begin that many seconds in.
+func serveRecordingFile(w http.ResponseWriter, r *http.Request, dir, name string) {
+	if name != filepath.Base(name) || (name != live_events.PlaylistName && !strings.HasSuffix(name, ".ts")) {
+		writeJSONError(w, "invalid recording file", http.StatusBadRequest)
+		return
+	}
+	path := filepath.Join(dir, name)
+
+	if name != live_events.PlaylistName {
+		w.Header().Set("Content-Type", "video/mp2t")
+		w.Header().Set("Cache-Control", "public, max-age=31536000")
+		http.ServeFile(w, r, path)
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		writeJSONError(w, "recording has not produced any segments yet", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, "failed to read recording playlist", http.StatusInternalServerError)
+		return
+	}
+
+	playlist := string(content)
+	if start, err := strconv.ParseFloat(r.URL.Query().Get("start"), 64); err == nil && start > 0 {
+		tag := fmt.Sprintf("#EXT-X-START:TIME-OFFSET=%.3f,PRECISE=YES", start)
+		playlist = strings.Replace(playlist, "#EXTM3U\n", "#EXTM3U\n"+tag+"\n", 1)
+	}
+	if token := strings.TrimSpace(r.URL.Query().Get("token")); token != "" {
+		// Segment requests need the auth token too
+		lines := strings.Split(playlist, "\n")
+		for i, line := range lines {
+			if strings.HasSuffix(strings.TrimSpace(line), ".ts") {
+				lines[i] = line + "?token=" + url.QueryEscape(token)
+			}
+		}
+		playlist = strings.Join(lines, "\n")
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(playlist))
+}
+
+func (h *LiveHandler) requireEvents(w http.ResponseWriter) bool {
+	if h.events == nil {
+		writeJSONError(w, "live event recording is not available", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// canManageEvent allows the account that scheduled an event, or the master
+// account, to stop or delete it.
+func (h *LiveHandler) canManageEvent(w http.ResponseWriter, r *http.Request, id string) bool {
+	event, err := h.events.Get(id)
+	if err != nil {
+		writeJSONError(w, err.Error(), liveEventErrorStatus(err))
+		return false
+	}
+	if !ownsEvent(r, *event) {
+		writeJSONError(w, "only the account that scheduled this event can change it", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// ownsEvent reports whether the calling account scheduled the event. The
+// master account owns every event.
+func ownsEvent(r *http.Request, event models.LiveEvent) bool {
+	return event.CreatedBy == "" || event.CreatedBy == auth.GetAccountID(r) || auth.IsMaster(r)
+}
+
+func liveEventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, live_events.ErrEventNotFound),
+		errors.Is(err, live_events.ErrBufferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, live_events.ErrStreamURLRequired),
+		errors.Is(err, live_events.ErrInvalidSchedule),
+		errors.Is(err, live_events.ErrEventEnded),
+		errors.Is(err, live_events.ErrEventTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, live_events.ErrNotRecording),
+		errors.Is(err, live_events.ErrNoRecording):
+		return http.StatusConflict
+	case errors.Is(err, live_events.ErrNotRunning):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/epg"
+	"novastream/services/live_events"
+)
+
+// liveStartProgram is the startAt value that starts live playback at the
+// beginning of the program currently airing.
+const liveStartProgram = "programStart"
+
+type liveTimeshiftService interface {
+	WatchChannel(streamURL string) error
+	TouchTimeshift(streamURL string)
+	Timeshift(streamURL, accountID string, at time.Time) (*models.LiveTimeshift, error)
+}
+
+type liveProgramGuide interface {
+	GetNowPlaying(channelIDs []string) []models.EPGNowPlaying
+}
+
+var (
+	_ liveTimeshiftService = (*live_events.Service)(nil)
+	_ liveProgramGuide     = (*epg.Service)(nil)
+)
+
+// SetLiveTimeshift enables starting live channels from the beginning of the
+// current program, using the guide to find when it started.
+func (h *VideoHandler) SetLiveTimeshift(svc liveTimeshiftService, guide liveProgramGuide) {
+	h.timeshift = svc
+	h.programGuide = guide
+}
+
+// programStartResponse builds the live play response for startAt=programStart.
+// It returns a reason instead when no timeshift cache reaches back to the start
+// of the current program, and the caller falls back to the live edge.
+func (h *VideoHandler) programStartResponse(r *http.Request, liveURL string) (map[string]interface{}, string) {
+	if h.timeshift == nil || h.programGuide == nil {
+		return nil, "timeshift is not available"
+	}
+	channelID := strings.TrimSpace(r.URL.Query().Get("channelId"))
+	if channelID == "" {
+		return nil, "channelId is required to find the current program"
+	}
+
+	var program *models.EPGProgram
+	if nowPlaying := h.programGuide.GetNowPlaying([]string{channelID}); len(nowPlaying) > 0 {
+		program = nowPlaying[0].Current
+	}
+	if program == nil {
+		return nil, "no guide data for the program currently airing"
+	}
+
+	cache, err := h.timeshift.Timeshift(liveURL, auth.GetAccountID(r), program.Start)
+	if err != nil {
+		if !errors.Is(err, live_events.ErrNotBuffered) {
+			log.Printf("[video] timeshift lookup failed for channel %s: %v", channelID, err)
+		}
+		return nil, "the channel has not been buffered since the program started"
+	}
+
+	offset := program.Start.Sub(cache.BufferedFrom).Seconds()
+	playlistURL := fmt.Sprintf("/live/timeshift/%s/%s?start=%.3f", cache.ID, live_events.PlaylistName, offset)
+	if cache.Source == models.LiveTimeshiftEvent {
+		playlistURL = fmt.Sprintf("/live/events/%s/recording/%s?start=%.3f", cache.ID, live_events.PlaylistName, offset)
+	}
+
+	return map[string]interface{}{
+		"playlistUrl": playlistURL,
+		"isLive":      true,
+		"startAt":     liveStartProgram,
+		"startOffset": offset,
+		"program":     program,
+		"timeshift":   cache,
+	}, ""
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/live_events"
+)
+
+type fakeTimeshift struct {
+	cache *models.LiveTimeshift
+}
+
+func (f fakeTimeshift) WatchChannel(string) error { return nil }
+
+func (f fakeTimeshift) TouchTimeshift(string) {}
+
+func (f fakeTimeshift) Timeshift(_, _ string, at time.Time) (*models.LiveTimeshift, error) {
+	if f.cache == nil || f.cache.BufferedFrom.After(at) {
+		return nil, live_events.ErrNotBuffered
+	}
+	return f.cache, nil
+}
+
+type fakeGuide struct {
+	program *models.EPGProgram
+}
+
+func (f fakeGuide) GetNowPlaying(channelIDs []string) []models.EPGNowPlaying {
+	return []models.EPGNowPlaying{{ChannelID: channelIDs[0], Current: f.program}}
+}
+
+func TestProgramStartResponse(t *testing.T) {
+	programStart := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	program := &models.EPGProgram{ChannelID: "news.uk", Title: "Evening News", Start: programStart, Stop: programStart.Add(time.Hour)}
+	buffer := &models.LiveTimeshift{Source: models.LiveTimeshiftBuffer, ID: "buf-1", BufferedFrom: programStart.Add(-90 * time.Second)}
+
+	tests := []struct {
+		name    string
+		url     string
+		cache   *models.LiveTimeshift
+		program *models.EPGProgram
+		want    string
+		reason  string
+	}{
+		{name: "buffered", url: "?url=http://tv/news&channelId=news.uk", cache: buffer, program: program, want: "/live/timeshift/buf-1/index.m3u8?start=90.000"},
+		{name: "event recording", url: "?url=http://tv/news&channelId=news.uk", program: program,
+			cache: &models.LiveTimeshift{Source: models.LiveTimeshiftEvent, ID: "evt-1", BufferedFrom: programStart},
+			want:  "/live/events/evt-1/recording/index.m3u8?start=0.000"},
+		{name: "buffer started after the program", url: "?url=http://tv/news&channelId=news.uk", program: program,
+			cache:  &models.LiveTimeshift{Source: models.LiveTimeshiftBuffer, ID: "buf-2", BufferedFrom: programStart.Add(time.Minute)},
+			reason: "buffered"},
+		{name: "no guide data", url: "?url=http://tv/news&channelId=news.uk", cache: buffer, reason: "guide"},
+		{name: "no channel", url: "?url=http://tv/news", cache: buffer, program: program, reason: "channelId"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewVideoHandler(true, "", "")
+			h.SetLiveTimeshift(fakeTimeshift{cache: tc.cache}, fakeGuide{program: tc.program})
+
+			req := httptest.NewRequest(http.MethodGet, "/live/hls/start"+tc.url+"&startAt=programStart", nil)
+			response, reason := h.programStartResponse(req, "http://tv/news")
+			if tc.reason != "" {
+				if response != nil || !strings.Contains(reason, tc.reason) {
+					t.Fatalf("expected a fallback mentioning %q, got %v (%q)", tc.reason, response, reason)
+				}
+				return
+			}
+			if response == nil {
+				t.Fatalf("expected a timeshift response, got %q", reason)
+			}
+			if response["playlistUrl"] != tc.want {
+				t.Errorf("expected playlist %s, got %v", tc.want, response["playlistUrl"])
+			}
+		})
+	}
+}
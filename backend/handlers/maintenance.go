@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"novastream/models"
+	"novastream/services/maintenance"
+)
+
+type maintenanceService interface {
+	Enabled() bool
+	Enable(drainTimeout time.Duration, message string) models.MaintenanceStatus
+	Disable() models.MaintenanceStatus
+	Status() models.MaintenanceStatus
+}
+
+var _ maintenanceService = (*maintenance.Service)(nil)
+
+// MaintenanceHandler lets admins put the server into maintenance mode and
+// follow stream draining before a restart or update
+type MaintenanceHandler struct {
+	svc maintenanceService
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(svc maintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{svc: svc}
+}
+
+// Status returns maintenance mode and drain progress
+func (h *MaintenanceHandler) Status(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Status())
+}
+
+// Enable turns maintenance mode on. Body (optional):
+// {"drainTimeoutSeconds": N, "message": "..."}
+func (h *MaintenanceHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DrainTimeoutSeconds int    `json:"drainTimeoutSeconds"`
+		Message             string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.DrainTimeoutSeconds < 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "drainTimeoutSeconds must not be negative"})
+		return
+	}
+
+	status := h.svc.Enable(time.Duration(req.DrainTimeoutSeconds)*time.Second, req.Message)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Disable turns maintenance mode off and resumes accepting playback
+func (h *MaintenanceHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Disable())
+}
+
+// Options handles CORS preflight requests
+func (h *MaintenanceHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
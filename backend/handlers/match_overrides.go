@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/match_overrides"
+
+	"github.com/gorilla/mux"
+)
+
+type matchOverridesService interface {
+	List() []models.MatchOverride
+	Set(accountID string, override models.MatchOverride) (*models.MatchOverride, error)
+	Delete(id string) error
+}
+
+var _ matchOverridesService = (*match_overrides.Service)(nil)
+
+// matchOverrideLookup finds manual corrections for mis-matched titles.
+type matchOverrideLookup interface {
+	Lookup(mediaType, titleID, name string, year int) (models.MatchOverride, bool)
+}
+
+var _ matchOverrideLookup = (*match_overrides.Service)(nil)
+
+// overrideIMDBID returns the IMDB ID to search indexers with. When the title
+// has a manual match correction, the client's IMDB ID came from the wrong
+// match, so the pinned one (possibly empty, forcing a text search) is used.
+func overrideIMDBID(lookup matchOverrideLookup, mediaType, titleID, name string, year int, imdbID string) string {
+	if lookup == nil {
+		return imdbID
+	}
+	if override, ok := lookup.Lookup(mediaType, titleID, name, year); ok {
+		return override.IMDBID
+	}
+	return imdbID
+}
+
+// MatchOverridesHandler lets admins pin titles to the correct TVDB/TMDB entry
+type MatchOverridesHandler struct {
+	svc matchOverridesService
+}
+
+// NewMatchOverridesHandler creates a new match overrides handler
+func NewMatchOverridesHandler(svc matchOverridesService) *MatchOverridesHandler {
+	return &MatchOverridesHandler{svc: svc}
+}
+
+// List handles GET /api/admin/metadata-overrides
+func (h *MatchOverridesHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List())
+}
+
+// Set handles PUT /api/admin/metadata-overrides
+// Creates or replaces the override for a title.
+func (h *MatchOverridesHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req models.MatchOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	override, err := h.svc.Set(auth.GetAccountID(r), req)
+	if err != nil {
+		writeJSONError(w, err.Error(), matchOverrideErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+// Delete handles DELETE /api/admin/metadata-overrides/{overrideID}
+func (h *MatchOverridesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(mux.Vars(r)["overrideID"]); err != nil {
+		writeJSONError(w, err.Error(), matchOverrideErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *MatchOverridesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func matchOverrideErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, match_overrides.ErrOverrideNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, match_overrides.ErrInvalidMediaType),
+		errors.Is(err, match_overrides.ErrTitleRequired),
+		errors.Is(err, match_overrides.ErrTargetIDRequired):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
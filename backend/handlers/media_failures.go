@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/media_failures"
+)
+
+// MediaFailureRecorder records ffprobe/transcode failures for aggregate metrics
+type MediaFailureRecorder interface {
+	RecordFailure(event models.MediaFailureEvent)
+}
+
+type mediaFailuresService interface {
+	MediaFailureRecorder
+	Summary(stage string, limit int) (*models.MediaFailureSummary, error)
+	Reset() error
+}
+
+var _ mediaFailuresService = (*media_failures.Service)(nil)
+
+// recordMediaFailure reports a failure for path to recorder, ignoring cancellations
+// (client disconnects and seeks) which aren't media problems.
+func recordMediaFailure(recorder MediaFailureRecorder, stage, streamPath, videoCodec string, err error) {
+	if recorder == nil || err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	recorder.RecordFailure(models.MediaFailureEvent{
+		Stage:      stage,
+		VideoCodec: videoCodec,
+		Container:  mediaFailureContainer(streamPath),
+		Provider:   mediaFailureProvider(streamPath),
+		Error:      err.Error(),
+	})
+}
+
+// mediaFailureContainer derives the container from the file extension of a stream path
+func mediaFailureContainer(streamPath string) string {
+	cleaned := streamPath
+	if idx := strings.IndexAny(cleaned, "?#"); idx >= 0 {
+		cleaned = cleaned[:idx]
+	}
+	return strings.TrimPrefix(strings.ToLower(path.Ext(cleaned)), ".")
+}
+
+// mediaFailureProvider identifies the source of a stream path: the debrid provider
+// for /debrid/<provider>/... paths, "external" for URLs, and "usenet" otherwise.
+func mediaFailureProvider(streamPath string) string {
+	trimmed := strings.TrimSpace(streamPath)
+	if strings.HasPrefix(trimmed, "http://") || strings.HasPrefix(trimmed, "https://") {
+		return "external"
+	}
+	trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "/webdav"), "/")
+	if strings.HasPrefix(trimmed, "debrid/") {
+		segments := strings.SplitN(strings.TrimPrefix(trimmed, "debrid/"), "/", 2)
+		if segments[0] != "" {
+			return strings.ToLower(segments[0])
+		}
+		return "debrid"
+	}
+	return "usenet"
+}
+
+// MediaFailuresHandler exposes aggregated probe/transcode failure statistics to admins
+type MediaFailuresHandler struct {
+	svc mediaFailuresService
+}
+
+// NewMediaFailuresHandler creates a new media failures handler
+func NewMediaFailuresHandler(svc mediaFailuresService) *MediaFailuresHandler {
+	return &MediaFailuresHandler{svc: svc}
+}
+
+// Summary returns failure totals by stage, codec, container and provider along with
+// the most frequent failure signatures. Query params: stage (probe|transcode), limit.
+func (h *MediaFailuresHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	summary, err := h.svc.Summary(r.URL.Query().Get("stage"), limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// Reset clears all recorded failure statistics
+func (h *MediaFailuresHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Reset(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *MediaFailuresHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import "testing"
+
+func TestMediaFailureProviderAndContainer(t *testing.T) {
+	tests := []struct {
+		path      string
+		provider  string
+		container string
+	}{
+		{"/debrid/realdebrid/ABC123/file/2", "realdebrid", ""},
+		{"/webdav/debrid/torbox/1/Movie.2019.2160p.mkv", "torbox", "mkv"},
+		{"https://cdn.example.com/video.mp4?token=abc", "external", "mp4"},
+		{"/streams/Show.S01E01.1080p.WEB.mkv", "usenet", "mkv"},
+	}
+
+	for _, tc := range tests {
+		if got := mediaFailureProvider(tc.path); got != tc.provider {
+			t.Errorf("mediaFailureProvider(%q) = %q, want %q", tc.path, got, tc.provider)
+		}
+		if got := mediaFailureContainer(tc.path); got != tc.container {
+			t.Errorf("mediaFailureContainer(%q) = %q, want %q", tc.path, got, tc.container)
+		}
+	}
+}
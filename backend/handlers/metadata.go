@@ -3,15 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"novastream/config"
 	"novastream/models"
+	"novastream/services/artwork"
+	"novastream/services/image_placeholders"
 	metadatapkg "novastream/services/metadata"
 )
 
@@ -30,10 +35,17 @@ type metadataService interface {
 	StreamTrailer(context.Context, string, io.Writer) error
 	StreamTrailerWithRange(context.Context, string, string, io.Writer) error
 	GetCustomList(ctx context.Context, listURL string, limit int) ([]models.TrendingItem, int, error)
+	Translations(context.Context, models.TitleTranslationsQuery) (*models.TitleTranslations, error)
 	// Trailer prequeue methods for 1080p YouTube trailers
 	PrequeueTrailer(videoURL string) (string, error)
 	GetTrailerPrequeueStatus(id string) (*metadatapkg.TrailerPrequeueItem, error)
 	ServePrequeuedTrailer(id string, w http.ResponseWriter, r *http.Request) error
+	// Home screen hero rotation
+	HeroRotation(ctx context.Context, watchlist []models.Title, trendingMovieSource config.TrendingMovieSource, limit int) (*models.HeroRotation, error)
+	// Composite MDBList scores for sorting rows by rating
+	AttachCompositeRatings(ctx context.Context, items []models.TrendingItem)
+	// US certifications for kids profile search filtering
+	AttachCertifications(ctx context.Context, results []models.SearchResult)
 }
 
 var _ metadataService = (*metadatapkg.Service)(nil)
@@ -43,6 +55,25 @@ type userSettingsProvider interface {
 	Get(userID string) (*models.UserSettings, error)
 }
 
+// artworkResolver swaps dead artwork URLs for cached substitutes.
+type artworkResolver interface {
+	Resolve(*models.Image) *models.Image
+}
+
+var _ artworkResolver = (*artwork.Service)(nil)
+
+// imagePlaceholderAttacher adds the placeholder computed by the image proxy.
+type imagePlaceholderAttacher interface {
+	Attach(*models.Image) *models.Image
+}
+
+var _ imagePlaceholderAttacher = (*image_placeholders.Service)(nil)
+
+// watchlistProvider lists a user's watchlist for the hero rotation.
+type watchlistProvider interface {
+	List(userID string) ([]models.WatchlistItem, error)
+}
+
 // historyServiceInterface provides access to watch history for filtering.
 type historyServiceInterface interface {
 	GetWatchHistoryItem(userID, mediaType, itemID string) (*models.WatchHistoryItem, error)
@@ -53,6 +84,12 @@ type MetadataHandler struct {
 	CfgManager     *config.Manager
 	UserSettings   userSettingsProvider
 	HistoryService historyServiceInterface
+	Artwork        artworkResolver
+	Placeholders   imagePlaceholderAttacher
+	Watchlist      watchlistProvider
+	SpoilerHolds   spoilerAnnotator
+	HiddenTitles   hiddenTitleMatcher
+	KidsProfiles   kidsProfileLookup
 }
 
 func NewMetadataHandler(s metadataService, cfgManager *config.Manager) *MetadataHandler {
@@ -69,6 +106,46 @@ func (h *MetadataHandler) SetHistoryService(service historyServiceInterface) {
 	h.HistoryService = service
 }
 
+// SetWatchlistService sets the watchlist used to seed the hero rotation.
+func (h *MetadataHandler) SetWatchlistService(service watchlistProvider) {
+	h.Watchlist = service
+}
+
+// SetArtworkResolver enables substituting fallback artwork for dead URLs.
+func (h *MetadataHandler) SetArtworkResolver(resolver artworkResolver) {
+	h.Artwork = resolver
+}
+
+// SetImagePlaceholders enables placeholders on title posters and backdrops.
+func (h *MetadataHandler) SetImagePlaceholders(placeholders imagePlaceholderAttacher) {
+	h.Placeholders = placeholders
+}
+
+// SetSpoilerAnnotator enables spoiler warnings on episodes held by other household profiles.
+func (h *MetadataHandler) SetSpoilerAnnotator(annotator spoilerAnnotator) {
+	h.SpoilerHolds = annotator
+}
+
+// SetHiddenTitles enables filtering titles a profile marked "not interested"
+// out of its trending, discovery and custom list rows.
+func (h *MetadataHandler) SetHiddenTitles(matcher hiddenTitleMatcher) {
+	h.HiddenTitles = matcher
+}
+
+// resolveArtwork points a title's poster and backdrop at working artwork and
+// attaches their placeholders. Images are replaced rather than modified since
+// they may be shared with the metadata cache.
+func (h *MetadataHandler) resolveArtwork(title *models.Title) {
+	if h.Artwork != nil {
+		title.Poster = h.Artwork.Resolve(title.Poster)
+		title.Backdrop = h.Artwork.Resolve(title.Backdrop)
+	}
+	if h.Placeholders != nil {
+		title.Poster = h.Placeholders.Attach(title.Poster)
+		title.Backdrop = h.Placeholders.Attach(title.Backdrop)
+	}
+}
+
 // DiscoverNewResponse wraps trending items with total count for pagination
 type DiscoverNewResponse struct {
 	Items           []models.TrendingItem `json:"items"`
@@ -81,6 +158,7 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
 	hideUnreleased := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("hideUnreleased"))) == "true"
 	hideWatched := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("hideWatched"))) == "true"
+	sortByRating := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort"))) == "rating"
 
 	// Parse optional pagination parameters
 	limit := 0
@@ -96,29 +174,7 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get trending movie source - prefer user settings, fall back to global settings
-	var trendingMovieSource config.TrendingMovieSource
-
-	// Try user-specific settings first
-	if userID != "" && h.UserSettings != nil {
-		if userSettings, err := h.UserSettings.Get(userID); err == nil && userSettings != nil {
-			if userSettings.HomeShelves.TrendingMovieSource != "" {
-				trendingMovieSource = config.TrendingMovieSource(userSettings.HomeShelves.TrendingMovieSource)
-			}
-		}
-	}
-
-	// Fall back to global settings
-	if trendingMovieSource == "" {
-		if settings, err := h.CfgManager.Load(); err == nil {
-			trendingMovieSource = settings.HomeShelves.TrendingMovieSource
-		}
-	}
-
-	// Default if still not set
-	if trendingMovieSource == "" {
-		trendingMovieSource = config.TrendingMovieSourceReleased
-	}
+	trendingMovieSource := h.trendingMovieSource(userID)
 
 	items, err := h.Service.Trending(r.Context(), mediaType, trendingMovieSource)
 	if err != nil {
@@ -128,6 +184,9 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Hidden titles never count towards the row
+	items = filterHiddenItems(items, h.hiddenMatcher(userID))
+
 	// Track pre-filter total for explore card logic
 	unfilteredTotal := len(items)
 
@@ -141,6 +200,10 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 		items = filterWatchedItems(items, userID, h.HistoryService)
 	}
 
+	if sortByRating {
+		items = h.sortItemsByRating(r.Context(), items)
+	}
+
 	// Apply pagination
 	total := len(items)
 	if offset > 0 {
@@ -154,6 +217,13 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 		items = items[:limit]
 	}
 
+	if h.Artwork != nil || h.Placeholders != nil {
+		items = slices.Clone(items)
+		for i := range items {
+			h.resolveArtwork(&items[i].Title)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	resp := DiscoverNewResponse{Items: items, Total: total}
 	if hideUnreleased || hideWatched {
@@ -162,9 +232,125 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// specialsMode returns how userID wants a series' specials arranged.
+func (h *MetadataHandler) specialsMode(userID string) string {
+	if userID != "" && h.UserSettings != nil {
+		if userSettings, err := h.UserSettings.Get(userID); err == nil && userSettings != nil {
+			return models.NormalizeSpecialsMode(userSettings.Specials.Mode)
+		}
+	}
+	return models.SpecialsSeparate
+}
+
+// trendingMovieSource returns the trending movie source for userID,
+// preferring user settings and falling back to global settings.
+func (h *MetadataHandler) trendingMovieSource(userID string) config.TrendingMovieSource {
+	var trendingMovieSource config.TrendingMovieSource
+
+	// Try user-specific settings first
+	if userID != "" && h.UserSettings != nil {
+		if userSettings, err := h.UserSettings.Get(userID); err == nil && userSettings != nil {
+			if userSettings.HomeShelves.TrendingMovieSource != "" {
+				trendingMovieSource = config.TrendingMovieSource(userSettings.HomeShelves.TrendingMovieSource)
+			}
+		}
+	}
+
+	// Fall back to global settings
+	if trendingMovieSource == "" && h.CfgManager != nil {
+		if settings, err := h.CfgManager.Load(); err == nil {
+			trendingMovieSource = settings.HomeShelves.TrendingMovieSource
+		}
+	}
+
+	// Default if still not set
+	if trendingMovieSource == "" {
+		trendingMovieSource = config.TrendingMovieSourceReleased
+	}
+	return trendingMovieSource
+}
+
+// HeroRotation returns the rotating hero set for the home screen: trending
+// and watchlist titles with validated backdrops, logos and trailer streams.
+func (h *MetadataHandler) HeroRotation(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	var watchlist []models.Title
+	if userID != "" && h.Watchlist != nil {
+		items, err := h.Watchlist.List(userID)
+		if err != nil {
+			log.Printf("[metadata] hero rotation: watchlist for user %s failed: %v", userID, err)
+		}
+		for _, item := range items {
+			watchlist = append(watchlist, watchlistHeroTitle(item))
+		}
+	}
+
+	rotation, err := h.Service.HeroRotation(r.Context(), watchlist, h.trendingMovieSource(userID), limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	resp := *rotation
+	if hidden := h.hiddenMatcher(userID); hidden != nil {
+		items := make([]models.HeroItem, 0, len(resp.Items))
+		for _, item := range resp.Items {
+			if item.Source == "watchlist" || !hidden(item.Title) {
+				items = append(items, item)
+			}
+		}
+		resp.Items = items
+	}
+	if h.Artwork != nil || h.Placeholders != nil {
+		resp.Items = slices.Clone(resp.Items)
+		for i := range resp.Items {
+			h.resolveArtwork(&resp.Items[i].Title)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// watchlistHeroTitle converts a watchlist entry into the title fields the
+// hero rotation needs to look up artwork and trailers.
+func watchlistHeroTitle(item models.WatchlistItem) models.Title {
+	title := models.Title{
+		ID:        item.ID,
+		Name:      item.Name,
+		Overview:  item.Overview,
+		Year:      item.Year,
+		MediaType: item.MediaType,
+		IMDBID:    item.ExternalIDs["imdb"],
+	}
+	if id, err := strconv.ParseInt(item.ExternalIDs["tmdb"], 10, 64); err == nil {
+		title.TMDBID = id
+	}
+	if id, err := strconv.ParseInt(item.ExternalIDs["tvdb"], 10, 64); err == nil {
+		title.TVDBID = id
+	}
+	if item.PosterURL != "" {
+		title.Poster = &models.Image{URL: item.PosterURL, Type: "poster"}
+	}
+	if item.BackdropURL != "" {
+		title.Backdrop = &models.Image{URL: item.BackdropURL, Type: "backdrop"}
+	}
+	return title
+}
+
 func (h *MetadataHandler) Search(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	mediaType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
+	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
 	results, err := h.Service.Search(r.Context(), q, mediaType)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -172,6 +358,15 @@ func (h *MetadataHandler) Search(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	if isKidsProfile(h.KidsProfiles, userID) {
+		results = h.sanitizeKidsSearch(r.Context(), results)
+	}
+	if h.Artwork != nil || h.Placeholders != nil {
+		results = slices.Clone(results)
+		for i := range results {
+			h.resolveArtwork(&results[i].Title)
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
 }
@@ -218,6 +413,16 @@ func (h *MetadataHandler) SeriesDetails(w http.ResponseWriter, r *http.Request)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	if h.Artwork != nil || h.Placeholders != nil {
+		resolved := *details
+		h.resolveArtwork(&resolved.Title)
+		details = &resolved
+	}
+	userID := strings.TrimSpace(query.Get("userId"))
+	details = models.ApplySpecialsMode(details, h.specialsMode(userID))
+	if h.SpoilerHolds != nil {
+		details = h.SpoilerHolds.Annotate(userID, details)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(details)
@@ -304,11 +509,68 @@ func (h *MetadataHandler) MovieDetails(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	if h.Artwork != nil || h.Placeholders != nil {
+		resolved := *details
+		h.resolveArtwork(&resolved)
+		details = &resolved
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(details)
 }
 
+// Translations returns a title's name and overview in several languages at once.
+// Query: type=series|movie, languages=en,de plus the usual titleId/name/year/tvdbId/tmdbId identifiers.
+func (h *MetadataHandler) Translations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var languages []string
+	for _, raw := range query["languages"] {
+		for _, lang := range strings.Split(raw, ",") {
+			if lang = strings.TrimSpace(lang); lang != "" {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	if len(languages) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "languages parameter is required"})
+		return
+	}
+
+	year, _ := strconv.Atoi(strings.TrimSpace(query.Get("year")))
+	tvdbID, _ := strconv.ParseInt(strings.TrimSpace(query.Get("tvdbId")), 10, 64)
+	tmdbID, _ := strconv.ParseInt(strings.TrimSpace(query.Get("tmdbId")), 10, 64)
+
+	req := models.TitleTranslationsQuery{
+		MediaType: strings.ToLower(strings.TrimSpace(query.Get("type"))),
+		TitleID:   strings.TrimSpace(query.Get("titleId")),
+		Name:      strings.TrimSpace(query.Get("name")),
+		Year:      year,
+		TVDBID:    tvdbID,
+		TMDBID:    tmdbID,
+		Languages: languages,
+	}
+
+	bundle, err := h.Service.Translations(r.Context(), req)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, metadatapkg.ErrUnsupportedMediaType) ||
+			errors.Is(err, metadatapkg.ErrLanguagesRequired) ||
+			errors.Is(err, metadatapkg.ErrTooManyLanguages) {
+			status = http.StatusBadRequest
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
 func (h *MetadataHandler) CollectionDetails(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -378,10 +640,18 @@ func (h *MetadataHandler) Similar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	titles = filterHiddenTitles(titles, h.hiddenMatcher(strings.TrimSpace(query.Get("userId"))))
+
 	// Return empty array instead of null if no results
 	if titles == nil {
 		titles = []models.Title{}
 	}
+	if h.Artwork != nil || h.Placeholders != nil {
+		titles = slices.Clone(titles)
+		for i := range titles {
+			h.resolveArtwork(&titles[i])
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(titles)
@@ -723,6 +993,22 @@ func filterWatchedItems(items []models.TrendingItem, userID string, historySvc h
 	return result
 }
 
+// sortItemsByRating orders a row by composite rating, highest first. Items
+// the service couldn't rate keep their original order after the rated ones.
+// The slice is copied so cached rows aren't reordered or annotated.
+func (h *MetadataHandler) sortItemsByRating(ctx context.Context, items []models.TrendingItem) []models.TrendingItem {
+	items = slices.Clone(items)
+	h.Service.AttachCompositeRatings(ctx, items)
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Title.CompositeRating, items[j].Title.CompositeRating
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+	return items
+}
+
 // buildItemIDForHistory constructs the item ID used in watch history from a TrendingItem.
 // Format: "tmdb:movie:12345" or "tvdb:123456" or "tmdb:tv:67890"
 func buildItemIDForHistory(item models.TrendingItem) string {
@@ -762,6 +1048,7 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
 	hideUnreleased := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("hideUnreleased"))) == "true"
 	hideWatched := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("hideWatched"))) == "true"
+	sortByRating := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort"))) == "rating"
 
 	// Parse optional pagination parameters (0 = no limit/offset)
 	limit := 0
@@ -793,8 +1080,10 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 
 	// When hideUnreleased or hideWatched is true, we need ALL items to get accurate filtered count
 	// Otherwise, fetch only what we need for pagination
+	// The same goes for hidden titles and sorting by rating
+	hidden := h.hiddenMatcher(userID)
 	fetchLimit := 0 // 0 = fetch all
-	if !hideUnreleased && !hideWatched {
+	if !hideUnreleased && !hideWatched && hidden == nil && !sortByRating {
 		if limit > 0 && offset > 0 {
 			fetchLimit = limit + offset
 		} else if limit > 0 {
@@ -810,6 +1099,11 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if hidden != nil {
+		items = filterHiddenItems(items, hidden)
+		total = len(items)
+	}
+
 	// Track pre-filter total for explore card logic
 	unfilteredTotal := total
 
@@ -825,6 +1119,10 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 		total = len(items)
 	}
 
+	if sortByRating {
+		items = h.sortItemsByRating(r.Context(), items)
+	}
+
 	// Apply offset
 	if offset > 0 {
 		if offset >= len(items) {
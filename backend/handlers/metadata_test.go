@@ -17,20 +17,25 @@ import (
 )
 
 type fakeMetadataService struct {
-	trendingResp []models.TrendingItem
-	trendingErr  error
-	searchResp   []models.SearchResult
-	searchErr    error
-	seriesResp   *models.SeriesDetails
-	seriesErr    error
-	movieResp    *models.Title
-	movieErr     error
-
-	lastTrendingType string
-	lastSearchQuery  string
-	lastSearchType   string
-	lastSeriesQuery  models.SeriesDetailsQuery
-	lastMovieQuery   models.MovieDetailsQuery
+	trendingResp    []models.TrendingItem
+	trendingErr     error
+	searchResp      []models.SearchResult
+	searchErr       error
+	seriesResp      *models.SeriesDetails
+	seriesErr       error
+	movieResp       *models.Title
+	movieErr        error
+	translationsErr error
+
+	compositeRatings map[string]float64
+	certifications   map[string]string
+
+	lastTrendingType      string
+	lastSearchQuery       string
+	lastSearchType        string
+	lastSeriesQuery       models.SeriesDetailsQuery
+	lastMovieQuery        models.MovieDetailsQuery
+	lastTranslationsQuery models.TitleTranslationsQuery
 }
 
 func (f *fakeMetadataService) Trending(_ context.Context, mediaType string, _ config.TrendingMovieSource) ([]models.TrendingItem, error) {
@@ -124,10 +129,42 @@ func (f *fakeMetadataService) ServePrequeuedTrailer(_ string, _ http.ResponseWri
 	return nil
 }
 
+func (f *fakeMetadataService) Translations(_ context.Context, query models.TitleTranslationsQuery) (*models.TitleTranslations, error) {
+	f.lastTranslationsQuery = query
+	if f.translationsErr != nil {
+		return nil, f.translationsErr
+	}
+	bundle := &models.TitleTranslations{MediaType: query.MediaType}
+	for _, lang := range query.Languages {
+		bundle.Translations = append(bundle.Translations, models.LocalizedText{Language: lang, Name: "name-" + lang})
+	}
+	return bundle, nil
+}
+
 func (f *fakeMetadataService) PersonDetails(_ context.Context, _ int64) (*models.PersonDetails, error) {
 	return nil, nil
 }
 
+func (f *fakeMetadataService) HeroRotation(_ context.Context, _ []models.Title, _ config.TrendingMovieSource, _ int) (*models.HeroRotation, error) {
+	return &models.HeroRotation{Items: []models.HeroItem{}}, nil
+}
+
+func (f *fakeMetadataService) AttachCompositeRatings(_ context.Context, items []models.TrendingItem) {
+	for i := range items {
+		if score, ok := f.compositeRatings[items[i].Title.ID]; ok {
+			items[i].Title.CompositeRating = &score
+		}
+	}
+}
+
+func (f *fakeMetadataService) AttachCertifications(_ context.Context, results []models.SearchResult) {
+	for i := range results {
+		if cert, ok := f.certifications[results[i].Title.ID]; ok {
+			results[i].Title.Certification = cert
+		}
+	}
+}
+
 func (f *fakeMetadataService) Similar(_ context.Context, _ string, _ int64) ([]models.Title, error) {
 	return nil, nil
 }
@@ -175,6 +212,36 @@ func TestMetadataHandler_DiscoverNew(t *testing.T) {
 	}
 }
 
+func TestMetadataHandler_DiscoverNewSortByRating(t *testing.T) {
+	fake := &fakeMetadataService{
+		trendingResp: []models.TrendingItem{
+			{Rank: 1, Title: models.Title{ID: "a", Name: "Unrated"}},
+			{Rank: 2, Title: models.Title{ID: "b", Name: "Good"}},
+			{Rank: 3, Title: models.Title{ID: "c", Name: "Great"}},
+		},
+		compositeRatings: map[string]float64{"b": 71.5, "c": 92},
+	}
+	handler := NewMetadataHandler(fake, testConfigManager(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/discover/new?type=movie&sort=rating&limit=2", nil)
+	rec := httptest.NewRecorder()
+	handler.DiscoverNew(rec, req)
+
+	var payload DiscoverNewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Total != 3 || len(payload.Items) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if payload.Items[0].Title.Name != "Great" || payload.Items[1].Title.Name != "Good" {
+		t.Fatalf("items not sorted by rating: %s, %s", payload.Items[0].Title.Name, payload.Items[1].Title.Name)
+	}
+	if fake.trendingResp[0].Title.Name != "Unrated" || fake.trendingResp[1].Title.CompositeRating != nil {
+		t.Fatal("sorting modified the service's row")
+	}
+}
+
 func TestMetadataHandler_DiscoverNewError(t *testing.T) {
 	fake := &fakeMetadataService{trendingErr: errors.New("tmdb unavailable")}
 	handler := NewMetadataHandler(fake, testConfigManager(t))
@@ -298,3 +365,44 @@ func TestMetadataHandler_MovieDetailsError(t *testing.T) {
 		t.Fatalf("expected error payload, got %+v", payload)
 	}
 }
+
+func TestMetadataHandler_Translations(t *testing.T) {
+	fake := &fakeMetadataService{}
+	handler := NewMetadataHandler(fake, testConfigManager(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/metadata/translations?type=series&tvdbId=81189&languages=en,de&languages=fr", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Translations(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if fake.lastTranslationsQuery.TVDBID != 81189 {
+		t.Fatalf("expected tvdb id to be forwarded, got %d", fake.lastTranslationsQuery.TVDBID)
+	}
+	if got := fake.lastTranslationsQuery.Languages; len(got) != 3 || got[0] != "en" || got[1] != "de" || got[2] != "fr" {
+		t.Fatalf("unexpected languages %v", got)
+	}
+
+	var payload models.TitleTranslations
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if len(payload.Translations) != 3 {
+		t.Fatalf("expected 3 translations, got %d", len(payload.Translations))
+	}
+}
+
+func TestMetadataHandler_TranslationsRequiresLanguages(t *testing.T) {
+	handler := NewMetadataHandler(&fakeMetadataService{}, testConfigManager(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/metadata/translations?type=movie&tvdbId=1", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Translations(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"novastream/models"
+	"novastream/services/orphan_gc"
+)
+
+type orphanGCService interface {
+	Collect(ctx context.Context, dryRun bool) (models.OrphanGCReport, error)
+	LastReport() (models.OrphanGCReport, bool)
+}
+
+var _ orphanGCService = (*orphan_gc.Service)(nil)
+
+// OrphanGCHandler reports on and runs the orphaned record garbage collector
+type OrphanGCHandler struct {
+	svc orphanGCService
+}
+
+// NewOrphanGCHandler creates a new orphan garbage collection handler
+func NewOrphanGCHandler(svc orphanGCService) *OrphanGCHandler {
+	return &OrphanGCHandler{svc: svc}
+}
+
+// Report handles GET /api/admin/orphan-gc
+// Returns the last pass since startup, or 204 if none has run.
+func (h *OrphanGCHandler) Report(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.svc.LastReport()
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Run handles POST /api/admin/orphan-gc?dryRun=true
+// Runs a pass now and returns its report. A dry run removes nothing.
+func (h *OrphanGCHandler) Run(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	report, err := h.svc.Collect(r.Context(), dryRun)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, orphan_gc.ErrRunning) {
+			code = http.StatusConflict
+		}
+		writeJSONError(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Options handles CORS preflight requests
+func (h *OrphanGCHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"novastream/internal/auth"
 	"novastream/models"
 	playbacksvc "novastream/services/playback"
+	release_versions "novastream/services/release_versions"
 )
 
 type playbackService interface {
@@ -22,8 +24,14 @@ type playbackService interface {
 // PlaybackHandler resolves NZB candidates into playable streams via the local registry.
 type PlaybackHandler struct {
 	Service           playbackService
-	SubtitleExtractor SubtitlePreExtractor // For pre-extracting subtitles
-	VideoProber       VideoFullProber      // For probing subtitle streams
+	SubtitleExtractor SubtitlePreExtractor  // For pre-extracting subtitles
+	VideoProber       VideoFullProber       // For probing subtitle streams
+	ReleaseVersions   releaseVersionLookup  // For resolving pinned release versions
+	Profiles          profileAccountChecker // For checking pinned versions belong to the caller
+}
+
+type releaseVersionLookup interface {
+	Get(userID, contentID, versionID string) (*models.ReleaseVersion, error)
 }
 
 var _ playbackService = (*playbacksvc.Service)(nil)
@@ -42,11 +50,23 @@ func (h *PlaybackHandler) SetVideoProber(prober VideoFullProber) {
 	h.VideoProber = prober
 }
 
+// SetReleaseVersions sets the pinned release version store used by the version selector,
+// and the profile lookup that keeps an account to its own profiles' versions
+func (h *PlaybackHandler) SetReleaseVersions(versions releaseVersionLookup, profiles profileAccountChecker) {
+	h.ReleaseVersions = versions
+	h.Profiles = profiles
+}
+
 // Resolve accepts an NZB indexer result and responds with a validated playback source.
 func (h *PlaybackHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		Result      models.NZBResult `json:"result"`
 		StartOffset float64          `json:"startOffset,omitempty"` // Seek position in seconds for subtitle extraction
+		// Version selector: play a pinned release instead of result.
+		// An empty versionId with a contentId plays the default version.
+		UserID    string `json:"userId,omitempty"`
+		ContentID string `json:"contentId,omitempty"`
+		VersionID string `json:"versionId,omitempty"`
 	}
 
 	dec := json.NewDecoder(r.Body)
@@ -56,6 +76,30 @@ func (h *PlaybackHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	versionID := ""
+	if request.ContentID != "" {
+		if h.ReleaseVersions == nil || h.Profiles == nil {
+			http.Error(w, "release versions not available", http.StatusServiceUnavailable)
+			return
+		}
+		if !auth.IsMaster(r) && !h.Profiles.BelongsToAccount(request.UserID, auth.GetAccountID(r)) {
+			http.Error(w, release_versions.ErrVersionNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		version, err := h.ReleaseVersions.Get(request.UserID, request.ContentID, request.VersionID)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, release_versions.ErrVersionNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		request.Result = version.Result
+		versionID = version.ID
+		log.Printf("[playback-handler] Resolving pinned version %q (%s) for %s", version.Label, version.ID, request.ContentID)
+	}
+
 	handlerStart := time.Now()
 	log.Printf("[playback-handler] TIMING: Received resolve request: Title=%q, GUID=%q, ServiceType=%q, titleId=%q, titleName=%q, startOffset=%.2f",
 		request.Result.Title, request.Result.GUID, request.Result.ServiceType,
@@ -64,10 +108,14 @@ func (h *PlaybackHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 	resolution, err := h.Service.Resolve(r.Context(), request.Result)
 	if err != nil {
 		log.Printf("[playback-handler] TIMING: resolve failed after %v: %v", time.Since(handlerStart), err)
+		if writeDiskSpaceError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
 	log.Printf("[playback-handler] TIMING: resolve complete (took: %v)", time.Since(handlerStart))
+	resolution.VersionID = versionID
 
 	// Pre-extract subtitles for direct streaming (non-HLS) path
 	if h.SubtitleExtractor != nil && h.VideoProber != nil && resolution.WebDAVPath != "" {
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/devices"
+	"novastream/services/playback_compat"
+
+	"github.com/gorilla/mux"
+)
+
+type playbackCompatService interface {
+	Clips() []models.CompatClip
+	ClipPath(ctx context.Context, id string) (string, error)
+	Summarize(results map[string]bool) (models.DeviceCompatibilityTest, error)
+}
+
+var _ playbackCompatService = (*playback_compat.Service)(nil)
+
+type compatDeviceService interface {
+	DeviceProvider
+	RecordCompatibility(accountID, id string, test models.DeviceCompatibilityTest) (*models.Device, error)
+}
+
+var _ compatDeviceService = (*devices.Service)(nil)
+
+// PlaybackCompatHandler serves the playback compatibility test suite and
+// applies a device's results to its capability profile
+type PlaybackCompatHandler struct {
+	svc     playbackCompatService
+	devices compatDeviceService
+}
+
+// NewPlaybackCompatHandler creates a new playback compatibility handler
+func NewPlaybackCompatHandler(svc playbackCompatService, devices compatDeviceService) *PlaybackCompatHandler {
+	return &PlaybackCompatHandler{svc: svc, devices: devices}
+}
+
+type compatSuiteResponse struct {
+	Clips    []models.CompatClip             `json:"clips"`
+	LastTest *models.DeviceCompatibilityTest `json:"lastTest,omitempty"`
+}
+
+type compatReportRequest struct {
+	DeviceID string          `json:"deviceId"`
+	Results  map[string]bool `json:"results"` // Clip ID -> played
+}
+
+// Suite handles GET /api/devices/compatibility
+// Lists the test clips with the URL to play each from, and the calling
+// device's last results. The device is the X-Client-ID header or clientId
+// query param.
+func (h *PlaybackCompatHandler) Suite(w http.ResponseWriter, r *http.Request) {
+	resp := compatSuiteResponse{Clips: h.svc.Clips()}
+	for i := range resp.Clips {
+		if resp.Clips[i].Available {
+			resp.Clips[i].URL = "/api/devices/compatibility/clips/" + resp.Clips[i].ID
+		}
+	}
+
+	if id := compatDeviceID(r, ""); id != "" {
+		device, err := h.devices.Get(id)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if device != nil && (device.AccountID == "" || device.AccountID == auth.GetAccountID(r)) {
+			resp.LastTest = device.Capabilities.CompatibilityTest
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ServeClip handles GET /api/devices/compatibility/clips/{clipID}
+// Clips are generated on first request, which takes a few seconds.
+func (h *PlaybackCompatHandler) ServeClip(w http.ResponseWriter, r *http.Request) {
+	path, err := h.svc.ClipPath(r.Context(), mux.Vars(r)["clipID"])
+	if err != nil {
+		status := compatErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Printf("[playback-compat] %v", err)
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+// Report handles POST /api/devices/compatibility
+// Records which clips played and updates the device's capabilities so
+// direct play negotiation uses what the device actually played. The device
+// ID falls back to the X-Client-ID header.
+func (h *PlaybackCompatHandler) Report(w http.ResponseWriter, r *http.Request) {
+	var req compatReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Results) == 0 {
+		writeJSONError(w, "results are required", http.StatusBadRequest)
+		return
+	}
+
+	test, err := h.svc.Summarize(req.Results)
+	if err != nil {
+		writeJSONError(w, err.Error(), compatErrorStatus(err))
+		return
+	}
+
+	id := compatDeviceID(r, req.DeviceID)
+	if id == "" {
+		writeJSONError(w, devices.ErrDeviceIDRequired.Error(), http.StatusBadRequest)
+		return
+	}
+	device, err := h.devices.RecordCompatibility(auth.GetAccountID(r), id, test)
+	if err != nil {
+		writeJSONError(w, err.Error(), deviceErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// Options handles CORS preflight requests
+func (h *PlaybackCompatHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func compatDeviceID(r *http.Request, id string) string {
+	if id = strings.TrimSpace(id); id != "" {
+		return id
+	}
+	if id = strings.TrimSpace(r.Header.Get("X-Client-ID")); id != "" {
+		return id
+	}
+	return strings.TrimSpace(r.URL.Query().Get("clientId"))
+}
+
+func compatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, playback_compat.ErrClipNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, playback_compat.ErrClipUnavailable):
+		return http.StatusNotImplemented
+	case errors.Is(err, playback_compat.ErrUnknownClip),
+		errors.Is(err, playback_compat.ErrBaselineFailed):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/playback_queue"
+
+	"github.com/gorilla/mux"
+)
+
+type playbackQueueService interface {
+	List(profileID string) []models.PlaybackQueueItem
+	Add(profileID string, req models.PlaybackQueueItemRequest) (*models.PlaybackQueueItem, error)
+	Remove(profileID, itemID string) error
+	Reorder(profileID string, itemIDs []string) ([]models.PlaybackQueueItem, error)
+	Clear(profileID string) error
+}
+
+var _ playbackQueueService = (*playback_queue.Service)(nil)
+
+// PlaybackQueueHandler manages a profile's queue of items to play back-to-back
+type PlaybackQueueHandler struct {
+	svc   playbackQueueService
+	users userService
+}
+
+// NewPlaybackQueueHandler creates a new playback queue handler
+func NewPlaybackQueueHandler(svc playbackQueueService, users userService) *PlaybackQueueHandler {
+	return &PlaybackQueueHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/playback-queue
+func (h *PlaybackQueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List(userID))
+}
+
+// Add handles POST /api/users/{userID}/playback-queue
+func (h *PlaybackQueueHandler) Add(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.PlaybackQueueItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.svc.Add(userID, req)
+	if err != nil {
+		writeJSONError(w, err.Error(), playbackQueueErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// Reorder handles PUT /api/users/{userID}/playback-queue/order
+func (h *PlaybackQueueHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.PlaybackQueueReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.svc.Reorder(userID, req.ItemIDs)
+	if err != nil {
+		writeJSONError(w, err.Error(), playbackQueueErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// Remove handles DELETE /api/users/{userID}/playback-queue/{itemID}
+func (h *PlaybackQueueHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Remove(userID, mux.Vars(r)["itemID"]); err != nil {
+		writeJSONError(w, err.Error(), playbackQueueErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Clear handles DELETE /api/users/{userID}/playback-queue
+func (h *PlaybackQueueHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Clear(userID); err != nil {
+		writeJSONError(w, err.Error(), playbackQueueErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *PlaybackQueueHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *PlaybackQueueHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func playbackQueueErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, playback_queue.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, playback_queue.ErrQueueFull):
+		return http.StatusConflict
+	case errors.Is(err, playback_queue.ErrProfileIDRequired),
+		errors.Is(err, playback_queue.ErrTitleIDRequired),
+		errors.Is(err, playback_queue.ErrInvalidMediaType),
+		errors.Is(err, playback_queue.ErrEpisodeRequired),
+		errors.Is(err, playback_queue.ErrInvalidOrder):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/handlers"
+	"novastream/internal/auth"
+	"novastream/models"
+)
+
+type fakeReleaseVersions struct {
+	calls int
+}
+
+func (f *fakeReleaseVersions) Get(userID, contentID, versionID string) (*models.ReleaseVersion, error) {
+	f.calls++
+	return nil, errors.New("lookup reached")
+}
+
+func TestPlaybackHandler_ResolveRejectsOtherAccountsVersions(t *testing.T) {
+	versions := &fakeReleaseVersions{}
+	handler := handlers.NewPlaybackHandler(nil)
+	handler.SetReleaseVersions(versions, fakeAccountUserService{"mine": "acct-1", "theirs": "acct-2"})
+
+	resolve := func(userID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"userId": userID, "contentId": "tmdb:movie:603"})
+		req := httptest.NewRequest(http.MethodPost, "/api/playback/resolve", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), auth.ContextKeyAccountID, "acct-1"))
+		rec := httptest.NewRecorder()
+		handler.Resolve(rec, req)
+		return rec
+	}
+
+	if rec := resolve("theirs"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for another account's profile, got %d", rec.Code)
+	}
+	if versions.calls != 0 {
+		t.Fatalf("expected no version lookup for another account's profile, got %d", versions.calls)
+	}
+
+	if rec := resolve("mine"); rec.Code != http.StatusBadRequest || versions.calls != 1 {
+		t.Fatalf("expected the caller's own profile to reach the version lookup, got %d (%d calls)", rec.Code, versions.calls)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/playback"
+	"novastream/services/playback_timing"
+)
+
+// StartTimingRecorder records how long each stage of a playback start takes
+type StartTimingRecorder interface {
+	BeginAttempt(attemptID, titleName, mediaType string)
+	RecordSpan(attemptID, stage string, startedAt time.Time)
+	LinkHLSSession(attemptID, sessionID string)
+	FinishAttempt(attemptID, outcome, errMsg string)
+}
+
+// FirstSegmentRecorder is notified when an HLS session serves a segment, to
+// time the first one
+type FirstSegmentRecorder interface {
+	FirstSegmentServed(sessionID string)
+}
+
+type playbackTimingService interface {
+	StartTimingRecorder
+	FirstSegmentRecorder
+	Summary(limit int) *models.PlaybackTimingSummary
+	Reset() error
+}
+
+var _ playbackTimingService = (*playback_timing.Service)(nil)
+
+// PlaybackTimingHandler exposes per-stage playback start latency to admins
+type PlaybackTimingHandler struct {
+	svc playbackTimingService
+}
+
+// NewPlaybackTimingHandler creates a new playback timing handler
+func NewPlaybackTimingHandler(svc playbackTimingService) *PlaybackTimingHandler {
+	return &PlaybackTimingHandler{svc: svc}
+}
+
+// Summary returns p50/p95 timings per start stage along with the most recent
+// attempts. Query params: limit (recent attempts).
+func (h *PlaybackTimingHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Summary(limit))
+}
+
+// Reset clears all recorded timings
+func (h *PlaybackTimingHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Reset(); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *PlaybackTimingHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *PrequeueHandler) beginStartTiming(prequeueID, titleName, mediaType string) {
+	if h.startTimings != nil {
+		h.startTimings.BeginAttempt(prequeueID, titleName, mediaType)
+	}
+}
+
+func (h *PrequeueHandler) recordStartSpan(prequeueID, stage string, startedAt time.Time) {
+	if h.startTimings != nil {
+		h.startTimings.RecordSpan(prequeueID, stage, startedAt)
+	}
+}
+
+// finishStartTiming closes the attempt with the prequeue's final status.
+// Workers that stop without becoming ready or failing were cancelled.
+func (h *PrequeueHandler) finishStartTiming(prequeueID string) {
+	if h.startTimings == nil {
+		return
+	}
+	outcome, errMsg := models.PlaybackAttemptFailed, "cancelled"
+	if entry, ok := h.store.Get(prequeueID); ok {
+		switch entry.Status {
+		case playback.PrequeueStatusReady:
+			outcome, errMsg = models.PlaybackAttemptReady, ""
+		case playback.PrequeueStatusFailed:
+			errMsg = entry.Error
+		}
+	}
+	h.startTimings.FinishAttempt(prequeueID, outcome, errMsg)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"novastream/services/playback"
 	user_settings "novastream/services/user_settings"
 	content_preferences "novastream/services/content_preferences"
+	"novastream/utils/diskspace"
 	"novastream/utils/filter"
 
 	"github.com/gorilla/mux"
@@ -41,9 +43,18 @@ type PrequeueHandler struct {
 	userSettingsSvc         *user_settings.Service
 	contentPreferencesSvc   *content_preferences.Service
 	clientSettingsSvc       ClientSettingsProvider
+	deviceSvc               DeviceProvider // Per-device bitrate cap (optional)
 	configManager           *config.Manager
 	metadataSvc        SeriesDetailsProvider // For episode counting
+	matchOverrides     matchOverrideLookup   // Manual metadata match corrections
 	subtitleExtractor  SubtitlePreExtractor  // For pre-extracting subtitles
+	selectionRecorder  SelectionRecorder     // Outcome logging per selection strategy (optional)
+	playbackQueue      playbackQueueSource   // Per-profile playback queues (optional)
+	startTimings       StartTimingRecorder   // Per-stage playback start latency (optional)
+	externalSubtitles  externalSubtitleLister // User-uploaded subtitles per title/episode (optional)
+	searchRetries      searchRetryService     // Background retries for just-aired episodes (optional)
+	failoverTargets    streamFailoverRegistry // Remembers titles behind usenet streams for debrid failover (optional)
+	nowPlaying         *nowPlayingRegistry    // Ready streams kept for now-playing metadata after the prequeue expires
 	demoMode           bool
 }
 
@@ -61,6 +72,7 @@ type VideoProber interface {
 type VideoProbeResult struct {
 	HasDolbyVision     bool
 	HasHDR10           bool
+	HasHDR10Plus       bool // HDR10+ dynamic metadata (SMPTE 2094-40) present
 	DolbyVisionProfile string
 }
 
@@ -80,6 +92,7 @@ type VideoFullResult struct {
 	// HDR detection
 	HasDolbyVision     bool
 	HasHDR10           bool
+	HasHDR10Plus       bool // HDR10+ dynamic metadata (SMPTE 2094-40) present
 	DolbyVisionProfile string
 	// Video codec detection
 	VideoCodec string // e.g., "h264", "hevc", "mpeg4" - used to detect incompatible codecs
@@ -100,7 +113,7 @@ type VideoFullProber interface {
 
 // HLSCreator interface for creating HLS sessions
 type HLSCreator interface {
-	CreateHLSSession(ctx context.Context, path string, hasDV bool, dvProfile string, hasHDR bool, audioTrackIndex int, subtitleTrackIndex int, profileID string, startOffset float64, prequeueType string) (*HLSSessionResult, error)
+	CreateHLSSession(ctx context.Context, path string, hasDV bool, dvProfile string, hasHDR bool, hdr10PlusPassthrough bool, audioTrackIndex int, subtitleTrackIndex int, profileID string, startOffset float64, prequeueType string) (*HLSSessionResult, error)
 }
 
 // HLSSessionResult contains HLS session info
@@ -133,6 +146,7 @@ func NewPrequeueHandler(
 		historySvc:  historySvc,
 		videoProber: videoProber,
 		hlsCreator:  hlsCreator,
+		nowPlaying:  newNowPlayingRegistry(),
 		demoMode:    demoMode,
 	}
 }
@@ -177,16 +191,41 @@ func (h *PrequeueHandler) SetClientSettingsService(svc ClientSettingsProvider) {
 	h.clientSettingsSvc = svc
 }
 
+// SetDeviceService sets the device registry for per-device bitrate and resolution caps
+func (h *PrequeueHandler) SetDeviceService(svc DeviceProvider) {
+	h.deviceSvc = svc
+}
+
 // SetMetadataService sets the metadata service for episode counting
 func (h *PrequeueHandler) SetMetadataService(svc SeriesDetailsProvider) {
 	h.metadataSvc = svc
 }
 
+// SetMatchOverrides applies manual metadata match corrections to searches
+func (h *PrequeueHandler) SetMatchOverrides(lookup matchOverrideLookup) {
+	h.matchOverrides = lookup
+}
+
 // SetSubtitleExtractor sets the subtitle extractor for pre-extraction
 func (h *PrequeueHandler) SetSubtitleExtractor(extractor SubtitlePreExtractor) {
 	h.subtitleExtractor = extractor
 }
 
+// SetSelectionRecorder sets where release selection outcomes are recorded
+func (h *PrequeueHandler) SetSelectionRecorder(recorder SelectionRecorder) {
+	h.selectionRecorder = recorder
+}
+
+// SetStartTimingRecorder sets where per-stage playback start timings are recorded
+func (h *PrequeueHandler) SetStartTimingRecorder(recorder StartTimingRecorder) {
+	h.startTimings = recorder
+}
+
+// SetExternalSubtitles sets where user-uploaded subtitles are looked up
+func (h *PrequeueHandler) SetExternalSubtitles(lister externalSubtitleLister) {
+	h.externalSubtitles = lister
+}
+
 // Prequeue initiates a prequeue request for a title
 func (h *PrequeueHandler) Prequeue(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
@@ -278,11 +317,7 @@ func (h *PrequeueHandler) Prequeue(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create prequeue entry
-	entry, _ := h.store.Create(req.TitleID, titleName, req.UserID, mediaType, req.Year, targetEpisode, req.Reason)
-
-	// Start background worker with all the info needed for search
-	go h.runPrequeueWorker(entry.ID, req.TitleID, titleName, req.ImdbID, mediaType, req.Year, req.UserID, clientID, targetEpisode, req.StartOffset)
+	entry := h.startPrequeue(req, titleName, mediaType, clientID, targetEpisode)
 
 	// Return response
 	resp := playback.PrequeueResponse{
@@ -295,6 +330,43 @@ func (h *PrequeueHandler) Prequeue(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// startPrequeue creates a prequeue entry, replacing any existing one for the
+// same title and user, and starts resolving it in the background
+func (h *PrequeueHandler) startPrequeue(req playback.PrequeueRequest, titleName, mediaType, clientID string, targetEpisode *models.EpisodeReference) *playback.PrequeueEntry {
+	entry, _ := h.store.Create(req.TitleID, titleName, req.UserID, mediaType, req.Year, targetEpisode, req.Reason)
+
+	bandwidthMbps := req.BandwidthMbps
+	if req.IgnoreBandwidth || bandwidthMbps < 0 {
+		bandwidthMbps = 0
+	}
+	bandwidthMbps = h.applyDeviceBitrateCap(clientID, bandwidthMbps)
+
+	imdbID := overrideIMDBID(h.matchOverrides, mediaType, req.TitleID, titleName, req.Year, req.ImdbID)
+
+	// Start background worker with all the info needed for search
+	go h.runPrequeueWorker(entry.ID, req.TitleID, titleName, imdbID, mediaType, req.Year, req.UserID, clientID, targetEpisode, req.StartOffset, bandwidthMbps)
+
+	return entry
+}
+
+// WarmEpisode prequeues an episode in the background on behalf of a profile,
+// without a client waiting on it, and returns the prequeue ID.
+func (h *PrequeueHandler) WarmEpisode(userID, titleID, titleName, imdbID string, year, season, episode int) string {
+	req := playback.PrequeueRequest{
+		TitleID:       titleID,
+		TitleName:     titleName,
+		MediaType:     "series",
+		UserID:        userID,
+		ImdbID:        imdbID,
+		Year:          year,
+		SeasonNumber:  season,
+		EpisodeNumber: episode,
+		Reason:        "next_episode",
+	}
+	target := &models.EpisodeReference{SeasonNumber: season, EpisodeNumber: episode}
+	return h.startPrequeue(req, titleName, "series", "", target).ID
+}
+
 // GetStatus returns the status of a prequeue request
 func (h *PrequeueHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
@@ -316,6 +388,7 @@ func (h *PrequeueHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := entry.ToResponse()
+	h.attachExternalSubtitles(resp, entry.TitleID)
 
 	// In demo mode, set displayName to hide actual filenames
 	if h.demoMode {
@@ -351,7 +424,7 @@ func (h *PrequeueHandler) Options(w http.ResponseWriter, r *http.Request) {
 }
 
 // runPrequeueWorker runs the prequeue background task
-func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdbID, mediaType string, year int, userID, clientID string, targetEpisode *models.EpisodeReference, startOffset float64) {
+func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdbID, mediaType string, year int, userID, clientID string, targetEpisode *models.EpisodeReference, startOffset float64, bandwidthMbps float64) {
 	// Create cancellable context
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
@@ -361,6 +434,8 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 
 	workerStart := time.Now()
 	log.Printf("[prequeue] TIMING: worker started for %s (title=%q)", prequeueID, titleName)
+	h.beginStartTiming(prequeueID, titleName, mediaType)
+	defer h.finishStartTiming(prequeueID)
 
 	// Update status to searching
 	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
@@ -382,6 +457,10 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 	var isDaily bool
 	var isAnime bool
 	var targetAirDate string
+	var runtimeMinutes int
+	if targetEpisode != nil {
+		runtimeMinutes = targetEpisode.RuntimeMinutes
+	}
 	if mediaType == "series" && h.metadataSvc != nil {
 		seriesMeta := h.createEpisodeResolverAndLookupAbsoluteEp(ctx, titleID, titleName, year, imdbID, targetEpisode)
 		episodeResolver = seriesMeta.EpisodeResolver
@@ -389,6 +468,9 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 		isDaily = seriesMeta.IsDaily
 		isAnime = seriesMeta.IsAnime
 		targetAirDate = seriesMeta.TargetAirDate
+		if seriesMeta.RuntimeMinutes > 0 {
+			runtimeMinutes = seriesMeta.RuntimeMinutes
+		}
 		if episodeResolver != nil {
 			log.Printf("[prequeue] Episode resolver created: %d total episodes, %d seasons", episodeResolver.TotalEpisodes, len(episodeResolver.SeasonEpisodeCounts))
 		}
@@ -412,6 +494,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 		IsDaily:         isDaily,
 		IsAnime:         isAnime,
 		TargetAirDate:   targetAirDate,
+		RuntimeMinutes:  runtimeMinutes,
 	}
 	// Pass absolute episode number for anime matching (if available)
 	if targetEpisode != nil && targetEpisode.AbsoluteEpisodeNumber > 0 {
@@ -648,10 +731,26 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 			errMsg += "usenet: " + usenetErr.Error()
 		}
 		h.failPrequeue(prequeueID, errMsg)
+		if debridErr == nil && usenetErr == nil && mediaType == "series" {
+			h.scheduleSearchRetry(prequeueID, titleID, titleName, imdbID, year, userID, clientID, query, targetEpisode, targetAirDate, isDaily, isAnime, runtimeMinutes)
+		}
 		return
 	}
 
 	log.Printf("[prequeue] TIMING: search phase complete, debrid=%d usenet=%d (elapsed: %v)", len(debridResults), len(usenetResults), time.Since(workerStart))
+	h.recordStartSpan(prequeueID, models.PlaybackStageSearch, workerStart)
+
+	// Prefer releases whose bitrate fits the client's measured link
+	if bandwidthMbps > 0 {
+		debridResults = orderResultsByBandwidth(debridResults, mediaType, bandwidthMbps)
+		usenetResults = orderResultsByBandwidth(usenetResults, mediaType, bandwidthMbps)
+	}
+
+	// Prefer releases within the device's resolution and SDR caps
+	if caps := h.deviceReleaseCaps(clientID); caps.active() {
+		debridResults = orderResultsByDeviceCaps(debridResults, caps)
+		usenetResults = orderResultsByDeviceCaps(usenetResults, caps)
+	}
 
 	// Update status to resolving
 	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
@@ -698,6 +797,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 	var resolution *models.PlaybackResolution
 	var lastErr error
 	var selectedResult *models.NZBResult // Track which result was successfully resolved
+	candidatesTried := 0                 // Results tried, for selection outcome logging
 
 	resolveStart := time.Now()
 	log.Printf("[prequeue] TIMING: starting resolution phase (debrid=%d, usenet=%d, priority=%s, elapsed: %v)",
@@ -760,6 +860,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 				continue
 			}
 
+			candidatesTried++
 			resolution, lastErr = h.playbackSvc.Resolve(ctx, result)
 			if lastErr == nil && resolution != nil && resolution.WebDAVPath != "" {
 				log.Printf("[prequeue] Resolved debrid result [%d]: %s -> %s", i, result.Title, resolution.WebDAVPath)
@@ -800,15 +901,14 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 		default:
 		}
 
-		healthMap := make(map[string]playback.HealthCheckResult)
+		// Keyed by position, since a healthy result may be an alternate copy
+		// from another indexer with its own download URL
+		healthMap := make(map[int]playback.HealthCheckResult)
 		for _, hr := range healthResults {
-			key := hr.Candidate.DownloadURL
-			if key == "" {
-				key = hr.Candidate.Link
-			}
-			healthMap[key] = hr
+			healthMap[hr.Index] = hr
 		}
 		log.Printf("[prequeue] TIMING: usenet health check complete (took: %v)", time.Since(healthCheckStart))
+		h.recordStartSpan(prequeueID, models.PlaybackStageHealthCheck, healthCheckStart)
 
 		// Try usenet results in priority order
 		for i, result := range usenetResults {
@@ -822,15 +922,12 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 				continue
 			}
 
-			key := result.DownloadURL
-			if key == "" {
-				key = result.Link
-			}
-			hr, found := healthMap[key]
+			hr, found := healthMap[i]
 			if !found {
 				log.Printf("[prequeue] No health result for usenet %s, skipping", result.Title)
 				continue
 			}
+			candidatesTried++
 			if !hr.Healthy {
 				log.Printf("[prequeue] Usenet %s unhealthy, skipping", result.Title)
 				continue
@@ -848,7 +945,8 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 					continue
 				}
 				cachedProbeResult = probeResult
-				selectedResult = &result
+				selected := hr.Candidate
+				selectedResult = &selected
 				log.Printf("[prequeue] TIMING: usenet resolved (resolve took: %v, total elapsed: %v)",
 					time.Since(resolveStart), time.Since(workerStart))
 				return true
@@ -892,11 +990,39 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 		if lastErr != nil {
 			errMsg = lastErr.Error()
 		}
+		if ctx.Err() == nil {
+			h.recordSelection(models.SelectionOutcome{
+				UserID:     userID,
+				MediaType:  mediaType,
+				Outcome:    models.SelectionOutcomeFailed,
+				Candidates: candidatesTried,
+				Error:      errMsg,
+			})
+		}
 		h.failPrequeue(prequeueID, errMsg)
+		if errors.Is(lastErr, diskspace.ErrInsufficientSpace) {
+			h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
+				e.ErrorCode = diskspace.ErrorCode
+			})
+		}
 		return
 	}
 
+	h.recordSelection(models.SelectionOutcome{
+		UserID:      userID,
+		MediaType:   mediaType,
+		Outcome:     models.SelectionOutcomeResolved,
+		Candidates:  candidatesTried,
+		ServiceType: selectedResult.ServiceType,
+		Release:     selectedResult.Title,
+	})
+
 	log.Printf("[prequeue] TIMING: resolution complete (resolve took: %v, total elapsed: %v)", time.Since(resolveStart), time.Since(workerStart))
+	h.recordStartSpan(prequeueID, models.PlaybackStageResolve, resolveStart)
+
+	if h.failoverTargets != nil && selectedResult.ServiceType != models.ServiceTypeDebrid {
+		h.failoverTargets.Register(resolution.WebDAVPath, searchOpts, debridResults, selectedResult.Title)
+	}
 
 	// Update with resolution
 	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
@@ -966,7 +1092,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 		// Use combined prober if available (single ffprobe call), otherwise fall back to separate probes
 		var audioStreams []AudioStreamInfo
 		var subtitleStreams []SubtitleStreamInfo
-		var hasDV, hasHDR10 bool
+		var hasDV, hasHDR10, hasHDR10Plus bool
 		var hasTrueHD, hasCompatibleAudio bool
 		var dvProfile string
 
@@ -977,6 +1103,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 			subtitleStreams = cachedProbeResult.SubtitleStreams
 			hasDV = cachedProbeResult.HasDolbyVision
 			hasHDR10 = cachedProbeResult.HasHDR10
+			hasHDR10Plus = cachedProbeResult.HasHDR10Plus
 			dvProfile = cachedProbeResult.DolbyVisionProfile
 			hasTrueHD = cachedProbeResult.HasTrueHD
 			hasCompatibleAudio = cachedProbeResult.HasCompatibleAudio
@@ -993,6 +1120,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 				subtitleStreams = fullResult.SubtitleStreams
 				hasDV = fullResult.HasDolbyVision
 				hasHDR10 = fullResult.HasHDR10
+				hasHDR10Plus = fullResult.HasHDR10Plus
 				dvProfile = fullResult.DolbyVisionProfile
 				hasTrueHD = fullResult.HasTrueHD
 				hasCompatibleAudio = fullResult.HasCompatibleAudio
@@ -1018,6 +1146,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 				} else if probeResult != nil {
 					hasDV = probeResult.HasDolbyVision
 					hasHDR10 = probeResult.HasHDR10
+					hasHDR10Plus = probeResult.HasHDR10Plus
 					dvProfile = probeResult.DolbyVisionProfile
 				}
 			}
@@ -1025,16 +1154,27 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 
 		// Process track selection using probe results
 		if len(audioStreams) > 0 || len(subtitleStreams) > 0 {
-			log.Printf("[prequeue] User track preferences: audioLang=%q, subLang=%q, subMode=%q",
+			log.Printf("[prequeue] User track preferences: audioLang=%q, subLang=%q, subMode=%q, preferAD=%v, preferSDH=%v",
 				userSettings.Playback.PreferredAudioLanguage,
 				userSettings.Playback.PreferredSubtitleLanguage,
-				userSettings.Playback.PreferredSubtitleMode)
+				userSettings.Playback.PreferredSubtitleMode,
+				userSettings.Playback.PreferAudioDescription,
+				userSettings.Playback.PreferSDH)
 
 			for i, stream := range audioStreams {
 				log.Printf("[prequeue] Audio stream[%d]: index=%d codec=%q lang=%q title=%q", i, stream.Index, stream.Codec, stream.Language, stream.Title)
 			}
 
-			if userSettings.Playback.PreferredAudioLanguage != "" {
+			if userSettings.Playback.PreferAudioDescription {
+				selectedAudioTrack = FindAudioDescriptionTrack(audioStreams, userSettings.Playback.PreferredAudioLanguage)
+				if selectedAudioTrack < 0 {
+					log.Printf("[prequeue] No audio description track available, using language preference")
+				}
+			}
+
+			if selectedAudioTrack >= 0 {
+				log.Printf("[prequeue] Selected audio description track %d (accessibility preference)", selectedAudioTrack)
+			} else if userSettings.Playback.PreferredAudioLanguage != "" {
 				selectedAudioTrack = h.findAudioTrackByLanguage(audioStreams, userSettings.Playback.PreferredAudioLanguage)
 				if selectedAudioTrack >= 0 {
 					log.Printf("[prequeue] Selected audio track %d for language %q", selectedAudioTrack, userSettings.Playback.PreferredAudioLanguage)
@@ -1047,7 +1187,11 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 
 			subMode := userSettings.Playback.PreferredSubtitleMode
 			subLang := userSettings.Playback.PreferredSubtitleLanguage
-			if subMode != "off" && subMode != "" {
+			// The SDH preference applies even when subtitles are otherwise off
+			if userSettings.Playback.PreferSDH {
+				selectedSubtitleTrack = FindSDHSubtitleTrack(subtitleStreams, subLang)
+			}
+			if selectedSubtitleTrack < 0 && subMode != "off" && subMode != "" {
 				selectedSubtitleTrack = h.findSubtitleTrackByPreference(subtitleStreams, subLang, subMode)
 				if selectedSubtitleTrack >= 0 {
 					log.Printf("[prequeue] Selected subtitle track %d for language %q (mode: %s)", selectedSubtitleTrack, subLang, subMode)
@@ -1070,10 +1214,11 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 			audioTracks := make([]playback.AudioTrackInfo, len(audioStreams))
 			for i, s := range audioStreams {
 				audioTracks[i] = playback.AudioTrackInfo{
-					Index:    s.Index,
-					Language: s.Language,
-					Codec:    s.Codec,
-					Title:    s.Title,
+					Index:            s.Index,
+					Language:         s.Language,
+					Codec:            s.Codec,
+					Title:            s.Title,
+					AudioDescription: s.IsAudioDescription,
 				}
 			}
 
@@ -1095,6 +1240,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 					Codec:         s.Codec,
 					Forced:        s.IsForced,
 					IsBitmap:      bitmapCodecs[codec],
+					SDH:           s.IsSDH,
 				}
 			}
 
@@ -1115,6 +1261,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 			h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
 				e.HasDolbyVision = hasDV
 				e.HasHDR10 = hasHDR10
+				e.HasHDR10Plus = hasHDR10Plus
 				e.DolbyVisionProfile = dvProfile
 				e.NeedsAudioTranscode = needsAudioTranscode
 			})
@@ -1122,6 +1269,8 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 			reason := "SDR (testing fMP4)"
 			if hasDV {
 				reason = "Dolby Vision"
+			} else if hasHDR10 && hasHDR10Plus {
+				reason = "HDR10+"
 			} else if hasHDR10 {
 				reason = "HDR10"
 			} else if hasTrueHD {
@@ -1132,6 +1281,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 				}
 			}
 			log.Printf("[prequeue] TIMING: probe complete (probe took: %v, total elapsed: %v)", time.Since(probeStart), time.Since(workerStart))
+			h.recordStartSpan(prequeueID, models.PlaybackStageProbe, probeStart)
 			log.Printf("[prequeue] Creating HLS session for: %s", reason)
 
 			hlsStart := time.Now()
@@ -1149,6 +1299,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 					hasDV,
 					dvProfile,
 					hasHDR10,
+					hasHDR10Plus && !h.deviceRejectsHDR10Plus(clientID),
 					selectedAudioTrack,
 					selectedSubtitleTrack,
 					userID,
@@ -1163,6 +1314,10 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 						e.HLSPlaylistURL = hlsResult.PlaylistURL
 					})
 					log.Printf("[prequeue] TIMING: HLS session created: %s (HLS took: %v, total elapsed: %v)", hlsResult.SessionID, time.Since(hlsStart), time.Since(workerStart))
+					h.recordStartSpan(prequeueID, models.PlaybackStageHLSWarmup, hlsStart)
+					if h.startTimings != nil {
+						h.startTimings.LinkHLSSession(prequeueID, hlsResult.SessionID)
+					}
 				}
 			}
 		}
@@ -1173,11 +1328,23 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleID, titleName, imdb
 	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
 		e.Status = playback.PrequeueStatusReady
 	})
+	if entry, ok := h.store.Get(prequeueID); ok {
+		h.nowPlaying.remember(entry, time.Now())
+	}
 
 	log.Printf("[prequeue] TIMING: Prequeue %s is ready (TOTAL: %v)", prequeueID, time.Since(workerStart))
 }
 
 // failPrequeue marks a prequeue as failed
+// recordSelection attributes a selection outcome to the user's release selection strategy
+func (h *PrequeueHandler) recordSelection(outcome models.SelectionOutcome) {
+	if h.selectionRecorder == nil || h.indexerSvc == nil {
+		return
+	}
+	outcome.Strategy = h.indexerSvc.SelectionStrategyFor(outcome.UserID)
+	h.selectionRecorder.RecordSelection(outcome)
+}
+
 func (h *PrequeueHandler) failPrequeue(prequeueID, errMsg string) {
 	log.Printf("[prequeue] Prequeue %s failed: %s", prequeueID, errMsg)
 	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
@@ -1319,6 +1486,7 @@ type SeriesMetadataResult struct {
 	IsDaily         bool   // True for daily shows (talk shows, news) that use date-based naming
 	TargetAirDate   string // Air date from TVDB in YYYY-MM-DD format
 	IsAnime         bool   // True for anime content - requires waiting for Nyaa scraper
+	RuntimeMinutes  int    // Target episode runtime, used to estimate release bitrates
 }
 
 // createEpisodeResolverAndLookupAbsoluteEp fetches series metadata, creates an episode resolver,
@@ -1398,6 +1566,7 @@ func (h *PrequeueHandler) createEpisodeResolverAndLookupAbsoluteEp(ctx context.C
 						log.Printf("[prequeue] Found absolute episode number %d for S%02dE%02d from TVDB",
 							foundAbsoluteEp, targetEpisode.SeasonNumber, targetEpisode.EpisodeNumber)
 					}
+					result.RuntimeMinutes = ep.Runtime
 					// Get air date for daily shows (AiredDate field in SeriesEpisode)
 					if ep.AiredDate != "" {
 						foundAirDate = ep.AiredDate
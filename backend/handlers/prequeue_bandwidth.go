@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"log"
+	"sort"
+
+	"novastream/models"
+)
+
+const (
+	// bandwidthHeadroom is the fraction of measured throughput a release may use;
+	// the rest absorbs bitrate spikes and throughput variance.
+	bandwidthHeadroom = 0.8
+
+	// Assumed runtimes used to turn release size into an average bitrate.
+	assumedMovieRuntimeMinutes   = 120
+	assumedEpisodeRuntimeMinutes = 45
+)
+
+// estimateReleaseBitrateMbps estimates the average bitrate of a release. The
+// search's metadata-based estimate is used when present; otherwise size is
+// spread over an assumed runtime and season packs are divided by their episode
+// count. Returns 0 when the size is unknown.
+func estimateReleaseBitrateMbps(result models.NZBResult, mediaType string) float64 {
+	if result.EstimatedBitrateKbps > 0 {
+		return float64(result.EstimatedBitrateKbps) / 1000
+	}
+	if result.SizeBytes <= 0 {
+		return 0
+	}
+	size := float64(result.SizeBytes)
+	runtime := float64(assumedMovieRuntimeMinutes)
+	if mediaType == "series" {
+		runtime = assumedEpisodeRuntimeMinutes
+		if result.EpisodeCount > 1 {
+			size /= float64(result.EpisodeCount)
+		}
+	}
+	return size * 8 / (runtime * 60) / 1_000_000
+}
+
+// orderResultsByBandwidth moves releases whose estimated bitrate exceeds the
+// client's usable bandwidth behind those that fit. Releases that fit keep their
+// original (score) order; oversized releases are kept as a fallback, smallest
+// bitrate first, so playback still starts if nothing fits the link.
+func orderResultsByBandwidth(results []models.NZBResult, mediaType string, bandwidthMbps float64) []models.NZBResult {
+	if bandwidthMbps <= 0 || len(results) == 0 {
+		return results
+	}
+
+	limit := bandwidthMbps * bandwidthHeadroom
+	fits := make([]models.NZBResult, 0, len(results))
+	var oversized []models.NZBResult
+	for _, result := range results {
+		if estimateReleaseBitrateMbps(result, mediaType) > limit {
+			oversized = append(oversized, result)
+			continue
+		}
+		fits = append(fits, result)
+	}
+
+	if len(oversized) == 0 {
+		return results
+	}
+
+	sort.SliceStable(oversized, func(i, j int) bool {
+		return estimateReleaseBitrateMbps(oversized[i], mediaType) < estimateReleaseBitrateMbps(oversized[j], mediaType)
+	})
+	log.Printf("[prequeue] Bandwidth %.1f Mbps (limit %.1f): %d release(s) fit, %d deprioritised as too large",
+		bandwidthMbps, limit, len(fits), len(oversized))
+
+	return append(fits, oversized...)
+}
+
+// applyDeviceBitrateCap lowers the usable bandwidth to the registered device's
+// bitrate cap. The cap is a stream bitrate, so it is scaled up by the headroom
+// that orderResultsByBandwidth takes back off.
+func (h *PrequeueHandler) applyDeviceBitrateCap(clientID string, bandwidthMbps float64) float64 {
+	if h.deviceSvc == nil || clientID == "" {
+		return bandwidthMbps
+	}
+	device, err := h.deviceSvc.Get(clientID)
+	if err != nil || device == nil || device.MaxBitrateKbps <= 0 {
+		return bandwidthMbps
+	}
+
+	capped := float64(device.MaxBitrateKbps) / 1000 / bandwidthHeadroom
+	if bandwidthMbps <= 0 || capped < bandwidthMbps {
+		log.Printf("[prequeue] device %s bitrate cap %dkbps limits release selection", clientID, device.MaxBitrateKbps)
+		return capped
+	}
+	return bandwidthMbps
+}
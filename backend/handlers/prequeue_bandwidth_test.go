@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+const gib = int64(1) << 30
+
+func TestOrderResultsByBandwidthDeprioritisesOversizedReleases(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2160p.REMUX", SizeBytes: 80 * gib},
+		{Title: "Movie.2160p.WEB-DL", SizeBytes: 20 * gib},
+		{Title: "Movie.1080p.WEB-DL", SizeBytes: 8 * gib},
+		{Title: "Movie.720p", SizeBytes: 4 * gib},
+	}
+
+	ordered := orderResultsByBandwidth(results, "movie", 20)
+
+	want := []string{"Movie.1080p.WEB-DL", "Movie.720p", "Movie.2160p.WEB-DL", "Movie.2160p.REMUX"}
+	for i, title := range want {
+		if ordered[i].Title != title {
+			t.Fatalf("position %d: got %q want %q (order %v)", i, ordered[i].Title, title, ordered)
+		}
+	}
+}
+
+func TestOrderResultsByBandwidthDisabled(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2160p.REMUX", SizeBytes: 80 * gib},
+		{Title: "Movie.1080p", SizeBytes: 8 * gib},
+	}
+
+	ordered := orderResultsByBandwidth(results, "movie", 0)
+	if ordered[0].Title != "Movie.2160p.REMUX" {
+		t.Fatalf("expected original order when bandwidth is unknown, got %v", ordered)
+	}
+}
+
+func TestEstimateReleaseBitrateSeasonPack(t *testing.T) {
+	pack := models.NZBResult{SizeBytes: 40 * gib, EpisodeCount: 10}
+	single := models.NZBResult{SizeBytes: 4 * gib}
+
+	if got, want := estimateReleaseBitrateMbps(pack, "series"), estimateReleaseBitrateMbps(single, "series"); got != want {
+		t.Fatalf("pack bitrate %.2f, want per-episode %.2f", got, want)
+	}
+}
+
+type fakeDeviceProvider struct {
+	device *models.Device
+}
+
+func (f fakeDeviceProvider) Get(id string) (*models.Device, error) {
+	return f.device, nil
+}
+
+func TestApplyDeviceBitrateCap(t *testing.T) {
+	h := &PrequeueHandler{deviceSvc: fakeDeviceProvider{device: &models.Device{ID: "dev-1", MaxBitrateKbps: 8000}}}
+
+	// An 8 Mbps cap becomes a 10 Mbps link once orderResultsByBandwidth takes its headroom back off
+	if got := h.applyDeviceBitrateCap("dev-1", 0); got != 10 {
+		t.Fatalf("expected cap to apply without a measurement, got %v", got)
+	}
+	if got := h.applyDeviceBitrateCap("dev-1", 50); got != 10 {
+		t.Fatalf("expected cap to lower measured bandwidth, got %v", got)
+	}
+	if got := h.applyDeviceBitrateCap("dev-1", 5); got != 5 {
+		t.Fatalf("expected slower measured bandwidth to win, got %v", got)
+	}
+	if got := h.applyDeviceBitrateCap("", 50); got != 50 {
+		t.Fatalf("expected no cap without a client id, got %v", got)
+	}
+}
+
+func TestEstimateReleaseBitrateUsesMetadataEstimate(t *testing.T) {
+	// A 90-minute film: the assumed 120-minute runtime would underestimate it
+	result := models.NZBResult{Title: "Movie.1080p", SizeBytes: 8 * gib, EstimatedBitrateKbps: 12726}
+
+	if got := estimateReleaseBitrateMbps(result, "movie"); got != 12.726 {
+		t.Fatalf("estimateReleaseBitrateMbps() = %v, want 12.726", got)
+	}
+}
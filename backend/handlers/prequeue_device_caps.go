@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"novastream/models"
+	"novastream/utils/releasename"
+)
+
+// deviceReleaseCaps are the registered device's resolution and dynamic range
+// limits for release selection.
+type deviceReleaseCaps struct {
+	MaxHeight int  // 0 = no resolution cap
+	SDROnly   bool // Prefer releases without HDR or Dolby Vision
+}
+
+func (c deviceReleaseCaps) active() bool {
+	return c.MaxHeight > 0 || c.SDROnly
+}
+
+// fits reports whether a release is within the caps. Releases of unknown
+// resolution are assumed to fit.
+func (c deviceReleaseCaps) fits(result models.NZBResult) bool {
+	release := releasename.Parse(result.Title)
+	if res := releasename.ParseResolution(result.Attributes["resolution"]); res > 0 {
+		release.Resolution = res
+	}
+	if c.MaxHeight > 0 && release.Resolution > c.MaxHeight {
+		return false
+	}
+	if c.SDROnly && (release.HasHDR() || result.Attributes["hdr"] != "") {
+		return false
+	}
+	return true
+}
+
+// deviceReleaseCaps returns the caps set on the registered device, if any.
+func (h *PrequeueHandler) deviceReleaseCaps(clientID string) deviceReleaseCaps {
+	if h.deviceSvc == nil || clientID == "" {
+		return deviceReleaseCaps{}
+	}
+	device, err := h.deviceSvc.Get(clientID)
+	if err != nil || device == nil {
+		return deviceReleaseCaps{}
+	}
+	return deviceReleaseCaps{
+		MaxHeight: releasename.ParseResolution(device.MaxResolution),
+		SDROnly:   device.SDROnly,
+	}
+}
+
+// deviceRejectsHDR10Plus reports whether the registered device lists the HDR
+// formats it decodes and HDR10+ isn't one of them. Unregistered devices and
+// devices that don't report HDR formats get HDR10+ passed through.
+func (h *PrequeueHandler) deviceRejectsHDR10Plus(clientID string) bool {
+	if h.deviceSvc == nil || clientID == "" {
+		return false
+	}
+	device, err := h.deviceSvc.Get(clientID)
+	if err != nil || device == nil || len(device.Capabilities.HDRFormats) == 0 {
+		return false
+	}
+	for _, format := range device.Capabilities.HDRFormats {
+		if strings.EqualFold(strings.TrimSpace(format), "hdr10+") {
+			return false
+		}
+	}
+	return true
+}
+
+// orderResultsByDeviceCaps moves releases above the device's resolution cap, or
+// with HDR on an SDR-only device, behind those within its caps. Both groups keep
+// their order; releases over the caps stay as a fallback so playback still
+// starts, with HLS downscaling SDR video to the cap.
+func orderResultsByDeviceCaps(results []models.NZBResult, caps deviceReleaseCaps) []models.NZBResult {
+	if !caps.active() || len(results) == 0 {
+		return results
+	}
+
+	fits := make([]models.NZBResult, 0, len(results))
+	var over []models.NZBResult
+	for _, result := range results {
+		if caps.fits(result) {
+			fits = append(fits, result)
+			continue
+		}
+		over = append(over, result)
+	}
+
+	if len(over) == 0 {
+		return results
+	}
+
+	log.Printf("[prequeue] Device caps (max %dp, sdrOnly=%v): %d release(s) fit, %d deprioritised",
+		caps.MaxHeight, caps.SDROnly, len(fits), len(over))
+
+	return append(fits, over...)
+}
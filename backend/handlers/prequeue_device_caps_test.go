@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestOrderResultsByDeviceCaps(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2024.2160p.UHD.BluRay.HDR.x265-GRP"},
+		{Title: "Movie.2024.1080p.BluRay.DV.x265-GRP"},
+		{Title: "Movie.2024.1080p.WEB-DL.x264-GRP"},
+		{Title: "Movie.2024.WEB-DL-GRP", Attributes: map[string]string{"resolution": "2160p"}},
+		{Title: "Movie.2024.720p.WEB-DL.x264-GRP"},
+	}
+
+	ordered := orderResultsByDeviceCaps(results, deviceReleaseCaps{MaxHeight: 1080, SDROnly: true})
+	want := []string{
+		"Movie.2024.1080p.WEB-DL.x264-GRP",
+		"Movie.2024.720p.WEB-DL.x264-GRP",
+		"Movie.2024.2160p.UHD.BluRay.HDR.x265-GRP",
+		"Movie.2024.1080p.BluRay.DV.x265-GRP",
+		"Movie.2024.WEB-DL-GRP",
+	}
+	for i, title := range want {
+		if ordered[i].Title != title {
+			t.Fatalf("position %d: got %q want %q", i, ordered[i].Title, title)
+		}
+	}
+
+	// HDR within the resolution cap is fine when the device isn't SDR-only
+	ordered = orderResultsByDeviceCaps(results, deviceReleaseCaps{MaxHeight: 1080})
+	if ordered[0].Title != "Movie.2024.1080p.BluRay.DV.x265-GRP" {
+		t.Fatalf("expected the DV 1080p release first, got %q", ordered[0].Title)
+	}
+
+	if ordered := orderResultsByDeviceCaps(results, deviceReleaseCaps{}); ordered[0].Title != results[0].Title {
+		t.Fatalf("expected original order without caps, got %q first", ordered[0].Title)
+	}
+}
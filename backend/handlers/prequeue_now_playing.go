@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+	"novastream/services/playback"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// nowPlayingTTL is how long a ready stream's now-playing details are kept
+	// after it was last looked up. Prequeue entries expire after 15 minutes, but
+	// a stream can be handed to AirPlay hours into playback.
+	nowPlayingTTL = 6 * time.Hour
+	// nowPlayingLookupTimeout bounds the metadata lookup for artwork.
+	nowPlayingLookupTimeout = 10 * time.Second
+)
+
+// movieInfoProvider looks up movie details for artwork. The metadata service
+// passed to SetMetadataService implements it.
+type movieInfoProvider interface {
+	MovieInfo(ctx context.Context, req models.MovieDetailsQuery) (*models.Title, error)
+}
+
+// NowPlayingResponse is what a client shows on a now-playing card, e.g. fed into
+// AVPlayerItem.externalMetadata before handing the stream to AirPlay.
+type NowPlayingResponse struct {
+	Title       string        `json:"title"`              // Movie or episode title
+	Subtitle    string        `json:"subtitle,omitempty"` // Series name for episodes
+	Description string        `json:"description,omitempty"`
+	Year        int           `json:"year,omitempty"`
+	MediaType   string        `json:"mediaType"`
+	Chapter     string        `json:"chapter,omitempty"` // Episode label, e.g. "S02E05 · Pilot"
+	Season      int           `json:"seasonNumber,omitempty"`
+	Episode     int           `json:"episodeNumber,omitempty"`
+	Duration    float64       `json:"duration,omitempty"` // Seconds; probed duration, else the listed runtime
+	Artwork     *models.Image `json:"artwork,omitempty"`  // Episode still or poster
+	Poster      *models.Image `json:"poster,omitempty"`
+	Backdrop    *models.Image `json:"backdrop,omitempty"`
+}
+
+// nowPlayingStream is what is remembered about a ready stream.
+type nowPlayingStream struct {
+	TitleID   string
+	TitleName string
+	Year      int
+	MediaType string
+	Episode   *models.EpisodeReference
+	Duration  float64
+	seenAt    time.Time
+}
+
+// nowPlayingRegistry keeps ready streams by prequeue ID and HLS session ID so
+// their metadata outlives the prequeue entry.
+type nowPlayingRegistry struct {
+	mu      sync.Mutex
+	streams map[string]nowPlayingStream
+}
+
+func newNowPlayingRegistry() *nowPlayingRegistry {
+	return &nowPlayingRegistry{streams: make(map[string]nowPlayingStream)}
+}
+
+// remember records a ready prequeue entry under its prequeue and HLS session IDs.
+func (r *nowPlayingRegistry) remember(entry *playback.PrequeueEntry, now time.Time) {
+	stream := nowPlayingStream{
+		TitleID:   entry.TitleID,
+		TitleName: entry.TitleName,
+		Year:      entry.Year,
+		MediaType: entry.MediaType,
+		Duration:  entry.Duration,
+		seenAt:    now,
+	}
+	if entry.TargetEpisode != nil {
+		episode := *entry.TargetEpisode
+		stream.Episode = &episode
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, existing := range r.streams {
+		if now.Sub(existing.seenAt) > nowPlayingTTL {
+			delete(r.streams, token)
+		}
+	}
+	r.streams[entry.ID] = stream
+	if entry.HLSSessionID != "" {
+		r.streams[entry.HLSSessionID] = stream
+	}
+}
+
+// lookup returns the stream for a token and extends its lifetime.
+func (r *nowPlayingRegistry) lookup(token string, now time.Time) (nowPlayingStream, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stream, ok := r.streams[token]
+	if !ok {
+		return nowPlayingStream{}, false
+	}
+	if now.Sub(stream.seenAt) > nowPlayingTTL {
+		delete(r.streams, token)
+		return nowPlayingStream{}, false
+	}
+	stream.seenAt = now
+	r.streams[token] = stream
+	return stream, true
+}
+
+// NowPlaying handles GET /api/playback/now-playing/{token}
+// The token is a prequeue ID or the HLS session ID it started. Returns the
+// title, artwork, duration and episode label so clients can fill in the
+// now-playing card when handing a stream to AirPlay.
+func (h *PrequeueHandler) NowPlaying(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(mux.Vars(r)["token"])
+	if token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	var stream nowPlayingStream
+	var found bool
+	if entry, ok := h.store.Get(token); ok {
+		stream = nowPlayingStream{
+			TitleID:   entry.TitleID,
+			TitleName: entry.TitleName,
+			Year:      entry.Year,
+			MediaType: entry.MediaType,
+			Episode:   entry.TargetEpisode,
+			Duration:  entry.Duration,
+		}
+		found = true
+	} else if h.nowPlaying != nil {
+		stream, found = h.nowPlaying.lookup(token, time.Now())
+	}
+	if !found {
+		http.Error(w, "stream not found or expired", http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), nowPlayingLookupTimeout)
+	defer cancel()
+	movies, _ := h.metadataSvc.(movieInfoProvider)
+	resp := buildNowPlaying(ctx, stream, h.metadataSvc, movies)
+
+	if h.demoMode {
+		resp.Description = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// buildNowPlaying fills in the now-playing details for a stream, adding
+// artwork and descriptions from metadata when available. Metadata failures
+// leave the details from the stream itself.
+func buildNowPlaying(ctx context.Context, stream nowPlayingStream, series SeriesDetailsProvider, movies movieInfoProvider) NowPlayingResponse {
+	resp := NowPlayingResponse{
+		Title:     stream.TitleName,
+		Year:      stream.Year,
+		MediaType: stream.MediaType,
+		Duration:  stream.Duration,
+	}
+
+	episode := stream.Episode
+	isEpisode := stream.MediaType == "series" && episode != nil && episode.EpisodeNumber > 0
+	if isEpisode {
+		resp.Subtitle = stream.TitleName
+		resp.Title = episode.Title
+		resp.Description = episode.Overview
+		resp.Season = episode.SeasonNumber
+		resp.Episode = episode.EpisodeNumber
+		if resp.Duration == 0 && episode.RuntimeMinutes > 0 {
+			resp.Duration = float64(episode.RuntimeMinutes * 60)
+		}
+	}
+
+	switch {
+	case stream.MediaType == "series" && series != nil:
+		details, err := series.SeriesDetails(ctx, models.SeriesDetailsQuery{TitleID: stream.TitleID, Name: stream.TitleName, Year: stream.Year})
+		if err != nil {
+			log.Printf("[now-playing] series details for %s unavailable: %v", stream.TitleID, err)
+			break
+		}
+		resp.Poster, resp.Backdrop = details.Title.Poster, details.Title.Backdrop
+		if !isEpisode {
+			resp.Description = details.Title.Overview
+			break
+		}
+		if found := findSeriesEpisode(details, episode.SeasonNumber, episode.EpisodeNumber); found != nil {
+			if resp.Title == "" {
+				resp.Title = found.Name
+			}
+			if resp.Description == "" {
+				resp.Description = found.Overview
+			}
+			if resp.Duration == 0 && found.Runtime > 0 {
+				resp.Duration = float64(found.Runtime * 60)
+			}
+			resp.Artwork = found.Image
+		}
+	case stream.MediaType == "movie" && movies != nil:
+		title, err := movies.MovieInfo(ctx, models.MovieDetailsQuery{TitleID: stream.TitleID, Name: stream.TitleName, Year: stream.Year})
+		if err != nil {
+			log.Printf("[now-playing] movie details for %s unavailable: %v", stream.TitleID, err)
+			break
+		}
+		resp.Description = title.Overview
+		resp.Poster, resp.Backdrop = title.Poster, title.Backdrop
+		if resp.Duration == 0 && title.RuntimeMinutes > 0 {
+			resp.Duration = float64(title.RuntimeMinutes * 60)
+		}
+	}
+
+	if isEpisode {
+		resp.Chapter = fmt.Sprintf("S%02dE%02d", episode.SeasonNumber, episode.EpisodeNumber)
+		if resp.Title != "" {
+			resp.Chapter += " · " + resp.Title
+		}
+		if resp.Title == "" {
+			resp.Title = resp.Chapter
+		}
+	}
+	if resp.Artwork == nil {
+		resp.Artwork = resp.Poster
+	}
+	return resp
+}
+
+func findSeriesEpisode(details *models.SeriesDetails, season, episode int) *models.SeriesEpisode {
+	if details == nil {
+		return nil
+	}
+	for _, s := range details.Seasons {
+		if s.Number != season {
+			continue
+		}
+		for i := range s.Episodes {
+			if s.Episodes[i].EpisodeNumber == episode {
+				return &s.Episodes[i]
+			}
+		}
+	}
+	return nil
+}
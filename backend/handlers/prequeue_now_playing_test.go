@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/playback"
+)
+
+type fakeNowPlayingMetadata struct {
+	series *models.SeriesDetails
+	movie  *models.Title
+	err    error
+}
+
+func (f fakeNowPlayingMetadata) SeriesDetails(ctx context.Context, req models.SeriesDetailsQuery) (*models.SeriesDetails, error) {
+	return f.series, f.err
+}
+
+func (f fakeNowPlayingMetadata) MovieInfo(ctx context.Context, req models.MovieDetailsQuery) (*models.Title, error) {
+	return f.movie, f.err
+}
+
+func TestBuildNowPlayingEpisode(t *testing.T) {
+	poster := &models.Image{URL: "https://img/poster.jpg", Type: "poster"}
+	still := &models.Image{URL: "https://img/still.jpg"}
+	metadata := fakeNowPlayingMetadata{series: &models.SeriesDetails{
+		Title: models.Title{Name: "Severance", Poster: poster},
+		Seasons: []models.SeriesSeason{{Number: 2, Episodes: []models.SeriesEpisode{
+			{SeasonNumber: 2, EpisodeNumber: 5, Name: "Trojan's Horse", Overview: "Mark and Helly...", Runtime: 48, Image: still},
+		}}},
+	}}
+
+	stream := nowPlayingStream{
+		TitleID:   "tvdb:series:371980",
+		TitleName: "Severance",
+		MediaType: "series",
+		Episode:   &models.EpisodeReference{SeasonNumber: 2, EpisodeNumber: 5},
+	}
+	got := buildNowPlaying(context.Background(), stream, metadata, metadata)
+
+	if got.Title != "Trojan's Horse" || got.Subtitle != "Severance" || got.Chapter != "S02E05 · Trojan's Horse" {
+		t.Fatalf("unexpected labels: %+v", got)
+	}
+	if got.Artwork != still || got.Poster != poster || got.Duration != 48*60 {
+		t.Fatalf("expected episode still, poster and runtime, got %+v", got)
+	}
+}
+
+func TestBuildNowPlayingMovieFallsBackWithoutMetadata(t *testing.T) {
+	stream := nowPlayingStream{TitleID: "tmdb:movie:603", TitleName: "The Matrix", Year: 1999, MediaType: "movie", Duration: 8160}
+
+	failing := fakeNowPlayingMetadata{err: errors.New("offline")}
+	got := buildNowPlaying(context.Background(), stream, failing, failing)
+	if got.Title != "The Matrix" || got.Duration != 8160 || got.Artwork != nil || got.Chapter != "" {
+		t.Fatalf("unexpected fallback response: %+v", got)
+	}
+
+	poster := &models.Image{URL: "https://img/matrix.jpg"}
+	ok := fakeNowPlayingMetadata{movie: &models.Title{Overview: "A hacker...", Poster: poster, RuntimeMinutes: 136}}
+	got = buildNowPlaying(context.Background(), stream, ok, ok)
+	if got.Artwork != poster || got.Description != "A hacker..." || got.Duration != 8160 {
+		t.Fatalf("expected poster artwork and probed duration, got %+v", got)
+	}
+}
+
+func TestNowPlayingRegistryOutlivesPrequeue(t *testing.T) {
+	registry := newNowPlayingRegistry()
+	start := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	registry.remember(&playback.PrequeueEntry{ID: "pq_1", HLSSessionID: "hls-1", TitleName: "The Matrix", MediaType: "movie"}, start)
+
+	if stream, ok := registry.lookup("hls-1", start.Add(2*time.Hour)); !ok || stream.TitleName != "The Matrix" {
+		t.Fatalf("expected the HLS session to resolve, got %+v, %v", stream, ok)
+	}
+	// The lookup above extended the lifetime of the HLS session token only
+	if _, ok := registry.lookup("hls-1", start.Add(2*time.Hour+nowPlayingTTL)); !ok {
+		t.Fatal("expected a looked-up token to stay alive")
+	}
+	if _, ok := registry.lookup("pq_1", start.Add(nowPlayingTTL+time.Minute)); ok {
+		t.Fatal("expected an idle token to expire")
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/playback"
+	"novastream/services/playback_queue"
+)
+
+// playbackQueueSource hands out the next item of a profile's playback queue.
+type playbackQueueSource interface {
+	Next(profileID, currentItemID string) (*models.PlaybackQueueItem, int, error)
+}
+
+var _ playbackQueueSource = (*playback_queue.Service)(nil)
+
+// SetPlaybackQueue sets the queue QueueNext resolves items from.
+func (h *PrequeueHandler) SetPlaybackQueue(queue playbackQueueSource) {
+	h.playbackQueue = queue
+}
+
+// QueueNextRequest identifies the queue item currently playing.
+type QueueNextRequest struct {
+	UserID   string `json:"userId"`
+	ClientID string `json:"clientId,omitempty"`
+	// CurrentItemID is the queue item playing now; empty starts the queue
+	CurrentItemID   string  `json:"currentItemId,omitempty"`
+	BandwidthMbps   float64 `json:"bandwidthMbps,omitempty"`
+	IgnoreBandwidth bool    `json:"ignoreBandwidth,omitempty"`
+	// WaitSeconds is how long to wait for the stream to resolve before
+	// responding (default 20, max 60), as for UpNext.
+	WaitSeconds int `json:"waitSeconds,omitempty"`
+}
+
+// QueueNextResponse carries the next queue item and its prequeue result.
+type QueueNextResponse struct {
+	Item      models.PlaybackQueueItem         `json:"item"`
+	Remaining int                              `json:"remaining"` // Items queued from Item onwards
+	Prequeue  *playback.PrequeueStatusResponse `json:"prequeue"`
+}
+
+// QueueNext resolves the playback queue item following the one currently
+// playing, just in time: clients call it near the end of playback, the same
+// way as UpNext. Items ahead of the current one have been played and are
+// dropped from the queue. Returns 404 when the queue has nothing after the
+// current item.
+func (h *PrequeueHandler) QueueNext(w http.ResponseWriter, r *http.Request) {
+	var req QueueNextRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.UserID = strings.TrimSpace(req.UserID)
+	if req.UserID == "" {
+		http.Error(w, "userId is required", http.StatusBadRequest)
+		return
+	}
+	if h.playbackQueue == nil {
+		http.Error(w, "playback queue not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	item, remaining, err := h.playbackQueue.Next(req.UserID, req.CurrentItemID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, playback_queue.ErrItemNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if item == nil {
+		http.Error(w, "playback queue is empty", http.StatusNotFound)
+		return
+	}
+
+	clientID := strings.TrimSpace(req.ClientID)
+	if clientID == "" {
+		clientID = strings.TrimSpace(r.Header.Get("X-Client-ID"))
+	}
+
+	mediaType := "movie"
+	var target *models.EpisodeReference
+	if item.MediaType == "episode" {
+		mediaType = "series"
+		target = &models.EpisodeReference{
+			SeasonNumber:  item.SeasonNumber,
+			EpisodeNumber: item.EpisodeNumber,
+		}
+	}
+
+	entry, ok := h.store.GetByTitleUser(item.TitleID, req.UserID)
+	if ok && sameEpisode(entry.TargetEpisode, target) && entry.Status != playback.PrequeueStatusFailed {
+		log.Printf("[prequeue] queue next: reusing prequeue %s for queue item %s", entry.ID, item.ID)
+	} else {
+		log.Printf("[prequeue] queue next: prequeuing queue item %s (%s %s) for user %s", item.ID, item.MediaType, item.TitleID, req.UserID)
+		entry = h.startPrequeue(playback.PrequeueRequest{
+			TitleID:         item.TitleID,
+			TitleName:       item.TitleName,
+			MediaType:       mediaType,
+			UserID:          req.UserID,
+			ClientID:        clientID,
+			ImdbID:          item.ImdbID,
+			Year:            item.Year,
+			SeasonNumber:    item.SeasonNumber,
+			EpisodeNumber:   item.EpisodeNumber,
+			Reason:          "playback_queue",
+			BandwidthMbps:   req.BandwidthMbps,
+			IgnoreBandwidth: req.IgnoreBandwidth,
+		}, item.TitleName, mediaType, clientID, target)
+	}
+
+	wait := upNextDefaultWait
+	if req.WaitSeconds > 0 {
+		wait = time.Duration(req.WaitSeconds) * time.Second
+	}
+	if wait > upNextMaxWait {
+		wait = upNextMaxWait
+	}
+	status := h.waitForPrequeue(r, entry.ID, wait)
+	if status == nil {
+		http.Error(w, "prequeue not found or expired", http.StatusNotFound)
+		return
+	}
+	if h.demoMode {
+		status.DisplayName = buildDisplayName(item.TitleName, item.Year, target)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(QueueNextResponse{
+		Item:      *item,
+		Remaining: remaining,
+		Prequeue:  status,
+	})
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/playback"
+)
+
+const (
+	// upNextDefaultWait is how long UpNext waits for the next episode's prequeue
+	// to finish when the client doesn't ask for a specific wait.
+	upNextDefaultWait = 20 * time.Second
+	// upNextMaxWait caps the wait a client can request.
+	upNextMaxWait = 60 * time.Second
+	// upNextPollInterval is how often the prequeue entry is checked while waiting.
+	upNextPollInterval = 250 * time.Millisecond
+)
+
+// UpNextRequest identifies the episode that is about to finish playing.
+type UpNextRequest struct {
+	TitleID         string  `json:"titleId"`
+	TitleName       string  `json:"titleName"`
+	UserID          string  `json:"userId"`
+	ClientID        string  `json:"clientId,omitempty"`
+	ImdbID          string  `json:"imdbId,omitempty"`
+	TVDBID          int64   `json:"tvdbId,omitempty"`
+	TMDBID          int64   `json:"tmdbId,omitempty"`
+	Year            int     `json:"year,omitempty"`
+	SeasonNumber    int     `json:"seasonNumber"`  // Currently playing season
+	EpisodeNumber   int     `json:"episodeNumber"` // Currently playing episode
+	BandwidthMbps   float64 `json:"bandwidthMbps,omitempty"`
+	IgnoreBandwidth bool    `json:"ignoreBandwidth,omitempty"`
+	// WaitSeconds is how long to wait for the stream to resolve before
+	// responding (default 20, max 60). Clients can poll the prequeue status
+	// with the returned prequeueId if it isn't ready yet.
+	WaitSeconds int `json:"waitSeconds,omitempty"`
+}
+
+// UpNextResponse bundles everything needed to render an Up Next card and
+// start the next episode as soon as the countdown expires.
+type UpNextResponse struct {
+	Episode  models.SeriesEpisode             `json:"episode"`
+	Poster   *models.Image                    `json:"poster,omitempty"`
+	Backdrop *models.Image                    `json:"backdrop,omitempty"`
+	Logo     *models.Image                    `json:"logo,omitempty"`
+	Prequeue *playback.PrequeueStatusResponse `json:"prequeue"`
+}
+
+// UpNext resolves the episode following the one currently playing and returns
+// its metadata and artwork together with its prequeue result: resolved stream,
+// HLS session and selected tracks. An existing prequeue for that episode is
+// reused. Returns 404 when the current episode is the last one.
+func (h *PrequeueHandler) UpNext(w http.ResponseWriter, r *http.Request) {
+	var req UpNextRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.TitleID = strings.TrimSpace(req.TitleID)
+	req.TitleName = strings.TrimSpace(req.TitleName)
+	req.UserID = strings.TrimSpace(req.UserID)
+	if req.TitleID == "" {
+		http.Error(w, "titleId is required", http.StatusBadRequest)
+		return
+	}
+	if req.TitleName == "" {
+		http.Error(w, "titleName is required", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" {
+		http.Error(w, "userId is required", http.StatusBadRequest)
+		return
+	}
+	if req.SeasonNumber <= 0 || req.EpisodeNumber <= 0 {
+		http.Error(w, "seasonNumber and episodeNumber are required", http.StatusBadRequest)
+		return
+	}
+	if h.metadataSvc == nil {
+		http.Error(w, "metadata service not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	clientID := strings.TrimSpace(req.ClientID)
+	if clientID == "" {
+		clientID = strings.TrimSpace(r.Header.Get("X-Client-ID"))
+	}
+
+	details, err := h.metadataSvc.SeriesDetails(r.Context(), models.SeriesDetailsQuery{
+		TitleID: req.TitleID,
+		Name:    req.TitleName,
+		Year:    req.Year,
+		TVDBID:  req.TVDBID,
+		TMDBID:  req.TMDBID,
+	})
+	if err != nil {
+		log.Printf("[prequeue] up next: series details failed for %s: %v", req.TitleID, err)
+		http.Error(w, "failed to load series details", http.StatusBadGateway)
+		return
+	}
+
+	specialsMode := models.SpecialsSeparate
+	if h.userSettingsSvc != nil {
+		if userSettings, err := h.userSettingsSvc.Get(req.UserID); err == nil && userSettings != nil {
+			specialsMode = userSettings.Specials.Mode
+		}
+	}
+
+	next := nextEpisodeAfter(details, req.SeasonNumber, req.EpisodeNumber, specialsMode, time.Now())
+	if next == nil {
+		http.Error(w, "no next episode", http.StatusNotFound)
+		return
+	}
+	target := &models.EpisodeReference{
+		SeasonNumber:          next.SeasonNumber,
+		EpisodeNumber:         next.EpisodeNumber,
+		AbsoluteEpisodeNumber: next.AbsoluteEpisodeNumber,
+	}
+
+	entry, ok := h.store.GetByTitleUser(req.TitleID, req.UserID)
+	if ok && sameEpisode(entry.TargetEpisode, target) && entry.Status != playback.PrequeueStatusFailed {
+		log.Printf("[prequeue] up next: reusing prequeue %s for %s S%02dE%02d", entry.ID, req.TitleID, target.SeasonNumber, target.EpisodeNumber)
+	} else {
+		log.Printf("[prequeue] up next: prequeuing %s S%02dE%02d for user %s", req.TitleID, target.SeasonNumber, target.EpisodeNumber, req.UserID)
+		entry = h.startPrequeue(playback.PrequeueRequest{
+			TitleID:               req.TitleID,
+			TitleName:             req.TitleName,
+			MediaType:             "series",
+			UserID:                req.UserID,
+			ClientID:              clientID,
+			ImdbID:                req.ImdbID,
+			Year:                  req.Year,
+			SeasonNumber:          target.SeasonNumber,
+			EpisodeNumber:         target.EpisodeNumber,
+			AbsoluteEpisodeNumber: target.AbsoluteEpisodeNumber,
+			Reason:                "next_episode",
+			BandwidthMbps:         req.BandwidthMbps,
+			IgnoreBandwidth:       req.IgnoreBandwidth,
+		}, req.TitleName, "series", clientID, target)
+	}
+
+	wait := upNextDefaultWait
+	if req.WaitSeconds > 0 {
+		wait = time.Duration(req.WaitSeconds) * time.Second
+	}
+	if wait > upNextMaxWait {
+		wait = upNextMaxWait
+	}
+	status := h.waitForPrequeue(r, entry.ID, wait)
+	if status == nil {
+		http.Error(w, "prequeue not found or expired", http.StatusNotFound)
+		return
+	}
+	if h.demoMode {
+		status.DisplayName = buildDisplayName(req.TitleName, req.Year, target)
+	}
+
+	resp := UpNextResponse{
+		Episode:  *next,
+		Poster:   details.Title.Poster,
+		Backdrop: details.Title.Backdrop,
+		Logo:     details.Title.Logo,
+		Prequeue: status,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// waitForPrequeue polls a prequeue entry until it is ready or failed, the wait
+// elapses or the client goes away, and returns its latest status. Returns nil
+// if the entry no longer exists.
+func (h *PrequeueHandler) waitForPrequeue(r *http.Request, prequeueID string, wait time.Duration) *playback.PrequeueStatusResponse {
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(upNextPollInterval)
+	defer ticker.Stop()
+
+	for {
+		entry, ok := h.store.Get(prequeueID)
+		if !ok {
+			return nil
+		}
+		status := entry.ToResponse()
+		h.attachExternalSubtitles(status, entry.TitleID)
+		if status.Status == playback.PrequeueStatusReady || status.Status == playback.PrequeueStatusFailed {
+			return status
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return status
+		case <-r.Context().Done():
+			return status
+		}
+	}
+}
+
+// nextEpisodeAfter returns the episode following season/episode in viewing
+// order for the profile's specials mode, so specials only come up next when
+// they are shown inline. Returns nil when there is none or it hasn't aired as
+// of now.
+func nextEpisodeAfter(details *models.SeriesDetails, season, episode int, specialsMode string, now time.Time) *models.SeriesEpisode {
+	if details == nil {
+		return nil
+	}
+
+	episodes := models.EpisodesInViewingOrder(details, specialsMode)
+	var next *models.SeriesEpisode
+	found := false
+	for i := range episodes {
+		if episodes[i].SeasonNumber == season && episodes[i].EpisodeNumber == episode {
+			found = true
+			if i+1 < len(episodes) {
+				next = &episodes[i+1]
+			}
+			break
+		}
+	}
+	if !found {
+		// The episode is missing from the metadata; take the first one after it
+		for i := range episodes {
+			ep := episodes[i]
+			if ep.SeasonNumber > season || (ep.SeasonNumber == season && ep.EpisodeNumber > episode) {
+				next = &episodes[i]
+				break
+			}
+		}
+	}
+	if next == nil {
+		return nil
+	}
+
+	if next.AiredDate != "" {
+		if aired, err := time.Parse("2006-01-02", next.AiredDate); err == nil && aired.After(now) {
+			return nil
+		}
+	}
+	return next
+}
+
+// sameEpisode reports whether two episode references point at the same episode.
+func sameEpisode(a, b *models.EpisodeReference) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.SeasonNumber == b.SeasonNumber && a.EpisodeNumber == b.EpisodeNumber
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestNextEpisodeAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	details := &models.SeriesDetails{
+		Seasons: []models.SeriesSeason{
+			{Number: 0, Episodes: []models.SeriesEpisode{{SeasonNumber: 0, EpisodeNumber: 1, Name: "Special"}}},
+			{Number: 2, Episodes: []models.SeriesEpisode{
+				{SeasonNumber: 2, EpisodeNumber: 1, Name: "S2E1", AiredDate: "2026-02-01"},
+				{SeasonNumber: 2, EpisodeNumber: 2, Name: "S2E2", AiredDate: "2026-04-01"},
+			}},
+			{Number: 1, Episodes: []models.SeriesEpisode{
+				{SeasonNumber: 1, EpisodeNumber: 2, Name: "S1E2"},
+				{SeasonNumber: 1, EpisodeNumber: 1, Name: "S1E1"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		season  int
+		episode int
+		want    string
+	}{
+		{name: "next in season", season: 1, episode: 1, want: "S1E2"},
+		{name: "rolls over to next season", season: 1, episode: 2, want: "S2E1"},
+		{name: "next episode not aired yet", season: 2, episode: 1, want: ""},
+		{name: "last episode", season: 2, episode: 2, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextEpisodeAfter(details, tt.season, tt.episode, models.SpecialsSeparate, now)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("nextEpisodeAfter(S%dE%d) = %q, want %q", tt.season, tt.episode, name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextEpisodeAfterInlineSpecial(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	details := &models.SeriesDetails{
+		Seasons: []models.SeriesSeason{
+			{Number: 0, Episodes: []models.SeriesEpisode{{SeasonNumber: 0, EpisodeNumber: 1, Name: "Special", AiredDate: "2025-12-25"}}},
+			{Number: 1, Episodes: []models.SeriesEpisode{
+				{SeasonNumber: 1, EpisodeNumber: 1, Name: "S1E1", AiredDate: "2025-12-01"},
+				{SeasonNumber: 1, EpisodeNumber: 2, Name: "S1E2", AiredDate: "2026-01-05"},
+			}},
+		},
+	}
+
+	if got := nextEpisodeAfter(details, 1, 1, models.SpecialsInline, now); got == nil || got.Name != "Special" {
+		t.Fatalf("expected the inline special next, got %+v", got)
+	}
+	if got := nextEpisodeAfter(details, 1, 1, models.SpecialsHidden, now); got == nil || got.Name != "S1E2" {
+		t.Fatalf("expected hidden specials to be skipped, got %+v", got)
+	}
+}
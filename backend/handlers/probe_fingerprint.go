@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/services/streaming"
+)
+
+const (
+	// fingerprintRecheckInterval bounds how often a cached source is
+	// re-checked, so track switches don't each cost a HEAD request
+	fingerprintRecheckInterval = 2 * time.Minute
+	fingerprintTimeout         = 10 * time.Second
+)
+
+// sourceFingerprints identifies the file currently behind a path by its size
+// and ETag (or Last-Modified). Probe caches store it alongside each result and
+// drop the result when it changes, e.g. when a debrid link now points at a
+// re-cached release.
+type sourceFingerprints struct {
+	streamer streaming.Provider
+	client   *http.Client
+
+	mu      sync.Mutex
+	entries map[string]fingerprintEntry
+}
+
+type fingerprintEntry struct {
+	value     string
+	checkedAt time.Time
+}
+
+func newSourceFingerprints(streamer streaming.Provider) *sourceFingerprints {
+	return &sourceFingerprints{
+		streamer: streamer,
+		client:   &http.Client{Timeout: fingerprintTimeout},
+		entries:  make(map[string]fingerprintEntry),
+	}
+}
+
+// get returns the fingerprint of the file behind path, or "" if the source
+// doesn't report a size or validator.
+func (f *sourceFingerprints) get(ctx context.Context, path string) string {
+	if f == nil || path == "" {
+		return ""
+	}
+
+	f.mu.Lock()
+	entry, ok := f.entries[path]
+	f.mu.Unlock()
+	if ok && time.Since(entry.checkedAt) < fingerprintRecheckInterval {
+		return entry.value
+	}
+
+	value := f.fetch(ctx, path)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	f.entries[path] = fingerprintEntry{value: value, checkedAt: now}
+	// Drop stale entries while we hold the lock
+	for key, stale := range f.entries {
+		if now.Sub(stale.checkedAt) > probeCacheTTL {
+			delete(f.entries, key)
+		}
+	}
+	return value
+}
+
+// fetch HEADs the source directly for external URLs, otherwise through the
+// stream provider, which resolves debrid paths to their current link.
+func (f *sourceFingerprints) fetch(ctx context.Context, path string) string {
+	ctx, cancel := context.WithTimeout(ctx, fingerprintTimeout)
+	defer cancel()
+
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, path, nil)
+		if err != nil {
+			return ""
+		}
+		req.Header.Set("User-Agent", "VLC/3.0.18 LibVLC/3.0.18")
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return ""
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return ""
+		}
+		return formatFingerprint(resp.ContentLength, resp.Header)
+	}
+
+	if f.streamer == nil {
+		return ""
+	}
+	resp, err := f.streamer.Stream(ctx, streaming.Request{Path: path, Method: http.MethodHead})
+	if err != nil || resp == nil {
+		return ""
+	}
+	defer resp.Close()
+	return formatFingerprint(resp.ContentLength, resp.Headers)
+}
+
+// formatFingerprint combines size and validator. ETags identify content best;
+// Last-Modified is the fallback for servers that don't send one.
+func formatFingerprint(size int64, headers http.Header) string {
+	if size <= 0 && headers != nil {
+		if parsed, err := strconv.ParseInt(headers.Get("Content-Length"), 10, 64); err == nil {
+			size = parsed
+		}
+	}
+
+	var validator string
+	if headers != nil {
+		validator = strings.TrimPrefix(headers.Get("ETag"), "W/")
+		if validator == "" {
+			validator = headers.Get("Last-Modified")
+		}
+	}
+
+	if size <= 0 && validator == "" {
+		return ""
+	}
+	return strconv.FormatInt(size, 10) + ":" + validator
+}
+
+// fingerprintChanged reports whether a cached fingerprint no longer matches
+// the source. Unknown fingerprints never invalidate.
+func fingerprintChanged(cached, current string) bool {
+	return cached != "" && current != "" && cached != current
+}
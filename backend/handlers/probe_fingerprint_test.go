@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFormatFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		headers http.Header
+		want    string
+	}{
+		{"etag", 100, http.Header{"Etag": {`W/"abc"`}, "Last-Modified": {"yesterday"}}, `100:"abc"`},
+		{"last modified", 100, http.Header{"Last-Modified": {"yesterday"}}, "100:yesterday"},
+		{"content length header", 0, http.Header{"Content-Length": {"42"}}, "42:"},
+		{"nothing known", 0, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFingerprint(tt.size, tt.headers); got != tt.want {
+				t.Errorf("formatFingerprint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeCacheInvalidatedWhenSourceChanges(t *testing.T) {
+	provider := &hlsTestProvider{data: make([]byte, 100), headers: http.Header{"Etag": {`"v1"`}}}
+	manager := NewHLSManager(t.TempDir(), "", "", provider)
+	defer manager.Shutdown()
+
+	ctx := context.Background()
+	const path = "/debrid/realdebrid/123/movie.mkv"
+	manager.CacheProbe(ctx, path, &UnifiedProbeResult{Duration: 60})
+
+	if got := manager.GetCachedProbe(ctx, path); got == nil || got.Duration != 60 {
+		t.Fatalf("GetCachedProbe() = %+v, want cached result", got)
+	}
+
+	// The link now serves a re-cached release; once the fingerprint is
+	// rechecked the stale probe must not be used
+	provider.data = make([]byte, 200)
+	provider.headers = http.Header{"Etag": {`"v2"`}}
+	if got := manager.GetCachedProbe(ctx, path); got == nil {
+		t.Fatal("fingerprint was rechecked before the recheck interval")
+	}
+	manager.fingerprints.mu.Lock()
+	entry := manager.fingerprints.entries[path]
+	entry.checkedAt = time.Now().Add(-fingerprintRecheckInterval)
+	manager.fingerprints.entries[path] = entry
+	manager.fingerprints.mu.Unlock()
+
+	if got := manager.GetCachedProbe(ctx, path); got != nil {
+		t.Fatalf("GetCachedProbe() = %+v after the source changed, want nil", got)
+	}
+	if _, ok := manager.probeCache[path]; ok {
+		t.Error("stale probe entry was not removed")
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// maxConcurrentProbes bounds how many ffprobe runs execute at once across
+	// direct probes, prequeue and HLS.
+	maxConcurrentProbes = 4
+	// probeRunTimeout caps a single deduplicated probe, which outlives the
+	// request that started it so waiters aren't failed by its cancellation.
+	probeRunTimeout = 3 * time.Minute
+)
+
+// sharedProbePool is used by both VideoHandler and HLSManager so their probes
+// share one worker limit. Deduplication is per key, and the two run different
+// probes under different key prefixes ("ffprobe:" and "unified:"), so a path
+// opened through both is probed once by each.
+var sharedProbePool = newProbePool(maxConcurrentProbes)
+
+// probePool deduplicates concurrent probes of the same key and limits how many
+// probes run at once.
+type probePool struct {
+	slots chan struct{}
+
+	inflightMu     sync.Mutex
+	inflightProbes map[string]*inflightProbe
+}
+
+type inflightProbe struct {
+	done    chan struct{}
+	result  interface{}
+	err     error
+	waiters int
+}
+
+func newProbePool(workers int) *probePool {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &probePool{
+		slots:          make(chan struct{}, workers),
+		inflightProbes: make(map[string]*inflightProbe),
+	}
+}
+
+// do runs probe for key, or waits for an identical probe that is already in
+// flight and shares its result. Callers stop waiting when their own ctx ends.
+func (p *probePool) do(ctx context.Context, key string, probe func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	p.inflightMu.Lock()
+	if inflight, exists := p.inflightProbes[key]; exists {
+		// Another request is already probing this path
+		inflight.waiters++
+		p.inflightMu.Unlock()
+		log.Printf("[probe] waiting for inflight probe key=%q", key)
+		select {
+		case <-inflight.done:
+			return inflight.result, inflight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Create a new inflight probe
+	inflight := &inflightProbe{done: make(chan struct{})}
+	p.inflightProbes[key] = inflight
+	p.inflightMu.Unlock()
+
+	go func() {
+		probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeRunTimeout)
+		defer cancel()
+
+		// Wait for a free worker slot, giving up if the run times out first
+		var result interface{}
+		var err error
+		select {
+		case p.slots <- struct{}{}:
+			result, err = probe(probeCtx)
+			<-p.slots
+		case <-probeCtx.Done():
+			err = probeCtx.Err()
+		}
+
+		// Store the result and signal completion
+		inflight.result = result
+		inflight.err = err
+
+		// Clean up the inflight probe before waking waiters so a failed probe
+		// can be retried by the next caller
+		p.inflightMu.Lock()
+		delete(p.inflightProbes, key)
+		if inflight.waiters > 0 {
+			log.Printf("[probe] probe key=%q shared with %d waiting callers", key, inflight.waiters)
+		}
+		p.inflightMu.Unlock()
+
+		close(inflight.done)
+	}()
+
+	select {
+	case <-inflight.done:
+		return inflight.result, inflight.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProbePoolDeduplicatesConcurrentProbes(t *testing.T) {
+	pool := newProbePool(2)
+
+	var runs int32
+	release := make(chan struct{})
+	probe := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return "probed", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := pool.do(context.Background(), "ffprobe:/movie.mkv", probe)
+			if err != nil {
+				t.Errorf("do() error = %v", err)
+			}
+			results[i] = result
+		}(i)
+	}
+
+	// Give every caller time to join the inflight probe before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Fatalf("expected a single probe run, got %d", got)
+	}
+	for i, result := range results {
+		if result != "probed" {
+			t.Fatalf("caller %d got %v", i, result)
+		}
+	}
+}
+
+func TestProbePoolBoundsConcurrency(t *testing.T) {
+	pool := newProbePool(2)
+
+	var active, peak int32
+	probe := func(ctx context.Context) (interface{}, error) {
+		now := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			pool.do(context.Background(), key, probe)
+		}(key)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got > 2 {
+		t.Fatalf("expected at most 2 concurrent probes, got %d", got)
+	}
+}
+
+func TestProbePoolWaiterHonoursOwnContext(t *testing.T) {
+	pool := newProbePool(1)
+
+	release := make(chan struct{})
+	defer close(release)
+	probe := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "late", nil
+	}
+
+	go pool.do(context.Background(), "slow", probe)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.do(ctx, "slow", probe); err == nil {
+		t.Fatal("expected waiter to return once its context expired")
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"novastream/config"
+	"novastream/utils/releasename"
+)
+
+// probeSource is the kind of source a provider probe reads from; each has its
+// own configurable ffprobe strategy.
+type probeSource string
+
+const (
+	probeSourceDebrid probeSource = "debrid"
+	probeSourceUsenet probeSource = "usenet"
+)
+
+const (
+	// probeFastPathMinProbes is how many full probes of a release group and
+	// container must go by without needing frame sampling before it is skipped
+	probeFastPathMinProbes = 3
+	// probeHistoryMaxEntries bounds the release group/container history
+	probeHistoryMaxEntries = 2000
+)
+
+// sharedProbeHistory remembers which release groups and containers needed
+// frame sampling, for the probe fast path.
+var sharedProbeHistory = newProbeHistory()
+
+// ffprobeOptions are the tunable parts of an ffprobe invocation.
+type ffprobeOptions struct {
+	probeSizeBytes    int64
+	analyzeDurationUs int64
+	sampleFrames      bool
+}
+
+// defaultFFprobeOptions are used by probes that don't pick a strategy.
+var defaultFFprobeOptions = newFFprobeOptions(config.ProbeStrategy{}.WithDefaults())
+
+func newFFprobeOptions(strategy config.ProbeStrategy) ffprobeOptions {
+	return ffprobeOptions{
+		probeSizeBytes:    int64(strategy.ProbeSizeKB) * 1000,
+		analyzeDurationUs: int64(strategy.AnalyzeDurationMs) * 1000,
+		sampleFrames:      true,
+	}
+}
+
+func (o ffprobeOptions) args() []string {
+	args := []string{
+		"-probesize", strconv.FormatInt(o.probeSizeBytes, 10),
+		"-analyzeduration", strconv.FormatInt(o.analyzeDurationUs, 10),
+	}
+	if o.sampleFrames {
+		args = append(args, ffprobeFrameSampleArgs...)
+	}
+	return args
+}
+
+// probeSourceForPath classifies a provider path: external URLs and debrid
+// paths are read by URL from a debrid service, anything else is usenet.
+func probeSourceForPath(cleanPath string) probeSource {
+	if strings.HasPrefix(cleanPath, "http://") || strings.HasPrefix(cleanPath, "https://") ||
+		strings.HasPrefix(cleanPath, "/debrid/") {
+		return probeSourceDebrid
+	}
+	return probeSourceUsenet
+}
+
+// probeStrategy returns the configured strategy for a source with defaults filled in.
+func (h *VideoHandler) probeStrategy(source probeSource) config.ProbeStrategy {
+	var strategy config.ProbeStrategy
+	if h.configManager != nil {
+		if settings, err := h.configManager.Load(); err == nil {
+			switch source {
+			case probeSourceDebrid:
+				strategy = settings.Transmux.Probe.Debrid
+			case probeSourceUsenet:
+				strategy = settings.Transmux.Probe.Usenet
+			}
+		}
+	}
+	return strategy.WithDefaults()
+}
+
+// probeHistoryKey identifies a release by its group and container, e.g.
+// "ntb|mkv" for "Show.S01E01.1080p.WEB-NTb.mkv". Returns "" when either is unknown.
+func probeHistoryKey(source string) string {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		parsed, err := url.Parse(source)
+		if err != nil {
+			return ""
+		}
+		source = parsed.Path
+	}
+
+	base := path.Base(source)
+	container := strings.ToLower(strings.TrimPrefix(path.Ext(base), "."))
+	if container == "" || len(container) > 5 {
+		return ""
+	}
+
+	group := strings.ToLower(releasename.Parse(base).Group)
+	if group == "" || strings.ContainsAny(group, " |") {
+		return ""
+	}
+	return group + "|" + container
+}
+
+type probeHistoryEntry struct {
+	probes       int
+	framesNeeded bool
+}
+
+// probeHistory counts full probes per release group and container and
+// whether any of them found information only frame sampling provides.
+type probeHistory struct {
+	mu      sync.Mutex
+	entries map[string]probeHistoryEntry
+}
+
+func newProbeHistory() *probeHistory {
+	return &probeHistory{entries: make(map[string]probeHistoryEntry)}
+}
+
+// canSkipFrames reports whether frame sampling can be skipped for key.
+func (p *probeHistory) canSkipFrames(key string) bool {
+	if key == "" {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry := p.entries[key]
+	return entry.probes >= probeFastPathMinProbes && !entry.framesNeeded
+}
+
+// record notes the outcome of a full probe for key.
+func (p *probeHistory) record(key string, framesNeeded bool) {
+	if key == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.entries[key]
+	if !ok && len(p.entries) >= probeHistoryMaxEntries {
+		return
+	}
+	entry.probes++
+	entry.framesNeeded = entry.framesNeeded || framesNeeded
+	p.entries[key] = entry
+}
+
+// frameSamplingMattered reports whether sampled frames carried HDR10+
+// metadata that a video stream's own side data didn't show.
+func frameSamplingMattered(meta *ffprobeOutput) bool {
+	if meta == nil {
+		return false
+	}
+	for i := range meta.Streams {
+		stream := &meta.Streams[i]
+		if stream.CodecType != "video" || detectHDR10Plus(stream, nil) {
+			continue
+		}
+		if detectHDR10Plus(stream, meta.Frames) {
+			return true
+		}
+	}
+	return false
+}
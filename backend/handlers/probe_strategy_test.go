@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"novastream/config"
+)
+
+func TestProbeHistoryKey(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"/usenet/Show.S01E01.1080p.WEB.h264-NTb.mkv", "ntb|mkv"},
+		{"/debrid/realdebrid/123/Movie.2024.2160p.UHD.BluRay.x265-TERMiNAL.mp4", "terminal|mp4"},
+		{"https://cdn.example.com/dl/abc/Movie.2024.1080p-FLUX.mkv?token=x", "flux|mkv"},
+		{"/usenet/Home Movie.mkv", ""},
+		{"/usenet/Show.S01E01-NTb", ""},
+		{"/usenet/Show.S01E01.1080p.WEB-DL.mkv", ""},
+	}
+	for _, tt := range tests {
+		if got := probeHistoryKey(tt.source); got != tt.want {
+			t.Errorf("probeHistoryKey(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestProbeHistoryFastPath(t *testing.T) {
+	history := newProbeHistory()
+
+	for i := 0; i < probeFastPathMinProbes; i++ {
+		if history.canSkipFrames("ntb|mkv") {
+			t.Fatalf("frames skipped after %d probes", i)
+		}
+		history.record("ntb|mkv", false)
+	}
+	if !history.canSkipFrames("ntb|mkv") {
+		t.Fatal("expected frames to be skipped after enough probes")
+	}
+
+	// One release that needed frame sampling keeps it on for the group
+	history.record("ntb|mkv", true)
+	if history.canSkipFrames("ntb|mkv") {
+		t.Fatal("frames skipped after a probe needed them")
+	}
+
+	history.record("", false)
+	if history.canSkipFrames("") {
+		t.Fatal("unknown releases must never skip frames")
+	}
+}
+
+func TestFFprobeOptionsArgs(t *testing.T) {
+	args := strings.Join(defaultFFprobeOptions.args(), " ")
+	if !strings.Contains(args, "-probesize 1000000 -analyzeduration 500000") || !strings.Contains(args, "-show_frames") {
+		t.Fatalf("default args changed: %s", args)
+	}
+
+	opts := newFFprobeOptions(config.ProbeStrategy{ProbeSizeKB: 20000, AnalyzeDurationMs: 5000}.WithDefaults())
+	opts.sampleFrames = false
+	args = strings.Join(opts.args(), " ")
+	if args != "-probesize 20000000 -analyzeduration 5000000" {
+		t.Fatalf("unexpected args: %s", args)
+	}
+
+	if got := probeSourceForPath("/debrid/realdebrid/1/a.mkv"); got != probeSourceDebrid {
+		t.Errorf("debrid path classified as %s", got)
+	}
+	if got := probeSourceForPath("/usenet/a.mkv"); got != probeSourceUsenet {
+		t.Errorf("usenet path classified as %s", got)
+	}
+}
+
+func TestFrameSamplingMattered(t *testing.T) {
+	hdr10Plus := []ffprobeSideData{{SideDataType: "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}}
+	frames := []ffprobeFrame{{StreamIndex: 0, SideDataList: hdr10Plus}}
+
+	meta := &ffprobeOutput{Streams: []ffprobeStream{{Index: 0, CodecType: "video"}}, Frames: frames}
+	if !frameSamplingMattered(meta) {
+		t.Error("expected frame-only HDR10+ to need frame sampling")
+	}
+
+	meta.Streams[0].SideDataList = hdr10Plus
+	if frameSamplingMattered(meta) {
+		t.Error("stream-level HDR10+ does not need frame sampling")
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"novastream/models"
+	"novastream/services/favorites"
+	"novastream/services/history"
+	"novastream/services/user_settings"
+	"novastream/services/watchlist"
+)
+
+type profileWatchlistSource interface {
+	List(userID string) ([]models.WatchlistItem, error)
+}
+
+type profileHistorySource interface {
+	ListWatchHistory(userID string) ([]models.WatchHistoryItem, error)
+	ListPlaybackProgress(userID string) ([]models.PlaybackProgress, error)
+}
+
+type profileSettingsSource interface {
+	Get(userID string) (*models.UserSettings, error)
+}
+
+type profileFavoritesSource interface {
+	List(userID string) ([]models.FavoriteItem, error)
+}
+
+var (
+	_ profileWatchlistSource = (*watchlist.Service)(nil)
+	_ profileHistorySource   = (*history.Service)(nil)
+	_ profileSettingsSource  = (*user_settings.Service)(nil)
+	_ profileFavoritesSource = (*favorites.Service)(nil)
+)
+
+// ProfileExporter collects a profile's watchlist, favorites, history and settings into
+// a single document so it can be kept before the profile is deleted
+type ProfileExporter struct {
+	watchlist profileWatchlistSource
+	history   profileHistorySource
+	settings  profileSettingsSource
+	favorites profileFavoritesSource
+}
+
+// NewProfileExporter creates an exporter; any source may be nil
+func NewProfileExporter(watchlist profileWatchlistSource, history profileHistorySource, settings profileSettingsSource) *ProfileExporter {
+	return &ProfileExporter{watchlist: watchlist, history: history, settings: settings}
+}
+
+// SetFavorites includes the profile's favorites in exports
+func (e *ProfileExporter) SetFavorites(favorites profileFavoritesSource) {
+	e.favorites = favorites
+}
+
+// Export builds the export document for a profile
+func (e *ProfileExporter) Export(user models.User) (models.ProfileExport, error) {
+	export := models.ProfileExport{
+		Version:          models.ProfileExportVersion,
+		ExportedAt:       time.Now().UTC(),
+		Profile:          user,
+		Watchlist:        []models.WatchlistItem{},
+		Favorites:        []models.FavoriteItem{},
+		WatchHistory:     []models.WatchHistoryItem{},
+		PlaybackProgress: []models.PlaybackProgress{},
+	}
+
+	if e.watchlist != nil {
+		items, err := e.watchlist.List(user.ID)
+		if err != nil {
+			return models.ProfileExport{}, fmt.Errorf("export watchlist: %w", err)
+		}
+		if items != nil {
+			export.Watchlist = items
+		}
+	}
+
+	if e.favorites != nil {
+		items, err := e.favorites.List(user.ID)
+		if err != nil {
+			return models.ProfileExport{}, fmt.Errorf("export favorites: %w", err)
+		}
+		if items != nil {
+			export.Favorites = items
+		}
+	}
+
+	if e.history != nil {
+		items, err := e.history.ListWatchHistory(user.ID)
+		if err != nil {
+			return models.ProfileExport{}, fmt.Errorf("export watch history: %w", err)
+		}
+		if items != nil {
+			export.WatchHistory = items
+		}
+
+		progress, err := e.history.ListPlaybackProgress(user.ID)
+		if err != nil {
+			return models.ProfileExport{}, fmt.Errorf("export playback progress: %w", err)
+		}
+		if progress != nil {
+			export.PlaybackProgress = progress
+		}
+	}
+
+	if e.settings != nil {
+		settings, err := e.settings.Get(user.ID)
+		if err != nil {
+			return models.ProfileExport{}, fmt.Errorf("export settings: %w", err)
+		}
+		export.Settings = settings
+	}
+
+	return export, nil
+}
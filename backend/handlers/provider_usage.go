@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/provider_usage"
+)
+
+type providerUsageService interface {
+	Summaries() ([]models.ProviderUsageSummary, error)
+}
+
+var _ providerUsageService = (*provider_usage.Service)(nil)
+
+// ProviderUsageHandler exposes traffic used per debrid account and usenet provider
+type ProviderUsageHandler struct {
+	svc providerUsageService
+}
+
+// NewProviderUsageHandler creates a new provider usage handler
+func NewProviderUsageHandler(svc providerUsageService) *ProviderUsageHandler {
+	return &ProviderUsageHandler{svc: svc}
+}
+
+// List handles GET /api/admin/provider-usage
+// Returns each provider's usage this month against its quota, with daily and
+// monthly series for charts.
+func (h *ProviderUsageHandler) List(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.svc.Summaries()
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// Options handles CORS preflight requests
+func (h *ProviderUsageHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
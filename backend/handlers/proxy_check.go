@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/utils"
+)
+
+const (
+	proxyCheckPath = "/api/health/proxy-check"
+
+	// proxyCheckChunks and proxyCheckInterval shape the slow stream used to
+	// detect buffering: a buffering proxy delivers every chunk at the end.
+	proxyCheckChunks   = 6
+	proxyCheckInterval = 500 * time.Millisecond
+	// proxyCheckDefaultIdle is the silent gap in the long-lived check; nginx
+	// and many load balancers time out idle upstream reads at 60s.
+	proxyCheckDefaultIdle = 30 * time.Second
+	proxyCheckMaxIdle     = 120 * time.Second
+	proxyCheckMaxChunks   = 30
+	proxyCheckPayloadSize = 64 * 1024
+)
+
+// proxyCheckPayload is the synthetic body served for range requests.
+var proxyCheckPayload = func() []byte {
+	payload := make([]byte, proxyCheckPayloadSize)
+	for i := range payload {
+		payload[i] = byte(i*31 + i>>8)
+	}
+	return payload
+}()
+
+// ProxyCheckResult is the outcome of one reverse-proxy compatibility check.
+type ProxyCheckResult struct {
+	Name      string `json:"name"` // "chunked", "range" or "longLived"
+	OK        bool   `json:"ok"`
+	Detail    string `json:"detail"`
+	Hint      string `json:"hint,omitempty"` // Proxy setting to look at when the check fails
+	ElapsedMs int64  `json:"elapsedMs"`
+}
+
+// ProxyCheckReport is returned by the proxy check endpoint.
+type ProxyCheckReport struct {
+	BaseURL   string             `json:"baseUrl"`
+	Proxied   bool               `json:"proxied"` // The request reached strmr through a proxy
+	OK        bool               `json:"ok"`
+	Checks    []ProxyCheckResult `json:"checks"`
+	CheckedAt time.Time          `json:"checkedAt"`
+}
+
+// ProxyCheckHandler checks whether the reverse proxy in front of strmr keeps
+// streams working, by fetching synthetic responses from the server's own
+// external URL, through the proxy.
+type ProxyCheckHandler struct {
+	client *http.Client
+}
+
+// NewProxyCheckHandler creates the handler. The client may be nil.
+func NewProxyCheckHandler(client *http.Client) *ProxyCheckHandler {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &ProxyCheckHandler{client: client}
+}
+
+// Check runs the chunked transfer, range request and long-lived connection
+// checks against the URL the caller used to reach the server.
+// GET /api/health/proxy-check?idle=30
+func (h *ProxyCheckHandler) Check(w http.ResponseWriter, r *http.Request) {
+	idle := proxyCheckDefaultIdle
+	if raw := r.URL.Query().Get("idle"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > proxyCheckMaxIdle {
+			writeJSONError(w, fmt.Sprintf("idle must be between 0 and %d seconds", int(proxyCheckMaxIdle.Seconds())), http.StatusBadRequest)
+			return
+		}
+		idle = time.Duration(seconds) * time.Second
+	}
+
+	base := strings.TrimSuffix(utils.ExternalBaseURL(r), "/")
+	report := ProxyCheckReport{
+		BaseURL:   base,
+		Proxied:   r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("X-Forwarded-Host") != "" || r.Header.Get("Via") != "",
+		Checks:    runProxyChecks(r.Context(), h.client, base, proxyCheckCredentialsFrom(r), idle),
+		CheckedAt: time.Now().UTC(),
+	}
+	report.OK = true
+	for _, check := range report.Checks {
+		report.OK = report.OK && check.OK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// Stream writes chunks lines at interval, then stays silent for idle before
+// a final line. It is fetched through the proxy by Check.
+// GET /api/health/proxy-check/stream?chunks=6&intervalMs=500&idle=0
+func (h *ProxyCheckHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	chunks := clampQueryInt(query.Get("chunks"), proxyCheckChunks, 1, proxyCheckMaxChunks)
+	interval := time.Duration(clampQueryInt(query.Get("intervalMs"), int(proxyCheckInterval.Milliseconds()), 0, 5000)) * time.Millisecond
+	idle := time.Duration(clampQueryInt(query.Get("idle"), 0, 0, int(proxyCheckMaxIdle.Seconds()))) * time.Second
+
+	// Same headers as media streams, so the proxy treats it the same way
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	write := func(line string) bool {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+	wait := func(d time.Duration) bool {
+		select {
+		case <-r.Context().Done():
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	for i := 0; i < chunks; i++ {
+		if i > 0 && !wait(interval) {
+			return
+		}
+		if !write(fmt.Sprintf("chunk %d", i)) {
+			return
+		}
+	}
+	if idle > 0 && !wait(idle) {
+		return
+	}
+	write("done")
+}
+
+// Payload serves a fixed synthetic body with range request support.
+// GET /api/health/proxy-check/payload
+func (h *ProxyCheckHandler) Payload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "payload.bin", time.Time{}, bytes.NewReader(proxyCheckPayload))
+}
+
+// proxyCheckCredentials carries the caller's credentials over to the check
+// requests, which go through the same authentication.
+type proxyCheckCredentials struct {
+	authorization string
+	pin           string
+	token         string
+}
+
+func proxyCheckCredentialsFrom(r *http.Request) proxyCheckCredentials {
+	return proxyCheckCredentials{
+		authorization: r.Header.Get("Authorization"),
+		pin:           r.Header.Get("X-PIN"),
+		token:         r.URL.Query().Get("token"),
+	}
+}
+
+func (c proxyCheckCredentials) request(ctx context.Context, target string, query url.Values) (*http.Request, error) {
+	if c.token != "" {
+		query.Set("token", c.token)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	if c.pin != "" {
+		req.Header.Set("X-PIN", c.pin)
+	}
+	return req, nil
+}
+
+// runProxyChecks fetches the synthetic endpoints under base.
+func runProxyChecks(ctx context.Context, client *http.Client, base string, creds proxyCheckCredentials, idle time.Duration) []ProxyCheckResult {
+	return []ProxyCheckResult{
+		checkChunkedStream(ctx, client, base, creds),
+		checkRangeRequest(ctx, client, base, creds),
+		checkLongLived(ctx, client, base, creds, idle),
+	}
+}
+
+// checkChunkedStream reports whether stream chunks arrive as they are
+// written rather than all at once when the response completes.
+func checkChunkedStream(ctx context.Context, client *http.Client, base string, creds proxyCheckCredentials) ProxyCheckResult {
+	result := ProxyCheckResult{Name: "chunked", Hint: "disable response buffering for strmr (nginx: proxy_buffering off)"}
+	total := time.Duration(proxyCheckChunks-1) * proxyCheckInterval
+
+	arrivals, elapsed, err := readProxyCheckStream(ctx, client, base, creds, url.Values{
+		"chunks":     {strconv.Itoa(proxyCheckChunks)},
+		"intervalMs": {strconv.FormatInt(proxyCheckInterval.Milliseconds(), 10)},
+	}, total+30*time.Second)
+	result.ElapsedMs = elapsed.Milliseconds()
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case len(arrivals) < proxyCheckChunks:
+		result.Detail = fmt.Sprintf("received %d of %d chunks", len(arrivals), proxyCheckChunks)
+	case arrivals[0] > total/2:
+		result.Detail = fmt.Sprintf("first chunk arrived after %s of a %s stream; the response was buffered", arrivals[0].Round(time.Millisecond), total)
+	default:
+		result.OK = true
+		result.Hint = ""
+		result.Detail = fmt.Sprintf("first chunk after %s, all %d chunks streamed", arrivals[0].Round(time.Millisecond), proxyCheckChunks)
+	}
+	return result
+}
+
+// checkRangeRequest reports whether a byte range request is answered with
+// the matching partial content.
+func checkRangeRequest(ctx context.Context, client *http.Client, base string, creds proxyCheckCredentials) ProxyCheckResult {
+	result := ProxyCheckResult{Name: "range", Hint: "pass Range and Content-Range headers through and don't compress or cache media responses"}
+	start, end := 1000, 1999
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := creds.request(ctx, base+proxyCheckPath+"/payload", url.Values{})
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	began := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, proxyCheckPayloadSize+1))
+	result.ElapsedMs = time.Since(began).Milliseconds()
+
+	wantRange := fmt.Sprintf("bytes %d-%d/%d", start, end, proxyCheckPayloadSize)
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case resp.StatusCode != http.StatusPartialContent:
+		result.Detail = fmt.Sprintf("expected 206 Partial Content, got %s", resp.Status)
+	case resp.Header.Get("Content-Range") != wantRange:
+		result.Detail = fmt.Sprintf("expected Content-Range %q, got %q", wantRange, resp.Header.Get("Content-Range"))
+	case !bytes.Equal(body, proxyCheckPayload[start:end+1]):
+		result.Detail = fmt.Sprintf("partial content did not match the requested bytes (%d bytes received)", len(body))
+	default:
+		result.OK = true
+		result.Hint = ""
+		result.Detail = "partial content returned for " + wantRange
+	}
+	return result
+}
+
+// checkLongLived reports whether a response that stays silent for idle
+// survives the proxy's read and connection timeouts.
+func checkLongLived(ctx context.Context, client *http.Client, base string, creds proxyCheckCredentials, idle time.Duration) ProxyCheckResult {
+	result := ProxyCheckResult{Name: "longLived", Hint: "raise the proxy's read/idle timeout for strmr (nginx: proxy_read_timeout)"}
+	if idle <= 0 {
+		result.OK = true
+		result.Hint = ""
+		result.Detail = "skipped"
+		return result
+	}
+
+	arrivals, elapsed, err := readProxyCheckStream(ctx, client, base, creds, url.Values{
+		"chunks": {"1"},
+		"idle":   {strconv.Itoa(int(idle.Seconds()))},
+	}, idle+30*time.Second)
+	result.ElapsedMs = elapsed.Milliseconds()
+	switch {
+	case err != nil:
+		result.Detail = fmt.Sprintf("connection dropped after %s: %v", elapsed.Round(time.Second), err)
+	case len(arrivals) < 2:
+		result.Detail = fmt.Sprintf("connection closed after %s of a %s silent gap", elapsed.Round(time.Second), idle)
+	default:
+		result.OK = true
+		result.Hint = ""
+		result.Detail = fmt.Sprintf("connection survived a %s silent gap", idle)
+	}
+	return result
+}
+
+// readProxyCheckStream fetches the synthetic stream and returns when each line
+// arrived, relative to the request.
+func readProxyCheckStream(ctx context.Context, client *http.Client, base string, creds proxyCheckCredentials, query url.Values, timeout time.Duration) ([]time.Duration, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := creds.request(ctx, base+proxyCheckPath+"/stream", query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	began := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, time.Since(began), err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Since(began), fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var arrivals []time.Duration
+	reader := bufio.NewReader(resp.Body)
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			if err == io.EOF {
+				return arrivals, time.Since(began), nil
+			}
+			return arrivals, time.Since(began), err
+		}
+		arrivals = append(arrivals, time.Since(began))
+	}
+}
+
+// clampQueryInt parses a query value, falling back to def when it is missing
+// or invalid, and clamps it to [lo, hi].
+func clampQueryInt(raw string, def, lo, hi int) int {
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return def
+	}
+	return min(max(value, lo), hi)
+}
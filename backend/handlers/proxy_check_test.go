@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newProxyCheckServer(h *ProxyCheckHandler) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(proxyCheckPath+"/stream", h.Stream)
+	mux.HandleFunc(proxyCheckPath+"/payload", h.Payload)
+	return httptest.NewServer(mux)
+}
+
+func TestProxyChecksPassWithoutProxy(t *testing.T) {
+	h := NewProxyCheckHandler(nil)
+	server := newProxyCheckServer(h)
+	defer server.Close()
+
+	results := runProxyChecks(context.Background(), server.Client(), server.URL, proxyCheckCredentials{}, time.Second)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 checks, got %+v", results)
+	}
+	for _, result := range results {
+		if !result.OK {
+			t.Errorf("check %s failed: %s", result.Name, result.Detail)
+		}
+		if result.Hint != "" {
+			t.Errorf("check %s passed but has hint %q", result.Name, result.Hint)
+		}
+	}
+}
+
+func TestProxyChecksDetectBufferingProxy(t *testing.T) {
+	h := NewProxyCheckHandler(nil)
+	upstream := newProxyCheckServer(h)
+	defer upstream.Close()
+
+	// Reads the whole upstream response before sending any of it, and drops
+	// Range headers, like a misconfigured caching proxy
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := upstream.Client().Get(upstream.URL + r.URL.RequestURI())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+	}))
+	defer proxy.Close()
+
+	results := runProxyChecks(context.Background(), proxy.Client(), proxy.URL, proxyCheckCredentials{}, 0)
+	byName := make(map[string]ProxyCheckResult, len(results))
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	if byName["chunked"].OK || byName["chunked"].Hint == "" {
+		t.Errorf("expected the chunked check to fail with a hint, got %+v", byName["chunked"])
+	}
+	if byName["range"].OK {
+		t.Errorf("expected the range check to fail, got %+v", byName["range"])
+	}
+	if !byName["longLived"].OK || byName["longLived"].Detail != "skipped" {
+		t.Errorf("expected the long-lived check to be skipped, got %+v", byName["longLived"])
+	}
+}
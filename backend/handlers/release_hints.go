@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/release_hints"
+)
+
+// maxReleaseHintQueries bounds one request; a browse screen shows a few rows
+const maxReleaseHintQueries = 500
+
+type releaseHintsService interface {
+	Check(queries []models.ReleaseHintQuery) []models.ReleaseHint
+}
+
+var _ releaseHintsService = (*release_hints.Service)(nil)
+
+// ReleaseHintsHandler answers "likely available" hints for browse screens
+type ReleaseHintsHandler struct {
+	svc releaseHintsService
+}
+
+// NewReleaseHintsHandler creates a new release hints handler
+func NewReleaseHintsHandler(svc releaseHintsService) *ReleaseHintsHandler {
+	return &ReleaseHintsHandler{svc: svc}
+}
+
+// Check handles POST /api/release-hints with {"titles": [...]} and returns a
+// hint per title, in order. Hints come from titles that recent searches found
+// releases for; no search is run.
+func (h *ReleaseHintsHandler) Check(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Titles []models.ReleaseHintQuery `json:"titles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Titles) > maxReleaseHintQueries {
+		writeJSONError(w, fmt.Sprintf("at most %d titles per request", maxReleaseHintQueries), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Check(req.Titles))
+}
+
+// Options handles CORS preflight requests
+func (h *ReleaseHintsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
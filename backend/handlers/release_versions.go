@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	release_versions "novastream/services/release_versions"
+
+	"github.com/gorilla/mux"
+)
+
+type releaseVersionsService interface {
+	List(userID, contentID string) ([]models.ReleaseVersion, error)
+	ListAll(userID string) ([]models.ReleaseVersion, error)
+	Get(userID, contentID, versionID string) (*models.ReleaseVersion, error)
+	Pin(userID, contentID string, pin models.ReleaseVersionPin) (models.ReleaseVersion, error)
+	SetDefault(userID, contentID, versionID string) error
+	Remove(userID, contentID, versionID string) error
+}
+
+var _ releaseVersionsService = (*release_versions.Service)(nil)
+
+// ReleaseVersionsHandler manages the pinned release versions of a title for a profile.
+type ReleaseVersionsHandler struct {
+	Service releaseVersionsService
+	Users   userService
+}
+
+func NewReleaseVersionsHandler(service releaseVersionsService, users userService) *ReleaseVersionsHandler {
+	return &ReleaseVersionsHandler{
+		Service: service,
+		Users:   users,
+	}
+}
+
+// ListAll returns every pinned version for a profile.
+func (h *ReleaseVersionsHandler) ListAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.Service.ListAll(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// List returns the pinned versions of a title (the version selector), default first.
+func (h *ReleaseVersionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.Service.List(userID, mux.Vars(r)["contentID"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// Pin stores a release as a version of a title.
+func (h *ReleaseVersionsHandler) Pin(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var pin models.ReleaseVersionPin
+	if err := json.NewDecoder(r.Body).Decode(&pin); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.Service.Pin(userID, mux.Vars(r)["contentID"], pin)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version)
+}
+
+// SetDefault marks a version as the one played when no version is selected.
+func (h *ReleaseVersionsHandler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.Service.SetDefault(userID, vars["contentID"], vars["versionID"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Remove unpins a version of a title.
+func (h *ReleaseVersionsHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.Service.Remove(userID, vars["contentID"], vars["versionID"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ReleaseVersionsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ReleaseVersionsHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, release_versions.ErrVersionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, release_versions.ErrContentIDRequired),
+		errors.Is(err, release_versions.ErrReleaseRequired),
+		errors.Is(err, release_versions.ErrTooManyVersions):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *ReleaseVersionsHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	vars := mux.Vars(r)
+	userID := strings.TrimSpace(vars["userID"])
+
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.Users != nil && !h.Users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
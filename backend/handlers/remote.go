@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/remote"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/net/websocket"
+)
+
+type remoteService interface {
+	CreatePairingCode(accountID, clientID, targetName string) (models.RemotePairingCode, error)
+	Pair(accountID, code, controllerID, controllerName string) (models.RemotePairing, error)
+	ListPairings(accountID string) []models.RemotePairing
+	GetPairing(accountID, pairingID string) (models.RemotePairing, error)
+	Unpair(accountID, pairingID string) error
+	SendCommand(accountID, pairingID string, cmd models.RemoteCommand) (models.RemoteCommand, error)
+	PublishStatus(accountID, clientID string, status map[string]interface{})
+	ConnectTarget(accountID, clientID string, conn remote.Conn) func()
+	ConnectController(pairingID string, conn remote.Conn) func()
+}
+
+var _ remoteService = (*remote.Service)(nil)
+
+// RemoteHandler exposes the companion remote-control API: TVs request pairing
+// codes and hold a WebSocket open for commands; phones pair with a code and send
+// commands over HTTP or their own WebSocket.
+type RemoteHandler struct {
+	svc remoteService
+}
+
+// NewRemoteHandler creates a new remote-control handler
+func NewRemoteHandler(svc remoteService) *RemoteHandler {
+	return &RemoteHandler{svc: svc}
+}
+
+// wsConn adapts a WebSocket to remote.Conn, serialising concurrent writes.
+type wsConn struct {
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+func (c *wsConn) Send(msg models.RemoteMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return websocket.JSON.Send(c.ws, msg)
+}
+
+// websocketServer builds a server that skips the Origin check; requests are
+// already authenticated by session token and native clients send no Origin.
+func websocketServer(handler websocket.Handler) websocket.Server {
+	return websocket.Server{
+		Handler:   handler,
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+	}
+}
+
+// CreatePairingCode handles POST /api/remote/pairing-codes (called by the TV)
+func (h *RemoteHandler) CreatePairingCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ClientID string `json:"clientId"`
+		Name     string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.svc.CreatePairingCode(auth.GetAccountID(r), req.ClientID, req.Name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, remote.ErrClientIDRequired) {
+			status = http.StatusBadRequest
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(code)
+}
+
+// Pair handles POST /api/remote/pair (called by the phone with the code shown on the TV)
+func (h *RemoteHandler) Pair(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code           string `json:"code"`
+		ControllerID   string `json:"controllerId"`
+		ControllerName string `json:"controllerName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	pairing, err := h.svc.Pair(auth.GetAccountID(r), req.Code, req.ControllerID, req.ControllerName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, remote.ErrInvalidCode) {
+			status = http.StatusBadRequest
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pairing)
+}
+
+// ListPairings handles GET /api/remote/pairings
+func (h *RemoteHandler) ListPairings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pairings": h.svc.ListPairings(auth.GetAccountID(r)),
+	})
+}
+
+// Unpair handles DELETE /api/remote/pairings/{pairingID}
+func (h *RemoteHandler) Unpair(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Unpair(auth.GetAccountID(r), mux.Vars(r)["pairingID"]); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, remote.ErrPairingNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendCommand handles POST /api/remote/pairings/{pairingID}/commands.
+// Returns 409 when the target device isn't connected to the relay.
+func (h *RemoteHandler) SendCommand(w http.ResponseWriter, r *http.Request) {
+	var cmd models.RemoteCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sent, err := h.svc.SendCommand(auth.GetAccountID(r), mux.Vars(r)["pairingID"], cmd)
+	if err != nil {
+		writeJSONError(w, err.Error(), remoteErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(sent)
+}
+
+// TargetSocket handles GET /api/remote/targets/{clientID}/ws. The TV keeps this
+// connection open to receive commands and sends "status" messages back, which are
+// relayed to paired controllers.
+func (h *RemoteHandler) TargetSocket(w http.ResponseWriter, r *http.Request) {
+	accountID := auth.GetAccountID(r)
+	clientID := strings.TrimSpace(mux.Vars(r)["clientID"])
+	if clientID == "" {
+		writeJSONError(w, "client id is required", http.StatusBadRequest)
+		return
+	}
+
+	server := websocketServer(func(ws *websocket.Conn) {
+		defer ws.Close()
+		disconnect := h.svc.ConnectTarget(accountID, clientID, &wsConn{ws: ws})
+		defer disconnect()
+		log.Printf("[remote] target %s connected", clientID)
+
+		for {
+			var msg models.RemoteMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				log.Printf("[remote] target %s disconnected: %v", clientID, err)
+				return
+			}
+			if msg.Type == models.RemoteMessageStatus {
+				h.svc.PublishStatus(accountID, clientID, msg.Status)
+			}
+		}
+	})
+	server.ServeHTTP(w, r)
+}
+
+// ControllerSocket handles GET /api/remote/pairings/{pairingID}/ws. The phone
+// receives target status updates and may send "command" messages instead of
+// using the HTTP endpoint.
+func (h *RemoteHandler) ControllerSocket(w http.ResponseWriter, r *http.Request) {
+	accountID := auth.GetAccountID(r)
+	pairingID := mux.Vars(r)["pairingID"]
+	if _, err := h.svc.GetPairing(accountID, pairingID); err != nil {
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	server := websocketServer(func(ws *websocket.Conn) {
+		defer ws.Close()
+		conn := &wsConn{ws: ws}
+		disconnect := h.svc.ConnectController(pairingID, conn)
+		defer disconnect()
+
+		for {
+			var msg models.RemoteMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			if msg.Type != models.RemoteMessageCommand || msg.Command == nil {
+				continue
+			}
+			if _, err := h.svc.SendCommand(accountID, pairingID, *msg.Command); err != nil {
+				_ = conn.Send(models.RemoteMessage{
+					Type:      models.RemoteMessageError,
+					PairingID: pairingID,
+					Error:     err.Error(),
+					SentAt:    time.Now().UTC(),
+				})
+			}
+		}
+	})
+	server.ServeHTTP(w, r)
+}
+
+// Options handles CORS preflight requests
+func (h *RemoteHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func remoteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, remote.ErrPairingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, remote.ErrInvalidCommand):
+		return http.StatusBadRequest
+	case errors.Is(err, remote.ErrTargetOffline):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
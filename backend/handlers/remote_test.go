@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/remote"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/net/websocket"
+)
+
+func newRemoteTestServer(t *testing.T) (*remote.Service, *httptest.Server) {
+	t.Helper()
+	svc, err := remote.NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("remote.NewService() error = %v", err)
+	}
+	h := NewRemoteHandler(svc)
+	r := mux.NewRouter()
+	r.HandleFunc("/pairings/{pairingID}/commands", h.SendCommand).Methods(http.MethodPost)
+	r.HandleFunc("/targets/{clientID}/ws", h.TargetSocket).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return svc, server
+}
+
+func TestRemoteHandler_SendCommandRelaysToConnectedTarget(t *testing.T) {
+	svc, server := newRemoteTestServer(t)
+
+	code, _ := svc.CreatePairingCode("", "tv-1", "Living Room")
+	pairing, err := svc.Pair("", code.Code, "phone-1", "Phone")
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+
+	body := `{"type":"play","titleId":"tmdb:movie:603","userId":"user-1"}`
+	resp, err := http.Post(server.URL+"/pairings/"+pairing.ID+"/commands", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST command error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 while target offline, got %d", resp.StatusCode)
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/targets/tv-1/ws"
+	ws, err := websocket.Dial(wsURL, "", server.URL)
+	if err != nil {
+		t.Fatalf("websocket dial error = %v", err)
+	}
+	defer ws.Close()
+
+	// Wait for the target connection to be registered
+	deadline := time.Now().Add(2 * time.Second)
+	for len(svc.ListPairings("")) == 0 || !svc.ListPairings("")[0].Online {
+		if time.Now().After(deadline) {
+			t.Fatal("target never came online")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, err = http.Post(server.URL+"/pairings/"+pairing.ID+"/commands", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST command error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg models.RemoteMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("receive error = %v", err)
+	}
+	if msg.Type != models.RemoteMessageCommand || msg.Command == nil || msg.Command.TitleID != "tmdb:movie:603" {
+		t.Fatalf("unexpected relayed message: %+v", msg)
+	}
+}
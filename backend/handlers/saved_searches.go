@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/saved_searches"
+
+	"github.com/gorilla/mux"
+)
+
+type savedSearchService interface {
+	Create(userID string, search models.SavedSearch) (models.SavedSearch, error)
+	ListForUser(userID string) []models.SavedSearch
+	Delete(userID, id string) error
+	Acknowledge(userID, id string) (models.SavedSearch, error)
+}
+
+var _ savedSearchService = (*saved_searches.Service)(nil)
+
+// SavedSearchesHandler manages a profile's saved release searches, which are
+// re-run in the background and collect newly appeared matching releases
+type SavedSearchesHandler struct {
+	svc   savedSearchService
+	users userService
+}
+
+// NewSavedSearchesHandler creates a new saved searches handler
+func NewSavedSearchesHandler(svc savedSearchService, users userService) *SavedSearchesHandler {
+	return &SavedSearchesHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/saved-searches
+func (h *SavedSearchesHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.ListForUser(userID))
+}
+
+// Create handles POST /api/users/{userID}/saved-searches
+// Body: {"name", "query", "mediaType", "imdbId", "year", "filters": {...}}
+func (h *SavedSearchesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	search, err := h.svc.Create(userID, req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(search)
+}
+
+// Acknowledge handles POST /api/users/{userID}/saved-searches/{searchID}/acknowledge
+// Clears the new releases once the profile has seen them.
+func (h *SavedSearchesHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	search, err := h.svc.Acknowledge(userID, mux.Vars(r)["searchID"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// Delete handles DELETE /api/users/{userID}/saved-searches/{searchID}
+func (h *SavedSearchesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(userID, mux.Vars(r)["searchID"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *SavedSearchesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SavedSearchesHandler) writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, saved_searches.ErrSearchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, saved_searches.ErrQueryRequired),
+		errors.Is(err, saved_searches.ErrInvalidFilters),
+		errors.Is(err, saved_searches.ErrTooManySearches):
+		status = http.StatusBadRequest
+	}
+	writeJSONError(w, err.Error(), status)
+}
+
+func (h *SavedSearchesHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
@@ -413,3 +413,14 @@ func (h *ScheduledTasksHandler) ToggleTask(w http.ResponseWriter, r *http.Reques
 		"enabled": req.Enabled,
 	})
 }
+
+// ListCustomListStatus returns the background refresh status of each custom MDBList shelf
+// GET /admin/api/custom-lists/status
+func (h *ScheduledTasksHandler) ListCustomListStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := h.schedulerService.GetCustomListStatus()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lists": statuses,
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/scrobble_outbox"
+
+	"github.com/gorilla/mux"
+)
+
+type scrobbleOutboxService interface {
+	List() []models.ScrobbleOutboxEntry
+	Flush() models.ScrobbleOutboxFlushResult
+	Discard(id string) error
+}
+
+var _ scrobbleOutboxService = (*scrobble_outbox.Service)(nil)
+
+// ScrobbleOutboxHandler lets admins inspect and flush Trakt scrobbles waiting to be retried
+type ScrobbleOutboxHandler struct {
+	svc scrobbleOutboxService
+}
+
+// NewScrobbleOutboxHandler creates a new scrobble outbox handler
+func NewScrobbleOutboxHandler(svc scrobbleOutboxService) *ScrobbleOutboxHandler {
+	return &ScrobbleOutboxHandler{svc: svc}
+}
+
+// List handles GET /api/admin/scrobble-outbox
+func (h *ScrobbleOutboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List())
+}
+
+// Flush handles POST /api/admin/scrobble-outbox/flush
+// Retries every queued scrobble immediately, ignoring backoff.
+func (h *ScrobbleOutboxHandler) Flush(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Flush())
+}
+
+// Discard handles DELETE /api/admin/scrobble-outbox/{entryID}
+func (h *ScrobbleOutboxHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Discard(mux.Vars(r)["entryID"]); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, scrobble_outbox.ErrEntryNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *ScrobbleOutboxHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/playback"
+	"novastream/services/search_retries"
+
+	"github.com/gorilla/mux"
+)
+
+// searchRetryErrorCode marks a prequeue that found no releases for a
+// just-aired episode and is being retried in the background.
+const searchRetryErrorCode = "no_results_retrying"
+
+type searchRetryService interface {
+	Schedule(retry models.SearchRetry) (models.SearchRetry, bool)
+	ListForUser(userID string) []models.SearchRetry
+	Dismiss(userID, id string) error
+}
+
+var _ searchRetryService = (*search_retries.Service)(nil)
+
+// SetSearchRetries enables background retries of episode searches that found
+// nothing shortly after the episode aired
+func (h *PrequeueHandler) SetSearchRetries(svc searchRetryService) {
+	h.searchRetries = svc
+}
+
+// scheduleSearchRetry queues a retry for an episode whose search found
+// nothing and marks the prequeue so clients can tell the user. It is a no-op
+// for movies, unknown air dates and episodes that aired too long ago.
+func (h *PrequeueHandler) scheduleSearchRetry(prequeueID, titleID, titleName, imdbID string, year int, userID, clientID, query string, episode *models.EpisodeReference, airDate string, isDaily, isAnime bool, runtimeMinutes int) {
+	if h.searchRetries == nil || episode == nil {
+		return
+	}
+	if airDate == "" {
+		airDate = episode.AirDate
+	}
+
+	retry, ok := h.searchRetries.Schedule(models.SearchRetry{
+		UserID:                userID,
+		ClientID:              clientID,
+		TitleID:               titleID,
+		TitleName:             titleName,
+		IMDBID:                imdbID,
+		Year:                  year,
+		SeasonNumber:          episode.SeasonNumber,
+		EpisodeNumber:         episode.EpisodeNumber,
+		AbsoluteEpisodeNumber: episode.AbsoluteEpisodeNumber,
+		AirDate:               airDate,
+		IsDaily:               isDaily,
+		IsAnime:               isAnime,
+		RuntimeMinutes:        runtimeMinutes,
+		Query:                 query,
+	})
+	if !ok {
+		return
+	}
+	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
+		e.ErrorCode = searchRetryErrorCode
+		e.Error = "no releases yet; searching again in the background until " + retry.ExpiresAt.Format("2006-01-02 15:04 MST")
+	})
+}
+
+// SearchRetriesHandler lists a profile's background episode search retries
+// and the releases they found
+type SearchRetriesHandler struct {
+	svc   searchRetryService
+	users userService
+}
+
+// NewSearchRetriesHandler creates a new search retries handler
+func NewSearchRetriesHandler(svc searchRetryService, users userService) *SearchRetriesHandler {
+	return &SearchRetriesHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/search-retries
+func (h *SearchRetriesHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.ListForUser(userID))
+}
+
+// Dismiss handles DELETE /api/users/{userID}/search-retries/{retryID}
+func (h *SearchRetriesHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Dismiss(userID, mux.Vars(r)["retryID"]); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, search_retries.ErrRetryNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *SearchRetriesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SearchRetriesHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/selection_stats"
+)
+
+// SelectionRecorder records the outcome of automatic release selection
+type SelectionRecorder interface {
+	RecordSelection(outcome models.SelectionOutcome)
+}
+
+type selectionStatsService interface {
+	SelectionRecorder
+	Summary(limit int) *models.SelectionStatsSummary
+	Reset() error
+}
+
+var _ selectionStatsService = (*selection_stats.Service)(nil)
+
+// SelectionStatsHandler exposes release selection outcomes per strategy to admins
+type SelectionStatsHandler struct {
+	svc selectionStatsService
+}
+
+// NewSelectionStatsHandler creates a new selection stats handler
+func NewSelectionStatsHandler(svc selectionStatsService) *SelectionStatsHandler {
+	return &SelectionStatsHandler{svc: svc}
+}
+
+// Summary returns selection totals, failure and first-pick rates per strategy
+// along with the most recent outcomes. Query params: limit (recent outcomes).
+func (h *SelectionStatsHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Summary(limit))
+}
+
+// Reset clears all recorded selection outcomes
+func (h *SelectionStatsHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Reset(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *SelectionStatsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -9,10 +10,27 @@ import (
 
 	"novastream/config"
 	"novastream/internal/pool"
+	"novastream/models"
 	"novastream/services/debrid"
 	"novastream/services/metadata"
+	"novastream/services/streaming"
+	user_settings "novastream/services/user_settings"
+	"novastream/utils"
+	"novastream/utils/diskspace"
+	"novastream/utils/sandbox"
+
+	"github.com/gorilla/mux"
 )
 
+// profileShelfSource lists the profiles with their own settings, whose home
+// shelves can add custom lists on top of the global ones.
+type profileShelfSource interface {
+	GetUsersWithOverrides() map[string]bool
+	Get(userID string) (*models.UserSettings, error)
+}
+
+var _ profileShelfSource = (*user_settings.Service)(nil)
+
 type SettingsHandler struct {
 	Manager             *config.Manager
 	DemoMode            bool
@@ -20,6 +38,7 @@ type SettingsHandler struct {
 	MetadataService     *metadata.Service
 	DebridSearchService *debrid.SearchService
 	ImageHandler        *ImageHandler
+	UserSettings        profileShelfSource
 }
 
 func NewSettingsHandler(m *config.Manager) *SettingsHandler {
@@ -35,6 +54,18 @@ func (h *SettingsHandler) SetPoolManager(pm pool.Manager) {
 	h.PoolManager = pm
 }
 
+// SetUserSettingsService sets the per-profile settings consulted for custom list shelves
+func (h *SettingsHandler) SetUserSettingsService(us profileShelfSource) {
+	h.UserSettings = us
+}
+
+// SyncCustomListTask creates or removes the auto-created custom list refresh
+// task to match the global and profile shelves. Called at startup so shelves
+// that already exist get refreshed without a settings save.
+func (h *SettingsHandler) SyncCustomListTask() error {
+	return syncCustomListTask(h.Manager, h.UserSettings)
+}
+
 // SetMetadataService sets the metadata service for hot reloading API keys
 func (h *SettingsHandler) SetMetadataService(ms *metadata.Service) {
 	h.MetadataService = ms
@@ -105,6 +136,8 @@ func (h *SettingsHandler) PutSettings(w http.ResponseWriter, r *http.Request) {
 	// Auto-create/remove scheduled tasks based on feature settings
 	h.ensureEPGTaskIfEnabled(&s)
 	h.ensurePlaylistTaskIfConfigured(&s)
+	h.ensureCustomListTaskIfConfigured(&s)
+	h.ensureArtworkWarmTask(&s)
 
 	if err := h.Manager.Save(s); err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -121,12 +154,42 @@ func (h *SettingsHandler) PutSettings(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(s)
 }
 
+// ListConfigBackups returns the shadow copies of the settings file, newest first.
+func (h *SettingsHandler) ListConfigBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.Manager.ListBackups()
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"backups": backups})
+}
+
+// RestoreConfigBackup replaces the settings with a shadow copy and applies it.
+func (h *SettingsHandler) RestoreConfigBackup(w http.ResponseWriter, r *http.Request) {
+	s, err := h.Manager.RestoreBackup(mux.Vars(r)["backupID"])
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrBackupNotFound) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.reloadServices(s)
+	json.NewEncoder(w).Encode(s)
+}
+
 // reloadServices reloads services that cache configuration at startup
 func (h *SettingsHandler) reloadServices(s config.Settings) {
 	// Reload NNTP connection pool with new usenet providers
 	if h.PoolManager != nil {
 		providers := config.ToNNTPProviders(s.Usenet)
-		if err := h.PoolManager.SetProviders(providers); err != nil {
+		if err := h.PoolManager.SetProviders(providers, config.NNTPBindings(s.Usenet)); err != nil {
 			log.Printf("[settings] failed to reload usenet pool: %v", err)
 		} else {
 			log.Printf("[settings] reloaded usenet pool with %d provider(s)", len(providers))
@@ -138,11 +201,12 @@ func (h *SettingsHandler) reloadServices(s config.Settings) {
 		h.MetadataService.UpdateAPIKeys(s.Metadata.TVDBAPIKey, s.Metadata.TMDBAPIKey, s.Metadata.Language)
 		log.Printf("[settings] reloaded metadata service API keys")
 
-		// Reload MDBList settings (rating sources, API key, enabled state)
+		// Reload MDBList settings (rating sources, API key, enabled state, composite weights)
 		h.MetadataService.UpdateMDBListSettings(metadata.MDBListConfig{
-			APIKey:         s.MDBList.APIKey,
-			Enabled:        s.MDBList.Enabled,
-			EnabledRatings: s.MDBList.EnabledRatings,
+			APIKey:           s.MDBList.APIKey,
+			Enabled:          s.MDBList.Enabled,
+			EnabledRatings:   s.MDBList.EnabledRatings,
+			CompositeWeights: s.MDBList.CompositeWeights,
 		})
 		log.Printf("[settings] reloaded MDBList settings (enabled=%v, ratings=%v)", s.MDBList.Enabled, s.MDBList.EnabledRatings)
 	}
@@ -151,6 +215,15 @@ func (h *SettingsHandler) reloadServices(s config.Settings) {
 	if h.DebridSearchService != nil {
 		h.DebridSearchService.ReloadScrapers()
 	}
+
+	// Apply the new CORS policy to subsequent requests
+	utils.SetCORSSettings(s.CORS)
+	utils.SetAccessLogSettings(s.AccessLog)
+	utils.SetBaseURL(s.Server.BaseURL)
+	utils.SetTrustedProxies(s.Server.TrustedProxies)
+	sandbox.SetSettings(s.Sandbox)
+	diskspace.SetSettings(s.DiskSpace)
+	streaming.SetNetworkSimulation(s.Developer.NetworkSimulation)
 }
 
 // ClearMetadataCache clears all cached metadata files and images
@@ -273,3 +346,116 @@ func (h *SettingsHandler) ensurePlaylistTaskIfConfigured(s *config.Settings) {
 	s.ScheduledTasks.Tasks = append(s.ScheduledTasks.Tasks, playlistTask)
 	log.Printf("[settings] auto-created playlist refresh task because Live TV is configured")
 }
+
+// ensureArtworkWarmTask auto-creates the task that caches continue-watching and
+// watchlist artwork unless one already exists. It can be disabled but not removed.
+func (h *SettingsHandler) ensureArtworkWarmTask(s *config.Settings) {
+	for _, task := range s.ScheduledTasks.Tasks {
+		if task.Type == config.ScheduledTaskTypeArtworkWarm {
+			return // Task already exists
+		}
+	}
+
+	artworkTask := config.ScheduledTask{
+		ID:         "auto-artwork-warm",
+		Type:       config.ScheduledTaskTypeArtworkWarm,
+		Name:       "Artwork Cache Warmer",
+		Enabled:    true,
+		Frequency:  config.ScheduledTaskFrequency6Hours,
+		Config:     map[string]string{},
+		LastStatus: config.ScheduledTaskStatusPending,
+		CreatedAt:  time.Now(),
+	}
+	s.ScheduledTasks.Tasks = append(s.ScheduledTasks.Tasks, artworkTask)
+	log.Printf("[settings] auto-created artwork cache warming task")
+}
+
+// ensureCustomListTaskIfConfigured auto-creates a custom list refresh task when any global or
+// profile home shelf uses an MDBList URL, so those lists are pre-enriched in the background
+// instead of on first load. Removes the auto-created task once no custom shelves remain.
+func (h *SettingsHandler) ensureCustomListTaskIfConfigured(s *config.Settings) {
+	ensureCustomListTask(s, h.UserSettings)
+}
+
+// syncCustomListTask applies ensureCustomListTask to the saved settings,
+// saving them only when the task list changed.
+func syncCustomListTask(manager *config.Manager, profiles profileShelfSource) error {
+	if manager == nil {
+		return nil
+	}
+	s, err := manager.Load()
+	if err != nil {
+		return err
+	}
+	if !ensureCustomListTask(&s, profiles) {
+		return nil
+	}
+	return manager.Save(s)
+}
+
+// hasCustomListShelf reports whether the global shelves or any profile's
+// shelves include an MDBList custom list.
+func hasCustomListShelf(s *config.Settings, profiles profileShelfSource) bool {
+	for _, shelf := range s.HomeShelves.Shelves {
+		if shelf.Type == "mdblist" && strings.TrimSpace(shelf.ListURL) != "" {
+			return true
+		}
+	}
+	if profiles == nil {
+		return false
+	}
+	for userID := range profiles.GetUsersWithOverrides() {
+		userSettings, err := profiles.Get(userID)
+		if err != nil || userSettings == nil {
+			continue
+		}
+		for _, shelf := range userSettings.HomeShelves.Shelves {
+			if shelf.Type == "mdblist" && strings.TrimSpace(shelf.ListURL) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ensureCustomListTask adds or removes the auto-created custom list refresh
+// task and reports whether it changed the task list.
+func ensureCustomListTask(s *config.Settings, profiles profileShelfSource) bool {
+	if !hasCustomListShelf(s, profiles) {
+		// No custom lists - remove any auto-created custom list refresh tasks
+		removed := false
+		filtered := s.ScheduledTasks.Tasks[:0]
+		for _, task := range s.ScheduledTasks.Tasks {
+			if task.Type == config.ScheduledTaskTypeCustomListRefresh && strings.Contains(task.ID, "auto") {
+				log.Printf("[settings] removing auto-created custom list refresh task (id=%s) because no custom lists are configured", task.ID)
+				removed = true
+				continue
+			}
+			filtered = append(filtered, task)
+		}
+		s.ScheduledTasks.Tasks = filtered
+		return removed
+	}
+
+	// Check if a custom list refresh task already exists
+	for _, task := range s.ScheduledTasks.Tasks {
+		if task.Type == config.ScheduledTaskTypeCustomListRefresh {
+			return false // Task already exists
+		}
+	}
+
+	// Create default custom list refresh task
+	customListTask := config.ScheduledTask{
+		ID:         "auto-custom-list-refresh",
+		Type:       config.ScheduledTaskTypeCustomListRefresh,
+		Name:       "Custom List Refresh",
+		Enabled:    true,
+		Frequency:  config.ScheduledTaskFrequency6Hours,
+		Config:     map[string]string{},
+		LastStatus: config.ScheduledTaskStatusPending,
+		CreatedAt:  time.Now(),
+	}
+	s.ScheduledTasks.Tasks = append(s.ScheduledTasks.Tasks, customListTask)
+	log.Printf("[settings] auto-created custom list refresh task because custom lists are configured")
+	return true
+}
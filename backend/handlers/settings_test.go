@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"novastream/config"
+	"novastream/models"
 )
 
 func TestSettingsHandler_GetSettings(t *testing.T) {
@@ -96,3 +97,69 @@ func TestSettingsHandler_PutSettings(t *testing.T) {
 		t.Fatalf("settings not persisted: %+v", saved)
 	}
 }
+
+func TestSettingsHandler_EnsureCustomListTask(t *testing.T) {
+	handler := NewSettingsHandler(config.NewManager(filepath.Join(t.TempDir(), "settings.json")))
+
+	s := config.Settings{}
+	s.HomeShelves.Shelves = []config.ShelfConfig{
+		{ID: "custom-1", Name: "Custom", Enabled: true, Type: "mdblist", ListURL: "https://mdblist.com/lists/user/list/json"},
+	}
+	handler.ensureCustomListTaskIfConfigured(&s)
+	handler.ensureCustomListTaskIfConfigured(&s)
+
+	if len(s.ScheduledTasks.Tasks) != 1 || s.ScheduledTasks.Tasks[0].Type != config.ScheduledTaskTypeCustomListRefresh {
+		t.Fatalf("expected a single custom list refresh task, got %+v", s.ScheduledTasks.Tasks)
+	}
+
+	s.HomeShelves.Shelves = nil
+	handler.ensureCustomListTaskIfConfigured(&s)
+	if len(s.ScheduledTasks.Tasks) != 0 {
+		t.Fatalf("expected auto-created task to be removed, got %+v", s.ScheduledTasks.Tasks)
+	}
+}
+
+type fakeProfileShelves map[string]*models.UserSettings
+
+func (f fakeProfileShelves) GetUsersWithOverrides() map[string]bool {
+	users := make(map[string]bool, len(f))
+	for userID := range f {
+		users[userID] = true
+	}
+	return users
+}
+
+func (f fakeProfileShelves) Get(userID string) (*models.UserSettings, error) {
+	return f[userID], nil
+}
+
+func TestSettingsHandler_SyncCustomListTaskFromProfileShelf(t *testing.T) {
+	manager := config.NewManager(filepath.Join(t.TempDir(), "settings.json"))
+	if err := manager.Save(config.Settings{}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	profiles := fakeProfileShelves{"profile-1": &models.UserSettings{}}
+	profiles["profile-1"].HomeShelves.Shelves = []models.ShelfConfig{
+		{ID: "custom-1", Name: "Custom", Enabled: true, Type: "mdblist", ListURL: "https://mdblist.com/lists/user/list/json"},
+	}
+
+	handler := NewSettingsHandler(manager)
+	handler.SetUserSettingsService(profiles)
+	if err := handler.SyncCustomListTask(); err != nil {
+		t.Fatalf("SyncCustomListTask() error = %v", err)
+	}
+
+	saved, err := manager.Load()
+	if err != nil {
+		t.Fatalf("load settings: %v", err)
+	}
+	if len(saved.ScheduledTasks.Tasks) != 1 || saved.ScheduledTasks.Tasks[0].Type != config.ScheduledTaskTypeCustomListRefresh {
+		t.Fatalf("expected a custom list refresh task for the profile shelf, got %+v", saved.ScheduledTasks.Tasks)
+	}
+
+	// Saving global settings without custom shelves keeps the profile's task
+	handler.ensureCustomListTaskIfConfigured(&saved)
+	if len(saved.ScheduledTasks.Tasks) != 1 {
+		t.Fatalf("expected the task to survive a global save, got %+v", saved.ScheduledTasks.Tasks)
+	}
+}
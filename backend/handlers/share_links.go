@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	"novastream/services/share_links"
+	"novastream/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type shareLinkService interface {
+	Create(userID, accountID, streamPath, title string, ttl time.Duration, maxViews int) (models.ShareLink, error)
+	ListForUser(userID string) []models.ShareLink
+	Revoke(userID, id string) error
+	Admit(token, viewerKey string) (models.ShareLink, error)
+}
+
+var _ shareLinkService = (*share_links.Service)(nil)
+
+// shareStreamer serves a stream by path. Implemented by *VideoHandler.
+type shareStreamer interface {
+	StreamVideo(w http.ResponseWriter, r *http.Request)
+}
+
+var _ shareStreamer = (*VideoHandler)(nil)
+
+// shareLinkPathPrefix is where shared streams are served without a session
+const shareLinkPathPrefix = "/api/share/"
+
+// shareLinkResponse adds the public URL path to a share link
+type shareLinkResponse struct {
+	models.ShareLink
+	URL string `json:"url"`
+}
+
+// ShareLinksHandler lets profiles create expiring watch links to a resolved
+// stream and serves those streams to anyone holding a valid link
+type ShareLinksHandler struct {
+	svc      shareLinkService
+	users    userService
+	streamer shareStreamer
+}
+
+// NewShareLinksHandler creates a new share links handler
+func NewShareLinksHandler(svc shareLinkService, users userService) *ShareLinksHandler {
+	return &ShareLinksHandler{svc: svc, users: users}
+}
+
+// SetStreamer sets what serves shared streams; without one, shared links
+// can be managed but not watched
+func (h *ShareLinksHandler) SetStreamer(streamer shareStreamer) {
+	h.streamer = streamer
+}
+
+// List handles GET /api/users/{userID}/share-links
+func (h *ShareLinksHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	links := h.svc.ListForUser(userID)
+	resp := make([]shareLinkResponse, 0, len(links))
+	for _, link := range links {
+		resp = append(resp, newShareLinkResponse(link))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Create handles POST /api/users/{userID}/share-links
+// Body: {"path": "/webdav/...", "title": "...", "expiresInHours": 24, "maxViews": 1}
+func (h *ShareLinksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Path           string  `json:"path"`
+		Title          string  `json:"title"`
+		ExpiresInHours float64 `json:"expiresInHours"`
+		MaxViews       int     `json:"maxViews"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInHours * float64(time.Hour))
+	link, err := h.svc.Create(userID, auth.GetAccountID(r), req.Path, req.Title, ttl, req.MaxViews)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, share_links.ErrPathRequired) || errors.Is(err, share_links.ErrTooManyLinks) {
+			status = http.StatusBadRequest
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newShareLinkResponse(link))
+}
+
+// Revoke handles DELETE /api/users/{userID}/share-links/{linkID}
+func (h *ShareLinksHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Revoke(userID, mux.Vars(r)["linkID"]); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, share_links.ErrLinkNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stream handles GET/HEAD /api/share/{token}
+// No session is required: the token admits the viewer to the one stream the
+// link was created for, and nothing the viewer sends can change that path.
+func (h *ShareLinksHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	link, err := h.svc.Admit(mux.Vars(r)["token"], utils.ClientIP(r))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, share_links.ErrLinkNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, share_links.ErrLinkExpired):
+			status = http.StatusGone
+		case errors.Is(err, share_links.ErrViewLimitReached):
+			status = http.StatusForbidden
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	if h.streamer == nil {
+		writeJSONError(w, "streaming unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	shared := r.Clone(r.Context())
+	shared.URL.RawQuery = url.Values{"path": {link.Path}}.Encode()
+	h.streamer.StreamVideo(w, shared)
+}
+
+// Options handles CORS preflight requests
+func (h *ShareLinksHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ShareLinksHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func newShareLinkResponse(link models.ShareLink) shareLinkResponse {
+	return shareLinkResponse{ShareLink: link, URL: shareLinkPathPrefix + link.Token}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/models"
+	"novastream/services/spoiler_holds"
+
+	"github.com/gorilla/mux"
+)
+
+type spoilerHoldsService interface {
+	List(profileID string) []models.SpoilerHold
+	Create(profileID string, req models.SpoilerHoldRequest) (*models.SpoilerHold, error)
+	Delete(profileID, holdID string) error
+}
+
+var _ spoilerHoldsService = (*spoiler_holds.Service)(nil)
+
+// spoilerAnnotator flags episodes other household profiles are holding.
+type spoilerAnnotator interface {
+	Annotate(viewerID string, details *models.SeriesDetails) *models.SeriesDetails
+}
+
+var _ spoilerAnnotator = (*spoiler_holds.Service)(nil)
+
+// SpoilerHoldsHandler manages a profile's "don't spoil" holds
+type SpoilerHoldsHandler struct {
+	svc   spoilerHoldsService
+	users userService
+}
+
+// NewSpoilerHoldsHandler creates a new spoiler holds handler
+func NewSpoilerHoldsHandler(svc spoilerHoldsService, users userService) *SpoilerHoldsHandler {
+	return &SpoilerHoldsHandler{svc: svc, users: users}
+}
+
+// List handles GET /api/users/{userID}/spoiler-holds
+func (h *SpoilerHoldsHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.List(userID))
+}
+
+// Create handles POST /api/users/{userID}/spoiler-holds
+func (h *SpoilerHoldsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SpoilerHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hold, err := h.svc.Create(userID, req)
+	if err != nil {
+		writeJSONError(w, err.Error(), spoilerHoldErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+// Delete handles DELETE /api/users/{userID}/spoiler-holds/{holdID}
+func (h *SpoilerHoldsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(userID, mux.Vars(r)["holdID"]); err != nil {
+		writeJSONError(w, err.Error(), spoilerHoldErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *SpoilerHoldsHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SpoilerHoldsHandler) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func spoilerHoldErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, spoiler_holds.ErrHoldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, spoiler_holds.ErrTooManyHolds):
+		return http.StatusConflict
+	case errors.Is(err, spoiler_holds.ErrProfileIDRequired),
+		errors.Is(err, spoiler_holds.ErrSeriesIDRequired),
+		errors.Is(err, spoiler_holds.ErrInvalidRange):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
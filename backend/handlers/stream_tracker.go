@@ -1,12 +1,13 @@
 package handlers
 
 import (
-	"net"
 	"net/http"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"novastream/utils"
 )
 
 // StreamTracker tracks active video streams for monitoring
@@ -54,7 +55,7 @@ func (t *StreamTracker) StartStream(r *http.Request, path string, contentLength
 	id := generateStreamID(atomic.AddUint64(&t.counter, 1))
 
 	// Get client IP
-	clientIP := getClientIP(r)
+	clientIP := utils.ClientIP(r)
 
 	// Extract filename
 	filename := filepath.Base(path)
@@ -153,33 +154,6 @@ func generateStreamID(counter uint64) string {
 	return time.Now().Format("20060102150405") + "-" + string(rune('A'+counter%26)) + string(rune('0'+counter%10))
 }
 
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first (for proxied requests)
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take the first IP in the chain
-		if idx := len(xff); idx > 0 {
-			for i, c := range xff {
-				if c == ',' {
-					return xff[:i]
-				}
-			}
-			return xff
-		}
-	}
-
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-
-	// Fall back to RemoteAddr
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return host
-}
-
 // TrackingResponseWriter wraps http.ResponseWriter to track bytes written
 type TrackingResponseWriter struct {
 	http.ResponseWriter
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/supervisor"
+)
+
+type subsystemStatus interface {
+	Status() []models.SubsystemStatus
+}
+
+var _ subsystemStatus = (*supervisor.Supervisor)(nil)
+
+// SetSubsystemStatus sets the supervisor of the optional subsystems started at boot
+func (h *AdminUIHandler) SetSubsystemStatus(ss subsystemStatus) {
+	h.subsystems = ss
+}
+
+// GetSubsystems returns the optional subsystems (usenet pool, WebDAV) and
+// whether each is up or still being retried after failing at startup
+// GET /admin/api/subsystems
+func (h *AdminUIHandler) GetSubsystems(w http.ResponseWriter, r *http.Request) {
+	if h.subsystems == nil {
+		http.Error(w, "Subsystem status not available", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subsystems": h.subsystems.Status(),
+	})
+}
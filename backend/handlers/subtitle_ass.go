@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/utils/subtitles"
+)
+
+// assFollowInterval is how often a growing ASS file is polled for new lines.
+const assFollowInterval = 250 * time.Millisecond
+
+// isASSCodec reports whether a subtitle codec carries ASS/SSA styling.
+func isASSCodec(codec string) bool {
+	codec = strings.ToLower(codec)
+	return codec == "ass" || codec == "ssa"
+}
+
+// subtitleOutputArgs returns the ffmpeg output arguments for one subtitle
+// track. ASS/SSA tracks are copied as-is to assPath so their styling can be
+// converted to WebVTT cue settings; other text codecs are encoded to WebVTT
+// by ffmpeg directly.
+func subtitleOutputArgs(codec, vttPath, assPath string) []string {
+	if assPath != "" && isASSCodec(codec) {
+		return []string{"-c", "copy", "-f", "ass", "-flush_packets", "1", assPath}
+	}
+	return []string{"-c", "webvtt", "-f", "webvtt", "-flush_packets", "1", vttPath}
+}
+
+// assPathFor is the raw ASS file extracted alongside a VTT file.
+func assPathFor(vttPath string) string {
+	return strings.TrimSuffix(vttPath, ".vtt") + ".ass"
+}
+
+// styledASSConversion converts an ASS file to styled WebVTT while ffmpeg is
+// still writing it, so the VTT grows cue by cue like a direct extraction.
+type styledASSConversion struct {
+	done     chan struct{}
+	finished chan struct{}
+	once     sync.Once
+}
+
+// startStyledASSConversion follows assPath and writes WebVTT to vttPath until
+// finish is called and the file is drained, or ctx ends.
+func startStyledASSConversion(ctx context.Context, assPath, vttPath string) *styledASSConversion {
+	conv := &styledASSConversion{
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go func() {
+		defer close(conv.finished)
+		if err := followASS(ctx, assPath, vttPath, conv.done); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("[subtitle-extract] styled ASS conversion of %s failed: %v", assPath, err)
+		}
+	}()
+	return conv
+}
+
+// finish signals that ffmpeg has exited and waits for the remaining lines to
+// be converted. It is safe to call more than once.
+func (c *styledASSConversion) finish() {
+	c.once.Do(func() { close(c.done) })
+	<-c.finished
+}
+
+func followASS(ctx context.Context, assPath, vttPath string, done <-chan struct{}) error {
+	// wait reports whether to keep polling: false once ffmpeg has exited,
+	// after which one more read drains what it wrote last
+	exited := false
+	wait := func() (bool, error) {
+		if exited {
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-done:
+			exited = true
+		case <-time.After(assFollowInterval):
+		}
+		return true, nil
+	}
+
+	var file *os.File
+	for file == nil {
+		var err error
+		file, err = os.Open(assPath)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if more, err := wait(); err != nil || !more {
+			return err
+		}
+	}
+	defer file.Close()
+
+	out := &lazyFile{path: vttPath}
+	defer out.Close()
+	converter := subtitles.NewASSConverter(out)
+
+	reader := bufio.NewReader(file)
+	var partial strings.Builder
+	for {
+		chunk, err := reader.ReadString('\n')
+		partial.WriteString(chunk)
+		if err == nil {
+			if convErr := converter.WriteLine(partial.String()); convErr != nil {
+				return convErr
+			}
+			partial.Reset()
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		more, waitErr := wait()
+		if waitErr != nil {
+			return waitErr
+		}
+		if !more {
+			break
+		}
+	}
+
+	if partial.Len() > 0 {
+		if err := converter.WriteLine(partial.String()); err != nil {
+			return err
+		}
+	}
+	return converter.Close()
+}
+
+// lazyFile creates its file on first write, so a pre-created placeholder VTT
+// keeps being served until the converter has a header to replace it with.
+type lazyFile struct {
+	path string
+	file *os.File
+}
+
+func (f *lazyFile) Write(p []byte) (int, error) {
+	if f.file == nil {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	return f.file.Write(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
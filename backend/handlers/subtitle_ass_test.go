@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStyledASSConversionFollowsGrowingFile(t *testing.T) {
+	dir := t.TempDir()
+	vttPath := filepath.Join(dir, "subtitles_0.vtt")
+	assPath := assPathFor(vttPath)
+	if err := os.WriteFile(vttPath, []byte("WEBVTT\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := startStyledASSConversion(context.Background(), assPath, vttPath)
+
+	// ffmpeg writes the header first, then dialogue as packets arrive,
+	// possibly ending mid-line
+	file, err := os.Create(assPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("[Script Info]\nPlayResX: 1920\nPlayResY: 1080\n\n[Events]\n" +
+		"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\i1}First{\\i0}\n")
+	time.Sleep(3 * assFollowInterval)
+
+	content, _ := os.ReadFile(vttPath)
+	if !strings.Contains(string(content), "00:00:01.000 --> 00:00:02.000\n<i>First</i>") {
+		t.Fatalf("first cue not converted while extracting:\n%s", content)
+	}
+
+	file.WriteString("Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,{\\an8}Second")
+	file.Close()
+	conv.finish()
+
+	content, _ = os.ReadFile(vttPath)
+	if !strings.Contains(string(content), "00:00:03.000 --> 00:00:04.000 line:0\nSecond") {
+		t.Errorf("final unterminated line not converted:\n%s", content)
+	}
+}
+
+func TestSubtitleOutputArgs(t *testing.T) {
+	args := subtitleOutputArgs("ass", "/tmp/s.vtt", "/tmp/s.ass")
+	if got := strings.Join(args, " "); got != "-c copy -f ass -flush_packets 1 /tmp/s.ass" {
+		t.Errorf("ass output args = %q", got)
+	}
+	args = subtitleOutputArgs("subrip", "/tmp/s.vtt", "")
+	if got := strings.Join(args, " "); got != "-c webvtt -f webvtt -flush_packets 1 /tmp/s.vtt" {
+		t.Errorf("subrip output args = %q", got)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -17,6 +16,7 @@ import (
 	"time"
 
 	"novastream/services/streaming"
+	"novastream/utils/sandbox"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -32,7 +32,7 @@ type SubtitleExtractSession struct {
 	VTTPath        string
 	CreatedAt      time.Time
 	LastAccess     time.Time
-	cmd            *exec.Cmd
+	cmd            *sandbox.Cmd
 	cancel         context.CancelFunc
 	mu             sync.Mutex
 	extractionDone bool
@@ -55,6 +55,9 @@ type SubtitleExtractManager struct {
 	webdavMu     sync.RWMutex
 	webdavBase   string
 	webdavPrefix string
+
+	// Optional per-release subtitle delay corrections applied when serving
+	subtitleOffsets SubtitleOffsetStore
 }
 
 // NewSubtitleExtractManager creates a new subtitle extraction manager
@@ -400,7 +403,7 @@ func (m *SubtitleExtractManager) probeSubtitleStreams(ctx context.Context, strea
 		"-i", streamURL,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("[subtitle-extract] ffprobe failed: %v", err)
@@ -476,7 +479,7 @@ func (m *SubtitleExtractManager) probeSubtitleStreamsFromProvider(ctx context.Co
 		"-i", "pipe:0",
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	cmd.Stdin = pr
 
 	output, err := cmd.Output()
@@ -594,6 +597,7 @@ func (m *SubtitleExtractManager) startExtraction(session *SubtitleExtractSession
 
 	// Get the actual stream index for the selected track
 	actualStreamIndex := subtitleStreams[session.SubtitleTrack].Index
+	codec := subtitleStreams[session.SubtitleTrack].Codec
 	log.Printf("[subtitle-extract] session %s: track %d maps to stream index %d (codec: %s)",
 		session.ID, session.SubtitleTrack, actualStreamIndex, subtitleStreams[session.SubtitleTrack].Codec)
 
@@ -613,16 +617,20 @@ func (m *SubtitleExtractManager) startExtraction(session *SubtitleExtractSession
 		log.Printf("[subtitle-extract] session %s: seeking to %.3f seconds with -copyts", session.ID, session.StartOffset)
 	}
 
+	// ASS/SSA tracks are extracted as-is and converted to styled VTT as they arrive
+	var assPath string
+	if isASSCodec(codec) {
+		assPath = assPathFor(session.VTTPath)
+		args = append(args, "-y")
+	}
+
 	args = append(args,
 		"-i", streamURL,
 		"-map", fmt.Sprintf("0:%d", actualStreamIndex),
-		"-c", "webvtt",
-		"-f", "webvtt",
-		"-flush_packets", "1",
-		session.VTTPath,
 	)
+	args = append(args, subtitleOutputArgs(codec, session.VTTPath, assPath)...)
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, m.ffmpegPath, args...)
 
 	// Capture stderr for logging
 	stderr, _ := cmd.StderrPipe()
@@ -639,6 +647,12 @@ func (m *SubtitleExtractManager) startExtraction(session *SubtitleExtractSession
 		return
 	}
 
+	var styled *styledASSConversion
+	if assPath != "" {
+		styled = startStyledASSConversion(ctx, assPath, session.VTTPath)
+		defer styled.finish()
+	}
+
 	// Log stderr in background
 	go func() {
 		if stderr != nil {
@@ -667,6 +681,9 @@ func (m *SubtitleExtractManager) startExtraction(session *SubtitleExtractSession
 		}
 		return
 	}
+	if styled != nil {
+		styled.finish()
+	}
 
 	// Parse first cue time for subtitle sync
 	firstCueTime := parseFirstVTTCueTime(session.VTTPath)
@@ -777,7 +794,7 @@ func (m *SubtitleExtractManager) probeSubtitleStreamsWithMetadata(ctx context.Co
 		"-i", streamURL,
 	}
 
-	cmd := exec.CommandContext(probeCtx, m.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, m.ffprobePath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Printf("[subtitle-extract] ffprobe failed: %v", err)
@@ -1018,7 +1035,6 @@ func (m *SubtitleExtractManager) ServeSubtitles(w http.ResponseWriter, r *http.R
 		log.Printf("[subtitle-extract] serve %s: VTT file not ready yet, returning empty header", sessionID[:8])
 		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Write([]byte("WEBVTT\n\n"))
 		return
 	}
@@ -1073,10 +1089,10 @@ func (m *SubtitleExtractManager) ServeSubtitles(w http.ResponseWriter, r *http.R
 
 	// Post-process VTT to merge karaoke character cues (from ASS conversion)
 	processedContent := mergeKaraokeCues(contentStr)
+	processedContent = shiftVTTCues(processedContent, releaseSubtitleOffset(m.subtitleOffsets, session.Path))
 
 	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Length", strconv.Itoa(len(processedContent)))
 	w.Write([]byte(processedContent))
 }
@@ -1143,6 +1159,7 @@ func (h *VideoHandler) StartSubtitleExtract(w http.ResponseWriter, r *http.Reque
 		"sessionId":    session.ID,
 		"subtitleUrl":  fmt.Sprintf("/api/video/subtitles/%s/subtitles.vtt", session.ID),
 		"firstCueTime": firstCueTime,
+		"offsetMs":     releaseSubtitleOffset(h.subtitleOffsets, session.Path),
 	})
 }
 
@@ -1386,24 +1403,26 @@ func (m *SubtitleExtractManager) startBatchExtraction(path, outputDir string, tr
 
 	args = append(args, "-i", streamURL)
 
-	// Add output for each subtitle track
+	// Add output for each subtitle track. ASS/SSA tracks are extracted as-is
+	// and converted to styled VTT as they arrive
+	styledPaths := make(map[string]string) // ass path -> vtt path
 	for _, track := range tracks {
 		vttPath := filepath.Join(outputDir, fmt.Sprintf("subtitles_%d.vtt", track.Index))
+		var assPath string
+		if isASSCodec(track.Codec) {
+			assPath = assPathFor(vttPath)
+			styledPaths[assPath] = vttPath
+		}
 		// Use AbsoluteIndex (ffprobe stream index) for -map
-		args = append(args,
-			"-map", fmt.Sprintf("0:%d", track.AbsoluteIndex),
-			"-c", "webvtt",
-			"-f", "webvtt",
-			"-flush_packets", "1",
-			vttPath,
-		)
+		args = append(args, "-map", fmt.Sprintf("0:%d", track.AbsoluteIndex))
+		args = append(args, subtitleOutputArgs(track.Codec, vttPath, assPath)...)
 		log.Printf("[subtitle-extract] batch: adding output for track %d (stream %d, codec=%s, lang=%s) -> %s",
 			track.Index, track.AbsoluteIndex, track.Codec, track.Language, vttPath)
 	}
 
 	log.Printf("[subtitle-extract] batch extraction starting: %d tracks from %s (single ffmpeg process)", len(tracks), streamURL)
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, m.ffmpegPath, args...)
 
 	// Capture stderr for logging
 	stderr, _ := cmd.StderrPipe()
@@ -1414,6 +1433,13 @@ func (m *SubtitleExtractManager) startBatchExtraction(path, outputDir string, tr
 		return
 	}
 
+	styled := make([]*styledASSConversion, 0, len(styledPaths))
+	for assPath, vttPath := range styledPaths {
+		conv := startStyledASSConversion(ctx, assPath, vttPath)
+		defer conv.finish()
+		styled = append(styled, conv)
+	}
+
 	// Log stderr in background
 	go func() {
 		if stderr != nil {
@@ -1440,6 +1466,9 @@ func (m *SubtitleExtractManager) startBatchExtraction(path, outputDir string, tr
 			return
 		}
 	}
+	for _, conv := range styled {
+		conv.finish()
+	}
 
 	// Parse first cue time for each session for subtitle sync
 	for _, session := range sessions {
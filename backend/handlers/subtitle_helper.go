@@ -101,6 +101,7 @@ func ConvertSessionsToInfo(
 			Title:        stream.Title,
 			Codec:        stream.Codec,
 			IsForced:     stream.IsForced,
+			IsSDH:        stream.IsSDH,
 			IsExtracting: !session.IsExtractionComplete(),
 			FirstCueTime: firstCueTime,
 		}
@@ -141,6 +142,7 @@ func ConvertSessionsFromPlaybackTracks(
 			Title:        track.Title,
 			Codec:        track.Codec,
 			IsForced:     track.Forced,
+			IsSDH:        track.SDH,
 			IsExtracting: !session.IsExtractionComplete(),
 			FirstCueTime: firstCueTime,
 		}
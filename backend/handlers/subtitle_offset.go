@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"novastream/services/subtitle_offsets"
+)
+
+// SubtitleOffsetStore remembers subtitle timing corrections per release path
+type SubtitleOffsetStore interface {
+	Get(releasePath string) int64
+	Set(releasePath string, offsetMs int64) (int64, error)
+}
+
+var _ SubtitleOffsetStore = (*subtitle_offsets.Service)(nil)
+
+// SetSubtitleOffsetStore enables persisted subtitle delay adjustments for sidecar VTTs
+func (h *VideoHandler) SetSubtitleOffsetStore(store SubtitleOffsetStore) {
+	h.subtitleOffsets = store
+	if h.hlsManager != nil {
+		h.hlsManager.subtitleOffsets = store
+	}
+	if h.subtitleExtractManager != nil {
+		h.subtitleExtractManager.subtitleOffsets = store
+	}
+}
+
+// AdjustSubtitleOffset shifts subtitle timing for the release behind an HLS or
+// standalone subtitle session. Body: {"deltaMs": N} to nudge relative to the
+// current offset, or {"offsetMs": N} to set it outright. Positive values delay
+// subtitles. The offset is remembered per release for subsequent plays.
+// POST /api/video/hls/{sessionID}/subtitles/offset
+// POST /api/video/subtitles/{sessionID}/offset
+func (h *VideoHandler) AdjustSubtitleOffset(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		h.HandleOptions(w, r)
+		return
+	}
+
+	if h.subtitleOffsets == nil {
+		http.Error(w, "subtitle offsets not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		DeltaMs  *int64 `json:"deltaMs,omitempty"`
+		OffsetMs *int64 `json:"offsetMs,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if (req.DeltaMs == nil) == (req.OffsetMs == nil) {
+		http.Error(w, "exactly one of deltaMs or offsetMs is required", http.StatusBadRequest)
+		return
+	}
+
+	releasePath, err := h.subtitleSessionReleasePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	offsetMs := h.subtitleOffsets.Get(releasePath)
+	if req.DeltaMs != nil {
+		offsetMs += *req.DeltaMs
+	} else {
+		offsetMs = *req.OffsetMs
+	}
+
+	stored, err := h.subtitleOffsets.Set(releasePath, offsetMs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[subtitles] offset for %s set to %dms", releasePath, stored)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"offsetMs": stored,
+	})
+}
+
+// subtitleSessionReleasePath returns the release path of the HLS session or
+// standalone subtitle session named in the route.
+func (h *VideoHandler) subtitleSessionReleasePath(r *http.Request) (string, error) {
+	sessionID := mux.Vars(r)["sessionID"]
+	if sessionID == "" {
+		return "", fmt.Errorf("missing session ID")
+	}
+
+	if strings.Contains(r.URL.Path, "/video/hls/") {
+		if h.hlsManager == nil {
+			return "", fmt.Errorf("HLS not enabled")
+		}
+		session, ok := h.hlsManager.GetSession(sessionID)
+		if !ok {
+			return "", fmt.Errorf("session not found")
+		}
+		return session.Path, nil
+	}
+
+	if h.subtitleExtractManager == nil {
+		return "", fmt.Errorf("subtitle extraction not configured")
+	}
+	session, ok := h.subtitleExtractManager.GetSession(sessionID)
+	if !ok {
+		return "", fmt.Errorf("session not found")
+	}
+	return session.Path, nil
+}
+
+// releaseSubtitleOffset returns the stored offset for a release, or 0 when no store is configured
+func releaseSubtitleOffset(store SubtitleOffsetStore, releasePath string) int64 {
+	if store == nil {
+		return 0
+	}
+	return store.Get(releasePath)
+}
+
+// shiftVTTCues moves every cue in a WebVTT document by offsetMs. Cues pushed
+// entirely before zero are dropped; partially negative cues are clamped to zero.
+func shiftVTTCues(content string, offsetMs int64) string {
+	if offsetMs == 0 {
+		return content
+	}
+	offset := float64(offsetMs) / 1000
+
+	blocks := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n")
+	kept := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		lines := strings.Split(block, "\n")
+		timingIdx := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timingIdx = i
+				break
+			}
+		}
+		if timingIdx < 0 {
+			kept = append(kept, block)
+			continue
+		}
+
+		parts := strings.SplitN(lines[timingIdx], "-->", 2)
+		startStr := strings.TrimSpace(parts[0])
+		endField := strings.TrimSpace(parts[1])
+		endStr, settings := endField, ""
+		if idx := strings.IndexAny(endField, " \t"); idx > 0 {
+			endStr, settings = endField[:idx], endField[idx:]
+		}
+
+		start := parseVTTTimestamp(startStr) + offset
+		end := parseVTTTimestamp(endStr) + offset
+		if end <= 0 {
+			continue
+		}
+		start = math.Max(start, 0)
+
+		lines[timingIdx] = formatVTTTimestamp(start) + " --> " + formatVTTTimestamp(end) + settings
+		kept = append(kept, strings.Join(lines, "\n"))
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// formatVTTTimestamp renders seconds as a WebVTT HH:MM:SS.mmm timestamp
+func formatVTTTimestamp(seconds float64) string {
+	totalMs := int64(math.Round(seconds * 1000))
+	hours := totalMs / 3600000
+	minutes := (totalMs % 3600000) / 60000
+	secs := (totalMs % 60000) / 1000
+	ms := totalMs % 1000
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, secs, ms)
+}
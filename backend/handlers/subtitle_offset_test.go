@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShiftVTTCues(t *testing.T) {
+	vtt := "WEBVTT\n\n" +
+		"1\n00:00:00.500 --> 00:00:01.000\nDropped\n\n" +
+		"2\n00:00:01.200 --> 00:00:03.000 align:start\nClamped\n\n" +
+		"3\n01:00:59.900 --> 01:01:00.400\nLate\n"
+
+	shifted := shiftVTTCues(vtt, -1500)
+
+	if strings.Contains(shifted, "Dropped") {
+		t.Fatalf("expected cue ending before zero to be dropped:\n%s", shifted)
+	}
+	if !strings.Contains(shifted, "00:00:00.000 --> 00:00:01.500 align:start\nClamped") {
+		t.Fatalf("expected partially negative cue to be clamped and keep settings:\n%s", shifted)
+	}
+	if !strings.Contains(shifted, "01:00:58.400 --> 01:00:58.900\nLate") {
+		t.Fatalf("expected cue shifted by 1.5s:\n%s", shifted)
+	}
+	if !strings.HasPrefix(shifted, "WEBVTT") {
+		t.Fatalf("expected header to be preserved:\n%s", shifted)
+	}
+	if shiftVTTCues(vtt, 0) != vtt {
+		t.Fatal("expected zero offset to leave content untouched")
+	}
+}
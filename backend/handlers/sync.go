@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/sync_journal"
+
+	"github.com/gorilla/mux"
+)
+
+type syncJournal interface {
+	ChangesSince(userID string, cursor int64) ([]models.SyncChange, int64, bool)
+	ChangesSinceTime(userID string, since time.Time) ([]models.SyncChange, int64, bool)
+	Cursor() int64
+}
+
+var _ syncJournal = (*sync_journal.Service)(nil)
+
+type syncWatchlistSource interface {
+	List(userID string) ([]models.WatchlistItem, error)
+}
+
+type syncHistorySource interface {
+	ListWatchHistory(userID string) ([]models.WatchHistoryItem, error)
+	ListPlaybackProgress(userID string) ([]models.PlaybackProgress, error)
+}
+
+// SyncHandler serves incremental watchlist/history/progress syncs from the change journal.
+type SyncHandler struct {
+	journal   syncJournal
+	watchlist syncWatchlistSource
+	history   syncHistorySource
+	users     userService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(journal syncJournal, watchlist syncWatchlistSource, history syncHistorySource, users userService) *SyncHandler {
+	return &SyncHandler{journal: journal, watchlist: watchlist, history: history, users: users}
+}
+
+// Sync handles GET /api/users/{userID}/sync.
+//
+// Query params:
+//   - since: cursor returned by the previous sync (omit or 0 for a full snapshot)
+//   - updatedSince: RFC3339 timestamp alternative to since
+//   - collections: comma-separated subset of watchlist,history,progress (default all)
+//
+// Deleted items are returned as tombstone keys. When reset is true the client
+// must replace its local state with the returned snapshot.
+func (h *SyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+	if userID == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return
+	}
+	if h.users != nil && !h.users.Exists(userID) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	collections, err := parseSyncCollections(r.URL.Query().Get("collections"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var (
+		changes []models.SyncChange
+		cursor  int64
+		reset   bool
+	)
+	query := r.URL.Query()
+	switch {
+	case strings.TrimSpace(query.Get("since")) != "" && query.Get("since") != "0":
+		since, err := strconv.ParseInt(strings.TrimSpace(query.Get("since")), 10, 64)
+		if err != nil || since < 0 {
+			http.Error(w, "since must be a non-negative integer cursor", http.StatusBadRequest)
+			return
+		}
+		changes, cursor, reset = h.journal.ChangesSince(userID, since)
+	case strings.TrimSpace(query.Get("updatedSince")) != "":
+		since, err := time.Parse(time.RFC3339, strings.TrimSpace(query.Get("updatedSince")))
+		if err != nil {
+			http.Error(w, "updatedSince must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		changes, cursor, reset = h.journal.ChangesSinceTime(userID, since)
+	default:
+		cursor, reset = h.journal.Cursor(), true
+	}
+
+	delta := models.SyncDelta{Cursor: cursor, Reset: reset, ServerNow: time.Now().UTC()}
+	changed := groupSyncChanges(changes)
+
+	if collections[models.SyncCollectionWatchlist] {
+		items, err := h.watchlist.List(userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		delta.Watchlist = buildSyncDelta(items, func(item models.WatchlistItem) string { return item.Key() }, changed[models.SyncCollectionWatchlist], reset)
+	}
+	if collections[models.SyncCollectionHistory] {
+		items, err := h.history.ListWatchHistory(userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		delta.History = buildSyncDelta(items, func(item models.WatchHistoryItem) string { return item.ID }, changed[models.SyncCollectionHistory], reset)
+	}
+	if collections[models.SyncCollectionProgress] {
+		items, err := h.history.ListPlaybackProgress(userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		delta.Progress = buildSyncDelta(items, func(item models.PlaybackProgress) string { return item.ID }, changed[models.SyncCollectionProgress], reset)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delta)
+}
+
+// Options handles CORS preflight requests
+func (h *SyncHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func parseSyncCollections(raw string) (map[string]bool, error) {
+	all := map[string]bool{
+		models.SyncCollectionWatchlist: true,
+		models.SyncCollectionHistory:   true,
+		models.SyncCollectionProgress:  true,
+	}
+	if strings.TrimSpace(raw) == "" {
+		return all, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if !all[name] {
+			return nil, fmt.Errorf("unknown sync collection: %s", name)
+		}
+		selected[name] = true
+	}
+	return selected, nil
+}
+
+// groupSyncChanges indexes journal changes by collection then key.
+func groupSyncChanges(changes []models.SyncChange) map[string]map[string]string {
+	grouped := make(map[string]map[string]string)
+	for _, change := range changes {
+		if grouped[change.Collection] == nil {
+			grouped[change.Collection] = make(map[string]string)
+		}
+		grouped[change.Collection][change.Key] = change.Op
+	}
+	return grouped
+}
+
+// buildSyncDelta returns the full collection on reset; otherwise only items whose
+// keys changed, plus tombstones for keys that were deleted (or no longer exist).
+func buildSyncDelta[T any](items []T, keyOf func(T) string, changed map[string]string, reset bool) *models.SyncCollectionDelta[T] {
+	delta := &models.SyncCollectionDelta[T]{Upserts: make([]T, 0), Deletes: make([]string, 0)}
+	if reset {
+		delta.Upserts = append(delta.Upserts, items...)
+		return delta
+	}
+
+	present := make(map[string]bool, len(changed))
+	for _, item := range items {
+		key := keyOf(item)
+		if op, ok := changed[key]; ok && op == models.SyncOpUpsert {
+			delta.Upserts = append(delta.Upserts, item)
+			present[key] = true
+		}
+	}
+	for key := range changed {
+		if !present[key] {
+			delta.Deletes = append(delta.Deletes, key)
+		}
+	}
+	sort.Strings(delta.Deletes)
+	return delta
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"novastream/models"
+	"novastream/services/history"
+	"novastream/services/sync_journal"
+	"novastream/services/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSyncHandler_ReturnsDeltaSinceCursor(t *testing.T) {
+	dir := t.TempDir()
+	journal, err := sync_journal.NewService(dir)
+	if err != nil {
+		t.Fatalf("sync_journal.NewService() error = %v", err)
+	}
+	watchlistSvc, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("watchlist.NewService() error = %v", err)
+	}
+	historySvc, err := history.NewService(dir)
+	if err != nil {
+		t.Fatalf("history.NewService() error = %v", err)
+	}
+	watchlistSvc.SetChangeRecorder(journal)
+	historySvc.SetChangeRecorder(journal)
+
+	userID := "user-1"
+	if _, err := watchlistSvc.AddOrUpdate(userID, models.WatchlistUpsert{ID: "tmdb:1", MediaType: "movie", Name: "One"}); err != nil {
+		t.Fatalf("AddOrUpdate() error = %v", err)
+	}
+	if _, err := watchlistSvc.AddOrUpdate(userID, models.WatchlistUpsert{ID: "tmdb:2", MediaType: "movie", Name: "Two"}); err != nil {
+		t.Fatalf("AddOrUpdate() error = %v", err)
+	}
+	cursor := journal.Cursor()
+
+	if _, err := watchlistSvc.Remove(userID, "movie", "tmdb:1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := watchlistSvc.AddOrUpdate(userID, models.WatchlistUpsert{ID: "tmdb:3", MediaType: "movie", Name: "Three"}); err != nil {
+		t.Fatalf("AddOrUpdate() error = %v", err)
+	}
+
+	h := NewSyncHandler(journal, watchlistSvc, historySvc, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user-1/sync?collections=watchlist&since="+strconv.FormatInt(cursor, 10), nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": userID})
+	rec := httptest.NewRecorder()
+	h.Sync(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var delta models.SyncDelta
+	if err := json.NewDecoder(rec.Body).Decode(&delta); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if delta.Reset {
+		t.Fatal("did not expect reset")
+	}
+	if delta.History != nil || delta.Progress != nil {
+		t.Fatal("expected only the watchlist collection")
+	}
+	if len(delta.Watchlist.Upserts) != 1 || delta.Watchlist.Upserts[0].ID != "tmdb:3" {
+		t.Fatalf("expected tmdb:3 upsert, got %+v", delta.Watchlist.Upserts)
+	}
+	if len(delta.Watchlist.Deletes) != 1 || delta.Watchlist.Deletes[0] != "movie:tmdb:1" {
+		t.Fatalf("expected movie:tmdb:1 tombstone, got %+v", delta.Watchlist.Deletes)
+	}
+}
+
+func TestSyncHandler_FullSnapshotWithoutCursor(t *testing.T) {
+	dir := t.TempDir()
+	journal, _ := sync_journal.NewService(dir)
+	watchlistSvc, _ := watchlist.NewService(dir)
+	historySvc, _ := history.NewService(dir)
+	if _, err := watchlistSvc.AddOrUpdate("user-1", models.WatchlistUpsert{ID: "tmdb:1", MediaType: "movie"}); err != nil {
+		t.Fatalf("AddOrUpdate() error = %v", err)
+	}
+
+	h := NewSyncHandler(journal, watchlistSvc, historySvc, nil)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/users/user-1/sync", nil), map[string]string{"userID": "user-1"})
+	rec := httptest.NewRecorder()
+	h.Sync(rec, req)
+
+	var delta models.SyncDelta
+	if err := json.NewDecoder(rec.Body).Decode(&delta); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !delta.Reset || len(delta.Watchlist.Upserts) != 1 {
+		t.Fatalf("expected full snapshot with reset, got %+v", delta)
+	}
+}
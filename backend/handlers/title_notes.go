@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"novastream/models"
+	"novastream/services/title_notes"
+
+	"github.com/gorilla/mux"
+)
+
+type titleNotesService interface {
+	List(profileID, titleID string) ([]models.TitleNote, error)
+	Create(profileID string, req models.TitleNoteRequest) (*models.TitleNote, error)
+	Update(profileID, noteID string, update models.TitleNoteUpdate) (*models.TitleNote, error)
+	Delete(profileID, noteID string) error
+}
+
+var _ titleNotesService = (*title_notes.Service)(nil)
+
+// TitleNotesHandler exposes notes on titles shared by a household's profiles
+type TitleNotesHandler struct {
+	svc titleNotesService
+}
+
+// NewTitleNotesHandler creates a new title notes handler
+func NewTitleNotesHandler(svc titleNotesService) *TitleNotesHandler {
+	return &TitleNotesHandler{svc: svc}
+}
+
+// List handles GET /api/users/{userID}/title-notes
+// Returns the notes of the profile's household, newest first.
+// Optional query param: titleId to list the notes on one title
+func (h *TitleNotesHandler) List(w http.ResponseWriter, r *http.Request) {
+	notes, err := h.svc.List(mux.Vars(r)["userID"], r.URL.Query().Get("titleId"))
+	if err != nil {
+		writeJSONError(w, err.Error(), titleNoteErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notes)
+}
+
+// Create handles POST /api/users/{userID}/title-notes
+func (h *TitleNotesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.TitleNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	note, err := h.svc.Create(mux.Vars(r)["userID"], req)
+	if err != nil {
+		writeJSONError(w, err.Error(), titleNoteErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
+
+// Update handles PATCH /api/users/{userID}/title-notes/{noteID}
+// Any profile of the household can edit a note; the editor is recorded.
+func (h *TitleNotesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var update models.TitleNoteUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	note, err := h.svc.Update(vars["userID"], vars["noteID"], update)
+	if err != nil {
+		writeJSONError(w, err.Error(), titleNoteErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(note)
+}
+
+// Delete handles DELETE /api/users/{userID}/title-notes/{noteID}
+func (h *TitleNotesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.svc.Delete(vars["userID"], vars["noteID"]); err != nil {
+		writeJSONError(w, err.Error(), titleNoteErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Options handles CORS preflight requests
+func (h *TitleNotesHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func titleNoteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, title_notes.ErrProfileNotFound),
+		errors.Is(err, title_notes.ErrNoteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, title_notes.ErrTitleIDRequired),
+		errors.Is(err, title_notes.ErrBodyRequired),
+		errors.Is(err, title_notes.ErrBodyTooLong):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -7,10 +7,11 @@ import (
 
 // AudioStreamInfo contains audio stream metadata for track selection
 type AudioStreamInfo struct {
-	Index    int
-	Codec    string
-	Language string
-	Title    string
+	Index              int
+	Codec              string
+	Language           string
+	Title              string
+	IsAudioDescription bool // Audio description (AD) for the visually impaired
 }
 
 // SubtitleStreamInfo contains subtitle stream metadata for track selection
@@ -21,6 +22,7 @@ type SubtitleStreamInfo struct {
 	Title     string
 	IsForced  bool
 	IsDefault bool
+	IsSDH     bool // Subtitles for the deaf and hard of hearing
 }
 
 // CompatibleAudioCodecs lists codecs that can be played without transcoding
@@ -101,10 +103,16 @@ func matchesLanguage(language, title, normalizedPref string) bool {
 	return false
 }
 
+// isSecondaryAudioTrack reports whether an audio track should only be picked
+// when nothing else matches (commentary and audio description).
+func isSecondaryAudioTrack(stream AudioStreamInfo) bool {
+	return IsCommentaryTrack(stream.Title) || stream.IsAudioDescription
+}
+
 // FindAudioTrackByLanguage finds an audio track matching the preferred language.
 // Prefers compatible audio codecs (AAC, AC3, etc.) over TrueHD/DTS when multiple tracks exist.
 // Specifically avoids TrueHD/MLP unless it's the only option for the preferred language.
-// Skips commentary tracks unless they are the only option.
+// Skips commentary and audio-description tracks unless they are the only option.
 // Returns -1 if no matching track is found.
 func FindAudioTrackByLanguage(streams []AudioStreamInfo, preferredLanguage string) int {
 	if preferredLanguage == "" || len(streams) == 0 {
@@ -117,7 +125,7 @@ func FindAudioTrackByLanguage(streams []AudioStreamInfo, preferredLanguage strin
 	for _, stream := range streams {
 		if matchesLanguage(stream.Language, stream.Title, normalizedPref) &&
 			CompatibleAudioCodecs[strings.ToLower(stream.Codec)] &&
-			!IsCommentaryTrack(stream.Title) {
+			!isSecondaryAudioTrack(stream) {
 			log.Printf("[track] Preferred compatible audio track %d (%s) for language %q",
 				stream.Index, stream.Codec, preferredLanguage)
 			return stream.Index
@@ -129,7 +137,7 @@ func FindAudioTrackByLanguage(streams []AudioStreamInfo, preferredLanguage strin
 	for _, stream := range streams {
 		if matchesLanguage(stream.Language, stream.Title, normalizedPref) &&
 			!IsTrueHDCodec(stream.Codec) &&
-			!IsCommentaryTrack(stream.Title) {
+			!isSecondaryAudioTrack(stream) {
 			log.Printf("[track] Selected non-TrueHD audio track %d (%s) for language %q - will need HLS transcoding",
 				stream.Index, stream.Codec, preferredLanguage)
 			return stream.Index
@@ -140,7 +148,7 @@ func FindAudioTrackByLanguage(streams []AudioStreamInfo, preferredLanguage strin
 	for _, stream := range streams {
 		if matchesLanguage(stream.Language, stream.Title, normalizedPref) &&
 			IsTrueHDCodec(stream.Codec) &&
-			!IsCommentaryTrack(stream.Title) {
+			!isSecondaryAudioTrack(stream) {
 			log.Printf("[track] Selected TrueHD audio track %d (%s) for language %q (only option) - will need HLS transcoding",
 				stream.Index, stream.Codec, preferredLanguage)
 			return stream.Index
@@ -179,9 +187,57 @@ func FindAudioTrackByLanguage(streams []AudioStreamInfo, preferredLanguage strin
 	return -1
 }
 
+// FindAudioDescriptionTrack finds an audio-description track matching the
+// preferred language, or the first one when no language is set. Compatible
+// codecs are preferred. Returns -1 if no matching track is found.
+func FindAudioDescriptionTrack(streams []AudioStreamInfo, preferredLanguage string) int {
+	normalizedPref := strings.ToLower(strings.TrimSpace(preferredLanguage))
+	match := -1
+	for _, stream := range streams {
+		if !stream.IsAudioDescription {
+			continue
+		}
+		if normalizedPref != "" && !matchesLanguage(stream.Language, stream.Title, normalizedPref) {
+			continue
+		}
+		if CompatibleAudioCodecs[strings.ToLower(stream.Codec)] {
+			log.Printf("[track] Selected audio description track %d (%s) for language %q", stream.Index, stream.Codec, preferredLanguage)
+			return stream.Index
+		}
+		if match < 0 {
+			match = stream.Index
+		}
+	}
+	if match >= 0 {
+		log.Printf("[track] Selected audio description track %d for language %q - may need HLS transcoding", match, preferredLanguage)
+	}
+	return match
+}
+
+// FindSDHSubtitleTrack finds a non-forced SDH subtitle track matching the
+// preferred language, or the first one when no language is set.
+// Returns -1 if no matching track is found.
+func FindSDHSubtitleTrack(streams []SubtitleStreamInfo, preferredLanguage string) int {
+	normalizedPref := strings.ToLower(strings.TrimSpace(preferredLanguage))
+	for _, stream := range streams {
+		if stream.IsForced || !isSDHTrack(stream) {
+			continue
+		}
+		if normalizedPref != "" && !matchesLanguage(stream.Language, stream.Title, normalizedPref) {
+			continue
+		}
+		log.Printf("[track] Selected SDH subtitle track %d for language %q (accessibility preference)", stream.Index, preferredLanguage)
+		return stream.Index
+	}
+	return -1
+}
+
 // isSDHTrack checks if a subtitle track is SDH (Subtitles for Deaf/Hard of Hearing)
-func isSDHTrack(title string) bool {
-	lower := strings.ToLower(strings.TrimSpace(title))
+func isSDHTrack(stream SubtitleStreamInfo) bool {
+	if stream.IsSDH {
+		return true
+	}
+	lower := strings.ToLower(strings.TrimSpace(stream.Title))
 	return strings.Contains(lower, "sdh") || strings.Contains(lower, "deaf") || strings.Contains(lower, "hard of hearing")
 }
 
@@ -221,7 +277,7 @@ func FindSubtitleTrackByPreference(streams []SubtitleStreamInfo, preferredLangua
 	if normalizedPref != "" {
 		// Pass 1: SDH tracks matching language (non-forced)
 		for _, stream := range streams {
-			if !stream.IsForced && isSDHTrack(stream.Title) && matchesLanguage(stream.Language, stream.Title, normalizedPref) {
+			if !stream.IsForced && isSDHTrack(stream) && matchesLanguage(stream.Language, stream.Title, normalizedPref) {
 				log.Printf("[track] Selected SDH subtitle track %d for language %q", stream.Index, preferredLanguage)
 				return stream.Index
 			}
@@ -229,7 +285,7 @@ func FindSubtitleTrackByPreference(streams []SubtitleStreamInfo, preferredLangua
 
 		// Pass 2: Regular non-forced, non-SDH tracks matching language
 		for _, stream := range streams {
-			if !stream.IsForced && !isSDHTrack(stream.Title) && matchesLanguage(stream.Language, stream.Title, normalizedPref) {
+			if !stream.IsForced && !isSDHTrack(stream) && matchesLanguage(stream.Language, stream.Title, normalizedPref) {
 				log.Printf("[track] Selected regular subtitle track %d for language %q", stream.Index, preferredLanguage)
 				return stream.Index
 			}
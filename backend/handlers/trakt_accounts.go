@@ -42,6 +42,8 @@ type TraktAccountResponse struct {
 	Username          string   `json:"username,omitempty"`
 	Connected         bool     `json:"connected"`
 	ScrobblingEnabled bool     `json:"scrobblingEnabled"`
+	CalendarPrefetch  bool     `json:"calendarPrefetchEnabled"`
+	CalendarWarmup    bool     `json:"calendarPrefetchWarmup"`
 	ExpiresAt         int64    `json:"expiresAt,omitempty"`
 	LinkedProfiles    []string `json:"linkedProfiles,omitempty"` // Profile IDs using this account
 }
@@ -86,6 +88,8 @@ func (h *TraktAccountsHandler) ListAccounts(w http.ResponseWriter, r *http.Reque
 				Username:          acc.Username,
 				Connected:         acc.AccessToken != "",
 				ScrobblingEnabled: acc.ScrobblingEnabled,
+				CalendarPrefetch:  acc.CalendarPrefetchEnabled,
+				CalendarWarmup:    acc.CalendarPrefetchWarmup,
 				ExpiresAt:         acc.ExpiresAt,
 				LinkedProfiles:    profilesByAccount[acc.ID],
 			})
@@ -198,6 +202,8 @@ func (h *TraktAccountsHandler) GetAccount(w http.ResponseWriter, r *http.Request
 		Username:          account.Username,
 		Connected:         account.AccessToken != "",
 		ScrobblingEnabled: account.ScrobblingEnabled,
+		CalendarPrefetch:  account.CalendarPrefetchEnabled,
+		CalendarWarmup:    account.CalendarPrefetchWarmup,
 		ExpiresAt:         account.ExpiresAt,
 		LinkedProfiles:    profileIDs,
 	})
@@ -217,6 +223,8 @@ func (h *TraktAccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Requ
 		ClientID          *string `json:"clientId,omitempty"`
 		ClientSecret      *string `json:"clientSecret,omitempty"`
 		ScrobblingEnabled *bool   `json:"scrobblingEnabled,omitempty"`
+		CalendarPrefetch  *bool   `json:"calendarPrefetchEnabled,omitempty"`
+		CalendarWarmup    *bool   `json:"calendarPrefetchWarmup,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -248,6 +256,12 @@ func (h *TraktAccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Requ
 	if req.ScrobblingEnabled != nil {
 		account.ScrobblingEnabled = *req.ScrobblingEnabled
 	}
+	if req.CalendarPrefetch != nil {
+		account.CalendarPrefetchEnabled = *req.CalendarPrefetch
+	}
+	if req.CalendarWarmup != nil {
+		account.CalendarPrefetchWarmup = *req.CalendarWarmup
+	}
 
 	settings.Trakt.UpdateAccount(*account)
 
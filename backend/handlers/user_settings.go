@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
@@ -17,6 +19,9 @@ type userSettingsService interface {
 	GetWithDefaults(userID string, defaults models.UserSettings) (models.UserSettings, error)
 	Update(userID string, settings models.UserSettings) error
 	Delete(userID string) error
+	GetUsersWithOverrides() map[string]bool
+	ClientPreferences(userID string) (models.ClientPreferences, error)
+	UpdateClientPreferences(userID, clientID string, update models.ClientPreferencesUpdate) (models.ClientPreferencesResult, error)
 }
 
 var _ userSettingsService = (*user_settings.Service)(nil)
@@ -74,11 +79,65 @@ func (h *UserSettingsHandler) PutSettings(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// A profile shelf may be the first (or last) custom list
+	if err := syncCustomListTask(h.ConfigManager, h.Service); err != nil {
+		log.Printf("[user_settings] failed to sync custom list refresh task: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(settings)
 }
 
+// GetPreferences returns the profile's synced client preferences.
+func (h *UserSettingsHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.Service.ClientPreferences(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// PatchPreferences merges a device's preference changes. Keys another device
+// changed since the update's base version come back as conflicts with the
+// stored value kept; the device can resend them with force to overwrite.
+func (h *UserSettingsHandler) PatchPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var update models.ClientPreferencesUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.Service.UpdateClientPreferences(userID, strings.TrimSpace(r.Header.Get("X-Client-ID")), update)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, user_settings.ErrPreferenceKeyRequired):
+			status = http.StatusBadRequest
+		case errors.Is(err, user_settings.ErrPreferencesTooLarge):
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 func (h *UserSettingsHandler) Options(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -28,6 +29,10 @@ type usersService interface {
 	ClearIconURL(id string) (models.User, error)
 	GetIconPath(id string) (string, error)
 	Delete(id string) error
+	ListDeletedForAccount(accountID string) []models.User
+	GetDeleted(id string) (models.User, bool)
+	Restore(id string) (models.User, error)
+	Get(id string) (models.User, bool)
 	Exists(id string) bool
 	SetPin(id, pin string) (models.User, error)
 	ClearPin(id string) (models.User, error)
@@ -43,13 +48,19 @@ type usersService interface {
 var _ usersService = (*users.Service)(nil)
 
 type UsersHandler struct {
-	Service usersService
+	Service  usersService
+	exporter *ProfileExporter
 }
 
 func NewUsersHandler(service usersService) *UsersHandler {
 	return &UsersHandler{Service: service}
 }
 
+// SetExporter enables profile exports, including export-before-delete
+func (h *UsersHandler) SetExporter(exporter *ProfileExporter) {
+	h.exporter = exporter
+}
+
 func (h *UsersHandler) List(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -147,21 +158,122 @@ func (h *UsersHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// With ?export=true the profile's data is returned as the response so it
+	// can be kept; nothing is deleted if the export fails.
+	var export *models.ProfileExport
+	if r.URL.Query().Get("export") == "true" {
+		user, ok := h.Service.Get(id)
+		if !ok {
+			http.Error(w, "profile not found", http.StatusNotFound)
+			return
+		}
+		built, ok := h.buildExport(w, user)
+		if !ok {
+			return
+		}
+		export = &built
+	}
+
 	if err := h.Service.Delete(id); err != nil {
 		status := http.StatusInternalServerError
 		switch {
 		case errors.Is(err, users.ErrUserNotFound):
 			status = http.StatusNotFound
-		case strings.Contains(err.Error(), "cannot delete the last user"):
+		case errors.Is(err, users.ErrLastUser):
 			status = http.StatusBadRequest
 		}
 		http.Error(w, err.Error(), status)
 		return
 	}
 
+	if export != nil {
+		writeProfileExport(w, *export)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ListDeleted handles GET /api/users/deleted
+// Returns the account's deleted profiles that can still be restored.
+func (h *UsersHandler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Service.ListDeletedForAccount(auth.GetAccountID(r)))
+}
+
+// Restore handles POST /api/users/deleted/{profileID}/restore
+func (h *UsersHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(mux.Vars(r)["profileID"])
+	if id == "" {
+		http.Error(w, "profile id is required", http.StatusBadRequest)
+		return
+	}
+
+	// Verify the deleted profile belongs to the logged-in account
+	deleted, ok := h.Service.GetDeleted(id)
+	if !ok || deleted.AccountID != auth.GetAccountID(r) {
+		http.Error(w, "profile not found", http.StatusNotFound)
+		return
+	}
+
+	user, err := h.Service.Restore(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, users.ErrUserNotFound) || errors.Is(err, users.ErrNotDeleted) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// Export handles GET /api/users/{userID}/export
+func (h *UsersHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(mux.Vars(r)["userID"])
+	if id == "" {
+		http.Error(w, "user id is required", http.StatusBadRequest)
+		return
+	}
+
+	// Verify profile belongs to the logged-in account
+	user, ok := h.Service.Get(id)
+	if !ok || user.AccountID != auth.GetAccountID(r) {
+		http.Error(w, "profile not found", http.StatusNotFound)
+		return
+	}
+
+	export, ok := h.buildExport(w, user)
+	if !ok {
+		return
+	}
+	writeProfileExport(w, export)
+}
+
+func (h *UsersHandler) buildExport(w http.ResponseWriter, user models.User) (models.ProfileExport, bool) {
+	if h.exporter == nil {
+		http.Error(w, "profile export unavailable", http.StatusServiceUnavailable)
+		return models.ProfileExport{}, false
+	}
+
+	export, err := h.exporter.Export(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return models.ProfileExport{}, false
+	}
+	return export, true
+}
+
+func writeProfileExport(w http.ResponseWriter, export models.ProfileExport) {
+	name := fmt.Sprintf("profile-%s-%s.json", export.Profile.ID, export.ExportedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(export)
+}
+
 func (h *UsersHandler) SetColor(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := strings.TrimSpace(vars["userID"])
@@ -26,6 +26,11 @@ import (
 	"novastream/internal/integration"
 	"novastream/models"
 	"novastream/services/streaming"
+	"novastream/utils"
+	"novastream/utils/accessibility"
+	"novastream/utils/mediatools"
+	"novastream/utils/releasename"
+	"novastream/utils/sandbox"
 
 	"github.com/gorilla/mux"
 )
@@ -40,6 +45,13 @@ var transmuxableExtensions = map[string]struct{}{
 	".mpeg": {},
 }
 
+// Transmux output containers. Matroska keeps every stream as-is, so it is
+// offered to clients that can play it instead of the MP4 remux.
+const (
+	transmuxContainerMP4      = "mp4"
+	transmuxContainerMatroska = "mkv"
+)
+
 var copyableAudioCodecs = map[string]struct{}{
 	"aac":  {},
 	"ac3":  {},
@@ -60,7 +72,6 @@ var browserFriendlyMp4VideoCodecs = map[string]struct{}{
 var legacyAudioWhitelist = []string{"aac", "ac3", "eac3", "mp3"}
 
 const ffprobeTimeout = 15 * time.Second
-const providerProbeSampleBytes int64 = 16 * 1024 * 1024
 
 // VideoHandler handles video streaming requests using the local stream provider.
 type VideoHandler struct {
@@ -72,6 +83,10 @@ type VideoHandler struct {
 
 	// Subtitle extraction for non-HLS streams
 	subtitleExtractManager *SubtitleExtractManager
+	// Persisted per-release subtitle delay corrections
+	subtitleOffsets SubtitleOffsetStore
+	// Persisted per-release audio delay corrections
+	audioOffsets AudioOffsetStore
 
 	// Local WebDAV access for ffprobe seeking (usenet paths)
 	webdavMu       sync.RWMutex
@@ -82,15 +97,27 @@ type VideoHandler struct {
 	userSettingsSvc   UserSettingsProvider
 	clientSettingsSvc ClientSettingsProvider
 	configManager     ConfigProvider
+	deviceSvc         DeviceProvider // Per-device transcode policy and bitrate cap (optional)
 
 	// Metadata response cache for /video/metadata endpoint
 	// Prevents repeated ffprobe calls during playback
 	metadataCacheMu sync.RWMutex
 	metadataCache   map[string]*cachedMetadataEntry
+	// Shared with the HLS manager's probe cache when transmux is enabled
+	fingerprints *sourceFingerprints
+	// Tail of each transmux stream's ffmpeg stderr, shared with the HLS manager
+	ffmpegLogs *ffmpegLogStore
 
 	// In-flight probe deduplication: prevents parallel ffprobe calls for the same path
 	// Key: path, Value: channel that closes when probe completes
 	probeInFlight sync.Map
+
+	// Aggregate ffprobe/transcode failure metrics (optional)
+	failureRecorder MediaFailureRecorder
+
+	// Live channel buffers and guide for starting at the program start (optional)
+	timeshift    liveTimeshiftService
+	programGuide liveProgramGuide
 }
 
 // UserSettingsProvider interface for accessing user settings
@@ -153,6 +180,14 @@ func newVideoHandler(transmuxEnabled bool, ffmpegPath, ffprobePath, hlsTempDir s
 		log.Printf("[video] initialized HLS manager for Dolby Vision streaming (temp dir: %s)", hlsMgr.baseDir)
 	}
 
+	// Cached probe results are invalidated when the file behind a path changes
+	fingerprints := newSourceFingerprints(provider)
+	ffmpegLogs := newFFmpegLogStore()
+	if hlsMgr != nil {
+		fingerprints = hlsMgr.fingerprints
+		ffmpegLogs = hlsMgr.ffmpegLogs
+	}
+
 	// Initialize subtitle extraction manager
 	var subtitleMgr *SubtitleExtractManager
 	if resolvedFFmpeg != "" && resolvedFFprobe != "" && provider != nil {
@@ -169,6 +204,8 @@ func newVideoHandler(transmuxEnabled bool, ffmpegPath, ffprobePath, hlsTempDir s
 		hlsManager:             hlsMgr,
 		subtitleExtractManager: subtitleMgr,
 		metadataCache:          make(map[string]*cachedMetadataEntry),
+		fingerprints:           fingerprints,
+		ffmpegLogs:             ffmpegLogs,
 	}
 }
 
@@ -187,6 +224,19 @@ func (h *VideoHandler) SetClientSettingsService(svc ClientSettingsProvider) {
 	h.clientSettingsSvc = svc
 }
 
+// SetDeviceService sets the device registry for per-device transcode overrides
+func (h *VideoHandler) SetDeviceService(svc DeviceProvider) {
+	h.deviceSvc = svc
+}
+
+// SetFailureRecorder sets the recorder for probe/transcode failure metrics, including HLS sessions
+func (h *VideoHandler) SetFailureRecorder(recorder MediaFailureRecorder) {
+	h.failureRecorder = recorder
+	if h.hlsManager != nil {
+		h.hlsManager.SetFailureRecorder(recorder)
+	}
+}
+
 // StreamVideo serves registered streams via the local provider.
 func (h *VideoHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 	// Handle OPTIONS requests for CORS
@@ -216,10 +266,16 @@ func (h *VideoHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 		cleanPath = "/" + strings.TrimPrefix(cleanPath, "webdav/")
 	}
 
+	if isAudioOnlyRequest(r) {
+		h.streamAudioOnly(w, r, cleanPath)
+		return
+	}
+
 	// Determine whether transmuxing is desired and possible
 	ext := detectContainerExt(cleanPath)
 	target := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("target")))
-	shouldTransmux, overrideTransmux, transmuxReason := h.shouldTransmux(r, cleanPath, ext)
+	container := h.transmuxContainer(r)
+	shouldTransmux, overrideTransmux, transmuxReason := h.shouldTransmux(r, cleanPath, ext, container)
 	if transmuxReason != "" {
 	}
 	forceAAC := target == "web" || target == "browser"
@@ -236,7 +292,7 @@ func (h *VideoHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Debug logging for troubleshooting
-	log.Printf("[video] request path=%q clean=%q method=%s target=%q range=%s transmux=%t provider=%t", filePath, cleanPath, r.Method, target, rangeSummary, shouldTransmux, h.streamer != nil)
+	log.Printf("[video] request path=%q clean=%q method=%s target=%q range=%s transmux=%t container=%s provider=%t", filePath, cleanPath, r.Method, target, rangeSummary, shouldTransmux, container, h.streamer != nil)
 
 	// Additional detailed logging for range requests (seek operations)
 	if rangeHeader != "" {
@@ -257,7 +313,7 @@ func (h *VideoHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 			r.Header.Del("Range")
 		}
 
-		handled, err := h.streamWithTransmuxProvider(w, r, cleanPath, forceAAC, overrideTransmux)
+		handled, err := h.streamWithTransmuxProvider(w, r, cleanPath, container, forceAAC, overrideTransmux)
 		if handled {
 			if err != nil {
 				log.Printf("[video] provider transmux error for %q: %v", cleanPath, err)
@@ -293,6 +349,13 @@ func (h *VideoHandler) streamViaProvider(w http.ResponseWriter, r *http.Request,
 		return h.proxyExternalURL(w, r, cleanPath)
 	}
 
+	// Complete files in the local stream cache bypass the copy loop below
+	if localPath, ok := h.localStreamFile(r.Context(), cleanPath); ok {
+		if h.serveLocalFile(w, r, cleanPath, localPath) {
+			return true, nil
+		}
+	}
+
 	// Create a context with timeout to prevent hanging streams
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Minute)
 	defer cancel()
@@ -492,7 +555,6 @@ func (h *VideoHandler) streamViaProvider(w http.ResponseWriter, r *http.Request,
 
 // HandleOptions handles CORS preflight requests
 func (h *VideoHandler) HandleOptions(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
 	w.Header().Set(
 		"Access-Control-Allow-Headers",
@@ -527,7 +589,46 @@ func isClientGone(err error) bool {
 	return false
 }
 
-func (h *VideoHandler) shouldTransmux(r *http.Request, cleanPath, ext string) (bool, bool, string) {
+// transmuxContainer picks the transmux output container: Matroska when asked
+// for with target=mkv or format=mkv, or when the registered device lists it
+// as its preferred container; MP4 otherwise.
+func (h *VideoHandler) transmuxContainer(r *http.Request) string {
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	target := strings.ToLower(strings.TrimSpace(query.Get("target")))
+
+	switch {
+	case isMatroskaTarget(format) || isMatroskaTarget(target):
+		return transmuxContainerMatroska
+	case format == "mp4" || target == "web" || target == "browser":
+		return transmuxContainerMP4
+	}
+
+	if h.deviceSvc == nil {
+		return transmuxContainerMP4
+	}
+	clientID := strings.TrimSpace(query.Get("clientId"))
+	if clientID == "" {
+		clientID = strings.TrimSpace(r.Header.Get("X-Client-ID"))
+	}
+	if clientID == "" {
+		return transmuxContainerMP4
+	}
+	device, err := h.deviceSvc.Get(clientID)
+	if err != nil || device == nil || len(device.Capabilities.Containers) == 0 {
+		return transmuxContainerMP4
+	}
+	if isMatroskaTarget(device.Capabilities.Containers[0]) {
+		return transmuxContainerMatroska
+	}
+	return transmuxContainerMP4
+}
+
+func isMatroskaTarget(value string) bool {
+	return value == "mkv" || value == "matroska"
+}
+
+func (h *VideoHandler) shouldTransmux(r *http.Request, cleanPath, ext, container string) (bool, bool, string) {
 	query := r.URL.Query()
 	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
 	target := strings.ToLower(strings.TrimSpace(query.Get("target")))
@@ -557,11 +658,19 @@ func (h *VideoHandler) shouldTransmux(r *http.Request, cleanPath, ext string) (b
 
 	ext = strings.ToLower(strings.TrimSpace(ext))
 
+	// A Matroska source already is what the client asked for
+	if ext == ".mkv" && container == transmuxContainerMatroska && !override {
+		return false, override, "already mkv"
+	}
+
 	// Never transmux when the source is already a browser-friendly MP4 unless forced
 	if ext == ".mp4" || ext == ".m4v" {
 		if override {
 			return true, override, "override mp4"
 		}
+		if isMatroskaTarget(format) || isMatroskaTarget(target) {
+			return true, override, "target mkv"
+		}
 
 		if target == "web" || target == "browser" {
 			needs, reason := h.mp4NeedsTransmux(r.Context(), cleanPath)
@@ -582,6 +691,9 @@ func (h *VideoHandler) shouldTransmux(r *http.Request, cleanPath, ext string) (b
 	if format == "mp4" || target == "web" || target == "browser" {
 		return true, override, "target mp4"
 	}
+	if isMatroskaTarget(format) || isMatroskaTarget(target) {
+		return true, override, "target mkv"
+	}
 
 	// Heuristics based on known container extensions
 	if ext == "" {
@@ -680,7 +792,7 @@ func detectContainerExt(name string) string {
 	return strings.ToLower(strings.TrimSpace(path.Ext(lower)))
 }
 
-func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http.Request, cleanPath string, forceAAC bool, override bool) (bool, error) {
+func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http.Request, cleanPath, container string, forceAAC bool, override bool) (bool, error) {
 	if !h.transmux && !override {
 		return false, errors.New("transmux disabled")
 	}
@@ -697,9 +809,14 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Minute)
 	defer cancel()
 
+	contentType := "video/mp4"
+	if container == transmuxContainerMatroska {
+		contentType = "video/x-matroska"
+	}
+
 	if r.Method == http.MethodHead {
 		h.writeCommonHeaders(w)
-		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Type", contentType)
 		w.Header().Set("Accept-Ranges", "none")
 
 		if h.ffprobePath != "" {
@@ -729,6 +846,7 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 			if !errors.Is(err, context.Canceled) {
 				log.Printf("[video] provider ffprobe failed for %q: %v", cleanPath, err)
 			}
+			recordMediaFailure(h.failureRecorder, models.MediaFailureStageProbe, cleanPath, "", err)
 			fallbackReason = fmt.Sprintf("ffprobe failed: %v", err)
 		} else {
 			meta = probe
@@ -741,6 +859,20 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 	}
 
 	plan := h.buildTransmuxPlan(meta, "pipe:0", forceAAC, fallbackReason)
+	if container == transmuxContainerMatroska {
+		// Every stream is copied, so no codec needs transcoding for the
+		// container; audio is still converted when AAC is forced
+		plan.args = buildMatroskaArgs("pipe:0", plan.videoMap, forceAAC && plan.audio.mode == audioPlanTranscode)
+	}
+	if offsetMs := releaseAudioOffset(h.audioOffsets, cleanPath); offsetMs != 0 {
+		// The piped input can only be read once, so shifted audio comes from WebDAV
+		if webdavURL := h.buildWebDAVURL(cleanPath); webdavURL != "" {
+			plan.args = withAudioOffsetInput(plan.args, webdavURL, offsetMs)
+			log.Printf("[video] provider transmux shifting audio by %dms for %q", offsetMs, cleanPath)
+		} else {
+			log.Printf("[video] audio offset %dms not applied to %q: no WebDAV URL", offsetMs, cleanPath)
+		}
+	}
 
 	resp, err := h.streamer.Stream(ctx, streaming.Request{Path: cleanPath, Method: http.MethodGet})
 	if err != nil {
@@ -765,7 +897,7 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 		_ = pw.Close()
 	}()
 
-	cmd := exec.CommandContext(ctx, h.ffmpegPath, plan.args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, h.ffmpegPath, plan.args...)
 	cmd.Stdin = pr
 
 	stdout, err := cmd.StdoutPipe()
@@ -784,12 +916,15 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 		return false, fmt.Errorf("ffmpeg start: %w", err)
 	}
 
+	ffmpegLog := h.ffmpegLogs.open(generateSessionID(), "transmux", cleanPath, plan.args)
+	var waitErr error
+	defer func() { ffmpegLog.finish(waitErr) }()
 	go func() {
-		_, _ = io.Copy(io.Discard, stderr)
+		_, _ = io.Copy(ffmpegLog, stderr)
 	}()
 
 	h.writeCommonHeaders(w)
-	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Accept-Ranges", "none")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Transfer-Encoding", "chunked")
@@ -853,9 +988,10 @@ func (h *VideoHandler) streamWithTransmuxProvider(w http.ResponseWriter, r *http
 		}
 	}
 
-	if err := cmd.Wait(); err != nil {
-		if !strings.Contains(strings.ToLower(err.Error()), "signal") && !strings.Contains(strings.ToLower(err.Error()), "broken pipe") {
-			return started, fmt.Errorf("ffmpeg wait: %w", err)
+	waitErr = cmd.Wait()
+	if waitErr != nil {
+		if !strings.Contains(strings.ToLower(waitErr.Error()), "signal") && !strings.Contains(strings.ToLower(waitErr.Error()), "broken pipe") {
+			return started, fmt.Errorf("ffmpeg wait: %w", waitErr)
 		}
 	}
 
@@ -892,12 +1028,41 @@ func (h *VideoHandler) buildWebDAVURL(cleanPath string) string {
 	return base + pathToUse
 }
 
+// runFFProbeFromProvider probes a path through the shared probe pool so that
+// concurrent probes of the same path (probe, prequeue, playback) run ffprobe once.
 func (h *VideoHandler) runFFProbeFromProvider(ctx context.Context, cleanPath string) (*ffprobeOutput, error) {
+	result, err := sharedProbePool.do(ctx, "ffprobe:"+cleanPath, func(ctx context.Context) (interface{}, error) {
+		return h.runFFProbeFromProviderActual(ctx, cleanPath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := result.(*ffprobeOutput)
+	return meta, nil
+}
+
+func (h *VideoHandler) runFFProbeFromProviderActual(ctx context.Context, cleanPath string) (*ffprobeOutput, error) {
+	// Probe depth follows the source type's strategy; releases from groups and
+	// containers that never needed frame sampling skip it on the fast path
+	strategy := h.probeStrategy(probeSourceForPath(cleanPath))
+	opts := newFFprobeOptions(strategy)
+	historyKey := probeHistoryKey(cleanPath)
+	if strategy.FastPath && sharedProbeHistory.canSkipFrames(historyKey) {
+		opts.sampleFrames = false
+	}
+	meta, err := h.runFFProbeFromProviderWith(ctx, cleanPath, strategy, opts)
+	if err == nil && opts.sampleFrames {
+		sharedProbeHistory.record(historyKey, frameSamplingMattered(meta))
+	}
+	return meta, err
+}
+
+func (h *VideoHandler) runFFProbeFromProviderWith(ctx context.Context, cleanPath string, strategy config.ProbeStrategy, opts ffprobeOptions) (*ffprobeOutput, error) {
 	// Check if this is already an external URL (e.g., from AIOStreams pre-resolved streams)
 	// If so, probe it directly without going through the provider
 	if strings.HasPrefix(cleanPath, "http://") || strings.HasPrefix(cleanPath, "https://") {
 		log.Printf("[video] ffprobe using external URL directly: %s", cleanPath)
-		meta, err := h.runFFProbe(ctx, cleanPath, nil)
+		meta, err := h.runFFProbeWith(ctx, cleanPath, nil, opts)
 		if err != nil {
 			return nil, fmt.Errorf("ffprobe external URL failed: %w", err)
 		}
@@ -913,7 +1078,7 @@ func (h *VideoHandler) runFFProbeFromProvider(ctx context.Context, cleanPath str
 		directURL, err := directProvider.GetDirectURL(ctx, cleanPath)
 		if err == nil && directURL != "" {
 			log.Printf("[video] ffprobe using direct URL for seekable access: %s", cleanPath)
-			meta, err := h.runFFProbe(ctx, directURL, nil)
+			meta, err := h.runFFProbeWith(ctx, directURL, nil, opts)
 			if err != nil {
 				// Log but don't fail - fall through to WebDAV or piped approach
 				log.Printf("[video] ffprobe with direct URL failed, trying alternatives: %v", err)
@@ -928,7 +1093,7 @@ func (h *VideoHandler) runFFProbeFromProvider(ctx context.Context, cleanPath str
 	// Try WebDAV URL for usenet paths - allows ffprobe to seek
 	if webdavURL := h.buildWebDAVURL(cleanPath); webdavURL != "" {
 		log.Printf("[video] ffprobe using WebDAV URL for seekable access: %s", cleanPath)
-		meta, err := h.runFFProbe(ctx, webdavURL, nil)
+		meta, err := h.runFFProbeWith(ctx, webdavURL, nil, opts)
 		if err != nil {
 			// Log but don't fail - fall through to piped approach
 			log.Printf("[video] ffprobe with WebDAV URL failed, falling back to piped probe: %v", err)
@@ -940,8 +1105,8 @@ func (h *VideoHandler) runFFProbeFromProvider(ctx context.Context, cleanPath str
 	// Fall back to piped approach (when direct URL and WebDAV fail)
 	log.Printf("[video] ffprobe falling back to piped probe for: %s", cleanPath)
 	request := streaming.Request{Path: cleanPath, Method: http.MethodGet}
-	if providerProbeSampleBytes > 0 {
-		request.RangeHeader = fmt.Sprintf("bytes=0-%d", providerProbeSampleBytes-1)
+	if sampleBytes := int64(strategy.SampleSizeMB) * 1024 * 1024; sampleBytes > 0 {
+		request.RangeHeader = fmt.Sprintf("bytes=0-%d", sampleBytes-1)
 	}
 
 	resp, err := h.streamer.Stream(ctx, request)
@@ -965,7 +1130,7 @@ func (h *VideoHandler) runFFProbeFromProvider(ctx context.Context, cleanPath str
 		pw.Close()
 	}()
 
-	meta, err := h.runFFProbe(ctx, "pipe:0", pr)
+	meta, err := h.runFFProbeWith(ctx, "pipe:0", pr, opts)
 	if err != nil {
 		pw.CloseWithError(err)
 		return nil, err
@@ -1014,7 +1179,7 @@ func (h *VideoHandler) ProbeVideo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check cache first to avoid repeated ffprobe calls during playback
-	if cachedResp := h.getCachedMetadata(cleanPath); cachedResp != nil {
+	if cachedResp := h.getCachedMetadata(r.Context(), cleanPath); cachedResp != nil {
 		log.Printf("[video] ProbeVideo: using cached metadata for path=%q", cleanPath)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(cachedResp)
@@ -1030,7 +1195,7 @@ func (h *VideoHandler) ProbeVideo(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-existingChan:
 			// Probe completed, result should now be in cache
-			if cachedResp := h.getCachedMetadata(cleanPath); cachedResp != nil {
+			if cachedResp := h.getCachedMetadata(r.Context(), cleanPath); cachedResp != nil {
 				log.Printf("[video] ProbeVideo: using result from completed in-flight probe for path=%q", cleanPath)
 				w.Header().Set("Content-Type", "application/json")
 				json.NewEncoder(w).Encode(cachedResp)
@@ -1135,7 +1300,7 @@ func (h *VideoHandler) ProbeVideo(w http.ResponseWriter, r *http.Request) {
 
 		// Cache successful probe results to avoid repeated ffprobe calls
 		if meta != nil {
-			h.setCachedMetadata(cleanPath, &response)
+			h.setCachedMetadata(r.Context(), cleanPath, &response)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -1230,7 +1395,7 @@ func (h *VideoHandler) ProbeVideo(w http.ResponseWriter, r *http.Request) {
 
 	// Cache successful probe results to avoid repeated ffprobe calls
 	if meta != nil {
-		h.setCachedMetadata(cleanPath, &response)
+		h.setCachedMetadata(r.Context(), cleanPath, &response)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1358,12 +1523,15 @@ func buildArgsWithProbe(inputURL, videoMap string, plan audioPlan, movflags stri
 	args = append(args, "-map", "0:s:m:codec_name:subrip?", "-map", "0:s:m:codec_name:ass?", "-map", "0:s:m:codec_name:ssa?", "-map", "0:s:m:codec_name:mov_text?", "-dn", "-c:v", "copy")
 
 	if shouldTagHevcAsHvc1(videoCodec) {
-		if hasDV {
+		if hasDV && mediatools.DolbyVisionMuxing() {
 			// Use dvh1 tag for Dolby Vision HEVC in MP4
 			// dvh1 = Dolby Vision with backward-compatible HDR10 base layer
 			// -strict unofficial enables dvcC box generation
 			// hevc_metadata fixes VUI for sources with incorrect color metadata (e.g., bt709 instead of bt2020/PQ)
-			args = append(args, "-strict", "unofficial", "-tag:v", "dvh1", "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+			args = append(args, "-strict", "unofficial", "-tag:v", "dvh1")
+			if mediatools.HEVCMetadata() {
+				args = append(args, "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+			}
 			log.Printf("[video] Using dvh1 tag for Dolby Vision content (profile: %s)", dvProfile)
 		} else {
 			args = append(args, "-tag:v", "hvc1")
@@ -1426,9 +1594,12 @@ func buildLegacyArgs(inputURL, movflags string, forceAAC bool, videoCodec string
 		"-c:v", "copy",
 	)
 	if shouldTagHevcAsHvc1(videoCodec) {
-		if hasDV {
+		if hasDV && mediatools.DolbyVisionMuxing() {
 			// -strict unofficial enables dvcC box, hevc_metadata fixes color VUI for sources with wrong metadata
-			args = append(args, "-strict", "unofficial", "-tag:v", "dvh1", "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+			args = append(args, "-strict", "unofficial", "-tag:v", "dvh1")
+			if mediatools.HEVCMetadata() {
+				args = append(args, "-bsf:v", "hevc_metadata=colour_primaries=9:transfer_characteristics=16:matrix_coefficients=9")
+			}
 			log.Printf("[video] Using dvh1 tag for Dolby Vision content (legacy mode, profile: %s)", dvProfile)
 		} else {
 			args = append(args, "-tag:v", "hvc1")
@@ -1450,6 +1621,29 @@ func buildLegacyArgs(inputURL, movflags string, forceAAC bool, videoCodec string
 	return args
 }
 
+// buildMatroskaArgs remuxes into Matroska with every stream copied. Unlike the
+// MP4 remux, bitmap (PGS, VobSub) and ASS subtitles are kept as they are, along
+// with attachments such as the fonts ASS subtitles depend on.
+func buildMatroskaArgs(inputURL, videoMap string, transcodeAAC bool) []string {
+	if strings.TrimSpace(videoMap) == "" {
+		videoMap = "0:v:0"
+	}
+	args := []string{"-nostdin", "-loglevel", "error", "-i", inputURL,
+		"-map", videoMap,
+		"-map", "0:a?",
+		"-map", "0:s?",
+		"-map", "0:t?",
+		"-dn",
+		"-c", "copy",
+	}
+	if transcodeAAC {
+		// Transcode first audio to AAC, copy others
+		args = append(args, "-c:a:0", "aac", "-b:a:0", "192k")
+	}
+	// Live mode skips the cues and seek head, which need a seekable output
+	return append(args, "-live", "1", "-f", "matroska", "pipe:1")
+}
+
 func appendStreamingOutputArgs(args []string, movflags string) []string {
 	flags := strings.TrimSpace(movflags)
 	if flags == "" {
@@ -1536,6 +1730,79 @@ func detectDolbyVision(stream *ffprobeStream) (hasDV bool, dvProfile string, hdr
 	return false, "", ""
 }
 
+// ffprobeFrameSampleArgs makes ffprobe also decode the first few packets so
+// per-frame side data (HDR10+ dynamic metadata is carried in SEI, not in the
+// stream header) shows up in the output.
+var ffprobeFrameSampleArgs = []string{"-show_frames", "-read_intervals", "%+#16"}
+
+// mayCarryHDR10Plus reports whether only sampled frames can tell if a stream is
+// HDR10+: the stream is PQ (HDR10+ requires it) and its header doesn't already
+// carry HDR10+ side data.
+func mayCarryHDR10Plus(stream *ffprobeStream) bool {
+	if stream == nil || !strings.EqualFold(strings.TrimSpace(stream.ColorTransfer), "smpte2084") {
+		return false
+	}
+	return !detectHDR10Plus(stream, nil)
+}
+
+// sampleVideoFrames decodes the first few frames of the first video stream of a
+// seekable input, for the per-frame side data detectHDR10Plus looks at.
+func sampleVideoFrames(ctx context.Context, ffprobePath, input string) ([]ffprobeFrame, error) {
+	args := []string{
+		"-v", "error",
+		"-print_format", "json",
+		"-select_streams", "v:0",
+	}
+	args = append(args, ffprobeFrameSampleArgs...)
+	args = append(args, "-i", input)
+
+	output, err := sandbox.CommandContext(ctx, sandbox.ToolFFprobe, ffprobePath, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe frame sample: %w", err)
+	}
+	var parsed struct {
+		Frames []ffprobeFrame `json:"frames"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parse ffprobe frame sample: %w", err)
+	}
+	return parsed.Frames, nil
+}
+
+// detectHDR10Plus reports whether a video stream carries HDR10+ dynamic metadata
+// (SMPTE ST 2094-40), looking at stream-level side data first and then at the
+// side data of its sampled frames.
+func detectHDR10Plus(stream *ffprobeStream, frames []ffprobeFrame) bool {
+	if stream == nil {
+		return false
+	}
+
+	for _, sd := range stream.SideDataList {
+		if isHDR10PlusSideData(sd.SideDataType) {
+			return true
+		}
+	}
+
+	for _, frame := range frames {
+		if frame.StreamIndex != stream.Index {
+			continue
+		}
+		for _, sd := range frame.SideDataList {
+			if isHDR10PlusSideData(sd.SideDataType) {
+				log.Printf("[video] HDR10+ dynamic metadata detected on stream %d: %s", stream.Index, sd.SideDataType)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHDR10PlusSideData matches ffprobe's "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)" side data type
+func isHDR10PlusSideData(sideDataType string) bool {
+	sdType := strings.ToLower(strings.TrimSpace(sideDataType))
+	return strings.Contains(sdType, "2094-40") || strings.Contains(sdType, "hdr10+")
+}
+
 func isDolbyVisionProfile7(profile string) bool {
 	profile = strings.ToLower(strings.TrimSpace(profile))
 	if profile == "" {
@@ -1579,6 +1846,10 @@ func shouldIncludeEmptyMoov(plan audioPlan) bool {
 }
 
 func (h *VideoHandler) runFFProbe(ctx context.Context, inputSpecifier string, reader io.Reader) (*ffprobeOutput, error) {
+	return h.runFFProbeWith(ctx, inputSpecifier, reader, defaultFFprobeOptions)
+}
+
+func (h *VideoHandler) runFFProbeWith(ctx context.Context, inputSpecifier string, reader io.Reader, opts ffprobeOptions) (*ffprobeOutput, error) {
 	if h.ffprobePath == "" {
 		return nil, errors.New("ffprobe not configured")
 	}
@@ -1592,21 +1863,25 @@ func (h *VideoHandler) runFFProbe(ctx context.Context, inputSpecifier string, re
 	probeCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Frames are only sampled in a second pass, once the header shows a stream
+	// that may be HDR10+. Piped input can't be read twice, so it samples inline.
+	headerOpts := opts
+	headerOpts.sampleFrames = opts.sampleFrames && reader != nil
+
 	args := []string{
 		"-v", "error",
-		"-probesize", "1000000",      // 1MB (faster startup)
-		"-analyzeduration", "500000", // 0.5s (faster startup)
 		"-print_format", "json",
 		"-show_streams",
 		"-show_format",
 	}
+	args = append(args, headerOpts.args()...)
 	if reader != nil {
 		args = append(args, "-i", "pipe:0")
 	} else {
 		args = append(args, "-i", inputSpecifier)
 	}
 
-	cmd := exec.CommandContext(probeCtx, h.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, h.ffprobePath, args...)
 	if reader != nil {
 		cmd.Stdin = reader
 	}
@@ -1631,6 +1906,22 @@ func (h *VideoHandler) runFFProbe(ctx context.Context, inputSpecifier string, re
 	if err := json.Unmarshal(stdout.Bytes(), &parsed); err != nil {
 		return nil, fmt.Errorf("parse ffprobe output: %w", err)
 	}
+
+	if opts.sampleFrames && reader == nil {
+		for i := range parsed.Streams {
+			if parsed.Streams[i].CodecType != "video" {
+				continue
+			}
+			if mayCarryHDR10Plus(&parsed.Streams[i]) {
+				frames, err := sampleVideoFrames(probeCtx, h.ffprobePath, inputSpecifier)
+				if err != nil {
+					log.Printf("[video] %v", err)
+				}
+				parsed.Frames = frames
+			}
+			break
+		}
+	}
 	return &parsed, nil
 }
 
@@ -1674,6 +1965,7 @@ func composeMetadataResponse(meta *ffprobeOutput, sanitizedPath string, plan aud
 				Title:         normalizeTag(stream.Tags, "title"),
 				Disposition:   stream.Disposition,
 			}
+			summary.AudioDescription = accessibility.IsAudioDescription(stream.Disposition, summary.Title)
 			codec := strings.ToLower(strings.TrimSpace(stream.CodecName))
 			if _, ok := copyableAudioCodecs[codec]; ok {
 				summary.CopySupported = true
@@ -1682,6 +1974,10 @@ func composeMetadataResponse(meta *ffprobeOutput, sanitizedPath string, plan aud
 			resp.AudioStreams = append(resp.AudioStreams, summary)
 		case "video":
 			hasDV, dvProfile, hdrFormat := detectDolbyVision(stream)
+			hasHDR10Plus := detectHDR10Plus(stream, meta.Frames)
+			if hasHDR10Plus && hdrFormat == "HDR10" {
+				hdrFormat = "HDR10+"
+			}
 			summary := videoStreamSummary{
 				Index:              stream.Index,
 				CodecName:          strings.TrimSpace(stream.CodecName),
@@ -1695,6 +1991,7 @@ func composeMetadataResponse(meta *ffprobeOutput, sanitizedPath string, plan aud
 				HasDolbyVision:     hasDV,
 				DolbyVisionProfile: dvProfile,
 				HdrFormat:          hdrFormat,
+				HasHDR10Plus:       hasHDR10Plus,
 				ColorTransfer:      strings.TrimSpace(stream.ColorTransfer),
 				ColorPrimaries:     strings.TrimSpace(stream.ColorPrimaries),
 				ColorSpace:         strings.TrimSpace(stream.ColorSpace),
@@ -1723,6 +2020,7 @@ func composeMetadataResponse(meta *ffprobeOutput, sanitizedPath string, plan aud
 				Title:         normalizeTag(stream.Tags, "title"),
 				Disposition:   stream.Disposition,
 			}
+			summary.SDH = accessibility.IsSDH(stream.Disposition, summary.Title)
 			resp.SubtitleStreams = append(resp.SubtitleStreams, summary)
 		}
 	}
@@ -1850,6 +2148,16 @@ type transmuxPlan struct {
 type ffprobeOutput struct {
 	Streams []ffprobeStream `json:"streams"`
 	Format  ffprobeFormat   `json:"format"`
+	// Leading frames (see ffprobeFrameSampleArgs), used for per-frame side data such as HDR10+
+	Frames []ffprobeFrame `json:"frames"`
+}
+
+// ffprobeFrame is a decoded frame from -show_frames; only the fields needed to
+// spot dynamic HDR metadata are kept.
+type ffprobeFrame struct {
+	MediaType    string            `json:"media_type"`
+	StreamIndex  int               `json:"stream_index"`
+	SideDataList []ffprobeSideData `json:"side_data_list"`
 }
 
 type ffprobeStream struct {
@@ -1909,6 +2217,8 @@ type audioStreamSummary struct {
 	Title         string         `json:"title,omitempty"`
 	Disposition   map[string]int `json:"disposition,omitempty"`
 	CopySupported bool           `json:"copySupported"`
+	// AudioDescription marks audio-description (AD) tracks for the visually impaired
+	AudioDescription bool `json:"audioDescription,omitempty"`
 }
 
 type videoStreamSummary struct {
@@ -1924,6 +2234,7 @@ type videoStreamSummary struct {
 	HasDolbyVision     bool   `json:"hasDolbyVision"`
 	DolbyVisionProfile string `json:"dolbyVisionProfile,omitempty"`
 	HdrFormat          string `json:"hdrFormat,omitempty"`
+	HasHDR10Plus       bool   `json:"hasHdr10Plus"`
 	// HDR color metadata for HDR10 detection
 	ColorTransfer  string `json:"colorTransfer,omitempty"`
 	ColorPrimaries string `json:"colorPrimaries,omitempty"`
@@ -1937,6 +2248,8 @@ type subtitleStreamSummary struct {
 	Language      string         `json:"language,omitempty"`
 	Title         string         `json:"title,omitempty"`
 	Disposition   map[string]int `json:"disposition,omitempty"`
+	// SDH marks subtitles for the deaf and hard of hearing
+	SDH bool `json:"sdh,omitempty"`
 }
 
 type videoMetadataResponse struct {
@@ -1959,44 +2272,59 @@ type videoMetadataResponse struct {
 	Notes                 []string                `json:"notes,omitempty"`
 }
 
-// cachedMetadataEntry stores a metadata response with expiration time
+// cachedMetadataEntry stores a metadata response with expiration time and
+// the fingerprint of the file it was probed from
 type cachedMetadataEntry struct {
-	response  *videoMetadataResponse
-	expiresAt time.Time
+	response    *videoMetadataResponse
+	fingerprint string
+	expiresAt   time.Time
 }
 
 // metadataCacheTTL is the TTL for cached metadata responses (2 hours, same as probe cache)
 const metadataCacheTTL = 2 * time.Hour
 
-// getCachedMetadata retrieves a cached metadata response if available and not expired
-func (h *VideoHandler) getCachedMetadata(path string) *videoMetadataResponse {
+// getCachedMetadata retrieves a cached metadata response if available and not
+// expired, dropping it if the file behind path has changed since the probe
+func (h *VideoHandler) getCachedMetadata(ctx context.Context, path string) *videoMetadataResponse {
 	h.metadataCacheMu.RLock()
-	defer h.metadataCacheMu.RUnlock()
-
 	entry, exists := h.metadataCache[path]
+	h.metadataCacheMu.RUnlock()
 	if !exists {
 		return nil
 	}
 	if time.Now().After(entry.expiresAt) {
 		return nil // Expired, will be cleaned up later
 	}
+	if entry.fingerprint != "" {
+		if current := h.fingerprints.get(ctx, path); fingerprintChanged(entry.fingerprint, current) {
+			log.Printf("[video] metadata cache invalidated for path: %s (source changed %s -> %s)", path, entry.fingerprint, current)
+			h.metadataCacheMu.Lock()
+			if h.metadataCache[path] == entry {
+				delete(h.metadataCache, path)
+			}
+			h.metadataCacheMu.Unlock()
+			return nil
+		}
+	}
 	return entry.response
 }
 
 // setCachedMetadata stores a metadata response in the cache
-func (h *VideoHandler) setCachedMetadata(path string, response *videoMetadataResponse) {
+func (h *VideoHandler) setCachedMetadata(ctx context.Context, path string, response *videoMetadataResponse) {
+	fingerprint := h.fingerprints.get(ctx, path)
+
 	h.metadataCacheMu.Lock()
 	defer h.metadataCacheMu.Unlock()
 
 	h.metadataCache[path] = &cachedMetadataEntry{
-		response:  response,
-		expiresAt: time.Now().Add(metadataCacheTTL),
+		response:    response,
+		fingerprint: fingerprint,
+		expiresAt:   time.Now().Add(metadataCacheTTL),
 	}
 	log.Printf("[video] metadata cached for path: %s (expires in %v)", path, metadataCacheTTL)
 }
 
 func (h *VideoHandler) writeCommonHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
 	w.Header().Set(
 		"Access-Control-Allow-Headers",
@@ -2082,6 +2410,8 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 	hasDV := r.URL.Query().Get("dv") == "true"
 	dvProfile := r.URL.Query().Get("dvProfile")
 	hasHDR := r.URL.Query().Get("hdr") == "true"
+	// Clients that can decode HDR10+ ask for passthrough rather than the HDR10 fallback
+	hdr10PlusPassthrough := r.URL.Query().Get("hdr10Plus") == "true"
 	forceAAC := r.URL.Query().Get("forceAAC") == "true"
 	// Check global setting for forced AAC transcoding (for Bluetooth compatibility)
 	if !forceAAC && h.configManager != nil {
@@ -2168,20 +2498,32 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 	// For warm start sessions, probe for the actual keyframe position FFmpeg will seek to BEFORE creating session
 	// This is critical because FFmpeg seeks to the nearest keyframe, not the exact requested time
 	// Both video and subtitles must start from the same keyframe position for sync
+	// Snapping to the preceding keyframe keeps copied video from starting mid-GOP;
+	// when no keyframe is found nearby, fall back to the one FFmpeg will seek to
 	transcodingOffset := startSeconds
 	if startSeconds > 0 {
-		keyframePos := h.hlsManager.probeKeyframePosition(r.Context(), cleanPath, startSeconds)
+		keyframePos, snapped := h.hlsManager.snapToKeyframe(r.Context(), cleanPath, startSeconds)
+		if !snapped {
+			keyframePos = h.hlsManager.probeKeyframePosition(r.Context(), cleanPath, startSeconds)
+		}
 		transcodingOffset = keyframePos
-		log.Printf("[video] warm start: probed keyframe position %.3fs (requested %.3fs, delta %.3fs)",
-			keyframePos, startSeconds, keyframePos-startSeconds)
+		log.Printf("[video] warm start: keyframe position %.3fs (requested %.3fs, delta %.3fs, snapped=%v)",
+			keyframePos, startSeconds, keyframePos-startSeconds, snapped)
 	}
 
 	log.Printf("[video] creating HLS session for path=%q dv=%v dvProfile=%q hdr=%v start=%.3fs transcodingOffset=%.3fs audioTrack=%d subtitleTrack=%d",
 		cleanPath, hasDV, dvProfile, hasHDR, startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex)
 
-	session, err := h.hlsManager.CreateSession(r.Context(), cleanPath, path, hasDV, dvProfile, hasHDR, forceAAC, startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex, profileID, profileName, getClientIP(r), "")
+	session, err := h.hlsManager.CreateSession(r.Context(), cleanPath, path, hasDV, dvProfile, hasHDR, hdr10PlusPassthrough, forceAAC, h.deviceVideoPolicy(clientID), startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex, profileID, profileName, utils.ClientIP(r), "")
 	if err != nil {
 		log.Printf("[video] failed to create HLS session: %v", err)
+		if writeDiskSpaceError(w, err) {
+			return
+		}
+		if errors.Is(err, ErrHDRExceedsDeviceCaps) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
 		http.Error(w, fmt.Sprintf("failed to create HLS session: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -2192,7 +2534,6 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 
 	// Return session ID, playlist URL, and duration (if available)
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	response := map[string]interface{}{
 		"sessionId":         session.ID,
@@ -2207,6 +2548,12 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 		response["duration"] = session.Duration
 	}
 
+	// Tell the client whether HDR10+ metadata is being passed through or dropped to HDR10
+	if session.HasHDR10Plus {
+		response["hasHdr10Plus"] = true
+		response["hdr10PlusPassthrough"] = session.HDR10PlusPassthrough
+	}
+
 	if session.Duration > 0 && session.StartOffset > 0 {
 		remaining := session.Duration - session.StartOffset
 		if remaining < 0 {
@@ -2241,23 +2588,57 @@ func (h *VideoHandler) StartLiveHLSSession(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// startAt=programStart plays the current program from its beginning out of
+	// the timeshift cache; timeshift=1 only buffers the channel so a later
+	// request can do so
+	query := r.URL.Query()
+	startAt := strings.TrimSpace(query.Get("startAt"))
+	if startAt != "" && startAt != liveStartProgram {
+		http.Error(w, "unsupported startAt value", http.StatusBadRequest)
+		return
+	}
+	var timeshiftReason string
+	if h.timeshift != nil && (startAt == liveStartProgram || query.Get("timeshift") == "1" || query.Get("timeshift") == "true") {
+		if err := h.timeshift.WatchChannel(liveURL); err != nil {
+			log.Printf("[video] failed to buffer live channel for timeshift: %v", err)
+		}
+	}
+	if startAt == liveStartProgram {
+		response, reason := h.programStartResponse(r, liveURL)
+		if response != nil {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				log.Printf("[video] failed to encode live timeshift response: %v", err)
+			}
+			return
+		}
+		timeshiftReason = reason
+	}
+
 	log.Printf("[video] creating live HLS session for URL: %s", liveURL)
 
 	session, err := h.hlsManager.CreateLiveSession(r.Context(), liveURL)
 	if err != nil {
 		log.Printf("[video] failed to create live HLS session: %v", err)
+		if writeDiskSpaceError(w, err) {
+			return
+		}
 		http.Error(w, fmt.Sprintf("failed to create live HLS session: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	response := map[string]interface{}{
 		"sessionId":   session.ID,
 		"playlistUrl": fmt.Sprintf("/video/hls/%s/stream.m3u8", session.ID),
 		"isLive":      true,
 	}
+	if timeshiftReason != "" {
+		// Started at the live edge instead
+		response["startAt"] = "live"
+		response["timeshiftUnavailable"] = timeshiftReason
+	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("[video] failed to encode live HLS session response: %v", err)
@@ -2281,6 +2662,13 @@ func (h *VideoHandler) ServeHLSPlaylist(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Keep the channel's timeshift buffer alive while it is played live
+	if h.timeshift != nil {
+		if session, ok := h.hlsManager.GetSession(sessionID); ok && session.IsLive {
+			h.timeshift.TouchTimeshift(session.OriginalPath)
+		}
+	}
+
 	h.hlsManager.ServePlaylist(w, r, sessionID)
 }
 
@@ -2339,6 +2727,43 @@ func (h *VideoHandler) KeepAliveHLSSession(w http.ResponseWriter, r *http.Reques
 	h.hlsManager.KeepAlive(w, r, sessionID)
 }
 
+// PauseHLSSession records that the client paused playback, letting the
+// transcode be suspended if the pause lasts
+func (h *VideoHandler) PauseHLSSession(w http.ResponseWriter, r *http.Request) {
+	if h.hlsManager == nil {
+		http.Error(w, "HLS not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	vars := mux.Vars(r)
+	sessionID := vars["sessionID"]
+
+	if sessionID == "" {
+		http.Error(w, "missing session ID", http.StatusBadRequest)
+		return
+	}
+
+	h.hlsManager.PausePlayback(w, r, sessionID)
+}
+
+// ResumeHLSSession records that the client resumed playback and resumes a suspended transcode
+func (h *VideoHandler) ResumeHLSSession(w http.ResponseWriter, r *http.Request) {
+	if h.hlsManager == nil {
+		http.Error(w, "HLS not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	vars := mux.Vars(r)
+	sessionID := vars["sessionID"]
+
+	if sessionID == "" {
+		http.Error(w, "missing session ID", http.StatusBadRequest)
+		return
+	}
+
+	h.hlsManager.ResumePlayback(w, r, sessionID)
+}
+
 // GetHLSSessionStatus returns the current status of an HLS session
 // Used by the frontend to poll for errors during playback
 func (h *VideoHandler) GetHLSSessionStatus(w http.ResponseWriter, r *http.Request) {
@@ -2377,6 +2802,24 @@ func (h *VideoHandler) SeekHLSSession(w http.ResponseWriter, r *http.Request) {
 	h.hlsManager.Seek(w, r, sessionID)
 }
 
+// AdjustHLSQuality switches an HLS session to a different quality rung mid-playback
+func (h *VideoHandler) AdjustHLSQuality(w http.ResponseWriter, r *http.Request) {
+	if h.hlsManager == nil {
+		http.Error(w, "HLS not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	vars := mux.Vars(r)
+	sessionID := vars["sessionID"]
+
+	if sessionID == "" {
+		http.Error(w, "missing session ID", http.StatusBadRequest)
+		return
+	}
+
+	h.hlsManager.AdjustQuality(w, r, sessionID)
+}
+
 // Shutdown gracefully shuts down the video handler and cleans up resources
 func (h *VideoHandler) Shutdown() {
 	if h.hlsManager != nil {
@@ -2442,7 +2885,7 @@ func (h *VideoHandler) GetSubtitleExtractManager() *SubtitleExtractManager {
 
 // CreateHLSSession implements the HLSCreator interface for prequeue.
 // This creates an HLS session for HDR content so the frontend can use native player.
-func (h *VideoHandler) CreateHLSSession(ctx context.Context, path string, hasDV bool, dvProfile string, hasHDR bool, audioTrackIndex int, subtitleTrackIndex int, profileID string, startOffset float64, prequeueType string) (*HLSSessionResult, error) {
+func (h *VideoHandler) CreateHLSSession(ctx context.Context, path string, hasDV bool, dvProfile string, hasHDR bool, hdr10PlusPassthrough bool, audioTrackIndex int, subtitleTrackIndex int, profileID string, startOffset float64, prequeueType string) (*HLSSessionResult, error) {
 	if h == nil {
 		return nil, errors.New("video handler is nil")
 	}
@@ -2450,7 +2893,7 @@ func (h *VideoHandler) CreateHLSSession(ctx context.Context, path string, hasDV
 		return nil, errors.New("HLS manager not configured")
 	}
 
-	log.Printf("[video] CreateHLSSession: creating session for path=%q hasDV=%v dvProfile=%s hasHDR=%v audioTrack=%d subtitleTrack=%d startOffset=%.2f", path, hasDV, dvProfile, hasHDR, audioTrackIndex, subtitleTrackIndex, startOffset)
+	log.Printf("[video] CreateHLSSession: creating session for path=%q hasDV=%v dvProfile=%s hasHDR=%v hdr10Plus=%v audioTrack=%d subtitleTrack=%d startOffset=%.2f", path, hasDV, dvProfile, hasHDR, hdr10PlusPassthrough, audioTrackIndex, subtitleTrackIndex, startOffset)
 
 	// Check HDR/DV policy and handle DV stripping
 	if hasDV && dvProfile != "" {
@@ -2472,7 +2915,7 @@ func (h *VideoHandler) CreateHLSSession(ctx context.Context, path string, hasDV
 		}
 	}
 
-	session, err := h.hlsManager.CreateSession(ctx, path, path, hasDV, dvProfile, hasHDR, false, startOffset, 0, audioTrackIndex, subtitleTrackIndex, profileID, "", "", prequeueType)
+	session, err := h.hlsManager.CreateSession(ctx, path, path, hasDV, dvProfile, hasHDR, hdr10PlusPassthrough, false, HLSVideoPolicy{}, startOffset, 0, audioTrackIndex, subtitleTrackIndex, profileID, "", "", prequeueType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HLS session: %w", err)
 	}
@@ -2559,6 +3002,7 @@ func (h *VideoHandler) ProbeVideoPath(ctx context.Context, path string) (*VideoP
 		result.HasHDR10 = true
 		log.Printf("[video] ProbeVideoPath: HDR10 detected (PQ + BT.2020)")
 	}
+	result.HasHDR10Plus = detectHDR10Plus(stream, meta.Frames)
 
 	if result.HasDolbyVision {
 		log.Printf("[video] ProbeVideoPath: Dolby Vision detected, profile=%s", result.DolbyVisionProfile)
@@ -2627,9 +3071,10 @@ func (h *VideoHandler) ProbeVideoMetadata(ctx context.Context, path string) (*Vi
 		switch codecType {
 		case "audio":
 			info := AudioStreamInfo{
-				Index:    stream.Index,
-				Language: normalizeTag(stream.Tags, "language"),
-				Title:    normalizeTag(stream.Tags, "title"),
+				Index:              stream.Index,
+				Language:           normalizeTag(stream.Tags, "language"),
+				Title:              normalizeTag(stream.Tags, "title"),
+				IsAudioDescription: accessibility.IsAudioDescription(stream.Disposition, normalizeTag(stream.Tags, "title")),
 			}
 			result.AudioStreams = append(result.AudioStreams, info)
 
@@ -2664,6 +3109,7 @@ func (h *VideoHandler) ProbeVideoMetadata(ctx context.Context, path string) (*Vi
 				Title:     normalizeTag(stream.Tags, "title"),
 				IsForced:  isForced,
 				IsDefault: isDefault,
+				IsSDH:     accessibility.IsSDH(stream.Disposition, normalizeTag(stream.Tags, "title")),
 			}
 			result.SubtitleStreams = append(result.SubtitleStreams, info)
 		}
@@ -2695,7 +3141,7 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 
 	// Check shared cache first (via HLSManager)
 	if h.hlsManager != nil {
-		if cached := h.hlsManager.GetCachedProbe(cleanPath); cached != nil {
+		if cached := h.hlsManager.GetCachedProbe(ctx, cleanPath); cached != nil {
 			log.Printf("[video] ProbeVideoFull: using cached probe for path=%q", cleanPath)
 			return h.unifiedProbeToVideoFull(cached), nil
 		}
@@ -2712,6 +3158,7 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 			if !errors.Is(err, context.Canceled) {
 				log.Printf("[video] ProbeVideoFull: ffprobe external URL failed for %q: %v", cleanPath, err)
 			}
+			recordMediaFailure(h.failureRecorder, models.MediaFailureStageProbe, cleanPath, "", err)
 			return nil, err
 		}
 		meta = m
@@ -2721,6 +3168,7 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 			if !errors.Is(err, context.Canceled) {
 				log.Printf("[video] ProbeVideoFull: ffprobe via provider failed for %q: %v", cleanPath, err)
 			}
+			recordMediaFailure(h.failureRecorder, models.MediaFailureStageProbe, cleanPath, "", err)
 			return nil, err
 		}
 		meta = m
@@ -2761,6 +3209,9 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 		if colorTransfer == "smpte2084" && colorPrimaries == "bt2020" {
 			result.HasHDR10 = true
 		}
+
+		// Detect HDR10+ dynamic metadata carried alongside the HDR10 base
+		result.HasHDR10Plus = detectHDR10Plus(stream, meta.Frames)
 	}
 
 	// Extract audio and subtitle stream info
@@ -2772,10 +3223,11 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 		case "audio":
 			codec := strings.ToLower(strings.TrimSpace(s.CodecName))
 			info := AudioStreamInfo{
-				Index:    s.Index,
-				Codec:    codec,
-				Language: normalizeTag(s.Tags, "language"),
-				Title:    normalizeTag(s.Tags, "title"),
+				Index:              s.Index,
+				Codec:              codec,
+				Language:           normalizeTag(s.Tags, "language"),
+				Title:              normalizeTag(s.Tags, "title"),
+				IsAudioDescription: accessibility.IsAudioDescription(s.Disposition, normalizeTag(s.Tags, "title")),
 			}
 			result.AudioStreams = append(result.AudioStreams, info)
 
@@ -2820,19 +3272,20 @@ func (h *VideoHandler) ProbeVideoFull(ctx context.Context, path string) (*VideoF
 				Title:     normalizeTag(s.Tags, "title"),
 				IsForced:  isForced,
 				IsDefault: isDefault,
+				IsSDH:     accessibility.IsSDH(s.Disposition, normalizeTag(s.Tags, "title")),
 			}
 			result.SubtitleStreams = append(result.SubtitleStreams, info)
 		}
 	}
 
-	log.Printf("[video] ProbeVideoFull: DV=%v HDR10=%v dvProfile=%q TrueHD=%v compatAudio=%v audioStreams=%d subStreams=%d videoCodec=%s",
-		result.HasDolbyVision, result.HasHDR10, result.DolbyVisionProfile,
+	log.Printf("[video] ProbeVideoFull: DV=%v HDR10=%v HDR10+=%v dvProfile=%q TrueHD=%v compatAudio=%v audioStreams=%d subStreams=%d videoCodec=%s",
+		result.HasDolbyVision, result.HasHDR10, result.HasHDR10Plus, result.DolbyVisionProfile,
 		result.HasTrueHD, result.HasCompatibleAudio,
 		len(result.AudioStreams), len(result.SubtitleStreams), result.VideoCodec)
 
 	// Cache the result for shared use between prequeue and HLS
 	if h.hlsManager != nil {
-		h.hlsManager.CacheProbe(cleanPath, h.videoFullToUnifiedProbe(result))
+		h.hlsManager.CacheProbe(ctx, cleanPath, h.videoFullToUnifiedProbe(result))
 	}
 
 	return result, nil
@@ -2845,6 +3298,7 @@ func (h *VideoHandler) unifiedProbeToVideoFull(cached *UnifiedProbeResult) *Vide
 		VideoCodec:         cached.VideoCodec,
 		HasDolbyVision:     cached.HasDolbyVision,
 		HasHDR10:           cached.HasHDR10,
+		HasHDR10Plus:       cached.HasHDR10Plus,
 		DolbyVisionProfile: cached.DolbyVisionProfile,
 		HasTrueHD:          cached.HasTrueHD,
 		HasCompatibleAudio: cached.HasCompatibleAudio,
@@ -2855,10 +3309,11 @@ func (h *VideoHandler) unifiedProbeToVideoFull(cached *UnifiedProbeResult) *Vide
 	// Convert audio streams
 	for _, as := range cached.AudioStreams {
 		result.AudioStreams = append(result.AudioStreams, AudioStreamInfo{
-			Index:    as.Index,
-			Codec:    as.Codec,
-			Language: as.Language,
-			Title:    as.Title,
+			Index:              as.Index,
+			Codec:              as.Codec,
+			Language:           as.Language,
+			Title:              as.Title,
+			IsAudioDescription: as.IsAudioDescription,
 		})
 	}
 
@@ -2871,6 +3326,7 @@ func (h *VideoHandler) unifiedProbeToVideoFull(cached *UnifiedProbeResult) *Vide
 			Title:     ss.Title,
 			IsForced:  ss.IsForced,
 			IsDefault: ss.IsDefault,
+			IsSDH:     ss.IsSDH,
 		})
 	}
 
@@ -2884,6 +3340,7 @@ func (h *VideoHandler) videoFullToUnifiedProbe(result *VideoFullResult) *Unified
 		VideoCodec:         result.VideoCodec,
 		HasDolbyVision:     result.HasDolbyVision,
 		HasHDR10:           result.HasHDR10,
+		HasHDR10Plus:       result.HasHDR10Plus,
 		DolbyVisionProfile: result.DolbyVisionProfile,
 		HasTrueHD:          result.HasTrueHD,
 		HasCompatibleAudio: result.HasCompatibleAudio,
@@ -2894,10 +3351,11 @@ func (h *VideoHandler) videoFullToUnifiedProbe(result *VideoFullResult) *Unified
 	// Convert audio streams
 	for _, as := range result.AudioStreams {
 		cached.AudioStreams = append(cached.AudioStreams, audioStreamInfo{
-			Index:    as.Index,
-			Codec:    as.Codec,
-			Language: as.Language,
-			Title:    as.Title,
+			Index:              as.Index,
+			Codec:              as.Codec,
+			Language:           as.Language,
+			Title:              as.Title,
+			IsAudioDescription: as.IsAudioDescription,
 		})
 	}
 
@@ -2910,6 +3368,7 @@ func (h *VideoHandler) videoFullToUnifiedProbe(result *VideoFullResult) *Unified
 			Title:     ss.Title,
 			IsForced:  ss.IsForced,
 			IsDefault: ss.IsDefault,
+			IsSDH:     ss.IsSDH,
 		})
 	}
 
@@ -2971,36 +3430,57 @@ func (h *VideoHandler) proxyExternalURL(w http.ResponseWriter, r *http.Request,
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Minute)
 	defer cancel()
 
-	proxyReq, err := http.NewRequestWithContext(ctx, r.Method, cleanURL, nil)
-	if err != nil {
-		http.Error(w, "failed to create proxy request", http.StatusInternalServerError)
-		return true, fmt.Errorf("create proxy request: %w", err)
-	}
-
 	// Forward range header for seeking support
 	rangeHeader := r.Header.Get("Range")
 	if rangeHeader != "" {
-		proxyReq.Header.Set("Range", rangeHeader)
 		log.Printf("[video] external proxy: forwarding range header: %s", rangeHeader)
 	}
 
-	// Add minimal headers - some servers are picky about extra headers
-	// Using a simple user agent that looks like a video player
-	proxyReq.Header.Set("User-Agent", "VLC/3.0.18 LibVLC/3.0.18")
-	proxyReq.Header.Set("Accept", "*/*")
-	proxyReq.Header.Set("Accept-Encoding", "identity") // Don't accept compression for video streaming
+	doRequest := func(ctx context.Context, method, rangeHeader string) (*http.Response, error) {
+		proxyReq, err := http.NewRequestWithContext(ctx, method, cleanURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create proxy request: %w", err)
+		}
+		if rangeHeader != "" {
+			proxyReq.Header.Set("Range", rangeHeader)
+		}
+
+		// Add minimal headers - some servers are picky about extra headers
+		// Using a simple user agent that looks like a video player
+		proxyReq.Header.Set("User-Agent", "VLC/3.0.18 LibVLC/3.0.18")
+		proxyReq.Header.Set("Accept", "*/*")
+		proxyReq.Header.Set("Accept-Encoding", "identity") // Don't accept compression for video streaming
 
-	// Log request details for debugging
-	log.Printf("[video] external proxy request: method=%s host=%s path=%s", proxyReq.Method, proxyReq.URL.Host, proxyReq.URL.Path)
+		// Log request details for debugging
+		log.Printf("[video] external proxy request: method=%s host=%s path=%s", proxyReq.Method, proxyReq.URL.Host, proxyReq.URL.Path)
+
+		resp, err := client.Do(proxyReq)
+		if err != nil {
+			return nil, fmt.Errorf("external request: %w", err)
+		}
+
+		// Debrid links behind addon URLs expire; requesting the addon URL again
+		// makes it re-unrestrict and redirect to a fresh link.
+		if streaming.IsLinkExpiredStatus(resp.StatusCode) {
+			resp.Body.Close()
+			log.Printf("[video] external proxy: link expired (%s), re-resolving %s", resp.Status, parsedURL.Host)
+			retryReq := proxyReq.Clone(ctx)
+			resp, err = client.Do(retryReq)
+			if err != nil {
+				return nil, fmt.Errorf("external request: %w", err)
+			}
+		}
+		return resp, nil
+	}
 
 	// Make the request
-	resp, err := client.Do(proxyReq)
+	resp, err := doRequest(ctx, r.Method, rangeHeader)
 	if err != nil {
 		log.Printf("[video] external proxy request failed: %v", err)
 		http.Error(w, "failed to fetch external stream", http.StatusBadGateway)
-		return true, fmt.Errorf("external request: %w", err)
+		return true, err
 	}
-	defer resp.Body.Close()
+	defer func() { resp.Body.Close() }()
 
 	// Log response details
 	contentLength := resp.Header.Get("Content-Length")
@@ -3067,6 +3547,21 @@ func (h *VideoHandler) proxyExternalURL(w http.ResponseWriter, r *http.Request,
 		return true, nil
 	}
 
+	// If the upstream connection drops mid-stream (e.g. the debrid link expired),
+	// reopen the addon URL at the byte offset we stopped at.
+	start, end := streaming.ResponseByteRange(resp)
+	resp.Body = streaming.NewResumingBody(ctx, resp.Body, start, end, func(ctx context.Context, offset, end int64) (io.ReadCloser, error) {
+		resumed, err := doRequest(ctx, http.MethodGet, streaming.RangeHeader(offset, end))
+		if err != nil {
+			return nil, err
+		}
+		if resumed.StatusCode != http.StatusPartialContent {
+			resumed.Body.Close()
+			return nil, fmt.Errorf("external stream returned %d on resume", resumed.StatusCode)
+		}
+		return resumed.Body, nil
+	})
+
 	// Track this stream for admin monitoring
 	tracker := GetStreamTracker()
 	var expectedLength int64
@@ -3165,6 +3660,10 @@ func (h *VideoHandler) GetDirectURL(w http.ResponseWriter, r *http.Request) {
 	// Check if provider supports direct URLs
 	directProvider, ok := h.streamer.(streaming.DirectURLProvider)
 	if !ok {
+		if proxied := proxiedStreamURL(r, path); proxied != "" {
+			writeDirectURL(w, proxied)
+			return
+		}
 		http.Error(w, "direct URL not supported for this path", http.StatusNotImplemented)
 		return
 	}
@@ -3172,6 +3671,12 @@ func (h *VideoHandler) GetDirectURL(w http.ResponseWriter, r *http.Request) {
 	directURL, err := directProvider.GetDirectURL(r.Context(), path)
 	if err != nil {
 		if err == streaming.ErrNotFound {
+			// Usenet and local paths have no upstream URL; with a base URL
+			// configured, hand out our own stream endpoint instead
+			if proxied := proxiedStreamURL(r, path); proxied != "" {
+				writeDirectURL(w, proxied)
+				return
+			}
 			http.Error(w, "path not found", http.StatusNotFound)
 			return
 		}
@@ -3181,13 +3686,36 @@ func (h *VideoHandler) GetDirectURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("[video] GetDirectURL: path=%q -> %q", path, directURL)
+	writeDirectURL(w, directURL)
+}
 
+func writeDirectURL(w http.ResponseWriter, directURL string) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"url": directURL,
 	})
 }
 
+// proxiedStreamURL returns an absolute URL to this server's stream endpoint for
+// path, carrying the caller's token, or "" when no base URL is configured.
+func proxiedStreamURL(r *http.Request, path string) string {
+	base := utils.ConfiguredBaseURL()
+	if base == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("path", path)
+	token := r.URL.Query().Get("token")
+	if authHeader := r.Header.Get("Authorization"); token == "" && strings.HasPrefix(authHeader, "Bearer ") {
+		token = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	if token != "" {
+		query.Set("token", token)
+	}
+	return base + "/api/video/stream?" + query.Encode()
+}
+
 // getHDRDVPolicy returns the effective HDR/DV policy for a user/client
 // Priority: client settings > user settings > global settings > default
 func (h *VideoHandler) getHDRDVPolicy(userID, clientID string) models.HDRDVPolicy {
@@ -3226,6 +3754,29 @@ func (h *VideoHandler) getHDRDVPolicy(userID, clientID string) models.HDRDVPolic
 	return policy
 }
 
+// deviceVideoPolicy returns the registered device's video overrides for HLS sessions
+func (h *VideoHandler) deviceVideoPolicy(clientID string) HLSVideoPolicy {
+	if h.deviceSvc == nil || strings.TrimSpace(clientID) == "" {
+		return HLSVideoPolicy{}
+	}
+	device, err := h.deviceSvc.Get(clientID)
+	if err != nil || device == nil {
+		return HLSVideoPolicy{}
+	}
+
+	policy := HLSVideoPolicy{
+		ForceTranscode: device.TranscodePolicy == models.DeviceTranscodeAlways,
+		NeverTranscode: device.TranscodePolicy == models.DeviceTranscodeNever,
+		MaxBitrateKbps: device.MaxBitrateKbps,
+		MaxHeight:      releasename.ParseResolution(device.MaxResolution),
+		SDROnly:        device.SDROnly,
+		VideoCodecs:    device.Capabilities.VideoCodecs,
+	}
+	log.Printf("[video] device %s video policy: transcode=%s maxBitrate=%dkbps maxHeight=%d codecs=%v",
+		device.ID, device.TranscodePolicy, device.MaxBitrateKbps, policy.MaxHeight, device.Capabilities.VideoCodecs)
+	return policy
+}
+
 // parseDVProfileNumber extracts the profile number from a DV profile string like "dvhe.05.06"
 func parseDVProfileNumber(dvProfile string) int {
 	parts := strings.Split(dvProfile, ".")
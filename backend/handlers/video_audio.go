@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/audio_downloads"
+	"novastream/services/streaming"
+	"novastream/utils/sandbox"
+)
+
+const (
+	audioOnlyFormatADTS = "aac"
+	audioOnlyFormatMP4  = "mp4"
+
+	defaultAudioOnlyBitrate = 128 // kbps
+	minAudioOnlyBitrate     = 32
+	maxAudioOnlyBitrate     = 320
+)
+
+// audioOnlyPlan describes the ffmpeg invocation for an audio-only stream.
+type audioOnlyPlan struct {
+	args        []string
+	contentType string
+	copyAudio   bool
+	duration    float64
+}
+
+// isAudioOnlyRequest reports whether the client asked for ?mode=audio.
+func isAudioOnlyRequest(r *http.Request) bool {
+	return strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("mode")), "audio")
+}
+
+// selectAudioOnlyStream picks the audio stream to keep: the requested absolute
+// stream index if it is an audio stream, else the default track, else the first.
+func selectAudioOnlyStream(streams []ffprobeStream, requested int) *ffprobeStream {
+	var first, def *ffprobeStream
+	for i := range streams {
+		stream := &streams[i]
+		if !strings.EqualFold(stream.CodecType, "audio") {
+			continue
+		}
+		if requested >= 0 && stream.Index == requested {
+			return stream
+		}
+		if first == nil {
+			first = stream
+		}
+		if def == nil && stream.Disposition["default"] > 0 {
+			def = stream
+		}
+	}
+	if def != nil {
+		return def
+	}
+	return first
+}
+
+// buildAudioOnlyPlan builds the ffmpeg arguments that strip everything but one
+// audio track from input. Stereo-or-less AAC is copied as-is; anything else is
+// downmixed to stereo AAC at bitrateKbps. A nil stream maps the first audio track.
+func buildAudioOnlyPlan(input string, stream *ffprobeStream, format string, bitrateKbps int, startSeconds float64) audioOnlyPlan {
+	args := []string{"-hide_banner", "-loglevel", "error", "-nostdin"}
+	if startSeconds > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(startSeconds, 'f', 3, 64))
+	}
+	args = append(args, "-i", input)
+
+	mapping := "0:a:0"
+	copyAudio := false
+	if stream != nil {
+		mapping = fmt.Sprintf("0:%d", stream.Index)
+		copyAudio = strings.EqualFold(stream.CodecName, "aac") && stream.Channels > 0 && stream.Channels <= 2
+	}
+	args = append(args, "-map", mapping, "-vn", "-sn", "-dn")
+
+	if copyAudio {
+		args = append(args, "-c:a", "copy")
+	} else {
+		args = append(args, "-c:a", "aac", "-ac", "2", "-b:a", fmt.Sprintf("%dk", bitrateKbps))
+	}
+
+	plan := audioOnlyPlan{copyAudio: copyAudio}
+	if format == audioOnlyFormatMP4 {
+		args = append(args,
+			"-movflags", "frag_keyframe+empty_moov+default_base_moof",
+			"-frag_duration", "2000000",
+			"-f", "mp4",
+		)
+		plan.contentType = "audio/mp4"
+	} else {
+		args = append(args, "-f", "adts")
+		plan.contentType = "audio/aac"
+	}
+	plan.args = append(args, "pipe:1")
+	return plan
+}
+
+// parseAudioOnlyBitrate reads the audioBitrate query parameter in kbps,
+// clamped to a sensible range.
+func parseAudioOnlyBitrate(raw string) int {
+	kbps, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), "k"))
+	if err != nil || kbps <= 0 {
+		return defaultAudioOnlyBitrate
+	}
+	if kbps < minAudioOnlyBitrate {
+		return minAudioOnlyBitrate
+	}
+	if kbps > maxAudioOnlyBitrate {
+		return maxAudioOnlyBitrate
+	}
+	return kbps
+}
+
+// streamAudioOnly serves a single audio track of cleanPath as ADTS AAC or
+// fragmented MP4 audio, for background listening at minimal bandwidth.
+// Query parameters: audioTrack (absolute stream index), format (aac|mp4),
+// audioBitrate (kbps) and startOffset (seconds).
+func (h *VideoHandler) streamAudioOnly(w http.ResponseWriter, r *http.Request, cleanPath string) {
+	if h.streamer == nil {
+		http.Error(w, "stream provider not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if h.ffmpegPath == "" {
+		http.Error(w, "ffmpeg is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	if format != audioOnlyFormatMP4 {
+		format = audioOnlyFormatADTS
+	}
+	requestedTrack := -1
+	if raw := strings.TrimSpace(query.Get("audioTrack")); raw != "" {
+		if idx, err := strconv.Atoi(raw); err == nil {
+			requestedTrack = idx
+		}
+	}
+	startSeconds := parseFloat(query.Get("startOffset"))
+	bitrate := parseAudioOnlyBitrate(query.Get("audioBitrate"))
+
+	// Seeking happens through startOffset; byte ranges don't map onto the output
+	r.Header.Del("Range")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Hour)
+	defer cancel()
+
+	var meta *ffprobeOutput
+	if h.ffprobePath != "" {
+		probe, err := h.runFFProbeFromProvider(ctx, cleanPath)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("[video] audio-only ffprobe failed for %q: %v", cleanPath, err)
+			}
+			recordMediaFailure(h.failureRecorder, models.MediaFailureStageProbe, cleanPath, "", err)
+		} else {
+			meta = probe
+		}
+	}
+
+	var selected *ffprobeStream
+	if meta != nil {
+		selected = selectAudioOnlyStream(meta.Streams, requestedTrack)
+		if selected == nil {
+			http.Error(w, "source has no audio track", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	plan := buildAudioOnlyPlan("pipe:0", selected, format, bitrate, startSeconds)
+	if meta != nil {
+		if duration := parseFloat(meta.Format.Duration); duration > startSeconds {
+			plan.duration = duration - startSeconds
+		}
+	}
+
+	writeHeaders := func() {
+		h.writeCommonHeaders(w)
+		w.Header().Set("Content-Type", plan.contentType)
+		w.Header().Set("Accept-Ranges", "none")
+		if plan.duration > 0 {
+			durationHeader := fmt.Sprintf("%.3f", plan.duration)
+			w.Header().Set("X-Content-Duration", durationHeader)
+			w.Header().Set("Content-Duration", durationHeader)
+		}
+	}
+
+	if r.Method == http.MethodHead {
+		writeHeaders()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	resp, err := h.streamer.Stream(ctx, streaming.Request{Path: cleanPath, Method: http.MethodGet})
+	if err != nil {
+		log.Printf("[video] audio-only provider stream failed for %q: %v", cleanPath, err)
+		http.Error(w, "failed to open stream", http.StatusBadGateway)
+		return
+	}
+	if resp.Body == nil {
+		resp.Close()
+		http.Error(w, "provider stream returned empty body", http.StatusBadGateway)
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer resp.Close()
+		buf := make([]byte, 128*1024)
+		_, copyErr := io.CopyBuffer(pw, resp.Body, buf)
+		_ = pw.CloseWithError(copyErr)
+	}()
+
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, h.ffmpegPath, plan.args...)
+	cmd.Stdin = pr
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = pr.CloseWithError(err)
+		http.Error(w, "failed to start audio pipeline", http.StatusInternalServerError)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		_ = pr.CloseWithError(err)
+		log.Printf("[video] audio-only ffmpeg start failed for %q: %v", cleanPath, err)
+		http.Error(w, "failed to start audio pipeline", http.StatusInternalServerError)
+		return
+	}
+	// Unblock the provider copy once ffmpeg stops reading
+	defer pr.Close()
+
+	log.Printf("[video] audio-only stream path=%q track=%d format=%s copy=%t bitrate=%dk start=%.3f",
+		cleanPath, requestedTrack, format, plan.copyAudio, bitrate, startSeconds)
+
+	writeHeaders()
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	var totalWritten int64
+	buf := make([]byte, 64*1024)
+	for {
+		n, readErr := stdout.Read(buf)
+		if n > 0 {
+			written, writeErr := w.Write(buf[:n])
+			totalWritten += int64(written)
+			if writeErr != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				if !isConnectionError(writeErr) {
+					log.Printf("[video] audio-only write failed path=%q total=%d: %v", cleanPath, totalWritten, writeErr)
+				}
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				_ = cmd.Process.Kill()
+			}
+			break
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		log.Printf("[video] audio-only ffmpeg exited for %q: %v (%s)", cleanPath, err, strings.TrimSpace(stderr.String()))
+		return
+	}
+	log.Printf("[video] audio-only stream complete path=%q bytes=%d", cleanPath, totalWritten)
+}
+
+var _ audio_downloads.Extractor = (*VideoHandler)(nil)
+
+// buildAudioExtractArgs builds the ffmpeg arguments that write one audio track
+// of input to output as M4A or Ogg Opus, reporting progress on stdout. Stereo
+// AAC (for M4A) and stereo Opus are copied; anything else is downmixed to
+// stereo at bitrateKbps. A nil stream maps the first audio track.
+func buildAudioExtractArgs(input string, stream *ffprobeStream, format string, bitrateKbps int, output string) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-nostdin", "-i", input}
+
+	mapping := "0:a:0"
+	codec := "aac"
+	if format == models.AudioDownloadFormatOpus {
+		codec = "opus"
+	}
+	copyAudio := false
+	if stream != nil {
+		mapping = fmt.Sprintf("0:%d", stream.Index)
+		copyAudio = strings.EqualFold(stream.CodecName, codec) && stream.Channels > 0 && stream.Channels <= 2
+	}
+	args = append(args, "-map", mapping, "-vn", "-sn", "-dn")
+
+	switch {
+	case copyAudio:
+		args = append(args, "-c:a", "copy")
+	case format == models.AudioDownloadFormatOpus:
+		args = append(args, "-c:a", "libopus", "-ac", "2", "-b:a", fmt.Sprintf("%dk", bitrateKbps))
+	default:
+		args = append(args, "-c:a", "aac", "-ac", "2", "-b:a", fmt.Sprintf("%dk", bitrateKbps))
+	}
+
+	// The output is written to a temporary name, so the muxer is explicit
+	if format == models.AudioDownloadFormatOpus {
+		args = append(args, "-f", "ogg")
+	} else {
+		args = append(args, "-movflags", "+faststart", "-f", "ipod")
+	}
+	return append(args, "-progress", "pipe:1", "-nostats", "-y", output)
+}
+
+// parseFFmpegProgressLine returns the output position in seconds from an
+// ffmpeg -progress line, or false for other lines.
+func parseFFmpegProgressLine(line string) (float64, bool) {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+	// out_time_ms is in microseconds too, despite its name
+	if !ok || (key != "out_time_us" && key != "out_time_ms") {
+		return 0, false
+	}
+	us, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || us < 0 {
+		return 0, false
+	}
+	return float64(us) / 1e6, true
+}
+
+// ExtractAudio writes one audio track of the stream at sourcePath to destPath
+// through the same provider and ffmpeg pipeline as audio-only streaming.
+// Progress is estimated from the probed duration; without ffprobe it is only
+// reported at the end.
+func (h *VideoHandler) ExtractAudio(ctx context.Context, sourcePath, destPath string, opts audio_downloads.ExtractOptions, progress func(float64)) error {
+	if h.streamer == nil {
+		return errors.New("stream provider not configured")
+	}
+	if h.ffmpegPath == "" {
+		return errors.New("ffmpeg is not configured")
+	}
+
+	cleanPath := sourcePath
+	if strings.HasPrefix(cleanPath, "/webdav/") {
+		cleanPath = strings.TrimPrefix(cleanPath, "/webdav")
+	} else if strings.HasPrefix(cleanPath, "webdav/") {
+		cleanPath = "/" + strings.TrimPrefix(cleanPath, "webdav/")
+	}
+
+	var selected *ffprobeStream
+	var duration float64
+	if h.ffprobePath != "" {
+		meta, err := h.runFFProbeFromProvider(ctx, cleanPath)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("[video] audio extract ffprobe failed for %q: %v", cleanPath, err)
+		} else {
+			selected = selectAudioOnlyStream(meta.Streams, opts.AudioTrack)
+			if selected == nil {
+				return errors.New("source has no audio track")
+			}
+			duration = parseFloat(meta.Format.Duration)
+		}
+	}
+
+	resp, err := h.streamer.Stream(ctx, streaming.Request{Path: cleanPath, Method: http.MethodGet})
+	if err != nil {
+		return fmt.Errorf("open stream: %w", err)
+	}
+	if resp.Body == nil {
+		resp.Close()
+		return errors.New("provider stream returned empty body")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer resp.Close()
+		buf := make([]byte, 128*1024)
+		_, copyErr := io.CopyBuffer(pw, resp.Body, buf)
+		_ = pw.CloseWithError(copyErr)
+	}()
+	// Unblock the provider copy once ffmpeg stops reading
+	defer pr.Close()
+
+	args := buildAudioExtractArgs("pipe:0", selected, opts.Format, opts.BitrateKbps, destPath)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, h.ffmpegPath, args...)
+	cmd.Stdin = pr
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("start audio pipeline: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start audio pipeline: %w", err)
+	}
+
+	log.Printf("[video] audio extract path=%q track=%d format=%s bitrate=%dk", cleanPath, opts.AudioTrack, opts.Format, opts.BitrateKbps)
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if position, ok := parseFFmpegProgressLine(scanner.Text()); ok && duration > 0 {
+			progress(position / duration)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 500 {
+			msg = msg[len(msg)-500:]
+		}
+		return fmt.Errorf("ffmpeg: %w: %s", err, msg)
+	}
+	progress(1)
+	return nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestSelectAudioOnlyStream(t *testing.T) {
+	streams := []ffprobeStream{
+		{Index: 0, CodecType: "video", CodecName: "hevc"},
+		{Index: 1, CodecType: "audio", CodecName: "truehd", Channels: 8},
+		{Index: 2, CodecType: "audio", CodecName: "aac", Channels: 2, Disposition: map[string]int{"default": 1}},
+		{Index: 3, CodecType: "subtitle", CodecName: "subrip"},
+	}
+
+	if got := selectAudioOnlyStream(streams, 1); got == nil || got.Index != 1 {
+		t.Fatalf("requested track: got %+v, want index 1", got)
+	}
+	if got := selectAudioOnlyStream(streams, 3); got == nil || got.Index != 2 {
+		t.Fatalf("non-audio request: got %+v, want default index 2", got)
+	}
+	if got := selectAudioOnlyStream(streams, -1); got == nil || got.Index != 2 {
+		t.Fatalf("no request: got %+v, want default index 2", got)
+	}
+	streams[2].Disposition = nil
+	if got := selectAudioOnlyStream(streams, -1); got == nil || got.Index != 1 {
+		t.Fatalf("no default: got %+v, want first audio index 1", got)
+	}
+	if got := selectAudioOnlyStream(streams[:1], -1); got != nil {
+		t.Fatalf("video only: got %+v, want nil", got)
+	}
+}
+
+func TestBuildAudioOnlyPlan(t *testing.T) {
+	stereoAAC := &ffprobeStream{Index: 2, CodecType: "audio", CodecName: "aac", Channels: 2}
+	plan := buildAudioOnlyPlan("pipe:0", stereoAAC, audioOnlyFormatADTS, 128, 0)
+	args := strings.Join(plan.args, " ")
+	if !plan.copyAudio || !strings.Contains(args, "-map 0:2 -vn -sn -dn -c:a copy") || !strings.HasSuffix(args, "-f adts pipe:1") {
+		t.Fatalf("unexpected ADTS copy args: %s", args)
+	}
+	if plan.contentType != "audio/aac" {
+		t.Fatalf("contentType = %q, want audio/aac", plan.contentType)
+	}
+
+	surround := &ffprobeStream{Index: 1, CodecType: "audio", CodecName: "eac3", Channels: 6}
+	plan = buildAudioOnlyPlan("pipe:0", surround, audioOnlyFormatMP4, 96, 61.5)
+	args = strings.Join(plan.args, " ")
+	if plan.copyAudio || !strings.Contains(args, "-c:a aac -ac 2 -b:a 96k") {
+		t.Fatalf("unexpected transcode args: %s", args)
+	}
+	if !strings.Contains(args, "-ss 61.500 -i pipe:0") || !strings.Contains(args, "empty_moov") || plan.contentType != "audio/mp4" {
+		t.Fatalf("unexpected fMP4 args: %s (%s)", args, plan.contentType)
+	}
+
+	if plan = buildAudioOnlyPlan("pipe:0", nil, audioOnlyFormatADTS, 128, 0); !strings.Contains(strings.Join(plan.args, " "), "-map 0:a:0") {
+		t.Fatalf("unprobed source should map first audio track: %v", plan.args)
+	}
+}
+
+func TestParseAudioOnlyBitrate(t *testing.T) {
+	cases := map[string]int{"": 128, "abc": 128, "192": 192, "64k": 64, "8": 32, "1000": 320}
+	for raw, want := range cases {
+		if got := parseAudioOnlyBitrate(raw); got != want {
+			t.Errorf("parseAudioOnlyBitrate(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
+func TestBuildAudioExtractArgs(t *testing.T) {
+	stereoAAC := &ffprobeStream{Index: 2, CodecType: "audio", CodecName: "aac", Channels: 2}
+	args := strings.Join(buildAudioExtractArgs("pipe:0", stereoAAC, models.AudioDownloadFormatM4A, 128, "/dl/a.m4a.part"), " ")
+	if !strings.Contains(args, "-map 0:2 -vn -sn -dn -c:a copy") || !strings.Contains(args, "-f ipod") {
+		t.Fatalf("unexpected m4a copy args: %s", args)
+	}
+	if !strings.HasSuffix(args, "-progress pipe:1 -nostats -y /dl/a.m4a.part") {
+		t.Fatalf("output not last: %s", args)
+	}
+
+	// AAC isn't copied into Opus; surround is downmixed
+	args = strings.Join(buildAudioExtractArgs("pipe:0", stereoAAC, models.AudioDownloadFormatOpus, 96, "out"), " ")
+	if !strings.Contains(args, "-c:a libopus -ac 2 -b:a 96k") || !strings.Contains(args, "-f ogg") {
+		t.Fatalf("unexpected opus args: %s", args)
+	}
+	args = strings.Join(buildAudioExtractArgs("pipe:0", nil, models.AudioDownloadFormatM4A, 160, "out"), " ")
+	if !strings.Contains(args, "-map 0:a:0") || !strings.Contains(args, "-c:a aac -ac 2 -b:a 160k") {
+		t.Fatalf("unexpected unprobed args: %s", args)
+	}
+}
+
+func TestParseFFmpegProgressLine(t *testing.T) {
+	if got, ok := parseFFmpegProgressLine("out_time_us=90500000\n"); !ok || got != 90.5 {
+		t.Fatalf("out_time_us: got %v, %v", got, ok)
+	}
+	if got, ok := parseFFmpegProgressLine("out_time_ms=1000000"); !ok || got != 1 {
+		t.Fatalf("out_time_ms: got %v, %v", got, ok)
+	}
+	for _, line := range []string{"out_time=00:01:30.500000", "progress=continue", "out_time_us=N/A", ""} {
+		if _, ok := parseFFmpegProgressLine(line); ok {
+			t.Errorf("%q should not parse", line)
+		}
+	}
+}
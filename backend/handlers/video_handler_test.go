@@ -216,6 +216,87 @@ func TestDetectDolbyVision(t *testing.T) {
 	}
 }
 
+// --- detectHDR10Plus tests ---
+
+func TestDetectHDR10Plus(t *testing.T) {
+	hdr10PlusSideData := []ffprobeSideData{{SideDataType: "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}}
+
+	tests := []struct {
+		name     string
+		stream   *ffprobeStream
+		frames   []ffprobeFrame
+		expected bool
+	}{
+		{
+			name:     "nil stream",
+			stream:   nil,
+			expected: false,
+		},
+		{
+			name:   "plain HDR10 frames",
+			stream: &ffprobeStream{Index: 0, CodecName: "hevc", ColorTransfer: "smpte2084"},
+			frames: []ffprobeFrame{
+				{MediaType: "video", StreamIndex: 0, SideDataList: []ffprobeSideData{{SideDataType: "Mastering display metadata"}}},
+			},
+			expected: false,
+		},
+		{
+			name:   "HDR10+ SEI on video frame",
+			stream: &ffprobeStream{Index: 0, CodecName: "hevc", ColorTransfer: "smpte2084"},
+			frames: []ffprobeFrame{
+				{MediaType: "audio", StreamIndex: 1},
+				{MediaType: "video", StreamIndex: 0, SideDataList: hdr10PlusSideData},
+			},
+			expected: true,
+		},
+		{
+			name:   "HDR10+ side data on a different stream",
+			stream: &ffprobeStream{Index: 0, CodecName: "hevc"},
+			frames: []ffprobeFrame{
+				{MediaType: "video", StreamIndex: 2, SideDataList: hdr10PlusSideData},
+			},
+			expected: false,
+		},
+		{
+			name:     "HDR10+ in stream side data",
+			stream:   &ffprobeStream{Index: 0, CodecName: "hevc", SideDataList: hdr10PlusSideData},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectHDR10Plus(tc.stream, tc.frames); got != tc.expected {
+				t.Errorf("detectHDR10Plus() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMayCarryHDR10Plus(t *testing.T) {
+	hdr10PlusSideData := []ffprobeSideData{{SideDataType: "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}}
+
+	tests := []struct {
+		name     string
+		stream   *ffprobeStream
+		expected bool
+	}{
+		{name: "nil stream", stream: nil, expected: false},
+		{name: "SDR stream", stream: &ffprobeStream{CodecName: "h264", ColorTransfer: "bt709"}, expected: false},
+		{name: "HLG stream", stream: &ffprobeStream{CodecName: "hevc", ColorTransfer: "arib-std-b67"}, expected: false},
+		{name: "PQ stream", stream: &ffprobeStream{CodecName: "hevc", ColorTransfer: "smpte2084"}, expected: true},
+		{name: "PQ stream with HDR10+ header", stream: &ffprobeStream{CodecName: "hevc", ColorTransfer: "smpte2084", SideDataList: hdr10PlusSideData}, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mayCarryHDR10Plus(tc.stream); got != tc.expected {
+				t.Errorf("mayCarryHDR10Plus() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
 // --- isDolbyVisionProfile7 tests ---
 
 func TestIsDolbyVisionProfile7(t *testing.T) {
@@ -901,10 +982,7 @@ func TestVideoHandler_HandleOptions(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
-	// Check CORS headers
-	if rr.Header().Get("Access-Control-Allow-Origin") != "*" {
-		t.Error("expected Access-Control-Allow-Origin: *")
-	}
+	// Check CORS headers (Allow-Origin comes from the router's CORS middleware)
 	if rr.Header().Get("Access-Control-Allow-Methods") == "" {
 		t.Error("expected Access-Control-Allow-Methods header")
 	}
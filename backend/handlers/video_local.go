@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"novastream/services/streaming"
+)
+
+// localStreamFile returns the on-disk path of a complete local copy of the
+// stream, when the provider has one.
+func (h *VideoHandler) localStreamFile(ctx context.Context, cleanPath string) (string, bool) {
+	localProvider, ok := h.streamer.(streaming.LocalFileProvider)
+	if !ok {
+		return "", false
+	}
+	return localProvider.LocalFile(ctx, cleanPath)
+}
+
+// serveLocalFile streams a complete local file with http.ServeContent, which
+// handles Range/If-Range/HEAD and lets the server sendfile the body instead of
+// copying it through a userspace buffer. Reports false if the file vanished or
+// is not a regular file, so the caller can fall back to the provider stream.
+func (h *VideoHandler) serveLocalFile(w http.ResponseWriter, r *http.Request, cleanPath, localPath string) bool {
+	file, err := os.Open(localPath)
+	if err != nil {
+		log.Printf("[video] local cache open failed path=%q local=%q err=%v", cleanPath, localPath, err)
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	log.Printf("[video] serving local cache hit path=%q local=%q size=%d range=%q method=%s",
+		cleanPath, localPath, info.Size(), r.Header.Get("Range"), r.Method)
+
+	h.writeCommonHeaders(w)
+	w.Header().Set("Content-Type", streaming.ContentTypeForPath(localPath))
+	w.Header().Set("X-Filename", filepath.Base(localPath))
+
+	if r.Method == http.MethodHead {
+		http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+		return true
+	}
+
+	tracker := GetStreamTracker()
+	streamID, bytesCounter := tracker.StartStream(r, cleanPath, info.Size(), 0, 0)
+	defer tracker.EndStream(streamID)
+
+	http.ServeContent(&countingResponseWriter{ResponseWriter: w, count: bytesCounter}, r, info.Name(), info.ModTime(), file)
+	return true
+}
+
+// countingResponseWriter tracks bytes written for stream monitoring. It keeps
+// the underlying writer's ReadFrom so copies from *os.File still use sendfile;
+// bytes sent that way are counted once the copy finishes.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	count *int64
+}
+
+func (c *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.add(int64(n))
+	return n, err
+}
+
+func (c *countingResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rf, ok := c.ResponseWriter.(io.ReaderFrom); ok {
+		n, err := rf.ReadFrom(src)
+		c.add(n)
+		return n, err
+	}
+	return io.Copy(struct{ io.Writer }{c}, src)
+}
+
+func (c *countingResponseWriter) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *countingResponseWriter) add(n int64) {
+	if c.count != nil && n > 0 {
+		atomic.AddInt64(c.count, n)
+	}
+}
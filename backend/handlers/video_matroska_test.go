@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestBuildMatroskaArgsCopiesAllStreams(t *testing.T) {
+	args := buildMatroskaArgs("pipe:0", "0:1", false)
+
+	for _, want := range []string{"0:1", "0:a?", "0:s?", "0:t?", "matroska", "pipe:1"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("expected %q in %v", want, args)
+		}
+	}
+	if slices.Contains(args, "mov_text") || slices.Contains(args, "aac") {
+		t.Errorf("expected every stream copied, got %v", args)
+	}
+
+	args = buildMatroskaArgs("pipe:0", "", true)
+	if !slices.Contains(args, "0:v:0") || !slices.Contains(args, "aac") {
+		t.Errorf("expected the default video map and AAC audio, got %v", args)
+	}
+}
+
+func TestTransmuxContainer(t *testing.T) {
+	h := NewVideoHandler(true, "", "")
+	h.SetDeviceService(fakeDeviceProvider{device: &models.Device{
+		ID:           "tv-1",
+		Capabilities: models.DeviceCapabilities{Containers: []string{"mkv", "mp4"}},
+	}})
+
+	tests := []struct {
+		name   string
+		url    string
+		client string
+		want   string
+	}{
+		{name: "default", url: "/video/stream?path=a.ts", want: transmuxContainerMP4},
+		{name: "target flag", url: "/video/stream?path=a.ts&target=mkv", want: transmuxContainerMatroska},
+		{name: "format flag", url: "/video/stream?path=a.ts&format=matroska", want: transmuxContainerMatroska},
+		{name: "device preference", url: "/video/stream?path=a.ts", client: "tv-1", want: transmuxContainerMatroska},
+		{name: "web target wins over device", url: "/video/stream?path=a.ts&target=web", client: "tv-1", want: transmuxContainerMP4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.client != "" {
+				req.Header.Set("X-Client-ID", tc.client)
+			}
+			if got := h.transmuxContainer(req); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestShouldTransmuxMatroskaTarget(t *testing.T) {
+	// Force transmux on so the result doesn't depend on ffmpeg being on PATH
+	h := NewVideoHandler(false, "", "")
+	h.transmux = true
+
+	tests := []struct {
+		name string
+		url  string
+		ext  string
+		want bool
+	}{
+		{name: "mkv source served directly", url: "/video/stream?path=a.mkv&target=mkv", ext: ".mkv", want: false},
+		{name: "forced mkv source", url: "/video/stream?path=a.mkv&target=mkv&transmux=force", ext: ".mkv", want: true},
+		{name: "mp4 source on request", url: "/video/stream?path=a.mp4&target=mkv", ext: ".mp4", want: true},
+		{name: "transport stream", url: "/video/stream?path=a.ts&target=mkv", ext: ".ts", want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			got, _, reason := h.shouldTransmux(req, "/a"+tc.ext, tc.ext, transmuxContainerMatroska)
+			if got != tc.want {
+				t.Errorf("expected transmux=%t, got %t (%s)", tc.want, got, reason)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"novastream/services/streaming"
@@ -55,3 +57,46 @@ func TestVideoHandlerStreamsFromMetadataProvider(t *testing.T) {
 		t.Fatalf("body = %q, want %q", body, data)
 	}
 }
+
+// localFileProvider serves every path from a single local file.
+type localFileProvider struct {
+	mockProvider
+	local string
+}
+
+func (p *localFileProvider) LocalFile(ctx context.Context, path string) (string, bool) {
+	return p.local, true
+}
+
+func TestVideoHandlerServesLocalCacheHitWithRanges(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "title.mkv")
+	if err := os.WriteFile(local, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The provider stream must not be used for a local cache hit
+	provider := &localFileProvider{mockProvider: mockProvider{data: []byte("wrong")}, local: local}
+	handler := NewVideoHandlerWithProvider(false, "", "", "", provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/video/stream?path=movies/title.mkv", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rr := httptest.NewRecorder()
+
+	handler.StreamVideo(rr, req)
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusPartialContent)
+	}
+	if got := res.Header.Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes 2-5/10")
+	}
+	if got := res.Header.Get("Content-Type"); got != "video/x-matroska" {
+		t.Errorf("Content-Type = %q, want video/x-matroska", got)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "2345" {
+		t.Fatalf("body = %q, want %q", body, "2345")
+	}
+}
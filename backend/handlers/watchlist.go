@@ -5,9 +5,11 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"novastream/models"
+	"novastream/services/availability"
 	"novastream/services/watchlist"
 
 	"github.com/gorilla/mux"
@@ -18,26 +20,45 @@ type watchlistService interface {
 	AddOrUpdate(userID string, input models.WatchlistUpsert) (models.WatchlistItem, error)
 	UpdateState(userID, mediaType, id string, watched *bool, progress interface{}) (models.WatchlistItem, error)
 	Remove(userID, mediaType, id string) (bool, error)
+	Share(ownerID, profileID string, permission models.WatchlistPermission) (models.WatchlistShare, error)
+	Unshare(ownerID, profileID string) (bool, error)
+	Shares(userID string) (models.WatchlistShares, error)
+	Access(profileID, ownerID string, edit bool) error
 }
 
 var _ watchlistService = (*watchlist.Service)(nil)
 
+type watchlistAvailabilityService interface {
+	Check(userID string, items []models.WatchlistItem, refresh bool) []models.WatchlistAvailability
+}
+
+var _ watchlistAvailabilityService = (*availability.Service)(nil)
+
 type userService interface {
 	Exists(id string) bool
 }
 
+// watchlistProfileLookup resolves a profile's household for watchlist sharing.
+type watchlistProfileLookup interface {
+	Get(id string) (models.User, bool)
+}
+
 type WatchlistHandler struct {
-	Service  watchlistService
-	Users    userService
-	DemoMode bool
+	Service             watchlistService
+	Users               userService
+	Profiles            watchlistProfileLookup
+	AvailabilityChecker watchlistAvailabilityService
+	DemoMode            bool
 }
 
 func NewWatchlistHandler(service watchlistService, users userService, demoMode bool) *WatchlistHandler {
 	return &WatchlistHandler{Service: service, Users: users, DemoMode: demoMode}
 }
 
+// List returns the profile's watchlist, or with ?owner= the watchlist another
+// profile shared with it.
 func (h *WatchlistHandler) List(w http.ResponseWriter, r *http.Request) {
-	userID, ok := h.requireUser(w, r)
+	userID, ok := h.requireWatchlist(w, r, false)
 	if !ok {
 		return
 	}
@@ -52,8 +73,48 @@ func (h *WatchlistHandler) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(items)
 }
 
+// SetProfileLookup enables watchlist sharing between profiles of a household.
+func (h *WatchlistHandler) SetProfileLookup(profiles watchlistProfileLookup) {
+	h.Profiles = profiles
+}
+
+// SetAvailabilityService enables the watchlist availability endpoint.
+func (h *WatchlistHandler) SetAvailabilityService(svc watchlistAvailabilityService) {
+	h.AvailabilityChecker = svc
+}
+
+// Availability reports which watchlist items currently have a healthy stream,
+// so clients can badge them as ready to watch. Results come from a cache that
+// is refreshed in the background; items still being checked are marked pending
+// and clients poll until none are. ?refresh=true forces every item to be rechecked.
+func (h *WatchlistHandler) Availability(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireWatchlist(w, r, false)
+	if !ok {
+		return
+	}
+
+	if h.AvailabilityChecker == nil {
+		http.Error(w, "availability checks unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	items, err := h.Service.List(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+	results := h.AvailabilityChecker.Check(userID, items, refresh)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// Add adds or updates an item. With ?owner= it changes a watchlist shared
+// with the profile for editing.
 func (h *WatchlistHandler) Add(w http.ResponseWriter, r *http.Request) {
-	userID, ok := h.requireUser(w, r)
+	userID, ok := h.requireWatchlist(w, r, true)
 	if !ok {
 		return
 	}
@@ -87,7 +148,7 @@ func (h *WatchlistHandler) Add(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WatchlistHandler) UpdateState(w http.ResponseWriter, r *http.Request) {
-	userID, ok := h.requireUser(w, r)
+	userID, ok := h.requireWatchlist(w, r, true)
 	if !ok {
 		return
 	}
@@ -127,7 +188,7 @@ func (h *WatchlistHandler) UpdateState(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WatchlistHandler) Remove(w http.ResponseWriter, r *http.Request) {
-	userID, ok := h.requireUser(w, r)
+	userID, ok := h.requireWatchlist(w, r, true)
 	if !ok {
 		return
 	}
@@ -157,6 +218,88 @@ func (h *WatchlistHandler) Remove(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Shares lists the profile's watchlist shares, granted and received.
+func (h *WatchlistHandler) Shares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	shares, err := h.Service.Shares(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shares)
+}
+
+// Share shares the profile's watchlist with another profile of the same
+// household, read-only or for editing.
+func (h *WatchlistHandler) Share(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Permission models.WatchlistPermission `json:"permission"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profileID := strings.TrimSpace(mux.Vars(r)["profileID"])
+	if !h.sameHousehold(userID, profileID) {
+		http.Error(w, "profile not found in this household", http.StatusNotFound)
+		return
+	}
+
+	share, err := h.Service.Share(userID, profileID, body.Permission)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, watchlist.ErrUserIDRequired),
+			errors.Is(err, watchlist.ErrProfileIDRequired),
+			errors.Is(err, watchlist.ErrShareWithSelf),
+			errors.Is(err, watchlist.ErrInvalidPermission):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(share)
+}
+
+// Unshare revokes another profile's access to the profile's watchlist.
+func (h *WatchlistHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := h.Service.Unshare(userID, mux.Vars(r)["profileID"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, watchlist.ErrUserIDRequired) || errors.Is(err, watchlist.ErrProfileIDRequired) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if !removed {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *WatchlistHandler) Options(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -176,3 +319,44 @@ func (h *WatchlistHandler) requireUser(w http.ResponseWriter, r *http.Request) (
 
 	return userID, true
 }
+
+// requireWatchlist resolves whose watchlist a request acts on: the profile's
+// own, or with ?owner= one shared with it. edit requires a collaborative share.
+func (h *WatchlistHandler) requireWatchlist(w http.ResponseWriter, r *http.Request, edit bool) (string, bool) {
+	userID, ok := h.requireUser(w, r)
+	if !ok {
+		return "", false
+	}
+
+	ownerID := strings.TrimSpace(r.URL.Query().Get("owner"))
+	if ownerID == "" || ownerID == userID {
+		return userID, true
+	}
+
+	if err := h.Service.Access(userID, ownerID, edit); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, watchlist.ErrNotShared):
+			status = http.StatusNotFound
+		case errors.Is(err, watchlist.ErrReadOnly):
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return "", false
+	}
+
+	return ownerID, true
+}
+
+// sameHousehold reports whether both profiles belong to the same account.
+func (h *WatchlistHandler) sameHousehold(userID, profileID string) bool {
+	if h.Profiles == nil || profileID == "" {
+		return false
+	}
+	owner, ok := h.Profiles.Get(userID)
+	if !ok {
+		return false
+	}
+	profile, ok := h.Profiles.Get(profileID)
+	return ok && profile.AccountID == owner.AccountID
+}
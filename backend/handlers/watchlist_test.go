@@ -131,3 +131,80 @@ func TestWatchlistUpdateAndRemove(t *testing.T) {
 		t.Fatalf("expected empty watchlist after removal, got %d", len(items))
 	}
 }
+
+func TestWatchlistSharing(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("failed to create watchlist service: %v", err)
+	}
+	userSvc, err := users.NewService(dir)
+	if err != nil {
+		t.Fatalf("failed to create users service: %v", err)
+	}
+	owner, err := userSvc.CreateForAccount("household", "Owner")
+	if err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+	reader, err := userSvc.CreateForAccount("household", "Reader")
+	if err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+	outsider, err := userSvc.CreateForAccount("elsewhere", "Outsider")
+	if err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+
+	h := handlers.NewWatchlistHandler(svc, userSvc, false)
+	h.SetProfileLookup(userSvc)
+	if _, err := svc.AddOrUpdate(owner.ID, models.WatchlistUpsert{ID: "m1", MediaType: "movie", Name: "Shared"}); err != nil {
+		t.Fatalf("failed to seed watchlist: %v", err)
+	}
+
+	share := func(profileID string) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/users/"+owner.ID+"/watchlist/shares/"+profileID, bytes.NewReader([]byte(`{"permission":"read"}`)))
+		req = mux.SetURLVars(req, map[string]string{"userID": owner.ID, "profileID": profileID})
+		rec := httptest.NewRecorder()
+		h.Share(rec, req)
+		return rec.Code
+	}
+	if code := share(outsider.ID); code != http.StatusNotFound {
+		t.Fatalf("expected sharing outside the household to fail with 404, got %d", code)
+	}
+	if code := share(reader.ID); code != http.StatusOK {
+		t.Fatalf("expected share status 200, got %d", code)
+	}
+
+	reqList := httptest.NewRequest(http.MethodGet, "/api/users/"+reader.ID+"/watchlist?owner="+owner.ID, nil)
+	reqList = mux.SetURLVars(reqList, map[string]string{"userID": reader.ID})
+	recList := httptest.NewRecorder()
+	h.List(recList, reqList)
+	var items []models.WatchlistItem
+	if err := json.Unmarshal(recList.Body.Bytes(), &items); err != nil {
+		t.Fatalf("failed to decode list response: %v", err)
+	}
+	if recList.Code != http.StatusOK || len(items) != 1 || items[0].Name != "Shared" {
+		t.Fatalf("expected the shared watchlist, got %d %+v", recList.Code, items)
+	}
+
+	payload, _ := json.Marshal(models.WatchlistUpsert{ID: "m2", MediaType: "movie", Name: "Not allowed"})
+	reqAdd := httptest.NewRequest(http.MethodPost, "/api/users/"+reader.ID+"/watchlist?owner="+owner.ID, bytes.NewReader(payload))
+	reqAdd = mux.SetURLVars(reqAdd, map[string]string{"userID": reader.ID})
+	recAdd := httptest.NewRecorder()
+	h.Add(recAdd, reqAdd)
+	if recAdd.Code != http.StatusForbidden {
+		t.Fatalf("expected a read-only share to reject edits with 403, got %d", recAdd.Code)
+	}
+
+	reqShares := httptest.NewRequest(http.MethodGet, "/api/users/"+reader.ID+"/watchlist/shares", nil)
+	reqShares = mux.SetURLVars(reqShares, map[string]string{"userID": reader.ID})
+	recShares := httptest.NewRecorder()
+	h.Shares(recShares, reqShares)
+	var shares models.WatchlistShares
+	if err := json.Unmarshal(recShares.Body.Bytes(), &shares); err != nil {
+		t.Fatalf("failed to decode shares response: %v", err)
+	}
+	if len(shares.Received) != 1 || shares.Received[0].OwnerID != owner.ID || shares.Received[0].Permission != models.WatchlistPermissionRead {
+		t.Fatalf("expected the received read share, got %+v", shares)
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Device is a registered playback device row. Capabilities holds the
+// JSON-encoded codec/HDR capabilities reported by the app.
+type Device struct {
+	ID              string
+	Name            string
+	Platform        string
+	AppVersion      string
+	Capabilities    string
+	ProfileID       string
+	AccountID       string // Account the device registered under, '' before ownership was tracked
+	TranscodePolicy string
+	MaxBitrateKbps  int
+	MaxResolution   string
+	SDROnly         bool
+	FirstSeen       time.Time
+	LastSeen        time.Time
+}
+
+// DeviceRepository handles the device registry
+type DeviceRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewDeviceRepository creates a new device repository
+func NewDeviceRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+const deviceColumns = `id, name, platform, app_version, capabilities, profile_id, account_id, transcode_policy, max_bitrate_kbps, max_resolution, sdr_only, first_seen, last_seen`
+
+// UpsertDevice registers a device or refreshes the details it reports.
+// Admin-managed fields (overrides, and name/profile once set) are kept on
+// refresh, as is the owning account once set.
+func (r *DeviceRepository) UpsertDevice(device *Device) error {
+	query := `
+		INSERT INTO devices (id, name, platform, app_version, capabilities, profile_id, account_id, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		ON CONFLICT(id) DO UPDATE SET
+		name = CASE WHEN devices.name = '' THEN excluded.name ELSE devices.name END,
+		platform = excluded.platform,
+		app_version = excluded.app_version,
+		capabilities = excluded.capabilities,
+		profile_id = CASE WHEN devices.profile_id = '' THEN excluded.profile_id ELSE devices.profile_id END,
+		account_id = CASE WHEN devices.account_id = '' THEN excluded.account_id ELSE devices.account_id END,
+		last_seen = datetime('now')
+	`
+
+	if _, err := r.db.Exec(query, device.ID, device.Name, device.Platform, device.AppVersion, device.Capabilities, device.ProfileID, device.AccountID); err != nil {
+		return fmt.Errorf("failed to upsert device: %w", err)
+	}
+
+	return nil
+}
+
+// GetDevice returns a device by ID, or nil if it is not registered
+func (r *DeviceRepository) GetDevice(id string) (*Device, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE id = ?`
+
+	device, err := scanDevice(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+
+	return device, nil
+}
+
+// ListDevices returns devices ordered by last seen, optionally filtered by assigned profile
+func (r *DeviceRepository) ListDevices(profileID string) ([]*Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM devices
+		WHERE (? = '' OR profile_id = ?)
+		ORDER BY last_seen DESC
+	`
+
+	rows, err := r.db.Query(query, profileID, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []*Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// UpdateDeviceSettings saves the admin-managed name, profile and overrides of device.
+// Returns false if the device is not registered.
+func (r *DeviceRepository) UpdateDeviceSettings(device *Device) (bool, error) {
+	query := `
+		UPDATE devices
+		SET name = ?, profile_id = ?, transcode_policy = ?, max_bitrate_kbps = ?, max_resolution = ?, sdr_only = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, device.Name, device.ProfileID, device.TranscodePolicy, device.MaxBitrateKbps,
+		device.MaxResolution, device.SDROnly, device.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update device: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteDevice removes a device. Returns false if it was not registered.
+func (r *DeviceRepository) DeleteDevice(id string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM devices WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete device: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func scanDevice(row interface {
+	Scan(dest ...interface{}) error
+}) (*Device, error) {
+	var device Device
+	if err := row.Scan(
+		&device.ID, &device.Name, &device.Platform, &device.AppVersion, &device.Capabilities,
+		&device.ProfileID, &device.AccountID, &device.TranscodePolicy, &device.MaxBitrateKbps,
+		&device.MaxResolution, &device.SDROnly, &device.FirstSeen, &device.LastSeen,
+	); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
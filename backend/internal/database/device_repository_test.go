@@ -0,0 +1,98 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func setupTestDeviceRepo(t *testing.T) *DeviceRepository {
+	t.Helper()
+	db, err := NewDB(Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewDeviceRepository(db.Connection())
+}
+
+func TestUpsertDevice_KeepsAdminSettings(t *testing.T) {
+	repo := setupTestDeviceRepo(t)
+
+	if err := repo.UpsertDevice(&Device{ID: "dev-1", Name: "Apple TV - tvOS", Platform: "tvOS", AppVersion: "1.0.0", Capabilities: `{}`, ProfileID: "p1"}); err != nil {
+		t.Fatalf("UpsertDevice() error = %v", err)
+	}
+	if ok, err := repo.UpdateDeviceSettings(&Device{
+		ID: "dev-1", Name: "Living Room", ProfileID: "p2", TranscodePolicy: "always", MaxBitrateKbps: 8000, MaxResolution: "1080p", SDROnly: true,
+	}); err != nil || !ok {
+		t.Fatalf("UpdateDeviceSettings() = %v, %v", ok, err)
+	}
+
+	// The app re-registering must not undo what the admin configured
+	if err := repo.UpsertDevice(&Device{ID: "dev-1", Name: "Apple TV - tvOS", Platform: "tvOS", AppVersion: "1.1.0", Capabilities: `{"videoCodecs":["hevc"]}`, ProfileID: "p1"}); err != nil {
+		t.Fatalf("UpsertDevice() refresh error = %v", err)
+	}
+
+	device, err := repo.GetDevice("dev-1")
+	if err != nil || device == nil {
+		t.Fatalf("GetDevice() = %v, %v", device, err)
+	}
+	if device.Name != "Living Room" || device.ProfileID != "p2" || device.TranscodePolicy != "always" || device.MaxBitrateKbps != 8000 ||
+		device.MaxResolution != "1080p" || !device.SDROnly {
+		t.Fatalf("admin settings were overwritten: %+v", device)
+	}
+	if device.AppVersion != "1.1.0" || device.Capabilities != `{"videoCodecs":["hevc"]}` {
+		t.Fatalf("reported details were not refreshed: %+v", device)
+	}
+}
+
+func TestUpsertDevice_KeepsOwningAccount(t *testing.T) {
+	repo := setupTestDeviceRepo(t)
+
+	if err := repo.UpsertDevice(&Device{ID: "dev-1", Capabilities: `{}`}); err != nil {
+		t.Fatalf("UpsertDevice() error = %v", err)
+	}
+	// An unowned device is claimed by the next account, which then keeps it
+	for _, account := range []string{"acct-1", "acct-2"} {
+		if err := repo.UpsertDevice(&Device{ID: "dev-1", Capabilities: `{}`, AccountID: account}); err != nil {
+			t.Fatalf("UpsertDevice(%s) error = %v", account, err)
+		}
+	}
+
+	device, err := repo.GetDevice("dev-1")
+	if err != nil || device == nil || device.AccountID != "acct-1" {
+		t.Fatalf("expected dev-1 owned by acct-1, got %+v, %v", device, err)
+	}
+}
+
+func TestListAndDeleteDevices(t *testing.T) {
+	repo := setupTestDeviceRepo(t)
+
+	for _, d := range []*Device{
+		{ID: "dev-1", Capabilities: `{}`, ProfileID: "p1"},
+		{ID: "dev-2", Capabilities: `{}`, ProfileID: "p2"},
+	} {
+		if err := repo.UpsertDevice(d); err != nil {
+			t.Fatalf("UpsertDevice() error = %v", err)
+		}
+	}
+
+	all, err := repo.ListDevices("")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListDevices(\"\") = %d devices, %v", len(all), err)
+	}
+	filtered, err := repo.ListDevices("p2")
+	if err != nil || len(filtered) != 1 || filtered[0].ID != "dev-2" {
+		t.Fatalf("ListDevices(p2) = %+v, %v", filtered, err)
+	}
+
+	if ok, err := repo.DeleteDevice("dev-1"); err != nil || !ok {
+		t.Fatalf("DeleteDevice() = %v, %v", ok, err)
+	}
+	if ok, _ := repo.DeleteDevice("dev-1"); ok {
+		t.Fatal("expected second delete to report missing device")
+	}
+	if device, err := repo.GetDevice("dev-1"); err != nil || device != nil {
+		t.Fatalf("expected deleted device to be gone, got %+v, %v", device, err)
+	}
+}
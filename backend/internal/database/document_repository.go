@@ -0,0 +1,176 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Document is one JSON record of a profile data collection.
+type Document struct {
+	UserID string // Owning profile, "" for collections not kept per profile
+	Key    string
+	Data   []byte
+}
+
+// DocumentChange writes or deletes one document.
+type DocumentChange struct {
+	Collection string
+	UserID     string
+	Key        string
+	Data       []byte
+	Delete     bool
+}
+
+// DocumentRepository handles profile data documents
+type DocumentRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+// IsImported reports whether every given collection has been imported
+func (r *DocumentRepository) IsImported(collections ...string) (bool, error) {
+	if len(collections) == 0 {
+		return true, nil
+	}
+
+	query := `SELECT COUNT(*) FROM profile_document_imports WHERE collection IN (?` + strings.Repeat(", ?", len(collections)-1) + `)`
+	args := make([]interface{}, len(collections))
+	for i, collection := range collections {
+		args[i] = collection
+	}
+
+	var count int
+	if err := r.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check document imports: %w", err)
+	}
+
+	return count == len(collections), nil
+}
+
+// ListDocuments returns every document of a collection
+func (r *DocumentRepository) ListDocuments(collection string) ([]Document, error) {
+	rows, err := r.db.Query(`SELECT user_id, doc_key, data FROM profile_documents WHERE collection = ?`, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s documents: %w", collection, err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		var data string
+		if err := rows.Scan(&doc.UserID, &doc.Key, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan %s document: %w", collection, err)
+		}
+		doc.Data = []byte(data)
+		docs = append(docs, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s documents: %w", collection, err)
+	}
+
+	return docs, nil
+}
+
+// ApplyChanges writes and deletes documents in one transaction
+func (r *DocumentRepository) ApplyChanges(changes []DocumentChange) error {
+	return r.withTransaction(func(tx *sql.Tx) error {
+		return applyDocumentChanges(tx, changes)
+	})
+}
+
+// ImportCollections replaces the given collections with docs and marks them
+// imported from source, in one transaction
+func (r *DocumentRepository) ImportCollections(source string, collections map[string][]Document) error {
+	return r.withTransaction(func(tx *sql.Tx) error {
+		var changes []DocumentChange
+		for collection, docs := range collections {
+			if _, err := tx.Exec(`DELETE FROM profile_documents WHERE collection = ?`, collection); err != nil {
+				return fmt.Errorf("failed to clear %s documents: %w", collection, err)
+			}
+			for _, doc := range docs {
+				changes = append(changes, DocumentChange{Collection: collection, UserID: doc.UserID, Key: doc.Key, Data: doc.Data})
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO profile_document_imports (collection, source, imported_at)
+				VALUES (?, ?, datetime('now'))
+				ON CONFLICT(collection) DO UPDATE SET source = excluded.source, imported_at = excluded.imported_at
+			`, collection, source); err != nil {
+				return fmt.Errorf("failed to mark %s imported: %w", collection, err)
+			}
+		}
+		return applyDocumentChanges(tx, changes)
+	})
+}
+
+func applyDocumentChanges(tx *sql.Tx, changes []DocumentChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	upsert, err := tx.Prepare(`
+		INSERT INTO profile_documents (collection, user_id, doc_key, data, updated_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
+		ON CONFLICT(collection, user_id, doc_key) DO UPDATE SET
+		data = excluded.data,
+		updated_at = datetime('now')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare document upsert: %w", err)
+	}
+	defer upsert.Close()
+
+	for _, change := range changes {
+		if change.Delete {
+			if _, err := tx.Exec(`DELETE FROM profile_documents WHERE collection = ? AND user_id = ? AND doc_key = ?`,
+				change.Collection, change.UserID, change.Key); err != nil {
+				return fmt.Errorf("failed to delete %s document: %w", change.Collection, err)
+			}
+			continue
+		}
+		if _, err := upsert.Exec(change.Collection, change.UserID, change.Key, string(change.Data)); err != nil {
+			return fmt.Errorf("failed to write %s document: %w", change.Collection, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *DocumentRepository) withTransaction(fn func(*sql.Tx) error) error {
+	sqlDB, ok := r.db.(*sql.DB)
+	if !ok {
+		return fmt.Errorf("document repository not connected to sql.DB")
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin document transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("failed to rollback document transaction (original error: %w): %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DocumentStore keeps profile data collections (users, watchlists, history,
+// user settings) in the database. Services hold their data in memory and save
+// whole collections; only documents that changed since the last save are
+// written, in a single transaction.
+type DocumentStore struct {
+	repo *DocumentRepository
+
+	mu      sync.Mutex
+	written map[string]map[documentID]string // collection -> last written data
+}
+
+type documentID struct {
+	userID string
+	key    string
+}
+
+// NewDocumentStore creates a document store backed by the given repository.
+func NewDocumentStore(repo *DocumentRepository) *DocumentStore {
+	return &DocumentStore{repo: repo, written: make(map[string]map[documentID]string)}
+}
+
+// Imported reports whether the collections were already imported from their
+// JSON files.
+func (s *DocumentStore) Imported(collections ...string) (bool, error) {
+	return s.repo.IsImported(collections...)
+}
+
+// Load returns every document of a collection.
+func (s *DocumentStore) Load(collection string) ([]Document, error) {
+	docs, err := s.repo.ListDocuments(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written[collection] = snapshot(docs)
+	return docs, nil
+}
+
+// Import stores collections read from JSON files and marks them imported,
+// then renames the files to *.migrated so nothing reads them again.
+func (s *DocumentStore) Import(collections map[string][]Document, sources ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repo.ImportCollections(strings.Join(sources, ", "), collections); err != nil {
+		return err
+	}
+	for collection, docs := range collections {
+		s.written[collection] = snapshot(docs)
+		log.Printf("[database] imported %d %s document(s)", len(docs), collection)
+	}
+
+	for _, path := range sources {
+		if err := os.Rename(path, path+".migrated"); err != nil && !os.IsNotExist(err) {
+			log.Printf("[database] imported %s but could not rename it: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// Save replaces the contents of the given collections with docs.
+func (s *DocumentStore) Save(collections map[string][]Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes []DocumentChange
+	next := make(map[string]map[documentID]string, len(collections))
+	for collection, docs := range collections {
+		previous := s.written[collection]
+		current := snapshot(docs)
+		for id, data := range current {
+			if old, ok := previous[id]; !ok || old != data {
+				changes = append(changes, DocumentChange{Collection: collection, UserID: id.userID, Key: id.key, Data: []byte(data)})
+			}
+		}
+		for id := range previous {
+			if _, ok := current[id]; !ok {
+				changes = append(changes, DocumentChange{Collection: collection, UserID: id.userID, Key: id.key, Delete: true})
+			}
+		}
+		next[collection] = current
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := s.repo.ApplyChanges(changes); err != nil {
+		return err
+	}
+	for collection, current := range next {
+		s.written[collection] = current
+	}
+	return nil
+}
+
+func snapshot(docs []Document) map[documentID]string {
+	out := make(map[documentID]string, len(docs))
+	for _, doc := range docs {
+		out[documentID{userID: doc.UserID, key: doc.Key}] = string(doc.Data)
+	}
+	return out
+}
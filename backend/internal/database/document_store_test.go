@@ -0,0 +1,74 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupTestDocumentStore(t *testing.T) (*DocumentStore, *DocumentRepository) {
+	t.Helper()
+	db, err := NewDB(Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewDocumentRepository(db.Connection())
+	return NewDocumentStore(repo), repo
+}
+
+func TestDocumentStore_ImportOnce(t *testing.T) {
+	store, _ := setupTestDocumentStore(t)
+	source := filepath.Join(t.TempDir(), "watchlist.json")
+	if err := os.WriteFile(source, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if imported, err := store.Imported("watchlist"); err != nil || imported {
+		t.Fatalf("Imported() before import = %v, %v", imported, err)
+	}
+	if err := store.Import(map[string][]Document{
+		"watchlist": {{UserID: "p1", Key: "movie:1", Data: []byte(`{"id":"1"}`)}},
+		"empty":     nil,
+	}, source); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if imported, err := store.Imported("watchlist", "empty"); err != nil || !imported {
+		t.Fatalf("Imported() after import = %v, %v", imported, err)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Fatalf("expected the JSON file to be renamed, stat error = %v", err)
+	}
+	if _, err := os.Stat(source + ".migrated"); err != nil {
+		t.Fatalf("expected %s.migrated: %v", source, err)
+	}
+
+	docs, err := store.Load("watchlist")
+	if err != nil || len(docs) != 1 || docs[0].UserID != "p1" || string(docs[0].Data) != `{"id":"1"}` {
+		t.Fatalf("Load() = %+v, %v", docs, err)
+	}
+}
+
+func TestDocumentStore_SaveWritesChanges(t *testing.T) {
+	store, repo := setupTestDocumentStore(t)
+
+	if err := store.Save(map[string][]Document{"users": {
+		{Key: "a", Data: []byte(`{"name":"A"}`)},
+		{Key: "b", Data: []byte(`{"name":"B"}`)},
+	}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(map[string][]Document{"users": {
+		{Key: "a", Data: []byte(`{"name":"A2"}`)},
+	}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A fresh store sees what was written, with the removed document gone
+	docs, err := NewDocumentStore(repo).Load("users")
+	if err != nil || len(docs) != 1 || docs[0].Key != "a" || string(docs[0].Data) != `{"name":"A2"}` {
+		t.Fatalf("Load() = %+v, %v", docs, err)
+	}
+}
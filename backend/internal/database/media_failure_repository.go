@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MediaFailureStat is an aggregated count of probe/transcode failures sharing
+// the same stage, codec, container, provider and error signature.
+type MediaFailureStat struct {
+	Stage      string
+	VideoCodec string
+	Container  string
+	Provider   string
+	Signature  string
+	Count      int64
+	LastError  *string
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+// MediaFailureRepository handles media failure statistics
+type MediaFailureRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewMediaFailureRepository creates a new media failure repository
+func NewMediaFailureRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *MediaFailureRepository {
+	return &MediaFailureRepository{db: db}
+}
+
+// RecordFailure increments the counter for a failure signature, creating it if needed
+func (r *MediaFailureRepository) RecordFailure(stage, videoCodec, container, provider, signature, lastError string) error {
+	query := `
+		INSERT INTO media_failure_stats (stage, video_codec, container, provider, signature, count, last_error, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, 1, ?, datetime('now'), datetime('now'))
+		ON CONFLICT(stage, video_codec, container, provider, signature) DO UPDATE SET
+		count = count + 1,
+		last_error = excluded.last_error,
+		last_seen = datetime('now')
+	`
+
+	if _, err := r.db.Exec(query, stage, videoCodec, container, provider, signature, lastError); err != nil {
+		return fmt.Errorf("failed to record media failure: %w", err)
+	}
+
+	return nil
+}
+
+// ListFailureStats returns failure signatures ordered by count, optionally filtered by stage.
+// A limit <= 0 returns all rows.
+func (r *MediaFailureRepository) ListFailureStats(stage string, limit int) ([]*MediaFailureStat, error) {
+	query := `
+		SELECT stage, video_codec, container, provider, signature, count, last_error, first_seen, last_seen
+		FROM media_failure_stats
+		WHERE (? = '' OR stage = ?)
+		ORDER BY count DESC, last_seen DESC
+	`
+	args := []interface{}{stage, stage}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media failures: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*MediaFailureStat
+	for rows.Next() {
+		var stat MediaFailureStat
+		if err := rows.Scan(
+			&stat.Stage, &stat.VideoCodec, &stat.Container, &stat.Provider, &stat.Signature,
+			&stat.Count, &stat.LastError, &stat.FirstSeen, &stat.LastSeen,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan media failure: %w", err)
+		}
+		stats = append(stats, &stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate media failures: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ClearFailureStats removes all recorded failure statistics
+func (r *MediaFailureRepository) ClearFailureStats() error {
+	if _, err := r.db.Exec(`DELETE FROM media_failure_stats`); err != nil {
+		return fmt.Errorf("failed to clear media failures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func setupTestMediaFailureRepo(t *testing.T) *MediaFailureRepository {
+	t.Helper()
+	db, err := NewDB(Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewMediaFailureRepository(db.Connection())
+}
+
+func TestRecordFailure_AggregatesBySignature(t *testing.T) {
+	repo := setupTestMediaFailureRepo(t)
+
+	for i := 0; i < 3; i++ {
+		if err := repo.RecordFailure("transcode", "hevc", "mkv", "usenet", "error applying bitstream filters", "err"); err != nil {
+			t.Fatalf("RecordFailure() error = %v", err)
+		}
+	}
+	if err := repo.RecordFailure("probe", "", "mp4", "realdebrid", "invalid data found when processing input", "other"); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+
+	stats, err := repo.ListFailureStats("", 0)
+	if err != nil {
+		t.Fatalf("ListFailureStats() error = %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(stats))
+	}
+	if stats[0].Count != 3 || stats[0].VideoCodec != "hevc" {
+		t.Fatalf("expected most frequent signature first, got %+v", stats[0])
+	}
+
+	probeOnly, err := repo.ListFailureStats("probe", 10)
+	if err != nil {
+		t.Fatalf("ListFailureStats(probe) error = %v", err)
+	}
+	if len(probeOnly) != 1 || probeOnly[0].Provider != "realdebrid" {
+		t.Fatalf("expected probe filter to return 1 row, got %+v", probeOnly)
+	}
+}
+
+func TestClearFailureStats(t *testing.T) {
+	repo := setupTestMediaFailureRepo(t)
+
+	if err := repo.RecordFailure("probe", "h264", "mkv", "usenet", "sig", "err"); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	if err := repo.ClearFailureStats(); err != nil {
+		t.Fatalf("ClearFailureStats() error = %v", err)
+	}
+
+	stats, err := repo.ListFailureStats("", 0)
+	if err != nil {
+		t.Fatalf("ListFailureStats() error = %v", err)
+	}
+	if len(stats) != 0 {
+		t.Fatalf("expected no stats after clear, got %d", len(stats))
+	}
+}
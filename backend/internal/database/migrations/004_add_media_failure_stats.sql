@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Aggregated ffprobe/transcode failures grouped by codec, container, provider and
+-- a normalised error signature. One row per distinct combination; count grows.
+CREATE TABLE media_failure_stats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    stage TEXT NOT NULL,
+    video_codec TEXT NOT NULL DEFAULT '',
+    container TEXT NOT NULL DEFAULT '',
+    provider TEXT NOT NULL DEFAULT '',
+    signature TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT DEFAULT NULL,
+    first_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(stage, video_codec, container, provider, signature)
+);
+
+CREATE INDEX idx_media_failure_stats_count ON media_failure_stats(count DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_media_failure_stats_count;
+DROP TABLE IF EXISTS media_failure_stats;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Registered playback devices, keyed by the client ID the apps send, with
+-- their reported capabilities and admin-assigned playback overrides.
+CREATE TABLE devices (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    platform TEXT NOT NULL DEFAULT '',
+    app_version TEXT NOT NULL DEFAULT '',
+    capabilities TEXT NOT NULL DEFAULT '{}', -- JSON-encoded codec/HDR capabilities
+    profile_id TEXT NOT NULL DEFAULT '',
+    transcode_policy TEXT NOT NULL DEFAULT 'auto',
+    max_bitrate_kbps INTEGER NOT NULL DEFAULT 0,
+    first_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_seen DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_devices_profile_id ON devices(profile_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_devices_profile_id;
+DROP TABLE IF EXISTS devices;
+
+-- +goose StatementEnd
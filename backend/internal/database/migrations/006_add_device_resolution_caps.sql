@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-device playback caps: the highest resolution a device should get
+-- (e.g. '1080p', '' = no cap) and whether it should only get SDR video.
+ALTER TABLE devices ADD COLUMN max_resolution TEXT NOT NULL DEFAULT '';
+ALTER TABLE devices ADD COLUMN sdr_only INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE devices DROP COLUMN sdr_only;
+ALTER TABLE devices DROP COLUMN max_resolution;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Notes on titles shared by every profile of an account (the household),
+-- e.g. "we stopped at S02E04 because...".
+CREATE TABLE title_notes (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL,
+    title_id TEXT NOT NULL,
+    media_type TEXT NOT NULL DEFAULT '',
+    title_name TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    author_profile_id TEXT NOT NULL,
+    edited_by_profile_id TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_title_notes_account_title ON title_notes(account_id, title_id);
+CREATE INDEX idx_title_notes_author ON title_notes(author_profile_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_title_notes_author;
+DROP INDEX IF EXISTS idx_title_notes_account_title;
+DROP TABLE IF EXISTS title_notes;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Profile data that used to live in JSON files under the cache directory
+-- (profiles, watchlists, watch history, playback progress, user settings).
+-- One JSON document per record, keyed by collection, owning profile and key.
+CREATE TABLE profile_documents (
+    collection TEXT NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '', -- '' for collections not kept per profile
+    doc_key TEXT NOT NULL,
+    data TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection, user_id, doc_key)
+);
+
+-- Collections imported from their JSON files, so the import runs once.
+CREATE TABLE profile_document_imports (
+    collection TEXT PRIMARY KEY,
+    source TEXT NOT NULL DEFAULT '',
+    imported_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS profile_document_imports;
+DROP TABLE IF EXISTS profile_documents;
+
+-- +goose StatementEnd
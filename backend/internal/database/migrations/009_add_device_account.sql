@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The account a device registered under. Only that account may refresh the
+-- device or record its compatibility results; '' for devices registered
+-- before ownership was tracked, which the next account to register them claims.
+ALTER TABLE devices ADD COLUMN account_id TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE devices DROP COLUMN account_id;
+
+-- +goose StatementEnd
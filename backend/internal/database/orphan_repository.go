@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const orphanDeleteBatch = 500
+
+// QueueFileRef is the on-disk state an import queue row depends on: the
+// spooled NZB until it is imported, the imported file afterwards.
+type QueueFileRef struct {
+	ID          int64
+	Status      QueueStatus
+	NzbPath     string
+	StoragePath string
+}
+
+// HealthFileRef is the file a health record tracks and the NZB it came from.
+type HealthFileRef struct {
+	ID            int64
+	Status        HealthStatus
+	FilePath      string
+	SourceNzbPath string
+}
+
+// OrphanRepository lists and deletes rows for the orphaned record garbage
+// collector. Rows that are being worked on are never deleted.
+type OrphanRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewOrphanRepository creates a new orphan repository
+func NewOrphanRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *OrphanRepository {
+	return &OrphanRepository{db: db}
+}
+
+// ListQueueFileRefs returns the file references of every import queue row.
+func (r *OrphanRepository) ListQueueFileRefs() ([]QueueFileRef, error) {
+	rows, err := r.db.Query(`SELECT id, status, nzb_path, COALESCE(storage_path, '') FROM import_queue`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue file references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []QueueFileRef
+	for rows.Next() {
+		var ref QueueFileRef
+		if err := rows.Scan(&ref.ID, &ref.Status, &ref.NzbPath, &ref.StoragePath); err != nil {
+			return nil, fmt.Errorf("failed to scan queue file reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// ListHealthFileRefs returns the file references of every health record.
+func (r *OrphanRepository) ListHealthFileRefs() ([]HealthFileRef, error) {
+	rows, err := r.db.Query(`SELECT id, status, file_path, COALESCE(source_nzb_path, '') FROM file_health`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list health file references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []HealthFileRef
+	for rows.Next() {
+		var ref HealthFileRef
+		if err := rows.Scan(&ref.ID, &ref.Status, &ref.FilePath, &ref.SourceNzbPath); err != nil {
+			return nil, fmt.Errorf("failed to scan health file reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// DeleteQueueItems removes queue rows by ID, skipping rows that are being
+// processed, and returns how many were removed.
+func (r *OrphanRepository) DeleteQueueItems(ids []int64) (int64, error) {
+	return r.deleteByID("import_queue", ids, string(QueueStatusProcessing))
+}
+
+// DeleteHealthRecords removes health records by ID, skipping records that
+// are being checked, and returns how many were removed.
+func (r *OrphanRepository) DeleteHealthRecords(ids []int64) (int64, error) {
+	return r.deleteByID("file_health", ids, string(HealthStatusChecking))
+}
+
+func (r *OrphanRepository) deleteByID(table string, ids []int64, busyStatus string) (int64, error) {
+	var deleted int64
+	// Batched to stay under SQLite's bound parameter limit
+	for start := 0; start < len(ids); start += orphanDeleteBatch {
+		batch := ids[start:min(start+orphanDeleteBatch, len(ids))]
+		args := make([]interface{}, 0, len(batch)+1)
+		for _, id := range batch {
+			args = append(args, id)
+		}
+		args = append(args, busyStatus)
+
+		placeholders := "?" + strings.Repeat(",?", len(batch)-1)
+		result, err := r.db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s) AND status != ?`, table, placeholders), args...)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete orphaned %s rows: %w", table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestOrphanRepository_ListAndDeleteSkipsBusyRows(t *testing.T) {
+	db := setupTestDB(t)
+	health := NewHealthRepository(db.Connection())
+	repo := NewOrphanRepository(db.Connection())
+
+	for _, path := range []string{"/tmp/a.nzb", "/tmp/b.nzb"} {
+		if err := db.Repository.AddToQueue(&ImportQueueItem{NzbPath: path, Priority: QueuePriorityNormal, Status: QueueStatusPending, MaxRetries: 3}); err != nil {
+			t.Fatalf("AddToQueue failed: %v", err)
+		}
+	}
+	claimed, err := db.Repository.ClaimNextQueueItem()
+	if err != nil || claimed == nil {
+		t.Fatalf("ClaimNextQueueItem = %v, %v", claimed, err)
+	}
+	source := "/tmp/a.nzb"
+	if err := health.UpdateFileHealth("/movie/file.mkv", HealthStatusCorrupted, nil, &source, nil); err != nil {
+		t.Fatalf("UpdateFileHealth failed: %v", err)
+	}
+
+	queueRefs, err := repo.ListQueueFileRefs()
+	if err != nil || len(queueRefs) != 2 {
+		t.Fatalf("ListQueueFileRefs = %v, %v", queueRefs, err)
+	}
+	healthRefs, err := repo.ListHealthFileRefs()
+	if err != nil || len(healthRefs) != 1 || healthRefs[0].SourceNzbPath != source {
+		t.Fatalf("ListHealthFileRefs = %v, %v", healthRefs, err)
+	}
+
+	// The claimed item is processing and must survive
+	deleted, err := repo.DeleteQueueItems([]int64{queueRefs[0].ID, queueRefs[1].ID})
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteQueueItems = %d, %v; want 1 deleted", deleted, err)
+	}
+	if item, _ := db.Repository.GetQueueItem(claimed.ID); item == nil {
+		t.Error("processing item was deleted")
+	}
+
+	if deleted, err := repo.DeleteHealthRecords([]int64{healthRefs[0].ID}); err != nil || deleted != 1 {
+		t.Fatalf("DeleteHealthRecords = %d, %v; want 1 deleted", deleted, err)
+	}
+}
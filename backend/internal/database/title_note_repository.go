@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TitleNote is a note on a title shared by the profiles of one account.
+type TitleNote struct {
+	ID                string
+	AccountID         string
+	TitleID           string
+	MediaType         string
+	TitleName         string
+	Body              string
+	AuthorProfileID   string
+	EditedByProfileID string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TitleNoteRepository handles shared title notes
+type TitleNoteRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewTitleNoteRepository creates a new title note repository
+func NewTitleNoteRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *TitleNoteRepository {
+	return &TitleNoteRepository{db: db}
+}
+
+const titleNoteColumns = `id, account_id, title_id, media_type, title_name, body, author_profile_id, edited_by_profile_id, created_at, updated_at`
+
+// CreateTitleNote stores a new note
+func (r *TitleNoteRepository) CreateTitleNote(note *TitleNote) error {
+	query := `
+		INSERT INTO title_notes (id, account_id, title_id, media_type, title_name, body, author_profile_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+	`
+
+	if _, err := r.db.Exec(query, note.ID, note.AccountID, note.TitleID, note.MediaType, note.TitleName, note.Body, note.AuthorProfileID); err != nil {
+		return fmt.Errorf("failed to create title note: %w", err)
+	}
+
+	return nil
+}
+
+// GetTitleNote returns a note by ID, or nil if it does not exist
+func (r *TitleNoteRepository) GetTitleNote(id string) (*TitleNote, error) {
+	query := `SELECT ` + titleNoteColumns + ` FROM title_notes WHERE id = ?`
+
+	note, err := scanTitleNote(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get title note: %w", err)
+	}
+
+	return note, nil
+}
+
+// ListTitleNotes returns an account's notes, newest first, optionally filtered by title
+func (r *TitleNoteRepository) ListTitleNotes(accountID, titleID string) ([]*TitleNote, error) {
+	query := `
+		SELECT ` + titleNoteColumns + `
+		FROM title_notes
+		WHERE account_id = ? AND (? = '' OR title_id = ?)
+		ORDER BY created_at DESC, rowid DESC
+	`
+
+	rows, err := r.db.Query(query, accountID, titleID, titleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list title notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*TitleNote
+	for rows.Next() {
+		note, err := scanTitleNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan title note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate title notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// UpdateTitleNoteBody replaces the text of a note and records who edited it.
+// Returns false if the note does not exist.
+func (r *TitleNoteRepository) UpdateTitleNoteBody(id, body, editedByProfileID string) (bool, error) {
+	query := `
+		UPDATE title_notes
+		SET body = ?, edited_by_profile_id = ?, updated_at = datetime('now')
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, body, editedByProfileID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update title note: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteTitleNote removes a note. Returns false if it does not exist.
+func (r *TitleNoteRepository) DeleteTitleNote(id string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM title_notes WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete title note: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteTitleNotesByAuthor removes every note written by a profile
+func (r *TitleNoteRepository) DeleteTitleNotesByAuthor(profileID string) error {
+	if _, err := r.db.Exec(`DELETE FROM title_notes WHERE author_profile_id = ?`, profileID); err != nil {
+		return fmt.Errorf("failed to delete title notes: %w", err)
+	}
+	return nil
+}
+
+func scanTitleNote(row interface {
+	Scan(dest ...interface{}) error
+}) (*TitleNote, error) {
+	var note TitleNote
+	if err := row.Scan(
+		&note.ID, &note.AccountID, &note.TitleID, &note.MediaType, &note.TitleName, &note.Body,
+		&note.AuthorProfileID, &note.EditedByProfileID, &note.CreatedAt, &note.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func setupTestTitleNoteRepo(t *testing.T) *TitleNoteRepository {
+	t.Helper()
+	db, err := NewDB(Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewTitleNoteRepository(db.Connection())
+}
+
+func TestTitleNotes_ListByAccountAndTitle(t *testing.T) {
+	repo := setupTestTitleNoteRepo(t)
+
+	for _, n := range []*TitleNote{
+		{ID: "n1", AccountID: "acct-1", TitleID: "tvdb:series:1", Body: "stopped at S02E04", AuthorProfileID: "p1"},
+		{ID: "n2", AccountID: "acct-1", TitleID: "tmdb:movie:2", Body: "save for movie night", AuthorProfileID: "p2"},
+		{ID: "n3", AccountID: "acct-2", TitleID: "tvdb:series:1", Body: "other household", AuthorProfileID: "p3"},
+	} {
+		if err := repo.CreateTitleNote(n); err != nil {
+			t.Fatalf("CreateTitleNote() error = %v", err)
+		}
+	}
+
+	all, err := repo.ListTitleNotes("acct-1", "")
+	if err != nil || len(all) != 2 || all[0].ID != "n2" {
+		t.Fatalf("ListTitleNotes(acct-1) = %+v, %v", all, err)
+	}
+	forTitle, err := repo.ListTitleNotes("acct-1", "tvdb:series:1")
+	if err != nil || len(forTitle) != 1 || forTitle[0].Body != "stopped at S02E04" {
+		t.Fatalf("ListTitleNotes(acct-1, series) = %+v, %v", forTitle, err)
+	}
+}
+
+func TestTitleNotes_UpdateAndDelete(t *testing.T) {
+	repo := setupTestTitleNoteRepo(t)
+
+	if err := repo.CreateTitleNote(&TitleNote{ID: "n1", AccountID: "acct-1", TitleID: "t1", Body: "first", AuthorProfileID: "p1"}); err != nil {
+		t.Fatalf("CreateTitleNote() error = %v", err)
+	}
+	if ok, err := repo.UpdateTitleNoteBody("n1", "edited", "p2"); err != nil || !ok {
+		t.Fatalf("UpdateTitleNoteBody() = %v, %v", ok, err)
+	}
+	note, err := repo.GetTitleNote("n1")
+	if err != nil || note == nil || note.Body != "edited" || note.EditedByProfileID != "p2" || note.AuthorProfileID != "p1" {
+		t.Fatalf("GetTitleNote() = %+v, %v", note, err)
+	}
+
+	if ok, _ := repo.UpdateTitleNoteBody("missing", "x", "p1"); ok {
+		t.Fatal("expected update of a missing note to report false")
+	}
+
+	if err := repo.DeleteTitleNotesByAuthor("p1"); err != nil {
+		t.Fatalf("DeleteTitleNotesByAuthor() error = %v", err)
+	}
+	if ok, _ := repo.DeleteTitleNote("n1"); ok {
+		t.Fatal("expected the author's notes to be gone")
+	}
+	if note, err := repo.GetTitleNote("n1"); err != nil || note != nil {
+		t.Fatalf("expected deleted note to be gone, got %+v, %v", note, err)
+	}
+}
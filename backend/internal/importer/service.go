@@ -22,13 +22,15 @@ import (
 	"novastream/internal/nzb/metadata"
 	"novastream/internal/pool"
 	"novastream/internal/sabnzbd"
+	"novastream/utils/diskspace"
 
 	"github.com/javi11/nzbparser"
 )
 
 // ServiceConfig holds configuration for the NZB import service
 type ServiceConfig struct {
-	Workers int // Number of parallel queue workers (default: 4)
+	Workers          int    // Number of parallel queue workers (default: 4)
+	MetadataRootPath string // Where NZB metadata is written, checked for free space on admission
 }
 
 // ScanStatus represents the current status of a manual scan
@@ -363,6 +365,11 @@ func (s *Service) isFileAlreadyInQueue(filePath string) bool {
 
 // AddToQueue adds a new NZB file to the import queue with optional category and priority
 func (s *Service) AddToQueue(filePath string, relativePath *string, category *string, priority *database.QueuePriority) (*database.ImportQueueItem, error) {
+	if err := s.checkDiskSpace(context.Background(), 0); err != nil {
+		s.log.Warn("Refusing to queue NZB file", "file", filePath, "error", err)
+		return nil, err
+	}
+
 	// Calculate file size before adding to queue
 	var fileSize *int64
 	if size, err := s.CalculateFileSizeOnly(filePath); err != nil {
@@ -916,6 +923,11 @@ func (s *Service) convertPriorityToSABnzbd(priority database.QueuePriority) stri
 func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes []byte) (*database.ImportQueueItem, error) {
 	s.log.InfoContext(ctx, "Adding NZB to queue", "fileName", fileName, "size", len(nzbBytes))
 
+	if err := s.checkDiskSpace(ctx, int64(len(nzbBytes))); err != nil {
+		s.log.WarnContext(ctx, "Refusing NZB", "fileName", fileName, "error", err)
+		return nil, err
+	}
+
 	// Create temp directory for NZBs if it doesn't exist
 	tempDir := filepath.Join(os.TempDir(), "novastream-nzbs")
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
@@ -994,6 +1006,11 @@ func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes [
 func (s *Service) ProcessNZBImmediately(ctx context.Context, fileName string, nzbBytes []byte) (string, error) {
 	s.log.InfoContext(ctx, "Processing NZB immediately", "fileName", fileName, "size", len(nzbBytes))
 
+	if err := s.checkDiskSpace(ctx, int64(len(nzbBytes))); err != nil {
+		s.log.WarnContext(ctx, "Refusing NZB", "fileName", fileName, "error", err)
+		return "", err
+	}
+
 	// Create temp directory for NZBs if it doesn't exist
 	tempDir := filepath.Join(os.TempDir(), "novastream-nzbs")
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
@@ -1025,6 +1042,21 @@ func (s *Service) ProcessNZBImmediately(ctx context.Context, fileName string, nz
 	return resultingPath, nil
 }
 
+// checkDiskSpace refuses new NZBs when the NZB temp directory or the
+// metadata root is below its free space reserve. The returned error wraps
+// diskspace.ErrInsufficientSpace.
+func (s *Service) checkDiskSpace(ctx context.Context, nzbSize int64) error {
+	for _, dir := range []string{filepath.Join(os.TempDir(), "novastream-nzbs"), s.config.MetadataRootPath} {
+		if dir == "" {
+			continue
+		}
+		if err := diskspace.Check(ctx, diskspace.PurposeNZB, dir, nzbSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // sanitizeFileName removes unsafe characters from a filename
 func sanitizeFileName(name string) string {
 	// Remove path separators and other unsafe characters
@@ -75,7 +75,8 @@ func NewNzbSystem(config NzbConfig, poolManager pool.Manager, configGetter confi
 
 	// Create NZB service using metadata + queue
 	serviceConfig := importer.ServiceConfig{
-		Workers: maxProcessorWorkers,
+		Workers:          maxProcessorWorkers,
+		MetadataRootPath: config.MetadataRootPath,
 	}
 
 	// Create service with poolManager for dynamic pool access
@@ -31,6 +31,7 @@ type MetadataRemoteFile struct {
 	poolManager      pool.Manager        // Pool manager for dynamic pool access
 	configGetter     config.ConfigGetter // Dynamic config access
 	rcloneCipher     encryption.Cipher   // For rclone encryption/decryption
+	streamRates      *streamRateRegistry // Measured consumption rate per file for read-ahead sizing
 }
 
 // Configuration is now accessed dynamically through config.ConfigGetter
@@ -58,6 +59,7 @@ func NewMetadataRemoteFile(
 		poolManager:      poolManager,
 		configGetter:     configGetter,
 		rcloneCipher:     rcloneCipher,
+		streamRates:      newStreamRateRegistry(),
 	}
 }
 
@@ -149,6 +151,7 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string, r util
 		rcloneCipher:     mrf.rcloneCipher,
 		globalPassword:   mrf.getGlobalPassword(),
 		globalSalt:       mrf.getGlobalSalt(),
+		rate:             mrf.streamRates.get(normalizedName),
 	}
 
 	return true, virtualFile, nil
@@ -479,6 +482,7 @@ type MetadataVirtualFile struct {
 	rcloneCipher     encryption.Cipher
 	globalPassword   string
 	globalSalt       string
+	rate             *streamRate // Consumption rate shared by handles of this file
 
 	// Reader state and position tracking
 	reader            io.ReadCloser
@@ -561,6 +565,7 @@ func (mvf *MetadataVirtualFile) Read(p []byte) (n int, err error) {
 
 	// Update the current position after reading
 	mvf.position += int64(totalRead)
+	mvf.rate.observe(totalRead, time.Now())
 	return totalRead, nil
 }
 
@@ -828,9 +833,11 @@ func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, e
 	}
 
 	// PERFORMANCE OPTIMIZATION: For very large files, implement progressive loading
-	// If the requested range is very large, limit it to prevent excessive memory usage
+	// If the requested range is very large, limit it to the read-ahead window. Once
+	// the playback rate is known the window covers readAheadTarget of content,
+	// until then a static estimate of 1MB per segment is used.
 	requestedRangeSize := end - start + 1
-	maxRangeSize := int64(maxSegments) * 1024 * 1024 // 1MB per segment estimate
+	maxRangeSize := mvf.rate.readAheadBytes(int64(maxSegments) * 1024 * 1024)
 
 	if requestedRangeSize > maxRangeSize {
 		// Limit the end to prevent excessive memory usage
@@ -853,6 +860,11 @@ func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, e
 		}
 	}
 
+	// Make sure the segment limit doesn't cut the read-ahead window short
+	if needed := segmentsForBytes(maxRangeSize, mvf.fileMeta); needed > maxSegments {
+		maxSegments = needed
+	}
+
 	loader := newMetadataSegmentLoader(mvf.fileMeta.SegmentData)
 	rg := usenet.GetSegmentsInRangeWithLimit(start, end, loader, maxSegments)
 	return usenet.NewUsenetReader(ctx, cp, rg, mvf.maxWorkers, mvf.maxCacheSizeMB)
@@ -0,0 +1,147 @@
+package nzbfilesystem
+
+import (
+	"sync"
+	"time"
+
+	metapb "novastream/internal/nzb/metadata/proto"
+)
+
+// Read-ahead sizing for streaming reads
+const (
+	// readAheadTarget is how much playback time the read-ahead window should cover
+	readAheadTarget = 30 * time.Second
+	// minReadAheadBytes keeps low-bitrate streams from fetching in tiny windows
+	minReadAheadBytes = 16 * 1024 * 1024
+	// maxReadAheadBytes bounds memory use; covers 30s at ~85 Mbit/s
+	maxReadAheadBytes = 320 * 1024 * 1024
+	// rateSampleInterval is the minimum span of reads folded into one rate sample
+	rateSampleInterval = 2 * time.Second
+	// rateIdleGap discards a sample when reads stop for this long (pause, stalled client)
+	rateIdleGap = 15 * time.Second
+	// rateSmoothing is the weight given to each new sample in the moving average
+	rateSmoothing = 0.3
+	// streamRateTTL is how long an idle stream's measured rate is kept
+	streamRateTTL = 10 * time.Minute
+)
+
+// streamRate measures how fast a stream's bytes are being consumed
+type streamRate struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	sampleStart time.Time
+	sampleBytes int64
+	lastRead    time.Time
+}
+
+// observe records n bytes handed to the client at now
+func (r *streamRate) observe(n int, now time.Time) {
+	if r == nil || n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Bytes are counted against the time since the previous read, so the read
+	// that opens a sample only marks its start
+	if r.sampleStart.IsZero() || now.Sub(r.lastRead) > rateIdleGap {
+		r.sampleStart = now
+		r.sampleBytes = 0
+		r.lastRead = now
+		return
+	}
+	r.sampleBytes += int64(n)
+	r.lastRead = now
+
+	elapsed := now.Sub(r.sampleStart)
+	if elapsed < rateSampleInterval {
+		return
+	}
+
+	sample := float64(r.sampleBytes) / elapsed.Seconds()
+	if r.bytesPerSec == 0 {
+		r.bytesPerSec = sample
+	} else {
+		r.bytesPerSec = rateSmoothing*sample + (1-rateSmoothing)*r.bytesPerSec
+	}
+	r.sampleStart = now
+	r.sampleBytes = 0
+}
+
+// readAheadBytes returns the window needed to keep readAheadTarget of content
+// buffered at the measured rate, or fallback until a rate has been measured
+func (r *streamRate) readAheadBytes(fallback int64) int64 {
+	if r == nil {
+		return fallback
+	}
+
+	r.mu.Lock()
+	rate := r.bytesPerSec
+	r.mu.Unlock()
+
+	if rate <= 0 {
+		return fallback
+	}
+
+	window := int64(rate * readAheadTarget.Seconds())
+	if window < minReadAheadBytes {
+		window = minReadAheadBytes
+	}
+	if window > maxReadAheadBytes {
+		window = maxReadAheadBytes
+	}
+	return window
+}
+
+func (r *streamRate) idleSince(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return now.Sub(r.lastRead)
+}
+
+// streamRateRegistry shares measured rates between handles of the same file.
+// Players open a new handle for every range request and seek, so a rate kept
+// on the handle alone would be lost each time.
+type streamRateRegistry struct {
+	mu    sync.Mutex
+	rates map[string]*streamRate
+}
+
+func newStreamRateRegistry() *streamRateRegistry {
+	return &streamRateRegistry{rates: make(map[string]*streamRate)}
+}
+
+// get returns the rate tracker for name, creating it if needed and dropping
+// trackers that have been idle longer than streamRateTTL
+func (reg *streamRateRegistry) get(name string) *streamRate {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	now := time.Now()
+	for key, rate := range reg.rates {
+		if key != name && rate.idleSince(now) > streamRateTTL {
+			delete(reg.rates, key)
+		}
+	}
+
+	rate, ok := reg.rates[name]
+	if !ok {
+		rate = &streamRate{lastRead: now}
+		reg.rates[name] = rate
+	}
+	return rate
+}
+
+// segmentsForBytes estimates how many segments of file cover n bytes
+func segmentsForBytes(n int64, file *metapb.FileMetadata) int {
+	if len(file.SegmentData) == 0 || file.FileSize <= 0 {
+		return 0
+	}
+	avg := file.FileSize / int64(len(file.SegmentData))
+	if avg <= 0 {
+		return len(file.SegmentData)
+	}
+	// Round up, plus one for a window that starts partway through a segment
+	return int(n/avg) + 2
+}
@@ -0,0 +1,64 @@
+package nzbfilesystem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadAheadBytesFallsBackUntilMeasured(t *testing.T) {
+	var nilRate *streamRate
+	if got := nilRate.readAheadBytes(42); got != 42 {
+		t.Errorf("nil rate readAheadBytes() = %d, want fallback 42", got)
+	}
+
+	rate := &streamRate{}
+	now := time.Now()
+	rate.observe(1024, now)
+	if got := rate.readAheadBytes(42); got != 42 {
+		t.Errorf("readAheadBytes() before a full sample = %d, want fallback 42", got)
+	}
+}
+
+func TestReadAheadBytesScalesWithRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		bytesPerSec int
+		want        int64
+	}{
+		{name: "low bitrate clamps to minimum", bytesPerSec: 256 * 1024, want: minReadAheadBytes},
+		{name: "mid bitrate covers target", bytesPerSec: 4 * 1024 * 1024, want: 4 * 1024 * 1024 * int64(readAheadTarget/time.Second)},
+		{name: "high bitrate clamps to maximum", bytesPerSec: 40 * 1024 * 1024, want: maxReadAheadBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := &streamRate{}
+			start := time.Now()
+			// One read per second for a few seconds at the given rate
+			for i := 0; i <= 4; i++ {
+				rate.observe(tt.bytesPerSec, start.Add(time.Duration(i)*time.Second))
+			}
+			got := rate.readAheadBytes(1)
+			if diff := got - tt.want; diff < -tt.want/10 || diff > tt.want/10 {
+				t.Errorf("readAheadBytes() = %d, want ~%d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamRateIgnoresIdleGaps(t *testing.T) {
+	rate := &streamRate{}
+	start := time.Now()
+	for i := 0; i <= 4; i++ {
+		rate.observe(1024*1024, start.Add(time.Duration(i)*time.Second))
+	}
+	before := rate.readAheadBytes(0)
+
+	// A long pause followed by a single read must not drag the rate down
+	resume := start.Add(4*time.Second + 2*rateIdleGap)
+	rate.observe(1024*1024, resume)
+	rate.observe(1024*1024, resume.Add(time.Second))
+	if after := rate.readAheadBytes(0); after != before {
+		t.Errorf("readAheadBytes() after pause = %d, want unchanged %d", after, before)
+	}
+}
@@ -0,0 +1,297 @@
+package pool
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/javi11/nntpcli"
+)
+
+// upstreamDialTimeout bounds connecting to a provider from a bound address
+const upstreamDialTimeout = 30 * time.Second
+
+// BindingKey identifies a provider in the bindings passed to SetProviders.
+func BindingKey(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// bindingClient dials providers that have a bind address from that local
+// IP or interface, and everything else as usual.
+//
+// nntpcli always dials with a default net.Dialer, so bound providers are
+// reached through a loopback relay: nntpcli connects to the relay in plain
+// text and the relay opens the real connection, including TLS, from the
+// bound address. Bound connections never fall back to the default route.
+type bindingClient struct {
+	nntpcli.Client
+	bindings map[string]string // host:port -> local IP or interface name
+
+	mu     sync.Mutex
+	relays map[string]*bindRelay
+	closed bool
+}
+
+func newBindingClient(bindings map[string]string) *bindingClient {
+	return &bindingClient{
+		Client:   nntpcli.New(),
+		bindings: bindings,
+		relays:   make(map[string]*bindRelay),
+	}
+}
+
+func (c *bindingClient) Dial(ctx context.Context, host string, port int, config ...nntpcli.DialConfig) (nntpcli.Connection, error) {
+	relay, err := c.relayFor(host, port, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if relay == nil {
+		return c.Client.Dial(ctx, host, port, config...)
+	}
+	return c.Client.Dial(ctx, "127.0.0.1", relay.listenPort(), config...)
+}
+
+func (c *bindingClient) DialTLS(ctx context.Context, host string, port int, insecureSSL bool, config ...nntpcli.DialConfig) (nntpcli.Connection, error) {
+	relay, err := c.relayFor(host, port, true, insecureSSL)
+	if err != nil {
+		return nil, err
+	}
+	if relay == nil {
+		return c.Client.DialTLS(ctx, host, port, insecureSSL, config...)
+	}
+	// The relay terminates TLS towards the provider
+	return c.Client.Dial(ctx, "127.0.0.1", relay.listenPort(), config...)
+}
+
+// relayFor returns the relay for a bound provider, starting it on first use,
+// or nil when the provider has no bind address.
+func (c *bindingClient) relayFor(host string, port int, useTLS, insecureSSL bool) (*bindRelay, error) {
+	bind, ok := c.bindings[BindingKey(host, port)]
+	if !ok || bind == "" {
+		return nil, nil
+	}
+
+	key := fmt.Sprintf("%s|%t|%t", BindingKey(host, port), useTLS, insecureSSL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("nntp dialer for %s is closed", host)
+	}
+	if relay, ok := c.relays[key]; ok {
+		return relay, nil
+	}
+
+	relay, err := startBindRelay(host, port, bind, useTLS, insecureSSL)
+	if err != nil {
+		return nil, err
+	}
+	c.relays[key] = relay
+	return relay, nil
+}
+
+// Close stops all relays. Connections already relayed are closed too.
+func (c *bindingClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for key, relay := range c.relays {
+		relay.close()
+		delete(c.relays, key)
+	}
+}
+
+// bindRelay forwards loopback connections to one provider from a bound address.
+type bindRelay struct {
+	listener    net.Listener
+	host        string
+	port        int
+	bind        string
+	useTLS      bool
+	insecureSSL bool
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+func startBindRelay(host string, port int, bind string, useTLS, insecureSSL bool) (*bindRelay, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("start nntp relay for %s: %w", host, err)
+	}
+	r := &bindRelay{
+		listener:    listener,
+		host:        host,
+		port:        port,
+		bind:        bind,
+		useTLS:      useTLS,
+		insecureSSL: insecureSSL,
+		conns:       make(map[net.Conn]struct{}),
+	}
+	slog.Info("Routing NNTP provider through bound address", "host", host, "port", port, "bind", bind)
+	go r.serve()
+	return r, nil
+}
+
+func (r *bindRelay) listenPort() int {
+	return r.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (r *bindRelay) serve() {
+	for {
+		local, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.forward(local)
+	}
+}
+
+func (r *bindRelay) forward(local net.Conn) {
+	if !r.track(local) {
+		local.Close()
+		return
+	}
+	defer r.untrack(local)
+
+	upstream, err := r.dialUpstream()
+	if err != nil {
+		// nntpcli sees the loopback connection close before the greeting
+		slog.Warn("NNTP dial from bound address failed", "host", r.host, "bind", r.bind, "error", err)
+		return
+	}
+	if !r.track(upstream) {
+		upstream.Close()
+		return
+	}
+	defer r.untrack(upstream)
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		// Unblock the other direction
+		dst.Close()
+		src.Close()
+		done <- struct{}{}
+	}
+	go pipe(upstream, local)
+	go pipe(local, upstream)
+	<-done
+	<-done
+}
+
+func (r *bindRelay) dialUpstream() (net.Conn, error) {
+	dialer, err := boundDialer(r.bind)
+	if err != nil {
+		return nil, err
+	}
+	dialer.Timeout = upstreamDialTimeout
+	dialer.KeepAlive = 30 * time.Second
+
+	conn, err := dialer.Dial("tcp", BindingKey(r.host, r.port))
+	if err != nil {
+		return nil, err
+	}
+	if !r.useTLS {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         r.host,
+		InsecureSkipVerify: r.insecureSSL,
+	})
+	tlsConn.SetDeadline(time.Now().Add(upstreamDialTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("tls handshake: %w", err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// track registers a relayed connection so close can shut it down. It returns
+// false once the relay is closed.
+func (r *bindRelay) track(conn net.Conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	r.conns[conn] = struct{}{}
+	return true
+}
+
+func (r *bindRelay) untrack(conn net.Conn) {
+	conn.Close()
+	r.mu.Lock()
+	delete(r.conns, conn)
+	r.mu.Unlock()
+}
+
+func (r *bindRelay) close() {
+	r.listener.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// boundDialer returns a dialer that connects from bind: a local IP, or the
+// name of a network interface. Interfaces are resolved on every dial so a VPN
+// that reconnects with a new address is picked up; a missing or down
+// interface fails the dial instead of using the default route.
+func boundDialer(bind string) (*net.Dialer, error) {
+	if ip := net.ParseIP(bind); ip != nil {
+		return &net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}}, nil
+	}
+
+	iface, err := net.InterfaceByName(bind)
+	if err != nil {
+		return nil, fmt.Errorf("bind interface %q: %w", bind, err)
+	}
+	if iface.Flags&net.FlagUp == 0 {
+		return nil, fmt.Errorf("bind interface %q is down", bind)
+	}
+	ip, err := interfaceIP(iface)
+	if err != nil {
+		return nil, err
+	}
+	return &net.Dialer{
+		LocalAddr: &net.TCPAddr{IP: ip},
+		Control:   bindToDeviceControl(iface.Name),
+	}, nil
+}
+
+// interfaceIP picks an interface's address, preferring IPv4 and skipping
+// link-local addresses.
+func interfaceIP(iface *net.Interface) (net.IP, error) {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("bind interface %q addresses: %w", iface.Name, err)
+	}
+	var v6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if v4 := ipNet.IP.To4(); v4 != nil {
+			return v4, nil
+		}
+		if v6 == nil {
+			v6 = ipNet.IP
+		}
+	}
+	if v6 != nil {
+		return v6, nil
+	}
+	return nil, fmt.Errorf("bind interface %q has no usable address", iface.Name)
+}
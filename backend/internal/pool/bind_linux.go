@@ -0,0 +1,22 @@
+//go:build linux
+
+package pool
+
+import "syscall"
+
+// bindToDeviceControl pins sockets to an interface with SO_BINDTODEVICE, so
+// traffic leaves through it even when the routing table would pick another
+// one (e.g. a VPN without policy routing). Requires CAP_NET_RAW on older
+// kernels.
+func bindToDeviceControl(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, name)
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package pool
+
+import "syscall"
+
+// bindToDeviceControl isn't supported on this platform; sockets are bound to
+// the interface's address only.
+func bindToDeviceControl(string) func(network, address string, c syscall.RawConn) error {
+	return nil
+}
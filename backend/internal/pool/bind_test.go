@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/javi11/nntpcli"
+)
+
+// startGreetingServer accepts connections, greets them like an NNTP server,
+// answers QUIT and reports each client's address.
+func startGreetingServer(t *testing.T) (*net.TCPAddr, <-chan net.Addr) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	remotes := make(chan net.Addr, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			remotes <- conn.RemoteAddr()
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte("200 test server ready\r\n"))
+				lines := bufio.NewScanner(conn)
+				for lines.Scan() {
+					if strings.HasPrefix(lines.Text(), "QUIT") {
+						conn.Write([]byte("205 bye\r\n"))
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr), remotes
+}
+
+func TestBindingClientRelaysBoundProviders(t *testing.T) {
+	addr, remotes := startGreetingServer(t)
+	client := newBindingClient(map[string]string{BindingKey("127.0.0.1", addr.Port): "127.0.0.1"})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := client.Dial(ctx, "127.0.0.1", addr.Port, nntpcli.DialConfig{})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if len(client.relays) != 1 {
+		t.Fatalf("expected the bound provider to use a relay, got %d relays", len(client.relays))
+	}
+	select {
+	case remote := <-remotes:
+		if ip := remote.(*net.TCPAddr).IP; !ip.Equal(net.ParseIP("127.0.0.1")) {
+			t.Errorf("provider saw connection from %v", ip)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("provider never saw a connection")
+	}
+}
+
+func TestBindingClientDialsUnboundProvidersDirectly(t *testing.T) {
+	addr, _ := startGreetingServer(t)
+	client := newBindingClient(map[string]string{BindingKey("news.example.com", 563): "127.0.0.1"})
+	defer client.Close()
+
+	conn, err := client.Dial(context.Background(), "127.0.0.1", addr.Port, nntpcli.DialConfig{})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	conn.Close()
+	if len(client.relays) != 0 {
+		t.Errorf("unbound provider went through a relay")
+	}
+}
+
+func TestBindingClientFailsWithoutInterface(t *testing.T) {
+	addr, remotes := startGreetingServer(t)
+	client := newBindingClient(map[string]string{BindingKey("127.0.0.1", addr.Port): "missing-vpn0"})
+	defer client.Close()
+
+	if _, err := client.Dial(context.Background(), "127.0.0.1", addr.Port, nntpcli.DialConfig{}); err == nil {
+		t.Fatal("expected dial through a missing interface to fail")
+	}
+	select {
+	case remote := <-remotes:
+		t.Fatalf("provider was reached from %v despite the missing interface", remote)
+	default:
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/javi11/nntpcli"
 	"github.com/javi11/nntppool"
 )
 
@@ -14,8 +16,10 @@ type Manager interface {
 	// GetPool returns the current connection pool or error if not available
 	GetPool() (nntppool.UsenetConnectionPool, error)
 
-	// SetProviders creates/recreates the pool with new providers
-	SetProviders(providers []nntppool.UsenetProviderConfig) error
+	// SetProviders creates/recreates the pool with new providers. bindings
+	// maps BindingKey(host, port) to the local IP or interface a provider's
+	// connections are made from
+	SetProviders(providers []nntppool.UsenetProviderConfig, bindings map[string]string) error
 
 	// ClearPool shuts down and removes the current pool
 	ClearPool() error
@@ -26,8 +30,10 @@ type Manager interface {
 
 // manager implements the Manager interface
 type manager struct {
-	mu   sync.RWMutex
-	pool nntppool.UsenetConnectionPool
+	mu      sync.RWMutex
+	pool    nntppool.UsenetConnectionPool
+	dialer  *bindingClient
+	fetched atomic.Int64
 }
 
 // NewManager creates a new pool manager
@@ -48,7 +54,7 @@ func (m *manager) GetPool() (nntppool.UsenetConnectionPool, error) {
 }
 
 // SetProviders creates/recreates the pool with new providers
-func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error {
+func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig, bindings map[string]string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -58,6 +64,7 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 		m.pool.Quit()
 		m.pool = nil
 	}
+	m.closeDialerLocked()
 
 	// Return early if no providers (clear pool scenario)
 	if len(providers) == 0 {
@@ -69,7 +76,13 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 	// Keep MinConnections > 0 to maintain warm connections for faster health checks
 	// MaxConnections is set per-provider from user config (UsenetSettings.Connections)
 	slog.Info("Creating NNTP connection pool", "provider_count", len(providers))
+	var nntpCli nntpcli.Client
+	if len(bindings) > 0 {
+		m.dialer = newBindingClient(bindings)
+		nntpCli = m.dialer
+	}
 	pool, err := nntppool.NewConnectionPool(nntppool.Config{
+		NntpCli:        nntpCli,
 		Providers:      providers,
 		Logger:         slog.Default(),
 		DelayType:      nntppool.DelayTypeFixed,
@@ -77,10 +90,11 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 		MinConnections: 2, // Keep 2 warm connections per provider for faster STAT commands
 	})
 	if err != nil {
+		m.closeDialerLocked()
 		return fmt.Errorf("failed to create NNTP connection pool: %w", err)
 	}
 
-	m.pool = pool
+	m.pool = &meteredPool{UsenetConnectionPool: pool, fetched: &m.fetched}
 	slog.Info("NNTP connection pool created successfully")
 	return nil
 }
@@ -95,10 +109,20 @@ func (m *manager) ClearPool() error {
 		m.pool.Quit()
 		m.pool = nil
 	}
+	m.closeDialerLocked()
 
 	return nil
 }
 
+// closeDialerLocked stops the relays of bound providers.
+// Must be called with m.mu held.
+func (m *manager) closeDialerLocked() {
+	if m.dialer != nil {
+		m.dialer.Close()
+		m.dialer = nil
+	}
+}
+
 // HasPool returns true if a pool is currently available
 func (m *manager) HasPool() bool {
 	m.mu.RLock()
@@ -106,3 +130,8 @@ func (m *manager) HasPool() bool {
 
 	return m.pool != nil
 }
+
+// FetchedBytes returns the total article bytes fetched since startup
+func (m *manager) FetchedBytes() int64 {
+	return m.fetched.Load()
+}
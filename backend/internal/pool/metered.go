@@ -0,0 +1,54 @@
+package pool
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/javi11/nntpcli"
+	"github.com/javi11/nntppool"
+)
+
+// UsageReporter exposes the number of article bytes fetched through a Manager.
+type UsageReporter interface {
+	// FetchedBytes returns the total decoded article bytes fetched since startup,
+	// across pool recreations
+	FetchedBytes() int64
+}
+
+// meteredPool counts the article bytes fetched through Body and BodyReader.
+// nntppool does not report which provider served an article, so bytes are
+// counted for the pool as a whole.
+type meteredPool struct {
+	nntppool.UsenetConnectionPool
+	fetched *atomic.Int64
+}
+
+func (p *meteredPool) Body(ctx context.Context, msgID string, w io.Writer, nntpGroups []string) (int64, error) {
+	n, err := p.UsenetConnectionPool.Body(ctx, msgID, w, nntpGroups)
+	if n > 0 {
+		p.fetched.Add(n)
+	}
+	return n, err
+}
+
+func (p *meteredPool) BodyReader(ctx context.Context, msgID string, nntpGroups []string) (nntpcli.ArticleBodyReader, error) {
+	r, err := p.UsenetConnectionPool.BodyReader(ctx, msgID, nntpGroups)
+	if err != nil || r == nil {
+		return r, err
+	}
+	return &meteredBodyReader{ArticleBodyReader: r, fetched: p.fetched}, nil
+}
+
+type meteredBodyReader struct {
+	nntpcli.ArticleBodyReader
+	fetched *atomic.Int64
+}
+
+func (r *meteredBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ArticleBodyReader.Read(p)
+	if n > 0 {
+		r.fetched.Add(int64(n))
+	}
+	return n, err
+}
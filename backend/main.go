@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -23,15 +24,36 @@ import (
 	"novastream/internal/pool"
 	"novastream/internal/webdav"
 	"novastream/services/accounts"
+	"novastream/services/artwork"
+	"novastream/services/artwork_warmer"
+	"novastream/services/audio_downloads"
+	"novastream/services/audio_offsets"
+	"novastream/services/availability"
+	"novastream/services/calendar_prefetch"
 	"novastream/services/debrid"
+	"novastream/services/devices"
+	"novastream/services/diagnostics"
 	"novastream/services/epg"
+	"novastream/services/external_subtitles"
+	"novastream/services/favorites"
+	"novastream/services/federation"
 	"novastream/services/history"
+	"novastream/services/hidden_titles"
+	"novastream/services/image_placeholders"
 	"novastream/services/indexer"
 	"novastream/services/invitations"
+	"novastream/services/jobs"
+	"novastream/services/live_events"
+	"novastream/services/maintenance"
+	"novastream/services/match_overrides"
+	"novastream/services/media_failures"
 	"novastream/services/metadata"
 	"novastream/services/playback"
 	"novastream/services/plex"
+	"novastream/services/remote"
 	"novastream/services/sessions"
+	"novastream/services/share_links"
+	"novastream/services/streaming"
 	"novastream/services/trakt"
 	"novastream/services/usenet"
 	user_settings "novastream/services/user_settings"
@@ -39,9 +61,30 @@ import (
 	"novastream/services/clients"
 	client_settings "novastream/services/client_settings"
 	content_preferences "novastream/services/content_preferences"
+	release_versions "novastream/services/release_versions"
+	"novastream/services/release_hints"
 	"novastream/services/scheduler"
+	"novastream/services/provider_usage"
+	"novastream/services/selection_stats"
+	"novastream/services/scrobble_outbox"
+	"novastream/services/saved_searches"
+	"novastream/services/search_retries"
+	"novastream/services/orphan_gc"
+	"novastream/services/playback_compat"
+	"novastream/services/playback_queue"
+	"novastream/services/playback_timing"
+	"novastream/services/spoiler_holds"
+	"novastream/services/stream_binding"
+	"novastream/services/stream_failover"
+	"novastream/services/subtitle_offsets"
+	"novastream/services/supervisor"
+	"novastream/services/sync_journal"
+	"novastream/services/title_notes"
 	"novastream/services/watchlist"
 	"novastream/utils"
+	"novastream/utils/diskspace"
+	"novastream/utils/mediatools"
+	"novastream/utils/sandbox"
 
 	"github.com/gorilla/mux"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -50,7 +93,7 @@ import (
 func main() {
 
 	demoMode := flag.Bool("demo", false, "serve curated public domain metadata instead of live feeds")
-	portOverride := flag.Int("port", 0, "override server port from config")
+	portOverride := flag.Int("port", 0, "override server port from config (ignored when server.listeners is set)")
 	flag.Parse()
 
 	fmt.Println("🚀 strmr Backend Starting...")
@@ -69,10 +112,18 @@ func main() {
 
 	// Init config manager and load settings (creates defaults if missing)
 	cfgManager := config.NewManager(configPath)
-	settings, err := cfgManager.Load()
+	settings, loadNotices, err := cfgManager.LoadWithNotices()
 	if err != nil {
 		log.Fatalf("failed to load settings: %v", err)
 	}
+	// Confine ffmpeg/ffprobe/yt-dlp before anything spawns them
+	sandbox.SetSettings(settings.Sandbox)
+	// Reserve free space before admitting NZBs and HLS sessions
+	diskspace.SetSettings(settings.DiskSpace)
+	// Turn off DV, HDR metadata and libfdk_aac when the configured ffmpeg lacks them
+	if settings.Transmux.Enabled {
+		mediatools.Set(mediatools.Detect(context.Background(), settings.Transmux.FFmpegPath, settings.Transmux.FFprobePath))
+	}
 
 	// Set up file logging with rotation
 	if settings.Log.File != "" {
@@ -102,14 +153,19 @@ func main() {
 	}
 
 	// Construct router
+	utils.SetCORSSettings(settings.CORS)
+	utils.SetAccessLogSettings(settings.AccessLog)
+	utils.SetBaseURL(settings.Server.BaseURL)
+	utils.SetTrustedProxies(settings.Server.TrustedProxies)
 	var r *mux.Router = utils.NewRouter()
 
 	// Register API routes
 	settingsHandler := handlers.NewSettingsHandlerWithDemoMode(cfgManager, *demoMode)
 	mdblistCfg := metadata.MDBListConfig{
-		APIKey:         settings.MDBList.APIKey,
-		Enabled:        settings.MDBList.Enabled,
-		EnabledRatings: settings.MDBList.EnabledRatings,
+		APIKey:           settings.MDBList.APIKey,
+		Enabled:          settings.MDBList.Enabled,
+		EnabledRatings:   settings.MDBList.EnabledRatings,
+		CompositeWeights: settings.MDBList.CompositeWeights,
 	}
 	metadataService := metadata.NewService(settings.Metadata.TVDBAPIKey, settings.Metadata.TMDBAPIKey, settings.Metadata.Language, settings.Cache.Directory, settings.Cache.MetadataTTLHours, *demoMode, mdblistCfg)
 	metadataHandler := handlers.NewMetadataHandler(metadataService, cfgManager)
@@ -117,6 +173,14 @@ func main() {
 	indexerService := indexer.NewService(cfgManager, metadataService, debridSearchService)
 	indexerHandler := handlers.NewIndexerHandler(indexerService, *demoMode)
 	indexerHandler.SetMetadataService(metadataService) // Enable episode resolver for pack size filtering
+
+	// Manual TVDB/TMDB match corrections used by metadata and indexer resolution
+	matchOverridesService, err := match_overrides.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise metadata match overrides: %v", err)
+	}
+	metadataService.SetMatchOverrides(matchOverridesService)
+	indexerHandler.SetMatchOverrides(matchOverridesService)
 	// Note: user settings service wiring happens later after userSettingsService is created
 	debridProxyService := debrid.NewProxyService(cfgManager)
 	// Create HealthService with ffprobe path for pre-resolved stream validation
@@ -131,6 +195,14 @@ func main() {
 	settingsHandler.SetMetadataService(metadataService)   // Enable hot reload of API keys
 	settingsHandler.SetDebridSearchService(debridSearchService) // Enable hot reload of scrapers
 
+	// Track traffic per debrid account and usenet provider against optional monthly quotas
+	providerUsageService, err := provider_usage.NewService(settings.Cache.Directory, cfgManager)
+	if err != nil {
+		log.Fatalf("failed to initialise provider usage tracking: %v", err)
+	}
+	providerUsageService.SetPoolManager(poolManager)
+	debridProxyService.SetUsageTracker(providerUsageService)
+
 	usenetService := usenet.NewService(cfgManager, poolManager)
 	streamRoot := filepath.Join(settings.Cache.Directory, "streams")
 	if err := os.MkdirAll(streamRoot, 0o755); err != nil {
@@ -152,10 +224,26 @@ func main() {
 			}
 		}
 	}
+	// Optional subsystems whose dependency is down at boot are retried in the
+	// background, so the server comes up degraded instead of exiting
+	startupCtx, stopStartup := context.WithCancel(context.Background())
+	subsystems := supervisor.New()
+
 	providers := config.ToNNTPProviders(settings.Usenet)
 	if len(providers) > 0 {
-		if err := poolManager.SetProviders(providers); err != nil {
-			log.Printf("warning: failed to initialize usenet pool: %v", err)
+		poolReady := subsystems.Start(startupCtx, "usenet pool", func(ctx context.Context) error {
+			// A settings reload may have rebuilt the pool since boot
+			if poolManager.HasPool() {
+				return nil
+			}
+			current, err := cfgManager.Load()
+			if err != nil {
+				return err
+			}
+			return poolManager.SetProviders(config.ToNNTPProviders(current.Usenet), config.NNTPBindings(current.Usenet))
+		})
+		if !poolReady {
+			log.Printf("warning: usenet pool unavailable; usenet streaming is disabled until it connects")
 		} else {
 			log.Printf("initialized usenet pool with %d provider(s)", len(providers))
 			if debugArticleID != "" {
@@ -219,11 +307,17 @@ func main() {
 		db := nzbSystem.Database()
 		userRepo := database.NewUserRepository(db.Connection())
 
-		handler, err := webdav.NewHandler(webdavConfig, nzbSystem.FileSystem(), nil, userRepo, configAdapter.GetConfigGetter())
-		if err != nil {
-			log.Fatalf("failed to create WebDAV handler: %v", err)
-		}
-		webdavHandler = handler.GetHTTPHandler()
+		// Answers 503 until the handler is created
+		pendingWebDAV := supervisor.NewPendingHandler("WebDAV")
+		webdavHandler = pendingWebDAV
+		subsystems.Start(startupCtx, "webdav", func(ctx context.Context) error {
+			handler, err := webdav.NewHandler(webdavConfig, nzbSystem.FileSystem(), nil, userRepo, configAdapter.GetConfigGetter())
+			if err != nil {
+				return fmt.Errorf("create WebDAV handler: %w", err)
+			}
+			pendingWebDAV.Set(handler.GetHTTPHandler())
+			return nil
+		})
 		fmt.Printf("📁 WebDAV endpoint enabled at %s\n", settings.WebDAV.Prefix)
 	}
 
@@ -232,7 +326,10 @@ func main() {
 	// Prequeue handler will be created later after historyService is available
 	var prequeueHandler *handlers.PrequeueHandler
 	usenetHandler := handlers.NewUsenetHandler(usenetService)
-	userService, err := users.NewService(settings.Cache.Directory)
+	// Profiles, watchlists, history and user settings live in the database;
+	// their JSON files in the cache directory are imported on first start
+	profileStore := database.NewDocumentStore(database.NewDocumentRepository(nzbSystem.Database().Connection()))
+	userService, err := users.NewServiceWithStore(settings.Cache.Directory, profileStore)
 	if err != nil {
 		log.Fatalf("failed to initialise users: %v", err)
 	}
@@ -255,17 +352,26 @@ func main() {
 	debugHandler := handlers.NewDebugHandler(log.New(os.Stdout, "[debug] ", log.LstdFlags))
 	logsHandler := handlers.NewLogsHandler(log.New(os.Stdout, "[logs] ", log.LstdFlags), settings.Log.File)
 
-	watchlistService, err := watchlist.NewService(settings.Cache.Directory)
+	watchlistService, err := watchlist.NewServiceWithStore(settings.Cache.Directory, profileStore)
 	if err != nil {
 		log.Fatalf("failed to initialise watchlist: %v", err)
 	}
 	watchlistHandler := handlers.NewWatchlistHandler(watchlistService, userService, *demoMode)
+	watchlistHandler.SetProfileLookup(userService)
+	// Background "ready to watch" checks for watchlist items
+	availabilityService := availability.NewService(indexerService, debridPlaybackService, playbackService)
+	watchlistHandler.SetAvailabilityService(availabilityService)
+	metadataHandler.SetWatchlistService(watchlistService)
 
-	userSettingsService, err := user_settings.NewService(settings.Cache.Directory)
+	userSettingsService, err := user_settings.NewServiceWithStore(settings.Cache.Directory, profileStore)
 	if err != nil {
 		log.Fatalf("failed to initialise user settings: %v", err)
 	}
 	userSettingsHandler := handlers.NewUserSettingsHandler(userSettingsService, userService, cfgManager)
+	settingsHandler.SetUserSettingsService(userSettingsService)
+	if err := settingsHandler.SyncCustomListTask(); err != nil {
+		log.Printf("warning: failed to sync custom list refresh task: %v", err)
+	}
 
 	// Initialize content preferences service for per-content language preferences
 	contentPreferencesService, err := content_preferences.NewService(settings.Cache.Directory)
@@ -295,18 +401,26 @@ func main() {
 	debridSearchService.SetClientSettingsProvider(clientSettingsService)
 	indexerService.SetClientSettingsProvider(clientSettingsService)
 
-	historyService, err := history.NewService(settings.Cache.Directory)
+	historyService, err := history.NewServiceWithStore(settings.Cache.Directory, profileStore)
 	if err != nil {
 		log.Fatalf("failed to initialise watch history: %v", err)
 	}
 	// Wire up metadata service for continue watching generation
 	historyService.SetMetadataService(metadataService)
+	// Per-profile continue watching retention policies
+	historyService.SetRetentionPolicySource(userSettingsService)
 
 	// Wire up Trakt scrobbler for syncing watch history
 	traktClient := trakt.NewClient("", "") // Credentials are per-account now
 	traktScrobbler := trakt.NewScrobbler(traktClient, cfgManager)
 	traktScrobbler.SetUserService(userService) // For per-profile Trakt account lookup
-	historyService.SetTraktScrobbler(traktScrobbler)
+
+	// Queue scrobbles in a persistent outbox so Trakt outages and expired tokens don't lose history
+	scrobbleOutbox, err := scrobble_outbox.NewService(settings.Cache.Directory, traktScrobbler)
+	if err != nil {
+		log.Fatalf("failed to initialise scrobble outbox: %v", err)
+	}
+	historyService.SetTraktScrobbler(scrobbleOutbox)
 
 	// Wire up history service to metadata handler for hideWatched filtering
 	metadataHandler.SetHistoryService(historyService)
@@ -326,7 +440,19 @@ func main() {
 
 	// Create composite streaming provider that handles both usenet and debrid
 	debridStreamingProvider := debrid.NewStreamingProvider(cfgManager)
-	compositeProvider := debrid.NewCompositeProvider(debridStreamingProvider, nzbSystem)
+	debridStreamingProvider.SetUsageTracker(providerUsageService)
+	// Complete downloads in the local stream cache are served from disk first
+	localStreamCache := streaming.NewLocalCache(filepath.Join(settings.Cache.Directory, "local_streams"))
+	diskspace.RegisterEvictor("local-streams", localStreamCache.Evict)
+	compositeProvider := debrid.NewCompositeProvider(localStreamCache, debridStreamingProvider, nzbSystem)
+
+	// QA installs can degrade the streaming path on purpose to test clients
+	streaming.SetNetworkSimulation(settings.Developer.NetworkSimulation)
+	var streamProvider streaming.Provider = compositeProvider
+	if settings.Developer.NetworkSimulation.Enabled {
+		log.Printf("warning: network simulation is enabled; streams get artificial latency, throughput caps and read errors")
+		streamProvider = streaming.NewNetworkSimulator(compositeProvider)
+	}
 
 	// Create video handler with composite provider
 	videoHandler := handlers.NewVideoHandlerWithProvider(
@@ -334,12 +460,19 @@ func main() {
 		settings.Transmux.FFmpegPath,
 		settings.Transmux.FFprobePath,
 		settings.Transmux.HLSTempDirectory,
-		compositeProvider,
+		streamProvider,
 	)
 
+	if hlsManager := videoHandler.GetHLSManager(); hlsManager != nil {
+		hlsManager.SetTranscodeCacheSize(int64(settings.Transmux.TranscodeCacheMB) << 20)
+	}
+
 	if videoHandler != nil && settings.WebDAV.Enabled {
-		localBaseURL := fmt.Sprintf("http://127.0.0.1:%d", settings.Server.Port)
-		videoHandler.ConfigureLocalWebDAVAccess(localBaseURL, settings.WebDAV.Prefix, settings.WebDAV.Username, settings.WebDAV.Password)
+		if localBaseURL := settings.Server.LocalBaseURL(); localBaseURL != "" {
+			videoHandler.ConfigureLocalWebDAVAccess(localBaseURL, settings.WebDAV.Prefix, settings.WebDAV.Username, settings.WebDAV.Password)
+		} else {
+			log.Printf("warning: no TCP listener serves streaming routes; ffprobe can't read usenet files over WebDAV")
+		}
 	}
 
 	// Wire up prequeue handler with video prober, HLS creator, metadata prober, user settings, and config
@@ -354,6 +487,7 @@ func main() {
 		prequeueHandler.SetClientSettingsService(clientSettingsService)
 		prequeueHandler.SetConfigManager(cfgManager)
 		prequeueHandler.SetMetadataService(metadataService) // For episode counting in pack size filtering
+		prequeueHandler.SetMatchOverrides(matchOverridesService)
 
 		// Wire up subtitle pre-extraction for direct streaming (SDR content)
 		if subtitleMgr := videoHandler.GetSubtitleExtractManager(); subtitleMgr != nil {
@@ -372,9 +506,18 @@ func main() {
 
 	liveHandler := handlers.NewLiveHandler(nil, settings.Transmux.Enabled, settings.Transmux.FFmpegPath, settings.Live.PlaylistCacheTTLHours, settings.Live.ProbeSizeMB, settings.Live.AnalyzeDurationSec, settings.Live.LowLatency, cfgManager)
 
+	// Scheduled live event recordings (sports/event mode)
+	liveEventsService, err := live_events.NewService(settings.Cache.Directory, live_events.FFmpegRecorder{FFmpegPath: settings.Transmux.FFmpegPath})
+	if err != nil {
+		log.Fatalf("failed to initialise live events service: %v", err)
+	}
+	liveHandler.SetEventsService(liveEventsService)
+
 	// Create EPG service and handler for Electronic Program Guide
 	epgService := epg.NewService(settings.Cache.Directory, cfgManager)
 	epgHandler := handlers.NewEPGHandler(epgService)
+	// Live channels can start from the beginning of the current program
+	videoHandler.SetLiveTimeshift(liveEventsService, epgService)
 
 	// Create subtitles handler for external subtitle search
 	subtitlesHandler := handlers.NewSubtitlesHandlerWithConfig(cfgManager)
@@ -383,6 +526,39 @@ func main() {
 	imageHandler := handlers.NewImageHandler(settings.Cache.Directory)
 	settingsHandler.SetImageHandler(imageHandler) // Enable clearing image cache
 
+	// Retry rate-limited artwork and substitute TMDB/alternate artwork for dead TVDB URLs
+	artworkService, err := artwork.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise artwork service: %v", err)
+	}
+	imageHandler.SetArtworkService(artworkService)
+	metadataHandler.SetArtworkResolver(artworkService)
+
+	// Dominant color and BlurHash placeholders for artwork cached by the image proxy
+	imagePlaceholders, err := image_placeholders.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise image placeholders: %v", err)
+	}
+	imageHandler.SetPlaceholderService(imagePlaceholders)
+	metadataHandler.SetImagePlaceholders(imagePlaceholders)
+
+	// Change journal for incremental client sync of watchlist/history/progress
+	syncJournal, err := sync_journal.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise sync journal: %v", err)
+	}
+	watchlistService.SetChangeRecorder(syncJournal)
+	historyService.SetChangeRecorder(syncJournal)
+	syncHandler := handlers.NewSyncHandler(syncJournal, watchlistService, historyService, userService)
+
+	// Pinned release versions per title (version selector for playback)
+	releaseVersionsService, err := release_versions.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise release versions: %v", err)
+	}
+	playbackHandler.SetReleaseVersions(releaseVersionsService, userService)
+	releaseVersionsHandler := handlers.NewReleaseVersionsHandler(releaseVersionsService, userService)
+
 	api.Register(
 		r,
 		settingsHandler,
@@ -405,11 +581,351 @@ func main() {
 		clientsHandler,
 		contentPreferencesHandler,
 		imageHandler,
+		syncHandler,
+		releaseVersionsHandler,
 		accountsService,
 		sessionsService,
 		userService,
 	)
 
+	// Aggregate ffprobe/transcode failures in the NZB system database for the admin summary
+	mediaFailuresService, err := media_failures.NewService(database.NewMediaFailureRepository(nzbSystem.Database().Connection()))
+	if err != nil {
+		log.Fatalf("failed to initialise media failure metrics: %v", err)
+	}
+	if videoHandler != nil {
+		videoHandler.SetFailureRecorder(mediaFailuresService)
+	}
+	api.RegisterMediaFailureRoutes(r, handlers.NewMediaFailuresHandler(mediaFailuresService), sessionsService)
+
+	// Recent ffmpeg stderr per transmux/HLS stream for diagnosing transcode failures
+	if videoHandler != nil {
+		api.RegisterFFmpegLogRoutes(r, handlers.NewFFmpegLogsHandler(videoHandler), sessionsService)
+	}
+
+	// Compare release selection strategies by their outcomes
+	selectionStatsService, err := selection_stats.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise selection stats: %v", err)
+	}
+	prequeueHandler.SetSelectionRecorder(selectionStatsService)
+	api.RegisterSelectionStatsRoutes(r, handlers.NewSelectionStatsHandler(selectionStatsService), sessionsService)
+
+	// Per-stage playback start latency, from search to the first HLS segment
+	playbackTimingService, err := playback_timing.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise playback timings: %v", err)
+	}
+	prequeueHandler.SetStartTimingRecorder(playbackTimingService)
+	if videoHandler != nil && videoHandler.GetHLSManager() != nil {
+		videoHandler.GetHLSManager().SetFirstSegmentRecorder(playbackTimingService)
+	}
+	api.RegisterPlaybackTimingRoutes(r, handlers.NewPlaybackTimingHandler(playbackTimingService), sessionsService)
+
+	api.RegisterScrobbleOutboxRoutes(r, handlers.NewScrobbleOutboxHandler(scrobbleOutbox), sessionsService)
+	api.RegisterProviderUsageRoutes(r, handlers.NewProviderUsageHandler(providerUsageService), sessionsService)
+	api.RegisterMatchOverrideRoutes(r, handlers.NewMatchOverridesHandler(matchOverridesService), sessionsService)
+
+	// Persistent device registry with per-device transcode policy and bitrate caps
+	devicesService, err := devices.NewService(database.NewDeviceRepository(nzbSystem.Database().Connection()), userService)
+	if err != nil {
+		log.Fatalf("failed to initialise device registry: %v", err)
+	}
+	if videoHandler != nil {
+		videoHandler.SetDeviceService(devicesService)
+	}
+	prequeueHandler.SetDeviceService(devicesService)
+	// Generated test clips whose results set each device's capability profile
+	compatService, err := playback_compat.NewService(settings.Cache.Directory, settings.Transmux.FFmpegPath)
+	if err != nil {
+		log.Fatalf("failed to initialise playback compatibility suite: %v", err)
+	}
+	api.RegisterDeviceRoutes(r, handlers.NewDevicesHandler(devicesService), handlers.NewPlaybackCompatHandler(compatService, devicesService), sessionsService)
+
+	// Maintenance mode refuses new playback while active streams drain before a restart
+	maintenanceService := maintenance.NewService(func() int {
+		active := handlers.GetStreamTracker().Count()
+		if videoHandler != nil {
+			active += videoHandler.GetHLSManager().ActiveSessionCount(2 * time.Minute)
+		}
+		return active
+	})
+	r.Use(api.MaintenanceMiddleware(maintenanceService))
+	api.RegisterMaintenanceRoutes(r, handlers.NewMaintenanceHandler(maintenanceService), sessionsService)
+
+	// Optionally refuse stream URLs replayed from outside the networks and devices the session is used from
+	r.Use(api.StreamBindingMiddleware(stream_binding.NewService(cfgManager), sessionsService))
+
+	// Per-profile favorites, kept apart from the watchlist
+	favoritesService, err := favorites.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise favorites: %v", err)
+	}
+	api.RegisterFavoriteRoutes(r, handlers.NewFavoritesHandler(favoritesService, watchlistService, userService), sessionsService, userService)
+
+	// Notes on titles shared by the profiles of a household
+	titleNotesService, err := title_notes.NewService(database.NewTitleNoteRepository(nzbSystem.Database().Connection()), userService)
+	if err != nil {
+		log.Fatalf("failed to initialise title notes: %v", err)
+	}
+	api.RegisterTitleNoteRoutes(r, handlers.NewTitleNotesHandler(titleNotesService), sessionsService, userService)
+
+	// Checks that the reverse proxy in front of the server keeps streams working
+	api.RegisterProxyCheckRoutes(r, handlers.NewProxyCheckHandler(nil), sessionsService)
+
+	// Deleted profiles can be restored until purged; purging removes their data
+	userService.OnPurge(func(userID string) {
+		if err := watchlistService.DeleteUser(userID); err != nil {
+			log.Printf("[users] failed to purge watchlist for %s: %v", userID, err)
+		}
+		if err := favoritesService.DeleteUser(userID); err != nil {
+			log.Printf("[users] failed to purge favorites for %s: %v", userID, err)
+		}
+		if err := titleNotesService.DeleteUser(userID); err != nil {
+			log.Printf("[users] failed to purge title notes for %s: %v", userID, err)
+		}
+		if err := historyService.DeleteUser(userID); err != nil {
+			log.Printf("[users] failed to purge history for %s: %v", userID, err)
+		}
+		if err := userSettingsService.Delete(userID); err != nil {
+			log.Printf("[users] failed to purge settings for %s: %v", userID, err)
+		}
+	})
+	profileExporter := handlers.NewProfileExporter(watchlistService, historyService, userSettingsService)
+	profileExporter.SetFavorites(favoritesService)
+	usersHandler.SetExporter(profileExporter)
+
+	// Scheduled maintenance jobs with persisted run status and an admin API
+	jobsService, err := jobs.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise jobs: %v", err)
+	}
+	for _, job := range []jobs.Job{
+		{
+			ID:          "invitations-cleanup",
+			Name:        "Invitation cleanup",
+			Description: "Deletes invitations that expired or were used more than 30 days ago",
+			Schedule:    "30 3 * * *",
+			Run: func(ctx context.Context) error {
+				removed, err := invitationsService.CleanupExpired(30 * 24 * time.Hour)
+				if removed > 0 {
+					log.Printf("[jobs] removed %d old invitations", removed)
+				}
+				return err
+			},
+		},
+		{
+			ID:          "profile-purge",
+			Name:        "Deleted profile purge",
+			Description: "Permanently removes deleted profiles and their data once the restore window has passed",
+			Schedule:    "45 3 * * *",
+			Run: func(ctx context.Context) error {
+				purged, err := userService.PurgeExpired()
+				if len(purged) > 0 {
+					log.Printf("[jobs] purged %d deleted profiles", len(purged))
+				}
+				return err
+			},
+		},
+	} {
+		if err := jobsService.Register(job); err != nil {
+			log.Fatalf("failed to register job %s: %v", job.ID, err)
+		}
+	}
+	api.RegisterJobRoutes(r, handlers.NewJobsHandler(jobsService), sessionsService)
+
+	// Drop queue and health rows whose files are gone, and spooled NZBs no row uses
+	orphanGCService := orphan_gc.NewService(database.NewOrphanRepository(nzbSystem.Database().Connection()), nzbSystem.FileSystem(), filepath.Join(os.TempDir(), "novastream-nzbs"))
+	if err := jobsService.Register(jobs.Job{
+		ID:          "orphan-gc",
+		Name:        "Orphaned record cleanup",
+		Description: "Removes import queue and file health records whose files are gone, and spooled NZBs that no record uses",
+		Schedule:    "15 4 * * *",
+		Timeout:     time.Hour,
+		Run:         orphanGCService.Run,
+	}); err != nil {
+		log.Fatalf("failed to register orphan cleanup job: %v", err)
+	}
+	api.RegisterOrphanGCRoutes(r, handlers.NewOrphanGCHandler(orphanGCService), sessionsService)
+
+	// "Likely available" hints for browse screens from titles recent searches found releases for
+	releaseHintsService, err := release_hints.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise release hints: %v", err)
+	}
+	indexerService.SetReleaseRecorder(releaseHintsService)
+	if err := jobsService.Register(jobs.Job{
+		ID:          "release-hints",
+		Name:        "Release hint index",
+		Description: "Rebuilds the availability hint filter, dropping titles without releases seen in the last two weeks",
+		Schedule:    "30 * * * *",
+		Timeout:     5 * time.Minute,
+		Run:         releaseHintsService.Rebuild,
+	}); err != nil {
+		log.Fatalf("failed to register release hints job: %v", err)
+	}
+	api.RegisterReleaseHintRoutes(r, handlers.NewReleaseHintsHandler(releaseHintsService), sessionsService)
+
+	// Remember per-release subtitle delay corrections for sidecar VTTs
+	subtitleOffsetsService, err := subtitle_offsets.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise subtitle offsets: %v", err)
+	}
+	if videoHandler != nil {
+		videoHandler.SetSubtitleOffsetStore(subtitleOffsetsService)
+	}
+
+	// Remember per-release audio sync corrections for remuxes with a consistent delay
+	audioOffsetsService, err := audio_offsets.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise audio offsets: %v", err)
+	}
+	if videoHandler != nil {
+		videoHandler.SetAudioOffsetStore(audioOffsetsService)
+	}
+
+	// User-uploaded SRT/ASS subtitles, offered on every release of their title/episode
+	externalSubtitlesService, err := external_subtitles.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise external subtitles: %v", err)
+	}
+	prequeueHandler.SetExternalSubtitles(externalSubtitlesService)
+	api.RegisterExternalSubtitleRoutes(r, handlers.NewExternalSubtitlesHandler(externalSubtitlesService), sessionsService)
+
+	// "Don't spoil" holds flag episodes for the rest of the household
+	spoilerHoldsService, err := spoiler_holds.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise spoiler holds: %v", err)
+	}
+	spoilerHoldsService.SetProfileDirectory(userService)
+	spoilerHoldsService.SetWatchedEpisodesSource(historyService)
+	metadataHandler.SetSpoilerAnnotator(spoilerHoldsService)
+	api.RegisterSpoilerHoldRoutes(r, handlers.NewSpoilerHoldsHandler(spoilerHoldsService, userService), sessionsService, userService)
+
+	hiddenTitlesService, err := hidden_titles.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise hidden titles: %v", err)
+	}
+	metadataHandler.SetHiddenTitles(hiddenTitlesService)
+	metadataHandler.SetKidsProfiles(userService)
+	indexerHandler.SetKidsProfiles(userService)
+	api.RegisterHiddenTitleRoutes(r, handlers.NewHiddenTitlesHandler(hiddenTitlesService, userService), sessionsService, userService)
+
+	// Playback queue: items played back-to-back, the next one resolved just in time
+	playbackQueueService, err := playback_queue.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise playback queue: %v", err)
+	}
+	prequeueHandler.SetPlaybackQueue(playbackQueueService)
+	historyHandler.SetPlaybackQueue(playbackQueueService)
+	historyHandler.SetAvailabilityService(availabilityService)
+	api.RegisterPlaybackQueueRoutes(r, handlers.NewPlaybackQueueHandler(playbackQueueService, userService), sessionsService, userService)
+
+	// Keep searching for just-aired episodes that had no releases yet
+	searchRetriesService, err := search_retries.NewService(settings.Cache.Directory, cfgManager, indexerService)
+	if err != nil {
+		log.Fatalf("failed to initialise search retries: %v", err)
+	}
+	prequeueHandler.SetSearchRetries(searchRetriesService)
+	api.RegisterSearchRetryRoutes(r, handlers.NewSearchRetriesHandler(searchRetriesService, userService), sessionsService, userService)
+
+	// Re-run saved release searches and collect newly appeared matches
+	savedSearchesService, err := saved_searches.NewService(settings.Cache.Directory, indexerService)
+	if err != nil {
+		log.Fatalf("failed to initialise saved searches: %v", err)
+	}
+	if err := jobsService.Register(jobs.Job{
+		ID:          "saved-searches",
+		Name:        "Saved searches",
+		Description: "Re-runs saved release searches and records new matching releases",
+		Schedule:    "0 */6 * * *",
+		Timeout:     2 * time.Hour,
+		Run:         savedSearchesService.CheckAll,
+	}); err != nil {
+		log.Fatalf("failed to register saved searches job: %v", err)
+	}
+	api.RegisterSavedSearchRoutes(r, handlers.NewSavedSearchesHandler(savedSearchesService, userService), sessionsService, userService)
+
+	// Resolve new episodes from linked Trakt calendars shortly after they air
+	calendarPrefetchService, err := calendar_prefetch.NewService(settings.Cache.Directory, cfgManager, traktClient, userService, indexerService, playbackService)
+	if err != nil {
+		log.Fatalf("failed to initialise calendar prefetch: %v", err)
+	}
+	calendarPrefetchService.SetWarmer(prequeueHandler)
+	if err := jobsService.Register(jobs.Job{
+		ID:          "calendar-prefetch",
+		Name:        "Trakt calendar prefetch",
+		Description: "Reads the Trakt calendar of accounts with prefetch enabled and resolves episodes shortly after they air",
+		Schedule:    "*/15 * * * *",
+		Timeout:     30 * time.Minute,
+		Run:         calendarPrefetchService.Run,
+	}); err != nil {
+		log.Fatalf("failed to register calendar prefetch job: %v", err)
+	}
+	api.RegisterCalendarPrefetchRoutes(r, handlers.NewCalendarPrefetchHandler(calendarPrefetchService, userService), sessionsService, userService)
+
+	// Expiring, view-limited watch links to a single resolved stream
+	shareLinksService, err := share_links.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise share links: %v", err)
+	}
+	if err := jobsService.Register(jobs.Job{
+		ID:          "share-links-cleanup",
+		Name:        "Share link cleanup",
+		Description: "Deletes share links that expired or were revoked more than a week ago",
+		Schedule:    "45 3 * * *",
+		Run: func(ctx context.Context) error {
+			removed, err := shareLinksService.PruneExpired()
+			if removed > 0 {
+				log.Printf("[jobs] removed %d old share links", removed)
+			}
+			return err
+		},
+	}); err != nil {
+		log.Fatalf("failed to register share links job: %v", err)
+	}
+	shareLinksHandler := handlers.NewShareLinksHandler(shareLinksService, userService)
+	if videoHandler != nil {
+		shareLinksHandler.SetStreamer(videoHandler)
+	}
+	api.RegisterShareLinkRoutes(r, shareLinksHandler, sessionsService, userService)
+
+	// Extract the audio of a title into the downloads area for offline listening
+	var audioDownloadsService *audio_downloads.Service
+	if videoHandler != nil {
+		audioDownloadsService, err = audio_downloads.NewService(settings.Cache.Directory, videoHandler)
+		if err != nil {
+			log.Fatalf("failed to initialise audio downloads: %v", err)
+		}
+		api.RegisterAudioDownloadRoutes(r, handlers.NewAudioDownloadsHandler(audioDownloadsService, userService), sessionsService, userService)
+	}
+
+	// Switch usenet playback that hits missing articles to a cached debrid release
+	streamFailoverService := stream_failover.NewService(cfgManager, indexerService, playbackService)
+	prequeueHandler.SetStreamFailover(streamFailoverService)
+	if videoHandler != nil {
+		videoHandler.SetStreamFailover(streamFailoverService)
+	}
+
+	// Federation: sync profiles, watchlists and history with other strmr servers
+	federationService, err := federation.NewService(settings.Cache.Directory, federation.Stores{
+		Profiles:  userService,
+		Watchlist: watchlistService,
+		History:   historyService,
+		Journal:   syncJournal,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialise federation: %v", err)
+	}
+	api.RegisterFederationRoutes(r, handlers.NewFederationHandler(federationService), sessionsService)
+
+	// Companion remote control (phone → TV pairing and command relay)
+	remoteService, err := remote.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise remote control: %v", err)
+	}
+	api.RegisterRemoteRoutes(r, handlers.NewRemoteHandler(remoteService), sessionsService)
+
 	// Register Trakt accounts API routes
 	traktAccountsHandler := handlers.NewTraktAccountsHandler(cfgManager, traktClient, userService, accountsService)
 	api.RegisterTraktRoutes(r, traktAccountsHandler, sessionsService)
@@ -421,6 +937,10 @@ func main() {
 	// Create scheduler service for background tasks
 	schedulerService := scheduler.NewService(cfgManager, plexClient, traktClient, watchlistService)
 	schedulerService.SetEPGService(epgService)
+	schedulerService.SetMetadataService(metadataService)
+	schedulerService.SetUserSettingsService(userSettingsService)
+	// Keep continue-watching and watchlist artwork in the image proxy cache
+	schedulerService.SetArtworkWarmer(artwork_warmer.NewService(userService, historyService, watchlistService, imageHandler))
 	scheduledTasksHandler := handlers.NewScheduledTasksHandler(cfgManager, schedulerService)
 
 	// Register admin UI routes
@@ -433,6 +953,23 @@ func main() {
 	adminUIHandler.SetSessionsService(sessionsService)
 	adminUIHandler.SetClientsService(clientsService)
 	adminUIHandler.SetClientSettingsService(clientSettingsService)
+	adminUIHandler.SetMaintenanceService(maintenanceService)
+	adminUIHandler.SetDevicesService(devicesService)
+
+	// Startup self-diagnostics, so users can see why a feature is disabled
+	diagnosticsService, err := diagnostics.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise diagnostics: %v", err)
+	}
+	adminUIHandler.SetDiagnosticsService(diagnosticsService)
+	adminUIHandler.SetSubsystemStatus(subsystems)
+	go func(settings config.Settings) {
+		report, err := diagnosticsService.Run(context.Background(), settings, loadNotices)
+		if err != nil {
+			log.Printf("[diagnostics] failed to save report: %v", err)
+		}
+		log.Printf("[diagnostics] startup checks finished: %d errors, %d warnings (see /admin/api/diagnostics)", report.Errors, report.Warnings)
+	}(settings)
 
 	// Login/logout routes (no auth required)
 	r.HandleFunc("/admin/login", adminUIHandler.LoginPage).Methods(http.MethodGet)
@@ -450,6 +987,8 @@ func main() {
 	r.HandleFunc("/admin/accounts", adminUIHandler.RequireAuth(adminUIHandler.AccountsPage)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/schema", adminUIHandler.RequireAuth(adminUIHandler.GetSchema)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/status", adminUIHandler.RequireAuth(adminUIHandler.GetStatus)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/diagnostics", adminUIHandler.RequireAuth(adminUIHandler.GetDiagnostics)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/subsystems", adminUIHandler.RequireAuth(adminUIHandler.GetSubsystems)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/streams", adminUIHandler.RequireAuth(adminUIHandler.GetStreams)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/debrid-status", adminUIHandler.RequireAuth(adminUIHandler.GetDebridStatus)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/user-settings", adminUIHandler.RequireAuth(adminUIHandler.GetUserSettings)).Methods(http.MethodGet)
@@ -500,11 +1039,20 @@ func main() {
 	r.HandleFunc("/admin/api/accounts/default-password", adminUIHandler.RequireAuth(adminUIHandler.HasDefaultPassword)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/profiles/reassign", adminUIHandler.RequireAuth(adminUIHandler.ReassignProfile)).Methods(http.MethodPut)
 
+	// Bulk household setup (master account only)
+	r.HandleFunc("/admin/api/bulk/profiles", adminUIHandler.RequireMasterAuth(adminUIHandler.BulkCreateProfiles)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/api/bulk/profiles/copy-settings", adminUIHandler.RequireMasterAuth(adminUIHandler.CopyProfileSettings)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/api/bulk/devices/apply-template", adminUIHandler.RequireMasterAuth(adminUIHandler.ApplyDeviceTemplate)).Methods(http.MethodPost)
+
 	// Invitation link management endpoints (master account only)
 	r.HandleFunc("/admin/api/invitations", adminUIHandler.RequireMasterAuth(adminUIHandler.ListInvitations)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/invitations", adminUIHandler.RequireMasterAuth(adminUIHandler.CreateInvitation)).Methods(http.MethodPost)
 	r.HandleFunc("/admin/api/invitations", adminUIHandler.RequireMasterAuth(adminUIHandler.DeleteInvitation)).Methods(http.MethodDelete)
 
+	// Admin UI session management (master sees all sessions, others their own)
+	r.HandleFunc("/admin/api/sessions", adminUIHandler.RequireAuth(adminUIHandler.ListAdminSessions)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/sessions", adminUIHandler.RequireAuth(adminUIHandler.RevokeAdminSession)).Methods(http.MethodDelete)
+
 	// Public registration endpoints (no auth required)
 	r.HandleFunc("/register", adminUIHandler.RegisterPage).Methods(http.MethodGet)
 	r.HandleFunc("/api/register/validate", adminUIHandler.ValidateInvitation).Methods(http.MethodGet)
@@ -590,6 +1138,7 @@ func main() {
 	r.HandleFunc("/admin/api/scheduled-tasks/{taskID}", adminUIHandler.RequireMasterAuth(scheduledTasksHandler.DeleteTask)).Methods(http.MethodDelete)
 	r.HandleFunc("/admin/api/scheduled-tasks/{taskID}/run", adminUIHandler.RequireMasterAuth(scheduledTasksHandler.RunTaskNow)).Methods(http.MethodPost)
 	r.HandleFunc("/admin/api/scheduled-tasks/{taskID}/toggle", adminUIHandler.RequireMasterAuth(scheduledTasksHandler.ToggleTask)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/api/custom-lists/status", adminUIHandler.RequireMasterAuth(scheduledTasksHandler.ListCustomListStatus)).Methods(http.MethodGet)
 
 	fmt.Println("📊 Admin dashboard available at /admin")
 
@@ -669,8 +1218,7 @@ func main() {
 		fmt.Printf("✅ WebDAV mounted at %s\n", settings.WebDAV.Prefix)
 	}
 
-	addr := fmt.Sprintf("%s:%d", settings.Server.Host, settings.Server.Port)
-	fmt.Printf("Server starting on %s\n", addr)
+	listeners := settings.Server.EffectiveListeners()
 
 	// Log warning if master account has default password
 	if accountsService.HasDefaultPassword() {
@@ -690,13 +1238,29 @@ func main() {
 		fmt.Println("")
 	}
 
-	// Create HTTP server with timeouts
-	srv := &http.Server{
-		Addr:         addr,
-		Handler:      r,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 0, // No write timeout for streaming
-		IdleTimeout:  120 * time.Second,
+	// Create an HTTP server with timeouts for each listener
+	servers := make([]*http.Server, 0, len(listeners))
+	netListeners := make([]net.Listener, 0, len(listeners))
+	for _, listener := range listeners {
+		ln, err := api.Listen(listener)
+		if err != nil {
+			log.Fatalf("failed to listen on %s: %v", listener.Address, err)
+		}
+		netListeners = append(netListeners, ln)
+		servers = append(servers, &http.Server{
+			Handler:      utils.AccessLogMiddleware(utils.APIVersionMiddleware(api.ListenerScope(r, listener))),
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 0, // No write timeout for streaming
+			IdleTimeout:  120 * time.Second,
+		})
+
+		routes := "admin + streaming"
+		if listener.DisableAdmin {
+			routes = "streaming only"
+		} else if listener.DisableStreaming {
+			routes = "admin only"
+		}
+		fmt.Printf("Server starting on %s (%s)\n", listener.Address, routes)
 	}
 
 	// Setup graceful shutdown
@@ -708,12 +1272,43 @@ func main() {
 		log.Printf("Warning: failed to start scheduler service: %v", err)
 	}
 
-	// Start server in goroutine
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
-		}
-	}()
+	// Run scheduled maintenance jobs
+	jobsService.Start(context.Background())
+
+	// Retry queued Trakt scrobbles in the background
+	scrobbleOutbox.Start(context.Background())
+
+	// Sample provider traffic and enforce monthly quotas in the background
+	providerUsageService.Start(context.Background())
+
+	// Start recordings for scheduled live events
+	liveEventsService.Start(context.Background())
+
+	// Expire and auto-complete continue watching items per profile policy
+	historyService.StartRetentionJob(context.Background())
+
+	// Keep TMDB's image base URL and sizes current for artwork URLs
+	metadataService.StartTMDBConfigRefresh(context.Background())
+
+	// Retry searches for just-aired episodes in the background
+	searchRetriesService.Start(context.Background())
+
+	// Run queued audio extractions in the background
+	if audioDownloadsService != nil {
+		audioDownloadsService.Start(context.Background())
+	}
+
+	// Pull changes from federated servers in the background
+	federationService.Start(context.Background())
+
+	// Start servers in goroutines
+	for i, srv := range servers {
+		go func(srv *http.Server, ln net.Listener) {
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Server error: %v", err)
+			}
+		}(srv, netListeners[i])
+	}
 
 	// Wait for shutdown signal
 	<-shutdownChan
@@ -728,6 +1323,19 @@ func main() {
 	if err := schedulerService.Stop(shutdownCtx); err != nil {
 		log.Printf("Scheduler shutdown error: %v", err)
 	}
+	jobsService.Stop()
+	stopStartup()
+	subsystems.Wait()
+	scrobbleOutbox.Stop()
+	providerUsageService.Stop()
+	liveEventsService.Stop()
+	historyService.StopRetentionJob()
+	metadataService.StopTMDBConfigRefresh()
+	searchRetriesService.Stop()
+	if audioDownloadsService != nil {
+		audioDownloadsService.Stop()
+	}
+	federationService.Stop()
 
 	// Stop NZB system workers first to cancel background processing
 	log.Println("🧹 Stopping NZB system workers...")
@@ -741,10 +1349,13 @@ func main() {
 		videoHandler.Shutdown()
 	}
 
-	// Shutdown HTTP server gracefully
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+	// Shutdown HTTP servers gracefully
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
 	}
+	syncJournal.Flush()
 
 	log.Println("✅ Shutdown complete")
 }
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// AudioDownloadStatus tracks an audio extraction through its lifecycle.
+type AudioDownloadStatus string
+
+const (
+	AudioDownloadQueued    AudioDownloadStatus = "queued"
+	AudioDownloadRunning   AudioDownloadStatus = "running"
+	AudioDownloadCompleted AudioDownloadStatus = "completed"
+	AudioDownloadFailed    AudioDownloadStatus = "failed"
+)
+
+// Audio download formats.
+const (
+	AudioDownloadFormatM4A  = "m4a"  // AAC in an MP4 container
+	AudioDownloadFormatOpus = "opus" // Opus in an Ogg container
+)
+
+// AudioDownload is the audio track of a title extracted into a file in the
+// downloads area, e.g. a standup special kept for offline listening.
+type AudioDownload struct {
+	ID          string              `json:"id"`
+	UserID      string              `json:"userId"`
+	Title       string              `json:"title"`
+	Path        string              `json:"path"` // Resolved stream path of the source
+	Format      string              `json:"format"`
+	BitrateKbps int                 `json:"bitrateKbps"`
+	AudioTrack  int                 `json:"audioTrack"` // Absolute stream index, -1 for the default track
+	Status      AudioDownloadStatus `json:"status"`
+	Progress    float64             `json:"progress"` // 0-1, estimated from the source duration
+	SizeBytes   int64               `json:"sizeBytes,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	StartedAt   *time.Time          `json:"startedAt,omitempty"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+}
+
+// AudioDownloadRequest is the body used to request an audio download.
+type AudioDownloadRequest struct {
+	Path        string `json:"path"`
+	Title       string `json:"title"`
+	Format      string `json:"format"`      // m4a (default) or opus
+	BitrateKbps int    `json:"bitrateKbps"` // Defaults to the format's default
+	AudioTrack  *int   `json:"audioTrack"`  // Absolute stream index; omitted for the default track
+}
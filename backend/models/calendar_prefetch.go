@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Calendar prefetch states.
+const (
+	CalendarPrefetchPending  = "pending"  // Waiting for air time or for a release to appear
+	CalendarPrefetchResolved = "resolved" // A release was resolved and is ready to play
+	CalendarPrefetchWarming  = "warming"  // Handed to prequeue for a full warmup
+	CalendarPrefetchFailed   = "failed"   // Nothing could be resolved before giving up
+)
+
+// CalendarPrefetch tracks one episode from a profile's Trakt calendar that is
+// resolved shortly after it airs, so it plays instantly later that day.
+type CalendarPrefetch struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"userId"`
+	TraktAccountID string     `json:"traktAccountId"`
+	TitleID        string     `json:"titleId"`
+	ShowTitle      string     `json:"showTitle"`
+	IMDBID         string     `json:"imdbId,omitempty"`
+	Year           int        `json:"year,omitempty"`
+	SeasonNumber   int        `json:"seasonNumber"`
+	EpisodeNumber  int        `json:"episodeNumber"`
+	EpisodeTitle   string     `json:"episodeTitle,omitempty"`
+	AirsAt         time.Time  `json:"airsAt"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	ReleaseTitle   string     `json:"releaseTitle,omitempty"` // Release that was resolved
+	PrequeueID     string     `json:"prequeueId,omitempty"`   // Set when warmed up through prequeue
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
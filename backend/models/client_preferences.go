@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ClientPreferences is an opaque blob of UI preferences (theme, row order,
+// playback defaults) that a profile's devices keep in sync through the
+// backend. Values are not interpreted; each key records the version it last
+// changed in, so concurrent edits to different keys merge cleanly.
+type ClientPreferences struct {
+	Version   int64                            `json:"version"`
+	UpdatedAt time.Time                        `json:"updatedAt"`
+	Values    map[string]ClientPreferenceValue `json:"values"`
+}
+
+// ClientPreferenceValue is one preference key. A null value marks a removed
+// key, kept so a device still holding it learns about the removal.
+type ClientPreferenceValue struct {
+	Value     json.RawMessage `json:"value"`
+	Version   int64           `json:"version"`
+	UpdatedBy string          `json:"updatedBy,omitempty"` // Client ID of the device that changed it
+}
+
+// Removed reports whether the key was removed.
+func (v ClientPreferenceValue) Removed() bool {
+	return len(v.Value) == 0 || string(v.Value) == "null"
+}
+
+// ClientPreferencesUpdate changes preference keys relative to the version
+// the device last synced (BaseVersion).
+type ClientPreferencesUpdate struct {
+	BaseVersion int64                      `json:"baseVersion"`
+	Set         map[string]json.RawMessage `json:"set,omitempty"`
+	Remove      []string                   `json:"remove,omitempty"`
+	// Force overwrites keys another device changed since BaseVersion
+	Force bool `json:"force,omitempty"`
+}
+
+// ClientPreferencesResult is the merged state after an update. Conflicts
+// lists keys left unchanged because another device changed them since the
+// update's base version; the stored value wins until the update is forced.
+type ClientPreferencesResult struct {
+	Preferences ClientPreferences `json:"preferences"`
+	Conflicts   []string          `json:"conflicts,omitempty"`
+}
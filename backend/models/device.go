@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// DeviceTranscodePolicy controls whether HLS sessions for a device re-encode video.
+type DeviceTranscodePolicy string
+
+const (
+	// DeviceTranscodeAuto re-encodes only video the device can't decode (default).
+	DeviceTranscodeAuto DeviceTranscodePolicy = "auto"
+	// DeviceTranscodeAlways always re-encodes SDR video so the bitrate cap applies.
+	DeviceTranscodeAlways DeviceTranscodePolicy = "always"
+	// DeviceTranscodeNever copies video even when the codec is normally re-encoded.
+	DeviceTranscodeNever DeviceTranscodePolicy = "never"
+)
+
+// IsValid reports whether the policy is one of the known values.
+func (p DeviceTranscodePolicy) IsValid() bool {
+	switch p {
+	case DeviceTranscodeAuto, DeviceTranscodeAlways, DeviceTranscodeNever:
+		return true
+	}
+	return false
+}
+
+// DeviceCapabilities describes what a device can play natively.
+type DeviceCapabilities struct {
+	VideoCodecs   []string `json:"videoCodecs,omitempty"`   // e.g. "h264", "hevc", "av1"
+	AudioCodecs   []string `json:"audioCodecs,omitempty"`   // e.g. "aac", "ac3", "eac3", "truehd"
+	HDRFormats    []string `json:"hdrFormats,omitempty"`    // e.g. "hdr10", "hdr10+", "dolbyvision"
+	MaxResolution string   `json:"maxResolution,omitempty"` // e.g. "1080p", "2160p"
+	Containers    []string `json:"containers,omitempty"`    // Most preferred first, e.g. "mkv", "mp4"
+	// SubtitleFormats lists the subtitle formats rendered natively, e.g. "subrip", "pgs"
+	SubtitleFormats []string `json:"subtitleFormats,omitempty"`
+	// CompatibilityTest is the last playback compatibility run; its results
+	// override what the app reports for the features it tested
+	CompatibilityTest *DeviceCompatibilityTest `json:"compatibilityTest,omitempty"`
+}
+
+// Device is a registered playback device with its admin-assigned overrides.
+type Device struct {
+	ID           string             `json:"id"`         // Same ID the app sends as X-Client-ID
+	Name         string             `json:"name"`       // Admin-editable display name
+	Platform     string             `json:"platform"`   // "iOS", "tvOS", "Android TV", "web", ...
+	AppVersion   string             `json:"appVersion"` // e.g. "1.2.1"
+	Capabilities DeviceCapabilities `json:"capabilities"`
+	ProfileID    string             `json:"profileId,omitempty"` // Assigned profile
+	AccountID    string             `json:"accountId,omitempty"` // Account the device registered under
+	FirstSeenAt  time.Time          `json:"firstSeenAt"`
+	LastSeenAt   time.Time          `json:"lastSeenAt"`
+
+	// Per-device overrides
+	TranscodePolicy DeviceTranscodePolicy `json:"transcodePolicy"`
+	MaxBitrateKbps  int                   `json:"maxBitrateKbps,omitempty"` // 0 = no cap
+	MaxResolution   string                `json:"maxResolution,omitempty"`  // e.g. "1080p", "" = no cap
+	SDROnly         bool                  `json:"sdrOnly,omitempty"`        // Prefer SDR releases for this device
+}
+
+// DeviceRegistration is sent by an app to register itself or refresh its details.
+type DeviceRegistration struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name,omitempty"`
+	Platform     string             `json:"platform"`
+	AppVersion   string             `json:"appVersion"`
+	Capabilities DeviceCapabilities `json:"capabilities"`
+	ProfileID    string             `json:"profileId,omitempty"`
+}
+
+// DeviceUpdate is a partial update to a device's name, profile or overrides.
+// Nil fields are left unchanged.
+type DeviceUpdate struct {
+	Name            *string                `json:"name,omitempty"`
+	ProfileID       *string                `json:"profileId,omitempty"`
+	TranscodePolicy *DeviceTranscodePolicy `json:"transcodePolicy,omitempty"`
+	MaxBitrateKbps  *int                   `json:"maxBitrateKbps,omitempty"`
+	MaxResolution   *string                `json:"maxResolution,omitempty"`
+	SDROnly         *bool                  `json:"sdrOnly,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Diagnostic issue categories.
+const (
+	DiagnosticCategoryConfig     = "config"     // invalid or incomplete settings
+	DiagnosticCategoryBinary     = "binary"     // an external tool is missing or lacks a capability
+	DiagnosticCategoryProvider   = "provider"   // a configured service could not be reached
+	DiagnosticCategoryDeprecated = "deprecated" // a legacy setting is ignored
+	DiagnosticCategoryMigrated   = "migrated"   // a legacy setting was converted
+)
+
+// Diagnostic issue severities.
+const (
+	DiagnosticSeverityError   = "error"
+	DiagnosticSeverityWarning = "warning"
+	DiagnosticSeverityInfo    = "info"
+)
+
+// DiagnosticIssue is one finding of the startup self-diagnostics.
+type DiagnosticIssue struct {
+	Category  string `json:"category"`
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+	// Feature names what is disabled or degraded because of the issue
+	Feature string `json:"feature,omitempty"`
+}
+
+// DiagnosticsReport is the result of the self-diagnostics run at startup.
+type DiagnosticsReport struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	DurationMs  int64             `json:"durationMs"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Issues      []DiagnosticIssue `json:"issues"`
+}
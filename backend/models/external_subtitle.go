@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ExternalSubtitle is a subtitle file a user uploaded for a title or episode.
+// It is stored as WebVTT and offered on every release of that item.
+type ExternalSubtitle struct {
+	ID            string    `json:"id"`
+	TitleID       string    `json:"titleId"`
+	SeasonNumber  int       `json:"seasonNumber,omitempty"`
+	EpisodeNumber int       `json:"episodeNumber,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	Label         string    `json:"label"`
+	Format        string    `json:"format"` // Format of the uploaded file (srt, ass, ssa, vtt)
+	AccountID     string    `json:"accountId"`
+	Size          int64     `json:"size"` // Size of the converted WebVTT file
+	UploadedAt    time.Time `json:"uploadedAt"`
+}
+
+// ExternalSubtitleTrack is an uploaded subtitle offered alongside a release's
+// own subtitle tracks.
+type ExternalSubtitleTrack struct {
+	ID       string `json:"id"`
+	Language string `json:"language,omitempty"`
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+}
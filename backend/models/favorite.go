@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// FavoriteItem is a title a profile loves and comes back to. Favorites are
+// kept apart from the watchlist, which holds titles still to be watched.
+type FavoriteItem struct {
+	ID          string            `json:"id"`
+	MediaType   string            `json:"mediaType"` // movie | series
+	Name        string            `json:"name"`
+	Overview    string            `json:"overview,omitempty"`
+	Year        int               `json:"year,omitempty"`
+	PosterURL   string            `json:"posterUrl,omitempty"`
+	BackdropURL string            `json:"backdropUrl,omitempty"`
+	ExternalIDs map[string]string `json:"externalIds,omitempty"`
+	AddedAt     time.Time         `json:"addedAt"`
+}
+
+// FavoriteUpsert captures data required to add a favorite or update its metadata.
+type FavoriteUpsert struct {
+	ID          string            `json:"id"`
+	MediaType   string            `json:"mediaType"`
+	Name        string            `json:"name"`
+	Overview    string            `json:"overview,omitempty"`
+	Year        int               `json:"year,omitempty"`
+	PosterURL   string            `json:"posterUrl,omitempty"`
+	BackdropURL string            `json:"backdropUrl,omitempty"`
+	ExternalIDs map[string]string `json:"externalIds,omitempty"`
+}
+
+// Key returns a stable identifier for the favorite combining media type and ID.
+func (f FavoriteItem) Key() string {
+	return f.MediaType + ":" + f.ID
+}
+
+// FavoriteImportRequest selects watchlist items to copy into favorites. Items
+// are picked by Keys ("mediaType:id") when given, otherwise every item synced
+// from a Trakt favorites list.
+type FavoriteImportRequest struct {
+	Keys                []string `json:"keys,omitempty"`
+	RemoveFromWatchlist bool     `json:"removeFromWatchlist,omitempty"` // Move rather than copy
+}
+
+// FavoriteImportResult reports what a watchlist import did.
+type FavoriteImportResult struct {
+	Imported int            `json:"imported"` // Newly added favorites
+	Skipped  int            `json:"skipped"`  // Already favorites
+	Removed  int            `json:"removed"`  // Removed from the watchlist
+	Items    []FavoriteItem `json:"items"`    // Favorites touched by the import
+}
+
+// FavoritesExport is a downloadable copy of a profile's favorites.
+type FavoritesExport struct {
+	ExportedAt time.Time      `json:"exportedAt"`
+	ProfileID  string         `json:"profileId"`
+	Favorites  []FavoriteItem `json:"favorites"`
+}
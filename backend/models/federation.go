@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// FederationPeer is another strmr server this one syncs profiles with. Both
+// servers add each other with the same shared secret, which signs every
+// request between them.
+type FederationPeer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"` // Base URL of the other server
+	// Secret is never returned by the API once stored
+	Secret string `json:"secret,omitempty"`
+	// AccountID is the local account whose profiles are shared with the peer
+	AccountID string    `json:"accountId"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	LastSyncAt time.Time `json:"lastSyncAt,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+
+	// ProfileLinks maps the peer's profile IDs to local profile IDs
+	ProfileLinks map[string]string `json:"profileLinks,omitempty"`
+	// Cursors holds the peer's change journal cursor per remote profile
+	Cursors map[string]int64 `json:"cursors,omitempty"`
+}
+
+// FederationPeerRequest adds or updates a federation peer.
+type FederationPeerRequest struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Secret    string `json:"secret,omitempty"`
+	AccountID string `json:"accountId"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+}
+
+// FederationProfile is a profile as shared with a peer.
+type FederationProfile struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Color         string `json:"color,omitempty"`
+	IsKidsProfile bool   `json:"isKidsProfile"`
+}
+
+// FederationDelta is one profile's changes since a cursor, as served to a
+// peer. Deletes carry the time of each removal so the peer can resolve
+// conflicts by timestamp. When Reset is true the upserts are a full snapshot.
+type FederationDelta struct {
+	Cursor    int64              `json:"cursor"`
+	Reset     bool               `json:"reset"`
+	Watchlist []WatchlistItem    `json:"watchlist"`
+	History   []WatchHistoryItem `json:"history"`
+	Progress  []PlaybackProgress `json:"progress"`
+	Deletes   []SyncChange       `json:"deletes"`
+}
+
+// FederationSyncResult summarises one pull from a peer.
+type FederationSyncResult struct {
+	PeerID     string    `json:"peerId"`
+	Profiles   int       `json:"profiles"`
+	Applied    int       `json:"applied"` // Remote changes newer than local state
+	Skipped    int       `json:"skipped"` // Remote changes older than local state
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// HiddenTitle is a title a profile marked "not interested". It is filtered
+// out of that profile's trending, discovery and custom list rows.
+type HiddenTitle struct {
+	ProfileID string    `json:"profileId"`
+	TitleID   string    `json:"titleId"`
+	MediaType string    `json:"mediaType"` // movie | series
+	Name      string    `json:"name,omitempty"`
+	Year      int       `json:"year,omitempty"`
+	IMDBID    string    `json:"imdbId,omitempty"`
+	TMDBID    int64     `json:"tmdbId,omitempty"`
+	TVDBID    int64     `json:"tvdbId,omitempty"`
+	PosterURL string    `json:"posterUrl,omitempty"`
+	HiddenAt  time.Time `json:"hiddenAt"`
+}
+
+// Matches reports whether title is the hidden title. Metadata rows identify
+// titles inconsistently, so any shared ID of the same media type matches.
+func (h HiddenTitle) Matches(title Title) bool {
+	if !strings.EqualFold(h.MediaType, title.MediaType) {
+		return false
+	}
+	switch {
+	case h.TitleID != "" && strings.EqualFold(h.TitleID, title.ID):
+		return true
+	case h.IMDBID != "" && strings.EqualFold(h.IMDBID, title.IMDBID):
+		return true
+	case h.TMDBID > 0 && h.TMDBID == title.TMDBID:
+		return true
+	case h.TVDBID > 0 && h.TVDBID == title.TVDBID:
+		return true
+	}
+	return false
+}
+
+// HiddenTitleRequest is the body for hiding a title. Supplying the external
+// IDs lets the title be matched in rows that identify it differently.
+type HiddenTitleRequest struct {
+	TitleID   string `json:"titleId"`
+	MediaType string `json:"mediaType"`
+	Name      string `json:"name,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	IMDBID    string `json:"imdbId,omitempty"`
+	TMDBID    int64  `json:"tmdbId,omitempty"`
+	TVDBID    int64  `json:"tvdbId,omitempty"`
+	PosterURL string `json:"posterUrl,omitempty"`
+}
@@ -34,6 +34,44 @@ type SeriesWatchState struct {
 	// Episode counts for tracking series completion (excludes specials/season 0)
 	WatchedEpisodeCount int `json:"watchedEpisodeCount,omitempty"` // Number of episodes user has watched
 	TotalEpisodeCount   int `json:"totalEpisodeCount,omitempty"`   // Total released episodes in series
+
+	// Queue is set on the synthetic entry representing the profile's playback queue
+	Queue *PlaybackQueueCard `json:"queue,omitempty"`
+}
+
+// MissingEpisode is an aired episode of a watched series that the user has not finished.
+type MissingEpisode struct {
+	SeasonNumber   int     `json:"seasonNumber"`
+	EpisodeNumber  int     `json:"episodeNumber"`
+	EpisodeID      string  `json:"episodeId,omitempty"`
+	Title          string  `json:"title,omitempty"`
+	AirDate        string  `json:"airDate,omitempty"`
+	RuntimeMinutes int     `json:"runtimeMinutes,omitempty"`
+	PercentWatched float64 `json:"percentWatched,omitempty"` // Set when the episode was started but not finished
+	Gap            bool    `json:"gap"`                      // Before the furthest watched episode (skipped rather than not reached yet)
+
+	// Availability is set for the first few episodes when the report is
+	// requested with ?availability=true
+	Availability *WatchlistAvailability `json:"availability,omitempty"`
+}
+
+// SeriesMissingEpisodes reports the aired episodes a user has not watched for one series.
+// Episode counts exclude specials (season 0).
+type SeriesMissingEpisodes struct {
+	SeriesID      string            `json:"seriesId"`
+	SeriesTitle   string            `json:"seriesTitle"`
+	PosterURL     string            `json:"posterUrl,omitempty"`
+	Year          int               `json:"year,omitempty"`
+	ExternalIDs   map[string]string `json:"externalIds,omitempty"`
+	LastWatchedAt time.Time         `json:"lastWatchedAt"`
+
+	AiredEpisodeCount    int              `json:"airedEpisodeCount"`
+	WatchedEpisodeCount  int              `json:"watchedEpisodeCount"`
+	GapCount             int              `json:"gapCount"`
+	MissingEpisodes      []MissingEpisode `json:"missingEpisodes"`
+	UpcomingEpisodeCount int              `json:"upcomingEpisodeCount"` // Announced episodes that have not aired yet
+	NextAirDate          string           `json:"nextAirDate,omitempty"`
+	Complete             bool             `json:"complete"` // Every aired episode has been watched
 }
 
 // EpisodeWatchPayload represents a request to record that a user started an episode.
@@ -64,6 +102,21 @@ type WatchHistoryItem struct {
 	EpisodeNumber int    `json:"episodeNumber,omitempty"`
 	SeriesID      string `json:"seriesId,omitempty"`      // Parent series ID for episodes
 	SeriesName    string `json:"seriesName,omitempty"`
+
+	// Profiles that watched this together with the owner (watch-together sessions)
+	WatchedWith []string `json:"watchedWith,omitempty"`
+
+	// Last change to the watched state, including marking it unwatched
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// LastModified is when the item's watched state last changed. Items stored
+// before UpdatedAt was tracked fall back to WatchedAt.
+func (i WatchHistoryItem) LastModified() time.Time {
+	if i.UpdatedAt.After(i.WatchedAt) {
+		return i.UpdatedAt
+	}
+	return i.WatchedAt
 }
 
 // WatchHistoryUpdate represents an update to mark an item as watched/unwatched.
@@ -83,6 +136,30 @@ type WatchHistoryUpdate struct {
 	SeriesName    string `json:"seriesName,omitempty"`
 }
 
+// SeriesWatchedUpdate marks every aired episode of a series, or of one of its
+// seasons, as watched or unwatched in one call.
+type SeriesWatchedUpdate struct {
+	SeriesID     string            `json:"seriesId"`
+	SeriesName   string            `json:"seriesName,omitempty"`
+	Year         int               `json:"year,omitempty"`
+	SeasonNumber *int              `json:"seasonNumber,omitempty"` // Omit for the whole series (specials excluded)
+	Watched      bool              `json:"watched"`
+	WatchedAt    time.Time         `json:"watchedAt,omitempty"`
+	ExternalIDs  map[string]string `json:"externalIds,omitempty"`
+	// SyncTrakt scrobbles newly watched episodes to Trakt; defaults to true
+	SyncTrakt *bool `json:"syncTrakt,omitempty"`
+}
+
+// SeriesWatchedResult reports the episodes a SeriesWatchedUpdate changed.
+type SeriesWatchedResult struct {
+	SeriesID     string             `json:"seriesId"`
+	SeasonNumber *int               `json:"seasonNumber,omitempty"`
+	Watched      bool               `json:"watched"`
+	Updated      int                `json:"updated"`
+	Unchanged    int                `json:"unchanged"` // Episodes already in the requested state
+	Items        []WatchHistoryItem `json:"items"`
+}
+
 // PlaybackProgressUpdate represents a playback progress update from the player.
 type PlaybackProgressUpdate struct {
 	MediaType     string            `json:"mediaType"`    // "movie" | "episode"
@@ -102,6 +179,9 @@ type PlaybackProgressUpdate struct {
 	// Movie-specific fields
 	MovieName     string `json:"movieName,omitempty"`
 	Year          int    `json:"year,omitempty"`
+
+	// Pinned release version being played (optional)
+	VersionID string `json:"versionId,omitempty"`
 }
 
 // PlaybackProgress stores the current playback progress for a media item.
@@ -128,4 +208,20 @@ type PlaybackProgress struct {
 
 	// Hidden from continue watching (user dismissed)
 	HiddenFromContinueWatching bool `json:"hiddenFromContinueWatching,omitempty"`
+
+	// Archived from continue watching by the profile's retention policy; cleared by new activity
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+
+	// Pinned release versions: the version last played and the position reached in each.
+	// Position/PercentWatched above always reflect the most recent update across versions.
+	VersionID string                     `json:"versionId,omitempty"`
+	Versions  map[string]VersionProgress `json:"versions,omitempty"`
+}
+
+// VersionProgress is the last position reached in one pinned release version of a title.
+type VersionProgress struct {
+	Position       float64   `json:"position"`
+	Duration       float64   `json:"duration"`
+	PercentWatched float64   `json:"percentWatched"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
@@ -23,4 +23,50 @@ type NZBResult struct {
 	Attributes   map[string]string  `json:"attributes,omitempty"`
 	ServiceType  ContentServiceType `json:"serviceType,omitempty"`
 	EpisodeCount int                `json:"episodeCount,omitempty"` // Number of episodes in pack (0 if not a pack)
+
+	EstimatedBitrateKbps int `json:"estimatedBitrateKbps,omitempty"` // Size / runtime; per episode for packs (0 if runtime unknown)
+
+	// Alternates are copies of the same release from other indexers, tried in
+	// order when this copy's NZB can't be fetched or is unhealthy
+	Alternates []NZBResult `json:"alternates,omitempty"`
+}
+
+// Clone returns a copy of r that shares no slices or maps with it.
+func (r NZBResult) Clone() NZBResult {
+	r.Categories = append([]string(nil), r.Categories...)
+	if r.Attributes != nil {
+		attrs := make(map[string]string, len(r.Attributes))
+		for k, v := range r.Attributes {
+			attrs[k] = v
+		}
+		r.Attributes = attrs
+	}
+	r.Alternates = CloneNZBResults(r.Alternates)
+	return r
+}
+
+// Copies returns the result followed by its alternates, each without
+// alternates of its own.
+func (r NZBResult) Copies() []NZBResult {
+	copies := make([]NZBResult, 0, 1+len(r.Alternates))
+	primary := r
+	primary.Alternates = nil
+	copies = append(copies, primary)
+	for _, alt := range r.Alternates {
+		alt.Alternates = nil
+		copies = append(copies, alt)
+	}
+	return copies
+}
+
+// CloneNZBResults deep-copies a result list.
+func CloneNZBResults(results []NZBResult) []NZBResult {
+	if results == nil {
+		return nil
+	}
+	cloned := make([]NZBResult, len(results))
+	for i, result := range results {
+		cloned[i] = result.Clone()
+	}
+	return cloned
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Maintenance job run outcomes
+const (
+	JobStatusSuccess     = "success"
+	JobStatusError       = "error"
+	JobStatusInterrupted = "interrupted" // The server stopped while the job was running
+)
+
+// JobStatus describes a background maintenance job, its schedule and the
+// outcome of its last run.
+type JobStatus struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description,omitempty"`
+	Schedule        string     `json:"schedule"`        // Cron expression in effect
+	DefaultSchedule string     `json:"defaultSchedule"` // Schedule used when no override is set
+	Enabled         bool       `json:"enabled"`
+	Running         bool       `json:"running"`
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty"` // Omitted while disabled
+	LastRunAt       *time.Time `json:"lastRunAt,omitempty"`
+	LastDurationMs  int64      `json:"lastDurationMs,omitempty"`
+	LastStatus      string     `json:"lastStatus,omitempty"`
+	LastError       string     `json:"lastError,omitempty"`
+	RunCount        int        `json:"runCount"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// LiveEventStatus tracks a scheduled live recording through its lifecycle.
+type LiveEventStatus string
+
+const (
+	LiveEventScheduled LiveEventStatus = "scheduled"
+	LiveEventRecording LiveEventStatus = "recording"
+	LiveEventCompleted LiveEventStatus = "completed"
+	LiveEventFailed    LiveEventStatus = "failed"
+)
+
+// LiveEvent is a scheduled recording of a live channel, e.g. a sports match.
+// The backend buffers the channel into a timeshift cache between StartAt and
+// EndAt; the recording can be watched (and seeked) while it is still running.
+type LiveEvent struct {
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	ChannelID   string          `json:"channelId,omitempty"`
+	ChannelName string          `json:"channelName,omitempty"`
+	StreamURL   string          `json:"streamUrl"`
+	StartAt     time.Time       `json:"startAt"`
+	EndAt       time.Time       `json:"endAt"`
+	Status      LiveEventStatus `json:"status"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedBy   string          `json:"createdBy,omitempty"` // Account ID
+	CreatedAt   time.Time       `json:"createdAt"`
+
+	RecordingStartedAt *time.Time `json:"recordingStartedAt,omitempty"`
+	RecordingEndedAt   *time.Time `json:"recordingEndedAt,omitempty"`
+}
+
+// Playable reports whether the event has a recording that can be watched.
+func (e LiveEvent) Playable() bool {
+	return e.Status == LiveEventRecording || e.Status == LiveEventCompleted
+}
+
+// LiveEventRequest is the body used to schedule a live event.
+type LiveEventRequest struct {
+	Title       string    `json:"title"`
+	ChannelID   string    `json:"channelId"`
+	ChannelName string    `json:"channelName"`
+	StreamURL   string    `json:"streamUrl"`
+	StartAt     time.Time `json:"startAt"`
+	EndAt       time.Time `json:"endAt"`
+}
+
+// LiveTimeshiftSource is the kind of cache a timeshifted start plays from.
+type LiveTimeshiftSource string
+
+const (
+	LiveTimeshiftEvent  LiveTimeshiftSource = "event"  // A live event recording in progress
+	LiveTimeshiftBuffer LiveTimeshiftSource = "buffer" // A channel buffered while it is watched
+)
+
+// LiveTimeshift is a timeshift cache of a live channel that reaches back far
+// enough to start playback from an earlier point, e.g. the program start.
+type LiveTimeshift struct {
+	Source       LiveTimeshiftSource `json:"source"`
+	ID           string              `json:"id"`
+	BufferedFrom time.Time           `json:"bufferedFrom"`
+}
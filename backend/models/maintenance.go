@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Maintenance phases reported by the admin status API.
+const (
+	MaintenancePhaseOff      = "off"
+	MaintenancePhaseDraining = "draining"
+	MaintenancePhaseDrained  = "drained"
+	MaintenancePhaseTimedOut = "timed_out"
+)
+
+// MaintenanceStatus describes maintenance mode and the progress of draining
+// active streams before a restart or update.
+type MaintenanceStatus struct {
+	Enabled             bool       `json:"enabled"`
+	Phase               string     `json:"phase"`
+	Message             string     `json:"message,omitempty"`
+	EnabledAt           *time.Time `json:"enabledAt,omitempty"`
+	DrainDeadline       *time.Time `json:"drainDeadline,omitempty"`
+	DrainTimeoutSeconds int        `json:"drainTimeoutSeconds,omitempty"`
+	InitialStreams      int        `json:"initialStreams"`
+	ActiveStreams       int        `json:"activeStreams"`
+	// SafeToRestart is true once every stream has finished or the drain timeout has passed.
+	SafeToRestart bool `json:"safeToRestart"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MatchOverride pins a title to the correct TVDB/TMDB entry when automatic
+// matching picks the wrong one, e.g. a remake that shares its name with the
+// original. An override applies to requests carrying its TitleID, or to
+// name-based lookups with the same media type, name and year.
+type MatchOverride struct {
+	ID        string    `json:"id"`
+	MediaType string    `json:"mediaType"` // series | movie
+	TitleID   string    `json:"titleId,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Year      int       `json:"year,omitempty"`
+	TVDBID    int64     `json:"tvdbId,omitempty"`
+	TMDBID    int64     `json:"tmdbId,omitempty"`
+	IMDBID    string    `json:"imdbId,omitempty"`
+	UpdatedBy string    `json:"updatedBy,omitempty"` // Account ID
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Media failure stages recorded by the playback pipeline.
+const (
+	MediaFailureStageProbe     = "probe"
+	MediaFailureStageTranscode = "transcode"
+)
+
+// MediaFailureEvent describes a single ffprobe or ffmpeg failure.
+type MediaFailureEvent struct {
+	Stage      string
+	VideoCodec string
+	Container  string
+	Provider   string
+	Error      string
+}
+
+// MediaFailureSignature is an aggregated group of failures sharing codec, container,
+// provider and a normalised error message.
+type MediaFailureSignature struct {
+	Stage      string    `json:"stage"`
+	VideoCodec string    `json:"videoCodec,omitempty"`
+	Container  string    `json:"container,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	Signature  string    `json:"signature"`
+	Count      int64     `json:"count"`
+	LastError  string    `json:"lastError,omitempty"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// MediaFailureSummary summarises recorded failures for the admin dashboard.
+type MediaFailureSummary struct {
+	TotalFailures int64                   `json:"totalFailures"`
+	ByStage       map[string]int64        `json:"byStage"`
+	ByVideoCodec  map[string]int64        `json:"byVideoCodec"`
+	ByContainer   map[string]int64        `json:"byContainer"`
+	ByProvider    map[string]int64        `json:"byProvider"`
+	TopSignatures []MediaFailureSignature `json:"topSignatures"`
+}
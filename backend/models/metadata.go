@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Basic metadata structures for titles and images.
 
 type Image struct {
@@ -7,6 +9,19 @@ type Image struct {
 	Type   string `json:"type"` // poster, backdrop, logo
 	Width  int    `json:"width"`
 	Height int    `json:"height"`
+	// Fallbacks are alternate URLs for the same artwork (TMDB first, then the
+	// next-best TVDB assets), used when URL is dead or rate limited.
+	Fallbacks []string `json:"fallbacks,omitempty"`
+	// Placeholder is shown while the image loads; only set once the image
+	// proxy has cached the artwork.
+	Placeholder *ImagePlaceholder `json:"placeholder,omitempty"`
+}
+
+// ImagePlaceholder summarises an image so clients can paint something
+// before it loads.
+type ImagePlaceholder struct {
+	Color    string `json:"color"`              // Dominant color as #rrggbb
+	BlurHash string `json:"blurHash,omitempty"` // https://blurha.sh encoding
 }
 
 type Trailer struct {
@@ -59,6 +74,8 @@ type Title struct {
 	Theatrical      *Release  `json:"theatricalRelease,omitempty"`
 	HomeRelease     *Release  `json:"homeRelease,omitempty"`
 	Ratings         []Rating    `json:"ratings,omitempty"`        // Aggregated ratings from MDBList
+	CompositeRating *float64    `json:"compositeRating,omitempty"` // Weighted 0-100 score across Ratings
+	Certification   string      `json:"certification,omitempty"`   // US certification or TV rating (e.g. PG-13, TV-PG)
 	Credits         *Credits    `json:"credits,omitempty"`        // Top billed cast
 	RuntimeMinutes  int         `json:"runtimeMinutes,omitempty"` // Runtime in minutes (movies only)
 	Collection      *Collection `json:"collection,omitempty"`     // Movie collection (movies only)
@@ -86,6 +103,8 @@ type SeriesEpisode struct {
 	AiredDate             string `json:"airedDate,omitempty"`
 	Runtime               int    `json:"runtimeMinutes,omitempty"`
 	Image                 *Image `json:"image,omitempty"`
+	// SpoilerWarning is set when another household profile is holding the episode
+	SpoilerWarning *SpoilerWarning `json:"spoilerWarning,omitempty"`
 }
 
 type SeriesSeason struct {
@@ -129,6 +148,25 @@ type TrailerResponse struct {
 	Trailers       []Trailer `json:"trailers"`
 }
 
+// HeroItem is one slide of the home screen hero rotation. Backdrop has been
+// checked to be wide and reachable; TrailerStreamURL is a pre-resolved direct
+// stream for the primary trailer and expires with the rotation.
+type HeroItem struct {
+	Title            Title    `json:"title"`
+	Source           string   `json:"source"` // trending | watchlist
+	Backdrop         *Image   `json:"backdrop"`
+	Logo             *Image   `json:"logo,omitempty"`
+	Trailer          *Trailer `json:"trailer,omitempty"`
+	TrailerStreamURL string   `json:"trailerStreamUrl,omitempty"`
+}
+
+// HeroRotation is a cached set of hero slides and when it will be rebuilt.
+type HeroRotation struct {
+	Items       []HeroItem `json:"items"`
+	GeneratedAt time.Time  `json:"generatedAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+}
+
 type MovieDetailsQuery struct {
 	TitleID string
 	Name    string
@@ -240,3 +278,30 @@ type BatchMovieReleasesItem struct {
 type BatchMovieReleasesResponse struct {
 	Results []BatchMovieReleasesItem `json:"results"`
 }
+
+// TitleTranslationsQuery identifies a title and the languages to fetch translations for.
+type TitleTranslationsQuery struct {
+	MediaType string // series | movie
+	TitleID   string
+	Name      string
+	Year      int
+	TVDBID    int64
+	TMDBID    int64
+	Languages []string // ISO 639-1 or 639-2 codes, e.g. ["en", "de"]
+}
+
+// LocalizedText holds a title's name and overview in a single language.
+type LocalizedText struct {
+	Language string `json:"language"` // requested language code as sent by the client
+	Name     string `json:"name,omitempty"`
+	Overview string `json:"overview,omitempty"`
+	Missing  bool   `json:"missing,omitempty"` // true when TVDB has no translation for this language
+}
+
+// TitleTranslations bundles per-language metadata for a single title.
+type TitleTranslations struct {
+	ID           string          `json:"id"`
+	MediaType    string          `json:"mediaType"`
+	TVDBID       int64           `json:"tvdbId"`
+	Translations []LocalizedText `json:"translations"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OrphanGCReport describes one pass of the orphaned record garbage collector.
+// In a dry run the counts are what would have been removed.
+type OrphanGCReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	DryRun     bool      `json:"dryRun"`
+	// Database rows whose files are gone
+	QueueItems    int64 `json:"queueItems"`
+	HealthRecords int64 `json:"healthRecords"`
+	// Spooled NZB files no row refers to, and the space they took
+	NZBFiles       int   `json:"nzbFiles"`
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
+	// References that could not be checked (e.g. an unreadable metadata
+	// file) and were left alone
+	Skipped int `json:"skipped"`
+}
@@ -9,7 +9,8 @@ type SubtitleSessionInfo struct {
 	Title        string  `json:"title"`
 	Codec        string  `json:"codec"`
 	IsForced     bool    `json:"isForced"`
-	IsExtracting bool    `json:"isExtracting"`          // true if extraction is still in progress
+	IsSDH        bool    `json:"isSdh,omitempty"`        // Subtitles for the deaf and hard of hearing
+	IsExtracting bool    `json:"isExtracting"`           // true if extraction is still in progress
 	FirstCueTime float64 `json:"firstCueTime,omitempty"` // Time of first extracted cue (for subtitle sync)
 }
 
@@ -20,6 +21,7 @@ type PlaybackResolution struct {
 	HealthStatus  string `json:"healthStatus"`
 	FileSize      int64  `json:"fileSize,omitempty"`
 	SourceNZBPath string `json:"sourceNzbPath,omitempty"`
+	VersionID     string `json:"versionId,omitempty"` // Pinned release version that was resolved, if any
 	// Pre-extracted subtitles (for manual selection path)
 	SubtitleSessions map[int]*SubtitleSessionInfo `json:"subtitleSessions,omitempty"`
 }
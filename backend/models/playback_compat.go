@@ -0,0 +1,72 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Kinds of capability a compatibility clip tests.
+const (
+	CompatVideoCodec     = "videoCodec"
+	CompatHDRFormat      = "hdrFormat"
+	CompatAudioCodec     = "audioCodec"
+	CompatContainer      = "container"
+	CompatSubtitleFormat = "subtitleFormat"
+)
+
+// CompatFeature is one capability, e.g. {videoCodec hevc}. Values use the
+// names of DeviceCapabilities.
+type CompatFeature struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// CompatClip is a short generated test clip. Each clip differs from the
+// clips it requires only in the features it tests, so a clip that fails
+// while its requirements played pins the failure on those features.
+type CompatClip struct {
+	ID             string          `json:"id"`
+	Label          string          `json:"label"`
+	Container      string          `json:"container"`
+	VideoCodec     string          `json:"videoCodec"`
+	HDRFormat      string          `json:"hdrFormat,omitempty"`
+	AudioCodec     string          `json:"audioCodec"`
+	SubtitleFormat string          `json:"subtitleFormat,omitempty"`
+	Tests          []CompatFeature `json:"tests"`
+	Requires       []string        `json:"requires,omitempty"` // Clip IDs that must play for a failure to count
+	Available      bool            `json:"available"`          // False when the server lacks the tools to generate it
+	URL            string          `json:"url,omitempty"`
+}
+
+// DeviceCompatibilityTest is the outcome of a device's last compatibility run.
+type DeviceCompatibilityTest struct {
+	TestedAt    time.Time       `json:"testedAt"`
+	Results     map[string]bool `json:"results"` // Clip ID -> played
+	Supported   []CompatFeature `json:"supported,omitempty"`
+	Unsupported []CompatFeature `json:"unsupported,omitempty"`
+}
+
+// ApplyCompatibility returns the capabilities with the tested features
+// added or removed. Untested features keep their reported values, and
+// containers keep their order of preference.
+func (c DeviceCapabilities) ApplyCompatibility(test DeviceCompatibilityTest) DeviceCapabilities {
+	lists := map[string]*[]string{
+		CompatVideoCodec:     &c.VideoCodecs,
+		CompatHDRFormat:      &c.HDRFormats,
+		CompatAudioCodec:     &c.AudioCodecs,
+		CompatContainer:      &c.Containers,
+		CompatSubtitleFormat: &c.SubtitleFormats,
+	}
+	for _, feature := range test.Unsupported {
+		if list, ok := lists[feature.Kind]; ok {
+			*list = slices.DeleteFunc(slices.Clone(*list), func(v string) bool { return v == feature.Value })
+		}
+	}
+	for _, feature := range test.Supported {
+		if list, ok := lists[feature.Kind]; ok && !slices.Contains(*list, feature.Value) {
+			*list = append(slices.Clone(*list), feature.Value)
+		}
+	}
+	c.CompatibilityTest = &test
+	return c
+}
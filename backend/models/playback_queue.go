@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// PlaybackQueueItem is a movie or episode a profile has queued to play back-to-back.
+type PlaybackQueueItem struct {
+	ID        string `json:"id"`
+	MediaType string `json:"mediaType"` // "movie" or "episode"
+	// TitleID is the movie or, for episodes, the series
+	TitleID       string    `json:"titleId"`
+	TitleName     string    `json:"titleName"`
+	Year          int       `json:"year,omitempty"`
+	ImdbID        string    `json:"imdbId,omitempty"`
+	TVDBID        int64     `json:"tvdbId,omitempty"`
+	TMDBID        int64     `json:"tmdbId,omitempty"`
+	SeasonNumber  int       `json:"seasonNumber,omitempty"`
+	EpisodeNumber int       `json:"episodeNumber,omitempty"`
+	EpisodeTitle  string    `json:"episodeTitle,omitempty"`
+	PosterURL     string    `json:"posterUrl,omitempty"`
+	BackdropURL   string    `json:"backdropUrl,omitempty"`
+	AddedAt       time.Time `json:"addedAt"`
+}
+
+// PlaybackQueueItemRequest adds an item to a profile's playback queue.
+type PlaybackQueueItemRequest struct {
+	MediaType     string `json:"mediaType"`
+	TitleID       string `json:"titleId"`
+	TitleName     string `json:"titleName"`
+	Year          int    `json:"year,omitempty"`
+	ImdbID        string `json:"imdbId,omitempty"`
+	TVDBID        int64  `json:"tvdbId,omitempty"`
+	TMDBID        int64  `json:"tmdbId,omitempty"`
+	SeasonNumber  int    `json:"seasonNumber,omitempty"`
+	EpisodeNumber int    `json:"episodeNumber,omitempty"`
+	EpisodeTitle  string `json:"episodeTitle,omitempty"`
+	PosterURL     string `json:"posterUrl,omitempty"`
+	BackdropURL   string `json:"backdropUrl,omitempty"`
+	// Position inserts the item at that index; nil appends it
+	Position *int `json:"position,omitempty"`
+}
+
+// PlaybackQueueReorderRequest sets the order of a profile's queue. It must
+// list every queued item exactly once.
+type PlaybackQueueReorderRequest struct {
+	ItemIDs []string `json:"itemIds"`
+}
+
+// PlaybackQueueCard marks a continue watching entry as the profile's playback
+// queue rather than a title, so clients can render it as its own card.
+type PlaybackQueueCard struct {
+	Next      PlaybackQueueItem `json:"next"`
+	ItemCount int               `json:"itemCount"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Stages of the playback start sequence, in the order they run.
+const (
+	PlaybackStageSearch       = "search"
+	PlaybackStageHealthCheck  = "health_check"
+	PlaybackStageResolve      = "resolve"
+	PlaybackStageProbe        = "probe"
+	PlaybackStageHLSWarmup    = "hls_warmup"
+	PlaybackStageFirstSegment = "first_segment"
+	// PlaybackStageTotal spans from the start of the attempt until it was ready
+	PlaybackStageTotal = "total"
+)
+
+// Outcomes of a playback start attempt.
+const (
+	PlaybackAttemptPending = "pending"
+	PlaybackAttemptReady   = "ready"
+	PlaybackAttemptFailed  = "failed"
+)
+
+// PlaybackTimingSpan is how long one stage of a playback start took.
+type PlaybackTimingSpan struct {
+	Stage      string    `json:"stage"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// PlaybackAttemptTiming is the timing breakdown of one playback start,
+// keyed by its prequeue ID. The resolve span includes the health check.
+type PlaybackAttemptTiming struct {
+	ID           string               `json:"id"`
+	TitleName    string               `json:"titleName,omitempty"`
+	MediaType    string               `json:"mediaType,omitempty"`
+	HLSSessionID string               `json:"hlsSessionId,omitempty"`
+	StartedAt    time.Time            `json:"startedAt"`
+	ReadyAt      time.Time            `json:"readyAt,omitempty"`
+	Outcome      string               `json:"outcome"`
+	Error        string               `json:"error,omitempty"`
+	Spans        []PlaybackTimingSpan `json:"spans"`
+}
+
+// PlaybackStageStats aggregates one stage's durations across attempts.
+type PlaybackStageStats struct {
+	Stage string `json:"stage"`
+	Count int    `json:"count"`
+	P50Ms int64  `json:"p50Ms"`
+	P95Ms int64  `json:"p95Ms"`
+	MaxMs int64  `json:"maxMs"`
+	AvgMs int64  `json:"avgMs"`
+}
+
+// PlaybackTimingSummary attributes playback start latency to its stages for
+// the admin dashboard.
+type PlaybackTimingSummary struct {
+	Attempts int                     `json:"attempts"`
+	Failed   int                     `json:"failed"`
+	Stages   []PlaybackStageStats    `json:"stages"`
+	Recent   []PlaybackAttemptTiming `json:"recent"`
+}
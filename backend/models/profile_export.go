@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ProfileExportVersion is bumped when the export layout changes
+const ProfileExportVersion = 2
+
+// ProfileExport is a downloadable copy of everything stored for a profile
+type ProfileExport struct {
+	Version          int                `json:"version"`
+	ExportedAt       time.Time          `json:"exportedAt"`
+	Profile          User               `json:"profile"`
+	Watchlist        []WatchlistItem    `json:"watchlist"`
+	Favorites        []FavoriteItem     `json:"favorites"`
+	WatchHistory     []WatchHistoryItem `json:"watchHistory"`
+	PlaybackProgress []PlaybackProgress `json:"playbackProgress"`
+	Settings         *UserSettings      `json:"settings,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Provider kinds tracked for traffic usage.
+const (
+	ProviderKindDebrid = "debrid"
+	ProviderKindUsenet = "usenet"
+)
+
+// Quota states reported in a ProviderUsageSummary.
+const (
+	ProviderQuotaOK       = "ok"
+	ProviderQuotaWarning  = "warning"
+	ProviderQuotaExceeded = "exceeded"
+)
+
+// ProviderUsageRecord is the persisted traffic history of one debrid account
+// or usenet provider. Daily keys are "2006-01-02", monthly keys "2006-01".
+type ProviderUsageRecord struct {
+	Kind    string           `json:"kind"`
+	Name    string           `json:"name"`
+	Daily   map[string]int64 `json:"daily"`
+	Monthly map[string]int64 `json:"monthly"`
+	// WarnedMonth and ExceededMonth remember which month the quota warnings were
+	// last logged for, so each is raised once per month
+	WarnedMonth   string    `json:"warnedMonth,omitempty"`
+	ExceededMonth string    `json:"exceededMonth,omitempty"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ProviderUsagePoint is one bar of a usage chart.
+type ProviderUsagePoint struct {
+	Period string `json:"period"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// ProviderUsageSummary is a provider's current-month usage against its quota,
+// with the series needed to chart daily and monthly traffic.
+type ProviderUsageSummary struct {
+	Kind        string               `json:"kind"`
+	Name        string               `json:"name"`
+	Configured  bool                 `json:"configured"` // false for history of a provider since removed from settings
+	MonthBytes  int64                `json:"monthBytes"`
+	QuotaBytes  int64                `json:"quotaBytes,omitempty"` // 0 = unlimited
+	PercentUsed float64              `json:"percentUsed,omitempty"`
+	Status      string               `json:"status"`
+	Daily       []ProviderUsagePoint `json:"daily"`   // last 30 days, oldest first
+	Monthly     []ProviderUsagePoint `json:"monthly"` // last 12 months, oldest first
+}
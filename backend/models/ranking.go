@@ -13,6 +13,7 @@ type UserRankingCriterion struct {
 // UserRankingSettings holds per-user ranking overrides.
 type UserRankingSettings struct {
 	Criteria []UserRankingCriterion `json:"criteria,omitempty"`
+	Strategy string                 `json:"strategy,omitempty"` // Release selection strategy; empty = global
 }
 
 // ClientRankingCriterion represents a per-client override for a ranking criterion.
@@ -0,0 +1,19 @@
+package models
+
+// ReleaseHintQuery identifies a title shown on a browse screen. The IMDB ID is
+// preferred; the title and year are used when it is missing or unknown.
+type ReleaseHintQuery struct {
+	ID        string `json:"id"` // Echoed back so clients can match hints to cards
+	IMDBID    string `json:"imdbId,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	MediaType string `json:"mediaType,omitempty"` // movie | series
+}
+
+// ReleaseHint says whether releases were recently seen for a title. It is a
+// hint only: a match can be a false positive, and titles nobody searched for
+// recently are never likely.
+type ReleaseHint struct {
+	ID     string `json:"id"`
+	Likely bool   `json:"likely"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ReleaseVersion is a release a profile has pinned for a title so it can be
+// replayed later, e.g. "4K DV", "1080p SDR" or "Director's Cut".
+type ReleaseVersion struct {
+	ID        string    `json:"id"`
+	ContentID string    `json:"contentId"`         // e.g., "tmdb:movie:67890"
+	Label     string    `json:"label"`             // User-facing name shown in the version selector
+	Edition   string    `json:"edition,omitempty"` // Optional cut/edition (e.g., "Director's Cut")
+	Result    NZBResult `json:"result"`            // Release passed to playback resolve
+	IsDefault bool      `json:"isDefault"`         // Played when no version is selected
+	PinnedAt  time.Time `json:"pinnedAt"`
+}
+
+// ReleaseVersionPin represents a request to pin a release as a version of a title.
+type ReleaseVersionPin struct {
+	Label       string    `json:"label,omitempty"`
+	Edition     string    `json:"edition,omitempty"`
+	Result      NZBResult `json:"result"`
+	MakeDefault bool      `json:"makeDefault,omitempty"`
+}
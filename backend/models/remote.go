@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Remote command types relayed from a controller (phone) to a target device (TV).
+const (
+	RemoteCommandPlay     = "play"
+	RemoteCommandPause    = "pause"
+	RemoteCommandResume   = "resume"
+	RemoteCommandStop     = "stop"
+	RemoteCommandSeek     = "seek"
+	RemoteCommandNavigate = "navigate"
+)
+
+// Remote message types sent over the WebSocket relay.
+const (
+	RemoteMessageCommand = "command"
+	RemoteMessageStatus  = "status"
+	RemoteMessagePaired  = "paired"
+	RemoteMessageError   = "error"
+)
+
+// RemotePairingCode is a short-lived code shown on a target device so a controller can pair with it.
+type RemotePairingCode struct {
+	Code           string    `json:"code"`
+	TargetClientID string    `json:"targetClientId"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// RemotePairing links a controller device to a target device within an account.
+type RemotePairing struct {
+	ID             string    `json:"id"`
+	AccountID      string    `json:"accountId"`
+	TargetClientID string    `json:"targetClientId"`
+	TargetName     string    `json:"targetName"`
+	ControllerID   string    `json:"controllerId,omitempty"`
+	ControllerName string    `json:"controllerName,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	LastUsedAt     time.Time `json:"lastUsedAt,omitempty"`
+	// Online reports whether the target currently has a relay connection (not persisted)
+	Online bool `json:"online"`
+}
+
+// RemoteCommand is a playback or navigation instruction for a target device.
+// For "play" the title fields identify what to start; the target resolves and
+// prequeues the stream itself using the given profile.
+type RemoteCommand struct {
+	ID            string  `json:"id,omitempty"`
+	Type          string  `json:"type"`
+	UserID        string  `json:"userId,omitempty"`
+	TitleID       string  `json:"titleId,omitempty"`
+	TitleName     string  `json:"titleName,omitempty"`
+	MediaType     string  `json:"mediaType,omitempty"`
+	ImdbID        string  `json:"imdbId,omitempty"`
+	Year          int     `json:"year,omitempty"`
+	SeasonNumber  int     `json:"seasonNumber,omitempty"`
+	EpisodeNumber int     `json:"episodeNumber,omitempty"`
+	StartOffset   float64 `json:"startOffset,omitempty"`
+	Position      float64 `json:"position,omitempty"` // Seek target in seconds
+	Route         string  `json:"route,omitempty"`    // Screen to open for "navigate"
+}
+
+// RemoteMessage is the envelope exchanged over the remote-control WebSocket relay.
+type RemoteMessage struct {
+	Type      string                 `json:"type"`
+	PairingID string                 `json:"pairingId,omitempty"`
+	Command   *RemoteCommand         `json:"command,omitempty"`
+	Status    map[string]interface{} `json:"status,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	SentAt    time.Time              `json:"sentAt"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// SavedSearchFilters narrows a saved search to the releases a profile is
+// waiting for. Empty fields don't filter.
+type SavedSearchFilters struct {
+	MinResolution  string   `json:"minResolution,omitempty"`  // e.g. "2160p"
+	Sources        []string `json:"sources,omitempty"`        // remux, bluray, web-dl, webrip, hdtv, dvd
+	RequireHDR     bool     `json:"requireHdr,omitempty"`     // Any HDR or Dolby Vision format
+	ProperOrRepack bool     `json:"properOrRepack,omitempty"` // Only PROPER/REPACK releases
+	RequiredTerms  []string `json:"requiredTerms,omitempty"`  // All must appear in the release title
+	ExcludedTerms  []string `json:"excludedTerms,omitempty"`  // None may appear in the release title
+	MinSizeGB      float64  `json:"minSizeGb,omitempty"`
+	MaxSizeGB      float64  `json:"maxSizeGb,omitempty"`
+}
+
+// SavedSearch is a profile's release search that is re-run in the background.
+// Matching releases that weren't there before are collected in NewResults
+// until the profile acknowledges them.
+type SavedSearch struct {
+	ID        string             `json:"id"`
+	UserID    string             `json:"userId"`
+	Name      string             `json:"name"`
+	Query     string             `json:"query"`
+	MediaType string             `json:"mediaType,omitempty"` // "movie" or "series"
+	IMDBID    string             `json:"imdbId,omitempty"`
+	Year      int                `json:"year,omitempty"`
+	Filters   SavedSearchFilters `json:"filters"`
+	CreatedAt time.Time          `json:"createdAt"`
+
+	LastCheckedAt *time.Time  `json:"lastCheckedAt,omitempty"` // Last successful check
+	LastError     string      `json:"lastError,omitempty"`
+	NewResults    []NZBResult `json:"newResults,omitempty"`
+	LastNewAt     *time.Time  `json:"lastNewAt,omitempty"` // When the newest of NewResults appeared
+	// SeenReleases are the normalized titles of matches already reported, or
+	// present when the search was first checked
+	SeenReleases []string `json:"seenReleases,omitempty"`
+}
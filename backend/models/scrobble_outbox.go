@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ScrobbleOutboxEntry is a Trakt scrobble that has not been delivered yet.
+// Entries stay queued across restarts until Trakt accepts them or an admin discards them.
+type ScrobbleOutboxEntry struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"userId"`
+	MediaType     string    `json:"mediaType"` // "movie" or "episode"
+	TMDBID        int       `json:"tmdbId,omitempty"`
+	TVDBID        int       `json:"tvdbId,omitempty"` // Show TVDB ID for episodes
+	IMDBID        string    `json:"imdbId,omitempty"`
+	SeasonNumber  int       `json:"seasonNumber,omitempty"`
+	EpisodeNumber int       `json:"episodeNumber,omitempty"`
+	WatchedAt     time.Time `json:"watchedAt"`
+	QueuedAt      time.Time `json:"queuedAt"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"lastError,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	// DeadLettered entries are no longer retried automatically: Trakt rejected
+	// them outright or they ran out of attempts. A flush still retries them.
+	DeadLettered bool `json:"deadLettered,omitempty"`
+}
+
+// ScrobbleOutboxFlushResult reports the outcome of delivering every queued scrobble at once.
+type ScrobbleOutboxFlushResult struct {
+	Attempted int `json:"attempted"`
+	Delivered int `json:"delivered"`
+	Remaining int `json:"remaining"`
+}
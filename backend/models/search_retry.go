@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Search retry states.
+const (
+	SearchRetryPending = "pending" // still searching on a backoff schedule
+	SearchRetryFound   = "found"   // a retry found releases
+	SearchRetryExpired = "expired" // gave up without finding any
+)
+
+// SearchRetry is an episode search that found nothing shortly after the
+// episode aired and is retried in the background until releases appear.
+type SearchRetry struct {
+	ID                    string `json:"id"`
+	UserID                string `json:"userId"`
+	ClientID              string `json:"clientId,omitempty"`
+	TitleID               string `json:"titleId,omitempty"`
+	TitleName             string `json:"titleName"`
+	IMDBID                string `json:"imdbId,omitempty"`
+	Year                  int    `json:"year,omitempty"`
+	SeasonNumber          int    `json:"seasonNumber"`
+	EpisodeNumber         int    `json:"episodeNumber"`
+	AbsoluteEpisodeNumber int    `json:"absoluteEpisodeNumber,omitempty"`
+	AirDate               string `json:"airDate"` // YYYY-MM-DD
+	IsDaily               bool   `json:"isDaily,omitempty"`
+	IsAnime               bool   `json:"isAnime,omitempty"`
+	RuntimeMinutes        int    `json:"runtimeMinutes,omitempty"`
+	Query                 string `json:"query"`
+
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	FoundAt       *time.Time `json:"foundAt,omitempty"`
+	// Results are the best releases found, kept so clients can offer the
+	// episode without searching again
+	Results []NZBResult `json:"results,omitempty"`
+}
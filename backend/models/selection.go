@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Outcomes of an automatic release selection.
+const (
+	SelectionOutcomeResolved = "resolved"
+	SelectionOutcomeFailed   = "failed"
+)
+
+// SelectionOutcome records how automatic release selection went for one
+// playback request under a selection strategy.
+type SelectionOutcome struct {
+	Strategy  string `json:"strategy"`
+	UserID    string `json:"userId,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+	Outcome   string `json:"outcome"`
+	// Candidates is how many results were tried, including the one selected
+	Candidates  int                `json:"candidates"`
+	ServiceType ContentServiceType `json:"serviceType,omitempty"`
+	Release     string             `json:"release,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	At          time.Time          `json:"at"`
+}
+
+// SelectionStrategyStats aggregates the outcomes of one selection strategy.
+type SelectionStrategyStats struct {
+	Strategy   string `json:"strategy"`
+	Selections int64  `json:"selections"`
+	Resolved   int64  `json:"resolved"`
+	Failed     int64  `json:"failed"`
+	// FirstPick counts selections resolved by the strategy's top result
+	FirstPick  int64     `json:"firstPick"`
+	Candidates int64     `json:"candidates"`
+	LastAt     time.Time `json:"lastAt"`
+
+	FailureRate   float64 `json:"failureRate"`
+	FirstPickRate float64 `json:"firstPickRate"`
+	AvgCandidates float64 `json:"avgCandidates"`
+}
+
+// SelectionStatsSummary compares selection strategies for the admin dashboard.
+type SelectionStatsSummary struct {
+	Strategies []SelectionStrategyStats `json:"strategies"`
+	Recent     []SelectionOutcome       `json:"recent"`
+}
@@ -1,19 +1,32 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // Session represents an authenticated session for an account.
 type Session struct {
-	Token     string    `json:"token"`
-	AccountID string    `json:"accountId"`
-	IsMaster  bool      `json:"isMaster"`   // Cached from account for quick access
-	ExpiresAt time.Time `json:"expiresAt"`
-	CreatedAt time.Time `json:"createdAt"`
-	UserAgent string    `json:"userAgent,omitempty"`
-	IPAddress string    `json:"ipAddress,omitempty"`
+	Token      string    `json:"token"`
+	AccountID  string    `json:"accountId"`
+	IsMaster   bool      `json:"isMaster"` // Cached from account for quick access
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	Source     string    `json:"source,omitempty"`     // Where the session was created, e.g. "admin-ui"; empty for app logins
+	RememberMe bool      `json:"rememberMe,omitempty"` // Created with the long-lived "remember me" duration
 }
 
 // IsExpired returns true if the session has expired.
 func (s Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
+
+// ID returns a stable identifier for the session that can be shown and used to
+// revoke it without exposing the token.
+func (s Session) ID() string {
+	sum := sha256.Sum256([]byte(s.Token))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ShareLink is a tokenized, expiring link to one resolved stream that a
+// profile can send to someone without an account. Anyone holding the token
+// can watch the stream until it expires, is revoked or runs out of views.
+type ShareLink struct {
+	ID         string     `json:"id"`
+	Token      string     `json:"token"` // Secret part of the public URL
+	UserID     string     `json:"userId"`
+	AccountID  string     `json:"accountId,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	Path       string     `json:"path"` // Resolved stream path as returned by playback resolution
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	MaxViews   int        `json:"maxViews"`
+	Views      int        `json:"views"`
+	LastViewAt *time.Time `json:"lastViewAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SourceSwitch records a playback session moving off a degraded usenet
+// release onto a cached debrid release of the same title.
+type SourceSwitch struct {
+	FromProvider string    `json:"fromProvider"` // usenet
+	ToProvider   string    `json:"toProvider"`   // debrid provider, e.g. realdebrid
+	FromRelease  string    `json:"fromRelease,omitempty"`
+	ToRelease    string    `json:"toRelease,omitempty"`
+	Position     float64   `json:"position"` // Seconds the new source resumes at
+	SwitchedAt   time.Time `json:"switchedAt"`
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// How a profile wants a series' specials (season 0) handled.
+const (
+	SpecialsSeparate = "separate" // Default: season 0 is its own season and never follows a regular episode
+	SpecialsHidden   = "hidden"   // Specials are left out of season lists and up next
+	SpecialsInline   = "inline"   // Specials sit among the regular episodes by air date
+)
+
+// SpecialsSettings is a profile's specials handling preference.
+type SpecialsSettings struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+// NormalizeSpecialsMode returns a known mode, defaulting to SpecialsSeparate.
+func NormalizeSpecialsMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case SpecialsHidden, SpecialsInline:
+		return mode
+	}
+	return SpecialsSeparate
+}
+
+// ApplySpecialsMode returns the details with season 0 arranged for mode;
+// details itself is not modified. Inline places each dated special after the
+// last regular episode that aired on or before it (at the start of the first
+// season if none did) and keeps its season 0 numbering so it still plays as
+// a special. Undated specials stay in season 0.
+func ApplySpecialsMode(details *SeriesDetails, mode string) *SeriesDetails {
+	if details == nil {
+		return nil
+	}
+	mode = NormalizeSpecialsMode(mode)
+	if mode == SpecialsSeparate {
+		return details
+	}
+
+	arranged := *details
+	arranged.Seasons = make([]SeriesSeason, 0, len(details.Seasons))
+	var specials []SeriesEpisode
+	var specialsSeason *SeriesSeason
+	for _, season := range details.Seasons {
+		if season.Number == 0 {
+			specials = append(specials, season.Episodes...)
+			copied := season
+			specialsSeason = &copied
+			continue
+		}
+		season.Episodes = sortedEpisodes(season)
+		arranged.Seasons = append(arranged.Seasons, season)
+	}
+	if mode == SpecialsHidden || len(specials) == 0 {
+		return &arranged
+	}
+
+	sort.SliceStable(arranged.Seasons, func(i, j int) bool { return arranged.Seasons[i].Number < arranged.Seasons[j].Number })
+
+	// Earlier specials are placed first, so later ones land after them
+	sort.SliceStable(specials, func(i, j int) bool { return specials[i].AiredDate < specials[j].AiredDate })
+
+	var undated []SeriesEpisode
+	for _, special := range specials {
+		aired, ok := parseAirDate(special.AiredDate)
+		if !ok || len(arranged.Seasons) == 0 {
+			undated = append(undated, special)
+			continue
+		}
+		seasonIdx, pos := 0, 0
+		for i, season := range arranged.Seasons {
+			for j, ep := range season.Episodes {
+				if epAired, ok := parseAirDate(ep.AiredDate); ok && !epAired.After(aired) {
+					seasonIdx, pos = i, j+1
+				}
+			}
+		}
+		season := &arranged.Seasons[seasonIdx]
+		season.Episodes = append(season.Episodes[:pos], append([]SeriesEpisode{special}, season.Episodes[pos:]...)...)
+		season.EpisodeCount = len(season.Episodes)
+	}
+
+	if len(undated) > 0 && specialsSeason != nil {
+		specialsSeason.Episodes = undated
+		specialsSeason.EpisodeCount = len(undated)
+		arranged.Seasons = append([]SeriesSeason{*specialsSeason}, arranged.Seasons...)
+	}
+	return &arranged
+}
+
+// EpisodesInViewingOrder flattens the series into the order episodes are
+// watched in under mode. Outside inline mode season 0 comes first, so
+// regular episodes are never followed by a special.
+func EpisodesInViewingOrder(details *SeriesDetails, mode string) []SeriesEpisode {
+	arranged := ApplySpecialsMode(details, mode)
+	if arranged == nil {
+		return nil
+	}
+
+	seasons := append([]SeriesSeason(nil), arranged.Seasons...)
+	sort.SliceStable(seasons, func(i, j int) bool { return seasons[i].Number < seasons[j].Number })
+
+	var episodes []SeriesEpisode
+	for _, season := range seasons {
+		if NormalizeSpecialsMode(mode) == SpecialsInline && season.Number != 0 {
+			// Already ordered, with specials in place
+			episodes = append(episodes, season.Episodes...)
+			continue
+		}
+		episodes = append(episodes, sortedEpisodes(season)...)
+	}
+	return episodes
+}
+
+// sortedEpisodes returns a copy of the season's episodes in episode order,
+// filling in missing season numbers.
+func sortedEpisodes(season SeriesSeason) []SeriesEpisode {
+	episodes := make([]SeriesEpisode, len(season.Episodes))
+	copy(episodes, season.Episodes)
+	for i := range episodes {
+		if episodes[i].SeasonNumber <= 0 {
+			episodes[i].SeasonNumber = season.Number
+		}
+	}
+	sort.SliceStable(episodes, func(i, j int) bool { return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber })
+	return episodes
+}
+
+func parseAirDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	aired, err := time.Parse("2006-01-02", value)
+	return aired, err == nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// SpoilerHold is a profile's "don't spoil" hold on a range of a series'
+// episodes. Other profiles in the same household see a spoiler warning on
+// those episodes until the holding profile has watched them.
+type SpoilerHold struct {
+	ID          string `json:"id"`
+	ProfileID   string `json:"profileId"`
+	SeriesID    string `json:"seriesId"`
+	SeriesName  string `json:"seriesName,omitempty"`
+	FromSeason  int    `json:"fromSeason"`
+	FromEpisode int    `json:"fromEpisode"`
+	ToSeason    int    `json:"toSeason"`
+	// ToEpisode 0 holds through the end of ToSeason
+	ToEpisode int       `json:"toEpisode"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Covers reports whether an episode falls within the hold's range.
+func (h SpoilerHold) Covers(season, episode int) bool {
+	if season < h.FromSeason || (season == h.FromSeason && episode < h.FromEpisode) {
+		return false
+	}
+	if season > h.ToSeason || (season == h.ToSeason && h.ToEpisode > 0 && episode > h.ToEpisode) {
+		return false
+	}
+	return true
+}
+
+// SpoilerHoldRequest is the body for creating a spoiler hold. ToSeason
+// defaults to FromSeason and FromEpisode to the first episode.
+type SpoilerHoldRequest struct {
+	SeriesID    string `json:"seriesId"`
+	SeriesName  string `json:"seriesName,omitempty"`
+	FromSeason  int    `json:"fromSeason"`
+	FromEpisode int    `json:"fromEpisode,omitempty"`
+	ToSeason    int    `json:"toSeason,omitempty"`
+	ToEpisode   int    `json:"toEpisode,omitempty"`
+}
+
+// SpoilerWarning flags an episode that another household profile is holding.
+type SpoilerWarning struct {
+	HeldBy []SpoilerHolder `json:"heldBy"`
+}
+
+// SpoilerHolder identifies the profile behind a spoiler warning.
+type SpoilerHolder struct {
+	ProfileID   string `json:"profileId"`
+	ProfileName string `json:"profileName"`
+}
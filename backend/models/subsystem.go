@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Subsystem states reported by the startup supervisor.
+const (
+	SubsystemStarting = "starting"
+	SubsystemReady    = "ready"
+	SubsystemRetrying = "retrying" // Initialisation failed; the server runs without it until a retry succeeds
+	SubsystemStopped  = "stopped"  // Gave up because the server is shutting down
+)
+
+// SubsystemStatus is the initialisation state of an optional subsystem.
+type SubsystemStatus struct {
+	Name          string     `json:"name"`
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	ReadyAt       *time.Time `json:"readyAt,omitempty"`
+}
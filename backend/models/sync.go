@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Sync collections tracked by the per-profile change journal.
+const (
+	SyncCollectionWatchlist = "watchlist"
+	SyncCollectionHistory   = "history"
+	SyncCollectionProgress  = "progress"
+)
+
+// Sync change operations. A delete is a tombstone: the client should drop the key.
+const (
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+)
+
+// SyncChange is a single journal entry for an item in a profile's collection.
+type SyncChange struct {
+	Seq        int64     `json:"seq"`
+	Collection string    `json:"collection"`
+	Key        string    `json:"key"`
+	Op         string    `json:"op"`
+	ChangedAt  time.Time `json:"changedAt"`
+}
+
+// SyncCollectionDelta holds the changed items and tombstoned keys of one collection.
+type SyncCollectionDelta[T any] struct {
+	Upserts []T      `json:"upserts"`
+	Deletes []string `json:"deletes"`
+}
+
+// SyncDelta is the response of an incremental sync. When Reset is true the
+// client's cursor was too old (or absent) and the upserts are a full snapshot
+// that replaces local state.
+type SyncDelta struct {
+	Cursor    int64                                  `json:"cursor"`
+	Reset     bool                                   `json:"reset"`
+	ServerNow time.Time                              `json:"serverNow"`
+	Watchlist *SyncCollectionDelta[WatchlistItem]    `json:"watchlist,omitempty"`
+	History   *SyncCollectionDelta[WatchHistoryItem] `json:"history,omitempty"`
+	Progress  *SyncCollectionDelta[PlaybackProgress] `json:"progress,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TitleNote is a note on a title that every profile of the account can see,
+// e.g. "we stopped at S02E04 because...".
+type TitleNote struct {
+	ID                string    `json:"id"`
+	TitleID           string    `json:"titleId"`
+	MediaType         string    `json:"mediaType,omitempty"` // movie | series
+	TitleName         string    `json:"titleName,omitempty"`
+	Body              string    `json:"body"`
+	AuthorProfileID   string    `json:"authorProfileId"`
+	AuthorName        string    `json:"authorName,omitempty"`        // Empty once the author profile is gone
+	EditedByProfileID string    `json:"editedByProfileId,omitempty"` // Last profile to edit the note
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// TitleNoteRequest is the body for adding a note to a title.
+type TitleNoteRequest struct {
+	TitleID   string `json:"titleId"`
+	MediaType string `json:"mediaType,omitempty"`
+	TitleName string `json:"titleName,omitempty"`
+	Body      string `json:"body"`
+}
+
+// TitleNoteUpdate is the body for editing a note.
+type TitleNoteUpdate struct {
+	Body string `json:"body"`
+}
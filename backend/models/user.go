@@ -25,6 +25,9 @@ type User struct {
 	IsKidsProfile  bool      `json:"isKidsProfile"`            // Whether this is a kids profile with content restrictions
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
+	// Set while the profile is soft-deleted; it can be restored until PurgeAt
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	PurgeAt   *time.Time `json:"purgeAt,omitempty"`
 }
 
 // HasPin returns true if the user has a PIN set.
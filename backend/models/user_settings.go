@@ -29,6 +29,27 @@ type UserSettings struct {
 	Display     DisplaySettings      `json:"display"`
 	Network     NetworkSettings      `json:"network"`
 	Ranking     *UserRankingSettings `json:"ranking,omitempty"`
+
+	ContinueWatching ContinueWatchingSettings `json:"continueWatching"`
+	Specials         SpecialsSettings         `json:"specials"`
+
+	// Synced UI preferences; only changed through the preferences endpoint
+	ClientPreferences *ClientPreferences `json:"clientPreferences,omitempty"`
+}
+
+// ContinueWatchingSettings is a profile's continue-watching retention policy.
+// Zero values disable each rule.
+type ContinueWatchingSettings struct {
+	// ExpireAfterDays archives items untouched for this many days
+	ExpireAfterDays int `json:"expireAfterDays,omitempty"`
+	// AutoCompletePercent marks items watched once progress reaches this
+	// percentage (e.g. 95), for titles abandoned during the credits
+	AutoCompletePercent float64 `json:"autoCompletePercent,omitempty"`
+}
+
+// Enabled reports whether any retention rule is set.
+func (c ContinueWatchingSettings) Enabled() bool {
+	return c.ExpireAfterDays > 0 || c.AutoCompletePercent > 0
 }
 
 // NetworkSettings configures network-aware backend URL switching.
@@ -65,11 +86,14 @@ type PlaybackSettings struct {
 	PreferredSubtitleMode     string  `json:"preferredSubtitleMode,omitempty"`
 	UseLoadingScreen          bool    `json:"useLoadingScreen,omitempty"`
 	SubtitleSize              float64 `json:"subtitleSize,omitempty"` // Scaling factor for subtitle size (1.0 = default)
+	// Accessibility: auto-select audio-description (AD) audio and SDH subtitle tracks when available
+	PreferAudioDescription bool `json:"preferAudioDescription,omitempty"`
+	PreferSDH              bool `json:"preferSdh,omitempty"`
 }
 
 // ShelfConfig represents a configurable home screen shelf.
 type ShelfConfig struct {
-	ID             string `json:"id"`                       // Unique identifier (e.g., "continue-watching", "watchlist", "trending-movies")
+	ID             string `json:"id"`                       // Unique identifier (e.g., "continue-watching", "watchlist", "favorites", "trending-movies")
 	Name           string `json:"name"`                     // Display name
 	Enabled        bool   `json:"enabled"`                  // Whether the shelf is visible
 	Order          int    `json:"order"`                    // Sort order (lower numbers appear first)
@@ -130,8 +154,9 @@ func DefaultUserSettings() UserSettings {
 			Shelves: []ShelfConfig{
 				{ID: "continue-watching", Name: "Continue Watching", Enabled: true, Order: 0},
 				{ID: "watchlist", Name: "Your Watchlist", Enabled: true, Order: 1},
-				{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 2},
-				{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 3},
+				{ID: "favorites", Name: "Your Favorites", Enabled: true, Order: 2},
+				{ID: "trending-movies", Name: "Trending Movies", Enabled: true, Order: 3},
+				{ID: "trending-tv", Name: "Trending TV Shows", Enabled: true, Order: 4},
 			},
 			TrendingMovieSource: TrendingMovieSourceReleased,
 		},
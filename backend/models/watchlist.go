@@ -40,3 +40,45 @@ func (w WatchlistUpsert) Key() string {
 func (w WatchlistItem) Key() string {
 	return w.MediaType + ":" + w.ID
 }
+
+// WatchlistPermission is what a profile may do with a watchlist shared with it.
+type WatchlistPermission string
+
+const (
+	WatchlistPermissionRead WatchlistPermission = "read" // View the items
+	WatchlistPermissionEdit WatchlistPermission = "edit" // Collaborative: also add and remove items
+)
+
+// WatchlistShare gives another profile of the household access to a
+// profile's watchlist.
+type WatchlistShare struct {
+	OwnerID    string              `json:"ownerId"`   // Profile whose watchlist is shared
+	ProfileID  string              `json:"profileId"` // Profile it is shared with
+	Permission WatchlistPermission `json:"permission"`
+	SharedAt   time.Time           `json:"sharedAt"`
+}
+
+// WatchlistShares lists the shares a profile granted and received.
+type WatchlistShares struct {
+	Granted  []WatchlistShare `json:"granted"`  // The profile's watchlist shared with others
+	Received []WatchlistShare `json:"received"` // Other watchlists shared with the profile
+}
+
+// Watchlist availability sources.
+const (
+	AvailabilitySourceDebrid = "debrid"
+	AvailabilitySourceUsenet = "usenet"
+)
+
+// WatchlistAvailability reports whether a watchlist item currently has at least
+// one healthy stream: a cached debrid release or a complete usenet release.
+type WatchlistAvailability struct {
+	ID        string    `json:"id"`
+	MediaType string    `json:"mediaType"`
+	Ready     bool      `json:"ready"`
+	Source    string    `json:"source,omitempty"`  // debrid | usenet, when ready
+	Release   string    `json:"release,omitempty"` // title of the healthy release
+	Error     string    `json:"error,omitempty"`   // set when the check could not complete
+	Pending   bool      `json:"pending,omitempty"` // a check is queued or running
+	CheckedAt time.Time `json:"checkedAt"`
+}
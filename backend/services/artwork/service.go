@@ -0,0 +1,360 @@
+package artwork
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrArtworkUnavailable = errors.New("artwork unavailable from primary and fallback sources")
+)
+
+const (
+	// How long a substitution is trusted before the primary is checked again
+	substitutionTTL = 7 * 24 * time.Hour
+
+	// How long a working (or completely dead) primary is trusted
+	recheckInterval = 24 * time.Hour
+
+	// Transient failures (429, 5xx, network errors) are retried with
+	// exponential backoff starting at retryBackoff
+	maxFetchAttempts = 3
+	retryBackoff     = 500 * time.Millisecond
+	maxRetryAfter    = 5 * time.Second
+
+	// Bounds background validations started while composing responses
+	maxConcurrentChecks = 4
+	validationTimeout   = time.Minute
+)
+
+// errDead marks a URL that answered with a permanent failure such as 404.
+var errDead = errors.New("artwork url is dead")
+
+// substitution records the outcome of the last check of a primary URL.
+type substitution struct {
+	Primary string `json:"primary"`
+	// Substitute is the fallback serving in place of Primary, empty when
+	// Primary itself works.
+	Substitute string `json:"substitute,omitempty"`
+	// Unavailable is set when neither Primary nor any fallback worked.
+	Unavailable bool      `json:"unavailable,omitempty"`
+	CheckedAt   time.Time `json:"checkedAt"`
+}
+
+// Service fetches artwork with retries and substitutes fallback artwork for
+// dead primary URLs (typically TVDB assets that 404 or are rate limited).
+// Substitutions are cached on disk so responses can point at working artwork
+// without re-checking every URL.
+type Service struct {
+	mu      sync.Mutex
+	path    string
+	httpc   *http.Client
+	records map[string]substitution
+	pending map[string]bool
+	checks  chan struct{}
+	now     func() time.Time
+	backoff time.Duration
+
+	wg sync.WaitGroup
+}
+
+// NewService constructs an artwork service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create artwork dir: %w", err)
+	}
+
+	svc := &Service{
+		path:    filepath.Join(storageDir, "artwork_substitutions.json"),
+		httpc:   &http.Client{Timeout: 30 * time.Second},
+		records: make(map[string]substitution),
+		pending: make(map[string]bool),
+		checks:  make(chan struct{}, maxConcurrentChecks),
+		now:     time.Now,
+		backoff: retryBackoff,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Fetch retrieves the artwork at primary, trying the fallbacks in order when
+// it is dead. A cached substitute is tried first. It returns the successful
+// response and the URL that produced it; the caller must close the body.
+func (s *Service) Fetch(ctx context.Context, primary string, fallbacks []string) (*http.Response, string, error) {
+	candidates := append([]string{primary}, fallbacks...)
+	cached := ""
+	s.mu.Lock()
+	if rec, ok := s.records[primary]; ok && rec.Substitute != "" && s.freshLocked(rec) {
+		cached = rec.Substitute
+		candidates = append([]string{cached}, slices.DeleteFunc(candidates, func(u string) bool { return u == cached })...)
+	}
+	s.mu.Unlock()
+
+	var lastErr error
+	for _, candidate := range candidates {
+		resp, err := s.get(ctx, candidate)
+		if err == nil {
+			// Serving the cached substitute doesn't extend it; the primary
+			// is re-checked once the substitution expires
+			if candidate != cached {
+				s.record(substitution{Primary: primary, Substitute: substituteFor(primary, candidate)})
+			}
+			return resp, candidate, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		log.Printf("[artwork] %s unavailable: %v", candidate, err)
+		lastErr = err
+	}
+
+	s.record(substitution{Primary: primary, Unavailable: true})
+	return nil, "", fmt.Errorf("%w: %v", ErrArtworkUnavailable, lastErr)
+}
+
+// Resolve returns img with any cached substitution applied so responses point
+// at working artwork. Images whose primary URL has not been checked recently
+// are validated in the background; the result applies to later responses.
+// img is never modified; a copy is returned when a substitution applies.
+func (s *Service) Resolve(img *models.Image) *models.Image {
+	if img == nil || img.URL == "" || len(img.Fallbacks) == 0 {
+		return img
+	}
+
+	s.mu.Lock()
+	rec, ok := s.records[img.URL]
+	fresh := ok && s.freshLocked(rec)
+	s.mu.Unlock()
+
+	if !fresh {
+		s.validate(img.URL, img.Fallbacks)
+	}
+	if !ok || rec.Substitute == "" {
+		return img
+	}
+
+	resolved := *img
+	resolved.URL = rec.Substitute
+	// Keep the original around in case it recovers before the next check
+	resolved.Fallbacks = append([]string{img.URL}, slices.DeleteFunc(slices.Clone(img.Fallbacks), func(u string) bool { return u == rec.Substitute })...)
+	return &resolved
+}
+
+// validate checks primary in the background unless a check is already running.
+func (s *Service) validate(primary string, fallbacks []string) {
+	s.mu.Lock()
+	if s.pending[primary] {
+		s.mu.Unlock()
+		return
+	}
+	s.pending[primary] = true
+	s.mu.Unlock()
+
+	fallbacks = slices.Clone(fallbacks)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.pending, primary)
+			s.mu.Unlock()
+		}()
+
+		s.checks <- struct{}{}
+		defer func() { <-s.checks }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+		defer cancel()
+
+		resp, used, err := s.Fetch(ctx, primary, fallbacks)
+		if err != nil {
+			log.Printf("[artwork] validation failed for %s: %v", primary, err)
+			return
+		}
+		resp.Body.Close()
+		if used != primary {
+			log.Printf("[artwork] substituting %s for dead artwork %s", used, primary)
+		}
+	}()
+}
+
+// get requests url, retrying rate limits, server errors and network failures.
+// Any other non-200 status is reported as errDead.
+func (s *Service) get(ctx context.Context, url string) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxFetchAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, s.retryDelay(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.httpc.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = &retryableStatusError{status: resp.StatusCode, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+			continue
+		}
+		return nil, fmt.Errorf("%w: status %d", errDead, resp.StatusCode)
+	}
+	return nil, lastErr
+}
+
+// retryDelay honours a server's Retry-After (capped), otherwise doubles the backoff.
+func (s *Service) retryDelay(attempt int, lastErr error) time.Duration {
+	var statusErr *retryableStatusError
+	if errors.As(lastErr, &statusErr) && statusErr.retryAfter > 0 {
+		return min(statusErr.retryAfter, maxRetryAfter)
+	}
+	return s.backoff << (attempt - 1)
+}
+
+type retryableStatusError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func substituteFor(primary, used string) string {
+	if used == primary {
+		return ""
+	}
+	return used
+}
+
+// freshLocked reports whether rec is recent enough to trust.
+// Must be called with s.mu held.
+func (s *Service) freshLocked(rec substitution) bool {
+	ttl := recheckInterval
+	if rec.Substitute != "" {
+		ttl = substitutionTTL
+	}
+	return s.now().Sub(rec.CheckedAt) < ttl
+}
+
+// record stores the outcome of a check, persisting substitution changes.
+func (s *Service) record(rec substitution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec.CheckedAt = s.now().UTC()
+	prev, existed := s.records[rec.Primary]
+	s.records[rec.Primary] = rec
+	// Only substitutions are persisted
+	if rec.Substitute == "" && (!existed || prev.Substitute == "") {
+		return
+	}
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[artwork] failed to save substitutions: %v", err)
+	}
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open artwork substitutions: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read artwork substitutions: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var records []substitution
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("decode artwork substitutions: %w", err)
+	}
+	for _, rec := range records {
+		// Expired entries would only be re-checked, so drop them
+		if rec.Primary != "" && s.freshLocked(rec) {
+			s.records[rec.Primary] = rec
+		}
+	}
+	return nil
+}
+
+// saveLocked writes substitutions to disk. Only substitutions are persisted;
+// working primaries are cheap to re-check after a restart.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	records := make([]substitution, 0, len(s.records))
+	for _, rec := range s.records {
+		if rec.Substitute != "" && s.freshLocked(rec) {
+			records = append(records, rec)
+		}
+	}
+	slices.SortFunc(records, func(a, b substitution) int { return strings.Compare(a.Primary, b.Primary) })
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode artwork substitutions: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
@@ -0,0 +1,144 @@
+package artwork
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func newTestService(t *testing.T, dir string) *Service {
+	t.Helper()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	svc.backoff = time.Millisecond
+	return svc
+}
+
+func newArtworkServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchRetriesRateLimits(t *testing.T) {
+	var calls atomic.Int32
+	server := newArtworkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("poster"))
+	})
+	svc := newTestService(t, t.TempDir())
+
+	resp, used, err := svc.Fetch(t.Context(), server.URL+"/poster.jpg", nil)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if used != server.URL+"/poster.jpg" || calls.Load() != 3 {
+		t.Fatalf("used %q after %d calls, want primary after 3", used, calls.Load())
+	}
+}
+
+func TestFetchSubstitutesDeadArtwork(t *testing.T) {
+	var primaryCalls atomic.Int32
+	server := newArtworkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tvdb.jpg":
+			primaryCalls.Add(1)
+			http.NotFound(w, r)
+		case "/tvdb-alt.jpg":
+			http.NotFound(w, r)
+		default:
+			w.Write([]byte("tmdb"))
+		}
+	})
+	dir := t.TempDir()
+	svc := newTestService(t, dir)
+	primary := server.URL + "/tvdb.jpg"
+	fallbacks := []string{server.URL + "/tvdb-alt.jpg", server.URL + "/tmdb.jpg"}
+
+	resp, used, err := svc.Fetch(t.Context(), primary, fallbacks)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if used != fallbacks[1] || string(body) != "tmdb" {
+		t.Fatalf("used %q body %q, want TMDB fallback", used, body)
+	}
+	if primaryCalls.Load() != 1 {
+		t.Errorf("404 should not be retried, primary called %d times", primaryCalls.Load())
+	}
+
+	// The substitution survives a restart and is tried first
+	reloaded := newTestService(t, dir)
+	resp, used, err = reloaded.Fetch(t.Context(), primary, fallbacks)
+	if err != nil {
+		t.Fatalf("Fetch() after reload error = %v", err)
+	}
+	resp.Body.Close()
+	if used != fallbacks[1] || primaryCalls.Load() != 1 {
+		t.Errorf("used %q with %d primary calls, want cached substitute without re-checking", used, primaryCalls.Load())
+	}
+}
+
+func TestFetchAllDead(t *testing.T) {
+	server := newArtworkServer(t, http.NotFound)
+	svc := newTestService(t, t.TempDir())
+
+	_, _, err := svc.Fetch(t.Context(), server.URL+"/a.jpg", []string{server.URL + "/b.jpg"})
+	if !errors.Is(err, ErrArtworkUnavailable) {
+		t.Fatalf("Fetch() error = %v, want ErrArtworkUnavailable", err)
+	}
+}
+
+func TestResolveValidatesLazily(t *testing.T) {
+	server := newArtworkServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tvdb.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("tmdb"))
+	})
+	svc := newTestService(t, t.TempDir())
+	img := &models.Image{URL: server.URL + "/tvdb.jpg", Type: "poster", Fallbacks: []string{server.URL + "/tmdb.jpg"}}
+
+	// The first response goes out unchanged while the URL is checked
+	if got := svc.Resolve(img); got != img {
+		t.Fatalf("first Resolve() = %+v, want original image", got)
+	}
+	svc.wg.Wait()
+
+	got := svc.Resolve(img)
+	if got.URL != server.URL+"/tmdb.jpg" {
+		t.Fatalf("Resolve() URL = %q, want TMDB substitute", got.URL)
+	}
+	if len(got.Fallbacks) != 1 || got.Fallbacks[0] != img.URL {
+		t.Errorf("Resolve() fallbacks = %v, want original URL kept", got.Fallbacks)
+	}
+	if img.URL != server.URL+"/tvdb.jpg" {
+		t.Errorf("Resolve() modified its input")
+	}
+
+	// Once the substitution expires the primary is checked again
+	svc.now = func() time.Time { return time.Now().Add(substitutionTTL + time.Hour) }
+	svc.Resolve(img)
+	svc.wg.Wait()
+	svc.mu.Lock()
+	checkedAt := svc.records[img.URL].CheckedAt
+	svc.mu.Unlock()
+	if !checkedAt.After(time.Now()) {
+		t.Error("expired substitution was not re-validated")
+	}
+}
@@ -0,0 +1,144 @@
+// Package artwork_warmer keeps the image proxy's cache filled with the
+// artwork of every profile's continue-watching and watchlist rows, so the
+// screens people open most load their images from the server instead of
+// the internet.
+package artwork_warmer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"novastream/models"
+)
+
+// Images are fetched this many at a time
+const maxConcurrentWarms = 4
+
+// profileLister lists every profile.
+type profileLister interface {
+	ListAll() []models.User
+}
+
+// continueWatchingLister lists a profile's continue-watching row.
+type continueWatchingLister interface {
+	ListContinueWatching(userID string) ([]models.SeriesWatchState, error)
+}
+
+// watchlistLister lists a profile's watchlist.
+type watchlistLister interface {
+	List(userID string) ([]models.WatchlistItem, error)
+}
+
+// imageWarmer caches an image through the image proxy, reporting whether it
+// had to be fetched.
+type imageWarmer interface {
+	Warm(ctx context.Context, sourceURL string) (bool, error)
+}
+
+// Result summarises a warming run.
+type Result struct {
+	Images  int // Distinct artwork URLs across all rows
+	Fetched int // Images that were not cached yet
+	Failed  int
+}
+
+// Service warms the artwork of the continue-watching and watchlist rows.
+type Service struct {
+	profiles         profileLister
+	continueWatching continueWatchingLister
+	watchlist        watchlistLister
+	images           imageWarmer
+}
+
+// NewService constructs an artwork warmer.
+func NewService(profiles profileLister, continueWatching continueWatchingLister, watchlist watchlistLister, images imageWarmer) *Service {
+	return &Service{
+		profiles:         profiles,
+		continueWatching: continueWatching,
+		watchlist:        watchlist,
+		images:           images,
+	}
+}
+
+// Warm caches the poster and backdrop of every item in each profile's
+// continue-watching and watchlist rows. A failed image doesn't stop the run;
+// failures are counted and reported together.
+func (s *Service) Warm(ctx context.Context) (Result, error) {
+	urls := s.collectURLs()
+	result := Result{Images: len(urls)}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentWarms)
+	for _, url := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fetched, err := s.images.Warm(ctx, url)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("[artwork_warmer] failed to warm %s: %v", url, err)
+				result.Failed++
+				return
+			}
+			if fetched {
+				result.Fetched++
+			}
+		}(url)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("artwork warming interrupted: %w", err)
+	}
+	if result.Failed > 0 {
+		return result, fmt.Errorf("%d of %d images failed to warm", result.Failed, result.Images)
+	}
+	return result, nil
+}
+
+// collectURLs returns the distinct artwork URLs of every profile's rows,
+// continue watching first.
+func (s *Service) collectURLs() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(candidates ...string) {
+		for _, url := range candidates {
+			if url != "" && !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+
+	for _, profile := range s.profiles.ListAll() {
+		if s.continueWatching != nil {
+			items, err := s.continueWatching.ListContinueWatching(profile.ID)
+			if err != nil {
+				log.Printf("[artwork_warmer] continue watching unavailable for %s: %v", profile.ID, err)
+			}
+			for _, item := range items {
+				add(item.PosterURL, item.BackdropURL)
+			}
+		}
+		if s.watchlist != nil {
+			items, err := s.watchlist.List(profile.ID)
+			if err != nil {
+				log.Printf("[artwork_warmer] watchlist unavailable for %s: %v", profile.ID, err)
+			}
+			for _, item := range items {
+				add(item.PosterURL, item.BackdropURL)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,82 @@
+package artwork_warmer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"novastream/models"
+)
+
+type fakeProfiles []models.User
+
+func (f fakeProfiles) ListAll() []models.User { return f }
+
+type fakeContinueWatching map[string][]models.SeriesWatchState
+
+func (f fakeContinueWatching) ListContinueWatching(userID string) ([]models.SeriesWatchState, error) {
+	return f[userID], nil
+}
+
+type fakeWatchlist map[string][]models.WatchlistItem
+
+func (f fakeWatchlist) List(userID string) ([]models.WatchlistItem, error) {
+	return f[userID], nil
+}
+
+type fakeImages struct {
+	mu     sync.Mutex
+	cached map[string]bool
+	warmed []string
+}
+
+func (f *fakeImages) Warm(ctx context.Context, url string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.warmed = append(f.warmed, url)
+	if url == "https://image.tmdb.org/t/p/w780/broken.jpg" {
+		return false, errors.New("404")
+	}
+	if f.cached[url] {
+		return false, nil
+	}
+	f.cached[url] = true
+	return true, nil
+}
+
+func TestWarmCoversEveryProfilesRowsOnce(t *testing.T) {
+	images := &fakeImages{cached: map[string]bool{"https://image.tmdb.org/t/p/w780/cached.jpg": true}}
+	svc := NewService(
+		fakeProfiles{{ID: "alice"}, {ID: "bob"}},
+		fakeContinueWatching{
+			"alice": {{PosterURL: "https://image.tmdb.org/t/p/w780/show.jpg", BackdropURL: "https://image.tmdb.org/t/p/w1280/show.jpg"}},
+		},
+		fakeWatchlist{
+			"alice": {{PosterURL: "https://image.tmdb.org/t/p/w780/cached.jpg"}},
+			"bob":   {{PosterURL: "https://image.tmdb.org/t/p/w780/show.jpg"}, {PosterURL: "https://image.tmdb.org/t/p/w780/broken.jpg"}},
+		},
+		images,
+	)
+
+	result, err := svc.Warm(t.Context())
+	if err == nil {
+		t.Fatal("expected the broken image to be reported")
+	}
+	if result.Images != 4 || result.Fetched != 2 || result.Failed != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	slices.Sort(images.warmed)
+	want := []string{
+		"https://image.tmdb.org/t/p/w1280/show.jpg",
+		"https://image.tmdb.org/t/p/w780/broken.jpg",
+		"https://image.tmdb.org/t/p/w780/cached.jpg",
+		"https://image.tmdb.org/t/p/w780/show.jpg",
+	}
+	if !slices.Equal(images.warmed, want) {
+		t.Errorf("warmed %v, want each URL once: %v", images.warmed, want)
+	}
+}
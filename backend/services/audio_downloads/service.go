@@ -0,0 +1,478 @@
+// Package audio_downloads extracts the audio of a title into a file in the
+// downloads area, for podcast-style offline listening. Extractions run one at
+// a time in the background and report their progress while they run.
+package audio_downloads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrExtractorRequired  = errors.New("audio extractor not provided")
+	ErrPathRequired       = errors.New("stream path is required")
+	ErrInvalidFormat      = errors.New("format must be m4a or opus")
+	ErrDownloadNotFound   = errors.New("audio download not found")
+	ErrNotCompleted       = errors.New("audio download has not completed")
+	ErrTooManyDownloads   = fmt.Errorf("a profile can have at most %d audio downloads", maxDownloadsPerUser)
+)
+
+const (
+	defaultM4ABitrate  = 128 // kbps
+	defaultOpusBitrate = 96
+	minBitrate         = 32
+	maxBitrate         = 320
+
+	maxDownloadsPerUser = 50
+
+	// How often the worker looks for queued downloads when not woken up
+	queueCheckInterval = 30 * time.Second
+
+	// Long specials over a slow provider can take a while to read through
+	extractTimeout = 6 * time.Hour
+)
+
+// ExtractOptions selects the audio track and encoding of an extraction.
+type ExtractOptions struct {
+	Format      string // models.AudioDownloadFormatM4A or models.AudioDownloadFormatOpus
+	BitrateKbps int
+	AudioTrack  int // Absolute stream index, -1 for the default track
+}
+
+// Extractor writes the audio of the stream at sourcePath to destPath,
+// reporting progress between 0 and 1 as it goes. Implemented by
+// *handlers.VideoHandler.
+type Extractor interface {
+	ExtractAudio(ctx context.Context, sourcePath, destPath string, opts ExtractOptions, progress func(float64)) error
+}
+
+// Service stores audio downloads in a JSON file on disk and runs their
+// extractions in the background.
+type Service struct {
+	mu        sync.Mutex
+	path      string
+	dir       string
+	extractor Extractor
+	downloads map[string]*models.AudioDownload
+	now       func() time.Time
+
+	// The running extraction, so deleting it can stop ffmpeg
+	runningID     string
+	cancelRunning context.CancelFunc
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs an audio download store. Files are written to
+// storageDir/downloads/audio.
+func NewService(storageDir string, extractor Extractor) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if extractor == nil {
+		return nil, ErrExtractorRequired
+	}
+
+	dir := filepath.Join(storageDir, "downloads", "audio")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create audio downloads dir: %w", err)
+	}
+
+	svc := &Service{
+		path:      filepath.Join(storageDir, "audio_downloads.json"),
+		dir:       dir,
+		extractor: extractor,
+		downloads: make(map[string]*models.AudioDownload),
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Create queues the extraction of a stream's audio for a profile.
+func (s *Service) Create(userID string, req models.AudioDownloadRequest) (models.AudioDownload, error) {
+	streamPath := strings.TrimSpace(req.Path)
+	if streamPath == "" {
+		return models.AudioDownload{}, ErrPathRequired
+	}
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	bitrate := req.BitrateKbps
+	switch format {
+	case "", models.AudioDownloadFormatM4A:
+		format = models.AudioDownloadFormatM4A
+		if bitrate <= 0 {
+			bitrate = defaultM4ABitrate
+		}
+	case models.AudioDownloadFormatOpus:
+		if bitrate <= 0 {
+			bitrate = defaultOpusBitrate
+		}
+	default:
+		return models.AudioDownload{}, ErrInvalidFormat
+	}
+	if bitrate < minBitrate {
+		bitrate = minBitrate
+	}
+	if bitrate > maxBitrate {
+		bitrate = maxBitrate
+	}
+
+	track := -1
+	if req.AudioTrack != nil && *req.AudioTrack >= 0 {
+		track = *req.AudioTrack
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(streamPath), filepath.Ext(streamPath))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, download := range s.downloads {
+		if download.UserID == userID {
+			count++
+		}
+	}
+	if count >= maxDownloadsPerUser {
+		return models.AudioDownload{}, ErrTooManyDownloads
+	}
+
+	download := models.AudioDownload{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		Title:       title,
+		Path:        streamPath,
+		Format:      format,
+		BitrateKbps: bitrate,
+		AudioTrack:  track,
+		Status:      models.AudioDownloadQueued,
+		CreatedAt:   s.now().UTC(),
+	}
+	s.downloads[download.ID] = &download
+	if err := s.saveLocked(); err != nil {
+		delete(s.downloads, download.ID)
+		return models.AudioDownload{}, err
+	}
+
+	log.Printf("[audio_downloads] user %s queued %q as %s at %dk", userID, title, format, bitrate)
+	s.signal()
+	return download, nil
+}
+
+// ListForUser returns a profile's audio downloads, newest first.
+func (s *Service) ListForUser(userID string) []models.AudioDownload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.AudioDownload, 0)
+	for _, download := range s.downloads {
+		if download.UserID == userID {
+			list = append(list, *download)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Get returns one of a profile's audio downloads.
+func (s *Service) Get(userID, id string) (models.AudioDownload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	download, ok := s.downloads[id]
+	if !ok || download.UserID != userID {
+		return models.AudioDownload{}, ErrDownloadNotFound
+	}
+	return *download, nil
+}
+
+// Open returns a completed download and its file. The caller closes the file.
+func (s *Service) Open(userID, id string) (models.AudioDownload, *os.File, error) {
+	download, err := s.Get(userID, id)
+	if err != nil {
+		return models.AudioDownload{}, nil, err
+	}
+	if download.Status != models.AudioDownloadCompleted {
+		return models.AudioDownload{}, nil, ErrNotCompleted
+	}
+
+	file, err := os.Open(s.filePath(download))
+	if err != nil {
+		return models.AudioDownload{}, nil, fmt.Errorf("open audio download: %w", err)
+	}
+	return download, file, nil
+}
+
+// Delete removes one of a profile's audio downloads and its file, stopping
+// the extraction if it is running.
+func (s *Service) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	download, ok := s.downloads[id]
+	if !ok || download.UserID != userID {
+		return ErrDownloadNotFound
+	}
+	if s.runningID == id && s.cancelRunning != nil {
+		s.cancelRunning()
+	}
+
+	delete(s.downloads, id)
+	for _, path := range []string{s.filePath(*download), s.filePath(*download) + ".part"} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[audio_downloads] failed to remove %s: %v", path, err)
+		}
+	}
+	return s.saveLocked()
+}
+
+// Start launches the background worker.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+	workerCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.loop(workerCtx)
+	s.signal()
+}
+
+// Stop ends the background worker. An interrupted extraction is queued again
+// and starts over on the next start.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(queueCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && s.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext extracts the oldest queued download. It reports whether there was
+// one to run.
+func (s *Service) runNext(ctx context.Context) bool {
+	s.mu.Lock()
+	var next *models.AudioDownload
+	for _, download := range s.downloads {
+		if download.Status != models.AudioDownloadQueued {
+			continue
+		}
+		if next == nil || download.CreatedAt.Before(next.CreatedAt) {
+			next = download
+		}
+	}
+	if next == nil {
+		s.mu.Unlock()
+		return false
+	}
+
+	started := s.now().UTC()
+	next.Status = models.AudioDownloadRunning
+	next.Progress = 0
+	next.Error = ""
+	next.StartedAt = &started
+	download := *next
+	runCtx, cancel := context.WithTimeout(ctx, extractTimeout)
+	s.runningID = download.ID
+	s.cancelRunning = cancel
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[audio_downloads] failed to persist downloads: %v", err)
+	}
+	s.mu.Unlock()
+
+	dest := s.filePath(download)
+	partial := dest + ".part"
+	log.Printf("[audio_downloads] extracting %q (%s)", download.Title, download.ID)
+
+	err := s.extractor.ExtractAudio(runCtx, download.Path, partial, ExtractOptions{
+		Format:      download.Format,
+		BitrateKbps: download.BitrateKbps,
+		AudioTrack:  download.AudioTrack,
+	}, func(progress float64) { s.setProgress(download.ID, progress) })
+	cancel()
+	if err == nil {
+		err = os.Rename(partial, dest)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runningID = ""
+	s.cancelRunning = nil
+	current, ok := s.downloads[download.ID]
+	if !ok {
+		// Deleted while running
+		os.Remove(partial)
+		return true
+	}
+
+	if ctx.Err() != nil {
+		// Shutting down; start over on the next start
+		os.Remove(partial)
+		current.Status = models.AudioDownloadQueued
+		current.Progress = 0
+		current.StartedAt = nil
+	} else if err != nil {
+		os.Remove(partial)
+		current.Status = models.AudioDownloadFailed
+		current.Error = err.Error()
+		log.Printf("[audio_downloads] extraction of %q (%s) failed: %v", download.Title, download.ID, err)
+	} else {
+		finished := s.now().UTC()
+		current.Status = models.AudioDownloadCompleted
+		current.Progress = 1
+		current.CompletedAt = &finished
+		if info, statErr := os.Stat(dest); statErr == nil {
+			current.SizeBytes = info.Size()
+		}
+		log.Printf("[audio_downloads] extracted %q (%s): %d bytes in %s", download.Title, download.ID, current.SizeBytes, finished.Sub(*current.StartedAt).Round(time.Second))
+	}
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[audio_downloads] failed to persist downloads: %v", err)
+	}
+	return true
+}
+
+// setProgress records the progress of a running extraction. Progress is only
+// kept in memory; it is persisted with the next status change.
+func (s *Service) setProgress(id string, progress float64) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 1 {
+		progress = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if download, ok := s.downloads[id]; ok && download.Status == models.AudioDownloadRunning {
+		download.Progress = progress
+	}
+}
+
+// signal wakes the worker without blocking.
+func (s *Service) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) filePath(download models.AudioDownload) string {
+	return filepath.Join(s.dir, download.ID+"."+download.Format)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audio downloads: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read audio downloads: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var downloads []models.AudioDownload
+	if err := json.Unmarshal(data, &downloads); err != nil {
+		return fmt.Errorf("decode audio downloads: %w", err)
+	}
+	for i := range downloads {
+		download := downloads[i]
+		if download.ID == "" {
+			continue
+		}
+		if download.Status == models.AudioDownloadRunning {
+			// Interrupted by a restart; start over
+			download.Status = models.AudioDownloadQueued
+			download.Progress = 0
+			download.StartedAt = nil
+		}
+		s.downloads[download.ID] = &download
+	}
+	return nil
+}
+
+// saveLocked writes the downloads to disk, oldest first.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]models.AudioDownload, 0, len(s.downloads))
+	for _, download := range s.downloads {
+		list = append(list, *download)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode audio downloads: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write audio downloads: %w", err)
+	}
+
+	return nil
+}
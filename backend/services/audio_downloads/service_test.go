@@ -0,0 +1,138 @@
+package audio_downloads
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+type fakeExtractor struct {
+	err     error
+	block   chan struct{}
+	started chan string
+	opts    []ExtractOptions
+}
+
+func (f *fakeExtractor) ExtractAudio(ctx context.Context, sourcePath, destPath string, opts ExtractOptions, progress func(float64)) error {
+	f.opts = append(f.opts, opts)
+	if f.started != nil {
+		f.started <- destPath
+	}
+	if f.block != nil {
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return f.err
+	}
+	progress(0.5)
+	return os.WriteFile(destPath, []byte("audio"), 0o644)
+}
+
+func newTestService(t *testing.T, extractor Extractor) *Service {
+	t.Helper()
+	svc, err := NewService(t.TempDir(), extractor)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc
+}
+
+func TestCreateValidatesAndDefaults(t *testing.T) {
+	svc := newTestService(t, &fakeExtractor{})
+
+	if _, err := svc.Create("u1", models.AudioDownloadRequest{Path: " "}); !errors.Is(err, ErrPathRequired) {
+		t.Errorf("expected ErrPathRequired, got %v", err)
+	}
+	if _, err := svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/a.mkv", Format: "flac"}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+
+	download, err := svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/Special.2024.mkv"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if download.Format != models.AudioDownloadFormatM4A || download.BitrateKbps != defaultM4ABitrate || download.AudioTrack != -1 || download.Title != "Special.2024" {
+		t.Fatalf("unexpected defaults: %+v", download)
+	}
+
+	track := 2
+	download, _ = svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/b.mkv", Format: "OPUS", BitrateKbps: 1000, AudioTrack: &track})
+	if download.Format != models.AudioDownloadFormatOpus || download.BitrateKbps != maxBitrate || download.AudioTrack != 2 {
+		t.Fatalf("unexpected opus download: %+v", download)
+	}
+
+	if _, err := svc.Get("u2", download.ID); !errors.Is(err, ErrDownloadNotFound) {
+		t.Errorf("read another profile's download: %v", err)
+	}
+	if _, _, err := svc.Open("u1", download.ID); !errors.Is(err, ErrNotCompleted) {
+		t.Errorf("expected ErrNotCompleted, got %v", err)
+	}
+}
+
+func TestWorkerExtractsQueuedDownloads(t *testing.T) {
+	extractor := &fakeExtractor{}
+	svc := newTestService(t, extractor)
+
+	first, _ := svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/a.mkv", Title: "A"})
+	if !svc.runNext(context.Background()) {
+		t.Fatal("expected a queued download to run")
+	}
+	got, _ := svc.Get("u1", first.ID)
+	if got.Status != models.AudioDownloadCompleted || got.Progress != 1 || got.SizeBytes != 5 || got.CompletedAt == nil {
+		t.Fatalf("unexpected completed download: %+v", got)
+	}
+	_, file, err := svc.Open("u1", first.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	file.Close()
+
+	extractor.err = errors.New("no audio")
+	failed, _ := svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/b.mkv"})
+	svc.runNext(context.Background())
+	if got, _ := svc.Get("u1", failed.ID); got.Status != models.AudioDownloadFailed || got.Error != "no audio" {
+		t.Fatalf("unexpected failed download: %+v", got)
+	}
+	if svc.runNext(context.Background()) {
+		t.Fatal("nothing should be left to run")
+	}
+
+	if err := svc.Delete("u1", first.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(svc.filePath(first)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file kept after delete: %v", err)
+	}
+}
+
+func TestDeleteStopsRunningExtraction(t *testing.T) {
+	extractor := &fakeExtractor{block: make(chan struct{}), started: make(chan string, 1)}
+	svc := newTestService(t, extractor)
+	svc.Start(context.Background())
+	defer svc.Stop()
+
+	download, _ := svc.Create("u1", models.AudioDownloadRequest{Path: "/webdav/a.mkv"})
+	select {
+	case <-extractor.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("extraction did not start")
+	}
+	if got, _ := svc.Get("u1", download.ID); got.Status != models.AudioDownloadRunning || got.StartedAt == nil {
+		t.Fatalf("unexpected running download: %+v", got)
+	}
+
+	if err := svc.Delete("u1", download.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if list := svc.ListForUser("u1"); len(list) != 0 {
+		t.Fatalf("deleted download still listed: %+v", list)
+	}
+}
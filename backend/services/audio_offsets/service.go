@@ -0,0 +1,154 @@
+package audio_offsets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrPathRequired       = errors.New("release path is required")
+)
+
+// MaxOffsetMs bounds how far audio can be shifted in either direction.
+const MaxOffsetMs = 30 * 1000
+
+// Offset is the remembered audio delay for a release. Positive values delay
+// the audio, for releases whose audio runs early.
+type Offset struct {
+	OffsetMs  int64     `json:"offsetMs"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Service persists per-release audio sync corrections, for remuxes with a
+// consistent A/V delay, so later plays start with the offset last chosen.
+type Service struct {
+	mu      sync.RWMutex
+	path    string
+	offsets map[string]Offset // release path -> offset
+}
+
+// NewService constructs an audio offset service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create audio offsets dir: %w", err)
+	}
+
+	svc := &Service{
+		path:    filepath.Join(storageDir, "audio_offsets.json"),
+		offsets: make(map[string]Offset),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Get returns the stored offset in milliseconds for a release (0 if none).
+func (s *Service) Get(releasePath string) int64 {
+	key := normalizePath(releasePath)
+	if key == "" {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.offsets[key].OffsetMs
+}
+
+// Set stores the offset for a release, clamped to ±MaxOffsetMs. A zero offset
+// clears the entry. Returns the offset actually stored.
+func (s *Service) Set(releasePath string, offsetMs int64) (int64, error) {
+	key := normalizePath(releasePath)
+	if key == "" {
+		return 0, ErrPathRequired
+	}
+
+	if offsetMs > MaxOffsetMs {
+		offsetMs = MaxOffsetMs
+	} else if offsetMs < -MaxOffsetMs {
+		offsetMs = -MaxOffsetMs
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offsetMs == 0 {
+		delete(s.offsets, key)
+	} else {
+		s.offsets[key] = Offset{OffsetMs: offsetMs, UpdatedAt: time.Now().UTC()}
+	}
+
+	if err := s.saveLocked(); err != nil {
+		return 0, err
+	}
+	return offsetMs, nil
+}
+
+// normalizePath strips the WebDAV prefix so direct and HLS sessions of the
+// same release share one entry.
+func normalizePath(releasePath string) string {
+	releasePath = strings.TrimSpace(releasePath)
+	return strings.TrimPrefix(releasePath, "/webdav")
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audio offsets: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read audio offsets: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &s.offsets); err != nil {
+		return fmt.Errorf("decode audio offsets: %w", err)
+	}
+	if s.offsets == nil {
+		s.offsets = make(map[string]Offset)
+	}
+
+	log.Printf("[audio_offsets] loaded offsets for %d releases", len(s.offsets))
+	return nil
+}
+
+// saveLocked writes the offsets to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.offsets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode audio offsets: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write audio offsets: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+// Package availability checks which watchlist items and episodes can be
+// played right now.
+package availability
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+	"novastream/services/debrid"
+	"novastream/services/indexer"
+	"novastream/services/playback"
+)
+
+const (
+	// defaultConcurrency bounds how many items are checked at once across all
+	// users, since every check runs an indexer search and health checks.
+	defaultConcurrency = 3
+	itemTimeout        = 90 * time.Second
+
+	// Ready items rarely disappear, while new releases appear for missing ones
+	readyTTL    = 6 * time.Hour
+	notReadyTTL = time.Hour
+	// Failed checks are retried soon, without hammering a down indexer
+	errorTTL = 5 * time.Minute
+	// How long an expired result is kept to show while it is rechecked
+	staleRetention = 24 * time.Hour
+
+	maxSearchResults = 20
+	// Debrid checks add and remove the torrent on the account, so try few
+	maxDebridChecks = 2
+	maxUsenetChecks = 3
+)
+
+type searcher interface {
+	Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error)
+}
+
+type debridChecker interface {
+	CheckHealthQuick(ctx context.Context, candidate models.NZBResult) (*debrid.DebridHealthCheck, error)
+}
+
+type usenetChecker interface {
+	ParallelHealthCheck(ctx context.Context, candidates []models.NZBResult, limit int) []playback.HealthCheckResult
+}
+
+var (
+	_ searcher      = (*indexer.Service)(nil)
+	_ debridChecker = (*debrid.PlaybackService)(nil)
+	_ usenetChecker = (*playback.Service)(nil)
+)
+
+// Episode identifies one episode of a series to check.
+type Episode struct {
+	ID            string
+	SeasonNumber  int
+	EpisodeNumber int
+}
+
+// target is a watchlist item, or one episode of a series, being checked.
+type target struct {
+	item    models.WatchlistItem
+	episode *Episode
+}
+
+func (t target) key() string {
+	if t.episode == nil {
+		return t.item.Key()
+	}
+	return fmt.Sprintf("%s:s%02de%02d", t.item.Key(), t.episode.SeasonNumber, t.episode.EpisodeNumber)
+}
+
+// blank is the result reported for the target before its first check.
+func (t target) blank() models.WatchlistAvailability {
+	if t.episode == nil {
+		return models.WatchlistAvailability{ID: t.item.ID, MediaType: t.item.MediaType}
+	}
+	return models.WatchlistAvailability{ID: t.episode.ID, MediaType: "episode"}
+}
+
+type cacheEntry struct {
+	result    models.WatchlistAvailability
+	expiresAt time.Time
+}
+
+// Service checks watchlist items for a healthy stream in the background with
+// bounded concurrency, caching results per user since filtering settings
+// differ between users.
+type Service struct {
+	search searcher
+	debrid debridChecker
+	usenet usenetChecker
+
+	sem chan struct{}
+	now func() time.Time
+
+	mu      sync.Mutex
+	cache   map[string]cacheEntry
+	pending map[string]bool
+}
+
+// NewService creates an availability checker. debridSvc and usenetSvc may be
+// nil when that source is not configured.
+func NewService(search searcher, debridSvc debridChecker, usenetSvc usenetChecker) *Service {
+	return &Service{
+		search:  search,
+		debrid:  debridSvc,
+		usenet:  usenetSvc,
+		sem:     make(chan struct{}, defaultConcurrency),
+		now:     time.Now,
+		cache:   make(map[string]cacheEntry),
+		pending: make(map[string]bool),
+	}
+}
+
+// Check returns the availability of each item, in the same order, without
+// waiting on indexers. Items with no fresh cached result (or all items, when
+// refresh is set) are queued for a background check and reported as pending,
+// alongside their previous result if there is one; clients poll until nothing
+// is pending.
+func (s *Service) Check(userID string, items []models.WatchlistItem, refresh bool) []models.WatchlistAvailability {
+	targets := make([]target, len(items))
+	for i, item := range items {
+		targets[i] = target{item: item}
+	}
+	return s.check(userID, targets, refresh)
+}
+
+// CheckEpisodes is like Check for episodes of a series, such as the ones a
+// profile hasn't watched yet. Each episode is searched for on its own.
+func (s *Service) CheckEpisodes(userID string, series models.WatchlistItem, episodes []Episode, refresh bool) []models.WatchlistAvailability {
+	targets := make([]target, len(episodes))
+	for i := range episodes {
+		targets[i] = target{item: series, episode: &episodes[i]}
+	}
+	return s.check(userID, targets, refresh)
+}
+
+func (s *Service) check(userID string, targets []target, refresh bool) []models.WatchlistAvailability {
+	results := make([]models.WatchlistAvailability, len(targets))
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range targets {
+		key := userID + "|" + t.key()
+		entry, cached := s.cache[key]
+		if cached {
+			results[i] = entry.result
+		} else {
+			results[i] = t.blank()
+		}
+		if s.pending[key] {
+			results[i].Pending = true
+			continue
+		}
+		if cached && !refresh && now.Before(entry.expiresAt) {
+			continue
+		}
+		results[i].Pending = true
+		s.pending[key] = true
+		go s.checkTarget(key, userID, t)
+	}
+	return results
+}
+
+func (s *Service) checkTarget(key, userID string, t target) {
+	result := s.runCheck(userID, t)
+
+	ttl := notReadyTTL
+	if result.Ready {
+		ttl = readyTTL
+	} else if result.Error != "" {
+		ttl = errorTTL
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, key)
+	// Drop long-expired results, e.g. of items since removed from watchlists.
+	// Recently expired ones are kept to show while their recheck runs
+	for k, entry := range s.cache {
+		if now.Sub(entry.expiresAt) > staleRetention {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = cacheEntry{result: result, expiresAt: now.Add(ttl)}
+}
+
+func (s *Service) runCheck(userID string, t target) models.WatchlistAvailability {
+	s.sem <- struct{}{}
+	defer func() { <-s.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), itemTimeout)
+	defer cancel()
+
+	if s.search == nil {
+		return s.failed(t, fmt.Errorf("indexer search unavailable"))
+	}
+
+	opts := searchOptions(userID, t)
+	if opts.Query == "" {
+		return s.failed(t, fmt.Errorf("item has no title"))
+	}
+	candidates, err := s.search.Search(ctx, opts)
+	if err != nil {
+		log.Printf("[availability] search failed for %s %q: %v", t.item.MediaType, opts.Query, err)
+		return s.failed(t, err)
+	}
+
+	result := t.blank()
+	result.CheckedAt = s.now().UTC()
+
+	var debridCandidates, usenetCandidates []models.NZBResult
+	for _, candidate := range candidates {
+		if candidate.ServiceType == models.ServiceTypeDebrid {
+			if len(debridCandidates) < maxDebridChecks {
+				debridCandidates = append(debridCandidates, candidate)
+			}
+		} else {
+			usenetCandidates = append(usenetCandidates, candidate)
+		}
+	}
+
+	if s.debrid != nil {
+		for _, candidate := range debridCandidates {
+			check, err := s.debrid.CheckHealthQuick(ctx, candidate)
+			if err != nil {
+				log.Printf("[availability] debrid check failed for %q: %v", candidate.Title, err)
+				continue
+			}
+			if check != nil && check.Healthy && check.Cached {
+				result.Ready = true
+				result.Source = models.AvailabilitySourceDebrid
+				result.Release = candidate.Title
+				return result
+			}
+		}
+	}
+
+	if s.usenet != nil && len(usenetCandidates) > 0 {
+		for _, check := range s.usenet.ParallelHealthCheck(ctx, usenetCandidates, maxUsenetChecks) {
+			if check.Healthy {
+				result.Ready = true
+				result.Source = models.AvailabilitySourceUsenet
+				result.Release = check.Candidate.Title
+				return result
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return s.failed(t, ctx.Err())
+	}
+	return result
+}
+
+func (s *Service) failed(t target, err error) models.WatchlistAvailability {
+	result := t.blank()
+	result.Error = err.Error()
+	result.CheckedAt = s.now().UTC()
+	return result
+}
+
+// searchOptions builds the search for a target. Series are checked by their
+// first episode, which is what starting the show would play, unless a
+// specific episode is being checked.
+func searchOptions(userID string, t target) indexer.SearchOptions {
+	item := t.item
+	name := strings.TrimSpace(item.Name)
+	opts := indexer.SearchOptions{
+		Query:      name,
+		MaxResults: maxSearchResults,
+		MediaType:  item.MediaType,
+		IMDBID:     strings.TrimSpace(item.ExternalIDs["imdb"]),
+		Year:       item.Year,
+		UserID:     userID,
+	}
+	if name != "" && item.MediaType == "series" {
+		season, episode := 1, 1
+		if t.episode != nil {
+			season, episode = t.episode.SeasonNumber, t.episode.EpisodeNumber
+		}
+		opts.Query = fmt.Sprintf("%s S%02dE%02d", name, season, episode)
+	}
+	return opts
+}
@@ -0,0 +1,157 @@
+package availability
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/debrid"
+	"novastream/services/indexer"
+	"novastream/services/playback"
+)
+
+type fakeSearcher struct {
+	calls   atomic.Int32
+	results []models.NZBResult
+	queries chan string
+}
+
+func (f *fakeSearcher) Search(_ context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error) {
+	f.calls.Add(1)
+	if f.queries != nil {
+		f.queries <- opts.Query
+	}
+	return f.results, nil
+}
+
+type fakeDebrid struct{ cached map[string]bool }
+
+func (f fakeDebrid) CheckHealthQuick(_ context.Context, candidate models.NZBResult) (*debrid.DebridHealthCheck, error) {
+	cached := f.cached[candidate.Title]
+	return &debrid.DebridHealthCheck{Healthy: cached, Cached: cached}, nil
+}
+
+type fakeUsenet struct{ healthy map[string]bool }
+
+func (f fakeUsenet) ParallelHealthCheck(_ context.Context, candidates []models.NZBResult, _ int) []playback.HealthCheckResult {
+	var results []playback.HealthCheckResult
+	for i, c := range candidates {
+		results = append(results, playback.HealthCheckResult{Index: i, Candidate: c, Healthy: f.healthy[c.Title]})
+	}
+	return results
+}
+
+// waitForResults polls Check until no item is pending.
+func waitForResults(t *testing.T, svc *Service, items []models.WatchlistItem) []models.WatchlistAvailability {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		results := svc.Check("user", items, false)
+		pending := false
+		for _, r := range results {
+			pending = pending || r.Pending
+		}
+		if !pending {
+			return results
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("checks still pending: %+v", results)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCheckReportsSourceAndCaches(t *testing.T) {
+	search := &fakeSearcher{results: []models.NZBResult{
+		{Title: "Uncached.Torrent", ServiceType: models.ServiceTypeDebrid},
+		{Title: "Broken.NZB", ServiceType: models.ServiceTypeUsenet},
+		{Title: "Complete.NZB", ServiceType: models.ServiceTypeUsenet},
+	}}
+	svc := NewService(search, fakeDebrid{}, fakeUsenet{healthy: map[string]bool{"Complete.NZB": true}})
+	items := []models.WatchlistItem{{ID: "tt1", MediaType: "movie", Name: "Movie"}}
+
+	first := svc.Check("user", items, false)
+	if len(first) != 1 || !first[0].Pending || first[0].ID != "tt1" {
+		t.Fatalf("first check = %+v, want pending tt1", first)
+	}
+
+	results := waitForResults(t, svc, items)
+	got := results[0]
+	if !got.Ready || got.Source != models.AvailabilitySourceUsenet || got.Release != "Complete.NZB" {
+		t.Fatalf("unexpected availability %+v", got)
+	}
+	if calls := search.calls.Load(); calls != 1 {
+		t.Fatalf("search ran %d times, want 1 (cached)", calls)
+	}
+
+	// Refresh keeps serving the previous result while the recheck runs
+	refreshed := svc.Check("user", items, true)
+	if !refreshed[0].Pending || !refreshed[0].Ready {
+		t.Fatalf("refresh = %+v, want pending with previous result", refreshed[0])
+	}
+	waitForResults(t, svc, items)
+	if calls := search.calls.Load(); calls != 2 {
+		t.Fatalf("search ran %d times after refresh, want 2", calls)
+	}
+}
+
+func TestCheckPrefersCachedDebridAndSearchesSeriesPilot(t *testing.T) {
+	search := &fakeSearcher{
+		results: []models.NZBResult{
+			{Title: "Show.S01E01.Torrent", ServiceType: models.ServiceTypeDebrid},
+			{Title: "Show.S01E01.NZB", ServiceType: models.ServiceTypeUsenet},
+		},
+		queries: make(chan string, 1),
+	}
+	svc := NewService(search, fakeDebrid{cached: map[string]bool{"Show.S01E01.Torrent": true}}, fakeUsenet{})
+	items := []models.WatchlistItem{{ID: "tvdb:1", MediaType: "series", Name: "Show"}}
+
+	svc.Check("user", items, false)
+	if query := <-search.queries; query != "Show S01E01" {
+		t.Fatalf("query = %q, want %q", query, "Show S01E01")
+	}
+	got := waitForResults(t, svc, items)[0]
+	if !got.Ready || got.Source != models.AvailabilitySourceDebrid {
+		t.Fatalf("unexpected availability %+v", got)
+	}
+}
+
+func TestCheckEpisodesSearchesEachEpisode(t *testing.T) {
+	search := &fakeSearcher{
+		results: []models.NZBResult{{Title: "Show.S02E03.NZB", ServiceType: models.ServiceTypeUsenet}},
+		queries: make(chan string, 1),
+	}
+	svc := NewService(search, nil, fakeUsenet{healthy: map[string]bool{"Show.S02E03.NZB": true}})
+	series := models.WatchlistItem{ID: "tvdb:1", MediaType: "series", Name: "Show"}
+	episodes := []Episode{{ID: "ep-23", SeasonNumber: 2, EpisodeNumber: 3}}
+
+	first := svc.CheckEpisodes("user", series, episodes, false)
+	if len(first) != 1 || !first[0].Pending || first[0].ID != "ep-23" || first[0].MediaType != "episode" {
+		t.Fatalf("first check = %+v, want pending episode ep-23", first)
+	}
+	if query := <-search.queries; query != "Show S02E03" {
+		t.Fatalf("query = %q, want %q", query, "Show S02E03")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := svc.CheckEpisodes("user", series, episodes, false)[0]
+		if !got.Pending {
+			if !got.Ready || got.Release != "Show.S02E03.NZB" {
+				t.Fatalf("unexpected availability %+v", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("episode check still pending: %+v", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The series itself is cached separately from its episodes
+	if got := svc.Check("user", []models.WatchlistItem{series}, false)[0]; !got.Pending {
+		t.Fatalf("series check = %+v, want pending", got)
+	}
+}
@@ -0,0 +1,518 @@
+// Package calendar_prefetch follows the Trakt "my shows" calendar of linked
+// accounts and resolves each new episode shortly after it airs, so it is
+// instantly playable by the time the profile sits down to watch it.
+package calendar_prefetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+	"novastream/services/trakt"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+const (
+	// The calendar is read from yesterday so episodes that aired late in the
+	// evening are still picked up after a restart
+	calendarLookback = 24 * time.Hour
+	calendarDays     = 3
+
+	// Releases usually show up on indexers within an hour of air time
+	prefetchDelay = 30 * time.Minute
+	retryInterval = time.Hour
+	giveUpAfter   = 24 * time.Hour
+
+	// Per-search timeout, matching the prequeue's
+	searchTimeout = 2 * time.Minute
+
+	// maxCandidates bounds how many releases are tried before waiting for the
+	// next attempt
+	maxCandidates = 3
+
+	// Entries are dropped this long after the episode aired
+	entryRetention = 7 * 24 * time.Hour
+)
+
+// CalendarClient reads a Trakt calendar. Implemented by *trakt.Client.
+type CalendarClient interface {
+	UpdateCredentials(clientID, clientSecret string)
+	RefreshAccessToken(refreshToken string) (*trakt.TokenResponse, error)
+	GetMyShowsCalendar(accessToken string, startDate time.Time, days int) ([]trakt.CalendarShow, error)
+}
+
+// ProfileLister finds the profiles linked to a Trakt account. Implemented by
+// *users.Service.
+type ProfileLister interface {
+	GetUsersByTraktAccountID(traktAccountID string) []models.User
+}
+
+// Searcher runs an indexer search. Implemented by *indexer.Service.
+type Searcher interface {
+	Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error)
+}
+
+// Resolver resolves a release into a playable stream. Implemented by
+// *playback.Service.
+type Resolver interface {
+	Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error)
+}
+
+// Warmer starts a full prequeue for an episode and returns its prequeue ID.
+// Implemented by *handlers.PrequeueHandler.
+type Warmer interface {
+	WarmEpisode(userID, titleID, titleName, imdbID string, year, season, episode int) string
+}
+
+// Service stores calendar prefetches in a JSON file on disk.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	cfg      *config.Manager
+	client   CalendarClient
+	profiles ProfileLister
+	searcher Searcher
+	resolver Resolver
+	warmer   Warmer
+	entries  map[string]*models.CalendarPrefetch
+	now      func() time.Time
+}
+
+// NewService constructs a calendar prefetch store in storageDir.
+func NewService(storageDir string, cfg *config.Manager, client CalendarClient, profiles ProfileLister, searcher Searcher, resolver Resolver) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create calendar prefetch dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "calendar_prefetch.json"),
+		cfg:      cfg,
+		client:   client,
+		profiles: profiles,
+		searcher: searcher,
+		resolver: resolver,
+		entries:  make(map[string]*models.CalendarPrefetch),
+		now:      time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// SetWarmer sets what runs a full prequeue for accounts with warmup enabled.
+// Without one, episodes are only resolved.
+func (s *Service) SetWarmer(warmer Warmer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warmer = warmer
+}
+
+// ListForUser returns a profile's prefetches, soonest air time first.
+func (s *Service) ListForUser(userID string) []models.CalendarPrefetch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.CalendarPrefetch, 0)
+	for _, entry := range s.entries {
+		if entry.UserID == userID {
+			list = append(list, *entry)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].AirsAt.Before(list[j].AirsAt) })
+	return list
+}
+
+// Run reads the calendars of accounts with prefetch enabled and prefetches
+// the episodes that are due. It only fails when no calendar could be read.
+func (s *Service) Run(ctx context.Context) error {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return fmt.Errorf("load settings: %w", err)
+	}
+
+	enabled := make(map[string]bool)
+	var syncErrs []error
+	for _, acct := range settings.Trakt.Accounts {
+		if !acct.CalendarPrefetchEnabled || acct.AccessToken == "" {
+			continue
+		}
+		enabled[acct.ID] = true
+		if err := s.syncAccount(acct); err != nil {
+			log.Printf("[calendar_prefetch] calendar sync failed for trakt account %s: %v", acct.ID, err)
+			syncErrs = append(syncErrs, err)
+		}
+	}
+
+	warmup := make(map[string]bool)
+	for _, acct := range settings.Trakt.Accounts {
+		warmup[acct.ID] = acct.CalendarPrefetchWarmup
+	}
+
+	for _, entry := range s.dueEntries(enabled) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.prefetch(ctx, entry, warmup[entry.TraktAccountID])
+	}
+
+	if err := s.prune(enabled); err != nil {
+		log.Printf("[calendar_prefetch] failed to persist prefetches: %v", err)
+	}
+
+	if len(syncErrs) > 0 && len(syncErrs) == len(enabled) {
+		return errors.Join(syncErrs...)
+	}
+	return nil
+}
+
+// syncAccount adds the episodes on an account's calendar for each profile
+// linked to it, and follows air time changes of episodes not yet prefetched.
+func (s *Service) syncAccount(acct config.TraktAccount) error {
+	profiles := s.profiles.GetUsersByTraktAccountID(acct.ID)
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	items, err := s.fetchCalendar(acct)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, item := range items {
+		titleID := seriesTitleID(item.Show.IDs)
+		if titleID == "" || item.FirstAired.IsZero() || item.Episode.Season <= 0 || item.Episode.Number <= 0 {
+			continue
+		}
+		for _, profile := range profiles {
+			id := entryID(profile.ID, titleID, item.Episode.Season, item.Episode.Number)
+			if existing, ok := s.entries[id]; ok {
+				if existing.Status == models.CalendarPrefetchPending && existing.Attempts == 0 && !existing.AirsAt.Equal(item.FirstAired) {
+					existing.AirsAt = item.FirstAired.UTC()
+					changed = true
+				}
+				continue
+			}
+			s.entries[id] = &models.CalendarPrefetch{
+				ID:             id,
+				UserID:         profile.ID,
+				TraktAccountID: acct.ID,
+				TitleID:        titleID,
+				ShowTitle:      item.Show.Title,
+				IMDBID:         item.Show.IDs.IMDB,
+				Year:           item.Show.Year,
+				SeasonNumber:   item.Episode.Season,
+				EpisodeNumber:  item.Episode.Number,
+				EpisodeTitle:   item.Episode.Title,
+				AirsAt:         item.FirstAired.UTC(),
+				Status:         models.CalendarPrefetchPending,
+			}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+// fetchCalendar reads an account's calendar, refreshing its token when it has
+// expired or Trakt rejects it.
+func (s *Service) fetchCalendar(acct config.TraktAccount) ([]trakt.CalendarShow, error) {
+	s.client.UpdateCredentials(acct.ClientID, acct.ClientSecret)
+
+	accessToken := acct.AccessToken
+	if acct.ExpiresAt > 0 && s.now().Unix() >= acct.ExpiresAt {
+		token, err := s.refreshToken(acct)
+		if err != nil {
+			return nil, fmt.Errorf("refresh token: %w", err)
+		}
+		accessToken = token
+	}
+
+	start := s.now().UTC().Add(-calendarLookback)
+	items, err := s.client.GetMyShowsCalendar(accessToken, start, calendarDays)
+	if !errors.Is(err, trakt.ErrUnauthorized) || acct.RefreshToken == "" {
+		return items, err
+	}
+
+	accessToken, refreshErr := s.refreshToken(acct)
+	if refreshErr != nil {
+		return nil, fmt.Errorf("%w (token refresh failed: %v)", err, refreshErr)
+	}
+	return s.client.GetMyShowsCalendar(accessToken, start, calendarDays)
+}
+
+// refreshToken exchanges an account's refresh token and saves the new tokens.
+func (s *Service) refreshToken(acct config.TraktAccount) (string, error) {
+	token, err := s.client.RefreshAccessToken(acct.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return "", err
+	}
+	if updated := settings.Trakt.GetAccountByID(acct.ID); updated != nil {
+		updated.AccessToken = token.AccessToken
+		updated.RefreshToken = token.RefreshToken
+		updated.ExpiresAt = token.CreatedAt + int64(token.ExpiresIn)
+		settings.Trakt.UpdateAccount(*updated)
+		if err := s.cfg.Save(settings); err != nil {
+			return "", err
+		}
+	}
+	return token.AccessToken, nil
+}
+
+// dueEntries returns copies of the pending entries of enabled accounts whose
+// episode aired at least prefetchDelay ago and that weren't tried within
+// retryInterval. Entries that waited too long are marked failed.
+func (s *Service) dueEntries(enabled map[string]bool) []models.CalendarPrefetch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	due := make([]models.CalendarPrefetch, 0)
+	changed := false
+	for _, entry := range s.entries {
+		if entry.Status != models.CalendarPrefetchPending || !enabled[entry.TraktAccountID] {
+			continue
+		}
+		if now.Before(entry.AirsAt.Add(prefetchDelay)) {
+			continue
+		}
+		if now.Sub(entry.AirsAt) > giveUpAfter {
+			entry.Status = models.CalendarPrefetchFailed
+			entry.CompletedAt = &now
+			changed = true
+			continue
+		}
+		if entry.LastAttemptAt != nil && now.Sub(*entry.LastAttemptAt) < retryInterval {
+			continue
+		}
+		due = append(due, *entry)
+	}
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			log.Printf("[calendar_prefetch] failed to persist prefetches: %v", err)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].AirsAt.Before(due[j].AirsAt) })
+	return due
+}
+
+// prefetch hands an episode to prequeue when warmup is enabled, and otherwise
+// searches for it and resolves the first release that works.
+func (s *Service) prefetch(ctx context.Context, entry models.CalendarPrefetch, warmup bool) {
+	s.mu.Lock()
+	warmer := s.warmer
+	s.mu.Unlock()
+
+	if warmup && warmer != nil {
+		prequeueID := warmer.WarmEpisode(entry.UserID, entry.TitleID, entry.ShowTitle, entry.IMDBID, entry.Year, entry.SeasonNumber, entry.EpisodeNumber)
+		log.Printf("[calendar_prefetch] warming up %s S%02dE%02d for user %s (prequeue %s)", entry.ShowTitle, entry.SeasonNumber, entry.EpisodeNumber, entry.UserID, prequeueID)
+		s.record(entry.ID, func(e *models.CalendarPrefetch, now time.Time) {
+			e.Status = models.CalendarPrefetchWarming
+			e.PrequeueID = prequeueID
+			e.CompletedAt = &now
+		})
+		return
+	}
+
+	releaseTitle, err := s.resolve(ctx, entry)
+	if err != nil {
+		log.Printf("[calendar_prefetch] %s S%02dE%02d for user %s not resolved yet: %v", entry.ShowTitle, entry.SeasonNumber, entry.EpisodeNumber, entry.UserID, err)
+		s.record(entry.ID, func(e *models.CalendarPrefetch, _ time.Time) {
+			e.LastError = err.Error()
+		})
+		return
+	}
+
+	log.Printf("[calendar_prefetch] resolved %s S%02dE%02d for user %s: %s", entry.ShowTitle, entry.SeasonNumber, entry.EpisodeNumber, entry.UserID, releaseTitle)
+	s.record(entry.ID, func(e *models.CalendarPrefetch, now time.Time) {
+		e.Status = models.CalendarPrefetchResolved
+		e.ReleaseTitle = releaseTitle
+		e.LastError = ""
+		e.CompletedAt = &now
+	})
+}
+
+// resolve searches for an episode and returns the title of the first of the
+// top releases that resolves.
+func (s *Service) resolve(ctx context.Context, entry models.CalendarPrefetch) (string, error) {
+	searchCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	results, err := s.searcher.Search(searchCtx, indexer.SearchOptions{
+		Query:      fmt.Sprintf("%s S%02dE%02d", entry.ShowTitle, entry.SeasonNumber, entry.EpisodeNumber),
+		MaxResults: 50,
+		MediaType:  "series",
+		IMDBID:     entry.IMDBID,
+		Year:       entry.Year,
+		UserID:     entry.UserID,
+	})
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("search: %w", err)
+	}
+	if len(results) == 0 {
+		return "", errors.New("no releases found yet")
+	}
+
+	var lastErr error
+	for i, result := range results {
+		if i == maxCandidates {
+			break
+		}
+		if _, err := s.resolver.Resolve(ctx, result); err != nil {
+			lastErr = err
+			continue
+		}
+		return result.Title, nil
+	}
+	return "", fmt.Errorf("resolve: %w", lastErr)
+}
+
+// record counts an attempt on an entry and applies update to it.
+func (s *Service) record(id string, update func(*models.CalendarPrefetch, time.Time)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return
+	}
+	now := s.now().UTC()
+	entry.Attempts++
+	entry.LastAttemptAt = &now
+	update(entry, now)
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[calendar_prefetch] failed to persist prefetches: %v", err)
+	}
+}
+
+// prune drops entries for episodes that aired over a week ago, and pending
+// entries of accounts that no longer have prefetch enabled.
+func (s *Service) prune(enabled map[string]bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	removed := 0
+	for id, entry := range s.entries {
+		stale := now.Sub(entry.AirsAt) > entryRetention
+		disabled := entry.Status == models.CalendarPrefetchPending && !enabled[entry.TraktAccountID]
+		if stale || disabled {
+			delete(s.entries, id)
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+// seriesTitleID builds the title ID the rest of the app uses for a show,
+// preferring TVDB like metadata does.
+func seriesTitleID(ids trakt.IDs) string {
+	switch {
+	case ids.TVDB > 0:
+		return fmt.Sprintf("tvdb:series:%d", ids.TVDB)
+	case ids.TMDB > 0:
+		return fmt.Sprintf("tmdb:tv:%d", ids.TMDB)
+	default:
+		return ""
+	}
+}
+
+func entryID(userID, titleID string, season, episode int) string {
+	return fmt.Sprintf("%s:%s:S%02dE%02d", userID, titleID, season, episode)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open calendar prefetches: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read calendar prefetches: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var entries []models.CalendarPrefetch
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("decode calendar prefetches: %w", err)
+	}
+	for i := range entries {
+		entry := entries[i]
+		if entry.ID == "" {
+			continue
+		}
+		s.entries[entry.ID] = &entry
+	}
+	return nil
+}
+
+// saveLocked writes the prefetches to disk, by air time.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]models.CalendarPrefetch, 0, len(s.entries))
+	for _, entry := range s.entries {
+		list = append(list, *entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].AirsAt.Equal(list[j].AirsAt) {
+			return list[i].AirsAt.Before(list[j].AirsAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode calendar prefetches: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write calendar prefetches: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package calendar_prefetch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+	"novastream/services/trakt"
+)
+
+type fakeCalendar struct {
+	items     []trakt.CalendarShow
+	rejectAll bool
+	tokens    []string
+	refreshed int
+}
+
+func (f *fakeCalendar) UpdateCredentials(clientID, clientSecret string) {}
+
+func (f *fakeCalendar) RefreshAccessToken(refreshToken string) (*trakt.TokenResponse, error) {
+	f.refreshed++
+	return &trakt.TokenResponse{AccessToken: "fresh", RefreshToken: "refresh-2", ExpiresIn: 3600, CreatedAt: time.Now().Unix()}, nil
+}
+
+func (f *fakeCalendar) GetMyShowsCalendar(accessToken string, startDate time.Time, days int) ([]trakt.CalendarShow, error) {
+	f.tokens = append(f.tokens, accessToken)
+	if f.rejectAll || accessToken == "stale" {
+		return nil, trakt.ErrUnauthorized
+	}
+	return f.items, nil
+}
+
+type fakeProfiles map[string][]models.User
+
+func (f fakeProfiles) GetUsersByTraktAccountID(id string) []models.User { return f[id] }
+
+type fakeSearcher struct {
+	results []models.NZBResult
+	calls   []indexer.SearchOptions
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error) {
+	f.calls = append(f.calls, opts)
+	return f.results, nil
+}
+
+type fakeResolver struct {
+	failing map[string]bool
+}
+
+func (f *fakeResolver) Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error) {
+	if f.failing[candidate.Title] {
+		return nil, errors.New("missing articles")
+	}
+	return &models.PlaybackResolution{}, nil
+}
+
+type fakeWarmer struct {
+	warmed []string
+}
+
+func (f *fakeWarmer) WarmEpisode(userID, titleID, titleName, imdbID string, year, season, episode int) string {
+	f.warmed = append(f.warmed, titleID)
+	return "pq-1"
+}
+
+func newTestService(t *testing.T, accounts []config.TraktAccount, client *fakeCalendar, searcher *fakeSearcher, resolver *fakeResolver, now *time.Time) (*Service, *config.Manager) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.NewManager(filepath.Join(dir, "settings.json"))
+	settings := config.DefaultSettings()
+	settings.Trakt.Accounts = accounts
+	if err := cfg.Save(settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	profiles := fakeProfiles{"acct": {{ID: "u1"}, {ID: "u2"}}}
+	svc, err := NewService(dir, cfg, client, profiles, searcher, resolver)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.now = func() time.Time { return *now }
+	return svc, cfg
+}
+
+func calendarItem(airsAt time.Time) trakt.CalendarShow {
+	return trakt.CalendarShow{
+		FirstAired: airsAt,
+		Episode:    trakt.Episode{Season: 2, Number: 5, Title: "Finale"},
+		Show:       trakt.Show{Title: "Show", Year: 2024, IDs: trakt.IDs{TVDB: 42, IMDB: "tt42"}},
+	}
+}
+
+func TestRunResolvesEpisodesAfterAirTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	airsAt := now.Add(time.Hour)
+	client := &fakeCalendar{items: []trakt.CalendarShow{calendarItem(airsAt)}}
+	searcher := &fakeSearcher{}
+	resolver := &fakeResolver{failing: map[string]bool{"Show.S02E05.2160p-BAD": true}}
+	svc, _ := newTestService(t, []config.TraktAccount{
+		{ID: "acct", AccessToken: "token", CalendarPrefetchEnabled: true},
+		{ID: "off", AccessToken: "token"},
+	}, client, searcher, resolver, &now)
+
+	// Episodes are tracked for every linked profile but not searched before air time
+	if err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(client.tokens) != 1 || len(searcher.calls) != 0 {
+		t.Fatalf("calendar reads %d, searches %d; want 1 and 0", len(client.tokens), len(searcher.calls))
+	}
+	list := svc.ListForUser("u1")
+	if len(list) != 1 || list[0].TitleID != "tvdb:series:42" || list[0].Status != models.CalendarPrefetchPending {
+		t.Fatalf("unexpected prefetches: %+v", list)
+	}
+
+	// Nothing on the indexers yet: retried after retryInterval
+	now = airsAt.Add(prefetchDelay)
+	svc.Run(context.Background())
+	if got := svc.ListForUser("u1")[0]; got.Status != models.CalendarPrefetchPending || got.Attempts != 1 || got.LastError == "" {
+		t.Fatalf("unexpected prefetch after empty search: %+v", got)
+	}
+	if opts := searcher.calls[0]; opts.Query != "Show S02E05" || opts.UserID != "u1" || opts.IMDBID != "tt42" {
+		t.Errorf("unexpected search: %+v", opts)
+	}
+
+	searcher.results = []models.NZBResult{{Title: "Show.S02E05.2160p-BAD"}, {Title: "Show.S02E05.1080p-GOOD"}}
+	now = now.Add(30 * time.Minute)
+	svc.Run(context.Background())
+	if len(searcher.calls) != 2 {
+		t.Fatalf("retried before retryInterval: %d searches", len(searcher.calls))
+	}
+	now = now.Add(retryInterval)
+	svc.Run(context.Background())
+	got := svc.ListForUser("u2")[0]
+	if got.Status != models.CalendarPrefetchResolved || got.ReleaseTitle != "Show.S02E05.1080p-GOOD" || got.CompletedAt == nil {
+		t.Fatalf("unexpected resolved prefetch: %+v", got)
+	}
+}
+
+func TestRunWarmsUpAndGivesUp(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	client := &fakeCalendar{items: []trakt.CalendarShow{calendarItem(now.Add(-2 * time.Hour))}}
+	searcher := &fakeSearcher{}
+	svc, _ := newTestService(t, []config.TraktAccount{
+		{ID: "acct", AccessToken: "token", CalendarPrefetchEnabled: true, CalendarPrefetchWarmup: true},
+	}, client, searcher, &fakeResolver{}, &now)
+	warmer := &fakeWarmer{}
+	svc.SetWarmer(warmer)
+
+	svc.Run(context.Background())
+	got := svc.ListForUser("u1")[0]
+	if got.Status != models.CalendarPrefetchWarming || got.PrequeueID != "pq-1" || len(warmer.warmed) != 2 || len(searcher.calls) != 0 {
+		t.Fatalf("unexpected warmup: %+v (warmed %d, searched %d)", got, len(warmer.warmed), len(searcher.calls))
+	}
+
+	// Without a warmer, searches that never find anything give up after a day
+	svc.SetWarmer(nil)
+	client.items = []trakt.CalendarShow{calendarItem(now.Add(-2 * time.Hour))}
+	client.items[0].Episode.Number = 6
+	svc.Run(context.Background())
+	now = now.Add(giveUpAfter)
+	svc.Run(context.Background())
+	for _, entry := range svc.ListForUser("u1") {
+		if entry.EpisodeNumber == 6 && entry.Status != models.CalendarPrefetchFailed {
+			t.Fatalf("expected the unresolved episode to fail, got %+v", entry)
+		}
+	}
+
+	// A week after airing, entries are dropped
+	now = now.Add(entryRetention)
+	client.items = nil
+	svc.Run(context.Background())
+	if list := svc.ListForUser("u1"); len(list) != 0 {
+		t.Fatalf("old prefetches kept: %+v", list)
+	}
+}
+
+func TestRunRefreshesRejectedToken(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	client := &fakeCalendar{items: []trakt.CalendarShow{calendarItem(now.Add(time.Hour))}}
+	svc, cfg := newTestService(t, []config.TraktAccount{
+		{ID: "acct", AccessToken: "stale", RefreshToken: "refresh", CalendarPrefetchEnabled: true},
+	}, client, &fakeSearcher{}, &fakeResolver{}, &now)
+
+	if err := svc.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if client.refreshed != 1 || len(client.tokens) != 2 || client.tokens[1] != "fresh" {
+		t.Fatalf("token not refreshed and retried: refreshed %d, tokens %v", client.refreshed, client.tokens)
+	}
+	settings, _ := cfg.Load()
+	if acct := settings.Trakt.GetAccountByID("acct"); acct == nil || acct.AccessToken != "fresh" || acct.RefreshToken != "refresh-2" {
+		t.Fatalf("refreshed token not saved: %+v", acct)
+	}
+
+	client.rejectAll = true
+	if err := svc.Run(context.Background()); !errors.Is(err, trakt.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized when every calendar fails, got %v", err)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"os/exec"
 	"path"
 	"strings"
 	"sync"
@@ -19,6 +18,8 @@ import (
 	"novastream/internal/mediaresolve"
 	"novastream/models"
 	"novastream/utils"
+	"novastream/utils/accessibility"
+	"novastream/utils/sandbox"
 )
 
 // trackCacheEntry stores cached track probe results
@@ -76,6 +77,8 @@ type AudioTrackInfo struct {
 	Language string `json:"language"`
 	Codec    string `json:"codec"`
 	Title    string `json:"title,omitempty"`
+	// AudioDescription marks audio-description (AD) tracks for the visually impaired
+	AudioDescription bool `json:"audioDescription,omitempty"`
 }
 
 // SubtitleTrackInfo contains metadata for a subtitle track.
@@ -87,6 +90,7 @@ type SubtitleTrackInfo struct {
 	Forced     bool   `json:"forced"`
 	IsBitmap   bool   `json:"isBitmap"`
 	BitmapType string `json:"bitmapType,omitempty"`
+	SDH        bool   `json:"sdh,omitempty"` // Subtitles for the deaf and hard of hearing
 }
 
 // CheckHealth verifies if a debrid result is healthy (cached and available).
@@ -614,11 +618,11 @@ var mediaExtensionPriority = map[string]int{
 }
 
 type mediaFileSelection struct {
-	OrderedIDs       []string
-	PreferredID      string
-	PreferredLabel   string
-	PreferredReason  string
-	RejectionReason  string // Set when selection is rejected (e.g., target episode not found)
+	OrderedIDs      []string
+	PreferredID     string
+	PreferredLabel  string
+	PreferredReason string
+	RejectionReason string // Set when selection is rejected (e.g., target episode not found)
 }
 
 func (s *mediaFileSelection) promotePreferredToFront() {
@@ -884,7 +888,7 @@ func (s *HealthService) probeAudioStreamCount(ctx context.Context, streamURL str
 		streamURL,
 	}
 
-	cmd := exec.CommandContext(probeCtx, s.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, s.ffprobePath, args...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -937,11 +941,11 @@ func (s *HealthService) probeAllTracks(ctx context.Context, streamURL string) (*
 		"-print_format", "json",
 		"-show_streams",
 		"-analyzeduration", "10000000", // 10 seconds
-		"-probesize", "10000000",       // 10MB
+		"-probesize", "10000000", // 10MB
 		streamURL,
 	}
 
-	cmd := exec.CommandContext(probeCtx, s.ffprobePath, args...)
+	cmd := sandbox.CommandContext(probeCtx, sandbox.ToolFFprobe, s.ffprobePath, args...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -979,10 +983,11 @@ func (s *HealthService) probeAllTracks(ctx context.Context, streamURL string) (*
 				title = stream.Tags["title"]
 			}
 			probeResult.AudioTracks = append(probeResult.AudioTracks, AudioTrackInfo{
-				Index:    stream.Index,
-				Language: lang,
-				Codec:    codec,
-				Title:    title,
+				Index:            stream.Index,
+				Language:         lang,
+				Codec:            codec,
+				Title:            title,
+				AudioDescription: accessibility.IsAudioDescription(stream.Disposition, title),
 			})
 
 		case "subtitle":
@@ -1013,6 +1018,7 @@ func (s *HealthService) probeAllTracks(ctx context.Context, streamURL string) (*
 				Forced:     isForced,
 				IsBitmap:   isBitmap,
 				BitmapType: bitmapType,
+				SDH:        accessibility.IsSDH(stream.Disposition, title),
 			})
 		}
 	}
@@ -23,6 +23,7 @@ type ProxyRequest struct {
 type ProxyService struct {
 	cfg        *config.Manager
 	httpClient *http.Client
+	usage      UsageTracker
 }
 
 // NewProxyService constructs a new proxy service with a default HTTP client.
@@ -33,6 +34,11 @@ func NewProxyService(cfg *config.Manager) *ProxyService {
 	}
 }
 
+// SetUsageTracker enables per-account traffic accounting and quota enforcement.
+func (s *ProxyService) SetUsageTracker(usage UsageTracker) {
+	s.usage = usage
+}
+
 // Proxy performs a basic authenticated passthrough to a debrid provider.
 // This is a minimal implementation intended to be expanded with provider-specific logic.
 func (s *ProxyService) Proxy(ctx context.Context, req ProxyRequest) (*streaming.Response, error) {
@@ -53,6 +59,10 @@ func (s *ProxyService) Proxy(ctx context.Context, req ProxyRequest) (*streaming.
 	if err != nil {
 		return nil, err
 	}
+	account := providerConfig.AccountName()
+	if s.usage != nil && s.usage.DebridQuotaExceeded(account) {
+		return nil, fmt.Errorf("debrid account %q has exceeded its monthly quota", account)
+	}
 
 	method := strings.ToUpper(strings.TrimSpace(req.Method))
 	if method == "" {
@@ -92,6 +102,9 @@ func (s *ProxyService) Proxy(ctx context.Context, req ProxyRequest) (*streaming.
 	if response.Headers == nil {
 		response.Headers = http.Header{}
 	}
+	if s.usage != nil {
+		response.Body = &usageCountingBody{ReadCloser: resp.Body, usage: s.usage, account: account}
+	}
 
 	return response, nil
 }
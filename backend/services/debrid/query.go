@@ -1,9 +1,9 @@
 package debrid
 
 import (
-	"regexp"
-	"strconv"
 	"strings"
+
+	"novastream/utils/releasename"
 )
 
 // MediaType represents the content family inferred from a search query.
@@ -16,10 +16,7 @@ const (
 )
 
 var (
-	reSeasonEpisode      = regexp.MustCompile(`(?i)\bS?(\d{1,2})[xE](\d{1,2})\b`)
-	reSeasonEpisodeWords = regexp.MustCompile(`(?i)\bseason\s+(\d{1,2})\s*(?:episode|ep)\s*(\d{1,2})\b`)
-	reYear               = regexp.MustCompile(`\b(19|20)\d{2}\b`)
-	stopTokens           = map[string]struct{}{
+	stopTokens = map[string]struct{}{
 		"1080p":  {},
 		"2160p":  {},
 		"720p":   {},
@@ -80,44 +77,19 @@ func ParseQuery(raw string) ParsedQuery {
 		return parsed
 	}
 
-	lower := strings.ToLower(candidate)
-
-	if match := reSeasonEpisode.FindStringSubmatch(lower); len(match) == 3 {
-		if season, err := strconv.Atoi(match[1]); err == nil {
-			parsed.Season = season
-		}
-		if episode, err := strconv.Atoi(match[2]); err == nil {
-			parsed.Episode = episode
-		}
-		if parsed.Season > 0 && parsed.Episode > 0 {
-			parsed.MediaType = MediaTypeSeries
-			parsed.HasSeasonMatch = true
-			candidate = removeSubstring(candidate, match[0])
-		}
-	} else if match := reSeasonEpisodeWords.FindStringSubmatch(lower); len(match) == 3 {
-		if season, err := strconv.Atoi(match[1]); err == nil {
-			parsed.Season = season
-		}
-		if episode, err := strconv.Atoi(match[2]); err == nil {
-			parsed.Episode = episode
-		}
-		if parsed.Season > 0 && parsed.Episode > 0 {
-			parsed.MediaType = MediaTypeSeries
-			parsed.HasSeasonMatch = true
-			candidate = removeSubstring(candidate, match[0])
-		}
-	}
-
-	if match := reYear.FindString(candidate); match != "" {
-		if yr, err := strconv.Atoi(match); err == nil && yr > 1900 && yr < 2100 {
-			parsed.Year = yr
-			candidate = removeSubstring(candidate, match)
-		}
+	release := releasename.Parse(candidate)
+	if len(release.Seasons) > 0 && len(release.Episodes) > 0 && release.Seasons[0] > 0 && release.Episodes[0] > 0 {
+		parsed.Season = release.Seasons[0]
+		parsed.Episode = release.Episodes[0]
+		parsed.MediaType = MediaTypeSeries
+		parsed.HasSeasonMatch = true
 	}
+	parsed.Year = release.Year
 
 	tokens := strings.Fields(candidate)
-	filtered := make([]string, 0, len(tokens))
-	for _, token := range tokens {
+	titleTokens := strings.Fields(release.Title)
+	filtered := make([]string, 0, len(titleTokens))
+	for _, token := range titleTokens {
 		normalized := strings.Trim(token, "-_.()[]{}")
 		key := strings.ToLower(normalized)
 		if key == "" {
@@ -145,15 +117,6 @@ func ParseQuery(raw string) ParsedQuery {
 	return parsed
 }
 
-func removeSubstring(input, toRemove string) string {
-	index := strings.Index(strings.ToLower(input), strings.ToLower(toRemove))
-	if index == -1 {
-		return input
-	}
-	removed := input[:index] + " " + input[index+len(toRemove):]
-	return strings.Join(strings.Fields(removed), " ")
-}
-
 func inferMediaTypeFromTokens(tokens []string) MediaType {
 	for _, token := range tokens {
 		key := strings.ToLower(strings.Trim(token, "-_.()[]{}"))
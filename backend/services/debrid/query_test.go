@@ -39,6 +39,15 @@ func TestParseQuery(t *testing.T) {
 			wantEpisode: 5,
 			wantType:    MediaTypeSeries,
 		},
+		{
+			name:        "series with cross format episode",
+			query:       "Show Name 1x05",
+			wantTitle:   "Show Name",
+			wantYear:    0,
+			wantSeason:  1,
+			wantEpisode: 5,
+			wantType:    MediaTypeSeries,
+		},
 		{
 			name:        "fallback retains tokens",
 			query:       "Dune Part Two",
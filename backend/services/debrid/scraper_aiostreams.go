@@ -14,6 +14,7 @@ import (
 
 	"novastream/internal/mediaresolve"
 	"novastream/models"
+	"novastream/utils/releasename"
 )
 
 // AIOStreamsScraper queries AIOStreams for pre-resolved debrid streams.
@@ -384,19 +385,7 @@ func parseAIODescription(desc string) parsedAIODescription {
 }
 
 func detectAIOResolution(name, bingeGroup string) string {
-	combined := strings.ToLower(name + " " + bingeGroup)
-	switch {
-	case strings.Contains(combined, "2160p") || strings.Contains(combined, "4k"):
-		return "2160p"
-	case strings.Contains(combined, "1080p"):
-		return "1080p"
-	case strings.Contains(combined, "720p"):
-		return "720p"
-	case strings.Contains(combined, "480p"):
-		return "480p"
-	default:
-		return ""
-	}
+	return releasename.Parse(name + " " + bingeGroup).ResolutionLabel()
 }
 
 func extractTitleFromDescription(desc string) string {
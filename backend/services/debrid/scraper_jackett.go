@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"novastream/models"
+	"novastream/utils/releasename"
 )
 
 // JackettScraper queries Jackett's Torznab API for torrent releases.
@@ -362,21 +363,13 @@ func buildMagnetFromHash(hash, title string) string {
 }
 
 // extractResolution parses resolution from a release title.
+// Jackett results label 2160p releases as "4K".
 func extractResolution(title string) string {
-	title = strings.ToLower(title)
-
-	switch {
-	case strings.Contains(title, "2160p") || strings.Contains(title, "4k") || strings.Contains(title, "uhd"):
+	res := releasename.Parse(title).Resolution
+	if res == 2160 {
 		return "4K"
-	case strings.Contains(title, "1080p") || strings.Contains(title, "1080i"):
-		return "1080p"
-	case strings.Contains(title, "720p"):
-		return "720p"
-	case strings.Contains(title, "480p") || strings.Contains(title, "sd"):
-		return "480p"
-	default:
-		return ""
 	}
+	return releasename.ResolutionLabel(res)
 }
 
 // TestConnection tests the Jackett connection by fetching capabilities.
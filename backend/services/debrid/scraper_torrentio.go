@@ -17,6 +17,7 @@ import (
 
 	"novastream/internal/mediaresolve"
 	"novastream/models"
+	"novastream/utils/releasename"
 )
 
 const (
@@ -680,19 +681,7 @@ func parseLanguages(raw string) []string {
 }
 
 func detectResolution(name, raw string) string {
-	release := strings.ToLower(name + " " + raw)
-	switch {
-	case strings.Contains(release, "2160p") || strings.Contains(release, "4k"):
-		return "2160p"
-	case strings.Contains(release, "1080p"):
-		return "1080p"
-	case strings.Contains(release, "720p"):
-		return "720p"
-	case strings.Contains(release, "480p"):
-		return "480p"
-	default:
-		return ""
-	}
+	return releasename.Parse(name + " " + raw).ResolutionLabel()
 }
 
 func parseTrackers(hints map[string]interface{}) []string {
@@ -8,7 +8,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,6 +15,7 @@ import (
 
 	"novastream/internal/mediaresolve"
 	"novastream/models"
+	"novastream/utils/releasename"
 )
 
 const (
@@ -424,31 +424,10 @@ func (z *ZileanScraper) parseResponse(body []byte) ([]ScrapeResult, error) {
 
 // normalizeResolution converts various resolution formats to standard format
 func normalizeResolution(res string) string {
-	if res == "" {
-		return ""
-	}
-
-	res = strings.ToLower(strings.TrimSpace(res))
-
-	switch {
-	case strings.Contains(res, "2160") || strings.Contains(res, "4k") || strings.Contains(res, "uhd"):
-		return "2160p"
-	case strings.Contains(res, "1080"):
-		return "1080p"
-	case strings.Contains(res, "720"):
-		return "720p"
-	case strings.Contains(res, "480") || strings.Contains(res, "sd"):
-		return "480p"
-	default:
-		// Return as-is if it looks like a resolution format
-		if regexp.MustCompile(`^\d{3,4}p?$`).MatchString(res) {
-			if !strings.HasSuffix(res, "p") {
-				return res + "p"
-			}
-			return res
-		}
-		return res
+	if n := releasename.ParseResolution(res); n > 0 {
+		return releasename.ResolutionLabel(n)
 	}
+	return strings.ToLower(strings.TrimSpace(res))
 }
 
 // TestConnection tests the Zilean connection by making a simple query.
@@ -13,6 +13,7 @@ import (
 	"novastream/config"
 	"novastream/models"
 	"novastream/utils/filter"
+	"novastream/utils/inflight"
 )
 
 // userSettingsProvider retrieves per-user settings.
@@ -56,6 +57,7 @@ type SearchService struct {
 	userSettings   userSettingsProvider
 	clientSettings clientSettingsProvider
 	imdbResolver   imdbResolver
+	inflight       inflight.Group[scrapeOutcome]
 }
 
 // NewSearchService constructs a new debrid search service.
@@ -327,6 +329,85 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 	log.Printf("[debrid] Using metadata: Title=%q, Season=%d, Episode=%d, Year=%d, MediaType=%s, IMDBID=%s",
 		parsed.Title, parsed.Season, parsed.Episode, parsed.Year, parsed.MediaType, imdbID)
 
+	// Identical searches from several profiles share one scraper run; per-user
+	// filtering below is applied to each caller's own copy of the results
+	useAccurateMode := settings.Streaming.SearchMode == config.SearchModeAccurate
+	outcome, shared, err := s.inflight.Do(ctx, scrapeKey(req, opts, useAccurateMode), func(runCtx context.Context) (scrapeOutcome, error) {
+		return s.runScrapers(runCtx, req, opts, useAccurateMode), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		log.Printf("[debrid] joined in-flight search for %q (%d results)", parsed.Title, len(outcome.results))
+	}
+	aggregate := models.CloneNZBResults(outcome.results)
+	errs := outcome.errs
+
+	if len(aggregate) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Check if filtering should be bypassed for AIOStreams-only mode
+	bypassFiltering := settings.Filtering.BypassFilteringForAIOStreamsOnly && isOnlyAIOStreamsEnabled(settings.TorrentScrapers)
+	if bypassFiltering {
+		log.Printf("[debrid] Bypassing strmr filtering - AIOStreams is the only enabled scraper and bypass setting is enabled")
+	}
+
+	// Apply parsed-based filtering if appropriate (using per-user filter settings)
+	if !bypassFiltering && ShouldFilter(parsed) {
+		hasResolver := opts.EpisodeResolver != nil
+		log.Printf("[debrid] Applying filter with title=%q, year=%d, mediaType=%s, hasEpisodeResolver=%v, targetS%02dE%02d, absoluteEp=%d",
+			parsed.Title, parsed.Year, parsed.MediaType, hasResolver, parsed.Season, parsed.Episode, opts.AbsoluteEpisodeNumber)
+		filterOpts := FilterOptions{
+			ExpectedTitle:         parsed.Title,
+			ExpectedYear:          parsed.Year,
+			MediaType:             parsed.MediaType,
+			MaxSizeMovieGB:        models.FloatVal(filterSettings.MaxSizeMovieGB, 0),
+			MaxSizeEpisodeGB:      models.FloatVal(filterSettings.MaxSizeEpisodeGB, 0),
+			MaxResolution:         filterSettings.MaxResolution,
+			HDRDVPolicy:           filter.HDRDVPolicy(filterSettings.HDRDVPolicy),
+			PrioritizeHdr:         models.BoolVal(filterSettings.PrioritizeHdr, false),
+			AlternateTitles:       opts.AlternateTitles,
+			FilterOutTerms:        filterSettings.FilterOutTerms,
+			TotalSeriesEpisodes:   opts.TotalSeriesEpisodes,
+			EpisodeResolver:       opts.EpisodeResolver,
+			TargetSeason:          parsed.Season,
+			TargetEpisode:         parsed.Episode,
+			TargetAbsoluteEpisode: opts.AbsoluteEpisodeNumber,
+			IsDaily:               opts.IsDaily,
+			TargetAirDate:         opts.TargetAirDate,
+		}
+		aggregate = FilterResults(aggregate, filterOpts)
+	}
+
+	// Apply MaxResults limit after filtering
+	if opts.MaxResults > 0 && len(aggregate) > opts.MaxResults {
+		aggregate = aggregate[:opts.MaxResults]
+	}
+
+	return aggregate, nil
+}
+
+// scrapeOutcome is what one scraper run produced, before per-user filtering.
+type scrapeOutcome struct {
+	results []models.NZBResult
+	errs    []error
+}
+
+// scrapeKey identifies scraper runs that produce the same results, so
+// concurrent identical searches can share one run.
+func scrapeKey(req SearchRequest, opts SearchOptions, accurate bool) string {
+	query := strings.Join(strings.Fields(strings.ToLower(req.Query)), " ")
+	return fmt.Sprintf("%s|%s|%s|%d|%s|%q|%d|%d|%d|%d|%t|%s|%t|%t",
+		query, strings.Join(req.Categories, ","), strings.ToLower(req.IMDBID), req.MaxResults,
+		req.Parsed.MediaType, strings.ToLower(req.Parsed.Title), req.Parsed.Year, req.Parsed.Season, req.Parsed.Episode,
+		opts.AbsoluteEpisodeNumber, req.IsDaily, req.TargetAirDate, opts.IsAnime, accurate)
+}
+
+// runScrapers queries every scraper in parallel and merges their results.
+// In fast mode it returns early once fast scrapers have produced enough.
+func (s *SearchService) runScrapers(ctx context.Context, req SearchRequest, opts SearchOptions, useAccurateMode bool) scrapeOutcome {
 	// scraperResult holds results from a single scraper
 	type scraperResult struct {
 		name    string
@@ -388,7 +469,7 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 			errs = append(errs, fmt.Errorf("%s scraper: %w", sr.name, sr.err))
 			return
 		}
-		log.Printf("[debrid] %s search produced %d results for %q in %s", sr.name, len(sr.results), parsed.Title, sr.elapsed.Round(10*time.Millisecond))
+		log.Printf("[debrid] %s search produced %d results for %q in %s", sr.name, len(sr.results), req.Parsed.Title, sr.elapsed.Round(10*time.Millisecond))
 		for _, res := range sr.results {
 			nzb := normalizeScrapeResult(res)
 			decorateResultWithParsedMetadata(&nzb, req.Parsed)
@@ -413,9 +494,6 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 		}
 	}
 
-	// Determine if we should wait for all results (accurate mode) or use early return (fast mode)
-	useAccurateMode := settings.Streaming.SearchMode == config.SearchModeAccurate
-
 	if useAccurateMode || opts.IsAnime {
 		// Accurate mode or Anime: wait for all scrapers
 		if useAccurateMode {
@@ -479,49 +557,7 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 		}
 	}
 
-	if len(aggregate) == 0 && len(errs) > 0 {
-		return nil, errors.Join(errs...)
-	}
-
-	// Check if filtering should be bypassed for AIOStreams-only mode
-	bypassFiltering := settings.Filtering.BypassFilteringForAIOStreamsOnly && isOnlyAIOStreamsEnabled(settings.TorrentScrapers)
-	if bypassFiltering {
-		log.Printf("[debrid] Bypassing strmr filtering - AIOStreams is the only enabled scraper and bypass setting is enabled")
-	}
-
-	// Apply parsed-based filtering if appropriate (using per-user filter settings)
-	if !bypassFiltering && ShouldFilter(parsed) {
-		hasResolver := opts.EpisodeResolver != nil
-		log.Printf("[debrid] Applying filter with title=%q, year=%d, mediaType=%s, hasEpisodeResolver=%v, targetS%02dE%02d, absoluteEp=%d",
-			parsed.Title, parsed.Year, parsed.MediaType, hasResolver, parsed.Season, parsed.Episode, opts.AbsoluteEpisodeNumber)
-		filterOpts := FilterOptions{
-			ExpectedTitle:         parsed.Title,
-			ExpectedYear:          parsed.Year,
-			MediaType:             parsed.MediaType,
-			MaxSizeMovieGB:        models.FloatVal(filterSettings.MaxSizeMovieGB, 0),
-			MaxSizeEpisodeGB:      models.FloatVal(filterSettings.MaxSizeEpisodeGB, 0),
-			MaxResolution:         filterSettings.MaxResolution,
-			HDRDVPolicy:           filter.HDRDVPolicy(filterSettings.HDRDVPolicy),
-			PrioritizeHdr:         models.BoolVal(filterSettings.PrioritizeHdr, false),
-			AlternateTitles:       opts.AlternateTitles,
-			FilterOutTerms:        filterSettings.FilterOutTerms,
-			TotalSeriesEpisodes:   opts.TotalSeriesEpisodes,
-			EpisodeResolver:       opts.EpisodeResolver,
-			TargetSeason:          parsed.Season,
-			TargetEpisode:         parsed.Episode,
-			TargetAbsoluteEpisode: opts.AbsoluteEpisodeNumber,
-			IsDaily:               opts.IsDaily,
-			TargetAirDate:         opts.TargetAirDate,
-		}
-		aggregate = FilterResults(aggregate, filterOpts)
-	}
-
-	// Apply MaxResults limit after filtering
-	if opts.MaxResults > 0 && len(aggregate) > opts.MaxResults {
-		aggregate = aggregate[:opts.MaxResults]
-	}
-
-	return aggregate, nil
+	return scrapeOutcome{results: aggregate, errs: errs}
 }
 
 func hasActiveDebridProviders(providers []config.DebridProviderSettings) bool {
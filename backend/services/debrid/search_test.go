@@ -175,3 +175,19 @@ func TestEarlyReturnThresholds(t *testing.T) {
 	t.Logf("  - SearchMode is 'accurate'")
 	t.Logf("  - Content is anime (needs Nyaa results)")
 }
+
+func TestScrapeKeyCoalescesEquivalentQueries(t *testing.T) {
+	base := SearchRequest{Query: "Breaking Bad S01E01", Parsed: ParseQuery("Breaking Bad S01E01")}
+	same := SearchRequest{Query: "  breaking   bad s01e01 ", Parsed: ParseQuery("breaking bad s01e01")}
+	other := SearchRequest{Query: "Breaking Bad S01E02", Parsed: ParseQuery("Breaking Bad S01E02")}
+
+	if scrapeKey(base, SearchOptions{}, false) != scrapeKey(same, SearchOptions{}, false) {
+		t.Error("queries differing only in case and spacing should share a scraper run")
+	}
+	if scrapeKey(base, SearchOptions{}, false) == scrapeKey(other, SearchOptions{}, false) {
+		t.Error("different episodes must not share a scraper run")
+	}
+	if scrapeKey(base, SearchOptions{}, false) == scrapeKey(base, SearchOptions{}, true) {
+		t.Error("fast and accurate searches must not share a scraper run")
+	}
+}
@@ -21,12 +21,20 @@ type cachedURL struct {
 	expiresAt time.Time
 }
 
+// UsageTracker records traffic per debrid account and enforces monthly quotas.
+// Implemented by *provider_usage.Service.
+type UsageTracker interface {
+	RecordDebrid(account string, bytes int64)
+	DebridQuotaExceeded(account string) bool
+}
+
 // StreamingProvider implements streaming.Provider for debrid content.
 type StreamingProvider struct {
 	cfg      *config.Manager
 	urlCache map[string]cachedURL
 	cacheMux sync.RWMutex
 	cacheTTL time.Duration
+	usage    UsageTracker
 }
 
 func parseDebridPath(path string) (provider, torrentID, fileID string, err error) {
@@ -74,6 +82,19 @@ func NewStreamingProvider(cfg *config.Manager) *StreamingProvider {
 	}
 }
 
+// SetUsageTracker enables per-account traffic accounting and quota enforcement.
+func (p *StreamingProvider) SetUsageTracker(usage UsageTracker) {
+	p.usage = usage
+}
+
+// checkQuota returns an error when account has used up its monthly quota.
+func (p *StreamingProvider) checkQuota(account string) error {
+	if p.usage != nil && p.usage.DebridQuotaExceeded(account) {
+		return fmt.Errorf("debrid account %q has exceeded its monthly quota", account)
+	}
+	return nil
+}
+
 // getCachedURL retrieves a cached unrestricted URL if it exists and hasn't expired.
 func (p *StreamingProvider) getCachedURL(cacheKey string) (url string, filename string, found bool) {
 	p.cacheMux.RLock()
@@ -110,6 +131,14 @@ func (p *StreamingProvider) setCachedURL(cacheKey, url, filename string) {
 	}
 }
 
+// invalidateCachedURL drops a cached unrestricted URL, e.g. after the provider reports it expired.
+func (p *StreamingProvider) invalidateCachedURL(cacheKey string) {
+	p.cacheMux.Lock()
+	defer p.cacheMux.Unlock()
+
+	delete(p.urlCache, cacheKey)
+}
+
 // GetDirectURL returns the unrestricted HTTP download URL for the given debrid path.
 // This URL can be used directly by FFmpeg for seekable input.
 func (p *StreamingProvider) GetDirectURL(ctx context.Context, path string) (string, error) {
@@ -133,10 +162,11 @@ func (p *StreamingProvider) GetDirectURL(ctx context.Context, path string) (stri
 	}
 
 	// Find the provider configuration
-	var apiKey string
+	var apiKey, account string
 	for _, debridProvider := range settings.Streaming.DebridProviders {
 		if strings.EqualFold(debridProvider.Provider, provider) && debridProvider.Enabled {
 			apiKey = strings.TrimSpace(debridProvider.APIKey)
+			account = debridProvider.AccountName()
 			break
 		}
 	}
@@ -144,6 +174,11 @@ func (p *StreamingProvider) GetDirectURL(ctx context.Context, path string) (stri
 	if apiKey == "" {
 		return "", fmt.Errorf("provider %s not configured or not enabled", provider)
 	}
+	// Direct URLs are fetched by FFmpeg, outside of Stream, so their traffic
+	// isn't counted; the quota is still enforced
+	if err := p.checkQuota(account); err != nil {
+		return "", err
+	}
 
 	// Get provider from registry
 	client, ok := GetProvider(provider, apiKey)
@@ -224,6 +259,10 @@ func (p *StreamingProvider) Stream(ctx context.Context, req streaming.Request) (
 	if providerConfig == nil {
 		return nil, fmt.Errorf("provider %q not configured", provider)
 	}
+	account := providerConfig.AccountName()
+	if err := p.checkQuota(account); err != nil {
+		return nil, err
+	}
 
 	// Get provider from registry
 	client, ok := GetProvider(strings.ToLower(providerConfig.Provider), providerConfig.APIKey)
@@ -231,90 +270,15 @@ func (p *StreamingProvider) Stream(ctx context.Context, req streaming.Request) (
 		return nil, fmt.Errorf("provider %q not registered", providerConfig.Provider)
 	}
 
-	return p.streamWithProvider(ctx, req, client, torrentID, fileID)
+	return p.streamWithProvider(ctx, req, client, account, torrentID, fileID)
 }
 
-func (p *StreamingProvider) streamWithProvider(ctx context.Context, req streaming.Request, client Provider, torrentID, fileID string) (*streaming.Response, error) {
+func (p *StreamingProvider) streamWithProvider(ctx context.Context, req streaming.Request, client Provider, account, torrentID, fileID string) (*streaming.Response, error) {
 	providerName := client.Name()
-	var downloadURL string
-	var filename string
-
-	cacheKey := cacheKeyFor(torrentID, fileID)
 
-	// Check cache first
-	if cachedURL, cachedFilename, found := p.getCachedURL(cacheKey); found {
-		log.Printf("[debrid-stream] using cached URL for torrent %s file %s", torrentID, fileID)
-		downloadURL = cachedURL
-		filename = cachedFilename
-	} else {
-		// Cache miss - need to unrestrict the link
-		// Get fresh torrent info to get download links
-		info, err := client.GetTorrentInfo(ctx, torrentID)
-		if err != nil {
-			return nil, fmt.Errorf("get torrent info: %w", err)
-		}
-
-		restrictedLink, resolvedFilename, _, matched := resolveRestrictedLink(info, fileID)
-		if restrictedLink == "" {
-			return nil, fmt.Errorf("no download links available for torrent %s", torrentID)
-		}
-		if fileID != "" && !matched {
-			log.Printf("[debrid-stream] requested file id %s not available for torrent %s; defaulting to first link", fileID, torrentID)
-		}
-		if resolvedFilename != "" {
-			log.Printf("[debrid-stream] resolved filename: %s", resolvedFilename)
-		}
-
-		log.Printf("[debrid-stream] unrestricting link: %s", restrictedLink)
-
-		// Unrestrict the link to get the actual download URL
-		unrestricted, err := client.UnrestrictLink(ctx, restrictedLink)
-		if err != nil {
-			return nil, fmt.Errorf("unrestrict link: %w", err)
-		}
-
-		// Use the direct download URL
-		downloadURL = unrestricted.DownloadURL
-
-		// Use filename from provider if available, otherwise use the resolved filename from torrent info
-		if unrestricted.Filename != "" {
-			filename = unrestricted.Filename
-		} else {
-			filename = resolvedFilename
-		}
-
-		// Cache the URL and filename for future requests
-		p.setCachedURL(cacheKey, downloadURL, filename)
-
-		log.Printf("[debrid-stream] proxying to unrestricted URL: %s", downloadURL)
-	}
-
-	// Create HTTP request to the provider
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, downloadURL, nil)
+	resp, filename, err := p.openDownload(ctx, client, req.Method, torrentID, fileID, req.RangeHeader)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	// Forward range header if present
-	if req.RangeHeader != "" {
-		httpReq.Header.Set("Range", req.RangeHeader)
-	}
-
-	// Make the request
-	httpClient := &http.Client{
-		Timeout: 30 * time.Minute,
-	}
-
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	// For failed requests, close and return error
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		resp.Body.Close()
-		return nil, fmt.Errorf("%s request failed: %s: %s", providerName, resp.Status, string(body))
+		return nil, err
 	}
 
 	log.Printf("[debrid-stream] %s response: status=%d content-length=%d range=%q",
@@ -345,15 +309,171 @@ func (p *StreamingProvider) streamWithProvider(ctx context.Context, req streamin
 		}, nil
 	}
 
+	// Unrestricted links expire; if the connection drops mid-stream, re-unrestrict
+	// and continue from the byte the client is waiting for.
+	start, end := streaming.ResponseByteRange(resp)
+	reopen := func(ctx context.Context, offset, end int64) (io.ReadCloser, error) {
+		resumed, _, err := p.openDownload(ctx, client, http.MethodGet, torrentID, fileID, streaming.RangeHeader(offset, end))
+		if err != nil {
+			return nil, err
+		}
+		if resumed.StatusCode != http.StatusPartialContent {
+			resumed.Body.Close()
+			return nil, fmt.Errorf("%s ignored range request: %s", providerName, resumed.Status)
+		}
+		return resumed.Body, nil
+	}
+
+	var body io.ReadCloser = streaming.NewResumingBody(ctx, resp.Body, start, end, reopen)
+	if p.usage != nil {
+		body = &usageCountingBody{ReadCloser: body, usage: p.usage, account: account}
+	}
+
 	return &streaming.Response{
 		Status:        resp.StatusCode,
 		Headers:       headers,
 		ContentLength: resp.ContentLength,
-		Body:          resp.Body,
+		Body:          body,
 		Filename:      filename,
 	}, nil
 }
 
+// usageFlushBytes is how much traffic a stream accumulates before reporting it,
+// so long streams show up in usage while they play
+const usageFlushBytes = 32 * 1024 * 1024
+
+// usageCountingBody reports the bytes read from a debrid stream to the usage tracker.
+type usageCountingBody struct {
+	io.ReadCloser
+	usage   UsageTracker
+	account string
+	pending int64
+}
+
+func (b *usageCountingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.pending += int64(n)
+	if b.pending >= usageFlushBytes {
+		b.usage.RecordDebrid(b.account, b.pending)
+		b.pending = 0
+	}
+	return n, err
+}
+
+func (b *usageCountingBody) Close() error {
+	if b.pending > 0 {
+		b.usage.RecordDebrid(b.account, b.pending)
+		b.pending = 0
+	}
+	return b.ReadCloser.Close()
+}
+
+// openDownload requests the unrestricted download URL for a torrent file. If the
+// provider reports the link as expired (403/410), the cached URL is dropped and
+// the link is unrestricted again before retrying once.
+func (p *StreamingProvider) openDownload(ctx context.Context, client Provider, method, torrentID, fileID, rangeHeader string) (*http.Response, string, error) {
+	providerName := client.Name()
+	cacheKey := cacheKeyFor(torrentID, fileID)
+
+	for attempt := 0; ; attempt++ {
+		downloadURL, filename, err := p.resolveDownloadURL(ctx, client, torrentID, fileID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Create HTTP request to the provider
+		httpReq, err := http.NewRequestWithContext(ctx, method, downloadURL, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("create request: %w", err)
+		}
+
+		// Forward range header if present
+		if rangeHeader != "" {
+			httpReq.Header.Set("Range", rangeHeader)
+		}
+
+		// Make the request
+		httpClient := &http.Client{
+			Timeout: 30 * time.Minute,
+		}
+
+		resp, err := httpClient.Do(httpReq)
+		if err != nil {
+			return nil, "", fmt.Errorf("request failed: %w", err)
+		}
+
+		if streaming.IsLinkExpiredStatus(resp.StatusCode) && attempt == 0 {
+			resp.Body.Close()
+			log.Printf("[debrid-stream] %s link expired (%s) for torrent %s file %s; re-unrestricting",
+				providerName, resp.Status, torrentID, fileID)
+			p.invalidateCachedURL(cacheKey)
+			continue
+		}
+
+		// For failed requests, close and return error
+		if resp.StatusCode >= 400 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("%s request failed: %s: %s", providerName, resp.Status, string(body))
+		}
+
+		return resp, filename, nil
+	}
+}
+
+// resolveDownloadURL returns the unrestricted download URL and filename for a
+// torrent file, unrestricting the link when no cached URL is available.
+func (p *StreamingProvider) resolveDownloadURL(ctx context.Context, client Provider, torrentID, fileID string) (string, string, error) {
+	cacheKey := cacheKeyFor(torrentID, fileID)
+
+	// Check cache first
+	if cachedURL, cachedFilename, found := p.getCachedURL(cacheKey); found {
+		log.Printf("[debrid-stream] using cached URL for torrent %s file %s", torrentID, fileID)
+		return cachedURL, cachedFilename, nil
+	}
+
+	// Cache miss - need to unrestrict the link
+	// Get fresh torrent info to get download links
+	info, err := client.GetTorrentInfo(ctx, torrentID)
+	if err != nil {
+		return "", "", fmt.Errorf("get torrent info: %w", err)
+	}
+
+	restrictedLink, resolvedFilename, _, matched := resolveRestrictedLink(info, fileID)
+	if restrictedLink == "" {
+		return "", "", fmt.Errorf("no download links available for torrent %s", torrentID)
+	}
+	if fileID != "" && !matched {
+		log.Printf("[debrid-stream] requested file id %s not available for torrent %s; defaulting to first link", fileID, torrentID)
+	}
+	if resolvedFilename != "" {
+		log.Printf("[debrid-stream] resolved filename: %s", resolvedFilename)
+	}
+
+	log.Printf("[debrid-stream] unrestricting link: %s", restrictedLink)
+
+	// Unrestrict the link to get the actual download URL
+	unrestricted, err := client.UnrestrictLink(ctx, restrictedLink)
+	if err != nil {
+		return "", "", fmt.Errorf("unrestrict link: %w", err)
+	}
+
+	// Use the direct download URL
+	downloadURL := unrestricted.DownloadURL
+
+	// Use filename from provider if available, otherwise use the resolved filename from torrent info
+	filename := resolvedFilename
+	if unrestricted.Filename != "" {
+		filename = unrestricted.Filename
+	}
+
+	// Cache the URL and filename for future requests
+	p.setCachedURL(cacheKey, downloadURL, filename)
+
+	log.Printf("[debrid-stream] proxying to unrestricted URL: %s", downloadURL)
+	return downloadURL, filename, nil
+}
+
 // CompositeProvider combines multiple streaming providers.
 type CompositeProvider struct {
 	providers []streaming.Provider
@@ -393,6 +513,33 @@ func (c *CompositeProvider) GetDirectURL(ctx context.Context, path string) (stri
 	return "", streaming.ErrNotFound
 }
 
+// LocalFile returns the on-disk path of a complete local copy from any provider that has one.
+func (c *CompositeProvider) LocalFile(ctx context.Context, path string) (string, bool) {
+	for _, provider := range c.providers {
+		localProvider, ok := provider.(streaming.LocalFileProvider)
+		if !ok || localProvider == nil {
+			continue
+		}
+		if local, ok := localProvider.LocalFile(ctx, path); ok {
+			return local, true
+		}
+	}
+	return "", false
+}
+
+// fillCaches lets local caches keep a copy of a stream another provider served.
+func (c *CompositeProvider) fillCaches(source streaming.Provider, req streaming.Request, resp *streaming.Response) *streaming.Response {
+	for _, provider := range c.providers {
+		if provider == nil || provider == source {
+			continue
+		}
+		if filler, ok := provider.(streaming.CacheFiller); ok {
+			resp = filler.Fill(req, resp)
+		}
+	}
+	return resp
+}
+
 // Stream tries each provider in order until one handles the request.
 func (c *CompositeProvider) Stream(ctx context.Context, req streaming.Request) (*streaming.Response, error) {
 	for _, provider := range c.providers {
@@ -402,7 +549,7 @@ func (c *CompositeProvider) Stream(ctx context.Context, req streaming.Request) (
 
 		resp, err := provider.Stream(ctx, req)
 		if err == nil {
-			return resp, nil
+			return c.fillCaches(provider, req, resp), nil
 		}
 
 		// If not found, try next provider
@@ -0,0 +1,333 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"novastream/internal/database"
+	"novastream/models"
+	"novastream/utils/releasename"
+)
+
+var (
+	ErrRepositoryRequired     = errors.New("device repository not provided")
+	ErrDeviceIDRequired       = errors.New("device id is required")
+	ErrDeviceNotFound         = errors.New("device not found")
+	ErrDeviceNotOwned         = errors.New("device is registered to another account")
+	ErrProfileNotOwned        = errors.New("profile not found")
+	ErrInvalidTranscodePolicy = errors.New("transcode policy must be auto, always or never")
+	ErrInvalidBitrateCap      = errors.New("max bitrate must not be negative")
+	ErrInvalidResolutionCap   = errors.New("max resolution must be a resolution such as 1080p")
+)
+
+// Repository persists registered devices.
+type Repository interface {
+	UpsertDevice(device *database.Device) error
+	GetDevice(id string) (*database.Device, error)
+	ListDevices(profileID string) ([]*database.Device, error)
+	UpdateDeviceSettings(device *database.Device) (bool, error)
+	DeleteDevice(id string) (bool, error)
+}
+
+var _ Repository = (*database.DeviceRepository)(nil)
+
+// ProfileOwner reports whether a profile belongs to an account.
+type ProfileOwner interface {
+	BelongsToAccount(profileID, accountID string) bool
+}
+
+// Service keeps a persistent registry of playback devices, their reported
+// capabilities and the per-device transcode policy, bitrate and resolution caps.
+type Service struct {
+	repo     Repository
+	profiles ProfileOwner
+}
+
+// NewService creates a device registry backed by the given repository.
+// Profiles checks that devices are only assigned to the registering
+// account's own profiles.
+func NewService(repo Repository, profiles ProfileOwner) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo, profiles: profiles}, nil
+}
+
+// Register records a device for the account or refreshes the details it
+// reports. The name and profile are only taken from the registration until an
+// admin sets them. A device belongs to the first account that registers it;
+// other accounts can't refresh it.
+func (s *Service) Register(accountID string, reg models.DeviceRegistration) (*models.Device, error) {
+	id := strings.TrimSpace(reg.ID)
+	if id == "" {
+		return nil, ErrDeviceIDRequired
+	}
+	profileID := strings.TrimSpace(reg.ProfileID)
+	if profileID != "" && s.profiles != nil && !s.profiles.BelongsToAccount(profileID, accountID) {
+		return nil, ErrProfileNotOwned
+	}
+
+	platform := strings.TrimSpace(reg.Platform)
+	name := strings.TrimSpace(reg.Name)
+	if name == "" {
+		name = platform
+	}
+	if name == "" {
+		name = "Unknown Device"
+	}
+
+	// A compatibility test run on the device outranks what the app reports
+	reported := normalizeCapabilities(reg.Capabilities)
+	existing, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !ownedBy(existing, accountID) {
+		return nil, ErrDeviceNotOwned
+	}
+	if existing != nil && existing.Capabilities.CompatibilityTest != nil {
+		reported = reported.ApplyCompatibility(*existing.Capabilities.CompatibilityTest)
+	}
+
+	capabilities, err := json.Marshal(reported)
+	if err != nil {
+		return nil, fmt.Errorf("encode device capabilities: %w", err)
+	}
+
+	if err := s.repo.UpsertDevice(&database.Device{
+		ID:           id,
+		Name:         name,
+		Platform:     platform,
+		AppVersion:   strings.TrimSpace(reg.AppVersion),
+		Capabilities: string(capabilities),
+		ProfileID:    profileID,
+		AccountID:    accountID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.Get(id)
+}
+
+// Get returns a device by ID, or nil if it is not registered.
+func (s *Service) Get(id string) (*models.Device, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, ErrDeviceIDRequired
+	}
+
+	row, err := s.repo.GetDevice(id)
+	if err != nil || row == nil {
+		return nil, err
+	}
+
+	device := toModel(row)
+	return &device, nil
+}
+
+// RecordCompatibility stores the outcome of a playback compatibility run
+// on one of the account's devices and applies it to its capabilities.
+func (s *Service) RecordCompatibility(accountID, id string, test models.DeviceCompatibilityTest) (*models.Device, error) {
+	device, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+	if !ownedBy(device, accountID) {
+		return nil, ErrDeviceNotOwned
+	}
+
+	capabilities, err := json.Marshal(normalizeCapabilities(device.Capabilities).ApplyCompatibility(test))
+	if err != nil {
+		return nil, fmt.Errorf("encode device capabilities: %w", err)
+	}
+	if err := s.repo.UpsertDevice(&database.Device{
+		ID:           device.ID,
+		Name:         device.Name,
+		Platform:     device.Platform,
+		AppVersion:   device.AppVersion,
+		Capabilities: string(capabilities),
+		ProfileID:    device.ProfileID,
+		AccountID:    accountID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.Get(id)
+}
+
+// List returns registered devices, most recently seen first. A non-empty
+// profileID limits the result to devices assigned to that profile.
+func (s *Service) List(profileID string) ([]models.Device, error) {
+	rows, err := s.repo.ListDevices(strings.TrimSpace(profileID))
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]models.Device, 0, len(rows))
+	for _, row := range rows {
+		devices = append(devices, toModel(row))
+	}
+	return devices, nil
+}
+
+// Update applies a partial update to a device's name, profile or overrides.
+func (s *Service) Update(id string, update models.DeviceUpdate) (*models.Device, error) {
+	device, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	if update.Name != nil {
+		if name := strings.TrimSpace(*update.Name); name != "" {
+			device.Name = name
+		}
+	}
+	if update.ProfileID != nil {
+		device.ProfileID = strings.TrimSpace(*update.ProfileID)
+	}
+	if update.TranscodePolicy != nil {
+		policy := models.DeviceTranscodePolicy(strings.ToLower(strings.TrimSpace(string(*update.TranscodePolicy))))
+		if policy == "" {
+			policy = models.DeviceTranscodeAuto
+		}
+		if !policy.IsValid() {
+			return nil, ErrInvalidTranscodePolicy
+		}
+		device.TranscodePolicy = policy
+	}
+	if update.MaxBitrateKbps != nil {
+		if *update.MaxBitrateKbps < 0 {
+			return nil, ErrInvalidBitrateCap
+		}
+		device.MaxBitrateKbps = *update.MaxBitrateKbps
+	}
+	if update.MaxResolution != nil {
+		label, err := normalizeResolutionCap(*update.MaxResolution)
+		if err != nil {
+			return nil, err
+		}
+		device.MaxResolution = label
+	}
+	if update.SDROnly != nil {
+		device.SDROnly = *update.SDROnly
+	}
+
+	ok, err := s.repo.UpdateDeviceSettings(&database.Device{
+		ID:              device.ID,
+		Name:            device.Name,
+		ProfileID:       device.ProfileID,
+		TranscodePolicy: string(device.TranscodePolicy),
+		MaxBitrateKbps:  device.MaxBitrateKbps,
+		MaxResolution:   device.MaxResolution,
+		SDROnly:         device.SDROnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+
+	return device, nil
+}
+
+// Delete removes a device from the registry.
+func (s *Service) Delete(id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ErrDeviceIDRequired
+	}
+
+	ok, err := s.repo.DeleteDevice(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// ownedBy reports whether the account may refresh the device. Devices
+// registered before ownership was tracked are open to the next account.
+func ownedBy(device *models.Device, accountID string) bool {
+	return device.AccountID == "" || device.AccountID == accountID
+}
+
+func toModel(row *database.Device) models.Device {
+	device := models.Device{
+		ID:              row.ID,
+		Name:            row.Name,
+		Platform:        row.Platform,
+		AppVersion:      row.AppVersion,
+		ProfileID:       row.ProfileID,
+		AccountID:       row.AccountID,
+		FirstSeenAt:     row.FirstSeen,
+		LastSeenAt:      row.LastSeen,
+		TranscodePolicy: models.DeviceTranscodePolicy(row.TranscodePolicy),
+		MaxBitrateKbps:  row.MaxBitrateKbps,
+		MaxResolution:   row.MaxResolution,
+		SDROnly:         row.SDROnly,
+	}
+	if !device.TranscodePolicy.IsValid() {
+		device.TranscodePolicy = models.DeviceTranscodeAuto
+	}
+	if row.Capabilities != "" {
+		if err := json.Unmarshal([]byte(row.Capabilities), &device.Capabilities); err != nil {
+			log.Printf("[devices] ignoring unreadable capabilities for device %s: %v", row.ID, err)
+		}
+	}
+	return device
+}
+
+// normalizeResolutionCap turns "1080", "1080P" or "FHD" into "1080p".
+// An empty value clears the cap.
+func normalizeResolutionCap(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	height := releasename.ParseResolution(value)
+	if height == 0 {
+		return "", ErrInvalidResolutionCap
+	}
+	return releasename.ResolutionLabel(height), nil
+}
+
+// normalizeCapabilities lowercases and de-duplicates reported codec and
+// container names so they can be compared against ffprobe names.
+func normalizeCapabilities(c models.DeviceCapabilities) models.DeviceCapabilities {
+	return models.DeviceCapabilities{
+		VideoCodecs:     normalizeList(c.VideoCodecs),
+		AudioCodecs:     normalizeList(c.AudioCodecs),
+		HDRFormats:      normalizeList(c.HDRFormats),
+		MaxResolution:   strings.ToLower(strings.TrimSpace(c.MaxResolution)),
+		Containers:      normalizeList(c.Containers),
+		SubtitleFormats: normalizeList(c.SubtitleFormats),
+	}
+}
+
+func normalizeList(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+package devices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+type fakeRepository struct {
+	devices map[string]*database.Device
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{devices: make(map[string]*database.Device)}
+}
+
+func (f *fakeRepository) UpsertDevice(device *database.Device) error {
+	if existing, ok := f.devices[device.ID]; ok {
+		existing.Platform = device.Platform
+		existing.AppVersion = device.AppVersion
+		existing.Capabilities = device.Capabilities
+		if existing.AccountID == "" {
+			existing.AccountID = device.AccountID
+		}
+		existing.LastSeen = time.Now()
+		return nil
+	}
+	copy := *device
+	copy.TranscodePolicy = "auto"
+	copy.FirstSeen = time.Now()
+	copy.LastSeen = copy.FirstSeen
+	f.devices[device.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) GetDevice(id string) (*database.Device, error) {
+	if device, ok := f.devices[id]; ok {
+		copy := *device
+		return &copy, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) ListDevices(profileID string) ([]*database.Device, error) {
+	var out []*database.Device
+	for _, device := range f.devices {
+		if profileID == "" || device.ProfileID == profileID {
+			out = append(out, device)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) UpdateDeviceSettings(update *database.Device) (bool, error) {
+	device, ok := f.devices[update.ID]
+	if !ok {
+		return false, nil
+	}
+	device.Name = update.Name
+	device.ProfileID = update.ProfileID
+	device.TranscodePolicy = update.TranscodePolicy
+	device.MaxBitrateKbps = update.MaxBitrateKbps
+	device.MaxResolution = update.MaxResolution
+	device.SDROnly = update.SDROnly
+	return true, nil
+}
+
+type fakeProfiles map[string]string
+
+func (f fakeProfiles) BelongsToAccount(profileID, accountID string) bool {
+	return f[profileID] == accountID
+}
+
+func (f *fakeRepository) DeleteDevice(id string) (bool, error) {
+	if _, ok := f.devices[id]; !ok {
+		return false, nil
+	}
+	delete(f.devices, id)
+	return true, nil
+}
+
+func TestRegisterNormalizesCapabilities(t *testing.T) {
+	svc, err := NewService(newFakeRepository(), nil)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	device, err := svc.Register("acct-1", models.DeviceRegistration{
+		ID:       " dev-1 ",
+		Platform: "tvOS",
+		Capabilities: models.DeviceCapabilities{
+			VideoCodecs: []string{"HEVC", "h264", "hevc", " "},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if device.ID != "dev-1" || device.Name != "tvOS" || device.TranscodePolicy != models.DeviceTranscodeAuto {
+		t.Fatalf("unexpected device %+v", device)
+	}
+	if got := device.Capabilities.VideoCodecs; len(got) != 2 || got[0] != "hevc" || got[1] != "h264" {
+		t.Fatalf("expected normalized codecs [hevc h264], got %v", got)
+	}
+}
+
+func TestRecordCompatibilityOverridesReportedCapabilities(t *testing.T) {
+	svc, _ := NewService(newFakeRepository(), nil)
+	reg := models.DeviceRegistration{
+		ID:       "dev-1",
+		Platform: "Android TV",
+		Capabilities: models.DeviceCapabilities{
+			VideoCodecs: []string{"h264", "hevc"},
+			HDRFormats:  []string{"hdr10", "dolbyvision"},
+		},
+	}
+	if _, err := svc.Register("acct-1", reg); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	test := models.DeviceCompatibilityTest{
+		Supported:   []models.CompatFeature{{Kind: models.CompatAudioCodec, Value: "eac3"}},
+		Unsupported: []models.CompatFeature{{Kind: models.CompatHDRFormat, Value: "dolbyvision"}},
+	}
+	device, err := svc.RecordCompatibility("acct-1", "dev-1", test)
+	if err != nil {
+		t.Fatalf("RecordCompatibility() error = %v", err)
+	}
+	if got := device.Capabilities.HDRFormats; len(got) != 1 || got[0] != "hdr10" {
+		t.Fatalf("expected dolbyvision to be removed, got %v", got)
+	}
+	if got := device.Capabilities.AudioCodecs; len(got) != 1 || got[0] != "eac3" {
+		t.Fatalf("expected eac3 to be added, got %v", got)
+	}
+
+	// The app re-registering with its own claims doesn't undo the test
+	device, err = svc.Register("acct-1", reg)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if got := device.Capabilities.HDRFormats; len(got) != 1 || got[0] != "hdr10" || device.Capabilities.CompatibilityTest == nil {
+		t.Fatalf("expected the compatibility test to survive re-registration, got %+v", device.Capabilities)
+	}
+
+	if _, err := svc.RecordCompatibility("acct-1", "missing", test); !errors.Is(err, ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func TestUpdateOverrides(t *testing.T) {
+	svc, _ := NewService(newFakeRepository(), nil)
+	if _, err := svc.Register("acct-1", models.DeviceRegistration{ID: "dev-1", Platform: "Android TV"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	policy := models.DeviceTranscodePolicy("Always")
+	bitrate := 6000
+	device, err := svc.Update("dev-1", models.DeviceUpdate{TranscodePolicy: &policy, MaxBitrateKbps: &bitrate})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if device.TranscodePolicy != models.DeviceTranscodeAlways || device.MaxBitrateKbps != 6000 {
+		t.Fatalf("overrides not applied: %+v", device)
+	}
+
+	invalid := models.DeviceTranscodePolicy("sometimes")
+	if _, err := svc.Update("dev-1", models.DeviceUpdate{TranscodePolicy: &invalid}); !errors.Is(err, ErrInvalidTranscodePolicy) {
+		t.Fatalf("expected ErrInvalidTranscodePolicy, got %v", err)
+	}
+	resolution, sdrOnly := "1080", true
+	device, err = svc.Update("dev-1", models.DeviceUpdate{MaxResolution: &resolution, SDROnly: &sdrOnly})
+	if err != nil || device.MaxResolution != "1080p" || !device.SDROnly || device.MaxBitrateKbps != 6000 {
+		t.Fatalf("resolution caps not applied: %+v, %v", device, err)
+	}
+	resolution = "huge"
+	if _, err := svc.Update("dev-1", models.DeviceUpdate{MaxResolution: &resolution}); !errors.Is(err, ErrInvalidResolutionCap) {
+		t.Fatalf("expected ErrInvalidResolutionCap, got %v", err)
+	}
+	resolution = ""
+	if device, _ := svc.Update("dev-1", models.DeviceUpdate{MaxResolution: &resolution}); device == nil || device.MaxResolution != "" {
+		t.Fatalf("expected empty resolution to clear the cap, got %+v", device)
+	}
+
+	negative := -1
+	if _, err := svc.Update("dev-1", models.DeviceUpdate{MaxBitrateKbps: &negative}); !errors.Is(err, ErrInvalidBitrateCap) {
+		t.Fatalf("expected ErrInvalidBitrateCap, got %v", err)
+	}
+	if _, err := svc.Update("missing", models.DeviceUpdate{}); !errors.Is(err, ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound, got %v", err)
+	}
+	if err := svc.Delete("missing"); !errors.Is(err, ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound on delete, got %v", err)
+	}
+}
+
+func TestRegisterRejectsOtherAccounts(t *testing.T) {
+	repo := newFakeRepository()
+	svc, _ := NewService(repo, fakeProfiles{"prof-1": "acct-1", "prof-2": "acct-2"})
+
+	device, err := svc.Register("acct-1", models.DeviceRegistration{ID: "dev-1", Platform: "tvOS", ProfileID: "prof-1"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if device.AccountID != "acct-1" || device.ProfileID != "prof-1" {
+		t.Fatalf("expected device owned by acct-1 with prof-1, got %+v", device)
+	}
+
+	if _, err := svc.Register("acct-2", models.DeviceRegistration{ID: "dev-1", Platform: "tvOS"}); !errors.Is(err, ErrDeviceNotOwned) {
+		t.Fatalf("expected ErrDeviceNotOwned, got %v", err)
+	}
+	if _, err := svc.RecordCompatibility("acct-2", "dev-1", models.DeviceCompatibilityTest{}); !errors.Is(err, ErrDeviceNotOwned) {
+		t.Fatalf("expected ErrDeviceNotOwned on compatibility, got %v", err)
+	}
+	if _, err := svc.Register("acct-1", models.DeviceRegistration{ID: "dev-2", Platform: "tvOS", ProfileID: "prof-2"}); !errors.Is(err, ErrProfileNotOwned) {
+		t.Fatalf("expected ErrProfileNotOwned, got %v", err)
+	}
+
+	// Devices registered before ownership was tracked go to the next account
+	repo.devices["legacy"] = &database.Device{ID: "legacy", Platform: "tvOS"}
+	device, err = svc.Register("acct-2", models.DeviceRegistration{ID: "legacy", Platform: "tvOS"})
+	if err != nil || device.AccountID != "acct-2" {
+		t.Fatalf("expected legacy device to be claimed by acct-2, got %+v, %v", device, err)
+	}
+}
@@ -0,0 +1,420 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/mediatools"
+	"novastream/utils/sandbox"
+)
+
+// defaultProviderHosts are the API hosts of providers configured without a URL.
+var defaultProviderHosts = map[string]string{
+	"realdebrid": "api.real-debrid.com:443",
+	"torbox":     "api.torbox.app:443",
+	"alldebrid":  "api.alldebrid.com:443",
+	"torrentio":  "torrentio.strem.fun:443",
+	"nyaa":       "nyaa.si:443",
+	"tmdb":       "api.themoviedb.org:443",
+	"tvdb":       "api4.thetvdb.com:443",
+	"mdblist":    "api.mdblist.com:443",
+}
+
+func configIssue(severity, component, feature, format string, args ...interface{}) models.DiagnosticIssue {
+	return models.DiagnosticIssue{
+		Category:  models.DiagnosticCategoryConfig,
+		Severity:  severity,
+		Component: component,
+		Message:   fmt.Sprintf(format, args...),
+		Feature:   feature,
+	}
+}
+
+// noticeIssues reports the legacy settings found while loading settings.
+func noticeIssues(notices []config.LoadNotice) []models.DiagnosticIssue {
+	var issues []models.DiagnosticIssue
+	for _, n := range notices {
+		issue := models.DiagnosticIssue{
+			Category:  models.DiagnosticCategoryMigrated,
+			Severity:  models.DiagnosticSeverityInfo,
+			Component: n.Setting,
+			Message:   n.Message,
+		}
+		if n.Kind == config.NoticeDeprecated {
+			issue.Category = models.DiagnosticCategoryDeprecated
+			issue.Severity = models.DiagnosticSeverityWarning
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// validateSettings reports settings that are invalid or leave a feature unusable.
+func validateSettings(s config.Settings) []models.DiagnosticIssue {
+	var issues []models.DiagnosticIssue
+	add := func(severity, component, feature, format string, args ...interface{}) {
+		issues = append(issues, configIssue(severity, component, feature, format, args...))
+	}
+
+	if len(s.Server.Listeners) == 0 && (s.Server.Port < 1 || s.Server.Port > 65535) {
+		add(models.DiagnosticSeverityError, "server", "", "port %d is out of range", s.Server.Port)
+	}
+	for i, l := range s.Server.Listeners {
+		if strings.TrimSpace(l.Address) == "" {
+			add(models.DiagnosticSeverityError, fmt.Sprintf("server.listeners[%d]", i), "", "listener has no address")
+		}
+	}
+	if base := strings.TrimSpace(s.Server.BaseURL); base != "" {
+		if u, err := url.Parse(base); err != nil || u.Scheme == "" || u.Host == "" {
+			add(models.DiagnosticSeverityWarning, "server.baseUrl", "Absolute links", "%q is not an absolute URL; links are derived from requests instead", base)
+		}
+	}
+	if strings.TrimSpace(s.Cache.Directory) == "" {
+		add(models.DiagnosticSeverityError, "cache", "", "cache directory is not set")
+	}
+
+	tmdb := strings.TrimSpace(s.Metadata.TMDBAPIKey) != ""
+	tvdb := strings.TrimSpace(s.Metadata.TVDBAPIKey) != ""
+	switch {
+	case !tmdb && !tvdb:
+		add(models.DiagnosticSeverityError, "metadata", "Metadata, search and artwork", "neither a TMDB nor a TVDB API key is set")
+	case !tvdb:
+		add(models.DiagnosticSeverityInfo, "metadata", "", "no TVDB API key; series metadata is served from TMDB")
+	case !tmdb:
+		add(models.DiagnosticSeverityWarning, "metadata", "Movie metadata and trending lists", "no TMDB API key is set")
+	}
+
+	enabledUsenet := 0
+	for i, u := range s.Usenet {
+		if !u.Enabled {
+			continue
+		}
+		component := "usenet:" + nameOr(u.Name, strconv.Itoa(i))
+		if strings.TrimSpace(u.Host) == "" {
+			add(models.DiagnosticSeverityError, component, "", "server is enabled but has no host")
+			continue
+		}
+		enabledUsenet++
+		if u.Port < 0 || u.Port > 65535 {
+			add(models.DiagnosticSeverityError, component, "", "port %d is out of range", u.Port)
+		}
+		if u.Connections <= 0 {
+			add(models.DiagnosticSeverityWarning, component, "", "connections is %d; no downloads can run", u.Connections)
+		}
+	}
+
+	enabledIndexers := 0
+	for i, idx := range s.Indexers {
+		if !idx.Enabled {
+			continue
+		}
+		component := "indexer:" + nameOr(idx.Name, strconv.Itoa(i))
+		if _, err := hostPort(idx.URL); err != nil {
+			add(models.DiagnosticSeverityError, component, "Search on this indexer", "invalid URL: %v", err)
+			continue
+		}
+		enabledIndexers++
+		if strings.TrimSpace(idx.APIKey) == "" {
+			add(models.DiagnosticSeverityWarning, component, "", "no API key is set")
+		}
+	}
+
+	enabledDebrid := 0
+	for i, d := range s.Streaming.DebridProviders {
+		if !d.Enabled {
+			continue
+		}
+		component := "debrid:" + nameOr(d.Name, strconv.Itoa(i))
+		if strings.TrimSpace(d.APIKey) == "" {
+			add(models.DiagnosticSeverityError, component, "Streaming from this provider", "provider is enabled but has no API key")
+			continue
+		}
+		enabledDebrid++
+	}
+
+	for i, sc := range s.TorrentScrapers {
+		if !sc.Enabled {
+			continue
+		}
+		if _, builtIn := defaultProviderHosts[strings.ToLower(sc.Type)]; builtIn && strings.TrimSpace(sc.URL) == "" {
+			continue
+		}
+		if _, err := hostPort(sc.URL); err != nil {
+			add(models.DiagnosticSeverityError, "scraper:"+nameOr(sc.Name, strconv.Itoa(i)), "Search on this scraper", "invalid URL: %v", err)
+		}
+	}
+
+	mode := s.Streaming.ServiceMode
+	if mode == config.StreamingServiceModeUsenet || mode == config.StreamingServiceModeHybrid {
+		if enabledUsenet == 0 {
+			add(models.DiagnosticSeverityWarning, "streaming", "Usenet streaming", "service mode is %q but no usenet server is enabled", mode)
+		}
+		if enabledIndexers == 0 {
+			add(models.DiagnosticSeverityWarning, "streaming", "Usenet search", "service mode is %q but no indexer is enabled", mode)
+		}
+	}
+	if (mode == config.StreamingServiceModeDebrid || mode == config.StreamingServiceModeHybrid) && enabledDebrid == 0 {
+		add(models.DiagnosticSeverityWarning, "streaming", "Debrid streaming", "service mode is %q but no debrid provider is enabled", mode)
+	}
+
+	if s.MDBList.Enabled && strings.TrimSpace(s.MDBList.APIKey) == "" {
+		add(models.DiagnosticSeverityWarning, "mdblist", "MDBList ratings", "MDBList is enabled but has no API key")
+	}
+
+	switch mode := strings.ToLower(strings.TrimSpace(s.Sandbox.Mode)); mode {
+	case "", config.SandboxModeNone, config.SandboxModeLimits, config.SandboxModeFirejail, config.SandboxModeNsjail:
+	default:
+		add(models.DiagnosticSeverityWarning, "sandbox", "Sandboxing of external tools", "unknown mode %q; tools run unsandboxed", mode)
+	}
+
+	return issues
+}
+
+// checkBinaries reports external tools that are missing from the system.
+func (s *Service) checkBinaries(settings config.Settings) []models.DiagnosticIssue {
+	var issues []models.DiagnosticIssue
+	missing := func(severity, component, feature, path string) {
+		issues = append(issues, models.DiagnosticIssue{
+			Category:  models.DiagnosticCategoryBinary,
+			Severity:  severity,
+			Component: component,
+			Message:   fmt.Sprintf("%q was not found", path),
+			Feature:   feature,
+		})
+	}
+
+	if settings.Transmux.Enabled {
+		if path := nameOr(settings.Transmux.FFmpegPath, "ffmpeg"); !s.hasBinary(path) {
+			missing(models.DiagnosticSeverityError, "ffmpeg", "Transmuxing and HLS playback", path)
+		}
+		if path := nameOr(settings.Transmux.FFprobePath, "ffprobe"); !s.hasBinary(path) {
+			missing(models.DiagnosticSeverityError, "ffprobe", "Media probing and track selection", path)
+		}
+	} else {
+		issues = append(issues, configIssue(models.DiagnosticSeverityInfo, "transmux", "Transmuxing and HLS playback", "transmuxing is disabled in settings"))
+	}
+
+	if !s.hasBinary("/usr/local/bin/yt-dlp") && !s.hasBinary("yt-dlp") {
+		missing(models.DiagnosticSeverityWarning, "yt-dlp", "Trailers", "yt-dlp")
+	}
+
+	for _, wrapper := range sandbox.RequiredBinaries(settings.Sandbox) {
+		if !s.hasBinary(wrapper) {
+			missing(models.DiagnosticSeverityError, "sandbox", "ffmpeg, ffprobe and yt-dlp (sandbox wrapper missing)", wrapper)
+		}
+	}
+
+	return issues
+}
+
+// checkMediaTools reports the detected ffmpeg, ffprobe and MP4Box versions
+// and the capabilities whose absence turns a playback feature off.
+func (s *Service) checkMediaTools(settings config.Settings) []models.DiagnosticIssue {
+	caps := s.mediaTools()
+	if !settings.Transmux.Enabled || !caps.Detected || caps.FFmpegVersion == "" {
+		// Not detected yet, or ffmpeg is missing, which checkBinaries reports
+		return nil
+	}
+
+	var issues []models.DiagnosticIssue
+	add := func(severity, component, feature, format string, args ...interface{}) {
+		issues = append(issues, models.DiagnosticIssue{
+			Category:  models.DiagnosticCategoryBinary,
+			Severity:  severity,
+			Component: component,
+			Message:   fmt.Sprintf(format, args...),
+			Feature:   feature,
+		})
+	}
+
+	versions := "ffmpeg " + caps.FFmpegVersion
+	if caps.FFprobeVersion != "" {
+		versions += ", ffprobe " + caps.FFprobeVersion
+	}
+	if caps.MP4BoxVersion != "" {
+		versions += ", MP4Box " + caps.MP4BoxVersion
+	}
+	add(models.DiagnosticSeverityInfo, "media-tools", "", "detected %s", versions)
+
+	if !caps.HEVCMetadataBSF {
+		add(models.DiagnosticSeverityWarning, "ffmpeg", "HDR10 color signaling",
+			"the hevc_metadata bitstream filter is not compiled in; HDR streams are sent without corrected color metadata")
+	}
+	if !caps.DolbyVisionMuxing {
+		add(models.DiagnosticSeverityWarning, "ffmpeg", "Dolby Vision playback",
+			"ffmpeg %s cannot write Dolby Vision (dvh1/dvhe) tracks; Dolby Vision sources play as HDR10 (needs FFmpeg 5.1 or newer)", caps.FFmpegVersion)
+	}
+	if !caps.LibFDKAAC {
+		add(models.DiagnosticSeverityInfo, "ffmpeg", "",
+			"libfdk_aac is not compiled in; surround audio is transcoded with ffmpeg's native AAC encoder")
+	}
+
+	if caps.FFprobeVersion != "" {
+		ffmpegMajor, ffmpegMinor, ok1 := mediatools.ParseMajorMinor(caps.FFmpegVersion)
+		ffprobeMajor, ffprobeMinor, ok2 := mediatools.ParseMajorMinor(caps.FFprobeVersion)
+		if ok1 && ok2 && (ffmpegMajor != ffprobeMajor || ffmpegMinor != ffprobeMinor) {
+			add(models.DiagnosticSeverityWarning, "ffprobe", "",
+				"ffprobe %s and ffmpeg %s are from different releases; probe results may not match what ffmpeg can do", caps.FFprobeVersion, caps.FFmpegVersion)
+		}
+	}
+
+	return issues
+}
+
+func (s *Service) hasBinary(path string) bool {
+	_, err := s.lookPath(path)
+	return err == nil
+}
+
+// providerTarget is an address a configured provider must be reachable at.
+type providerTarget struct {
+	component string
+	address   string
+	feature   string
+}
+
+// checkProviders reports configured providers that cannot be connected to.
+// Only a TCP connection is attempted; credentials are not verified.
+func (s *Service) checkProviders(ctx context.Context, settings config.Settings) []models.DiagnosticIssue {
+	targets := providerTargets(settings)
+
+	results := make([]*models.DiagnosticIssue, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target providerTarget) {
+			defer wg.Done()
+			dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+			defer cancel()
+			conn, err := s.dial(dialCtx, "tcp", target.address)
+			if err == nil {
+				conn.Close()
+				return
+			}
+			results[i] = &models.DiagnosticIssue{
+				Category:  models.DiagnosticCategoryProvider,
+				Severity:  models.DiagnosticSeverityWarning,
+				Component: target.component,
+				Message:   fmt.Sprintf("%s is unreachable: %v", target.address, err),
+				Feature:   target.feature,
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	var issues []models.DiagnosticIssue
+	for _, issue := range results {
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// providerTargets lists the enabled, valid providers to check. Invalid ones
+// are already reported by validateSettings.
+func providerTargets(s config.Settings) []providerTarget {
+	var targets []providerTarget
+
+	for i, u := range s.Usenet {
+		if !u.Enabled || strings.TrimSpace(u.Host) == "" {
+			continue
+		}
+		port := u.Port
+		if port == 0 {
+			port = 119
+			if u.SSL {
+				port = 563
+			}
+		}
+		targets = append(targets, providerTarget{
+			component: "usenet:" + nameOr(u.Name, strconv.Itoa(i)),
+			address:   net.JoinHostPort(strings.TrimSpace(u.Host), strconv.Itoa(port)),
+			feature:   "Usenet downloads from this server",
+		})
+	}
+
+	for i, idx := range s.Indexers {
+		if !idx.Enabled {
+			continue
+		}
+		if address, err := hostPort(idx.URL); err == nil {
+			targets = append(targets, providerTarget{"indexer:" + nameOr(idx.Name, strconv.Itoa(i)), address, "Search on this indexer"})
+		}
+	}
+
+	for i, sc := range s.TorrentScrapers {
+		if !sc.Enabled {
+			continue
+		}
+		address, err := hostPort(sc.URL)
+		if strings.TrimSpace(sc.URL) == "" {
+			address, err = defaultProviderHosts[strings.ToLower(sc.Type)], nil
+		}
+		if err == nil && address != "" {
+			targets = append(targets, providerTarget{"scraper:" + nameOr(sc.Name, strconv.Itoa(i)), address, "Search on this scraper"})
+		}
+	}
+
+	for i, d := range s.Streaming.DebridProviders {
+		if !d.Enabled || strings.TrimSpace(d.APIKey) == "" {
+			continue
+		}
+		if address, ok := defaultProviderHosts[strings.ToLower(d.Provider)]; ok {
+			targets = append(targets, providerTarget{"debrid:" + nameOr(d.Name, strconv.Itoa(i)), address, "Streaming from this provider"})
+		}
+	}
+
+	if strings.TrimSpace(s.Metadata.TMDBAPIKey) != "" {
+		targets = append(targets, providerTarget{"metadata:tmdb", defaultProviderHosts["tmdb"], "TMDB metadata"})
+	}
+	if strings.TrimSpace(s.Metadata.TVDBAPIKey) != "" {
+		targets = append(targets, providerTarget{"metadata:tvdb", defaultProviderHosts["tvdb"], "TVDB metadata"})
+	}
+	if s.MDBList.Enabled && strings.TrimSpace(s.MDBList.APIKey) != "" {
+		targets = append(targets, providerTarget{"mdblist", defaultProviderHosts["mdblist"], "MDBList ratings"})
+	}
+
+	return targets
+}
+
+// hostPort returns the host:port an http(s) URL connects to.
+func hostPort(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("URL is empty")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%q has no host", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+func nameOr(value, fallback string) string {
+	if v := strings.TrimSpace(value); v != "" {
+		return v
+	}
+	return fallback
+}
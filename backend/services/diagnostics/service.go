@@ -0,0 +1,181 @@
+// Package diagnostics runs a self-check at startup (settings validation,
+// external binaries and their capabilities, provider reachability, legacy
+// settings) and keeps the report so users can see why a feature is disabled
+// without reading logs.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/mediatools"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// dialTimeout bounds each provider reachability check.
+const dialTimeout = 5 * time.Second
+
+// Service runs the diagnostics and stores the latest report, backed by a JSON
+// file on disk.
+type Service struct {
+	mu      sync.RWMutex
+	path    string
+	report  *models.DiagnosticsReport
+	running bool
+
+	now      func() time.Time
+	lookPath func(file string) (string, error)
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+
+	mediaTools func() mediatools.Capabilities
+}
+
+// NewService constructs a diagnostics store in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create diagnostics dir: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	svc := &Service{
+		path:     filepath.Join(storageDir, "diagnostics.json"),
+		now:      time.Now,
+		lookPath: exec.LookPath,
+		dial:     dialer.DialContext,
+
+		mediaTools: mediatools.Current,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Run checks settings and the environment, then stores and returns the
+// report. notices are the legacy settings reported while loading settings.
+// The previous report is discarded as soon as a run starts.
+func (s *Service) Run(ctx context.Context, settings config.Settings, notices []config.LoadNotice) (models.DiagnosticsReport, error) {
+	s.mu.Lock()
+	s.report = nil
+	s.running = true
+	s.mu.Unlock()
+
+	started := s.now()
+	var issues []models.DiagnosticIssue
+	issues = append(issues, noticeIssues(notices)...)
+	issues = append(issues, validateSettings(settings)...)
+	issues = append(issues, s.checkBinaries(settings)...)
+	issues = append(issues, s.checkMediaTools(settings)...)
+	issues = append(issues, s.checkProviders(ctx, settings)...)
+	sortIssues(issues)
+
+	report := models.DiagnosticsReport{
+		GeneratedAt: started.UTC(),
+		DurationMs:  s.now().Sub(started).Milliseconds(),
+		Issues:      issues,
+	}
+	if report.Issues == nil {
+		report.Issues = []models.DiagnosticIssue{}
+	}
+	for _, issue := range issues {
+		switch issue.Severity {
+		case models.DiagnosticSeverityError:
+			report.Errors++
+		case models.DiagnosticSeverityWarning:
+			report.Warnings++
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report = &report
+	s.running = false
+	return report, s.saveLocked()
+}
+
+// Latest returns the most recent report. ok is false while the first run
+// since startup is in progress or when none has completed.
+func (s *Service) Latest() (report models.DiagnosticsReport, running bool, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.report == nil {
+		return models.DiagnosticsReport{}, s.running, false
+	}
+	return *s.report, s.running, true
+}
+
+// sortIssues orders issues by severity, keeping check order within each.
+func sortIssues(issues []models.DiagnosticIssue) {
+	rank := map[string]int{
+		models.DiagnosticSeverityError:   0,
+		models.DiagnosticSeverityWarning: 1,
+		models.DiagnosticSeverityInfo:    2,
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return rank[issues[i].Severity] < rank[issues[j].Severity]
+	})
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open diagnostics: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read diagnostics: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var report models.DiagnosticsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("decode diagnostics: %w", err)
+	}
+	s.report = &report
+	return nil
+}
+
+// saveLocked writes the report to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode diagnostics: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write diagnostics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/mediatools"
+)
+
+func newTestService(t *testing.T, binaries map[string]bool, reachable map[string]bool) *Service {
+	t.Helper()
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.lookPath = func(file string) (string, error) {
+		if binaries[file] {
+			return file, nil
+		}
+		return "", errors.New("not found")
+	}
+	svc.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if reachable[address] {
+			client, server := net.Pipe()
+			server.Close()
+			return client, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	return svc
+}
+
+func findIssue(report models.DiagnosticsReport, category, component string) *models.DiagnosticIssue {
+	for i := range report.Issues {
+		if report.Issues[i].Category == category && report.Issues[i].Component == component {
+			return &report.Issues[i]
+		}
+	}
+	return nil
+}
+
+func TestRunReportsIssues(t *testing.T) {
+	svc := newTestService(t,
+		map[string]bool{"ffprobe": true, "yt-dlp": true},
+		map[string]bool{"news.example.com:563": true},
+	)
+
+	settings := config.DefaultSettings()
+	settings.Metadata.TMDBAPIKey = "key"
+	settings.Usenet = []config.UsenetSettings{
+		{Name: "Primary", Host: "news.example.com", SSL: true, Connections: 10, Enabled: true},
+	}
+	settings.Indexers = []config.IndexerConfig{
+		{Name: "Broken", URL: "not a url", Enabled: true},
+		{Name: "Down", URL: "https://indexer.example.com/api", APIKey: "k", Enabled: true},
+	}
+	notices := []config.LoadNotice{
+		{Kind: config.NoticeDeprecated, Setting: "altmount", Message: "ignored"},
+		{Kind: config.NoticeMigrated, Setting: "trakt", Message: "converted"},
+	}
+
+	report, err := svc.Run(context.Background(), settings, notices)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if issue := findIssue(report, models.DiagnosticCategoryBinary, "ffmpeg"); issue == nil || issue.Severity != models.DiagnosticSeverityError {
+		t.Errorf("missing ffmpeg not reported as error: %+v", issue)
+	}
+	if findIssue(report, models.DiagnosticCategoryBinary, "ffprobe") != nil {
+		t.Error("ffprobe reported missing")
+	}
+	if findIssue(report, models.DiagnosticCategoryConfig, "indexer:Broken") == nil {
+		t.Error("invalid indexer URL not reported")
+	}
+	if issue := findIssue(report, models.DiagnosticCategoryProvider, "indexer:Down"); issue == nil {
+		t.Error("unreachable indexer not reported")
+	}
+	if findIssue(report, models.DiagnosticCategoryProvider, "usenet:Primary") != nil {
+		t.Error("reachable usenet server reported")
+	}
+	if findIssue(report, models.DiagnosticCategoryProvider, "indexer:Broken") != nil {
+		t.Error("invalid indexer should not be dialled")
+	}
+	if findIssue(report, models.DiagnosticCategoryDeprecated, "altmount") == nil {
+		t.Error("deprecated setting not reported")
+	}
+	if findIssue(report, models.DiagnosticCategoryMigrated, "trakt") == nil {
+		t.Error("migrated setting not reported")
+	}
+
+	if report.Issues[0].Severity != models.DiagnosticSeverityError {
+		t.Errorf("issues not sorted by severity: first is %q", report.Issues[0].Severity)
+	}
+	if report.Errors < 2 {
+		t.Errorf("Errors = %d, want at least 2", report.Errors)
+	}
+}
+
+func TestLatestPersists(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if _, _, ok := svc.Latest(); ok {
+		t.Fatal("expected no report before the first run")
+	}
+
+	svc.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	svc.dial = func(context.Context, string, string) (net.Conn, error) { return nil, errors.New("offline") }
+	settings := config.DefaultSettings()
+	if _, err := svc.Run(context.Background(), settings, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	report, running, ok := reloaded.Latest()
+	if !ok || running {
+		t.Fatalf("Latest() ok=%v running=%v after reload", ok, running)
+	}
+	if findIssue(report, models.DiagnosticCategoryConfig, "metadata") == nil {
+		t.Error("missing metadata keys not reported after reload")
+	}
+}
+
+func TestRunReportsMissingCapabilities(t *testing.T) {
+	svc := newTestService(t, map[string]bool{"ffmpeg": true, "ffprobe": true}, nil)
+	svc.mediaTools = func() mediatools.Capabilities {
+		return mediatools.Capabilities{Detected: true, FFmpegVersion: "4.4.2", FFprobeVersion: "6.1.1", HEVCMetadataBSF: true}
+	}
+
+	report, err := svc.Run(context.Background(), config.DefaultSettings(), nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var dolbyVision, hevcMetadata, mismatch bool
+	for _, issue := range report.Issues {
+		switch {
+		case issue.Feature == "Dolby Vision playback":
+			dolbyVision = issue.Severity == models.DiagnosticSeverityWarning
+		case issue.Feature == "HDR10 color signaling":
+			hevcMetadata = true
+		case issue.Component == "ffprobe" && issue.Category == models.DiagnosticCategoryBinary:
+			mismatch = true
+		}
+	}
+	if !dolbyVision {
+		t.Error("missing Dolby Vision muxing not reported as a warning")
+	}
+	if hevcMetadata {
+		t.Error("hevc_metadata reported missing although it was detected")
+	}
+	if !mismatch {
+		t.Error("ffmpeg/ffprobe version mismatch not reported")
+	}
+	if findIssue(report, models.DiagnosticCategoryBinary, "media-tools") == nil {
+		t.Error("detected versions not reported")
+	}
+}
@@ -0,0 +1,314 @@
+// Package external_subtitles stores subtitle files users upload for a title
+// or episode. Uploads are converted to WebVTT once and offered on every
+// release of the item they were uploaded for.
+package external_subtitles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+	"novastream/utils/subtitles"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrTitleIDRequired    = errors.New("titleId is required")
+	ErrInvalidEpisode     = errors.New("seasonNumber and episodeNumber must both be set for an episode")
+	ErrEmptyFile          = errors.New("subtitle file is empty")
+	ErrFileTooLarge       = errors.New("subtitle file is too large")
+	ErrUnsupportedFormat  = errors.New("subtitle file must be SRT, ASS/SSA or WebVTT")
+	ErrTooManySubtitles   = errors.New("too many subtitles uploaded for this item")
+	ErrNotFound           = errors.New("subtitle not found")
+	ErrForbidden          = errors.New("subtitle was uploaded by another account")
+)
+
+const (
+	// MaxUploadBytes bounds the size of an uploaded subtitle file.
+	MaxUploadBytes = 5 << 20
+	// maxPerItem bounds how many subtitles can be attached to one title or episode.
+	maxPerItem  = 20
+	maxLabelLen = 80
+)
+
+// Upload is a subtitle file to attach to a title, or to one of its episodes
+// when SeasonNumber and EpisodeNumber are set.
+type Upload struct {
+	AccountID     string
+	TitleID       string
+	SeasonNumber  int
+	EpisodeNumber int
+	Language      string
+	Label         string
+	Filename      string
+	Data          []byte
+}
+
+// Service stores uploaded subtitles as WebVTT files with a JSON index on disk.
+type Service struct {
+	mu        sync.RWMutex
+	path      string
+	fileDir   string
+	subtitles map[string]models.ExternalSubtitle // ID -> subtitle
+	now       func() time.Time
+}
+
+// NewService constructs an external subtitle store in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	fileDir := filepath.Join(storageDir, "external_subtitles")
+	if err := os.MkdirAll(fileDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create external subtitles dir: %w", err)
+	}
+
+	svc := &Service{
+		path:      filepath.Join(storageDir, "external_subtitles.json"),
+		fileDir:   fileDir,
+		subtitles: make(map[string]models.ExternalSubtitle),
+		now:       time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Upload converts a subtitle file to WebVTT and attaches it to its title or
+// episode.
+func (s *Service) Upload(upload Upload) (models.ExternalSubtitle, error) {
+	titleID := strings.TrimSpace(upload.TitleID)
+	if titleID == "" {
+		return models.ExternalSubtitle{}, ErrTitleIDRequired
+	}
+	if upload.SeasonNumber < 0 || upload.EpisodeNumber < 0 ||
+		(upload.SeasonNumber > 0) != (upload.EpisodeNumber > 0) {
+		return models.ExternalSubtitle{}, ErrInvalidEpisode
+	}
+	if len(upload.Data) == 0 {
+		return models.ExternalSubtitle{}, ErrEmptyFile
+	}
+	if len(upload.Data) > MaxUploadBytes {
+		return models.ExternalSubtitle{}, ErrFileTooLarge
+	}
+
+	vtt, format, err := subtitles.ToVTT(upload.Filename, upload.Data)
+	if errors.Is(err, subtitles.ErrUnknownFormat) {
+		return models.ExternalSubtitle{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return models.ExternalSubtitle{}, fmt.Errorf("convert subtitle: %w", err)
+	}
+
+	sub := models.ExternalSubtitle{
+		ID:            uuid.NewString(),
+		TitleID:       titleID,
+		SeasonNumber:  upload.SeasonNumber,
+		EpisodeNumber: upload.EpisodeNumber,
+		Language:      strings.ToLower(strings.TrimSpace(upload.Language)),
+		Label:         subtitleLabel(upload),
+		Format:        format,
+		AccountID:     upload.AccountID,
+		Size:          int64(len(vtt)),
+		UploadedAt:    s.now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.listLocked(sub.TitleID, sub.SeasonNumber, sub.EpisodeNumber)) >= maxPerItem {
+		return models.ExternalSubtitle{}, ErrTooManySubtitles
+	}
+
+	if err := os.WriteFile(s.filePath(sub.ID), vtt, 0o644); err != nil {
+		return models.ExternalSubtitle{}, fmt.Errorf("write subtitle file: %w", err)
+	}
+	s.subtitles[sub.ID] = sub
+	if err := s.saveLocked(); err != nil {
+		delete(s.subtitles, sub.ID)
+		os.Remove(s.filePath(sub.ID))
+		return models.ExternalSubtitle{}, err
+	}
+
+	log.Printf("[external_subtitles] stored %s subtitle %s for %s", format, sub.ID, describeItem(sub))
+	return sub, nil
+}
+
+// List returns the subtitles attached to a title (season and episode 0) or
+// one of its episodes, oldest first.
+func (s *Service) List(titleID string, seasonNumber, episodeNumber int) []models.ExternalSubtitle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listLocked(strings.TrimSpace(titleID), seasonNumber, episodeNumber)
+}
+
+// Get returns a stored subtitle by ID.
+func (s *Service) Get(id string) (models.ExternalSubtitle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subtitles[id]
+	if !ok {
+		return models.ExternalSubtitle{}, ErrNotFound
+	}
+	return sub, nil
+}
+
+// Open returns a stored subtitle and its WebVTT file. The caller closes the file.
+func (s *Service) Open(id string) (models.ExternalSubtitle, *os.File, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return models.ExternalSubtitle{}, nil, err
+	}
+
+	file, err := os.Open(s.filePath(sub.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.ExternalSubtitle{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return models.ExternalSubtitle{}, nil, fmt.Errorf("open subtitle file: %w", err)
+	}
+	return sub, file, nil
+}
+
+// Delete removes a subtitle. Only the account that uploaded it or the master
+// account may delete it.
+func (s *Service) Delete(id, accountID string, isMaster bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subtitles[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !isMaster && sub.AccountID != accountID {
+		return ErrForbidden
+	}
+
+	delete(s.subtitles, id)
+	if err := s.saveLocked(); err != nil {
+		s.subtitles[id] = sub
+		return err
+	}
+	if err := os.Remove(s.filePath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[external_subtitles] failed to remove subtitle file %s: %v", id, err)
+	}
+	return nil
+}
+
+// listLocked returns the subtitles attached to one item, oldest first.
+// Must be called with s.mu held.
+func (s *Service) listLocked(titleID string, seasonNumber, episodeNumber int) []models.ExternalSubtitle {
+	result := make([]models.ExternalSubtitle, 0)
+	for _, sub := range s.subtitles {
+		if sub.TitleID == titleID && sub.SeasonNumber == seasonNumber && sub.EpisodeNumber == episodeNumber {
+			result = append(result, sub)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].UploadedAt.Equal(result[j].UploadedAt) {
+			return result[i].UploadedAt.Before(result[j].UploadedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func (s *Service) filePath(id string) string {
+	return filepath.Join(s.fileDir, id+".vtt")
+}
+
+// subtitleLabel is the track name shown in players: the given label, else the
+// uploaded file's name without its extension.
+func subtitleLabel(upload Upload) string {
+	label := strings.TrimSpace(upload.Label)
+	if label == "" {
+		name := filepath.Base(strings.ReplaceAll(upload.Filename, "\\", "/"))
+		label = strings.TrimSpace(strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	if label == "" || label == "." {
+		label = "Uploaded subtitles"
+	}
+	if runes := []rune(label); len(runes) > maxLabelLen {
+		label = string(runes[:maxLabelLen])
+	}
+	return label
+}
+
+func describeItem(sub models.ExternalSubtitle) string {
+	if sub.SeasonNumber > 0 {
+		return fmt.Sprintf("%s S%02dE%02d", sub.TitleID, sub.SeasonNumber, sub.EpisodeNumber)
+	}
+	return sub.TitleID
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open external subtitles: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read external subtitles: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var stored []models.ExternalSubtitle
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("decode external subtitles: %w", err)
+	}
+	for _, sub := range stored {
+		if sub.ID == "" {
+			continue
+		}
+		s.subtitles[sub.ID] = sub
+	}
+
+	log.Printf("[external_subtitles] loaded %d uploaded subtitles", len(s.subtitles))
+	return nil
+}
+
+// saveLocked writes the subtitle index to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	stored := make([]models.ExternalSubtitle, 0, len(s.subtitles))
+	for _, sub := range s.subtitles {
+		stored = append(stored, sub)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].ID < stored[j].ID })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode external subtitles: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write external subtitles: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package external_subtitles
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testSRT = "1\n00:00:01,000 --> 00:00:02,000\nHello\n"
+
+func TestUploadListAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	sub, err := svc.Upload(Upload{
+		AccountID:     "acct-1",
+		TitleID:       "tmdb:tv:100",
+		SeasonNumber:  1,
+		EpisodeNumber: 2,
+		Language:      "ENG",
+		Filename:      "Show.S01E02.srt",
+		Data:          []byte(testSRT),
+	})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if sub.Format != "srt" || sub.Language != "eng" || sub.Label != "Show.S01E02" {
+		t.Fatalf("unexpected subtitle %+v", sub)
+	}
+
+	if got := svc.List("tmdb:tv:100", 1, 3); len(got) != 0 {
+		t.Fatalf("expected no subtitles on another episode, got %d", len(got))
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	listed := reloaded.List("tmdb:tv:100", 1, 2)
+	if len(listed) != 1 || listed[0].ID != sub.ID {
+		t.Fatalf("expected uploaded subtitle to persist, got %+v", listed)
+	}
+
+	_, file, err := reloaded.Open(sub.ID)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if !strings.HasPrefix(string(data), "WEBVTT") || !strings.Contains(string(data), "00:00:01.000 --> 00:00:02.000") {
+		t.Fatalf("expected converted WebVTT, got %q", data)
+	}
+
+	if err := reloaded.Delete(sub.ID, "acct-2", false); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another account, got %v", err)
+	}
+	if err := reloaded.Delete(sub.ID, "acct-2", true); err != nil {
+		t.Fatalf("Delete() as master error = %v", err)
+	}
+	if _, _, err := reloaded.Open(sub.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestUploadValidation(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		upload Upload
+		want   error
+	}{
+		{"missing title", Upload{Filename: "a.srt", Data: []byte(testSRT)}, ErrTitleIDRequired},
+		{"season without episode", Upload{TitleID: "t", SeasonNumber: 1, Filename: "a.srt", Data: []byte(testSRT)}, ErrInvalidEpisode},
+		{"empty", Upload{TitleID: "t", Filename: "a.srt"}, ErrEmptyFile},
+		{"not subtitles", Upload{TitleID: "t", Filename: "a.txt", Data: []byte("hello")}, ErrUnsupportedFormat},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Upload(tc.upload); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	for i := 0; i < maxPerItem; i++ {
+		if _, err := svc.Upload(Upload{TitleID: "movie", Filename: "a.srt", Data: []byte(testSRT)}); err != nil {
+			t.Fatalf("Upload() %d error = %v", i, err)
+		}
+	}
+	if _, err := svc.Upload(Upload{TitleID: "movie", Filename: "a.srt", Data: []byte(testSRT)}); !errors.Is(err, ErrTooManySubtitles) {
+		t.Fatalf("expected ErrTooManySubtitles, got %v", err)
+	}
+}
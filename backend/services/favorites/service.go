@@ -0,0 +1,302 @@
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrUserIDRequired     = errors.New("user id is required")
+	ErrIDRequired         = errors.New("id is required")
+	ErrMediaTypeRequired  = errors.New("media type is required")
+	ErrIdentifierRequired = errors.New("id and media type are required")
+)
+
+// Service stores each profile's favorites, independent of its watchlist.
+type Service struct {
+	mu    sync.RWMutex
+	path  string
+	items map[string]map[string]models.FavoriteItem
+}
+
+// NewService creates a favorites service storing data inside the provided directory.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create favorites dir: %w", err)
+	}
+
+	svc := &Service{
+		path:  filepath.Join(storageDir, "favorites.json"),
+		items: make(map[string]map[string]models.FavoriteItem),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// List returns a profile's favorites, most recently added first.
+func (s *Service) List(userID string) ([]models.FavoriteItem, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]models.FavoriteItem, 0, len(s.items[userID]))
+	for _, item := range s.items[userID] {
+		items = append(items, item)
+	}
+	sortNewestFirst(items)
+	return items, nil
+}
+
+// AddOrUpdate adds a favorite or updates the metadata of an existing one.
+func (s *Service) AddOrUpdate(userID string, input models.FavoriteUpsert) (models.FavoriteItem, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.FavoriteItem{}, ErrUserIDRequired
+	}
+	if strings.TrimSpace(input.ID) == "" {
+		return models.FavoriteItem{}, ErrIDRequired
+	}
+	if strings.TrimSpace(input.MediaType) == "" {
+		return models.FavoriteItem{}, ErrMediaTypeRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.upsertLocked(userID, input, time.Now().UTC())
+	if err := s.saveLocked(); err != nil {
+		return models.FavoriteItem{}, err
+	}
+	return item, nil
+}
+
+// Remove deletes a favorite. Returns false if it was not a favorite.
+func (s *Service) Remove(userID, mediaType, id string) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" || strings.TrimSpace(id) == "" {
+		return false, ErrIdentifierRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := mediaType + ":" + id
+	if _, ok := s.items[userID][key]; !ok {
+		return false, nil
+	}
+	delete(s.items[userID], key)
+	if err := s.saveLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteUser removes a profile's favorites. Used when a deleted profile is purged.
+func (s *Service) DeleteUser(userID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[userID]; !ok {
+		return nil
+	}
+	delete(s.items, userID)
+	return s.saveLocked()
+}
+
+// ImportFromWatchlist adds watchlist items as favorites, keeping when each
+// was added to the watchlist. Items that are already favorites are skipped.
+func (s *Service) ImportFromWatchlist(userID string, items []models.WatchlistItem) (models.FavoriteImportResult, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.FavoriteImportResult{}, ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := models.FavoriteImportResult{Items: []models.FavoriteItem{}}
+	for _, item := range items {
+		mediaType := strings.ToLower(strings.TrimSpace(item.MediaType))
+		if mediaType == "" || strings.TrimSpace(item.ID) == "" {
+			continue
+		}
+		if existing, ok := s.items[userID][mediaType+":"+item.ID]; ok {
+			result.Skipped++
+			result.Items = append(result.Items, existing)
+			continue
+		}
+
+		addedAt := item.AddedAt
+		if addedAt.IsZero() {
+			addedAt = time.Now().UTC()
+		}
+		favorite := s.upsertLocked(userID, models.FavoriteUpsert{
+			ID:          item.ID,
+			MediaType:   mediaType,
+			Name:        item.Name,
+			Overview:    item.Overview,
+			Year:        item.Year,
+			PosterURL:   item.PosterURL,
+			BackdropURL: item.BackdropURL,
+			ExternalIDs: item.ExternalIDs,
+		}, addedAt)
+		result.Imported++
+		result.Items = append(result.Items, favorite)
+	}
+
+	if result.Imported > 0 {
+		if err := s.saveLocked(); err != nil {
+			return models.FavoriteImportResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// IsFavoritesSyncSource reports whether a watchlist item's sync source is a
+// Trakt favorites list sync ("trakt:<account>:favorites:<task>").
+func IsFavoritesSyncSource(syncSource string) bool {
+	parts := strings.Split(syncSource, ":")
+	return len(parts) >= 3 && parts[0] == "trakt" && parts[2] == "favorites"
+}
+
+// upsertLocked adds or updates a favorite, using addedAt for new ones.
+// Must be called with s.mu held.
+func (s *Service) upsertLocked(userID string, input models.FavoriteUpsert, addedAt time.Time) models.FavoriteItem {
+	perUser, ok := s.items[userID]
+	if !ok {
+		perUser = make(map[string]models.FavoriteItem)
+		s.items[userID] = perUser
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(input.MediaType))
+	key := mediaType + ":" + input.ID
+	item, exists := perUser[key]
+	if !exists {
+		item = models.FavoriteItem{ID: input.ID, MediaType: mediaType, AddedAt: addedAt}
+	}
+
+	if strings.TrimSpace(input.Name) != "" {
+		item.Name = input.Name
+	}
+	if input.Overview != "" {
+		item.Overview = input.Overview
+	}
+	if input.Year != 0 {
+		item.Year = input.Year
+	}
+	if strings.TrimSpace(input.PosterURL) != "" {
+		item.PosterURL = input.PosterURL
+	}
+	if strings.TrimSpace(input.BackdropURL) != "" {
+		item.BackdropURL = input.BackdropURL
+	}
+	if len(input.ExternalIDs) > 0 {
+		ids := make(map[string]string, len(input.ExternalIDs))
+		for k, v := range input.ExternalIDs {
+			ids[k] = v
+		}
+		item.ExternalIDs = ids
+	}
+
+	perUser[key] = item
+	return item
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read favorites: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var byUser map[string][]models.FavoriteItem
+	if err := json.Unmarshal(data, &byUser); err != nil {
+		return fmt.Errorf("decode favorites: %w", err)
+	}
+
+	for userID, items := range byUser {
+		perUser := make(map[string]models.FavoriteItem, len(items))
+		for _, item := range items {
+			item.MediaType = strings.ToLower(strings.TrimSpace(item.MediaType))
+			perUser[item.Key()] = item
+		}
+		s.items[userID] = perUser
+	}
+	return nil
+}
+
+// saveLocked writes all favorites to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	byUser := make(map[string][]models.FavoriteItem, len(s.items))
+	for userID, perUser := range s.items {
+		items := make([]models.FavoriteItem, 0, len(perUser))
+		for _, item := range perUser {
+			items = append(items, item)
+		}
+		sortNewestFirst(items)
+		byUser[userID] = items
+	}
+
+	data, err := json.MarshalIndent(byUser, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode favorites: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write favorites: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace favorites file: %w", err)
+	}
+	return nil
+}
+
+func sortNewestFirst(items []models.FavoriteItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].AddedAt.Equal(items[j].AddedAt) {
+			return items[i].Key() < items[j].Key()
+		}
+		return items[i].AddedAt.After(items[j].AddedAt)
+	})
+}
@@ -0,0 +1,82 @@
+package favorites_test
+
+import (
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/favorites"
+)
+
+func TestServiceAddRemoveAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := favorites.NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	if _, err := svc.AddOrUpdate("u1", models.FavoriteUpsert{ID: "603", MediaType: "Movie", Name: "The Matrix"}); err != nil {
+		t.Fatalf("AddOrUpdate: %v", err)
+	}
+	if _, err := svc.AddOrUpdate("u1", models.FavoriteUpsert{ID: "1399", MediaType: "series", Name: "Game of Thrones"}); err != nil {
+		t.Fatalf("AddOrUpdate: %v", err)
+	}
+	if _, err := svc.AddOrUpdate("u1", models.FavoriteUpsert{MediaType: "movie"}); err != favorites.ErrIDRequired {
+		t.Fatalf("expected ErrIDRequired, got %v", err)
+	}
+
+	reloaded, err := favorites.NewService(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	items, _ := reloaded.List("u1")
+	if len(items) != 2 || items[0].Key() != "series:1399" || items[1].Key() != "movie:603" {
+		t.Fatalf("unexpected favorites after reload: %+v", items)
+	}
+	if other, _ := reloaded.List("u2"); len(other) != 0 {
+		t.Fatalf("favorites leaked to another profile: %+v", other)
+	}
+
+	if removed, err := reloaded.Remove("u1", "movie", "603"); err != nil || !removed {
+		t.Fatalf("Remove = %v, %v", removed, err)
+	}
+	if removed, _ := reloaded.Remove("u1", "movie", "603"); removed {
+		t.Fatal("expected second remove to report nothing removed")
+	}
+}
+
+func TestImportFromWatchlist(t *testing.T) {
+	svc, err := favorites.NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if _, err := svc.AddOrUpdate("u1", models.FavoriteUpsert{ID: "603", MediaType: "movie", Name: "The Matrix"}); err != nil {
+		t.Fatalf("AddOrUpdate: %v", err)
+	}
+
+	addedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	result, err := svc.ImportFromWatchlist("u1", []models.WatchlistItem{
+		{ID: "603", MediaType: "movie", Name: "The Matrix"},
+		{ID: "1399", MediaType: "series", Name: "Game of Thrones", AddedAt: addedAt},
+	})
+	if err != nil {
+		t.Fatalf("ImportFromWatchlist: %v", err)
+	}
+	if result.Imported != 1 || result.Skipped != 1 || len(result.Items) != 2 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	items, _ := svc.List("u1")
+	for _, item := range items {
+		if item.Key() == "series:1399" && !item.AddedAt.Equal(addedAt) {
+			t.Fatalf("expected the watchlist added time to be kept, got %v", item.AddedAt)
+		}
+	}
+
+	if !favorites.IsFavoritesSyncSource("trakt:acct1:favorites:task-1") {
+		t.Error("expected a Trakt favorites sync to be recognised")
+	}
+	if favorites.IsFavoritesSyncSource("trakt:acct1:watchlist:task-1") || favorites.IsFavoritesSyncSource("plex:acct:task") {
+		t.Error("expected other sync sources not to be treated as favorites")
+	}
+}
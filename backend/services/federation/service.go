@@ -0,0 +1,416 @@
+// Package federation syncs profiles, watchlists, watch history and playback
+// progress between two strmr servers (say, home and a second home) so a
+// profile can resume in either place. Each server pulls the other's change
+// journal over signed requests and keeps whichever side changed an item last.
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrStoresRequired     = errors.New("federation stores not provided")
+	ErrNameRequired       = errors.New("name is required")
+	ErrInvalidURL         = errors.New("url must be an http(s) URL")
+	ErrAccountRequired    = errors.New("accountId is required")
+	ErrSecretTooShort     = errors.New("secret must be at least 16 characters")
+	ErrDuplicateSecret    = errors.New("another peer already uses this secret")
+	ErrPeerNotFound       = errors.New("federation peer not found")
+	ErrPeerDisabled       = errors.New("federation peer is disabled")
+	ErrUnauthorized       = errors.New("invalid federation signature")
+	ErrProfileNotShared   = errors.New("profile is not shared with this peer")
+)
+
+const (
+	// syncInterval is how often enabled peers are pulled in the background.
+	syncInterval = 5 * time.Minute
+
+	minSecretLength = 16
+)
+
+// ProfileStore lists and creates the profiles shared with peers.
+type ProfileStore interface {
+	ListForAccount(accountID string) []models.User
+	Get(id string) (models.User, bool)
+	CreateForAccount(accountID, name string) (models.User, error)
+	SetKidsProfile(id string, isKids bool) (models.User, error)
+}
+
+// WatchlistStore reads and merges watchlists.
+type WatchlistStore interface {
+	List(userID string) ([]models.WatchlistItem, error)
+	Merge(userID string, item models.WatchlistItem) (bool, error)
+	RemoveBefore(userID, key string, removedAt time.Time) (bool, error)
+}
+
+// HistoryStore reads and merges watch history and playback progress.
+type HistoryStore interface {
+	ListWatchHistory(userID string) ([]models.WatchHistoryItem, error)
+	ListPlaybackProgress(userID string) ([]models.PlaybackProgress, error)
+	MergeWatchHistoryItem(userID string, item models.WatchHistoryItem) (bool, error)
+	MergePlaybackProgress(userID string, progress models.PlaybackProgress) (bool, error)
+	DeletePlaybackProgressBefore(userID, key string, deletedAt time.Time) (bool, error)
+}
+
+// ChangeJournal is the per-profile change journal peers pull from.
+type ChangeJournal interface {
+	ChangesSince(userID string, cursor int64) ([]models.SyncChange, int64, bool)
+	Cursor() int64
+	LastChange(userID, collection, key string) (models.SyncChange, bool)
+}
+
+// Stores are the local data a federation service serves and merges into.
+type Stores struct {
+	Profiles  ProfileStore
+	Watchlist WatchlistStore
+	History   HistoryStore
+	Journal   ChangeJournal
+}
+
+// Service manages federation peers, serves this server's changes to them and
+// pulls theirs, backed by a JSON file on disk.
+type Service struct {
+	mu     sync.RWMutex
+	path   string
+	peers  map[string]*models.FederationPeer
+	stores Stores
+	client *http.Client
+	now    func() time.Time
+
+	syncMu sync.Mutex // serialises pulls so two never merge the same peer at once
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs a federation service backed by a JSON file on disk.
+func NewService(storageDir string, stores Stores) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if stores.Profiles == nil || stores.Watchlist == nil || stores.History == nil || stores.Journal == nil {
+		return nil, ErrStoresRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create federation dir: %w", err)
+	}
+
+	svc := &Service{
+		path:   filepath.Join(storageDir, "federation_peers.json"),
+		peers:  make(map[string]*models.FederationPeer),
+		stores: stores,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// ListPeers returns the configured peers without their secrets.
+func (s *Service) ListPeers() []models.FederationPeer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peers := make([]models.FederationPeer, 0, len(s.peers))
+	for _, peer := range s.peers {
+		peers = append(peers, redact(*peer))
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].CreatedAt.Before(peers[j].CreatedAt)
+	})
+	return peers
+}
+
+// CreatePeer adds a peer. When no secret is given one is generated; the
+// returned peer carries it so it can be entered on the other server.
+func (s *Service) CreatePeer(req models.FederationPeerRequest) (models.FederationPeer, error) {
+	peer := models.FederationPeer{Enabled: true}
+	if err := applyPeerRequest(&peer, req); err != nil {
+		return models.FederationPeer{}, err
+	}
+	if peer.Secret == "" {
+		secret, err := GenerateSecret()
+		if err != nil {
+			return models.FederationPeer{}, fmt.Errorf("generate secret: %w", err)
+		}
+		peer.Secret = secret
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peerByKeyIDLocked(KeyID(peer.Secret)) != nil {
+		return models.FederationPeer{}, ErrDuplicateSecret
+	}
+
+	peer.ID = uuid.NewString()
+	peer.CreatedAt = s.now().UTC()
+	s.peers[peer.ID] = &peer
+
+	if err := s.saveLocked(); err != nil {
+		delete(s.peers, peer.ID)
+		return models.FederationPeer{}, err
+	}
+	log.Printf("[federation] added peer %s (%s) sharing account %s", peer.Name, peer.URL, peer.AccountID)
+
+	return peer, nil
+}
+
+// UpdatePeer changes a peer's settings. An empty secret keeps the current one.
+func (s *Service) UpdatePeer(peerID string, req models.FederationPeerRequest) (models.FederationPeer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.peers[strings.TrimSpace(peerID)]
+	if !ok {
+		return models.FederationPeer{}, ErrPeerNotFound
+	}
+	updated := *existing
+	if err := applyPeerRequest(&updated, req); err != nil {
+		return models.FederationPeer{}, err
+	}
+	if updated.Secret == "" {
+		updated.Secret = existing.Secret
+	}
+	if other := s.peerByKeyIDLocked(KeyID(updated.Secret)); other != nil && other.ID != existing.ID {
+		return models.FederationPeer{}, ErrDuplicateSecret
+	}
+	if updated.AccountID != existing.AccountID || updated.URL != existing.URL {
+		// A different server or account: start over rather than reuse links
+		updated.ProfileLinks = nil
+		updated.Cursors = nil
+	}
+
+	s.peers[updated.ID] = &updated
+	if err := s.saveLocked(); err != nil {
+		s.peers[existing.ID] = existing
+		return models.FederationPeer{}, err
+	}
+	return redact(updated), nil
+}
+
+// DeletePeer removes a peer. Synced data stays in place.
+func (s *Service) DeletePeer(peerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	peer, ok := s.peers[strings.TrimSpace(peerID)]
+	if !ok {
+		return ErrPeerNotFound
+	}
+	delete(s.peers, peer.ID)
+	return s.saveLocked()
+}
+
+// Authenticate returns the enabled peer that signed r.
+func (s *Service) Authenticate(r *http.Request) (models.FederationPeer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peer := s.peerByKeyIDLocked(r.Header.Get(HeaderKeyID))
+	if peer == nil || !verifySignature(r, peer.Secret, s.now()) {
+		return models.FederationPeer{}, ErrUnauthorized
+	}
+	if !peer.Enabled {
+		return models.FederationPeer{}, ErrPeerDisabled
+	}
+	return *peer, nil
+}
+
+// Start pulls every enabled peer now and then every syncInterval until ctx
+// ends or Stop is called.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.syncLoop(loopCtx)
+
+	log.Printf("[federation] sync loop started (%d peers)", len(s.peers))
+}
+
+// Stop ends the sync loop and waits for an in-progress pull to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) syncLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		s.syncAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) syncAll(ctx context.Context) {
+	s.mu.RLock()
+	var ids []string
+	for id, peer := range s.peers {
+		if peer.Enabled {
+			ids = append(ids, id)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.SyncPeer(ctx, id); err != nil {
+			log.Printf("[federation] sync with peer %s failed: %v", id, err)
+		}
+	}
+}
+
+func applyPeerRequest(peer *models.FederationPeer, req models.FederationPeerRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrNameRequired
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(req.URL), "/")
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	accountID := strings.TrimSpace(req.AccountID)
+	if accountID == "" {
+		return ErrAccountRequired
+	}
+	secret := strings.TrimSpace(req.Secret)
+	if secret != "" && len(secret) < minSecretLength {
+		return ErrSecretTooShort
+	}
+
+	peer.Name = name
+	peer.URL = baseURL
+	peer.AccountID = accountID
+	peer.Secret = secret
+	if req.Enabled != nil {
+		peer.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// peerByKeyIDLocked finds the peer whose secret has the given key ID.
+// Must be called with s.mu held.
+func (s *Service) peerByKeyIDLocked(keyID string) *models.FederationPeer {
+	if keyID == "" {
+		return nil
+	}
+	for _, peer := range s.peers {
+		if KeyID(peer.Secret) == keyID {
+			return peer
+		}
+	}
+	return nil
+}
+
+func redact(peer models.FederationPeer) models.FederationPeer {
+	peer.Secret = ""
+	peer.Cursors = nil
+	return peer
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open federation peers: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read federation peers: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var peers []*models.FederationPeer
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return fmt.Errorf("decode federation peers: %w", err)
+	}
+	for _, peer := range peers {
+		if peer != nil && peer.ID != "" {
+			s.peers[peer.ID] = peer
+		}
+	}
+
+	log.Printf("[federation] loaded %d peers", len(s.peers))
+	return nil
+}
+
+// saveLocked writes the peers to disk. The file holds shared secrets, so it
+// is only readable by the server's user.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	peers := make([]*models.FederationPeer, 0, len(s.peers))
+	for _, peer := range s.peers {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].CreatedAt.Before(peers[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(peers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode federation peers: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("write federation peers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/history"
+	"novastream/services/sync_journal"
+	"novastream/services/users"
+	"novastream/services/watchlist"
+)
+
+type testServer struct {
+	users     *users.Service
+	watchlist *watchlist.Service
+	history   *history.Service
+	fed       *Service
+	http      *httptest.Server
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+
+	userSvc, err := users.NewService(dir)
+	if err != nil {
+		t.Fatalf("users: %v", err)
+	}
+	watchlistSvc, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("watchlist: %v", err)
+	}
+	historySvc, err := history.NewService(dir)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	journal, err := sync_journal.NewService(dir)
+	if err != nil {
+		t.Fatalf("journal: %v", err)
+	}
+	watchlistSvc.SetChangeRecorder(journal)
+	historySvc.SetChangeRecorder(journal)
+
+	fed, err := NewService(dir, Stores{Profiles: userSvc, Watchlist: watchlistSvc, History: historySvc, Journal: journal})
+	if err != nil {
+		t.Fatalf("federation: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/federation/", func(w http.ResponseWriter, r *http.Request) {
+		peer, err := fed.Authenticate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/api/federation/profiles")
+		if path == "" {
+			json.NewEncoder(w).Encode(fed.SharedProfiles(peer))
+			return
+		}
+		profileID := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/changes")
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		delta, err := fed.Changes(peer, profileID, since)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(delta)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return &testServer{users: userSvc, watchlist: watchlistSvc, history: historySvc, fed: fed, http: srv}
+}
+
+func (ts *testServer) primary(t *testing.T) models.User {
+	t.Helper()
+	profiles := ts.users.ListForAccount(models.DefaultAccountID)
+	if len(profiles) != 1 {
+		t.Fatalf("expected one default profile, got %d", len(profiles))
+	}
+	return profiles[0]
+}
+
+func pair(t *testing.T) (home, away *testServer, homePeer, awayPeer models.FederationPeer) {
+	t.Helper()
+	home, away = newTestServer(t), newTestServer(t)
+
+	var err error
+	homePeer, err = home.fed.CreatePeer(models.FederationPeerRequest{Name: "away", URL: away.http.URL, AccountID: models.DefaultAccountID})
+	if err != nil {
+		t.Fatalf("create peer on home: %v", err)
+	}
+	awayPeer, err = away.fed.CreatePeer(models.FederationPeerRequest{Name: "home", URL: home.http.URL, AccountID: models.DefaultAccountID, Secret: homePeer.Secret})
+	if err != nil {
+		t.Fatalf("create peer on away: %v", err)
+	}
+	return home, away, homePeer, awayPeer
+}
+
+func TestSyncPeerLinksProfilesAndMergesNewerChanges(t *testing.T) {
+	home, away, homePeer, awayPeer := pair(t)
+	homeProfile, awayProfile := home.primary(t), away.primary(t)
+
+	kids, err := home.users.CreateForAccount(models.DefaultAccountID, "Kids")
+	if err != nil {
+		t.Fatalf("create kids profile: %v", err)
+	}
+	if _, err := home.users.SetKidsProfile(kids.ID, true); err != nil {
+		t.Fatalf("set kids: %v", err)
+	}
+
+	if _, err := home.watchlist.AddOrUpdate(homeProfile.ID, models.WatchlistUpsert{ID: "tt1", MediaType: "movie", Name: "Heat"}); err != nil {
+		t.Fatalf("add watchlist: %v", err)
+	}
+
+	older := time.Now().Add(-time.Hour).UTC()
+	newer := time.Now().UTC()
+	if _, err := home.history.MergeWatchHistoryItem(homeProfile.ID, models.WatchHistoryItem{
+		MediaType: "movie", ItemID: "tt2", Name: "Ronin", Watched: true, WatchedAt: older,
+	}); err != nil {
+		t.Fatalf("seed home history: %v", err)
+	}
+	if _, err := away.history.MergeWatchHistoryItem(awayProfile.ID, models.WatchHistoryItem{
+		MediaType: "movie", ItemID: "tt2", Name: "Ronin", Watched: false, WatchedAt: older, UpdatedAt: newer,
+	}); err != nil {
+		t.Fatalf("seed away history: %v", err)
+	}
+
+	result, err := away.fed.SyncPeer(context.Background(), awayPeer.ID)
+	if err != nil {
+		t.Fatalf("sync: %v (%+v)", err, result)
+	}
+	if result.Profiles != 2 {
+		t.Fatalf("expected 2 profiles synced, got %d", result.Profiles)
+	}
+
+	var awayKids *models.User
+	for _, user := range away.users.ListForAccount(models.DefaultAccountID) {
+		if user.Name == "Kids" {
+			u := user
+			awayKids = &u
+		}
+	}
+	if awayKids == nil || !awayKids.IsKidsProfile {
+		t.Fatalf("expected kids profile to be created on away server, got %+v", awayKids)
+	}
+
+	items, _ := away.watchlist.List(awayProfile.ID)
+	if len(items) != 1 || items[0].ID != "tt1" {
+		t.Fatalf("expected watchlist item to sync into the name-matched profile, got %+v", items)
+	}
+
+	watched, _ := away.history.ListWatchHistory(awayProfile.ID)
+	if len(watched) != 1 || watched[0].Watched {
+		t.Fatalf("expected newer local unwatch to win, got %+v", watched)
+	}
+
+	// Pulling the other way brings the newer unwatch home
+	if _, err := home.fed.SyncPeer(context.Background(), homePeer.ID); err != nil {
+		t.Fatalf("reverse sync: %v", err)
+	}
+	watched, _ = home.history.ListWatchHistory(homeProfile.ID)
+	if len(watched) != 1 || watched[0].Watched {
+		t.Fatalf("expected home to take newer unwatch, got %+v", watched)
+	}
+	if profiles := home.users.ListForAccount(models.DefaultAccountID); len(profiles) != 2 {
+		t.Fatalf("expected reverse sync to link existing profiles, got %d", len(profiles))
+	}
+
+	// Incremental pull carries deletions
+	if _, err := home.watchlist.Remove(homeProfile.ID, "movie", "tt1"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := away.fed.SyncPeer(context.Background(), awayPeer.ID); err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+	if items, _ := away.watchlist.List(awayProfile.ID); len(items) != 0 {
+		t.Fatalf("expected removal to sync, got %+v", items)
+	}
+}
+
+func TestAuthenticateRejectsWrongSecret(t *testing.T) {
+	home, _, homePeer, _ := pair(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/federation/profiles", nil)
+	signRequest(req, homePeer.Secret, time.Now())
+	if _, err := home.fed.Authenticate(req); err != nil {
+		t.Fatalf("expected valid signature to authenticate: %v", err)
+	}
+
+	forged := httptest.NewRequest(http.MethodGet, "/api/federation/profiles", nil)
+	signRequest(forged, homePeer.Secret, time.Now())
+	forged.Header.Set(HeaderSignature, strings.Repeat("0", 64))
+	if _, err := home.fed.Authenticate(forged); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized for forged signature, got %v", err)
+	}
+
+	stale := httptest.NewRequest(http.MethodGet, "/api/federation/profiles", nil)
+	signRequest(stale, homePeer.Secret, time.Now().Add(-time.Hour))
+	if _, err := home.fed.Authenticate(stale); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized for stale timestamp, got %v", err)
+	}
+}
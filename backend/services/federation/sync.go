@@ -0,0 +1,337 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+// SharedProfiles returns the profiles a peer may sync.
+func (s *Service) SharedProfiles(peer models.FederationPeer) []models.FederationProfile {
+	users := s.stores.Profiles.ListForAccount(peer.AccountID)
+	profiles := make([]models.FederationProfile, 0, len(users))
+	for _, user := range users {
+		profiles = append(profiles, models.FederationProfile{
+			ID:            user.ID,
+			Name:          user.Name,
+			Color:         user.Color,
+			IsKidsProfile: user.IsKidsProfile,
+		})
+	}
+	return profiles
+}
+
+// Changes returns a shared profile's changes since cursor. A zero cursor, or
+// one older than the retained journal, returns a full snapshot.
+func (s *Service) Changes(peer models.FederationPeer, profileID string, since int64) (*models.FederationDelta, error) {
+	user, ok := s.stores.Profiles.Get(strings.TrimSpace(profileID))
+	if !ok || user.AccountID != peer.AccountID {
+		return nil, ErrProfileNotShared
+	}
+
+	var (
+		changes []models.SyncChange
+		cursor  int64
+		reset   bool
+	)
+	if since > 0 {
+		changes, cursor, reset = s.stores.Journal.ChangesSince(user.ID, since)
+	} else {
+		cursor, reset = s.stores.Journal.Cursor(), true
+	}
+
+	changed := make(map[string]map[string]bool)
+	delta := &models.FederationDelta{
+		Cursor:    cursor,
+		Reset:     reset,
+		Watchlist: make([]models.WatchlistItem, 0),
+		History:   make([]models.WatchHistoryItem, 0),
+		Progress:  make([]models.PlaybackProgress, 0),
+		Deletes:   make([]models.SyncChange, 0),
+	}
+	for _, change := range changes {
+		if change.Op == models.SyncOpDelete {
+			delta.Deletes = append(delta.Deletes, change)
+			continue
+		}
+		if changed[change.Collection] == nil {
+			changed[change.Collection] = make(map[string]bool)
+		}
+		changed[change.Collection][change.Key] = true
+	}
+	include := func(collection, key string) bool {
+		return reset || changed[collection][key]
+	}
+
+	watchlist, err := s.stores.Watchlist.List(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range watchlist {
+		if include(models.SyncCollectionWatchlist, item.Key()) {
+			delta.Watchlist = append(delta.Watchlist, item)
+		}
+	}
+
+	history, err := s.stores.History.ListWatchHistory(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range history {
+		if include(models.SyncCollectionHistory, item.ID) {
+			delta.History = append(delta.History, item)
+		}
+	}
+
+	progress, err := s.stores.History.ListPlaybackProgress(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range progress {
+		if include(models.SyncCollectionProgress, item.ID) {
+			delta.Progress = append(delta.Progress, item)
+		}
+	}
+
+	return delta, nil
+}
+
+// SyncPeer pulls a peer's profiles and their changes since the last pull,
+// creating local profiles as needed and keeping whichever side changed each
+// item last.
+func (s *Service) SyncPeer(ctx context.Context, peerID string) (models.FederationSyncResult, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.mu.RLock()
+	stored, ok := s.peers[strings.TrimSpace(peerID)]
+	var peer models.FederationPeer
+	if ok {
+		peer = *stored
+		peer.ProfileLinks = copyLinks(stored.ProfileLinks)
+		peer.Cursors = copyCursors(stored.Cursors)
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return models.FederationSyncResult{}, ErrPeerNotFound
+	}
+	if !peer.Enabled {
+		return models.FederationSyncResult{}, ErrPeerDisabled
+	}
+
+	result := models.FederationSyncResult{PeerID: peer.ID, StartedAt: s.now().UTC()}
+	err := s.pull(ctx, &peer, &result)
+	result.FinishedAt = s.now().UTC()
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	if current, ok := s.peers[peer.ID]; ok && current.URL == peer.URL && current.AccountID == peer.AccountID {
+		current.ProfileLinks = peer.ProfileLinks
+		current.Cursors = peer.Cursors
+		current.LastSyncAt = result.FinishedAt
+		current.LastError = result.Error
+		if saveErr := s.saveLocked(); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("[federation] synced peer %s: %d profiles, %d applied, %d skipped", peer.Name, result.Profiles, result.Applied, result.Skipped)
+	return result, err
+}
+
+func (s *Service) pull(ctx context.Context, peer *models.FederationPeer, result *models.FederationSyncResult) error {
+	var remoteProfiles []models.FederationProfile
+	if err := s.fetch(ctx, *peer, "/api/federation/profiles", &remoteProfiles); err != nil {
+		return err
+	}
+	if peer.ProfileLinks == nil {
+		peer.ProfileLinks = make(map[string]string)
+	}
+	if peer.Cursors == nil {
+		peer.Cursors = make(map[string]int64)
+	}
+
+	for _, remote := range remoteProfiles {
+		localID, err := s.linkProfile(peer, remote)
+		if err != nil {
+			return fmt.Errorf("link profile %q: %w", remote.Name, err)
+		}
+
+		path := "/api/federation/profiles/" + url.PathEscape(remote.ID) + "/changes?since=" + strconv.FormatInt(peer.Cursors[remote.ID], 10)
+		var delta models.FederationDelta
+		if err := s.fetch(ctx, *peer, path, &delta); err != nil {
+			return fmt.Errorf("fetch changes for %q: %w", remote.Name, err)
+		}
+
+		applied, skipped, err := s.applyDelta(localID, delta)
+		result.Applied += applied
+		result.Skipped += skipped
+		if err != nil {
+			return fmt.Errorf("merge changes for %q: %w", remote.Name, err)
+		}
+		peer.Cursors[remote.ID] = delta.Cursor
+		result.Profiles++
+	}
+	return nil
+}
+
+// linkProfile returns the local profile a remote one syncs into: the one it
+// was linked to before, else an unlinked profile with the same name, else a
+// new profile.
+func (s *Service) linkProfile(peer *models.FederationPeer, remote models.FederationProfile) (string, error) {
+	if localID, ok := peer.ProfileLinks[remote.ID]; ok {
+		if user, exists := s.stores.Profiles.Get(localID); exists && user.AccountID == peer.AccountID {
+			return localID, nil
+		}
+		// The local profile was deleted or moved; relink and resync from scratch
+		delete(peer.Cursors, remote.ID)
+	}
+
+	linked := make(map[string]bool, len(peer.ProfileLinks))
+	for remoteID, localID := range peer.ProfileLinks {
+		if remoteID != remote.ID {
+			linked[localID] = true
+		}
+	}
+	for _, user := range s.stores.Profiles.ListForAccount(peer.AccountID) {
+		if !linked[user.ID] && strings.EqualFold(strings.TrimSpace(user.Name), strings.TrimSpace(remote.Name)) {
+			peer.ProfileLinks[remote.ID] = user.ID
+			return user.ID, nil
+		}
+	}
+
+	user, err := s.stores.Profiles.CreateForAccount(peer.AccountID, remote.Name)
+	if err != nil {
+		return "", err
+	}
+	if remote.IsKidsProfile {
+		if _, err := s.stores.Profiles.SetKidsProfile(user.ID, true); err != nil {
+			return "", err
+		}
+	}
+	log.Printf("[federation] created profile %q from peer %s", remote.Name, peer.Name)
+	peer.ProfileLinks[remote.ID] = user.ID
+	return user.ID, nil
+}
+
+// applyDelta merges a remote profile's changes into a local profile. Remote
+// changes older than the local item, or than a local deletion of it, are
+// skipped.
+func (s *Service) applyDelta(localID string, delta models.FederationDelta) (applied, skipped int, err error) {
+	count := func(ok bool) {
+		if ok {
+			applied++
+		} else {
+			skipped++
+		}
+	}
+
+	for _, item := range delta.Watchlist {
+		if s.deletedLocallyAfter(localID, models.SyncCollectionWatchlist, item.Key(), item.AddedAt) {
+			skipped++
+			continue
+		}
+		ok, err := s.stores.Watchlist.Merge(localID, item)
+		if err != nil {
+			return applied, skipped, err
+		}
+		count(ok)
+	}
+	for _, item := range delta.History {
+		ok, err := s.stores.History.MergeWatchHistoryItem(localID, item)
+		if err != nil {
+			return applied, skipped, err
+		}
+		count(ok)
+	}
+	for _, item := range delta.Progress {
+		if s.deletedLocallyAfter(localID, models.SyncCollectionProgress, item.ID, item.UpdatedAt) {
+			skipped++
+			continue
+		}
+		ok, err := s.stores.History.MergePlaybackProgress(localID, item)
+		if err != nil {
+			return applied, skipped, err
+		}
+		count(ok)
+	}
+	for _, change := range delta.Deletes {
+		var ok bool
+		switch change.Collection {
+		case models.SyncCollectionWatchlist:
+			ok, err = s.stores.Watchlist.RemoveBefore(localID, change.Key, change.ChangedAt)
+		case models.SyncCollectionProgress:
+			ok, err = s.stores.History.DeletePlaybackProgressBefore(localID, change.Key, change.ChangedAt)
+		default:
+			continue
+		}
+		if err != nil {
+			return applied, skipped, err
+		}
+		count(ok)
+	}
+	return applied, skipped, nil
+}
+
+// deletedLocallyAfter reports whether the local profile removed an item after
+// the remote copy was last changed.
+func (s *Service) deletedLocallyAfter(localID, collection, key string, changedAt time.Time) bool {
+	change, ok := s.stores.Journal.LastChange(localID, collection, key)
+	return ok && change.Op == models.SyncOpDelete && change.ChangedAt.After(changedAt)
+}
+
+// fetch performs a signed GET against a peer and decodes its JSON response.
+func (s *Service) fetch(ctx context.Context, peer models.FederationPeer, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	signRequest(req, peer.Secret, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("peer returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func copyLinks(links map[string]string) map[string]string {
+	if links == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(links))
+	for k, v := range links {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyCursors(cursors map[string]int64) map[string]int64 {
+	if cursors == nil {
+		return nil
+	}
+	copied := make(map[string]int64, len(cursors))
+	for k, v := range cursors {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,267 @@
+// Package hidden_titles stores the titles each profile marked "not
+// interested" so they can be left out of that profile's discovery rows.
+package hidden_titles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrProfileIDRequired  = errors.New("profile id is required")
+	ErrTitleIDRequired    = errors.New("titleId is required")
+	ErrInvalidMediaType   = errors.New("mediaType must be movie or series")
+	ErrTitleNotHidden     = errors.New("title is not hidden")
+	ErrTooManyHidden      = errors.New("too many hidden titles for this profile")
+)
+
+// maxHiddenPerProfile bounds how many titles one profile can hide.
+const maxHiddenPerProfile = 2000
+
+// Service persists hidden titles per profile, backed by a JSON file on disk.
+type Service struct {
+	mu     sync.RWMutex
+	path   string
+	hidden map[string][]models.HiddenTitle // profileID -> titles
+	now    func() time.Time
+}
+
+// NewService constructs a hidden titles service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create hidden titles dir: %w", err)
+	}
+
+	svc := &Service{
+		path:   filepath.Join(storageDir, "hidden_titles.json"),
+		hidden: make(map[string][]models.HiddenTitle),
+		now:    time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// List returns a profile's hidden titles, most recently hidden first.
+func (s *Service) List(profileID string) []models.HiddenTitle {
+	profileID = strings.TrimSpace(profileID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	titles := make([]models.HiddenTitle, len(s.hidden[profileID]))
+	copy(titles, s.hidden[profileID])
+	sort.SliceStable(titles, func(i, j int) bool {
+		return titles[i].HiddenAt.After(titles[j].HiddenAt)
+	})
+	return titles
+}
+
+// Hide marks a title as not interesting to a profile. Hiding a title that is
+// already hidden refreshes its details.
+func (s *Service) Hide(profileID string, req models.HiddenTitleRequest) (*models.HiddenTitle, error) {
+	profileID = strings.TrimSpace(profileID)
+	if profileID == "" {
+		return nil, ErrProfileIDRequired
+	}
+	title := models.HiddenTitle{
+		ProfileID: profileID,
+		TitleID:   strings.TrimSpace(req.TitleID),
+		MediaType: strings.ToLower(strings.TrimSpace(req.MediaType)),
+		Name:      strings.TrimSpace(req.Name),
+		Year:      req.Year,
+		IMDBID:    strings.TrimSpace(req.IMDBID),
+		TMDBID:    req.TMDBID,
+		TVDBID:    req.TVDBID,
+		PosterURL: strings.TrimSpace(req.PosterURL),
+	}
+	if title.TitleID == "" {
+		return nil, ErrTitleIDRequired
+	}
+	if title.MediaType != "movie" && title.MediaType != "series" {
+		return nil, ErrInvalidMediaType
+	}
+	fillIDsFromTitleID(&title)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.hidden[profileID]
+	title.HiddenAt = s.now().UTC()
+	index := indexOf(existing, title.TitleID)
+	if index < 0 && len(existing) >= maxHiddenPerProfile {
+		return nil, ErrTooManyHidden
+	}
+
+	updated := make([]models.HiddenTitle, len(existing), len(existing)+1)
+	copy(updated, existing)
+	if index >= 0 {
+		updated[index] = title
+	} else {
+		updated = append(updated, title)
+	}
+	s.hidden[profileID] = updated
+
+	if err := s.saveLocked(); err != nil {
+		s.hidden[profileID] = existing
+		return nil, err
+	}
+	log.Printf("[hidden_titles] profile=%s hid %s %q", profileID, title.TitleID, title.Name)
+
+	hidden := title
+	return &hidden, nil
+}
+
+// Unhide returns a title to a profile's discovery rows.
+func (s *Service) Unhide(profileID, titleID string) error {
+	profileID = strings.TrimSpace(profileID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.hidden[profileID]
+	index := indexOf(existing, strings.TrimSpace(titleID))
+	if index < 0 {
+		return ErrTitleNotHidden
+	}
+
+	updated := make([]models.HiddenTitle, 0, len(existing)-1)
+	updated = append(updated, existing[:index]...)
+	updated = append(updated, existing[index+1:]...)
+	if len(updated) == 0 {
+		delete(s.hidden, profileID)
+	} else {
+		s.hidden[profileID] = updated
+	}
+
+	if err := s.saveLocked(); err != nil {
+		s.hidden[profileID] = existing
+		return err
+	}
+	log.Printf("[hidden_titles] profile=%s unhid %s", profileID, titleID)
+	return nil
+}
+
+// Matcher returns a func reporting whether a title is hidden for a profile,
+// or nil if the profile hasn't hidden anything.
+func (s *Service) Matcher(profileID string) func(models.Title) bool {
+	profileID = strings.TrimSpace(profileID)
+	if profileID == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	// Entries are never modified in place, so the slice can be used unlocked
+	hidden := s.hidden[profileID]
+	s.mu.RUnlock()
+
+	if len(hidden) == 0 {
+		return nil
+	}
+	return func(title models.Title) bool {
+		for _, h := range hidden {
+			if h.Matches(title) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func indexOf(titles []models.HiddenTitle, titleID string) int {
+	for i, title := range titles {
+		if strings.EqualFold(title.TitleID, titleID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fillIDsFromTitleID fills external IDs encoded in title IDs such as
+// "tmdb:movie:123" or "tvdb:series:456" when the client didn't send them.
+func fillIDsFromTitleID(title *models.HiddenTitle) {
+	parts := strings.Split(strings.ToLower(title.TitleID), ":")
+	if len(parts) < 2 {
+		return
+	}
+	id, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil || id <= 0 {
+		return
+	}
+	switch parts[0] {
+	case "tmdb":
+		if title.TMDBID == 0 {
+			title.TMDBID = id
+		}
+	case "tvdb":
+		if title.TVDBID == 0 {
+			title.TVDBID = id
+		}
+	}
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open hidden titles: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read hidden titles: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &s.hidden); err != nil {
+		return fmt.Errorf("decode hidden titles: %w", err)
+	}
+	if s.hidden == nil {
+		s.hidden = make(map[string][]models.HiddenTitle)
+	}
+
+	log.Printf("[hidden_titles] loaded hidden titles for %d profiles", len(s.hidden))
+	return nil
+}
+
+// saveLocked writes the hidden titles to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.hidden, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode hidden titles: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write hidden titles: %w", err)
+	}
+	return nil
+}
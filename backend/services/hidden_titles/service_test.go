@@ -0,0 +1,96 @@
+package hidden_titles
+
+import (
+	"errors"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestHideValidates(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	cases := []struct {
+		profileID string
+		req       models.HiddenTitleRequest
+		want      error
+	}{
+		{"", models.HiddenTitleRequest{TitleID: "tmdb:movie:1", MediaType: "movie"}, ErrProfileIDRequired},
+		{"p1", models.HiddenTitleRequest{MediaType: "movie"}, ErrTitleIDRequired},
+		{"p1", models.HiddenTitleRequest{TitleID: "tmdb:movie:1", MediaType: "episode"}, ErrInvalidMediaType},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Hide(tc.profileID, tc.req); !errors.Is(err, tc.want) {
+			t.Errorf("Hide(%q, %+v) error = %v, want %v", tc.profileID, tc.req, err, tc.want)
+		}
+	}
+}
+
+func TestMatcherUsesAnySharedID(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if svc.Matcher("p1") != nil {
+		t.Fatal("expected nil matcher for a profile with nothing hidden")
+	}
+
+	if _, err := svc.Hide("p1", models.HiddenTitleRequest{TitleID: "tmdb:movie:603", MediaType: "Movie", IMDBID: "tt0133093"}); err != nil {
+		t.Fatalf("Hide() error = %v", err)
+	}
+
+	hidden := svc.Matcher("p1")
+	cases := []struct {
+		title models.Title
+		want  bool
+	}{
+		{models.Title{ID: "tmdb:movie:603", MediaType: "movie"}, true},
+		{models.Title{ID: "tvdb:movie:169", MediaType: "movie", TMDBID: 603}, true},
+		{models.Title{ID: "mdblist:1", MediaType: "movie", IMDBID: "tt0133093"}, true},
+		{models.Title{ID: "tmdb:tv:603", MediaType: "series", TMDBID: 603}, false},
+		{models.Title{ID: "tmdb:movie:604", MediaType: "movie", TMDBID: 604}, false},
+	}
+	for _, tc := range cases {
+		if got := hidden(tc.title); got != tc.want {
+			t.Errorf("matcher(%+v) = %v, want %v", tc.title, got, tc.want)
+		}
+	}
+	if svc.Matcher("p2") != nil {
+		t.Error("hidden title leaked to another profile")
+	}
+}
+
+func TestUnhideAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	for _, id := range []string{"tvdb:series:1", "tvdb:series:2"} {
+		if _, err := svc.Hide("p1", models.HiddenTitleRequest{TitleID: id, MediaType: "series"}); err != nil {
+			t.Fatalf("Hide(%s) error = %v", id, err)
+		}
+	}
+	// Hiding again updates rather than duplicates
+	if _, err := svc.Hide("p1", models.HiddenTitleRequest{TitleID: "tvdb:series:1", MediaType: "series", Name: "Show"}); err != nil {
+		t.Fatalf("Hide() again error = %v", err)
+	}
+	if err := svc.Unhide("p1", "tvdb:series:2"); err != nil {
+		t.Fatalf("Unhide() error = %v", err)
+	}
+	if err := svc.Unhide("p1", "tvdb:series:2"); !errors.Is(err, ErrTitleNotHidden) {
+		t.Errorf("second Unhide() error = %v, want ErrTitleNotHidden", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	titles := reloaded.List("p1")
+	if len(titles) != 1 || titles[0].Name != "Show" || titles[0].TVDBID != 1 {
+		t.Fatalf("List() after reload = %+v", titles)
+	}
+}
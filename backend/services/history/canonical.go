@@ -0,0 +1,246 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+
+	"novastream/models"
+)
+
+// canonicalProgressKey returns a release-independent identity for a playback
+// progress entry: the parent series plus season/episode for episodes, and the
+// title's external IDs (or name+year) for movies. Re-resolving the same title
+// from a different release changes the item ID but not this key, so it is used
+// to collapse duplicate continue-watching entries. An empty key means the entry
+// can't be identified well enough to merge and is left untouched.
+func canonicalProgressKey(p models.PlaybackProgress) string {
+	isEpisode := p.MediaType == "episode" || (p.SeasonNumber > 0 && p.EpisodeNumber > 0)
+	if isEpisode {
+		if p.SeasonNumber <= 0 && p.EpisodeNumber <= 0 {
+			return ""
+		}
+		seriesID := strings.ToLower(strings.TrimSpace(p.SeriesID))
+		if seriesID == "" {
+			seriesID = inferSeriesIDFromItemID(p.ItemID)
+		}
+		if seriesID == "" {
+			return ""
+		}
+		return fmt.Sprintf("episode:%s:s%02de%02d", seriesID, p.SeasonNumber, p.EpisodeNumber)
+	}
+
+	if p.MediaType != "movie" {
+		return ""
+	}
+
+	for _, source := range []string{"tmdb", "imdb", "tvdb"} {
+		if id := strings.ToLower(strings.TrimSpace(p.ExternalIDs[source])); id != "" {
+			return "movie:" + source + ":" + id
+		}
+	}
+
+	name := strings.ToLower(strings.Join(strings.Fields(p.MovieName), " "))
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("movie:name:%s:%d", name, p.Year)
+}
+
+// inferSeriesIDFromItemID extracts the series portion of an episode item ID
+// such as "tvdb:series:12345:S01E02".
+func inferSeriesIDFromItemID(itemID string) string {
+	parts := strings.Split(strings.ToLower(itemID), ":")
+	for i := len(parts) - 1; i > 0; i-- {
+		if strings.HasPrefix(parts[i], "s") && len(parts[i]) > 1 && strings.Contains(parts[i], "e") {
+			return strings.Join(parts[:i], ":")
+		}
+	}
+	return ""
+}
+
+// dedupeProgressLocked collapses entries that share a canonical key, keeping the
+// most recently updated one. Returns the number of entries removed.
+// Callers must hold s.mu before invoking this helper.
+func dedupeProgressLocked(perUser map[string]models.PlaybackProgress) int {
+	newest := make(map[string]string, len(perUser))
+	removed := 0
+	for key, item := range perUser {
+		canonical := canonicalProgressKey(item)
+		if canonical == "" {
+			continue
+		}
+		existingKey, exists := newest[canonical]
+		if !exists {
+			newest[canonical] = key
+			continue
+		}
+		existing := perUser[existingKey]
+		if item.UpdatedAt.After(existing.UpdatedAt) {
+			delete(perUser, existingKey)
+			newest[canonical] = key
+		} else {
+			delete(perUser, key)
+		}
+		removed++
+	}
+	return removed
+}
+
+// removeCanonicalDuplicatesLocked drops entries representing the same title as
+// keep but stored under a different key (e.g. an older release path) and returns
+// the removed keys.
+// Callers must hold s.mu before invoking this helper.
+func removeCanonicalDuplicatesLocked(perUser map[string]models.PlaybackProgress, keepKey string, keep models.PlaybackProgress) []string {
+	canonical := canonicalProgressKey(keep)
+	if canonical == "" {
+		return nil
+	}
+	var removed []string
+	for key, item := range perUser {
+		if key == keepKey {
+			continue
+		}
+		if canonicalProgressKey(item) == canonical {
+			delete(perUser, key)
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// collectVersionProgressLocked gathers the per-version positions stored for the
+// same title as progress, whether under keepKey or a canonical duplicate that is
+// about to be replaced. The newest position wins when a version appears twice.
+// Callers must hold s.mu before invoking this helper.
+func collectVersionProgressLocked(perUser map[string]models.PlaybackProgress, keepKey string, progress models.PlaybackProgress) map[string]models.VersionProgress {
+	canonical := canonicalProgressKey(progress)
+	var versions map[string]models.VersionProgress
+	for key, item := range perUser {
+		if key != keepKey && (canonical == "" || canonicalProgressKey(item) != canonical) {
+			continue
+		}
+		for versionID, versionProgress := range item.Versions {
+			if versions == nil {
+				versions = make(map[string]models.VersionProgress)
+			}
+			if current, ok := versions[versionID]; !ok || versionProgress.UpdatedAt.After(current.UpdatedAt) {
+				versions[versionID] = versionProgress
+			}
+		}
+	}
+	return versions
+}
+
+// canonicalWatchKey is the release-independent identity of a watch history
+// item, matching canonicalProgressKey. Series-level items have no canonical
+// key and are never merged.
+func canonicalWatchKey(item models.WatchHistoryItem) string {
+	return canonicalProgressKey(models.PlaybackProgress{
+		MediaType:     item.MediaType,
+		ItemID:        item.ItemID,
+		SeriesID:      item.SeriesID,
+		SeasonNumber:  item.SeasonNumber,
+		EpisodeNumber: item.EpisodeNumber,
+		ExternalIDs:   item.ExternalIDs,
+		MovieName:     item.Name,
+		Year:          item.Year,
+	})
+}
+
+// findWatchDuplicateLocked returns the key and entry of an item stored under
+// another key than key that represents the same title as item.
+// Callers must hold s.mu before invoking this helper.
+func findWatchDuplicateLocked(perUser map[string]models.WatchHistoryItem, key string, item models.WatchHistoryItem) (string, models.WatchHistoryItem, bool) {
+	canonical := canonicalWatchKey(item)
+	if canonical == "" {
+		return "", models.WatchHistoryItem{}, false
+	}
+	for existingKey, existing := range perUser {
+		if existingKey != key && canonicalWatchKey(existing) == canonical {
+			return existingKey, existing, true
+		}
+	}
+	return "", models.WatchHistoryItem{}, false
+}
+
+// mergeWatchDuplicatesLocked folds entries for the same title as item stored
+// under other keys (e.g. a different release or ID scheme) into item and
+// removes them. The most recently changed watched state wins; missing
+// metadata and external IDs are filled in from the duplicates. Returns the
+// merged item and the removed entries.
+// Callers must hold s.mu before invoking this helper.
+func mergeWatchDuplicatesLocked(perUser map[string]models.WatchHistoryItem, key string, item models.WatchHistoryItem) (models.WatchHistoryItem, []models.WatchHistoryItem) {
+	canonical := canonicalWatchKey(item)
+	if canonical == "" {
+		return item, nil
+	}
+
+	var removed []models.WatchHistoryItem
+	for existingKey, existing := range perUser {
+		if existingKey == key || canonicalWatchKey(existing) != canonical {
+			continue
+		}
+		item = mergeWatchItems(item, existing)
+		delete(perUser, existingKey)
+		removed = append(removed, existing)
+	}
+	return item, removed
+}
+
+// mergeWatchItems merges duplicate into item, keeping item's identity.
+func mergeWatchItems(item, duplicate models.WatchHistoryItem) models.WatchHistoryItem {
+	if duplicate.LastModified().After(item.LastModified()) {
+		item.Watched = duplicate.Watched
+		item.WatchedAt = duplicate.WatchedAt
+		item.WatchedWith = duplicate.WatchedWith
+		item.UpdatedAt = duplicate.UpdatedAt
+	}
+	if item.Name == "" {
+		item.Name = duplicate.Name
+	}
+	if item.Year == 0 {
+		item.Year = duplicate.Year
+	}
+	if item.SeriesID == "" {
+		item.SeriesID = duplicate.SeriesID
+	}
+	if item.SeriesName == "" {
+		item.SeriesName = duplicate.SeriesName
+	}
+	if len(duplicate.ExternalIDs) > 0 {
+		ids := make(map[string]string, len(item.ExternalIDs)+len(duplicate.ExternalIDs))
+		for source, id := range duplicate.ExternalIDs {
+			ids[source] = id
+		}
+		for source, id := range item.ExternalIDs {
+			ids[source] = id
+		}
+		item.ExternalIDs = ids
+	}
+	return item
+}
+
+// dedupeWatchHistoryLocked merges entries that share a canonical key into the
+// most recently changed one. Returns the number of entries removed.
+// Callers must hold s.mu before invoking this helper.
+func dedupeWatchHistoryLocked(perUser map[string]models.WatchHistoryItem) int {
+	removed := 0
+	for key := range perUser {
+		item, ok := perUser[key]
+		if !ok {
+			continue // Merged into an earlier entry
+		}
+		duplicateKey, duplicate, ok := findWatchDuplicateLocked(perUser, key, item)
+		if !ok {
+			continue
+		}
+		keepKey := key
+		if duplicate.LastModified().After(item.LastModified()) {
+			keepKey, item = duplicateKey, duplicate
+		}
+		merged, dropped := mergeWatchDuplicatesLocked(perUser, keepKey, item)
+		perUser[keepKey] = merged
+		removed += len(dropped)
+	}
+	return removed
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+// coViewerIdleTTL is how long a set of co-viewers stays active without any
+// playback activity from the primary profile. It covers a long movie paused
+// overnight but stops a forgotten group from crediting the next day's viewing.
+const coViewerIdleTTL = 6 * time.Hour
+
+// coViewerGroup lists the other profiles watching along with a primary profile.
+type coViewerGroup struct {
+	viewers   []string
+	expiresAt time.Time
+}
+
+// SetCoViewers records the profiles watching together with userID. Until they
+// are cleared or go idle, playback started and progress reported by userID is
+// credited to every co-viewer's history and continue watching as well. An
+// empty list clears the group. Returns the normalised co-viewer IDs.
+func (s *Service) SetCoViewers(userID string, viewerIDs []string) ([]string, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	viewers := normaliseCoViewers(userID, viewerIDs)
+
+	s.coViewersMu.Lock()
+	defer s.coViewersMu.Unlock()
+	if len(viewers) == 0 {
+		delete(s.coViewers, userID)
+		return []string{}, nil
+	}
+	s.coViewers[userID] = &coViewerGroup{
+		viewers:   viewers,
+		expiresAt: time.Now().Add(coViewerIdleTTL),
+	}
+	log.Printf("[history] user %s watching together with %v", userID, viewers)
+	return append([]string(nil), viewers...), nil
+}
+
+// CoViewers returns the profiles currently watching together with userID.
+func (s *Service) CoViewers(userID string) ([]string, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+	return s.activeCoViewers(userID, false), nil
+}
+
+// activeCoViewers returns the live co-viewers of userID, dropping the group
+// once it has gone idle. Playback activity passes touch to keep it alive.
+func (s *Service) activeCoViewers(userID string, touch bool) []string {
+	s.coViewersMu.Lock()
+	defer s.coViewersMu.Unlock()
+
+	group, ok := s.coViewers[userID]
+	if !ok {
+		return []string{}
+	}
+	now := time.Now()
+	if now.After(group.expiresAt) {
+		delete(s.coViewers, userID)
+		return []string{}
+	}
+	if touch {
+		group.expiresAt = now.Add(coViewerIdleTTL)
+	}
+	return append([]string(nil), group.viewers...)
+}
+
+// normaliseCoViewers trims, de-duplicates and sorts viewer IDs, dropping the
+// primary profile itself.
+func normaliseCoViewers(userID string, viewerIDs []string) []string {
+	seen := make(map[string]bool, len(viewerIDs))
+	viewers := make([]string, 0, len(viewerIDs))
+	for _, id := range viewerIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || id == userID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		viewers = append(viewers, id)
+	}
+	sort.Strings(viewers)
+	return viewers
+}
+
+// watchedWith returns the other members of a watch-together group from the
+// point of view of member: everyone in the group except member.
+func watchedWith(member string, group []string) []string {
+	var others []string
+	for _, id := range group {
+		if id != member {
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+// recordEpisodeForCoViewers credits a started episode to each co-viewer.
+func (s *Service) recordEpisodeForCoViewers(userID string, update models.WatchHistoryUpdate, group []string) {
+	for _, viewer := range group {
+		if viewer == userID {
+			continue
+		}
+		if _, err := s.updateWatchHistory(viewer, update, watchedWith(viewer, group)); err != nil {
+			log.Printf("[history] failed to record episode %s for co-viewer %s of %s: %v", update.ItemID, viewer, userID, err)
+		}
+	}
+}
+
+// updateProgressForCoViewers mirrors a progress update to each co-viewer.
+func (s *Service) updateProgressForCoViewers(userID string, update models.PlaybackProgressUpdate, group []string) {
+	for _, viewer := range group {
+		if viewer == userID {
+			continue
+		}
+		if _, err := s.updatePlaybackProgress(viewer, update, watchedWith(viewer, group)); err != nil {
+			log.Printf("[history] failed to update progress %s for co-viewer %s of %s: %v", update.ItemID, viewer, userID, err)
+		}
+	}
+}
+
+// watchGroup returns userID plus its active co-viewers, or nil when userID
+// is watching alone.
+func (s *Service) watchGroup(userID string) []string {
+	viewers := s.activeCoViewers(userID, true)
+	if len(viewers) == 0 {
+		return nil
+	}
+	return append([]string{userID}, viewers...)
+}
@@ -0,0 +1,115 @@
+package history
+
+import (
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+// MergeWatchHistoryItem stores a watch history item from another server when
+// it is newer than the local copy, keeping its timestamps. Returns whether it
+// was applied. Merged items are not scrobbled; the origin already did.
+func (s *Service) MergeWatchHistoryItem(userID string, item models.WatchHistoryItem) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+
+	item.ItemID = strings.ToLower(item.ItemID)
+	item.MediaType = strings.ToLower(item.MediaType)
+	key := makeWatchKey(item.MediaType, item.ItemID)
+	item.ID = key
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensureWatchHistoryUserLocked(userID)
+	if existing, ok := perUser[key]; ok && !item.LastModified().After(existing.LastModified()) {
+		return false, nil
+	}
+
+	perUser[key] = item
+	s.recordChangeLocked(userID, models.SyncCollectionHistory, key, models.SyncOpUpsert)
+
+	progressCleared := s.clearPlaybackProgressEntryLocked(userID, item.MediaType, item.ItemID)
+	if err := s.saveWatchHistoryLocked(); err != nil {
+		return false, err
+	}
+	if progressCleared {
+		if err := s.savePlaybackProgressLocked(); err != nil {
+			return false, err
+		}
+	}
+	delete(s.continueWatchingCache, userID)
+	return true, nil
+}
+
+// MergePlaybackProgress stores playback progress from another server when it
+// is newer than the local copy. Returns whether it was applied.
+func (s *Service) MergePlaybackProgress(userID string, progress models.PlaybackProgress) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+
+	progress.MediaType = strings.ToLower(progress.MediaType)
+	key := makeWatchKey(progress.MediaType, progress.ItemID)
+	progress.ID = key
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensurePlaybackProgressUserLocked(userID)
+	if existing, ok := perUser[key]; ok && !progress.UpdatedAt.After(existing.UpdatedAt) {
+		return false, nil
+	}
+	// Finished locally after this position was reached elsewhere
+	if watched, ok := s.watchHistory[userID][key]; ok && watched.Watched && !progress.UpdatedAt.After(watched.LastModified()) {
+		return false, nil
+	}
+
+	perUser[key] = progress
+	s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
+	if removed := removeCanonicalDuplicatesLocked(perUser, key, progress); len(removed) > 0 {
+		for _, removedKey := range removed {
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, removedKey, models.SyncOpDelete)
+		}
+	}
+
+	if err := s.savePlaybackProgressLocked(); err != nil {
+		return false, err
+	}
+	delete(s.continueWatchingCache, userID)
+	return true, nil
+}
+
+// DeletePlaybackProgressBefore removes playback progress deleted on another
+// server at deletedAt, unless it has been updated locally since. Returns
+// whether it was removed.
+func (s *Service) DeletePlaybackProgressBefore(userID, key string, deletedAt time.Time) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser, ok := s.playbackProgress[userID]
+	if !ok {
+		return false, nil
+	}
+	existing, ok := perUser[key]
+	if !ok || existing.UpdatedAt.After(deletedAt) {
+		return false, nil
+	}
+
+	delete(perUser, key)
+	s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpDelete)
+	if err := s.savePlaybackProgressLocked(); err != nil {
+		return false, err
+	}
+	delete(s.continueWatchingCache, userID)
+	return true, nil
+}
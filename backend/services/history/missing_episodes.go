@@ -0,0 +1,270 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+// watchedSeries collects what the user has watched of one series.
+type watchedSeries struct {
+	info          models.WatchHistoryItem
+	watched       map[string]bool    // episodeKey -> watched
+	progress      map[string]float64 // episodeKey -> percent watched for unfinished episodes
+	lastWatchedAt time.Time
+}
+
+// ListMissingEpisodes reports the aired episodes the user has not watched for
+// every series they have watched or started an episode of. Fully watched series
+// are omitted. Results are sorted by most recent activity so the top entries
+// are the best candidates for prefetching.
+func (s *Service) ListMissingEpisodes(userID string) ([]models.SeriesMissingEpisodes, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	s.mu.RLock()
+	metadataSvc := s.metadataService
+	s.mu.RUnlock()
+
+	if metadataSvc == nil {
+		// Metadata service not available, return empty list
+		return []models.SeriesMissingEpisodes{}, nil
+	}
+
+	series, err := s.collectWatchedSeries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// Use a semaphore to limit concurrent metadata requests
+	const maxConcurrent = 5
+	sem := make(chan struct{}, maxConcurrent)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	reports := []models.SeriesMissingEpisodes{}
+	for seriesID, ws := range series {
+		wg.Add(1)
+		go func(seriesID string, ws *watchedSeries) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			details, err := s.getSeriesMetadataWithCache(ctx, seriesID, ws.info.SeriesName, ws.info.ExternalIDs)
+			if err != nil || details == nil {
+				log.Printf("[history] missing episodes: skipping seriesId=%s, metadata unavailable: %v", seriesID, err)
+				return
+			}
+
+			report := buildMissingEpisodesReport(seriesID, ws, details, now)
+			if report.Complete {
+				return
+			}
+
+			mu.Lock()
+			reports = append(reports, report)
+			mu.Unlock()
+		}(seriesID, ws)
+	}
+	wg.Wait()
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].LastWatchedAt.Equal(reports[j].LastWatchedAt) {
+			return reports[i].SeriesID < reports[j].SeriesID
+		}
+		return reports[i].LastWatchedAt.After(reports[j].LastWatchedAt)
+	})
+
+	return reports, nil
+}
+
+// GetMissingEpisodes reports the aired episodes the user has not watched for a
+// single series. Returns nil if the user has not watched any of it.
+func (s *Service) GetMissingEpisodes(userID, seriesID string) (*models.SeriesMissingEpisodes, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	seriesID = strings.TrimSpace(seriesID)
+	if seriesID == "" {
+		return nil, ErrSeriesIDRequired
+	}
+
+	series, err := s.collectWatchedSeries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, ok := series[seriesID]
+	if !ok {
+		return nil, nil
+	}
+
+	details, err := s.getSeriesMetadataWithCache(context.Background(), seriesID, ws.info.SeriesName, ws.info.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("series metadata: %w", err)
+	}
+	if details == nil {
+		return nil, fmt.Errorf("series metadata not found for %s", seriesID)
+	}
+
+	report := buildMissingEpisodesReport(seriesID, ws, details, time.Now())
+	return &report, nil
+}
+
+// collectWatchedSeries groups the user's watched and in-progress episodes by series.
+func (s *Service) collectWatchedSeries(userID string) (map[string]*watchedSeries, error) {
+	items, err := s.ListWatchHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	progressItems, err := s.ListPlaybackProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[string]*watchedSeries)
+	ensure := func(seriesID string) *watchedSeries {
+		ws, ok := series[seriesID]
+		if !ok {
+			ws = &watchedSeries{
+				watched:  make(map[string]bool),
+				progress: make(map[string]float64),
+			}
+			series[seriesID] = ws
+		}
+		return ws
+	}
+
+	for _, item := range items {
+		if item.MediaType != "episode" || !item.Watched || item.SeriesID == "" {
+			continue
+		}
+		ws := ensure(item.SeriesID)
+		ws.watched[episodeKey(item.SeasonNumber, item.EpisodeNumber)] = true
+		if ws.info.SeriesID == "" {
+			ws.info = item
+		}
+		if item.WatchedAt.After(ws.lastWatchedAt) {
+			ws.lastWatchedAt = item.WatchedAt
+		}
+	}
+
+	for _, prog := range progressItems {
+		if prog.MediaType != "episode" || prog.SeriesID == "" || prog.SeasonNumber <= 0 {
+			continue
+		}
+		ws := ensure(prog.SeriesID)
+		ws.progress[episodeKey(prog.SeasonNumber, prog.EpisodeNumber)] = prog.PercentWatched
+		if ws.info.SeriesID == "" {
+			ws.info = models.WatchHistoryItem{
+				SeriesID:    prog.SeriesID,
+				SeriesName:  prog.SeriesName,
+				ExternalIDs: prog.ExternalIDs,
+				Year:        prog.Year,
+			}
+		}
+		if prog.UpdatedAt.After(ws.lastWatchedAt) {
+			ws.lastWatchedAt = prog.UpdatedAt
+		}
+	}
+
+	return series, nil
+}
+
+// buildMissingEpisodesReport compares the aired episodes in seriesDetails against
+// what the user has watched. Specials (season 0) are ignored.
+func buildMissingEpisodesReport(seriesID string, ws *watchedSeries, seriesDetails *models.SeriesDetails, now time.Time) models.SeriesMissingEpisodes {
+	report := models.SeriesMissingEpisodes{
+		SeriesID:        seriesID,
+		SeriesTitle:     ws.info.SeriesName,
+		Year:            ws.info.Year,
+		ExternalIDs:     ws.info.ExternalIDs,
+		LastWatchedAt:   ws.lastWatchedAt,
+		MissingEpisodes: []models.MissingEpisode{},
+	}
+	if seriesDetails.Title.Name != "" {
+		report.SeriesTitle = seriesDetails.Title.Name
+	}
+	if seriesDetails.Title.Year > 0 {
+		report.Year = seriesDetails.Title.Year
+	}
+	if seriesDetails.Title.Poster != nil {
+		report.PosterURL = seriesDetails.Title.Poster.URL
+	}
+
+	var episodes []models.SeriesEpisode
+	for _, season := range seriesDetails.Seasons {
+		if season.Number == 0 {
+			continue
+		}
+		for _, ep := range season.Episodes {
+			if ep.SeasonNumber == 0 {
+				continue
+			}
+			episodes = append(episodes, ep)
+		}
+	}
+	sort.Slice(episodes, func(i, j int) bool {
+		if episodes[i].SeasonNumber != episodes[j].SeasonNumber {
+			return episodes[i].SeasonNumber < episodes[j].SeasonNumber
+		}
+		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
+	})
+
+	// The furthest watched episode separates skipped episodes (gaps) from ones not reached yet
+	furthest := -1
+	for i, ep := range episodes {
+		if ws.watched[episodeKey(ep.SeasonNumber, ep.EpisodeNumber)] {
+			furthest = i
+		}
+	}
+
+	for i, ep := range episodes {
+		if !episodeAired(ep, now) {
+			report.UpcomingEpisodeCount++
+			if report.NextAirDate == "" || ep.AiredDate < report.NextAirDate {
+				report.NextAirDate = ep.AiredDate
+			}
+			continue
+		}
+
+		report.AiredEpisodeCount++
+		key := episodeKey(ep.SeasonNumber, ep.EpisodeNumber)
+		if ws.watched[key] {
+			report.WatchedEpisodeCount++
+			continue
+		}
+
+		missing := models.MissingEpisode{
+			SeasonNumber:   ep.SeasonNumber,
+			EpisodeNumber:  ep.EpisodeNumber,
+			EpisodeID:      ep.ID,
+			Title:          ep.Name,
+			AirDate:        ep.AiredDate,
+			RuntimeMinutes: ep.Runtime,
+			PercentWatched: ws.progress[key],
+			Gap:            i < furthest,
+		}
+		if missing.Gap {
+			report.GapCount++
+		}
+		report.MissingEpisodes = append(report.MissingEpisodes, missing)
+	}
+
+	report.Complete = len(report.MissingEpisodes) == 0
+	return report
+}
@@ -0,0 +1,361 @@
+package history
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+const (
+	retentionInterval = time.Hour
+	// autoCompleteIdle is how long an item must sit untouched before the
+	// auto-complete rule applies, so a title paused during playback isn't
+	// marked watched from under the viewer
+	autoCompleteIdle = 24 * time.Hour
+	// historyWindow matches the continue watching builder, which ignores
+	// episodes watched longer ago than this
+	historyWindow = 365 * 24 * time.Hour
+)
+
+// RetentionPolicySource provides each profile's continue watching settings:
+// the retention policy and how specials are handled.
+type RetentionPolicySource interface {
+	Get(userID string) (*models.UserSettings, error)
+}
+
+// RetentionResult reports what a pruning pass changed for a profile.
+type RetentionResult struct {
+	AutoCompleted int `json:"autoCompleted"`
+	Archived      int `json:"archived"`
+}
+
+// SetRetentionPolicySource sets where the retention job reads per-profile policies.
+func (s *Service) SetRetentionPolicySource(source RetentionPolicySource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retentionPolicies = source
+}
+
+// StartRetentionJob prunes continue watching for every profile with a
+// retention policy, now and then hourly, until ctx ends or StopRetentionJob.
+func (s *Service) StartRetentionJob(ctx context.Context) {
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+
+	if s.retentionCancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.retentionCancel = cancel
+
+	s.retentionWG.Add(1)
+	go s.retentionLoop(loopCtx)
+}
+
+// StopRetentionJob ends the background pruning loop.
+func (s *Service) StopRetentionJob() {
+	s.retentionMu.Lock()
+	cancel := s.retentionCancel
+	s.retentionCancel = nil
+	s.retentionMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.retentionWG.Wait()
+}
+
+func (s *Service) retentionLoop(ctx context.Context) {
+	defer s.retentionWG.Done()
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		s.pruneAllContinueWatching()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneAllContinueWatching applies each profile's policy.
+func (s *Service) pruneAllContinueWatching() {
+	s.mu.RLock()
+	source := s.retentionPolicies
+	userIDs := make(map[string]struct{}, len(s.playbackProgress)+len(s.watchHistory))
+	for userID := range s.playbackProgress {
+		userIDs[userID] = struct{}{}
+	}
+	for userID := range s.watchHistory {
+		userIDs[userID] = struct{}{}
+	}
+	s.mu.RUnlock()
+
+	if source == nil {
+		return
+	}
+
+	for userID := range userIDs {
+		settings, err := source.Get(userID)
+		if err != nil || settings == nil || !settings.ContinueWatching.Enabled() {
+			continue
+		}
+		result, err := s.PruneContinueWatching(userID, settings.ContinueWatching)
+		if err != nil {
+			log.Printf("[history] continue watching retention failed for user=%s: %v", userID, err)
+			continue
+		}
+		if result.AutoCompleted > 0 || result.Archived > 0 {
+			log.Printf("[history] continue watching retention user=%s: %d auto-completed, %d archived",
+				userID, result.AutoCompleted, result.Archived)
+		}
+	}
+}
+
+// PruneContinueWatching applies a retention policy to a profile's continue
+// watching: items idle at or beyond the auto-complete percentage are marked
+// watched, then items untouched for the expiry period are archived. Archived
+// items leave continue watching until restored or watched again.
+func (s *Service) PruneContinueWatching(userID string, policy models.ContinueWatchingSettings) (RetentionResult, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return RetentionResult{}, ErrUserIDRequired
+	}
+
+	var result RetentionResult
+	now := time.Now().UTC()
+
+	if policy.AutoCompletePercent > 0 {
+		for _, update := range s.autoCompleteCandidates(userID, policy.AutoCompletePercent, now.Add(-autoCompleteIdle)) {
+			if _, err := s.updateWatchHistory(userID, update, nil); err != nil {
+				return result, err
+			}
+			result.AutoCompleted++
+		}
+	}
+
+	if policy.ExpireAfterDays > 0 {
+		cutoff := now.AddDate(0, 0, -policy.ExpireAfterDays)
+		archived, err := s.archiveExpired(userID, cutoff, now)
+		if err != nil {
+			return result, err
+		}
+		result.Archived = archived
+	}
+
+	return result, nil
+}
+
+// autoCompleteCandidates returns watched updates for progress entries at or
+// beyond percent that haven't been touched since idleBefore. The original
+// viewing time is kept so completing an old item doesn't make it recent.
+func (s *Service) autoCompleteCandidates(userID string, percent float64, idleBefore time.Time) []models.WatchHistoryUpdate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var updates []models.WatchHistoryUpdate
+	for _, progress := range s.playbackProgress[userID] {
+		if progress.Duration <= 0 || progress.HiddenFromContinueWatching || progress.ArchivedAt != nil {
+			continue
+		}
+		if progress.PercentWatched < percent || !progress.UpdatedAt.Before(idleBefore) {
+			continue
+		}
+		watched := true
+		update := models.WatchHistoryUpdate{
+			MediaType:     progress.MediaType,
+			ItemID:        progress.ItemID,
+			Watched:       &watched,
+			WatchedAt:     progress.UpdatedAt,
+			ExternalIDs:   progress.ExternalIDs,
+			SeasonNumber:  progress.SeasonNumber,
+			EpisodeNumber: progress.EpisodeNumber,
+			SeriesID:      progress.SeriesID,
+			SeriesName:    progress.SeriesName,
+		}
+		if progress.MediaType == "episode" {
+			update.Name = progress.EpisodeName
+		} else {
+			update.Name = progress.MovieName
+			update.Year = progress.Year
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// archiveExpired archives series and movies whose last activity, across all
+// their progress entries and watched episodes, is before cutoff. Series kept
+// in continue watching only by watched episodes get a marker entry, like
+// hidden series without progress.
+func (s *Service) archiveExpired(userID string, cutoff, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensurePlaybackProgressUserLocked(userID)
+
+	latestWatched := make(map[string]models.WatchHistoryItem)
+	for _, item := range s.watchHistory[userID] {
+		if item.MediaType != "episode" || !item.Watched || item.SeriesID == "" {
+			continue
+		}
+		if latest, ok := latestWatched[item.SeriesID]; !ok || item.WatchedAt.After(latest.WatchedAt) {
+			latestWatched[item.SeriesID] = item
+		}
+	}
+
+	type group struct {
+		keys         []string
+		lastActivity time.Time
+		excluded     bool
+	}
+	groups := make(map[string]*group)
+	for key, progress := range perUser {
+		id := retentionGroupID(progress)
+		g := groups[id]
+		if g == nil {
+			g = &group{lastActivity: latestWatched[id].WatchedAt}
+			groups[id] = g
+		}
+		g.keys = append(g.keys, key)
+		if excludedFromContinueWatching(progress) {
+			g.excluded = true
+		}
+		if progress.UpdatedAt.After(g.lastActivity) {
+			g.lastActivity = progress.UpdatedAt
+		}
+	}
+
+	archived := 0
+	for _, g := range groups {
+		if g.excluded || !g.lastActivity.Before(cutoff) {
+			continue
+		}
+		for _, key := range g.keys {
+			progress := perUser[key]
+			archivedAt := now
+			progress.ArchivedAt = &archivedAt
+			perUser[key] = progress
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
+		}
+		archived++
+	}
+
+	// Series with watched episodes but no progress left. Ones watched before
+	// the builder's window have already dropped out by themselves
+	windowStart := now.Add(-historyWindow)
+	for seriesID, latest := range latestWatched {
+		if _, ok := groups[seriesID]; ok || !latest.WatchedAt.Before(cutoff) || latest.WatchedAt.Before(windowStart) {
+			continue
+		}
+		key := makeWatchKey("episode", seriesID)
+		archivedAt := now
+		perUser[key] = models.PlaybackProgress{
+			ID:          key,
+			MediaType:   "episode",
+			ItemID:      seriesID,
+			SeriesID:    seriesID,
+			SeriesName:  latest.SeriesName,
+			ExternalIDs: latest.ExternalIDs,
+			UpdatedAt:   latest.WatchedAt,
+			ArchivedAt:  &archivedAt,
+		}
+		s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
+		archived++
+	}
+
+	if archived == 0 {
+		return 0, nil
+	}
+	delete(s.continueWatchingCache, userID)
+	return archived, s.savePlaybackProgressLocked()
+}
+
+// retentionGroupID is the series a progress entry belongs to, or the movie itself.
+func retentionGroupID(progress models.PlaybackProgress) string {
+	if progress.SeriesID != "" {
+		return progress.SeriesID
+	}
+	return progress.ItemID
+}
+
+// ListContinueWatchingArchive returns the profile's archived continue watching
+// entries, most recently archived first.
+func (s *Service) ListContinueWatchingArchive(userID string) ([]models.PlaybackProgress, error) {
+	progress, err := s.ListPlaybackProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]models.PlaybackProgress, 0)
+	for _, item := range progress {
+		if item.ArchivedAt != nil {
+			archived = append(archived, item)
+		}
+	}
+	sort.SliceStable(archived, func(i, j int) bool {
+		return archived[i].ArchivedAt.After(*archived[j].ArchivedAt)
+	})
+	return archived, nil
+}
+
+// RestoreToContinueWatching returns an archived series or movie to continue
+// watching. Restoring counts as activity, restarting the expiry period.
+func (s *Service) RestoreToContinueWatching(userID, seriesID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+	seriesID = strings.TrimSpace(seriesID)
+	if seriesID == "" {
+		return ErrSeriesIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.unarchiveLocked(userID, seriesID, time.Now().UTC()) {
+		return nil
+	}
+	delete(s.continueWatchingCache, userID)
+	return s.savePlaybackProgressLocked()
+}
+
+// unarchiveLocked returns a series or movie archived before activity at time
+// at to continue watching, recording at as its latest activity. Callers must
+// hold s.mu.
+func (s *Service) unarchiveLocked(userID, seriesID string, at time.Time) bool {
+	perUser, ok := s.playbackProgress[userID]
+	if !ok {
+		return false
+	}
+
+	changed := false
+	for key, progress := range perUser {
+		if progress.ArchivedAt == nil || !progress.ArchivedAt.Before(at) ||
+			(progress.ItemID != seriesID && progress.SeriesID != seriesID) {
+			continue
+		}
+		progress.ArchivedAt = nil
+		progress.UpdatedAt = at
+		perUser[key] = progress
+		s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
+		changed = true
+	}
+	return changed
+}
+
+// excludedFromContinueWatching reports whether a progress entry was dismissed
+// by the user or archived by the retention policy.
+func excludedFromContinueWatching(progress models.PlaybackProgress) bool {
+	return progress.HiddenFromContinueWatching || progress.ArchivedAt != nil
+}
@@ -0,0 +1,189 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+// SetSeriesWatched marks every aired episode of a series, or of one season,
+// as watched or unwatched in a single history write. Marking watched needs
+// the series metadata to know which episodes exist; unwatching clears the
+// episodes already in history. Episodes already in the requested state are
+// left alone so their watch dates survive and they are not scrobbled again.
+func (s *Service) SetSeriesWatched(ctx context.Context, userID string, update models.SeriesWatchedUpdate) (models.SeriesWatchedResult, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.SeriesWatchedResult{}, ErrUserIDRequired
+	}
+	seriesID := strings.TrimSpace(update.SeriesID)
+	if seriesID == "" {
+		return models.SeriesWatchedResult{}, ErrSeriesIDRequired
+	}
+
+	inScope := func(season int) bool {
+		if update.SeasonNumber != nil {
+			return season == *update.SeasonNumber
+		}
+		return season > 0
+	}
+
+	// Existing history for the series, by episode
+	existing := make(map[string]models.WatchHistoryItem)
+	s.mu.RLock()
+	for _, item := range s.watchHistory[userID] {
+		if item.MediaType == "episode" && item.SeriesID == seriesID && inScope(item.SeasonNumber) {
+			existing[episodeKey(item.SeasonNumber, item.EpisodeNumber)] = item
+		}
+	}
+	s.mu.RUnlock()
+
+	result := models.SeriesWatchedResult{
+		SeriesID:     seriesID,
+		SeasonNumber: update.SeasonNumber,
+		Watched:      update.Watched,
+		Items:        []models.WatchHistoryItem{},
+	}
+
+	var updates []models.WatchHistoryUpdate
+	if update.Watched {
+		details, err := s.getSeriesMetadataWithCache(ctx, seriesID, update.SeriesName, update.ExternalIDs)
+		if err != nil {
+			return models.SeriesWatchedResult{}, fmt.Errorf("series metadata: %w", err)
+		}
+		if details == nil {
+			return models.SeriesWatchedResult{}, fmt.Errorf("series metadata not found for %s", seriesID)
+		}
+		updates, err = watchedEpisodeUpdates(seriesID, update, details, existing, inScope, &result)
+		if err != nil {
+			return models.SeriesWatchedResult{}, err
+		}
+	} else {
+		for _, item := range existing {
+			if !item.Watched {
+				result.Unchanged++
+				continue
+			}
+			updates = append(updates, models.WatchHistoryUpdate{
+				MediaType:     "episode",
+				ItemID:        item.ItemID,
+				Watched:       &update.Watched,
+				SeasonNumber:  item.SeasonNumber,
+				EpisodeNumber: item.EpisodeNumber,
+				SeriesID:      seriesID,
+			})
+		}
+		sortEpisodeUpdates(updates)
+	}
+
+	if len(updates) == 0 {
+		return result, nil
+	}
+
+	scrobble := update.SyncTrakt == nil || *update.SyncTrakt
+	items, err := s.bulkUpdateWatchHistory(userID, updates, scrobble)
+	if err != nil {
+		return models.SeriesWatchedResult{}, err
+	}
+	result.Updated = len(items)
+	result.Items = items
+
+	scope := "series"
+	if update.SeasonNumber != nil {
+		scope = fmt.Sprintf("season %d", *update.SeasonNumber)
+	}
+	log.Printf("[history] marked %d episodes of %s %s watched=%v for user %s", len(items), seriesID, scope, update.Watched, userID)
+	return result, nil
+}
+
+// watchedEpisodeUpdates builds the updates marking the aired, not yet watched
+// episodes in scope as watched.
+func watchedEpisodeUpdates(seriesID string, update models.SeriesWatchedUpdate, details *models.SeriesDetails, existing map[string]models.WatchHistoryItem, inScope func(int) bool, result *models.SeriesWatchedResult) ([]models.WatchHistoryUpdate, error) {
+	seriesName := update.SeriesName
+	if seriesName == "" {
+		seriesName = details.Title.Name
+	}
+	year := update.Year
+	if year == 0 {
+		year = details.Title.Year
+	}
+	externalIDs := update.ExternalIDs
+	if externalIDs == nil {
+		externalIDs = titleExternalIDs(details.Title)
+	}
+
+	seasonFound := update.SeasonNumber == nil
+	now := time.Now()
+	var updates []models.WatchHistoryUpdate
+	for _, season := range details.Seasons {
+		if !inScope(season.Number) {
+			continue
+		}
+		seasonFound = true
+		for _, ep := range season.Episodes {
+			if !inScope(ep.SeasonNumber) || !episodeAired(ep, now) {
+				continue
+			}
+			itemID := fmt.Sprintf("%s:s%02de%02d", seriesID, ep.SeasonNumber, ep.EpisodeNumber)
+			if current, ok := existing[episodeKey(ep.SeasonNumber, ep.EpisodeNumber)]; ok {
+				if current.Watched {
+					result.Unchanged++
+					continue
+				}
+				itemID = current.ItemID
+			}
+			updates = append(updates, models.WatchHistoryUpdate{
+				MediaType:     "episode",
+				ItemID:        itemID,
+				Name:          ep.Name,
+				Year:          year,
+				Watched:       &update.Watched,
+				WatchedAt:     update.WatchedAt,
+				ExternalIDs:   externalIDs,
+				SeasonNumber:  ep.SeasonNumber,
+				EpisodeNumber: ep.EpisodeNumber,
+				SeriesID:      seriesID,
+				SeriesName:    seriesName,
+			})
+		}
+	}
+	if !seasonFound {
+		return nil, ErrSeasonNotFound
+	}
+
+	sortEpisodeUpdates(updates)
+	return updates, nil
+}
+
+// titleExternalIDs returns a series' IDs in the form watch history stores them.
+func titleExternalIDs(title models.Title) map[string]string {
+	ids := make(map[string]string)
+	if title.TVDBID > 0 {
+		ids["tvdb"] = strconv.FormatInt(title.TVDBID, 10)
+	}
+	if title.TMDBID > 0 {
+		ids["tmdb"] = strconv.FormatInt(title.TMDBID, 10)
+	}
+	if title.IMDBID != "" {
+		ids["imdb"] = title.IMDBID
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+func sortEpisodeUpdates(updates []models.WatchHistoryUpdate) {
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].SeasonNumber != updates[j].SeasonNumber {
+			return updates[i].SeasonNumber < updates[j].SeasonNumber
+		}
+		return updates[i].EpisodeNumber < updates[j].EpisodeNumber
+	})
+}
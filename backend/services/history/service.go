@@ -22,6 +22,7 @@ var (
 	ErrStorageDirRequired = errors.New("storage directory not provided")
 	ErrUserIDRequired     = errors.New("user id is required")
 	ErrSeriesIDRequired   = errors.New("series id is required")
+	ErrSeasonNotFound     = errors.New("season not found")
 )
 
 // MetadataService provides series and movie metadata for continue watching generation.
@@ -78,6 +79,7 @@ type Service struct {
 	path                  string
 	watchHistPath         string
 	playbackProgressPath  string
+	store                 Store                                         // nil keeps history in JSON files
 	states                map[string]map[string]models.SeriesWatchState // Deprecated: kept for migration only
 	watchHistory          map[string]map[string]models.WatchHistoryItem // Manual watch tracking (all media)
 	playbackProgress      map[string]map[string]models.PlaybackProgress // userID -> mediaKey -> progress
@@ -89,10 +91,23 @@ type Service struct {
 	metadataCacheTTL      time.Duration
 	continueWatchingCache map[string]*cachedContinueWatching // userID -> continue watching
 	continueWatchingTTL   time.Duration
+	changeRecorder        ChangeRecorder // Optional journal for incremental client sync
+	coViewersMu           sync.Mutex
+	coViewers             map[string]*coViewerGroup // primary userID -> profiles watching together
+	retentionPolicies     RetentionPolicySource     // Per-profile continue watching retention and specials handling
+	retentionMu           sync.Mutex
+	retentionCancel       context.CancelFunc
+	retentionWG           sync.WaitGroup
 }
 
 // NewService constructs a history service backed by a JSON file on disk.
 func NewService(storageDir string) (*Service, error) {
+	return NewServiceWithStore(storageDir, nil)
+}
+
+// NewServiceWithStore constructs a history service kept in store. The first
+// time, the history JSON files in storageDir are imported into it.
+func NewServiceWithStore(storageDir string, store Store) (*Service, error) {
 	if strings.TrimSpace(storageDir) == "" {
 		return nil, ErrStorageDirRequired
 	}
@@ -114,6 +129,7 @@ func NewService(storageDir string) (*Service, error) {
 		metadataCacheTTL:      24 * time.Hour, // Cache metadata for 24 hours - ensures new episodes are detected daily
 		continueWatchingCache: make(map[string]*cachedContinueWatching),
 		continueWatchingTTL:   10 * time.Minute, // Cache continue watching response for 10 minutes - reduces frequent rebuilds
+		coViewers:             make(map[string]*coViewerGroup),
 	}
 
 	if err := svc.load(); err != nil {
@@ -128,6 +144,12 @@ func NewService(storageDir string) (*Service, error) {
 		return nil, err
 	}
 
+	if store != nil {
+		if err := svc.useStore(store); err != nil {
+			return nil, err
+		}
+	}
+
 	return svc, nil
 }
 
@@ -138,6 +160,26 @@ func (s *Service) SetMetadataService(metadataService MetadataService) {
 	s.metadataService = metadataService
 }
 
+// ChangeRecorder journals per-profile item changes so clients can sync incrementally.
+type ChangeRecorder interface {
+	RecordChange(userID, collection, key, op string)
+}
+
+// SetChangeRecorder sets the journal notified when watch history or playback progress changes.
+func (s *Service) SetChangeRecorder(recorder ChangeRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changeRecorder = recorder
+}
+
+// recordChangeLocked journals a change if a recorder is configured.
+// Callers must hold s.mu before invoking this helper.
+func (s *Service) recordChangeLocked(userID, collection, key, op string) {
+	if s.changeRecorder != nil {
+		s.changeRecorder.RecordChange(userID, collection, key, op)
+	}
+}
+
 // SetTraktScrobbler sets the Trakt scrobbler for syncing watch history.
 func (s *Service) SetTraktScrobbler(scrobbler TraktScrobbler) {
 	s.mu.Lock()
@@ -248,9 +290,11 @@ func (s *Service) RecordEpisode(userID string, payload models.EpisodeWatchPayloa
 		SeriesName:    payload.SeriesTitle,
 	}
 
-	if _, err := s.UpdateWatchHistory(userID, update); err != nil {
+	group := s.watchGroup(userID)
+	if _, err := s.updateWatchHistory(userID, update, watchedWith(userID, group)); err != nil {
 		return models.SeriesWatchState{}, err
 	}
+	s.recordEpisodeForCoViewers(userID, update, group)
 
 	// Invalidate continue watching cache for this user since they watched something new
 	s.mu.Lock()
@@ -371,6 +415,7 @@ func (s *Service) buildContinueWatchingFromHistory(ctx context.Context, userID s
 		// Metadata service not available, return empty list
 		return []models.SeriesWatchState{}, nil
 	}
+	specialsMode := s.specialsMode(userID)
 
 	// Get playback progress for in-progress items
 	progressItems, err := s.ListPlaybackProgress(userID)
@@ -387,7 +432,7 @@ func (s *Service) buildContinueWatchingFromHistory(ctx context.Context, userID s
 	// Build set of hidden series IDs from progress items
 	hiddenSeriesIDs := make(map[string]bool)
 	for _, prog := range progressItems {
-		if prog.HiddenFromContinueWatching {
+		if excludedFromContinueWatching(prog) {
 			// Add both the itemID (for movies) and seriesID (for episodes)
 			if prog.ItemID != "" {
 				hiddenSeriesIDs[prog.ItemID] = true
@@ -420,8 +465,8 @@ func (s *Service) buildContinueWatchingFromHistory(ctx context.Context, userID s
 	for i := range progressItems {
 		prog := &progressItems[i]
 
-		// Skip hidden items
-		if prog.HiddenFromContinueWatching {
+		// Skip hidden items, and markers that only track hidden/archived state
+		if excludedFromContinueWatching(*prog) || prog.Duration <= 0 {
 			continue
 		}
 
@@ -500,7 +545,7 @@ func (s *Service) buildContinueWatchingFromHistory(ctx context.Context, userID s
 		prog := &progressItems[i]
 
 		// Skip hidden movies
-		if prog.HiddenFromContinueWatching {
+		if excludedFromContinueWatching(*prog) {
 			continue
 		}
 
@@ -638,7 +683,7 @@ func (s *Service) buildContinueWatchingFromHistory(ctx context.Context, userID s
 				}
 
 				// Find next unwatched episode
-				nextEpisode = s.findNextUnwatchedEpisode(seriesDetails, mostRecentEpisode, episodes)
+				nextEpisode = s.findNextUnwatchedEpisode(seriesDetails, episodes, specialsMode)
 				if nextEpisode == nil {
 					// No next episode available, skip this series
 					return
@@ -1058,11 +1103,15 @@ func (s *Service) getSeriesInfoWithCache(ctx context.Context, seriesID, seriesNa
 	return info, nil
 }
 
-// findNextUnwatchedEpisode finds the next unwatched episode after the most recently watched one.
+// findNextUnwatchedEpisode finds the next unwatched episode after the most
+// recently watched one, in the viewing order of the profile's specials mode.
+// watchedEpisodes must be ordered most recent first. When the most recent
+// episode is not part of that order (a special while specials are hidden),
+// the most recent one that is picks up instead.
 func (s *Service) findNextUnwatchedEpisode(
 	seriesDetails *models.SeriesDetails,
-	lastWatched models.WatchHistoryItem,
 	watchedEpisodes []models.WatchHistoryItem,
+	specialsMode string,
 ) *models.EpisodeReference {
 	if seriesDetails == nil {
 		return nil
@@ -1075,60 +1124,59 @@ func (s *Service) findNextUnwatchedEpisode(
 		watchedSet[key] = true
 	}
 
-	// Flatten all episodes in series order
-	type orderedEpisode struct {
-		season  int
-		episode int
-		details models.SeriesEpisode
+	allEpisodes := models.EpisodesInViewingOrder(seriesDetails, specialsMode)
+	position := make(map[string]int, len(allEpisodes))
+	for i, ep := range allEpisodes {
+		position[episodeKey(ep.SeasonNumber, ep.EpisodeNumber)] = i
 	}
-	var allEpisodes []orderedEpisode
 
-	for _, season := range seriesDetails.Seasons {
-		for _, ep := range season.Episodes {
-			allEpisodes = append(allEpisodes, orderedEpisode{
-				season:  ep.SeasonNumber,
-				episode: ep.EpisodeNumber,
-				details: ep,
-			})
+	start := -1
+	for _, watched := range watchedEpisodes {
+		if i, ok := position[episodeKey(watched.SeasonNumber, watched.EpisodeNumber)]; ok {
+			start = i
+			break
 		}
 	}
+	if start < 0 {
+		return nil
+	}
 
-	// Sort by season, then episode number
-	sort.Slice(allEpisodes, func(i, j int) bool {
-		if allEpisodes[i].season != allEpisodes[j].season {
-			return allEpisodes[i].season < allEpisodes[j].season
-		}
-		return allEpisodes[i].episode < allEpisodes[j].episode
-	})
-
-	// Find the last watched episode in the list, then scan forward for next unwatched
-	foundLast := false
-	for _, ep := range allEpisodes {
-		if ep.season == lastWatched.SeasonNumber && ep.episode == lastWatched.EpisodeNumber {
-			foundLast = true
+	// Scan forward from the last watched episode for the next unwatched one
+	for _, ep := range allEpisodes[start+1:] {
+		if watchedSet[episodeKey(ep.SeasonNumber, ep.EpisodeNumber)] {
 			continue
 		}
-
-		if foundLast {
-			key := episodeKey(ep.season, ep.episode)
-			if !watchedSet[key] {
-				// Found next unwatched episode
-				return &models.EpisodeReference{
-					SeasonNumber:   ep.details.SeasonNumber,
-					EpisodeNumber:  ep.details.EpisodeNumber,
-					EpisodeID:      ep.details.ID,
-					Title:          ep.details.Name,
-					Overview:       ep.details.Overview,
-					RuntimeMinutes: ep.details.Runtime,
-					AirDate:        ep.details.AiredDate,
-				}
-			}
+		return &models.EpisodeReference{
+			SeasonNumber:   ep.SeasonNumber,
+			EpisodeNumber:  ep.EpisodeNumber,
+			EpisodeID:      ep.ID,
+			Title:          ep.Name,
+			Overview:       ep.Overview,
+			RuntimeMinutes: ep.Runtime,
+			AirDate:        ep.AiredDate,
 		}
 	}
 
 	return nil
 }
 
+// specialsMode returns how the profile wants specials handled in continue
+// watching.
+func (s *Service) specialsMode(userID string) string {
+	s.mu.RLock()
+	source := s.retentionPolicies
+	s.mu.RUnlock()
+
+	if source == nil {
+		return models.SpecialsSeparate
+	}
+	settings, err := source.Get(userID)
+	if err != nil || settings == nil {
+		return models.SpecialsSeparate
+	}
+	return models.NormalizeSpecialsMode(settings.Specials.Mode)
+}
+
 // convertToEpisodeRef converts a WatchHistoryItem to an EpisodeReference.
 func (s *Service) convertToEpisodeRef(item models.WatchHistoryItem) models.EpisodeReference {
 	tvdbID := ""
@@ -1233,6 +1281,10 @@ func (s *Service) load() error {
 }
 
 func (s *Service) saveLocked() error {
+	if s.store != nil {
+		return saveDocuments(s.store, statesCollection, s.states)
+	}
+
 	data, err := json.MarshalIndent(s.states, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode history: %w", err)
@@ -1264,18 +1316,7 @@ func countTotalEpisodes(seriesDetails *models.SeriesDetails) int {
 		}
 		for _, ep := range season.Episodes {
 			// Only count episodes that have aired
-			if ep.AiredDate != "" {
-				if airDate, err := time.Parse("2006-01-02", ep.AiredDate); err == nil {
-					if airDate.Before(now) || airDate.Equal(now) {
-						total++
-					}
-				} else {
-					// If we can't parse the date, assume it's released
-					total++
-				}
-			} else {
-				// No air date means it might not be released yet, but if it has an ID it's likely out
-				// Use episodeCount from season as fallback indication
+			if episodeAired(ep, now) {
 				total++
 			}
 		}
@@ -1283,6 +1324,20 @@ func countTotalEpisodes(seriesDetails *models.SeriesDetails) int {
 	return total
 }
 
+// episodeAired reports whether an episode has been released as of now.
+func episodeAired(ep models.SeriesEpisode, now time.Time) bool {
+	if ep.AiredDate == "" {
+		// No air date means it might not be released yet, but if it has an ID it's likely out
+		return true
+	}
+	airDate, err := time.Parse("2006-01-02", ep.AiredDate)
+	if err != nil {
+		// If we can't parse the date, assume it's released
+		return true
+	}
+	return !airDate.After(now)
+}
+
 // countWatchedEpisodes counts how many non-special episodes have been watched.
 func countWatchedEpisodes(watchedEpisodes map[string]models.EpisodeReference) int {
 	count := 0
@@ -1379,6 +1434,35 @@ func (s *Service) ListWatchHistory(userID string) ([]models.WatchHistoryItem, er
 	return items, nil
 }
 
+// ListWatchedEpisodes returns the episodes of a series the user has marked watched.
+func (s *Service) ListWatchedEpisodes(userID, seriesID string) ([]models.EpisodeReference, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+	seriesID = strings.TrimSpace(seriesID)
+	if seriesID == "" {
+		return nil, ErrSeriesIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	episodes := make([]models.EpisodeReference, 0)
+	for _, item := range s.watchHistory[userID] {
+		if item.MediaType != "episode" || !item.Watched || !strings.EqualFold(item.SeriesID, seriesID) {
+			continue
+		}
+		episodes = append(episodes, models.EpisodeReference{
+			SeasonNumber:  item.SeasonNumber,
+			EpisodeNumber: item.EpisodeNumber,
+			Title:         item.Name,
+			WatchedAt:     item.WatchedAt,
+		})
+	}
+	return episodes, nil
+}
+
 // WatchHistoryPage represents a paginated response of watch history items.
 type WatchHistoryPage struct {
 	Items      []models.WatchHistoryItem `json:"items"`
@@ -1500,6 +1584,10 @@ func (s *Service) ToggleWatched(userID string, update models.WatchHistoryUpdate)
 	normalizedItemID := strings.ToLower(update.ItemID)
 	key := makeWatchKey(update.MediaType, normalizedItemID)
 	item, exists := perUser[key]
+	if !exists {
+		// The same title stored under another ID toggles as this item
+		_, item, exists = findWatchDuplicateLocked(perUser, key, watchItemFromUpdate(key, normalizedItemID, update))
+	}
 
 	now := time.Now().UTC()
 	if !exists {
@@ -1516,6 +1604,7 @@ func (s *Service) ToggleWatched(userID string, update models.WatchHistoryUpdate)
 		item.Watched = !item.Watched
 		if item.Watched {
 			item.WatchedAt = now
+			item.WatchedWith = nil
 		}
 	}
 
@@ -1544,7 +1633,12 @@ func (s *Service) ToggleWatched(userID string, update models.WatchHistoryUpdate)
 		item.SeriesName = update.SeriesName
 	}
 
+	item.UpdatedAt = now
+	item.ID = key
+	item.ItemID = normalizedItemID
+	item, duplicates := mergeWatchDuplicatesLocked(perUser, key, item)
 	perUser[key] = item
+	s.recordChangeLocked(userID, models.SyncCollectionHistory, key, models.SyncOpUpsert)
 
 	if err := s.saveWatchHistoryLocked(); err != nil {
 		return models.WatchHistoryItem{}, err
@@ -1552,6 +1646,9 @@ func (s *Service) ToggleWatched(userID string, update models.WatchHistoryUpdate)
 
 	// Clear playback progress when toggling watched status (both marking as watched and unwatched)
 	progressCleared := s.clearPlaybackProgressEntryLocked(userID, item.MediaType, item.ItemID)
+	if s.removeWatchDuplicatesLocked(userID, duplicates, true) {
+		progressCleared = true
+	}
 
 	// If marking an episode as watched, also clear progress for earlier episodes
 	if item.Watched && item.MediaType == "episode" && item.SeriesID != "" && item.SeasonNumber > 0 && item.EpisodeNumber > 0 {
@@ -1583,6 +1680,13 @@ func (s *Service) ToggleWatched(userID string, update models.WatchHistoryUpdate)
 
 // UpdateWatchHistory updates or creates a watch history item.
 func (s *Service) UpdateWatchHistory(userID string, update models.WatchHistoryUpdate) (models.WatchHistoryItem, error) {
+	return s.updateWatchHistory(userID, update, nil)
+}
+
+// updateWatchHistory updates or creates a watch history item. When the item is
+// marked watched, watchedWith records the profiles that watched it together
+// with userID (nil for a solo viewing).
+func (s *Service) updateWatchHistory(userID string, update models.WatchHistoryUpdate, watchedWith []string) (models.WatchHistoryItem, error) {
 	userID = strings.TrimSpace(userID)
 	if userID == "" {
 		return models.WatchHistoryItem{}, ErrUserIDRequired
@@ -1626,6 +1730,7 @@ func (s *Service) UpdateWatchHistory(userID string, update models.WatchHistoryUp
 			} else {
 				item.WatchedAt = now
 			}
+			item.WatchedWith = watchedWith
 		}
 		// Clear playback progress when watched status changes (both marking as watched and unwatched)
 		progressCleared = s.clearPlaybackProgressEntryLocked(userID, update.MediaType, update.ItemID)
@@ -1648,13 +1753,25 @@ func (s *Service) UpdateWatchHistory(userID string, update models.WatchHistoryUp
 		item.SeriesName = update.SeriesName
 	}
 
+	if update.Watched != nil {
+		item.UpdatedAt = now
+	}
+	item, duplicates := mergeWatchDuplicatesLocked(perUser, key, item)
 	perUser[key] = item
+	s.recordChangeLocked(userID, models.SyncCollectionHistory, key, models.SyncOpUpsert)
+	if s.removeWatchDuplicatesLocked(userID, duplicates, update.Watched != nil) {
+		progressCleared = true
+	}
 
 	// If marking an episode as watched, also clear progress for earlier episodes
 	if update.Watched != nil && *update.Watched && update.MediaType == "episode" && update.SeriesID != "" && update.SeasonNumber > 0 && update.EpisodeNumber > 0 {
 		if s.clearEarlierEpisodesProgressLocked(userID, update.SeriesID, update.SeasonNumber, update.EpisodeNumber) {
 			progressCleared = true
 		}
+		// Watching an episode brings an archived series back
+		if s.unarchiveLocked(userID, update.SeriesID, item.WatchedAt) {
+			progressCleared = true
+		}
 	}
 
 	if err := s.saveWatchHistoryLocked(); err != nil {
@@ -1696,6 +1813,12 @@ func (s *Service) IsWatched(userID, mediaType, itemID string) (bool, error) {
 
 // BulkUpdateWatchHistory marks multiple episodes as watched/unwatched in a single operation.
 func (s *Service) BulkUpdateWatchHistory(userID string, updates []models.WatchHistoryUpdate) ([]models.WatchHistoryItem, error) {
+	return s.bulkUpdateWatchHistory(userID, updates, true)
+}
+
+// bulkUpdateWatchHistory applies updates in one write, scrobbling newly
+// watched items to Trakt when scrobble is set.
+func (s *Service) bulkUpdateWatchHistory(userID string, updates []models.WatchHistoryUpdate, scrobble bool) ([]models.WatchHistoryItem, error) {
 	userID = strings.TrimSpace(userID)
 	if userID == "" {
 		return nil, ErrUserIDRequired
@@ -1740,6 +1863,7 @@ func (s *Service) BulkUpdateWatchHistory(userID string, updates []models.WatchHi
 				} else {
 					item.WatchedAt = now
 				}
+				item.WatchedWith = nil
 			}
 			// Clear playback progress when watched status changes (both marking as watched and unwatched)
 			if s.clearPlaybackProgressEntryLocked(userID, update.MediaType, update.ItemID) {
@@ -1764,7 +1888,15 @@ func (s *Service) BulkUpdateWatchHistory(userID string, updates []models.WatchHi
 			item.SeriesName = update.SeriesName
 		}
 
+		if update.Watched != nil {
+			item.UpdatedAt = now
+		}
+		item, duplicates := mergeWatchDuplicatesLocked(perUser, key, item)
 		perUser[key] = item
+		s.recordChangeLocked(userID, models.SyncCollectionHistory, key, models.SyncOpUpsert)
+		if s.removeWatchDuplicatesLocked(userID, duplicates, update.Watched != nil) {
+			progressCleared = true
+		}
 
 		// If marking an episode as watched, also clear progress for earlier episodes
 		if update.Watched != nil && *update.Watched && update.MediaType == "episode" && update.SeriesID != "" && update.SeasonNumber > 0 && update.EpisodeNumber > 0 {
@@ -1789,6 +1921,10 @@ func (s *Service) BulkUpdateWatchHistory(userID string, updates []models.WatchHi
 	// Invalidate continue watching cache for this user
 	delete(s.continueWatchingCache, userID)
 
+	if !scrobble {
+		return results, nil
+	}
+
 	// Get scrobbler reference while holding lock (safe since we have write lock)
 	scrobbler := s.traktScrobbler
 
@@ -1803,6 +1939,37 @@ func (s *Service) BulkUpdateWatchHistory(userID string, updates []models.WatchHi
 	return results, nil
 }
 
+// removeWatchDuplicatesLocked records the deletion of watch history entries
+// merged into another and, when the watched state changed, clears their
+// playback progress. Reports whether any progress was cleared.
+// Callers must hold s.mu before invoking this helper.
+func (s *Service) removeWatchDuplicatesLocked(userID string, duplicates []models.WatchHistoryItem, clearProgress bool) bool {
+	progressCleared := false
+	for _, duplicate := range duplicates {
+		s.recordChangeLocked(userID, models.SyncCollectionHistory, makeWatchKey(duplicate.MediaType, duplicate.ItemID), models.SyncOpDelete)
+		if clearProgress && s.clearPlaybackProgressEntryLocked(userID, duplicate.MediaType, duplicate.ItemID) {
+			progressCleared = true
+		}
+	}
+	return progressCleared
+}
+
+// watchItemFromUpdate builds the watch history item an update describes, for
+// finding the same title stored under another ID.
+func watchItemFromUpdate(key, itemID string, update models.WatchHistoryUpdate) models.WatchHistoryItem {
+	return models.WatchHistoryItem{
+		ID:            key,
+		MediaType:     strings.ToLower(update.MediaType),
+		ItemID:        itemID,
+		Name:          update.Name,
+		Year:          update.Year,
+		ExternalIDs:   update.ExternalIDs,
+		SeasonNumber:  update.SeasonNumber,
+		EpisodeNumber: update.EpisodeNumber,
+		SeriesID:      update.SeriesID,
+	}
+}
+
 func (s *Service) ensureWatchHistoryUserLocked(userID string) map[string]models.WatchHistoryItem {
 	perUser, ok := s.watchHistory[userID]
 	if !ok {
@@ -1892,6 +2059,11 @@ func (s *Service) loadWatchHistory() error {
 				perUser[key] = item
 			}
 		}
+		// Merge entries for the same title stored under different IDs
+		if removed := dedupeWatchHistoryLocked(perUser); removed > 0 {
+			log.Printf("[history] merged %d duplicate watch history entries for user %s", removed, userID)
+			needsSave = true
+		}
 		s.watchHistory[userID] = perUser
 	}
 
@@ -1908,6 +2080,10 @@ func (s *Service) loadWatchHistory() error {
 }
 
 func (s *Service) saveWatchHistoryLocked() error {
+	if s.store != nil {
+		return saveDocuments(s.store, watchedItemsCollection, s.watchHistory)
+	}
+
 	// Convert to array format for storage
 	toSave := make(map[string][]models.WatchHistoryItem)
 	for userID, perUser := range s.watchHistory {
@@ -1945,7 +2121,21 @@ func makeWatchKey(mediaType, itemID string) string {
 
 // UpdatePlaybackProgress updates the playback progress for a media item.
 // Automatically marks items as watched when they reach 90% completion.
+// Progress is mirrored to any profiles watching together with userID.
 func (s *Service) UpdatePlaybackProgress(userID string, update models.PlaybackProgressUpdate) (models.PlaybackProgress, error) {
+	userID = strings.TrimSpace(userID)
+	group := s.watchGroup(userID)
+	progress, err := s.updatePlaybackProgress(userID, update, watchedWith(userID, group))
+	if err != nil {
+		return progress, err
+	}
+	s.updateProgressForCoViewers(userID, update, group)
+	return progress, nil
+}
+
+// updatePlaybackProgress stores progress for a single profile. watchedWith is
+// recorded on the history item if the update marks the title watched.
+func (s *Service) updatePlaybackProgress(userID string, update models.PlaybackProgressUpdate, watchedWith []string) (models.PlaybackProgress, error) {
 	userID = strings.TrimSpace(userID)
 	if userID == "" {
 		return models.PlaybackProgress{}, ErrUserIDRequired
@@ -1993,7 +2183,33 @@ func (s *Service) UpdatePlaybackProgress(userID string, update models.PlaybackPr
 		Year:           update.Year,
 	}
 
+	// Carry over per-version positions so each pinned release resumes where it left off.
+	// Watched state stays keyed by title, so finishing any version marks the title watched.
+	progress.Versions = collectVersionProgressLocked(perUser, key, progress)
+	if versionID := strings.TrimSpace(update.VersionID); versionID != "" {
+		if progress.Versions == nil {
+			progress.Versions = make(map[string]models.VersionProgress, 1)
+		}
+		progress.Versions[versionID] = models.VersionProgress{
+			Position:       update.Position,
+			Duration:       update.Duration,
+			PercentWatched: percentWatched,
+			UpdatedAt:      progress.UpdatedAt,
+		}
+		progress.VersionID = versionID
+	}
+
 	perUser[key] = progress
+	s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
+
+	// Drop entries for the same title stored under a different item ID (e.g. the
+	// episode was re-resolved from another release) so only one card remains.
+	if removed := removeCanonicalDuplicatesLocked(perUser, key, progress); len(removed) > 0 {
+		log.Printf("[history] replaced %d duplicate progress entr(ies) for %s user=%s", len(removed), key, userID)
+		for _, removedKey := range removed {
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, removedKey, models.SyncOpDelete)
+		}
+	}
 
 	// Clear hidden flag for related series entries when new progress is logged
 	// This ensures the series reappears in continue watching when user resumes watching
@@ -2003,8 +2219,10 @@ func (s *Service) UpdatePlaybackProgress(userID string, update models.PlaybackPr
 				(existingProg.ItemID == update.SeriesID || existingProg.SeriesID == update.SeriesID) {
 				existingProg.HiddenFromContinueWatching = false
 				perUser[existingKey] = existingProg
+				s.recordChangeLocked(userID, models.SyncCollectionProgress, existingKey, models.SyncOpUpsert)
 			}
 		}
+		s.unarchiveLocked(userID, update.SeriesID, progress.UpdatedAt)
 	}
 
 	if err := s.savePlaybackProgressLocked(); err != nil {
@@ -2017,7 +2235,7 @@ func (s *Service) UpdatePlaybackProgress(userID string, update models.PlaybackPr
 	// Auto-mark as watched if >= 90% complete
 	if percentWatched >= 90 {
 		s.mu.Unlock() // Unlock before calling other methods
-		err := s.markAsWatchedFromProgress(userID, update)
+		err := s.markAsWatchedFromProgress(userID, update, watchedWith)
 		s.mu.Lock() // Re-lock after
 		if err != nil {
 			// Log but don't fail the progress update
@@ -2029,7 +2247,7 @@ func (s *Service) UpdatePlaybackProgress(userID string, update models.PlaybackPr
 }
 
 // markAsWatchedFromProgress marks an item as watched based on progress threshold.
-func (s *Service) markAsWatchedFromProgress(userID string, update models.PlaybackProgressUpdate) error {
+func (s *Service) markAsWatchedFromProgress(userID string, update models.PlaybackProgressUpdate, watchedWith []string) error {
 	watched := true
 	historyUpdate := models.WatchHistoryUpdate{
 		MediaType:     update.MediaType,
@@ -2049,7 +2267,7 @@ func (s *Service) markAsWatchedFromProgress(userID string, update models.Playbac
 		historyUpdate.Year = update.Year
 	}
 
-	_, err := s.UpdateWatchHistory(userID, historyUpdate)
+	_, err := s.updateWatchHistory(userID, historyUpdate, watchedWith)
 	return err
 }
 
@@ -2095,6 +2313,12 @@ func (s *Service) ListPlaybackProgress(userID string) ([]models.PlaybackProgress
 					copy.ExternalIDs[k] = v
 				}
 			}
+			if progress.Versions != nil {
+				copy.Versions = make(map[string]models.VersionProgress, len(progress.Versions))
+				for k, v := range progress.Versions {
+					copy.Versions[k] = v
+				}
+			}
 			items = append(items, copy)
 		}
 	}
@@ -2123,6 +2347,7 @@ func (s *Service) DeletePlaybackProgress(userID, mediaType, itemID string) error
 	key := makeWatchKey(mediaType, itemID)
 	if perUser, ok := s.playbackProgress[userID]; ok {
 		delete(perUser, key)
+		s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpDelete)
 		// Invalidate continue watching cache for this user since progress changed
 		delete(s.continueWatchingCache, userID)
 		return s.savePlaybackProgressLocked()
@@ -2131,6 +2356,41 @@ func (s *Service) DeletePlaybackProgress(userID, mediaType, itemID string) error
 	return nil
 }
 
+// DeleteUser removes all watch history and playback progress stored for a
+// profile. Used when a deleted profile is purged.
+func (s *Service) DeleteUser(userID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.continueWatchingCache, userID)
+
+	if _, ok := s.states[userID]; ok {
+		delete(s.states, userID)
+		if err := s.saveLocked(); err != nil {
+			return err
+		}
+	}
+	if _, ok := s.watchHistory[userID]; ok {
+		delete(s.watchHistory, userID)
+		if err := s.saveWatchHistoryLocked(); err != nil {
+			return err
+		}
+	}
+	if _, ok := s.playbackProgress[userID]; ok {
+		delete(s.playbackProgress, userID)
+		if err := s.savePlaybackProgressLocked(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *Service) ensurePlaybackProgressUserLocked(userID string) map[string]models.PlaybackProgress {
 	perUser, ok := s.playbackProgress[userID]
 	if !ok {
@@ -2198,6 +2458,11 @@ func (s *Service) loadPlaybackProgress() error {
 				perUser[key] = item
 			}
 		}
+		// Migrate duplicates created before progress was keyed by canonical title+episode
+		if removed := dedupeProgressLocked(perUser); removed > 0 {
+			log.Printf("[history] merged %d duplicate playback progress entr(ies) for user %s", removed, userID)
+			needsSave = true
+		}
 		s.playbackProgress[userID] = perUser
 	}
 
@@ -2214,6 +2479,10 @@ func (s *Service) loadPlaybackProgress() error {
 }
 
 func (s *Service) savePlaybackProgressLocked() error {
+	if s.store != nil {
+		return saveDocuments(s.store, playbackProgressCollection, s.playbackProgress)
+	}
+
 	// Convert to array format for storage
 	toSave := make(map[string][]models.PlaybackProgress)
 	for userID, perUser := range s.playbackProgress {
@@ -2252,8 +2521,8 @@ func (s *Service) ListAllPlaybackProgress() map[string][]models.PlaybackProgress
 	for userID, perUser := range s.playbackProgress {
 		items := make([]models.PlaybackProgress, 0, len(perUser))
 		for _, progress := range perUser {
-			// Only include items that haven't been hidden from continue watching
-			if !progress.HiddenFromContinueWatching {
+			// Only include items that haven't been hidden or archived from continue watching
+			if !excludedFromContinueWatching(progress) {
 				items = append(items, progress)
 			}
 		}
@@ -2282,6 +2551,7 @@ func (s *Service) clearPlaybackProgressEntryLocked(userID, mediaType, itemID str
 	key := makeWatchKey(mediaType, itemID)
 	if _, exists := perUser[key]; exists {
 		delete(perUser, key)
+		s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpDelete)
 		return true
 	}
 
@@ -2290,6 +2560,7 @@ func (s *Service) clearPlaybackProgressEntryLocked(userID, mediaType, itemID str
 	for existingKey := range perUser {
 		if strings.ToLower(existingKey) == target {
 			delete(perUser, existingKey)
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, existingKey, models.SyncOpDelete)
 			return true
 		}
 	}
@@ -2322,6 +2593,7 @@ func (s *Service) HideFromContinueWatching(userID, seriesID string) error {
 		if progress.ItemID == seriesID || progress.SeriesID == seriesID {
 			progress.HiddenFromContinueWatching = true
 			perUser[key] = progress
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
 			found = true
 		}
 	}
@@ -2343,6 +2615,7 @@ func (s *Service) HideFromContinueWatching(userID, seriesID string) error {
 			UpdatedAt:                  time.Now().UTC(),
 			HiddenFromContinueWatching: true,
 		}
+		s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpUpsert)
 	}
 
 	// Invalidate continue watching cache
@@ -2381,6 +2654,7 @@ func (s *Service) clearEarlierEpisodesProgressLocked(userID, seriesID string, se
 
 		if isEarlier {
 			delete(perUser, key)
+			s.recordChangeLocked(userID, models.SyncCollectionProgress, key, models.SyncOpDelete)
 			anyCleared = true
 		}
 	}
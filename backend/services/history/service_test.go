@@ -2,6 +2,7 @@ package history
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -750,3 +751,575 @@ func TestBulkUpdateWatchHistoryClearsProgressWhenMarkingUnwatched(t *testing.T)
 		t.Fatalf("expected playback progress to be cleared when marking as unwatched, got %d items", len(progressItems))
 	}
 }
+
+func TestUpdatePlaybackProgressReplacesDuplicateFromDifferentRelease(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	userID := "user-1"
+	first := models.PlaybackProgressUpdate{
+		MediaType:     "episode",
+		ItemID:        "/streams/Show.S01E02.1080p.WEB-DL.mkv",
+		Position:      300,
+		Duration:      1800,
+		SeasonNumber:  1,
+		EpisodeNumber: 2,
+		SeriesID:      "tvdb:series:123",
+		SeriesName:    "Show",
+	}
+	if _, err := svc.UpdatePlaybackProgress(userID, first); err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+
+	second := first
+	second.ItemID = "/streams/Show.S01E02.2160p.REMUX.mkv"
+	second.Position = 600
+	if _, err := svc.UpdatePlaybackProgress(userID, second); err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+
+	items, err := svc.ListPlaybackProgress(userID)
+	if err != nil {
+		t.Fatalf("ListPlaybackProgress() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 progress entry after re-resolve, got %d", len(items))
+	}
+	if items[0].Position != 600 || !strings.Contains(items[0].ItemID, "2160p") {
+		t.Fatalf("expected newest release to be kept, got %+v", items[0])
+	}
+}
+
+func TestLoadPlaybackProgressMergesExistingDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Now().UTC().Add(-2 * time.Hour)
+	newer := time.Now().UTC().Add(-1 * time.Hour)
+
+	data := `{"user-1":[` +
+		`{"id":"movie:/a/movie.1080p.mkv","mediaType":"movie","itemId":"/a/movie.1080p.mkv","position":100,"duration":6000,"percentWatched":10,"updatedAt":"` + older.Format(time.RFC3339Nano) + `","externalIds":{"tmdb":"603"}},` +
+		`{"id":"movie:/b/movie.2160p.mkv","mediaType":"movie","itemId":"/b/movie.2160p.mkv","position":900,"duration":6000,"percentWatched":15,"updatedAt":"` + newer.Format(time.RFC3339Nano) + `","externalIds":{"tmdb":"603"}},` +
+		`{"id":"movie:tmdb:604","mediaType":"movie","itemId":"tmdb:604","position":900,"duration":6000,"percentWatched":15,"updatedAt":"` + newer.Format(time.RFC3339Nano) + `","externalIds":{"tmdb":"604"}}` +
+		`]}`
+	if err := os.WriteFile(filepath.Join(dir, "playback_progress.json"), []byte(data), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	items, err := svc.ListPlaybackProgress("user-1")
+	if err != nil {
+		t.Fatalf("ListPlaybackProgress() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected duplicates for tmdb:603 to merge into 2 entries, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.ExternalIDs["tmdb"] == "603" && item.Position != 900 {
+			t.Fatalf("expected most recent entry to survive migration, got %+v", item)
+		}
+	}
+}
+
+func TestPlaybackProgressTracksPinnedVersions(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	base := models.PlaybackProgressUpdate{
+		MediaType:   "movie",
+		ItemID:      "tmdb:movie:603",
+		ExternalIDs: map[string]string{"tmdb": "603"},
+		MovieName:   "The Matrix",
+		Year:        1999,
+	}
+
+	uhd := base
+	uhd.VersionID, uhd.Position, uhd.Duration = "uhd", 1200, 8160
+	if _, err := svc.UpdatePlaybackProgress("user-1", uhd); err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+
+	// Director's cut resolved from a different release path
+	cut := base
+	cut.ItemID = "/releases/the.matrix.directors.cut.mkv"
+	cut.VersionID, cut.Position, cut.Duration = "cut", 300, 9000
+	progress, err := svc.UpdatePlaybackProgress("user-1", cut)
+	if err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+
+	if progress.VersionID != "cut" || progress.Position != 300 {
+		t.Fatalf("expected title progress to follow the latest version, got %+v", progress)
+	}
+	if len(progress.Versions) != 2 || progress.Versions["uhd"].Position != 1200 || progress.Versions["cut"].Duration != 9000 {
+		t.Fatalf("expected positions for both versions, got %+v", progress.Versions)
+	}
+
+	items, err := svc.ListPlaybackProgress("user-1")
+	if err != nil {
+		t.Fatalf("ListPlaybackProgress() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected a single unified progress entry, got %d", len(items))
+	}
+}
+
+func TestMissingEpisodesReport(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	svc.SetMetadataService(&mockMetadataService{
+		seriesDetails: &models.SeriesDetails{
+			Title: models.Title{ID: "series-1", Name: "Example Show"},
+			Seasons: []models.SeriesSeason{
+				{Number: 0, Episodes: []models.SeriesEpisode{
+					{ID: "special", SeasonNumber: 0, EpisodeNumber: 1, AiredDate: "2020-01-01"},
+				}},
+				{Number: 1, Episodes: []models.SeriesEpisode{
+					{ID: "ep-1", SeasonNumber: 1, EpisodeNumber: 1, AiredDate: "2020-01-01"},
+					{ID: "ep-2", SeasonNumber: 1, EpisodeNumber: 2, AiredDate: "2020-01-08"},
+					{ID: "ep-3", SeasonNumber: 1, EpisodeNumber: 3, AiredDate: "2020-01-15"},
+					{ID: "ep-4", SeasonNumber: 1, EpisodeNumber: 4, AiredDate: "2020-01-22"},
+					{ID: "ep-5", SeasonNumber: 1, EpisodeNumber: 5, AiredDate: future},
+				}},
+			},
+		},
+	})
+
+	watched := true
+	markWatched := func(episode int) {
+		t.Helper()
+		_, err := svc.UpdateWatchHistory("user-1", models.WatchHistoryUpdate{
+			MediaType:     "episode",
+			ItemID:        fmt.Sprintf("series-1:s01e%02d", episode),
+			Watched:       &watched,
+			SeasonNumber:  1,
+			EpisodeNumber: episode,
+			SeriesID:      "series-1",
+			SeriesName:    "Example Show",
+		})
+		if err != nil {
+			t.Fatalf("UpdateWatchHistory() error = %v", err)
+		}
+	}
+	markWatched(1)
+	markWatched(3)
+
+	report, err := svc.GetMissingEpisodes("user-1", "series-1")
+	if err != nil {
+		t.Fatalf("GetMissingEpisodes() error = %v", err)
+	}
+	if report == nil {
+		t.Fatal("expected a report for a watched series")
+	}
+	if report.AiredEpisodeCount != 4 || report.WatchedEpisodeCount != 2 || report.UpcomingEpisodeCount != 1 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+	if report.NextAirDate != future {
+		t.Fatalf("expected next air date %s, got %q", future, report.NextAirDate)
+	}
+	if len(report.MissingEpisodes) != 2 || report.GapCount != 1 {
+		t.Fatalf("expected episodes 2 and 4 missing with one gap, got %+v", report.MissingEpisodes)
+	}
+	if ep := report.MissingEpisodes[0]; ep.EpisodeNumber != 2 || !ep.Gap {
+		t.Fatalf("expected episode 2 to be a gap, got %+v", ep)
+	}
+	if ep := report.MissingEpisodes[1]; ep.EpisodeNumber != 4 || ep.Gap {
+		t.Fatalf("expected episode 4 to be unwatched but not a gap, got %+v", ep)
+	}
+
+	markWatched(2)
+	markWatched(4)
+
+	reports, err := svc.ListMissingEpisodes("user-1")
+	if err != nil {
+		t.Fatalf("ListMissingEpisodes() error = %v", err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected completed series to be omitted, got %+v", reports)
+	}
+
+	report, err = svc.GetMissingEpisodes("user-1", "unknown")
+	if err != nil || report != nil {
+		t.Fatalf("expected nil report for unwatched series, got %+v, %v", report, err)
+	}
+}
+
+func TestCoViewersShareProgressAndHistory(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	viewers, err := svc.SetCoViewers("user-1", []string{" user-2 ", "user-1", "user-2", "user-3"})
+	if err != nil {
+		t.Fatalf("SetCoViewers() error = %v", err)
+	}
+	if strings.Join(viewers, ",") != "user-2,user-3" {
+		t.Fatalf("unexpected co-viewers %v", viewers)
+	}
+
+	update := models.PlaybackProgressUpdate{
+		MediaType: "movie",
+		ItemID:    "tmdb:603",
+		Position:  600,
+		Duration:  6000,
+		MovieName: "The Matrix",
+		Year:      1999,
+	}
+	if _, err := svc.UpdatePlaybackProgress("user-1", update); err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		progress, err := svc.GetPlaybackProgress(userID, "movie", "tmdb:603")
+		if err != nil || progress == nil || progress.Position != 600 {
+			t.Fatalf("%s: expected shared progress, got %+v, %v", userID, progress, err)
+		}
+	}
+
+	update.Position = 5900
+	if _, err := svc.UpdatePlaybackProgress("user-1", update); err != nil {
+		t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+	}
+	item, err := svc.GetWatchHistoryItem("user-2", "movie", "tmdb:603")
+	if err != nil || item == nil || !item.Watched {
+		t.Fatalf("expected co-viewer to be credited with watching, got %+v, %v", item, err)
+	}
+	if strings.Join(item.WatchedWith, ",") != "user-1,user-3" {
+		t.Fatalf("unexpected watchedWith %v", item.WatchedWith)
+	}
+
+	if _, err := svc.SetCoViewers("user-1", nil); err != nil {
+		t.Fatalf("SetCoViewers() error = %v", err)
+	}
+	if _, err := svc.RecordEpisode("user-1", models.EpisodeWatchPayload{
+		SeriesID:    "tvdb:81189",
+		SeriesTitle: "Breaking Bad",
+		Episode:     models.EpisodeReference{SeasonNumber: 1, EpisodeNumber: 1},
+	}); err != nil {
+		t.Fatalf("RecordEpisode() error = %v", err)
+	}
+	if watched, _ := svc.IsWatched("user-2", "episode", "tvdb:81189:s01e01"); watched {
+		t.Fatal("cleared co-viewers should not be credited")
+	}
+	item, err = svc.GetWatchHistoryItem("user-1", "episode", "tvdb:81189:s01e01")
+	if err != nil || item == nil || len(item.WatchedWith) != 0 {
+		t.Fatalf("expected solo viewing, got %+v, %v", item, err)
+	}
+}
+
+func TestPruneContinueWatchingArchivesAndRestores(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	userID := "user-1"
+	stale := time.Now().UTC().AddDate(0, 0, -40)
+
+	for _, movieID := range []string{"tmdb:movie:1", "tmdb:movie:2"} {
+		if _, err := svc.UpdatePlaybackProgress(userID, models.PlaybackProgressUpdate{
+			MediaType: "movie",
+			ItemID:    movieID,
+			Position:  600,
+			Duration:  6000,
+		}); err != nil {
+			t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+		}
+	}
+	// Backdate the first movie past the expiry period
+	key := makeWatchKey("movie", "tmdb:movie:1")
+	progress := svc.playbackProgress[userID][key]
+	progress.UpdatedAt = stale
+	svc.playbackProgress[userID][key] = progress
+
+	watched := true
+	if _, err := svc.UpdateWatchHistory(userID, models.WatchHistoryUpdate{
+		MediaType:     "episode",
+		ItemID:        "tvdb:series:9:s01e01",
+		Watched:       &watched,
+		WatchedAt:     stale,
+		SeriesID:      "tvdb:series:9",
+		SeriesName:    "Stale Show",
+		SeasonNumber:  1,
+		EpisodeNumber: 1,
+	}); err != nil {
+		t.Fatalf("UpdateWatchHistory() error = %v", err)
+	}
+
+	result, err := svc.PruneContinueWatching(userID, models.ContinueWatchingSettings{ExpireAfterDays: 30})
+	if err != nil {
+		t.Fatalf("PruneContinueWatching() error = %v", err)
+	}
+	if result.Archived != 2 {
+		t.Fatalf("expected stale movie and series archived, got %+v", result)
+	}
+
+	archive, err := svc.ListContinueWatchingArchive(userID)
+	if err != nil {
+		t.Fatalf("ListContinueWatchingArchive() error = %v", err)
+	}
+	archivedIDs := make(map[string]bool)
+	for _, item := range archive {
+		archivedIDs[item.ItemID] = true
+	}
+	if len(archive) != 2 || !archivedIDs["tmdb:movie:1"] || !archivedIDs["tvdb:series:9"] {
+		t.Fatalf("unexpected archive %+v", archive)
+	}
+
+	// A second pass leaves archived items alone
+	if result, _ := svc.PruneContinueWatching(userID, models.ContinueWatchingSettings{ExpireAfterDays: 30}); result.Archived != 0 {
+		t.Fatalf("expected nothing newly archived, got %+v", result)
+	}
+
+	if err := svc.RestoreToContinueWatching(userID, "tmdb:movie:1"); err != nil {
+		t.Fatalf("RestoreToContinueWatching() error = %v", err)
+	}
+	restored, _ := svc.GetPlaybackProgress(userID, "movie", "tmdb:movie:1")
+	if restored == nil || restored.ArchivedAt != nil || restored.UpdatedAt.Before(time.Now().Add(-time.Minute)) {
+		t.Fatalf("expected movie restored with fresh activity, got %+v", restored)
+	}
+
+	// Watching the next episode brings the series back
+	if _, err := svc.UpdateWatchHistory(userID, models.WatchHistoryUpdate{
+		MediaType:     "episode",
+		ItemID:        "tvdb:series:9:s01e02",
+		Watched:       &watched,
+		SeriesID:      "tvdb:series:9",
+		SeasonNumber:  1,
+		EpisodeNumber: 2,
+	}); err != nil {
+		t.Fatalf("UpdateWatchHistory() error = %v", err)
+	}
+	archive, _ = svc.ListContinueWatchingArchive(userID)
+	if len(archive) != 0 {
+		t.Fatalf("expected empty archive, got %+v", archive)
+	}
+}
+
+func TestPruneContinueWatchingAutoCompletes(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	userID := "user-1"
+	idle := time.Now().UTC().Add(-48 * time.Hour)
+
+	for i, movieID := range []string{"tmdb:movie:1", "tmdb:movie:2"} {
+		if _, err := svc.UpdatePlaybackProgress(userID, models.PlaybackProgressUpdate{
+			MediaType: "movie",
+			ItemID:    movieID,
+			MovieName: fmt.Sprintf("Movie %d", i+1),
+			Position:  5300,
+			Duration:  6000,
+		}); err != nil {
+			t.Fatalf("UpdatePlaybackProgress() error = %v", err)
+		}
+	}
+	// Only the idle one is abandoned; the other may still be playing
+	key := makeWatchKey("movie", "tmdb:movie:1")
+	progress := svc.playbackProgress[userID][key]
+	progress.UpdatedAt = idle
+	svc.playbackProgress[userID][key] = progress
+
+	result, err := svc.PruneContinueWatching(userID, models.ContinueWatchingSettings{AutoCompletePercent: 85})
+	if err != nil {
+		t.Fatalf("PruneContinueWatching() error = %v", err)
+	}
+	if result.AutoCompleted != 1 {
+		t.Fatalf("expected one item auto-completed, got %+v", result)
+	}
+
+	item, _ := svc.GetWatchHistoryItem(userID, "movie", "tmdb:movie:1")
+	if item == nil || !item.Watched || !item.WatchedAt.Equal(idle) {
+		t.Fatalf("expected movie watched at its last activity, got %+v", item)
+	}
+	if remaining, _ := svc.GetPlaybackProgress(userID, "movie", "tmdb:movie:1"); remaining != nil {
+		t.Fatalf("expected progress cleared, got %+v", remaining)
+	}
+	if active, _ := svc.GetPlaybackProgress(userID, "movie", "tmdb:movie:2"); active == nil {
+		t.Fatal("expected recent progress kept")
+	}
+}
+
+func TestSetSeriesWatchedMarksSeasonsInOneWrite(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	svc.SetMetadataService(&mockMetadataService{
+		seriesDetails: &models.SeriesDetails{
+			Title: models.Title{ID: "tvdb:100", Name: "Bulk Show", TVDBID: 100},
+			Seasons: []models.SeriesSeason{
+				{Number: 0, Episodes: []models.SeriesEpisode{{SeasonNumber: 0, EpisodeNumber: 1, Name: "Special"}}},
+				{Number: 1, Episodes: []models.SeriesEpisode{
+					{SeasonNumber: 1, EpisodeNumber: 1, Name: "S1E1"},
+					{SeasonNumber: 1, EpisodeNumber: 2, Name: "S1E2"},
+				}},
+				{Number: 2, Episodes: []models.SeriesEpisode{
+					{SeasonNumber: 2, EpisodeNumber: 1, Name: "S2E1"},
+					{SeasonNumber: 2, EpisodeNumber: 2, Name: "S2E2", AiredDate: time.Now().AddDate(0, 1, 0).Format("2006-01-02")},
+				}},
+			},
+		},
+	})
+
+	const user = "user-1"
+	season1 := 1
+	result, err := svc.SetSeriesWatched(context.Background(), user, models.SeriesWatchedUpdate{SeriesID: "tvdb:100", SeasonNumber: &season1, Watched: true})
+	if err != nil {
+		t.Fatalf("SetSeriesWatched(season 1) error = %v", err)
+	}
+	if result.Updated != 2 || result.Unchanged != 0 {
+		t.Fatalf("expected 2 updated, got %+v", result)
+	}
+	if result.Items[0].ExternalIDs["tvdb"] != "100" || result.Items[0].SeriesName != "Bulk Show" {
+		t.Fatalf("expected series ids and name from metadata, got %+v", result.Items[0])
+	}
+
+	// Whole series skips specials, unaired episodes and already watched ones
+	result, err = svc.SetSeriesWatched(context.Background(), user, models.SeriesWatchedUpdate{SeriesID: "tvdb:100", Watched: true})
+	if err != nil {
+		t.Fatalf("SetSeriesWatched(series) error = %v", err)
+	}
+	if result.Updated != 1 || result.Unchanged != 2 || result.Items[0].SeasonNumber != 2 {
+		t.Fatalf("expected only S2E1 updated, got %+v", result)
+	}
+
+	missing := 7
+	if _, err := svc.SetSeriesWatched(context.Background(), user, models.SeriesWatchedUpdate{SeriesID: "tvdb:100", SeasonNumber: &missing, Watched: true}); err != ErrSeasonNotFound {
+		t.Fatalf("expected ErrSeasonNotFound, got %v", err)
+	}
+
+	result, err = svc.SetSeriesWatched(context.Background(), user, models.SeriesWatchedUpdate{SeriesID: "tvdb:100", SeasonNumber: &season1, Watched: false})
+	if err != nil {
+		t.Fatalf("SetSeriesWatched(unwatch) error = %v", err)
+	}
+	if result.Updated != 2 {
+		t.Fatalf("expected 2 episodes unwatched, got %+v", result)
+	}
+	if watched, _ := svc.IsWatched(user, "episode", "tvdb:100:s02e01"); !watched {
+		t.Fatalf("expected season 2 to stay watched")
+	}
+	if watched, _ := svc.IsWatched(user, "episode", "tvdb:100:s01e01"); watched {
+		t.Fatalf("expected season 1 to be unwatched")
+	}
+}
+
+func TestWatchHistoryMergesSameTitleUnderDifferentIDs(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	watched := true
+	first := time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)
+	if _, err := svc.UpdateWatchHistory("user-1", models.WatchHistoryUpdate{
+		MediaType:   "movie",
+		ItemID:      "tmdb:movie:603",
+		Name:        "The Matrix",
+		Year:        1999,
+		Watched:     &watched,
+		WatchedAt:   first,
+		ExternalIDs: map[string]string{"tmdb": "603", "imdb": "tt0133093"},
+	}); err != nil {
+		t.Fatalf("UpdateWatchHistory() error = %v", err)
+	}
+
+	// The same movie resolved through another release arrives under its IMDB ID
+	second := first.Add(48 * time.Hour)
+	item, err := svc.UpdateWatchHistory("user-1", models.WatchHistoryUpdate{
+		MediaType:   "movie",
+		ItemID:      "imdb:tt0133093",
+		Watched:     &watched,
+		WatchedAt:   second,
+		ExternalIDs: map[string]string{"tmdb": "603"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateWatchHistory() error = %v", err)
+	}
+	if !item.WatchedAt.Equal(second) || item.Name != "The Matrix" || item.ExternalIDs["imdb"] != "tt0133093" {
+		t.Fatalf("expected the newest watch with merged metadata, got %+v", item)
+	}
+
+	items, err := svc.ListWatchHistory("user-1")
+	if err != nil {
+		t.Fatalf("ListWatchHistory() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ItemID != "imdb:tt0133093" {
+		t.Fatalf("expected a single merged entry, got %+v", items)
+	}
+
+	// Toggling through the original ID acts on the merged entry
+	toggled, err := svc.ToggleWatched("user-1", models.WatchHistoryUpdate{
+		MediaType:   "movie",
+		ItemID:      "tmdb:movie:603",
+		ExternalIDs: map[string]string{"tmdb": "603"},
+	})
+	if err != nil {
+		t.Fatalf("ToggleWatched() error = %v", err)
+	}
+	items, _ = svc.ListWatchHistory("user-1")
+	if toggled.Watched || len(items) != 1 || items[0].ItemID != "tmdb:movie:603" {
+		t.Fatalf("expected the merged entry to be toggled unwatched, got %+v (%d items)", toggled, len(items))
+	}
+}
+
+func TestFindNextUnwatchedEpisodeSpecialsModes(t *testing.T) {
+	details := &models.SeriesDetails{
+		Seasons: []models.SeriesSeason{
+			{Number: 0, Episodes: []models.SeriesEpisode{
+				{SeasonNumber: 0, EpisodeNumber: 1, Name: "Christmas Special", AiredDate: "2020-12-25"},
+			}},
+			{Number: 1, Episodes: []models.SeriesEpisode{
+				{SeasonNumber: 1, EpisodeNumber: 1, Name: "Pilot", AiredDate: "2020-12-01"},
+				{SeasonNumber: 1, EpisodeNumber: 2, Name: "Second", AiredDate: "2020-12-20"},
+				{SeasonNumber: 1, EpisodeNumber: 3, Name: "Third", AiredDate: "2021-01-05"},
+			}},
+		},
+	}
+	watched := func(season, episode int) models.WatchHistoryItem {
+		return models.WatchHistoryItem{SeasonNumber: season, EpisodeNumber: episode}
+	}
+
+	svc := &Service{}
+	tests := []struct {
+		name    string
+		mode    string
+		history []models.WatchHistoryItem
+		want    string
+	}{
+		{name: "separate skips the special", mode: models.SpecialsSeparate, history: []models.WatchHistoryItem{watched(1, 2)}, want: "Third"},
+		{name: "inline by air date", mode: models.SpecialsInline, history: []models.WatchHistoryItem{watched(1, 2)}, want: "Christmas Special"},
+		{name: "inline after the special", mode: models.SpecialsInline, history: []models.WatchHistoryItem{watched(0, 1), watched(1, 2)}, want: "Third"},
+		{name: "hidden resumes from the last regular episode", mode: models.SpecialsHidden, history: []models.WatchHistoryItem{watched(0, 1), watched(1, 1)}, want: "Second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := svc.findNextUnwatchedEpisode(details, tt.history, tt.mode)
+			if next == nil || next.Title != tt.want {
+				t.Fatalf("findNextUnwatchedEpisode() = %+v, want %q", next, tt.want)
+			}
+		})
+	}
+
+	arranged := models.ApplySpecialsMode(details, models.SpecialsInline)
+	if len(arranged.Seasons) != 1 || arranged.Seasons[0].Episodes[2].Name != "Christmas Special" {
+		t.Errorf("expected the special inlined into season 1, got %+v", arranged.Seasons)
+	}
+	if len(details.Seasons) != 2 || len(details.Seasons[1].Episodes) != 3 {
+		t.Error("ApplySpecialsMode modified the original details")
+	}
+	if hidden := models.ApplySpecialsMode(details, models.SpecialsHidden); len(hidden.Seasons) != 1 || hidden.Seasons[0].Number != 1 {
+		t.Errorf("expected season 0 hidden, got %+v", hidden.Seasons)
+	}
+}
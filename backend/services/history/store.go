@@ -0,0 +1,112 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+// Database collections holding the history data, per profile.
+const (
+	statesCollection           = "history_states"
+	watchedItemsCollection     = "watched_items"
+	playbackProgressCollection = "playback_progress"
+)
+
+// Store keeps history in the database instead of watch_history.json,
+// watched_items.json and playback_progress.json.
+type Store interface {
+	Imported(collections ...string) (bool, error)
+	Load(collection string) ([]database.Document, error)
+	Import(collections map[string][]database.Document, sources ...string) error
+	Save(collections map[string][]database.Document) error
+}
+
+var _ Store = (*database.DocumentStore)(nil)
+
+// useStore moves the history into store, importing the loaded JSON files
+// unless that was already done.
+func (s *Service) useStore(store Store) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported, err := store.Imported(statesCollection, watchedItemsCollection, playbackProgressCollection)
+	if err != nil {
+		return fmt.Errorf("check history import: %w", err)
+	}
+	if !imported {
+		collections := make(map[string][]database.Document, 3)
+		if collections[statesCollection], err = encodeDocuments(s.states); err != nil {
+			return err
+		}
+		if collections[watchedItemsCollection], err = encodeDocuments(s.watchHistory); err != nil {
+			return err
+		}
+		if collections[playbackProgressCollection], err = encodeDocuments(s.playbackProgress); err != nil {
+			return err
+		}
+		if err := store.Import(collections, s.path, s.watchHistPath, s.playbackProgressPath); err != nil {
+			return fmt.Errorf("import history: %w", err)
+		}
+		s.store = store
+		return nil
+	}
+
+	if s.states, err = loadDocuments[models.SeriesWatchState](store, statesCollection); err != nil {
+		return err
+	}
+	if s.watchHistory, err = loadDocuments[models.WatchHistoryItem](store, watchedItemsCollection); err != nil {
+		return err
+	}
+	if s.playbackProgress, err = loadDocuments[models.PlaybackProgress](store, playbackProgressCollection); err != nil {
+		return err
+	}
+	s.store = store
+	return nil
+}
+
+// saveDocuments writes one per-profile collection to store.
+func saveDocuments[T any](store Store, collection string, byUser map[string]map[string]T) error {
+	docs, err := encodeDocuments(byUser)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", collection, err)
+	}
+	return store.Save(map[string][]database.Document{collection: docs})
+}
+
+func encodeDocuments[T any](byUser map[string]map[string]T) ([]database.Document, error) {
+	var docs []database.Document
+	for userID, perUser := range byUser {
+		for key, value := range perUser {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("encode %s for %s: %w", key, userID, err)
+			}
+			docs = append(docs, database.Document{UserID: userID, Key: key, Data: data})
+		}
+	}
+	return docs, nil
+}
+
+func loadDocuments[T any](store Store, collection string) (map[string]map[string]T, error) {
+	docs, err := store.Load(collection)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", collection, err)
+	}
+	byUser := make(map[string]map[string]T)
+	for _, doc := range docs {
+		var value T
+		if err := json.Unmarshal(doc.Data, &value); err != nil {
+			return nil, fmt.Errorf("decode %s %s: %w", collection, doc.Key, err)
+		}
+		perUser, ok := byUser[doc.UserID]
+		if !ok {
+			perUser = make(map[string]T)
+			byUser[doc.UserID] = perUser
+		}
+		perUser[doc.Key] = value
+	}
+	return byUser, nil
+}
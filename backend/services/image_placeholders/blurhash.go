@@ -0,0 +1,171 @@
+package image_placeholders
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+const (
+	// Images are shrunk to at most this many pixels per side before they are
+	// summarised; placeholders are blurry by design
+	sampleSize = 32
+
+	// Dominant color buckets per channel
+	colorLevels = 8
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// pixels is a downsampled image in sRGB, row by row.
+type pixels struct {
+	width, height int
+	rgb           [][3]uint8
+}
+
+// sample averages img into at most sampleSize×sampleSize pixels.
+func sample(img image.Image) pixels {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= 0 || h <= 0 {
+		return pixels{}
+	}
+	scale := math.Max(float64(w), float64(h)) / sampleSize
+	if scale < 1 {
+		scale = 1
+	}
+	sw := max(1, int(math.Round(float64(w)/scale)))
+	sh := max(1, int(math.Round(float64(h)/scale)))
+
+	out := pixels{width: sw, height: sh, rgb: make([][3]uint8, sw*sh)}
+	for y := 0; y < sh; y++ {
+		y0, y1 := bounds.Min.Y+y*h/sh, bounds.Min.Y+(y+1)*h/sh
+		for x := 0; x < sw; x++ {
+			x0, x1 := bounds.Min.X+x*w/sw, bounds.Min.X+(x+1)*w/sw
+			var r, g, b, n uint64
+			for py := y0; py < max(y1, y0+1); py++ {
+				for px := x0; px < max(x1, x0+1); px++ {
+					cr, cg, cb, _ := img.At(px, py).RGBA()
+					r, g, b, n = r+uint64(cr>>8), g+uint64(cg>>8), b+uint64(cb>>8), n+1
+				}
+			}
+			out.rgb[y*sw+x] = [3]uint8{uint8(r / n), uint8(g / n), uint8(b / n)}
+		}
+	}
+	return out
+}
+
+// dominantColor returns the average of the most common color bucket as
+// #rrggbb.
+func dominantColor(p pixels) string {
+	if len(p.rgb) == 0 {
+		return ""
+	}
+	type bucket struct{ r, g, b, n int }
+	buckets := make(map[int]*bucket)
+	var best *bucket
+	for _, c := range p.rgb {
+		key := int(c[0])*colorLevels/256*colorLevels*colorLevels + int(c[1])*colorLevels/256*colorLevels + int(c[2])*colorLevels/256
+		bk := buckets[key]
+		if bk == nil {
+			bk = &bucket{}
+			buckets[key] = bk
+		}
+		bk.r, bk.g, bk.b, bk.n = bk.r+int(c[0]), bk.g+int(c[1]), bk.b+int(c[2]), bk.n+1
+		if best == nil || bk.n > best.n {
+			best = bk
+		}
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.n, best.g/best.n, best.b/best.n)
+}
+
+// blurHash encodes p with xComponents×yComponents components following the
+// reference BlurHash encoder.
+func blurHash(p pixels, xComponents, yComponents int) string {
+	if len(p.rgb) == 0 {
+		return ""
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var r, g, b float64
+			for y := 0; y < p.height; y++ {
+				for x := 0; x < p.width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(p.width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(p.height))
+					c := p.rgb[y*p.width+x]
+					r += basis * srgbToLinear(c[0])
+					g += basis * srgbToLinear(c[1])
+					b += basis * srgbToLinear(c[2])
+				}
+			}
+			scale := normalisation / float64(p.width*p.height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maximumValue = float64(quantisedMax+1) / 166
+		hash.WriteString(encode83(quantisedMax, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	hash.WriteString(encode83(linearToSrgb(dc[0])<<16+linearToSrgb(dc[1])<<8+linearToSrgb(dc[2]), 4))
+	for _, f := range ac {
+		hash.WriteString(encode83(encodeAC(f, maximumValue), 2))
+	}
+	return hash.String()
+}
+
+func encodeAC(f [3]float64, maximumValue float64) int {
+	quant := func(v float64) int {
+		return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+	}
+	return quant(f[0])*19*19 + quant(f[1])*19 + quant(f[2])
+}
+
+func encode83(value, length int) string {
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = base83Chars[value%83]
+		value /= 83
+	}
+	return string(out)
+}
+
+func srgbToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
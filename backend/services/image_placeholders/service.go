@@ -0,0 +1,171 @@
+// Package image_placeholders computes a dominant color and BlurHash for
+// artwork as the image proxy caches it, so title responses can carry a
+// placeholder clients paint before the image loads.
+package image_placeholders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// Placeholders are saved this long after the last new one so a screen full
+// of fresh artwork is written once
+const saveDelay = 5 * time.Second
+
+// tmdbSize matches the size segment of TMDB image URLs; every size of the
+// same artwork shares a placeholder.
+var tmdbSize = regexp.MustCompile(`(image\.tmdb\.org/t/p/)[^/]+/`)
+
+// Service stores placeholders keyed by artwork URL in a JSON file on disk.
+type Service struct {
+	mu        sync.RWMutex
+	path      string
+	records   map[string]models.ImagePlaceholder
+	saveTimer *time.Timer
+}
+
+// NewService constructs a placeholder service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create image placeholders dir: %w", err)
+	}
+
+	svc := &Service{
+		path:    filepath.Join(storageDir, "image_placeholders.json"),
+		records: make(map[string]models.ImagePlaceholder),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Compute summarises img as a dominant color and BlurHash. Portrait images
+// get more vertical components, landscape images more horizontal ones.
+func Compute(img image.Image) models.ImagePlaceholder {
+	p := sample(img)
+	xComponents, yComponents := 4, 3
+	if p.height > p.width {
+		xComponents, yComponents = 3, 4
+	}
+	return models.ImagePlaceholder{
+		Color:    dominantColor(p),
+		BlurHash: blurHash(p, xComponents, yComponents),
+	}
+}
+
+// Has reports whether url already has a placeholder.
+func (s *Service) Has(url string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.records[placeholderKey(url)]
+	return ok
+}
+
+// Record computes and stores the placeholder for the artwork at each of urls
+// (the requested URL and, when a fallback served it, the fallback's).
+func (s *Service) Record(img image.Image, urls ...string) {
+	placeholder := Compute(img)
+	if placeholder.Color == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, url := range urls {
+		if url != "" {
+			s.records[placeholderKey(url)] = placeholder
+		}
+	}
+	if s.saveTimer == nil {
+		s.saveTimer = time.AfterFunc(saveDelay, s.save)
+	}
+}
+
+// Attach returns img with its placeholder, if one has been computed. img is
+// never modified since it may be shared with the metadata cache.
+func (s *Service) Attach(img *models.Image) *models.Image {
+	if img == nil || img.URL == "" || img.Placeholder != nil {
+		return img
+	}
+
+	s.mu.RLock()
+	placeholder, ok := s.records[placeholderKey(img.URL)]
+	s.mu.RUnlock()
+	if !ok {
+		return img
+	}
+
+	attached := *img
+	attached.Placeholder = &placeholder
+	return &attached
+}
+
+// placeholderKey drops the parts of url that don't change the artwork.
+func placeholderKey(url string) string {
+	return tmdbSize.ReplaceAllString(strings.TrimSpace(url), "${1}")
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read image placeholders: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return fmt.Errorf("decode image placeholders: %w", err)
+	}
+	return nil
+}
+
+// save writes the placeholders to disk. A placeholder lost before it is
+// saved is recomputed the next time the proxy serves the image.
+func (s *Service) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveTimer = nil
+	data, err := json.Marshal(s.records)
+	if err != nil {
+		log.Printf("[image_placeholders] failed to encode placeholders: %v", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Printf("[image_placeholders] failed to save placeholders: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		log.Printf("[image_placeholders] failed to save placeholders: %v", err)
+	}
+}
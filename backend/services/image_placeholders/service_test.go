@@ -0,0 +1,92 @@
+package image_placeholders
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"novastream/models"
+)
+
+func solid(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestComputeSolidImage(t *testing.T) {
+	placeholder := Compute(solid(300, 200, color.RGBA{R: 255, A: 255}))
+
+	if placeholder.Color != "#ff0000" {
+		t.Errorf("Color = %q, want #ff0000", placeholder.Color)
+	}
+	// 4x3 components, then the pure red DC component and 11 AC components
+	if hash := placeholder.BlurHash; len(hash) != 28 || hash[0] != 'L' || hash[2:6] != "TI:j" {
+		t.Errorf("unexpected BlurHash %q", hash)
+	}
+}
+
+func TestComputeDominantColorAndPortraitComponents(t *testing.T) {
+	// A poster that is mostly blue with a red band
+	img := image.NewRGBA(image.Rect(0, 0, 200, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.RGBA{B: 200, A: 255}
+			if y < 60 {
+				c = color.RGBA{R: 220, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	placeholder := Compute(img)
+	if placeholder.Color != "#0000c8" {
+		t.Errorf("Color = %q, want the blue majority", placeholder.Color)
+	}
+	// 3x4 components: size flag 2+3*9, then 4 DC and 11 AC characters
+	if len(placeholder.BlurHash) != 28 || placeholder.BlurHash[0] != 'T' {
+		t.Errorf("unexpected BlurHash %q", placeholder.BlurHash)
+	}
+	if flat := Compute(solid(200, 300, color.RGBA{B: 200, A: 255})); strings.IndexByte(base83Chars, flat.BlurHash[1]) >= strings.IndexByte(base83Chars, placeholder.BlurHash[1]) {
+		t.Errorf("expected the banded poster to carry more AC energy than a flat one: %q vs %q", placeholder.BlurHash, flat.BlurHash)
+	}
+}
+
+func TestAttachSharesPlaceholderAcrossSizes(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.Record(solid(20, 30, color.RGBA{G: 255, A: 255}), "https://image.tmdb.org/t/p/w780/poster.jpg")
+	if !svc.Has("https://image.tmdb.org/t/p/original/poster.jpg") {
+		t.Fatal("expected every size of the poster to share the placeholder")
+	}
+
+	poster := &models.Image{URL: "https://image.tmdb.org/t/p/w500/poster.jpg", Type: "poster"}
+	attached := svc.Attach(poster)
+	if attached.Placeholder == nil || attached.Placeholder.Color != "#00ff00" {
+		t.Fatalf("expected the green placeholder, got %+v", attached.Placeholder)
+	}
+	if poster.Placeholder != nil {
+		t.Error("Attach modified the original image")
+	}
+	if other := svc.Attach(&models.Image{URL: "https://image.tmdb.org/t/p/w500/other.jpg"}); other.Placeholder != nil {
+		t.Errorf("expected no placeholder for uncached artwork, got %+v", other.Placeholder)
+	}
+
+	svc.save()
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.Has("https://image.tmdb.org/t/p/w780/poster.jpg") {
+		t.Error("expected placeholders to persist")
+	}
+}
@@ -0,0 +1,116 @@
+package indexer
+
+import (
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/filter"
+	"novastream/utils/releasename"
+)
+
+// estimateBitrateKbps returns the average bitrate implied by a release's size
+// spread over the given runtime, or 0 when either is unknown.
+func estimateBitrateKbps(sizeBytes int64, runtimeMinutes int) int {
+	if sizeBytes <= 0 || runtimeMinutes <= 0 {
+		return 0
+	}
+	seconds := float64(runtimeMinutes) * 60
+	return int(float64(sizeBytes) * 8 / seconds / 1000)
+}
+
+// releaseEpisodeCount returns how many episodes a release covers: 1 for a
+// movie or single episode, the pack size for season packs, or 0 when a pack's
+// size is unknown.
+func releaseEpisodeCount(result models.NZBResult, mediaType string, resolver filter.EpisodeCountResolver) int {
+	if result.EpisodeCount > 0 {
+		return result.EpisodeCount
+	}
+	if mediaType != "series" {
+		return 1
+	}
+
+	release := releasename.Parse(result.Title)
+	switch {
+	case len(release.Episodes) > 1:
+		return len(release.Episodes)
+	case len(release.Episodes) == 1:
+		return 1
+	case len(release.Seasons) == 0 && !release.Complete:
+		return 1
+	case resolver == nil:
+		return 0
+	case len(release.Seasons) > 0:
+		return resolver.GetEpisodesForSeasons(release.Seasons)
+	}
+	return resolver.GetTotalSeriesEpisodes()
+}
+
+// annotateBitrates fills EstimatedBitrateKbps for every result using the
+// per-item runtime from metadata. Packs are divided by their episode count.
+func annotateBitrates(results []models.NZBResult, opts SearchOptions) {
+	if opts.RuntimeMinutes <= 0 {
+		return
+	}
+	for i := range results {
+		episodes := releaseEpisodeCount(results[i], opts.MediaType, opts.EpisodeResolver)
+		if episodes <= 0 {
+			continue
+		}
+		results[i].EstimatedBitrateKbps = estimateBitrateKbps(results[i].SizeBytes, opts.RuntimeMinutes*episodes)
+	}
+}
+
+// effectiveBitrateBands returns the configured bands, or the defaults for
+// settings saved before bands existed.
+func effectiveBitrateBands(settings config.Settings) []config.BitrateBand {
+	if len(settings.Ranking.BitrateBands) > 0 {
+		return settings.Ranking.BitrateBands
+	}
+	return config.DefaultBitrateBands()
+}
+
+// bitrateBandDistance reports how far a bitrate falls outside the target band
+// for its resolution, as a fraction of the nearest band edge (0 inside the
+// band). ok is false when no band applies.
+func bitrateBandDistance(bitrateKbps, resolution int, bands []config.BitrateBand) (distance float64, ok bool) {
+	if bitrateKbps <= 0 || resolution <= 0 {
+		return 0, false
+	}
+	for _, band := range bands {
+		if band.Resolution != resolution || band.MinKbps <= 0 || band.MaxKbps < band.MinKbps {
+			continue
+		}
+		switch {
+		case bitrateKbps < band.MinKbps:
+			return float64(band.MinKbps-bitrateKbps) / float64(band.MinKbps), true
+		case bitrateKbps > band.MaxKbps:
+			return float64(bitrateKbps-band.MaxKbps) / float64(band.MaxKbps), true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// compareBitrate prefers the release whose estimated bitrate sits closer to
+// the target band for its resolution, then the higher bitrate. Results without
+// an estimate or band fall back to comparing raw size.
+func compareBitrate(i, j models.NZBResult, bands []config.BitrateBand) int {
+	distI, okI := bitrateBandDistance(i.EstimatedBitrateKbps, extractResolutionFromResult(i), bands)
+	distJ, okJ := bitrateBandDistance(j.EstimatedBitrateKbps, extractResolutionFromResult(j), bands)
+	if !okI || !okJ {
+		return compareSize(i, j)
+	}
+
+	if distI < distJ {
+		return -1
+	}
+	if distI > distJ {
+		return 1
+	}
+	if i.EstimatedBitrateKbps > j.EstimatedBitrateKbps {
+		return -1
+	}
+	if i.EstimatedBitrateKbps < j.EstimatedBitrateKbps {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/filter"
+)
+
+const gib = int64(1024 * 1024 * 1024)
+
+func TestEstimateBitrateKbps(t *testing.T) {
+	// 10 GiB over 120 minutes is roughly 11.9 Mbps
+	if got := estimateBitrateKbps(10*gib, 120); got != 11930 {
+		t.Errorf("estimateBitrateKbps(10GiB, 120) = %d, want 11930", got)
+	}
+	if got := estimateBitrateKbps(10*gib, 0); got != 0 {
+		t.Errorf("unknown runtime should give 0, got %d", got)
+	}
+	if got := estimateBitrateKbps(0, 120); got != 0 {
+		t.Errorf("unknown size should give 0, got %d", got)
+	}
+}
+
+func TestAnnotateBitratesDividesPacks(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Show.S01E01.1080p.WEB-DL.x264-GRP", SizeBytes: 2 * gib},
+		{Title: "Show.S01.1080p.WEB-DL.x264-GRP", SizeBytes: 20 * gib},
+		{Title: "Other.S02.1080p.WEB-DL.x264-GRP", SizeBytes: 20 * gib},
+	}
+	opts := SearchOptions{
+		MediaType:       "series",
+		RuntimeMinutes:  45,
+		EpisodeResolver: filter.NewSeriesEpisodeResolver(map[int]int{1: 10}),
+	}
+
+	annotateBitrates(results, opts)
+
+	single := results[0].EstimatedBitrateKbps
+	if single == 0 {
+		t.Fatal("expected an estimate for the single episode")
+	}
+	if results[1].EstimatedBitrateKbps != single {
+		t.Errorf("10-episode pack of 20GiB should match a 2GiB episode: got %d, want %d", results[1].EstimatedBitrateKbps, single)
+	}
+	if results[2].EstimatedBitrateKbps != 0 {
+		t.Errorf("pack with unknown episode count should have no estimate, got %d", results[2].EstimatedBitrateKbps)
+	}
+}
+
+func TestCompareBitratePrefersTargetBand(t *testing.T) {
+	bands := config.DefaultBitrateBands()
+
+	bloated := models.NZBResult{Title: "Movie.2024.1080p.BluRay.REMUX-GRP", SizeBytes: 40 * gib, EstimatedBitrateKbps: 35000}
+	inBand := models.NZBResult{Title: "Movie.2024.1080p.BluRay.x264-GRP", SizeBytes: 12 * gib, EstimatedBitrateKbps: 12000}
+	starved := models.NZBResult{Title: "Movie.2024.1080p.WEB.x264-GRP", SizeBytes: 2 * gib, EstimatedBitrateKbps: 2000}
+
+	if got := compareBitrate(inBand, bloated, bands); got != -1 {
+		t.Errorf("in-band release should beat a bloated one, got %d", got)
+	}
+	if got := compareBitrate(inBand, starved, bands); got != -1 {
+		t.Errorf("in-band release should beat a starved one, got %d", got)
+	}
+
+	higher := inBand
+	higher.EstimatedBitrateKbps = 18000
+	if got := compareBitrate(higher, inBand, bands); got != -1 {
+		t.Errorf("within the band the higher bitrate should win, got %d", got)
+	}
+
+	// Without estimates the raw size heuristic still applies
+	bloated.EstimatedBitrateKbps = 0
+	if got := compareBitrate(bloated, inBand, bands); got != -1 {
+		t.Errorf("without an estimate the larger release should win, got %d", got)
+	}
+}
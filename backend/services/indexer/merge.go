@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"log"
+	"strings"
+
+	"novastream/models"
+)
+
+const (
+	// duplicateSizeTolerance is how far apart, as a fraction of the larger
+	// size, two copies of a release may be and still count as the same post
+	duplicateSizeTolerance = 0.01
+
+	// maxSameSourceRun is how many results in a row may come from one indexer
+	// before a nearby result from another indexer is moved up
+	maxSameSourceRun = 2
+	// sourceDiversityLookahead bounds how far down a result may be moved up from
+	sourceDiversityLookahead = 4
+)
+
+// releaseDedupKey normalises a release name so copies listed by different
+// indexers with different separators, case or an .nzb suffix compare equal.
+func releaseDedupKey(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	title = strings.TrimSuffix(title, ".nzb")
+	var b strings.Builder
+	b.Grow(len(title))
+	for _, r := range title {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sameReleaseSize reports whether two sizes belong to the same post. Copies
+// only match when both sizes are known and close, or both are unknown.
+func sameReleaseSize(a, b int64) bool {
+	if a <= 0 || b <= 0 {
+		return a <= 0 && b <= 0
+	}
+	larger, diff := a, a-b
+	if b > a {
+		larger, diff = b, b-a
+	}
+	return float64(diff) <= float64(larger)*duplicateSizeTolerance
+}
+
+// mergeDuplicateReleases folds copies of the same release (same normalised
+// name and size) into the first one listed, keeping the others as its
+// alternates so resolution can fall back to another indexer's copy. Attributes
+// only an alternate reported are copied onto the kept result.
+func mergeDuplicateReleases(results []models.NZBResult) []models.NZBResult {
+	if len(results) < 2 {
+		return results
+	}
+
+	merged := make([]models.NZBResult, 0, len(results))
+	byKey := make(map[string][]int, len(results))
+	duplicates := 0
+	for _, result := range results {
+		key := releaseDedupKey(result.Title)
+		if key == "" {
+			merged = append(merged, result)
+			continue
+		}
+
+		primary := -1
+		for _, idx := range byKey[key] {
+			if sameReleaseSize(merged[idx].SizeBytes, result.SizeBytes) {
+				primary = idx
+				break
+			}
+		}
+		if primary < 0 {
+			byKey[key] = append(byKey[key], len(merged))
+			merged = append(merged, result)
+			continue
+		}
+
+		duplicates++
+		kept := &merged[primary]
+		if sameDownload(*kept, result) {
+			continue
+		}
+		for k, v := range result.Attributes {
+			if _, ok := kept.Attributes[k]; ok {
+				continue
+			}
+			if kept.Attributes == nil {
+				kept.Attributes = make(map[string]string)
+			}
+			kept.Attributes[k] = v
+		}
+		alt := result
+		alt.Alternates = nil
+		kept.Alternates = append(kept.Alternates, alt)
+	}
+
+	if duplicates > 0 {
+		log.Printf("[indexer] merged %d duplicate release(s) into %d result(s)", duplicates, len(merged))
+	}
+	return merged
+}
+
+// sameDownload reports whether result is the kept result or one of its
+// alternates, i.e. the same NZB listed twice.
+func sameDownload(kept, result models.NZBResult) bool {
+	url := downloadURLOf(result)
+	if url == "" {
+		return false
+	}
+	if downloadURLOf(kept) == url {
+		return true
+	}
+	for _, alt := range kept.Alternates {
+		if downloadURLOf(alt) == url {
+			return true
+		}
+	}
+	return false
+}
+
+func downloadURLOf(result models.NZBResult) string {
+	if url := strings.TrimSpace(result.DownloadURL); url != "" {
+		return url
+	}
+	return strings.TrimSpace(result.Link)
+}
+
+// diversifySources reorders ranked results so one indexer doesn't supply a
+// long run of top candidates: after maxSameSourceRun results in a row from
+// one indexer, the next result from another indexer at the same resolution
+// within sourceDiversityLookahead places is moved up. Fallbacks then come from
+// a different source when the top indexer's copies are dead, while results
+// only move a few places and never past a different resolution.
+func diversifySources(results []models.NZBResult) {
+	if len(results) <= maxSameSourceRun {
+		return
+	}
+
+	sources := make(map[string]struct{})
+	for _, result := range results {
+		sources[resultSource(result)] = struct{}{}
+	}
+	if len(sources) < 2 {
+		return
+	}
+
+	moved := 0
+	for i := maxSameSourceRun; i < len(results); i++ {
+		source := resultSource(results[i])
+		run := true
+		for j := i - maxSameSourceRun; j < i; j++ {
+			if resultSource(results[j]) != source {
+				run = false
+				break
+			}
+		}
+		if !run {
+			continue
+		}
+
+		resolution := extractResolutionFromResult(results[i])
+		for j := i + 1; j < len(results) && j <= i+sourceDiversityLookahead; j++ {
+			if extractResolutionFromResult(results[j]) != resolution {
+				break
+			}
+			if resultSource(results[j]) == source {
+				continue
+			}
+			// Shift i..j-1 down one place and put j at i
+			candidate := results[j]
+			copy(results[i+1:j+1], results[i:j])
+			results[i] = candidate
+			moved++
+			break
+		}
+	}
+
+	if moved > 0 {
+		log.Printf("[indexer] moved up %d result(s) from other indexers for source diversity", moved)
+	}
+}
+
+// resultSource identifies the indexer or scraper a result came from.
+func resultSource(result models.NZBResult) string {
+	return string(result.ServiceType) + "|" + strings.ToLower(strings.TrimSpace(result.Indexer))
+}
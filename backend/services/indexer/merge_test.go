@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestMergeDuplicateReleases(t *testing.T) {
+	const gb = int64(1) << 30
+	results := []models.NZBResult{
+		{Title: "Show.S01E01.1080p.WEB.h264-GRP", Indexer: "geek", DownloadURL: "https://geek/1", SizeBytes: 2 * gb},
+		{Title: "Show S01E01 1080p WEB H264-GRP.nzb", Indexer: "planet", DownloadURL: "https://planet/9", SizeBytes: 2*gb + 1<<20,
+			Attributes: map[string]string{"grabs": "40"}},
+		{Title: "Show.S01E01.1080p.WEB.h264-GRP", Indexer: "geek", DownloadURL: "https://geek/1", SizeBytes: 2 * gb},
+		{Title: "Show.S01E01.1080p.WEB.h264-GRP", Indexer: "other", DownloadURL: "https://other/3", SizeBytes: 3 * gb},
+		{Title: "Show.S01E01.720p.WEB.h264-GRP", Indexer: "planet", DownloadURL: "https://planet/10", SizeBytes: gb},
+	}
+
+	merged := mergeDuplicateReleases(results)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 results after merging, got %d: %+v", len(merged), merged)
+	}
+
+	kept := merged[0]
+	if kept.Indexer != "geek" || len(kept.Alternates) != 1 || kept.Alternates[0].Indexer != "planet" {
+		t.Fatalf("expected the geek copy kept with the planet copy as its alternate, got %+v", kept)
+	}
+	if kept.Attributes["grabs"] != "40" {
+		t.Errorf("expected attributes from the alternate to be merged, got %v", kept.Attributes)
+	}
+	if merged[1].Indexer != "other" || len(merged[1].Alternates) != 0 {
+		t.Errorf("a copy with a different size must not be merged, got %+v", merged[1])
+	}
+
+	copies := kept.Copies()
+	if len(copies) != 2 || copies[0].Alternates != nil || copies[1].DownloadURL != "https://planet/9" {
+		t.Fatalf("unexpected copies: %+v", copies)
+	}
+}
+
+func TestDiversifySources(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2024.2160p.REMUX-A", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-B", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-C", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-D", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-E", Indexer: "planet"},
+		{Title: "Movie.2024.1080p.WEB-F", Indexer: "planet"},
+	}
+
+	diversifySources(results)
+
+	want := []string{"A", "B", "E", "C", "D", "F"}
+	for i, group := range want {
+		if got := results[i].Title[len(results[i].Title)-1:]; got != group {
+			t.Fatalf("position %d: got %s want %s (order %v)", i, results[i].Title, group, results)
+		}
+	}
+
+	// Results never move up past a different resolution
+	results = []models.NZBResult{
+		{Title: "Movie.2024.2160p.WEB-A", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-B", Indexer: "geek"},
+		{Title: "Movie.2024.2160p.WEB-C", Indexer: "geek"},
+		{Title: "Movie.2024.1080p.WEB-D", Indexer: "planet"},
+	}
+	diversifySources(results)
+	if results[2].Title != "Movie.2024.2160p.WEB-C" {
+		t.Fatalf("expected a lower resolution release to stay behind, got %v", results)
+	}
+}
@@ -0,0 +1,98 @@
+package indexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"novastream/config"
+	"novastream/services/debrid"
+	"novastream/utils/releasename"
+)
+
+// indexerCategories returns the newznab categories to search idx with: the
+// indexer's mapping for the search's media type, then its general categories,
+// then the categories requested by the caller.
+func indexerCategories(idx config.IndexerConfig, opts SearchOptions) string {
+	switch searchMediaType(opts) {
+	case debrid.MediaTypeMovie:
+		if cats := strings.TrimSpace(idx.MovieCategories); cats != "" {
+			return cats
+		}
+	case debrid.MediaTypeSeries:
+		if cats := strings.TrimSpace(idx.TVCategories); cats != "" {
+			return cats
+		}
+	}
+	if cats := strings.TrimSpace(idx.Categories); cats != "" {
+		return cats
+	}
+	return strings.Join(opts.Categories, ",")
+}
+
+// applyQueryTemplate rewrites the search query in the indexer's configured
+// format for episode, season pack and movie searches. Queries with no
+// matching template are returned unchanged.
+func applyQueryTemplate(idx config.IndexerConfig, opts SearchOptions) string {
+	query := strings.TrimSpace(opts.Query)
+	if query == "" {
+		return query
+	}
+
+	parsed := debrid.ParseQuery(query)
+	season, episode := parsed.Season, parsed.Episode
+	title := parsed.Title
+	if season == 0 {
+		// Season packs name a single season and no episode
+		if release := releasename.Parse(query); len(release.Seasons) == 1 && len(release.Episodes) == 0 {
+			season = release.Seasons[0]
+		}
+	}
+	if title == "" {
+		return query
+	}
+
+	var template string
+	switch {
+	case season > 0 && episode > 0:
+		template = idx.EpisodeQueryTemplate
+	case season > 0:
+		template = idx.SeasonQueryTemplate
+	case searchMediaType(opts) == debrid.MediaTypeMovie:
+		template = idx.MovieQueryTemplate
+	}
+	if strings.TrimSpace(template) == "" {
+		return query
+	}
+
+	year := parsed.Year
+	if year == 0 {
+		year = opts.Year
+	}
+	yearStr := ""
+	if year > 0 {
+		yearStr = strconv.Itoa(year)
+	}
+
+	rendered := strings.NewReplacer(
+		"{title}", title,
+		"{year}", yearStr,
+		"{season}", strconv.Itoa(season),
+		"{episode}", strconv.Itoa(episode),
+		"{ss}", fmt.Sprintf("%02d", season),
+		"{ee}", fmt.Sprintf("%02d", episode),
+	).Replace(template)
+	return strings.Join(strings.Fields(rendered), " ")
+}
+
+// searchMediaType returns the media type of a search, preferring the caller's
+// explicit type over what can be inferred from the query.
+func searchMediaType(opts SearchOptions) debrid.MediaType {
+	switch strings.ToLower(strings.TrimSpace(opts.MediaType)) {
+	case "movie", "movies", "film", "films":
+		return debrid.MediaTypeMovie
+	case "series", "tv", "show", "episode":
+		return debrid.MediaTypeSeries
+	}
+	return debrid.ParseQuery(opts.Query).MediaType
+}
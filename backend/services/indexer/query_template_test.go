@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"testing"
+
+	"novastream/config"
+)
+
+func TestApplyQueryTemplate(t *testing.T) {
+	idx := config.IndexerConfig{
+		EpisodeQueryTemplate: "{title} {season}x{ee}",
+		SeasonQueryTemplate:  "{title} Season {season}",
+		MovieQueryTemplate:   "{title} ({year})",
+	}
+
+	tests := []struct {
+		name string
+		idx  config.IndexerConfig
+		opts SearchOptions
+		want string
+	}{
+		{"episode", idx, SearchOptions{Query: "The Office S02E05", MediaType: "series"}, "The Office 2x05"},
+		{"season pack", idx, SearchOptions{Query: "The Office S02", MediaType: "series"}, "The Office Season 2"},
+		{"movie uses search year", idx, SearchOptions{Query: "Heat", MediaType: "movie", Year: 1995}, "Heat (1995)"},
+		{"movie year in query", idx, SearchOptions{Query: "Heat 1995", MediaType: "movie"}, "Heat (1995)"},
+		{"series without episode unchanged", idx, SearchOptions{Query: "The Daily Show 2026.01.21", MediaType: "series"}, "The Daily Show 2026.01.21"},
+		{"no template unchanged", config.IndexerConfig{}, SearchOptions{Query: "The Office S02E05"}, "The Office S02E05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyQueryTemplate(tt.idx, tt.opts); got != tt.want {
+				t.Errorf("applyQueryTemplate(%q) = %q, want %q", tt.opts.Query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexerCategoriesByMediaType(t *testing.T) {
+	idx := config.IndexerConfig{Categories: "2000,5000", MovieCategories: "2040", TVCategories: "5040,5045"}
+
+	if got := indexerCategories(idx, SearchOptions{Query: "Heat", MediaType: "movie"}); got != "2040" {
+		t.Errorf("movie categories = %q, want 2040", got)
+	}
+	if got := indexerCategories(idx, SearchOptions{Query: "The Office S02E05"}); got != "5040,5045" {
+		t.Errorf("tv categories = %q, want 5040,5045", got)
+	}
+
+	idx.TVCategories = ""
+	if got := indexerCategories(idx, SearchOptions{Query: "The Office", MediaType: "series"}); got != "2000,5000" {
+		t.Errorf("unmapped categories = %q, want 2000,5000", got)
+	}
+	if got := indexerCategories(config.IndexerConfig{}, SearchOptions{Categories: []string{"5000", "5030"}}); got != "5000,5030" {
+		t.Errorf("fallback categories = %q, want 5000,5030", got)
+	}
+}
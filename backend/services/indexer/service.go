@@ -22,7 +22,9 @@ import (
 	"novastream/models"
 	"novastream/services/debrid"
 	"novastream/utils/filter"
+	"novastream/utils/inflight"
 	"novastream/utils/language"
+	"novastream/utils/releasename"
 
 	"github.com/mozillazg/go-unidecode"
 )
@@ -91,6 +93,11 @@ type (
 	metadataSearchService interface {
 		Search(context.Context, string, string) ([]models.SearchResult, error)
 	}
+
+	// releaseRecorder indexes the titles searches found releases for
+	releaseRecorder interface {
+		RecordReleases(imdbID, title string, year int, releases []models.NZBResult)
+	}
 )
 
 type Service struct {
@@ -101,6 +108,8 @@ type Service struct {
 	metadata       metadataSearchService
 	userSettings   userSettingsProvider
 	clientSettings clientSettingsProvider
+	releases       releaseRecorder
+	inflight       inflight.Group[[]models.NZBResult]
 }
 
 func NewService(cfg *config.Manager, metadataSvc metadataSearchService, debridSvc debridSearchService) *Service {
@@ -126,6 +135,28 @@ func (s *Service) SetClientSettingsProvider(provider clientSettingsProvider) {
 	s.clientSettings = provider
 }
 
+// SetReleaseRecorder sets the index fed with titles that searches found
+// releases for, used for availability hints on browse screens.
+func (s *Service) SetReleaseRecorder(recorder releaseRecorder) {
+	s.releases = recorder
+}
+
+// recordReleases tells the release recorder, if any, what a search found.
+func (s *Service) recordReleases(opts SearchOptions, parsed debrid.ParsedQuery, results []models.NZBResult) {
+	if s.releases == nil || len(results) == 0 {
+		return
+	}
+	title := parsed.Title
+	if title == "" {
+		title = opts.Query
+	}
+	year := opts.Year
+	if year == 0 {
+		year = parsed.Year
+	}
+	s.releases.RecordReleases(opts.IMDBID, title, year, results)
+}
+
 // getEffectiveFilterSettings returns the filtering settings to use for a search.
 // Settings cascade: Global -> Profile -> Client (client settings win)
 func (s *Service) getEffectiveFilterSettings(userID, clientID string, globalSettings config.Settings) models.FilterSettings {
@@ -251,6 +282,18 @@ func (s *Service) getEffectiveRankingCriteria(userID, clientID string, globalSet
 	return criteria
 }
 
+// rankingContext gathers the ranking configuration for a search.
+func (s *Service) rankingContext(opts SearchOptions, settings config.Settings, filterSettings models.FilterSettings) RankingContext {
+	return RankingContext{
+		Criteria:        s.getEffectiveRankingCriteria(opts.UserID, opts.ClientID, settings),
+		ServicePriority: settings.Streaming.ServicePriority,
+		PreferredTerms:  filterSettings.PreferredTerms,
+		PrioritizeHDR:   models.BoolVal(filterSettings.PrioritizeHdr, false),
+		PreferredLang:   settings.Metadata.Language,
+		BitrateBands:    effectiveBitrateBands(settings),
+	}
+}
+
 // applyUserRankingOverrides applies user-level ranking overrides to the base criteria.
 func applyUserRankingOverrides(base []config.RankingCriterion, overrides []models.UserRankingCriterion) []config.RankingCriterion {
 	result := make([]config.RankingCriterion, len(base))
@@ -411,6 +454,7 @@ type SearchOptions struct {
 	IsAnime               bool                        // True for anime content - requires waiting for Nyaa scraper
 	IsDaily               bool                        // True for daily shows (talk shows, news) that use date-based naming
 	TargetAirDate         string                      // For daily shows: air date in YYYY-MM-DD format
+	RuntimeMinutes        int                         // Optional: movie or episode runtime from metadata, used to estimate bitrates
 }
 
 func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBResult, error) {
@@ -540,6 +584,9 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 		return nil, lastErr
 	}
 
+	annotateBitrates(aggregated, opts)
+	s.recordReleases(opts, parsedQuery, aggregated)
+
 	// Check if ranking should be bypassed for AIOStreams-only mode
 	// Only bypass when: setting is enabled, AIOStreams is the only scraper, and no usenet results are mixed in
 	bypassRanking := settings.Filtering.BypassFilteringForAIOStreamsOnly &&
@@ -549,50 +596,17 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 	if bypassRanking {
 		log.Printf("[indexer] Bypassing strmr ranking - AIOStreams is the only enabled scraper and bypass setting is enabled")
 	} else {
-		// Get effective ranking criteria (cascade: global -> profile -> client)
-		rankingCriteria := s.getEffectiveRankingCriteria(opts.UserID, opts.ClientID, settings)
-		log.Printf("[indexer] Sorting %d results with %d ranking criteria, ServicePriority=%q", len(aggregated), len(rankingCriteria), settings.Streaming.ServicePriority)
-
-		// Cache settings needed for comparison functions
-		servicePriority := settings.Streaming.ServicePriority
-		preferredTerms := filterSettings.PreferredTerms
-		prioritizeHdr := models.BoolVal(filterSettings.PrioritizeHdr, false)
-		preferredLang := settings.Metadata.Language
-
-		sort.SliceStable(aggregated, func(i, j int) bool {
-			for _, criterion := range rankingCriteria {
-				if !criterion.Enabled {
-					continue
-				}
-
-				var result int
-				switch criterion.ID {
-				case config.RankingServicePriority:
-					result = compareServicePriority(aggregated[i], aggregated[j], servicePriority)
-				case config.RankingPreferredTerms:
-					result = comparePreferredTerms(aggregated[i], aggregated[j], preferredTerms)
-				case config.RankingResolution:
-					result = compareResolution(aggregated[i], aggregated[j])
-				case config.RankingHDR:
-					result = compareHDR(aggregated[i], aggregated[j], prioritizeHdr)
-				case config.RankingLanguage:
-					result = compareLanguage(aggregated[i], aggregated[j], preferredLang)
-				case config.RankingSize:
-					result = compareSize(aggregated[i], aggregated[j])
-				}
-
-				if result != 0 {
-					return result < 0
-				}
-			}
-			return false
-		})
+		rc := s.rankingContext(opts, settings, filterSettings)
+		strategyName := s.selectionStrategyFor(opts.UserID, settings)
+		log.Printf("[indexer] Sorting %d results with strategy %q, %d ranking criteria, ServicePriority=%q", len(aggregated), strategyName, len(rc.Criteria), settings.Streaming.ServicePriority)
+		sortResults(aggregated, newSelectionStrategy(strategyName, rc))
+		diversifySources(aggregated)
 	}
 
 	// Debug: log top results after sorting
 	for idx := 0; idx < len(aggregated) && idx < 5; idx++ {
 		res := extractResolutionFromResult(aggregated[idx])
-		log.Printf("[indexer] Result #%d: ServiceType=%q Resolution=%d Size=%d Title=%q", idx, aggregated[idx].ServiceType, res, aggregated[idx].SizeBytes, aggregated[idx].Title)
+		log.Printf("[indexer] Result #%d: ServiceType=%q Resolution=%d Size=%d Bitrate=%dkbps Title=%q", idx, aggregated[idx].ServiceType, res, aggregated[idx].SizeBytes, aggregated[idx].EstimatedBitrateKbps, aggregated[idx].Title)
 	}
 
 	if opts.MaxResults > 0 && len(aggregated) > opts.MaxResults {
@@ -650,44 +664,16 @@ func (s *Service) SearchSplit(ctx context.Context, opts SearchOptions) (debridCh
 	includeUsenet := shouldUseUsenet(settings.Streaming.ServiceMode)
 	includeDebrid := shouldUseDebrid(settings.Streaming.ServiceMode)
 
-	// Prepare ranking criteria and settings for sorting (same as main Search function)
-	rankingCriteria := s.getEffectiveRankingCriteria(opts.UserID, opts.ClientID, settings)
-	servicePriority := settings.Streaming.ServicePriority
-	preferredTerms := filterSettings.PreferredTerms
-	prioritizeHdr := models.BoolVal(filterSettings.PrioritizeHdr, false)
-	preferredLang := settings.Metadata.Language
-
-	// Helper to apply ranking sort to results
+	// Rank with the same strategy as the main Search function
+	strategy := newSelectionStrategy(s.selectionStrategyFor(opts.UserID, settings), s.rankingContext(opts, settings, filterSettings))
 	applyRanking := func(results []models.NZBResult) {
 		if len(results) == 0 {
 			return
 		}
-		sort.SliceStable(results, func(i, j int) bool {
-			for _, criterion := range rankingCriteria {
-				if !criterion.Enabled {
-					continue
-				}
-				var result int
-				switch criterion.ID {
-				case config.RankingServicePriority:
-					result = compareServicePriority(results[i], results[j], servicePriority)
-				case config.RankingPreferredTerms:
-					result = comparePreferredTerms(results[i], results[j], preferredTerms)
-				case config.RankingResolution:
-					result = compareResolution(results[i], results[j])
-				case config.RankingHDR:
-					result = compareHDR(results[i], results[j], prioritizeHdr)
-				case config.RankingLanguage:
-					result = compareLanguage(results[i], results[j], preferredLang)
-				case config.RankingSize:
-					result = compareSize(results[i], results[j])
-				}
-				if result != 0 {
-					return result < 0
-				}
-			}
-			return false
-		})
+		annotateBitrates(results, opts)
+		s.recordReleases(opts, parsedQuery, results)
+		sortResults(results, strategy)
+		diversifySources(results)
 	}
 
 	// Launch debrid search
@@ -1175,7 +1161,7 @@ func (s *Service) fetchUsenetResults(ctx context.Context, settings config.Settin
 
 		switch strings.ToLower(strings.TrimSpace(idx.Type)) {
 		case "", "newznab", "torznab":
-			results, err := s.searchTorznab(ctx, idx, opts)
+			results, err := s.searchTorznabCoalesced(ctx, idx, opts)
 			if err != nil {
 				lastErr = err
 				continue
@@ -1193,7 +1179,31 @@ func (s *Service) fetchUsenetResults(ctx context.Context, settings config.Settin
 	if len(allResults) == 0 && lastErr != nil {
 		return nil, lastErr
 	}
-	return allResults, nil
+	return mergeDuplicateReleases(allResults), nil
+}
+
+// searchTorznabCoalesced runs searchTorznab, sharing one request between
+// concurrent identical searches of the same indexer. Each caller gets its
+// own copy of the results to filter.
+func (s *Service) searchTorznabCoalesced(ctx context.Context, idx config.IndexerConfig, opts SearchOptions) ([]models.NZBResult, error) {
+	key := strings.Join([]string{
+		idx.Name,
+		idx.URL,
+		strings.Join(strings.Fields(strings.ToLower(opts.Query)), " "),
+		strings.Join(opts.Categories, ","),
+		strings.ToLower(opts.MediaType),
+		strconv.Itoa(opts.Year),
+	}, "|")
+	results, shared, err := s.inflight.Do(ctx, key, func(runCtx context.Context) ([]models.NZBResult, error) {
+		return s.searchTorznab(runCtx, idx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		log.Printf("[indexer/newznab] joined in-flight search of %s for %q", idx.Name, opts.Query)
+	}
+	return models.CloneNZBResults(results), nil
 }
 
 // applyUsenetFilteringWithSettings applies filtering using explicit filter settings (for per-user filtering)
@@ -1331,19 +1341,22 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 	params.Set("apikey", idx.APIKey)
 	params.Set("t", "search")
 	if opts.Query != "" {
+		// Rewrite the query in the indexer's own format when it has a template for this kind of search
+		query := applyQueryTemplate(idx, opts)
+		if query != opts.Query {
+			log.Printf("[indexer/newznab] applied query template for %s: %q -> %q", idx.Name, opts.Query, query)
+		}
 		// Sanitize query to remove special characters that break newznab/torznab searches
-		sanitizedQuery := sanitizeNewznabQuery(opts.Query)
+		sanitizedQuery := sanitizeNewznabQuery(query)
 		params.Set("q", sanitizedQuery)
-		if sanitizedQuery != opts.Query {
-			log.Printf("[indexer/newznab] sanitized query for %s: %q -> %q", idx.Name, opts.Query, sanitizedQuery)
+		if sanitizedQuery != query {
+			log.Printf("[indexer/newznab] sanitized query for %s: %q -> %q", idx.Name, query, sanitizedQuery)
 		}
 	}
-	// Use indexer-specific categories if configured, otherwise fall back to search options
-	if cats := strings.TrimSpace(idx.Categories); cats != "" {
+	// Use indexer-specific categories (per media type, then general) if configured, otherwise fall back to search options
+	if cats := indexerCategories(idx, opts); cats != "" {
 		params.Set("cat", cats)
-		log.Printf("[indexer/newznab] using configured categories for %s: %s", idx.Name, cats)
-	} else if len(opts.Categories) > 0 {
-		params.Set("cat", strings.Join(opts.Categories, ","))
+		log.Printf("[indexer/newznab] using categories for %s: %s", idx.Name, cats)
 	}
 
 	searchURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join(u.Path, "")}
@@ -1473,65 +1486,10 @@ func dedupe(items []string) []string {
 // It first checks the "resolution" attribute (set by scrapers like AIOStreams),
 // then falls back to parsing the title.
 func extractResolutionFromResult(result models.NZBResult) int {
-	// First check the resolution attribute (set by AIOStreams and other scrapers)
-	if resAttr := result.Attributes["resolution"]; resAttr != "" {
-		res := parseResolutionString(resAttr)
-		if res > 0 {
-			return res
-		}
+	if res := releasename.ParseResolution(result.Attributes["resolution"]); res > 0 {
+		return res
 	}
-	// Fall back to extracting from title
-	return extractResolution(result.Title)
-}
-
-// parseResolutionString converts a resolution string like "2160p", "1080p", "4K" to a numeric value.
-func parseResolutionString(res string) int {
-	res = strings.ToLower(strings.TrimSpace(res))
-	switch {
-	case strings.Contains(res, "2160") || strings.Contains(res, "4k") || strings.Contains(res, "uhd"):
-		return 2160
-	case strings.Contains(res, "1080"):
-		return 1080
-	case strings.Contains(res, "720"):
-		return 720
-	case strings.Contains(res, "576"):
-		return 576
-	case strings.Contains(res, "480"):
-		return 480
-	default:
-		return 0
-	}
-}
-
-// extractResolution extracts resolution from the title using simple regex patterns.
-// Returns a numeric value representing resolution priority (higher is better).
-// Common resolutions: 2160p/4K (2160), 1080p (1080), 720p (720), 480p (480), etc.
-func extractResolution(title string) int {
-	title = strings.ToLower(title)
-
-	// Check for 4K/UHD (highest priority)
-	if strings.Contains(title, "2160p") || strings.Contains(title, "4k") || strings.Contains(title, "uhd") {
-		return 2160
-	}
-	// Check for 1080p
-	if strings.Contains(title, "1080p") || strings.Contains(title, "1080i") {
-		return 1080
-	}
-	// Check for 720p
-	if strings.Contains(title, "720p") || strings.Contains(title, "720i") {
-		return 720
-	}
-	// Check for 576p (PAL)
-	if strings.Contains(title, "576p") || strings.Contains(title, "576i") {
-		return 576
-	}
-	// Check for 480p (NTSC)
-	if strings.Contains(title, "480p") || strings.Contains(title, "480i") {
-		return 480
-	}
-
-	// Default (no resolution detected)
-	return 0
+	return releasename.Parse(result.Title).Resolution
 }
 
 // isOnlyAIOStreamsEnabled returns true if AIOStreams is the only enabled scraper in the config.
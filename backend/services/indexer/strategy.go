@@ -0,0 +1,226 @@
+package indexer
+
+import (
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+// SelectionStrategy orders search results for automatic release selection.
+// Playback starts with the first result that resolves, so the order decides
+// which release a user gets.
+type SelectionStrategy interface {
+	// Compare returns -1 if a should be tried before b, 1 if after, 0 if tied.
+	Compare(a, b models.NZBResult) int
+}
+
+// RankingContext carries the effective ranking configuration for a search.
+type RankingContext struct {
+	Criteria        []config.RankingCriterion
+	ServicePriority config.StreamingServicePriority
+	PreferredTerms  []string
+	PrioritizeHDR   bool
+	PreferredLang   string
+	BitrateBands    []config.BitrateBand
+}
+
+// StrategyFactory builds a strategy for one search.
+type StrategyFactory func(rc RankingContext) SelectionStrategy
+
+var selectionStrategies = map[string]StrategyFactory{
+	config.SelectionStrategyHeuristic: func(rc RankingContext) SelectionStrategy {
+		return heuristicStrategy{rc: rc}
+	},
+	config.SelectionStrategySizeFirst: func(rc RankingContext) SelectionStrategy {
+		return primaryThenHeuristic{primary: compareSize, rc: rc}
+	},
+	config.SelectionStrategyBitrateFit: func(rc RankingContext) SelectionStrategy {
+		return primaryThenHeuristic{
+			primary: func(a, b models.NZBResult) int { return compareBitrate(a, b, rc.BitrateBands) },
+			rc:      rc,
+		}
+	},
+	config.SelectionStrategyCachedFirst: func(rc RankingContext) SelectionStrategy {
+		return primaryThenHeuristic{primary: compareCached, rc: rc}
+	},
+}
+
+// RegisterSelectionStrategy adds or replaces a named strategy. It must be
+// called before searches run, typically from an init function.
+func RegisterSelectionStrategy(name string, factory StrategyFactory) {
+	selectionStrategies[strings.ToLower(strings.TrimSpace(name))] = factory
+}
+
+// SelectionStrategyNames lists the registered strategies.
+func SelectionStrategyNames() []string {
+	names := make([]string, 0, len(selectionStrategies))
+	for name := range selectionStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsSelectionStrategy reports whether name is a registered strategy.
+func IsSelectionStrategy(name string) bool {
+	_, ok := selectionStrategies[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// SelectionStrategyFor returns the strategy a profile's searches use: the
+// profile's own choice, else its stable assignment to one arm of the
+// configured experiment, else the global strategy.
+func (s *Service) SelectionStrategyFor(userID string) string {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return config.SelectionStrategyHeuristic
+	}
+	return s.selectionStrategyFor(userID, settings)
+}
+
+func (s *Service) selectionStrategyFor(userID string, settings config.Settings) string {
+	if userID != "" && s.userSettings != nil {
+		userSettings, err := s.userSettings.Get(userID)
+		if err != nil {
+			log.Printf("[indexer] failed to get user settings for selection strategy %s: %v", userID, err)
+		} else if userSettings != nil && userSettings.Ranking != nil && userSettings.Ranking.Strategy != "" {
+			if name := strings.ToLower(strings.TrimSpace(userSettings.Ranking.Strategy)); IsSelectionStrategy(name) {
+				return name
+			}
+			log.Printf("[indexer] ignoring unknown selection strategy %q for user %s", userSettings.Ranking.Strategy, userID)
+		}
+	}
+
+	if arm := experimentArm(userID, settings.Ranking.ExperimentStrategies); arm != "" {
+		return arm
+	}
+
+	if name := strings.ToLower(strings.TrimSpace(settings.Ranking.Strategy)); IsSelectionStrategy(name) {
+		return name
+	}
+	return config.SelectionStrategyHeuristic
+}
+
+// experimentArm assigns a profile to one of the experiment's strategies by
+// hashing its ID, so a profile stays in the same arm across searches and
+// restarts. Unknown strategy names are left out.
+func experimentArm(userID string, strategies []string) string {
+	if userID == "" {
+		return ""
+	}
+	var arms []string
+	for _, name := range strategies {
+		if name = strings.ToLower(strings.TrimSpace(name)); IsSelectionStrategy(name) {
+			arms = append(arms, name)
+		}
+	}
+	if len(arms) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return arms[h.Sum32()%uint32(len(arms))]
+}
+
+// newSelectionStrategy builds the named strategy, falling back to the heuristic.
+func newSelectionStrategy(name string, rc RankingContext) SelectionStrategy {
+	if factory, ok := selectionStrategies[name]; ok {
+		return factory(rc)
+	}
+	return heuristicStrategy{rc: rc}
+}
+
+// sortResults orders results in place with a strategy.
+func sortResults(results []models.NZBResult, strategy SelectionStrategy) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return strategy.Compare(results[i], results[j]) < 0
+	})
+}
+
+// heuristicStrategy applies the enabled ranking criteria in order.
+type heuristicStrategy struct {
+	rc RankingContext
+}
+
+func (h heuristicStrategy) Compare(a, b models.NZBResult) int {
+	for _, criterion := range h.rc.Criteria {
+		if !criterion.Enabled {
+			continue
+		}
+
+		var result int
+		switch criterion.ID {
+		case config.RankingServicePriority:
+			result = compareServicePriority(a, b, h.rc.ServicePriority)
+		case config.RankingPreferredTerms:
+			result = comparePreferredTerms(a, b, h.rc.PreferredTerms)
+		case config.RankingResolution:
+			result = compareResolution(a, b)
+		case config.RankingHDR:
+			result = compareHDR(a, b, h.rc.PrioritizeHDR)
+		case config.RankingLanguage:
+			result = compareLanguage(a, b, h.rc.PreferredLang)
+		case config.RankingSize:
+			result = compareBitrate(a, b, h.rc.BitrateBands)
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// primaryThenHeuristic ranks by one comparison first and breaks ties with the
+// ranking criteria.
+type primaryThenHeuristic struct {
+	primary func(a, b models.NZBResult) int
+	rc      RankingContext
+}
+
+func (p primaryThenHeuristic) Compare(a, b models.NZBResult) int {
+	if result := p.primary(a, b); result != 0 {
+		return result
+	}
+	return heuristicStrategy{rc: p.rc}.Compare(a, b)
+}
+
+// compareCached prefers releases known to be instantly available (pre-resolved
+// debrid streams), then debrid releases by seeders, since well-seeded torrents
+// are the most likely to be cached.
+func compareCached(i, j models.NZBResult) int {
+	cachedI, cachedJ := isKnownCached(i), isKnownCached(j)
+	if cachedI != cachedJ {
+		if cachedI {
+			return -1
+		}
+		return 1
+	}
+
+	debridI, debridJ := i.ServiceType == models.ServiceTypeDebrid, j.ServiceType == models.ServiceTypeDebrid
+	if debridI != debridJ {
+		if debridI {
+			return -1
+		}
+		return 1
+	}
+
+	seedersI, _ := strconv.Atoi(i.Attributes["seeders"])
+	seedersJ, _ := strconv.Atoi(j.Attributes["seeders"])
+	if seedersI > seedersJ {
+		return -1
+	}
+	if seedersI < seedersJ {
+		return 1
+	}
+	return 0
+}
+
+func isKnownCached(result models.NZBResult) bool {
+	return result.Attributes["preresolved"] == "true" || result.Attributes["cached"] == "true"
+}
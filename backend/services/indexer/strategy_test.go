@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+func strategyTestContext() RankingContext {
+	return RankingContext{
+		Criteria:     config.DefaultRankingCriteria(),
+		BitrateBands: config.DefaultBitrateBands(),
+	}
+}
+
+func titlesOf(results []models.NZBResult) []string {
+	titles := make([]string, len(results))
+	for i, result := range results {
+		titles[i] = result.Title
+	}
+	return titles
+}
+
+func TestSelectionStrategiesOrderResults(t *testing.T) {
+	results := func() []models.NZBResult {
+		return []models.NZBResult{
+			{Title: "Movie.2020.720p.WEB-DL", SizeBytes: 30 * gib, ServiceType: models.ServiceTypeUsenet},
+			{Title: "Movie.2020.1080p.WEB-DL", SizeBytes: 4 * gib, ServiceType: models.ServiceTypeDebrid,
+				Attributes: map[string]string{"preresolved": "true"}},
+			{Title: "Movie.2020.2160p.WEB-DL", SizeBytes: 20 * gib, ServiceType: models.ServiceTypeDebrid,
+				Attributes: map[string]string{"seeders": "50"}},
+		}
+	}
+
+	tests := []struct {
+		strategy string
+		first    string
+	}{
+		{config.SelectionStrategyHeuristic, "Movie.2020.2160p.WEB-DL"},
+		{config.SelectionStrategySizeFirst, "Movie.2020.720p.WEB-DL"},
+		{config.SelectionStrategyCachedFirst, "Movie.2020.1080p.WEB-DL"},
+	}
+	for _, tt := range tests {
+		ordered := results()
+		sortResults(ordered, newSelectionStrategy(tt.strategy, strategyTestContext()))
+		if ordered[0].Title != tt.first {
+			t.Errorf("%s: got order %v, want %q first", tt.strategy, titlesOf(ordered), tt.first)
+		}
+	}
+}
+
+func TestExperimentArmIsStable(t *testing.T) {
+	arms := []string{config.SelectionStrategyHeuristic, "Size-First", "unknown"}
+
+	seen := make(map[string]bool)
+	for _, userID := range []string{"alice", "bob", "carol", "dave", "erin", "frank"} {
+		arm := experimentArm(userID, arms)
+		if arm != experimentArm(userID, arms) {
+			t.Fatalf("assignment for %s changed between calls", userID)
+		}
+		if arm != config.SelectionStrategyHeuristic && arm != config.SelectionStrategySizeFirst {
+			t.Fatalf("unexpected arm %q for %s", arm, userID)
+		}
+		seen[arm] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected profiles spread over both arms, got %v", seen)
+	}
+
+	if arm := experimentArm("", arms); arm != "" {
+		t.Errorf("anonymous searches should not join the experiment, got %q", arm)
+	}
+	if arm := experimentArm("alice", []string{"unknown"}); arm != "" {
+		t.Errorf("unknown strategies should disable the experiment, got %q", arm)
+	}
+}
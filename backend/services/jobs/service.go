@@ -0,0 +1,480 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrJobNotFound        = errors.New("job not found")
+	ErrJobRunning         = errors.New("job is already running")
+)
+
+// How often the scheduler loop looks for jobs that are due. Schedules have
+// minute resolution, so this keeps runs within half a minute of their slot.
+const checkInterval = 30 * time.Second
+
+// Job is a unit of periodic background work such as pruning or cache refresh.
+type Job struct {
+	ID          string
+	Name        string
+	Description string
+	Schedule    string        // Default schedule, see ParseSchedule
+	Timeout     time.Duration // Cancels the run's context after this long; 0 for no limit
+	Run         func(ctx context.Context) error
+}
+
+// jobState is what's persisted per job: admin overrides and the last run.
+type jobState struct {
+	ID             string     `json:"id"`
+	Schedule       string     `json:"schedule,omitempty"` // Overrides the job's default schedule
+	Disabled       bool       `json:"disabled,omitempty"`
+	Running        bool       `json:"running,omitempty"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs,omitempty"`
+	LastStatus     string     `json:"lastStatus,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	RunCount       int        `json:"runCount"`
+}
+
+type registeredJob struct {
+	job       Job
+	schedule  Schedule
+	state     *jobState
+	running   bool
+	nextRunAt time.Time
+}
+
+// Service runs registered maintenance jobs on cron-like schedules. A job
+// never overlaps with itself: scheduled runs are skipped and manual runs
+// refused while it is still running. Schedule overrides, disabled jobs and
+// the last run of each job are persisted so they survive restarts, and a job
+// whose slot passed while the server was down runs once on startup.
+type Service struct {
+	mu     sync.Mutex
+	path   string
+	jobs   map[string]*registeredJob
+	states map[string]*jobState // Includes jobs that are no longer registered
+	now    func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs a job scheduler backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create jobs dir: %w", err)
+	}
+
+	svc := &Service{
+		path:   filepath.Join(storageDir, "jobs.json"),
+		jobs:   make(map[string]*registeredJob),
+		states: make(map[string]*jobState),
+		now:    time.Now,
+		ctx:    context.Background(),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Register adds a job. Jobs are usually registered before Start but may be
+// added later. A persisted schedule override that no longer parses is
+// dropped in favour of the job's default schedule.
+func (s *Service) Register(job Job) error {
+	job.ID = strings.TrimSpace(job.ID)
+	if job.ID == "" {
+		return errors.New("job id is required")
+	}
+	if job.Run == nil {
+		return fmt.Errorf("job %s has no run function", job.ID)
+	}
+	defaultSchedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.ID]; exists {
+		return fmt.Errorf("job %s is already registered", job.ID)
+	}
+
+	state, ok := s.states[job.ID]
+	if !ok {
+		state = &jobState{ID: job.ID}
+		s.states[job.ID] = state
+	}
+
+	schedule := defaultSchedule
+	if state.Schedule != "" {
+		if override, err := ParseSchedule(state.Schedule); err == nil {
+			schedule = override
+		} else {
+			log.Printf("[jobs] ignoring schedule override for %s: %v", job.ID, err)
+			state.Schedule = ""
+		}
+	}
+
+	entry := &registeredJob{job: job, schedule: schedule, state: state}
+	entry.nextRunAt = s.firstRunLocked(entry)
+	s.jobs[job.ID] = entry
+	return nil
+}
+
+// firstRunLocked picks a newly registered job's first run: the slot after
+// its last run, or after now if it has never run. A slot already in the past
+// makes the job due straight away.
+// Must be called with s.mu held.
+func (s *Service) firstRunLocked(entry *registeredJob) time.Time {
+	if entry.state.LastRunAt != nil {
+		return entry.schedule.Next(*entry.state.LastRunAt)
+	}
+	return entry.schedule.Next(s.now())
+}
+
+// Start begins the scheduler loop.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.ctx = loopCtx
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.loop(loopCtx)
+
+	log.Printf("[jobs] scheduler started (%d jobs)", len(s.jobs))
+}
+
+// Stop ends the scheduler loop, cancels running jobs and waits for them to return.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue starts every enabled job whose next run time has passed.
+func (s *Service) runDue() {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.jobs {
+		if entry.state.Disabled || entry.running || entry.nextRunAt.IsZero() || now.Before(entry.nextRunAt) {
+			continue
+		}
+		s.startLocked(entry)
+	}
+}
+
+// startLocked marks a job as running and runs it in the background.
+// Must be called with s.mu held.
+func (s *Service) startLocked(entry *registeredJob) {
+	entry.running = true
+	entry.state.Running = true
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[jobs] failed to persist job state: %v", err)
+	}
+
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx, entry)
+	}()
+}
+
+// run executes a job and records the outcome.
+func (s *Service) run(ctx context.Context, entry *registeredJob) {
+	if entry.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, entry.job.Timeout)
+		defer cancel()
+	}
+
+	started := s.now()
+	err := runRecovered(ctx, entry.job.Run)
+	finished := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.running = false
+	state := entry.state
+	state.Running = false
+	state.LastRunAt = &started
+	state.LastDurationMs = finished.Sub(started).Milliseconds()
+	state.RunCount++
+	if err != nil {
+		state.LastStatus = models.JobStatusError
+		state.LastError = err.Error()
+		log.Printf("[jobs] %s failed after %s: %v", entry.job.ID, finished.Sub(started).Round(time.Millisecond), err)
+	} else {
+		state.LastStatus = models.JobStatusSuccess
+		state.LastError = ""
+	}
+	// Schedule from the end of the run so a slow job isn't immediately due again
+	entry.nextRunAt = entry.schedule.Next(finished)
+
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[jobs] failed to persist job state: %v", err)
+	}
+}
+
+// runRecovered calls run, turning a panic into an error so one broken job
+// can't take down the server.
+func runRecovered(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// List returns every registered job, ordered by ID.
+func (s *Service) List() []models.JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]models.JobStatus, 0, len(s.jobs))
+	for _, entry := range s.jobs {
+		statuses = append(statuses, entry.statusLocked())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// Get returns a single job.
+func (s *Service) Get(id string) (models.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return models.JobStatus{}, ErrJobNotFound
+	}
+	return entry.statusLocked(), nil
+}
+
+// RunNow starts a job immediately, whether or not it is enabled, and returns
+// without waiting for it to finish. It fails with ErrJobRunning rather than
+// starting a second, overlapping run.
+func (s *Service) RunNow(id string) (models.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return models.JobStatus{}, ErrJobNotFound
+	}
+	if entry.running {
+		return models.JobStatus{}, ErrJobRunning
+	}
+	log.Printf("[jobs] %s started manually", id)
+	s.startLocked(entry)
+	return entry.statusLocked(), nil
+}
+
+// SetEnabled enables or disables scheduled runs of a job. A run already in
+// progress is left to finish.
+func (s *Service) SetEnabled(id string, enabled bool) (models.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return models.JobStatus{}, ErrJobNotFound
+	}
+	if entry.state.Disabled == !enabled {
+		return entry.statusLocked(), nil
+	}
+	entry.state.Disabled = !enabled
+	if enabled {
+		// Don't catch up on slots missed while disabled
+		entry.nextRunAt = entry.schedule.Next(s.now())
+	}
+	if err := s.saveLocked(); err != nil {
+		return models.JobStatus{}, err
+	}
+	if enabled {
+		log.Printf("[jobs] %s enabled", id)
+	} else {
+		log.Printf("[jobs] %s disabled", id)
+	}
+	return entry.statusLocked(), nil
+}
+
+// SetSchedule overrides a job's schedule. An empty expression restores the default.
+func (s *Service) SetSchedule(id, expr string) (models.JobStatus, error) {
+	expr = strings.TrimSpace(expr)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok {
+		return models.JobStatus{}, ErrJobNotFound
+	}
+
+	effective := expr
+	if effective == "" || effective == entry.job.Schedule {
+		effective, expr = entry.job.Schedule, ""
+	}
+	schedule, err := ParseSchedule(effective)
+	if err != nil {
+		return models.JobStatus{}, err
+	}
+
+	entry.schedule = schedule
+	entry.state.Schedule = expr
+	entry.nextRunAt = schedule.Next(s.now())
+	if err := s.saveLocked(); err != nil {
+		return models.JobStatus{}, err
+	}
+	log.Printf("[jobs] %s schedule set to %q", id, effective)
+	return entry.statusLocked(), nil
+}
+
+// statusLocked describes a job for the admin API.
+// Must be called with s.mu held.
+func (j *registeredJob) statusLocked() models.JobStatus {
+	state := j.state
+	status := models.JobStatus{
+		ID:              j.job.ID,
+		Name:            j.job.Name,
+		Description:     j.job.Description,
+		Schedule:        j.job.Schedule,
+		DefaultSchedule: j.job.Schedule,
+		Enabled:         !state.Disabled,
+		Running:         j.running,
+		LastDurationMs:  state.LastDurationMs,
+		LastStatus:      state.LastStatus,
+		LastError:       state.LastError,
+		RunCount:        state.RunCount,
+	}
+	if state.Schedule != "" {
+		status.Schedule = state.Schedule
+	}
+	if state.LastRunAt != nil {
+		lastRun := *state.LastRunAt
+		status.LastRunAt = &lastRun
+	}
+	if !state.Disabled && !j.nextRunAt.IsZero() {
+		next := j.nextRunAt
+		status.NextRunAt = &next
+	}
+	return status
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open jobs: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read jobs: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var states []jobState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("decode jobs: %w", err)
+	}
+	for i := range states {
+		state := states[i]
+		if state.Running {
+			// The server stopped mid-run; nothing is running in this process
+			state.Running = false
+			state.LastStatus = models.JobStatusInterrupted
+			state.LastError = ""
+		}
+		s.states[state.ID] = &state
+	}
+	return nil
+}
+
+// saveLocked writes job state to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	states := make([]jobState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode jobs: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write jobs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // Saturday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2026, 3, 15, 3, 30, 0, 0, time.UTC)},
+		{"0 4 * * 1-5", time.Date(2026, 3, 16, 4, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"@every 90m", time.Date(2026, 3, 14, 11, 37, 30, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(c.want) {
+			t.Errorf("%q: next = %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "@every 10s", "@sometimes"} {
+		if _, err := ParseSchedule(expr); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("%q: expected ErrInvalidSchedule, got %v", expr, err)
+		}
+	}
+
+	never, _ := ParseSchedule("0 0 30 2 *")
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("February 30th: next = %s, want zero", got)
+	}
+}
+
+func TestRunNowPreventsOverlap(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	release := make(chan struct{})
+	if err := svc.Register(Job{
+		ID:       "prune",
+		Schedule: "@daily",
+		Run: func(ctx context.Context) error {
+			<-release
+			return errors.New("disk full")
+		},
+	}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	status, err := svc.RunNow("prune")
+	if err != nil || !status.Running {
+		t.Fatalf("RunNow: %+v, %v", status, err)
+	}
+	if _, err := svc.RunNow("prune"); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected ErrJobRunning for overlapping run, got %v", err)
+	}
+	if _, err := svc.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+
+	close(release)
+	svc.wg.Wait()
+
+	status, _ = svc.Get("prune")
+	if status.Running || status.RunCount != 1 || status.LastStatus != models.JobStatusError || status.LastError != "disk full" {
+		t.Fatalf("unexpected status after run: %+v", status)
+	}
+}
+
+func TestStatePersists(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	job := Job{ID: "refresh", Schedule: "0 * * * *", Run: func(context.Context) error { return nil }}
+
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.now = func() time.Time { return now }
+	if err := svc.Register(job); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := svc.SetSchedule("refresh", "not a schedule"); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule, got %v", err)
+	}
+	if _, err := svc.SetSchedule("refresh", "*/30 * * * *"); err != nil {
+		t.Fatalf("SetSchedule: %v", err)
+	}
+	svc.RunNow("refresh")
+	svc.wg.Wait()
+	if _, err := svc.SetEnabled("refresh", false); err != nil {
+		t.Fatalf("SetEnabled: %v", err)
+	}
+
+	// A restart two hours later keeps the override and disabled flag
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	reloaded.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if err := reloaded.Register(job); err != nil {
+		t.Fatalf("Register after reload: %v", err)
+	}
+	status, _ := reloaded.Get("refresh")
+	if status.Schedule != "*/30 * * * *" || status.DefaultSchedule != "0 * * * *" || status.Enabled || status.NextRunAt != nil {
+		t.Fatalf("unexpected reloaded status: %+v", status)
+	}
+	if status.RunCount != 1 || status.LastStatus != models.JobStatusSuccess || status.LastRunAt == nil || !status.LastRunAt.Equal(now) {
+		t.Fatalf("last run not persisted: %+v", status)
+	}
+
+	// Disabled jobs aren't run by the scheduler, and re-enabling doesn't catch up
+	reloaded.runDue()
+	if status, _ := reloaded.Get("refresh"); status.Running {
+		t.Fatal("disabled job was started")
+	}
+	status, _ = reloaded.SetEnabled("refresh", true)
+	if want := now.Add(2*time.Hour + 30*time.Minute); status.NextRunAt == nil || !status.NextRunAt.Equal(want) {
+		t.Fatalf("next run after enabling = %v, want %s", status.NextRunAt, want)
+	}
+}
+
+func TestMissedRunCatchesUpOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	ran := make(chan struct{}, 2)
+	job := Job{ID: "health", Schedule: "@hourly", Run: func(context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}}
+
+	svc, _ := NewService(dir)
+	svc.now = func() time.Time { return now }
+	svc.Register(job)
+	svc.RunNow("health")
+	svc.wg.Wait()
+	<-ran
+
+	// Down for three hours: one catch-up run, not three
+	reloaded, _ := NewService(dir)
+	reloaded.now = func() time.Time { return now.Add(3 * time.Hour) }
+	reloaded.Register(job)
+	reloaded.runDue()
+	reloaded.wg.Wait()
+	reloaded.runDue()
+	reloaded.wg.Wait()
+	if len(ran) != 1 {
+		t.Fatalf("expected a single catch-up run, got %d", len(ran))
+	}
+	status, _ := reloaded.Get("health")
+	if want := now.Add(4 * time.Hour); status.NextRunAt == nil || !status.NextRunAt.Equal(want) {
+		t.Fatalf("next run = %v, want %s", status.NextRunAt, want)
+	}
+}
@@ -0,0 +1,88 @@
+package live_events
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"novastream/utils/sandbox"
+)
+
+const (
+	// PlaylistName is the HLS playlist written into each recording directory
+	PlaylistName = "index.m3u8"
+
+	recordingSegmentSeconds = 6
+
+	// How long ffmpeg gets to finalize the playlist after being interrupted
+	recorderStopGrace = 10 * time.Second
+)
+
+// Recorder buffers a live stream into an HLS playlist in dir for at most
+// duration. Restarting a recorder on the same dir appends to the playlist.
+type Recorder interface {
+	Record(ctx context.Context, streamURL, dir string, duration time.Duration) error
+}
+
+// FFmpegRecorder records live streams with ffmpeg, copying the video and
+// normalising audio to stereo AAC like the live transmuxer does.
+type FFmpegRecorder struct {
+	FFmpegPath string
+}
+
+// Record runs ffmpeg until the duration elapses, the stream ends or ctx is
+// cancelled. Cancellation interrupts ffmpeg so it can finish the playlist.
+func (r FFmpegRecorder) Record(ctx context.Context, streamURL, dir string, duration time.Duration) error {
+	ffmpegPath := strings.TrimSpace(r.FFmpegPath)
+	if ffmpegPath == "" {
+		return fmt.Errorf("ffmpeg is not configured")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create recording dir: %w", err)
+	}
+
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "warning",
+		"-fflags", "+genpts",
+		"-reconnect", "1",
+		"-reconnect_streamed", "1",
+		"-reconnect_delay_max", "3",
+		"-i", streamURL,
+		"-t", fmt.Sprintf("%.0f", duration.Seconds()),
+		"-map", "0:v:0?",
+		"-map", "0:a:0?",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-ac", "2",
+		"-b:a", "128k",
+		"-ar", "48000",
+		"-f", "hls",
+		"-hls_time", fmt.Sprintf("%d", recordingSegmentSeconds),
+		"-hls_list_size", "0",
+		"-hls_playlist_type", "event",
+		"-hls_flags", "append_list+independent_segments",
+		"-hls_segment_filename", filepath.Join(dir, "segment_%05d.ts"),
+		filepath.Join(dir, PlaylistName),
+	}
+
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolFFmpeg, ffmpegPath, args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = recorderStopGrace
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(output))
+		if len(msg) > 500 {
+			msg = msg[len(msg)-500:]
+		}
+		return fmt.Errorf("ffmpeg: %w: %s", err, msg)
+	}
+	return nil
+}
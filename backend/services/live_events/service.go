@@ -0,0 +1,536 @@
+package live_events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrRecorderRequired   = errors.New("recorder not provided")
+	ErrEventNotFound      = errors.New("live event not found")
+	ErrStreamURLRequired  = errors.New("stream url is required")
+	ErrInvalidSchedule    = errors.New("end time must be after start time")
+	ErrEventEnded         = errors.New("event end time has already passed")
+	ErrEventTooLong       = errors.New("events cannot be longer than 12 hours")
+	ErrNotRecording       = errors.New("live event is not recording")
+	ErrNoRecording        = errors.New("live event has no recording yet")
+	ErrNotRunning         = errors.New("live event scheduler is not running")
+	ErrNotBuffered        = errors.New("no timeshift cache reaches back that far")
+	ErrBufferNotFound     = errors.New("timeshift buffer not found")
+)
+
+const (
+	// How often the scheduler looks for events that are due to start
+	scheduleCheckInterval = 15 * time.Second
+
+	// Pause before reconnecting when the stream drops before the event ends
+	reconnectDelay = 10 * time.Second
+
+	maxEventDuration = 12 * time.Hour
+)
+
+// Service schedules live event recordings. At an event's start time it
+// buffers the channel into an HLS timeshift cache on disk, reconnecting if the
+// stream drops, and keeps the recording available once the event has ended.
+// Channels can also be buffered while they are watched, without an event.
+type Service struct {
+	mu            sync.Mutex
+	path          string
+	recordingsDir string
+	timeshiftRoot string
+	recorder      Recorder
+	events        map[string]*models.LiveEvent
+	active        map[string]*activeRecording
+	buffers       map[string]*timeshiftBuffer // Keyed by stream URL
+	now           func() time.Time
+	retryDelay    time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type activeRecording struct {
+	cancel context.CancelFunc
+	finish bool // Set when the recording was ended early and should be kept
+	done   chan struct{}
+}
+
+// NewService constructs a live event scheduler backed by a JSON file on disk.
+// Recordings are stored under storageDir/live_recordings/<event id>, channel
+// buffers under storageDir/live_timeshift/<buffer id>.
+func NewService(storageDir string, recorder Recorder) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if recorder == nil {
+		return nil, ErrRecorderRequired
+	}
+
+	recordingsDir := filepath.Join(storageDir, "live_recordings")
+	if err := os.MkdirAll(recordingsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create live recordings dir: %w", err)
+	}
+
+	// Channel buffers do not outlive the process
+	timeshiftRoot := filepath.Join(storageDir, "live_timeshift")
+	if err := os.RemoveAll(timeshiftRoot); err != nil {
+		return nil, fmt.Errorf("clear timeshift buffers: %w", err)
+	}
+
+	svc := &Service{
+		path:          filepath.Join(storageDir, "live_events.json"),
+		recordingsDir: recordingsDir,
+		timeshiftRoot: timeshiftRoot,
+		recorder:      recorder,
+		events:        make(map[string]*models.LiveEvent),
+		active:        make(map[string]*activeRecording),
+		buffers:       make(map[string]*timeshiftBuffer),
+		now:           time.Now,
+		retryDelay:    reconnectDelay,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Create schedules a new live event. Events whose start time has already
+// passed begin recording on the next scheduler pass.
+func (s *Service) Create(accountID string, req models.LiveEventRequest) (*models.LiveEvent, error) {
+	streamURL := strings.TrimSpace(req.StreamURL)
+	if streamURL == "" {
+		return nil, ErrStreamURLRequired
+	}
+	if !req.EndAt.After(req.StartAt) {
+		return nil, ErrInvalidSchedule
+	}
+	if req.EndAt.Sub(req.StartAt) > maxEventDuration {
+		return nil, ErrEventTooLong
+	}
+
+	now := s.now().UTC()
+	if !req.EndAt.After(now) {
+		return nil, ErrEventEnded
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = strings.TrimSpace(req.ChannelName)
+	}
+
+	event := &models.LiveEvent{
+		ID:          uuid.NewString(),
+		Title:       title,
+		ChannelID:   strings.TrimSpace(req.ChannelID),
+		ChannelName: strings.TrimSpace(req.ChannelName),
+		StreamURL:   streamURL,
+		StartAt:     req.StartAt.UTC(),
+		EndAt:       req.EndAt.UTC(),
+		Status:      models.LiveEventScheduled,
+		CreatedBy:   accountID,
+		CreatedAt:   now,
+	}
+
+	s.mu.Lock()
+	s.events[event.ID] = event
+	err := s.saveLocked()
+	snapshot := *event
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[live_events] scheduled %q on %s from %s to %s", snapshot.Title, snapshot.ChannelName,
+		snapshot.StartAt.Format(time.RFC3339), snapshot.EndAt.Format(time.RFC3339))
+
+	if !snapshot.StartAt.After(now) {
+		s.startDue()
+	}
+	return &snapshot, nil
+}
+
+// List returns every live event ordered by start time.
+func (s *Service) List() []models.LiveEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.LiveEvent, 0, len(s.events))
+	for _, event := range s.events {
+		list = append(list, *event)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartAt.Before(list[j].StartAt) })
+	return list
+}
+
+// Get returns a live event by ID.
+func (s *Service) Get(id string) (*models.LiveEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, ok := s.events[id]
+	if !ok {
+		return nil, ErrEventNotFound
+	}
+	snapshot := *event
+	return &snapshot, nil
+}
+
+// Finish ends an in-progress recording early and keeps what was recorded.
+func (s *Service) Finish(id string) error {
+	s.mu.Lock()
+	if _, ok := s.events[id]; !ok {
+		s.mu.Unlock()
+		return ErrEventNotFound
+	}
+	rec, ok := s.active[id]
+	if !ok {
+		s.mu.Unlock()
+		return ErrNotRecording
+	}
+	rec.finish = true
+	rec.cancel()
+	s.mu.Unlock()
+
+	<-rec.done
+	return nil
+}
+
+// Delete cancels a live event, stopping its recording if one is running, and
+// removes the recording from disk.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	if _, ok := s.events[id]; !ok {
+		s.mu.Unlock()
+		return ErrEventNotFound
+	}
+	rec := s.active[id]
+	if rec != nil {
+		rec.cancel()
+	}
+	s.mu.Unlock()
+
+	if rec != nil {
+		<-rec.done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.events, id)
+	if err := os.RemoveAll(s.recordingDir(id)); err != nil {
+		log.Printf("[live_events] failed to remove recording for %s: %v", id, err)
+	}
+	return s.saveLocked()
+}
+
+// RecordingDir returns the directory holding the event's HLS recording. It is
+// available as soon as recording starts, so clients can watch with timeshift.
+func (s *Service) RecordingDir(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, ok := s.events[id]
+	if !ok {
+		return "", ErrEventNotFound
+	}
+	if !event.Playable() {
+		return "", ErrNoRecording
+	}
+	return s.recordingDir(id), nil
+}
+
+// Start launches the scheduler loop. Recordings interrupted by a restart are
+// resumed if their event has not ended yet.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.scheduleLoop(s.ctx)
+	s.mu.Unlock()
+
+	log.Printf("[live_events] scheduler started (%d events)", len(s.List()))
+	s.startDue()
+}
+
+// Stop ends the scheduler loop and interrupts active recordings. Their events
+// stay in the recording state so they resume on the next start.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) scheduleLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.startDue()
+			s.expireTimeshift()
+		}
+	}
+}
+
+// startDue starts recordings for events inside their window and settles
+// events whose window passed while the server was not running.
+func (s *Service) startDue() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel == nil || s.ctx.Err() != nil {
+		return
+	}
+
+	now := s.now()
+	changed := false
+	for id, event := range s.events {
+		if _, running := s.active[id]; running {
+			continue
+		}
+
+		switch event.Status {
+		case models.LiveEventScheduled, models.LiveEventRecording:
+		default:
+			continue
+		}
+
+		if !event.EndAt.After(now) {
+			if event.Status == models.LiveEventScheduled {
+				event.Status = models.LiveEventFailed
+				event.LastError = "missed: the server was not running during the event"
+			} else {
+				s.settleLocked(event, nil)
+			}
+			changed = true
+			continue
+		}
+		if event.StartAt.After(now) {
+			continue
+		}
+
+		if event.Status == models.LiveEventScheduled {
+			started := now.UTC()
+			event.Status = models.LiveEventRecording
+			event.RecordingStartedAt = &started
+			changed = true
+			log.Printf("[live_events] recording %q (%s)", event.Title, id)
+		} else {
+			log.Printf("[live_events] resuming recording %q (%s)", event.Title, id)
+		}
+
+		recCtx, cancel := context.WithCancel(s.ctx)
+		rec := &activeRecording{cancel: cancel, done: make(chan struct{})}
+		s.active[id] = rec
+		s.wg.Add(1)
+		go s.record(recCtx, id, event.StreamURL, event.EndAt, rec)
+	}
+
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			log.Printf("[live_events] failed to persist events: %v", err)
+		}
+	}
+}
+
+// record keeps the stream buffered until the event ends, reconnecting when
+// the stream drops early.
+func (s *Service) record(ctx context.Context, id, streamURL string, endAt time.Time, rec *activeRecording) {
+	defer s.wg.Done()
+	defer close(rec.done)
+
+	dir := s.recordingDir(id)
+	var lastErr error
+	for ctx.Err() == nil {
+		remaining := endAt.Sub(s.now())
+		if remaining < time.Second {
+			break
+		}
+
+		err := s.recorder.Record(ctx, streamURL, dir, remaining)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			lastErr = err
+			log.Printf("[live_events] recording %s interrupted: %v", id, err)
+		}
+		if endAt.Sub(s.now()) < time.Second {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.retryDelay):
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.active, id)
+	event, ok := s.events[id]
+	if !ok {
+		return
+	}
+	if ctx.Err() != nil && !rec.finish {
+		// Shutting down or being deleted; leave the event to resume or be removed
+		return
+	}
+
+	s.settleLocked(event, lastErr)
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[live_events] failed to persist events: %v", err)
+	}
+}
+
+// settleLocked marks an event whose recording window is over as completed or
+// failed, and closes its playlist so players treat it as a seekable VOD.
+// Must be called with s.mu held.
+func (s *Service) settleLocked(event *models.LiveEvent, lastErr error) {
+	ended := s.now().UTC()
+	event.RecordingEndedAt = &ended
+
+	playlist := filepath.Join(s.recordingDir(event.ID), PlaylistName)
+	hasSegments, err := closePlaylist(playlist)
+	switch {
+	case hasSegments:
+		event.Status = models.LiveEventCompleted
+		event.LastError = ""
+		if lastErr != nil {
+			// Partial recording; keep the reason for the gap
+			event.LastError = lastErr.Error()
+		}
+	case lastErr != nil:
+		event.Status = models.LiveEventFailed
+		event.LastError = lastErr.Error()
+	case err != nil:
+		event.Status = models.LiveEventFailed
+		event.LastError = err.Error()
+	default:
+		event.Status = models.LiveEventFailed
+		event.LastError = "no video was recorded"
+	}
+
+	log.Printf("[live_events] recording %q (%s) %s", event.Title, event.ID, event.Status)
+}
+
+// closePlaylist appends the ENDLIST tag if ffmpeg did not write it and reports
+// whether the playlist references any segments.
+func closePlaylist(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read recording playlist: %w", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, "#EXTINF") {
+		return false, nil
+	}
+	if strings.Contains(content, "#EXT-X-ENDLIST") {
+		return true, nil
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "#EXT-X-ENDLIST\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return true, fmt.Errorf("close recording playlist: %w", err)
+	}
+	return true, nil
+}
+
+func (s *Service) recordingDir(id string) string {
+	return filepath.Join(s.recordingsDir, id)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open live events: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read live events: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var events []models.LiveEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("decode live events: %w", err)
+	}
+	for i := range events {
+		event := events[i]
+		s.events[event.ID] = &event
+	}
+	return nil
+}
+
+// saveLocked writes the events to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	events := make([]models.LiveEvent, 0, len(s.events))
+	for _, event := range s.events {
+		events = append(events, *event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].StartAt.Before(events[j].StartAt) })
+
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode live events: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write live events: %w", err)
+	}
+
+	return nil
+}
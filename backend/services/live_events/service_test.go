@@ -0,0 +1,193 @@
+package live_events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+// fakeRecorder writes a one-segment playlist and blocks until cancelled,
+// like ffmpeg buffering a stream that is still live.
+type fakeRecorder struct {
+	calls chan string
+}
+
+func (f *fakeRecorder) Record(ctx context.Context, streamURL, dir string, duration time.Duration) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	playlist := "#EXTM3U\n#EXT-X-PLAYLIST-TYPE:EVENT\n#EXTINF:6.0,\nsegment_00000.ts\n"
+	if err := os.WriteFile(filepath.Join(dir, PlaylistName), []byte(playlist), 0o644); err != nil {
+		return err
+	}
+	f.calls <- streamURL
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func newTestService(t *testing.T) (*Service, *fakeRecorder) {
+	t.Helper()
+	recorder := &fakeRecorder{calls: make(chan string, 4)}
+	svc, err := NewService(t.TempDir(), recorder)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return svc, recorder
+}
+
+func TestCreateValidatesSchedule(t *testing.T) {
+	svc, _ := newTestService(t)
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		req  models.LiveEventRequest
+		want error
+	}{
+		{"missing url", models.LiveEventRequest{StartAt: now, EndAt: now.Add(time.Hour)}, ErrStreamURLRequired},
+		{"end before start", models.LiveEventRequest{StreamURL: "http://tv/1", StartAt: now, EndAt: now.Add(-time.Minute)}, ErrInvalidSchedule},
+		{"too long", models.LiveEventRequest{StreamURL: "http://tv/1", StartAt: now, EndAt: now.Add(13 * time.Hour)}, ErrEventTooLong},
+		{"already ended", models.LiveEventRequest{StreamURL: "http://tv/1", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Hour)}, ErrEventEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create("acct", tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventRecordsAndFinishesEarly(t *testing.T) {
+	svc, recorder := newTestService(t)
+	svc.Start(t.Context())
+	defer svc.Stop()
+
+	now := time.Now()
+	event, err := svc.Create("acct", models.LiveEventRequest{
+		ChannelName: "Sports 1",
+		StreamURL:   "http://tv/sports1",
+		StartAt:     now.Add(-time.Minute),
+		EndAt:       now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if event.Title != "Sports 1" {
+		t.Errorf("title = %q, want channel name fallback", event.Title)
+	}
+
+	select {
+	case url := <-recorder.calls:
+		if url != "http://tv/sports1" {
+			t.Fatalf("recorded %q", url)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("recording did not start")
+	}
+
+	// Timeshift: the recording is watchable while it is still running
+	if _, err := svc.RecordingDir(event.ID); err != nil {
+		t.Fatalf("RecordingDir() during recording error = %v", err)
+	}
+
+	if err := svc.Finish(event.ID); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	got, _ := svc.Get(event.ID)
+	if got.Status != models.LiveEventCompleted || got.RecordingEndedAt == nil {
+		t.Fatalf("status = %s, want completed with end time", got.Status)
+	}
+
+	dir, err := svc.RecordingDir(event.ID)
+	if err != nil {
+		t.Fatalf("RecordingDir() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, PlaylistName))
+	if err != nil {
+		t.Fatalf("read playlist: %v", err)
+	}
+	if !strings.HasSuffix(string(data), "#EXT-X-ENDLIST\n") {
+		t.Errorf("playlist not closed:\n%s", data)
+	}
+
+	if err := svc.Finish(event.ID); !errors.Is(err, ErrNotRecording) {
+		t.Errorf("second Finish() error = %v, want ErrNotRecording", err)
+	}
+}
+
+func TestMissedEventIsMarkedFailed(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	now := time.Now()
+	event, err := svc.Create("acct", models.LiveEventRequest{
+		StreamURL: "http://tv/1",
+		StartAt:   now.Add(time.Hour),
+		EndAt:     now.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// The server comes back after the event window
+	svc.now = func() time.Time { return now.Add(3 * time.Hour) }
+	svc.Start(t.Context())
+	defer svc.Stop()
+
+	got, _ := svc.Get(event.ID)
+	if got.Status != models.LiveEventFailed {
+		t.Fatalf("status = %s, want failed", got.Status)
+	}
+	if _, err := svc.RecordingDir(event.ID); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("RecordingDir() error = %v, want ErrNoRecording", err)
+	}
+}
+
+func TestDeleteStopsRecordingAndRemovesFiles(t *testing.T) {
+	svc, recorder := newTestService(t)
+	svc.Start(t.Context())
+	defer svc.Stop()
+
+	now := time.Now()
+	event, err := svc.Create("acct", models.LiveEventRequest{
+		StreamURL: "http://tv/1",
+		StartAt:   now,
+		EndAt:     now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	select {
+	case <-recorder.calls:
+	case <-time.After(2 * time.Second):
+		t.Fatal("recording did not start")
+	}
+	dir, _ := svc.RecordingDir(event.ID)
+
+	if err := svc.Delete(event.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("recording dir still exists: %v", err)
+	}
+	if _, err := svc.Get(event.ID); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("Get() error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func TestClosePlaylistWithoutSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PlaylistName)
+	if err := os.WriteFile(path, []byte("#EXTM3U\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hasSegments, err := closePlaylist(path)
+	if err != nil || hasSegments {
+		t.Fatalf("closePlaylist() = %v, %v; want false, nil", hasSegments, err)
+	}
+}
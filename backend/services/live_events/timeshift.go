@@ -0,0 +1,195 @@
+package live_events
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+const (
+	// How long a channel stays buffered after it was last played
+	timeshiftIdleTimeout = 30 * time.Minute
+
+	// Buffers are dropped after this long so an always-on channel cannot
+	// fill the disk; the next play starts a fresh one
+	maxTimeshiftDuration = 4 * time.Hour
+)
+
+// timeshiftBuffer is a channel being buffered to disk while it is watched,
+// so playback can reach back to the start of the current program.
+type timeshiftBuffer struct {
+	id        string
+	streamURL string
+	startedAt time.Time
+	lastUsed  time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// WatchChannel starts buffering the stream into a timeshift cache, or keeps
+// the existing buffer alive. Buffers are dropped once the channel has not been
+// played for a while.
+func (s *Service) WatchChannel(streamURL string) error {
+	streamURL = strings.TrimSpace(streamURL)
+	if streamURL == "" {
+		return ErrStreamURLRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel == nil || s.ctx.Err() != nil {
+		return ErrNotRunning
+	}
+
+	now := s.now()
+	if buf, ok := s.buffers[streamURL]; ok {
+		buf.lastUsed = now
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	buf := &timeshiftBuffer{
+		id:        uuid.NewString(),
+		streamURL: streamURL,
+		startedAt: now.UTC(),
+		lastUsed:  now,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	s.buffers[streamURL] = buf
+	s.wg.Add(1)
+	go s.buffer(ctx, buf)
+
+	log.Printf("[live_events] buffering channel for timeshift (%s)", buf.id)
+	return nil
+}
+
+// TouchTimeshift keeps the stream's buffer alive while it is being played. It
+// does nothing when the stream is not buffered.
+func (s *Service) TouchTimeshift(streamURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if buf, ok := s.buffers[strings.TrimSpace(streamURL)]; ok {
+		buf.lastUsed = s.now()
+	}
+}
+
+// Timeshift finds a timeshift cache of the stream that reaches back to at:
+// an event recording in progress or a buffer of a watched channel. When more
+// than one does, the most recent is used so the offset into it is shortest.
+// Event recordings are only used when accountID scheduled them.
+func (s *Service) Timeshift(streamURL, accountID string, at time.Time) (*models.LiveTimeshift, error) {
+	streamURL = strings.TrimSpace(streamURL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *models.LiveTimeshift
+	consider := func(source models.LiveTimeshiftSource, id string, from time.Time) {
+		if from.After(at) || (found != nil && !from.After(found.BufferedFrom)) {
+			return
+		}
+		found = &models.LiveTimeshift{Source: source, ID: id, BufferedFrom: from}
+	}
+
+	for id, event := range s.events {
+		if event.StreamURL != streamURL || event.Status != models.LiveEventRecording || event.RecordingStartedAt == nil {
+			continue
+		}
+		if event.CreatedBy != "" && event.CreatedBy != accountID {
+			continue
+		}
+		if _, running := s.active[id]; running {
+			consider(models.LiveTimeshiftEvent, id, *event.RecordingStartedAt)
+		}
+	}
+	if buf, ok := s.buffers[streamURL]; ok {
+		consider(models.LiveTimeshiftBuffer, buf.id, buf.startedAt)
+	}
+
+	if found == nil {
+		return nil, ErrNotBuffered
+	}
+	return found, nil
+}
+
+// TimeshiftDir returns the directory holding a channel buffer's HLS playlist.
+// Reading from a buffer keeps it alive.
+func (s *Service) TimeshiftDir(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, buf := range s.buffers {
+		if buf.id == id {
+			buf.lastUsed = s.now()
+			return s.timeshiftDir(id), nil
+		}
+	}
+	return "", ErrBufferNotFound
+}
+
+// buffer records the channel until the buffer expires, reconnecting when the
+// stream drops, then removes it from disk.
+func (s *Service) buffer(ctx context.Context, buf *timeshiftBuffer) {
+	defer s.wg.Done()
+	defer close(buf.done)
+
+	dir := s.timeshiftDir(buf.id)
+	endAt := buf.startedAt.Add(maxTimeshiftDuration)
+	for ctx.Err() == nil {
+		remaining := endAt.Sub(s.now())
+		if remaining < time.Second {
+			break
+		}
+
+		err := s.recorder.Record(ctx, buf.streamURL, dir, remaining)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			log.Printf("[live_events] timeshift buffer %s interrupted: %v", buf.id, err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.retryDelay):
+		}
+	}
+
+	s.mu.Lock()
+	if s.buffers[buf.streamURL] == buf {
+		delete(s.buffers, buf.streamURL)
+	}
+	s.mu.Unlock()
+
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("[live_events] failed to remove timeshift buffer %s: %v", buf.id, err)
+	}
+}
+
+// expireTimeshift stops buffers of channels nobody has played recently.
+func (s *Service) expireTimeshift() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, buf := range s.buffers {
+		if now.Sub(buf.lastUsed) > timeshiftIdleTimeout {
+			log.Printf("[live_events] dropping idle timeshift buffer %s", buf.id)
+			buf.cancel()
+		}
+	}
+}
+
+func (s *Service) timeshiftDir(id string) string {
+	return filepath.Join(s.timeshiftRoot, id)
+}
@@ -0,0 +1,129 @@
+package live_events
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestWatchChannelBuffersForTimeshift(t *testing.T) {
+	svc, recorder := newTestService(t)
+
+	if err := svc.WatchChannel("http://tv/news"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("WatchChannel() before Start error = %v, want ErrNotRunning", err)
+	}
+
+	svc.Start(t.Context())
+	defer svc.Stop()
+
+	start := time.Now()
+	svc.now = func() time.Time { return start }
+	if err := svc.WatchChannel("http://tv/news"); err != nil {
+		t.Fatalf("WatchChannel() error = %v", err)
+	}
+	select {
+	case <-recorder.calls:
+	case <-time.After(2 * time.Second):
+		t.Fatal("buffer did not start")
+	}
+
+	// Watching again keeps the same buffer
+	if err := svc.WatchChannel("http://tv/news"); err != nil {
+		t.Fatalf("second WatchChannel() error = %v", err)
+	}
+	select {
+	case <-recorder.calls:
+		t.Fatal("watching again started a second buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := svc.Timeshift("http://tv/news", "acct", start.Add(-time.Minute)); !errors.Is(err, ErrNotBuffered) {
+		t.Errorf("Timeshift() before the buffer started error = %v, want ErrNotBuffered", err)
+	}
+	cache, err := svc.Timeshift("http://tv/news", "acct", start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Timeshift() error = %v", err)
+	}
+	if cache.Source != models.LiveTimeshiftBuffer || !cache.BufferedFrom.Equal(start.UTC()) {
+		t.Fatalf("Timeshift() = %+v, want the buffer started at %s", cache, start)
+	}
+
+	dir, err := svc.TimeshiftDir(cache.ID)
+	if err != nil {
+		t.Fatalf("TimeshiftDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, PlaylistName)); err != nil {
+		t.Fatalf("buffer playlist missing: %v", err)
+	}
+
+	// Nobody played the channel for a while
+	svc.now = func() time.Time { return start.Add(timeshiftIdleTimeout + time.Minute) }
+	svc.expireTimeshift()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := svc.TimeshiftDir(cache.ID); errors.Is(err, ErrBufferNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("idle buffer was not dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("buffer dir still exists: %v", err)
+	}
+}
+
+func TestTimeshiftPrefersEventRecording(t *testing.T) {
+	svc, recorder := newTestService(t)
+	svc.Start(t.Context())
+	defer svc.Stop()
+
+	now := time.Now()
+	event, err := svc.Create("acct", models.LiveEventRequest{
+		StreamURL: "http://tv/sports",
+		StartAt:   now.Add(-time.Minute),
+		EndAt:     now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	select {
+	case <-recorder.calls:
+	case <-time.After(2 * time.Second):
+		t.Fatal("recording did not start")
+	}
+
+	svc.now = func() time.Time { return now.Add(10 * time.Minute) }
+	if err := svc.WatchChannel("http://tv/sports"); err != nil {
+		t.Fatalf("WatchChannel() error = %v", err)
+	}
+
+	// Only the event recording reaches back five minutes
+	cache, err := svc.Timeshift("http://tv/sports", "acct", now.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("Timeshift() error = %v", err)
+	}
+	if cache.Source != models.LiveTimeshiftEvent || cache.ID != event.ID {
+		t.Errorf("Timeshift() = %+v, want the event recording", cache)
+	}
+
+	// Another account's recording is not offered to a different account
+	if _, err := svc.Timeshift("http://tv/sports", "other", now.Add(5*time.Minute)); !errors.Is(err, ErrNotBuffered) {
+		t.Errorf("Timeshift() for another account error = %v, want ErrNotBuffered", err)
+	}
+
+	// Both do; the more recent buffer needs the shorter seek
+	cache, err = svc.Timeshift("http://tv/sports", "acct", now.Add(15*time.Minute))
+	if err != nil {
+		t.Fatalf("Timeshift() error = %v", err)
+	}
+	if cache.Source != models.LiveTimeshiftBuffer {
+		t.Errorf("Timeshift() = %+v, want the channel buffer", cache)
+	}
+}
@@ -0,0 +1,171 @@
+package maintenance
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+const (
+	// DefaultDrainTimeout is how long active streams may keep playing before
+	// a restart is considered safe anyway.
+	DefaultDrainTimeout = 30 * time.Minute
+	// MaxDrainTimeout bounds the configurable drain timeout.
+	MaxDrainTimeout = 6 * time.Hour
+
+	drainPollInterval = 5 * time.Second
+)
+
+// ActivityCounter returns the number of streams currently being served.
+type ActivityCounter func() int
+
+// Service tracks maintenance mode. While enabled, new playback is refused and
+// active streams are left to finish; once they have (or the drain timeout
+// passes) the service reports that it is safe to restart.
+type Service struct {
+	mu           sync.RWMutex
+	activity     ActivityCounter
+	enabled      bool
+	message      string
+	enabledAt    time.Time
+	drainTimeout time.Duration
+	initial      int
+	drainedAt    time.Time
+	stop         chan struct{}
+	now          func() time.Time
+}
+
+// NewService creates a maintenance service that counts active streams with activity.
+func NewService(activity ActivityCounter) *Service {
+	return &Service{
+		activity: activity,
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether maintenance mode is on and new playback should be refused.
+func (s *Service) Enabled() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enabled
+}
+
+// Enable turns maintenance mode on and starts draining. A non-positive
+// drainTimeout uses DefaultDrainTimeout. Enabling again updates the timeout
+// and message without restarting the drain clock.
+func (s *Service) Enable(drainTimeout time.Duration, message string) models.MaintenanceStatus {
+	if drainTimeout <= 0 {
+		drainTimeout = DefaultDrainTimeout
+	}
+	if drainTimeout > MaxDrainTimeout {
+		drainTimeout = MaxDrainTimeout
+	}
+
+	s.mu.Lock()
+	s.drainTimeout = drainTimeout
+	s.message = strings.TrimSpace(message)
+	if !s.enabled {
+		s.enabled = true
+		s.enabledAt = s.now()
+		s.drainedAt = time.Time{}
+		s.initial = s.activeStreams()
+		s.stop = make(chan struct{})
+		go s.watchDrain(s.stop)
+		log.Printf("[maintenance] enabled: refusing new playback, draining %d active streams (timeout %s)", s.initial, drainTimeout)
+	}
+	s.mu.Unlock()
+
+	return s.Status()
+}
+
+// Disable turns maintenance mode off and resumes accepting playback.
+func (s *Service) Disable() models.MaintenanceStatus {
+	s.mu.Lock()
+	if s.enabled {
+		s.enabled = false
+		s.message = ""
+		close(s.stop)
+		log.Printf("[maintenance] disabled: accepting playback again")
+	}
+	s.mu.Unlock()
+
+	return s.Status()
+}
+
+// Status returns maintenance mode and drain progress.
+func (s *Service) Status() models.MaintenanceStatus {
+	active := s.activeStreams()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := models.MaintenanceStatus{
+		Enabled:       s.enabled,
+		Phase:         models.MaintenancePhaseOff,
+		ActiveStreams: active,
+	}
+	if !s.enabled {
+		return status
+	}
+
+	if active == 0 && s.drainedAt.IsZero() {
+		s.drainedAt = s.now()
+	}
+
+	enabledAt := s.enabledAt
+	deadline := s.enabledAt.Add(s.drainTimeout)
+	status.Message = s.message
+	status.EnabledAt = &enabledAt
+	status.DrainDeadline = &deadline
+	status.DrainTimeoutSeconds = int(s.drainTimeout / time.Second)
+	status.InitialStreams = s.initial
+
+	switch {
+	case !s.drainedAt.IsZero():
+		status.Phase = models.MaintenancePhaseDrained
+		status.SafeToRestart = true
+	case !s.now().Before(deadline):
+		status.Phase = models.MaintenancePhaseTimedOut
+		status.SafeToRestart = true
+	default:
+		status.Phase = models.MaintenancePhaseDraining
+	}
+	return status
+}
+
+// watchDrain polls drain progress and logs once it becomes safe to restart.
+func (s *Service) watchDrain(stop <-chan struct{}) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			status := s.Status()
+			if !status.SafeToRestart {
+				continue
+			}
+			if status.Phase == models.MaintenancePhaseDrained {
+				log.Printf("[maintenance] all streams drained; safe to restart")
+			} else {
+				log.Printf("[maintenance] drain timeout reached with %d streams still active; safe to restart", status.ActiveStreams)
+			}
+			return
+		}
+	}
+}
+
+func (s *Service) activeStreams() int {
+	if s.activity == nil {
+		return 0
+	}
+	return s.activity()
+}
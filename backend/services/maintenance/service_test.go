@@ -0,0 +1,54 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestDrainProgress(t *testing.T) {
+	active := 2
+	svc := NewService(func() int { return active })
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if svc.Enabled() {
+		t.Fatal("expected maintenance mode to start disabled")
+	}
+
+	status := svc.Enable(10*time.Minute, "updating")
+	defer svc.Disable()
+	if !svc.Enabled() || status.Phase != models.MaintenancePhaseDraining || status.SafeToRestart {
+		t.Fatalf("expected draining status, got %+v", status)
+	}
+	if status.InitialStreams != 2 || status.DrainTimeoutSeconds != 600 {
+		t.Fatalf("unexpected drain details %+v", status)
+	}
+
+	active = 0
+	status = svc.Status()
+	if status.Phase != models.MaintenancePhaseDrained || !status.SafeToRestart {
+		t.Fatalf("expected drained once streams finish, got %+v", status)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	svc := NewService(func() int { return 1 })
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	svc.Enable(0, "")
+	defer svc.Disable()
+
+	now = now.Add(DefaultDrainTimeout)
+	status := svc.Status()
+	if status.Phase != models.MaintenancePhaseTimedOut || !status.SafeToRestart || status.ActiveStreams != 1 {
+		t.Fatalf("expected timed out drain, got %+v", status)
+	}
+
+	status = svc.Disable()
+	if svc.Enabled() || status.Phase != models.MaintenancePhaseOff {
+		t.Fatalf("expected maintenance mode off, got %+v", status)
+	}
+}
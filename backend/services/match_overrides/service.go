@@ -0,0 +1,230 @@
+package match_overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrInvalidMediaType   = errors.New("mediaType must be series or movie")
+	ErrTitleRequired      = errors.New("titleId or name is required")
+	ErrTargetIDRequired   = errors.New("tvdbId or tmdbId is required")
+	ErrOverrideNotFound   = errors.New("match override not found")
+)
+
+// Service persists manual metadata match corrections. Metadata and indexer
+// resolution consult it before any automatic matching.
+type Service struct {
+	mu        sync.RWMutex
+	path      string
+	overrides map[string]*models.MatchOverride // id -> override
+	now       func() time.Time
+}
+
+// NewService constructs a match override service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create match overrides dir: %w", err)
+	}
+
+	svc := &Service{
+		path:      filepath.Join(storageDir, "match_overrides.json"),
+		overrides: make(map[string]*models.MatchOverride),
+		now:       time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Set stores an override, replacing any existing one for the same title key
+// (its TitleID, or media type, name and year when there is no TitleID).
+func (s *Service) Set(accountID string, override models.MatchOverride) (*models.MatchOverride, error) {
+	override.MediaType = strings.ToLower(strings.TrimSpace(override.MediaType))
+	override.TitleID = strings.TrimSpace(override.TitleID)
+	override.Name = strings.TrimSpace(override.Name)
+	override.IMDBID = strings.TrimSpace(override.IMDBID)
+
+	if override.MediaType != "series" && override.MediaType != "movie" {
+		return nil, ErrInvalidMediaType
+	}
+	if override.TitleID == "" && override.Name == "" {
+		return nil, ErrTitleRequired
+	}
+	if override.TVDBID <= 0 && override.TMDBID <= 0 {
+		return nil, ErrTargetIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	override.ID = uuid.NewString()
+	for id, existing := range s.overrides {
+		if sameTitleKey(*existing, override) {
+			override.ID = id
+			break
+		}
+	}
+	override.UpdatedBy = accountID
+	override.UpdatedAt = s.now().UTC()
+	s.overrides[override.ID] = &override
+
+	if err := s.saveLocked(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[match_overrides] %s %q (titleId=%q year=%d) pinned to tvdbId=%d tmdbId=%d",
+		override.MediaType, override.Name, override.TitleID, override.Year, override.TVDBID, override.TMDBID)
+	result := override
+	return &result, nil
+}
+
+// List returns all overrides, most recently updated first.
+func (s *Service) List() []models.MatchOverride {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]models.MatchOverride, 0, len(s.overrides))
+	for _, override := range s.overrides {
+		list = append(list, *override)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UpdatedAt.After(list[j].UpdatedAt) })
+	return list
+}
+
+// Delete removes an override; later lookups go back to automatic matching.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.overrides[id]; !ok {
+		return ErrOverrideNotFound
+	}
+	delete(s.overrides, id)
+	return s.saveLocked()
+}
+
+// Lookup returns the override for a title. A TitleID match wins; otherwise
+// overrides are matched by media type and name, preferring one for the exact
+// year over a year-less one.
+func (s *Service) Lookup(mediaType, titleID, name string, year int) (models.MatchOverride, bool) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	titleID = strings.TrimSpace(titleID)
+	name = normalizeName(name)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var byName *models.MatchOverride
+	for _, override := range s.overrides {
+		if mediaType != "" && override.MediaType != mediaType {
+			continue
+		}
+		if titleID != "" && strings.EqualFold(override.TitleID, titleID) {
+			return *override, true
+		}
+		if name == "" || override.Name == "" || normalizeName(override.Name) != name {
+			continue
+		}
+		switch {
+		case override.Year == year:
+			byName = override
+		case override.Year == 0 && byName == nil:
+			byName = override
+		}
+	}
+	if byName != nil {
+		return *byName, true
+	}
+	return models.MatchOverride{}, false
+}
+
+func sameTitleKey(a, b models.MatchOverride) bool {
+	if a.MediaType != b.MediaType {
+		return false
+	}
+	if a.TitleID != "" || b.TitleID != "" {
+		return strings.EqualFold(a.TitleID, b.TitleID)
+	}
+	return normalizeName(a.Name) == normalizeName(b.Name) && a.Year == b.Year
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open match overrides: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read match overrides: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var overrides []models.MatchOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("decode match overrides: %w", err)
+	}
+	for i := range overrides {
+		override := overrides[i]
+		s.overrides[override.ID] = &override
+	}
+
+	log.Printf("[match_overrides] loaded %d overrides", len(s.overrides))
+	return nil
+}
+
+// saveLocked writes the overrides to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	overrides := make([]models.MatchOverride, 0, len(s.overrides))
+	for _, override := range s.overrides {
+		overrides = append(overrides, *override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].ID < overrides[j].ID })
+
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode match overrides: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write match overrides: %w", err)
+	}
+
+	return nil
+}
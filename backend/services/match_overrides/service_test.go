@@ -0,0 +1,86 @@
+package match_overrides
+
+import (
+	"errors"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestSetValidates(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		override models.MatchOverride
+		want     error
+	}{
+		{"bad media type", models.MatchOverride{MediaType: "episode", TitleID: "tmdb:tv:1", TVDBID: 2}, ErrInvalidMediaType},
+		{"no title", models.MatchOverride{MediaType: "series", TVDBID: 2}, ErrTitleRequired},
+		{"no target", models.MatchOverride{MediaType: "series", TitleID: "tmdb:tv:1"}, ErrTargetIDRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Set("acct", tt.override); !errors.Is(err, tt.want) {
+				t.Fatalf("Set() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupByTitleIDAndName(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	// Same-name remakes: only the 2005 series is corrected
+	if _, err := svc.Set("acct", models.MatchOverride{MediaType: "series", TitleID: "tmdb:tv:2316", Name: "The Office", Year: 2005, TVDBID: 73244}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if got, ok := svc.Lookup("series", "TMDB:tv:2316", "", 0); !ok || got.TVDBID != 73244 {
+		t.Errorf("Lookup by titleId = %+v, %v", got, ok)
+	}
+	if got, ok := svc.Lookup("series", "", "the  office", 2005); !ok || got.TVDBID != 73244 {
+		t.Errorf("Lookup by name = %+v, %v", got, ok)
+	}
+	if _, ok := svc.Lookup("series", "", "The Office", 2001); ok {
+		t.Error("override matched a different year")
+	}
+	if _, ok := svc.Lookup("movie", "tmdb:tv:2316", "", 0); ok {
+		t.Error("override matched a different media type")
+	}
+
+	// Setting the same title again replaces the override and survives a restart
+	first := svc.List()[0]
+	updated, err := svc.Set("acct", models.MatchOverride{MediaType: "series", TitleID: "tmdb:tv:2316", TVDBID: 99})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if updated.ID != first.ID {
+		t.Errorf("replacement got new id %q, want %q", updated.ID, first.ID)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if list := reloaded.List(); len(list) != 1 || list[0].TVDBID != 99 {
+		t.Fatalf("reloaded overrides = %+v", list)
+	}
+
+	if err := reloaded.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := reloaded.Lookup("series", "tmdb:tv:2316", "", 0); ok {
+		t.Error("deleted override still matches")
+	}
+	if err := reloaded.Delete(first.ID); !errors.Is(err, ErrOverrideNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrOverrideNotFound", err)
+	}
+}
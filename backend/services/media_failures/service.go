@@ -0,0 +1,156 @@
+package media_failures
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+var ErrRepositoryRequired = errors.New("media failure repository not provided")
+
+// maxSignatureLength bounds stored signatures so noisy ffmpeg output can't bloat the table.
+const maxSignatureLength = 160
+
+// Repository persists aggregated failure counts.
+type Repository interface {
+	RecordFailure(stage, videoCodec, container, provider, signature, lastError string) error
+	ListFailureStats(stage string, limit int) ([]*database.MediaFailureStat, error)
+	ClearFailureStats() error
+}
+
+var _ Repository = (*database.MediaFailureRepository)(nil)
+
+// Service records ffprobe/transcode failures and summarises them by codec,
+// container and provider so quality profiles can be tuned against real data.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a media failure service backed by the given repository.
+func NewService(repo Repository) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo}, nil
+}
+
+// RecordFailure stores a failure event. Errors are logged rather than returned since
+// callers are already on a failure path and shouldn't fail harder because of metrics.
+func (s *Service) RecordFailure(event models.MediaFailureEvent) {
+	if s == nil || s.repo == nil {
+		return
+	}
+
+	stage := strings.ToLower(strings.TrimSpace(event.Stage))
+	if stage == "" {
+		stage = models.MediaFailureStageProbe
+	}
+	lastError := strings.TrimSpace(event.Error)
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+
+	if err := s.repo.RecordFailure(
+		stage,
+		strings.ToLower(strings.TrimSpace(event.VideoCodec)),
+		strings.ToLower(strings.TrimSpace(event.Container)),
+		strings.ToLower(strings.TrimSpace(event.Provider)),
+		NormalizeSignature(lastError),
+		lastError,
+	); err != nil {
+		log.Printf("[media-failures] failed to record %s failure: %v", stage, err)
+	}
+}
+
+// Summary aggregates all recorded failures and returns the top signatures.
+// stage filters to a single pipeline stage when non-empty.
+func (s *Service) Summary(stage string, limit int) (*models.MediaFailureSummary, error) {
+	stats, err := s.repo.ListFailureStats(strings.ToLower(strings.TrimSpace(stage)), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.MediaFailureSummary{
+		ByStage:       make(map[string]int64),
+		ByVideoCodec:  make(map[string]int64),
+		ByContainer:   make(map[string]int64),
+		ByProvider:    make(map[string]int64),
+		TopSignatures: make([]models.MediaFailureSignature, 0),
+	}
+
+	for _, stat := range stats {
+		summary.TotalFailures += stat.Count
+		summary.ByStage[stat.Stage] += stat.Count
+		summary.ByVideoCodec[unknownIfEmpty(stat.VideoCodec)] += stat.Count
+		summary.ByContainer[unknownIfEmpty(stat.Container)] += stat.Count
+		summary.ByProvider[unknownIfEmpty(stat.Provider)] += stat.Count
+
+		// Stats are ordered by count, so the first `limit` rows are the top signatures
+		if limit > 0 && len(summary.TopSignatures) >= limit {
+			continue
+		}
+		sig := models.MediaFailureSignature{
+			Stage:      stat.Stage,
+			VideoCodec: stat.VideoCodec,
+			Container:  stat.Container,
+			Provider:   stat.Provider,
+			Signature:  stat.Signature,
+			Count:      stat.Count,
+			FirstSeen:  stat.FirstSeen,
+			LastSeen:   stat.LastSeen,
+		}
+		if stat.LastError != nil {
+			sig.LastError = *stat.LastError
+		}
+		summary.TopSignatures = append(summary.TopSignatures, sig)
+	}
+
+	return summary, nil
+}
+
+// Reset clears all recorded failure statistics.
+func (s *Service) Reset() error {
+	return s.repo.ClearFailureStats()
+}
+
+var (
+	signatureURLPattern    = regexp.MustCompile(`[a-z]+://\S+`)
+	signaturePathPattern   = regexp.MustCompile(`(?:^|[\s"'(])/[^\s"')]+`)
+	signatureHexPattern    = regexp.MustCompile(`\b0x[0-9a-f]+\b`)
+	signatureNumberPattern = regexp.MustCompile(`\d+`)
+)
+
+// NormalizeSignature reduces an error message to a stable signature by stripping
+// URLs, file paths and numbers, so failures from different files group together.
+func NormalizeSignature(message string) string {
+	sig := strings.ToLower(strings.TrimSpace(message))
+	if sig == "" {
+		return "unknown"
+	}
+	sig = signatureURLPattern.ReplaceAllString(sig, "<url>")
+	sig = signaturePathPattern.ReplaceAllStringFunc(sig, func(match string) string {
+		// Keep the leading delimiter captured before the path
+		if match[0] != '/' {
+			return match[:1] + "<path>"
+		}
+		return "<path>"
+	})
+	sig = signatureHexPattern.ReplaceAllString(sig, "#")
+	sig = signatureNumberPattern.ReplaceAllString(sig, "#")
+	sig = strings.Join(strings.Fields(sig), " ")
+	if len(sig) > maxSignatureLength {
+		sig = sig[:maxSignatureLength]
+	}
+	return sig
+}
+
+func unknownIfEmpty(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package media_failures
+
+import (
+	"testing"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+type fakeRepository struct {
+	stats []*database.MediaFailureStat
+}
+
+func (f *fakeRepository) RecordFailure(stage, videoCodec, container, provider, signature, lastError string) error {
+	for _, stat := range f.stats {
+		if stat.Stage == stage && stat.VideoCodec == videoCodec && stat.Container == container && stat.Provider == provider && stat.Signature == signature {
+			stat.Count++
+			return nil
+		}
+	}
+	f.stats = append(f.stats, &database.MediaFailureStat{
+		Stage: stage, VideoCodec: videoCodec, Container: container, Provider: provider,
+		Signature: signature, Count: 1, LastError: &lastError, FirstSeen: time.Now(), LastSeen: time.Now(),
+	})
+	return nil
+}
+
+func (f *fakeRepository) ListFailureStats(stage string, limit int) ([]*database.MediaFailureStat, error) {
+	var out []*database.MediaFailureStat
+	for _, stat := range f.stats {
+		if stage == "" || stat.Stage == stage {
+			out = append(out, stat)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) ClearFailureStats() error {
+	f.stats = nil
+	return nil
+}
+
+func TestNormalizeSignatureGroupsSimilarErrors(t *testing.T) {
+	a := NormalizeSignature("ffprobe failed: /mnt/media/Movie.A.2019.mkv: Invalid data found at offset 12345")
+	b := NormalizeSignature("ffprobe failed: /mnt/media/Other.Film.2021.mkv: Invalid data found at offset 99")
+	if a != b {
+		t.Fatalf("expected identical signatures, got %q vs %q", a, b)
+	}
+
+	c := NormalizeSignature("provider stream: Get \"https://cdn.example.com/dl/abc123?token=x\": EOF")
+	if c != `provider stream: get "<url> eof` {
+		t.Fatalf("unexpected url normalisation: %q", c)
+	}
+}
+
+func TestSummaryAggregatesByDimension(t *testing.T) {
+	repo := &fakeRepository{}
+	svc, err := NewService(repo)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	svc.RecordFailure(models.MediaFailureEvent{Stage: "transcode", VideoCodec: "HEVC", Container: "mkv", Provider: "usenet", Error: "Error applying bitstream filters 1"})
+	svc.RecordFailure(models.MediaFailureEvent{Stage: "transcode", VideoCodec: "hevc", Container: "mkv", Provider: "usenet", Error: "Error applying bitstream filters 2"})
+	svc.RecordFailure(models.MediaFailureEvent{Stage: "probe", Container: "avi", Provider: "realdebrid", Error: "timeout"})
+
+	summary, err := svc.Summary("", 1)
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if summary.TotalFailures != 3 {
+		t.Fatalf("expected 3 failures, got %d", summary.TotalFailures)
+	}
+	if summary.ByVideoCodec["hevc"] != 2 || summary.ByVideoCodec["unknown"] != 1 {
+		t.Fatalf("unexpected codec breakdown: %+v", summary.ByVideoCodec)
+	}
+	if summary.ByProvider["usenet"] != 2 || summary.ByStage["probe"] != 1 {
+		t.Fatalf("unexpected provider/stage breakdown: %+v %+v", summary.ByProvider, summary.ByStage)
+	}
+	if len(summary.TopSignatures) != 1 || summary.TopSignatures[0].Count != 2 {
+		t.Fatalf("expected top signature with count 2, got %+v", summary.TopSignatures)
+	}
+}
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"slices"
+
+	"novastream/models"
+)
+
+// maxArtworkFallbacks bounds how many alternate URLs travel with each image.
+const maxArtworkFallbacks = 3
+
+// addArtworkFallback appends candidate to img's fallbacks unless it is the
+// primary URL, already listed, or the list is full.
+func addArtworkFallback(img *models.Image, candidate string) {
+	if img == nil || candidate == "" || candidate == img.URL || slices.Contains(img.Fallbacks, candidate) {
+		return
+	}
+	if len(img.Fallbacks) >= maxArtworkFallbacks {
+		return
+	}
+	img.Fallbacks = append(img.Fallbacks, candidate)
+}
+
+// preferArtworkFallback puts candidate at the front of img's fallbacks,
+// dropping the last entry if the list is full.
+func preferArtworkFallback(img *models.Image, candidate string) {
+	if img == nil || candidate == "" || candidate == img.URL {
+		return
+	}
+	rest := slices.DeleteFunc(slices.Clone(img.Fallbacks), func(u string) bool { return u == candidate })
+	img.Fallbacks = append([]string{candidate}, rest...)
+	if len(img.Fallbacks) > maxArtworkFallbacks {
+		img.Fallbacks = img.Fallbacks[:maxArtworkFallbacks]
+	}
+}
+
+// applyTMDBImages applies TMDB's logo and textless poster to title. The
+// artwork it replaces is kept as a fallback, and TMDB's best poster and
+// backdrop become the preferred substitutes for dead TVDB assets.
+func applyTMDBImages(title *models.Title, images *tmdbImagesResult) {
+	if images.Logo != nil {
+		title.Logo = images.Logo
+	}
+	if images.TextlessPoster != nil {
+		previous := title.Poster
+		title.Poster = images.TextlessPoster
+		if previous != nil {
+			addArtworkFallback(title.Poster, previous.URL)
+			for _, u := range previous.Fallbacks {
+				addArtworkFallback(title.Poster, u)
+			}
+		}
+	}
+	if images.Poster != nil {
+		preferArtworkFallback(title.Poster, images.Poster.URL)
+	}
+	if images.Backdrop != nil {
+		preferArtworkFallback(title.Backdrop, images.Backdrop.URL)
+	}
+}
@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"slices"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestApplyTVDBArtworksCollectsFallbacks(t *testing.T) {
+	arts := []tvdbArtwork{
+		{Image: "/banners/posters/1.jpg", Type: "2"},
+		{Image: "/banners/fanart/1.jpg", Type: "3"},
+		{Image: "/banners/posters/2.jpg", Type: "2"},
+		{Image: "/banners/fanart/2.jpg", Type: "3"},
+	}
+	var title models.Title
+	if !applyTVDBArtworks(&title, arts, "eng") {
+		t.Fatal("applyTVDBArtworks() reported no update")
+	}
+	if title.Poster.URL != tvdbArtworkBaseURL+"/banners/posters/1.jpg" {
+		t.Errorf("poster = %q", title.Poster.URL)
+	}
+	if !slices.Equal(title.Poster.Fallbacks, []string{tvdbArtworkBaseURL + "/banners/posters/2.jpg"}) {
+		t.Errorf("poster fallbacks = %v", title.Poster.Fallbacks)
+	}
+	if !slices.Equal(title.Backdrop.Fallbacks, []string{tvdbArtworkBaseURL + "/banners/fanart/2.jpg"}) {
+		t.Errorf("backdrop fallbacks = %v", title.Backdrop.Fallbacks)
+	}
+}
+
+func TestApplyTMDBImagesPrefersTMDBFallbacks(t *testing.T) {
+	title := models.Title{
+		Poster:   &models.Image{URL: "https://artworks.thetvdb.com/poster.jpg", Fallbacks: []string{"https://artworks.thetvdb.com/poster2.jpg"}},
+		Backdrop: &models.Image{URL: "https://artworks.thetvdb.com/fanart.jpg"},
+	}
+	applyTMDBImages(&title, &tmdbImagesResult{
+		TextlessPoster: &models.Image{URL: "https://image.tmdb.org/t/p/w780/textless.jpg"},
+		Poster:         &models.Image{URL: "https://image.tmdb.org/t/p/w780/poster.jpg"},
+		Backdrop:       &models.Image{URL: "https://image.tmdb.org/t/p/w1280/backdrop.jpg"},
+	})
+
+	wantPoster := []string{
+		"https://image.tmdb.org/t/p/w780/poster.jpg",
+		"https://artworks.thetvdb.com/poster.jpg",
+		"https://artworks.thetvdb.com/poster2.jpg",
+	}
+	if title.Poster.URL != "https://image.tmdb.org/t/p/w780/textless.jpg" || !slices.Equal(title.Poster.Fallbacks, wantPoster) {
+		t.Errorf("poster = %q fallbacks %v, want textless with %v", title.Poster.URL, title.Poster.Fallbacks, wantPoster)
+	}
+	if !slices.Equal(title.Backdrop.Fallbacks, []string{"https://image.tmdb.org/t/p/w1280/backdrop.jpg"}) {
+		t.Errorf("backdrop fallbacks = %v", title.Backdrop.Fallbacks)
+	}
+}
+
+func TestApplyTVDBArtworksPrefersLanguage(t *testing.T) {
+	arts := []tvdbArtwork{
+		{Image: "/banners/posters/jpn.jpg", Type: "2", Language: "jpn"},
+		{Image: "/banners/posters/eng.jpg", Type: "2", Language: "eng"},
+		{Image: "/banners/posters/none.jpg", Type: "2"},
+		{Image: "/banners/posters/deu.jpg", Type: "2", Language: "deu"},
+		{Image: "/banners/fanart/jpn.jpg", Type: "3", Language: "jpn"},
+		{Image: "/banners/fanart/none.jpg", Type: "3"},
+	}
+
+	tests := []struct {
+		language     string
+		wantPoster   string
+		wantBackdrop string
+	}{
+		{"de", "/banners/posters/deu.jpg", "/banners/fanart/none.jpg"},
+		{"eng", "/banners/posters/eng.jpg", "/banners/fanart/none.jpg"},
+		{"fra", "/banners/posters/none.jpg", "/banners/fanart/none.jpg"},
+		{"jpn", "/banners/posters/jpn.jpg", "/banners/fanart/jpn.jpg"},
+	}
+	for _, tt := range tests {
+		var title models.Title
+		applyTVDBArtworks(&title, arts, tt.language)
+		if title.Poster.URL != tvdbArtworkBaseURL+tt.wantPoster {
+			t.Errorf("language %q: poster = %q, want %q", tt.language, title.Poster.URL, tt.wantPoster)
+		}
+		if title.Backdrop.URL != tvdbArtworkBaseURL+tt.wantBackdrop {
+			t.Errorf("language %q: backdrop = %q, want %q", tt.language, title.Backdrop.URL, tt.wantBackdrop)
+		}
+	}
+}
+
+func TestApplyTVDBArtworksReplacesForeignDefaultPoster(t *testing.T) {
+	arts := []tvdbArtwork{
+		{Image: "/banners/posters/jpn.jpg", Type: "2", Language: "jpn"},
+		{Image: "/banners/posters/eng.jpg", Type: "2", Language: "eng"},
+	}
+	title := models.Title{
+		Poster:   &models.Image{URL: tvdbArtworkBaseURL + "/banners/posters/jpn.jpg", Type: "poster"},
+		Backdrop: &models.Image{URL: "https://image.tmdb.org/t/p/w1280/backdrop.jpg", Type: "backdrop"},
+	}
+	if !applyTVDBArtworks(&title, arts, "eng") {
+		t.Fatal("applyTVDBArtworks() reported no update")
+	}
+	if title.Poster.URL != tvdbArtworkBaseURL+"/banners/posters/eng.jpg" {
+		t.Errorf("poster = %q", title.Poster.URL)
+	}
+	if !slices.Equal(title.Poster.Fallbacks, []string{tvdbArtworkBaseURL + "/banners/posters/jpn.jpg"}) {
+		t.Errorf("poster fallbacks = %v", title.Poster.Fallbacks)
+	}
+	if title.Backdrop.URL != "https://image.tmdb.org/t/p/w1280/backdrop.jpg" {
+		t.Errorf("backdrop not from artworks should be kept, got %q", title.Backdrop.URL)
+	}
+}
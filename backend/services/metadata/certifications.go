@@ -0,0 +1,48 @@
+package metadata
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"novastream/models"
+)
+
+// AttachCertifications fills in the US certification of search results that
+// don't have one yet. Lookups go through TMDB and are cached, including
+// titles TMDB has no certification for, so repeated searches stay cheap.
+func (s *Service) AttachCertifications(ctx context.Context, results []models.SearchResult) {
+	if !s.tmdb.isConfigured() {
+		return
+	}
+	for i := range results {
+		if ctx.Err() != nil {
+			return
+		}
+		title := &results[i].Title
+		if title.Certification != "" {
+			continue
+		}
+		mediaType := "series"
+		if title.MediaType == "movie" {
+			mediaType = "movie"
+		}
+
+		// No name: a fuzzy name search could pick up another title's rating
+		tmdbID, err := s.resolveTMDBID(ctx, mediaType, title.ID, "", 0, title.TMDBID, title.TVDBID, title.IMDBID)
+		if err != nil || tmdbID <= 0 {
+			continue
+		}
+
+		cacheID := cacheKey("tmdb", "certification", mediaType, strconv.FormatInt(tmdbID, 10))
+		var cert string
+		if ok, _ := s.cache.get(cacheID, &cert); !ok {
+			if cert, err = s.tmdb.fetchCertification(ctx, mediaType, tmdbID); err != nil {
+				log.Printf("[metadata] certification lookup failed type=%s tmdbId=%d err=%v", mediaType, tmdbID, err)
+				continue
+			}
+			_ = s.cache.set(cacheID, cert)
+		}
+		title.Certification = cert
+	}
+}
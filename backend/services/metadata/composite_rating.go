@@ -0,0 +1,79 @@
+package metadata
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+// compositeRating normalizes each rating to a 0-100 scale and returns their
+// weighted average rounded to one decimal, or nil when no rated source has a
+// positive weight.
+func compositeRating(ratings []models.Rating, weights config.RatingWeights) *float64 {
+	var sum, total float64
+	for _, r := range ratings {
+		weight := weights.ForSource(r.Source)
+		if weight <= 0 || r.Value <= 0 {
+			continue
+		}
+		max := r.Max
+		if max <= 0 {
+			if info, ok := ratingSourceInfo[r.Source]; ok {
+				max = info.max
+			} else {
+				max = 10
+			}
+		}
+		sum += weight * math.Min(r.Value/max, 1) * 100
+		total += weight
+	}
+	if total == 0 {
+		return nil
+	}
+	score := math.Round(sum/total*10) / 10
+	return &score
+}
+
+// setRatings stores ratings on a title along with their composite score.
+func (s *Service) setRatings(title *models.Title, ratings []models.Rating) {
+	title.Ratings = ratings
+	title.CompositeRating = compositeRating(ratings, s.mdblist.Weights())
+}
+
+// AttachCompositeRatings fetches MDBList ratings for items that don't have a
+// composite score yet and fills in Ratings and CompositeRating in place.
+// Ratings are cached by the MDBList client, so only the first pass over a
+// row pays for the API calls. Items without an IMDB ID are left unrated.
+func (s *Service) AttachCompositeRatings(ctx context.Context, items []models.TrendingItem) {
+	if s.mdblist == nil || !s.mdblist.IsEnabled() {
+		return
+	}
+	for i := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		title := &items[i].Title
+		if title.IMDBID == "" || title.CompositeRating != nil {
+			continue
+		}
+		if len(title.Ratings) > 0 {
+			title.CompositeRating = compositeRating(title.Ratings, s.mdblist.Weights())
+			continue
+		}
+		mediaType := "movie"
+		if title.MediaType == "series" {
+			mediaType = "show"
+		}
+		ratings, err := s.mdblist.GetRatings(ctx, title.IMDBID, mediaType)
+		if err != nil {
+			log.Printf("[metadata] composite rating fetch failed imdbId=%s: %v", title.IMDBID, err)
+			continue
+		}
+		if len(ratings) > 0 {
+			s.setRatings(title, ratings)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+func TestCompositeRating(t *testing.T) {
+	ratings := []models.Rating{
+		{Source: "imdb", Value: 8, Max: 10},
+		{Source: "tomatoes", Value: 90, Max: 100},
+		{Source: "letterboxd", Value: 4, Max: 5},
+		{Source: "metacritic", Value: 0, Max: 100}, // missing score
+	}
+
+	got := compositeRating(ratings, config.RatingWeights{IMDB: 1, Tomatoes: 2, Metacritic: 1})
+	if got == nil {
+		t.Fatal("expected a score")
+	}
+	// (80*1 + 90*2) / 3, letterboxd unweighted and metacritic unrated
+	if *got != 86.7 {
+		t.Errorf("composite = %v, want 86.7", *got)
+	}
+
+	if got := compositeRating(ratings, config.RatingWeights{Trakt: 1}); got != nil {
+		t.Errorf("expected nil without weighted sources, got %v", *got)
+	}
+	if got := compositeRating(nil, config.DefaultRatingWeights()); got != nil {
+		t.Errorf("expected nil for no ratings, got %v", *got)
+	}
+}
+
+func TestCompositeRatingDefaultsMissingMax(t *testing.T) {
+	got := compositeRating([]models.Rating{{Source: "popcorn", Value: 75}}, config.RatingWeights{Audience: 1})
+	if got == nil || *got != 75 {
+		t.Errorf("composite = %v, want 75", got)
+	}
+}
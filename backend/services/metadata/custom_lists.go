@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CustomListRefreshState represents the outcome of the most recent background refresh
+type CustomListRefreshState string
+
+const (
+	CustomListRefreshRunning CustomListRefreshState = "running"
+	CustomListRefreshSuccess CustomListRefreshState = "success"
+	CustomListRefreshError   CustomListRefreshState = "error"
+)
+
+// CustomListRefreshStatus tracks background pre-enrichment of a single custom list
+type CustomListRefreshStatus struct {
+	ListURL         string                 `json:"listUrl"`
+	State           CustomListRefreshState `json:"state"`
+	ItemCount       int                    `json:"itemCount"`
+	LastStartedAt   time.Time              `json:"lastStartedAt"`
+	LastRefreshedAt *time.Time             `json:"lastRefreshedAt,omitempty"` // Last successful refresh
+	DurationMs      int64                  `json:"durationMs"`
+	LastError       string                 `json:"lastError,omitempty"`
+}
+
+// customListTracker holds in-memory refresh status per list URL (not persisted)
+type customListTracker struct {
+	mu       sync.RWMutex
+	statuses map[string]*CustomListRefreshStatus
+}
+
+// customListCacheKey returns the cache key for a fully enriched custom list.
+// v3: includes release data (with IMDB→TMDB resolution) and series status enrichment
+func customListCacheKey(listURL string) string {
+	return cacheKey("mdblist", "custom", "v3", listURL)
+}
+
+// RefreshCustomList re-fetches and fully enriches a custom MDBList, replacing the
+// cached copy so home screen requests are served without enrichment delays.
+func (s *Service) RefreshCustomList(ctx context.Context, listURL string) (int, error) {
+	started := time.Now().UTC()
+	s.customLists.update(listURL, func(status *CustomListRefreshStatus) {
+		status.State = CustomListRefreshRunning
+		status.LastStartedAt = started
+	})
+
+	items, _, err := s.fetchCustomList(ctx, customListCacheKey(listURL), listURL, 0)
+
+	s.customLists.update(listURL, func(status *CustomListRefreshStatus) {
+		status.DurationMs = time.Since(started).Milliseconds()
+		if err != nil {
+			status.State = CustomListRefreshError
+			status.LastError = err.Error()
+			return
+		}
+		now := time.Now().UTC()
+		status.State = CustomListRefreshSuccess
+		status.LastError = ""
+		status.ItemCount = len(items)
+		status.LastRefreshedAt = &now
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("[metadata] pre-enriched custom list %s (%d items) in %s", listURL, len(items), time.Since(started).Round(time.Millisecond))
+	return len(items), nil
+}
+
+// CustomListStatuses returns the refresh status of every custom list seen by the refresher.
+func (s *Service) CustomListStatuses() []CustomListRefreshStatus {
+	s.customLists.mu.RLock()
+	defer s.customLists.mu.RUnlock()
+
+	statuses := make([]CustomListRefreshStatus, 0, len(s.customLists.statuses))
+	for _, status := range s.customLists.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ListURL < statuses[j].ListURL
+	})
+	return statuses
+}
+
+func (t *customListTracker) update(listURL string, fn func(status *CustomListRefreshStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.statuses == nil {
+		t.statuses = make(map[string]*CustomListRefreshStatus)
+	}
+	status, ok := t.statuses[listURL]
+	if !ok {
+		status = &CustomListRefreshStatus{ListURL: listURL}
+		t.statuses[listURL] = status
+	}
+	fn(status)
+}
@@ -0,0 +1,159 @@
+package metadata
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	enrichMinConcurrency     = 1
+	enrichInitialConcurrency = 5
+	enrichMaxConcurrency     = 16
+	// enrichBackoffInterval is the minimum time between two reductions, so a
+	// burst of 429s from requests already in flight only halves the limit once.
+	enrichBackoffInterval = 2 * time.Second
+)
+
+// enrichLimiter bounds concurrent metadata enrichment shared by trending,
+// batch details and custom list enrichment. It adapts to API health: the
+// limit halves when TMDB or TVDB answer 429 or 5xx, and grows by one after
+// a limit's worth of successful responses.
+type enrichLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	inFlight    int
+	successes   int
+	lastBackoff time.Time
+	waiters     []chan struct{}
+	now         func() time.Time
+}
+
+func newEnrichLimiter() *enrichLimiter {
+	return &enrichLimiter{limit: enrichInitialConcurrency, now: time.Now}
+}
+
+// acquire waits for an enrichment slot. It returns the context's error if the
+// context ends first, in which case no slot is held.
+func (l *enrichLimiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, waiter := range l.waiters {
+			if waiter == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// The slot was granted while giving up; hand it on
+		l.inFlight--
+		l.grantLocked()
+		return ctx.Err()
+	}
+}
+
+// release returns a slot taken by acquire.
+func (l *enrichLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.grantLocked()
+}
+
+// grantLocked hands free slots to waiters in arrival order.
+func (l *enrichLimiter) grantLocked() {
+	for l.inFlight < l.limit && len(l.waiters) > 0 {
+		ready := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+		close(ready)
+	}
+}
+
+// succeeded records a healthy API response.
+func (l *enrichLimiter) succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.successes++
+	if l.successes < l.limit || l.limit >= enrichMaxConcurrency {
+		return
+	}
+	l.successes = 0
+	l.limit++
+	l.grantLocked()
+}
+
+// throttled records a rate-limited or failing API response.
+func (l *enrichLimiter) throttled(status int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.lastBackoff) < enrichBackoffInterval {
+		return
+	}
+	l.lastBackoff = now
+	l.successes = 0
+	previous := l.limit
+	l.limit = max(enrichMinConcurrency, l.limit/2)
+	if l.limit != previous {
+		log.Printf("[metadata] enrichment concurrency %d -> %d after status %d", previous, l.limit, status)
+	}
+}
+
+// concurrency returns the current limit.
+func (l *enrichLimiter) concurrency() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// httpClient returns an HTTP client whose responses feed the limiter.
+func (l *enrichLimiter) httpClient() *http.Client {
+	return &http.Client{Transport: &enrichHealthTransport{next: http.DefaultTransport, limiter: l}}
+}
+
+// enrichHealthTransport reports each metadata API response to the limiter.
+type enrichHealthTransport struct {
+	next    http.RoundTripper
+	limiter *enrichLimiter
+}
+
+func (t *enrichHealthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		t.limiter.throttled(resp.StatusCode)
+	case resp.StatusCode < 400:
+		t.limiter.succeeded()
+	}
+	return resp, nil
+}
+
+// enrichSlots returns the service's enrichment limiter, creating it for
+// services built without NewService.
+func (s *Service) enrichSlots() *enrichLimiter {
+	s.enrichOnce.Do(func() {
+		if s.enrich == nil {
+			s.enrich = newEnrichLimiter()
+		}
+	})
+	return s.enrich
+}
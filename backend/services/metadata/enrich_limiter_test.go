@@ -0,0 +1,114 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnrichLimiterBacksOffAndRecovers(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newEnrichLimiter()
+	l.now = func() time.Time { return clock }
+
+	l.throttled(http.StatusTooManyRequests)
+	if got := l.concurrency(); got != 2 {
+		t.Fatalf("after 429 concurrency = %d, want 2", got)
+	}
+	// Other requests from the same burst don't halve it again
+	l.throttled(http.StatusTooManyRequests)
+	if got := l.concurrency(); got != 2 {
+		t.Fatalf("within backoff interval concurrency = %d, want 2", got)
+	}
+	clock = clock.Add(enrichBackoffInterval)
+	l.throttled(http.StatusServiceUnavailable)
+	l.throttled(http.StatusServiceUnavailable)
+	if got := l.concurrency(); got != enrichMinConcurrency {
+		t.Fatalf("concurrency = %d, want floor %d", got, enrichMinConcurrency)
+	}
+
+	// One step up per limit's worth of successes
+	l.succeeded()
+	if got := l.concurrency(); got != 2 {
+		t.Fatalf("after 1 success concurrency = %d, want 2", got)
+	}
+	l.succeeded()
+	l.succeeded()
+	if got := l.concurrency(); got != 3 {
+		t.Fatalf("after 3 successes concurrency = %d, want 3", got)
+	}
+	for i := 0; i < 1000; i++ {
+		l.succeeded()
+	}
+	if got := l.concurrency(); got != enrichMaxConcurrency {
+		t.Fatalf("concurrency = %d, want cap %d", got, enrichMaxConcurrency)
+	}
+}
+
+func TestEnrichLimiterBoundsInFlight(t *testing.T) {
+	l := newEnrichLimiter()
+	l.limit = 1
+	ctx := context.Background()
+
+	if err := l.acquire(ctx); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(timeoutCtx); err == nil {
+		t.Fatal("expected second acquire to wait until the context ends")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if err := l.acquire(ctx); err == nil {
+			close(acquired)
+		}
+	}()
+	// Growing the limit admits the waiter without a release
+	l.succeeded()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not admitted when the limit grew")
+	}
+	l.release()
+	l.release()
+	if l.inFlight != 0 || len(l.waiters) != 0 {
+		t.Fatalf("inFlight=%d waiters=%d, want none", l.inFlight, len(l.waiters))
+	}
+}
+
+func TestEnrichHealthTransportReportsStatus(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	l := newEnrichLimiter()
+	client := l.httpClient()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if got := l.concurrency(); got != enrichInitialConcurrency/2 {
+		t.Fatalf("after 429 concurrency = %d, want %d", got, enrichInitialConcurrency/2)
+	}
+
+	status = http.StatusOK
+	for i := 0; i < enrichInitialConcurrency/2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if got := l.concurrency(); got != enrichInitialConcurrency/2+1 {
+		t.Fatalf("after successes concurrency = %d, want %d", got, enrichInitialConcurrency/2+1)
+	}
+}
@@ -0,0 +1,305 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+const (
+	// heroRotationTTL is how long a hero rotation is served before it is
+	// rebuilt. Trailer stream URLs expire after a few hours, so this must stay
+	// well below that.
+	heroRotationTTL = time.Hour
+	// heroEnrichConcurrency bounds parallel image, trailer and yt-dlp lookups.
+	heroEnrichConcurrency = 4
+	// heroBuildTimeout bounds building a rotation, mostly spent in yt-dlp.
+	heroBuildTimeout = 2 * time.Minute
+	// heroMinBackdropAspect rejects backdrops that would letterbox a 16:9 hero.
+	heroMinBackdropAspect = 1.6
+	// heroMaxLimit caps the number of slides a rotation can request.
+	heroMaxLimit = 20
+)
+
+// heroCacheEntry is a built rotation kept until it expires.
+type heroCacheEntry struct {
+	rotation  models.HeroRotation
+	expiresAt time.Time
+}
+
+// heroRotationCache holds built rotations keyed by their candidate set.
+type heroRotationCache struct {
+	mu      sync.Mutex
+	entries map[string]heroCacheEntry
+	httpc   *http.Client
+}
+
+// HeroRotation returns the home screen hero slides: up to limit titles drawn
+// from the user's watchlist and the trending movie and series lists, each with
+// a validated wide backdrop, logo art and a pre-resolved trailer stream URL.
+// Titles without a usable backdrop are skipped. Rotations are cached for an
+// hour; the same candidate set within that window is served from memory.
+func (s *Service) HeroRotation(ctx context.Context, watchlist []models.Title, trendingMovieSource config.TrendingMovieSource, limit int) (*models.HeroRotation, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > heroMaxLimit {
+		limit = heroMaxLimit
+	}
+
+	movies, movieErr := s.Trending(ctx, "movie", trendingMovieSource)
+	if movieErr != nil {
+		log.Printf("[metadata] hero rotation: trending movies failed: %v", movieErr)
+	}
+	series, seriesErr := s.Trending(ctx, "series", trendingMovieSource)
+	if seriesErr != nil {
+		log.Printf("[metadata] hero rotation: trending series failed: %v", seriesErr)
+	}
+	if movieErr != nil && seriesErr != nil && len(watchlist) == 0 {
+		return nil, fmt.Errorf("hero rotation: no candidates: %w", movieErr)
+	}
+
+	// Consider twice as many titles as requested so rejected backdrops can be replaced.
+	candidates := heroCandidates(watchlist, movies, series, limit*2)
+	key := heroCacheKey(candidates, limit)
+
+	cache := s.heroCache()
+	cache.mu.Lock()
+	if entry, ok := cache.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		cache.mu.Unlock()
+		return &entry.rotation, nil
+	}
+	cache.mu.Unlock()
+
+	// The rotation is shared for an hour, so don't let one client disconnecting
+	// cut its enrichment short.
+	buildCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), heroBuildTimeout)
+	defer cancel()
+
+	items := make([]*models.HeroItem, len(candidates))
+	sem := make(chan struct{}, heroEnrichConcurrency)
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func(i int, candidate heroCandidate) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			items[i] = s.buildHeroItem(buildCtx, cache.httpc, candidate)
+		}(i, candidate)
+	}
+	wg.Wait()
+
+	now := time.Now().UTC()
+	rotation := models.HeroRotation{
+		Items:       make([]models.HeroItem, 0, limit),
+		GeneratedAt: now,
+		ExpiresAt:   now.Add(heroRotationTTL),
+	}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		rotation.Items = append(rotation.Items, *item)
+		if len(rotation.Items) == limit {
+			break
+		}
+	}
+	log.Printf("[metadata] hero rotation built: %d/%d candidates usable", len(rotation.Items), len(candidates))
+
+	cache.mu.Lock()
+	for k, entry := range cache.entries {
+		if now.After(entry.expiresAt) {
+			delete(cache.entries, k)
+		}
+	}
+	cache.entries[key] = heroCacheEntry{rotation: rotation, expiresAt: rotation.ExpiresAt}
+	cache.mu.Unlock()
+
+	return &rotation, nil
+}
+
+// heroCache returns the service's rotation cache, creating it on first use.
+func (s *Service) heroCache() *heroRotationCache {
+	s.heroOnce.Do(func() {
+		s.hero = &heroRotationCache{
+			entries: make(map[string]heroCacheEntry),
+			httpc:   &http.Client{Timeout: 10 * time.Second},
+		}
+	})
+	return s.hero
+}
+
+// heroCandidate is a title considered for the rotation and where it came from.
+type heroCandidate struct {
+	title  models.Title
+	source string
+}
+
+// heroCandidates picks up to maxCount titles: watchlist entries fill at most half
+// the slots, the rest alternate between trending movies and series.
+// Duplicates (by ID) are dropped.
+func heroCandidates(watchlist []models.Title, movies, series []models.TrendingItem, maxCount int) []heroCandidate {
+	seen := make(map[string]bool)
+	out := make([]heroCandidate, 0, maxCount)
+	add := func(title models.Title, source string) {
+		id := strings.TrimSpace(title.ID)
+		if id == "" || seen[id] || len(out) >= maxCount {
+			return
+		}
+		seen[id] = true
+		out = append(out, heroCandidate{title: title, source: source})
+	}
+
+	for _, title := range watchlist {
+		if len(out) >= maxCount/2 {
+			break
+		}
+		add(title, "watchlist")
+	}
+	for i := 0; i < len(movies) || i < len(series); i++ {
+		if i < len(movies) {
+			add(movies[i].Title, "trending")
+		}
+		if i < len(series) {
+			add(series[i].Title, "trending")
+		}
+	}
+	return out
+}
+
+// heroCacheKey identifies a rotation by its ordered candidates and size.
+func heroCacheKey(candidates []heroCandidate, limit int) string {
+	parts := make([]string, 0, len(candidates)+2)
+	parts = append(parts, "hero", fmt.Sprintf("%d", limit))
+	for _, c := range candidates {
+		parts = append(parts, c.source+"="+c.title.ID)
+	}
+	return cacheKey(parts...)
+}
+
+// buildHeroItem resolves artwork and trailer for a candidate. Returns nil when
+// no wide, reachable backdrop is available.
+func (s *Service) buildHeroItem(ctx context.Context, httpc *http.Client, candidate heroCandidate) *models.HeroItem {
+	title := candidate.title
+
+	var images *tmdbImagesResult
+	if title.TMDBID > 0 && s.tmdb != nil && s.tmdb.isConfigured() {
+		var err error
+		if images, err = s.tmdb.fetchImages(ctx, title.MediaType, title.TMDBID); err != nil {
+			log.Printf("[metadata] hero rotation: tmdb images failed for %q (tmdbId=%d): %v", title.Name, title.TMDBID, err)
+		}
+	}
+
+	var backdropCandidates []*models.Image
+	if images != nil && images.Backdrop != nil {
+		backdropCandidates = append(backdropCandidates, images.Backdrop)
+	}
+	if title.Backdrop != nil {
+		backdropCandidates = append(backdropCandidates, title.Backdrop)
+		for _, fallback := range title.Backdrop.Fallbacks {
+			backdropCandidates = append(backdropCandidates, &models.Image{URL: fallback, Type: "backdrop"})
+		}
+	}
+	backdrop := firstUsableBackdrop(ctx, httpc, backdropCandidates)
+	if backdrop == nil {
+		log.Printf("[metadata] hero rotation: skipping %q, no usable backdrop", title.Name)
+		return nil
+	}
+
+	item := &models.HeroItem{
+		Title:    title,
+		Source:   candidate.source,
+		Backdrop: backdrop,
+		Logo:     title.Logo,
+		Trailer:  title.PrimaryTrailer,
+	}
+	if images != nil && images.Logo != nil {
+		item.Logo = images.Logo
+	}
+
+	if item.Trailer == nil {
+		resp, err := s.Trailers(ctx, models.TrailerQuery{
+			MediaType: title.MediaType,
+			TitleID:   title.ID,
+			Name:      title.Name,
+			Year:      title.Year,
+			IMDBID:    title.IMDBID,
+			TMDBID:    title.TMDBID,
+			TVDBID:    title.TVDBID,
+		})
+		if err != nil {
+			log.Printf("[metadata] hero rotation: trailers failed for %q: %v", title.Name, err)
+		} else if resp != nil {
+			item.Trailer = resp.PrimaryTrailer
+		}
+	}
+	if item.Trailer != nil && isYouTubeURL(item.Trailer.URL) {
+		if streamURL, err := s.ExtractTrailerStreamURL(ctx, item.Trailer.URL); err != nil {
+			log.Printf("[metadata] hero rotation: trailer stream failed for %q: %v", title.Name, err)
+		} else {
+			item.TrailerStreamURL = streamURL
+		}
+	}
+
+	return item
+}
+
+// firstUsableBackdrop returns a copy of the first candidate that is wide
+// enough (when its dimensions are known) and loads as an image.
+func firstUsableBackdrop(ctx context.Context, httpc *http.Client, candidates []*models.Image) *models.Image {
+	checked := make(map[string]bool)
+	for _, img := range candidates {
+		if img == nil || strings.TrimSpace(img.URL) == "" || checked[img.URL] {
+			continue
+		}
+		checked[img.URL] = true
+		if img.Width > 0 && img.Height > 0 && float64(img.Width)/float64(img.Height) < heroMinBackdropAspect {
+			continue
+		}
+		if err := checkImageURL(ctx, httpc, img.URL); err != nil {
+			log.Printf("[metadata] hero rotation: backdrop %s rejected: %v", img.URL, err)
+			continue
+		}
+		usable := *img
+		usable.Type = "backdrop"
+		usable.Fallbacks = nil
+		return &usable
+	}
+	return nil
+}
+
+// checkImageURL fetches the first byte of url and verifies it is an image.
+// A ranged GET is used since some artwork CDNs reject HEAD requests.
+func checkImageURL(ctx context.Context, httpc *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := httpc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/") {
+		return fmt.Errorf("unexpected content type %q", contentType)
+	}
+	return nil
+}
+
+// isYouTubeURL reports whether url points at YouTube, the only trailer host
+// yt-dlp extraction is used for.
+func isYouTubeURL(url string) bool {
+	return strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be")
+}
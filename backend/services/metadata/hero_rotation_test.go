@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestHeroCandidatesMixesWatchlistAndTrending(t *testing.T) {
+	watchlist := []models.Title{{ID: "w1"}, {ID: "m1"}, {ID: "w2"}, {ID: "w3"}}
+	movies := []models.TrendingItem{{Title: models.Title{ID: "m1"}}, {Title: models.Title{ID: "m2"}}}
+	series := []models.TrendingItem{{Title: models.Title{ID: "s1"}}, {Title: models.Title{ID: ""}}, {Title: models.Title{ID: "s3"}}}
+
+	got := heroCandidates(watchlist, movies, series, 6)
+
+	want := []struct{ id, source string }{
+		{"w1", "watchlist"},
+		{"m1", "watchlist"},
+		{"w2", "watchlist"},
+		{"s1", "trending"},
+		{"m2", "trending"},
+		{"s3", "trending"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d candidates, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].title.ID != w.id || got[i].source != w.source {
+			t.Errorf("candidate %d = %s/%s, want %s/%s", i, got[i].title.ID, got[i].source, w.id, w.source)
+		}
+	}
+}
+
+func TestFirstUsableBackdropSkipsDeadAndNarrowImages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dead.jpg":
+			http.NotFound(w, r)
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("expected ranged request, got Range=%q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0xff})
+		}
+	}))
+	defer srv.Close()
+
+	candidates := []*models.Image{
+		{URL: srv.URL + "/dead.jpg"},
+		{URL: srv.URL + "/page"},
+		{URL: srv.URL + "/square.jpg", Width: 1000, Height: 1000},
+		{URL: srv.URL + "/wide.jpg", Width: 1920, Height: 1080, Fallbacks: []string{"x"}},
+	}
+
+	got := firstUsableBackdrop(context.Background(), srv.Client(), candidates)
+	if got == nil || got.URL != srv.URL+"/wide.jpg" {
+		t.Fatalf("expected wide backdrop, got %+v", got)
+	}
+	if got.Type != "backdrop" || len(got.Fallbacks) != 0 {
+		t.Fatalf("expected normalised backdrop, got %+v", got)
+	}
+	if candidates[3].Fallbacks == nil {
+		t.Fatal("candidate image should not be modified")
+	}
+
+	if got := firstUsableBackdrop(context.Background(), srv.Client(), candidates[:3]); got != nil {
+		t.Fatalf("expected no usable backdrop, got %+v", got)
+	}
+}
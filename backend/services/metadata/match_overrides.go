@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"novastream/models"
+)
+
+// MatchOverrideProvider looks up manual corrections for titles that automatic
+// matching resolves to the wrong TVDB/TMDB entry. Implemented by
+// *match_overrides.Service.
+type MatchOverrideProvider interface {
+	Lookup(mediaType, titleID, name string, year int) (models.MatchOverride, bool)
+}
+
+// SetMatchOverrides enables manual match corrections. Overrides take priority
+// over IDs sent by clients, since those came from the wrong match.
+func (s *Service) SetMatchOverrides(provider MatchOverrideProvider) {
+	s.matchOverrides = provider
+}
+
+func (s *Service) matchOverride(mediaType, titleID, name string, year int) (models.MatchOverride, bool) {
+	if s.matchOverrides == nil {
+		return models.MatchOverride{}, false
+	}
+	override, ok := s.matchOverrides.Lookup(mediaType, titleID, name, year)
+	if ok {
+		log.Printf("[metadata] match override applied type=%s titleId=%q name=%q year=%d → tvdbId=%d tmdbId=%d",
+			mediaType, titleID, name, year, override.TVDBID, override.TMDBID)
+	}
+	return override, ok
+}
+
+// applyMovieMatchOverride replaces the IDs in req with the pinned match.
+// Reports whether an override applied, in which case name searches must not
+// be used to resolve the movie.
+func (s *Service) applyMovieMatchOverride(req models.MovieDetailsQuery) (models.MovieDetailsQuery, bool) {
+	override, ok := s.matchOverride("movie", req.TitleID, req.Name, req.Year)
+	if !ok {
+		return req, false
+	}
+	req.TitleID = ""
+	req.TVDBID = override.TVDBID
+	req.TMDBID = override.TMDBID
+	req.IMDBID = override.IMDBID
+	return req, true
+}
+
+// resolveOverriddenSeriesTVDBID finds the TVDB series for a TMDB-only override.
+// Unlike name resolution it only accepts an exact TMDB remote ID match, and it
+// caches under its own key since the regular TMDB→TVDB mapping may hold the
+// wrong match that prompted the override.
+func (s *Service) resolveOverriddenSeriesTVDBID(name string, year int, tmdbID int64) (int64, error) {
+	cacheID := cacheKey("tvdb", "resolve", "tmdb", "exact", fmt.Sprintf("%d", tmdbID))
+	var cachedTVDBID int64
+	if ok, _ := s.cache.get(cacheID, &cachedTVDBID); ok && cachedTVDBID > 0 {
+		return cachedTVDBID, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("series name required to resolve overridden tmdb id %d", tmdbID)
+	}
+
+	years := []int{year}
+	if year > 0 {
+		years = append(years, 0)
+	}
+	for _, y := range years {
+		results, err := s.searchTVDBSeries(name, y, "")
+		if err != nil {
+			return 0, err
+		}
+		if id := tvdbIDWithTMDBRemoteID(results, tmdbID); id > 0 {
+			_ = s.cache.set(cacheID, id)
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("no tvdb series with tmdb id %d found for %q", tmdbID, name)
+}
+
+// tvdbIDWithTMDBRemoteID returns the TVDB ID of the search result linked to the TMDB ID.
+func tvdbIDWithTMDBRemoteID(results []tvdbSearchResult, tmdbID int64) int64 {
+	tmdbIDStr := strconv.FormatInt(tmdbID, 10)
+	for _, result := range results {
+		for _, remote := range result.RemoteIDs {
+			source := strings.ToLower(remote.SourceName)
+			if !strings.Contains(source, "themoviedb") && !strings.Contains(source, "tmdb") {
+				continue
+			}
+			if strings.TrimSpace(remote.ID) != tmdbIDStr {
+				continue
+			}
+			if id, err := strconv.ParseInt(strings.TrimSpace(result.TVDBID), 10, 64); err == nil && id > 0 {
+				return id
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"novastream/models"
+)
+
+type staticOverrides map[string]models.MatchOverride
+
+func (o staticOverrides) Lookup(mediaType, titleID, name string, year int) (models.MatchOverride, bool) {
+	override, ok := o[titleID]
+	return override, ok && override.MediaType == mediaType
+}
+
+func TestResolveSeriesTVDBIDUsesOverride(t *testing.T) {
+	s := &Service{}
+	s.SetMatchOverrides(staticOverrides{
+		"tvdb:series:78107": {MediaType: "series", TVDBID: 73244},
+	})
+
+	// The client's TVDB ID belongs to the wrong same-name series
+	id, err := s.resolveSeriesTVDBID(models.SeriesDetailsQuery{TitleID: "tvdb:series:78107", Name: "The Office", TVDBID: 78107})
+	if err != nil || id != 73244 {
+		t.Fatalf("resolveSeriesTVDBID() = %d, %v; want 73244", id, err)
+	}
+}
+
+func TestApplyMovieMatchOverrideReplacesClientIDs(t *testing.T) {
+	s := &Service{}
+	s.SetMatchOverrides(staticOverrides{
+		"tmdb:movie:1": {MediaType: "movie", TMDBID: 2, IMDBID: "tt0000002"},
+	})
+
+	req, overridden := s.applyMovieMatchOverride(models.MovieDetailsQuery{TitleID: "tmdb:movie:1", TMDBID: 1, TVDBID: 10, IMDBID: "tt0000001"})
+	if !overridden {
+		t.Fatal("override not applied")
+	}
+	if req.TitleID != "" || req.TVDBID != 0 || req.TMDBID != 2 || req.IMDBID != "tt0000002" {
+		t.Errorf("query = %+v, want only the pinned IDs", req)
+	}
+
+	if _, overridden := s.applyMovieMatchOverride(models.MovieDetailsQuery{TitleID: "tmdb:movie:3"}); overridden {
+		t.Error("override applied to another title")
+	}
+}
+
+func TestTVDBIDWithTMDBRemoteID(t *testing.T) {
+	var results []tvdbSearchResult
+	payload := `[
+		{"tvdb_id": "78107", "remote_ids": [{"id": "2996", "sourceName": "TheMovieDB.com"}]},
+		{"tvdb_id": "73244", "remote_ids": [{"id": "tt0386676", "sourceName": "IMDB"}, {"id": "2316", "sourceName": "TheMovieDB.com"}]}
+	]`
+	if err := json.Unmarshal([]byte(payload), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tvdbIDWithTMDBRemoteID(results, 2316); got != 73244 {
+		t.Errorf("tvdbIDWithTMDBRemoteID(2316) = %d, want 73244", got)
+	}
+	if got := tvdbIDWithTMDBRemoteID(results, 1); got != 0 {
+		t.Errorf("tvdbIDWithTMDBRemoteID(1) = %d, want 0", got)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"novastream/config"
 	"novastream/models"
 )
 
@@ -17,6 +18,7 @@ import (
 type mdblistClient struct {
 	apiKey         string
 	enabledRatings map[string]bool
+	weights        config.RatingWeights
 	httpClient     *http.Client
 	enabled        bool
 
@@ -66,7 +68,7 @@ type mdblistMediaResponse struct {
 	} `json:"ratings"`
 }
 
-func newMDBListClient(apiKey string, enabledRatings []string, enabled bool, cacheTTLHours int) *mdblistClient {
+func newMDBListClient(apiKey string, enabledRatings []string, enabled bool, weights config.RatingWeights, cacheTTLHours int) *mdblistClient {
 	enabledMap := make(map[string]bool)
 	for _, r := range enabledRatings {
 		enabledMap[r] = true
@@ -79,6 +81,7 @@ func newMDBListClient(apiKey string, enabledRatings []string, enabled bool, cach
 	return &mdblistClient{
 		apiKey:         apiKey,
 		enabledRatings: enabledMap,
+		weights:        weights,
 		httpClient:     &http.Client{Timeout: 10 * time.Second},
 		enabled:        enabled,
 		cache:          make(map[string]*mdblistCacheEntry),
@@ -88,7 +91,7 @@ func newMDBListClient(apiKey string, enabledRatings []string, enabled bool, cach
 }
 
 // UpdateSettings updates the client configuration
-func (c *mdblistClient) UpdateSettings(apiKey string, enabledRatings []string, enabled bool) {
+func (c *mdblistClient) UpdateSettings(apiKey string, enabledRatings []string, enabled bool, weights config.RatingWeights) {
 	enabledMap := make(map[string]bool)
 	for _, r := range enabledRatings {
 		enabledMap[r] = true
@@ -105,6 +108,14 @@ func (c *mdblistClient) UpdateSettings(apiKey string, enabledRatings []string, e
 	c.apiKey = apiKey
 	c.enabledRatings = enabledMap
 	c.enabled = enabled
+	c.weights = weights
+}
+
+// Weights returns the per-source weights used for composite scores
+func (c *mdblistClient) Weights() config.RatingWeights {
+	c.cacheMu.RLock()
+	defer c.cacheMu.RUnlock()
+	return c.weights
 }
 
 // GetRatings fetches all ratings for a title from MDBList in a single API call
@@ -24,6 +24,7 @@ import (
 
 	"novastream/config"
 	"novastream/models"
+	"novastream/utils/sandbox"
 )
 
 type Service struct {
@@ -44,6 +45,27 @@ type Service struct {
 
 	// Trailer prequeue manager for 1080p YouTube trailers
 	trailerPrequeue *TrailerPrequeueManager
+
+	// Background refresh status for custom MDBList shelves
+	customLists customListTracker
+
+	// Manual corrections for mis-matched titles
+	matchOverrides MatchOverrideProvider
+
+	// Home screen hero rotations, built on first use
+	heroOnce sync.Once
+	hero     *heroRotationCache
+
+	// Concurrency budget for enrichment, adapted to TMDB/TVDB health
+	enrichOnce sync.Once
+	enrich     *enrichLimiter
+
+	// Background refresh of TMDB's image base URL and sizes
+	tmdbConfigPath   string
+	tmdbConfigMu     sync.Mutex
+	tmdbConfigCancel context.CancelFunc
+	tmdbConfigWG     sync.WaitGroup
+	tmdbConfigNudge  chan struct{}
 }
 
 type inflightRequest struct {
@@ -56,9 +78,10 @@ const tvdbArtworkBaseURL = "https://artworks.thetvdb.com"
 
 // MDBListConfig holds configuration for the MDBList client
 type MDBListConfig struct {
-	APIKey         string
-	Enabled        bool
-	EnabledRatings []string
+	APIKey           string
+	Enabled          bool
+	EnabledRatings   []string
+	CompositeWeights config.RatingWeights
 }
 
 // stableIDCacheTTLMultiplier is used for ID mappings (TMDB↔IMDB) that rarely change
@@ -77,24 +100,29 @@ func NewService(tvdbAPIKey, tmdbAPIKey, language, cacheDir string, ttlHours int,
 		log.Printf("[metadata] WARNING: failed to initialize trailer prequeue manager: %v", err)
 	}
 
+	enrich := newEnrichLimiter()
 	return &Service{
-		client:           newTVDBClient(tvdbAPIKey, language, &http.Client{}, ttlHours),
-		tmdb:             newTMDBClient(tmdbAPIKey, language, &http.Client{}, newFileCache(metadataCacheDir, ttlHours)),
-		mdblist:          newMDBListClient(mdblistCfg.APIKey, mdblistCfg.EnabledRatings, mdblistCfg.Enabled, ttlHours),
+		client:           newTVDBClient(tvdbAPIKey, language, enrich.httpClient(), ttlHours),
+		tmdb:             newTMDBClient(tmdbAPIKey, language, enrich.httpClient(), newFileCache(metadataCacheDir, ttlHours)),
+		mdblist:          newMDBListClient(mdblistCfg.APIKey, mdblistCfg.EnabledRatings, mdblistCfg.Enabled, mdblistCfg.CompositeWeights, ttlHours),
 		cache:            newFileCache(metadataCacheDir, ttlHours),
 		idCache:          newFileCache(idCacheDir, ttlHours*stableIDCacheTTLMultiplier),
 		demo:             demo,
 		ttlHours:         ttlHours,
 		inflightRequests: make(map[string]*inflightRequest),
 		trailerPrequeue:  trailerMgr,
+		enrich:           enrich,
+		tmdbConfigPath:   filepath.Join(cacheDir, tmdbConfigFile),
+		tmdbConfigNudge:  make(chan struct{}, 1),
 	}
 }
 
 // UpdateAPIKeys updates the API keys for TVDB and TMDB clients
 // This allows hot reloading when settings change
 func (s *Service) UpdateAPIKeys(tvdbAPIKey, tmdbAPIKey, language string) {
-	s.client = newTVDBClient(tvdbAPIKey, language, &http.Client{}, s.ttlHours)
-	s.tmdb = newTMDBClient(tmdbAPIKey, language, &http.Client{}, s.cache)
+	s.client = newTVDBClient(tvdbAPIKey, language, s.enrichSlots().httpClient(), s.ttlHours)
+	s.tmdb = newTMDBClient(tmdbAPIKey, language, s.enrichSlots().httpClient(), s.cache)
+	s.refreshTMDBConfigSoon()
 
 	// Clear all cached metadata so fresh data is fetched with new API keys
 	if err := s.cache.clear(); err != nil {
@@ -113,7 +141,7 @@ func (s *Service) UpdateAPIKeys(tvdbAPIKey, tmdbAPIKey, language string) {
 // UpdateMDBListSettings updates the MDBList client configuration
 func (s *Service) UpdateMDBListSettings(cfg MDBListConfig) {
 	if s.mdblist != nil {
-		s.mdblist.UpdateSettings(cfg.APIKey, cfg.EnabledRatings, cfg.Enabled)
+		s.mdblist.UpdateSettings(cfg.APIKey, cfg.EnabledRatings, cfg.Enabled, cfg.CompositeWeights)
 		log.Printf("[metadata] updated MDBList settings (enabled=%v, ratings=%v)", cfg.Enabled, cfg.EnabledRatings)
 	}
 }
@@ -317,7 +345,7 @@ func (s *Service) enrichDemoArtwork(ctx context.Context, items []models.Trending
 		// Fetch artwork from TVDB
 		if mediaType == "movie" {
 			if ext, err := s.client.movieExtended(title.TVDBID, []string{"artwork"}); err == nil {
-				applyTVDBArtworks(title, ext.Artworks)
+				applyTVDBArtworks(title, ext.Artworks, s.tvdbLanguage())
 			}
 		} else {
 			if ext, err := s.client.seriesExtended(title.TVDBID, []string{"artworks"}); err == nil {
@@ -333,7 +361,7 @@ func (s *Service) enrichDemoArtwork(ctx context.Context, items []models.Trending
 					title.Backdrop = backdrop
 				}
 				// Then apply artworks array
-				applyTVDBArtworks(title, ext.Artworks)
+				applyTVDBArtworks(title, ext.Artworks, s.tvdbLanguage())
 				log.Printf("[demo] series tvdbId=%d after enrichment hasPoster=%v hasBackdrop=%v",
 					title.TVDBID, title.Poster != nil, title.Backdrop != nil)
 			} else {
@@ -348,10 +376,9 @@ func (s *Service) enrichDemoArtwork(ctx context.Context, items []models.Trending
 
 // enrichTrendingIMDBIDs adds IMDB IDs to trending items using cached lookups.
 // This runs concurrently for performance but uses the ID cache to minimize API calls.
-// Uses a semaphore to limit concurrent TMDB API calls and prevent thundering herd.
+// Shares the adaptive enrichment budget to limit concurrent TMDB API calls and prevent thundering herd.
 func (s *Service) enrichTrendingIMDBIDs(ctx context.Context, items []models.TrendingItem, mediaType string) {
-	const maxConcurrent = 5
-	sem := make(chan struct{}, maxConcurrent)
+	slots := s.enrichSlots()
 	var wg sync.WaitGroup
 	for idx := range items {
 		if items[idx].Title.IMDBID != "" || items[idx].Title.TMDBID <= 0 {
@@ -360,8 +387,10 @@ func (s *Service) enrichTrendingIMDBIDs(ctx context.Context, items []models.Tren
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if slots.acquire(ctx) != nil {
+				return
+			}
+			defer slots.release()
 			imdbID := s.getIMDBIDForTMDB(ctx, mediaType, items[i].Title.TMDBID)
 			if imdbID != "" {
 				items[i].Title.IMDBID = imdbID
@@ -374,8 +403,7 @@ func (s *Service) enrichTrendingIMDBIDs(ctx context.Context, items []models.Tren
 // enrichTrendingMovieReleases adds release data (theatrical/home release) to trending movie items.
 // This runs concurrently for performance. Release data is cached by enrichMovieReleases.
 func (s *Service) enrichTrendingMovieReleases(ctx context.Context, items []models.TrendingItem) {
-	const maxConcurrent = 5
-	sem := make(chan struct{}, maxConcurrent)
+	slots := s.enrichSlots()
 	var wg sync.WaitGroup
 	var enrichedCount int32
 
@@ -397,8 +425,10 @@ func (s *Service) enrichTrendingMovieReleases(ctx context.Context, items []model
 		wg.Add(1)
 		go func(i int, tmdbID int64) {
 			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if slots.acquire(ctx) != nil {
+				return
+			}
+			defer slots.release()
 
 			if s.enrichMovieReleases(ctx, &items[i].Title, tmdbID) {
 				atomic.AddInt32(&enrichedCount, 1)
@@ -607,7 +637,7 @@ func (s *Service) getRecentMovies() ([]models.TrendingItem, error) {
 			// Get additional artwork from TVDB if we have a TVDB ID
 			if title.TVDBID > 0 {
 				if ext, err := s.client.movieExtended(title.TVDBID, []string{"artwork"}); err == nil {
-					applyTVDBArtworks(&title, ext.Artworks)
+					applyTVDBArtworks(&title, ext.Artworks, s.tvdbLanguage())
 					if title.Backdrop == nil {
 						log.Printf("[metadata] no movie backdrop from artworks title=%q tvdbId=%d", title.Name, title.TVDBID)
 					}
@@ -667,7 +697,8 @@ func (s *Service) getMovieDetailsFromTMDB(ctx context.Context, req models.MovieD
 	log.Printf("[metadata] fetching movie details from TMDB tmdbId=%d name=%q", req.TMDBID, req.Name)
 
 	// Check cache with TMDB key
-	cacheID := cacheKey("tmdb", "movie", "details", "v1", s.client.language, strconv.FormatInt(req.TMDBID, 10))
+	// v2 adds TMDB logo and textless poster
+	cacheID := cacheKey("tmdb", "movie", "details", "v2", s.client.language, strconv.FormatInt(req.TMDBID, 10))
 	var cached models.Title
 	if ok, _ := s.cache.get(cacheID, &cached); ok && cached.ID != "" {
 		log.Printf("[metadata] movie details cache hit (TMDB) tmdbId=%d lang=%s", req.TMDBID, s.client.language)
@@ -718,6 +749,12 @@ func (s *Service) getMovieDetailsFromTMDB(ctx context.Context, req models.MovieD
 		log.Printf("[metadata] failed to fetch credits for movie (TMDB) tmdbId=%d: %v", req.TMDBID, err)
 	}
 
+	if images, err := s.tmdb.fetchImages(ctx, "movie", req.TMDBID); err == nil && images != nil {
+		applyTMDBImages(&movieTitle, images)
+	} else if err != nil {
+		log.Printf("[metadata] failed to fetch images for movie (TMDB) tmdbId=%d: %v", req.TMDBID, err)
+	}
+
 	// Cache the result
 	_ = s.cache.set(cacheID, movieTitle)
 
@@ -1020,7 +1057,7 @@ func (s *Service) getTrendingSeries() ([]models.TrendingItem, error) {
 	for idx := range items {
 		if items[idx].Title.TVDBID > 0 {
 			if arts, err := s.client.seriesArtworks(items[idx].Title.TVDBID); err == nil {
-				applyTVDBArtworks(&items[idx].Title, arts)
+				applyTVDBArtworks(&items[idx].Title, arts, s.tvdbLanguage())
 				if items[idx].Title.Backdrop == nil {
 					log.Printf("[metadata] no series backdrop from artworks title=%q tvdbId=%d", items[idx].Title.Name, items[idx].Title.TVDBID)
 				}
@@ -1053,6 +1090,10 @@ func (s *Service) Search(ctx context.Context, query string, mediaType string) ([
 		return s.searchDemo(ctx, q, mediaType), nil
 	}
 
+	if s.tmdbOnly() {
+		return s.searchFromTMDB(ctx, q, mediaType)
+	}
+
 	key := cacheKey("tvdb", "search", mediaType, q)
 	var cached []models.SearchResult
 	if ok, _ := s.cache.get(key, &cached); ok {
@@ -1295,6 +1336,14 @@ func (s *Service) fetchTVDBAliases(mediaType string, tvdbID int64) []string {
 }
 
 func (s *Service) resolveSeriesTVDBID(req models.SeriesDetailsQuery) (int64, error) {
+	// Manual corrections win over whatever IDs the client sent
+	if override, ok := s.matchOverride("series", req.TitleID, req.Name, req.Year); ok {
+		if override.TVDBID > 0 {
+			return override.TVDBID, nil
+		}
+		return s.resolveOverriddenSeriesTVDBID(req.Name, req.Year, override.TMDBID)
+	}
+
 	// Fast path: if we already have the TVDB ID, return it
 	if req.TVDBID > 0 {
 		return req.TVDBID, nil
@@ -1521,31 +1570,111 @@ func normalizeTVDBImageURL(value string) string {
 	return tvdbArtworkBaseURL + "/" + strings.TrimPrefix(trimmed, "/")
 }
 
-func applyTVDBArtworks(title *models.Title, arts []tvdbArtwork) bool {
+// applyTVDBArtworks fills in a missing poster and backdrop from TVDB artworks,
+// choosing by language (see rankTVDBArtworksByLanguage). A poster or backdrop
+// already taken from the artworks is replaced when one in a better-ranked
+// language exists, so TVDB's default foreign-text poster doesn't stick.
+func applyTVDBArtworks(title *models.Title, arts []tvdbArtwork, language string) bool {
 	if title == nil {
 		return false
 	}
+	ranked := rankTVDBArtworksByLanguage(arts, language)
+	if title.Poster != nil && tvdbArtworkOutranked(title.Poster.URL, ranked, language, artworkLooksLikePoster) {
+		title.Poster = nil
+	}
+	if title.Backdrop != nil && tvdbArtworkOutranked(title.Backdrop.URL, ranked, language, artworkLooksLikeBackdrop) {
+		title.Backdrop = nil
+	}
+	// Only images picked here collect the remaining artworks as fallbacks
+	ownPoster, ownBackdrop := title.Poster == nil, title.Backdrop == nil
+	if !ownPoster && !ownBackdrop {
+		return false
+	}
 	updated := false
-	for _, art := range arts {
+	for _, art := range ranked {
 		normalized := normalizeTVDBImageURL(art.Image)
 		if normalized == "" {
 			continue
 		}
-		if title.Poster == nil && artworkLooksLikePoster(art) {
-			title.Poster = &models.Image{URL: normalized, Type: "poster", Width: art.Width, Height: art.Height}
-			updated = true
-		}
-		if title.Backdrop == nil && artworkLooksLikeBackdrop(art) {
-			title.Backdrop = &models.Image{URL: normalized, Type: "backdrop", Width: art.Width, Height: art.Height}
-			updated = true
+		if ownPoster && artworkLooksLikePoster(art) {
+			if title.Poster == nil {
+				title.Poster = &models.Image{URL: normalized, Type: "poster", Width: art.Width, Height: art.Height}
+				updated = true
+			} else {
+				addArtworkFallback(title.Poster, normalized)
+			}
 		}
-		if title.Poster != nil && title.Backdrop != nil {
-			break
+		if ownBackdrop && artworkLooksLikeBackdrop(art) {
+			if title.Backdrop == nil {
+				title.Backdrop = &models.Image{URL: normalized, Type: "backdrop", Width: art.Width, Height: art.Height}
+				updated = true
+			} else {
+				addArtworkFallback(title.Backdrop, normalized)
+			}
 		}
 	}
 	return updated
 }
 
+// rankTVDBArtworksByLanguage orders artworks for display in language (a TVDB
+// three-letter code): artwork in that language first, then textless artwork,
+// then English, then everything else. TVDB's own order is kept within a rank.
+func rankTVDBArtworksByLanguage(arts []tvdbArtwork, language string) []tvdbArtwork {
+	ranked := make([]tvdbArtwork, len(arts))
+	copy(ranked, arts)
+	language = normalizeTVDBLanguage(language)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return tvdbArtworkLanguageRank(ranked[i].Language, language) < tvdbArtworkLanguageRank(ranked[j].Language, language)
+	})
+	return ranked
+}
+
+// tvdbArtworkOutranked reports whether current is one of the ranked artworks
+// of a kind and a better-ranked artwork of that kind exists.
+func tvdbArtworkOutranked(current string, ranked []tvdbArtwork, language string, isKind func(tvdbArtwork) bool) bool {
+	language = normalizeTVDBLanguage(language)
+	bestRank := -1
+	for _, art := range ranked {
+		normalized := normalizeTVDBImageURL(art.Image)
+		if normalized == "" || !isKind(art) {
+			continue
+		}
+		rank := tvdbArtworkLanguageRank(art.Language, language)
+		if bestRank < 0 {
+			bestRank = rank
+		}
+		if normalized == current {
+			return rank > bestRank
+		}
+	}
+	return false
+}
+
+// tvdbArtworkLanguageRank scores an artwork language against the preferred
+// (normalised) language; lower is better. Textless artwork has no language.
+func tvdbArtworkLanguageRank(artLanguage, preferred string) int {
+	if strings.TrimSpace(artLanguage) == "" {
+		return 1
+	}
+	artLanguage = normalizeTVDBLanguage(artLanguage)
+	switch {
+	case artLanguage == preferred:
+		return 0
+	case artLanguage == "eng":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// tvdbLanguage returns the configured metadata language as a TVDB code.
+func (s *Service) tvdbLanguage() string {
+	if s.client == nil {
+		return ""
+	}
+	return s.client.language
+}
+
 func artworkLooksLikePoster(art tvdbArtwork) bool {
 	lt := strings.ToLower(art.Type.String())
 	switch {
@@ -1582,6 +1711,9 @@ func (s *Service) SeriesDetails(ctx context.Context, req models.SeriesDetailsQue
 	if s.client == nil {
 		return nil, fmt.Errorf("tvdb client not configured")
 	}
+	if s.tmdbOnly() {
+		return s.seriesDetailsFromTMDB(ctx, req)
+	}
 
 	log.Printf("[metadata] series details request titleId=%q name=%q year=%d tvdbId=%d",
 
@@ -1614,7 +1746,7 @@ func (s *Service) SeriesDetails(ctx context.Context, req models.SeriesDetailsQue
 			log.Printf("[metadata] cached series missing backdrop, fetching artworks tvdbId=%d", tvdbID)
 			if extended, err := s.client.seriesExtended(tvdbID, []string{"artworks"}); err == nil {
 				log.Printf("[metadata] received %d artworks for cached series tvdbId=%d", len(extended.Artworks), tvdbID)
-				applyTVDBArtworks(&cached.Title, extended.Artworks)
+				applyTVDBArtworks(&cached.Title, extended.Artworks, s.tvdbLanguage())
 				if cached.Title.Backdrop != nil {
 					log.Printf("[metadata] backdrop added to cached series: %s", cached.Title.Backdrop.URL)
 					// Update cache with enriched data
@@ -1866,7 +1998,7 @@ func (s *Service) SeriesDetails(ctx context.Context, req models.SeriesDetailsQue
 	// Apply artworks from extended response (fetched in single combined call)
 	if len(extended.Artworks) > 0 {
 		log.Printf("[metadata] received %d artworks for tvdbId=%d", len(extended.Artworks), tvdbID)
-		applyTVDBArtworks(&seriesTitle, extended.Artworks)
+		applyTVDBArtworks(&seriesTitle, extended.Artworks, s.tvdbLanguage())
 		if seriesTitle.Backdrop != nil {
 			log.Printf("[metadata] series backdrop URL: %s", seriesTitle.Backdrop.URL)
 		}
@@ -2046,7 +2178,7 @@ func (s *Service) SeriesDetails(ctx context.Context, req models.SeriesDetailsQue
 	// Fetch ratings from MDBList if enabled and IMDB ID is available
 	if seriesTitle.IMDBID != "" && s.mdblist != nil && s.mdblist.IsEnabled() {
 		if ratings, err := s.mdblist.GetRatings(ctx, seriesTitle.IMDBID, "show"); err == nil && len(ratings) > 0 {
-			seriesTitle.Ratings = ratings
+			s.setRatings(&seriesTitle, ratings)
 			details.Title = seriesTitle // Update the details with ratings
 			log.Printf("[metadata] fetched %d ratings for series imdbId=%s", len(ratings), seriesTitle.IMDBID)
 		}
@@ -2066,12 +2198,11 @@ func (s *Service) SeriesDetails(ctx context.Context, req models.SeriesDetailsQue
 	// Fetch logo and textless poster from TMDB if configured
 	if seriesTitle.TMDBID > 0 && s.tmdb != nil && s.tmdb.isConfigured() {
 		if images, err := s.tmdb.fetchImages(ctx, "series", seriesTitle.TMDBID); err == nil && images != nil {
+			applyTMDBImages(&seriesTitle, images)
 			if images.Logo != nil {
-				seriesTitle.Logo = images.Logo
 				log.Printf("[metadata] fetched logo for series tmdbId=%d", seriesTitle.TMDBID)
 			}
 			if images.TextlessPoster != nil {
-				seriesTitle.Poster = images.TextlessPoster
 				log.Printf("[metadata] textless poster applied to series tmdbId=%d", seriesTitle.TMDBID)
 			}
 			details.Title = seriesTitle // Update the details with images
@@ -2131,6 +2262,13 @@ func (s *Service) BatchSeriesDetails(ctx context.Context, queries []models.Serie
 	for i, query := range queries {
 		results[i].Query = query
 
+		// Without TVDB there's no TVDB ID to key the cache on; SeriesDetails
+		// checks the TMDB cache itself
+		if s.tmdbOnly() {
+			tasksToFetch = append(tasksToFetch, fetchTask{index: i, query: query})
+			continue
+		}
+
 		// Try to get from cache using the same logic as SeriesDetails
 		tvdbID, err := s.resolveSeriesTVDBID(query)
 		if err != nil {
@@ -2162,9 +2300,8 @@ func (s *Service) BatchSeriesDetails(ctx context.Context, queries []models.Serie
 	log.Printf("[metadata] batch series fetching cached=%d uncached=%d total=%d",
 		len(queries)-len(tasksToFetch), len(tasksToFetch), len(queries))
 
-	// Second pass: fetch uncached items concurrently with controlled parallelism
-	const maxConcurrent = 5
-	sem := make(chan struct{}, maxConcurrent)
+	// Second pass: fetch uncached items concurrently within the shared enrichment budget
+	slots := s.enrichSlots()
 	var wg sync.WaitGroup
 
 	for _, task := range tasksToFetch {
@@ -2172,9 +2309,11 @@ func (s *Service) BatchSeriesDetails(ctx context.Context, queries []models.Serie
 		go func(idx int, q models.SeriesDetailsQuery) {
 			defer wg.Done()
 
-			// Acquire semaphore
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if err := slots.acquire(ctx); err != nil {
+				results[idx].Error = err.Error()
+				return
+			}
+			defer slots.release()
 
 			// Fetch the details
 			details, err := s.SeriesDetails(ctx, q)
@@ -2262,14 +2401,19 @@ func (s *Service) BatchMovieReleases(ctx context.Context, queries []models.Batch
 	var mu sync.Mutex
 
 	// Limit concurrency to avoid overwhelming TMDB
-	sem := make(chan struct{}, 5)
+	slots := s.enrichSlots()
 
 	for _, task := range tasksToFetch {
 		wg.Add(1)
 		go func(t fetchTask) {
 			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if err := slots.acquire(ctx); err != nil {
+				mu.Lock()
+				results[t.index].Error = err.Error()
+				mu.Unlock()
+				return
+			}
+			defer slots.release()
 
 			tempTitle := &models.Title{TMDBID: t.tmdbID}
 			if s.enrichMovieReleases(ctx, tempTitle, t.tmdbID) {
@@ -2296,6 +2440,9 @@ func (s *Service) SeriesInfo(ctx context.Context, req models.SeriesDetailsQuery)
 	if s.client == nil {
 		return nil, fmt.Errorf("tvdb client not configured")
 	}
+	if s.tmdbOnly() {
+		return s.seriesInfoFromTMDB(ctx, req)
+	}
 
 	log.Printf("[metadata] series info request (lightweight) titleId=%q name=%q year=%d tvdbId=%d",
 		strings.TrimSpace(req.TitleID), strings.TrimSpace(req.Name), req.Year, req.TVDBID)
@@ -2407,7 +2554,7 @@ func (s *Service) SeriesInfo(ctx context.Context, req models.SeriesDetailsQuery)
 	}
 
 	// Apply additional artworks from the artworks array
-	applyTVDBArtworks(&seriesTitle, extended.Artworks)
+	applyTVDBArtworks(&seriesTitle, extended.Artworks, s.tvdbLanguage())
 
 	// Note: Ratings are NOT fetched here to keep this lightweight.
 	// Use SeriesDetails for full metadata including ratings.
@@ -2590,10 +2737,18 @@ func (s *Service) movieDetailsInternal(ctx context.Context, req models.MovieDeta
 	if s.client == nil {
 		return nil, fmt.Errorf("tvdb client not configured")
 	}
+	if s.tmdbOnly() {
+		return s.movieDetailsFromTMDB(ctx, req, includeRatings)
+	}
 
 	log.Printf("[metadata] movie details request titleId=%q name=%q year=%d tvdbId=%d tmdbId=%d imdbId=%s",
 		strings.TrimSpace(req.TitleID), strings.TrimSpace(req.Name), req.Year, req.TVDBID, req.TMDBID, strings.TrimSpace(req.IMDBID))
 
+	// Manual corrections replace the client's IDs. Without a pinned TVDB ID the
+	// movie is served from TMDB, since the cached mapping or name search may be
+	// what picked the wrong title.
+	req, overridden := s.applyMovieMatchOverride(req)
+
 	// Try to resolve TVDB ID
 	tvdbID := req.TVDBID
 
@@ -2603,7 +2758,7 @@ func (s *Service) movieDetailsInternal(ctx context.Context, req models.MovieDeta
 	}
 
 	// If still no TVDB ID, try TMDB or search
-	if tvdbID <= 0 {
+	if tvdbID <= 0 && !overridden {
 		// Check if we have a cached TMDB→TVDB ID mapping
 		if req.TMDBID > 0 {
 			cacheID := cacheKey("tvdb", "resolve", "movie", "tmdb", fmt.Sprintf("%d", req.TMDBID))
@@ -2772,7 +2927,7 @@ func (s *Service) movieDetailsInternal(ctx context.Context, req models.MovieDeta
 	var extended *tvdbMovieExtendedData
 	if ext, err := s.client.movieExtended(tvdbID, []string{"artwork"}); err == nil {
 		extended = &ext
-		applyTVDBArtworks(&movieTitle, ext.Artworks)
+		applyTVDBArtworks(&movieTitle, ext.Artworks, s.tvdbLanguage())
 		if movieTitle.Backdrop == nil {
 			log.Printf("[metadata] no movie backdrop from TVDB artworks tvdbId=%d name=%q", tvdbID, finalName)
 		}
@@ -2842,7 +2997,7 @@ func (s *Service) movieDetailsInternal(ctx context.Context, req models.MovieDeta
 			if ratings, err := s.mdblist.GetRatings(ctx, imdbIDForRatings, "movie"); err != nil {
 				log.Printf("[metadata] error fetching ratings for movie imdbId=%s: %v", imdbIDForRatings, err)
 			} else if len(ratings) > 0 {
-				movieTitle.Ratings = ratings
+				s.setRatings(&movieTitle, ratings)
 				log.Printf("[metadata] fetched %d ratings for movie imdbId=%s", len(ratings), imdbIDForRatings)
 			}
 		}
@@ -2869,12 +3024,11 @@ func (s *Service) movieDetailsInternal(ctx context.Context, req models.MovieDeta
 	}
 	if tmdbIDForImages > 0 && s.tmdb != nil && s.tmdb.isConfigured() {
 		if images, err := s.tmdb.fetchImages(ctx, "movie", tmdbIDForImages); err == nil && images != nil {
+			applyTMDBImages(&movieTitle, images)
 			if images.Logo != nil {
-				movieTitle.Logo = images.Logo
 				log.Printf("[metadata] fetched logo for movie tmdbId=%d", tmdbIDForImages)
 			}
 			if images.TextlessPoster != nil {
-				movieTitle.Poster = images.TextlessPoster
 				log.Printf("[metadata] textless poster applied to movie tmdbId=%d", tmdbIDForImages)
 			}
 		} else if err != nil {
@@ -3120,7 +3274,7 @@ func (s *Service) Trailers(ctx context.Context, req models.TrailerQuery) (*model
 		}
 	}
 
-	if tvdbID > 0 && s.client != nil {
+	if tvdbID > 0 && s.client.isConfigured() {
 		var (
 			tvdbTrailers []models.Trailer
 			err          error
@@ -3580,8 +3734,7 @@ func (s *Service) ResolveIMDBID(ctx context.Context, title string, mediaType str
 // Returns the items, total count, and any error.
 func (s *Service) GetCustomList(ctx context.Context, listURL string, limit int) ([]models.TrendingItem, int, error) {
 	// Check cache first - cache stores all enriched items
-	// v3: includes release data (with IMDB→TMDB resolution) and series status enrichment
-	cacheID := cacheKey("mdblist", "custom", "v3", listURL)
+	cacheID := customListCacheKey(listURL)
 	var cached []models.TrendingItem
 	if ok, _ := s.cache.get(cacheID, &cached); ok && len(cached) > 0 {
 		log.Printf("[metadata] custom list cache hit for %s (%d items)", listURL, len(cached))
@@ -3592,6 +3745,12 @@ func (s *Service) GetCustomList(ctx context.Context, listURL string, limit int)
 		return cached, len(cached), nil
 	}
 
+	return s.fetchCustomList(ctx, cacheID, listURL, limit)
+}
+
+// fetchCustomList downloads and enriches a custom MDBList, bypassing the cache.
+// The enriched list is written back to the cache when every item was enriched.
+func (s *Service) fetchCustomList(ctx context.Context, cacheID, listURL string, limit int) ([]models.TrendingItem, int, error) {
 	// Fetch items from the custom MDBList
 	mdblistItems, err := s.client.FetchMDBListCustom(listURL)
 	if err != nil {
@@ -3608,238 +3767,254 @@ func (s *Service) GetCustomList(ctx context.Context, listURL string, limit int)
 		log.Printf("[metadata] limiting enrichment to %d items (total: %d)", enrichCount, totalCount)
 	}
 
-	// Convert to TrendingItem and enrich with TVDB data where possible
-	items := make([]models.TrendingItem, 0, enrichCount)
-	for i, item := range mdblistItems {
-		// Only enrich up to enrichCount items
-		if i >= enrichCount {
-			break
-		}
+	// Convert to TrendingItem and enrich with TVDB data where possible, sharing
+	// the enrichment budget with trending and batch details
+	items := make([]models.TrendingItem, enrichCount)
+	slots := s.enrichSlots()
+	var wg sync.WaitGroup
+	for i := range items {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if slots.acquire(ctx) != nil {
+				return
+			}
+			defer slots.release()
+			items[i] = s.enrichCustomListItem(ctx, mdblistItems[i])
+		}(i)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
 
-		// Determine media type from MDBList item
-		mediaType := "movie"
-		if item.MediaType == "show" || item.MediaType == "series" || item.MediaType == "tv" {
-			mediaType = "series"
-		}
+	// Only cache if we enriched all items (no limit applied)
+	// This ensures the cache always has the full list
+	if len(items) > 0 && (limit == 0 || limit >= totalCount) {
+		_ = s.cache.set(cacheID, items)
+		log.Printf("[metadata] cached %d enriched items for custom list: %s", len(items), listURL)
+	}
 
-		// Create base title from MDBList data
-		title := models.Title{
-			ID:         fmt.Sprintf("mdblist:%s:%d", mediaType, item.ID),
-			Name:       item.Title,
-			Year:       item.ReleaseYear,
-			Language:   s.client.language,
-			MediaType:  mediaType,
-			Popularity: float64(100 - item.Rank),
-		}
+	return items, totalCount, nil
+}
 
-		// Set IMDB ID from MDBList
-		if item.IMDBID != "" {
-			title.IMDBID = item.IMDBID
-		}
+// enrichCustomListItem converts a custom MDBList item to a trending item,
+// matching it to TVDB and adding release data or series status.
+func (s *Service) enrichCustomListItem(ctx context.Context, item mdblistItem) models.TrendingItem {
+	// Determine media type from MDBList item
+	mediaType := "movie"
+	if item.MediaType == "show" || item.MediaType == "series" || item.MediaType == "tv" {
+		mediaType = "series"
+	}
 
-		// Set TMDB ID from MDBList if available
-		if item.TMDBID != nil && *item.TMDBID > 0 {
-			title.TMDBID = *item.TMDBID
-		}
+	// Create base title from MDBList data
+	title := models.Title{
+		ID:         fmt.Sprintf("mdblist:%s:%d", mediaType, item.ID),
+		Name:       item.Title,
+		Year:       item.ReleaseYear,
+		Language:   s.client.language,
+		MediaType:  mediaType,
+		Popularity: float64(100 - item.Rank),
+	}
 
-		// Try to enrich with TVDB data
-		var found bool
+	// Set IMDB ID from MDBList
+	if item.IMDBID != "" {
+		title.IMDBID = item.IMDBID
+	}
 
-		// First, try to use TVDB ID from MDBList if available
-		if item.TVDBID != nil && *item.TVDBID > 0 {
-			if mediaType == "movie" {
-				if tvdbDetails, err := s.getTVDBMovieDetails(*item.TVDBID); err == nil {
-					title.TVDBID = *item.TVDBID
-					title.ID = fmt.Sprintf("tvdb:movie:%d", *item.TVDBID)
-					title.Name = tvdbDetails.Name
-					title.Overview = tvdbDetails.Overview
-					found = true
+	// Set TMDB ID from MDBList if available
+	if item.TMDBID != nil && *item.TMDBID > 0 {
+		title.TMDBID = *item.TMDBID
+	}
 
-					// Fetch translated name/overview if available
-					if translation, err := s.client.movieTranslations(*item.TVDBID, s.client.language); err == nil && translation != nil {
-						if translation.Name != "" {
-							title.Name = translation.Name
-						}
-						if translation.Overview != "" {
-							title.Overview = translation.Overview
-						}
-					}
+	// Try to enrich with TVDB data
+	var found bool
 
-					// Get artwork
-					if ext, err := s.client.movieExtended(*item.TVDBID, []string{"artwork"}); err == nil {
-						applyTVDBArtworks(&title, ext.Artworks)
+	// First, try to use TVDB ID from MDBList if available
+	if item.TVDBID != nil && *item.TVDBID > 0 {
+		if mediaType == "movie" {
+			if tvdbDetails, err := s.getTVDBMovieDetails(*item.TVDBID); err == nil {
+				title.TVDBID = *item.TVDBID
+				title.ID = fmt.Sprintf("tvdb:movie:%d", *item.TVDBID)
+				title.Name = tvdbDetails.Name
+				title.Overview = tvdbDetails.Overview
+				found = true
+
+				// Fetch translated name/overview if available
+				if translation, err := s.client.movieTranslations(*item.TVDBID, s.client.language); err == nil && translation != nil {
+					if translation.Name != "" {
+						title.Name = translation.Name
 					}
-				}
-			} else {
-				if tvdbDetails, err := s.getTVDBSeriesDetails(*item.TVDBID); err == nil {
-					title.TVDBID = *item.TVDBID
-					title.ID = fmt.Sprintf("tvdb:series:%d", *item.TVDBID)
-					title.Overview = tvdbDetails.Overview
-					if tvdbDetails.Score > 0 {
-						title.Popularity = tvdbDetails.Score
+					if translation.Overview != "" {
+						title.Overview = translation.Overview
 					}
-					found = true
+				}
 
-					// Fetch translated overview if available
-					if translation, err := s.client.seriesTranslations(*item.TVDBID, s.client.language); err == nil && translation != nil {
-						if translation.Name != "" {
-							title.Name = translation.Name
-						}
-						if translation.Overview != "" {
-							title.Overview = translation.Overview
-						}
-					}
+				// Get artwork
+				if ext, err := s.client.movieExtended(*item.TVDBID, []string{"artwork"}); err == nil {
+					applyTVDBArtworks(&title, ext.Artworks, s.tvdbLanguage())
+				}
+			}
+		} else {
+			if tvdbDetails, err := s.getTVDBSeriesDetails(*item.TVDBID); err == nil {
+				title.TVDBID = *item.TVDBID
+				title.ID = fmt.Sprintf("tvdb:series:%d", *item.TVDBID)
+				title.Overview = tvdbDetails.Overview
+				if tvdbDetails.Score > 0 {
+					title.Popularity = tvdbDetails.Score
+				}
+				found = true
 
-					// Get artwork
-					if ext, err := s.client.seriesExtended(*item.TVDBID, []string{"artworks"}); err == nil {
-						applyTVDBArtworks(&title, ext.Artworks)
+				// Fetch translated overview if available
+				if translation, err := s.client.seriesTranslations(*item.TVDBID, s.client.language); err == nil && translation != nil {
+					if translation.Name != "" {
+						title.Name = translation.Name
+					}
+					if translation.Overview != "" {
+						title.Overview = translation.Overview
 					}
 				}
+
+				// Get artwork
+				if ext, err := s.client.seriesExtended(*item.TVDBID, []string{"artworks"}); err == nil {
+					applyTVDBArtworks(&title, ext.Artworks, s.tvdbLanguage())
+				}
 			}
 		}
+	}
 
-		// Fallback: search TVDB by title/year if no TVDB ID or direct lookup failed
-		if !found {
-			// Use IMDB ID as remote_id if available (TVDB recognizes IMDB IDs), otherwise empty
-			remoteID := item.IMDBID
-			if mediaType == "movie" {
-				// Try to search TVDB by title/year
-				searchResults, err := s.searchTVDBMovie(item.Title, item.ReleaseYear, remoteID)
-				if err != nil {
-					log.Printf("[metadata] custom list movie tvdb search error title=%q year=%d imdbId=%q err=%v", item.Title, item.ReleaseYear, item.IMDBID, err)
-				} else if len(searchResults) == 0 {
-					log.Printf("[metadata] custom list movie tvdb search returned 0 results title=%q year=%d imdbId=%q", item.Title, item.ReleaseYear, item.IMDBID)
-					// Fallback: retry without year constraint
-					if item.ReleaseYear > 0 {
-						log.Printf("[metadata] custom list movie tvdb search retrying without year title=%q imdbId=%q", item.Title, item.IMDBID)
-						searchResults, err = s.searchTVDBMovie(item.Title, 0, remoteID)
-						if err != nil {
-							log.Printf("[metadata] custom list movie tvdb search (no year) error title=%q imdbId=%q err=%v", item.Title, item.IMDBID, err)
-						} else if len(searchResults) > 0 {
-							log.Printf("[metadata] custom list movie tvdb search (no year) found %d results title=%q imdbId=%q", len(searchResults), item.Title, item.IMDBID)
-						}
+	// Fallback: search TVDB by title/year if no TVDB ID or direct lookup failed
+	if !found {
+		// Use IMDB ID as remote_id if available (TVDB recognizes IMDB IDs), otherwise empty
+		remoteID := item.IMDBID
+		if mediaType == "movie" {
+			// Try to search TVDB by title/year
+			searchResults, err := s.searchTVDBMovie(item.Title, item.ReleaseYear, remoteID)
+			if err != nil {
+				log.Printf("[metadata] custom list movie tvdb search error title=%q year=%d imdbId=%q err=%v", item.Title, item.ReleaseYear, item.IMDBID, err)
+			} else if len(searchResults) == 0 {
+				log.Printf("[metadata] custom list movie tvdb search returned 0 results title=%q year=%d imdbId=%q", item.Title, item.ReleaseYear, item.IMDBID)
+				// Fallback: retry without year constraint
+				if item.ReleaseYear > 0 {
+					log.Printf("[metadata] custom list movie tvdb search retrying without year title=%q imdbId=%q", item.Title, item.IMDBID)
+					searchResults, err = s.searchTVDBMovie(item.Title, 0, remoteID)
+					if err != nil {
+						log.Printf("[metadata] custom list movie tvdb search (no year) error title=%q imdbId=%q err=%v", item.Title, item.IMDBID, err)
+					} else if len(searchResults) > 0 {
+						log.Printf("[metadata] custom list movie tvdb search (no year) found %d results title=%q imdbId=%q", len(searchResults), item.Title, item.IMDBID)
 					}
 				}
-				// Process results if we have any
-				if err == nil && len(searchResults) > 0 {
-					result := searchResults[0]
-					if result.TVDBID == "" {
-						log.Printf("[metadata] custom list movie tvdb search result has no tvdb_id title=%q year=%d imdbId=%q firstResultName=%q", item.Title, item.ReleaseYear, item.IMDBID, result.Name)
-					} else if tvdbID, err := strconv.ParseInt(result.TVDBID, 10, 64); err != nil {
-						log.Printf("[metadata] custom list movie tvdb search result has invalid tvdb_id title=%q year=%d tvdbId=%q err=%v", item.Title, item.ReleaseYear, result.TVDBID, err)
-					} else {
-						title.TVDBID = tvdbID
-						title.ID = fmt.Sprintf("tvdb:movie:%d", tvdbID)
-
-						// Use image from search result
-						if img := newTVDBImage(result.ImageURL, "poster", 0, 0); img != nil {
-							title.Poster = img
-						}
+			}
+			// Process results if we have any
+			if err == nil && len(searchResults) > 0 {
+				result := searchResults[0]
+				if result.TVDBID == "" {
+					log.Printf("[metadata] custom list movie tvdb search result has no tvdb_id title=%q year=%d imdbId=%q firstResultName=%q", item.Title, item.ReleaseYear, item.IMDBID, result.Name)
+				} else if tvdbID, err := strconv.ParseInt(result.TVDBID, 10, 64); err != nil {
+					log.Printf("[metadata] custom list movie tvdb search result has invalid tvdb_id title=%q year=%d tvdbId=%q err=%v", item.Title, item.ReleaseYear, result.TVDBID, err)
+				} else {
+					title.TVDBID = tvdbID
+					title.ID = fmt.Sprintf("tvdb:movie:%d", tvdbID)
 
-						// Get additional artwork
-						if ext, err := s.client.movieExtended(tvdbID, []string{"artwork"}); err == nil {
-							applyTVDBArtworks(&title, ext.Artworks)
-						}
+					// Use image from search result
+					if img := newTVDBImage(result.ImageURL, "poster", 0, 0); img != nil {
+						title.Poster = img
+					}
 
-						if result.Overview != "" {
-							title.Overview = result.Overview
-						}
-						found = true
+					// Get additional artwork
+					if ext, err := s.client.movieExtended(tvdbID, []string{"artwork"}); err == nil {
+						applyTVDBArtworks(&title, ext.Artworks, s.tvdbLanguage())
 					}
+
+					if result.Overview != "" {
+						title.Overview = result.Overview
+					}
+					found = true
 				}
-			} else {
-				// Try to search TVDB by title/year for series
-				searchResults, err := s.searchTVDBSeries(item.Title, item.ReleaseYear, remoteID)
-				if err != nil {
-					log.Printf("[metadata] custom list series tvdb search error title=%q year=%d imdbId=%q err=%v", item.Title, item.ReleaseYear, item.IMDBID, err)
-				} else if len(searchResults) == 0 {
-					log.Printf("[metadata] custom list series tvdb search returned 0 results title=%q year=%d imdbId=%q", item.Title, item.ReleaseYear, item.IMDBID)
-					// Fallback: retry without year constraint
-					if item.ReleaseYear > 0 {
-						log.Printf("[metadata] custom list series tvdb search retrying without year title=%q imdbId=%q", item.Title, item.IMDBID)
-						searchResults, err = s.searchTVDBSeries(item.Title, 0, remoteID)
-						if err != nil {
-							log.Printf("[metadata] custom list series tvdb search (no year) error title=%q imdbId=%q err=%v", item.Title, item.IMDBID, err)
-						} else if len(searchResults) > 0 {
-							log.Printf("[metadata] custom list series tvdb search (no year) found %d results title=%q imdbId=%q", len(searchResults), item.Title, item.IMDBID)
-						}
+			}
+		} else {
+			// Try to search TVDB by title/year for series
+			searchResults, err := s.searchTVDBSeries(item.Title, item.ReleaseYear, remoteID)
+			if err != nil {
+				log.Printf("[metadata] custom list series tvdb search error title=%q year=%d imdbId=%q err=%v", item.Title, item.ReleaseYear, item.IMDBID, err)
+			} else if len(searchResults) == 0 {
+				log.Printf("[metadata] custom list series tvdb search returned 0 results title=%q year=%d imdbId=%q", item.Title, item.ReleaseYear, item.IMDBID)
+				// Fallback: retry without year constraint
+				if item.ReleaseYear > 0 {
+					log.Printf("[metadata] custom list series tvdb search retrying without year title=%q imdbId=%q", item.Title, item.IMDBID)
+					searchResults, err = s.searchTVDBSeries(item.Title, 0, remoteID)
+					if err != nil {
+						log.Printf("[metadata] custom list series tvdb search (no year) error title=%q imdbId=%q err=%v", item.Title, item.IMDBID, err)
+					} else if len(searchResults) > 0 {
+						log.Printf("[metadata] custom list series tvdb search (no year) found %d results title=%q imdbId=%q", len(searchResults), item.Title, item.IMDBID)
 					}
 				}
-				// Process results if we have any
-				if err == nil && len(searchResults) > 0 {
-					result := searchResults[0]
-					if result.TVDBID == "" {
-						log.Printf("[metadata] custom list series tvdb search result has no tvdb_id title=%q year=%d imdbId=%q firstResultName=%q", item.Title, item.ReleaseYear, item.IMDBID, result.Name)
-					} else if tvdbID, err := strconv.ParseInt(result.TVDBID, 10, 64); err != nil {
-						log.Printf("[metadata] custom list series tvdb search result has invalid tvdb_id title=%q year=%d tvdbId=%q err=%v", item.Title, item.ReleaseYear, result.TVDBID, err)
-					} else {
-						title.TVDBID = tvdbID
-						title.ID = fmt.Sprintf("tvdb:series:%d", tvdbID)
-
-						// Use image from search result
-						if img := newTVDBImage(result.ImageURL, "poster", 0, 0); img != nil {
-							title.Poster = img
-						}
+			}
+			// Process results if we have any
+			if err == nil && len(searchResults) > 0 {
+				result := searchResults[0]
+				if result.TVDBID == "" {
+					log.Printf("[metadata] custom list series tvdb search result has no tvdb_id title=%q year=%d imdbId=%q firstResultName=%q", item.Title, item.ReleaseYear, item.IMDBID, result.Name)
+				} else if tvdbID, err := strconv.ParseInt(result.TVDBID, 10, 64); err != nil {
+					log.Printf("[metadata] custom list series tvdb search result has invalid tvdb_id title=%q year=%d tvdbId=%q err=%v", item.Title, item.ReleaseYear, result.TVDBID, err)
+				} else {
+					title.TVDBID = tvdbID
+					title.ID = fmt.Sprintf("tvdb:series:%d", tvdbID)
 
-						// Get additional artwork
-						if ext, err := s.client.seriesExtended(tvdbID, []string{"artworks"}); err == nil {
-							applyTVDBArtworks(&title, ext.Artworks)
-						}
+					// Use image from search result
+					if img := newTVDBImage(result.ImageURL, "poster", 0, 0); img != nil {
+						title.Poster = img
+					}
 
-						if result.Overview != "" {
-							title.Overview = result.Overview
-						}
-						found = true
+					// Get additional artwork
+					if ext, err := s.client.seriesExtended(tvdbID, []string{"artworks"}); err == nil {
+						applyTVDBArtworks(&title, ext.Artworks, s.tvdbLanguage())
 					}
+
+					if result.Overview != "" {
+						title.Overview = result.Overview
+					}
+					found = true
 				}
 			}
 		}
+	}
 
-		if !found {
-			log.Printf("[metadata] no tvdb match for custom list item title=%q year=%d type=%s imdbId=%q", item.Title, item.ReleaseYear, mediaType, item.IMDBID)
-		}
+	if !found {
+		log.Printf("[metadata] no tvdb match for custom list item title=%q year=%d type=%s imdbId=%q", item.Title, item.ReleaseYear, mediaType, item.IMDBID)
+	}
 
-		// Enrich movies with release data from TMDB (needed for hideUnreleased filter)
-		if mediaType == "movie" {
-			tmdbID := title.TMDBID
-			// Resolve IMDB to TMDB if we don't have TMDB ID
-			if tmdbID <= 0 && title.IMDBID != "" {
-				if resolved := s.getTMDBIDForIMDB(ctx, title.IMDBID); resolved > 0 {
-					tmdbID = resolved
-					title.TMDBID = resolved
-				}
+	// Enrich movies with release data from TMDB (needed for hideUnreleased filter)
+	if mediaType == "movie" {
+		tmdbID := title.TMDBID
+		// Resolve IMDB to TMDB if we don't have TMDB ID
+		if tmdbID <= 0 && title.IMDBID != "" {
+			if resolved := s.getTMDBIDForIMDB(ctx, title.IMDBID); resolved > 0 {
+				tmdbID = resolved
+				title.TMDBID = resolved
 			}
-			if tmdbID > 0 {
-				if s.enrichMovieReleases(ctx, &title, tmdbID) {
-					log.Printf("[metadata] custom list movie release data enriched title=%q tmdbId=%d hasHomeRelease=%v released=%v",
-						title.Name, tmdbID, title.HomeRelease != nil, title.HomeRelease != nil && title.HomeRelease.Released)
-				}
+		}
+		if tmdbID > 0 {
+			if s.enrichMovieReleases(ctx, &title, tmdbID) {
+				log.Printf("[metadata] custom list movie release data enriched title=%q tmdbId=%d hasHomeRelease=%v released=%v",
+					title.Name, tmdbID, title.HomeRelease != nil, title.HomeRelease != nil && title.HomeRelease.Released)
 			}
 		}
+	}
 
-		// For series, try to get status from TVDB extended info if we have a TVDB ID
-		if mediaType == "series" && title.TVDBID > 0 && title.Status == "" {
-			if ext, err := s.client.seriesExtended(title.TVDBID, nil); err == nil {
-				if ext.Status.Name != "" {
-					title.Status = ext.Status.Name
-				}
+	// For series, try to get status from TVDB extended info if we have a TVDB ID
+	if mediaType == "series" && title.TVDBID > 0 && title.Status == "" {
+		if ext, err := s.client.seriesExtended(title.TVDBID, nil); err == nil {
+			if ext.Status.Name != "" {
+				title.Status = ext.Status.Name
 			}
 		}
-
-		items = append(items, models.TrendingItem{
-			Rank:  item.Rank,
-			Title: title,
-		})
 	}
 
-	// Only cache if we enriched all items (no limit applied)
-	// This ensures the cache always has the full list
-	if len(items) > 0 && (limit == 0 || limit >= totalCount) {
-		_ = s.cache.set(cacheID, items)
-		log.Printf("[metadata] cached %d enriched items for custom list: %s", len(items), listURL)
+	return models.TrendingItem{
+		Rank:  item.Rank,
+		Title: title,
 	}
-
-	return items, totalCount, nil
 }
 
 // ExtractTrailerStreamURL uses yt-dlp to extract a direct stream URL from a YouTube video.
@@ -3876,7 +4051,7 @@ func (s *Service) ExtractTrailerStreamURL(ctx context.Context, videoURL string)
 		videoURL,
 	}
 
-	cmd := exec.CommandContext(ctx, ytdlpPath, args...)
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolYtDlp, ytdlpPath, args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
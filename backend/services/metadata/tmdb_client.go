@@ -9,7 +9,6 @@ import (
 	"math"
 	"net/http"
 	"net/url"
-	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,16 +19,7 @@ import (
 )
 
 const (
-	tmdbBaseURL      = "https://api.themoviedb.org/3"
-	tmdbImageBaseURL = "https://image.tmdb.org/t/p"
-	// Use optimized image sizes instead of "original" to reduce memory usage
-	// Posters: w500 = 500px wide (plenty for TV cards ~200-300px)
-	// Backdrops: w1280 = 1280px wide (good for 1080p backgrounds)
-	// Profiles: w185 = 185px wide (good for cast member photos)
-	tmdbPosterSize   = "w780"
-	tmdbBackdropSize = "w1280"
-	tmdbProfileSize  = "w185"
-	tmdbLogoSize     = "w500"
+	tmdbBaseURL = "https://api.themoviedb.org/3"
 )
 
 type tmdbClient struct {
@@ -254,10 +244,10 @@ func (c *tmdbClient) trending(ctx context.Context, mediaType string) ([]models.T
 		if year := parseTMDBYear(r.ReleaseDate, r.FirstAirDate); year != 0 {
 			title.Year = year
 		}
-		if poster := buildTMDBImage(r.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+		if poster := buildTMDBImage(r.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 			title.Poster = poster
 		}
-		if backdrop := buildTMDBImage(r.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+		if backdrop := buildTMDBImage(r.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 			title.Backdrop = backdrop
 		}
 
@@ -306,14 +296,13 @@ func parseTMDBYear(movieDate, seriesDate string) int {
 	return 0
 }
 
-func buildTMDBImage(imagePath, size, imageType string) *models.Image {
-	trimmed := strings.TrimSpace(imagePath)
-	if trimmed == "" {
+func buildTMDBImage(imagePath string, image tmdbImageContext, imageType string) *models.Image {
+	imageURL := tmdbImageURL(imagePath, image)
+	if imageURL == "" {
 		return nil
 	}
-	fullPath := path.Join(size, strings.TrimPrefix(trimmed, "/"))
 	return &models.Image{
-		URL:  fmt.Sprintf("%s/%s", tmdbImageBaseURL, fullPath),
+		URL:  imageURL,
 		Type: imageType,
 	}
 }
@@ -330,14 +319,18 @@ type tmdbImageItem struct {
 
 // tmdbImagesResponse represents the response from TMDB's /images endpoint
 type tmdbImagesResponse struct {
-	Logos   []tmdbImageItem `json:"logos"`
-	Posters []tmdbImageItem `json:"posters"`
+	Logos     []tmdbImageItem `json:"logos"`
+	Posters   []tmdbImageItem `json:"posters"`
+	Backdrops []tmdbImageItem `json:"backdrops"`
 }
 
-// tmdbImagesResult contains logo and textless poster from a single /images API call
+// tmdbImagesResult contains logo and textless poster from a single /images API call,
+// plus the best-rated poster and backdrop for use as artwork fallbacks
 type tmdbImagesResult struct {
 	Logo           *models.Image
 	TextlessPoster *models.Image
+	Poster         *models.Image
+	Backdrop       *models.Image
 }
 
 // fetchImages retrieves logo and textless poster for a movie or TV show from TMDB
@@ -386,7 +379,7 @@ func (c *tmdbClient) fetchImages(ctx context.Context, mediaType string, tmdbID i
 			// Finally sort by vote average
 			return li.VoteAverage > lj.VoteAverage
 		})
-		result.Logo = buildTMDBImage(payload.Logos[0].FilePath, tmdbLogoSize, "logo")
+		result.Logo = buildTMDBImage(payload.Logos[0].FilePath, tmdbLogoImage, "logo")
 	}
 
 	// Find best textless poster (no language = textless)
@@ -402,13 +395,36 @@ func (c *tmdbClient) fetchImages(ctx context.Context, mediaType string, tmdbID i
 			sort.Slice(textless, func(i, j int) bool {
 				return textless[i].VoteAverage > textless[j].VoteAverage
 			})
-			result.TextlessPoster = buildTMDBImage(textless[0].FilePath, tmdbPosterSize, "poster")
+			result.TextlessPoster = buildTMDBImage(textless[0].FilePath, tmdbPosterImage, "poster")
 		}
+		result.Poster = buildTMDBImage(bestTMDBImage(payload.Posters).FilePath, tmdbPosterImage, "poster")
+	}
+
+	if len(payload.Backdrops) > 0 {
+		result.Backdrop = buildTMDBImage(bestTMDBImage(payload.Backdrops).FilePath, tmdbBackdropImage, "backdrop")
 	}
 
 	return result, nil
 }
 
+// bestTMDBImage returns the highest voted image, preferring English ones.
+func bestTMDBImage(items []tmdbImageItem) tmdbImageItem {
+	best := items[0]
+	for _, item := range items[1:] {
+		itemEng, bestEng := item.ISO6391 == "en", best.ISO6391 == "en"
+		if itemEng != bestEng {
+			if itemEng {
+				best = item
+			}
+			continue
+		}
+		if item.VoteAverage > best.VoteAverage {
+			best = item
+		}
+	}
+	return best
+}
+
 // fetchSeriesGenres retrieves genres for a TV series from TMDB
 func (c *tmdbClient) fetchSeriesGenres(ctx context.Context, tmdbID int64) ([]string, error) {
 	if !c.isConfigured() {
@@ -814,10 +830,10 @@ func (c *tmdbClient) movieDetails(ctx context.Context, tmdbID int64) (*models.Ti
 	if year := parseTMDBYear(movie.ReleaseDate, ""); year != 0 {
 		title.Year = year
 	}
-	if poster := buildTMDBImage(movie.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+	if poster := buildTMDBImage(movie.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 		title.Poster = poster
 	}
-	if backdrop := buildTMDBImage(movie.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+	if backdrop := buildTMDBImage(movie.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 		title.Backdrop = backdrop
 	}
 	if movie.BelongsToCollection != nil {
@@ -825,10 +841,10 @@ func (c *tmdbClient) movieDetails(ctx context.Context, tmdbID int64) (*models.Ti
 			ID:   movie.BelongsToCollection.ID,
 			Name: movie.BelongsToCollection.Name,
 		}
-		if poster := buildTMDBImage(movie.BelongsToCollection.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+		if poster := buildTMDBImage(movie.BelongsToCollection.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 			title.Collection.Poster = poster
 		}
-		if backdrop := buildTMDBImage(movie.BelongsToCollection.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+		if backdrop := buildTMDBImage(movie.BelongsToCollection.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 			title.Collection.Backdrop = backdrop
 		}
 	}
@@ -904,10 +920,10 @@ func (c *tmdbClient) fetchCollectionDetails(ctx context.Context, collectionID in
 		Name:     collection.Name,
 		Overview: collection.Overview,
 	}
-	if poster := buildTMDBImage(collection.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+	if poster := buildTMDBImage(collection.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 		details.Poster = poster
 	}
-	if backdrop := buildTMDBImage(collection.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+	if backdrop := buildTMDBImage(collection.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 		details.Backdrop = backdrop
 	}
 
@@ -924,10 +940,10 @@ func (c *tmdbClient) fetchCollectionDetails(ctx context.Context, collectionID in
 		if year := parseTMDBYear(part.ReleaseDate, ""); year != 0 {
 			title.Year = year
 		}
-		if poster := buildTMDBImage(part.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+		if poster := buildTMDBImage(part.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 			title.Poster = poster
 		}
-		if backdrop := buildTMDBImage(part.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+		if backdrop := buildTMDBImage(part.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 			title.Backdrop = backdrop
 		}
 		title.Popularity = part.Popularity
@@ -995,7 +1011,7 @@ func (c *tmdbClient) fetchMovieCredits(ctx context.Context, tmdbID int64) (*mode
 		}
 		if cm.ProfilePath != "" {
 			member.ProfilePath = cm.ProfilePath
-			member.ProfileURL = fmt.Sprintf("%s/%s%s", tmdbImageBaseURL, tmdbProfileSize, cm.ProfilePath)
+			member.ProfileURL = tmdbImageURL(cm.ProfilePath, tmdbProfileImage)
 		}
 		cast = append(cast, member)
 	}
@@ -1040,7 +1056,7 @@ func (c *tmdbClient) fetchTVCredits(ctx context.Context, tmdbID int64) (*models.
 		}
 		if cm.ProfilePath != "" {
 			member.ProfilePath = cm.ProfilePath
-			member.ProfileURL = fmt.Sprintf("%s/%s%s", tmdbImageBaseURL, tmdbProfileSize, cm.ProfilePath)
+			member.ProfileURL = tmdbImageURL(cm.ProfilePath, tmdbProfileImage)
 		}
 		cast = append(cast, member)
 	}
@@ -1154,6 +1170,70 @@ func (c *tmdbClient) movieReleaseDates(ctx context.Context, tmdbID int64) ([]mod
 	return releases, nil
 }
 
+// fetchCertification returns the US movie certification or TV content rating
+// of a title, or "" when TMDB has none.
+func (c *tmdbClient) fetchCertification(ctx context.Context, mediaType string, tmdbID int64) (string, error) {
+	if !c.isConfigured() {
+		return "", errors.New("tmdb api key not configured")
+	}
+
+	if mediaType != "movie" {
+		endpoint, err := url.JoinPath(tmdbBaseURL, "tv", fmt.Sprintf("%d", tmdbID), "content_ratings")
+		if err != nil {
+			return "", err
+		}
+		endpoint = endpoint + "?api_key=" + c.apiKey
+
+		var payload struct {
+			Results []struct {
+				ISO31661 string `json:"iso_3166_1"`
+				Rating   string `json:"rating"`
+			} `json:"results"`
+		}
+		if err := c.doGET(ctx, endpoint, &payload); err != nil {
+			return "", fmt.Errorf("tmdb tv/%d content ratings failed: %w", tmdbID, err)
+		}
+		for _, r := range payload.Results {
+			if r.ISO31661 == "US" {
+				return strings.TrimSpace(r.Rating), nil
+			}
+		}
+		return "", nil
+	}
+
+	endpoint, err := url.JoinPath(tmdbBaseURL, "movie", fmt.Sprintf("%d", tmdbID), "release_dates")
+	if err != nil {
+		return "", err
+	}
+	endpoint = endpoint + "?api_key=" + c.apiKey
+
+	var payload tmdbReleaseDatesResponse
+	if err := c.doGET(ctx, endpoint, &payload); err != nil {
+		return "", fmt.Errorf("tmdb movie/%d release dates failed: %w", tmdbID, err)
+	}
+	for _, country := range payload.Results {
+		if country.ISO31661 != "US" {
+			continue
+		}
+		// Prefer the theatrical certification, falling back to any release
+		var fallback string
+		for _, entry := range country.ReleaseDates {
+			cert := strings.TrimSpace(entry.Certification)
+			if cert == "" {
+				continue
+			}
+			if entry.Type == 3 {
+				return cert, nil
+			}
+			if fallback == "" {
+				fallback = cert
+			}
+		}
+		return fallback, nil
+	}
+	return "", nil
+}
+
 func (c *tmdbClient) fetchExternalID(ctx context.Context, mediaType string, tmdbID int64) (string, error) {
 	if !c.isConfigured() {
 		return "", errors.New("tmdb api key not configured")
@@ -1377,7 +1457,7 @@ func (c *tmdbClient) fetchPersonDetails(ctx context.Context, personID int64) (*m
 		KnownFor:     strings.TrimSpace(payload.KnownForDepartment),
 	}
 	if payload.ProfilePath != "" {
-		person.ProfileURL = fmt.Sprintf("%s/%s%s", tmdbImageBaseURL, tmdbPosterSize, payload.ProfilePath)
+		person.ProfileURL = tmdbImageURL(payload.ProfilePath, tmdbPortraitImage)
 	}
 
 	return person, nil
@@ -1594,10 +1674,10 @@ func (c *tmdbClient) fetchPersonCombinedCredits(ctx context.Context, personID in
 		if year := parseTMDBYear(credit.ReleaseDate, credit.FirstAirDate); year != 0 {
 			title.Year = year
 		}
-		if poster := buildTMDBImage(credit.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+		if poster := buildTMDBImage(credit.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 			title.Poster = poster
 		}
-		if backdrop := buildTMDBImage(credit.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+		if backdrop := buildTMDBImage(credit.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 			title.Backdrop = backdrop
 		}
 
@@ -1686,10 +1766,10 @@ func (c *tmdbClient) fetchSimilar(ctx context.Context, mediaType string, tmdbID
 		if year := parseTMDBYear(r.ReleaseDate, r.FirstAirDate); year != 0 {
 			title.Year = year
 		}
-		if poster := buildTMDBImage(r.PosterPath, tmdbPosterSize, "poster"); poster != nil {
+		if poster := buildTMDBImage(r.PosterPath, tmdbPosterImage, "poster"); poster != nil {
 			title.Poster = poster
 		}
-		if backdrop := buildTMDBImage(r.BackdropPath, tmdbBackdropSize, "backdrop"); backdrop != nil {
+		if backdrop := buildTMDBImage(r.BackdropPath, tmdbBackdropImage, "backdrop"); backdrop != nil {
 			title.Backdrop = backdrop
 		}
 		title.Popularity = scoreFallback(r.Popularity, r.VoteAverage)
@@ -1699,3 +1779,188 @@ func (c *tmdbClient) fetchSimilar(ctx context.Context, mediaType string, tmdbID
 
 	return titles, nil
 }
+
+// tmdbSeries is the subset of TMDB's /tv/{id} response used when series
+// metadata is served from TMDB alone.
+type tmdbSeries struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name"`
+	Overview     string `json:"overview"`
+	FirstAirDate string `json:"first_air_date"`
+	Status       string `json:"status"`
+	Type         string `json:"type"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+	Networks     []struct {
+		Name string `json:"name"`
+	} `json:"networks"`
+	Genres []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Seasons []struct {
+		ID           int64  `json:"id"`
+		Name         string `json:"name"`
+		Overview     string `json:"overview"`
+		SeasonNumber int    `json:"season_number"`
+		EpisodeCount int    `json:"episode_count"`
+		PosterPath   string `json:"poster_path"`
+	} `json:"seasons"`
+	ExternalIDs struct {
+		IMDBID string `json:"imdb_id"`
+		TVDBID int64  `json:"tvdb_id"`
+	} `json:"external_ids"`
+}
+
+// tmdbSeason is TMDB's /tv/{id}/season/{n} response.
+type tmdbSeason struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Overview     string `json:"overview"`
+	SeasonNumber int    `json:"season_number"`
+	PosterPath   string `json:"poster_path"`
+	Episodes     []struct {
+		ID            int64  `json:"id"`
+		Name          string `json:"name"`
+		Overview      string `json:"overview"`
+		SeasonNumber  int    `json:"season_number"`
+		EpisodeNumber int    `json:"episode_number"`
+		AirDate       string `json:"air_date"`
+		Runtime       int    `json:"runtime"`
+		StillPath     string `json:"still_path"`
+	} `json:"episodes"`
+}
+
+// localizedEndpoint joins parts onto the TMDB base URL and adds the API key,
+// the configured language and any extra query parameters.
+func (c *tmdbClient) localizedEndpoint(params url.Values, parts ...string) (string, error) {
+	endpoint, err := url.JoinPath(tmdbBaseURL, parts...)
+	if err != nil {
+		return "", err
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("api_key", c.apiKey)
+	params.Set("language", normalizeLanguage(c.language))
+	return endpoint + "?" + params.Encode(), nil
+}
+
+// seriesDetails retrieves a TV series with its season list and external IDs
+func (c *tmdbClient) seriesDetails(ctx context.Context, tmdbID int64) (*tmdbSeries, error) {
+	if !c.isConfigured() {
+		return nil, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := c.localizedEndpoint(url.Values{"append_to_response": []string{"external_ids"}}, "tv", strconv.FormatInt(tmdbID, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	var series tmdbSeries
+	if err := c.doGET(ctx, endpoint, &series); err != nil {
+		return nil, fmt.Errorf("tmdb tv/%d failed: %w", tmdbID, err)
+	}
+	if series.ID == 0 {
+		return nil, fmt.Errorf("tmdb tv/%d returned no series", tmdbID)
+	}
+	return &series, nil
+}
+
+// seasonDetails retrieves one season of a TV series including its episodes
+func (c *tmdbClient) seasonDetails(ctx context.Context, tmdbID int64, seasonNumber int) (*tmdbSeason, error) {
+	if !c.isConfigured() {
+		return nil, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := c.localizedEndpoint(nil, "tv", strconv.FormatInt(tmdbID, 10), "season", strconv.Itoa(seasonNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	var season tmdbSeason
+	if err := c.doGET(ctx, endpoint, &season); err != nil {
+		return nil, fmt.Errorf("tmdb tv/%d/season/%d failed: %w", tmdbID, seasonNumber, err)
+	}
+	return &season, nil
+}
+
+// search searches TMDB for movies or TV shows, optionally narrowed to a year
+func (c *tmdbClient) search(ctx context.Context, mediaType, query string, year int) ([]models.Title, error) {
+	if !c.isConfigured() {
+		return nil, errors.New("tmdb api key not configured")
+	}
+
+	apiMediaType := "tv"
+	yearParam := "first_air_date_year"
+	if mediaType == "movie" {
+		apiMediaType = "movie"
+		yearParam = "year"
+	}
+	params := url.Values{"query": []string{query}}
+	if year > 0 {
+		params.Set(yearParam, strconv.Itoa(year))
+	}
+	endpoint, err := c.localizedEndpoint(params, "search", apiMediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload tmdbSimilarResponse
+	if err := c.doGET(ctx, endpoint, &payload); err != nil {
+		return nil, fmt.Errorf("tmdb search %s failed: %w", apiMediaType, err)
+	}
+
+	titles := make([]models.Title, 0, len(payload.Results))
+	for _, r := range payload.Results {
+		title := models.Title{
+			ID:         fmt.Sprintf("tmdb:%s:%d", apiMediaType, r.ID),
+			Name:       pickTMDBName(apiMediaType, r.Name, r.Title),
+			Overview:   r.Overview,
+			Language:   r.OriginalLanguage,
+			MediaType:  mapMediaType(apiMediaType),
+			TMDBID:     r.ID,
+			Year:       parseTMDBYear(r.ReleaseDate, r.FirstAirDate),
+			Popularity: scoreFallback(r.Popularity, r.VoteAverage),
+		}
+		if title.Name == "" {
+			continue
+		}
+		title.Poster = buildTMDBImage(r.PosterPath, tmdbPosterImage, "poster")
+		title.Backdrop = buildTMDBImage(r.BackdropPath, tmdbBackdropImage, "backdrop")
+		titles = append(titles, title)
+	}
+	return titles, nil
+}
+
+// findByTVDBID looks up the TMDB ID of a movie or TV show from its TVDB ID
+func (c *tmdbClient) findByTVDBID(ctx context.Context, mediaType string, tvdbID int64) (int64, error) {
+	if !c.isConfigured() {
+		return 0, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := c.localizedEndpoint(url.Values{"external_source": []string{"tvdb_id"}}, "find", strconv.FormatInt(tvdbID, 10))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		MovieResults []struct {
+			ID int64 `json:"id"`
+		} `json:"movie_results"`
+		TVResults []struct {
+			ID int64 `json:"id"`
+		} `json:"tv_results"`
+	}
+	if err := c.doGET(ctx, endpoint, &result); err != nil {
+		return 0, fmt.Errorf("tmdb find tvdb %d failed: %w", tvdbID, err)
+	}
+
+	if mediaType == "movie" && len(result.MovieResults) > 0 {
+		return result.MovieResults[0].ID, nil
+	}
+	if mediaType != "movie" && len(result.TVResults) > 0 {
+		return result.TVResults[0].ID, nil
+	}
+	return 0, fmt.Errorf("no %s found for TVDB ID %d", mapMediaType(mediaType), tvdbID)
+}
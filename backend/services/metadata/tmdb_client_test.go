@@ -19,14 +19,14 @@ func TestNormalizeLanguage(t *testing.T) {
 }
 
 func TestBuildTMDBImage(t *testing.T) {
-	if img := buildTMDBImage("", tmdbPosterSize, "poster"); img != nil {
+	if img := buildTMDBImage("", tmdbPosterImage, "poster"); img != nil {
 		t.Fatal("expected nil image when path empty")
 	}
-	img := buildTMDBImage("/poster.png", tmdbPosterSize, "poster")
+	img := buildTMDBImage("/poster.png", tmdbPosterImage, "poster")
 	if img == nil {
 		t.Fatal("expected image for valid path")
 	}
-	if img.URL != "https://image.tmdb.org/t/p/w500/poster.png" {
+	if img.URL != "https://image.tmdb.org/t/p/w780/poster.png" {
 		t.Fatalf("unexpected image url: %s", img.URL)
 	}
 	if img.Type != "poster" {
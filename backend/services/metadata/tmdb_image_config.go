@@ -0,0 +1,312 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TMDB suggests checking its configuration every few days
+	tmdbConfigRefreshInterval = 72 * time.Hour
+	// Retry sooner when the fetch fails or no API key is set yet
+	tmdbConfigRetryInterval = time.Hour
+	tmdbConfigFile          = "tmdb_configuration.json"
+)
+
+// tmdbImageConfig is the images part of TMDB's /configuration response.
+type tmdbImageConfig struct {
+	SecureBaseURL string   `json:"secure_base_url"`
+	PosterSizes   []string `json:"poster_sizes"`
+	BackdropSizes []string `json:"backdrop_sizes"`
+	LogoSizes     []string `json:"logo_sizes"`
+	ProfileSizes  []string `json:"profile_sizes"`
+	StillSizes    []string `json:"still_sizes"`
+}
+
+// defaultTMDBImageConfig is used until the first fetch succeeds. It matches
+// what TMDB has served for years.
+var defaultTMDBImageConfig = tmdbImageConfig{
+	SecureBaseURL: "https://image.tmdb.org/t/p/",
+	PosterSizes:   []string{"w92", "w154", "w185", "w342", "w500", "w780", "original"},
+	BackdropSizes: []string{"w300", "w780", "w1280", "original"},
+	LogoSizes:     []string{"w45", "w92", "w154", "w185", "w300", "w500", "original"},
+	ProfileSizes:  []string{"w45", "w185", "h632", "original"},
+	StillSizes:    []string{"w92", "w185", "w300", "original"},
+}
+
+// tmdbImages holds the image configuration artwork URLs are built from.
+var tmdbImages = struct {
+	sync.RWMutex
+	config tmdbImageConfig
+}{config: defaultTMDBImageConfig}
+
+// tmdbImageContext is where an image is shown: the kind of TMDB image and
+// the smallest width that looks sharp there.
+type tmdbImageContext struct {
+	kind  string
+	width int
+}
+
+var (
+	tmdbPosterImage   = tmdbImageContext{kind: "poster", width: 780}    // Detail pages on 4K TVs
+	tmdbBackdropImage = tmdbImageContext{kind: "backdrop", width: 1280} // Full-screen 1080p backgrounds
+	tmdbLogoImage     = tmdbImageContext{kind: "logo", width: 500}
+	tmdbStillImage    = tmdbImageContext{kind: "still", width: 780}   // Episode thumbnails and the selected episode
+	tmdbProfileImage  = tmdbImageContext{kind: "profile", width: 185} // Cast rows
+	tmdbPortraitImage = tmdbImageContext{kind: "profile", width: 500} // Person pages
+)
+
+// tmdbImageURL builds the URL of a TMDB image for the context, or "" when
+// there is no image.
+func tmdbImageURL(imagePath string, image tmdbImageContext) string {
+	trimmed := strings.TrimSpace(imagePath)
+	if trimmed == "" {
+		return ""
+	}
+
+	tmdbImages.RLock()
+	config := tmdbImages.config
+	tmdbImages.RUnlock()
+
+	return strings.TrimSuffix(config.SecureBaseURL, "/") + "/" + path.Join(config.size(image), strings.TrimPrefix(trimmed, "/"))
+}
+
+// size picks the smallest size at least as wide as the context needs. Sizes
+// listed for the image's kind come first; if none is wide enough, a size
+// listed for another kind is used, as TMDB serves any listed size for any
+// image. "original" is the last resort since it can be several megabytes.
+func (c tmdbImageConfig) size(image tmdbImageContext) string {
+	if size, ok := smallestWidth(c.sizes(image.kind), image.width); ok {
+		return size
+	}
+	var all []string
+	for _, sizes := range [][]string{c.PosterSizes, c.BackdropSizes, c.LogoSizes, c.ProfileSizes, c.StillSizes} {
+		all = append(all, sizes...)
+	}
+	if size, ok := smallestWidth(all, image.width); ok {
+		return size
+	}
+	// Nothing is wide enough; take the widest fixed size
+	widest, widestWidth := "original", 0
+	for _, size := range all {
+		if width, ok := sizeWidth(size); ok && width > widestWidth {
+			widest, widestWidth = size, width
+		}
+	}
+	return widest
+}
+
+func (c tmdbImageConfig) sizes(kind string) []string {
+	switch kind {
+	case "poster":
+		return c.PosterSizes
+	case "backdrop":
+		return c.BackdropSizes
+	case "logo":
+		return c.LogoSizes
+	case "profile":
+		return c.ProfileSizes
+	case "still":
+		return c.StillSizes
+	}
+	return nil
+}
+
+func smallestWidth(sizes []string, minWidth int) (string, bool) {
+	best, bestWidth := "", 0
+	for _, size := range sizes {
+		width, ok := sizeWidth(size)
+		if ok && width >= minWidth && (best == "" || width < bestWidth) {
+			best, bestWidth = size, width
+		}
+	}
+	return best, best != ""
+}
+
+// sizeWidth parses width sizes such as "w780". Height sizes such as "h632"
+// and "original" don't say how wide the image is.
+func sizeWidth(size string) (int, bool) {
+	if !strings.HasPrefix(size, "w") {
+		return 0, false
+	}
+	width, err := strconv.Atoi(size[1:])
+	return width, err == nil && width > 0
+}
+
+// valid reports whether the configuration can build URLs: an https base URL
+// and at least one size of every kind.
+func (c tmdbImageConfig) valid() bool {
+	base, err := url.Parse(c.SecureBaseURL)
+	if err != nil || base.Scheme != "https" || base.Host == "" {
+		return false
+	}
+	for _, sizes := range [][]string{c.PosterSizes, c.BackdropSizes, c.LogoSizes, c.ProfileSizes, c.StillSizes} {
+		if len(sizes) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c tmdbImageConfig) equal(other tmdbImageConfig) bool {
+	return c.SecureBaseURL == other.SecureBaseURL &&
+		slices.Equal(c.PosterSizes, other.PosterSizes) &&
+		slices.Equal(c.BackdropSizes, other.BackdropSizes) &&
+		slices.Equal(c.LogoSizes, other.LogoSizes) &&
+		slices.Equal(c.ProfileSizes, other.ProfileSizes) &&
+		slices.Equal(c.StillSizes, other.StillSizes)
+}
+
+// setTMDBImageConfig switches artwork URLs to the configuration, reporting
+// whether it changed anything. Invalid configurations are ignored.
+func setTMDBImageConfig(config tmdbImageConfig) bool {
+	if !config.valid() {
+		return false
+	}
+	tmdbImages.Lock()
+	defer tmdbImages.Unlock()
+	if tmdbImages.config.equal(config) {
+		return false
+	}
+	tmdbImages.config = config
+	return true
+}
+
+// fetchImageConfig fetches the image configuration from TMDB.
+func (c *tmdbClient) fetchImageConfig(ctx context.Context) (tmdbImageConfig, error) {
+	var payload struct {
+		Images tmdbImageConfig `json:"images"`
+	}
+	if err := c.doGET(ctx, tmdbBaseURL+"/configuration?api_key="+url.QueryEscape(c.apiKey), &payload); err != nil {
+		return tmdbImageConfig{}, fmt.Errorf("tmdb configuration failed: %w", err)
+	}
+	if !payload.Images.valid() {
+		return tmdbImageConfig{}, fmt.Errorf("tmdb configuration has no usable image settings")
+	}
+	return payload.Images, nil
+}
+
+// StartTMDBConfigRefresh loads the last fetched TMDB image configuration and
+// keeps it current in the background, so artwork URLs follow TMDB's base URL
+// and sizes instead of assuming them. Until a configuration has been fetched
+// the long-standing defaults are used.
+func (s *Service) StartTMDBConfigRefresh(ctx context.Context) {
+	s.tmdbConfigMu.Lock()
+	defer s.tmdbConfigMu.Unlock()
+
+	if s.tmdbConfigCancel != nil {
+		return
+	}
+
+	s.loadTMDBImageConfig()
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.tmdbConfigCancel = cancel
+
+	s.tmdbConfigWG.Add(1)
+	go s.tmdbConfigLoop(loopCtx)
+}
+
+// StopTMDBConfigRefresh ends the background refresh.
+func (s *Service) StopTMDBConfigRefresh() {
+	s.tmdbConfigMu.Lock()
+	cancel := s.tmdbConfigCancel
+	s.tmdbConfigCancel = nil
+	s.tmdbConfigMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.tmdbConfigWG.Wait()
+}
+
+// refreshTMDBConfigSoon asks the refresh loop to fetch now, e.g. after the
+// TMDB API key changes.
+func (s *Service) refreshTMDBConfigSoon() {
+	select {
+	case s.tmdbConfigNudge <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) tmdbConfigLoop(ctx context.Context) {
+	defer s.tmdbConfigWG.Done()
+
+	for {
+		wait := tmdbConfigRefreshInterval
+		if !s.tmdb.isConfigured() {
+			wait = tmdbConfigRetryInterval
+		} else if err := s.refreshTMDBImageConfig(ctx); err != nil {
+			log.Printf("[tmdb] image configuration refresh failed, keeping current settings: %v", err)
+			wait = tmdbConfigRetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.tmdbConfigNudge:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Service) refreshTMDBImageConfig(ctx context.Context) error {
+	config, err := s.tmdb.fetchImageConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if setTMDBImageConfig(config) {
+		log.Printf("[tmdb] image configuration updated: base %s", config.SecureBaseURL)
+	}
+	return s.saveTMDBImageConfig(config)
+}
+
+// loadTMDBImageConfig applies the configuration saved by the last
+// successful fetch, so a restart while TMDB is unreachable keeps it.
+func (s *Service) loadTMDBImageConfig() {
+	if s.tmdbConfigPath == "" {
+		return
+	}
+	data, err := os.ReadFile(s.tmdbConfigPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[tmdb] read saved image configuration: %v", err)
+		}
+		return
+	}
+	var config tmdbImageConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Printf("[tmdb] decode saved image configuration: %v", err)
+		return
+	}
+	setTMDBImageConfig(config)
+}
+
+func (s *Service) saveTMDBImageConfig(config tmdbImageConfig) error {
+	if s.tmdbConfigPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.tmdbConfigPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.tmdbConfigPath)
+}
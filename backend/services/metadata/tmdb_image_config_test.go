@@ -0,0 +1,79 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestTMDBImageSizeSelection(t *testing.T) {
+	config := defaultTMDBImageConfig
+	tests := []struct {
+		image tmdbImageContext
+		want  string
+	}{
+		{tmdbPosterImage, "w780"},
+		{tmdbBackdropImage, "w1280"},
+		{tmdbLogoImage, "w500"},
+		{tmdbProfileImage, "w185"},
+		{tmdbStillImage, "w780"},    // Still sizes stop at w300, so a backdrop size is used
+		{tmdbPortraitImage, "w500"}, // h632 doesn't count as a width
+		{tmdbImageContext{kind: "poster", width: 4000}, "w1280"},
+	}
+	for _, tc := range tests {
+		if got := config.size(tc.image); got != tc.want {
+			t.Errorf("size(%+v) = %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
+
+func TestTMDBImageConfigRefresh(t *testing.T) {
+	defer setTMDBImageConfig(defaultTMDBImageConfig)
+
+	fetched := defaultTMDBImageConfig
+	fetched.SecureBaseURL = "https://images.example.org/t/p/"
+	fetched.PosterSizes = []string{"w200", "w800", "original"}
+	httpc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/3/configuration" {
+				t.Fatalf("unexpected request %s", req.URL)
+			}
+			body, _ := json.Marshal(map[string]any{"images": fetched})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: make(http.Header)}, nil
+		}),
+	}
+
+	svc := &Service{
+		tmdb:           newTMDBClient("key", "en-US", httpc, nil),
+		tmdbConfigPath: filepath.Join(t.TempDir(), tmdbConfigFile),
+	}
+	if err := svc.refreshTMDBImageConfig(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if got := tmdbImageURL("/poster.jpg", tmdbPosterImage); got != "https://images.example.org/t/p/w800/poster.jpg" {
+		t.Fatalf("expected URLs to follow the fetched configuration, got %s", got)
+	}
+
+	// A restart picks up the saved configuration before TMDB is reachable
+	setTMDBImageConfig(defaultTMDBImageConfig)
+	svc.loadTMDBImageConfig()
+	if got := tmdbImageURL("/poster.jpg", tmdbPosterImage); got != "https://images.example.org/t/p/w800/poster.jpg" {
+		t.Fatalf("expected the saved configuration to be loaded, got %s", got)
+	}
+
+	// An unusable configuration is ignored
+	broken := fetched
+	broken.SecureBaseURL = "http://insecure.example.org/"
+	if setTMDBImageConfig(broken) {
+		t.Fatal("expected a non-https base URL to be rejected")
+	}
+	broken = fetched
+	broken.BackdropSizes = nil
+	if setTMDBImageConfig(broken) {
+		t.Fatal("expected a configuration without backdrop sizes to be rejected")
+	}
+}
@@ -0,0 +1,327 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"novastream/models"
+)
+
+// maxConcurrentTMDBSeasons bounds parallel season fetches for one series.
+const maxConcurrentTMDBSeasons = 4
+
+// tmdbOnly reports whether metadata is served from TMDB alone, which is the
+// case when no TVDB API key is configured but a TMDB key is.
+func (s *Service) tmdbOnly() bool {
+	return !s.client.isConfigured() && s.tmdb.isConfigured()
+}
+
+// resolveTMDBID finds the TMDB ID of a title from a match override, explicit
+// IDs, a tmdb: or tvdb: title ID, its IMDB ID (movies) or a TMDB name search.
+func (s *Service) resolveTMDBID(ctx context.Context, mediaType, titleID, name string, year int, tmdbID, tvdbID int64, imdbID string) (int64, error) {
+	if override, ok := s.matchOverride(mediaType, titleID, name, year); ok {
+		titleID, tmdbID, tvdbID, imdbID = "", override.TMDBID, override.TVDBID, override.IMDBID
+	}
+
+	if tmdbID > 0 {
+		return tmdbID, nil
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(titleID)), "tmdb:") {
+		if id := parseTMDBIDFromTitleID(titleID); id > 0 {
+			return id, nil
+		}
+	}
+
+	if tvdbID <= 0 {
+		tvdbID = parseTVDBIDFromTitleID(titleID)
+	}
+	if tvdbID > 0 {
+		cacheID := cacheKey("id", "tvdb-to-tmdb", mediaType, strconv.FormatInt(tvdbID, 10))
+		var cached int64
+		if ok, _ := s.idCache.get(cacheID, &cached); ok && cached > 0 {
+			return cached, nil
+		}
+		if id, err := s.tmdb.findByTVDBID(ctx, mediaType, tvdbID); err == nil && id > 0 {
+			_ = s.idCache.set(cacheID, id)
+			return id, nil
+		} else if err != nil {
+			log.Printf("[metadata] tmdb lookup by tvdb id failed type=%s tvdbId=%d err=%v", mediaType, tvdbID, err)
+		}
+	}
+
+	if mediaType == "movie" && strings.TrimSpace(imdbID) != "" {
+		if id := s.getTMDBIDForIMDB(ctx, strings.TrimSpace(imdbID)); id > 0 {
+			return id, nil
+		}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("%s name required to resolve tmdb id", mediaType)
+	}
+
+	cacheID := cacheKey("tmdb", "resolve", mediaType, name, strconv.Itoa(year))
+	var cached int64
+	if ok, _ := s.cache.get(cacheID, &cached); ok && cached > 0 {
+		return cached, nil
+	}
+
+	results, err := s.tmdb.search(ctx, mediaType, name, year)
+	if err == nil && len(results) == 0 && year > 0 {
+		log.Printf("[metadata] tmdb search retrying without year type=%s name=%q", mediaType, name)
+		results, err = s.tmdb.search(ctx, mediaType, name, 0)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, fmt.Errorf("no tmdb match found for %q", name)
+	}
+
+	_ = s.cache.set(cacheID, results[0].TMDBID)
+	log.Printf("[metadata] tmdb search resolved type=%s name=%q year=%d → tmdbId=%d", mediaType, name, year, results[0].TMDBID)
+	return results[0].TMDBID, nil
+}
+
+// seriesDetailsFromTMDB builds series details, including every season's
+// episode list, from TMDB alone.
+func (s *Service) seriesDetailsFromTMDB(ctx context.Context, req models.SeriesDetailsQuery) (*models.SeriesDetails, error) {
+	tmdbID, err := s.resolveTMDBID(ctx, "series", req.TitleID, req.Name, req.Year, req.TMDBID, req.TVDBID, "")
+	if err != nil {
+		log.Printf("[metadata] series details (TMDB) resolve error titleId=%q name=%q year=%d err=%v",
+			strings.TrimSpace(req.TitleID), strings.TrimSpace(req.Name), req.Year, err)
+		return nil, err
+	}
+
+	cacheID := cacheKey("tmdb", "series", "details", "v1", s.tmdb.language, strconv.FormatInt(tmdbID, 10))
+	var cached models.SeriesDetails
+	if ok, _ := s.cache.get(cacheID, &cached); ok && cached.Title.ID != "" {
+		log.Printf("[metadata] series details cache hit (TMDB) tmdbId=%d seasons=%d", tmdbID, len(cached.Seasons))
+		return &cached, nil
+	}
+
+	log.Printf("[metadata] series details fetch (TMDB) tmdbId=%d", tmdbID)
+
+	series, err := s.tmdb.seriesDetails(ctx, tmdbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch series details: %w", err)
+	}
+	seriesTitle := s.tmdbSeriesTitle(ctx, series, req.Name)
+
+	seasons := make([]models.SeriesSeason, 0, len(series.Seasons))
+	for _, season := range series.Seasons {
+		if season.SeasonNumber < 0 {
+			continue
+		}
+		seasons = append(seasons, models.SeriesSeason{
+			ID:       fmt.Sprintf("tmdb:season:%d", season.ID),
+			Name:     strings.TrimSpace(firstNonEmpty(season.Name, fmt.Sprintf("Season %d", season.SeasonNumber))),
+			Number:   season.SeasonNumber,
+			Overview: strings.TrimSpace(season.Overview),
+			Image:    buildTMDBImage(season.PosterPath, tmdbPosterImage, "poster"),
+			Episodes: make([]models.SeriesEpisode, 0),
+		})
+	}
+
+	sem := make(chan struct{}, maxConcurrentTMDBSeasons)
+	var wg sync.WaitGroup
+	var failedMu sync.Mutex
+	var failed error
+	for i := range seasons {
+		wg.Add(1)
+		go func(season *models.SeriesSeason) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			detail, err := s.tmdb.seasonDetails(ctx, tmdbID, season.Number)
+			if err != nil {
+				failedMu.Lock()
+				failed = err
+				failedMu.Unlock()
+				return
+			}
+			season.Episodes = tmdbSeasonEpisodes(detail)
+		}(&seasons[i])
+	}
+	wg.Wait()
+	if failed != nil {
+		// Partial episode lists would be cached as complete, so fail the whole request
+		log.Printf("[metadata] series details (TMDB) season fetch failed tmdbId=%d err=%v", tmdbID, failed)
+		return nil, fmt.Errorf("failed to fetch series seasons: %w", failed)
+	}
+
+	for i := range seasons {
+		seasons[i].EpisodeCount = len(seasons[i].Episodes)
+	}
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].Number < seasons[j].Number })
+
+	if seriesTitle.IMDBID != "" && s.mdblist != nil && s.mdblist.IsEnabled() {
+		if ratings, err := s.mdblist.GetRatings(ctx, seriesTitle.IMDBID, "show"); err == nil && len(ratings) > 0 {
+			s.setRatings(&seriesTitle, ratings)
+		}
+	}
+	if credits, err := s.tmdb.fetchCredits(ctx, "series", tmdbID); err == nil && credits != nil && len(credits.Cast) > 0 {
+		seriesTitle.Credits = credits
+	} else if err != nil {
+		log.Printf("[metadata] failed to fetch credits for series tmdbId=%d: %v", tmdbID, err)
+	}
+
+	details := models.SeriesDetails{
+		Title:   seriesTitle,
+		Seasons: seasons,
+	}
+	_ = s.cache.set(cacheID, details)
+
+	log.Printf("[metadata] series details complete (TMDB) tmdbId=%d seasons=%d", tmdbID, len(seasons))
+	return &details, nil
+}
+
+// seriesInfoFromTMDB builds lightweight series metadata (no episodes) from TMDB alone.
+func (s *Service) seriesInfoFromTMDB(ctx context.Context, req models.SeriesDetailsQuery) (*models.Title, error) {
+	tmdbID, err := s.resolveTMDBID(ctx, "series", req.TitleID, req.Name, req.Year, req.TMDBID, req.TVDBID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cacheID := cacheKey("tmdb", "series", "info", "v1", s.tmdb.language, strconv.FormatInt(tmdbID, 10))
+	var cached models.Title
+	if ok, _ := s.cache.get(cacheID, &cached); ok && cached.ID != "" {
+		return &cached, nil
+	}
+
+	series, err := s.tmdb.seriesDetails(ctx, tmdbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch series info: %w", err)
+	}
+	seriesTitle := s.tmdbSeriesTitle(ctx, series, req.Name)
+
+	_ = s.cache.set(cacheID, seriesTitle)
+	return &seriesTitle, nil
+}
+
+// movieDetailsFromTMDB resolves a movie's TMDB ID however the client
+// identified it and serves its details from TMDB alone.
+func (s *Service) movieDetailsFromTMDB(ctx context.Context, req models.MovieDetailsQuery, includeRatings bool) (*models.Title, error) {
+	tmdbID, err := s.resolveTMDBID(ctx, "movie", req.TitleID, req.Name, req.Year, req.TMDBID, req.TVDBID, req.IMDBID)
+	if err != nil {
+		log.Printf("[metadata] movie details (TMDB) resolve error titleId=%q name=%q year=%d err=%v",
+			strings.TrimSpace(req.TitleID), strings.TrimSpace(req.Name), req.Year, err)
+		return nil, err
+	}
+	req.TMDBID = tmdbID
+
+	title, err := s.getMovieDetailsFromTMDB(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if includeRatings && title.IMDBID != "" && s.mdblist != nil && s.mdblist.IsEnabled() {
+		if ratings, err := s.mdblist.GetRatings(ctx, title.IMDBID, "movie"); err != nil {
+			log.Printf("[metadata] error fetching ratings for movie imdbId=%s: %v", title.IMDBID, err)
+		} else if len(ratings) > 0 {
+			s.setRatings(title, ratings)
+		}
+	}
+	return title, nil
+}
+
+// searchFromTMDB serves Search from TMDB when TVDB isn't configured.
+func (s *Service) searchFromTMDB(ctx context.Context, query, mediaType string) ([]models.SearchResult, error) {
+	if mediaType == "movie" || mediaType == "movies" {
+		mediaType = "movie"
+	} else {
+		mediaType = "series"
+	}
+
+	key := cacheKey("tmdb", "search", mediaType, query)
+	var cached []models.SearchResult
+	if ok, _ := s.cache.get(key, &cached); ok && len(cached) > 0 {
+		return cached, nil
+	}
+
+	titles, err := s.tmdb.search(ctx, mediaType, query, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(titles))
+	for i, title := range titles {
+		// TMDB returns results by relevance; keep that order as a descending score
+		results = append(results, models.SearchResult{Title: title, Score: len(titles) - i})
+	}
+	_ = s.cache.set(key, results)
+	return results, nil
+}
+
+// tmdbSeriesTitle converts a TMDB series to a Title with TMDB artwork, logo and genres.
+func (s *Service) tmdbSeriesTitle(ctx context.Context, series *tmdbSeries, fallbackName string) models.Title {
+	title := models.Title{
+		ID:        fmt.Sprintf("tmdb:tv:%d", series.ID),
+		Name:      strings.TrimSpace(firstNonEmpty(series.Name, series.OriginalName, fallbackName)),
+		Overview:  strings.TrimSpace(series.Overview),
+		Year:      parseTMDBYear("", series.FirstAirDate),
+		Language:  s.tmdb.language,
+		MediaType: "series",
+		TMDBID:    series.ID,
+		TVDBID:    series.ExternalIDs.TVDBID,
+		IMDBID:    strings.TrimSpace(series.ExternalIDs.IMDBID),
+		Status:    series.Status,
+		Poster:    buildTMDBImage(series.PosterPath, tmdbPosterImage, "poster"),
+		Backdrop:  buildTMDBImage(series.BackdropPath, tmdbBackdropImage, "backdrop"),
+	}
+	if original := strings.TrimSpace(series.OriginalName); original != "" && !strings.EqualFold(original, title.Name) {
+		title.OriginalName = original
+	}
+	if len(series.Networks) > 0 {
+		title.Network = series.Networks[0].Name
+	}
+
+	// Daily shows (talk shows, news) use date-based episode naming
+	switch strings.ToLower(series.Type) {
+	case "talk show", "news":
+		title.IsDaily = true
+	}
+	for _, genre := range series.Genres {
+		if genre.Name == "" {
+			continue
+		}
+		title.Genres = append(title.Genres, genre.Name)
+		switch strings.ToLower(genre.Name) {
+		case "talk", "talk show", "news":
+			title.IsDaily = true
+		}
+	}
+
+	if images, err := s.tmdb.fetchImages(ctx, "series", series.ID); err == nil && images != nil {
+		applyTMDBImages(&title, images)
+	} else if err != nil {
+		log.Printf("[metadata] failed to fetch images for series tmdbId=%d: %v", series.ID, err)
+	}
+	return title
+}
+
+// tmdbSeasonEpisodes converts a TMDB season's episodes, ordered by episode number.
+func tmdbSeasonEpisodes(season *tmdbSeason) []models.SeriesEpisode {
+	episodes := make([]models.SeriesEpisode, 0, len(season.Episodes))
+	for _, ep := range season.Episodes {
+		episode := models.SeriesEpisode{
+			ID:            fmt.Sprintf("tmdb:episode:%d", ep.ID),
+			Name:          strings.TrimSpace(firstNonEmpty(ep.Name, fmt.Sprintf("Episode %d", ep.EpisodeNumber))),
+			Overview:      strings.TrimSpace(ep.Overview),
+			SeasonNumber:  season.SeasonNumber,
+			EpisodeNumber: ep.EpisodeNumber,
+			AiredDate:     strings.TrimSpace(ep.AirDate),
+			Runtime:       ep.Runtime,
+			Image:         buildTMDBImage(ep.StillPath, tmdbStillImage, "still"),
+		}
+		episodes = append(episodes, episode)
+	}
+	sort.Slice(episodes, func(i, j int) bool { return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber })
+	return episodes
+}
@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestSeriesDetailsTMDBOnly(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+
+	httpc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			requests = append(requests, req.URL.Host+req.URL.Path)
+			mu.Unlock()
+
+			var body string
+			switch req.URL.Path {
+			case "/3/find/555":
+				body = `{"tv_results":[{"id":100}]}`
+			case "/3/tv/100":
+				body = `{"id":100,"name":"Test Show","overview":"A show","first_air_date":"2020-01-05","status":"Ended",
+					"type":"Scripted","poster_path":"/p.jpg","networks":[{"name":"HBO"}],"genres":[{"name":"Drama"}],
+					"seasons":[{"id":11,"season_number":1,"name":"Season 1"},{"id":10,"season_number":0,"name":"Specials"}],
+					"external_ids":{"imdb_id":"tt0000100","tvdb_id":555}}`
+			case "/3/tv/100/season/0":
+				body = `{"id":10,"season_number":0,"episodes":[{"id":900,"episode_number":1,"name":"Special"}]}`
+			case "/3/tv/100/season/1":
+				body = `{"id":11,"season_number":1,"episodes":[
+					{"id":102,"episode_number":2,"name":"Second","air_date":"2020-01-12","still_path":"/s2.jpg"},
+					{"id":101,"episode_number":1,"name":"First","air_date":"2020-01-05","runtime":55}]}`
+			default:
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(`{}`)), Header: make(http.Header)}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body)), Header: make(http.Header)}, nil
+		}),
+	}
+
+	service := &Service{
+		client:  newTVDBClient("", "eng", httpc, 24),
+		tmdb:    newTMDBClient("test-key", "eng", httpc, nil),
+		cache:   newFileCache(t.TempDir(), 24),
+		idCache: newFileCache(t.TempDir(), 24),
+	}
+	service.tmdb.minInterval = 0
+
+	// A TVDB title ID left over from before TVDB was removed still resolves
+	details, err := service.SeriesDetails(context.Background(), models.SeriesDetailsQuery{TitleID: "tvdb:series:555"})
+	if err != nil {
+		t.Fatalf("SeriesDetails() error = %v", err)
+	}
+
+	if details.Title.ID != "tmdb:tv:100" || details.Title.TVDBID != 555 || details.Title.IMDBID != "tt0000100" {
+		t.Fatalf("unexpected title IDs %+v", details.Title)
+	}
+	if details.Title.Year != 2020 || details.Title.Network != "HBO" || details.Title.Poster == nil {
+		t.Fatalf("unexpected title metadata %+v", details.Title)
+	}
+	if len(details.Seasons) != 2 || details.Seasons[0].Number != 0 || details.Seasons[1].Number != 1 {
+		t.Fatalf("expected seasons 0 and 1 in order, got %+v", details.Seasons)
+	}
+	episodes := details.Seasons[1].Episodes
+	if details.Seasons[1].EpisodeCount != 2 || episodes[0].Name != "First" || episodes[1].Image == nil {
+		t.Fatalf("unexpected season 1 episodes %+v", episodes)
+	}
+
+	for _, r := range requests {
+		if r == "api4.thetvdb.com/v4/login" {
+			t.Fatal("expected no TVDB requests without a TVDB key")
+		}
+	}
+
+	mu.Lock()
+	requests = nil
+	mu.Unlock()
+	if _, err := service.SeriesDetails(context.Background(), models.SeriesDetailsQuery{TitleID: "tmdb:tv:100"}); err != nil {
+		t.Fatalf("SeriesDetails() cached error = %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected cached details, got requests %v", requests)
+	}
+}
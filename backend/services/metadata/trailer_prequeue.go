@@ -14,6 +14,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"novastream/utils/sandbox"
 )
 
 // TrailerStatus represents the current state of a prequeued trailer download
@@ -201,7 +203,7 @@ func (m *TrailerPrequeueManager) downloadTrailer(id, videoURL string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, ytdlpPath,
+	cmd := sandbox.CommandContext(ctx, sandbox.ToolYtDlp, ytdlpPath,
 		"-f", "137+140/bestvideo[height<=1080]+bestaudio/best",
 		"--merge-output-format", "mp4",
 		"--no-warnings",
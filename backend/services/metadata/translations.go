@@ -0,0 +1,182 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"novastream/models"
+)
+
+// maxTranslationLanguages caps how many languages a single bundle request may ask for.
+const maxTranslationLanguages = 8
+
+// Translation request errors caused by the caller's input rather than TVDB.
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrLanguagesRequired    = errors.New("at least one language is required")
+	ErrTooManyLanguages     = errors.New("too many languages requested")
+)
+
+// Translations returns the name and overview of a title in each requested language.
+// Translations are fetched from TVDB and cached per title+language so that clients
+// showing several languages side by side share a single fetch path.
+func (s *Service) Translations(ctx context.Context, req models.TitleTranslationsQuery) (*models.TitleTranslations, error) {
+	if s.client == nil || !s.client.isConfigured() {
+		return nil, fmt.Errorf("tvdb client not configured")
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(req.MediaType))
+	if mediaType == "tv" || mediaType == "show" {
+		mediaType = "series"
+	}
+	if mediaType != "series" && mediaType != "movie" {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedMediaType, req.MediaType)
+	}
+
+	languages := dedupeLanguages(req.Languages)
+	if len(languages) == 0 {
+		return nil, ErrLanguagesRequired
+	}
+	if len(languages) > maxTranslationLanguages {
+		return nil, fmt.Errorf("%w (max %d)", ErrTooManyLanguages, maxTranslationLanguages)
+	}
+
+	var tvdbID int64
+	var err error
+	if mediaType == "series" {
+		tvdbID, err = s.resolveSeriesTVDBID(models.SeriesDetailsQuery{
+			TitleID: req.TitleID,
+			Name:    req.Name,
+			Year:    req.Year,
+			TVDBID:  req.TVDBID,
+			TMDBID:  req.TMDBID,
+		})
+	} else {
+		tvdbID, err = s.resolveMovieTVDBID(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.TitleTranslations{
+		ID:           fmt.Sprintf("tvdb:%s:%d", mediaType, tvdbID),
+		MediaType:    mediaType,
+		TVDBID:       tvdbID,
+		Translations: make([]models.LocalizedText, 0, len(languages)),
+	}
+
+	for _, lang := range languages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bundle.Translations = append(bundle.Translations, s.titleTranslation(mediaType, tvdbID, lang))
+	}
+
+	return bundle, nil
+}
+
+// titleTranslation fetches a single translation, consulting the metadata cache first.
+func (s *Service) titleTranslation(mediaType string, tvdbID int64, lang string) models.LocalizedText {
+	tvdbLang := normalizeTVDBLanguage(lang)
+	result := models.LocalizedText{Language: lang}
+
+	cacheID := cacheKey("tvdb", "translation", mediaType, tvdbLang, strconv.FormatInt(tvdbID, 10))
+	var cached models.LocalizedText
+	if ok, _ := s.cache.get(cacheID, &cached); ok {
+		cached.Language = lang
+		return cached
+	}
+
+	var translation *tvdbSeriesTranslation
+	var err error
+	if mediaType == "series" {
+		translation, err = s.client.seriesTranslations(tvdbID, tvdbLang)
+	} else {
+		translation, err = s.client.movieTranslations(tvdbID, tvdbLang)
+	}
+	if err != nil {
+		// TVDB answers 404 for languages without a translation; treat any failure as missing
+		// but don't cache it so transient errors can recover on the next request.
+		log.Printf("[metadata] translation fetch failed type=%s tvdbId=%d lang=%s err=%v", mediaType, tvdbID, tvdbLang, err)
+		result.Missing = true
+		return result
+	}
+
+	if translation != nil {
+		result.Name = strings.TrimSpace(translation.Name)
+		result.Overview = strings.TrimSpace(translation.Overview)
+	}
+	result.Missing = result.Name == "" && result.Overview == ""
+
+	_ = s.cache.set(cacheID, result)
+	return result
+}
+
+// resolveMovieTVDBID resolves the TVDB ID of a movie from explicit IDs, the cached
+// TMDB→TVDB mapping, or a TVDB name search.
+func (s *Service) resolveMovieTVDBID(req models.TitleTranslationsQuery) (int64, error) {
+	if override, ok := s.matchOverride("movie", req.TitleID, req.Name, req.Year); ok {
+		if override.TVDBID <= 0 {
+			return 0, fmt.Errorf("match override for movie %q has no tvdb id", req.Name)
+		}
+		return override.TVDBID, nil
+	}
+	if req.TVDBID > 0 {
+		return req.TVDBID, nil
+	}
+	if id := parseTVDBIDFromTitleID(req.TitleID); id > 0 {
+		return id, nil
+	}
+
+	if req.TMDBID > 0 {
+		cacheID := cacheKey("tvdb", "resolve", "movie", "tmdb", fmt.Sprintf("%d", req.TMDBID))
+		var cachedTVDBID int64
+		if ok, _ := s.cache.get(cacheID, &cachedTVDBID); ok && cachedTVDBID > 0 {
+			return cachedTVDBID, nil
+		}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return 0, fmt.Errorf("movie name required to resolve tvdb id")
+	}
+
+	results, err := s.searchTVDBMovie(name, req.Year, "")
+	if err != nil {
+		return 0, err
+	}
+	for _, result := range results {
+		if id, err := strconv.ParseInt(result.TVDBID, 10, 64); err == nil && id > 0 {
+			if req.TMDBID > 0 {
+				cacheID := cacheKey("tvdb", "resolve", "movie", "tmdb", fmt.Sprintf("%d", req.TMDBID))
+				_ = s.cache.set(cacheID, id)
+			}
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no tvdb match found for %q", name)
+}
+
+// dedupeLanguages trims, drops empties and removes languages that normalise to the same TVDB code.
+func dedupeLanguages(languages []string) []string {
+	seen := make(map[string]bool, len(languages))
+	out := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		key := normalizeTVDBLanguage(lang)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, lang)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"novastream/models"
+)
+
+// TestTranslationsBundlesLanguages verifies that each requested language is fetched
+// once, missing translations are flagged, and repeat requests are served from cache.
+func TestTranslationsBundlesLanguages(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched []string
+	)
+
+	httpc := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			path := req.URL.Path
+			if path == "/v4/login" {
+				body := bytes.NewBufferString(`{"data":{"token":"test-token"}}`)
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(body), Header: make(http.Header)}, nil
+			}
+
+			if strings.HasPrefix(path, "/v4/series/42/translations/") {
+				lang := strings.TrimPrefix(path, "/v4/series/42/translations/")
+				fetched = append(fetched, lang)
+				switch lang {
+				case "eng":
+					body := bytes.NewBufferString(`{"data":{"language":"eng","name":"The Show","overview":"English overview"}}`)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(body), Header: make(http.Header)}, nil
+				case "deu":
+					body := bytes.NewBufferString(`{"data":{"language":"deu","name":"Die Serie","overview":"Deutsche Beschreibung"}}`)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(body), Header: make(http.Header)}, nil
+				}
+			}
+
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(`{}`)), Header: make(http.Header)}, nil
+		}),
+	}
+
+	service := &Service{
+		client: newTVDBClient("test-api-key", "eng", httpc, 24),
+		cache:  newFileCache(t.TempDir(), 24),
+	}
+	service.client.minInterval = 0
+
+	query := models.TitleTranslationsQuery{
+		MediaType: "series",
+		TVDBID:    42,
+		Languages: []string{"en", "de", "eng", "ja"},
+	}
+
+	bundle, err := service.Translations(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Translations failed: %v", err)
+	}
+
+	if len(bundle.Translations) != 3 {
+		t.Fatalf("expected duplicate en/eng to collapse into 3 languages, got %d", len(bundle.Translations))
+	}
+	if got := bundle.Translations[0]; got.Language != "en" || got.Name != "The Show" {
+		t.Errorf("unexpected english translation: %+v", got)
+	}
+	if got := bundle.Translations[1]; got.Language != "de" || got.Name != "Die Serie" || got.Overview != "Deutsche Beschreibung" {
+		t.Errorf("unexpected german translation: %+v", got)
+	}
+	if got := bundle.Translations[2]; !got.Missing {
+		t.Errorf("expected japanese translation to be flagged missing: %+v", got)
+	}
+
+	if _, err := service.Translations(context.Background(), query); err != nil {
+		t.Fatalf("second Translations call failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// en and de are cached after the first call; ja failed and is retried.
+	if len(fetched) != 4 {
+		t.Fatalf("expected 4 upstream fetches (3 + 1 retry for missing), got %d: %v", len(fetched), fetched)
+	}
+}
+
+func TestTranslationsRejectsUnknownMediaType(t *testing.T) {
+	service := &Service{
+		client: newTVDBClient("test-api-key", "eng", &http.Client{}, 24),
+		cache:  newFileCache(t.TempDir(), 24),
+	}
+
+	_, err := service.Translations(context.Background(), models.TitleTranslationsQuery{MediaType: "book", TVDBID: 1, Languages: []string{"en"}})
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Fatalf("expected ErrUnsupportedMediaType, got %v", err)
+	}
+}
@@ -121,6 +121,11 @@ func normalizeTVDBLanguage(lang string) string {
 	}
 }
 
+// isConfigured reports whether a TVDB API key is set.
+func (c *tvdbClient) isConfigured() bool {
+	return c != nil && strings.TrimSpace(c.apiKey) != ""
+}
+
 func (c *tvdbClient) ensureToken() (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,257 @@
+// Package orphan_gc removes import queue and file health rows whose files are
+// gone, and spooled NZB files that no row refers to any more. Metadata files
+// without a row are left alone: they are still served over WebDAV.
+package orphan_gc
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+var ErrRunning = errors.New("orphan garbage collection is already running")
+
+// spoolGracePeriod protects NZBs that were just written and whose queue row
+// is not inserted yet, or that are being imported without a queue row.
+const spoolGracePeriod = time.Hour
+
+// Store lists and deletes the rows that reference files. Implemented by
+// *database.OrphanRepository.
+type Store interface {
+	ListQueueFileRefs() ([]database.QueueFileRef, error)
+	ListHealthFileRefs() ([]database.HealthFileRef, error)
+	DeleteQueueItems(ids []int64) (int64, error)
+	DeleteHealthRecords(ids []int64) (int64, error)
+}
+
+var _ Store = (*database.OrphanRepository)(nil)
+
+// FileSystem resolves imported files. Implemented by the NZB filesystem,
+// which answers from the metadata files on disk.
+type FileSystem interface {
+	Stat(name string) (os.FileInfo, error)
+}
+
+// Service cross-checks the database against the disk.
+type Service struct {
+	store    Store
+	files    FileSystem
+	spoolDir string
+	now      func() time.Time
+
+	runMu sync.Mutex // Held for the duration of a pass
+
+	mu   sync.RWMutex
+	last *models.OrphanGCReport
+}
+
+// NewService constructs a garbage collector. spoolDir is where queued NZBs
+// are written before import.
+func NewService(store Store, files FileSystem, spoolDir string) *Service {
+	return &Service{store: store, files: files, spoolDir: spoolDir, now: time.Now}
+}
+
+// Run removes orphans; it is the scheduled job entry point.
+func (s *Service) Run(ctx context.Context) error {
+	report, err := s.Collect(ctx, false)
+	if report.QueueItems > 0 || report.HealthRecords > 0 || report.NZBFiles > 0 {
+		log.Printf("[orphan_gc] removed %d queue items, %d health records and %d NZB files (%d bytes)",
+			report.QueueItems, report.HealthRecords, report.NZBFiles, report.ReclaimedBytes)
+	}
+	return err
+}
+
+// LastReport returns the most recent pass, if any has run since startup.
+func (s *Service) LastReport() (models.OrphanGCReport, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.last == nil {
+		return models.OrphanGCReport{}, false
+	}
+	return *s.last, true
+}
+
+// Collect runs one pass. A dry run only counts what would be removed.
+func (s *Service) Collect(ctx context.Context, dryRun bool) (models.OrphanGCReport, error) {
+	if !s.runMu.TryLock() {
+		return models.OrphanGCReport{}, ErrRunning
+	}
+	defer s.runMu.Unlock()
+
+	report := models.OrphanGCReport{StartedAt: s.now().UTC(), DryRun: dryRun}
+	err := s.collect(ctx, &report)
+	report.DurationMs = s.now().Sub(report.StartedAt).Milliseconds()
+
+	s.mu.Lock()
+	s.last = &report
+	s.mu.Unlock()
+	return report, err
+}
+
+func (s *Service) collect(ctx context.Context, report *models.OrphanGCReport) error {
+	queueRefs, err := s.store.ListQueueFileRefs()
+	if err != nil {
+		return err
+	}
+	healthRefs, err := s.store.ListHealthFileRefs()
+	if err != nil {
+		return err
+	}
+
+	// NZBs still needed by the rows that stay
+	referenced := make(map[string]struct{})
+
+	var orphanedQueue []int64
+	for _, ref := range queueRefs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if orphaned, checked := s.queueItemOrphaned(ref); !checked {
+			report.Skipped++
+		} else if orphaned {
+			orphanedQueue = append(orphanedQueue, ref.ID)
+			continue
+		}
+		referenced[filepath.Clean(ref.NzbPath)] = struct{}{}
+	}
+
+	var orphanedHealth []int64
+	for _, ref := range healthRefs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if ref.Status != database.HealthStatusChecking {
+			if orphaned, checked := s.importedFileOrphaned(ref.FilePath); !checked {
+				report.Skipped++
+			} else if orphaned {
+				orphanedHealth = append(orphanedHealth, ref.ID)
+				continue
+			}
+		}
+		if ref.SourceNzbPath != "" {
+			referenced[filepath.Clean(ref.SourceNzbPath)] = struct{}{}
+		}
+	}
+
+	if report.DryRun {
+		report.QueueItems = int64(len(orphanedQueue))
+		report.HealthRecords = int64(len(orphanedHealth))
+	} else {
+		if report.QueueItems, err = s.store.DeleteQueueItems(orphanedQueue); err != nil {
+			return err
+		}
+		if report.HealthRecords, err = s.store.DeleteHealthRecords(orphanedHealth); err != nil {
+			return err
+		}
+		// Rows that started processing in the meantime were kept, so take
+		// the NZBs to keep from what is left rather than from the pass
+		if referenced, err = s.nzbReferences(); err != nil {
+			return err
+		}
+	}
+
+	return s.collectSpool(ctx, referenced, report)
+}
+
+// nzbReferences returns the NZBs referenced by any row.
+func (s *Service) nzbReferences() (map[string]struct{}, error) {
+	queueRefs, err := s.store.ListQueueFileRefs()
+	if err != nil {
+		return nil, err
+	}
+	healthRefs, err := s.store.ListHealthFileRefs()
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]struct{}, len(queueRefs)+len(healthRefs))
+	for _, ref := range queueRefs {
+		referenced[filepath.Clean(ref.NzbPath)] = struct{}{}
+	}
+	for _, ref := range healthRefs {
+		if ref.SourceNzbPath != "" {
+			referenced[filepath.Clean(ref.SourceNzbPath)] = struct{}{}
+		}
+	}
+	return referenced, nil
+}
+
+// queueItemOrphaned reports whether a queue row's file is gone: the imported
+// file once completed, the spooled NZB before that. Rows being processed are
+// never orphaned.
+func (s *Service) queueItemOrphaned(ref database.QueueFileRef) (orphaned, checked bool) {
+	switch ref.Status {
+	case database.QueueStatusProcessing:
+		return false, true
+	case database.QueueStatusCompleted:
+		if strings.TrimSpace(ref.StoragePath) == "" {
+			// Nothing to stream; playback of the item fails anyway
+			return true, true
+		}
+		return s.importedFileOrphaned(ref.StoragePath)
+	default:
+		_, err := os.Stat(ref.NzbPath)
+		return errors.Is(err, fs.ErrNotExist), err == nil || errors.Is(err, fs.ErrNotExist)
+	}
+}
+
+func (s *Service) importedFileOrphaned(virtualPath string) (orphaned, checked bool) {
+	_, err := s.files.Stat(strings.TrimPrefix(virtualPath, "/"))
+	if err == nil {
+		return false, true
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, true
+	}
+	log.Printf("[orphan_gc] could not check %s: %v", virtualPath, err)
+	return false, false
+}
+
+// collectSpool deletes spooled NZBs that no remaining row refers to.
+func (s *Service) collectSpool(ctx context.Context, referenced map[string]struct{}, report *models.OrphanGCReport) error {
+	if s.spoolDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(s.spoolDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	cutoff := s.now().Add(-spoolGracePeriod)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(s.spoolDir, entry.Name())
+		if _, ok := referenced[path]; ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if !report.DryRun {
+			if err := os.Remove(path); err != nil {
+				log.Printf("[orphan_gc] failed to remove %s: %v", path, err)
+				continue
+			}
+		}
+		report.NZBFiles++
+		report.ReclaimedBytes += info.Size()
+	}
+	return nil
+}
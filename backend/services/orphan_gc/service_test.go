@@ -0,0 +1,146 @@
+package orphan_gc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/internal/database"
+)
+
+type fakeStore struct {
+	queue  []database.QueueFileRef
+	health []database.HealthFileRef
+}
+
+func (f *fakeStore) ListQueueFileRefs() ([]database.QueueFileRef, error) { return f.queue, nil }
+
+func (f *fakeStore) ListHealthFileRefs() ([]database.HealthFileRef, error) { return f.health, nil }
+
+func (f *fakeStore) DeleteQueueItems(ids []int64) (int64, error) {
+	kept := f.queue[:0]
+	for _, ref := range f.queue {
+		if !containsID(ids, ref.ID) || ref.Status == database.QueueStatusProcessing {
+			kept = append(kept, ref)
+		}
+	}
+	deleted := int64(len(f.queue) - len(kept))
+	f.queue = kept
+	return deleted, nil
+}
+
+func (f *fakeStore) DeleteHealthRecords(ids []int64) (int64, error) {
+	kept := f.health[:0]
+	for _, ref := range f.health {
+		if !containsID(ids, ref.ID) {
+			kept = append(kept, ref)
+		}
+	}
+	deleted := int64(len(f.health) - len(kept))
+	f.health = kept
+	return deleted, nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeFiles holds the imported files that still have metadata.
+type fakeFiles map[string]bool
+
+func (f fakeFiles) Stat(name string) (os.FileInfo, error) {
+	if name == "broken.mkv" {
+		return nil, errors.New("failed to read file metadata")
+	}
+	if !f[name] {
+		return nil, os.ErrNotExist
+	}
+	return nil, nil
+}
+
+func writeSpooled(t *testing.T, dir, name string, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("<nzb/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCollectRemovesOrphansBothWays(t *testing.T) {
+	spool := t.TempDir()
+	pending := writeSpooled(t, spool, "pending.nzb", 2*time.Hour)
+	imported := writeSpooled(t, spool, "imported.nzb", 2*time.Hour)
+	gone := writeSpooled(t, spool, "gone.nzb", 2*time.Hour)
+	unreferenced := writeSpooled(t, spool, "unreferenced.nzb", 2*time.Hour)
+	fresh := writeSpooled(t, spool, "fresh.nzb", time.Minute)
+
+	store := &fakeStore{
+		queue: []database.QueueFileRef{
+			{ID: 1, Status: database.QueueStatusPending, NzbPath: pending},
+			{ID: 2, Status: database.QueueStatusFailed, NzbPath: filepath.Join(spool, "deleted.nzb")},
+			{ID: 3, Status: database.QueueStatusCompleted, NzbPath: imported, StoragePath: "/movie/file.mkv"},
+			{ID: 4, Status: database.QueueStatusCompleted, NzbPath: gone, StoragePath: "/removed/file.mkv"},
+			{ID: 5, Status: database.QueueStatusProcessing, NzbPath: filepath.Join(spool, "missing.nzb")},
+			{ID: 6, Status: database.QueueStatusCompleted, NzbPath: imported, StoragePath: "/broken.mkv"},
+		},
+		health: []database.HealthFileRef{
+			{ID: 1, Status: database.HealthStatusCorrupted, FilePath: "/movie/file.mkv"},
+			{ID: 2, Status: database.HealthStatusCorrupted, FilePath: "/removed/file.mkv", SourceNzbPath: gone},
+			{ID: 3, Status: database.HealthStatusChecking, FilePath: "/removed/other.mkv"},
+		},
+	}
+	svc := NewService(store, fakeFiles{"movie/file.mkv": true}, spool)
+
+	dry, err := svc.Collect(context.Background(), true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(store.queue) != 6 || len(store.health) != 3 {
+		t.Fatal("dry run should not delete rows")
+	}
+	if _, err := os.Stat(unreferenced); err != nil {
+		t.Fatal("dry run should not delete files")
+	}
+
+	report, err := svc.Collect(context.Background(), false)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if report.QueueItems != 2 || report.HealthRecords != 1 || report.NZBFiles != 2 || report.Skipped != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.ReclaimedBytes != 2*int64(len("<nzb/>")) {
+		t.Errorf("expected the removed NZBs' size to be reported, got %d", report.ReclaimedBytes)
+	}
+	if dry.QueueItems != report.QueueItems || dry.HealthRecords != report.HealthRecords || dry.NZBFiles != report.NZBFiles {
+		t.Errorf("dry run %+v should match the real run %+v", dry, report)
+	}
+
+	for _, path := range []string{pending, imported, fresh} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s should be kept: %v", filepath.Base(path), err)
+		}
+	}
+	for _, path := range []string{gone, unreferenced} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, stat err=%v", filepath.Base(path), err)
+		}
+	}
+
+	if last, ok := svc.LastReport(); !ok || last.DryRun {
+		t.Errorf("expected the last report to be the real run, got %+v", last)
+	}
+}
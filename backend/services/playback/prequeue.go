@@ -40,6 +40,12 @@ type PrequeueRequest struct {
 	// Prequeue reason: "details" (user opened details page) or "next_episode" (auto-queue for next episode)
 	// Defaults to "details" if not specified
 	Reason string `json:"reason,omitempty"`
+	// Last measured client throughput in Mbps. When set, releases whose estimated
+	// bitrate won't fit the link are deprioritised during resolution.
+	BandwidthMbps float64 `json:"bandwidthMbps,omitempty"`
+	// IgnoreBandwidth disables bandwidth-aware ordering for power users who want
+	// the highest quality release regardless of the measured link.
+	IgnoreBandwidth bool `json:"ignoreBandwidth,omitempty"`
 }
 
 // PrequeueResponse is returned when a prequeue request is initiated
@@ -55,6 +61,8 @@ type AudioTrackInfo struct {
 	Language string `json:"language"` // Language code (e.g., "eng", "spa")
 	Codec    string `json:"codec"`    // Codec name (e.g., "aac", "ac3", "truehd")
 	Title    string `json:"title"`    // Track title/name
+	// AudioDescription marks audio-description (AD) tracks for the visually impaired
+	AudioDescription bool `json:"audioDescription,omitempty"`
 }
 
 // SubtitleTrackInfo represents a subtitle track with metadata
@@ -66,6 +74,7 @@ type SubtitleTrackInfo struct {
 	Codec         string `json:"codec"`         // Codec name
 	Forced        bool   `json:"forced"`        // Whether this is a forced subtitle track
 	IsBitmap      bool   `json:"isBitmap"`      // Whether this is a bitmap subtitle (PGS, VOBSUB)
+	SDH           bool   `json:"sdh,omitempty"` // Subtitles for the deaf and hard of hearing
 }
 
 // PrequeueStatusResponse is the full status of a prequeue entry
@@ -84,6 +93,7 @@ type PrequeueStatusResponse struct {
 	// HDR detection results
 	HasDolbyVision     bool   `json:"hasDolbyVision,omitempty"`
 	HasHDR10           bool   `json:"hasHdr10,omitempty"`
+	HasHDR10Plus       bool   `json:"hasHdr10Plus,omitempty"`
 	DolbyVisionProfile string `json:"dolbyVisionProfile,omitempty"`
 
 	// Audio transcoding detection (TrueHD, DTS, etc.)
@@ -105,12 +115,16 @@ type PrequeueStatusResponse struct {
 	// Pre-extracted subtitle sessions (for direct streaming/VLC path)
 	SubtitleSessions map[int]*models.SubtitleSessionInfo `json:"subtitleSessions,omitempty"`
 
+	// Subtitles uploaded by users for this title/episode, served as WebVTT
+	ExternalSubtitles []models.ExternalSubtitleTrack `json:"externalSubtitles,omitempty"`
+
 	// AIOStreams passthrough format
 	PassthroughName        string `json:"passthroughName,omitempty"`        // Raw display name from AIOStreams
 	PassthroughDescription string `json:"passthroughDescription,omitempty"` // Raw description from AIOStreams
 
 	// On failure:
-	Error string `json:"error,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty"` // e.g. "disk_space_insufficient"
 }
 
 // PrequeueEntry is the internal state of a prequeue item
@@ -132,6 +146,7 @@ type PrequeueEntry struct {
 	// HDR detection
 	HasDolbyVision     bool
 	HasHDR10           bool
+	HasHDR10Plus       bool // HDR10+ dynamic metadata; capable clients may request passthrough
 	DolbyVisionProfile string
 
 	// Audio transcoding detection (TrueHD, DTS, etc.)
@@ -158,6 +173,7 @@ type PrequeueEntry struct {
 	PassthroughDescription string
 
 	Error     string
+	ErrorCode string
 	CreatedAt time.Time
 	ExpiresAt time.Time
 
@@ -399,6 +415,7 @@ func (e *PrequeueEntry) ToResponse() *PrequeueStatusResponse {
 		HealthStatus:           e.HealthStatus,
 		HasDolbyVision:         e.HasDolbyVision,
 		HasHDR10:               e.HasHDR10,
+		HasHDR10Plus:           e.HasHDR10Plus,
 		DolbyVisionProfile:     e.DolbyVisionProfile,
 		NeedsAudioTranscode:    e.NeedsAudioTranscode,
 		HLSSessionID:           e.HLSSessionID,
@@ -412,5 +429,6 @@ func (e *PrequeueEntry) ToResponse() *PrequeueStatusResponse {
 		PassthroughName:        e.PassthroughName,
 		PassthroughDescription: e.PassthroughDescription,
 		Error:                  e.Error,
+		ErrorCode:              e.ErrorCode,
 	}
 }
@@ -99,21 +99,6 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 	}
 
 	// Otherwise, handle as usenet
-	downloadURL := strings.TrimSpace(candidate.DownloadURL)
-	if downloadURL == "" {
-		downloadURL = strings.TrimSpace(candidate.Link)
-	}
-	if downloadURL == "" {
-		return nil, fmt.Errorf("candidate is missing a download URL")
-	}
-
-	nzbBytes, fileName, err := s.fetchNZB(ctx, downloadURL, candidate)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Printf("[playback] nzb fetched size=%d fileName=%q", len(nzbBytes), fileName)
-
 	// Check if health check should be skipped (optimization for faster startup)
 	cfg, err := s.cfg.Load()
 	if err != nil {
@@ -121,29 +106,24 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 	}
 	skipHealthCheck := cfg.Import.SkipHealthCheck
 
-	healthStatus := "unknown"
-	var healthCheck *models.NZBHealthCheck
-
-	if skipHealthCheck {
-		log.Printf("[playback] health check skipped (skipHealthCheck=true in config)")
-	} else if s.usenet != nil {
-		check, err := s.usenet.CheckHealthWithNZB(ctx, candidate, nzbBytes, fileName)
-		if err != nil {
-			return nil, fmt.Errorf("check nzb health: %w", err)
+	// Fall back to copies of the release from other indexers when this
+	// copy's NZB is gone or unhealthy
+	var (
+		nzbBytes    []byte
+		fileName    string
+		healthCheck *models.NZBHealthCheck
+	)
+	copies := candidate.Copies()
+	for i, c := range copies {
+		nzbBytes, fileName, healthCheck, err = s.fetchHealthyNZB(ctx, c, skipHealthCheck)
+		if err == nil {
+			candidate = c
+			break
 		}
-		healthCheck = check
-		if check != nil {
-			healthStatus = strings.ToLower(strings.TrimSpace(check.Status))
-			if healthStatus == "" {
-				healthStatus = "unknown"
-			}
-			log.Printf("[playback] backend health status=%q healthy=%t sampled=%t missing=%d", healthStatus, check.Healthy, check.Sampled, len(check.MissingSegments))
-			if !check.Healthy {
-				return nil, fmt.Errorf("nzb health check reported %s", healthStatus)
-			}
+		if i == len(copies)-1 || ctx.Err() != nil {
+			return nil, err
 		}
-	} else {
-		log.Printf("[playback] warning: usenet health service not configured; proceeding without pre-flight validation")
+		log.Printf("[playback] copy from %q failed: %v; trying copy from %q", c.Indexer, err, copies[i+1].Indexer)
 	}
 
 	if s.nzbSystem == nil {
@@ -221,6 +201,52 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 	return resolution, nil
 }
 
+// fetchHealthyNZB fetches one copy's NZB and runs the usenet health check on
+// it unless skipped, returning an error when the copy can't be used.
+func (s *Service) fetchHealthyNZB(ctx context.Context, candidate models.NZBResult, skipHealthCheck bool) ([]byte, string, *models.NZBHealthCheck, error) {
+	downloadURL := strings.TrimSpace(candidate.DownloadURL)
+	if downloadURL == "" {
+		downloadURL = strings.TrimSpace(candidate.Link)
+	}
+	if downloadURL == "" {
+		return nil, "", nil, fmt.Errorf("candidate is missing a download URL")
+	}
+
+	nzbBytes, fileName, err := s.fetchNZB(ctx, downloadURL, candidate)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	log.Printf("[playback] nzb fetched size=%d fileName=%q", len(nzbBytes), fileName)
+
+	healthStatus := "unknown"
+	var healthCheck *models.NZBHealthCheck
+
+	if skipHealthCheck {
+		log.Printf("[playback] health check skipped (skipHealthCheck=true in config)")
+	} else if s.usenet != nil {
+		check, err := s.usenet.CheckHealthWithNZB(ctx, candidate, nzbBytes, fileName)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("check nzb health: %w", err)
+		}
+		healthCheck = check
+		if check != nil {
+			healthStatus = strings.ToLower(strings.TrimSpace(check.Status))
+			if healthStatus == "" {
+				healthStatus = "unknown"
+			}
+			log.Printf("[playback] backend health status=%q healthy=%t sampled=%t missing=%d", healthStatus, check.Healthy, check.Sampled, len(check.MissingSegments))
+			if !check.Healthy {
+				return nil, "", nil, fmt.Errorf("nzb health check reported %s", healthStatus)
+			}
+		}
+	} else {
+		log.Printf("[playback] warning: usenet health service not configured; proceeding without pre-flight validation")
+	}
+
+	return nzbBytes, fileName, healthCheck, nil
+}
+
 // ParallelHealthCheck performs health checks on multiple candidates concurrently.
 // It returns results sorted by original index (priority order), with healthy results first.
 // The limit parameter controls how many candidates to check in parallel.
@@ -275,76 +301,14 @@ func (s *Service) ParallelHealthCheck(ctx context.Context, candidates []models.N
 		go func(idx int, candidate models.NZBResult) {
 			defer wg.Done()
 
-			result := HealthCheckResult{
-				Index:     idx,
-				Candidate: candidate,
-			}
-
-			// Check if context was cancelled
-			select {
-			case <-checkCtx.Done():
-				result.Error = checkCtx.Err()
-				mu.Lock()
-				results = append(results, result)
-				mu.Unlock()
-				return
-			default:
-			}
-
-			// Get download URL
-			downloadURL := strings.TrimSpace(candidate.DownloadURL)
-			if downloadURL == "" {
-				downloadURL = strings.TrimSpace(candidate.Link)
-			}
-			if downloadURL == "" {
-				result.Error = fmt.Errorf("missing download URL")
-				mu.Lock()
-				results = append(results, result)
-				mu.Unlock()
-				return
-			}
-
-			// Fetch NZB
-			nzbBytes, fileName, err := s.fetchNZB(checkCtx, downloadURL, candidate)
-			if err != nil {
-				result.Error = fmt.Errorf("fetch NZB: %w", err)
-				mu.Lock()
-				results = append(results, result)
-				mu.Unlock()
-				return
-			}
-
-			result.NZBBytes = nzbBytes
-			result.FileName = fileName
-
-			// Perform health check if not skipped
-			if skipHealthCheck {
-				result.Healthy = true
-				log.Printf("[playback] parallel health check [%d] %s: skipped (config)", idx, candidate.Title)
-			} else if s.usenet != nil {
-				check, err := s.usenet.CheckHealthWithNZB(checkCtx, candidate, nzbBytes, fileName)
-				if err != nil {
-					result.Error = fmt.Errorf("health check: %w", err)
-					mu.Lock()
-					results = append(results, result)
-					mu.Unlock()
-					return
+			// Fall back to copies of the release from other indexers when
+			// this copy's NZB is gone or unhealthy
+			var result HealthCheckResult
+			for _, c := range candidate.Copies() {
+				result = s.healthCheckCopy(checkCtx, idx, c, skipHealthCheck)
+				if result.Healthy || checkCtx.Err() != nil {
+					break
 				}
-				result.Check = check
-				result.Healthy = check != nil && check.Healthy
-				if result.Healthy {
-					log.Printf("[playback] parallel health check [%d] %s: healthy", idx, candidate.Title)
-				} else {
-					status := "unknown"
-					if check != nil {
-						status = check.Status
-					}
-					log.Printf("[playback] parallel health check [%d] %s: %s", idx, candidate.Title, status)
-				}
-			} else {
-				// No health service, assume healthy
-				result.Healthy = true
-				log.Printf("[playback] parallel health check [%d] %s: no health service, assuming healthy", idx, candidate.Title)
 			}
 
 			mu.Lock()
@@ -375,6 +339,71 @@ func (s *Service) ParallelHealthCheck(ctx context.Context, candidates []models.N
 	return results
 }
 
+// healthCheckCopy fetches and health checks one copy of the candidate at idx.
+func (s *Service) healthCheckCopy(ctx context.Context, idx int, candidate models.NZBResult, skipHealthCheck bool) HealthCheckResult {
+	result := HealthCheckResult{
+		Index:     idx,
+		Candidate: candidate,
+	}
+
+	// Check if context was cancelled
+	select {
+	case <-ctx.Done():
+		result.Error = ctx.Err()
+		return result
+	default:
+	}
+
+	// Get download URL
+	downloadURL := strings.TrimSpace(candidate.DownloadURL)
+	if downloadURL == "" {
+		downloadURL = strings.TrimSpace(candidate.Link)
+	}
+	if downloadURL == "" {
+		result.Error = fmt.Errorf("missing download URL")
+		return result
+	}
+
+	// Fetch NZB
+	nzbBytes, fileName, err := s.fetchNZB(ctx, downloadURL, candidate)
+	if err != nil {
+		result.Error = fmt.Errorf("fetch NZB: %w", err)
+		return result
+	}
+
+	result.NZBBytes = nzbBytes
+	result.FileName = fileName
+
+	// Perform health check if not skipped
+	if skipHealthCheck {
+		result.Healthy = true
+		log.Printf("[playback] parallel health check [%d] %s: skipped (config)", idx, candidate.Title)
+	} else if s.usenet != nil {
+		check, err := s.usenet.CheckHealthWithNZB(ctx, candidate, nzbBytes, fileName)
+		if err != nil {
+			result.Error = fmt.Errorf("health check: %w", err)
+			return result
+		}
+		result.Check = check
+		result.Healthy = check != nil && check.Healthy
+		if result.Healthy {
+			log.Printf("[playback] parallel health check [%d] %s: healthy", idx, candidate.Title)
+		} else {
+			status := "unknown"
+			if check != nil {
+				status = check.Status
+			}
+			log.Printf("[playback] parallel health check [%d] %s: %s", idx, candidate.Title, status)
+		}
+	} else {
+		// No health service, assume healthy
+		result.Healthy = true
+		log.Printf("[playback] parallel health check [%d] %s: no health service, assuming healthy", idx, candidate.Title)
+	}
+
+	return result
+}
+
 // ResolveWithHealthResult processes an NZB using pre-fetched health check results.
 // This avoids re-fetching and re-checking the NZB when we already have the data.
 func (s *Service) ResolveWithHealthResult(ctx context.Context, result HealthCheckResult) (*models.PlaybackResolution, error) {
@@ -0,0 +1,169 @@
+package playback_compat
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// PGS segment types
+const (
+	pgsPalette     = 0x14
+	pgsObject      = 0x15
+	pgsComposition = 0x16
+	pgsWindow      = 0x17
+	pgsEnd         = 0x80
+)
+
+// pgsGlyphs is a 5x7 pixel font for the caption.
+var pgsGlyphs = map[rune][7]string{
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".###."},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+}
+
+// pgsCaption writes a Blu-ray PGS (.sup) stream that shows text centred near
+// the bottom of a width×height frame from 0 until end (in seconds). ffmpeg
+// can mux PGS but not encode it, so the stream is built here.
+func pgsCaption(text string, width, height int, end float64) []byte {
+	const scale = 8
+	bitmapW := (len(text)*6 - 1) * scale
+	bitmapH := 7 * scale
+	x := (width - bitmapW) / 2
+	y := height - bitmapH - height/10
+
+	// Palette entry 1 is opaque white, 0 transparent
+	bitmap := make([][]byte, bitmapH)
+	for row := range bitmap {
+		bitmap[row] = make([]byte, bitmapW)
+	}
+	for i, r := range text {
+		glyph := pgsGlyphs[r]
+		for gy, line := range glyph {
+			for gx, c := range line {
+				if c != '#' {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						bitmap[gy*scale+dy][(i*6+gx)*scale+dx] = 1
+					}
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+
+	// Display set shown at the start
+	pgsSegment(&out, 0, pgsComposition, composition(width, height, 0, 0x80, &pgsObjectPosition{x: x, y: y}))
+	pgsSegment(&out, 0, pgsWindow, window(x, y, bitmapW, bitmapH))
+	pgsSegment(&out, 0, pgsPalette, []byte{
+		0, 0, // Palette ID and version
+		0, 16, 128, 128, 0, // Transparent
+		1, 235, 128, 128, 255, // White
+	})
+	pgsSegment(&out, 0, pgsObject, object(bitmapW, bitmapH, runLengthEncode(bitmap)))
+	pgsSegment(&out, 0, pgsEnd, nil)
+
+	// Display set that clears it
+	pts := uint32(end * 90000)
+	pgsSegment(&out, pts, pgsComposition, composition(width, height, 1, 0x00, nil))
+	pgsSegment(&out, pts, pgsWindow, window(x, y, bitmapW, bitmapH))
+	pgsSegment(&out, pts, pgsEnd, nil)
+
+	return out.Bytes()
+}
+
+type pgsObjectPosition struct {
+	x, y int
+}
+
+func pgsSegment(out *bytes.Buffer, pts uint32, kind byte, data []byte) {
+	out.WriteString("PG")
+	binary.Write(out, binary.BigEndian, pts)
+	binary.Write(out, binary.BigEndian, uint32(0)) // DTS
+	out.WriteByte(kind)
+	binary.Write(out, binary.BigEndian, uint16(len(data)))
+	out.Write(data)
+}
+
+func composition(width, height, number int, state byte, object *pgsObjectPosition) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint16(width))
+	binary.Write(&b, binary.BigEndian, uint16(height))
+	b.WriteByte(0x10) // 23.976 fps
+	binary.Write(&b, binary.BigEndian, uint16(number))
+	b.WriteByte(state)
+	b.WriteByte(0) // Palette update
+	b.WriteByte(0) // Palette ID
+	if object == nil {
+		b.WriteByte(0)
+		return b.Bytes()
+	}
+	b.WriteByte(1)
+	binary.Write(&b, binary.BigEndian, uint16(0)) // Object ID
+	b.WriteByte(0)                                // Window ID
+	b.WriteByte(0)                                // Not cropped
+	binary.Write(&b, binary.BigEndian, uint16(object.x))
+	binary.Write(&b, binary.BigEndian, uint16(object.y))
+	return b.Bytes()
+}
+
+func window(x, y, width, height int) []byte {
+	var b bytes.Buffer
+	b.WriteByte(1) // One window
+	b.WriteByte(0) // Window ID
+	for _, v := range []int{x, y, width, height} {
+		binary.Write(&b, binary.BigEndian, uint16(v))
+	}
+	return b.Bytes()
+}
+
+func object(width, height int, rle []byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint16(0)) // Object ID
+	b.WriteByte(0)                                // Version
+	b.WriteByte(0xC0)                             // First and last fragment
+	dataLen := len(rle) + 4
+	b.Write([]byte{byte(dataLen >> 16), byte(dataLen >> 8), byte(dataLen)})
+	binary.Write(&b, binary.BigEndian, uint16(width))
+	binary.Write(&b, binary.BigEndian, uint16(height))
+	b.Write(rle)
+	return b.Bytes()
+}
+
+// runLengthEncode encodes palette indexes with the PGS run-length scheme,
+// one line at a time.
+func runLengthEncode(bitmap [][]byte) []byte {
+	var out []byte
+	for _, line := range bitmap {
+		for i := 0; i < len(line); {
+			color := line[i]
+			run := 1
+			for i+run < len(line) && line[i+run] == color && run < 0x3FFF {
+				run++
+			}
+			i += run
+
+			switch {
+			case color == 0 && run < 64:
+				out = append(out, 0, byte(run))
+			case color == 0:
+				out = append(out, 0, 0x40|byte(run>>8), byte(run))
+			case run < 3:
+				for ; run > 0; run-- {
+					out = append(out, color)
+				}
+			case run < 64:
+				out = append(out, 0, 0x80|byte(run), color)
+			default:
+				out = append(out, 0, 0xC0|byte(run>>8), byte(run), color)
+			}
+		}
+		out = append(out, 0, 0) // End of line
+	}
+	return out
+}
@@ -0,0 +1,459 @@
+// Package playback_compat generates short test clips covering the codecs,
+// HDR formats and subtitle formats a device may or may not play, and turns
+// a device's playback results into the capability profile used for direct
+// play negotiation.
+package playback_compat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+	"novastream/utils/sandbox"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrClipNotFound       = errors.New("compatibility clip not found")
+	ErrClipUnavailable    = errors.New("compatibility clip cannot be generated on this server")
+	ErrUnknownClip        = errors.New("result reported for an unknown clip")
+	ErrBaselineFailed     = errors.New("the baseline clip did not play, so the results are inconclusive")
+)
+
+const (
+	clipDuration = 5 // Seconds
+	clipWidth    = 1280
+	clipHeight   = 720
+	clipFPS      = 24
+
+	// Generating a clip takes a few seconds; give slow hosts plenty of room
+	generateTimeout = 2 * time.Minute
+
+	baselineClipID = "baseline"
+	mkvClipID      = "container-mkv"
+	hevcClipID     = "video-hevc"
+)
+
+// HDR10 mastering metadata for a P3 D65 display at 1000 nits
+const (
+	masterDisplay = "G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,1)"
+	maxCLL        = "1000,400"
+)
+
+// clips is the test suite, baseline first. Every clip changes one thing
+// from the clips it requires.
+var clips = []models.CompatClip{
+	{
+		ID: baselineClipID, Label: "H.264 + AAC (MP4)",
+		Container: "mp4", VideoCodec: "h264", AudioCodec: "aac",
+		Tests: []models.CompatFeature{
+			{Kind: models.CompatVideoCodec, Value: "h264"},
+			{Kind: models.CompatAudioCodec, Value: "aac"},
+			{Kind: models.CompatContainer, Value: "mp4"},
+		},
+	},
+	{
+		ID: mkvClipID, Label: "H.264 + AAC (MKV)",
+		Container: "mkv", VideoCodec: "h264", AudioCodec: "aac",
+		Tests:    []models.CompatFeature{{Kind: models.CompatContainer, Value: "mkv"}},
+		Requires: []string{baselineClipID},
+	},
+	{
+		ID: hevcClipID, Label: "HEVC",
+		Container: "mp4", VideoCodec: "hevc", AudioCodec: "aac",
+		Tests:    []models.CompatFeature{{Kind: models.CompatVideoCodec, Value: "hevc"}},
+		Requires: []string{baselineClipID},
+	},
+	{
+		ID: "hdr-hdr10", Label: "HEVC HDR10",
+		Container: "mp4", VideoCodec: "hevc", HDRFormat: "hdr10", AudioCodec: "aac",
+		Tests:    []models.CompatFeature{{Kind: models.CompatHDRFormat, Value: "hdr10"}},
+		Requires: []string{hevcClipID},
+	},
+	{
+		// Profile 8.1 falls back to HDR10 on players without Dolby Vision, so
+		// clients should only report it played when it rendered in Dolby Vision
+		ID: "hdr-dv8", Label: "HEVC Dolby Vision profile 8.1",
+		Container: "mp4", VideoCodec: "hevc", HDRFormat: "dolbyvision", AudioCodec: "aac",
+		Tests:    []models.CompatFeature{{Kind: models.CompatHDRFormat, Value: "dolbyvision"}},
+		Requires: []string{hevcClipID},
+	},
+	{
+		ID: "audio-eac3", Label: "E-AC-3 5.1",
+		Container: "mp4", VideoCodec: "h264", AudioCodec: "eac3",
+		Tests:    []models.CompatFeature{{Kind: models.CompatAudioCodec, Value: "eac3"}},
+		Requires: []string{baselineClipID},
+	},
+	{
+		ID: "audio-truehd", Label: "TrueHD",
+		Container: "mkv", VideoCodec: "h264", AudioCodec: "truehd",
+		Tests:    []models.CompatFeature{{Kind: models.CompatAudioCodec, Value: "truehd"}},
+		Requires: []string{mkvClipID},
+	},
+	{
+		ID: "subs-text", Label: "SubRip subtitles",
+		Container: "mkv", VideoCodec: "h264", AudioCodec: "aac", SubtitleFormat: "subrip",
+		Tests:    []models.CompatFeature{{Kind: models.CompatSubtitleFormat, Value: "subrip"}},
+		Requires: []string{mkvClipID},
+	},
+	{
+		ID: "subs-pgs", Label: "PGS subtitles",
+		Container: "mkv", VideoCodec: "h264", AudioCodec: "aac", SubtitleFormat: "pgs",
+		Tests:    []models.CompatFeature{{Kind: models.CompatSubtitleFormat, Value: "pgs"}},
+		Requires: []string{mkvClipID},
+	},
+}
+
+// Service generates compatibility clips on first request and caches them on
+// disk.
+type Service struct {
+	dir        string
+	ffmpegPath string
+
+	mu sync.Mutex // Serialises generation
+
+	encodersOnce sync.Once
+	encoders     map[string]bool
+}
+
+// NewService constructs a compatibility suite that caches its clips under
+// storageDir.
+func NewService(storageDir, ffmpegPath string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if strings.TrimSpace(ffmpegPath) == "" {
+		ffmpegPath = "ffmpeg"
+	}
+
+	dir := filepath.Join(storageDir, "compat_clips")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create compatibility clips dir: %w", err)
+	}
+
+	return &Service{dir: dir, ffmpegPath: ffmpegPath}, nil
+}
+
+// Clips lists the suite, marking the clips this server can generate.
+func (s *Service) Clips() []models.CompatClip {
+	out := make([]models.CompatClip, len(clips))
+	for i, clip := range clips {
+		clip.Available = s.canGenerate(clip)
+		out[i] = clip
+	}
+	return out
+}
+
+// ClipPath returns the path of the clip, generating it if needed.
+func (s *Service) ClipPath(ctx context.Context, id string) (string, error) {
+	clip, ok := findClip(id)
+	if !ok {
+		return "", ErrClipNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, clip.ID+"."+clip.Container)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if !s.canGenerate(clip) {
+		return "", ErrClipUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, generateTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.generate(ctx, clip, path); err != nil {
+		return "", fmt.Errorf("generate %s clip: %w", clip.ID, err)
+	}
+	log.Printf("[playback-compat] generated %s clip in %s", clip.ID, time.Since(start).Round(time.Millisecond))
+	return path, nil
+}
+
+// Summarize turns a device's results (clip ID -> played) into the features
+// it supports and doesn't. A feature is supported when any clip testing it
+// played, and unsupported when a clip testing it failed while the clips it
+// requires played; anything else was not tested.
+func (s *Service) Summarize(results map[string]bool) (models.DeviceCompatibilityTest, error) {
+	for id := range results {
+		if _, ok := findClip(id); !ok {
+			return models.DeviceCompatibilityTest{}, fmt.Errorf("%w: %s", ErrUnknownClip, id)
+		}
+	}
+	if played, tested := results[baselineClipID]; tested && !played {
+		return models.DeviceCompatibilityTest{}, ErrBaselineFailed
+	}
+
+	supported := make(map[models.CompatFeature]bool)
+	unsupported := make(map[models.CompatFeature]bool)
+	for _, clip := range clips {
+		played, tested := results[clip.ID]
+		if !tested {
+			continue
+		}
+		if played {
+			for _, feature := range clip.Tests {
+				supported[feature] = true
+			}
+			continue
+		}
+		conclusive := true
+		for _, required := range clip.Requires {
+			if !results[required] {
+				conclusive = false
+				break
+			}
+		}
+		if conclusive {
+			for _, feature := range clip.Tests {
+				unsupported[feature] = true
+			}
+		}
+	}
+
+	test := models.DeviceCompatibilityTest{
+		TestedAt: time.Now().UTC(),
+		Results:  results,
+	}
+	// Walk the suite so the lists come out in a stable order
+	for _, clip := range clips {
+		for _, feature := range clip.Tests {
+			switch {
+			case supported[feature]:
+				test.Supported = append(test.Supported, feature)
+				delete(supported, feature)
+			case unsupported[feature]:
+				test.Unsupported = append(test.Unsupported, feature)
+				delete(unsupported, feature)
+			}
+		}
+	}
+	return test, nil
+}
+
+func findClip(id string) (models.CompatClip, bool) {
+	for _, clip := range clips {
+		if clip.ID == id {
+			return clip, true
+		}
+	}
+	return models.CompatClip{}, false
+}
+
+// canGenerate reports whether ffmpeg has the encoders the clip needs, and
+// for Dolby Vision whether dovi_tool is installed to build the RPU.
+func (s *Service) canGenerate(clip models.CompatClip) bool {
+	s.encodersOnce.Do(func() { s.encoders = s.listEncoders() })
+	for _, encoder := range requiredEncoders(clip) {
+		if !s.encoders[encoder] {
+			return false
+		}
+	}
+	if clip.HDRFormat == "dolbyvision" {
+		if _, err := exec.LookPath("dovi_tool"); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// listEncoders asks ffmpeg which encoders it was built with. A missing
+// ffmpeg leaves the set empty so every clip reports unavailable.
+func (s *Service) listEncoders() map[string]bool {
+	encoders := make(map[string]bool)
+	out, err := sandbox.CommandContext(context.Background(), sandbox.ToolFFmpeg, s.ffmpegPath, "-hide_banner", "-encoders").Output()
+	if err != nil {
+		log.Printf("[playback-compat] list ffmpeg encoders: %v", err)
+		return encoders
+	}
+	// Lines look like " V....D libx264   libx264 H.264 ..."
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && len(fields[0]) == 6 && !strings.Contains(fields[0], "=") {
+			encoders[fields[1]] = true
+		}
+	}
+	return encoders
+}
+
+func requiredEncoders(clip models.CompatClip) []string {
+	encoders := []string{videoEncoder(clip.VideoCodec), clip.AudioCodec}
+	if clip.SubtitleFormat == "subrip" {
+		encoders = append(encoders, "srt")
+	}
+	return encoders
+}
+
+func videoEncoder(codec string) string {
+	if codec == "hevc" {
+		return "libx265"
+	}
+	return "libx264"
+}
+
+// generate encodes the clip into a temporary file beside path and renames it
+// into place, so an interrupted run never leaves a truncated clip behind.
+func (s *Service) generate(ctx context.Context, clip models.CompatClip, path string) error {
+	work, err := os.MkdirTemp(s.dir, "."+clip.ID+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", fmt.Sprintf("testsrc2=size=%dx%d:rate=%d:duration=%d", clipWidth, clipHeight, clipFPS, clipDuration),
+		"-f", "lavfi", "-i", fmt.Sprintf("sine=frequency=440:sample_rate=48000:duration=%d", clipDuration),
+	}
+
+	subtitles, err := writeSubtitles(work, clip.SubtitleFormat)
+	if err != nil {
+		return err
+	}
+	if subtitles != "" {
+		args = append(args, "-i", subtitles)
+	}
+
+	args = append(args, "-map", "0:v", "-map", "1:a")
+	if subtitles != "" {
+		args = append(args, "-map", "2:s")
+	}
+
+	videoArgs, err := s.videoArgs(ctx, clip, work)
+	if err != nil {
+		return err
+	}
+	args = append(args, videoArgs...)
+	args = append(args, audioArgs(clip.AudioCodec)...)
+
+	switch clip.SubtitleFormat {
+	case "subrip":
+		args = append(args, "-c:s", "srt")
+	case "pgs":
+		args = append(args, "-c:s", "copy")
+	}
+	if clip.Container == "mp4" {
+		args = append(args, "-movflags", "+faststart")
+	}
+
+	tmp := filepath.Join(work, "clip."+clip.Container)
+	args = append(args, tmp)
+	if err := run(ctx, sandbox.ToolFFmpeg, s.ffmpegPath, args...); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Service) videoArgs(ctx context.Context, clip models.CompatClip, work string) ([]string, error) {
+	if clip.VideoCodec != "hevc" {
+		return []string{"-c:v", "libx264", "-preset", "veryfast", "-pix_fmt", "yuv420p"}, nil
+	}
+
+	args := []string{"-c:v", "libx265", "-preset", "fast"}
+	switch clip.HDRFormat {
+	case "":
+		return append(args, "-pix_fmt", "yuv420p", "-tag:v", "hvc1"), nil
+	case "hdr10":
+		return append(args,
+			"-pix_fmt", "yuv420p10le", "-tag:v", "hvc1",
+			"-x265-params", hdr10Params(),
+		), nil
+	case "dolbyvision":
+		rpu, err := generateRPU(ctx, work)
+		if err != nil {
+			return nil, err
+		}
+		return append(args,
+			"-pix_fmt", "yuv420p10le", "-tag:v", "dvh1", "-strict", "unofficial",
+			"-x265-params", hdr10Params()+":dolby-vision-profile=8.1:dolby-vision-rpu="+rpu+":vbv-bufsize=20000:vbv-maxrate=20000",
+		), nil
+	default:
+		return nil, fmt.Errorf("unsupported HDR format %q", clip.HDRFormat)
+	}
+}
+
+func hdr10Params() string {
+	return strings.Join([]string{
+		"hdr10=1", "repeat-headers=1",
+		"colorprim=bt2020", "transfer=smpte2084", "colormatrix=bt2020nc",
+		"master-display=" + masterDisplay, "max-cll=" + maxCLL,
+	}, ":")
+}
+
+func audioArgs(codec string) []string {
+	switch codec {
+	case "eac3":
+		return []string{"-c:a", "eac3", "-ac", "6", "-b:a", "384k"}
+	case "truehd":
+		return []string{"-c:a", "truehd", "-strict", "-2"}
+	default:
+		return []string{"-c:a", "aac", "-ac", "2", "-b:a", "128k"}
+	}
+}
+
+// generateRPU builds a static profile 8.1 RPU for every frame of the clip
+// with dovi_tool.
+func generateRPU(ctx context.Context, work string) (string, error) {
+	config := filepath.Join(work, "rpu.json")
+	rpu := filepath.Join(work, "rpu.bin")
+	body := fmt.Sprintf(`{"profile":"8.1","cm_version":"V40","length":%d,"level6":{"max_display_mastering_luminance":1000,"min_display_mastering_luminance":1,"max_content_light_level":1000,"max_frame_average_light_level":400}}`,
+		clipDuration*clipFPS)
+	if err := os.WriteFile(config, []byte(body), 0o644); err != nil {
+		return "", err
+	}
+	if err := run(ctx, sandbox.ToolDoviTool, "dovi_tool", "generate", "-j", config, "-o", rpu); err != nil {
+		return "", err
+	}
+	return rpu, nil
+}
+
+// writeSubtitles writes the subtitle input for the format, returning its
+// path, or "" when the clip has no subtitles.
+func writeSubtitles(work, format string) (string, error) {
+	var name string
+	var body []byte
+	switch format {
+	case "":
+		return "", nil
+	case "subrip":
+		name = "subs.srt"
+		body = []byte(fmt.Sprintf("1\n00:00:00,000 --> 00:00:%02d,000\nSubtitles OK\n", clipDuration))
+	case "pgs":
+		name = "subs.sup"
+		body = pgsCaption("PGS OK", clipWidth, clipHeight, clipDuration)
+	default:
+		return "", fmt.Errorf("unsupported subtitle format %q", format)
+	}
+	path := filepath.Join(work, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func run(ctx context.Context, tool sandbox.Tool, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := sandbox.CommandContext(ctx, tool, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
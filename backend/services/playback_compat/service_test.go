@@ -0,0 +1,116 @@
+package playback_compat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestSummarize(t *testing.T) {
+	svc, err := NewService(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	test, err := svc.Summarize(map[string]bool{
+		"baseline":      true,
+		"video-hevc":    true,
+		"hdr-hdr10":     false,
+		"container-mkv": false,
+		"audio-truehd":  false,
+	})
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+
+	hevc := models.CompatFeature{Kind: models.CompatVideoCodec, Value: "hevc"}
+	hdr10 := models.CompatFeature{Kind: models.CompatHDRFormat, Value: "hdr10"}
+	mkv := models.CompatFeature{Kind: models.CompatContainer, Value: "mkv"}
+	truehd := models.CompatFeature{Kind: models.CompatAudioCodec, Value: "truehd"}
+
+	if !slices.Contains(test.Supported, hevc) {
+		t.Errorf("expected hevc to be supported, got %+v", test.Supported)
+	}
+	if !slices.Contains(test.Unsupported, hdr10) || !slices.Contains(test.Unsupported, mkv) {
+		t.Errorf("expected hdr10 and mkv to be unsupported, got %+v", test.Unsupported)
+	}
+	// TrueHD is tested in MKV, which didn't play, so its failure proves nothing
+	if slices.Contains(test.Unsupported, truehd) || slices.Contains(test.Supported, truehd) {
+		t.Errorf("expected truehd to be inconclusive, got supported %+v unsupported %+v", test.Supported, test.Unsupported)
+	}
+}
+
+func TestSummarizeRejectsBadResults(t *testing.T) {
+	svc, err := NewService(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	if _, err := svc.Summarize(map[string]bool{"baseline": true, "av1": true}); !errors.Is(err, ErrUnknownClip) {
+		t.Errorf("expected an unknown clip to be rejected, got %v", err)
+	}
+	if _, err := svc.Summarize(map[string]bool{"baseline": false, "video-hevc": false}); !errors.Is(err, ErrBaselineFailed) {
+		t.Errorf("expected a failed baseline to be rejected, got %v", err)
+	}
+}
+
+func TestRunLengthEncode(t *testing.T) {
+	var line []byte
+	line = append(line, 1, 1)                           // Raw pixels
+	line = append(line, make([]byte, 70)...)            // Long transparent run
+	line = append(line, bytes.Repeat([]byte{1}, 5)...)  // Short colour run
+	line = append(line, 0)                              // Short transparent run
+	line = append(line, bytes.Repeat([]byte{1}, 99)...) // Long colour run
+
+	got := runLengthEncode([][]byte{line})
+	want := []byte{
+		1, 1,
+		0, 0x40, 70,
+		0, 0x80 | 5, 1,
+		0, 1,
+		0, 0xC0, 99, 1,
+		0, 0,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("unexpected encoding\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestPGSCaptionSegments(t *testing.T) {
+	sup := pgsCaption("PGS OK", 1280, 720, 5)
+
+	var kinds []byte
+	var lastPTS uint32
+	for r := bytes.NewReader(sup); r.Len() > 0; {
+		var header struct {
+			Magic [2]byte
+			PTS   uint32
+			DTS   uint32
+			Kind  byte
+			Size  uint16
+		}
+		if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+			t.Fatalf("read segment header: %v", err)
+		}
+		if string(header.Magic[:]) != "PG" {
+			t.Fatalf("expected PG magic, got %q", header.Magic[:])
+		}
+		if _, err := r.Seek(int64(header.Size), 1); err != nil {
+			t.Fatalf("skip segment: %v", err)
+		}
+		kinds = append(kinds, header.Kind)
+		lastPTS = header.PTS
+	}
+
+	want := []byte{pgsComposition, pgsWindow, pgsPalette, pgsObject, pgsEnd, pgsComposition, pgsWindow, pgsEnd}
+	if !bytes.Equal(kinds, want) {
+		t.Errorf("unexpected segments % x, want % x", kinds, want)
+	}
+	if lastPTS != 5*90000 {
+		t.Errorf("expected the caption to clear at 5s, got PTS %d", lastPTS)
+	}
+}
@@ -0,0 +1,348 @@
+// Package playback_queue keeps a per-profile list of movies and episodes to
+// play back-to-back.
+package playback_queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrProfileIDRequired  = errors.New("profile id is required")
+	ErrTitleIDRequired    = errors.New("titleId is required")
+	ErrInvalidMediaType   = errors.New("mediaType must be movie or episode")
+	ErrEpisodeRequired    = errors.New("seasonNumber and episodeNumber are required for episodes")
+	ErrItemNotFound       = errors.New("queue item not found")
+	ErrInvalidOrder       = errors.New("itemIds must list every queued item exactly once")
+	ErrQueueFull          = errors.New("playback queue is full")
+)
+
+// maxItemsPerProfile bounds how long one profile's queue can get.
+const maxItemsPerProfile = 100
+
+// Service persists playback queues, backed by a JSON file on disk.
+type Service struct {
+	mu     sync.RWMutex
+	path   string
+	queues map[string][]models.PlaybackQueueItem // profile id -> ordered items
+	now    func() time.Time
+}
+
+// NewService constructs a playback queue service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create playback queue dir: %w", err)
+	}
+
+	svc := &Service{
+		path:   filepath.Join(storageDir, "playback_queues.json"),
+		queues: make(map[string][]models.PlaybackQueueItem),
+		now:    time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// List returns a profile's queue in play order.
+func (s *Service) List(profileID string) []models.PlaybackQueueItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	queue := s.queues[strings.TrimSpace(profileID)]
+	items := make([]models.PlaybackQueueItem, len(queue))
+	copy(items, queue)
+	return items
+}
+
+// Add queues an item for a profile, appending it unless a position is given.
+func (s *Service) Add(profileID string, req models.PlaybackQueueItemRequest) (*models.PlaybackQueueItem, error) {
+	profileID = strings.TrimSpace(profileID)
+	if profileID == "" {
+		return nil, ErrProfileIDRequired
+	}
+	item := models.PlaybackQueueItem{
+		MediaType:     strings.ToLower(strings.TrimSpace(req.MediaType)),
+		TitleID:       strings.TrimSpace(req.TitleID),
+		TitleName:     strings.TrimSpace(req.TitleName),
+		Year:          req.Year,
+		ImdbID:        strings.TrimSpace(req.ImdbID),
+		TVDBID:        req.TVDBID,
+		TMDBID:        req.TMDBID,
+		SeasonNumber:  req.SeasonNumber,
+		EpisodeNumber: req.EpisodeNumber,
+		EpisodeTitle:  strings.TrimSpace(req.EpisodeTitle),
+		PosterURL:     strings.TrimSpace(req.PosterURL),
+		BackdropURL:   strings.TrimSpace(req.BackdropURL),
+	}
+	if item.TitleID == "" {
+		return nil, ErrTitleIDRequired
+	}
+	switch item.MediaType {
+	case "movie":
+		item.SeasonNumber, item.EpisodeNumber = 0, 0
+	case "episode":
+		if item.SeasonNumber <= 0 || item.EpisodeNumber <= 0 {
+			return nil, ErrEpisodeRequired
+		}
+	default:
+		return nil, ErrInvalidMediaType
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[profileID]
+	if len(queue) >= maxItemsPerProfile {
+		return nil, ErrQueueFull
+	}
+
+	item.ID = uuid.NewString()
+	item.AddedAt = s.now().UTC()
+
+	position := len(queue)
+	if req.Position != nil && *req.Position >= 0 && *req.Position < len(queue) {
+		position = *req.Position
+	}
+	updated := make([]models.PlaybackQueueItem, 0, len(queue)+1)
+	updated = append(updated, queue[:position]...)
+	updated = append(updated, item)
+	updated = append(updated, queue[position:]...)
+
+	if err := s.replaceLocked(profileID, updated); err != nil {
+		return nil, err
+	}
+	log.Printf("[playback_queue] profile=%s queued %s %s at position %d", profileID, item.MediaType, item.TitleID, position)
+
+	return &item, nil
+}
+
+// Remove takes one item out of a profile's queue.
+func (s *Service) Remove(profileID, itemID string) error {
+	profileID = strings.TrimSpace(profileID)
+	itemID = strings.TrimSpace(itemID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[profileID]
+	index := indexOf(queue, itemID)
+	if index < 0 {
+		return ErrItemNotFound
+	}
+	updated := make([]models.PlaybackQueueItem, 0, len(queue)-1)
+	updated = append(updated, queue[:index]...)
+	updated = append(updated, queue[index+1:]...)
+	return s.replaceLocked(profileID, updated)
+}
+
+// Reorder sets the play order of a profile's queue.
+func (s *Service) Reorder(profileID string, itemIDs []string) ([]models.PlaybackQueueItem, error) {
+	profileID = strings.TrimSpace(profileID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[profileID]
+	if len(itemIDs) != len(queue) {
+		return nil, ErrInvalidOrder
+	}
+	byID := make(map[string]models.PlaybackQueueItem, len(queue))
+	for _, item := range queue {
+		byID[item.ID] = item
+	}
+	updated := make([]models.PlaybackQueueItem, 0, len(queue))
+	for _, id := range itemIDs {
+		item, ok := byID[strings.TrimSpace(id)]
+		if !ok {
+			return nil, ErrInvalidOrder
+		}
+		delete(byID, item.ID)
+		updated = append(updated, item)
+	}
+
+	if err := s.replaceLocked(profileID, updated); err != nil {
+		return nil, err
+	}
+	items := make([]models.PlaybackQueueItem, len(updated))
+	copy(items, updated)
+	return items, nil
+}
+
+// Clear empties a profile's queue.
+func (s *Service) Clear(profileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replaceLocked(strings.TrimSpace(profileID), nil)
+}
+
+// Next returns the item to play after currentItemID and how many items are
+// queued from it onwards. Items ahead of currentItemID have already been
+// played and are dropped. An empty currentItemID returns the head of the
+// queue. Returns nil when nothing follows.
+func (s *Service) Next(profileID, currentItemID string) (*models.PlaybackQueueItem, int, error) {
+	profileID = strings.TrimSpace(profileID)
+	currentItemID = strings.TrimSpace(currentItemID)
+	if profileID == "" {
+		return nil, 0, ErrProfileIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.queues[profileID]
+	if currentItemID != "" {
+		index := indexOf(queue, currentItemID)
+		if index < 0 {
+			return nil, 0, ErrItemNotFound
+		}
+		if index > 0 {
+			queue = append([]models.PlaybackQueueItem(nil), queue[index:]...)
+			if err := s.replaceLocked(profileID, queue); err != nil {
+				return nil, 0, err
+			}
+		}
+		// queue[0] is now the item playing
+		queue = queue[1:]
+	}
+	if len(queue) == 0 {
+		return nil, 0, nil
+	}
+	next := queue[0]
+	return &next, len(queue), nil
+}
+
+// ContinueWatchingCard returns the continue watching entry for a profile's
+// queue, or nil when it is empty.
+func (s *Service) ContinueWatchingCard(profileID string) *models.SeriesWatchState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	queue := s.queues[strings.TrimSpace(profileID)]
+	if len(queue) == 0 {
+		return nil
+	}
+	head := queue[0]
+	updatedAt := head.AddedAt
+	for _, item := range queue {
+		if item.AddedAt.After(updatedAt) {
+			updatedAt = item.AddedAt
+		}
+	}
+	card := &models.SeriesWatchState{
+		SeriesID:    "queue:" + strings.TrimSpace(profileID),
+		SeriesTitle: head.TitleName,
+		PosterURL:   head.PosterURL,
+		BackdropURL: head.BackdropURL,
+		Year:        head.Year,
+		UpdatedAt:   updatedAt,
+		Queue:       &models.PlaybackQueueCard{Next: head, ItemCount: len(queue)},
+	}
+	if head.MediaType == "episode" {
+		card.NextEpisode = &models.EpisodeReference{
+			SeasonNumber:  head.SeasonNumber,
+			EpisodeNumber: head.EpisodeNumber,
+			Title:         head.EpisodeTitle,
+		}
+	}
+	return card
+}
+
+func indexOf(queue []models.PlaybackQueueItem, itemID string) int {
+	for i, item := range queue {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceLocked swaps in a profile's queue and persists it, restoring the
+// previous queue if the write fails.
+// Must be called with s.mu held.
+func (s *Service) replaceLocked(profileID string, queue []models.PlaybackQueueItem) error {
+	previous, had := s.queues[profileID]
+	if len(queue) == 0 {
+		delete(s.queues, profileID)
+	} else {
+		s.queues[profileID] = queue
+	}
+	if err := s.saveLocked(); err != nil {
+		if had {
+			s.queues[profileID] = previous
+		} else {
+			delete(s.queues, profileID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open playback queues: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read playback queues: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var queues map[string][]models.PlaybackQueueItem
+	if err := json.Unmarshal(data, &queues); err != nil {
+		return fmt.Errorf("decode playback queues: %w", err)
+	}
+	for profileID, queue := range queues {
+		if len(queue) > 0 {
+			s.queues[profileID] = queue
+		}
+	}
+
+	log.Printf("[playback_queue] loaded queues for %d profiles", len(s.queues))
+	return nil
+}
+
+// saveLocked writes the queues to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.queues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode playback queues: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write playback queues: %w", err)
+	}
+	return nil
+}
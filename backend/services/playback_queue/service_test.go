@@ -0,0 +1,164 @@
+package playback_queue
+
+import (
+	"errors"
+	"testing"
+
+	"novastream/models"
+)
+
+func queueIDs(items []models.PlaybackQueueItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func mustAdd(t *testing.T, svc *Service, req models.PlaybackQueueItemRequest) *models.PlaybackQueueItem {
+	t.Helper()
+	item, err := svc.Add("alice", req)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	return item
+}
+
+func TestAddValidates(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  models.PlaybackQueueItemRequest
+		want error
+	}{
+		{"no title", models.PlaybackQueueItemRequest{MediaType: "movie"}, ErrTitleIDRequired},
+		{"bad media type", models.PlaybackQueueItemRequest{MediaType: "series", TitleID: "tmdb:tv:1"}, ErrInvalidMediaType},
+		{"episode without number", models.PlaybackQueueItemRequest{MediaType: "episode", TitleID: "tmdb:tv:1", SeasonNumber: 1}, ErrEpisodeRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Add("alice", tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("Add() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAtPositionAndReorder(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	a := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:1"})
+	b := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:2"})
+	zero := 0
+	c := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "episode", TitleID: "tmdb:tv:3", SeasonNumber: 1, EpisodeNumber: 2, Position: &zero})
+
+	got := queueIDs(svc.List("alice"))
+	if want := []string{c.ID, a.ID, b.ID}; !equalIDs(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+
+	if _, err := svc.Reorder("alice", []string{a.ID, b.ID}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("Reorder() with a missing item error = %v, want ErrInvalidOrder", err)
+	}
+	if _, err := svc.Reorder("alice", []string{a.ID, a.ID, b.ID}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("Reorder() with a duplicate error = %v, want ErrInvalidOrder", err)
+	}
+	if _, err := svc.Reorder("alice", []string{b.ID, c.ID, a.ID}); err != nil {
+		t.Fatalf("Reorder() error = %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	got = queueIDs(reloaded.List("alice"))
+	if want := []string{b.ID, c.ID, a.ID}; !equalIDs(got, want) {
+		t.Fatalf("List() after reload = %v, want %v", got, want)
+	}
+	if len(reloaded.List("bob")) != 0 {
+		t.Error("queue leaked to another profile")
+	}
+}
+
+func TestNextDropsPlayedItems(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	a := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:1"})
+	b := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:2"})
+	c := mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:3"})
+
+	next, remaining, err := svc.Next("alice", "")
+	if err != nil || next == nil || next.ID != a.ID || remaining != 3 {
+		t.Fatalf("Next() = %v, %d, %v, want head of queue", next, remaining, err)
+	}
+
+	// Playing a, up next is b
+	next, remaining, err = svc.Next("alice", a.ID)
+	if err != nil || next == nil || next.ID != b.ID || remaining != 2 {
+		t.Fatalf("Next(a) = %v, %d, %v, want b", next, remaining, err)
+	}
+
+	// Playing b, so a has been played
+	next, _, err = svc.Next("alice", b.ID)
+	if err != nil || next == nil || next.ID != c.ID {
+		t.Fatalf("Next(b) = %v, %v, want c", next, err)
+	}
+	if got := queueIDs(svc.List("alice")); !equalIDs(got, []string{b.ID, c.ID}) {
+		t.Fatalf("List() = %v, want played item dropped", got)
+	}
+
+	next, _, err = svc.Next("alice", c.ID)
+	if err != nil || next != nil {
+		t.Fatalf("Next(c) = %v, %v, want end of queue", next, err)
+	}
+	if _, _, err := svc.Next("alice", a.ID); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("Next() with a dropped item error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestContinueWatchingCard(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if card := svc.ContinueWatchingCard("alice"); card != nil {
+		t.Fatalf("ContinueWatchingCard() = %+v for an empty queue", card)
+	}
+
+	mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "episode", TitleID: "tmdb:tv:3", TitleName: "Show", SeasonNumber: 2, EpisodeNumber: 5})
+	mustAdd(t, svc, models.PlaybackQueueItemRequest{MediaType: "movie", TitleID: "tmdb:movie:1"})
+
+	card := svc.ContinueWatchingCard("alice")
+	if card == nil || card.Queue == nil {
+		t.Fatalf("ContinueWatchingCard() = %+v, want a queue card", card)
+	}
+	if card.Queue.ItemCount != 2 || card.SeriesTitle != "Show" {
+		t.Errorf("card = %+v, want head item and count", card)
+	}
+	if card.NextEpisode == nil || card.NextEpisode.SeasonNumber != 2 || card.NextEpisode.EpisodeNumber != 5 {
+		t.Errorf("card.NextEpisode = %+v, want S02E05", card.NextEpisode)
+	}
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
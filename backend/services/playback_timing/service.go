@@ -0,0 +1,360 @@
+// Package playback_timing records how long each stage of starting playback
+// takes (search, health check, resolve, probe, HLS warmup, first segment) per
+// attempt, so admins can attribute slow starts to a specific subsystem.
+package playback_timing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// maxAttempts bounds the attempts kept; percentiles are computed over them.
+const maxAttempts = 500
+
+// stageOrder is the order stages are reported in.
+var stageOrder = []string{
+	models.PlaybackStageSearch,
+	models.PlaybackStageHealthCheck,
+	models.PlaybackStageResolve,
+	models.PlaybackStageProbe,
+	models.PlaybackStageHLSWarmup,
+	models.PlaybackStageFirstSegment,
+	models.PlaybackStageTotal,
+}
+
+// sessionLink ties an HLS session to the attempt that created it.
+type sessionLink struct {
+	attemptID string
+	readyAt   time.Time
+}
+
+// Service stores per-attempt timing spans, backed by a JSON file on disk.
+// Spans are kept in memory while an attempt runs and persisted once it
+// finishes or its first segment is served.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	attempts []*models.PlaybackAttemptTiming // Oldest first
+	byID     map[string]*models.PlaybackAttemptTiming
+	sessions map[string]sessionLink
+	now      func() time.Time
+}
+
+// NewService constructs a playback timing store in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create playback timing dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "playback_timings.json"),
+		byID:     make(map[string]*models.PlaybackAttemptTiming),
+		sessions: make(map[string]sessionLink),
+		now:      time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// BeginAttempt starts timing a playback attempt.
+func (s *Service) BeginAttempt(attemptID, titleName, mediaType string) {
+	if s == nil || attemptID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byID[attemptID]; exists {
+		return
+	}
+	attempt := &models.PlaybackAttemptTiming{
+		ID:        attemptID,
+		TitleName: titleName,
+		MediaType: mediaType,
+		StartedAt: s.now().UTC(),
+		Outcome:   models.PlaybackAttemptPending,
+		Spans:     []models.PlaybackTimingSpan{},
+	}
+	s.attempts = append(s.attempts, attempt)
+	s.byID[attemptID] = attempt
+	s.trimLocked()
+}
+
+// RecordSpan records that stage ran from startedAt until now.
+func (s *Service) RecordSpan(attemptID, stage string, startedAt time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.byID[attemptID]
+	if !ok {
+		return
+	}
+	attempt.Spans = append(attempt.Spans, models.PlaybackTimingSpan{
+		Stage:      stage,
+		StartedAt:  startedAt.UTC(),
+		DurationMs: s.now().Sub(startedAt).Milliseconds(),
+	})
+}
+
+// LinkHLSSession ties an HLS session to an attempt, so its first segment
+// request can be timed. The first segment span starts now.
+func (s *Service) LinkHLSSession(attemptID, sessionID string) {
+	if s == nil || sessionID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.byID[attemptID]
+	if !ok {
+		return
+	}
+	attempt.HLSSessionID = sessionID
+	s.sessions[sessionID] = sessionLink{attemptID: attemptID, readyAt: s.now()}
+}
+
+// FinishAttempt records an attempt's outcome and its total time.
+func (s *Service) FinishAttempt(attemptID, outcome, errMsg string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.byID[attemptID]
+	if !ok || attempt.Outcome != models.PlaybackAttemptPending {
+		return
+	}
+	attempt.Outcome = outcome
+	if len(errMsg) > 512 {
+		errMsg = errMsg[:512]
+	}
+	attempt.Error = errMsg
+	if outcome == models.PlaybackAttemptReady {
+		attempt.ReadyAt = s.now().UTC()
+		attempt.Spans = append(attempt.Spans, models.PlaybackTimingSpan{
+			Stage:      models.PlaybackStageTotal,
+			StartedAt:  attempt.StartedAt,
+			DurationMs: attempt.ReadyAt.Sub(attempt.StartedAt).Milliseconds(),
+		})
+	}
+
+	log.Printf("[playback-timing] attempt %s %s: %s", attempt.ID, outcome, formatSpans(attempt.Spans))
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[playback-timing] failed to persist timings: %v", err)
+	}
+}
+
+// FirstSegmentServed records the first segment span for the attempt that
+// created sessionID, if any. Later calls for the same session are ignored.
+func (s *Service) FirstSegmentServed(sessionID string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.sessions[sessionID]
+	if !ok {
+		return
+	}
+	delete(s.sessions, sessionID)
+
+	attempt, ok := s.byID[link.attemptID]
+	if !ok {
+		return
+	}
+	attempt.Spans = append(attempt.Spans, models.PlaybackTimingSpan{
+		Stage:      models.PlaybackStageFirstSegment,
+		StartedAt:  link.readyAt.UTC(),
+		DurationMs: s.now().Sub(link.readyAt).Milliseconds(),
+	})
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[playback-timing] failed to persist timings: %v", err)
+	}
+}
+
+// Summary returns p50/p95 durations per stage over the stored attempts and
+// up to limit recent attempts, newest first.
+func (s *Service) Summary(limit int) *models.PlaybackTimingSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	durations := make(map[string][]int64)
+	summary := &models.PlaybackTimingSummary{
+		Stages: make([]models.PlaybackStageStats, 0, len(stageOrder)),
+		Recent: make([]models.PlaybackAttemptTiming, 0),
+	}
+	for _, attempt := range s.attempts {
+		if attempt.Outcome == models.PlaybackAttemptPending {
+			continue
+		}
+		summary.Attempts++
+		if attempt.Outcome == models.PlaybackAttemptFailed {
+			summary.Failed++
+		}
+		for _, span := range attempt.Spans {
+			durations[span.Stage] = append(durations[span.Stage], span.DurationMs)
+		}
+	}
+
+	for _, stage := range stageOrder {
+		values := durations[stage]
+		if len(values) == 0 {
+			continue
+		}
+		summary.Stages = append(summary.Stages, stageStats(stage, values))
+	}
+
+	for i := len(s.attempts) - 1; i >= 0 && (limit <= 0 || len(summary.Recent) < limit); i-- {
+		entry := *s.attempts[i]
+		entry.Spans = append([]models.PlaybackTimingSpan(nil), entry.Spans...)
+		summary.Recent = append(summary.Recent, entry)
+	}
+	return summary
+}
+
+// Reset clears all recorded timings.
+func (s *Service) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = nil
+	s.byID = make(map[string]*models.PlaybackAttemptTiming)
+	s.sessions = make(map[string]sessionLink)
+	return s.saveLocked()
+}
+
+func stageStats(stage string, values []int64) models.PlaybackStageStats {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+	return models.PlaybackStageStats{
+		Stage: stage,
+		Count: len(values),
+		P50Ms: percentile(values, 0.50),
+		P95Ms: percentile(values, 0.95),
+		MaxMs: values[len(values)-1],
+		AvgMs: total / int64(len(values)),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func formatSpans(spans []models.PlaybackTimingSpan) string {
+	parts := make([]string, 0, len(spans))
+	for _, span := range spans {
+		parts = append(parts, fmt.Sprintf("%s=%dms", span.Stage, span.DurationMs))
+	}
+	return strings.Join(parts, " ")
+}
+
+// trimLocked drops the oldest attempts beyond maxAttempts.
+// Must be called with s.mu held.
+func (s *Service) trimLocked() {
+	if len(s.attempts) <= maxAttempts {
+		return
+	}
+	dropped := s.attempts[:len(s.attempts)-maxAttempts]
+	for _, attempt := range dropped {
+		delete(s.byID, attempt.ID)
+		if attempt.HLSSessionID != "" {
+			delete(s.sessions, attempt.HLSSessionID)
+		}
+	}
+	s.attempts = append([]*models.PlaybackAttemptTiming(nil), s.attempts[len(s.attempts)-maxAttempts:]...)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open playback timings: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read playback timings: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var attempts []*models.PlaybackAttemptTiming
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return fmt.Errorf("decode playback timings: %w", err)
+	}
+	for _, attempt := range attempts {
+		if attempt == nil || attempt.ID == "" {
+			continue
+		}
+		// Attempts interrupted by a restart never finished
+		if attempt.Outcome == models.PlaybackAttemptPending {
+			attempt.Outcome = models.PlaybackAttemptFailed
+			attempt.Error = "interrupted by restart"
+		}
+		s.attempts = append(s.attempts, attempt)
+		s.byID[attempt.ID] = attempt
+	}
+	s.trimLocked()
+	return nil
+}
+
+// saveLocked writes the attempts to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.attempts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode playback timings: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write playback timings: %w", err)
+	}
+	return nil
+}
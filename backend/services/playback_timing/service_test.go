@@ -0,0 +1,83 @@
+package playback_timing
+
+import (
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestSummaryReportsStagePercentiles(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return clock }
+
+	// Ten attempts whose search takes 1..10s and probe a steady 500ms
+	for i := 1; i <= 10; i++ {
+		id := string(rune('a' + i))
+		svc.BeginAttempt(id, "Title", "movie")
+		start := clock
+		clock = clock.Add(time.Duration(i) * time.Second)
+		svc.RecordSpan(id, models.PlaybackStageSearch, start)
+		start = clock
+		clock = clock.Add(500 * time.Millisecond)
+		svc.RecordSpan(id, models.PlaybackStageProbe, start)
+		svc.LinkHLSSession(id, "hls-"+id)
+		svc.FinishAttempt(id, models.PlaybackAttemptReady, "")
+		clock = clock.Add(2 * time.Second)
+		svc.FirstSegmentServed("hls-" + id)
+		svc.FirstSegmentServed("hls-" + id) // Later segments are ignored
+	}
+	svc.BeginAttempt("failed", "Other", "series")
+	svc.FinishAttempt("failed", models.PlaybackAttemptFailed, "no results")
+	svc.BeginAttempt("running", "Pending", "movie")
+
+	summary := svc.Summary(3)
+	if summary.Attempts != 11 || summary.Failed != 1 {
+		t.Fatalf("expected 11 finished attempts with 1 failure, got %+v", summary)
+	}
+
+	stages := make(map[string]models.PlaybackStageStats)
+	for _, stage := range summary.Stages {
+		stages[stage.Stage] = stage
+	}
+	if search := stages[models.PlaybackStageSearch]; search.Count != 10 || search.P50Ms != 5000 || search.P95Ms != 10000 || search.MaxMs != 10000 {
+		t.Fatalf("unexpected search stats %+v", search)
+	}
+	if probe := stages[models.PlaybackStageProbe]; probe.P50Ms != 500 || probe.P95Ms != 500 {
+		t.Fatalf("unexpected probe stats %+v", probe)
+	}
+	if first := stages[models.PlaybackStageFirstSegment]; first.Count != 10 || first.P50Ms != 2000 {
+		t.Fatalf("unexpected first segment stats %+v", first)
+	}
+	if total := stages[models.PlaybackStageTotal]; total.Count != 10 || total.MaxMs != 10500 {
+		t.Fatalf("unexpected total stats %+v", total)
+	}
+	if summary.Stages[0].Stage != models.PlaybackStageSearch {
+		t.Fatalf("expected stages in start sequence order, got %+v", summary.Stages)
+	}
+	if len(summary.Recent) != 3 || summary.Recent[0].ID != "running" {
+		t.Fatalf("expected newest attempts first, got %+v", summary.Recent)
+	}
+
+	// Reload: finished attempts persist
+	svc, err = NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if summary := svc.Summary(0); summary.Attempts != 11 || summary.Failed != 1 {
+		t.Fatalf("expected persisted attempts after reload, got attempts=%d failed=%d", summary.Attempts, summary.Failed)
+	}
+
+	if err := svc.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if summary := svc.Summary(0); summary.Attempts != 0 || len(summary.Stages) != 0 {
+		t.Fatalf("expected empty summary after reset, got %+v", summary)
+	}
+}
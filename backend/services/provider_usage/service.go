@@ -0,0 +1,619 @@
+package provider_usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/pool"
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+const (
+	// How often usenet traffic is sampled, quotas are checked and usage is saved
+	checkInterval = time.Minute
+
+	// warnThreshold is the share of a quota at which a warning is logged
+	warnThreshold = 0.8
+
+	// Retention of the daily and monthly history
+	dailyRetention   = 90
+	monthlyRetention = 24
+
+	// Length of the chart series returned by Summaries
+	dailySeriesLength   = 30
+	monthlySeriesLength = 12
+
+	bytesPerGB = 1 << 30
+
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// Service tracks the traffic consumed per debrid account and per usenet
+// provider, persisted per day and month, and enforces the optional monthly
+// quotas configured on each.
+//
+// Debrid traffic is counted exactly as it is streamed. Usenet traffic is
+// counted exactly for the pool as a whole, but nntppool does not report which
+// provider served an article, so each sample is split between providers in
+// proportion to the connections acquired from each. With a single provider,
+// or one primary provider and rarely used backups, this is exact or close to it.
+type Service struct {
+	mu      sync.Mutex
+	path    string
+	cfg     *config.Manager
+	records map[string]*models.ProviderUsageRecord
+	dirty   bool
+	now     func() time.Time
+
+	poolManager  pool.Manager
+	lastFetched  int64
+	lastAcquires map[string]int64
+	suspended    map[string]bool // usenet providers taken out of the pool for exceeding their quota
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs a provider usage tracker backed by a JSON file on disk.
+func NewService(storageDir string, cfg *config.Manager) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create provider usage dir: %w", err)
+	}
+
+	svc := &Service{
+		path:         filepath.Join(storageDir, "provider_usage.json"),
+		cfg:          cfg,
+		records:      make(map[string]*models.ProviderUsageRecord),
+		now:          time.Now,
+		lastAcquires: make(map[string]int64),
+		suspended:    make(map[string]bool),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// SetPoolManager enables usenet traffic sampling and quota enforcement.
+func (s *Service) SetPoolManager(pm pool.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.poolManager = pm
+	if reporter, ok := pm.(pool.UsageReporter); ok {
+		s.lastFetched = reporter.FetchedBytes()
+	}
+}
+
+// RecordDebrid adds bytes streamed from a debrid account.
+func (s *Service) RecordDebrid(account string, bytes int64) {
+	s.record(models.ProviderKindDebrid, account, bytes)
+}
+
+// DebridQuotaExceeded reports whether a debrid account has used up its
+// monthly quota.
+func (s *Service) DebridQuotaExceeded(account string) bool {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return false
+	}
+	for _, provider := range settings.Streaming.DebridProviders {
+		if provider.AccountName() == account {
+			return s.quotaExceeded(models.ProviderKindDebrid, account, provider.MonthlyQuotaGB)
+		}
+	}
+	return false
+}
+
+// Summaries returns the usage of every configured provider, followed by any
+// with recorded history that are no longer configured.
+func (s *Service) Summaries() ([]models.ProviderUsageSummary, error) {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load settings: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	seen := make(map[string]bool)
+	summaries := make([]models.ProviderUsageSummary, 0, len(s.records))
+	add := func(kind, name string, quotaGB int, configured bool) {
+		key := recordKey(kind, name)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		summaries = append(summaries, summarize(kind, name, s.records[key], quotaGB, configured, now))
+	}
+
+	for _, provider := range settings.Streaming.DebridProviders {
+		add(models.ProviderKindDebrid, provider.AccountName(), provider.MonthlyQuotaGB, true)
+	}
+	for _, provider := range settings.Usenet {
+		add(models.ProviderKindUsenet, provider.AccountName(), provider.MonthlyQuotaGB, true)
+	}
+
+	var removed []*models.ProviderUsageRecord
+	for key, record := range s.records {
+		if !seen[key] {
+			removed = append(removed, record)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return recordKey(removed[i].Kind, removed[i].Name) < recordKey(removed[j].Kind, removed[j].Name)
+	})
+	for _, record := range removed {
+		add(record.Kind, record.Name, 0, false)
+	}
+
+	return summaries, nil
+}
+
+// Start launches the background loop that samples usenet traffic, checks
+// quotas and saves usage.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.checkLoop(loopCtx)
+
+	log.Printf("[provider_usage] usage tracking started (%d providers with history)", len(s.records))
+}
+
+// Stop ends the background loop and saves pending usage.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[provider_usage] failed to persist usage: %v", err)
+	}
+}
+
+func (s *Service) checkLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check runs one pass of the background loop.
+func (s *Service) check() {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		log.Printf("[provider_usage] failed to load settings: %v", err)
+		return
+	}
+
+	s.sampleUsenet(settings.Usenet)
+	s.checkQuotas(settings)
+	s.enforceUsenetQuotas(settings.Usenet)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[provider_usage] failed to persist usage: %v", err)
+	}
+}
+
+// sampleUsenet attributes the article bytes fetched since the last sample to
+// the providers in the pool, weighted by the connections acquired from each.
+func (s *Service) sampleUsenet(providers []config.UsenetSettings) {
+	s.mu.Lock()
+	pm := s.poolManager
+	s.mu.Unlock()
+
+	reporter, ok := pm.(pool.UsageReporter)
+	if !ok {
+		return
+	}
+	fetched := reporter.FetchedBytes()
+
+	// Acquire counts are sampled even without new traffic so the next split
+	// only weighs connections acquired since this one
+	weights := make(map[string]int64)
+	if cp, err := pm.GetPool(); err == nil {
+		s.mu.Lock()
+		current := make(map[string]int64)
+		for _, provider := range cp.GetMetricsSnapshot().ProviderMetrics {
+			key := usenetKey(provider.Host, provider.Username)
+			current[key] = provider.AcquireCount
+			delta := provider.AcquireCount - s.lastAcquires[key]
+			if delta < 0 {
+				// Pool was recreated and its counters reset
+				delta = provider.AcquireCount
+			}
+			weights[key] = delta
+		}
+		s.lastAcquires = current
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	delta := fetched - s.lastFetched
+	s.lastFetched = fetched
+	s.mu.Unlock()
+	if delta <= 0 || len(weights) == 0 {
+		return
+	}
+
+	names := make(map[string]string, len(providers))
+	for _, provider := range providers {
+		names[usenetKey(provider.Host, provider.Username)] = provider.AccountName()
+	}
+	for key, bytes := range splitByWeight(delta, weights) {
+		name, ok := names[key]
+		if !ok {
+			name, _, _ = strings.Cut(key, "|")
+		}
+		s.record(models.ProviderKindUsenet, name, bytes)
+	}
+}
+
+// checkQuotas logs a warning once per month when a provider passes
+// warnThreshold of its quota, and again when it exceeds it.
+func (s *Service) checkQuotas(settings config.Settings) {
+	type quota struct {
+		kind, name string
+		gb         int
+	}
+	var quotas []quota
+	for _, provider := range settings.Streaming.DebridProviders {
+		quotas = append(quotas, quota{models.ProviderKindDebrid, provider.AccountName(), provider.MonthlyQuotaGB})
+	}
+	for _, provider := range settings.Usenet {
+		quotas = append(quotas, quota{models.ProviderKindUsenet, provider.AccountName(), provider.MonthlyQuotaGB})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	month := s.now().UTC().Format(monthLayout)
+	for _, q := range quotas {
+		if q.gb <= 0 {
+			continue
+		}
+		record := s.records[recordKey(q.kind, q.name)]
+		if record == nil {
+			continue
+		}
+		used := record.Monthly[month]
+		limit := int64(q.gb) * bytesPerGB
+		switch {
+		case used >= limit && record.ExceededMonth != month:
+			record.ExceededMonth = month
+			record.WarnedMonth = month
+			s.dirty = true
+			log.Printf("[provider_usage] WARNING: %s provider %q exceeded its monthly quota (%s of %d GB)", q.kind, q.name, formatGB(used), q.gb)
+		case float64(used) >= warnThreshold*float64(limit) && record.WarnedMonth != month:
+			record.WarnedMonth = month
+			s.dirty = true
+			log.Printf("[provider_usage] WARNING: %s provider %q has used %.0f%% of its monthly quota (%s of %d GB)", q.kind, q.name, 100*float64(used)/float64(limit), formatGB(used), q.gb)
+		}
+	}
+}
+
+// enforceUsenetQuotas recreates the pool without providers over their quota,
+// and restores them once they are back under it (new month or raised quota).
+// A settings reload that re-adds an exhausted provider is undone on the next pass.
+func (s *Service) enforceUsenetQuotas(providers []config.UsenetSettings) {
+	s.mu.Lock()
+	pm := s.poolManager
+	s.mu.Unlock()
+	if pm == nil {
+		return
+	}
+
+	exceeded := make(map[string]bool)
+	allowed := make([]config.UsenetSettings, 0, len(providers))
+	for _, provider := range providers {
+		if provider.Enabled && s.quotaExceeded(models.ProviderKindUsenet, provider.AccountName(), provider.MonthlyQuotaGB) {
+			exceeded[usenetKey(provider.Host, provider.Username)] = true
+			continue
+		}
+		allowed = append(allowed, provider)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := len(exceeded) != len(s.suspended)
+	for key := range exceeded {
+		if !s.suspended[key] {
+			changed = true
+		}
+	}
+	inPool := false
+	if cp, err := pm.GetPool(); err == nil {
+		for _, info := range cp.GetProvidersInfo() {
+			if exceeded[usenetKey(info.Host, info.Username)] {
+				inPool = true
+			}
+		}
+	}
+	if !changed && !inPool {
+		return
+	}
+
+	if err := pm.SetProviders(config.ToNNTPProviders(allowed), config.NNTPBindings(allowed)); err != nil {
+		log.Printf("[provider_usage] failed to apply usenet quotas to the pool: %v", err)
+		return
+	}
+	s.suspended = exceeded
+	log.Printf("[provider_usage] usenet pool updated: %d providers suspended for exceeding their monthly quota", len(exceeded))
+}
+
+// quotaExceeded reports whether a provider's usage this month has reached quotaGB.
+func (s *Service) quotaExceeded(kind, name string, quotaGB int) bool {
+	if quotaGB <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[recordKey(kind, name)]
+	if record == nil {
+		return false
+	}
+	return record.Monthly[s.now().UTC().Format(monthLayout)] >= int64(quotaGB)*bytesPerGB
+}
+
+func (s *Service) record(kind, name string, bytes int64) {
+	name = strings.TrimSpace(name)
+	if name == "" || bytes <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recordKey(kind, name)
+	record := s.records[key]
+	if record == nil {
+		record = &models.ProviderUsageRecord{
+			Kind:    kind,
+			Name:    name,
+			Daily:   make(map[string]int64),
+			Monthly: make(map[string]int64),
+		}
+		s.records[key] = record
+	}
+
+	now := s.now().UTC()
+	record.Daily[now.Format(dayLayout)] += bytes
+	record.Monthly[now.Format(monthLayout)] += bytes
+	record.UpdatedAt = now
+	s.dirty = true
+}
+
+// summarize builds a provider's summary as of now.
+func summarize(kind, name string, record *models.ProviderUsageRecord, quotaGB int, configured bool, now time.Time) models.ProviderUsageSummary {
+	now = now.UTC()
+	summary := models.ProviderUsageSummary{
+		Kind:       kind,
+		Name:       name,
+		Configured: configured,
+		Status:     models.ProviderQuotaOK,
+		Daily:      make([]models.ProviderUsagePoint, 0, dailySeriesLength),
+		Monthly:    make([]models.ProviderUsagePoint, 0, monthlySeriesLength),
+	}
+
+	var daily, monthly map[string]int64
+	if record != nil {
+		daily, monthly = record.Daily, record.Monthly
+	}
+	for i := dailySeriesLength - 1; i >= 0; i-- {
+		day := now.AddDate(0, 0, -i).Format(dayLayout)
+		summary.Daily = append(summary.Daily, models.ProviderUsagePoint{Period: day, Bytes: daily[day]})
+	}
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := monthlySeriesLength - 1; i >= 0; i-- {
+		month := firstOfMonth.AddDate(0, -i, 0).Format(monthLayout)
+		summary.Monthly = append(summary.Monthly, models.ProviderUsagePoint{Period: month, Bytes: monthly[month]})
+	}
+
+	summary.MonthBytes = monthly[now.Format(monthLayout)]
+	if quotaGB > 0 {
+		summary.QuotaBytes = int64(quotaGB) * bytesPerGB
+		summary.PercentUsed = 100 * float64(summary.MonthBytes) / float64(summary.QuotaBytes)
+		switch {
+		case summary.MonthBytes >= summary.QuotaBytes:
+			summary.Status = models.ProviderQuotaExceeded
+		case float64(summary.MonthBytes) >= warnThreshold*float64(summary.QuotaBytes):
+			summary.Status = models.ProviderQuotaWarning
+		}
+	}
+	return summary
+}
+
+// splitByWeight divides total between keys in proportion to their weights,
+// evenly when no key has weight. Rounding leftovers go to the heaviest key so
+// the parts always sum to total.
+func splitByWeight(total int64, weights map[string]int64) map[string]int64 {
+	keys := make([]string, 0, len(weights))
+	var sum int64
+	for key, weight := range weights {
+		keys = append(keys, key)
+		if weight > 0 {
+			sum += weight
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if weights[keys[i]] != weights[keys[j]] {
+			return weights[keys[i]] > weights[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make(map[string]int64, len(keys))
+	var assigned int64
+	for _, key := range keys {
+		var part int64
+		if sum == 0 {
+			part = total / int64(len(keys))
+		} else if weights[key] > 0 {
+			part = int64(float64(total) * float64(weights[key]) / float64(sum))
+		}
+		parts[key] = part
+		assigned += part
+	}
+	parts[keys[0]] += total - assigned
+	for key, part := range parts {
+		if part <= 0 {
+			delete(parts, key)
+		}
+	}
+	return parts
+}
+
+func recordKey(kind, name string) string {
+	return kind + ":" + name
+}
+
+func usenetKey(host, username string) string {
+	return host + "|" + username
+}
+
+func formatGB(bytes int64) string {
+	return fmt.Sprintf("%.1f GB", float64(bytes)/bytesPerGB)
+}
+
+// pruneLocked drops history older than the retention windows.
+// Must be called with s.mu held.
+func (s *Service) pruneLocked() {
+	now := s.now().UTC()
+	oldestDay := now.AddDate(0, 0, -dailyRetention).Format(dayLayout)
+	oldestMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -monthlyRetention, 0).Format(monthLayout)
+	for _, record := range s.records {
+		for day := range record.Daily {
+			if day < oldestDay {
+				delete(record.Daily, day)
+			}
+		}
+		for month := range record.Monthly {
+			if month < oldestMonth {
+				delete(record.Monthly, month)
+			}
+		}
+	}
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open provider usage: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read provider usage: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var records []models.ProviderUsageRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("decode provider usage: %w", err)
+	}
+	for i := range records {
+		record := records[i]
+		if record.Daily == nil {
+			record.Daily = make(map[string]int64)
+		}
+		if record.Monthly == nil {
+			record.Monthly = make(map[string]int64)
+		}
+		s.records[recordKey(record.Kind, record.Name)] = &record
+	}
+	return nil
+}
+
+// saveLocked writes usage to disk if it changed since the last save.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	if !s.dirty {
+		return nil
+	}
+	s.pruneLocked()
+
+	records := make([]models.ProviderUsageRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return recordKey(records[i].Kind, records[i].Name) < recordKey(records[j].Kind, records[j].Name)
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode provider usage: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write provider usage: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,125 @@
+package provider_usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+func newTestService(t *testing.T, settings config.Settings) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := cfg.Save(settings); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	svc, err := NewService(dir, cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return svc
+}
+
+func TestDebridQuotaAndSummary(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.Streaming.DebridProviders = []config.DebridProviderSettings{
+		{Name: "RD main", Provider: "realdebrid", Enabled: true, MonthlyQuotaGB: 10},
+		{Provider: "torbox", Enabled: true},
+	}
+	svc := newTestService(t, settings)
+	now := time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	svc.RecordDebrid("RD main", 8*bytesPerGB)
+	svc.checkQuotas(settings)
+	if svc.DebridQuotaExceeded("RD main") {
+		t.Fatal("account under quota reported as exceeded")
+	}
+	if got := svc.records[recordKey(models.ProviderKindDebrid, "RD main")].WarnedMonth; got != "2026-03" {
+		t.Fatalf("WarnedMonth = %q, want 2026-03", got)
+	}
+
+	svc.RecordDebrid("RD main", 2*bytesPerGB)
+	if !svc.DebridQuotaExceeded("RD main") {
+		t.Fatal("account at quota not reported as exceeded")
+	}
+
+	summaries, err := svc.Summaries()
+	if err != nil {
+		t.Fatalf("Summaries() error = %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(summaries))
+	}
+	rd := summaries[0]
+	if rd.Status != models.ProviderQuotaExceeded || rd.MonthBytes != 10*bytesPerGB || rd.PercentUsed != 100 {
+		t.Fatalf("unexpected summary %+v", rd)
+	}
+	if len(rd.Daily) != dailySeriesLength || rd.Daily[len(rd.Daily)-1].Bytes != 10*bytesPerGB {
+		t.Fatalf("unexpected daily series %+v", rd.Daily)
+	}
+	if len(rd.Monthly) != monthlySeriesLength || rd.Monthly[len(rd.Monthly)-1].Period != "2026-03" {
+		t.Fatalf("unexpected monthly series %+v", rd.Monthly)
+	}
+	if tb := summaries[1]; tb.Name != "torbox" || tb.Status != models.ProviderQuotaOK || tb.MonthBytes != 0 {
+		t.Fatalf("unexpected summary for unnamed account %+v", tb)
+	}
+
+	// A new month starts from zero
+	now = now.Add(3 * time.Hour)
+	if svc.DebridQuotaExceeded("RD main") {
+		t.Fatal("quota still exceeded in the following month")
+	}
+}
+
+func TestUsagePersistsAcrossRestart(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.Usenet = []config.UsenetSettings{{Name: "Block", Host: "news.example.com", Enabled: true}}
+	svc := newTestService(t, settings)
+
+	svc.record(models.ProviderKindUsenet, "Block", 1234)
+	svc.mu.Lock()
+	if err := svc.saveLocked(); err != nil {
+		t.Fatalf("saveLocked() error = %v", err)
+	}
+	svc.mu.Unlock()
+
+	reloaded, err := NewService(filepath.Dir(svc.path), svc.cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	summaries, err := reloaded.Summaries()
+	if err != nil {
+		t.Fatalf("Summaries() error = %v", err)
+	}
+	for _, summary := range summaries {
+		if summary.Kind == models.ProviderKindUsenet && summary.Name == "Block" {
+			if summary.MonthBytes != 1234 {
+				t.Fatalf("MonthBytes after reload = %d, want 1234", summary.MonthBytes)
+			}
+			return
+		}
+	}
+	t.Fatal("usenet provider missing from summaries after reload")
+}
+
+func TestSplitByWeight(t *testing.T) {
+	parts := splitByWeight(1000, map[string]int64{"a": 3, "b": 1, "c": 0})
+	if parts["a"] != 750 || parts["b"] != 250 || parts["c"] != 0 {
+		t.Fatalf("unexpected split %v", parts)
+	}
+
+	parts = splitByWeight(1001, map[string]int64{"a": 1, "b": 1, "c": 1})
+	if sum := parts["a"] + parts["b"] + parts["c"]; sum != 1001 {
+		t.Fatalf("parts sum to %d, want 1001 (%v)", sum, parts)
+	}
+
+	// No acquires since the last sample: split evenly
+	parts = splitByWeight(100, map[string]int64{"a": 0, "b": 0})
+	if parts["a"] != 50 || parts["b"] != 50 {
+		t.Fatalf("unexpected even split %v", parts)
+	}
+}
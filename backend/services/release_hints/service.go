@@ -0,0 +1,240 @@
+// Package release_hints keeps a Bloom filter of the titles that indexer
+// searches recently returned releases for, so browse screens can show a
+// "likely available" hint without searching for every card.
+package release_hints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"novastream/models"
+	"novastream/utils/bloom"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+const (
+	// window is how long a title stays likely after releases were last seen.
+	window = 14 * 24 * time.Hour
+	// maxKeys bounds the index; the least recently seen keys go first.
+	maxKeys = 200000
+	// falsePositiveRate is the filter's target rate at full capacity.
+	falsePositiveRate = 0.01
+	// minCapacity leaves room for keys recorded between rebuilds.
+	minCapacity = 1024
+)
+
+// Service records titles with releases and answers hint queries from a
+// filter rebuilt periodically from the recent keys.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	seen     map[string]time.Time // Key -> last seen
+	capacity int                  // Keys the current filter was sized for
+	filter   atomic.Pointer[bloom.Filter]
+	now      func() time.Time
+}
+
+// NewService constructs a hint index persisted in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create release hints dir: %w", err)
+	}
+
+	svc := &Service{
+		path: filepath.Join(storageDir, "release_hints.json"),
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	svc.mu.Lock()
+	svc.rebuildLocked()
+	svc.mu.Unlock()
+	return svc, nil
+}
+
+// RecordReleases indexes a search that returned releases. imdbID, title and
+// year describe what was searched for; releases may carry the IMDB ID too.
+func (s *Service) RecordReleases(imdbID, title string, year int, releases []models.NZBResult) {
+	if s == nil || len(releases) == 0 {
+		return
+	}
+
+	keys := titleKeys(imdbID, title, year)
+	for _, release := range releases {
+		for _, attr := range []string{"imdb", "imdbid"} {
+			if key := imdbKey(release.Attributes[attr]); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	filter := s.filter.Load()
+	for _, key := range keys {
+		s.seen[key] = now
+		filter.Add(key)
+	}
+	if len(s.seen) > s.capacity {
+		s.rebuildLocked()
+	}
+}
+
+// Check returns a hint for each query, in order.
+func (s *Service) Check(queries []models.ReleaseHintQuery) []models.ReleaseHint {
+	filter := s.filter.Load()
+	hints := make([]models.ReleaseHint, 0, len(queries))
+	for _, query := range queries {
+		hints = append(hints, models.ReleaseHint{ID: query.ID, Likely: likely(filter, query)})
+	}
+	return hints
+}
+
+// Rebuild drops keys not seen within the window and rebuilds the filter from
+// the rest, clearing stale bits and false positives that built up; it is the
+// scheduled job entry point.
+func (s *Service) Rebuild(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-window)
+	for key, seenAt := range s.seen {
+		if seenAt.Before(cutoff) {
+			delete(s.seen, key)
+		}
+	}
+	s.rebuildLocked()
+	return s.saveLocked()
+}
+
+func (s *Service) rebuildLocked() {
+	if len(s.seen) > maxKeys {
+		keys := make([]string, 0, len(s.seen))
+		for key := range s.seen {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return s.seen[keys[i]].After(s.seen[keys[j]]) })
+		for _, key := range keys[maxKeys:] {
+			delete(s.seen, key)
+		}
+	}
+
+	// Twice the current keys, so the filter stays near its target rate
+	// until the next rebuild
+	s.capacity = max(2*len(s.seen), minCapacity)
+	filter := bloom.New(s.capacity, falsePositiveRate)
+	for key := range s.seen {
+		filter.Add(key)
+	}
+	s.filter.Store(filter)
+}
+
+func likely(filter *bloom.Filter, query models.ReleaseHintQuery) bool {
+	if key := imdbKey(query.IMDBID); key != "" && filter.Test(key) {
+		return true
+	}
+	name := normalizeTitle(query.Title)
+	if name == "" {
+		return false
+	}
+	// Series are searched by episode without a year
+	if query.Year > 0 && !strings.EqualFold(query.MediaType, "series") {
+		return filter.Test(titleYearKey(name, query.Year))
+	}
+	return filter.Test("title:" + name)
+}
+
+func titleKeys(imdbID, title string, year int) []string {
+	var keys []string
+	if key := imdbKey(imdbID); key != "" {
+		keys = append(keys, key)
+	}
+	if name := normalizeTitle(title); name != "" {
+		keys = append(keys, "title:"+name)
+		if year > 0 {
+			keys = append(keys, titleYearKey(name, year))
+		}
+	}
+	return keys
+}
+
+func titleYearKey(name string, year int) string {
+	return "title:" + name + ":" + strconv.Itoa(year)
+}
+
+// imdbKey normalizes "tt0111161" and the bare "0111161" indexers report.
+func imdbKey(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	id = strings.TrimPrefix(id, "tt")
+	if id == "" || strings.Trim(id, "0123456789") != "" || strings.Trim(id, "0") == "" {
+		return ""
+	}
+	return "imdb:tt" + id
+}
+
+// normalizeTitle lowercases a title and keeps its letters and digits, so
+// punctuation and spacing differences between metadata and queries match.
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (s *Service) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read release hints: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.seen); err != nil {
+		return fmt.Errorf("decode release hints: %w", err)
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]time.Time)
+	}
+	return nil
+}
+
+func (s *Service) saveLocked() error {
+	data, err := json.Marshal(s.seen)
+	if err != nil {
+		return fmt.Errorf("encode release hints: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write release hints: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace release hints file: %w", err)
+	}
+	return nil
+}
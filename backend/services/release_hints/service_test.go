@@ -0,0 +1,88 @@
+package release_hints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestCheckMatchesRecordedTitles(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	releases := []models.NZBResult{{Title: "Heat.1995.1080p.BluRay.x264-GRP", Attributes: map[string]string{"imdb": "0113277"}}}
+	svc.RecordReleases("", "Heat", 1995, releases)
+	svc.RecordReleases("tt0903747", "Breaking Bad", 0, []models.NZBResult{{Title: "Breaking.Bad.S01E01.720p"}})
+	svc.RecordReleases("tt9999999", "Nothing Found", 2020, nil)
+
+	hints := svc.Check([]models.ReleaseHintQuery{
+		{ID: "imdb-from-release", IMDBID: "tt0113277"},
+		{ID: "title-year", Title: "Heat", Year: 1995, MediaType: "movie"},
+		{ID: "wrong-year", Title: "Heat", Year: 1986, MediaType: "movie"},
+		{ID: "series", Title: "Breaking Bad!", Year: 2008, MediaType: "series"},
+		{ID: "empty-search", IMDBID: "tt9999999", Title: "Nothing Found", Year: 2020},
+		{ID: "unknown", Title: "Some Other Movie", Year: 2001},
+	})
+	want := map[string]bool{
+		"imdb-from-release": true,
+		"title-year":        true,
+		"wrong-year":        false,
+		"series":            true,
+		"empty-search":      false,
+		"unknown":           false,
+	}
+	if len(hints) != len(want) {
+		t.Fatalf("expected %d hints, got %d", len(want), len(hints))
+	}
+	for _, hint := range hints {
+		if hint.Likely != want[hint.ID] {
+			t.Errorf("%s: expected likely=%t", hint.ID, want[hint.ID])
+		}
+	}
+}
+
+func TestRebuildExpiresAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	svc.now = func() time.Time { return now.Add(-window - time.Hour) }
+	svc.RecordReleases("tt0000001", "Old", 2000, []models.NZBResult{{Title: "Old.2000"}})
+	svc.now = func() time.Time { return now }
+	svc.RecordReleases("tt0000002", "New", 2024, []models.NZBResult{{Title: "New.2024"}})
+
+	if err := svc.Rebuild(context.Background()); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hints := reloaded.Check([]models.ReleaseHintQuery{{ID: "old", IMDBID: "tt0000001"}, {ID: "new", IMDBID: "tt0000002"}})
+	if hints[0].Likely || !hints[1].Likely {
+		t.Errorf("expected only the recent title after a rebuild and reload, got %+v", hints)
+	}
+}
+
+func TestRecordGrowsFilter(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3*minCapacity; i++ {
+		svc.RecordReleases("", "Title", 1900+i, []models.NZBResult{{Title: "x"}})
+	}
+	if svc.capacity < len(svc.seen) {
+		t.Errorf("expected the filter to be resized for %d keys, capacity %d", len(svc.seen), svc.capacity)
+	}
+	if hint := svc.Check([]models.ReleaseHintQuery{{ID: "first", Title: "Title", Year: 1900}}); !hint[0].Likely {
+		t.Error("expected keys recorded before the resize to be kept")
+	}
+}
@@ -0,0 +1,384 @@
+package release_versions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrUserIDRequired     = errors.New("user id is required")
+	ErrContentIDRequired  = errors.New("content id is required")
+	ErrReleaseRequired    = errors.New("release title or guid is required")
+	ErrVersionNotFound    = errors.New("release version not found")
+	ErrTooManyVersions    = errors.New("too many pinned versions for this title")
+)
+
+// maxVersionsPerTitle caps how many releases a profile can pin for one title.
+const maxVersionsPerTitle = 10
+
+// Service persists per-profile pinned release versions of titles.
+type Service struct {
+	mu       sync.RWMutex
+	path     string
+	versions map[string]map[string][]models.ReleaseVersion // userID -> contentID -> versions
+}
+
+// NewService constructs a release versions service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create release versions dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "release_versions.json"),
+		versions: make(map[string]map[string][]models.ReleaseVersion),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// List returns the pinned versions of a title, default version first.
+func (s *Service) List(userID, contentID string) ([]models.ReleaseVersion, error) {
+	userID, contentID, err := normalizeIDs(userID, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sortedVersions(s.versions[userID][contentID]), nil
+}
+
+// ListAll returns every pinned version for a user across all titles.
+func (s *Service) ListAll(userID string) ([]models.ReleaseVersion, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.ReleaseVersion, 0)
+	for _, versions := range s.versions[userID] {
+		result = append(result, versions...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ContentID != result[j].ContentID {
+			return result[i].ContentID < result[j].ContentID
+		}
+		return result[i].PinnedAt.Before(result[j].PinnedAt)
+	})
+	return result, nil
+}
+
+// Get returns a pinned version of a title. An empty versionID returns the default version.
+func (s *Service) Get(userID, contentID, versionID string) (*models.ReleaseVersion, error) {
+	userID, contentID, err := normalizeIDs(userID, contentID)
+	if err != nil {
+		return nil, err
+	}
+	versionID = strings.TrimSpace(versionID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, version := range s.versions[userID][contentID] {
+		if (versionID == "" && version.IsDefault) || (versionID != "" && version.ID == versionID) {
+			found := version
+			return &found, nil
+		}
+	}
+	return nil, ErrVersionNotFound
+}
+
+// Pin stores a release as a version of a title. Pinning a release that is already
+// pinned (same GUID, or same title when no GUID) updates its label instead.
+// The first version pinned for a title becomes the default.
+func (s *Service) Pin(userID, contentID string, pin models.ReleaseVersionPin) (models.ReleaseVersion, error) {
+	userID, contentID, err := normalizeIDs(userID, contentID)
+	if err != nil {
+		return models.ReleaseVersion{}, err
+	}
+	if strings.TrimSpace(pin.Result.GUID) == "" && strings.TrimSpace(pin.Result.Title) == "" {
+		return models.ReleaseVersion{}, ErrReleaseRequired
+	}
+
+	label := strings.TrimSpace(pin.Label)
+	if label == "" {
+		label = strings.TrimSpace(pin.Result.Title)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensureUserLocked(userID)
+	versions := perUser[contentID]
+
+	idx := -1
+	for i, existing := range versions {
+		if sameRelease(existing.Result, pin.Result) {
+			idx = i
+			break
+		}
+	}
+
+	if idx < 0 {
+		if len(versions) >= maxVersionsPerTitle {
+			return models.ReleaseVersion{}, ErrTooManyVersions
+		}
+		versions = append(versions, models.ReleaseVersion{
+			ID:        uuid.New().String(),
+			ContentID: contentID,
+			PinnedAt:  time.Now().UTC(),
+			IsDefault: len(versions) == 0,
+		})
+		idx = len(versions) - 1
+	}
+
+	versions[idx].Label = label
+	versions[idx].Edition = strings.TrimSpace(pin.Edition)
+	versions[idx].Result = pin.Result
+	if pin.MakeDefault {
+		setDefault(versions, versions[idx].ID)
+	}
+
+	perUser[contentID] = versions
+	pinned := versions[idx]
+
+	if err := s.saveLocked(); err != nil {
+		return models.ReleaseVersion{}, err
+	}
+	return pinned, nil
+}
+
+// SetDefault marks a pinned version as the one played when no version is selected.
+func (s *Service) SetDefault(userID, contentID, versionID string) error {
+	userID, contentID, err := normalizeIDs(userID, contentID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[userID][contentID]
+	if !setDefault(versions, strings.TrimSpace(versionID)) {
+		return ErrVersionNotFound
+	}
+
+	return s.saveLocked()
+}
+
+// Remove unpins a version. If it was the default, the oldest remaining version takes over.
+func (s *Service) Remove(userID, contentID, versionID string) error {
+	userID, contentID, err := normalizeIDs(userID, contentID)
+	if err != nil {
+		return err
+	}
+	versionID = strings.TrimSpace(versionID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser, ok := s.versions[userID]
+	if !ok {
+		return ErrVersionNotFound
+	}
+
+	versions := perUser[contentID]
+	idx := -1
+	for i, version := range versions {
+		if version.ID == versionID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return ErrVersionNotFound
+	}
+
+	wasDefault := versions[idx].IsDefault
+	versions = append(versions[:idx], versions[idx+1:]...)
+
+	switch {
+	case len(versions) == 0:
+		delete(perUser, contentID)
+	case wasDefault:
+		oldest := 0
+		for i := range versions {
+			if versions[i].PinnedAt.Before(versions[oldest].PinnedAt) {
+				oldest = i
+			}
+		}
+		versions[oldest].IsDefault = true
+		perUser[contentID] = versions
+	default:
+		perUser[contentID] = versions
+	}
+
+	// Clean up empty user maps
+	if len(perUser) == 0 {
+		delete(s.versions, userID)
+	}
+
+	return s.saveLocked()
+}
+
+func normalizeIDs(userID, contentID string) (string, string, error) {
+	userID = strings.TrimSpace(userID)
+	contentID = strings.TrimSpace(strings.ToLower(contentID))
+	if userID == "" {
+		return "", "", ErrUserIDRequired
+	}
+	if contentID == "" {
+		return "", "", ErrContentIDRequired
+	}
+	return userID, contentID, nil
+}
+
+func sameRelease(a, b models.NZBResult) bool {
+	if a.GUID != "" || b.GUID != "" {
+		return a.GUID == b.GUID
+	}
+	return strings.EqualFold(strings.TrimSpace(a.Title), strings.TrimSpace(b.Title))
+}
+
+// setDefault flags versionID as the default and clears the flag on all others.
+func setDefault(versions []models.ReleaseVersion, versionID string) bool {
+	found := false
+	for i := range versions {
+		if versions[i].ID == versionID {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	for i := range versions {
+		versions[i].IsDefault = versions[i].ID == versionID
+	}
+	return true
+}
+
+// sortedVersions returns a copy with the default version first, then by pin time.
+func sortedVersions(versions []models.ReleaseVersion) []models.ReleaseVersion {
+	result := make([]models.ReleaseVersion, len(versions))
+	copy(result, versions)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].IsDefault != result[j].IsDefault {
+			return result[i].IsDefault
+		}
+		return result[i].PinnedAt.Before(result[j].PinnedAt)
+	})
+	return result
+}
+
+// ensureUserLocked creates the per-user map if it doesn't exist.
+// Must be called with s.mu held.
+func (s *Service) ensureUserLocked(userID string) map[string][]models.ReleaseVersion {
+	perUser, ok := s.versions[userID]
+	if !ok {
+		perUser = make(map[string][]models.ReleaseVersion)
+		s.versions[userID] = perUser
+	}
+	return perUser
+}
+
+// load reads the pinned versions from disk.
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open release versions: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read release versions: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Stored as map[userID][]ReleaseVersion
+	var loaded map[string][]models.ReleaseVersion
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("decode release versions: %w", err)
+	}
+
+	for userID, items := range loaded {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		perUser := s.ensureUserLocked(userID)
+		for _, version := range items {
+			contentID := strings.ToLower(version.ContentID)
+			version.ContentID = contentID
+			perUser[contentID] = append(perUser[contentID], version)
+		}
+	}
+
+	log.Printf("[release_versions] loaded pinned versions for %d users", len(s.versions))
+	return nil
+}
+
+// saveLocked writes the pinned versions to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	toSave := make(map[string][]models.ReleaseVersion)
+	for userID, perUser := range s.versions {
+		items := make([]models.ReleaseVersion, 0)
+		for _, versions := range perUser {
+			items = append(items, versions...)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].ContentID != items[j].ContentID {
+				return items[i].ContentID < items[j].ContentID
+			}
+			return items[i].PinnedAt.Before(items[j].PinnedAt)
+		})
+		toSave[userID] = items
+	}
+
+	data, err := json.MarshalIndent(toSave, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode release versions: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write release versions: %w", err)
+	}
+
+	return nil
+}
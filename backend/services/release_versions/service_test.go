@@ -0,0 +1,89 @@
+package release_versions
+
+import (
+	"errors"
+	"testing"
+
+	"novastream/models"
+)
+
+func TestPinListAndDefault(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	uhd, err := svc.Pin("user-1", "tmdb:movie:603", models.ReleaseVersionPin{
+		Label:  "4K DV",
+		Result: models.NZBResult{Title: "The.Matrix.1999.2160p.DV", GUID: "guid-uhd"},
+	})
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	if !uhd.IsDefault {
+		t.Fatal("expected first pinned version to become the default")
+	}
+
+	cut, err := svc.Pin("user-1", "TMDB:movie:603", models.ReleaseVersionPin{
+		Edition:     "Director's Cut",
+		Result:      models.NZBResult{Title: "The.Matrix.1999.Directors.Cut.1080p", GUID: "guid-cut"},
+		MakeDefault: true,
+	})
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	if cut.Label != "The.Matrix.1999.Directors.Cut.1080p" {
+		t.Fatalf("expected label to default to the release title, got %q", cut.Label)
+	}
+
+	// Re-pinning the same release updates it rather than adding a duplicate
+	if _, err := svc.Pin("user-1", "tmdb:movie:603", models.ReleaseVersionPin{
+		Label:  "UHD",
+		Result: models.NZBResult{Title: "The.Matrix.1999.2160p.DV", GUID: "guid-uhd"},
+	}); err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+
+	versions, err := svc.List("user-1", "tmdb:movie:603")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].ID != cut.ID || versions[1].Label != "UHD" {
+		t.Fatalf("expected default cut first then relabelled UHD, got %+v", versions)
+	}
+
+	def, err := svc.Get("user-1", "tmdb:movie:603", "")
+	if err != nil || def.ID != cut.ID {
+		t.Fatalf("expected default version %s, got %+v (err=%v)", cut.ID, def, err)
+	}
+}
+
+func TestRemovePromotesNewDefaultAndPersists(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	first, _ := svc.Pin("user-1", "tmdb:movie:1", models.ReleaseVersionPin{Result: models.NZBResult{Title: "A.2160p"}})
+	second, _ := svc.Pin("user-1", "tmdb:movie:1", models.ReleaseVersionPin{Result: models.NZBResult{Title: "A.1080p"}})
+
+	if err := svc.Remove("user-1", "tmdb:movie:1", first.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := svc.Remove("user-1", "tmdb:movie:1", first.ID); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("expected ErrVersionNotFound, got %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	def, err := reloaded.Get("user-1", "tmdb:movie:1", "")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if def.ID != second.ID || !def.IsDefault {
+		t.Fatalf("expected remaining version to be promoted to default, got %+v", def)
+	}
+}
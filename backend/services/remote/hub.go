@@ -0,0 +1,104 @@
+package remote
+
+import (
+	"log"
+	"sync"
+
+	"novastream/models"
+)
+
+// Conn is a live relay connection (a WebSocket in production).
+type Conn interface {
+	Send(msg models.RemoteMessage) error
+}
+
+// targetKey identifies a target device within the account it signed in with,
+// so one account can't take over another account's device by its client ID.
+type targetKey struct {
+	accountID string
+	clientID  string
+}
+
+// hub tracks live target and controller connections. A target device has at most
+// one connection; a newer connection replaces the old one.
+type hub struct {
+	mu          sync.RWMutex
+	targets     map[targetKey]Conn
+	controllers map[string]map[Conn]struct{}
+}
+
+func newHub() *hub {
+	return &hub{
+		targets:     make(map[targetKey]Conn),
+		controllers: make(map[string]map[Conn]struct{}),
+	}
+}
+
+func (h *hub) addTarget(key targetKey, conn Conn) func() {
+	h.mu.Lock()
+	h.targets[key] = conn
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.targets[key] == conn {
+			delete(h.targets, key)
+		}
+	}
+}
+
+func (h *hub) addController(pairingID string, conn Conn) func() {
+	h.mu.Lock()
+	if h.controllers[pairingID] == nil {
+		h.controllers[pairingID] = make(map[Conn]struct{})
+	}
+	h.controllers[pairingID][conn] = struct{}{}
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.controllers[pairingID], conn)
+		if len(h.controllers[pairingID]) == 0 {
+			delete(h.controllers, pairingID)
+		}
+	}
+}
+
+func (h *hub) targetOnline(key targetKey) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.targets[key]
+	return ok
+}
+
+// sendToTarget delivers msg to the target's connection and reports whether it was sent.
+func (h *hub) sendToTarget(key targetKey, msg models.RemoteMessage) bool {
+	h.mu.RLock()
+	conn, ok := h.targets[key]
+	h.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if err := conn.Send(msg); err != nil {
+		log.Printf("[remote] failed to send %s to target %s: %v", msg.Type, key.clientID, err)
+		return false
+	}
+	return true
+}
+
+func (h *hub) sendToControllers(pairingID string, msg models.RemoteMessage) {
+	h.mu.RLock()
+	conns := make([]Conn, 0, len(h.controllers[pairingID]))
+	for conn := range h.controllers[pairingID] {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		if err := conn.Send(msg); err != nil {
+			log.Printf("[remote] failed to send %s to controller of pairing %s: %v", msg.Type, pairingID, err)
+		}
+	}
+}
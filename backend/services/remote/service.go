@@ -0,0 +1,369 @@
+package remote
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrClientIDRequired   = errors.New("target client id is required")
+	ErrInvalidCode        = errors.New("pairing code is invalid or expired")
+	ErrPairingNotFound    = errors.New("pairing not found")
+	ErrTargetOffline      = errors.New("target device is not connected")
+	ErrInvalidCommand     = errors.New("invalid remote command")
+)
+
+// pairingCodeTTL is how long a code shown on the TV remains valid.
+const pairingCodeTTL = 10 * time.Minute
+
+type pendingCode struct {
+	accountID  string
+	clientID   string
+	targetName string
+	expiresAt  time.Time
+}
+
+// Service manages remote-control pairings between controller and target devices
+// and relays commands/status between their live connections.
+type Service struct {
+	mu       sync.RWMutex
+	path     string
+	pairings map[string]models.RemotePairing
+	codes    map[string]pendingCode
+
+	hub *hub
+}
+
+// NewService creates a remote-control service storing pairings inside the provided directory.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create remote dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "remote_pairings.json"),
+		pairings: make(map[string]models.RemotePairing),
+		codes:    make(map[string]pendingCode),
+		hub:      newHub(),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// CreatePairingCode issues a six-digit code for a target device. Any previous
+// unused code for the same device is replaced.
+func (s *Service) CreatePairingCode(accountID, clientID, targetName string) (models.RemotePairingCode, error) {
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" {
+		return models.RemotePairingCode{}, ErrClientIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for code, pending := range s.codes {
+		if (pending.accountID == accountID && pending.clientID == clientID) || now.After(pending.expiresAt) {
+			delete(s.codes, code)
+		}
+	}
+
+	var code string
+	for {
+		n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil {
+			return models.RemotePairingCode{}, fmt.Errorf("generate pairing code: %w", err)
+		}
+		code = fmt.Sprintf("%06d", n.Int64())
+		if _, taken := s.codes[code]; !taken {
+			break
+		}
+	}
+
+	pending := pendingCode{
+		accountID:  accountID,
+		clientID:   clientID,
+		targetName: strings.TrimSpace(targetName),
+		expiresAt:  now.Add(pairingCodeTTL),
+	}
+	s.codes[code] = pending
+
+	return models.RemotePairingCode{Code: code, TargetClientID: clientID, ExpiresAt: pending.expiresAt}, nil
+}
+
+// Pair redeems a pairing code for a controller. Codes can only be redeemed by
+// the account that issued them and are single-use.
+func (s *Service) Pair(accountID, code, controllerID, controllerName string) (models.RemotePairing, error) {
+	code = strings.TrimSpace(code)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.codes[code]
+	if !ok || pending.accountID != accountID || time.Now().UTC().After(pending.expiresAt) {
+		return models.RemotePairing{}, ErrInvalidCode
+	}
+	delete(s.codes, code)
+
+	targetName := pending.targetName
+	if targetName == "" {
+		targetName = "TV"
+	}
+
+	pairing := models.RemotePairing{
+		ID:             uuid.NewString(),
+		AccountID:      accountID,
+		TargetClientID: pending.clientID,
+		TargetName:     targetName,
+		ControllerID:   strings.TrimSpace(controllerID),
+		ControllerName: strings.TrimSpace(controllerName),
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	// Re-pairing the same controller with the same target replaces the old pairing
+	for id, existing := range s.pairings {
+		if existing.AccountID == accountID && existing.TargetClientID == pairing.TargetClientID &&
+			pairing.ControllerID != "" && existing.ControllerID == pairing.ControllerID {
+			delete(s.pairings, id)
+		}
+	}
+	s.pairings[pairing.ID] = pairing
+
+	if err := s.saveLocked(); err != nil {
+		return models.RemotePairing{}, err
+	}
+
+	pairing.Online = s.hub.targetOnline(pairingTarget(pairing))
+	s.hub.sendToTarget(pairingTarget(pairing), models.RemoteMessage{
+		Type:      models.RemoteMessagePaired,
+		PairingID: pairing.ID,
+		SentAt:    time.Now().UTC(),
+	})
+
+	return pairing, nil
+}
+
+// ListPairings returns the account's pairings, newest first, with live online state.
+func (s *Service) ListPairings(accountID string) []models.RemotePairing {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.RemotePairing, 0)
+	for _, pairing := range s.pairings {
+		if pairing.AccountID != accountID {
+			continue
+		}
+		pairing.Online = s.hub.targetOnline(pairingTarget(pairing))
+		result = append(result, pairing)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result
+}
+
+// GetPairing returns a pairing owned by the account.
+func (s *Service) GetPairing(accountID, pairingID string) (models.RemotePairing, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pairing, ok := s.pairings[pairingID]
+	if !ok || pairing.AccountID != accountID {
+		return models.RemotePairing{}, ErrPairingNotFound
+	}
+	pairing.Online = s.hub.targetOnline(pairingTarget(pairing))
+	return pairing, nil
+}
+
+// Unpair removes a pairing owned by the account.
+func (s *Service) Unpair(accountID, pairingID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pairing, ok := s.pairings[pairingID]
+	if !ok || pairing.AccountID != accountID {
+		return ErrPairingNotFound
+	}
+	delete(s.pairings, pairingID)
+
+	return s.saveLocked()
+}
+
+// SendCommand validates a command and relays it to the pairing's target device.
+// Returns the command with its assigned ID.
+func (s *Service) SendCommand(accountID, pairingID string, cmd models.RemoteCommand) (models.RemoteCommand, error) {
+	if err := validateCommand(cmd); err != nil {
+		return models.RemoteCommand{}, err
+	}
+
+	s.mu.Lock()
+	pairing, ok := s.pairings[pairingID]
+	if !ok || pairing.AccountID != accountID {
+		s.mu.Unlock()
+		return models.RemoteCommand{}, ErrPairingNotFound
+	}
+	pairing.LastUsedAt = time.Now().UTC()
+	s.pairings[pairingID] = pairing
+	_ = s.saveLocked()
+	s.mu.Unlock()
+
+	if cmd.ID == "" {
+		cmd.ID = uuid.NewString()
+	}
+
+	delivered := s.hub.sendToTarget(pairingTarget(pairing), models.RemoteMessage{
+		Type:      models.RemoteMessageCommand,
+		PairingID: pairingID,
+		Command:   &cmd,
+		SentAt:    time.Now().UTC(),
+	})
+	if !delivered {
+		return models.RemoteCommand{}, ErrTargetOffline
+	}
+
+	return cmd, nil
+}
+
+// PublishStatus relays a status update from a target device to every controller
+// the account paired with it (e.g. now-playing title and position).
+func (s *Service) PublishStatus(accountID, clientID string, status map[string]interface{}) {
+	s.mu.RLock()
+	var pairingIDs []string
+	for id, pairing := range s.pairings {
+		if pairing.AccountID == accountID && pairing.TargetClientID == clientID {
+			pairingIDs = append(pairingIDs, id)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, id := range pairingIDs {
+		s.hub.sendToControllers(id, models.RemoteMessage{
+			Type:      models.RemoteMessageStatus,
+			PairingID: id,
+			Status:    status,
+			SentAt:    time.Now().UTC(),
+		})
+	}
+}
+
+// ConnectTarget registers a live connection for a target device signed in to
+// the account. Only that account's pairings reach it. The returned function
+// must be called when the connection closes.
+func (s *Service) ConnectTarget(accountID, clientID string, conn Conn) func() {
+	return s.hub.addTarget(targetKey{accountID: accountID, clientID: clientID}, conn)
+}
+
+// ConnectController registers a live connection for a controller of a pairing.
+// The returned function must be called when the connection closes.
+func (s *Service) ConnectController(pairingID string, conn Conn) func() {
+	return s.hub.addController(pairingID, conn)
+}
+
+// pairingTarget is the hub key of a pairing's target device.
+func pairingTarget(pairing models.RemotePairing) targetKey {
+	return targetKey{accountID: pairing.AccountID, clientID: pairing.TargetClientID}
+}
+
+func validateCommand(cmd models.RemoteCommand) error {
+	switch cmd.Type {
+	case models.RemoteCommandPlay:
+		if strings.TrimSpace(cmd.TitleID) == "" || strings.TrimSpace(cmd.UserID) == "" {
+			return fmt.Errorf("%w: play requires titleId and userId", ErrInvalidCommand)
+		}
+	case models.RemoteCommandSeek:
+		if cmd.Position < 0 {
+			return fmt.Errorf("%w: seek position must not be negative", ErrInvalidCommand)
+		}
+	case models.RemoteCommandNavigate:
+		if strings.TrimSpace(cmd.Route) == "" {
+			return fmt.Errorf("%w: navigate requires route", ErrInvalidCommand)
+		}
+	case models.RemoteCommandPause, models.RemoteCommandResume, models.RemoteCommandStop:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidCommand, cmd.Type)
+	}
+	return nil
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.pairings = make(map[string]models.RemotePairing)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open remote pairings file: %w", err)
+	}
+	defer file.Close()
+
+	var pairings map[string]models.RemotePairing
+	if err := json.NewDecoder(file).Decode(&pairings); err != nil {
+		return fmt.Errorf("decode remote pairings: %w", err)
+	}
+	if pairings == nil {
+		pairings = make(map[string]models.RemotePairing)
+	}
+
+	s.pairings = pairings
+	return nil
+}
+
+func (s *Service) saveLocked() error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create remote pairings temp file: %w", err)
+	}
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.pairings); err != nil {
+		file.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("encode remote pairings: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("sync remote pairings: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close remote pairings temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace remote pairings file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package remote
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"novastream/models"
+)
+
+type recordingConn struct {
+	mu       sync.Mutex
+	messages []models.RemoteMessage
+}
+
+func (c *recordingConn) Send(msg models.RemoteMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func TestPairingCodeRedeemsOnceForSameAccount(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	code, err := svc.CreatePairingCode("acct-1", "tv-1", "Living Room")
+	if err != nil {
+		t.Fatalf("CreatePairingCode() error = %v", err)
+	}
+	if len(code.Code) != 6 {
+		t.Fatalf("expected 6 digit code, got %q", code.Code)
+	}
+
+	if _, err := svc.Pair("acct-2", code.Code, "phone-1", "Phone"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("expected other account to be rejected, got %v", err)
+	}
+
+	pairing, err := svc.Pair("acct-1", code.Code, "phone-1", "Phone")
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+	if pairing.TargetClientID != "tv-1" || pairing.TargetName != "Living Room" {
+		t.Fatalf("unexpected pairing: %+v", pairing)
+	}
+
+	if _, err := svc.Pair("acct-1", code.Code, "phone-2", "Tablet"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("expected code to be single-use, got %v", err)
+	}
+}
+
+func TestPairingsPersistAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	code, _ := svc.CreatePairingCode("acct-1", "tv-1", "Living Room")
+	if _, err := svc.Pair("acct-1", code.Code, "phone-1", "Phone"); err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if got := reloaded.ListPairings("acct-1"); len(got) != 1 {
+		t.Fatalf("expected 1 pairing after reload, got %d", len(got))
+	}
+}
+
+func TestSendCommandRelaysToTargetAndStatusToControllers(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	code, _ := svc.CreatePairingCode("acct-1", "tv-1", "Living Room")
+	pairing, err := svc.Pair("acct-1", code.Code, "phone-1", "Phone")
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+
+	play := models.RemoteCommand{Type: models.RemoteCommandPlay, TitleID: "tmdb:movie:603", UserID: "user-1"}
+	if _, err := svc.SendCommand("acct-1", pairing.ID, play); !errors.Is(err, ErrTargetOffline) {
+		t.Fatalf("expected offline error, got %v", err)
+	}
+
+	// Another account connecting a device with the same client ID must not
+	// receive this account's commands
+	intruder := &recordingConn{}
+	defer svc.ConnectTarget("acct-2", "tv-1", intruder)()
+	if _, err := svc.SendCommand("acct-1", pairing.ID, play); !errors.Is(err, ErrTargetOffline) {
+		t.Fatalf("expected another account's device to be ignored, got %v", err)
+	}
+
+	target := &recordingConn{}
+	disconnect := svc.ConnectTarget("acct-1", "tv-1", target)
+	defer disconnect()
+	controller := &recordingConn{}
+	defer svc.ConnectController(pairing.ID, controller)()
+
+	if _, err := svc.SendCommand("acct-1", pairing.ID, models.RemoteCommand{Type: models.RemoteCommandPlay}); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("expected invalid play command to be rejected, got %v", err)
+	}
+
+	sent, err := svc.SendCommand("acct-1", pairing.ID, play)
+	if err != nil {
+		t.Fatalf("SendCommand() error = %v", err)
+	}
+	if sent.ID == "" {
+		t.Fatal("expected command ID to be assigned")
+	}
+	if len(target.messages) != 1 || target.messages[0].Command.TitleID != "tmdb:movie:603" {
+		t.Fatalf("expected play command relayed to target, got %+v", target.messages)
+	}
+
+	svc.PublishStatus("acct-2", "tv-1", map[string]interface{}{"state": "spoofed"})
+	svc.PublishStatus("acct-1", "tv-1", map[string]interface{}{"state": "playing"})
+	if len(intruder.messages) != 0 {
+		t.Fatalf("expected nothing relayed to another account's device, got %+v", intruder.messages)
+	}
+	if len(controller.messages) != 1 || controller.messages[0].Status["state"] != "playing" {
+		t.Fatalf("expected status relayed to controller, got %+v", controller.messages)
+	}
+}
@@ -0,0 +1,435 @@
+// Package saved_searches re-runs release searches profiles have saved and
+// collects matching releases that weren't there before, so a profile waiting
+// for a specific remux or a PROPER release learns when one appears.
+package saved_searches
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+	"novastream/services/indexer"
+	"novastream/utils/releasename"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrQueryRequired      = errors.New("search query is required")
+	ErrSearchNotFound     = errors.New("saved search not found")
+	ErrTooManySearches    = fmt.Errorf("a profile can save at most %d searches", maxSearchesPerUser)
+	ErrInvalidFilters     = errors.New("invalid search filters")
+)
+
+const (
+	maxSearchesPerUser = 50
+
+	// Per-search timeout, matching the prequeue's
+	searchTimeout = 2 * time.Minute
+
+	// maxNewResults bounds the unacknowledged releases kept per search
+	maxNewResults = 20
+
+	// maxSeenReleases bounds the remembered releases per search; the oldest
+	// are forgotten first
+	maxSeenReleases = 1000
+)
+
+// Searcher runs an indexer search. Implemented by *indexer.Service.
+type Searcher interface {
+	Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error)
+}
+
+// Service stores saved searches in a JSON file on disk. CheckAll re-runs
+// them; it is registered as a scheduled job rather than running its own loop.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	searcher Searcher
+	searches map[string]*models.SavedSearch
+	now      func() time.Time
+}
+
+// NewService constructs a saved search store in storageDir.
+func NewService(storageDir string, searcher Searcher) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create saved searches dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "saved_searches.json"),
+		searcher: searcher,
+		searches: make(map[string]*models.SavedSearch),
+		now:      time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Create saves a search for a profile. Releases matching when the search is
+// first checked are treated as already known; only later ones are reported.
+func (s *Service) Create(userID string, search models.SavedSearch) (models.SavedSearch, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return models.SavedSearch{}, ErrQueryRequired
+	}
+	if err := validateFilters(search.Filters); err != nil {
+		return models.SavedSearch{}, err
+	}
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" {
+		search.Name = search.Query
+	}
+	if search.MediaType != "movie" && search.MediaType != "series" {
+		search.MediaType = ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.countForUserLocked(userID) >= maxSearchesPerUser {
+		return models.SavedSearch{}, ErrTooManySearches
+	}
+
+	search.ID = uuid.NewString()
+	search.UserID = userID
+	search.CreatedAt = s.now().UTC()
+	search.LastCheckedAt = nil
+	search.LastError = ""
+	search.NewResults = nil
+	search.LastNewAt = nil
+	search.SeenReleases = nil
+	s.searches[search.ID] = &search
+	if err := s.saveLocked(); err != nil {
+		delete(s.searches, search.ID)
+		return models.SavedSearch{}, err
+	}
+	return search, nil
+}
+
+// ListForUser returns a profile's saved searches, newest first.
+func (s *Service) ListForUser(userID string) []models.SavedSearch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.SavedSearch, 0)
+	for _, search := range s.searches {
+		if search.UserID == userID {
+			list = append(list, *search)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Delete removes one of a profile's saved searches.
+func (s *Service) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[id]
+	if !ok || search.UserID != userID {
+		return ErrSearchNotFound
+	}
+	delete(s.searches, id)
+	return s.saveLocked()
+}
+
+// Acknowledge clears the new releases of one of a profile's saved searches.
+// They stay known, so they aren't reported again.
+func (s *Service) Acknowledge(userID, id string) (models.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[id]
+	if !ok || search.UserID != userID {
+		return models.SavedSearch{}, ErrSearchNotFound
+	}
+	search.NewResults = nil
+	if err := s.saveLocked(); err != nil {
+		return models.SavedSearch{}, err
+	}
+	return *search, nil
+}
+
+// CheckAll re-runs every saved search, oldest checked first, until ctx ends.
+// It returns an error only when every search failed.
+func (s *Service) CheckAll(ctx context.Context) error {
+	s.mu.Lock()
+	due := make([]models.SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		due = append(due, *search)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return checkedBefore(due[i], due[j]) })
+
+	var failed int
+	var lastErr error
+	for _, search := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.check(ctx, search); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 && failed == len(due) {
+		return fmt.Errorf("all %d saved searches failed: %w", failed, lastErr)
+	}
+	return nil
+}
+
+// check runs one saved search and records the releases that are new.
+func (s *Service) check(ctx context.Context, snapshot models.SavedSearch) error {
+	searchCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	results, err := s.searcher.Search(searchCtx, searchOptions(snapshot))
+	cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[snapshot.ID]
+	if !ok {
+		// Deleted while the search was running
+		return nil
+	}
+
+	now := s.now().UTC()
+	if err != nil {
+		search.LastError = err.Error()
+		log.Printf("[saved_searches] search %q for user %s failed: %v", search.Name, search.UserID, err)
+	} else {
+		// The first successful check only records what already exists
+		found := recordMatches(search, results, search.LastCheckedAt == nil)
+		search.LastCheckedAt = &now
+		search.LastError = ""
+		if found > 0 {
+			search.LastNewAt = &now
+			log.Printf("[saved_searches] %d new releases for %q (user %s)", found, search.Name, search.UserID)
+		}
+	}
+
+	if saveErr := s.saveLocked(); saveErr != nil {
+		log.Printf("[saved_searches] failed to persist saved searches: %v", saveErr)
+	}
+	return err
+}
+
+// recordMatches remembers the results matching a search's filters and, unless
+// this is the baseline check, adds the ones not seen before to NewResults.
+// It returns how many new releases were added.
+func recordMatches(search *models.SavedSearch, results []models.NZBResult, baseline bool) int {
+	seen := make(map[string]struct{}, len(search.SeenReleases))
+	for _, key := range search.SeenReleases {
+		seen[key] = struct{}{}
+	}
+
+	var added []models.NZBResult
+	for _, result := range results {
+		if !matchesFilters(result, search.Filters) {
+			continue
+		}
+		key := releaseKey(result)
+		if _, ok := seen[key]; ok || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		search.SeenReleases = append(search.SeenReleases, key)
+		if !baseline {
+			added = append(added, result.Clone())
+		}
+	}
+	if len(search.SeenReleases) > maxSeenReleases {
+		search.SeenReleases = search.SeenReleases[len(search.SeenReleases)-maxSeenReleases:]
+	}
+
+	if len(added) > 0 {
+		// Newest first, dropping the oldest unacknowledged releases
+		search.NewResults = append(added, search.NewResults...)
+		if len(search.NewResults) > maxNewResults {
+			search.NewResults = search.NewResults[:maxNewResults]
+		}
+	}
+	return len(added)
+}
+
+// matchesFilters reports whether a release satisfies every set filter.
+func matchesFilters(result models.NZBResult, filters models.SavedSearchFilters) bool {
+	release := releasename.Parse(result.Title)
+	if res := releasename.ParseResolution(result.Attributes["resolution"]); res > 0 {
+		release.Resolution = res
+	}
+
+	if filters.MinResolution != "" && release.Resolution < releasename.ParseResolution(filters.MinResolution) {
+		return false
+	}
+	if len(filters.Sources) > 0 && !containsFold(filters.Sources, release.Source) {
+		return false
+	}
+	if filters.RequireHDR && len(release.HDR) == 0 {
+		return false
+	}
+	if filters.ProperOrRepack && !release.Proper && !release.Repack {
+		return false
+	}
+
+	title := strings.ToLower(result.Title)
+	for _, term := range filters.RequiredTerms {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" && !strings.Contains(title, term) {
+			return false
+		}
+	}
+	for _, term := range filters.ExcludedTerms {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" && strings.Contains(title, term) {
+			return false
+		}
+	}
+
+	sizeGB := float64(result.SizeBytes) / (1 << 30)
+	if filters.MinSizeGB > 0 && sizeGB < filters.MinSizeGB {
+		return false
+	}
+	if filters.MaxSizeGB > 0 && sizeGB > filters.MaxSizeGB {
+		return false
+	}
+	return true
+}
+
+func validateFilters(filters models.SavedSearchFilters) error {
+	if filters.MinResolution != "" && releasename.ParseResolution(filters.MinResolution) == 0 {
+		return fmt.Errorf("%w: unknown resolution %q", ErrInvalidFilters, filters.MinResolution)
+	}
+	if filters.MinSizeGB < 0 || filters.MaxSizeGB < 0 {
+		return fmt.Errorf("%w: sizes must not be negative", ErrInvalidFilters)
+	}
+	if filters.MaxSizeGB > 0 && filters.MinSizeGB > filters.MaxSizeGB {
+		return fmt.Errorf("%w: minimum size exceeds maximum size", ErrInvalidFilters)
+	}
+	return nil
+}
+
+// releaseKey identifies a release across indexers, which each give it their own GUID.
+func releaseKey(result models.NZBResult) string {
+	return strings.ToLower(strings.TrimSpace(result.Title))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkedBefore orders never-checked searches first, then by last check.
+func checkedBefore(a, b models.SavedSearch) bool {
+	switch {
+	case a.LastCheckedAt == nil:
+		return b.LastCheckedAt != nil || a.CreatedAt.Before(b.CreatedAt)
+	case b.LastCheckedAt == nil:
+		return false
+	default:
+		return a.LastCheckedAt.Before(*b.LastCheckedAt)
+	}
+}
+
+func searchOptions(search models.SavedSearch) indexer.SearchOptions {
+	return indexer.SearchOptions{
+		Query:      search.Query,
+		MaxResults: 100,
+		MediaType:  search.MediaType,
+		IMDBID:     search.IMDBID,
+		Year:       search.Year,
+		UserID:     search.UserID,
+	}
+}
+
+// countForUserLocked returns how many searches a profile has saved.
+// Must be called with s.mu held.
+func (s *Service) countForUserLocked(userID string) int {
+	n := 0
+	for _, search := range s.searches {
+		if search.UserID == userID {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open saved searches: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read saved searches: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var searches []models.SavedSearch
+	if err := json.Unmarshal(data, &searches); err != nil {
+		return fmt.Errorf("decode saved searches: %w", err)
+	}
+	for i := range searches {
+		search := searches[i]
+		if search.ID == "" {
+			continue
+		}
+		s.searches[search.ID] = &search
+	}
+	return nil
+}
+
+// saveLocked writes the saved searches to disk, oldest first.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]models.SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		list = append(list, *search)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode saved searches: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write saved searches: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package saved_searches
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"novastream/models"
+	"novastream/services/indexer"
+)
+
+type fakeSearcher struct {
+	results []models.NZBResult
+	err     error
+	calls   []indexer.SearchOptions
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error) {
+	f.calls = append(f.calls, opts)
+	return f.results, f.err
+}
+
+func newTestService(t *testing.T, searcher Searcher) *Service {
+	t.Helper()
+	svc, err := NewService(t.TempDir(), searcher)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestCheckReportsOnlyNewMatches(t *testing.T) {
+	searcher := &fakeSearcher{results: []models.NZBResult{
+		{Title: "Movie.2024.2160p.UHD.BluRay.REMUX.HDR.HEVC-GRP", SizeBytes: 60 << 30},
+		{Title: "Movie.2024.1080p.WEB-DL.H264-GRP", SizeBytes: 8 << 30},
+	}}
+	svc := newTestService(t, searcher)
+
+	search, err := svc.Create("u1", models.SavedSearch{
+		Query:     "Movie 2024",
+		MediaType: "movie",
+		Filters:   models.SavedSearchFilters{MinResolution: "2160p", Sources: []string{"remux"}},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if search.Name != "Movie 2024" {
+		t.Errorf("name defaulted to %q", search.Name)
+	}
+
+	// The first check records existing releases without reporting them
+	if err := svc.CheckAll(context.Background()); err != nil {
+		t.Fatalf("CheckAll: %v", err)
+	}
+	if got := svc.ListForUser("u1")[0]; len(got.NewResults) != 0 || got.LastCheckedAt == nil {
+		t.Fatalf("baseline check reported releases: %+v", got)
+	}
+
+	// Another indexer listing the same release isn't new; a second remux is
+	searcher.results = append(searcher.results,
+		models.NZBResult{Title: "movie.2024.2160p.uhd.bluray.remux.hdr.hevc-grp", Indexer: "other"},
+		models.NZBResult{Title: "Movie.2024.PROPER.2160p.BluRay.REMUX.DV.HEVC-FIX", SizeBytes: 62 << 30},
+	)
+	if err := svc.CheckAll(context.Background()); err != nil {
+		t.Fatalf("CheckAll: %v", err)
+	}
+	got := svc.ListForUser("u1")[0]
+	if len(got.NewResults) != 1 || got.NewResults[0].Title != "Movie.2024.PROPER.2160p.BluRay.REMUX.DV.HEVC-FIX" || got.LastNewAt == nil {
+		t.Fatalf("unexpected new results: %+v", got.NewResults)
+	}
+
+	// Acknowledged releases aren't reported again
+	if _, err := svc.Acknowledge("u1", search.ID); err != nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	svc.CheckAll(context.Background())
+	if got := svc.ListForUser("u1")[0]; len(got.NewResults) != 0 {
+		t.Fatalf("acknowledged release reported again: %+v", got.NewResults)
+	}
+	if len(searcher.calls) != 3 || searcher.calls[0].UserID != "u1" || searcher.calls[0].MediaType != "movie" {
+		t.Errorf("unexpected searches: %+v", searcher.calls)
+	}
+}
+
+func TestFailedFirstCheckKeepsBaseline(t *testing.T) {
+	searcher := &fakeSearcher{err: errors.New("indexer down")}
+	svc := newTestService(t, searcher)
+	svc.Create("u1", models.SavedSearch{Query: "Show"})
+
+	if err := svc.CheckAll(context.Background()); err == nil {
+		t.Fatal("expected an error when every search fails")
+	}
+	if got := svc.ListForUser("u1")[0]; got.LastError != "indexer down" || got.LastCheckedAt != nil {
+		t.Fatalf("unexpected state after failure: %+v", got)
+	}
+
+	searcher.err = nil
+	searcher.results = []models.NZBResult{{Title: "Show.S01E01.1080p.WEB-DL-GRP"}}
+	svc.CheckAll(context.Background())
+	if got := svc.ListForUser("u1")[0]; len(got.NewResults) != 0 || got.LastError != "" {
+		t.Fatalf("first successful check should only set the baseline: %+v", got)
+	}
+}
+
+func TestMatchesFilters(t *testing.T) {
+	result := models.NZBResult{Title: "Movie.2024.REPACK.1080p.BluRay.x264-GRP", SizeBytes: 12 << 30}
+	cases := []struct {
+		name    string
+		filters models.SavedSearchFilters
+		want    bool
+	}{
+		{"no filters", models.SavedSearchFilters{}, true},
+		{"resolution too low", models.SavedSearchFilters{MinResolution: "4K"}, false},
+		{"source", models.SavedSearchFilters{Sources: []string{"BluRay"}}, true},
+		{"hdr required", models.SavedSearchFilters{RequireHDR: true}, false},
+		{"repack", models.SavedSearchFilters{ProperOrRepack: true}, true},
+		{"required term", models.SavedSearchFilters{RequiredTerms: []string{"x264"}}, true},
+		{"excluded term", models.SavedSearchFilters{ExcludedTerms: []string{" GRP "}}, false},
+		{"too small", models.SavedSearchFilters{MinSizeGB: 20}, false},
+		{"size window", models.SavedSearchFilters{MinSizeGB: 10, MaxSizeGB: 15}, true},
+	}
+	for _, c := range cases {
+		if got := matchesFilters(result, c.filters); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCreateValidation(t *testing.T) {
+	svc := newTestService(t, &fakeSearcher{})
+
+	if _, err := svc.Create("u1", models.SavedSearch{Query: "  "}); !errors.Is(err, ErrQueryRequired) {
+		t.Errorf("expected ErrQueryRequired, got %v", err)
+	}
+	if _, err := svc.Create("u1", models.SavedSearch{Query: "x", Filters: models.SavedSearchFilters{MinResolution: "huge"}}); !errors.Is(err, ErrInvalidFilters) {
+		t.Errorf("expected ErrInvalidFilters for resolution, got %v", err)
+	}
+	if _, err := svc.Create("u1", models.SavedSearch{Query: "x", Filters: models.SavedSearchFilters{MinSizeGB: 10, MaxSizeGB: 5}}); !errors.Is(err, ErrInvalidFilters) {
+		t.Errorf("expected ErrInvalidFilters for sizes, got %v", err)
+	}
+
+	search, _ := svc.Create("u1", models.SavedSearch{Query: "x"})
+	if err := svc.Delete("u2", search.ID); !errors.Is(err, ErrSearchNotFound) {
+		t.Errorf("deleted another profile's search: %v", err)
+	}
+	if err := svc.Delete("u1", search.ID); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+}
@@ -13,9 +13,12 @@ import (
 
 	"novastream/config"
 	"novastream/models"
+	"novastream/services/artwork_warmer"
 	"novastream/services/epg"
+	"novastream/services/metadata"
 	"novastream/services/plex"
 	"novastream/services/trakt"
+	"novastream/services/user_settings"
 	"novastream/services/watchlist"
 )
 
@@ -26,6 +29,9 @@ type Service struct {
 	traktClient      *trakt.Client
 	watchlistService *watchlist.Service
 	epgService       *epg.Service
+	metadataService  *metadata.Service
+	userSettings     *user_settings.Service
+	artworkWarmer    *artwork_warmer.Service
 
 	// Runtime state
 	mu      sync.RWMutex
@@ -238,6 +244,10 @@ func (s *Service) executeTask(task config.ScheduledTask) {
 		result, err = s.executeEPGRefresh(task)
 	case config.ScheduledTaskTypePlaylistRefresh:
 		result, err = s.executePlaylistRefresh(task)
+	case config.ScheduledTaskTypeCustomListRefresh:
+		result, err = s.executeCustomListRefresh(task)
+	case config.ScheduledTaskTypeArtworkWarm:
+		result, err = s.executeArtworkWarm(task)
 	default:
 		log.Printf("[scheduler] Unknown task type: %s", task.Type)
 		return
@@ -359,6 +369,39 @@ func (s *Service) SetEPGService(epgService *epg.Service) {
 	s.epgService = epgService
 }
 
+// SetMetadataService sets the metadata service used to pre-enrich custom MDBList shelves.
+func (s *Service) SetMetadataService(metadataService *metadata.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadataService = metadataService
+}
+
+// SetUserSettingsService sets the user settings service so per-profile custom shelves are refreshed too.
+func (s *Service) SetUserSettingsService(userSettings *user_settings.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userSettings = userSettings
+}
+
+// SetArtworkWarmer sets the warmer that caches continue-watching and watchlist artwork.
+func (s *Service) SetArtworkWarmer(warmer *artwork_warmer.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artworkWarmer = warmer
+}
+
+// GetCustomListStatus returns the background refresh status of each custom MDBList shelf.
+func (s *Service) GetCustomListStatus() []metadata.CustomListRefreshStatus {
+	s.mu.RLock()
+	metadataSvc := s.metadataService
+	s.mu.RUnlock()
+
+	if metadataSvc == nil {
+		return []metadata.CustomListRefreshStatus{}
+	}
+	return metadataSvc.CustomListStatuses()
+}
+
 // executePlexWatchlistSync syncs a Plex watchlist to/from a profile
 func (s *Service) executePlexWatchlistSync(task config.ScheduledTask) (SyncResult, error) {
 	plexAccountID := task.Config["plexAccountId"]
@@ -1575,3 +1618,107 @@ func (s *Service) executePlaylistRefresh(task config.ScheduledTask) (SyncResult,
 	log.Printf("[scheduler] cleared %d cached playlist files", cleared)
 	return SyncResult{Count: cleared}, nil
 }
+
+// executeCustomListRefresh re-fetches and enriches every configured custom MDBList shelf
+// (global and per-profile) so the home screen is served from a warm cache.
+func (s *Service) executeCustomListRefresh(task config.ScheduledTask) (SyncResult, error) {
+	s.mu.RLock()
+	metadataSvc := s.metadataService
+	s.mu.RUnlock()
+
+	if metadataSvc == nil {
+		return SyncResult{}, errors.New("metadata service not configured")
+	}
+
+	settings, err := s.configManager.Load()
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("load settings: %w", err)
+	}
+
+	listURLs := s.collectCustomListURLs(settings)
+	if len(listURLs) == 0 {
+		log.Printf("[scheduler] no custom lists configured, nothing to refresh")
+		return SyncResult{Count: 0}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	total := 0
+	var failed []string
+	for _, listURL := range listURLs {
+		if ctx.Err() != nil {
+			return SyncResult{Count: total}, fmt.Errorf("custom list refresh timed out: %w", ctx.Err())
+		}
+		count, err := metadataSvc.RefreshCustomList(ctx, listURL)
+		if err != nil {
+			log.Printf("[scheduler] custom list refresh failed for %s: %v", listURL, err)
+			failed = append(failed, listURL)
+			continue
+		}
+		total += count
+	}
+
+	if len(failed) > 0 {
+		return SyncResult{Count: total}, fmt.Errorf("%d of %d custom lists failed to refresh: %s", len(failed), len(listURLs), strings.Join(failed, ", "))
+	}
+
+	log.Printf("[scheduler] refreshed %d custom lists (%d items)", len(listURLs), total)
+	return SyncResult{Count: total}, nil
+}
+
+// executeArtworkWarm caches the artwork of every profile's continue-watching and
+// watchlist rows through the image proxy.
+func (s *Service) executeArtworkWarm(task config.ScheduledTask) (SyncResult, error) {
+	s.mu.RLock()
+	warmer := s.artworkWarmer
+	s.mu.RUnlock()
+
+	if warmer == nil {
+		return SyncResult{}, errors.New("artwork warmer not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	result, err := warmer.Warm(ctx)
+	log.Printf("[scheduler] warmed artwork: %d images, %d fetched, %d failed", result.Images, result.Fetched, result.Failed)
+	return SyncResult{Count: result.Fetched}, err
+}
+
+// collectCustomListURLs returns the unique MDBList URLs of enabled custom shelves
+// from the global settings and any profile overrides.
+func (s *Service) collectCustomListURLs(settings config.Settings) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(shelfType, listURL string, enabled bool) {
+		listURL = strings.TrimSpace(listURL)
+		if !enabled || shelfType != "mdblist" || listURL == "" || seen[listURL] {
+			return
+		}
+		seen[listURL] = true
+		urls = append(urls, listURL)
+	}
+
+	for _, shelf := range settings.HomeShelves.Shelves {
+		add(shelf.Type, shelf.ListURL, shelf.Enabled)
+	}
+
+	s.mu.RLock()
+	userSettingsSvc := s.userSettings
+	s.mu.RUnlock()
+
+	if userSettingsSvc != nil {
+		for userID := range userSettingsSvc.GetUsersWithOverrides() {
+			userSettings, err := userSettingsSvc.Get(userID)
+			if err != nil || userSettings == nil {
+				continue
+			}
+			for _, shelf := range userSettings.HomeShelves.Shelves {
+				add(shelf.Type, shelf.ListURL, shelf.Enabled)
+			}
+		}
+	}
+
+	return urls
+}
@@ -0,0 +1,434 @@
+package scrobble_outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+	"novastream/services/trakt"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrSenderRequired     = errors.New("scrobble sender not provided")
+	ErrEntryNotFound      = errors.New("outbox entry not found")
+)
+
+const (
+	// How often the background loop looks for entries that are due for a retry
+	retryCheckInterval = 30 * time.Second
+
+	// Backoff between attempts doubles from minRetryBackoff up to maxRetryBackoff.
+	minRetryBackoff = time.Minute
+	maxRetryBackoff = 6 * time.Hour
+
+	// Entries that still fail after maxAttempts (roughly three days of
+	// retries) are dead-lettered instead of retried forever.
+	maxAttempts = 20
+)
+
+// Sender delivers scrobbles to Trakt. Implemented by *trakt.Scrobbler.
+type Sender interface {
+	ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID string, watchedAt time.Time) error
+	ScrobbleEpisode(userID string, showTVDBID, season, episode int, watchedAt time.Time) error
+	IsEnabled() bool
+	IsEnabledForUser(userID string) bool
+}
+
+// Service is a persistent outbox in front of the Trakt scrobbler. Every
+// scrobble is written to disk before it is sent and only removed once Trakt
+// accepts it, so scrobbles made while Trakt is down or a token has expired are
+// retried with backoff instead of being lost.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	sender   Sender
+	entries  map[string]*models.ScrobbleOutboxEntry
+	inFlight map[string]bool
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs a scrobble outbox backed by a JSON file on disk.
+func NewService(storageDir string, sender Sender) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+	if sender == nil {
+		return nil, ErrSenderRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create scrobble outbox dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "scrobble_outbox.json"),
+		sender:   sender,
+		entries:  make(map[string]*models.ScrobbleOutboxEntry),
+		inFlight: make(map[string]bool),
+		now:      time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// IsEnabled reports whether scrobbling is enabled for any account.
+func (s *Service) IsEnabled() bool {
+	return s.sender.IsEnabled()
+}
+
+// IsEnabledForUser reports whether scrobbling is enabled for the user.
+func (s *Service) IsEnabledForUser(userID string) bool {
+	return s.sender.IsEnabledForUser(userID)
+}
+
+// ScrobbleMovie queues a watched movie and attempts delivery immediately.
+// A returned error means the scrobble stays queued for retry.
+func (s *Service) ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID string, watchedAt time.Time) error {
+	return s.enqueueAndSend(models.ScrobbleOutboxEntry{
+		UserID:    userID,
+		MediaType: "movie",
+		TMDBID:    tmdbID,
+		TVDBID:    tvdbID,
+		IMDBID:    imdbID,
+		WatchedAt: watchedAt.UTC(),
+	})
+}
+
+// ScrobbleEpisode queues a watched episode and attempts delivery immediately.
+// A returned error means the scrobble stays queued for retry.
+func (s *Service) ScrobbleEpisode(userID string, showTVDBID, season, episode int, watchedAt time.Time) error {
+	return s.enqueueAndSend(models.ScrobbleOutboxEntry{
+		UserID:        userID,
+		MediaType:     "episode",
+		TVDBID:        showTVDBID,
+		SeasonNumber:  season,
+		EpisodeNumber: episode,
+		WatchedAt:     watchedAt.UTC(),
+	})
+}
+
+// List returns queued scrobbles, oldest first.
+func (s *Service) List() []models.ScrobbleOutboxEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.ScrobbleOutboxEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		list = append(list, *entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].QueuedAt.Before(list[j].QueuedAt) })
+	return list
+}
+
+// Flush attempts delivery of every queued scrobble now, ignoring backoff.
+func (s *Service) Flush() models.ScrobbleOutboxFlushResult {
+	var result models.ScrobbleOutboxFlushResult
+	for _, id := range s.claim(func(*models.ScrobbleOutboxEntry) bool { return true }) {
+		result.Attempted++
+		if s.send(id) == nil {
+			result.Delivered++
+		}
+	}
+
+	s.mu.Lock()
+	result.Remaining = len(s.entries)
+	s.mu.Unlock()
+
+	log.Printf("[scrobble_outbox] flush delivered %d of %d scrobbles (%d remaining)", result.Delivered, result.Attempted, result.Remaining)
+	return result
+}
+
+// Discard removes a queued scrobble without delivering it.
+func (s *Service) Discard(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[id]; !ok {
+		return ErrEntryNotFound
+	}
+	delete(s.entries, id)
+	return s.saveLocked()
+}
+
+// Start launches the background retry loop.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.retryLoop(loopCtx)
+
+	log.Printf("[scrobble_outbox] retry loop started (%d queued)", len(s.entries))
+}
+
+// Stop ends the retry loop and waits for an in-progress pass to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) retryLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(retryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryDue(ctx)
+		}
+	}
+}
+
+// retryDue sends every entry whose backoff has elapsed. Dead-lettered entries
+// are skipped; only a flush retries them.
+func (s *Service) retryDue(ctx context.Context) {
+	now := s.now()
+	for _, id := range s.claim(func(e *models.ScrobbleOutboxEntry) bool { return !e.DeadLettered && !e.NextAttemptAt.After(now) }) {
+		if ctx.Err() != nil {
+			s.release(id)
+			continue
+		}
+		s.send(id)
+	}
+}
+
+func (s *Service) enqueueAndSend(entry models.ScrobbleOutboxEntry) error {
+	s.mu.Lock()
+	for _, existing := range s.entries {
+		if sameScrobble(*existing, entry) {
+			// Already queued; the retry loop will deliver it
+			s.mu.Unlock()
+			return nil
+		}
+	}
+
+	now := s.now().UTC()
+	entry.ID = uuid.NewString()
+	entry.QueuedAt = now
+	entry.NextAttemptAt = now
+	s.entries[entry.ID] = &entry
+	s.inFlight[entry.ID] = true
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[scrobble_outbox] failed to persist scrobble for user %s: %v", entry.UserID, err)
+	}
+	s.mu.Unlock()
+
+	if err := s.send(entry.ID); err != nil {
+		return fmt.Errorf("queued for retry: %w", err)
+	}
+	return nil
+}
+
+// claim marks matching entries as in flight and returns their IDs, so the
+// retry loop, a flush and a fresh scrobble never send the same entry twice.
+func (s *Service) claim(match func(*models.ScrobbleOutboxEntry) bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id, entry := range s.entries {
+		if s.inFlight[id] || !match(entry) {
+			continue
+		}
+		s.inFlight[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (s *Service) release(id string) {
+	s.mu.Lock()
+	delete(s.inFlight, id)
+	s.mu.Unlock()
+}
+
+// send delivers a claimed entry. Delivered entries are removed; failed ones are
+// rescheduled with exponential backoff, or dead-lettered when Trakt rejected
+// them outright or they ran out of attempts. The entry is released either way.
+func (s *Service) send(id string) error {
+	s.mu.Lock()
+	entry, ok := s.entries[id]
+	if !ok {
+		delete(s.inFlight, id)
+		s.mu.Unlock()
+		return ErrEntryNotFound
+	}
+	snapshot := *entry
+	s.mu.Unlock()
+
+	var err error
+	switch snapshot.MediaType {
+	case "movie":
+		err = s.sender.ScrobbleMovie(snapshot.UserID, snapshot.TMDBID, snapshot.TVDBID, snapshot.IMDBID, snapshot.WatchedAt)
+	case "episode":
+		err = s.sender.ScrobbleEpisode(snapshot.UserID, snapshot.TVDBID, snapshot.SeasonNumber, snapshot.EpisodeNumber, snapshot.WatchedAt)
+	default:
+		err = fmt.Errorf("unknown media type %q", snapshot.MediaType)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, id)
+
+	entry, ok = s.entries[id]
+	if !ok {
+		// Discarded while the request was in flight
+		return err
+	}
+
+	if err == nil {
+		delete(s.entries, id)
+		if snapshot.Attempts > 0 {
+			log.Printf("[scrobble_outbox] delivered %s scrobble for user %s after %d retries", snapshot.MediaType, snapshot.UserID, snapshot.Attempts)
+		}
+	} else {
+		entry.Attempts++
+		entry.LastError = err.Error()
+		entry.NextAttemptAt = s.now().UTC().Add(retryBackoff(entry.Attempts))
+		if permanentFailure(err) || entry.Attempts >= maxAttempts {
+			entry.DeadLettered = true
+			log.Printf("[scrobble_outbox] %s scrobble for user %s failed (attempt %d), giving up: %v",
+				entry.MediaType, entry.UserID, entry.Attempts, err)
+		} else {
+			log.Printf("[scrobble_outbox] %s scrobble for user %s failed (attempt %d), retrying at %s: %v",
+				entry.MediaType, entry.UserID, entry.Attempts, entry.NextAttemptAt.Format(time.RFC3339), err)
+		}
+	}
+
+	if saveErr := s.saveLocked(); saveErr != nil {
+		log.Printf("[scrobble_outbox] failed to persist outbox: %v", saveErr)
+	}
+	return err
+}
+
+// permanentFailure reports whether Trakt rejected a scrobble in a way a retry
+// cannot fix: any 4xx response other than 429 Too Many Requests. Expired tokens
+// surface as trakt.ErrUnauthorized rather than an HTTPError and keep retrying,
+// since reconnecting the account makes them deliverable again.
+func permanentFailure(err error) bool {
+	var httpErr *trakt.HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 && httpErr.StatusCode != http.StatusTooManyRequests
+}
+
+// retryBackoff returns the wait before the next attempt after the given number of failures.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return backoff
+}
+
+func sameScrobble(a, b models.ScrobbleOutboxEntry) bool {
+	return a.UserID == b.UserID &&
+		a.MediaType == b.MediaType &&
+		a.TMDBID == b.TMDBID &&
+		a.TVDBID == b.TVDBID &&
+		a.IMDBID == b.IMDBID &&
+		a.SeasonNumber == b.SeasonNumber &&
+		a.EpisodeNumber == b.EpisodeNumber &&
+		a.WatchedAt.Equal(b.WatchedAt)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open scrobble outbox: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read scrobble outbox: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var entries []models.ScrobbleOutboxEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("decode scrobble outbox: %w", err)
+	}
+	for i := range entries {
+		entry := entries[i]
+		s.entries[entry.ID] = &entry
+	}
+
+	if len(s.entries) > 0 {
+		log.Printf("[scrobble_outbox] loaded %d queued scrobbles", len(s.entries))
+	}
+	return nil
+}
+
+// saveLocked writes the outbox to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	entries := make([]models.ScrobbleOutboxEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].QueuedAt.Before(entries[j].QueuedAt) })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode scrobble outbox: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write scrobble outbox: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package scrobble_outbox
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"novastream/services/trakt"
+)
+
+type fakeSender struct {
+	mu    sync.Mutex
+	fail  error
+	calls int
+}
+
+func (f *fakeSender) ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID string, watchedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.fail
+}
+
+func (f *fakeSender) ScrobbleEpisode(userID string, showTVDBID, season, episode int, watchedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.fail
+}
+
+func (f *fakeSender) IsEnabled() bool                     { return true }
+func (f *fakeSender) IsEnabledForUser(userID string) bool { return true }
+
+func (f *fakeSender) setFail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = err
+}
+
+func TestFailedScrobbleIsPersistedAndRetried(t *testing.T) {
+	dir := t.TempDir()
+	sender := &fakeSender{fail: errors.New("trakt unavailable")}
+
+	svc, err := NewService(dir, sender)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	watchedAt := now.Add(-time.Hour)
+	if err := svc.ScrobbleEpisode("user-1", 81189, 1, 2, watchedAt); err == nil {
+		t.Fatal("expected error for failed delivery")
+	}
+	// A duplicate scrobble while the first is queued must not add a second entry
+	if err := svc.ScrobbleEpisode("user-1", 81189, 1, 2, watchedAt); err != nil {
+		t.Fatalf("duplicate scrobble error = %v", err)
+	}
+
+	entries := svc.List()
+	if len(entries) != 1 || entries[0].Attempts != 1 || entries[0].LastError == "" {
+		t.Fatalf("expected one queued entry with one failed attempt, got %+v", entries)
+	}
+	if want := now.Add(minRetryBackoff); !entries[0].NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt = %v, want %v", entries[0].NextAttemptAt, want)
+	}
+
+	// The outbox survives a restart
+	reloaded, err := NewService(dir, sender)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	reloaded.now = func() time.Time { return now }
+	if got := len(reloaded.List()); got != 1 {
+		t.Fatalf("expected 1 entry after reload, got %d", got)
+	}
+
+	// Not due yet: the retry pass leaves it alone
+	reloaded.retryDue(t.Context())
+	if sender.calls != 1 {
+		t.Fatalf("expected no retry before backoff elapsed, got %d calls", sender.calls)
+	}
+
+	sender.setFail(nil)
+	now = now.Add(2 * minRetryBackoff)
+	reloaded.retryDue(t.Context())
+	if got := len(reloaded.List()); got != 0 {
+		t.Fatalf("expected outbox to be empty after delivery, got %d entries", got)
+	}
+}
+
+func TestFlushAndDiscard(t *testing.T) {
+	sender := &fakeSender{fail: errors.New("token expired")}
+	svc, err := NewService(t.TempDir(), sender)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	svc.ScrobbleMovie("user-1", 603, 0, "tt0133093", time.Now())
+	svc.ScrobbleMovie("user-1", 604, 0, "tt0234215", time.Now())
+
+	result := svc.Flush()
+	if result.Attempted != 2 || result.Delivered != 0 || result.Remaining != 2 {
+		t.Fatalf("unexpected flush result %+v", result)
+	}
+
+	entries := svc.List()
+	if err := svc.Discard(entries[0].ID); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if err := svc.Discard(entries[0].ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	sender.setFail(nil)
+	result = svc.Flush()
+	if result.Attempted != 1 || result.Delivered != 1 || result.Remaining != 0 {
+		t.Fatalf("unexpected flush result after recovery %+v", result)
+	}
+}
+
+func TestRejectedScrobbleIsDeadLettered(t *testing.T) {
+	rejected := fmt.Errorf("trakt sync history failed: %w", &trakt.HTTPError{StatusCode: http.StatusUnprocessableEntity, Status: "422 Unprocessable Entity"})
+	sender := &fakeSender{fail: rejected}
+	svc, err := NewService(t.TempDir(), sender)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	svc.ScrobbleMovie("user-1", 603, 0, "tt0133093", now)
+	entries := svc.List()
+	if len(entries) != 1 || !entries[0].DeadLettered {
+		t.Fatalf("expected a dead-lettered entry, got %+v", entries)
+	}
+
+	now = now.Add(maxRetryBackoff)
+	svc.retryDue(t.Context())
+	if sender.calls != 1 {
+		t.Fatalf("expected dead-lettered entry to be skipped by the retry loop, got %d calls", sender.calls)
+	}
+}
+
+func TestRateLimitedScrobbleKeepsRetrying(t *testing.T) {
+	limited := fmt.Errorf("trakt sync history failed: %w", &trakt.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"})
+	sender := &fakeSender{fail: limited}
+	svc, err := NewService(t.TempDir(), sender)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	svc.ScrobbleMovie("user-1", 603, 0, "tt0133093", now)
+	for i := 1; i < maxAttempts; i++ {
+		if entries := svc.List(); entries[0].DeadLettered {
+			t.Fatalf("entry dead-lettered after %d attempts", entries[0].Attempts)
+		}
+		now = now.Add(maxRetryBackoff)
+		svc.retryDue(t.Context())
+	}
+
+	entries := svc.List()
+	if entries[0].Attempts != maxAttempts || !entries[0].DeadLettered {
+		t.Fatalf("expected entry dead-lettered after %d attempts, got %+v", maxAttempts, entries[0])
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	if got := retryBackoff(1); got != minRetryBackoff {
+		t.Fatalf("retryBackoff(1) = %v, want %v", got, minRetryBackoff)
+	}
+	if got := retryBackoff(3); got != 4*minRetryBackoff {
+		t.Fatalf("retryBackoff(3) = %v, want %v", got, 4*minRetryBackoff)
+	}
+	if got := retryBackoff(50); got != maxRetryBackoff {
+		t.Fatalf("retryBackoff(50) = %v, want %v", got, maxRetryBackoff)
+	}
+}
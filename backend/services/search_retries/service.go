@@ -0,0 +1,425 @@
+// Package search_retries keeps searching for episodes that had no releases
+// when they were requested because they had only just aired. Searches are
+// retried on a backoff schedule and the releases eventually found are kept so
+// clients can offer the episode without searching again.
+package search_retries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrRetryNotFound      = errors.New("search retry not found")
+)
+
+const (
+	// How often the background loop looks for retries that are due
+	retryCheckInterval = time.Minute
+
+	// Backoff between searches doubles from minRetryBackoff up to maxRetryBackoff
+	minRetryBackoff = 15 * time.Minute
+	maxRetryBackoff = 4 * time.Hour
+
+	// Per-search timeout, matching the prequeue's
+	searchTimeout = 2 * time.Minute
+
+	// maxKeptResults bounds the releases stored on a found retry
+	maxKeptResults = 5
+
+	// Found and expired retries are dropped after this long
+	finishedRetention = 7 * 24 * time.Hour
+
+	airDateLayout = "2006-01-02"
+)
+
+// Searcher runs an indexer search. Implemented by *indexer.Service.
+type Searcher interface {
+	Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error)
+}
+
+// Service stores search retries in a JSON file on disk and runs them in the
+// background.
+type Service struct {
+	mu       sync.Mutex
+	path     string
+	cfg      *config.Manager
+	searcher Searcher
+	retries  map[string]*models.SearchRetry
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService constructs a search retry store in storageDir.
+func NewService(storageDir string, cfg *config.Manager, searcher Searcher) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create search retries dir: %w", err)
+	}
+
+	svc := &Service{
+		path:     filepath.Join(storageDir, "search_retries.json"),
+		cfg:      cfg,
+		searcher: searcher,
+		retries:  make(map[string]*models.SearchRetry),
+		now:      time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Schedule queues retries for an episode search that found nothing, if
+// retries are enabled and the episode aired recently enough. A pending retry
+// for the same profile and episode is returned instead of adding another.
+// The boolean reports whether a retry is pending.
+func (s *Service) Schedule(retry models.SearchRetry) (models.SearchRetry, bool) {
+	if s == nil || strings.TrimSpace(retry.Query) == "" {
+		return models.SearchRetry{}, false
+	}
+	settings, err := s.settings()
+	if err != nil || !settings.Enabled {
+		return models.SearchRetry{}, false
+	}
+
+	now := s.now().UTC()
+	if !recentlyAired(retry.AirDate, now, time.Duration(settings.AiredWithinHours)*time.Hour) {
+		return models.SearchRetry{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.retries {
+		if existing.Status == models.SearchRetryPending && sameEpisode(*existing, retry) {
+			return *existing, true
+		}
+	}
+
+	retry.ID = uuid.NewString()
+	retry.Status = models.SearchRetryPending
+	retry.Attempts = 0
+	retry.CreatedAt = now
+	retry.NextAttemptAt = now.Add(minRetryBackoff)
+	retry.ExpiresAt = now.Add(time.Duration(settings.GiveUpAfterHours) * time.Hour)
+	retry.Results = nil
+	s.retries[retry.ID] = &retry
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[search_retries] failed to persist retry for %s: %v", retry.Query, err)
+	}
+
+	log.Printf("[search_retries] no releases yet for %q (aired %s), retrying until %s",
+		retry.Query, retry.AirDate, retry.ExpiresAt.Format(time.RFC3339))
+	return retry, true
+}
+
+// ListForUser returns a profile's retries, newest first.
+func (s *Service) ListForUser(userID string) []models.SearchRetry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.SearchRetry, 0)
+	for _, retry := range s.retries {
+		if retry.UserID == userID {
+			list = append(list, *retry)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Dismiss removes one of a profile's retries, stopping it if still pending.
+func (s *Service) Dismiss(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	retry, ok := s.retries[id]
+	if !ok || retry.UserID != userID {
+		return ErrRetryNotFound
+	}
+	delete(s.retries, id)
+	return s.saveLocked()
+}
+
+// Start launches the background retry loop.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.retryLoop(loopCtx)
+
+	log.Printf("[search_retries] retry loop started (%d pending)", s.countLocked(models.SearchRetryPending))
+}
+
+// Stop ends the retry loop and waits for an in-progress search to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) retryLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(retryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryDue(ctx)
+		}
+	}
+}
+
+// retryDue expires stale retries and searches for every one that is due.
+func (s *Service) retryDue(ctx context.Context) {
+	now := s.now().UTC()
+
+	s.mu.Lock()
+	var due []models.SearchRetry
+	changed := false
+	for id, retry := range s.retries {
+		switch {
+		case retry.Status != models.SearchRetryPending:
+			if now.Sub(retry.CreatedAt) > finishedRetention {
+				delete(s.retries, id)
+				changed = true
+			}
+		case !now.Before(retry.ExpiresAt):
+			retry.Status = models.SearchRetryExpired
+			changed = true
+			log.Printf("[search_retries] giving up on %q after %d retries", retry.Query, retry.Attempts)
+		case !retry.NextAttemptAt.After(now):
+			due = append(due, *retry)
+		}
+	}
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			log.Printf("[search_retries] failed to persist retries: %v", err)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	for _, retry := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.attempt(ctx, retry)
+	}
+}
+
+// attempt runs one retry's search and records the outcome.
+func (s *Service) attempt(ctx context.Context, snapshot models.SearchRetry) {
+	searchCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	results, err := s.searcher.Search(searchCtx, searchOptions(snapshot))
+	cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	retry, ok := s.retries[snapshot.ID]
+	if !ok {
+		// Dismissed while the search was running
+		return
+	}
+
+	now := s.now().UTC()
+	retry.Attempts++
+	retry.LastAttemptAt = &now
+	switch {
+	case err != nil:
+		retry.LastError = err.Error()
+	case len(results) > 0:
+		if len(results) > maxKeptResults {
+			results = results[:maxKeptResults]
+		}
+		retry.Status = models.SearchRetryFound
+		retry.FoundAt = &now
+		retry.LastError = ""
+		retry.Results = results
+		log.Printf("[search_retries] found %d releases for %q after %d retries", len(results), retry.Query, retry.Attempts)
+	default:
+		retry.LastError = ""
+	}
+	if retry.Status == models.SearchRetryPending {
+		retry.NextAttemptAt = now.Add(retryBackoff(retry.Attempts))
+	}
+
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[search_retries] failed to persist retries: %v", err)
+	}
+}
+
+func (s *Service) settings() (config.SearchRetrySettings, error) {
+	if s.cfg == nil {
+		return config.SearchRetrySettings{}, errors.New("config manager not configured")
+	}
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return config.SearchRetrySettings{}, err
+	}
+	return settings.SearchRetry, nil
+}
+
+// countLocked returns how many retries have the given status.
+// Must be called with s.mu held.
+func (s *Service) countLocked(status string) int {
+	n := 0
+	for _, retry := range s.retries {
+		if retry.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func searchOptions(retry models.SearchRetry) indexer.SearchOptions {
+	return indexer.SearchOptions{
+		Query:                 retry.Query,
+		MaxResults:            50,
+		MediaType:             "series",
+		IMDBID:                retry.IMDBID,
+		Year:                  retry.Year,
+		UserID:                retry.UserID,
+		ClientID:              retry.ClientID,
+		AbsoluteEpisodeNumber: retry.AbsoluteEpisodeNumber,
+		IsDaily:               retry.IsDaily,
+		IsAnime:               retry.IsAnime,
+		TargetAirDate:         retry.AirDate,
+		RuntimeMinutes:        retry.RuntimeMinutes,
+	}
+}
+
+// recentlyAired reports whether an episode aired no more than within ago.
+// Air dates have no time of day, so an episode counts as aired at the end of
+// its air date; one airing tomorrow (UTC) may already be out in some time zones.
+func recentlyAired(airDate string, now time.Time, within time.Duration) bool {
+	aired, err := time.Parse(airDateLayout, strings.TrimSpace(airDate))
+	if err != nil {
+		return false
+	}
+	if aired.After(now.Add(24 * time.Hour)) {
+		return false
+	}
+	return now.Sub(aired.Add(24*time.Hour)) <= within
+}
+
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 0; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+func sameEpisode(a, b models.SearchRetry) bool {
+	if a.UserID != b.UserID || a.SeasonNumber != b.SeasonNumber || a.EpisodeNumber != b.EpisodeNumber {
+		return false
+	}
+	if a.TitleID != "" && b.TitleID != "" {
+		return a.TitleID == b.TitleID
+	}
+	return strings.EqualFold(a.Query, b.Query)
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open search retries: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read search retries: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var retries []models.SearchRetry
+	if err := json.Unmarshal(data, &retries); err != nil {
+		return fmt.Errorf("decode search retries: %w", err)
+	}
+	for i := range retries {
+		retry := retries[i]
+		if retry.ID == "" {
+			continue
+		}
+		s.retries[retry.ID] = &retry
+	}
+	return nil
+}
+
+// saveLocked writes the retries to disk, oldest first.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]models.SearchRetry, 0, len(s.retries))
+	for _, retry := range s.retries {
+		list = append(list, *retry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode search retries: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write search retries: %w", err)
+	}
+	return nil
+}
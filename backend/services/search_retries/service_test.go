@@ -0,0 +1,148 @@
+package search_retries
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+)
+
+type fakeSearcher struct {
+	results []models.NZBResult
+	err     error
+	calls   []indexer.SearchOptions
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error) {
+	f.calls = append(f.calls, opts)
+	return f.results, f.err
+}
+
+func newTestService(t *testing.T, searcher Searcher, now time.Time) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := cfg.Save(config.DefaultSettings()); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	svc, err := NewService(dir, cfg, searcher)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestScheduleOnlyRecentEpisodes(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	svc := newTestService(t, &fakeSearcher{}, now)
+
+	base := models.SearchRetry{UserID: "u1", TitleID: "tvdb:1", TitleName: "Show", SeasonNumber: 2, EpisodeNumber: 5, Query: "Show S02E05"}
+
+	old := base
+	old.AirDate = "2026-03-01"
+	if _, ok := svc.Schedule(old); ok {
+		t.Fatal("scheduled an episode that aired 9 days ago")
+	}
+
+	recent := base
+	recent.AirDate = "2026-03-10"
+	first, ok := svc.Schedule(recent)
+	if !ok {
+		t.Fatal("did not schedule an episode airing today")
+	}
+	if first.Status != models.SearchRetryPending || !first.NextAttemptAt.Equal(now.Add(minRetryBackoff)) {
+		t.Errorf("unexpected retry: %+v", first)
+	}
+
+	again, ok := svc.Schedule(recent)
+	if !ok || again.ID != first.ID {
+		t.Errorf("duplicate schedule created %s, want existing %s", again.ID, first.ID)
+	}
+	if n := len(svc.ListForUser("u1")); n != 1 {
+		t.Errorf("ListForUser returned %d retries, want 1", n)
+	}
+}
+
+func TestRetryDueBacksOffThenFinds(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	searcher := &fakeSearcher{err: errors.New("indexer down")}
+	svc := newTestService(t, searcher, now)
+
+	retry, ok := svc.Schedule(models.SearchRetry{UserID: "u1", TitleName: "Show", SeasonNumber: 1, EpisodeNumber: 1, AirDate: "2026-03-10", Query: "Show S01E01"})
+	if !ok {
+		t.Fatal("not scheduled")
+	}
+
+	// Not yet due
+	svc.retryDue(context.Background())
+	if len(searcher.calls) != 0 {
+		t.Fatalf("searched before the first backoff elapsed")
+	}
+
+	now = now.Add(minRetryBackoff)
+	svc.now = func() time.Time { return now }
+	svc.retryDue(context.Background())
+	got := svc.ListForUser("u1")[0]
+	if got.Attempts != 1 || got.LastError == "" || !got.NextAttemptAt.Equal(now.Add(2*minRetryBackoff)) {
+		t.Fatalf("after failed attempt: %+v", got)
+	}
+
+	searcher.err = nil
+	searcher.results = make([]models.NZBResult, maxKeptResults+2)
+	now = got.NextAttemptAt
+	svc.retryDue(context.Background())
+	got = svc.ListForUser("u1")[0]
+	if got.Status != models.SearchRetryFound || len(got.Results) != maxKeptResults || got.FoundAt == nil {
+		t.Fatalf("after successful attempt: %+v", got)
+	}
+	if searcher.calls[1].Query != retry.Query || searcher.calls[1].MediaType != "series" {
+		t.Errorf("unexpected search options: %+v", searcher.calls[1])
+	}
+}
+
+func TestRetryExpires(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	searcher := &fakeSearcher{}
+	svc := newTestService(t, searcher, now)
+
+	retry, _ := svc.Schedule(models.SearchRetry{UserID: "u1", TitleName: "Show", SeasonNumber: 1, EpisodeNumber: 1, AirDate: "2026-03-10", Query: "Show S01E01"})
+	now = retry.ExpiresAt
+	svc.now = func() time.Time { return now }
+	svc.retryDue(context.Background())
+
+	if got := svc.ListForUser("u1")[0]; got.Status != models.SearchRetryExpired {
+		t.Errorf("status = %q, want expired", got.Status)
+	}
+	if len(searcher.calls) != 0 {
+		t.Errorf("searched an expired retry")
+	}
+	if err := svc.Dismiss("u2", retry.ID); !errors.Is(err, ErrRetryNotFound) {
+		t.Errorf("Dismiss by another profile: %v", err)
+	}
+	if err := svc.Dismiss("u1", retry.ID); err != nil {
+		t.Errorf("Dismiss: %v", err)
+	}
+}
+
+func TestRecentlyAired(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	cases := map[string]bool{
+		"2026-03-11": true,  // tomorrow UTC, may be out already elsewhere
+		"2026-03-12": false, // not aired
+		"2026-03-10": true,
+		"2026-03-08": true, // ended 44h ago
+		"2026-03-07": false,
+		"":           false,
+	}
+	for airDate, want := range cases {
+		if got := recentlyAired(airDate, now, 48*time.Hour); got != want {
+			t.Errorf("recentlyAired(%q) = %v, want %v", airDate, got, want)
+		}
+	}
+}
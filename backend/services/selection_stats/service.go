@@ -0,0 +1,200 @@
+// Package selection_stats records the outcome of automatic release selection
+// per strategy, so admins can compare strategies' failure rates.
+package selection_stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// maxRecent bounds the recent outcomes kept for inspection.
+const maxRecent = 200
+
+// state is the persisted file layout.
+type state struct {
+	Strategies map[string]*models.SelectionStrategyStats `json:"strategies"`
+	Recent     []models.SelectionOutcome                 `json:"recent"`
+}
+
+// Service aggregates selection outcomes per strategy, backed by a JSON file on disk.
+type Service struct {
+	mu    sync.Mutex
+	path  string
+	state state
+	now   func() time.Time
+}
+
+// NewService constructs a selection stats store in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create selection stats dir: %w", err)
+	}
+
+	svc := &Service{
+		path:  filepath.Join(storageDir, "selection_stats.json"),
+		state: state{Strategies: make(map[string]*models.SelectionStrategyStats)},
+		now:   time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// RecordSelection logs and stores the outcome of one selection. Errors are
+// logged rather than returned since callers shouldn't fail because of metrics.
+func (s *Service) RecordSelection(outcome models.SelectionOutcome) {
+	if s == nil {
+		return
+	}
+
+	outcome.Strategy = strings.ToLower(strings.TrimSpace(outcome.Strategy))
+	if outcome.Strategy == "" {
+		outcome.Strategy = "unknown"
+	}
+	if outcome.At.IsZero() {
+		outcome.At = s.now().UTC()
+	}
+	if len(outcome.Error) > 512 {
+		outcome.Error = outcome.Error[:512]
+	}
+
+	log.Printf("[selection] strategy=%s outcome=%s candidates=%d service=%s media=%s release=%q",
+		outcome.Strategy, outcome.Outcome, outcome.Candidates, outcome.ServiceType, outcome.MediaType, outcome.Release)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.state.Strategies[outcome.Strategy]
+	if stats == nil {
+		stats = &models.SelectionStrategyStats{Strategy: outcome.Strategy}
+		s.state.Strategies[outcome.Strategy] = stats
+	}
+	stats.Selections++
+	stats.Candidates += int64(outcome.Candidates)
+	stats.LastAt = outcome.At
+	switch outcome.Outcome {
+	case models.SelectionOutcomeResolved:
+		stats.Resolved++
+		if outcome.Candidates == 1 {
+			stats.FirstPick++
+		}
+	default:
+		stats.Failed++
+	}
+
+	s.state.Recent = append(s.state.Recent, outcome)
+	if len(s.state.Recent) > maxRecent {
+		s.state.Recent = append([]models.SelectionOutcome(nil), s.state.Recent[len(s.state.Recent)-maxRecent:]...)
+	}
+
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[selection] failed to persist selection stats: %v", err)
+	}
+}
+
+// Summary returns per-strategy totals and rates, most used strategy first,
+// and up to limit recent outcomes, newest first.
+func (s *Service) Summary(limit int) *models.SelectionStatsSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := &models.SelectionStatsSummary{
+		Strategies: make([]models.SelectionStrategyStats, 0, len(s.state.Strategies)),
+		Recent:     make([]models.SelectionOutcome, 0),
+	}
+	for _, stats := range s.state.Strategies {
+		entry := *stats
+		if entry.Selections > 0 {
+			entry.FailureRate = float64(entry.Failed) / float64(entry.Selections)
+			entry.AvgCandidates = float64(entry.Candidates) / float64(entry.Selections)
+		}
+		if entry.Resolved > 0 {
+			entry.FirstPickRate = float64(entry.FirstPick) / float64(entry.Resolved)
+		}
+		summary.Strategies = append(summary.Strategies, entry)
+	}
+	sort.Slice(summary.Strategies, func(i, j int) bool {
+		if summary.Strategies[i].Selections != summary.Strategies[j].Selections {
+			return summary.Strategies[i].Selections > summary.Strategies[j].Selections
+		}
+		return summary.Strategies[i].Strategy < summary.Strategies[j].Strategy
+	})
+
+	for i := len(s.state.Recent) - 1; i >= 0 && (limit <= 0 || len(summary.Recent) < limit); i-- {
+		summary.Recent = append(summary.Recent, s.state.Recent[i])
+	}
+	return summary
+}
+
+// Reset clears all recorded outcomes, e.g. when starting a new experiment.
+func (s *Service) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = state{Strategies: make(map[string]*models.SelectionStrategyStats)}
+	return s.saveLocked()
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open selection stats: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read selection stats: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var loaded state
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("decode selection stats: %w", err)
+	}
+	if loaded.Strategies == nil {
+		loaded.Strategies = make(map[string]*models.SelectionStrategyStats)
+	}
+	s.state = loaded
+	return nil
+}
+
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode selection stats: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write selection stats: %w", err)
+	}
+	return nil
+}
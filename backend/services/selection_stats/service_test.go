@@ -0,0 +1,52 @@
+package selection_stats
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestSummaryComparesStrategies(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	svc.RecordSelection(models.SelectionOutcome{Strategy: "heuristic", Outcome: models.SelectionOutcomeResolved, Candidates: 1})
+	svc.RecordSelection(models.SelectionOutcome{Strategy: "heuristic", Outcome: models.SelectionOutcomeResolved, Candidates: 3})
+	svc.RecordSelection(models.SelectionOutcome{Strategy: "heuristic", Outcome: models.SelectionOutcomeFailed, Candidates: 4})
+	svc.RecordSelection(models.SelectionOutcome{Strategy: "Size-First", Outcome: models.SelectionOutcomeResolved, Candidates: 1})
+
+	// Reload to check persistence
+	svc, err = NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	summary := svc.Summary(2)
+
+	if len(summary.Strategies) != 2 {
+		t.Fatalf("expected 2 strategies, got %+v", summary.Strategies)
+	}
+	heuristic := summary.Strategies[0]
+	if heuristic.Strategy != "heuristic" || heuristic.Selections != 3 || heuristic.Failed != 1 || heuristic.FirstPick != 1 {
+		t.Fatalf("unexpected heuristic stats %+v", heuristic)
+	}
+	if heuristic.FailureRate != 1.0/3 || heuristic.FirstPickRate != 0.5 || heuristic.AvgCandidates != 8.0/3 {
+		t.Fatalf("unexpected heuristic rates %+v", heuristic)
+	}
+	if sizeFirst := summary.Strategies[1]; sizeFirst.Strategy != "size-first" || sizeFirst.FailureRate != 0 {
+		t.Fatalf("unexpected size-first stats %+v", sizeFirst)
+	}
+
+	if len(summary.Recent) != 2 || summary.Recent[0].Strategy != "size-first" {
+		t.Fatalf("expected newest outcomes first, got %+v", summary.Recent)
+	}
+
+	if err := svc.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if summary := svc.Summary(0); len(summary.Strategies) != 0 || len(summary.Recent) != 0 {
+		t.Fatalf("expected empty summary after reset, got %+v", summary)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +32,13 @@ const (
 
 	// TokenLength is the number of random bytes used for session tokens.
 	TokenLength = 32
+
+	// SourceAdminUI marks sessions created by the web admin UI login.
+	SourceAdminUI = "admin-ui"
+
+	// MaxExpiryTolerance is the longest a session is still accepted after it
+	// expires, so a client whose clock runs slow can still refresh in time.
+	MaxExpiryTolerance = 5 * time.Minute
 )
 
 // Service manages session tokens for authenticated accounts.
@@ -85,21 +93,38 @@ func (s *Service) CreatePersistent(accountID string, isMaster bool, userAgent, i
 
 // CreateWithDuration generates a new session with a custom duration.
 func (s *Service) CreateWithDuration(accountID string, isMaster bool, userAgent, ipAddress string, duration time.Duration) (models.Session, error) {
+	return s.create(models.Session{
+		AccountID: accountID,
+		IsMaster:  isMaster,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+	}, duration)
+}
+
+// CreateAdminUI generates a session for a web admin UI login. Admin UI
+// sessions are persisted like any other and can be listed with ListBySource.
+func (s *Service) CreateAdminUI(accountID string, isMaster bool, userAgent, ipAddress string, duration time.Duration, rememberMe bool) (models.Session, error) {
+	return s.create(models.Session{
+		AccountID:  accountID,
+		IsMaster:   isMaster,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		Source:     SourceAdminUI,
+		RememberMe: rememberMe,
+	}, duration)
+}
+
+// create fills in the token and timestamps of session and stores it.
+func (s *Service) create(session models.Session, duration time.Duration) (models.Session, error) {
 	token, err := generateToken()
 	if err != nil {
 		return models.Session{}, err
 	}
 
 	now := time.Now().UTC()
-	session := models.Session{
-		Token:     token,
-		AccountID: accountID,
-		IsMaster:  isMaster,
-		ExpiresAt: now.Add(duration),
-		CreatedAt: now,
-		UserAgent: userAgent,
-		IPAddress: ipAddress,
-	}
+	session.Token = token
+	session.CreatedAt = now
+	session.ExpiresAt = now.Add(duration)
 
 	s.mu.Lock()
 	s.sessions[token] = session
@@ -127,7 +152,7 @@ func (s *Service) Validate(token string) (models.Session, error) {
 		return models.Session{}, ErrSessionNotFound
 	}
 
-	if session.IsExpired() {
+	if expired(session, time.Now()) {
 		// Clean up expired session
 		s.mu.Lock()
 		delete(s.sessions, token)
@@ -183,6 +208,38 @@ func (s *Service) GetSessionsForAccount(accountID string) []models.Session {
 	return sessions
 }
 
+// ListBySource returns all active sessions created by source, newest first.
+func (s *Service) ListBySource(source string) []models.Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]models.Session, 0)
+	for _, session := range s.sessions {
+		if session.Source == source && !session.IsExpired() {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions
+}
+
+// RevokeByID invalidates the session with the given ID (see models.Session.ID)
+// and returns it.
+func (s *Service) RevokeByID(id string) (models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token, session := range s.sessions {
+		if session.ID() == id {
+			delete(s.sessions, token)
+			return session, s.saveLocked()
+		}
+	}
+	return models.Session{}, ErrSessionNotFound
+}
+
 // Refresh extends a session's expiration time.
 func (s *Service) Refresh(token string) (models.Session, error) {
 	s.mu.Lock()
@@ -193,7 +250,7 @@ func (s *Service) Refresh(token string) (models.Session, error) {
 		return models.Session{}, ErrSessionNotFound
 	}
 
-	if session.IsExpired() {
+	if expired(session, time.Now()) {
 		delete(s.sessions, token)
 		_ = s.saveLocked()
 		return models.Session{}, ErrSessionExpired
@@ -214,7 +271,7 @@ func (s *Service) Cleanup() int {
 	count := 0
 	now := time.Now()
 	for token, session := range s.sessions {
-		if now.After(session.ExpiresAt) {
+		if expired(session, now) {
 			delete(s.sessions, token)
 			count++
 		}
@@ -225,6 +282,14 @@ func (s *Service) Cleanup() int {
 	return count
 }
 
+// expired reports whether session is past its expiry plus the tolerance, which
+// is capped at a hundredth of the session's lifetime so short sessions aren't
+// noticeably extended.
+func expired(session models.Session, now time.Time) bool {
+	tolerance := min(MaxExpiryTolerance, session.ExpiresAt.Sub(session.CreatedAt)/100)
+	return now.After(session.ExpiresAt.Add(tolerance))
+}
+
 // cleanupLoop periodically removes expired sessions.
 func (s *Service) cleanupLoop() {
 	ticker := time.NewTicker(1 * time.Hour)
@@ -279,7 +344,7 @@ func (s *Service) load() error {
 		if strings.TrimSpace(session.Token) == "" {
 			continue
 		}
-		if now.After(session.ExpiresAt) {
+		if expired(session, now) {
 			continue // Skip expired sessions
 		}
 		s.sessions[session.Token] = session
@@ -511,6 +511,81 @@ func TestPersistence_DoesNotLoadExpired(t *testing.T) {
 	}
 }
 
+func TestCreateAdminUI_ListedBySource(t *testing.T) {
+	svc := setupTestService(t)
+
+	if _, err := svc.Create("account-123", false, "App", ""); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	first, err := svc.CreateAdminUI("account-123", false, "Browser", "10.0.0.1", time.Hour, false)
+	if err != nil {
+		t.Fatalf("CreateAdminUI failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	second, err := svc.CreateAdminUI("master", true, "Browser", "10.0.0.2", 24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("CreateAdminUI failed: %v", err)
+	}
+
+	listed := svc.ListBySource(SourceAdminUI)
+	if len(listed) != 2 {
+		t.Fatalf("expected 2 admin UI sessions, got %d", len(listed))
+	}
+	if listed[0].Token != second.Token || listed[1].Token != first.Token {
+		t.Errorf("expected newest session first")
+	}
+	if !listed[0].RememberMe || listed[1].RememberMe {
+		t.Errorf("expected rememberMe to be recorded per session")
+	}
+}
+
+func TestRevokeByID_Success(t *testing.T) {
+	svc := setupTestService(t)
+
+	session, err := svc.CreateAdminUI("account-123", false, "", "", time.Hour, false)
+	if err != nil {
+		t.Fatalf("CreateAdminUI failed: %v", err)
+	}
+
+	revoked, err := svc.RevokeByID(session.ID())
+	if err != nil {
+		t.Fatalf("RevokeByID failed: %v", err)
+	}
+	if revoked.AccountID != "account-123" {
+		t.Errorf("expected revoked session for account-123, got %q", revoked.AccountID)
+	}
+	if _, err := svc.Validate(session.Token); err != ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound after revoke, got %v", err)
+	}
+	if _, err := svc.RevokeByID(session.ID()); err != ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound for second revoke, got %v", err)
+	}
+}
+
+func TestCreateAdminUI_SurvivesRestart(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc, err := NewService(tmpDir, DefaultSessionDuration)
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+	session, err := svc.CreateAdminUI("account-123", false, "Browser", "10.0.0.1", 30*24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("CreateAdminUI failed: %v", err)
+	}
+
+	svc2, err := NewService(tmpDir, DefaultSessionDuration)
+	if err != nil {
+		t.Fatalf("NewService reload failed: %v", err)
+	}
+	loaded, err := svc2.Validate(session.Token)
+	if err != nil {
+		t.Fatalf("expected admin UI session to survive reload: %v", err)
+	}
+	if loaded.Source != SourceAdminUI || !loaded.RememberMe {
+		t.Errorf("expected source and rememberMe to persist, got %q/%v", loaded.Source, loaded.RememberMe)
+	}
+}
+
 func TestGenerateToken_Length(t *testing.T) {
 	token, err := generateToken()
 	if err != nil {
@@ -536,3 +611,30 @@ func TestGenerateToken_Uniqueness(t *testing.T) {
 		tokens[token] = true
 	}
 }
+
+func TestValidate_ToleratesRecentExpiry(t *testing.T) {
+	svc := setupTestServiceWithDuration(t, time.Hour)
+	session, err := svc.Create("account-1", false, "", "")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// An hour-long session is accepted for 36s past its expiry
+	session.ExpiresAt = time.Now().Add(-20 * time.Second)
+	session.CreatedAt = session.ExpiresAt.Add(-time.Hour)
+	svc.sessions[session.Token] = session
+	if _, err := svc.Validate(session.Token); err != nil {
+		t.Fatalf("expected session within the expiry tolerance to validate, got %v", err)
+	}
+	refreshed, err := svc.Refresh(session.Token)
+	if err != nil || !refreshed.ExpiresAt.After(time.Now()) {
+		t.Fatalf("expected refresh within the expiry tolerance, got %v", err)
+	}
+
+	refreshed.ExpiresAt = time.Now().Add(-time.Minute)
+	refreshed.CreatedAt = refreshed.ExpiresAt.Add(-time.Hour)
+	svc.sessions[session.Token] = refreshed
+	if _, err := svc.Validate(session.Token); err != ErrSessionExpired {
+		t.Fatalf("expected ErrSessionExpired past the tolerance, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package share_links issues expiring, view-limited links to a single
+// resolved stream so a profile can send a one-off watch link to someone who
+// has no account, without sharing a PIN or a session.
+package share_links
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrPathRequired       = errors.New("stream path is required")
+	ErrLinkNotFound       = errors.New("share link not found")
+	ErrLinkExpired        = errors.New("share link has expired")
+	ErrViewLimitReached   = errors.New("share link has no views left")
+	ErrTooManyLinks       = fmt.Errorf("a profile can have at most %d active share links", maxActiveLinksPerUser)
+)
+
+const (
+	DefaultTTL      = 24 * time.Hour
+	MaxTTL          = 7 * 24 * time.Hour
+	DefaultMaxViews = 1
+	MaxViews        = 20
+
+	maxActiveLinksPerUser = 50
+
+	// viewIdleWindow groups a viewer's requests into one view: players make
+	// many range requests while seeking, and a viewer who pauses for a while
+	// shouldn't use up another view when they resume.
+	viewIdleWindow = 30 * time.Minute
+
+	// finishedRetention is how long expired and revoked links stay listed
+	finishedRetention = 7 * 24 * time.Hour
+
+	tokenBytes = 32
+)
+
+// Service stores share links in a JSON file on disk and admits viewers.
+type Service struct {
+	mu    sync.Mutex
+	path  string
+	links map[string]*models.ShareLink // by ID
+	// viewers tracks the active view of each client per link, in memory only:
+	// after a restart a returning viewer counts as a new view
+	viewers map[string]map[string]time.Time
+	now     func() time.Time
+}
+
+// NewService constructs a share link store in storageDir.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create share links dir: %w", err)
+	}
+
+	svc := &Service{
+		path:    filepath.Join(storageDir, "share_links.json"),
+		links:   make(map[string]*models.ShareLink),
+		viewers: make(map[string]map[string]time.Time),
+		now:     time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Create issues a link to a resolved stream. A non-positive ttl or maxViews
+// uses the default; larger values are capped at MaxTTL and MaxViews.
+func (s *Service) Create(userID, accountID, streamPath, title string, ttl time.Duration, maxViews int) (models.ShareLink, error) {
+	streamPath = strings.TrimSpace(streamPath)
+	if streamPath == "" {
+		return models.ShareLink{}, ErrPathRequired
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > MaxTTL {
+		ttl = MaxTTL
+	}
+	if maxViews <= 0 {
+		maxViews = DefaultMaxViews
+	}
+	if maxViews > MaxViews {
+		maxViews = MaxViews
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return models.ShareLink{}, fmt.Errorf("generate share token: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	active := 0
+	for _, link := range s.links {
+		if link.UserID == userID && linkUsable(link, now) {
+			active++
+		}
+	}
+	if active >= maxActiveLinksPerUser {
+		return models.ShareLink{}, ErrTooManyLinks
+	}
+
+	link := models.ShareLink{
+		ID:        uuid.NewString(),
+		Token:     token,
+		UserID:    userID,
+		AccountID: accountID,
+		Title:     strings.TrimSpace(title),
+		Path:      streamPath,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		MaxViews:  maxViews,
+	}
+	s.links[link.ID] = &link
+	if err := s.saveLocked(); err != nil {
+		delete(s.links, link.ID)
+		return models.ShareLink{}, err
+	}
+
+	log.Printf("[share_links] user %s shared %q until %s (%d views)", userID, link.Title, link.ExpiresAt.Format(time.RFC3339), maxViews)
+	return link, nil
+}
+
+// ListForUser returns a profile's share links, newest first.
+func (s *Service) ListForUser(userID string) []models.ShareLink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]models.ShareLink, 0)
+	for _, link := range s.links {
+		if link.UserID == userID {
+			list = append(list, *link)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Revoke stops one of a profile's links from admitting anyone, including
+// viewers who are mid-stream.
+func (s *Service) Revoke(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[id]
+	if !ok || link.UserID != userID {
+		return ErrLinkNotFound
+	}
+	if link.RevokedAt == nil {
+		now := s.now().UTC()
+		link.RevokedAt = &now
+	}
+	delete(s.viewers, id)
+	return s.saveLocked()
+}
+
+// Admit checks a request for a shared stream. A viewer is identified by
+// viewerKey (the client address); their first request starts a view, and
+// further requests within viewIdleWindow of the previous one belong to the
+// same view. Revoked links are reported as not found.
+func (s *Service) Admit(token, viewerKey string) (models.ShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findByTokenLocked(token)
+	if link == nil || link.RevokedAt != nil {
+		return models.ShareLink{}, ErrLinkNotFound
+	}
+
+	now := s.now().UTC()
+	if !now.Before(link.ExpiresAt) {
+		delete(s.viewers, link.ID)
+		return models.ShareLink{}, ErrLinkExpired
+	}
+
+	viewers := s.viewers[link.ID]
+	if lastSeen, ok := viewers[viewerKey]; ok && now.Sub(lastSeen) < viewIdleWindow {
+		viewers[viewerKey] = now
+		return *link, nil
+	}
+
+	if link.Views >= link.MaxViews {
+		return models.ShareLink{}, ErrViewLimitReached
+	}
+	if viewers == nil {
+		viewers = make(map[string]time.Time)
+		s.viewers[link.ID] = viewers
+	}
+	viewers[viewerKey] = now
+	link.Views++
+	link.LastViewAt = &now
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[share_links] failed to persist share links: %v", err)
+	}
+
+	log.Printf("[share_links] link %s viewed (%d/%d) from %s", link.ID, link.Views, link.MaxViews, viewerKey)
+	return *link, nil
+}
+
+// PruneExpired deletes links that expired or were revoked more than a week
+// ago and forgets idle viewers. It returns how many links were deleted.
+func (s *Service) PruneExpired() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	removed := 0
+	for id, link := range s.links {
+		ended := link.ExpiresAt
+		if link.RevokedAt != nil && link.RevokedAt.Before(ended) {
+			ended = *link.RevokedAt
+		}
+		if now.Sub(ended) > finishedRetention {
+			delete(s.links, id)
+			delete(s.viewers, id)
+			removed++
+		}
+	}
+	for id, viewers := range s.viewers {
+		for key, lastSeen := range viewers {
+			if now.Sub(lastSeen) >= viewIdleWindow {
+				delete(viewers, key)
+			}
+		}
+		if len(viewers) == 0 {
+			delete(s.viewers, id)
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.saveLocked()
+}
+
+// findByTokenLocked returns the link with the given token, or nil.
+// Must be called with s.mu held.
+func (s *Service) findByTokenLocked(token string) *models.ShareLink {
+	if token == "" {
+		return nil
+	}
+	for _, link := range s.links {
+		if link.Token == token {
+			return link
+		}
+	}
+	return nil
+}
+
+// linkUsable reports whether a link can still admit new views.
+func linkUsable(link *models.ShareLink, now time.Time) bool {
+	return link.RevokedAt == nil && now.Before(link.ExpiresAt) && link.Views < link.MaxViews
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open share links: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read share links: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var links []models.ShareLink
+	if err := json.Unmarshal(data, &links); err != nil {
+		return fmt.Errorf("decode share links: %w", err)
+	}
+	for i := range links {
+		link := links[i]
+		if link.ID == "" || link.Token == "" {
+			continue
+		}
+		s.links[link.ID] = &link
+	}
+	return nil
+}
+
+// saveLocked writes the share links to disk, oldest first. The file holds
+// live tokens, so it is only readable by the server's user.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]models.ShareLink, 0, len(s.links))
+	for _, link := range s.links {
+		list = append(list, *link)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode share links: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("write share links: %w", err)
+	}
+
+	return nil
+}
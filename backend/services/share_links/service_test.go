@@ -0,0 +1,102 @@
+package share_links
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, now *time.Time) *Service {
+	t.Helper()
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	svc.now = func() time.Time { return *now }
+	return svc
+}
+
+func TestAdmitCountsViewsPerViewer(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	svc := newTestService(t, &now)
+
+	link, err := svc.Create("u1", "acct", "/webdav/movie.mkv", "Movie", 0, 2)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !link.ExpiresAt.Equal(now.Add(DefaultTTL)) || link.MaxViews != 2 || len(link.Token) < 40 {
+		t.Fatalf("unexpected link: %+v", link)
+	}
+
+	// Range requests from the same viewer belong to one view
+	for i := 0; i < 3; i++ {
+		if _, err := svc.Admit(link.Token, "10.0.0.1"); err != nil {
+			t.Fatalf("Admit first viewer: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	if _, err := svc.Admit(link.Token, "10.0.0.2"); err != nil {
+		t.Fatalf("Admit second viewer: %v", err)
+	}
+	if _, err := svc.Admit(link.Token, "10.0.0.3"); !errors.Is(err, ErrViewLimitReached) {
+		t.Fatalf("expected ErrViewLimitReached for a third viewer, got %v", err)
+	}
+
+	// An active viewer keeps watching, an idle one needs a new view
+	if _, err := svc.Admit(link.Token, "10.0.0.2"); err != nil {
+		t.Fatalf("active viewer refused: %v", err)
+	}
+	now = now.Add(viewIdleWindow)
+	if _, err := svc.Admit(link.Token, "10.0.0.1"); !errors.Is(err, ErrViewLimitReached) {
+		t.Fatalf("expected ErrViewLimitReached after idling, got %v", err)
+	}
+
+	if got := svc.ListForUser("u1"); len(got) != 1 || got[0].Views != 2 {
+		t.Fatalf("unexpected stored links: %+v", got)
+	}
+}
+
+func TestAdmitExpiredAndRevoked(t *testing.T) {
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	svc := newTestService(t, &now)
+
+	if _, err := svc.Create("u1", "", " ", "", time.Hour, 1); !errors.Is(err, ErrPathRequired) {
+		t.Fatalf("expected ErrPathRequired, got %v", err)
+	}
+
+	expiring, _ := svc.Create("u1", "", "/webdav/a.mkv", "", time.Hour, 5)
+	revoked, _ := svc.Create("u1", "", "/webdav/b.mkv", "", 30*24*time.Hour, 5)
+	if !revoked.ExpiresAt.Equal(now.Add(MaxTTL)) {
+		t.Errorf("ttl not capped: expires %s", revoked.ExpiresAt)
+	}
+
+	if _, err := svc.Admit(revoked.Token, "viewer"); err != nil {
+		t.Fatalf("Admit: %v", err)
+	}
+	if err := svc.Revoke("u2", revoked.ID); !errors.Is(err, ErrLinkNotFound) {
+		t.Fatalf("revoked another profile's link: %v", err)
+	}
+	if err := svc.Revoke("u1", revoked.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := svc.Admit(revoked.Token, "viewer"); !errors.Is(err, ErrLinkNotFound) {
+		t.Fatalf("expected revoked link to stop an active viewer, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := svc.Admit(expiring.Token, "viewer"); !errors.Is(err, ErrLinkExpired) {
+		t.Fatalf("expected ErrLinkExpired, got %v", err)
+	}
+	if _, err := svc.Admit("unknown", "viewer"); !errors.Is(err, ErrLinkNotFound) {
+		t.Fatalf("expected ErrLinkNotFound, got %v", err)
+	}
+
+	// Both are kept for a week after they ended, then pruned
+	if removed, _ := svc.PruneExpired(); removed != 0 {
+		t.Fatalf("pruned %d links too early", removed)
+	}
+	now = now.Add(finishedRetention + time.Hour)
+	if removed, err := svc.PruneExpired(); err != nil || removed != 2 {
+		t.Fatalf("PruneExpired = %d, %v; want 2", removed, err)
+	}
+}
@@ -0,0 +1,387 @@
+// Package spoiler_holds lets a profile hold a range of a series' episodes so
+// the other profiles in its household get a spoiler warning on them until
+// the holding profile has caught up.
+package spoiler_holds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrProfileIDRequired  = errors.New("profile id is required")
+	ErrSeriesIDRequired   = errors.New("seriesId is required")
+	ErrInvalidRange       = errors.New("invalid episode range")
+	ErrHoldNotFound       = errors.New("spoiler hold not found")
+	ErrTooManyHolds       = errors.New("too many spoiler holds for this profile")
+)
+
+// maxHoldsPerProfile bounds how many holds one profile can place.
+const maxHoldsPerProfile = 50
+
+// ProfileDirectory looks up profiles to find which ones share a household.
+type ProfileDirectory interface {
+	Get(id string) (models.User, bool)
+}
+
+// WatchedEpisodesSource reports which episodes of a series a profile has watched.
+type WatchedEpisodesSource interface {
+	ListWatchedEpisodes(userID, seriesID string) ([]models.EpisodeReference, error)
+}
+
+// Service persists spoiler holds and annotates series metadata with the
+// warnings they produce, backed by a JSON file on disk.
+type Service struct {
+	mu       sync.RWMutex
+	path     string
+	holds    map[string]*models.SpoilerHold // id -> hold
+	profiles ProfileDirectory
+	watched  WatchedEpisodesSource
+	now      func() time.Time
+}
+
+// NewService constructs a spoiler hold service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spoiler holds dir: %w", err)
+	}
+
+	svc := &Service{
+		path:  filepath.Join(storageDir, "spoiler_holds.json"),
+		holds: make(map[string]*models.SpoilerHold),
+		now:   time.Now,
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// SetProfileDirectory sets how households are resolved. Without it no
+// warnings are produced.
+func (s *Service) SetProfileDirectory(profiles ProfileDirectory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles = profiles
+}
+
+// SetWatchedEpisodesSource sets where the holding profile's progress is read.
+func (s *Service) SetWatchedEpisodesSource(source WatchedEpisodesSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watched = source
+}
+
+// List returns a profile's holds, newest first.
+func (s *Service) List(profileID string) []models.SpoilerHold {
+	profileID = strings.TrimSpace(profileID)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	holds := make([]models.SpoilerHold, 0)
+	for _, hold := range s.holds {
+		if hold.ProfileID == profileID {
+			holds = append(holds, *hold)
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].CreatedAt.After(holds[j].CreatedAt)
+	})
+	return holds
+}
+
+// Create places a hold for a profile.
+func (s *Service) Create(profileID string, req models.SpoilerHoldRequest) (*models.SpoilerHold, error) {
+	profileID = strings.TrimSpace(profileID)
+	if profileID == "" {
+		return nil, ErrProfileIDRequired
+	}
+	hold := models.SpoilerHold{
+		ProfileID:   profileID,
+		SeriesID:    strings.TrimSpace(req.SeriesID),
+		SeriesName:  strings.TrimSpace(req.SeriesName),
+		FromSeason:  req.FromSeason,
+		FromEpisode: req.FromEpisode,
+		ToSeason:    req.ToSeason,
+		ToEpisode:   req.ToEpisode,
+	}
+	if hold.SeriesID == "" {
+		return nil, ErrSeriesIDRequired
+	}
+	if hold.FromEpisode <= 0 {
+		hold.FromEpisode = 1
+	}
+	if hold.ToSeason == 0 {
+		hold.ToSeason = hold.FromSeason
+	}
+	if hold.FromSeason < 0 || hold.ToEpisode < 0 || hold.ToSeason < hold.FromSeason ||
+		(hold.ToSeason == hold.FromSeason && hold.ToEpisode > 0 && hold.ToEpisode < hold.FromEpisode) {
+		return nil, ErrInvalidRange
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, existing := range s.holds {
+		if existing.ProfileID == profileID {
+			count++
+		}
+	}
+	if count >= maxHoldsPerProfile {
+		return nil, ErrTooManyHolds
+	}
+
+	hold.ID = uuid.NewString()
+	hold.CreatedAt = s.now().UTC()
+	s.holds[hold.ID] = &hold
+
+	if err := s.saveLocked(); err != nil {
+		delete(s.holds, hold.ID)
+		return nil, err
+	}
+	log.Printf("[spoiler_holds] profile=%s holding %s S%02dE%02d-S%02dE%02d",
+		profileID, hold.SeriesID, hold.FromSeason, hold.FromEpisode, hold.ToSeason, hold.ToEpisode)
+
+	created := hold
+	return &created, nil
+}
+
+// Delete removes one of a profile's holds.
+func (s *Service) Delete(profileID, holdID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[strings.TrimSpace(holdID)]
+	if !ok || hold.ProfileID != strings.TrimSpace(profileID) {
+		return ErrHoldNotFound
+	}
+	delete(s.holds, hold.ID)
+	return s.saveLocked()
+}
+
+// Annotate returns details with a spoiler warning on every episode held by
+// another profile in the viewer's household that the holder hasn't watched
+// yet. Holds whose episodes have all been watched are released. details is
+// shared with the metadata cache, so annotated seasons are copied rather
+// than modified in place.
+func (s *Service) Annotate(viewerID string, details *models.SeriesDetails) *models.SeriesDetails {
+	viewerID = strings.TrimSpace(viewerID)
+	if details == nil || viewerID == "" {
+		return details
+	}
+
+	s.mu.RLock()
+	profiles, watchedSource := s.profiles, s.watched
+	var holds []models.SpoilerHold
+	for _, hold := range s.holds {
+		if hold.ProfileID != viewerID && strings.EqualFold(hold.SeriesID, details.Title.ID) {
+			holds = append(holds, *hold)
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(holds) == 0 || profiles == nil {
+		return details
+	}
+	viewer, ok := profiles.Get(viewerID)
+	if !ok {
+		return details
+	}
+
+	// Oldest hold first, so holders are listed in the order they asked
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].CreatedAt.Before(holds[j].CreatedAt)
+	})
+
+	warnings := make(map[[2]int]*models.SpoilerWarning)
+	var released []string
+	for _, hold := range holds {
+		holder, ok := profiles.Get(hold.ProfileID)
+		if !ok {
+			// Profile was deleted
+			released = append(released, hold.ID)
+			continue
+		}
+		if holder.AccountID != viewer.AccountID {
+			continue
+		}
+
+		watched := s.watchedEpisodes(watchedSource, hold)
+		covered, pending := 0, 0
+		for _, season := range details.Seasons {
+			for _, episode := range season.Episodes {
+				if !hold.Covers(episode.SeasonNumber, episode.EpisodeNumber) {
+					continue
+				}
+				covered++
+				key := [2]int{episode.SeasonNumber, episode.EpisodeNumber}
+				if watched[key] {
+					continue
+				}
+				pending++
+				warning := warnings[key]
+				if warning == nil {
+					warning = &models.SpoilerWarning{}
+					warnings[key] = warning
+				}
+				warning.HeldBy = append(warning.HeldBy, models.SpoilerHolder{
+					ProfileID:   holder.ID,
+					ProfileName: holder.Name,
+				})
+			}
+		}
+		if covered > 0 && pending == 0 {
+			released = append(released, hold.ID)
+		}
+	}
+
+	if len(released) > 0 {
+		s.release(released)
+	}
+	if len(warnings) == 0 {
+		return details
+	}
+
+	annotated := *details
+	annotated.Seasons = make([]models.SeriesSeason, len(details.Seasons))
+	for i, season := range details.Seasons {
+		annotated.Seasons[i] = season
+		if !seasonHasWarning(season, warnings) {
+			continue
+		}
+		episodes := make([]models.SeriesEpisode, len(season.Episodes))
+		for j, episode := range season.Episodes {
+			episode.SpoilerWarning = warnings[[2]int{episode.SeasonNumber, episode.EpisodeNumber}]
+			episodes[j] = episode
+		}
+		annotated.Seasons[i].Episodes = episodes
+	}
+	return &annotated
+}
+
+// watchedEpisodes returns the episodes the holder has watched, keyed by season and episode.
+func (s *Service) watchedEpisodes(source WatchedEpisodesSource, hold models.SpoilerHold) map[[2]int]bool {
+	watched := make(map[[2]int]bool)
+	if source == nil {
+		return watched
+	}
+	episodes, err := source.ListWatchedEpisodes(hold.ProfileID, hold.SeriesID)
+	if err != nil {
+		log.Printf("[spoiler_holds] failed to load watched episodes for profile=%s series=%s: %v",
+			hold.ProfileID, hold.SeriesID, err)
+		return watched
+	}
+	for _, episode := range episodes {
+		watched[[2]int{episode.SeasonNumber, episode.EpisodeNumber}] = true
+	}
+	return watched
+}
+
+// release removes holds the holder has caught up on.
+func (s *Service) release(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, id := range ids {
+		if hold, ok := s.holds[id]; ok {
+			log.Printf("[spoiler_holds] releasing hold %s on %s for profile=%s", id, hold.SeriesID, hold.ProfileID)
+			delete(s.holds, id)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return
+	}
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[spoiler_holds] failed to persist released holds: %v", err)
+	}
+}
+
+func seasonHasWarning(season models.SeriesSeason, warnings map[[2]int]*models.SpoilerWarning) bool {
+	for _, episode := range season.Episodes {
+		if warnings[[2]int{episode.SeasonNumber, episode.EpisodeNumber}] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open spoiler holds: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read spoiler holds: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var holds []*models.SpoilerHold
+	if err := json.Unmarshal(data, &holds); err != nil {
+		return fmt.Errorf("decode spoiler holds: %w", err)
+	}
+	for _, hold := range holds {
+		if hold != nil && hold.ID != "" {
+			s.holds[hold.ID] = hold
+		}
+	}
+
+	log.Printf("[spoiler_holds] loaded %d holds", len(s.holds))
+	return nil
+}
+
+// saveLocked writes the holds to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	holds := make([]*models.SpoilerHold, 0, len(s.holds))
+	for _, hold := range s.holds {
+		holds = append(holds, hold)
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].CreatedAt.Before(holds[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(holds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode spoiler holds: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write spoiler holds: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package spoiler_holds
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"novastream/models"
+)
+
+type fakeProfiles map[string]models.User
+
+func (f fakeProfiles) Get(id string) (models.User, bool) {
+	user, ok := f[id]
+	return user, ok
+}
+
+type fakeWatched map[string][]models.EpisodeReference
+
+func (f fakeWatched) ListWatchedEpisodes(userID, seriesID string) ([]models.EpisodeReference, error) {
+	return f[userID], nil
+}
+
+func testSeries() *models.SeriesDetails {
+	details := &models.SeriesDetails{Title: models.Title{ID: "tmdb:tv:100"}}
+	for season := 1; season <= 2; season++ {
+		s := models.SeriesSeason{Number: season}
+		for episode := 1; episode <= 3; episode++ {
+			s.Episodes = append(s.Episodes, models.SeriesEpisode{SeasonNumber: season, EpisodeNumber: episode})
+		}
+		details.Seasons = append(details.Seasons, s)
+	}
+	return details
+}
+
+func TestCreateValidatesRange(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  models.SpoilerHoldRequest
+		want error
+	}{
+		{"no series", models.SpoilerHoldRequest{FromSeason: 1}, ErrSeriesIDRequired},
+		{"seasons reversed", models.SpoilerHoldRequest{SeriesID: "tmdb:tv:100", FromSeason: 2, ToSeason: 1}, ErrInvalidRange},
+		{"episodes reversed", models.SpoilerHoldRequest{SeriesID: "tmdb:tv:100", FromSeason: 1, FromEpisode: 3, ToEpisode: 2}, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Create("alice", tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	hold, err := svc.Create("alice", models.SpoilerHoldRequest{SeriesID: "tmdb:tv:100", FromSeason: 2})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if hold.FromEpisode != 1 || hold.ToSeason != 2 || hold.ToEpisode != 0 {
+		t.Errorf("range defaults = %+v, want the whole of season 2", hold)
+	}
+}
+
+func TestAnnotateWarnsHouseholdUntilHolderCatchesUp(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	watched := fakeWatched{}
+	svc.SetProfileDirectory(fakeProfiles{
+		"alice":    {ID: "alice", AccountID: "home", Name: "Alice"},
+		"bob":      {ID: "bob", AccountID: "home", Name: "Bob"},
+		"neighbor": {ID: "neighbor", AccountID: "other", Name: "Neighbor"},
+	})
+	svc.SetWatchedEpisodesSource(watched)
+
+	if _, err := svc.Create("alice", models.SpoilerHoldRequest{SeriesID: "TMDB:tv:100", FromSeason: 1, FromEpisode: 2, ToSeason: 2, ToEpisode: 1}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	series := testSeries()
+	annotated := svc.Annotate("bob", series)
+	var flagged []string
+	for _, season := range annotated.Seasons {
+		for _, episode := range season.Episodes {
+			if episode.SpoilerWarning != nil {
+				if holder := episode.SpoilerWarning.HeldBy[0]; holder.ProfileName != "Alice" {
+					t.Errorf("held by %+v, want Alice", holder)
+				}
+				flagged = append(flagged, episodeLabel(episode))
+			}
+		}
+	}
+	if got, want := fmt.Sprint(flagged), "[1x2 1x3 2x1]"; got != want {
+		t.Errorf("flagged episodes = %s, want %s", got, want)
+	}
+	if series.Seasons[0].Episodes[1].SpoilerWarning != nil {
+		t.Error("annotation modified the shared details")
+	}
+
+	// The holder and other households see no warnings
+	for _, viewer := range []string{"alice", "neighbor"} {
+		if got := svc.Annotate(viewer, series); got != series {
+			t.Errorf("%s got annotated details", viewer)
+		}
+	}
+
+	// Watched episodes stop warning, and catching up releases the hold
+	watched["alice"] = []models.EpisodeReference{{SeasonNumber: 1, EpisodeNumber: 2}, {SeasonNumber: 1, EpisodeNumber: 3}}
+	annotated = svc.Annotate("bob", series)
+	if annotated.Seasons[0].Episodes[2].SpoilerWarning != nil || annotated.Seasons[1].Episodes[0].SpoilerWarning == nil {
+		t.Errorf("after partial catch-up got %+v", annotated.Seasons)
+	}
+
+	watched["alice"] = append(watched["alice"], models.EpisodeReference{SeasonNumber: 2, EpisodeNumber: 1})
+	if got := svc.Annotate("bob", series); got != series {
+		t.Error("expected no warnings once the holder caught up")
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if holds := reloaded.List("alice"); len(holds) != 0 {
+		t.Errorf("hold not released: %+v", holds)
+	}
+}
+
+func episodeLabel(episode models.SeriesEpisode) string {
+	return fmt.Sprintf("%dx%d", episode.SeasonNumber, episode.EpisodeNumber)
+}
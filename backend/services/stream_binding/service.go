@@ -0,0 +1,225 @@
+// Package stream_binding ties stream URLs to the devices and networks a
+// session is actually used from. Stream URLs carry the session token in the
+// query string because players can't set headers, so they end up in player
+// logs, share sheets and screenshots. Requests authenticated with a header
+// come from the app itself and record where the session is in use; requests
+// authenticated from the query string are then only accepted from there.
+package stream_binding
+
+import (
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+)
+
+var (
+	ErrNetworkNotBound = errors.New("stream url is not bound to this network")
+	ErrClientNotBound  = errors.New("stream url is not bound to this device")
+)
+
+const (
+	// How long a network or client stays bound after the app last made an
+	// authenticated API call from it
+	bindingWindow = 24 * time.Hour
+
+	// Bounds what is remembered per session, dropping the least recently seen
+	maxBindingsPerSession = 32
+
+	// Settings are re-read at most this often since every segment request
+	// is checked
+	settingsTTL = 30 * time.Second
+
+	sweepInterval = 10 * time.Minute
+)
+
+// settingsLoader loads the server settings.
+type settingsLoader interface {
+	Load() (config.Settings, error)
+}
+
+var _ settingsLoader = (*config.Manager)(nil)
+
+// sessionBindings records where a session's app was last seen.
+type sessionBindings struct {
+	ips      map[string]time.Time
+	clients  map[string]time.Time
+	lastSeen time.Time
+}
+
+// Service tracks session bindings in memory; after a restart streams are
+// accepted once the app makes its next API call.
+type Service struct {
+	mu        sync.Mutex
+	cfg       settingsLoader
+	settings  config.StreamBindingSettings
+	loadedAt  time.Time
+	sessions  map[string]*sessionBindings
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewService constructs a stream binding service.
+func NewService(cfg settingsLoader) *Service {
+	return &Service{
+		cfg:      cfg,
+		sessions: make(map[string]*sessionBindings),
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether stream URLs are bound.
+func (s *Service) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.settingsLocked().Enabled
+}
+
+// Observe records that the session's app made an authenticated API call from
+// ip, as the device identified by clientID (which may be empty).
+func (s *Service) Observe(token, ip, clientID string) {
+	if token == "" {
+		return
+	}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	bindings := s.sessions[token]
+	if bindings == nil {
+		bindings = &sessionBindings{ips: make(map[string]time.Time), clients: make(map[string]time.Time)}
+		s.sessions[token] = bindings
+	}
+	bindings.lastSeen = now
+	if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil {
+		remember(bindings.ips, parsed.String(), now)
+	}
+	if clientID = strings.TrimSpace(clientID); clientID != "" {
+		remember(bindings.clients, clientID, now)
+	}
+}
+
+// Check reports whether a stream request authenticated with token may be
+// served to ip and clientID under the current settings.
+func (s *Service) Check(token, ip, clientID string) error {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.settingsLocked()
+	if !settings.Enabled {
+		return nil
+	}
+	bindings := s.sessions[token]
+	match := normalizeMatch(settings.Match)
+
+	if match != config.StreamBindingClient && !s.networkBoundLocked(bindings, ip, settings.CGNAT, now) {
+		return ErrNetworkNotBound
+	}
+	if match != config.StreamBindingNetwork {
+		clientID = strings.TrimSpace(clientID)
+		if bindings == nil || clientID == "" || now.Sub(bindings.clients[clientID]) > bindingWindow {
+			return ErrClientNotBound
+		}
+	}
+	return nil
+}
+
+// networkBoundLocked reports whether ip shares a network with an address the
+// session was recently seen from.
+// Must be called with s.mu held.
+func (s *Service) networkBoundLocked(bindings *sessionBindings, ip string, cgnat bool, now time.Time) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || bindings == nil {
+		return false
+	}
+	network := networkOf(parsed, cgnat)
+	for seen, at := range bindings.ips {
+		if now.Sub(at) > bindingWindow {
+			continue
+		}
+		if network.Contains(net.ParseIP(seen)) {
+			return true
+		}
+	}
+	return false
+}
+
+// settingsLocked returns the binding settings, reloading them when stale.
+// Must be called with s.mu held.
+func (s *Service) settingsLocked() config.StreamBindingSettings {
+	if s.cfg == nil || s.now().Sub(s.loadedAt) < settingsTTL {
+		return s.settings
+	}
+	settings, err := s.cfg.Load()
+	if err != nil {
+		log.Printf("[stream_binding] failed to load settings, keeping previous: %v", err)
+	} else {
+		s.settings = settings.Streaming.Binding
+	}
+	s.loadedAt = s.now()
+	return s.settings
+}
+
+// sweepLocked drops sessions not seen within the binding window.
+// Must be called with s.mu held.
+func (s *Service) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for token, bindings := range s.sessions {
+		if now.Sub(bindings.lastSeen) > bindingWindow {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// remember marks key as seen, evicting the least recently seen entry when
+// the map is full.
+func remember(seen map[string]time.Time, key string, now time.Time) {
+	if _, ok := seen[key]; !ok && len(seen) >= maxBindingsPerSession {
+		oldest := ""
+		for k, at := range seen {
+			if oldest == "" || at.Before(seen[oldest]) {
+				oldest = k
+			}
+		}
+		delete(seen, oldest)
+	}
+	seen[key] = now
+}
+
+// networkOf returns the network ip is matched on.
+func networkOf(ip net.IP, cgnat bool) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		ones := 24
+		if cgnat {
+			ones = 16
+		}
+		mask := net.CIDRMask(ones, 32)
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}
+	}
+	ones := 64
+	if cgnat {
+		ones = 48
+	}
+	mask := net.CIDRMask(ones, 128)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+func normalizeMatch(match string) string {
+	switch match = strings.ToLower(strings.TrimSpace(match)); match {
+	case config.StreamBindingClient, config.StreamBindingBoth:
+		return match
+	}
+	return config.StreamBindingNetwork
+}
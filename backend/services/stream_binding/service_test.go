@@ -0,0 +1,95 @@
+package stream_binding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"novastream/config"
+)
+
+type staticSettings config.StreamBindingSettings
+
+func (s staticSettings) Load() (config.Settings, error) {
+	var settings config.Settings
+	settings.Streaming.Binding = config.StreamBindingSettings(s)
+	return settings, nil
+}
+
+func newTestService(settings config.StreamBindingSettings) (*Service, *time.Time) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	svc := NewService(staticSettings(settings))
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestCheckNetworkBinding(t *testing.T) {
+	svc, now := newTestService(config.StreamBindingSettings{Enabled: true})
+	svc.Observe("token", "203.0.113.7", "tv")
+
+	if err := svc.Check("token", "203.0.113.42", ""); err != nil {
+		t.Errorf("expected another address on the home network to be allowed, got %v", err)
+	}
+	if err := svc.Check("token", "198.51.100.9", ""); !errors.Is(err, ErrNetworkNotBound) {
+		t.Errorf("expected a replay from another network to be refused, got %v", err)
+	}
+	if err := svc.Check("other", "203.0.113.7", ""); !errors.Is(err, ErrNetworkNotBound) {
+		t.Errorf("expected an unseen session to be refused, got %v", err)
+	}
+	if err := svc.Check("token", "127.0.0.1", ""); !errors.Is(err, ErrNetworkNotBound) {
+		t.Errorf("expected a loopback address to get no exemption from the service, got %v", err)
+	}
+
+	// Roaming: the app calls the API from the new network first
+	svc.Observe("token", "198.51.100.9", "tv")
+	if err := svc.Check("token", "198.51.100.9", ""); err != nil {
+		t.Errorf("expected the new network to be bound, got %v", err)
+	}
+
+	*now = now.Add(bindingWindow + time.Minute)
+	if err := svc.Check("token", "203.0.113.7", ""); !errors.Is(err, ErrNetworkNotBound) {
+		t.Errorf("expected the binding to expire, got %v", err)
+	}
+}
+
+func TestCheckCGNATRelaxation(t *testing.T) {
+	strict, _ := newTestService(config.StreamBindingSettings{Enabled: true})
+	relaxed, _ := newTestService(config.StreamBindingSettings{Enabled: true, CGNAT: true})
+	for _, svc := range []*Service{strict, relaxed} {
+		svc.Observe("token", "100.72.14.3", "")
+		svc.Observe("token", "2001:db8:1:2::10", "")
+	}
+
+	if err := strict.Check("token", "100.72.200.9", ""); !errors.Is(err, ErrNetworkNotBound) {
+		t.Errorf("expected a different /24 to be refused, got %v", err)
+	}
+	if err := relaxed.Check("token", "100.72.200.9", ""); err != nil {
+		t.Errorf("expected CGNAT to allow the same /16, got %v", err)
+	}
+	if err := relaxed.Check("token", "2001:db8:1:ff::1", ""); err != nil {
+		t.Errorf("expected CGNAT to allow the same IPv6 /48, got %v", err)
+	}
+	if err := strict.Check("token", "2001:db8:1:2::99", ""); err != nil {
+		t.Errorf("expected the same IPv6 /64 to be allowed, got %v", err)
+	}
+}
+
+func TestCheckClientBinding(t *testing.T) {
+	svc, _ := newTestService(config.StreamBindingSettings{Enabled: true, Match: config.StreamBindingBoth})
+	svc.Observe("token", "203.0.113.7", "tv")
+
+	if err := svc.Check("token", "203.0.113.7", "tv"); err != nil {
+		t.Errorf("expected the bound device to be allowed, got %v", err)
+	}
+	if err := svc.Check("token", "203.0.113.7", ""); !errors.Is(err, ErrClientNotBound) {
+		t.Errorf("expected a request without a client ID to be refused, got %v", err)
+	}
+	if err := svc.Check("token", "203.0.113.7", "laptop"); !errors.Is(err, ErrClientNotBound) {
+		t.Errorf("expected another device to be refused, got %v", err)
+	}
+
+	disabled, _ := newTestService(config.StreamBindingSettings{})
+	if err := disabled.Check("token", "198.51.100.9", ""); err != nil {
+		t.Errorf("expected binding to be off by default, got %v", err)
+	}
+}
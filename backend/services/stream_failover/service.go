@@ -0,0 +1,175 @@
+// Package stream_failover moves usenet playback onto a cached debrid release
+// of the same title when the usenet release degrades mid-playback.
+package stream_failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+)
+
+var (
+	ErrDisabled       = errors.New("debrid failover is disabled")
+	ErrUnknownStream  = errors.New("stream was not resolved by a prequeue")
+	ErrNoCachedSource = errors.New("no cached debrid release found")
+)
+
+const (
+	// targetTTL bounds how long a resolved stream stays eligible for failover
+	targetTTL = 24 * time.Hour
+	// maxCandidates bounds how many debrid releases are tried per failover
+	maxCandidates = 5
+)
+
+// Searcher finds releases for a title.
+type Searcher interface {
+	Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error)
+}
+
+// Resolver turns a release into a playable stream. Debrid releases only
+// resolve when they are cached.
+type Resolver interface {
+	Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error)
+}
+
+// Result is the debrid stream a degraded usenet stream can switch to.
+type Result struct {
+	Path        string
+	Release     string
+	FromRelease string
+}
+
+type target struct {
+	opts         indexer.SearchOptions
+	candidates   []models.NZBResult
+	release      string
+	registeredAt time.Time
+}
+
+// Service remembers which title each usenet stream belongs to so an
+// equivalent debrid release can be found once playback has started.
+type Service struct {
+	mu       sync.Mutex
+	cfg      *config.Manager
+	searcher Searcher
+	resolver Resolver
+	targets  map[string]*target // stream path -> title
+	now      func() time.Time
+}
+
+// NewService constructs a failover service.
+func NewService(cfg *config.Manager, searcher Searcher, resolver Resolver) *Service {
+	return &Service{
+		cfg:      cfg,
+		searcher: searcher,
+		resolver: resolver,
+		targets:  make(map[string]*target),
+		now:      time.Now,
+	}
+}
+
+// Register records the title behind a usenet stream. candidates are debrid
+// releases already found for the title; they are tried before searching again.
+func (s *Service) Register(streamPath string, opts indexer.SearchOptions, candidates []models.NZBResult, release string) {
+	key := pathKey(streamPath)
+	if key == "" {
+		return
+	}
+	debrid := make([]models.NZBResult, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.ServiceType == models.ServiceTypeDebrid {
+			debrid = append(debrid, candidate)
+		}
+	}
+	// The episode resolver holds the whole series; search without it
+	opts.EpisodeResolver = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for path, t := range s.targets {
+		if now.Sub(t.registeredAt) > targetTTL {
+			delete(s.targets, path)
+		}
+	}
+	s.targets[key] = &target{opts: opts, candidates: debrid, release: release, registeredAt: now}
+}
+
+// Failover finds a cached debrid release for the title behind streamPath.
+// Each stream fails over at most once; later calls return ErrUnknownStream.
+func (s *Service) Failover(ctx context.Context, streamPath string) (*Result, error) {
+	if s.cfg != nil {
+		settings, err := s.cfg.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load settings: %w", err)
+		}
+		if !settings.Streaming.DebridFailover {
+			return nil, ErrDisabled
+		}
+	}
+
+	key := pathKey(streamPath)
+	s.mu.Lock()
+	t, ok := s.targets[key]
+	delete(s.targets, key)
+	s.mu.Unlock()
+	if !ok || s.now().Sub(t.registeredAt) > targetTTL {
+		return nil, ErrUnknownStream
+	}
+
+	candidates := t.candidates
+	if len(candidates) == 0 && s.searcher != nil {
+		results, err := s.searcher.Search(ctx, t.opts)
+		if err != nil {
+			return nil, fmt.Errorf("search debrid releases: %w", err)
+		}
+		for _, result := range results {
+			if result.ServiceType == models.ServiceTypeDebrid {
+				candidates = append(candidates, result)
+			}
+		}
+	}
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		resolution, err := s.resolver.Resolve(ctx, candidate)
+		if err != nil || resolution == nil || resolution.WebDAVPath == "" {
+			log.Printf("[stream_failover] %s not usable: %v", candidate.Title, err)
+			continue
+		}
+		log.Printf("[stream_failover] %q -> %q (%s)", t.release, candidate.Title, resolution.WebDAVPath)
+		return &Result{
+			Path:        pathKey(resolution.WebDAVPath),
+			Release:     candidate.Title,
+			FromRelease: t.release,
+		}, nil
+	}
+	return nil, ErrNoCachedSource
+}
+
+// pathKey normalises a stream path the way HLS sessions store it.
+func pathKey(streamPath string) string {
+	path := strings.TrimSpace(streamPath)
+	if path == "" {
+		return ""
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "webdav/")
+	return "/" + path
+}
@@ -0,0 +1,100 @@
+package stream_failover
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/services/indexer"
+)
+
+type fakeSearcher struct {
+	results []models.NZBResult
+	calls   int
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, opts indexer.SearchOptions) ([]models.NZBResult, error) {
+	f.calls++
+	return f.results, nil
+}
+
+// fakeResolver resolves releases listed in cached to a debrid path
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error) {
+	path, ok := f[candidate.Title]
+	if !ok {
+		return nil, errors.New("torrent not cached")
+	}
+	return &models.PlaybackResolution{WebDAVPath: path}, nil
+}
+
+func newTestService(t *testing.T, enabled bool, searcher Searcher, resolver Resolver) *Service {
+	t.Helper()
+	cfg := config.NewManager(filepath.Join(t.TempDir(), "settings.json"))
+	settings := config.DefaultSettings()
+	settings.Streaming.DebridFailover = enabled
+	if err := cfg.Save(settings); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	return NewService(cfg, searcher, resolver)
+}
+
+func debridResult(title string) models.NZBResult {
+	return models.NZBResult{Title: title, ServiceType: models.ServiceTypeDebrid}
+}
+
+func TestFailoverUsesFirstCachedCandidate(t *testing.T) {
+	searcher := &fakeSearcher{}
+	resolver := fakeResolver{"Movie.2160p": "/webdav/debrid/realdebrid/Movie.2160p.mkv"}
+	svc := newTestService(t, true, searcher, resolver)
+
+	svc.Register("/webdav/streams/Movie.1080p/movie.mkv", indexer.SearchOptions{Query: "Movie"}, []models.NZBResult{
+		{Title: "Movie.1080p.NZB", ServiceType: models.ServiceTypeUsenet},
+		debridResult("Movie.Uncached"),
+		debridResult("Movie.2160p"),
+	}, "Movie.1080p")
+
+	result, err := svc.Failover(context.Background(), "/streams/Movie.1080p/movie.mkv")
+	if err != nil {
+		t.Fatalf("Failover() error = %v", err)
+	}
+	if result.Path != "/debrid/realdebrid/Movie.2160p.mkv" || result.Release != "Movie.2160p" || result.FromRelease != "Movie.1080p" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if searcher.calls != 0 {
+		t.Errorf("searched again despite registered candidates")
+	}
+
+	if _, err := svc.Failover(context.Background(), "/streams/Movie.1080p/movie.mkv"); !errors.Is(err, ErrUnknownStream) {
+		t.Errorf("second Failover() error = %v, want ErrUnknownStream", err)
+	}
+}
+
+func TestFailoverSearchesWithoutCandidates(t *testing.T) {
+	searcher := &fakeSearcher{results: []models.NZBResult{
+		{Title: "Show.S01E01.NZB", ServiceType: models.ServiceTypeUsenet},
+		debridResult("Show.S01E01.WEB"),
+	}}
+	svc := newTestService(t, true, searcher, fakeResolver{})
+
+	svc.Register("/streams/Show.S01E01/ep.mkv", indexer.SearchOptions{Query: "Show S01E01"}, nil, "Show.S01E01")
+	if _, err := svc.Failover(context.Background(), "/streams/Show.S01E01/ep.mkv"); !errors.Is(err, ErrNoCachedSource) {
+		t.Errorf("Failover() error = %v, want ErrNoCachedSource", err)
+	}
+	if searcher.calls != 1 {
+		t.Errorf("search calls = %d, want 1", searcher.calls)
+	}
+}
+
+func TestFailoverDisabled(t *testing.T) {
+	svc := newTestService(t, false, &fakeSearcher{}, fakeResolver{"A": "/debrid/a.mkv"})
+	svc.Register("/streams/a.mkv", indexer.SearchOptions{}, []models.NZBResult{debridResult("A")}, "a")
+
+	if _, err := svc.Failover(context.Background(), "/streams/a.mkv"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Failover() error = %v, want ErrDisabled", err)
+	}
+}
@@ -0,0 +1,318 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartialSuffix marks files in the local cache that are still being written.
+// They are ignored until renamed to their final name.
+const PartialSuffix = ".part"
+
+// partialStaleAfter is how long a partial file may go unwritten before it is
+// treated as left behind by a crash and replaced by a new fill.
+const partialStaleAfter = 10 * time.Minute
+
+// LocalFileProvider is an optional interface for providers that can serve a
+// stream from a complete file on local disk. The video handler serves those
+// files with http.ServeContent so the kernel can sendfile them directly.
+type LocalFileProvider interface {
+	Provider
+	LocalFile(ctx context.Context, path string) (string, bool)
+}
+
+// CacheFiller is an optional interface for providers that keep a local copy
+// of streams served by other providers.
+type CacheFiller interface {
+	Fill(req Request, resp *Response) *Response
+}
+
+// LocalCache serves streams that have already been downloaded completely to
+// a directory on local disk, laid out by stream path. It is filled by Fill as
+// whole files are streamed from the other providers.
+type LocalCache struct {
+	root string
+}
+
+// NewLocalCache creates a local cache provider rooted at dir.
+func NewLocalCache(dir string) *LocalCache {
+	return &LocalCache{root: dir}
+}
+
+// localPath maps a stream path to its location in the cache.
+func (c *LocalCache) localPath(streamPath string) (string, bool) {
+	if c == nil || c.root == "" {
+		return "", false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(streamPath, "/"), "webdav/")
+	if rel == "" || strings.HasSuffix(rel, PartialSuffix) {
+		return "", false
+	}
+
+	// Cleaning the rooted path drops any ".." that would escape the cache root
+	return filepath.Join(c.root, filepath.FromSlash(path.Clean("/"+rel))), true
+}
+
+// LocalFile returns the on-disk path of a complete cached copy of the stream.
+func (c *LocalCache) LocalFile(ctx context.Context, streamPath string) (string, bool) {
+	local, ok := c.localPath(streamPath)
+	if !ok {
+		return "", false
+	}
+	info, err := os.Stat(local)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return local, true
+}
+
+// Stream serves the cached file for callers that need a streaming response.
+func (c *LocalCache) Stream(ctx context.Context, req Request) (*Response, error) {
+	local, ok := c.LocalFile(ctx, req.Path)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(local)
+	if err != nil {
+		return nil, fmt.Errorf("open cached stream: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat cached stream: %w", err)
+	}
+	size := info.Size()
+
+	headers := make(http.Header)
+	headers.Set("Accept-Ranges", "bytes")
+	headers.Set("Content-Type", ContentTypeForPath(local))
+
+	status := http.StatusOK
+	start, length := int64(0), size
+	if s, e, ok := parseSingleRange(req.RangeHeader, size); ok {
+		status = http.StatusPartialContent
+		start, length = s, e-s+1
+		headers.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", s, e, size))
+	}
+	headers.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	resp := &Response{
+		Headers:       headers,
+		Status:        status,
+		ContentLength: length,
+		Filename:      filepath.Base(local),
+	}
+	if req.Method == http.MethodHead {
+		file.Close()
+		resp.Body = io.NopCloser(strings.NewReader(""))
+		return resp, nil
+	}
+
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("seek cached stream: %w", err)
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}
+	return resp, nil
+}
+
+// Fill wraps a provider response that carries a whole file so the stream is
+// written to the cache as the client reads it. The copy is kept under a
+// PartialSuffix name and renamed once every byte has arrived, so LocalFile
+// only ever sees complete files; a stream closed early leaves nothing behind.
+// Ranged responses, HEAD requests and paths already cached or being filled
+// are returned unchanged.
+func (c *LocalCache) Fill(req Request, resp *Response) *Response {
+	if resp == nil || resp.Body == nil || resp.ContentLength <= 0 || req.Method == http.MethodHead {
+		return resp
+	}
+	if !coversWholeFile(resp) {
+		return resp
+	}
+	local, ok := c.localPath(req.Path)
+	if !ok {
+		return resp
+	}
+	if _, err := os.Stat(local); err == nil {
+		return resp
+	}
+
+	partial := local + PartialSuffix
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return resp
+	}
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		// Another stream is filling it, unless a crash left it behind
+		if info, statErr := os.Stat(partial); statErr != nil || time.Since(info.ModTime()) < partialStaleAfter {
+			return resp
+		}
+		os.Remove(partial)
+		file, err = os.OpenFile(partial, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	}
+	if err != nil {
+		return resp
+	}
+
+	resp.Body = &cacheFill{src: resp.Body, file: file, partial: partial, local: local, size: resp.ContentLength}
+	return resp
+}
+
+// coversWholeFile reports whether a response body is the complete file: a
+// 200, or a 206 for "bytes 0-" through the last byte.
+func coversWholeFile(resp *Response) bool {
+	switch resp.Status {
+	case http.StatusOK:
+		return resp.Headers.Get("Content-Range") == ""
+	case http.StatusPartialContent:
+		var start, end, total int64
+		if _, err := fmt.Sscanf(resp.Headers.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil {
+			return false
+		}
+		return start == 0 && end == total-1 && total == resp.ContentLength
+	}
+	return false
+}
+
+// cacheFill copies a stream body into a partial cache file as it is read.
+type cacheFill struct {
+	src     io.ReadCloser
+	file    *os.File
+	partial string
+	local   string
+	size    int64
+	written int64
+	failed  bool
+}
+
+func (f *cacheFill) Read(p []byte) (int, error) {
+	n, err := f.src.Read(p)
+	if n > 0 && !f.failed {
+		if _, werr := f.file.Write(p[:n]); werr != nil {
+			f.failed = true
+		} else {
+			f.written += int64(n)
+		}
+	}
+	return n, err
+}
+
+// Close publishes the cached copy if the whole file was read, and drops it
+// otherwise.
+func (f *cacheFill) Close() error {
+	err := f.src.Close()
+	closeErr := f.file.Close()
+	if f.failed || closeErr != nil || f.written != f.size || os.Rename(f.partial, f.local) != nil {
+		os.Remove(f.partial)
+	}
+	return err
+}
+
+// Evict removes complete cached files, least recently modified first, until
+// about needBytes have been freed. Files still being written are kept. It
+// returns the number of bytes removed.
+func (c *LocalCache) Evict(ctx context.Context, needBytes int64) int64 {
+	if c == nil || c.root == "" || needBytes <= 0 {
+		return 0
+	}
+
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cachedFile
+	filepath.WalkDir(c.root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(p, PartialSuffix) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, cachedFile{path: p, size: info.Size(), modTime: info.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var freed int64
+	for _, f := range files {
+		if freed >= needBytes || ctx.Err() != nil {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			continue
+		}
+		freed += f.size
+	}
+	return freed
+}
+
+// ContentTypeForPath returns the video content type for a file's container extension.
+func ContentTypeForPath(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mkv":
+		return "video/x-matroska"
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".avi":
+		return "video/x-msvideo"
+	case ".webm":
+		return "video/webm"
+	case ".ts", ".m2ts", ".mts":
+		return "video/mp2t"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// parseSingleRange parses a single "bytes=" range against size, returning the
+// inclusive span. Multi-range and unsatisfiable headers are not handled.
+func parseSingleRange(header string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || size <= 0 || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix <= 0 {
+			return 0, 0, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size - 1, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if endStr != "" {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, true
+}
@@ -0,0 +1,190 @@
+package streaming
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalCacheLocalFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "movies"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"movies/title.mkv", "movies/partial.mkv" + PartialSuffix} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("0123456789"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := NewLocalCache(root)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/movies/title.mkv", true},
+		{"/webdav/movies/title.mkv", true},
+		{"/movies/partial.mkv" + PartialSuffix, false},
+		{"/movies/missing.mkv", false},
+		{"/movies", false},
+		{"/../" + filepath.Base(root) + "/movies/title.mkv", false},
+	}
+	for _, tt := range tests {
+		if _, got := cache.LocalFile(context.Background(), tt.path); got != tt.want {
+			t.Errorf("LocalFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLocalCacheStreamRange(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "title.mkv"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewLocalCache(root)
+
+	tests := []struct {
+		rangeHeader string
+		status      int
+		body        string
+	}{
+		{"", http.StatusOK, "0123456789"},
+		{"bytes=2-4", http.StatusPartialContent, "234"},
+		{"bytes=7-", http.StatusPartialContent, "789"},
+		{"bytes=-2", http.StatusPartialContent, "89"},
+		{"bytes=8-100", http.StatusPartialContent, "89"},
+	}
+	for _, tt := range tests {
+		resp, err := cache.Stream(context.Background(), Request{Path: "/title.mkv", RangeHeader: tt.rangeHeader, Method: http.MethodGet})
+		if err != nil {
+			t.Fatalf("Stream(%q) error = %v", tt.rangeHeader, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != tt.status || string(body) != tt.body {
+			t.Errorf("Stream(%q) = %d %q, want %d %q", tt.rangeHeader, resp.Status, body, tt.status, tt.body)
+		}
+	}
+
+	if _, err := cache.Stream(context.Background(), Request{Path: "/missing.mkv"}); err != ErrNotFound {
+		t.Errorf("Stream(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalCacheFill(t *testing.T) {
+	const body = "0123456789"
+	newResponse := func(status int, contentRange string) *Response {
+		headers := http.Header{}
+		if contentRange != "" {
+			headers.Set("Content-Range", contentRange)
+		}
+		return &Response{Body: io.NopCloser(strings.NewReader(body)), Headers: headers, Status: status, ContentLength: int64(len(body))}
+	}
+
+	tests := []struct {
+		name     string
+		resp     *Response
+		method   string
+		readAll  bool
+		wantFile bool
+	}{
+		{"whole file read", newResponse(http.StatusOK, ""), http.MethodGet, true, true},
+		{"open-ended range read", newResponse(http.StatusPartialContent, "bytes 0-9/10"), http.MethodGet, true, true},
+		{"closed early", newResponse(http.StatusOK, ""), http.MethodGet, false, false},
+		{"mid-file range", newResponse(http.StatusPartialContent, "bytes 0-9/20"), http.MethodGet, true, false},
+		{"head request", newResponse(http.StatusOK, ""), http.MethodHead, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewLocalCache(t.TempDir())
+			resp := cache.Fill(Request{Path: "/webdav/movies/title.mkv", Method: tt.method}, tt.resp)
+			if tt.readAll {
+				if got, err := io.ReadAll(resp.Body); err != nil || string(got) != body {
+					t.Fatalf("body = %q, %v; want %q", got, err, body)
+				}
+			} else {
+				buf := make([]byte, 4)
+				if _, err := resp.Body.Read(buf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			resp.Body.Close()
+
+			local, ok := cache.LocalFile(context.Background(), "/movies/title.mkv")
+			if ok != tt.wantFile {
+				t.Fatalf("LocalFile hit = %v, want %v", ok, tt.wantFile)
+			}
+			if ok {
+				if got, _ := os.ReadFile(local); string(got) != body {
+					t.Errorf("cached file = %q, want %q", got, body)
+				}
+			}
+			partials, _ := filepath.Glob(filepath.Join(cache.root, "movies", "*"+PartialSuffix))
+			if len(partials) != 0 {
+				t.Errorf("partial files left behind: %v", partials)
+			}
+		})
+	}
+}
+
+func TestLocalCacheFillSkipsActivePartial(t *testing.T) {
+	root := t.TempDir()
+	partial := filepath.Join(root, "title.mkv"+PartialSuffix)
+	if err := os.WriteFile(partial, []byte("01"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewLocalCache(root)
+
+	body := io.NopCloser(strings.NewReader("0123456789"))
+	resp := cache.Fill(Request{Path: "/title.mkv", Method: http.MethodGet}, &Response{Body: body, Status: http.StatusOK, Headers: http.Header{}, ContentLength: 10})
+	if resp.Body != body {
+		t.Fatal("Fill wrapped a stream that another fill is already writing")
+	}
+
+	stale := time.Now().Add(-2 * partialStaleAfter)
+	if err := os.Chtimes(partial, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	resp = cache.Fill(Request{Path: "/title.mkv", Method: http.MethodGet}, &Response{Body: body, Status: http.StatusOK, Headers: http.Header{}, ContentLength: 10})
+	if resp.Body == body {
+		t.Fatal("Fill did not replace a stale partial file")
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if _, ok := cache.LocalFile(context.Background(), "/title.mkv"); !ok {
+		t.Error("LocalFile missed the refilled stream")
+	}
+}
+
+func TestLocalCacheEvictOldestFirst(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"old.mkv", "new.mkv", "writing.mkv" + PartialSuffix} {
+		p := filepath.Join(root, name)
+		if err := os.WriteFile(p, []byte("0123456789"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mod := old.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	freed := NewLocalCache(root).Evict(context.Background(), 5)
+	if freed != 10 {
+		t.Fatalf("expected 10 bytes freed, got %d", freed)
+	}
+	for name, want := range map[string]bool{"old.mkv": false, "new.mkv": true, "writing.mkv" + PartialSuffix: true} {
+		if _, err := os.Stat(filepath.Join(root, name)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", name, err == nil, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package streaming
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"novastream/config"
+)
+
+// staticProvider streams the same content for every request.
+type staticProvider struct {
+	content []byte
+}
+
+func (p staticProvider) Stream(ctx context.Context, req Request) (*Response, error) {
+	return &Response{Body: io.NopCloser(bytes.NewReader(p.content)), Status: 200, ContentLength: int64(len(p.content))}, nil
+}
+
+func simulate(t *testing.T, s config.NetworkSimulationSettings) {
+	t.Helper()
+	SetNetworkSimulation(s)
+	t.Cleanup(func() { SetNetworkSimulation(config.NetworkSimulationSettings{}) })
+}
+
+func TestNetworkSimulatorPassesThroughWhenDisabled(t *testing.T) {
+	simulate(t, config.NetworkSimulationSettings{LatencyMs: 5000, ReadErrorRate: 1})
+
+	resp, err := NewNetworkSimulator(staticProvider{content: []byte("hello")}).Stream(context.Background(), Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "hello" {
+		t.Fatalf("expected the body untouched, got %q (%v)", body, err)
+	}
+}
+
+func TestNetworkSimulatorAddsLatency(t *testing.T) {
+	simulate(t, config.NetworkSimulationSettings{Enabled: true, LatencyMs: 50})
+
+	started := time.Now()
+	resp, err := NewNetworkSimulator(staticProvider{content: []byte("hello")}).Stream(context.Background(), Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if elapsed := time.Since(started); elapsed < 50*time.Millisecond {
+		t.Errorf("expected at least 50ms of latency, got %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewNetworkSimulator(staticProvider{}).Stream(ctx, Request{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled request to stop waiting, got %v", err)
+	}
+}
+
+func TestNetworkSimulatorCapsThroughput(t *testing.T) {
+	// 64 KiB at 2048 kbps takes 256ms
+	simulate(t, config.NetworkSimulationSettings{Enabled: true, ThroughputKbps: 2048})
+
+	resp, err := NewNetworkSimulator(staticProvider{content: make([]byte, 64*1024)}).Stream(context.Background(), Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	started := time.Now()
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil || n != 64*1024 {
+		t.Fatalf("expected the whole body, got %d bytes (%v)", n, err)
+	}
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("expected reads to be throttled, took %s", elapsed)
+	}
+}
+
+func TestNetworkSimulatorInjectsReadErrors(t *testing.T) {
+	// A rate above 1 per MB fails the first 1 MiB read for certain
+	simulate(t, config.NetworkSimulationSettings{Enabled: true, ReadErrorRate: 2})
+
+	resp, err := NewNetworkSimulator(staticProvider{content: make([]byte, 1<<20)}).Stream(context.Background(), Request{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	buf := make([]byte, 1<<20)
+	if _, err := resp.Body.Read(buf); !errors.Is(err, ErrSimulatedReadError) {
+		t.Fatalf("expected a simulated read error, got %v", err)
+	}
+	SetNetworkSimulation(config.NetworkSimulationSettings{})
+	if _, err := resp.Body.Read(buf); !errors.Is(err, ErrSimulatedReadError) {
+		t.Errorf("a failed stream should stay failed, got %v", err)
+	}
+}
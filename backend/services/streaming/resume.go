@@ -0,0 +1,157 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxResumeAttempts bounds how many times a single failure is retried before giving up.
+const maxResumeAttempts = 3
+
+// ReopenFunc issues a fresh upstream request for the bytes starting at offset,
+// through end (inclusive) or to the end of the file when end is negative.
+type ReopenFunc func(ctx context.Context, offset, end int64) (io.ReadCloser, error)
+
+// IsLinkExpiredStatus reports whether an upstream status code indicates that a
+// time-limited download link (e.g. a debrid unrestricted URL) has expired.
+func IsLinkExpiredStatus(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusGone
+}
+
+// RangeHeader formats a Range request header for the given byte span.
+func RangeHeader(offset, end int64) string {
+	if end < 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, end)
+}
+
+// ResponseByteRange returns the absolute byte span carried by an upstream
+// response body. End is -1 when the span is open-ended or unknown.
+func ResponseByteRange(resp *http.Response) (start, end int64) {
+	if resp.StatusCode != http.StatusPartialContent {
+		if resp.ContentLength > 0 {
+			return 0, resp.ContentLength - 1
+		}
+		return 0, -1
+	}
+
+	// Content-Range: bytes 100-199/1000
+	spec := strings.TrimSpace(strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes"))
+	if idx := strings.Index(spec, "/"); idx >= 0 {
+		spec = spec[:idx]
+	}
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, -1
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil {
+		return 0, -1
+	}
+	end, err = strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		return start, -1
+	}
+	return start, end
+}
+
+// resumingBody wraps an upstream response body and transparently reopens the
+// stream at the current byte offset when the connection fails mid-read, so a
+// link that expires part way through playback does not end the stream.
+type resumingBody struct {
+	ctx    context.Context
+	body   io.ReadCloser
+	offset int64
+	end    int64
+	reopen ReopenFunc
+	broken bool // body failed and must be reopened before the next read
+	stalls int  // resumes since the last successful read
+	err    error
+}
+
+// NewResumingBody wraps body, which starts at absolute byte offset and runs
+// through end (-1 when unknown). When a read fails before the span is complete,
+// reopen is called to continue from the first byte not yet delivered.
+func NewResumingBody(ctx context.Context, body io.ReadCloser, offset, end int64, reopen ReopenFunc) io.ReadCloser {
+	return &resumingBody{
+		ctx:    ctx,
+		body:   body,
+		offset: offset,
+		end:    end,
+		reopen: reopen,
+	}
+}
+
+func (b *resumingBody) Read(p []byte) (int, error) {
+	for {
+		if b.err != nil {
+			return 0, b.err
+		}
+		if b.broken {
+			if err := b.resume(); err != nil {
+				b.err = err
+				return 0, err
+			}
+		}
+
+		n, err := b.body.Read(p)
+		b.offset += int64(n)
+		if n > 0 {
+			b.stalls = 0
+		}
+		if err == nil || errors.Is(err, io.EOF) || !b.canResume() {
+			return n, err
+		}
+
+		log.Printf("[streaming] upstream read failed at byte %d: %v; resuming", b.offset, err)
+		b.broken = true
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (b *resumingBody) canResume() bool {
+	if b.ctx.Err() != nil {
+		return false
+	}
+	if b.stalls >= maxResumeAttempts {
+		return false
+	}
+	return b.end < 0 || b.offset <= b.end
+}
+
+// resume replaces the failed body with a fresh upstream request starting at the
+// current offset, retrying up to maxResumeAttempts times.
+func (b *resumingBody) resume() error {
+	b.body.Close()
+
+	var lastErr error
+	for attempt := 1; attempt <= maxResumeAttempts; attempt++ {
+		body, err := b.reopen(b.ctx, b.offset, b.end)
+		if err == nil {
+			log.Printf("[streaming] resumed upstream stream at byte %d", b.offset)
+			b.body = body
+			b.broken = false
+			b.stalls++
+			return nil
+		}
+		lastErr = err
+		log.Printf("[streaming] resume at byte %d failed (attempt %d/%d): %v", b.offset, attempt, maxResumeAttempts, err)
+		if b.ctx.Err() != nil {
+			break
+		}
+	}
+	return fmt.Errorf("resume stream at byte %d: %w", b.offset, lastErr)
+}
+
+func (b *resumingBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,85 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// failingReader returns data then fails as if the connection was dropped.
+type failingReader struct {
+	data string
+	read bool
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.read {
+		return 0, errors.New("connection reset by peer")
+	}
+	f.read = true
+	return copy(p, f.data), nil
+}
+
+func (f *failingReader) Close() error { return nil }
+
+func TestResumingBodyReopensAtOffset(t *testing.T) {
+	const content = "0123456789abcdefghij"
+
+	var offsets []int64
+	reopen := func(ctx context.Context, offset, end int64) (io.ReadCloser, error) {
+		offsets = append(offsets, offset)
+		if len(offsets) == 1 {
+			return nil, errors.New("403 Forbidden")
+		}
+		return io.NopCloser(strings.NewReader(content[offset : end+1])), nil
+	}
+
+	// Body covers bytes 5-19 but drops after the first five bytes
+	body := NewResumingBody(context.Background(), &failingReader{data: content[5:10]}, 5, 19, reopen)
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(got) != content[5:] {
+		t.Fatalf("expected %q, got %q", content[5:], got)
+	}
+	if len(offsets) != 2 || offsets[0] != 10 || offsets[1] != 10 {
+		t.Fatalf("expected two reopen attempts at byte 10, got %v", offsets)
+	}
+}
+
+func TestResumingBodyGivesUpWhenReopenKeepsFailing(t *testing.T) {
+	calls := 0
+	reopen := func(ctx context.Context, offset, end int64) (io.ReadCloser, error) {
+		calls++
+		return nil, errors.New("410 Gone")
+	}
+
+	body := NewResumingBody(context.Background(), &failingReader{data: "abc"}, 0, -1, reopen)
+	if _, err := io.ReadAll(body); err == nil {
+		t.Fatal("expected an error once resume attempts are exhausted")
+	}
+	if calls != maxResumeAttempts {
+		t.Fatalf("expected %d reopen attempts, got %d", maxResumeAttempts, calls)
+	}
+}
+
+func TestResponseByteRange(t *testing.T) {
+	partial := &http.Response{StatusCode: http.StatusPartialContent, Header: http.Header{}}
+	partial.Header.Set("Content-Range", "bytes 100-199/1000")
+	if start, end := ResponseByteRange(partial); start != 100 || end != 199 {
+		t.Fatalf("expected 100-199, got %d-%d", start, end)
+	}
+
+	full := &http.Response{StatusCode: http.StatusOK, ContentLength: 50}
+	if start, end := ResponseByteRange(full); start != 0 || end != 49 {
+		t.Fatalf("expected 0-49, got %d-%d", start, end)
+	}
+
+	if got := RangeHeader(10, -1); got != "bytes=10-" {
+		t.Fatalf("unexpected range header %q", got)
+	}
+}
@@ -0,0 +1,41 @@
+package subtitle_offsets
+
+import "testing"
+
+func TestSetPersistsAndClamps(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	if _, err := svc.Set("/webdav/movies/a.mkv", 750); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := svc.Get("/movies/a.mkv"); got != 750 {
+		t.Fatalf("expected WebDAV and provider paths to share an offset, got %d", got)
+	}
+
+	stored, err := svc.Set("/movies/b.mkv", -MaxOffsetMs*2)
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if stored != -MaxOffsetMs {
+		t.Fatalf("expected offset clamped to %d, got %d", -MaxOffsetMs, stored)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if got := reloaded.Get("/movies/a.mkv"); got != 750 {
+		t.Fatalf("expected offset to persist, got %d", got)
+	}
+
+	if _, err := reloaded.Set("/movies/a.mkv", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := reloaded.offsets["/movies/a.mkv"]; ok {
+		t.Fatal("expected zero offset to clear the entry")
+	}
+}
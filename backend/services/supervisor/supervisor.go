@@ -0,0 +1,191 @@
+// Package supervisor initialises optional subsystems (the usenet pool, WebDAV)
+// with retries, so a dependency that is down at boot leaves the server running
+// in a degraded mode instead of stopping it, and the subsystem comes up on its
+// own once the dependency returns.
+package supervisor
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"novastream/models"
+)
+
+const (
+	initialBackoff = 5 * time.Second
+	maxBackoff     = 5 * time.Minute
+)
+
+// InitFunc brings a subsystem up. It is called again after a failure, so it
+// must be safe to retry.
+type InitFunc func(ctx context.Context) error
+
+// Supervisor tracks the subsystems it started and retries the failed ones.
+type Supervisor struct {
+	mu      sync.RWMutex
+	systems map[string]*models.SubsystemStatus
+
+	now            func() time.Time
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	wg sync.WaitGroup
+}
+
+// New constructs a supervisor.
+func New() *Supervisor {
+	return &Supervisor{
+		systems:        make(map[string]*models.SubsystemStatus),
+		now:            time.Now,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// Start runs init once and reports whether it succeeded. On failure it keeps
+// retrying in the background with exponential backoff until init succeeds or
+// ctx is cancelled; the caller carries on without the subsystem meanwhile.
+func (s *Supervisor) Start(ctx context.Context, name string, init InitFunc) bool {
+	s.mu.Lock()
+	s.systems[name] = &models.SubsystemStatus{Name: name, State: models.SubsystemStarting}
+	s.mu.Unlock()
+
+	if s.attempt(ctx, name, init, s.initialBackoff) {
+		return true
+	}
+
+	s.wg.Add(1)
+	go s.retry(ctx, name, init)
+	return false
+}
+
+// Ready reports whether the named subsystem has initialised.
+func (s *Supervisor) Ready(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, ok := s.systems[name]
+	return ok && status.State == models.SubsystemReady
+}
+
+// Status returns every subsystem's state ordered by name.
+func (s *Supervisor) Status() []models.SubsystemStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]models.SubsystemStatus, 0, len(s.systems))
+	for _, status := range s.systems {
+		list = append(list, *status)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Wait blocks until every background retry has finished; cancel the context
+// passed to Start first.
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Supervisor) retry(ctx context.Context, name string, init InitFunc) {
+	defer s.wg.Done()
+
+	backoff := s.initialBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			s.update(name, func(status *models.SubsystemStatus) {
+				status.State = models.SubsystemStopped
+				status.NextAttemptAt = nil
+			})
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, s.maxBackoff)
+		if s.attempt(ctx, name, init, backoff) {
+			return
+		}
+	}
+}
+
+// attempt runs init once and records the outcome. backoff is the delay
+// before the next attempt if this one fails.
+func (s *Supervisor) attempt(ctx context.Context, name string, init InitFunc, backoff time.Duration) bool {
+	err := init(ctx)
+	now := s.now().UTC()
+
+	s.update(name, func(status *models.SubsystemStatus) {
+		status.Attempts++
+		if err == nil {
+			status.State = models.SubsystemReady
+			status.LastError = ""
+			status.NextAttemptAt = nil
+			status.ReadyAt = &now
+			return
+		}
+		next := now.Add(backoff)
+		status.State = models.SubsystemRetrying
+		status.LastError = err.Error()
+		status.NextAttemptAt = &next
+	})
+
+	if err != nil {
+		log.Printf("[supervisor] %s failed to start, retrying in %s: %v", name, backoff, err)
+		return false
+	}
+	if status := s.status(name); status.Attempts > 1 {
+		log.Printf("[supervisor] %s recovered after %d attempts", name, status.Attempts)
+	}
+	return true
+}
+
+func (s *Supervisor) update(name string, fn func(status *models.SubsystemStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status, ok := s.systems[name]; ok {
+		fn(status)
+	}
+}
+
+func (s *Supervisor) status(name string) models.SubsystemStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if status, ok := s.systems[name]; ok {
+		return *status
+	}
+	return models.SubsystemStatus{Name: name}
+}
+
+// PendingHandler stands in for the HTTP handler of a subsystem that has not
+// started yet, answering 503 until the real handler is set.
+type PendingHandler struct {
+	name    string
+	handler atomic.Pointer[http.Handler]
+}
+
+// NewPendingHandler constructs a placeholder for the named subsystem.
+func NewPendingHandler(name string) *PendingHandler {
+	return &PendingHandler{name: name}
+}
+
+// Set installs the subsystem's handler once it is up.
+func (p *PendingHandler) Set(handler http.Handler) {
+	p.handler.Store(&handler)
+}
+
+func (p *PendingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if handler := p.handler.Load(); handler != nil {
+		(*handler).ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Retry-After", "30")
+	http.Error(w, p.name+" is not available yet", http.StatusServiceUnavailable)
+}
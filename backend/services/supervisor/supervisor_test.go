@@ -0,0 +1,81 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func newTestSupervisor() *Supervisor {
+	s := New()
+	s.initialBackoff = time.Millisecond
+	s.maxBackoff = 4 * time.Millisecond
+	return s
+}
+
+func TestStartRetriesUntilReady(t *testing.T) {
+	s := newTestSupervisor()
+
+	var attempts atomic.Int32
+	ready := s.Start(t.Context(), "pool", func(ctx context.Context) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if ready {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if status := s.Status(); len(status) != 1 || status[0].State != models.SubsystemRetrying || status[0].LastError != "connection refused" {
+		t.Fatalf("expected the pool to be retrying, got %+v", status)
+	}
+
+	s.Wait()
+	if !s.Ready("pool") {
+		t.Fatalf("expected the pool to recover, got %+v", s.Status())
+	}
+	status := s.Status()[0]
+	if status.Attempts != 3 || status.LastError != "" || status.ReadyAt == nil || status.NextAttemptAt != nil {
+		t.Errorf("unexpected status after recovery %+v", status)
+	}
+}
+
+func TestStartStopsRetryingOnCancel(t *testing.T) {
+	s := newTestSupervisor()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if s.Start(ctx, "webdav", func(ctx context.Context) error { return errors.New("down") }) {
+		t.Fatal("expected the start to fail")
+	}
+	cancel()
+	s.Wait()
+
+	if status := s.Status()[0]; status.State != models.SubsystemStopped {
+		t.Errorf("expected the subsystem to be stopped, got %+v", status)
+	}
+}
+
+func TestPendingHandler(t *testing.T) {
+	pending := NewPendingHandler("WebDAV")
+
+	rec := httptest.NewRecorder()
+	pending.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webdav/", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After before the handler is set, got %d", rec.Code)
+	}
+
+	pending.Set(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+	}))
+	rec = httptest.NewRecorder()
+	pending.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webdav/", nil))
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("expected the real handler to answer, got %d", rec.Code)
+	}
+}
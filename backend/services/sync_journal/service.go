@@ -0,0 +1,336 @@
+package sync_journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+const (
+	// maxEntriesPerUser bounds each profile's journal after compaction. Clients
+	// whose cursor predates the retained window must do a full resync.
+	maxEntriesPerUser = 5000
+
+	// tombstoneRetention is how long deletions are kept before being pruned.
+	tombstoneRetention = 90 * 24 * time.Hour
+
+	// saveDelay batches the journal writes of a burst of changes, such as
+	// playback progress ticks, into one save.
+	saveDelay = 2 * time.Second
+)
+
+type userJournal struct {
+	// Entries are ordered by Seq and hold only the latest change per collection+key
+	Entries []models.SyncChange `json:"entries"`
+	// FloorSeq is the highest sequence number ever pruned; cursors below it are stale
+	FloorSeq int64 `json:"floorSeq"`
+	// FloorTime is the change time of the newest pruned entry
+	FloorTime time.Time `json:"floorTime,omitempty"`
+
+	// latest maps collection+key to the Seq of its entry
+	latest map[string]int64
+}
+
+type journalFile struct {
+	Seq   int64                   `json:"seq"`
+	Users map[string]*userJournal `json:"users"`
+}
+
+// Service keeps a per-profile change journal for watchlist, watch history and
+// playback progress so clients can sync incrementally using a cursor.
+type Service struct {
+	mu        sync.RWMutex
+	saveMu    sync.Mutex
+	path      string
+	seq       int64
+	users     map[string]*userJournal
+	saveTimer *time.Timer
+}
+
+// NewService creates a sync journal storing data inside the provided directory.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create sync journal dir: %w", err)
+	}
+
+	svc := &Service{
+		path:  filepath.Join(storageDir, "sync_journal.json"),
+		users: make(map[string]*userJournal),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// RecordChange appends a change for a profile, replacing any earlier entry for
+// the same key. The journal is saved shortly afterwards rather than on every
+// change, so callers can record changes while holding their own locks.
+func (s *Service) RecordChange(userID, collection, key, op string) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" || key == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	journal, ok := s.users[userID]
+	if !ok {
+		journal = &userJournal{latest: make(map[string]int64)}
+		s.users[userID] = journal
+	}
+
+	// Drop the previous entry for this key; the new one supersedes it
+	if i, ok := journal.find(collection, key); ok {
+		journal.Entries = append(journal.Entries[:i], journal.Entries[i+1:]...)
+	}
+
+	s.seq++
+	journal.Entries = append(journal.Entries, models.SyncChange{
+		Seq:        s.seq,
+		Collection: collection,
+		Key:        key,
+		Op:         op,
+		ChangedAt:  time.Now().UTC(),
+	})
+	journal.latest[entryKey(collection, key)] = s.seq
+	s.pruneLocked(journal)
+
+	if s.saveTimer == nil {
+		s.saveTimer = time.AfterFunc(saveDelay, s.save)
+	}
+}
+
+// Flush saves any changes not yet written to disk. Call it on shutdown.
+func (s *Service) Flush() {
+	s.mu.Lock()
+	if s.saveTimer != nil {
+		s.saveTimer.Stop()
+	}
+	s.mu.Unlock()
+	s.save()
+}
+
+// Cursor returns the latest sequence number across all profiles.
+func (s *Service) Cursor() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seq
+}
+
+// ChangesSince returns a profile's changes after the cursor along with the new
+// cursor. reset is true when the cursor predates the retained journal and the
+// client must fetch a full snapshot instead.
+func (s *Service) ChangesSince(userID string, cursor int64) (changes []models.SyncChange, next int64, reset bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	journal, ok := s.users[userID]
+	if !ok {
+		return nil, s.seq, cursor > s.seq
+	}
+	if cursor < journal.FloorSeq || cursor > s.seq {
+		return nil, s.seq, true
+	}
+
+	idx := sort.Search(len(journal.Entries), func(i int) bool {
+		return journal.Entries[i].Seq > cursor
+	})
+	changes = append(changes, journal.Entries[idx:]...)
+	return changes, s.seq, false
+}
+
+// ChangesSinceTime is like ChangesSince but uses a wall-clock timestamp, for
+// clients that track "updatedSince" rather than an opaque cursor.
+func (s *Service) ChangesSinceTime(userID string, since time.Time) (changes []models.SyncChange, next int64, reset bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	journal, ok := s.users[userID]
+	if !ok {
+		return nil, s.seq, false
+	}
+	if !journal.FloorTime.IsZero() && since.Before(journal.FloorTime) {
+		return nil, s.seq, true
+	}
+
+	for _, entry := range journal.Entries {
+		if entry.ChangedAt.After(since) {
+			changes = append(changes, entry)
+		}
+	}
+	return changes, s.seq, false
+}
+
+// LastChange returns the latest journalled change to one item of a
+// profile's collection, if it is still retained.
+func (s *Service) LastChange(userID, collection, key string) (models.SyncChange, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	journal, ok := s.users[userID]
+	if !ok {
+		return models.SyncChange{}, false
+	}
+	i, ok := journal.find(collection, key)
+	if !ok {
+		return models.SyncChange{}, false
+	}
+	return journal.Entries[i], true
+}
+
+// find returns the position of the entry for collection+key. Entries are
+// ordered by Seq, so the index's Seq locates it with a binary search.
+func (j *userJournal) find(collection, key string) (int, bool) {
+	seq, ok := j.latest[entryKey(collection, key)]
+	if !ok {
+		return 0, false
+	}
+	i := sort.Search(len(j.Entries), func(i int) bool {
+		return j.Entries[i].Seq >= seq
+	})
+	if i == len(j.Entries) || j.Entries[i].Seq != seq {
+		return 0, false
+	}
+	return i, true
+}
+
+func (j *userJournal) reindex() {
+	j.latest = make(map[string]int64, len(j.Entries))
+	for _, entry := range j.Entries {
+		j.latest[entryKey(entry.Collection, entry.Key)] = entry.Seq
+	}
+}
+
+func entryKey(collection, key string) string {
+	return collection + "\x00" + key
+}
+
+// pruneLocked drops expired tombstones and trims the journal to its size cap,
+// raising the floor so stale cursors are detected.
+func (s *Service) pruneLocked(journal *userJournal) {
+	cutoff := time.Now().UTC().Add(-tombstoneRetention)
+	kept := journal.Entries[:0]
+	for _, entry := range journal.Entries {
+		if entry.Op == models.SyncOpDelete && entry.ChangedAt.Before(cutoff) {
+			journal.drop(entry)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	journal.Entries = kept
+
+	if overflow := len(journal.Entries) - maxEntriesPerUser; overflow > 0 {
+		for _, entry := range journal.Entries[:overflow] {
+			journal.drop(entry)
+		}
+		journal.Entries = append([]models.SyncChange(nil), journal.Entries[overflow:]...)
+	}
+}
+
+// drop removes a pruned entry from the index and raises the floor past it.
+func (j *userJournal) drop(entry models.SyncChange) {
+	delete(j.latest, entryKey(entry.Collection, entry.Key))
+	j.raiseFloor(entry)
+}
+
+func (j *userJournal) raiseFloor(entry models.SyncChange) {
+	if entry.Seq > j.FloorSeq {
+		j.FloorSeq = entry.Seq
+	}
+	if entry.ChangedAt.After(j.FloorTime) {
+		j.FloorTime = entry.ChangedAt
+	}
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open sync journal: %w", err)
+	}
+	defer file.Close()
+
+	var data journalFile
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		return fmt.Errorf("decode sync journal: %w", err)
+	}
+
+	s.seq = data.Seq
+	if data.Users != nil {
+		s.users = data.Users
+	}
+	for _, journal := range s.users {
+		journal.reindex()
+	}
+	return nil
+}
+
+// save writes the journal to disk. It is encoded under the lock and
+// written without it, so recording changes never waits on the disk. Changes
+// lost before a save only cost clients ahead of the saved cursor a resync.
+func (s *Service) save() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	s.saveTimer = nil
+	data, err := json.Marshal(journalFile{Seq: s.seq, Users: s.users})
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("[sync] failed to encode journal: %v", err)
+		return
+	}
+
+	if err := s.write(data); err != nil {
+		log.Printf("[sync] failed to persist journal: %v", err)
+	}
+}
+
+func (s *Service) write(data []byte) error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create sync journal temp file: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write sync journal: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close sync journal temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace sync journal: %w", err)
+	}
+
+	return nil
+}
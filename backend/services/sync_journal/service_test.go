@@ -0,0 +1,85 @@
+package sync_journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/models"
+)
+
+func TestChangesSinceReturnsLatestPerKey(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	svc.RecordChange("user-1", models.SyncCollectionWatchlist, "movie:1", models.SyncOpUpsert)
+	cursor := svc.Cursor()
+	svc.RecordChange("user-1", models.SyncCollectionWatchlist, "movie:2", models.SyncOpUpsert)
+	svc.RecordChange("user-1", models.SyncCollectionWatchlist, "movie:2", models.SyncOpDelete)
+	svc.RecordChange("user-2", models.SyncCollectionHistory, "movie:3", models.SyncOpUpsert)
+
+	changes, next, reset := svc.ChangesSince("user-1", cursor)
+	if reset {
+		t.Fatal("did not expect reset for a fresh cursor")
+	}
+	if next != svc.Cursor() {
+		t.Fatalf("expected next cursor %d, got %d", svc.Cursor(), next)
+	}
+	if len(changes) != 1 || changes[0].Key != "movie:2" || changes[0].Op != models.SyncOpDelete {
+		t.Fatalf("expected single tombstone for movie:2, got %+v", changes)
+	}
+}
+
+func TestChangesSinceDetectsStaleCursor(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	svc.RecordChange("user-1", models.SyncCollectionProgress, "movie:1", models.SyncOpUpsert)
+	svc.users["user-1"].FloorSeq = svc.Cursor()
+	svc.RecordChange("user-1", models.SyncCollectionProgress, "movie:2", models.SyncOpUpsert)
+
+	if _, _, reset := svc.ChangesSince("user-1", 0); !reset {
+		t.Fatal("expected reset for cursor older than retained journal")
+	}
+	if _, _, reset := svc.ChangesSince("user-1", svc.Cursor()+10); !reset {
+		t.Fatal("expected reset for cursor from the future")
+	}
+}
+
+func TestJournalPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	before := time.Now().UTC().Add(-time.Second)
+	svc.RecordChange("user-1", models.SyncCollectionHistory, "episode:x", models.SyncOpUpsert)
+	svc.RecordChange("user-1", models.SyncCollectionHistory, "episode:y", models.SyncOpUpsert)
+	svc.RecordChange("user-1", models.SyncCollectionHistory, "episode:x", models.SyncOpDelete)
+
+	// Changes are batched into a delayed save rather than written as recorded
+	if _, err := os.Stat(filepath.Join(dir, "sync_journal.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no journal file before the save, got %v", err)
+	}
+	svc.Flush()
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("NewService() reload error = %v", err)
+	}
+	if reloaded.Cursor() != svc.Cursor() {
+		t.Fatalf("expected cursor %d after reload, got %d", svc.Cursor(), reloaded.Cursor())
+	}
+	changes, _, _ := reloaded.ChangesSinceTime("user-1", before)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes since %v, got %d", before, len(changes))
+	}
+	if change, ok := reloaded.LastChange("user-1", models.SyncCollectionHistory, "episode:x"); !ok || change.Op != models.SyncOpDelete {
+		t.Fatalf("expected episode:x tombstone after reload, got %+v, %v", change, ok)
+	}
+}
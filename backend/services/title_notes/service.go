@@ -0,0 +1,222 @@
+package title_notes
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"novastream/internal/database"
+	"novastream/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRepositoryRequired = errors.New("title note repository not provided")
+	ErrProfileNotFound    = errors.New("profile not found")
+	ErrTitleIDRequired    = errors.New("title id is required")
+	ErrBodyRequired       = errors.New("note text is required")
+	ErrBodyTooLong        = errors.New("note text is too long")
+	ErrNoteNotFound       = errors.New("note not found")
+)
+
+// maxBodyLength bounds a note; they are meant to be short reminders.
+const maxBodyLength = 2000
+
+// Repository persists title notes.
+type Repository interface {
+	CreateTitleNote(note *database.TitleNote) error
+	GetTitleNote(id string) (*database.TitleNote, error)
+	ListTitleNotes(accountID, titleID string) ([]*database.TitleNote, error)
+	UpdateTitleNoteBody(id, body, editedByProfileID string) (bool, error)
+	DeleteTitleNote(id string) (bool, error)
+	DeleteTitleNotesByAuthor(profileID string) error
+}
+
+var _ Repository = (*database.TitleNoteRepository)(nil)
+
+// ProfileLookup resolves a profile to the account (household) that owns it.
+type ProfileLookup interface {
+	Get(id string) (models.User, bool)
+}
+
+// Service keeps notes on titles that are shared by all profiles of an
+// account, so a household can leave each other reminders about what it watches.
+type Service struct {
+	repo     Repository
+	profiles ProfileLookup
+}
+
+// NewService creates a title note service backed by the given repository.
+func NewService(repo Repository, profiles ProfileLookup) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo, profiles: profiles}, nil
+}
+
+// List returns the notes of profileID's household, newest first. A non-empty
+// titleID limits the result to notes on that title.
+func (s *Service) List(profileID, titleID string) ([]models.TitleNote, error) {
+	profile, err := s.profile(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListTitleNotes(profile.AccountID, strings.TrimSpace(titleID))
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]models.TitleNote, 0, len(rows))
+	for _, row := range rows {
+		notes = append(notes, s.toModel(row))
+	}
+	return notes, nil
+}
+
+// Create adds a note by profileID to a title.
+func (s *Service) Create(profileID string, req models.TitleNoteRequest) (*models.TitleNote, error) {
+	profile, err := s.profile(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	titleID := strings.TrimSpace(req.TitleID)
+	if titleID == "" {
+		return nil, ErrTitleIDRequired
+	}
+	body, err := normalizeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	if err := s.repo.CreateTitleNote(&database.TitleNote{
+		ID:              id,
+		AccountID:       profile.AccountID,
+		TitleID:         titleID,
+		MediaType:       strings.ToLower(strings.TrimSpace(req.MediaType)),
+		TitleName:       strings.TrimSpace(req.TitleName),
+		Body:            body,
+		AuthorProfileID: profile.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.get(profile, id)
+}
+
+// Update replaces the text of a note. Any profile of the household may edit it.
+func (s *Service) Update(profileID, noteID string, update models.TitleNoteUpdate) (*models.TitleNote, error) {
+	profile, err := s.profile(profileID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.get(profile, noteID); err != nil {
+		return nil, err
+	}
+
+	body, err := normalizeBody(update.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.UpdateTitleNoteBody(strings.TrimSpace(noteID), body, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoteNotFound
+	}
+
+	return s.get(profile, noteID)
+}
+
+// Delete removes a note. Any profile of the household may delete it.
+func (s *Service) Delete(profileID, noteID string) error {
+	profile, err := s.profile(profileID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.get(profile, noteID); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.DeleteTitleNote(strings.TrimSpace(noteID))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// DeleteUser removes the notes written by a purged profile.
+func (s *Service) DeleteUser(profileID string) error {
+	return s.repo.DeleteTitleNotesByAuthor(strings.TrimSpace(profileID))
+}
+
+func (s *Service) profile(profileID string) (models.User, error) {
+	profileID = strings.TrimSpace(profileID)
+	if profileID == "" || s.profiles == nil {
+		return models.User{}, ErrProfileNotFound
+	}
+	profile, ok := s.profiles.Get(profileID)
+	if !ok {
+		return models.User{}, ErrProfileNotFound
+	}
+	return profile, nil
+}
+
+// get returns a note if it belongs to profile's household. Notes of other
+// households are reported as not found.
+func (s *Service) get(profile models.User, noteID string) (*models.TitleNote, error) {
+	noteID = strings.TrimSpace(noteID)
+	if noteID == "" {
+		return nil, ErrNoteNotFound
+	}
+
+	row, err := s.repo.GetTitleNote(noteID)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil || row.AccountID != profile.AccountID {
+		return nil, ErrNoteNotFound
+	}
+
+	note := s.toModel(row)
+	return &note, nil
+}
+
+func (s *Service) toModel(row *database.TitleNote) models.TitleNote {
+	note := models.TitleNote{
+		ID:                row.ID,
+		TitleID:           row.TitleID,
+		MediaType:         row.MediaType,
+		TitleName:         row.TitleName,
+		Body:              row.Body,
+		AuthorProfileID:   row.AuthorProfileID,
+		EditedByProfileID: row.EditedByProfileID,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+	if s.profiles != nil {
+		if author, ok := s.profiles.Get(row.AuthorProfileID); ok {
+			note.AuthorName = author.Name
+		}
+	}
+	return note
+}
+
+func normalizeBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrBodyRequired
+	}
+	if utf8.RuneCountInString(body) > maxBodyLength {
+		return "", ErrBodyTooLong
+	}
+	return body, nil
+}
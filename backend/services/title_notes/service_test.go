@@ -0,0 +1,170 @@
+package title_notes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+type fakeRepository struct {
+	notes []*database.TitleNote
+}
+
+func (f *fakeRepository) CreateTitleNote(note *database.TitleNote) error {
+	copy := *note
+	copy.CreatedAt = time.Now()
+	copy.UpdatedAt = copy.CreatedAt
+	f.notes = append(f.notes, &copy)
+	return nil
+}
+
+func (f *fakeRepository) GetTitleNote(id string) (*database.TitleNote, error) {
+	for _, note := range f.notes {
+		if note.ID == id {
+			copy := *note
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) ListTitleNotes(accountID, titleID string) ([]*database.TitleNote, error) {
+	var out []*database.TitleNote
+	for i := len(f.notes) - 1; i >= 0; i-- {
+		note := f.notes[i]
+		if note.AccountID == accountID && (titleID == "" || note.TitleID == titleID) {
+			copy := *note
+			out = append(out, &copy)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) UpdateTitleNoteBody(id, body, editedByProfileID string) (bool, error) {
+	for _, note := range f.notes {
+		if note.ID == id {
+			note.Body = body
+			note.EditedByProfileID = editedByProfileID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepository) DeleteTitleNote(id string) (bool, error) {
+	for i, note := range f.notes {
+		if note.ID == id {
+			f.notes = append(f.notes[:i], f.notes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepository) DeleteTitleNotesByAuthor(profileID string) error {
+	kept := f.notes[:0]
+	for _, note := range f.notes {
+		if note.AuthorProfileID != profileID {
+			kept = append(kept, note)
+		}
+	}
+	f.notes = kept
+	return nil
+}
+
+type fakeProfiles map[string]models.User
+
+func (f fakeProfiles) Get(id string) (models.User, bool) {
+	user, ok := f[id]
+	return user, ok
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	svc, err := NewService(&fakeRepository{}, fakeProfiles{
+		"alice": {ID: "alice", AccountID: "home", Name: "Alice"},
+		"bob":   {ID: "bob", AccountID: "home", Name: "Bob"},
+		"eve":   {ID: "eve", AccountID: "elsewhere", Name: "Eve"},
+	})
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return svc
+}
+
+func TestNotesAreSharedWithinHousehold(t *testing.T) {
+	svc := newTestService(t)
+
+	note, err := svc.Create("alice", models.TitleNoteRequest{TitleID: "tvdb:series:1", MediaType: "Series", Body: "  we stopped at S02E04  "})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if note.Body != "we stopped at S02E04" || note.MediaType != "series" || note.AuthorName != "Alice" {
+		t.Fatalf("unexpected note: %+v", note)
+	}
+
+	shared, err := svc.List("bob", "tvdb:series:1")
+	if err != nil || len(shared) != 1 || shared[0].ID != note.ID {
+		t.Fatalf("List(bob) = %+v, %v", shared, err)
+	}
+	if other, _ := svc.List("eve", ""); len(other) != 0 {
+		t.Fatalf("expected another household not to see the note, got %+v", other)
+	}
+
+	edited, err := svc.Update("bob", note.ID, models.TitleNoteUpdate{Body: "finished it"})
+	if err != nil || edited.Body != "finished it" || edited.EditedByProfileID != "bob" || edited.AuthorProfileID != "alice" {
+		t.Fatalf("Update(bob) = %+v, %v", edited, err)
+	}
+	if _, err := svc.Update("eve", note.ID, models.TitleNoteUpdate{Body: "mine now"}); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("expected ErrNoteNotFound editing another household's note, got %v", err)
+	}
+	if err := svc.Delete("eve", note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Fatalf("expected ErrNoteNotFound deleting another household's note, got %v", err)
+	}
+	if err := svc.Delete("bob", note.ID); err != nil {
+		t.Fatalf("Delete(bob) error = %v", err)
+	}
+}
+
+func TestCreateValidation(t *testing.T) {
+	svc := newTestService(t)
+
+	tests := []struct {
+		profile string
+		req     models.TitleNoteRequest
+		want    error
+	}{
+		{"nobody", models.TitleNoteRequest{TitleID: "t1", Body: "hi"}, ErrProfileNotFound},
+		{"alice", models.TitleNoteRequest{Body: "hi"}, ErrTitleIDRequired},
+		{"alice", models.TitleNoteRequest{TitleID: "t1", Body: "   "}, ErrBodyRequired},
+		{"alice", models.TitleNoteRequest{TitleID: "t1", Body: strings.Repeat("a", maxBodyLength+1)}, ErrBodyTooLong},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Create(tt.profile, tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("Create(%s, %+v) error = %v, want %v", tt.profile, tt.req.TitleID, err, tt.want)
+		}
+	}
+}
+
+func TestDeleteUserRemovesAuthoredNotes(t *testing.T) {
+	svc := newTestService(t)
+
+	if _, err := svc.Create("alice", models.TitleNoteRequest{TitleID: "t1", Body: "from alice"}); err != nil {
+		t.Fatalf("Create(alice) error = %v", err)
+	}
+	if _, err := svc.Create("bob", models.TitleNoteRequest{TitleID: "t1", Body: "from bob"}); err != nil {
+		t.Fatalf("Create(bob) error = %v", err)
+	}
+
+	if err := svc.DeleteUser("alice"); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	notes, _ := svc.List("bob", "t1")
+	if len(notes) != 1 || notes[0].AuthorProfileID != "bob" {
+		t.Fatalf("expected only bob's note to remain, got %+v", notes)
+	}
+}
@@ -3,6 +3,7 @@ package trakt
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,20 @@ const (
 	traktAPIVersion = "2"
 )
 
+// ErrUnauthorized is returned when Trakt rejects the access token (expired or revoked).
+var ErrUnauthorized = errors.New("trakt access token rejected")
+
+// HTTPError is an unexpected status returned by the Trakt API.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Status + " - " + e.Body
+}
+
 // Client handles Trakt API interactions for OAuth and data fetching
 type Client struct {
 	httpClient   *http.Client
@@ -516,9 +531,12 @@ func (c *Client) AddToHistory(accessToken string, request SyncHistoryRequest) (*
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("trakt sync history failed: %w", ErrUnauthorized)
+	}
 	if resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("trakt sync history failed: %s - %s", resp.Status, string(respBody))
+		return nil, fmt.Errorf("trakt sync history failed: %w", &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(respBody)})
 	}
 
 	var syncResp SyncHistoryResponse
@@ -904,3 +922,44 @@ func (c *Client) RemoveFromWatchlist(accessToken string, movies []SyncMovie, sho
 
 	return nil
 }
+
+// CalendarShow is an episode airing on the user's "my shows" calendar
+type CalendarShow struct {
+	FirstAired time.Time `json:"first_aired"`
+	Episode    Episode   `json:"episode"`
+	Show       Show      `json:"show"`
+}
+
+// GetMyShowsCalendar retrieves the episodes of shows the user watches that air
+// within days of startDate (at most 33 days, as Trakt allows)
+func (c *Client) GetMyShowsCalendar(accessToken string, startDate time.Time, days int) ([]CalendarShow, error) {
+	url := fmt.Sprintf("%s/calendars/my/shows/%s/%d", traktAPIBaseURL, startDate.UTC().Format("2006-01-02"), days)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setTraktHeaders(req, accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("trakt api request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("trakt calendar failed: %w", ErrUnauthorized)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("trakt calendar failed: %s - %s", resp.Status, string(respBody))
+	}
+
+	var items []CalendarShow
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return items, nil
+}
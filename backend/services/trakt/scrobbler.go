@@ -1,6 +1,8 @@
 package trakt
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
@@ -92,43 +94,44 @@ func (s *Scrobbler) getAccessTokenForUser(userID string) (string, error) {
 	if account.ExpiresAt > 0 {
 		expiresIn := account.ExpiresAt - time.Now().Unix()
 		if expiresIn < 3600 && account.RefreshToken != "" {
-			token, err := s.client.RefreshAccessToken(account.RefreshToken)
-			if err != nil {
-				return "", err
-			}
-
-			// Update account with new tokens
-			settings, err := s.configManager.Load()
-			if err != nil {
-				return "", err
-			}
-
-			updatedAccount := settings.Trakt.GetAccountByID(account.ID)
-			if updatedAccount != nil {
-				updatedAccount.AccessToken = token.AccessToken
-				updatedAccount.RefreshToken = token.RefreshToken
-				updatedAccount.ExpiresAt = token.CreatedAt + int64(token.ExpiresIn)
-				settings.Trakt.UpdateAccount(*updatedAccount)
-
-				if err := s.configManager.Save(settings); err != nil {
-					return "", err
-				}
-			}
-
-			return token.AccessToken, nil
+			return s.refreshAccountToken(account)
 		}
 	}
 
 	return account.AccessToken, nil
 }
 
-// ScrobbleMovie syncs a watched movie to Trakt for the given user.
-func (s *Scrobbler) ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID string, watchedAt time.Time) error {
-	if !s.IsEnabledForUser(userID) {
-		log.Printf("[trakt] scrobbling not enabled for user %s", userID)
-		return nil
+// refreshAccountToken exchanges the account's refresh token for a new access token and saves it.
+func (s *Scrobbler) refreshAccountToken(account *config.TraktAccount) (string, error) {
+	token, err := s.client.RefreshAccessToken(account.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	// Update account with new tokens
+	settings, err := s.configManager.Load()
+	if err != nil {
+		return "", err
 	}
 
+	updatedAccount := settings.Trakt.GetAccountByID(account.ID)
+	if updatedAccount != nil {
+		updatedAccount.AccessToken = token.AccessToken
+		updatedAccount.RefreshToken = token.RefreshToken
+		updatedAccount.ExpiresAt = token.CreatedAt + int64(token.ExpiresIn)
+		settings.Trakt.UpdateAccount(*updatedAccount)
+
+		if err := s.configManager.Save(settings); err != nil {
+			return "", err
+		}
+	}
+
+	return token.AccessToken, nil
+}
+
+// withTokenRetry runs send with the user's access token. If Trakt rejects the
+// token before its recorded expiry, the token is refreshed once and send retried.
+func (s *Scrobbler) withTokenRetry(userID string, send func(accessToken string) error) error {
 	accessToken, err := s.getAccessTokenForUser(userID)
 	if err != nil || accessToken == "" {
 		return err
@@ -140,30 +143,43 @@ func (s *Scrobbler) ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID stri
 		s.client.UpdateCredentials(account.ClientID, account.ClientSecret)
 	}
 
-	watchedAtStr := watchedAt.UTC().Format(time.RFC3339)
-	return s.client.AddMovieToHistory(accessToken, tmdbID, tvdbID, imdbID, watchedAtStr)
+	err = send(accessToken)
+	if !errors.Is(err, ErrUnauthorized) || account == nil || account.RefreshToken == "" {
+		return err
+	}
+
+	log.Printf("[trakt] access token rejected for user %s, refreshing", userID)
+	accessToken, refreshErr := s.refreshAccountToken(account)
+	if refreshErr != nil {
+		return fmt.Errorf("%w (token refresh failed: %v)", err, refreshErr)
+	}
+	return send(accessToken)
 }
 
-// ScrobbleEpisode syncs a watched episode to Trakt using show TVDB ID + season/episode for the given user.
-func (s *Scrobbler) ScrobbleEpisode(userID string, showTVDBID, season, episode int, watchedAt time.Time) error {
+// ScrobbleMovie syncs a watched movie to Trakt for the given user.
+func (s *Scrobbler) ScrobbleMovie(userID string, tmdbID, tvdbID int, imdbID string, watchedAt time.Time) error {
 	if !s.IsEnabledForUser(userID) {
 		log.Printf("[trakt] scrobbling not enabled for user %s", userID)
 		return nil
 	}
 
-	accessToken, err := s.getAccessTokenForUser(userID)
-	if err != nil || accessToken == "" {
-		return err
-	}
+	watchedAtStr := watchedAt.UTC().Format(time.RFC3339)
+	return s.withTokenRetry(userID, func(accessToken string) error {
+		return s.client.AddMovieToHistory(accessToken, tmdbID, tvdbID, imdbID, watchedAtStr)
+	})
+}
 
-	// Set client credentials for this account
-	account := s.getAccountForUser(userID)
-	if account != nil {
-		s.client.UpdateCredentials(account.ClientID, account.ClientSecret)
+// ScrobbleEpisode syncs a watched episode to Trakt using show TVDB ID + season/episode for the given user.
+func (s *Scrobbler) ScrobbleEpisode(userID string, showTVDBID, season, episode int, watchedAt time.Time) error {
+	if !s.IsEnabledForUser(userID) {
+		log.Printf("[trakt] scrobbling not enabled for user %s", userID)
+		return nil
 	}
 
 	watchedAtStr := watchedAt.UTC().Format(time.RFC3339)
-	return s.client.AddEpisodeToHistory(accessToken, showTVDBID, season, episode, watchedAtStr)
+	return s.withTokenRetry(userID, func(accessToken string) error {
+		return s.client.AddEpisodeToHistory(accessToken, showTVDBID, season, episode, watchedAtStr)
+	})
 }
 
 // ScrobbleMovieLegacy is for backward compatibility - scrobbles without user context.
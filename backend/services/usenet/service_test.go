@@ -50,7 +50,9 @@ func (s *stubPoolManager) GetPool() (nntppool.UsenetConnectionPool, error) {
 	return s.pool, nil
 }
 
-func (s *stubPoolManager) SetProviders(providers []nntppool.UsenetProviderConfig) error { return nil }
+func (s *stubPoolManager) SetProviders(providers []nntppool.UsenetProviderConfig, bindings map[string]string) error {
+	return nil
+}
 
 func (s *stubPoolManager) ClearPool() error {
 	s.pool = nil
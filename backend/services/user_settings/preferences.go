@@ -0,0 +1,133 @@
+package user_settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+var (
+	ErrPreferenceKeyRequired = errors.New("preference key is required")
+	ErrPreferencesTooLarge   = errors.New("client preferences are too large")
+)
+
+const (
+	maxPreferenceKeys  = 200
+	maxPreferenceBytes = 64 * 1024
+)
+
+// ClientPreferences returns the profile's synced UI preferences. A profile
+// that never synced any has version 0 and no values.
+func (s *Service) ClientPreferences(userID string) (models.ClientPreferences, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.ClientPreferences{}, ErrUserIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyPreferences(s.settings[userID].ClientPreferences), nil
+}
+
+// UpdateClientPreferences applies a device's changes on top of the stored
+// preferences. Keys another device changed since update.BaseVersion are left
+// alone and reported as conflicts unless the update is forced; every other key
+// is applied, so devices editing different preferences never clash. The
+// version only moves when something changed.
+func (s *Service) UpdateClientPreferences(userID, clientID string, update models.ClientPreferencesUpdate) (models.ClientPreferencesResult, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.ClientPreferencesResult{}, ErrUserIDRequired
+	}
+
+	changes := make(map[string]json.RawMessage, len(update.Set)+len(update.Remove))
+	for key, value := range update.Set {
+		changes[key] = value
+	}
+	for _, key := range update.Remove {
+		changes[key] = json.RawMessage("null")
+	}
+	for key := range changes {
+		if strings.TrimSpace(key) == "" {
+			return models.ClientPreferencesResult{}, ErrPreferenceKeyRequired
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.settings[userID]
+	prefs := copyPreferences(settings.ClientPreferences)
+	version := prefs.Version + 1
+
+	var result models.ClientPreferencesResult
+	changed := false
+	for key, value := range changes {
+		current, exists := prefs.Values[key]
+		if exists && sameValue(current.Value, value) {
+			continue
+		}
+		if !exists && (models.ClientPreferenceValue{Value: value}).Removed() {
+			continue
+		}
+		if exists && current.Version > update.BaseVersion && !update.Force {
+			result.Conflicts = append(result.Conflicts, key)
+			continue
+		}
+		prefs.Values[key] = models.ClientPreferenceValue{Value: value, Version: version, UpdatedBy: clientID}
+		changed = true
+	}
+	sort.Strings(result.Conflicts)
+
+	if !changed {
+		result.Preferences = prefs
+		return result, nil
+	}
+
+	if len(prefs.Values) > maxPreferenceKeys {
+		return models.ClientPreferencesResult{}, ErrPreferencesTooLarge
+	}
+	if data, err := json.Marshal(prefs.Values); err != nil || len(data) > maxPreferenceBytes {
+		return models.ClientPreferencesResult{}, ErrPreferencesTooLarge
+	}
+
+	prefs.Version = version
+	prefs.UpdatedAt = time.Now().UTC()
+	stored := copyPreferences(&prefs)
+	settings.ClientPreferences = &stored
+	s.settings[userID] = settings
+	if err := s.saveLocked(); err != nil {
+		return models.ClientPreferencesResult{}, err
+	}
+
+	result.Preferences = prefs
+	return result, nil
+}
+
+// copyPreferences returns a copy that does not share the values map.
+func copyPreferences(prefs *models.ClientPreferences) models.ClientPreferences {
+	if prefs == nil {
+		return models.ClientPreferences{Values: make(map[string]models.ClientPreferenceValue)}
+	}
+	copied := *prefs
+	copied.Values = make(map[string]models.ClientPreferenceValue, len(prefs.Values))
+	for key, value := range prefs.Values {
+		copied.Values[key] = value
+	}
+	return copied
+}
+
+// sameValue compares two JSON values ignoring insignificant whitespace.
+func sameValue(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
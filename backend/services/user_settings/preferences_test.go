@@ -0,0 +1,125 @@
+package user_settings
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"novastream/models"
+)
+
+func raw(v string) json.RawMessage { return json.RawMessage(v) }
+
+func TestClientPreferencesMergeAcrossDevices(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both devices start from the same state
+	base, err := svc.UpdateClientPreferences("user", "tv", models.ClientPreferencesUpdate{
+		Set: map[string]json.RawMessage{"theme": raw(`"dark"`), "rowOrder": raw(`["continue","trending"]`)},
+	})
+	if err != nil {
+		t.Fatalf("initial update: %v", err)
+	}
+	if base.Preferences.Version != 1 || len(base.Conflicts) != 0 {
+		t.Fatalf("unexpected initial result %+v", base)
+	}
+
+	// The phone changes the theme, the tablet the row order: both apply
+	if _, err := svc.UpdateClientPreferences("user", "phone", models.ClientPreferencesUpdate{
+		BaseVersion: 1,
+		Set:         map[string]json.RawMessage{"theme": raw(`"light"`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := svc.UpdateClientPreferences("user", "tablet", models.ClientPreferencesUpdate{
+		BaseVersion: 1,
+		Set:         map[string]json.RawMessage{"rowOrder": raw(`["trending"]`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Conflicts) != 0 || merged.Preferences.Version != 3 {
+		t.Fatalf("expected a clean merge at version 3, got %+v", merged)
+	}
+	if got := string(merged.Preferences.Values["theme"].Value); got != `"light"` {
+		t.Errorf("expected the phone's theme to be kept, got %s", got)
+	}
+
+	// The tablet's stale theme edit conflicts and the stored value wins
+	conflicted, err := svc.UpdateClientPreferences("user", "tablet", models.ClientPreferencesUpdate{
+		BaseVersion: 1,
+		Set:         map[string]json.RawMessage{"theme": raw(`"oled"`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(conflicted.Conflicts, []string{"theme"}) || conflicted.Preferences.Version != 3 {
+		t.Fatalf("expected a theme conflict without a new version, got %+v", conflicted)
+	}
+
+	// Forcing overwrites it
+	forced, err := svc.UpdateClientPreferences("user", "tablet", models.ClientPreferencesUpdate{
+		BaseVersion: 1,
+		Set:         map[string]json.RawMessage{"theme": raw(`"oled"`)},
+		Force:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme := forced.Preferences.Values["theme"]
+	if string(theme.Value) != `"oled"` || theme.UpdatedBy != "tablet" || theme.Version != 4 {
+		t.Fatalf("expected the forced theme at version 4, got %+v", theme)
+	}
+
+	// Removal leaves a marker for devices that still have the key
+	removed, err := svc.UpdateClientPreferences("user", "tv", models.ClientPreferencesUpdate{BaseVersion: 4, Remove: []string{"rowOrder"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed.Preferences.Values["rowOrder"].Removed() {
+		t.Errorf("expected rowOrder to be marked removed, got %+v", removed.Preferences.Values["rowOrder"])
+	}
+}
+
+func TestClientPreferencesSurviveSettingsUpdates(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateClientPreferences("user", "tv", models.ClientPreferencesUpdate{
+		Set: map[string]json.RawMessage{"theme": raw(`"dark"`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving the regular settings, even empty ones, keeps the preferences
+	if err := svc.Update("user", models.UserSettings{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefs, err := reloaded.ClientPreferences("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefs.Version != 1 || string(prefs.Values["theme"].Value) != `"dark"` {
+		t.Fatalf("expected the theme to persist, got %+v", prefs)
+	}
+
+	_, err = reloaded.UpdateClientPreferences("user", "tv", models.ClientPreferencesUpdate{
+		BaseVersion: 1,
+		Set:         map[string]json.RawMessage{"blob": raw(`"` + strings.Repeat("x", maxPreferenceBytes) + `"`)},
+	})
+	if !errors.Is(err, ErrPreferencesTooLarge) {
+		t.Errorf("expected ErrPreferencesTooLarge, got %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"novastream/internal/database"
 	"novastream/models"
 )
 
@@ -18,15 +19,35 @@ var (
 	ErrUserIDRequired     = errors.New("user id is required")
 )
 
+// settingsCollection is the database collection holding per-user settings.
+const settingsCollection = "user_settings"
+
+// Store keeps user settings in the database instead of user_settings.json.
+type Store interface {
+	Imported(collections ...string) (bool, error)
+	Load(collection string) ([]database.Document, error)
+	Import(collections map[string][]database.Document, sources ...string) error
+	Save(collections map[string][]database.Document) error
+}
+
+var _ Store = (*database.DocumentStore)(nil)
+
 // Service manages persistence and retrieval of per-user settings.
 type Service struct {
 	mu       sync.RWMutex
 	path     string
+	store    Store // nil keeps settings in user_settings.json
 	settings map[string]models.UserSettings
 }
 
 // NewService creates a user settings service storing data inside the provided directory.
 func NewService(storageDir string) (*Service, error) {
+	return NewServiceWithStore(storageDir, nil)
+}
+
+// NewServiceWithStore creates a user settings service kept in store. The
+// first time, the user_settings.json in storageDir is imported into it.
+func NewServiceWithStore(storageDir string, store Store) (*Service, error) {
 	if strings.TrimSpace(storageDir) == "" {
 		return nil, ErrStorageDirRequired
 	}
@@ -44,6 +65,12 @@ func NewService(storageDir string) (*Service, error) {
 		return nil, err
 	}
 
+	if store != nil {
+		if err := svc.useStore(store); err != nil {
+			return nil, err
+		}
+	}
+
 	return svc, nil
 }
 
@@ -145,6 +172,9 @@ func (s *Service) Update(userID string, settings models.UserSettings) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Synced client preferences have their own endpoint; keep them
+	settings.ClientPreferences = s.settings[userID].ClientPreferences
+
 	// If settings are empty, delete the entry instead of saving
 	if isSettingsEmpty(settings) {
 		delete(s.settings, userID)
@@ -164,7 +194,9 @@ func isSettingsEmpty(s models.UserSettings) bool {
 		s.Playback.PreferredSubtitleLanguage != "" ||
 		s.Playback.PreferredSubtitleMode != "" ||
 		s.Playback.UseLoadingScreen ||
-		s.Playback.SubtitleSize != 0 {
+		s.Playback.SubtitleSize != 0 ||
+		s.Playback.PreferAudioDescription ||
+		s.Playback.PreferSDH {
 		return false
 	}
 
@@ -197,6 +229,20 @@ func isSettingsEmpty(s models.UserSettings) bool {
 		return false
 	}
 
+	// Check Ranking
+	if s.Ranking != nil && (len(s.Ranking.Criteria) > 0 || s.Ranking.Strategy != "") {
+		return false
+	}
+
+	// Check ContinueWatching
+	if s.ContinueWatching.Enabled() {
+		return false
+	}
+
+	if s.Specials.Mode != "" {
+		return false
+	}
+
 	// Check Network
 	if s.Network.HomeWifiSSID != "" ||
 		s.Network.HomeBackendUrl != "" ||
@@ -204,6 +250,10 @@ func isSettingsEmpty(s models.UserSettings) bool {
 		return false
 	}
 
+	if s.ClientPreferences != nil && len(s.ClientPreferences.Values) > 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -258,7 +308,66 @@ func (s *Service) load() error {
 	return nil
 }
 
+// useStore moves the settings into store, importing the loaded JSON file
+// unless that was already done.
+func (s *Service) useStore(store Store) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported, err := store.Imported(settingsCollection)
+	if err != nil {
+		return fmt.Errorf("check user settings import: %w", err)
+	}
+	if !imported {
+		docs, err := s.documentsLocked()
+		if err != nil {
+			return err
+		}
+		if err := store.Import(map[string][]database.Document{settingsCollection: docs}, s.path); err != nil {
+			return fmt.Errorf("import user settings: %w", err)
+		}
+		s.store = store
+		return nil
+	}
+
+	docs, err := store.Load(settingsCollection)
+	if err != nil {
+		return fmt.Errorf("load user settings: %w", err)
+	}
+	s.settings = make(map[string]models.UserSettings, len(docs))
+	for _, doc := range docs {
+		var settings models.UserSettings
+		if err := json.Unmarshal(doc.Data, &settings); err != nil {
+			return fmt.Errorf("decode user settings for %s: %w", doc.UserID, err)
+		}
+		s.settings[doc.UserID] = settings
+	}
+	s.store = store
+	return nil
+}
+
+// documentsLocked encodes each profile's settings as a database document.
+func (s *Service) documentsLocked() ([]database.Document, error) {
+	docs := make([]database.Document, 0, len(s.settings))
+	for userID, settings := range s.settings {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return nil, fmt.Errorf("encode user settings for %s: %w", userID, err)
+		}
+		docs = append(docs, database.Document{UserID: userID, Key: "settings", Data: data})
+	}
+	return docs, nil
+}
+
 func (s *Service) saveLocked() error {
+	if s.store != nil {
+		docs, err := s.documentsLocked()
+		if err != nil {
+			return err
+		}
+		return s.store.Save(map[string][]database.Document{settingsCollection: docs})
+	}
+
 	tmp := s.path + ".tmp"
 	file, err := os.Create(tmp)
 	if err != nil {
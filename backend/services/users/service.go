@@ -16,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"novastream/internal/database"
 	"novastream/models"
 )
 
@@ -29,18 +30,55 @@ var (
 	ErrInvalidIconURL     = errors.New("invalid icon URL")
 	ErrIconDownloadFailed = errors.New("failed to download icon")
 	ErrInvalidImageFormat = errors.New("invalid image format, must be PNG or JPG")
+	ErrLastUser           = errors.New("cannot delete the last user")
+	ErrNotDeleted         = errors.New("profile is not deleted")
 )
 
+// DefaultDeletedRetention is how long a deleted profile can be restored
+// before it and its data are purged.
+const DefaultDeletedRetention = 30 * 24 * time.Hour
+
+// Database collections holding active and soft-deleted profiles.
+const (
+	usersCollection        = "users"
+	deletedUsersCollection = "deleted_users"
+)
+
+// Store keeps profiles in the database instead of users.json and
+// deleted_users.json.
+type Store interface {
+	Imported(collections ...string) (bool, error)
+	Load(collection string) ([]database.Document, error)
+	Import(collections map[string][]database.Document, sources ...string) error
+	Save(collections map[string][]database.Document) error
+}
+
+var _ Store = (*database.DocumentStore)(nil)
+
 // Service manages persistence of NovaStream user profiles.
 type Service struct {
-	mu         sync.RWMutex
-	path       string
-	storageDir string
-	users      map[string]models.User
+	mu          sync.RWMutex
+	path        string
+	deletedPath string
+	storageDir  string
+	store       Store // nil keeps profiles in JSON files
+	users       map[string]models.User
+
+	// Soft-deleted profiles, kept apart so that lookups never see them
+	deleted   map[string]models.User
+	retention time.Duration
+	onPurge   []func(userID string)
 }
 
 // NewService creates a users service storing data inside the provided directory.
 func NewService(storageDir string) (*Service, error) {
+	return NewServiceWithStore(storageDir, nil)
+}
+
+// NewServiceWithStore creates a users service kept in store. The first time,
+// the profile JSON files in storageDir are imported into it. Profile icons
+// stay in storageDir.
+func NewServiceWithStore(storageDir string, store Store) (*Service, error) {
 	if strings.TrimSpace(storageDir) == "" {
 		return nil, ErrStorageDirRequired
 	}
@@ -50,15 +88,28 @@ func NewService(storageDir string) (*Service, error) {
 	}
 
 	svc := &Service{
-		path:       filepath.Join(storageDir, "users.json"),
-		storageDir: storageDir,
-		users:      make(map[string]models.User),
+		path:        filepath.Join(storageDir, "users.json"),
+		deletedPath: filepath.Join(storageDir, "deleted_users.json"),
+		storageDir:  storageDir,
+		users:       make(map[string]models.User),
+		deleted:     make(map[string]models.User),
+		retention:   DefaultDeletedRetention,
 	}
 
 	if err := svc.load(); err != nil {
 		return nil, err
 	}
 
+	if err := svc.loadDeleted(); err != nil {
+		return nil, err
+	}
+
+	if store != nil {
+		if err := svc.useStore(store); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := svc.ensureDefaultUser(); err != nil {
 		return nil, err
 	}
@@ -748,6 +799,8 @@ func (s *Service) GetUsersByPlexAccountID(plexAccountID string) []models.User {
 }
 
 // Delete removes a user by ID. The last remaining user cannot be deleted.
+// The profile is soft-deleted: it disappears from every lookup but can be
+// restored until its retention window ends and PurgeExpired removes it.
 func (s *Service) Delete(id string) error {
 	id = strings.TrimSpace(id)
 	if id == "" {
@@ -757,19 +810,203 @@ func (s *Service) Delete(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.users[id]; !ok {
+	user, ok := s.users[id]
+	if !ok {
 		return ErrUserNotFound
 	}
 
 	if len(s.users) <= 1 {
-		return fmt.Errorf("cannot delete the last user")
+		return ErrLastUser
 	}
 
+	now := time.Now().UTC()
+	purgeAt := now.Add(s.retention)
+	user.DeletedAt = &now
+	user.PurgeAt = &purgeAt
+	s.deleted[id] = user
 	delete(s.users, id)
 
+	if err := s.saveDeletedLocked(); err != nil {
+		delete(s.deleted, id)
+		s.users[id] = user
+		return err
+	}
+
 	return s.saveLocked()
 }
 
+// SetDeletedRetention sets how long deleted profiles can be restored.
+// It applies to profiles deleted afterwards.
+func (s *Service) SetDeletedRetention(retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retention = retention
+}
+
+// OnPurge registers a callback that removes a profile's data once the
+// profile is purged. Callbacks run without the service lock held.
+func (s *Service) OnPurge(fn func(userID string)) {
+	if fn == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPurge = append(s.onPurge, fn)
+}
+
+// ListDeleted returns all soft-deleted profiles, soonest purge first.
+// This should only be used by master accounts.
+func (s *Service) ListDeleted() []models.User {
+	return s.listDeleted(func(models.User) bool { return true })
+}
+
+// ListDeletedForAccount returns an account's soft-deleted profiles, soonest purge first.
+func (s *Service) ListDeletedForAccount(accountID string) []models.User {
+	return s.listDeleted(func(u models.User) bool { return u.AccountID == accountID })
+}
+
+func (s *Service) listDeleted(include func(models.User) bool) []models.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.User, 0)
+	for _, u := range s.deleted {
+		if include(u) {
+			users = append(users, u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].PurgeAt.Equal(*users[j].PurgeAt) {
+			return users[i].Name < users[j].Name
+		}
+		return users[i].PurgeAt.Before(*users[j].PurgeAt)
+	})
+
+	return users
+}
+
+// GetDeleted returns the soft-deleted profile with the given ID if present.
+func (s *Service) GetDeleted(id string) (models.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.deleted[strings.TrimSpace(id)]
+	return user, ok
+}
+
+// Restore brings back a soft-deleted profile with its data intact.
+func (s *Service) Restore(id string) (models.User, error) {
+	id = strings.TrimSpace(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.deleted[id]
+	if !ok {
+		if _, active := s.users[id]; active {
+			return models.User{}, ErrNotDeleted
+		}
+		return models.User{}, ErrUserNotFound
+	}
+
+	user.DeletedAt = nil
+	user.PurgeAt = nil
+	user.UpdatedAt = time.Now().UTC()
+	s.users[id] = user
+	delete(s.deleted, id)
+
+	if err := s.saveLocked(); err != nil {
+		delete(s.users, id)
+		return models.User{}, err
+	}
+
+	if err := s.saveDeletedLocked(); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// Purge permanently removes a soft-deleted profile and its data without
+// waiting for the retention window to end.
+func (s *Service) Purge(id string) error {
+	id = strings.TrimSpace(id)
+
+	s.mu.Lock()
+	user, ok := s.deleted[id]
+	if !ok {
+		_, active := s.users[id]
+		s.mu.Unlock()
+		if active {
+			return ErrNotDeleted
+		}
+		return ErrUserNotFound
+	}
+
+	delete(s.deleted, id)
+	err := s.saveDeletedLocked()
+	hooks := append([]func(string){}, s.onPurge...)
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.purgeData(user, hooks)
+	return nil
+}
+
+// PurgeExpired permanently removes deleted profiles whose retention window
+// has ended and returns their IDs.
+func (s *Service) PurgeExpired() ([]string, error) {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	var expired []models.User
+	for id, user := range s.deleted {
+		if user.PurgeAt == nil || !user.PurgeAt.After(now) {
+			expired = append(expired, user)
+			delete(s.deleted, id)
+		}
+	}
+	if len(expired) == 0 {
+		s.mu.Unlock()
+		return nil, nil
+	}
+
+	err := s.saveDeletedLocked()
+	hooks := append([]func(string){}, s.onPurge...)
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(expired))
+	for _, user := range expired {
+		s.purgeData(user, hooks)
+		ids = append(ids, user.ID)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func (s *Service) purgeData(user models.User, hooks []func(string)) {
+	if user.IconURL != "" {
+		os.Remove(filepath.Join(s.storageDir, "profile-icons", user.IconURL)) // Ignore error - file might not exist
+	}
+	for _, hook := range hooks {
+		hook(user.ID)
+	}
+}
+
 func (s *Service) ensureDefaultUser() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -855,7 +1092,158 @@ func (s *Service) load() error {
 	return nil
 }
 
+func (s *Service) loadDeleted() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.deletedPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read deleted users file: %w", err)
+	}
+
+	var stored []models.User
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("decode deleted users: %w", err)
+	}
+
+	for _, user := range stored {
+		if strings.TrimSpace(user.ID) == "" {
+			continue
+		}
+		if _, active := s.users[user.ID]; active {
+			continue
+		}
+		if user.DeletedAt == nil {
+			deletedAt := user.UpdatedAt
+			user.DeletedAt = &deletedAt
+		}
+		if user.PurgeAt == nil {
+			purgeAt := user.DeletedAt.Add(s.retention)
+			user.PurgeAt = &purgeAt
+		}
+		s.deleted[user.ID] = user
+	}
+
+	return nil
+}
+
+// useStore moves the profiles into store, importing the loaded JSON files
+// unless that was already done.
+func (s *Service) useStore(store Store) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported, err := store.Imported(usersCollection, deletedUsersCollection)
+	if err != nil {
+		return fmt.Errorf("check users import: %w", err)
+	}
+	if !imported {
+		collections, err := s.documentsLocked()
+		if err != nil {
+			return err
+		}
+		if err := store.Import(collections, s.path, s.deletedPath); err != nil {
+			return fmt.Errorf("import users: %w", err)
+		}
+		s.store = store
+		return nil
+	}
+
+	if s.users, err = loadUsers(store, usersCollection); err != nil {
+		return err
+	}
+	if s.deleted, err = loadUsers(store, deletedUsersCollection); err != nil {
+		return err
+	}
+	s.store = store
+	return nil
+}
+
+func loadUsers(store Store, collection string) (map[string]models.User, error) {
+	docs, err := store.Load(collection)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", collection, err)
+	}
+	users := make(map[string]models.User, len(docs))
+	for _, doc := range docs {
+		var user models.User
+		if err := json.Unmarshal(doc.Data, &user); err != nil {
+			return nil, fmt.Errorf("decode %s %s: %w", collection, doc.Key, err)
+		}
+		users[doc.Key] = user
+	}
+	return users, nil
+}
+
+// documentsLocked encodes the active and deleted profiles as database documents.
+func (s *Service) documentsLocked() (map[string][]database.Document, error) {
+	collections := map[string][]database.Document{
+		usersCollection:        make([]database.Document, 0, len(s.users)),
+		deletedUsersCollection: make([]database.Document, 0, len(s.deleted)),
+	}
+	for collection, users := range map[string]map[string]models.User{usersCollection: s.users, deletedUsersCollection: s.deleted} {
+		for id, user := range users {
+			data, err := json.Marshal(user)
+			if err != nil {
+				return nil, fmt.Errorf("encode user %s: %w", id, err)
+			}
+			collections[collection] = append(collections[collection], database.Document{Key: id, Data: data})
+		}
+	}
+	return collections, nil
+}
+
+// saveStoreLocked writes active and deleted profiles in one transaction, so
+// moving a profile between them is atomic.
+func (s *Service) saveStoreLocked() error {
+	collections, err := s.documentsLocked()
+	if err != nil {
+		return err
+	}
+	return s.store.Save(collections)
+}
+
+// Must be called with s.mu held.
+func (s *Service) saveDeletedLocked() error {
+	if s.store != nil {
+		return s.saveStoreLocked()
+	}
+
+	users := make([]models.User, 0, len(s.deleted))
+	for _, user := range s.deleted {
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode deleted users: %w", err)
+	}
+
+	tmp := s.deletedPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write deleted users temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.deletedPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace deleted users file: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) saveLocked() error {
+	if s.store != nil {
+		return s.saveStoreLocked()
+	}
+
 	users := make([]models.User, 0, len(s.users))
 	for _, user := range s.users {
 		users = append(users, user)
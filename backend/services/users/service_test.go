@@ -1,9 +1,11 @@
 package users_test
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"novastream/models"
 	"novastream/services/users"
@@ -77,6 +79,91 @@ func TestDeleteLastUserFails(t *testing.T) {
 	}
 }
 
+func TestDeletedProfileCanBeRestored(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := users.NewService(dir)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	created, err := svc.CreateForAccount("acct", "Kids")
+	if err != nil {
+		t.Fatalf("CreateForAccount: %v", err)
+	}
+	if err := svc.Delete(created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Deleted profiles survive a restart but stay out of every lookup
+	svc, err = users.NewService(dir)
+	if err != nil {
+		t.Fatalf("failed to reload service: %v", err)
+	}
+	if svc.Exists(created.ID) || len(svc.ListForAccount("acct")) != 0 {
+		t.Fatal("expected deleted profile to be hidden")
+	}
+	deleted := svc.ListDeletedForAccount("acct")
+	if len(deleted) != 1 || deleted[0].DeletedAt == nil || deleted[0].PurgeAt == nil {
+		t.Fatalf("unexpected deleted profiles: %+v", deleted)
+	}
+	if want := deleted[0].DeletedAt.Add(users.DefaultDeletedRetention); !deleted[0].PurgeAt.Equal(want) {
+		t.Fatalf("expected purge at %s, got %s", want, deleted[0].PurgeAt)
+	}
+	if got := svc.ListDeletedForAccount("other"); len(got) != 0 {
+		t.Fatalf("deleted profile listed for another account: %+v", got)
+	}
+
+	restored, err := svc.Restore(created.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.PurgeAt != nil || !svc.Exists(created.ID) {
+		t.Fatalf("profile not restored: %+v", restored)
+	}
+	if _, err := svc.Restore(created.ID); !errors.Is(err, users.ErrNotDeleted) {
+		t.Fatalf("expected ErrNotDeleted, got %v", err)
+	}
+}
+
+func TestPurgeExpiredRunsHooks(t *testing.T) {
+	svc, err := users.NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	var purged []string
+	svc.OnPurge(func(userID string) { purged = append(purged, userID) })
+
+	kept, _ := svc.Create("Kept")
+	if err := svc.Delete(kept.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	svc.SetDeletedRetention(time.Millisecond)
+	expiring, _ := svc.Create("Expiring")
+	if err := svc.Delete(expiring.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	ids, err := svc.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != expiring.ID || len(purged) != 1 || purged[0] != expiring.ID {
+		t.Fatalf("expected only the expired profile purged, got %v (hooks %v)", ids, purged)
+	}
+	if _, err := svc.Restore(expiring.ID); !errors.Is(err, users.ErrUserNotFound) {
+		t.Fatalf("expected purged profile to be gone, got %v", err)
+	}
+
+	if err := svc.Purge(kept.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(purged) != 2 || len(svc.ListDeleted()) != 0 {
+		t.Fatalf("expected immediate purge, hooks %v", purged)
+	}
+}
+
 func TestSetIconURLSendsUserAgent(t *testing.T) {
 	var receivedUserAgent string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"novastream/internal/database"
 	"novastream/models"
 )
 
@@ -21,17 +22,55 @@ var (
 	ErrIDRequired         = errors.New("id is required")
 	ErrMediaTypeRequired  = errors.New("media type is required")
 	ErrIdentifierRequired = errors.New("id and media type are required")
+	ErrProfileIDRequired  = errors.New("profile id is required")
+	ErrShareWithSelf      = errors.New("a watchlist cannot be shared with its owner")
+	ErrInvalidPermission  = errors.New("permission must be read or edit")
+	ErrNotShared          = errors.New("watchlist is not shared with this profile")
+	ErrReadOnly           = errors.New("watchlist is shared read-only")
 )
 
+const (
+	// watchlistCollection is the database collection holding watchlist items.
+	watchlistCollection = "watchlist"
+	// sharesCollection holds watchlist shares, keyed by owner and profile.
+	sharesCollection = "watchlist_shares"
+)
+
+// Store keeps the watchlist in the database instead of watchlist.json.
+type Store interface {
+	Imported(collections ...string) (bool, error)
+	Load(collection string) ([]database.Document, error)
+	Import(collections map[string][]database.Document, sources ...string) error
+	Save(collections map[string][]database.Document) error
+}
+
+var _ Store = (*database.DocumentStore)(nil)
+
 // Service manages persistence and retrieval of user watchlist items.
 type Service struct {
-	mu    sync.RWMutex
-	path  string
-	items map[string]map[string]models.WatchlistItem
+	mu         sync.RWMutex
+	path       string
+	sharesPath string
+	store      Store // nil keeps the watchlist in watchlist.json
+	items      map[string]map[string]models.WatchlistItem
+	shares     map[string]map[string]models.WatchlistShare // owner -> profile -> share
+
+	changeRecorder ChangeRecorder
+}
+
+// ChangeRecorder journals per-profile item changes so clients can sync incrementally.
+type ChangeRecorder interface {
+	RecordChange(userID, collection, key, op string)
 }
 
 // NewService creates a watchlist service storing data inside the provided directory.
 func NewService(storageDir string) (*Service, error) {
+	return NewServiceWithStore(storageDir, nil)
+}
+
+// NewServiceWithStore creates a watchlist service kept in store. The first
+// time, the watchlist.json in storageDir is imported into it.
+func NewServiceWithStore(storageDir string, store Store) (*Service, error) {
 	if strings.TrimSpace(storageDir) == "" {
 		return nil, ErrStorageDirRequired
 	}
@@ -41,17 +80,41 @@ func NewService(storageDir string) (*Service, error) {
 	}
 
 	svc := &Service{
-		path:  filepath.Join(storageDir, "watchlist.json"),
-		items: make(map[string]map[string]models.WatchlistItem),
+		path:       filepath.Join(storageDir, "watchlist.json"),
+		sharesPath: filepath.Join(storageDir, "watchlist_shares.json"),
+		items:      make(map[string]map[string]models.WatchlistItem),
+		shares:     make(map[string]map[string]models.WatchlistShare),
 	}
 
 	if err := svc.load(); err != nil {
 		return nil, err
 	}
+	if err := svc.loadShares(); err != nil {
+		return nil, err
+	}
+
+	if store != nil {
+		if err := svc.useStore(store); err != nil {
+			return nil, err
+		}
+	}
 
 	return svc, nil
 }
 
+// SetChangeRecorder sets the journal notified when watchlist items change.
+func (s *Service) SetChangeRecorder(recorder ChangeRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changeRecorder = recorder
+}
+
+func (s *Service) recordChangeLocked(userID, key, op string) {
+	if s.changeRecorder != nil {
+		s.changeRecorder.RecordChange(userID, models.SyncCollectionWatchlist, key, op)
+	}
+}
+
 // List returns all watchlist items sorted by most recent additions first.
 func (s *Service) List(userID string) ([]models.WatchlistItem, error) {
 	userID = strings.TrimSpace(userID)
@@ -171,6 +234,7 @@ func (s *Service) AddOrUpdate(userID string, input models.WatchlistUpsert) (mode
 	}
 
 	perUser[key] = item
+	s.recordChangeLocked(userID, key, models.SyncOpUpsert)
 
 	if err := s.saveLocked(); err != nil {
 		return models.WatchlistItem{}, err
@@ -180,7 +244,7 @@ func (s *Service) AddOrUpdate(userID string, input models.WatchlistUpsert) (mode
 }
 
 // UpdateState is deprecated - watch status is now tracked separately via the history service.
-// This method is kept for backwards compatibility but does nothing.
+// This method is kept for backwards compatibility; it only journals the item as changed.
 func (s *Service) UpdateState(userID, mediaType, id string, watched *bool, progress interface{}) (models.WatchlistItem, error) {
 	userID = strings.TrimSpace(userID)
 	if userID == "" {
@@ -203,7 +267,9 @@ func (s *Service) UpdateState(userID, mediaType, id string, watched *bool, progr
 		return models.WatchlistItem{}, os.ErrNotExist
 	}
 
-	// Watch status is now tracked separately - this method does nothing but return the item
+	// Watch status is now tracked separately - clients calling this still expect
+	// the item to sync as updated
+	s.recordChangeLocked(userID, key, models.SyncOpUpsert)
 	return item, nil
 }
 
@@ -230,6 +296,7 @@ func (s *Service) Remove(userID, mediaType, id string) (bool, error) {
 	}
 
 	delete(perUser, key)
+	s.recordChangeLocked(userID, key, models.SyncOpDelete)
 
 	if err := s.saveLocked(); err != nil {
 		return false, err
@@ -238,6 +305,236 @@ func (s *Service) Remove(userID, mediaType, id string) (bool, error) {
 	return true, nil
 }
 
+// DeleteUser removes a profile's whole watchlist. Used when a deleted
+// profile is purged.
+func (s *Service) DeleteUser(userID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.removeSharesLocked(userID) {
+		if err := s.saveSharesLocked(); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := s.items[userID]; !ok {
+		return nil
+	}
+
+	delete(s.items, userID)
+	return s.saveLocked()
+}
+
+// Share gives profileID read or edit access to ownerID's watchlist, replacing
+// an earlier share between them. Callers check that both profiles belong to
+// the same household.
+func (s *Service) Share(ownerID, profileID string, permission models.WatchlistPermission) (models.WatchlistShare, error) {
+	ownerID = strings.TrimSpace(ownerID)
+	profileID = strings.TrimSpace(profileID)
+	if ownerID == "" {
+		return models.WatchlistShare{}, ErrUserIDRequired
+	}
+	if profileID == "" {
+		return models.WatchlistShare{}, ErrProfileIDRequired
+	}
+	if ownerID == profileID {
+		return models.WatchlistShare{}, ErrShareWithSelf
+	}
+	if permission != models.WatchlistPermissionRead && permission != models.WatchlistPermissionEdit {
+		return models.WatchlistShare{}, ErrInvalidPermission
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perOwner, ok := s.shares[ownerID]
+	if !ok {
+		perOwner = make(map[string]models.WatchlistShare)
+		s.shares[ownerID] = perOwner
+	}
+	share, exists := perOwner[profileID]
+	if !exists {
+		share = models.WatchlistShare{OwnerID: ownerID, ProfileID: profileID, SharedAt: time.Now().UTC()}
+	}
+	share.Permission = permission
+	perOwner[profileID] = share
+
+	if err := s.saveSharesLocked(); err != nil {
+		return models.WatchlistShare{}, err
+	}
+	return share, nil
+}
+
+// Unshare revokes profileID's access to ownerID's watchlist. Returns whether
+// it was shared.
+func (s *Service) Unshare(ownerID, profileID string) (bool, error) {
+	ownerID = strings.TrimSpace(ownerID)
+	profileID = strings.TrimSpace(profileID)
+	if ownerID == "" {
+		return false, ErrUserIDRequired
+	}
+	if profileID == "" {
+		return false, ErrProfileIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.shares[ownerID][profileID]; !ok {
+		return false, nil
+	}
+	delete(s.shares[ownerID], profileID)
+	if len(s.shares[ownerID]) == 0 {
+		delete(s.shares, ownerID)
+	}
+
+	if err := s.saveSharesLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Shares returns the shares userID granted and received, oldest first.
+func (s *Service) Shares(userID string) (models.WatchlistShares, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.WatchlistShares{}, ErrUserIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := models.WatchlistShares{
+		Granted:  make([]models.WatchlistShare, 0),
+		Received: make([]models.WatchlistShare, 0),
+	}
+	for ownerID, perOwner := range s.shares {
+		for profileID, share := range perOwner {
+			switch {
+			case ownerID == userID:
+				result.Granted = append(result.Granted, share)
+			case profileID == userID:
+				result.Received = append(result.Received, share)
+			}
+		}
+	}
+	sortShares(result.Granted)
+	sortShares(result.Received)
+	return result, nil
+}
+
+// Access reports whether profileID may read ownerID's watchlist, or change it
+// when edit is set. A profile always has full access to its own watchlist.
+func (s *Service) Access(profileID, ownerID string, edit bool) error {
+	profileID = strings.TrimSpace(profileID)
+	ownerID = strings.TrimSpace(ownerID)
+	if profileID == "" || ownerID == "" {
+		return ErrUserIDRequired
+	}
+	if profileID == ownerID {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	share, ok := s.shares[ownerID][profileID]
+	if !ok {
+		return ErrNotShared
+	}
+	if edit && share.Permission != models.WatchlistPermissionEdit {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// removeSharesLocked drops every share granted or received by userID and
+// reports whether there were any.
+func (s *Service) removeSharesLocked(userID string) bool {
+	_, removed := s.shares[userID]
+	delete(s.shares, userID)
+	for ownerID, perOwner := range s.shares {
+		if _, ok := perOwner[userID]; ok {
+			delete(perOwner, userID)
+			removed = true
+		}
+		if len(perOwner) == 0 {
+			delete(s.shares, ownerID)
+		}
+	}
+	return removed
+}
+
+func sortShares(shares []models.WatchlistShare) {
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].SharedAt.Equal(shares[j].SharedAt) {
+			return shares[i].OwnerID+shares[i].ProfileID < shares[j].OwnerID+shares[j].ProfileID
+		}
+		return shares[i].SharedAt.Before(shares[j].SharedAt)
+	})
+}
+
+// Merge stores a watchlist item from another server when it is not on the
+// local watchlist or was added there more recently, keeping its timestamps.
+// Returns whether it was applied.
+func (s *Service) Merge(userID string, item models.WatchlistItem) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+	if strings.TrimSpace(item.ID) == "" || strings.TrimSpace(item.MediaType) == "" {
+		return false, ErrIdentifierRequired
+	}
+	item.MediaType = strings.ToLower(strings.TrimSpace(item.MediaType))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensureUserLocked(userID)
+	key := item.Key()
+	if existing, ok := perUser[key]; ok && !item.AddedAt.After(existing.AddedAt) {
+		return false, nil
+	}
+
+	perUser[key] = item
+	s.recordChangeLocked(userID, key, models.SyncOpUpsert)
+	if err := s.saveLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveBefore removes a watchlist item removed on another server at
+// removedAt, unless it was added again locally since. Returns whether it was
+// removed.
+func (s *Service) RemoveBefore(userID, key string, removedAt time.Time) (bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return false, ErrUserIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensureUserLocked(userID)
+	existing, ok := perUser[key]
+	if !ok || existing.AddedAt.After(removedAt) {
+		return false, nil
+	}
+
+	delete(perUser, key)
+	s.recordChangeLocked(userID, key, models.SyncOpDelete)
+	if err := s.saveLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (s *Service) load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -297,7 +594,169 @@ func (s *Service) load() error {
 	return nil
 }
 
+// useStore moves the watchlist into store, importing the loaded JSON file
+// unless that was already done.
+func (s *Service) useStore(store Store) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported, err := store.Imported(watchlistCollection)
+	if err != nil {
+		return fmt.Errorf("check watchlist import: %w", err)
+	}
+	if !imported {
+		docs, err := s.documentsLocked()
+		if err != nil {
+			return err
+		}
+		shareDocs, err := s.shareDocumentsLocked()
+		if err != nil {
+			return err
+		}
+		collections := map[string][]database.Document{watchlistCollection: docs, sharesCollection: shareDocs}
+		if err := store.Import(collections, s.path, s.sharesPath); err != nil {
+			return fmt.Errorf("import watchlist: %w", err)
+		}
+		s.store = store
+		return nil
+	}
+
+	docs, err := store.Load(watchlistCollection)
+	if err != nil {
+		return fmt.Errorf("load watchlist: %w", err)
+	}
+	s.items = make(map[string]map[string]models.WatchlistItem)
+	for _, doc := range docs {
+		var item models.WatchlistItem
+		if err := json.Unmarshal(doc.Data, &item); err != nil {
+			return fmt.Errorf("decode watchlist item %s: %w", doc.Key, err)
+		}
+		s.ensureUserLocked(doc.UserID)[doc.Key] = normaliseItem(item)
+	}
+
+	// Shares were added after the watchlist moved into the database, so they
+	// are not in the import marker and an older database has none yet
+	shareDocs, err := store.Load(sharesCollection)
+	if err != nil {
+		return fmt.Errorf("load watchlist shares: %w", err)
+	}
+	s.shares = make(map[string]map[string]models.WatchlistShare)
+	for _, doc := range shareDocs {
+		var share models.WatchlistShare
+		if err := json.Unmarshal(doc.Data, &share); err != nil {
+			return fmt.Errorf("decode watchlist share %s: %w", doc.Key, err)
+		}
+		s.addShareLocked(share)
+	}
+	s.store = store
+	return nil
+}
+
+// loadShares reads watchlist_shares.json, if there is one.
+func (s *Service) loadShares() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.sharesPath)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read watchlist shares: %w", err)
+	}
+
+	var shares []models.WatchlistShare
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return fmt.Errorf("decode watchlist shares: %w", err)
+	}
+	for _, share := range shares {
+		s.addShareLocked(share)
+	}
+	return nil
+}
+
+func (s *Service) addShareLocked(share models.WatchlistShare) {
+	if share.OwnerID == "" || share.ProfileID == "" {
+		return
+	}
+	perOwner, ok := s.shares[share.OwnerID]
+	if !ok {
+		perOwner = make(map[string]models.WatchlistShare)
+		s.shares[share.OwnerID] = perOwner
+	}
+	perOwner[share.ProfileID] = share
+}
+
+// shareDocumentsLocked encodes every share as a database document.
+func (s *Service) shareDocumentsLocked() ([]database.Document, error) {
+	var docs []database.Document
+	for ownerID, perOwner := range s.shares {
+		for profileID, share := range perOwner {
+			data, err := json.Marshal(share)
+			if err != nil {
+				return nil, fmt.Errorf("encode watchlist share %s: %w", profileID, err)
+			}
+			docs = append(docs, database.Document{UserID: ownerID, Key: profileID, Data: data})
+		}
+	}
+	return docs, nil
+}
+
+func (s *Service) saveSharesLocked() error {
+	if s.store != nil {
+		docs, err := s.shareDocumentsLocked()
+		if err != nil {
+			return err
+		}
+		return s.store.Save(map[string][]database.Document{sharesCollection: docs})
+	}
+
+	shares := make([]models.WatchlistShare, 0)
+	for _, perOwner := range s.shares {
+		for _, share := range perOwner {
+			shares = append(shares, share)
+		}
+	}
+	sortShares(shares)
+
+	data, err := json.MarshalIndent(shares, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode watchlist shares: %w", err)
+	}
+	tmp := s.sharesPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write watchlist shares: %w", err)
+	}
+	if err := os.Rename(tmp, s.sharesPath); err != nil {
+		return fmt.Errorf("replace watchlist shares file: %w", err)
+	}
+	return nil
+}
+
+// documentsLocked encodes every watchlist item as a database document.
+func (s *Service) documentsLocked() ([]database.Document, error) {
+	var docs []database.Document
+	for userID, collection := range s.items {
+		for key, item := range collection {
+			data, err := json.Marshal(item)
+			if err != nil {
+				return nil, fmt.Errorf("encode watchlist item %s: %w", key, err)
+			}
+			docs = append(docs, database.Document{UserID: userID, Key: key, Data: data})
+		}
+	}
+	return docs, nil
+}
+
 func (s *Service) saveLocked() error {
+	if s.store != nil {
+		docs, err := s.documentsLocked()
+		if err != nil {
+			return err
+		}
+		return s.store.Save(map[string][]database.Document{watchlistCollection: docs})
+	}
+
 	byUser := make(map[string][]models.WatchlistItem, len(s.items))
 	for userID, collection := range s.items {
 		items := make([]models.WatchlistItem, 0, len(collection))
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"novastream/internal/database"
 	"novastream/models"
 	"novastream/services/watchlist"
 )
@@ -75,8 +76,15 @@ func TestServiceUpdateAndRemove(t *testing.T) {
 		t.Fatalf("failed to seed watchlist: %v", err)
 	}
 
+	recorder := &changeRecorder{}
+	svc.SetChangeRecorder(recorder)
+
 	// Note: Watch progress tracking has been moved to a separate service (history service)
-	// This test now only verifies basic watchlist functionality
+	// UpdateState only journals the item so clients still sync it
+	watched := true
+	if _, err := svc.UpdateState(models.DefaultUserID, "series", "s1", &watched, nil); err != nil {
+		t.Fatalf("update state returned error: %v", err)
+	}
 
 	removed, err := svc.Remove(models.DefaultUserID, "series", "s1")
 	if err != nil {
@@ -91,6 +99,19 @@ func TestServiceUpdateAndRemove(t *testing.T) {
 	} else if len(items) != 0 {
 		t.Fatalf("expected watchlist to be empty, got %d", len(items))
 	}
+
+	if want := []string{"series:s1 upsert", "series:s1 delete"}; len(recorder.changes) != 2 ||
+		recorder.changes[0] != want[0] || recorder.changes[1] != want[1] {
+		t.Fatalf("expected changes %v, got %v", want, recorder.changes)
+	}
+}
+
+type changeRecorder struct {
+	changes []string
+}
+
+func (r *changeRecorder) RecordChange(userID, collection, key, op string) {
+	r.changes = append(r.changes, key+" "+op)
 }
 
 func TestServiceIsolatesUsers(t *testing.T) {
@@ -162,3 +183,136 @@ func TestServiceLoadsLegacyFormat(t *testing.T) {
 		t.Fatalf("expected legacy item name, got %q", items[0].Name)
 	}
 }
+
+func TestServiceWithStoreImportsJSONOnce(t *testing.T) {
+	dir := t.TempDir()
+	legacy, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+	if _, err := legacy.AddOrUpdate(models.DefaultUserID, models.WatchlistUpsert{ID: "1", MediaType: "movie", Name: "Imported"}); err != nil {
+		t.Fatalf("failed to add item: %v", err)
+	}
+
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewDocumentRepository(db.Connection())
+
+	svc, err := watchlist.NewServiceWithStore(dir, database.NewDocumentStore(repo))
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "watchlist.json.migrated")); err != nil {
+		t.Fatalf("expected watchlist.json to be renamed after import: %v", err)
+	}
+	if _, err := svc.AddOrUpdate(models.DefaultUserID, models.WatchlistUpsert{ID: "2", MediaType: "series", Name: "Added"}); err != nil {
+		t.Fatalf("failed to add item: %v", err)
+	}
+
+	reopened, err := watchlist.NewServiceWithStore(dir, database.NewDocumentStore(repo))
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+	items, err := reopened.List(models.DefaultUserID)
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected imported and added items, got %+v", items)
+	}
+}
+
+func TestServiceSharesWatchlist(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+
+	if _, err := svc.Share("owner", "owner", models.WatchlistPermissionRead); err != watchlist.ErrShareWithSelf {
+		t.Fatalf("expected ErrShareWithSelf, got %v", err)
+	}
+	if _, err := svc.Share("owner", "reader", "admin"); err != watchlist.ErrInvalidPermission {
+		t.Fatalf("expected ErrInvalidPermission, got %v", err)
+	}
+	if _, err := svc.Share("owner", "reader", models.WatchlistPermissionRead); err != nil {
+		t.Fatalf("failed to share: %v", err)
+	}
+	if _, err := svc.Share("owner", "editor", models.WatchlistPermissionEdit); err != nil {
+		t.Fatalf("failed to share: %v", err)
+	}
+
+	for _, tc := range []struct {
+		profile string
+		edit    bool
+		want    error
+	}{
+		{profile: "owner", edit: true, want: nil},
+		{profile: "reader", edit: false, want: nil},
+		{profile: "reader", edit: true, want: watchlist.ErrReadOnly},
+		{profile: "editor", edit: true, want: nil},
+		{profile: "stranger", edit: false, want: watchlist.ErrNotShared},
+	} {
+		if err := svc.Access(tc.profile, "owner", tc.edit); err != tc.want {
+			t.Errorf("Access(%s, edit=%t) = %v, want %v", tc.profile, tc.edit, err, tc.want)
+		}
+	}
+
+	reloaded, err := watchlist.NewService(dir)
+	if err != nil {
+		t.Fatalf("failed to reload service: %v", err)
+	}
+	shares, err := reloaded.Shares("owner")
+	if err != nil {
+		t.Fatalf("shares returned error: %v", err)
+	}
+	if len(shares.Granted) != 2 || len(shares.Received) != 0 {
+		t.Fatalf("expected 2 granted shares after reload, got %+v", shares)
+	}
+	if received, _ := reloaded.Shares("editor"); len(received.Received) != 1 || received.Received[0].Permission != models.WatchlistPermissionEdit {
+		t.Fatalf("expected the editor to have received an edit share, got %+v", received)
+	}
+
+	if removed, err := reloaded.Unshare("owner", "reader"); err != nil || !removed {
+		t.Fatalf("expected share to be revoked, got %t %v", removed, err)
+	}
+	if err := reloaded.Access("reader", "owner", false); err != watchlist.ErrNotShared {
+		t.Fatalf("expected revoked access, got %v", err)
+	}
+
+	if err := reloaded.DeleteUser("editor"); err != nil {
+		t.Fatalf("delete user returned error: %v", err)
+	}
+	if shares, _ := reloaded.Shares("owner"); len(shares.Granted) != 0 {
+		t.Fatalf("expected shares with a purged profile to be removed, got %+v", shares)
+	}
+}
+
+func TestServiceWithStoreKeepsShares(t *testing.T) {
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewDocumentRepository(db.Connection())
+
+	dir := t.TempDir()
+	svc, err := watchlist.NewServiceWithStore(dir, database.NewDocumentStore(repo))
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+	if _, err := svc.Share("owner", "editor", models.WatchlistPermissionEdit); err != nil {
+		t.Fatalf("failed to share: %v", err)
+	}
+
+	reopened, err := watchlist.NewServiceWithStore(dir, database.NewDocumentStore(repo))
+	if err != nil {
+		t.Fatalf("expected service, got error: %v", err)
+	}
+	if err := reopened.Access("editor", "owner", true); err != nil {
+		t.Fatalf("expected the share to survive a restart, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"novastream/config"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// accessLogger is the active access log; nil while access logging is off.
+type accessLogger struct {
+	settings config.AccessLogSettings
+	logger   *slog.Logger
+	file     *lumberjack.Logger
+}
+
+var (
+	accessLog   atomic.Pointer[accessLogger]
+	accessLogMu sync.Mutex
+)
+
+// SetAccessLogSettings turns the access log on or off and applies its
+// rotation and redaction settings. The log file is reopened only when its
+// location or rotation changed.
+func SetAccessLogSettings(s config.AccessLogSettings) {
+	accessLogMu.Lock()
+	defer accessLogMu.Unlock()
+
+	previous := accessLog.Load()
+	if !s.Enabled || strings.TrimSpace(s.File) == "" {
+		accessLog.Store(nil)
+		if previous != nil {
+			previous.file.Close()
+		}
+		return
+	}
+
+	var file *lumberjack.Logger
+	if previous != nil && sameAccessLogFile(previous.settings, s) {
+		file = previous.file
+	} else {
+		file = &lumberjack.Logger{
+			Filename:   s.File,
+			MaxSize:    s.MaxSize,
+			MaxBackups: s.MaxBackups,
+			MaxAge:     s.MaxAge,
+			Compress:   s.Compress,
+		}
+	}
+	accessLog.Store(&accessLogger{
+		settings: s,
+		logger:   slog.New(slog.NewJSONHandler(file, nil)),
+		file:     file,
+	})
+	if previous != nil && previous.file != file {
+		previous.file.Close()
+	}
+}
+
+func sameAccessLogFile(a, b config.AccessLogSettings) bool {
+	return a.File == b.File && a.MaxSize == b.MaxSize && a.MaxBackups == b.MaxBackups &&
+		a.MaxAge == b.MaxAge && a.Compress == b.Compress
+}
+
+type accessLogRouteKey struct{}
+
+// AccessLogMiddleware writes one structured line per request to the access
+// log: method, route template, status, duration, bytes written and client ID.
+// It wraps the whole server so requests rejected before routing are logged
+// too; the route template is filled in by AccessLogRouteMiddleware.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := accessLog.Load()
+		if logger == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var route string
+		path := r.URL.Path
+		started := time.Now()
+		aw := &accessLogWriter{ResponseWriter: w}
+		next.ServeHTTP(aw, r.WithContext(context.WithValue(r.Context(), accessLogRouteKey{}, &route)))
+		if aw.status == 0 {
+			aw.status = http.StatusOK
+		}
+		if route == "" {
+			// Unmatched requests have no template; the path is all there is
+			route = path
+		}
+
+		s := logger.settings
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.Int("status", aw.status),
+			slog.Float64("durationMs", float64(time.Since(started).Microseconds())/1000),
+			slog.Int64("bytes", aw.bytes),
+			slog.String("clientId", accessLogClientID(r)),
+			slog.String("ip", accessLogIP(r, s.RedactIPs)),
+		}
+		if r.URL.RawQuery != "" {
+			attrs = append(attrs, slog.String("query", accessLogQuery(r.URL.Query(), s.RedactTokens)))
+		}
+		logger.logger.LogAttrs(context.Background(), slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// AccessLogRouteMiddleware records the template of the matched route for
+// AccessLogMiddleware, so logs group requests by endpoint and carry no IDs
+// from the path.
+func AccessLogRouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(accessLogRouteKey{}).(*string); ok {
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					*route = template
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// accessLogClientID identifies the app install making the request, from the
+// X-Client-ID header or the clientId query parameter.
+func accessLogClientID(r *http.Request) string {
+	if id := strings.TrimSpace(r.Header.Get("X-Client-ID")); id != "" {
+		return id
+	}
+	return strings.TrimSpace(r.URL.Query().Get("clientId"))
+}
+
+// accessLogIP returns the client address, truncated to its /24 (IPv4) or
+// /48 (IPv6) network when redacting.
+func accessLogIP(r *http.Request, redact bool) string {
+	addr := ClientIP(r)
+	if !redact {
+		return addr
+	}
+
+	ip := net.ParseIP(addr)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return ip.Mask(net.CIDRMask(24, 32)).String()
+	default:
+		return ip.Mask(net.CIDRMask(48, 128)).String()
+	}
+}
+
+// accessLogQuery renders the query string, blanking credentials when
+// redacting.
+func accessLogQuery(query url.Values, redact bool) string {
+	if redact {
+		for key, values := range query {
+			if !isCredentialParam(key) {
+				continue
+			}
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+		}
+	}
+	return query.Encode()
+}
+
+func isCredentialParam(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "pin", "code", "devicecode", "sig", "signature":
+		return true
+	}
+	for _, marker := range []string{"token", "key", "password", "secret", "auth"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLogWriter records the status and size of a response.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (aw *accessLogWriter) WriteHeader(status int) {
+	if aw.status == 0 {
+		aw.status = status
+	}
+	aw.ResponseWriter.WriteHeader(status)
+}
+
+func (aw *accessLogWriter) Write(p []byte) (int, error) {
+	if aw.status == 0 {
+		aw.status = http.StatusOK
+	}
+	n, err := aw.ResponseWriter.Write(p)
+	aw.bytes += int64(n)
+	return n, err
+}
+
+// Flush passes through so streamed responses are not held back.
+func (aw *accessLogWriter) Flush() {
+	if flusher, ok := aw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets connection upgrades through untouched.
+func (aw *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := aw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("access log: response writer does not support hijacking")
+	}
+	if aw.status == 0 {
+		aw.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (aw *accessLogWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
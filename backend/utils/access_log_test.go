@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"novastream/config"
+
+	"github.com/gorilla/mux"
+)
+
+func readAccessLog(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("malformed access log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	SetAccessLogSettings(config.AccessLogSettings{Enabled: true, File: path, MaxSize: 1, RedactIPs: true, RedactTokens: true})
+	t.Cleanup(func() { SetAccessLogSettings(config.AccessLogSettings{}) })
+
+	r := mux.NewRouter()
+	r.Use(AccessLogRouteMiddleware)
+	r.HandleFunc("/api/users/{userID}/watchlist", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	handler := AccessLogMiddleware(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/abc123/watchlist?token=secret&apiKey=k&page=2", nil)
+	req.RemoteAddr = "203.0.113.77:51234"
+	req.Header.Set("X-Client-ID", "tv-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+
+	entries := readAccessLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	entry := entries[0]
+	for key, want := range map[string]interface{}{
+		"method":   "POST",
+		"route":    "/api/users/{userID}/watchlist",
+		"status":   float64(http.StatusCreated),
+		"bytes":    float64(5),
+		"clientId": "tv-1",
+		"ip":       "203.0.113.0",
+		"query":    "apiKey=REDACTED&page=2&token=REDACTED",
+	} {
+		if entry[key] != want {
+			t.Errorf("%s: expected %v, got %v", key, want, entry[key])
+		}
+	}
+	if _, ok := entry["durationMs"]; !ok {
+		t.Error("expected a duration")
+	}
+	if entries[1]["route"] != "/api/missing" || entries[1]["status"] != float64(http.StatusNotFound) {
+		t.Errorf("expected the unmatched request logged by path, got %v", entries[1])
+	}
+}
+
+func TestAccessLogRedactionCanBeTurnedOff(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/live/stream?token=secret", nil)
+	req.RemoteAddr = "127.0.0.1:41000"
+	req.Header.Set("X-Forwarded-For", "2001:db8:1:2::5, 10.0.0.1")
+
+	if got := accessLogIP(req, false); got != "2001:db8:1:2::5" {
+		t.Errorf("expected the full address, got %s", got)
+	}
+	if got := accessLogIP(req, true); got != "2001:db8:1::" {
+		t.Errorf("expected the /48 network, got %s", got)
+	}
+	if got := accessLogQuery(req.URL.Query(), false); got != "token=secret" {
+		t.Errorf("expected the raw query, got %s", got)
+	}
+}
+
+func TestAccessLogDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	SetAccessLogSettings(config.AccessLogSettings{Enabled: true, File: path})
+	SetAccessLogSettings(config.AccessLogSettings{Enabled: false, File: path})
+
+	AccessLogMiddleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/settings", nil))
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no access log while disabled, stat err=%v", err)
+	}
+}
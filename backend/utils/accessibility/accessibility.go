@@ -0,0 +1,81 @@
+// Package accessibility detects audio-description and SDH tracks from ffprobe
+// stream dispositions and titles.
+package accessibility
+
+import "strings"
+
+// audioDescriptionIndicators are title fragments used by releases that don't
+// set the visual_impaired disposition.
+var audioDescriptionIndicators = []string{
+	"audio description",
+	"audio-description",
+	"described video",
+	"descriptive audio",
+	"descriptive video",
+	"visually impaired",
+	"dvs",
+}
+
+// sdhIndicators are title fragments used by releases that don't set the
+// hearing_impaired disposition.
+var sdhIndicators = []string{
+	"sdh",
+	"deaf",
+	"hard of hearing",
+	"hearing impaired",
+	"closed caption",
+	"cc",
+}
+
+// IsAudioDescription reports whether an audio stream is an audio-description
+// (AD) track, from its ffprobe disposition or title.
+func IsAudioDescription(disposition map[string]int, title string) bool {
+	if disposition["visual_impaired"] > 0 || disposition["descriptions"] > 0 {
+		return true
+	}
+	lower := strings.ToLower(title)
+	if hasIndicator(lower, audioDescriptionIndicators) {
+		return true
+	}
+	// "AD" alone is too common a substring to match loosely
+	for _, word := range titleWords(lower) {
+		if word == "ad" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSDH reports whether a subtitle stream is SDH (subtitles for the deaf and
+// hard of hearing), from its ffprobe disposition or title.
+func IsSDH(disposition map[string]int, title string) bool {
+	if disposition["hearing_impaired"] > 0 || disposition["captions"] > 0 {
+		return true
+	}
+	return hasIndicator(strings.ToLower(title), sdhIndicators)
+}
+
+func hasIndicator(lowerTitle string, indicators []string) bool {
+	words := titleWords(lowerTitle)
+	for _, indicator := range indicators {
+		if strings.Contains(indicator, " ") || strings.Contains(indicator, "-") {
+			if strings.Contains(lowerTitle, indicator) {
+				return true
+			}
+			continue
+		}
+		// Short indicators must be whole words ("cc" shouldn't match "accent")
+		for _, word := range words {
+			if word == indicator {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func titleWords(lowerTitle string) []string {
+	return strings.FieldsFunc(lowerTitle, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
@@ -0,0 +1,48 @@
+package accessibility
+
+import "testing"
+
+func TestIsAudioDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition map[string]int
+		title       string
+		want        bool
+	}{
+		{"visual impaired disposition", map[string]int{"visual_impaired": 1}, "English", true},
+		{"descriptive title", nil, "English - Descriptive Audio", true},
+		{"AD word", nil, "English AD 2.0", true},
+		{"AD substring", nil, "Headphones Mix", false},
+		{"commentary", map[string]int{"comment": 1}, "Director's Commentary", false},
+		{"plain", map[string]int{"default": 1}, "English 5.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAudioDescription(tt.disposition, tt.title); got != tt.want {
+				t.Errorf("IsAudioDescription(%v, %q) = %v, want %v", tt.disposition, tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSDH(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition map[string]int
+		title       string
+		want        bool
+	}{
+		{"hearing impaired disposition", map[string]int{"hearing_impaired": 1}, "English", true},
+		{"SDH title", nil, "English [SDH]", true},
+		{"CC word", nil, "English CC", true},
+		{"CC substring", nil, "Accented Dialogue", false},
+		{"forced", map[string]int{"forced": 1}, "English Forced", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSDH(tt.disposition, tt.title); got != tt.want {
+				t.Errorf("IsSDH(%v, %q) = %v, want %v", tt.disposition, tt.title, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// APIVersionHeader negotiates the API version: clients send the version
+	// they were built against, responses carry the version they were served with.
+	APIVersionHeader = "X-API-Version"
+
+	// LegacyAPIVersion serves requests that name no version, i.e. unprefixed
+	// /api paths from clients that predate versioning.
+	LegacyAPIVersion = 1
+	// LatestAPIVersion is the newest version the server speaks.
+	LatestAPIVersion = 1
+)
+
+type apiVersionContextKey struct{}
+
+// RequestAPIVersion returns the API version negotiated for r, or
+// LegacyAPIVersion when the request did not go through APIVersionMiddleware.
+func RequestAPIVersion(r *http.Request) int {
+	if version, ok := r.Context().Value(apiVersionContextKey{}).(int); ok {
+		return version
+	}
+	return LegacyAPIVersion
+}
+
+// WithAPIVersion returns r with the negotiated API version set.
+func WithAPIVersion(r *http.Request, version int) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), apiVersionContextKey{}, version))
+}
+
+// APIVersionMiddleware negotiates the API version of /api requests. The
+// version comes from an /api/v<N> prefix, which is stripped so versioned and
+// unversioned paths reach the same routes, or from the X-API-Version header.
+// Requests naming neither get LegacyAPIVersion, so installed clients keep the
+// response models they were built for. It wraps the whole router because
+// mux middleware only runs once a route has matched the unprefixed path.
+func APIVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" && !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, err := negotiateAPIVersion(r)
+		if err != nil {
+			CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"error":             err.Error(),
+					"supportedVersions": SupportedAPIVersions(),
+				})
+			})).ServeHTTP(w, r)
+			return
+		}
+
+		if path, ok := stripAPIVersion(r.URL.Path); ok {
+			r.URL.Path = path
+			r.URL.RawPath = ""
+		}
+		w.Header().Set(APIVersionHeader, strconv.Itoa(version))
+		exposeHeader(w.Header(), APIVersionHeader)
+		next.ServeHTTP(w, WithAPIVersion(r, version))
+	})
+}
+
+// SupportedAPIVersions lists the versions the server can serve, oldest first.
+func SupportedAPIVersions() []int {
+	versions := make([]int, 0, LatestAPIVersion-LegacyAPIVersion+1)
+	for v := LegacyAPIVersion; v <= LatestAPIVersion; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func negotiateAPIVersion(r *http.Request) (int, error) {
+	pathVersion, hasPathVersion := apiPathVersion(r.URL.Path)
+	headerVersion := 0
+	if raw := strings.TrimSpace(r.Header.Get(APIVersionHeader)); raw != "" {
+		parsed, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(raw), "v"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q", APIVersionHeader, raw)
+		}
+		headerVersion = parsed
+	}
+
+	version := LegacyAPIVersion
+	switch {
+	case hasPathVersion && headerVersion != 0 && headerVersion != pathVersion:
+		return 0, fmt.Errorf("path requests API version %d but %s is %d", pathVersion, APIVersionHeader, headerVersion)
+	case hasPathVersion:
+		version = pathVersion
+	case headerVersion != 0:
+		version = headerVersion
+	}
+	if version < LegacyAPIVersion || version > LatestAPIVersion {
+		return 0, fmt.Errorf("API version %d is not supported", version)
+	}
+	return version, nil
+}
+
+// apiPathVersion parses the N of an /api/v<N> path prefix.
+func apiPathVersion(path string) (int, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v")
+	if !ok {
+		return 0, false
+	}
+	digits, _, _ := strings.Cut(rest, "/")
+	version, err := strconv.Atoi(digits)
+	if err != nil || digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return 0, false
+	}
+	return version, true
+}
+
+// stripAPIVersion turns /api/v<N>/... into /api/...
+func stripAPIVersion(path string) (string, bool) {
+	if _, ok := apiPathVersion(path); !ok {
+		return path, false
+	}
+	rest := strings.TrimPrefix(path, "/api/v")
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return "/api" + rest[i:], true
+	}
+	return "/api", true
+}
+
+// VersionedHandler serves each API version with the handler registered for
+// the newest version not above it, so a route can change its response model
+// in a new version while older clients keep getting the previous one.
+type VersionedHandler map[int]http.Handler
+
+func (v VersionedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requested := RequestAPIVersion(r)
+	versions := make([]int, 0, len(v))
+	for version := range v {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	for _, version := range versions {
+		if version <= requested {
+			v[version].ServeHTTP(w, r)
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("not available in API version %d", requested), http.StatusNotFound)
+}
+
+// exposeHeader adds name to the headers browser clients may read.
+func exposeHeader(header http.Header, name string) {
+	if exposed := header.Get("Access-Control-Expose-Headers"); exposed == "" {
+		header.Set("Access-Control-Expose-Headers", name)
+	} else if !strings.Contains(exposed, name) {
+		header.Set("Access-Control-Expose-Headers", exposed+", "+name)
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func serveVersioned(t *testing.T, path, header string) (*httptest.ResponseRecorder, string, int) {
+	t.Helper()
+	var gotPath string
+	var gotVersion int
+	handler := APIVersionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = RequestAPIVersion(r)
+	}))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if header != "" {
+		req.Header.Set(APIVersionHeader, header)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, gotPath, gotVersion
+}
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	tests := []struct {
+		name, path, header string
+		wantPath           string
+		wantVersion        int
+	}{
+		{"legacy client", "/api/users/abc/watchlist", "", "/api/users/abc/watchlist", LegacyAPIVersion},
+		{"path prefix", "/api/v1/users/abc/watchlist", "", "/api/users/abc/watchlist", 1},
+		{"prefix only", "/api/v1", "", "/api", 1},
+		{"header", "/api/discover/trending", "1", "/api/discover/trending", 1},
+		{"header with v", "/api/discover/trending", "v1", "/api/discover/trending", 1},
+		{"matching path and header", "/api/v1/discover/trending", "1", "/api/discover/trending", 1},
+		{"not a version", "/api/video/hls/abc/stream.m3u8", "", "/api/video/hls/abc/stream.m3u8", LegacyAPIVersion},
+		{"outside the API", "/admin/v1", "", "/admin/v1", LegacyAPIVersion},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, path, version := serveVersioned(t, tc.path, tc.header)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if path != tc.wantPath || version != tc.wantVersion {
+				t.Fatalf("expected %s at version %d, got %s at version %d", tc.wantPath, tc.wantVersion, path, version)
+			}
+		})
+	}
+
+	rec, _, _ := serveVersioned(t, "/api/v1/settings", "")
+	if rec.Header().Get(APIVersionHeader) != strconv.Itoa(1) {
+		t.Errorf("expected the served version in the response, got %q", rec.Header().Get(APIVersionHeader))
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") != APIVersionHeader {
+		t.Errorf("expected the version header to be exposed, got %q", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+}
+
+func TestAPIVersionMiddlewareRejectsUnsupportedVersions(t *testing.T) {
+	for _, tc := range []struct{ path, header string }{
+		{"/api/v99/settings", ""},
+		{"/api/settings", "99"},
+		{"/api/settings", "latest"},
+		{"/api/v1/settings", "2"},
+	} {
+		rec, path, _ := serveVersioned(t, tc.path, tc.header)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s (header %q): expected 400, got %d", tc.path, tc.header, rec.Code)
+		}
+		if path != "" {
+			t.Errorf("%s (header %q): request should not reach the router", tc.path, tc.header)
+		}
+	}
+}
+
+func TestVersionedHandler(t *testing.T) {
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, body) })
+	}
+	handler := VersionedHandler{1: respond("v1"), 3: respond("v3")}
+
+	for requested, want := range map[int]string{1: "v1", 2: "v1", 3: "v3", 4: "v3"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, WithAPIVersion(httptest.NewRequest(http.MethodGet, "/api/title", nil), requested))
+		if rec.Body.String() != want {
+			t.Errorf("version %d: expected %s, got %s", requested, want, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	VersionedHandler{2: respond("v2")}.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/title", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a route added after the requested version, got %d", rec.Code)
+	}
+}
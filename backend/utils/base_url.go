@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// baseURL holds the configured external base URL, swapped when settings are saved.
+var baseURL atomic.Pointer[string]
+
+// SetBaseURL replaces the configured external base URL. An empty value means
+// absolute URLs are derived from each request.
+func SetBaseURL(raw string) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	baseURL.Store(&trimmed)
+}
+
+// ConfiguredBaseURL returns the configured external base URL, or "" if none is set.
+func ConfiguredBaseURL() string {
+	if v := baseURL.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// ExternalBaseURL returns the base URL clients should use to reach the server:
+// the configured base URL, otherwise one built from the request, honouring
+// X-Forwarded-Proto and X-Forwarded-Host from a reverse proxy.
+func ExternalBaseURL(r *http.Request) string {
+	if configured := ConfiguredBaseURL(); configured != "" {
+		return configured
+	}
+
+	scheme := "https"
+	if r.TLS == nil {
+		scheme = "http"
+	}
+	if fwdProto := r.Header.Get("X-Forwarded-Proto"); fwdProto != "" {
+		scheme = fwdProto
+	}
+	host := r.Host
+	if fwdHost := r.Header.Get("X-Forwarded-Host"); fwdHost != "" {
+		host = fwdHost
+	}
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
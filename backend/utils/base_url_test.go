@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExternalBaseURL_DerivedFromRequest(t *testing.T) {
+	SetBaseURL("")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/invitations", nil)
+	req.Host = "192.168.1.10:7777"
+	if got := ExternalBaseURL(req); got != "http://192.168.1.10:7777" {
+		t.Errorf("ExternalBaseURL() = %q, want request host", got)
+	}
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "media.example.com")
+	if got := ExternalBaseURL(req); got != "https://media.example.com" {
+		t.Errorf("ExternalBaseURL() = %q, want forwarded host", got)
+	}
+}
+
+func TestExternalBaseURL_Configured(t *testing.T) {
+	SetBaseURL(" https://media.example.com/strmr/ ")
+	t.Cleanup(func() { SetBaseURL("") })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/invitations", nil)
+	req.Header.Set("X-Forwarded-Host", "other.example.com")
+	if got := ExternalBaseURL(req); got != "https://media.example.com/strmr" {
+		t.Errorf("ExternalBaseURL() = %q, want configured base URL without trailing slash", got)
+	}
+	if got := ConfiguredBaseURL(); got != "https://media.example.com/strmr" {
+		t.Errorf("ConfiguredBaseURL() = %q", got)
+	}
+}
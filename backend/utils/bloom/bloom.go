@@ -0,0 +1,69 @@
+// Package bloom implements a fixed-size Bloom filter over strings that can be
+// added to while it is being read.
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+// Filter answers "possibly seen" or "definitely not seen" for strings.
+type Filter struct {
+	bits   []uint64
+	size   uint64 // Number of bits
+	hashes int
+}
+
+// New sizes a filter for n strings at false positive rate p.
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	size = (size + 63) / 64 * 64
+	hashes := int(math.Round(float64(size) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &Filter{bits: make([]uint64, size/64), size: size, hashes: hashes}
+}
+
+// Add records s.
+func (f *Filter) Add(s string) {
+	h1, h2 := hash(s)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.size
+		atomic.OrUint64(&f.bits[bit/64], 1<<(bit%64))
+	}
+}
+
+// Test reports whether s may have been added. False positives are possible,
+// false negatives are not.
+func (f *Filter) Test(s string) bool {
+	h1, h2 := hash(s)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.size
+		if atomic.LoadUint64(&f.bits[bit/64])&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hash derives the two hashes combined into each of the filter's positions.
+func hash(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	h1 := h.Sum64()
+	// splitmix64 finalizer for an independent second hash; odd so every
+	// position is reachable
+	h2 := h1 + 0x9e3779b97f4a7c15
+	h2 = (h2 ^ (h2 >> 30)) * 0xbf58476d1ce4e5b9
+	h2 = (h2 ^ (h2 >> 27)) * 0x94d049bb133111eb
+	h2 ^= h2 >> 31
+	return h1, h2 | 1
+}
@@ -0,0 +1,36 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilterHasNoFalseNegatives(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("title:%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.Test(fmt.Sprintf("title:%d", i)) {
+			t.Fatalf("expected title:%d to be found", i)
+		}
+	}
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(fmt.Sprintf("seen:%d", i))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Test(fmt.Sprintf("unseen:%d", i)) {
+			falsePositives++
+		}
+	}
+	// 1% expected; allow for variance
+	if falsePositives > 300 {
+		t.Errorf("expected about 100 false positives in 10000, got %d", falsePositives)
+	}
+}
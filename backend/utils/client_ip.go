@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultTrustedProxies are trusted when none are configured: a reverse
+// proxy on the same host, in a container network or on the LAN.
+var defaultTrustedProxies = []string{
+	"127.0.0.0/8", "::1/128",
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7",
+}
+
+// trustedProxies holds the networks whose forwarding headers are honoured,
+// swapped when settings are saved.
+var trustedProxies atomic.Pointer[[]*net.IPNet]
+
+// SetTrustedProxies replaces the reverse proxies whose X-Forwarded-For and
+// X-Real-IP headers are honoured. Entries are addresses or CIDR networks; an
+// empty list trusts loopback and private networks. Invalid entries are
+// logged and skipped.
+func SetTrustedProxies(entries []string) {
+	if len(entries) == 0 {
+		entries = defaultTrustedProxies
+	}
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("[server] ignoring invalid trusted proxy %q", entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies.Store(&networks)
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	networks := trustedProxies.Load()
+	if networks == nil {
+		SetTrustedProxies(nil)
+		networks = trustedProxies.Load()
+	}
+	for _, network := range *networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that sent the request.
+func ClientIP(r *http.Request) string {
+	ip, _ := ResolveClientIP(r)
+	return ip
+}
+
+// ResolveClientIP returns the client's address and whether it was taken from
+// forwarding headers. The headers are only honoured when the connection
+// comes from a trusted proxy, and X-Forwarded-For is read from the right,
+// skipping trusted proxies, so entries a client prepends itself are never
+// used.
+func ResolveClientIP(r *http.Request) (string, bool) {
+	peer := RemoteIP(r)
+	peerIP := net.ParseIP(peer)
+	if peerIP == nil || !isTrustedProxy(peerIP) {
+		return peer, false
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(stripPort(strings.TrimSpace(hops[i])))
+			if hop == nil {
+				break
+			}
+			if i == 0 || !isTrustedProxy(hop) {
+				return hop.String(), true
+			}
+		}
+	}
+	if xri := net.ParseIP(stripPort(strings.TrimSpace(r.Header.Get("X-Real-IP")))); xri != nil {
+		return xri.String(), true
+	}
+	return peer, false
+}
+
+// RemoteIP returns the address of the connection's peer, ignoring
+// forwarding headers.
+func RemoteIP(r *http.Request) string {
+	return stripPort(strings.TrimSpace(r.RemoteAddr))
+}
+
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	defer SetTrustedProxies(nil)
+	SetTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		want       string
+		forwarded  bool
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7", false},
+		{"untrusted peer can't spoof", "203.0.113.7:5000", "127.0.0.1", "203.0.113.7", false},
+		{"trusted proxy", "192.0.2.10:5000", "198.51.100.4", "198.51.100.4", true},
+		{"prepended entries are skipped", "192.0.2.10:5000", "127.0.0.1, 198.51.100.4", "198.51.100.4", true},
+		{"chained proxies", "10.1.1.1:5000", "198.51.100.4, 10.2.2.2", "198.51.100.4", true},
+		{"loopback is untrusted once proxies are configured", "127.0.0.1:5000", "198.51.100.4", "127.0.0.1", false},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		ip, forwarded := ResolveClientIP(req)
+		if ip != tc.want || forwarded != tc.forwarded {
+			t.Errorf("%s: got %s (forwarded %v), want %s (forwarded %v)", tc.name, ip, forwarded, tc.want, tc.forwarded)
+		}
+	}
+}
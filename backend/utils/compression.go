@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressMinBytes is the smallest response body worth compressing; anything
+// that fits in roughly one packet is sent as is.
+const compressMinBytes = 1024
+
+// compressExcludedPrefixes are API paths that stream media or events and must
+// reach the client unbuffered and with byte ranges intact.
+var compressExcludedPrefixes = []string{
+	"/api/video/",
+	"/api/live/stream",
+	"/api/live/hls/",
+	"/api/live/events/",
+	"/api/metadata/trailers/stream",
+	"/api/remote/",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	},
+}
+
+var zstdWriters = sync.Pool{
+	New: func() interface{} {
+		// A 1 MiB window stays well inside what browsers will decode (8 MiB).
+		w, _ := zstd.NewWriter(nil,
+			zstd.WithEncoderLevel(zstd.SpeedDefault),
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(1<<20),
+			zstd.WithLowerEncoderMem(true))
+		return w
+	},
+}
+
+// CompressionMiddleware compresses JSON API responses with zstd or gzip,
+// whichever the client's Accept-Encoding prefers. Responses that are not
+// JSON, are already encoded, or are smaller than compressMinBytes pass through
+// unchanged, as do streaming paths.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !compressiblePath(r.URL.Path) || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+func compressiblePath(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	for _, prefix := range compressExcludedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiateEncoding picks zstd or gzip from an Accept-Encoding header,
+// honouring q-values and preferring zstd on a tie. Returns "" for identity.
+func negotiateEncoding(header string) string {
+	var zstdQ, gzipQ, anyQ float64 = -1, -1, -1
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "zstd":
+			zstdQ = q
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if zstdQ < 0 && gzipQ < 0 {
+		// A bare wildcard gets gzip, the encoding every client can decode.
+		gzipQ = anyQ
+	}
+
+	switch {
+	case zstdQ > 0 && zstdQ >= gzipQ:
+		return "zstd"
+	case gzipQ > 0:
+		return "gzip"
+	}
+	return ""
+}
+
+// compressWriter holds back the status and the first compressMinBytes of the
+// body so it can decide whether the response is worth compressing.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	status      int
+	wroteHeader bool // WriteHeader was called by the handler
+	decided     bool // headers were sent downstream
+	buf         []byte
+	enc         io.WriteCloser
+	flush       func() error
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		// Informational responses don't carry the body
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	if !cw.eligible() {
+		cw.decide(false)
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) < compressMinBytes {
+		return len(p), nil
+	}
+	if err := cw.decide(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// eligible reports whether the response headers allow compression.
+func (cw *compressWriter) eligible() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < compressMinBytes {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// decide sends the headers downstream, starting the encoder when compress is
+// set, and then writes any buffered body.
+func (cw *compressWriter) decide(compress bool) error {
+	if cw.decided {
+		return nil
+	}
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			// The encoded bytes differ, so a strong validator no longer applies
+			header.Set("ETag", "W/"+etag)
+		}
+		cw.startEncoder()
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressWriter) startEncoder() {
+	switch cw.encoding {
+	case "zstd":
+		zw := zstdWriters.Get().(*zstd.Encoder)
+		zw.Reset(cw.ResponseWriter)
+		cw.enc, cw.flush = zw, zw.Flush
+	default:
+		gw := gzipWriters.Get().(*gzip.Writer)
+		gw.Reset(cw.ResponseWriter)
+		cw.enc, cw.flush = gw, gw.Flush
+	}
+}
+
+// Close finishes the response: a body still under compressMinBytes is sent
+// uncompressed, otherwise the encoder is flushed and returned to its pool.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if !cw.wroteHeader && len(cw.buf) == 0 {
+			// The handler wrote nothing; let net/http send its default response
+			return nil
+		}
+		return cw.decide(false)
+	}
+	if cw.enc == nil {
+		return nil
+	}
+
+	err := cw.enc.Close()
+	switch enc := cw.enc.(type) {
+	case *zstd.Encoder:
+		zstdWriters.Put(enc)
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		gzipWriters.Put(enc)
+	}
+	cw.enc, cw.flush = nil, nil
+	return err
+}
+
+// Flush sends whatever has been written so far, compressing it if the
+// response qualifies.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(cw.wroteHeader && len(cw.buf) > 0 && cw.eligible())
+	}
+	if cw.flush != nil {
+		cw.flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets connection upgrades through untouched.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("compression: response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
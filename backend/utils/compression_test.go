@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"novastream/config"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func serveCompressed(t *testing.T, path, acceptEncoding, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, body)
+	}))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func largeJSON() string {
+	return `{"episodes":[` + strings.Repeat(`{"name":"Episode","overview":"Lorem ipsum dolor sit amet"},`, 100) + `{}]}`
+}
+
+func TestCompressionMiddleware_Gzip(t *testing.T) {
+	body := largeJSON()
+	rr := serveCompressed(t, "/api/metadata/series/details", "gzip, deflate", "application/json; charset=utf-8", body)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+	if rr.Body.Len() >= len(body) {
+		t.Errorf("compressed body is %d bytes, original %d", rr.Body.Len(), len(body))
+	}
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	decoded, _ := io.ReadAll(zr)
+	if string(decoded) != body {
+		t.Fatal("decoded body does not match")
+	}
+}
+
+func TestCompressionMiddleware_Zstd(t *testing.T) {
+	body := largeJSON()
+	rr := serveCompressed(t, "/api/metadata/trending", "gzip;q=0.8, zstd", "application/json", body)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "zstd" {
+		t.Fatalf("Content-Encoding = %q, want zstd", got)
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatalf("zstd.NewReader: %v", err)
+	}
+	defer dec.Close()
+	decoded, err := dec.DecodeAll(rr.Body.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("DecodeAll: %v", err)
+	}
+	if !bytes.Equal(decoded, []byte(body)) {
+		t.Fatal("decoded body does not match")
+	}
+}
+
+func TestCompressionMiddleware_PassThrough(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		contentType    string
+		body           string
+	}{
+		{"no accept-encoding", "/api/metadata/trending", "", "application/json", largeJSON()},
+		{"small body", "/api/settings", "gzip", "application/json", `{"ok":true}`},
+		{"not json", "/api/metadata/image", "gzip", "image/jpeg", largeJSON()},
+		{"streaming path", "/api/video/stream", "gzip", "application/json", largeJSON()},
+		{"outside api", "/admin/settings", "gzip", "application/json", largeJSON()},
+		{"identity only", "/api/metadata/trending", "gzip;q=0, zstd;q=0", "application/json", largeJSON()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveCompressed(t, tt.path, tt.acceptEncoding, tt.contentType, tt.body)
+			if got := rr.Header().Get("Content-Encoding"); got != "" {
+				t.Fatalf("Content-Encoding = %q, want none", got)
+			}
+			if rr.Body.String() != tt.body {
+				t.Fatal("body was altered")
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"gzip":                   "gzip",
+		"br, zstd, gzip":         "zstd",
+		"zstd;q=0.5, gzip":       "gzip",
+		"zstd;q=0, gzip;q=0.1":   "gzip",
+		"*":                      "gzip",
+		"identity":               "",
+		"GZIP;Q=1.0":             "gzip",
+		"deflate, x-gzip;q=0.7 ": "gzip",
+	}
+	for header, want := range tests {
+		if got := negotiateEncoding(header); got != want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressionAndCORSMiddleware_KeepBothVaryValues(t *testing.T) {
+	SetCORSSettings(config.CORSSettings{AllowedOrigins: []string{"https://app.example"}})
+	t.Cleanup(func() { SetCORSSettings(config.CORSSettings{}) })
+
+	handler := CompressionMiddleware(CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, largeJSON())
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/api/metadata/series/details", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Origin", "https://app.example")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	vary := strings.Join(rr.Header().Values("Vary"), ", ")
+	if !strings.Contains(vary, "Accept-Encoding") || !strings.Contains(vary, "Origin") {
+		t.Errorf("Vary = %q, want both Accept-Encoding and Origin", vary)
+	}
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"novastream/config"
+)
+
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders = "*"
+)
+
+// corsSettings holds the active CORS policy. It is swapped atomically when
+// settings are saved so every request sees a consistent policy.
+var corsSettings atomic.Pointer[config.CORSSettings]
+
+// SetCORSSettings replaces the CORS policy used by the router middleware.
+func SetCORSSettings(s config.CORSSettings) {
+	corsSettings.Store(&s)
+}
+
+// corsRule is the effective policy for a single request path.
+type corsRule struct {
+	allowedOrigins   []string
+	allowCredentials bool
+}
+
+func currentCORSRule(path string) corsRule {
+	s := corsSettings.Load()
+	if s == nil {
+		return corsRule{}
+	}
+
+	rule := corsRule{allowedOrigins: s.AllowedOrigins, allowCredentials: s.AllowCredentials}
+	longest := -1
+	for _, override := range s.RouteOverrides {
+		prefix := strings.TrimSpace(override.PathPrefix)
+		if prefix == "" || !strings.HasPrefix(path, prefix) || len(prefix) <= longest {
+			continue
+		}
+		longest = len(prefix)
+		rule = corsRule{allowedOrigins: override.AllowedOrigins, allowCredentials: override.AllowCredentials}
+	}
+	return rule
+}
+
+func (c corsRule) allowsAnyOrigin() bool {
+	if len(c.allowedOrigins) == 0 {
+		return true
+	}
+	for _, origin := range c.allowedOrigins {
+		if strings.TrimSpace(origin) == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c corsRule) allowsOrigin(origin string) bool {
+	if c.allowsAnyOrigin() {
+		return true
+	}
+	origin = normalizeOrigin(origin)
+	for _, allowed := range c.allowedOrigins {
+		if matchOrigin(normalizeOrigin(allowed), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
+
+// matchOrigin compares an allowed origin against a request origin. A "*."
+// host prefix matches any subdomain, e.g. "https://*.example.com" matches
+// "https://app.example.com" but not "https://example.com".
+func matchOrigin(allowed, origin string) bool {
+	if allowed == origin {
+		return true
+	}
+	scheme, host, ok := strings.Cut(allowed, "://*.")
+	if !ok {
+		return false
+	}
+	prefix := scheme + "://"
+	if !strings.HasPrefix(origin, prefix) {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimPrefix(origin, prefix), "."+host)
+}
+
+// applyCORSHeaders writes the CORS response headers for r and reports whether
+// the request origin is allowed. Requests without an Origin header are not
+// cross-origin browser requests and are always allowed.
+func applyCORSHeaders(w http.ResponseWriter, r *http.Request) bool {
+	rule := currentCORSRule(r.URL.Path)
+	origin := r.Header.Get("Origin")
+	header := w.Header()
+
+	// Credentials are only ever granted to an explicit allow-list. Echoing
+	// every origin back with credentials would let any site make
+	// authenticated requests, so wildcard policies never send them.
+	credentialed := rule.allowCredentials && !rule.allowsAnyOrigin()
+
+	switch {
+	case rule.allowsAnyOrigin():
+		header.Set("Access-Control-Allow-Origin", "*")
+	case origin == "":
+		return true
+	case rule.allowsOrigin(origin):
+		// Credentialed responses must name the origin explicitly.
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+		if credentialed {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	default:
+		header.Add("Vary", "Origin")
+		return false
+	}
+
+	header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+	if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" && credentialed {
+		// The "*" wildcard is treated literally on credentialed requests.
+		header.Set("Access-Control-Allow-Headers", requested)
+	} else {
+		header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+	}
+	return true
+}
+
+// CORSMiddleware applies the configured CORS policy and answers preflight
+// requests. Preflights from origins that are not allowed are rejected.
+func CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := applyCORSHeaders(w, r)
+
+		// Handle preflight requests
+		if r.Method == http.MethodOptions {
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
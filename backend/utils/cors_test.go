@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/config"
+)
+
+func serveCORS(t *testing.T, method, path, origin string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCORSMiddleware_DefaultAllowsAnyOrigin(t *testing.T) {
+	SetCORSSettings(config.CORSSettings{AllowedOrigins: []string{"*"}})
+
+	rr := serveCORS(t, http.MethodGet, "/api/settings", "https://evil.example")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q, want empty", got)
+	}
+}
+
+func TestCORSMiddleware_WildcardNeverSendsCredentials(t *testing.T) {
+	SetCORSSettings(config.CORSSettings{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	rr := serveCORS(t, http.MethodGet, "/api/settings", "https://evil.example")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Allow-Origin = %q, want *", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Allow-Credentials = %q, want empty", got)
+	}
+}
+
+func TestCORSMiddleware_AllowList(t *testing.T) {
+	SetCORSSettings(config.CORSSettings{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.tv.example.com"},
+		AllowCredentials: true,
+	})
+	t.Cleanup(func() { SetCORSSettings(config.CORSSettings{}) })
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		wantOrigin string
+		wantStatus int
+	}{
+		{"exact match", http.MethodGet, "https://app.example.com", "https://app.example.com", http.StatusNoContent},
+		{"subdomain wildcard", http.MethodGet, "https://living.tv.example.com", "https://living.tv.example.com", http.StatusNoContent},
+		{"wildcard excludes apex", http.MethodGet, "https://tv.example.com", "", http.StatusNoContent},
+		{"other origin", http.MethodGet, "https://evil.example", "", http.StatusNoContent},
+		{"no origin", http.MethodGet, "", "", http.StatusNoContent},
+		{"preflight allowed", http.MethodOptions, "https://app.example.com", "https://app.example.com", http.StatusOK},
+		{"preflight rejected", http.MethodOptions, "https://evil.example", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveCORS(t, tt.method, "/api/settings", tt.origin)
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			wantCreds := ""
+			if tt.wantOrigin != "" {
+				wantCreds = "true"
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != wantCreds {
+				t.Errorf("Allow-Credentials = %q, want %q", got, wantCreds)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_RouteOverride(t *testing.T) {
+	SetCORSSettings(config.CORSSettings{
+		AllowedOrigins: []string{"https://app.example.com"},
+		RouteOverrides: []config.CORSRouteOverride{
+			{PathPrefix: "/api/video/", AllowedOrigins: []string{"*"}},
+			{PathPrefix: "/api/video/hls/", AllowedOrigins: []string{"https://player.example.com"}},
+		},
+	})
+	t.Cleanup(func() { SetCORSSettings(config.CORSSettings{}) })
+
+	if got := serveCORS(t, http.MethodGet, "/api/settings", "https://player.example.com").Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("global policy: Allow-Origin = %q, want empty", got)
+	}
+	if got := serveCORS(t, http.MethodGet, "/api/video/stream", "https://player.example.com").Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("video override: Allow-Origin = %q, want *", got)
+	}
+	if got := serveCORS(t, http.MethodGet, "/api/video/hls/abc/stream.m3u8", "https://app.example.com").Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("longest prefix should win: Allow-Origin = %q, want empty", got)
+	}
+	if got := serveCORS(t, http.MethodGet, "/api/video/hls/abc/stream.m3u8", "https://player.example.com").Header().Get("Access-Control-Allow-Origin"); got != "https://player.example.com" {
+		t.Errorf("hls override: Allow-Origin = %q, want player origin", got)
+	}
+}
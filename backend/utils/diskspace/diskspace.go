@@ -0,0 +1,158 @@
+// Package diskspace admits new NZB downloads and HLS sessions only when the
+// disk they write to has room for them plus a configured reserve. When a disk
+// is short, registered evictors (idle HLS sessions, cached stream files) are
+// asked to free space before the request is refused.
+package diskspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"novastream/config"
+)
+
+// ErrorCode is reported to clients when a request is refused for lack of space.
+const ErrorCode = "disk_space_insufficient"
+
+// ErrInsufficientSpace is matched by every *InsufficientSpaceError.
+var ErrInsufficientSpace = errors.New("insufficient disk space")
+
+// Purpose identifies what the space is needed for, which decides the reserve.
+type Purpose string
+
+const (
+	PurposeNZB Purpose = "nzb"
+	PurposeHLS Purpose = "hls"
+)
+
+// InsufficientSpaceError describes a refused admission.
+type InsufficientSpaceError struct {
+	Purpose       Purpose
+	Path          string
+	FreeBytes     int64
+	RequiredBytes int64 // Expected size plus the reserve
+}
+
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("%s: %s has %d MB free, %d MB required for %s",
+		ErrInsufficientSpace, e.Path, e.FreeBytes>>20, e.RequiredBytes>>20, e.Purpose)
+}
+
+func (e *InsufficientSpaceError) Unwrap() error {
+	return ErrInsufficientSpace
+}
+
+// Evictor frees disk space, returning roughly how many bytes it released.
+// needBytes is how much is still missing; evictors may stop once it is freed.
+type Evictor func(ctx context.Context, needBytes int64) int64
+
+var (
+	settings atomic.Pointer[config.DiskSpaceSettings]
+
+	evictorsMu sync.Mutex
+	evictors   = map[string]Evictor{}
+
+	// freeSpace is swapped in tests.
+	freeSpace = availableBytes
+)
+
+// SetSettings replaces the reserve thresholds used by later checks.
+func SetSettings(s config.DiskSpaceSettings) {
+	settings.Store(&s)
+}
+
+// RegisterEvictor adds or replaces a named evictor. Evictors run in name order.
+func RegisterEvictor(name string, fn Evictor) {
+	evictorsMu.Lock()
+	defer evictorsMu.Unlock()
+	if fn == nil {
+		delete(evictors, name)
+		return
+	}
+	evictors[name] = fn
+}
+
+func reserveBytes(purpose Purpose) int64 {
+	s := settings.Load()
+	if s == nil {
+		return 0
+	}
+	switch purpose {
+	case PurposeNZB:
+		return int64(s.NZBReserveMB) << 20
+	case PurposeHLS:
+		return int64(s.HLSReserveMB) << 20
+	}
+	return 0
+}
+
+// Check returns an *InsufficientSpaceError when dir's disk can't hold
+// expectedBytes while keeping the reserve for purpose free. Evictors are
+// tried once before giving up. A zero reserve disables the check, and disks
+// whose free space can't be determined are admitted.
+func Check(ctx context.Context, purpose Purpose, dir string, expectedBytes int64) error {
+	reserve := reserveBytes(purpose)
+	if reserve <= 0 {
+		return nil
+	}
+	if expectedBytes < 0 {
+		expectedBytes = 0
+	}
+	required := reserve + expectedBytes
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		log.Printf("[diskspace] unable to read free space for %s: %v", dir, err)
+		return nil
+	}
+	if free >= required {
+		return nil
+	}
+
+	log.Printf("[diskspace] %s: %s has %d MB free, %d MB required; evicting",
+		purpose, dir, free>>20, required>>20)
+	for _, name := range evictorNames() {
+		if ctx.Err() != nil {
+			break
+		}
+		fn := lookupEvictor(name)
+		if fn == nil {
+			continue
+		}
+		if freed := fn(ctx, required-free); freed > 0 {
+			log.Printf("[diskspace] evictor %s freed %d MB", name, freed>>20)
+		}
+		if free, err = freeSpace(dir); err != nil || free >= required {
+			return nil
+		}
+	}
+
+	return &InsufficientSpaceError{
+		Purpose:       purpose,
+		Path:          dir,
+		FreeBytes:     free,
+		RequiredBytes: required,
+	}
+}
+
+func evictorNames() []string {
+	evictorsMu.Lock()
+	defer evictorsMu.Unlock()
+	names := make([]string, 0, len(evictors))
+	for name := range evictors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupEvictor(name string) Evictor {
+	evictorsMu.Lock()
+	defer evictorsMu.Unlock()
+	return evictors[name]
+}
@@ -0,0 +1,85 @@
+package diskspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"novastream/config"
+)
+
+func stubFreeSpace(t *testing.T, free *int64) {
+	t.Helper()
+	orig := freeSpace
+	freeSpace = func(string) (int64, error) { return *free, nil }
+	t.Cleanup(func() {
+		freeSpace = orig
+		SetSettings(config.DiskSpaceSettings{})
+	})
+}
+
+func TestCheckDisabledWithoutReserve(t *testing.T) {
+	free := int64(0)
+	stubFreeSpace(t, &free)
+	SetSettings(config.DiskSpaceSettings{})
+
+	if err := Check(context.Background(), PurposeNZB, "/cache", 1<<30); err != nil {
+		t.Fatalf("expected no error with zero reserve, got %v", err)
+	}
+}
+
+func TestCheckRefusesBelowReserve(t *testing.T) {
+	free := int64(1500 << 20)
+	stubFreeSpace(t, &free)
+	SetSettings(config.DiskSpaceSettings{NZBReserveMB: 1024, HLSReserveMB: 2048})
+
+	if err := Check(context.Background(), PurposeNZB, "/cache", 100<<20); err != nil {
+		t.Fatalf("expected nzb admission, got %v", err)
+	}
+
+	err := Check(context.Background(), PurposeHLS, "/tmp/hls", 0)
+	if !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+	var spaceErr *InsufficientSpaceError
+	if !errors.As(err, &spaceErr) {
+		t.Fatalf("expected *InsufficientSpaceError, got %T", err)
+	}
+	if spaceErr.RequiredBytes != 2048<<20 || spaceErr.FreeBytes != free {
+		t.Fatalf("unexpected error details: %+v", spaceErr)
+	}
+}
+
+func TestCheckRunsEvictors(t *testing.T) {
+	free := int64(512 << 20)
+	stubFreeSpace(t, &free)
+	SetSettings(config.DiskSpaceSettings{NZBReserveMB: 1024})
+
+	var calls []string
+	RegisterEvictor("a", func(_ context.Context, need int64) int64 {
+		calls = append(calls, "a")
+		free += 256 << 20
+		return 256 << 20
+	})
+	RegisterEvictor("b", func(_ context.Context, need int64) int64 {
+		calls = append(calls, "b")
+		free += need
+		return need
+	})
+	RegisterEvictor("c", func(context.Context, int64) int64 {
+		calls = append(calls, "c")
+		return 0
+	})
+	t.Cleanup(func() {
+		RegisterEvictor("a", nil)
+		RegisterEvictor("b", nil)
+		RegisterEvictor("c", nil)
+	})
+
+	if err := Check(context.Background(), PurposeNZB, "/cache", 0); err != nil {
+		t.Fatalf("expected eviction to make room, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "a" || calls[1] != "b" {
+		t.Fatalf("expected evictors a then b, got %v", calls)
+	}
+}
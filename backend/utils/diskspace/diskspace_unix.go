@@ -0,0 +1,32 @@
+//go:build unix
+
+package diskspace
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// availableBytes returns the space available to unprivileged users on dir's
+// disk. Missing directories are resolved to their nearest existing parent,
+// since callers check before creating them.
+func availableBytes(dir string) (int64, error) {
+	path := filepath.Clean(dir)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
@@ -0,0 +1,76 @@
+// Package inflight coalesces identical concurrent calls into one run whose
+// result every caller shares.
+package inflight
+
+import (
+	"context"
+	"sync"
+)
+
+// Group deduplicates calls by key. The zero value is ready to use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	val     T
+	err     error
+}
+
+// Do runs fn for key unless a run for the same key is already in flight, in
+// which case it waits for that run instead. shared reports whether the result
+// came from another caller's run.
+//
+// The run gets its own context that carries ctx's values but is only
+// cancelled once every waiting caller has gone away, so one client giving up
+// doesn't fail the search for the others.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(context.Context) (T, error)) (val T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c, shared := g.calls[key]
+	if shared {
+		c.waiters++
+	} else {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[T]{done: make(chan struct{}), cancel: cancel, waiters: 1}
+		g.calls[key] = c
+		go g.run(runCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, shared, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			// Later callers start a fresh run rather than joining a cancelled one
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, shared, ctx.Err()
+	}
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(context.Context) (T, error)) {
+	c.val, c.err = fn(ctx)
+	c.cancel()
+
+	g.mu.Lock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(c.done)
+}
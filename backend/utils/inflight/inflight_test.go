@@ -0,0 +1,144 @@
+package inflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoCoalescesConcurrentCalls(t *testing.T) {
+	var g Group[int]
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	fn := func(ctx context.Context) (int, error) {
+		runs.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, shared, err := g.Do(context.Background(), "matrix", fn)
+			if err != nil || val != 42 {
+				t.Errorf("Do() = %d, %v, want 42", val, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+
+	// Let every caller join before the run finishes
+	deadline := time.Now().Add(time.Second)
+	for {
+		g.mu.Lock()
+		waiters := 0
+		if c := g.calls["matrix"]; c != nil {
+			waiters = c.waiters
+		}
+		g.mu.Unlock()
+		if waiters == 5 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("fn ran %d times, want 1", got)
+	}
+	if got := sharedCount.Load(); got != 4 {
+		t.Errorf("%d callers shared the result, want 4", got)
+	}
+
+	// Once finished, the next call runs again
+	if _, shared, _ := g.Do(context.Background(), "matrix", func(context.Context) (int, error) { return 1, nil }); shared {
+		t.Error("call after completion joined a finished run")
+	}
+}
+
+func TestDoKeepsRunningWhileAnyCallerWaits(t *testing.T) {
+	var g Group[string]
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runErr atomic.Value
+
+	fn := func(ctx context.Context) (string, error) {
+		close(started)
+		select {
+		case <-release:
+			return "ok", nil
+		case <-ctx.Done():
+			runErr.Store(ctx.Err())
+			return "", ctx.Err()
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(firstCtx, "key", fn)
+		firstDone <- err
+	}()
+	<-started
+
+	secondDone := make(chan string, 1)
+	go func() {
+		val, _, _ := g.Do(context.Background(), "key", fn)
+		secondDone <- val
+	}()
+	for {
+		g.mu.Lock()
+		waiters := g.calls["key"].waiters
+		g.mu.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelFirst()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if val := <-secondDone; val != "ok" {
+		t.Fatalf("second caller got %q, want the run's result", val)
+	}
+	if err := runErr.Load(); err != nil {
+		t.Fatalf("run was cancelled while a caller was still waiting: %v", err)
+	}
+}
+
+func TestDoCancelsRunWhenEveryCallerLeaves(t *testing.T) {
+	var g Group[int]
+	cancelled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, _, err := g.Do(ctx, "key", func(runCtx context.Context) (int, error) {
+		<-runCtx.Done()
+		close(cancelled)
+		return 0, runCtx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("run was not cancelled after its only caller left")
+	}
+}
@@ -0,0 +1,172 @@
+// Package mediatools detects the versions and compiled-in capabilities of the
+// configured ffmpeg and ffprobe binaries (and MP4Box, when installed) so
+// features they lack are turned off up front instead of failing mid-transcode.
+package mediatools
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"novastream/utils/sandbox"
+)
+
+// probeTimeout bounds each version or capability query.
+const probeTimeout = 10 * time.Second
+
+// Capabilities are the detected tool versions and the features they support.
+type Capabilities struct {
+	// Detected is false until detection has run; gates then assume support.
+	Detected bool `json:"detected"`
+
+	FFmpegVersion  string `json:"ffmpegVersion,omitempty"` // "" when ffmpeg could not be run
+	FFprobeVersion string `json:"ffprobeVersion,omitempty"`
+	MP4BoxVersion  string `json:"mp4boxVersion,omitempty"` // "" when MP4Box is not installed
+
+	HEVCMetadataBSF   bool `json:"hevcMetadataBsf"`   // hevc_metadata bitstream filter, for HDR10 color signaling
+	LibFDKAAC         bool `json:"libfdkAac"`         // Fraunhofer FDK AAC encoder
+	DolbyVisionMuxing bool `json:"dolbyVisionMuxing"` // mp4 muxer writes dvcC/dvvC boxes for dvh1/dvhe tracks
+}
+
+// runner runs a tool and returns its combined output. Replaced in tests.
+type runner func(ctx context.Context, tool sandbox.Tool, name string, args ...string) ([]byte, error)
+
+func runTool(ctx context.Context, tool sandbox.Tool, name string, args ...string) ([]byte, error) {
+	return sandbox.CommandContext(ctx, tool, name, args...).CombinedOutput()
+}
+
+var current atomic.Pointer[Capabilities]
+
+// Set replaces the capabilities used by the feature gates.
+func Set(c Capabilities) {
+	current.Store(&c)
+}
+
+// Current returns the detected capabilities, or the zero value (Detected
+// false) before detection has run.
+func Current() Capabilities {
+	if c := current.Load(); c != nil {
+		return *c
+	}
+	return Capabilities{}
+}
+
+// HEVCMetadata reports whether the hevc_metadata bitstream filter can be used.
+func HEVCMetadata() bool {
+	c := Current()
+	return !c.Detected || c.HEVCMetadataBSF
+}
+
+// DolbyVisionMuxing reports whether ffmpeg can write Dolby Vision tracks.
+func DolbyVisionMuxing() bool {
+	c := Current()
+	return !c.Detected || c.DolbyVisionMuxing
+}
+
+// AACEncoder returns the AAC encoder to transcode with: libfdk_aac when it is
+// compiled in, otherwise ffmpeg's native encoder.
+func AACEncoder() string {
+	if c := Current(); c.Detected && c.LibFDKAAC {
+		return "libfdk_aac"
+	}
+	return "aac"
+}
+
+// Detect queries the ffmpeg and ffprobe binaries at the given paths, and
+// MP4Box on the PATH, for their versions and capabilities.
+func Detect(ctx context.Context, ffmpegPath, ffprobePath string) Capabilities {
+	return detect(ctx, runTool, ffmpegPath, ffprobePath)
+}
+
+func detect(ctx context.Context, run runner, ffmpegPath, ffprobePath string) Capabilities {
+	query := func(tool sandbox.Tool, name string, args ...string) string {
+		qctx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+		out, err := run(qctx, tool, name, args...)
+		if err != nil && len(out) == 0 {
+			return ""
+		}
+		return string(out)
+	}
+
+	caps := Capabilities{Detected: true}
+	if ffmpegPath = strings.TrimSpace(ffmpegPath); ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	if ffprobePath = strings.TrimSpace(ffprobePath); ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+
+	caps.FFmpegVersion = parseVersion(query(sandbox.ToolFFmpeg, ffmpegPath, "-version"), "ffmpeg version ")
+	caps.FFprobeVersion = parseVersion(query(sandbox.ToolFFprobe, ffprobePath, "-version"), "ffprobe version ")
+	if caps.FFmpegVersion != "" {
+		caps.HEVCMetadataBSF = hasListEntry(query(sandbox.ToolFFmpeg, ffmpegPath, "-hide_banner", "-bsfs"), "hevc_metadata", 0)
+		caps.LibFDKAAC = hasListEntry(query(sandbox.ToolFFmpeg, ffmpegPath, "-hide_banner", "-encoders"), "libfdk_aac", 1)
+		caps.DolbyVisionMuxing = supportsDolbyVisionMuxing(caps.FFmpegVersion)
+	}
+	if path, err := exec.LookPath("MP4Box"); err == nil {
+		caps.MP4BoxVersion = parseVersion(query(sandbox.ToolMP4Box, path, "-version"), "GPAC version ")
+	}
+
+	log.Printf("[mediatools] ffmpeg=%q ffprobe=%q MP4Box=%q hevc_metadata=%v libfdk_aac=%v dolbyVisionMuxing=%v",
+		caps.FFmpegVersion, caps.FFprobeVersion, caps.MP4BoxVersion, caps.HEVCMetadataBSF, caps.LibFDKAAC, caps.DolbyVisionMuxing)
+	return caps
+}
+
+// parseVersion returns the word following marker in a tool's version output,
+// e.g. "6.1.1-3ubuntu5" from "ffmpeg version 6.1.1-3ubuntu5 Copyright ...".
+func parseVersion(output, marker string) string {
+	idx := strings.Index(output, marker)
+	if idx < 0 {
+		return ""
+	}
+	fields := strings.Fields(output[idx+len(marker):])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// hasListEntry reports whether a tool listing (-bsfs, -encoders) has a line
+// whose field at index column is name.
+func hasListEntry(output, name string, column int) bool {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > column && fields[column] == name {
+			return true
+		}
+	}
+	return false
+}
+
+var releaseVersion = regexp.MustCompile(`^n?(\d+)\.(\d+)`)
+
+// ParseMajorMinor extracts the release number from an ffmpeg version string.
+// ok is false for git snapshots ("N-113088-g...") and unrecognised builds.
+func ParseMajorMinor(version string) (major, minor int, ok bool) {
+	m := releaseVersion.FindStringSubmatch(version)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+// supportsDolbyVisionMuxing reports whether an ffmpeg release writes the
+// dvcC/dvvC configuration boxes that dvh1/dvhe tracks need, which requires
+// FFmpeg 5.1 or newer. Git snapshots are assumed to be recent.
+func supportsDolbyVisionMuxing(version string) bool {
+	major, minor, ok := ParseMajorMinor(version)
+	if !ok {
+		return version != ""
+	}
+	return major > 5 || (major == 5 && minor >= 1)
+}
@@ -0,0 +1,74 @@
+package mediatools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"novastream/utils/sandbox"
+)
+
+func fakeRunner(outputs map[string]string) runner {
+	return func(ctx context.Context, tool sandbox.Tool, name string, args ...string) ([]byte, error) {
+		out, ok := outputs[name+" "+strings.Join(args, " ")]
+		if !ok {
+			return nil, errors.New("executable file not found")
+		}
+		return []byte(out), nil
+	}
+}
+
+func TestDetect(t *testing.T) {
+	caps := detect(context.Background(), fakeRunner(map[string]string{
+		"/opt/ffmpeg -version":               "ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023 the FFmpeg developers\nconfiguration: --enable-gpl\n",
+		"/opt/ffmpeg -hide_banner -bsfs":     "Bitstream filters:\naac_adtstoasc\nhevc_metadata\nhevc_mp4toannexb\n",
+		"/opt/ffmpeg -hide_banner -encoders": "Encoders:\n V..... = Video\n ------\n A....D aac                  AAC (Advanced Audio Coding)\n A....D libfdk_aac           Fraunhofer FDK AAC (codec aac)\n",
+		"ffprobe -version":                   "ffprobe version 6.1.1-3ubuntu5 Copyright (c) 2007-2023 the FFmpeg developers\n",
+	}), "/opt/ffmpeg", "")
+
+	if !caps.Detected || caps.FFmpegVersion != "6.1.1-3ubuntu5" || caps.FFprobeVersion != "6.1.1-3ubuntu5" {
+		t.Fatalf("unexpected versions: %+v", caps)
+	}
+	if !caps.HEVCMetadataBSF || !caps.LibFDKAAC || !caps.DolbyVisionMuxing {
+		t.Fatalf("expected all capabilities, got %+v", caps)
+	}
+}
+
+func TestDetectMissingFFmpeg(t *testing.T) {
+	caps := detect(context.Background(), fakeRunner(nil), "ffmpeg", "ffprobe")
+	if !caps.Detected || caps.FFmpegVersion != "" || caps.HEVCMetadataBSF || caps.DolbyVisionMuxing {
+		t.Fatalf("expected nothing detected for a missing ffmpeg, got %+v", caps)
+	}
+
+	Set(caps)
+	defer Set(Capabilities{})
+	if HEVCMetadata() || DolbyVisionMuxing() || AACEncoder() != "aac" {
+		t.Fatal("expected gates to close when capabilities are missing")
+	}
+}
+
+func TestGatesAssumeSupportBeforeDetection(t *testing.T) {
+	Set(Capabilities{})
+	if !HEVCMetadata() || !DolbyVisionMuxing() || AACEncoder() != "aac" {
+		t.Fatal("expected gates to stay open before detection")
+	}
+}
+
+func TestSupportsDolbyVisionMuxing(t *testing.T) {
+	tests := map[string]bool{
+		"4.4.2-0ubuntu0.22.04.1":   false,
+		"5.0.3":                    false,
+		"5.1.4":                    true,
+		"n7.0.2":                   true,
+		"N-113088-g1a2b3c4d5e":     true,
+		"7.1-static":               true,
+		"":                         false,
+		"git-2024-01-05-1234abcd9": true,
+	}
+	for version, want := range tests {
+		if got := supportsDolbyVisionMuxing(version); got != want {
+			t.Errorf("supportsDolbyVisionMuxing(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+// Package releasename parses scene/P2P release names into the attributes used
+// for scoring: resolution, source, codec, HDR formats, season/episode numbers,
+// release group, the REPACK/PROPER/HYBRID style flags and the title ahead of
+// them.
+//
+// Parsing is token based. The name is lowercased, split on the usual
+// separators (space . _ [ ] ( ) { } ,) and every token is matched against the
+// grammar tables below. Hyphenated tokens are tried whole first ("web-dl",
+// "blu-ray") and then piece by piece ("x265-group").
+package releasename
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Release holds the attributes parsed from a release name.
+// Zero values mean the attribute was not present in the name.
+type Release struct {
+	Title      string   `json:"title,omitempty"`      // Words before the season/episode, year or resolution
+	Resolution int      `json:"resolution,omitempty"` // Vertical lines: 2160, 1080, 720, ...
+	Source     string   `json:"source,omitempty"`     // remux, bluray, web-dl, webrip, hdtv, dvd
+	Codec      string   `json:"codec,omitempty"`      // hevc, avc, av1, vc1, mpeg2, xvid
+	BitDepth   int      `json:"bitDepth,omitempty"`   // 8, 10 or 12
+	HDR        []string `json:"hdr,omitempty"`        // Canonical formats: DV, HDR10+, HDR10, HDR, HLG
+	DVProfile  int      `json:"dvProfile,omitempty"`  // Dolby Vision profile when stated (5, 7, 8)
+	Year       int      `json:"year,omitempty"`
+	Seasons    []int    `json:"seasons,omitempty"`
+	Episodes   []int    `json:"episodes,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	Repack     bool     `json:"repack,omitempty"`
+	Proper     bool     `json:"proper,omitempty"`
+	Hybrid     bool     `json:"hybrid,omitempty"`
+	Multi      bool     `json:"multi,omitempty"`    // Multiple audio languages
+	Complete   bool     `json:"complete,omitempty"` // Complete season/series pack
+}
+
+// HDR format names in the order they are reported
+const (
+	FormatDolbyVision = "DV"
+	FormatHDR10Plus   = "HDR10+"
+	FormatHDR10       = "HDR10"
+	FormatHDR         = "HDR"
+	FormatHLG         = "HLG"
+)
+
+var hdrOrder = map[string]int{
+	FormatDolbyVision: 0,
+	FormatHDR10Plus:   1,
+	FormatHDR10:       2,
+	FormatHDR:         3,
+	FormatHLG:         4,
+}
+
+var (
+	tokenSplitter = regexp.MustCompile(`[\s._\[\](){},]+`)
+
+	resolutionToken = regexp.MustCompile(`^(\d{3,4})[pi](?:\d{2})?$`)
+	dimensionsToken = regexp.MustCompile(`^\d{3,4}x(\d{3,4})$`)
+	yearToken       = regexp.MustCompile(`^(19\d{2}|20\d{2})$`)
+
+	// Patterns that span separators are matched against the normalized name
+	seasonEpisodePattern = regexp.MustCompile(`\bs(\d{1,2}) ?((?:-? ?e\d{1,4})+)\b`)
+	episodeListPattern   = regexp.MustCompile(`e(\d{1,4})`)
+	seasonRangePattern   = regexp.MustCompile(`\bs(\d{1,2}) ?- ?s?(\d{1,2})\b`)
+	seasonPattern        = regexp.MustCompile(`\bs(\d{1,2})\b`)
+	seasonEpisodeWords   = regexp.MustCompile(`\bseason ?(\d{1,2}) ?(?:episode|ep) ?(\d{1,4})\b`)
+	seasonWordPattern    = regexp.MustCompile(`\bseasons? ?(\d{1,2})(?: ?(?:-|to) ?(\d{1,2}))?\b`)
+	crossEpisodePattern  = regexp.MustCompile(`\b(\d{1,2})x(\d{2,3})\b`)
+	bitDepthPattern      = regexp.MustCompile(`\b(8|10|12) ?-?bits?\b`)
+	dvProfilePattern     = regexp.MustCompile(`\b(?:dv|dovi|dolby ?vision) ?-?p(?:rofile)? ?0?([4-9])\b`)
+	dolbyVisionPhrase    = regexp.MustCompile(`\bdolby ?vision\b`)
+	completeSeriesPhrase = regexp.MustCompile(`\bcomplete (?:series|season|collection)\b`)
+	leadingGroupPattern  = regexp.MustCompile(`^\[([^\]]+)\]`)
+	dottedCodecPattern   = regexp.MustCompile(`(?i)\b([hx])[. ](26[45])\b`)
+)
+
+var mediaExtensions = map[string]bool{
+	".mkv": true, ".mp4": true, ".avi": true, ".m4v": true, ".ts": true,
+	".m2ts": true, ".wmv": true, ".mov": true, ".webm": true, ".nzb": true,
+	".torrent": true,
+}
+
+var resolutionAliases = map[string]int{
+	"4k": 2160, "uhd": 2160, "2160": 2160,
+	"8k":  4320,
+	"fhd": 1080,
+	"sd":  480,
+}
+
+// When a name mentions several sources the highest sourceRank wins (Remux over BluRay)
+var sourceTokens = map[string]string{
+	"remux":  "remux",
+	"bluray": "bluray", "blu-ray": "bluray", "bdrip": "bluray", "brrip": "bluray", "bdremux": "remux", "bd": "bluray",
+	"web-dl": "web-dl", "webdl": "web-dl", "web": "web-dl",
+	"webrip": "webrip", "web-rip": "webrip",
+	"hdtv": "hdtv", "pdtv": "hdtv",
+	"dvdrip": "dvd", "dvd": "dvd", "dvd5": "dvd", "dvd9": "dvd",
+}
+
+var sourceRank = map[string]int{
+	"dvd":    1,
+	"hdtv":   2,
+	"webrip": 3,
+	"web-dl": 4,
+	"bluray": 5,
+	"remux":  6,
+}
+
+var codecTokens = map[string]string{
+	"x265": "hevc", "h265": "hevc", "hevc": "hevc",
+	"x264": "avc", "h264": "avc", "avc": "avc",
+	"av1":  "av1",
+	"vc-1": "vc1", "vc1": "vc1",
+	"mpeg2": "mpeg2", "mpeg-2": "mpeg2",
+	"xvid": "xvid", "divx": "xvid",
+}
+
+var hdrTokens = map[string]string{
+	"dv": FormatDolbyVision, "dovi": FormatDolbyVision, "dolbyvision": FormatDolbyVision,
+	"hdr10+": FormatHDR10Plus, "hdr10plus": FormatHDR10Plus, "hdr10p": FormatHDR10Plus,
+	"hdr10": FormatHDR10,
+	"hdr":   FormatHDR,
+	"hlg":   FormatHLG,
+}
+
+var flagTokens = map[string]func(*Release){
+	"repack":  func(r *Release) { r.Repack = true },
+	"repack2": func(r *Release) { r.Repack = true },
+	"rerip":   func(r *Release) { r.Repack = true },
+	"proper":  func(r *Release) { r.Proper = true },
+	"hybrid":  func(r *Release) { r.Hybrid = true },
+	"multi":   func(r *Release) { r.Multi = true },
+	// Multi-language subtitles only; the audio is not multi-language
+	"multi-subs": func(*Release) {},
+	"multisubs":  func(*Release) {},
+	"complete":   func(r *Release) { r.Complete = true },
+	"hi10p":      func(r *Release) { r.BitDepth = 10 },
+	"hi10":       func(r *Release) { r.BitDepth = 10 },
+}
+
+// Parse extracts release attributes from a release or file name.
+func Parse(name string) Release {
+	var r Release
+
+	name = strings.TrimSpace(name)
+	if mediaExtensions[strings.ToLower(path.Ext(name))] {
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	lower := strings.ToLower(name)
+
+	if m := leadingGroupPattern.FindStringSubmatch(name); m != nil {
+		r.Group = strings.TrimSpace(m[1])
+	}
+
+	// Keep "H.264" / "H 265" together before the separators are split out
+	normalized := dottedCodecPattern.ReplaceAllString(lower, "$1$2")
+	tokens := tokenSplitter.Split(normalized, -1)
+
+	for i, token := range tokens {
+		if token == "" {
+			continue
+		}
+		if r.matchToken(token, i) {
+			continue
+		}
+		if strings.Contains(token, "-") {
+			for _, part := range strings.Split(token, "-") {
+				if part != "" {
+					r.matchToken(part, i)
+				}
+			}
+		}
+	}
+
+	// Patterns spanning several tokens run on the name with separators collapsed to spaces
+	spaced := strings.Join(strings.Fields(tokenSplitter.ReplaceAllString(normalized, " ")), " ")
+	marker := r.parseEpisodes(spaced)
+	r.Title = parseTitle(name, spaced, marker, r.Year)
+
+	if m := bitDepthPattern.FindStringSubmatch(spaced); m != nil {
+		r.BitDepth, _ = strconv.Atoi(m[1])
+	}
+	if dolbyVisionPhrase.MatchString(spaced) {
+		r.addHDR(FormatDolbyVision)
+	}
+	if m := dvProfilePattern.FindStringSubmatch(spaced); m != nil {
+		r.DVProfile, _ = strconv.Atoi(m[1])
+		r.addHDR(FormatDolbyVision)
+	}
+	if completeSeriesPhrase.MatchString(spaced) {
+		r.Complete = true
+	}
+
+	if r.Group == "" {
+		r.Group = trailingGroup(name)
+	}
+
+	sort.Slice(r.HDR, func(i, j int) bool { return hdrOrder[r.HDR[i]] < hdrOrder[r.HDR[j]] })
+	return r
+}
+
+// matchToken applies a single token to the release. Returns true if the token
+// was recognised as a whole.
+func (r *Release) matchToken(token string, index int) bool {
+	if m := resolutionToken.FindStringSubmatch(token); m != nil {
+		r.setResolution(atoi(m[1]))
+		return true
+	}
+	if m := dimensionsToken.FindStringSubmatch(token); m != nil {
+		r.setResolution(atoi(m[1]))
+		return true
+	}
+	if res, ok := resolutionAliases[token]; ok {
+		r.setResolution(res)
+		return true
+	}
+	if source, ok := sourceTokens[token]; ok {
+		if sourceRank[source] > sourceRank[r.Source] {
+			r.Source = source
+		}
+		return true
+	}
+	if codec, ok := codecTokens[token]; ok {
+		if r.Codec == "" {
+			r.Codec = codec
+		}
+		return true
+	}
+	if format, ok := hdrTokens[token]; ok {
+		r.addHDR(format)
+		return true
+	}
+	if apply, ok := flagTokens[token]; ok {
+		apply(r)
+		return true
+	}
+	// A year as the very first token is part of the title (e.g. "2012.2009.1080p")
+	if m := yearToken.FindStringSubmatch(token); m != nil && index > 0 {
+		r.Year = atoi(m[1])
+		return true
+	}
+	return false
+}
+
+// parseEpisodes sets the seasons and episodes and returns where the first
+// marker naming them starts in spaced, or -1 when there is none.
+func (r *Release) parseEpisodes(spaced string) int {
+	seasons := make(map[int]bool)
+	episodes := make(map[int]bool)
+	first := -1
+	mark := func(m []int) {
+		if first < 0 || m[0] < first {
+			first = m[0]
+		}
+	}
+	group := func(m []int, n int) string {
+		if m[2*n] < 0 {
+			return ""
+		}
+		return spaced[m[2*n]:m[2*n+1]]
+	}
+
+	for _, m := range seasonEpisodePattern.FindAllStringSubmatchIndex(spaced, -1) {
+		mark(m)
+		seasons[atoi(group(m, 1))] = true
+		list := episodeListPattern.FindAllStringSubmatch(group(m, 2), -1)
+		// S01E01-E03 is a range; S01E01E02 lists each episode
+		if len(list) == 2 && strings.Contains(group(m, 2), "-") {
+			for ep := atoi(list[0][1]); ep <= atoi(list[1][1]); ep++ {
+				episodes[ep] = true
+			}
+			continue
+		}
+		for _, ep := range list {
+			episodes[atoi(ep[1])] = true
+		}
+	}
+
+	if len(seasons) == 0 {
+		for _, m := range crossEpisodePattern.FindAllStringSubmatchIndex(spaced, -1) {
+			mark(m)
+			seasons[atoi(group(m, 1))] = true
+			episodes[atoi(group(m, 2))] = true
+		}
+	}
+
+	if len(seasons) == 0 {
+		for _, m := range seasonEpisodeWords.FindAllStringSubmatchIndex(spaced, -1) {
+			mark(m)
+			seasons[atoi(group(m, 1))] = true
+			episodes[atoi(group(m, 2))] = true
+		}
+	}
+
+	if len(seasons) == 0 {
+		for _, m := range seasonRangePattern.FindAllStringSubmatchIndex(spaced, -1) {
+			mark(m)
+			addRange(seasons, atoi(group(m, 1)), atoi(group(m, 2)))
+		}
+		for _, m := range seasonWordPattern.FindAllStringSubmatchIndex(spaced, -1) {
+			mark(m)
+			if to := group(m, 2); to != "" {
+				addRange(seasons, atoi(group(m, 1)), atoi(to))
+			} else {
+				seasons[atoi(group(m, 1))] = true
+			}
+		}
+		if len(seasons) == 0 {
+			for _, m := range seasonPattern.FindAllStringSubmatchIndex(spaced, -1) {
+				mark(m)
+				seasons[atoi(group(m, 1))] = true
+			}
+		}
+	}
+
+	r.Seasons = sortedKeys(seasons)
+	r.Episodes = sortedKeys(episodes)
+	return first
+}
+
+// parseTitle returns the words of name ahead of the season/episode marker
+// (at byte offset marker of spaced, the lowercased name split into words),
+// the release year or the resolution, keeping their original case. Earlier
+// years belong to the title ("Blade.Runner.2049.2017"). A leading "[Group]"
+// tag is not part of the title.
+func parseTitle(name, spaced string, marker, year int) string {
+	lowerWords := strings.Fields(spaced)
+	words := strings.Fields(tokenSplitter.ReplaceAllString(dottedCodecPattern.ReplaceAllString(name, "$1$2"), " "))
+	if len(words) != len(lowerWords) {
+		words = lowerWords
+	}
+
+	end := len(lowerWords)
+	if marker >= 0 {
+		end = strings.Count(spaced[:marker], " ")
+	}
+	// A year or resolution as the very first word is part of the title ("2012", "1917")
+	yearWord := strconv.Itoa(year)
+	for i := 1; i < end; i++ {
+		if lowerWords[i] == yearWord || resolutionToken.MatchString(lowerWords[i]) {
+			end = i
+			break
+		}
+	}
+
+	start := 0
+	if m := leadingGroupPattern.FindStringSubmatch(name); m != nil {
+		start = len(strings.Fields(tokenSplitter.ReplaceAllString(m[1], " ")))
+	}
+	for end > start && strings.Trim(words[end-1], "-") == "" {
+		end--
+	}
+	if start >= end {
+		return ""
+	}
+	return strings.Join(words[start:end], " ")
+}
+
+func (r *Release) setResolution(res int) {
+	if res > r.Resolution {
+		r.Resolution = res
+	}
+}
+
+func (r *Release) addHDR(format string) {
+	for _, existing := range r.HDR {
+		if existing == format {
+			return
+		}
+	}
+	r.HDR = append(r.HDR, format)
+}
+
+// HasDolbyVision reports whether the release carries Dolby Vision
+func (r Release) HasDolbyVision() bool {
+	return r.hasFormat(FormatDolbyVision)
+}
+
+// HasHDR reports whether the release carries any HDR format, including Dolby Vision
+func (r Release) HasHDR() bool {
+	return len(r.HDR) > 0
+}
+
+func (r Release) hasFormat(format string) bool {
+	for _, f := range r.HDR {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolutionLabel returns the resolution as a label such as "2160p", or "" when unknown
+func (r Release) ResolutionLabel() string {
+	return ResolutionLabel(r.Resolution)
+}
+
+// ResolutionLabel formats a numeric resolution as "1080p", or "" for 0
+func ResolutionLabel(res int) string {
+	if res <= 0 {
+		return ""
+	}
+	return strconv.Itoa(res) + "p"
+}
+
+// ParseResolution parses a standalone resolution value such as "2160p", "4K",
+// "1080" or "720i" as reported in scraper attributes. Returns 0 when unknown.
+func ParseResolution(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 240 && n <= 4320 {
+		return n
+	}
+	return Parse(value).Resolution
+}
+
+// trailingGroup returns the scene group after the final hyphen ("...x264-SPARKS")
+func trailingGroup(name string) string {
+	var last string
+	for _, token := range tokenSplitter.Split(name, -1) {
+		if token != "" {
+			last = token
+		}
+	}
+	idx := strings.LastIndex(last, "-")
+	if idx < 0 || idx == len(last)-1 {
+		return ""
+	}
+
+	// "...WEB-DL" or "...x264-HEVC" end in grammar tokens, not a group
+	var probe Release
+	group := last[idx+1:]
+	if probe.matchToken(strings.ToLower(last), 1) || probe.matchToken(strings.ToLower(group), 1) {
+		return ""
+	}
+	if _, err := strconv.Atoi(group); err == nil {
+		return ""
+	}
+	return group
+}
+
+func addRange(set map[int]bool, from, to int) {
+	if to < from {
+		from, to = to, from
+	}
+	for n := from; n <= to; n++ {
+		set[n] = true
+	}
+}
+
+func sortedKeys(set map[int]bool) []int {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]int, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
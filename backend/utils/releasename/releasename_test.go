@@ -0,0 +1,126 @@
+package releasename
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/releases.golden from the current parser output")
+
+type goldenEntry struct {
+	Name   string  `json:"name"`
+	Parsed Release `json:"parsed"`
+}
+
+func TestParseGolden(t *testing.T) {
+	names := readReleaseNames(t, filepath.Join("testdata", "releases.txt"))
+
+	entries := make([]goldenEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, goldenEntry{Name: name, Parsed: Parse(name)})
+	}
+
+	got, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal parsed releases: %v", err)
+	}
+	got = append(got, '\n')
+
+	goldenPath := filepath.Join("testdata", "releases.golden")
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	var wantEntries []goldenEntry
+	if err := json.Unmarshal(want, &wantEntries); err != nil {
+		t.Fatalf("parse golden file: %v", err)
+	}
+	wantByName := make(map[string]Release, len(wantEntries))
+	for _, entry := range wantEntries {
+		wantByName[entry.Name] = entry.Parsed
+	}
+	for _, entry := range entries {
+		wantRelease, ok := wantByName[entry.Name]
+		if !ok {
+			t.Errorf("%q: missing from golden file", entry.Name)
+			continue
+		}
+		gotJSON, _ := json.Marshal(entry.Parsed)
+		wantJSON, _ := json.Marshal(wantRelease)
+		if !bytes.Equal(gotJSON, wantJSON) {
+			t.Errorf("%q:\n got  %s\n want %s", entry.Name, gotJSON, wantJSON)
+		}
+	}
+	if len(wantEntries) != len(entries) {
+		t.Errorf("golden file has %d entries, parsed %d", len(wantEntries), len(entries))
+	}
+}
+
+func readReleaseNames(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return names
+}
+
+func TestParseResolution(t *testing.T) {
+	tests := map[string]int{
+		"2160p": 2160,
+		"4K":    2160,
+		"UHD":   2160,
+		"1080":  1080,
+		"1080i": 1080,
+		"720p":  720,
+		"576p":  576,
+		"SD":    480,
+		"":      0,
+		"HD":    0,
+	}
+
+	for input, want := range tests {
+		if got := ParseResolution(input); got != want {
+			t.Errorf("ParseResolution(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestParseIgnoresSubstrings(t *testing.T) {
+	// Substring matching used to read "4k" out of words and "sd" out of "Wednesday"
+	r := Parse("Wednesday.S01E01.4kids.Special.1080p.WEB.h264-GRP")
+	if r.Resolution != 1080 {
+		t.Fatalf("expected 1080p, got %d", r.Resolution)
+	}
+}
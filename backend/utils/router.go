@@ -6,30 +6,18 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// CORS middleware to allow cross-origin requests
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
-}
-
 // NewRouter constructs the base mux router with common routes.
 func NewRouter() *mux.Router {
 	r := mux.NewRouter()
 
-	// Add CORS middleware
-	r.Use(corsMiddleware)
+	// Add CORS middleware (policy comes from SetCORSSettings)
+	r.Use(CORSMiddleware)
+	// Compress JSON API responses for clients that accept it
+	r.Use(CompressionMiddleware)
+	// Stamp responses with the server clock for clients with a drifting one
+	r.Use(ServerTimeMiddleware)
+	// Name the matched route in the access log
+	r.Use(AccessLogRouteMiddleware)
 
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,247 @@
+// Package sandbox runs the external binaries the backend spawns (ffmpeg,
+// ffprobe, yt-dlp, MP4Box, dovi_tool) under the configured confinement: resource limits, a
+// network namespace, or a firejail/nsjail wrapper.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"novastream/config"
+)
+
+// Tool identifies the binary being run, which decides the network policy.
+type Tool string
+
+const (
+	ToolFFmpeg   Tool = "ffmpeg"
+	ToolFFprobe  Tool = "ffprobe"
+	ToolYtDlp    Tool = "yt-dlp"
+	ToolMP4Box   Tool = "MP4Box"
+	ToolDoviTool Tool = "dovi_tool"
+)
+
+// settings holds the active sandbox policy, swapped when settings are saved.
+// Processes already running keep the policy they were started with.
+var settings atomic.Pointer[config.SandboxSettings]
+
+// SetSettings replaces the sandbox policy used for newly spawned processes.
+func SetSettings(s config.SandboxSettings) {
+	settings.Store(&s)
+}
+
+func currentSettings() config.SandboxSettings {
+	if s := settings.Load(); s != nil {
+		return *s
+	}
+	return config.SandboxSettings{Mode: config.SandboxModeNone}
+}
+
+// Cmd is an exec.Cmd that enforces the sandbox's memory budget while running.
+// It is used exactly like exec.Cmd.
+type Cmd struct {
+	*exec.Cmd
+
+	tool        Tool
+	memoryLimit int64 // bytes, 0 = unlimited
+
+	done     chan struct{}
+	doneOnce sync.Once
+	exceeded atomic.Bool
+}
+
+// CommandContext is exec.CommandContext with the current sandbox policy applied.
+func CommandContext(ctx context.Context, tool Tool, name string, args ...string) *Cmd {
+	s := currentSettings()
+	wrappedName, wrappedArgs := wrapCommand(s, tool, name, args)
+	if wrappedName != name {
+		log.Printf("[sandbox] running %s via %s (mode=%s)", tool, wrappedName, s.Mode)
+	}
+	return &Cmd{
+		Cmd:         exec.CommandContext(ctx, wrappedName, wrappedArgs...),
+		tool:        tool,
+		memoryLimit: memoryLimitBytes(s),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start starts the process and, if a memory limit is set, its watchdog.
+func (c *Cmd) Start() error {
+	if err := c.Cmd.Start(); err != nil {
+		return err
+	}
+	if c.memoryLimit > 0 {
+		go c.watchMemory()
+	}
+	return nil
+}
+
+// Wait waits for the process to exit and stops its watchdog.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.doneOnce.Do(func() { close(c.done) })
+	return err
+}
+
+// Run starts the process and waits for it to exit.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Output runs the process and returns its standard output. As with exec.Cmd,
+// standard error is captured into the returned *exec.ExitError when unset.
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	captureErr := c.Stderr == nil
+	if captureErr {
+		c.Stderr = &stderr
+	}
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if captureErr && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the process and returns its standard output and error.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil || c.Stderr != nil {
+		return nil, errors.New("exec: Stdout or Stderr already set")
+	}
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.Bytes(), err
+}
+
+// BudgetExceeded reports whether the process was killed for exceeding its
+// memory limit.
+func (c *Cmd) BudgetExceeded() bool {
+	return c.exceeded.Load()
+}
+
+func memoryLimitBytes(s config.SandboxSettings) int64 {
+	if normalizeMode(s.Mode) == config.SandboxModeNone || s.MemoryLimitMB <= 0 {
+		return 0
+	}
+	return int64(s.MemoryLimitMB) * 1024 * 1024
+}
+
+func normalizeMode(mode string) string {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case config.SandboxModeLimits, config.SandboxModeFirejail, config.SandboxModeNsjail:
+		return m
+	default:
+		return config.SandboxModeNone
+	}
+}
+
+// wrapCommand returns the command line that runs name under the sandbox.
+func wrapCommand(s config.SandboxSettings, tool Tool, name string, args []string) (string, []string) {
+	mode := normalizeMode(s.Mode)
+	isolateNetwork := s.NoNetwork && tool != ToolYtDlp && !hasURLInput(args)
+
+	switch mode {
+	case config.SandboxModeLimits:
+		// sh and unshare both exec the target, so it keeps the PID the
+		// memory watchdog and context cancellation act on
+		cmdline := append([]string{name}, args...)
+		if isolateNetwork {
+			cmdline = append([]string{"unshare", "--net", "--map-root-user"}, cmdline...)
+		}
+		if s.CPUTimeLimitSeconds > 0 {
+			script := "ulimit -t " + strconv.Itoa(s.CPUTimeLimitSeconds) + ` && exec "$@"`
+			return "sh", append([]string{"-c", script, "sh"}, cmdline...)
+		}
+		return cmdline[0], cmdline[1:]
+
+	case config.SandboxModeFirejail:
+		wrapper := wrapperPath(s, "firejail")
+		wrapped := []string{"--quiet", "--noprofile"}
+		if isolateNetwork {
+			wrapped = append(wrapped, "--net=none")
+		}
+		if s.CPUTimeLimitSeconds > 0 {
+			wrapped = append(wrapped, "--rlimit-cpu="+strconv.Itoa(s.CPUTimeLimitSeconds))
+		}
+		wrapped = append(wrapped, s.ExtraArgs...)
+		wrapped = append(wrapped, "--", name)
+		return wrapper, append(wrapped, args...)
+
+	case config.SandboxModeNsjail:
+		wrapper := wrapperPath(s, "nsjail")
+		// nsjail defaults to a 10 minute wall clock, 4GB address space and 32
+		// open files, all too tight for an HLS transcode
+		cpu := "inf"
+		if s.CPUTimeLimitSeconds > 0 {
+			cpu = strconv.Itoa(s.CPUTimeLimitSeconds)
+		}
+		wrapped := []string{
+			"--mode", "o", "--quiet",
+			"--chroot", "/", "--rw", "--keep_env",
+			"--time_limit", "0",
+			"--rlimit_as", "inf",
+			"--rlimit_cpu", cpu,
+			"--rlimit_fsize", "inf",
+			"--rlimit_nofile", "max",
+		}
+		if !isolateNetwork {
+			wrapped = append(wrapped, "--disable_clone_newnet")
+		}
+		wrapped = append(wrapped, s.ExtraArgs...)
+		wrapped = append(wrapped, "--", name)
+		return wrapper, append(wrapped, args...)
+	}
+
+	return name, args
+}
+
+// RequiredBinaries returns the wrapper binaries the configured mode runs
+// commands through, so their absence can be reported before anything spawns.
+func RequiredBinaries(s config.SandboxSettings) []string {
+	switch normalizeMode(s.Mode) {
+	case config.SandboxModeLimits:
+		if s.NoNetwork {
+			return []string{"unshare"}
+		}
+	case config.SandboxModeFirejail:
+		return []string{wrapperPath(s, "firejail")}
+	case config.SandboxModeNsjail:
+		return []string{wrapperPath(s, "nsjail")}
+	}
+	return nil
+}
+
+func wrapperPath(s config.SandboxSettings, fallback string) string {
+	if path := strings.TrimSpace(s.WrapperPath); path != "" {
+		return path
+	}
+	return fallback
+}
+
+// hasURLInput reports whether a command line reads from the network, such as
+// ffprobe against the local WebDAV server.
+func hasURLInput(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "://") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"novastream/config"
+)
+
+func TestWrapCommand(t *testing.T) {
+	probeArgs := []string{"-i", "http://127.0.0.1:7777/webdav/movie.mkv"}
+	pipeArgs := []string{"-i", "pipe:0", "-f", "mp4", "pipe:1"}
+
+	tests := []struct {
+		name     string
+		settings config.SandboxSettings
+		tool     Tool
+		args     []string
+		wantName string
+		wantArgs []string
+	}{
+		{
+			name:     "none passes through",
+			settings: config.SandboxSettings{Mode: "none", NoNetwork: true, CPUTimeLimitSeconds: 60},
+			tool:     ToolFFmpeg,
+			args:     pipeArgs,
+			wantName: "ffmpeg",
+			wantArgs: pipeArgs,
+		},
+		{
+			name:     "limits with cpu cap and no network",
+			settings: config.SandboxSettings{Mode: "limits", NoNetwork: true, CPUTimeLimitSeconds: 60},
+			tool:     ToolFFmpeg,
+			args:     pipeArgs,
+			wantName: "sh",
+			wantArgs: append([]string{"-c", `ulimit -t 60 && exec "$@"`, "sh", "unshare", "--net", "--map-root-user", "ffmpeg"}, pipeArgs...),
+		},
+		{
+			name:     "limits keeps network for URL inputs",
+			settings: config.SandboxSettings{Mode: "limits", NoNetwork: true},
+			tool:     ToolFFprobe,
+			args:     probeArgs,
+			wantName: "ffprobe",
+			wantArgs: probeArgs,
+		},
+		{
+			name:     "firejail",
+			settings: config.SandboxSettings{Mode: "firejail", NoNetwork: true, CPUTimeLimitSeconds: 30, ExtraArgs: []string{"--nice=10"}},
+			tool:     ToolFFmpeg,
+			args:     pipeArgs,
+			wantName: "firejail",
+			wantArgs: append([]string{"--quiet", "--noprofile", "--net=none", "--rlimit-cpu=30", "--nice=10", "--", "ffmpeg"}, pipeArgs...),
+		},
+		{
+			name:     "nsjail keeps network for yt-dlp",
+			settings: config.SandboxSettings{Mode: "NSJail", WrapperPath: "/opt/nsjail", NoNetwork: true},
+			tool:     ToolYtDlp,
+			args:     []string{"-g", "https://example.com/watch"},
+			wantName: "/opt/nsjail",
+			wantArgs: []string{
+				"--mode", "o", "--quiet", "--chroot", "/", "--rw", "--keep_env", "--time_limit", "0",
+				"--rlimit_as", "inf", "--rlimit_cpu", "inf", "--rlimit_fsize", "inf", "--rlimit_nofile", "max",
+				"--disable_clone_newnet", "--", "yt-dlp", "-g", "https://example.com/watch",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotArgs := wrapCommand(tt.settings, tt.tool, string(tt.tool), tt.args)
+			if gotName != tt.wantName || !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Fatalf("wrapCommand() = %s %q, want %s %q", gotName, gotArgs, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCommandContextRunsUnderLimits(t *testing.T) {
+	SetSettings(config.SandboxSettings{Mode: config.SandboxModeLimits, CPUTimeLimitSeconds: 30})
+	defer SetSettings(config.SandboxSettings{})
+
+	out, err := CommandContext(context.Background(), ToolFFmpeg, "echo", "hello world").Output()
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello world" {
+		t.Fatalf("Output() = %q, want %q", got, "hello world")
+	}
+}
+
+func TestMemoryWatchdogKillsProcessTree(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("procfs unavailable")
+	}
+
+	cmd := CommandContext(context.Background(), ToolFFmpeg, "sh", "-c", "sleep 30 & wait")
+	cmd.memoryLimit = 1 // any resident process exceeds a one byte budget
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("process exited cleanly, want killed")
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("watchdog did not kill the process")
+	}
+	if !cmd.BudgetExceeded() {
+		t.Fatal("BudgetExceeded() = false after watchdog kill")
+	}
+}
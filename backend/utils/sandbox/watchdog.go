@@ -0,0 +1,137 @@
+package sandbox
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const watchInterval = 2 * time.Second
+
+// procStat is the part of /proc/<pid>/stat the watchdog needs.
+type procStat struct {
+	pid      int
+	ppid     int
+	rssPages int64
+}
+
+// watchMemory polls the resident memory of the process tree and kills the
+// whole tree once it exceeds the limit. Wrappers such as firejail run the
+// real binary as a child, so descendants are counted too.
+func (c *Cmd) watchMemory() {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	root := c.Process.Pid
+	pageSize := int64(os.Getpagesize())
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		tree := processTree(root)
+		if len(tree) == 0 {
+			// Exited (or /proc is unavailable)
+			return
+		}
+		var rss int64
+		for _, stat := range tree {
+			rss += stat.rssPages * pageSize
+		}
+		if rss <= c.memoryLimit {
+			continue
+		}
+
+		c.exceeded.Store(true)
+		log.Printf("[sandbox] killing %s pid=%d: resident memory %dMB exceeds limit %dMB",
+			c.tool, root, rss/(1024*1024), c.memoryLimit/(1024*1024))
+		for _, stat := range tree {
+			_ = syscall.Kill(stat.pid, syscall.SIGKILL)
+		}
+		return
+	}
+}
+
+// processTree returns root and all its descendants, or nil if root is gone.
+func processTree(root int) []procStat {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	children := make(map[int][]procStat)
+	var rootStat *procStat
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		stat, ok := readProcStat(pid)
+		if !ok {
+			continue
+		}
+		if pid == root {
+			rootStat = &stat
+			continue
+		}
+		children[stat.ppid] = append(children[stat.ppid], stat)
+	}
+	if rootStat == nil {
+		return nil
+	}
+
+	tree := []procStat{*rootStat}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].pid]...)
+	}
+	return tree
+}
+
+// readProcStat parses /proc/<pid>/stat. Zombies report no memory and are skipped.
+func readProcStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, false
+	}
+	// The command name is parenthesised and may contain spaces, so fields are
+	// counted from the last ')'
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return procStat{}, false
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	// fields[0] is state (stat field 3), fields[1] ppid (4), fields[21] rss (24)
+	if len(fields) < 22 || fields[0] == "Z" {
+		return procStat{}, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return procStat{}, false
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return procStat{}, false
+	}
+	return procStat{pid: pid, ppid: ppid, rssPages: rss}, true
+}
+
+// SignalTree sends sig to pid and all its descendants, so that suspending or
+// resuming a sandboxed process reaches the real binary behind its wrapper.
+func SignalTree(pid int, sig syscall.Signal) error {
+	tree := processTree(pid)
+	if len(tree) == 0 {
+		return syscall.Kill(pid, sig)
+	}
+	var firstErr error
+	for _, stat := range tree {
+		if err := syscall.Kill(stat.pid, sig); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
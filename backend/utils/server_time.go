@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ServerTimeHeader carries the server clock (Unix milliseconds) on every
+	// response so clients with a drifting clock can measure their skew.
+	ServerTimeHeader = "X-Server-Time"
+	// ClientTimeHeader is the client clock (Unix milliseconds), sent by clients
+	// that want expiry times expressed on their own clock.
+	ClientTimeHeader = "X-Client-Time"
+)
+
+// ServerTimeMiddleware stamps responses with the server time and exposes the
+// header to browser clients.
+func ServerTimeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set(ServerTimeHeader, strconv.FormatInt(time.Now().UnixMilli(), 10))
+		exposeHeader(header, ServerTimeHeader)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientClockSkew returns how far the request's X-Client-Time is ahead of now
+// (negative when the client clock is behind). ok is false when the header is
+// missing or malformed.
+func ClientClockSkew(r *http.Request, now time.Time) (skew time.Duration, ok bool) {
+	millis, err := strconv.ParseInt(strings.TrimSpace(r.Header.Get(ClientTimeHeader)), 10, 64)
+	if err != nil || millis <= 0 {
+		return 0, false
+	}
+	return time.UnixMilli(millis).Sub(now), true
+}
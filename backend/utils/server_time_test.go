@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestServerTimeMiddleware(t *testing.T) {
+	handler := ServerTimeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/me", nil))
+
+	millis, err := strconv.ParseInt(rec.Header().Get(ServerTimeHeader), 10, 64)
+	if err != nil || time.Since(time.UnixMilli(millis)) > time.Minute {
+		t.Fatalf("expected current server time, got %q", rec.Header().Get(ServerTimeHeader))
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "Content-Length, "+ServerTimeHeader {
+		t.Fatalf("expected the header to be exposed, got %q", got)
+	}
+}
+
+func TestClientClockSkew(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+	if _, ok := ClientClockSkew(req, now); ok {
+		t.Fatal("expected no skew without the header")
+	}
+
+	req.Header.Set(ClientTimeHeader, strconv.FormatInt(now.Add(-90*time.Minute).UnixMilli(), 10))
+	if skew, ok := ClientClockSkew(req, now); !ok || skew != -90*time.Minute {
+		t.Fatalf("expected the client to be 90m behind, got %v, %v", skew, ok)
+	}
+}
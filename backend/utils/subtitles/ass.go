@@ -0,0 +1,594 @@
+// Package subtitles converts SRT and ASS/SSA subtitles to WebVTT. ASS styling
+// that WebVTT can express (position, alignment, colours, italics, bold and
+// underline) is kept rather than stripped as ffmpeg's webvtt encoder does.
+package subtitles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Script resolution assumed when a script doesn't declare one, per the ASS spec
+const (
+	defaultPlayResX = 384
+	defaultPlayResY = 288
+)
+
+// namedColors are the WebVTT default text colour classes, which native
+// renderers support without a STYLE block.
+var namedColors = []struct {
+	class   string
+	r, g, b int
+}{
+	{"white", 255, 255, 255},
+	{"lime", 0, 255, 0},
+	{"cyan", 0, 255, 255},
+	{"red", 255, 0, 0},
+	{"yellow", 255, 255, 0},
+	{"magenta", 255, 0, 255},
+	{"blue", 0, 0, 255},
+	{"black", 0, 0, 0},
+}
+
+var classNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// assStyle is the part of an ASS style WebVTT can express.
+type assStyle struct {
+	class     string // WebVTT class carrying the style's colour, "" for white
+	color     string // primary colour as rrggbb
+	bold      bool
+	italic    bool
+	underline bool
+	alignment int // numpad layout: 1-3 bottom, 4-6 middle, 7-9 top
+}
+
+// ASSConverter converts an ASS/SSA script to WebVTT one line at a time, so a
+// track can be converted while it is still being extracted. Styles are
+// written as a STYLE block once the [Events] section starts, since WebVTT
+// requires them before the first cue.
+type ASSConverter struct {
+	w             io.Writer
+	section       string
+	playResX      float64
+	playResY      float64
+	styleFormat   []string
+	styles        map[string]assStyle
+	styleOrder    []string
+	eventFormat   []string
+	headerWritten bool
+}
+
+// NewASSConverter returns a converter writing WebVTT to w.
+func NewASSConverter(w io.Writer) *ASSConverter {
+	return &ASSConverter{
+		w:        w,
+		playResX: defaultPlayResX,
+		playResY: defaultPlayResY,
+		styles:   make(map[string]assStyle),
+	}
+}
+
+// ConvertASS converts a complete ASS/SSA script to WebVTT.
+func ConvertASS(r io.Reader, w io.Writer) error {
+	conv := NewASSConverter(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := conv.WriteLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read ass: %w", err)
+	}
+	return conv.Close()
+}
+
+// WriteLine feeds one line of the script to the converter.
+func (c *ASSConverter) WriteLine(line string) error {
+	line = strings.TrimRight(strings.TrimPrefix(line, "\ufeff"), "\r\n")
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+		return nil
+	}
+
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		c.section = strings.ToLower(trimmed)
+		if c.section == "[events]" {
+			return c.writeHeader()
+		}
+		return nil
+	}
+
+	key, value, ok := strings.Cut(trimmed, ":")
+	if !ok {
+		return nil
+	}
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+
+	switch c.section {
+	case "[script info]":
+		switch key {
+		case "playresx":
+			if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+				c.playResX = v
+			}
+		case "playresy":
+			if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+				c.playResY = v
+			}
+		}
+	case "[v4+ styles]", "[v4 styles]":
+		switch key {
+		case "format":
+			c.styleFormat = splitFormat(value)
+		case "style":
+			c.addStyle(value, c.section == "[v4 styles]")
+		}
+	case "[events]":
+		switch key {
+		case "format":
+			c.eventFormat = splitFormat(value)
+		case "dialogue":
+			return c.writeDialogue(value)
+		}
+	}
+	return nil
+}
+
+// Close writes the WebVTT header if the script had no events.
+func (c *ASSConverter) Close() error {
+	return c.writeHeader()
+}
+
+func (c *ASSConverter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, name := range c.styleOrder {
+		style := c.styles[name]
+		if style.class == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "STYLE\n::cue(.%s) {\n  color: #%s;\n}\n\n", style.class, style.color)
+	}
+	_, err := io.WriteString(c.w, b.String())
+	return err
+}
+
+func (c *ASSConverter) addStyle(value string, legacy bool) {
+	format := c.styleFormat
+	if len(format) == 0 {
+		format = splitFormat("Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding")
+	}
+	fields := splitFields(value, len(format))
+
+	var name string
+	style := assStyle{alignment: 2}
+	for i, field := range format {
+		if i >= len(fields) {
+			break
+		}
+		v := strings.TrimSpace(fields[i])
+		switch field {
+		case "name":
+			name = v
+		case "primarycolour":
+			if rgb, ok := parseASSColor(v); ok && rgb != "ffffff" {
+				style.color = rgb
+			}
+		case "bold":
+			style.bold = assFlag(v)
+		case "italic":
+			style.italic = assFlag(v)
+		case "underline":
+			style.underline = assFlag(v)
+		case "alignment":
+			if n, err := strconv.Atoi(v); err == nil {
+				if legacy {
+					n = legacyAlignment(n)
+				}
+				if n >= 1 && n <= 9 {
+					style.alignment = n
+				}
+			}
+		}
+	}
+	if name == "" {
+		return
+	}
+	if style.color != "" {
+		style.class = "s-" + classNameInvalid.ReplaceAllString(name, "_")
+	}
+	if _, exists := c.styles[name]; !exists {
+		c.styleOrder = append(c.styleOrder, name)
+	}
+	c.styles[name] = style
+}
+
+func (c *ASSConverter) writeDialogue(value string) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	format := c.eventFormat
+	if len(format) == 0 {
+		format = splitFormat("Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text")
+	}
+	fields := splitFields(value, len(format))
+
+	var start, end float64
+	var styleName, text string
+	for i, field := range format {
+		if i >= len(fields) {
+			break
+		}
+		switch field {
+		case "start":
+			start = parseASSTime(fields[i])
+		case "end":
+			end = parseASSTime(fields[i])
+		case "style":
+			styleName = strings.TrimPrefix(strings.TrimSpace(fields[i]), "*")
+		case "text":
+			text = fields[i]
+		}
+	}
+	if end <= start {
+		return nil
+	}
+
+	style, ok := c.styles[styleName]
+	if !ok {
+		style = assStyle{alignment: 2}
+	}
+	cue := c.convertText(text, style)
+	if cue.text == "" {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(c.w, "%s --> %s%s\n%s\n\n",
+		formatTimestamp(start), formatTimestamp(end), c.cueSettings(cue), cue.text)
+	return err
+}
+
+// textState is the formatting in effect for a run of cue text.
+type textState struct {
+	class     string
+	bold      bool
+	italic    bool
+	underline bool
+}
+
+func stateFromStyle(style assStyle) textState {
+	return textState{class: style.class, bold: style.bold, italic: style.italic, underline: style.underline}
+}
+
+// convertedCue is a dialogue line rendered as WebVTT cue text plus the
+// layout needed for its cue settings.
+type convertedCue struct {
+	text      string
+	alignment int
+	hasPos    bool
+	posX      float64
+	posY      float64
+}
+
+// convertText renders ASS dialogue text with override tags as WebVTT cue
+// text. Formatting is written as a fresh set of tags per run, which keeps the
+// markup balanced however the overrides nest.
+func (c *ASSConverter) convertText(text string, style assStyle) convertedCue {
+	cue := convertedCue{alignment: style.alignment}
+	state := stateFromStyle(style)
+	drawing := false
+
+	var out strings.Builder
+	var run strings.Builder
+	runState := state
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		out.WriteString(wrapRun(run.String(), runState))
+		run.Reset()
+	}
+	appendText := func(s string) {
+		if drawing || s == "" {
+			return
+		}
+		if state != runState {
+			flush()
+			runState = state
+		}
+		run.WriteString(s)
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case text[i] == '{':
+			closeIdx := strings.IndexByte(text[i:], '}')
+			if closeIdx < 0 {
+				appendText(escapeText(text[i:]))
+				i = len(text)
+				continue
+			}
+			c.applyOverrides(text[i+1:i+closeIdx], style, &state, &cue, &drawing)
+			i += closeIdx + 1
+		case text[i] == '\\' && i+1 < len(text) && (text[i+1] == 'N' || text[i+1] == 'n'):
+			if text[i+1] == 'N' {
+				// Hard breaks end the run so tags don't span lines
+				flush()
+				out.WriteString("\n")
+			} else {
+				appendText(" ")
+			}
+			i += 2
+		case text[i] == '\\' && i+1 < len(text) && text[i+1] == 'h':
+			appendText("\u00a0")
+			i += 2
+		default:
+			next := strings.IndexAny(text[i+1:], "{\\")
+			end := len(text)
+			if next >= 0 {
+				end = i + 1 + next
+			}
+			appendText(escapeText(text[i:end]))
+			i = end
+		}
+	}
+	flush()
+
+	// Blank lines would end the cue early
+	lines := strings.Split(out.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(stripTags(line)) != "" {
+			kept = append(kept, strings.TrimSpace(line))
+		}
+	}
+	cue.text = strings.Join(kept, "\n")
+	return cue
+}
+
+// applyOverrides applies one {...} override block.
+func (c *ASSConverter) applyOverrides(block string, style assStyle, state *textState, cue *convertedCue, drawing *bool) {
+	for _, tag := range strings.Split(block, "\\") {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "":
+		case strings.HasPrefix(tag, "pos("):
+			if x, y, ok := parsePos(tag); ok {
+				cue.hasPos, cue.posX, cue.posY = true, x, y
+			}
+		case strings.HasPrefix(tag, "an"):
+			if n, err := strconv.Atoi(tag[2:]); err == nil && n >= 1 && n <= 9 {
+				cue.alignment = n
+			}
+		case strings.HasPrefix(tag, "a") && len(tag) > 1 && tag[1] >= '0' && tag[1] <= '9':
+			if n, err := strconv.Atoi(tag[1:]); err == nil {
+				if n = legacyAlignment(n); n >= 1 && n <= 9 {
+					cue.alignment = n
+				}
+			}
+		case strings.HasPrefix(tag, "1c") || (strings.HasPrefix(tag, "c") && !strings.HasPrefix(tag, "clip")):
+			value := strings.TrimPrefix(strings.TrimPrefix(tag, "1"), "c")
+			if rgb, ok := parseASSColor(value); ok {
+				state.class = nearestNamedColor(rgb)
+			} else {
+				state.class = style.class
+			}
+		case tag == "i1" || tag == "i0" || tag == "i":
+			state.italic = tag == "i1"
+		case tag == "u1" || tag == "u0" || tag == "u":
+			state.underline = tag == "u1"
+		case strings.HasPrefix(tag, "b") && !strings.HasPrefix(tag, "be") && !strings.HasPrefix(tag, "blur") && !strings.HasPrefix(tag, "bord"):
+			// \b1, \b0 or a font weight such as \b700
+			if n, err := strconv.Atoi(tag[1:]); err == nil {
+				state.bold = n == 1 || n >= 600
+			}
+		case strings.HasPrefix(tag, "r") && !strings.HasPrefix(tag, "rnd"):
+			reset := style
+			if other, ok := c.styles[strings.TrimSpace(tag[1:])]; ok {
+				reset = other
+			}
+			*state = stateFromStyle(reset)
+		case strings.HasPrefix(tag, "p") && !strings.HasPrefix(tag, "pbo"):
+			if n, err := strconv.Atoi(tag[1:]); err == nil {
+				*drawing = n > 0
+			}
+		}
+	}
+}
+
+// cueSettings maps the cue's alignment and \pos to WebVTT cue settings.
+func (c *ASSConverter) cueSettings(cue convertedCue) string {
+	col := (cue.alignment - 1) % 3 // 0 left, 1 centre, 2 right
+	row := (cue.alignment - 1) / 3 // 0 bottom, 1 middle, 2 top
+	textAlign := [...]string{"left", "center", "right"}[col]
+
+	if cue.hasPos {
+		x := clampPercent(cue.posX / c.playResX * 100)
+		y := clampPercent(cue.posY / c.playResY * 100)
+		return fmt.Sprintf(" position:%s%%,%s line:%s%%,%s align:%s",
+			formatPercent(x), [...]string{"line-left", "center", "line-right"}[col],
+			formatPercent(y), [...]string{"end", "center", "start"}[row],
+			textAlign)
+	}
+
+	var settings []string
+	switch row {
+	case 1:
+		settings = append(settings, "line:50%,center")
+	case 2:
+		settings = append(settings, "line:0")
+	}
+	if col != 1 {
+		settings = append(settings, "align:"+textAlign)
+	}
+	if len(settings) == 0 {
+		return ""
+	}
+	return " " + strings.Join(settings, " ")
+}
+
+func wrapRun(text string, state textState) string {
+	var open, close string
+	if state.class != "" {
+		open += "<c." + state.class + ">"
+		close = "</c>" + close
+	}
+	if state.bold {
+		open += "<b>"
+		close = "</b>" + close
+	}
+	if state.italic {
+		open += "<i>"
+		close = "</i>" + close
+	}
+	if state.underline {
+		open += "<u>"
+		close = "</u>" + close
+	}
+	return open + text + close
+}
+
+// splitFormat parses a Format line into lowercase field names.
+func splitFormat(value string) []string {
+	fields := strings.Split(value, ",")
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+	return fields
+}
+
+// splitFields splits a Style or Dialogue value into n fields. The last field
+// (the text, for events) may itself contain commas.
+func splitFields(value string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	return strings.SplitN(value, ",", n)
+}
+
+// parseASSTime parses H:MM:SS.cc into seconds.
+func parseASSTime(value string) float64 {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err1 := strconv.ParseFloat(parts[0], 64)
+	minutes, err2 := strconv.ParseFloat(parts[1], 64)
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0
+	}
+	return hours*3600 + minutes*60 + seconds
+}
+
+// formatTimestamp renders seconds as a WebVTT HH:MM:SS.mmm timestamp.
+func formatTimestamp(seconds float64) string {
+	totalMs := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		totalMs/3600000, (totalMs%3600000)/60000, (totalMs%60000)/1000, totalMs%1000)
+}
+
+// parseASSColor parses &HAABBGGRR& (or a decimal colour, as older SSA scripts
+// use) into rrggbb.
+func parseASSColor(value string) (string, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "&")
+	var parsed uint64
+	var err error
+	if strings.HasPrefix(strings.ToUpper(value), "&H") {
+		parsed, err = strconv.ParseUint(value[2:], 16, 32)
+	} else {
+		parsed, err = strconv.ParseUint(value, 10, 32)
+	}
+	if err != nil || value == "" {
+		return "", false
+	}
+	r, g, b := parsed&0xff, (parsed>>8)&0xff, (parsed>>16)&0xff
+	return fmt.Sprintf("%02x%02x%02x", r, g, b), true
+}
+
+// nearestNamedColor maps a colour to the closest WebVTT default colour
+// class, or "" for white, the default.
+func nearestNamedColor(rgb string) string {
+	value, err := strconv.ParseUint(rgb, 16, 32)
+	if err != nil {
+		return ""
+	}
+	r, g, b := int(value>>16)&0xff, int(value>>8)&0xff, int(value)&0xff
+
+	best, bestDist := "", math.MaxInt
+	for _, named := range namedColors {
+		dr, dg, db := r-named.r, g-named.g, b-named.b
+		if dist := dr*dr + dg*dg + db*db; dist < bestDist {
+			best, bestDist = named.class, dist
+		}
+	}
+	if best == "white" {
+		return ""
+	}
+	return best
+}
+
+// legacyAlignment converts SSA alignment (1-3 bottom, +4 top, +8 middle) to numpad layout.
+func legacyAlignment(n int) int {
+	col := n & 3
+	if col == 0 {
+		return 0
+	}
+	switch {
+	case n&8 != 0:
+		return col + 3
+	case n&4 != 0:
+		return col + 6
+	}
+	return col
+}
+
+func parsePos(tag string) (float64, float64, bool) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(tag, "pos("), ")")
+	xs, ys, ok := strings.Cut(inner, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	return x, y, errX == nil && errY == nil
+}
+
+func assFlag(value string) bool {
+	n, err := strconv.Atoi(value)
+	return err == nil && n != 0
+}
+
+func clampPercent(v float64) float64 {
+	return math.Max(0, math.Min(100, v))
+}
+
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func escapeText(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	return strings.ReplaceAll(text, ">", "&gt;")
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func stripTags(text string) string {
+	return tagPattern.ReplaceAllString(text, "")
+}
@@ -0,0 +1,85 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+)
+
+const testScript = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,48,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,2,2,10,10,10,1
+Style: Sign Top,Arial,40,&H0000FFFF,&H000000FF,&H00000000,&H00000000,-1,0,0,0,100,100,0,0,1,2,2,8,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,not shown
+Dialogue: 0,0:00:01.00,0:00:03.50,Default,,0,0,0,,Hello, {\i1}world{\i0} & <friends>
+Dialogue: 0,0:00:04.00,0:00:05.00,Sign Top,,0,0,0,,Road closed
+Dialogue: 0,0:00:06.00,0:00:07.00,Default,,0,0,0,,{\an7\c&H0000FF&}Red line\N{\r}second line
+Dialogue: 0,0:00:08.00,0:00:09.00,Default,,0,0,0,,{\pos(960,540)}Centered
+Dialogue: 0,0:00:10.00,0:00:11.00,Default,,0,0,0,,{\p1}m 0 0 l 100 0 100 100{\p0}
+`
+
+func TestConvertASS(t *testing.T) {
+	var out strings.Builder
+	if err := ConvertASS(strings.NewReader(testScript), &out); err != nil {
+		t.Fatalf("ConvertASS() error = %v", err)
+	}
+	got := out.String()
+
+	want := "WEBVTT\n\n" +
+		"STYLE\n::cue(.s-Sign_Top) {\n  color: #ffff00;\n}\n\n" +
+		"00:00:01.000 --> 00:00:03.500\nHello, <i>world</i> &amp; &lt;friends&gt;\n\n" +
+		"00:00:04.000 --> 00:00:05.000 line:0\n<c.s-Sign_Top><b>Road closed</b></c>\n\n" +
+		"00:00:06.000 --> 00:00:07.000 line:0 align:left\n<c.red>Red line</c>\nsecond line\n\n" +
+		"00:00:08.000 --> 00:00:09.000 position:50%,center line:50%,end align:center\nCentered\n\n"
+	if got != want {
+		t.Errorf("ConvertASS() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConvertASSWithoutEvents(t *testing.T) {
+	var out strings.Builder
+	if err := ConvertASS(strings.NewReader("[Script Info]\nTitle: empty\n"), &out); err != nil {
+		t.Fatalf("ConvertASS() error = %v", err)
+	}
+	if out.String() != "WEBVTT\n\n" {
+		t.Errorf("ConvertASS() = %q, want a bare header", out.String())
+	}
+}
+
+func TestParseASSColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		named string
+	}{
+		{"&H000000FF", "ff0000", "red"},
+		{"&H00FF00&", "00ff00", "lime"},
+		{"&HFFFFFF", "ffffff", ""},
+		{"65535", "ffff00", "yellow"},
+	}
+	for _, tt := range tests {
+		got, ok := parseASSColor(tt.value)
+		if !ok || got != tt.want {
+			t.Errorf("parseASSColor(%q) = %q, %v, want %q", tt.value, got, ok, tt.want)
+			continue
+		}
+		if named := nearestNamedColor(got); named != tt.named {
+			t.Errorf("nearestNamedColor(%q) = %q, want %q", got, named, tt.named)
+		}
+	}
+}
+
+func TestLegacyAlignment(t *testing.T) {
+	for legacy, want := range map[int]int{1: 1, 2: 2, 3: 3, 5: 7, 6: 8, 7: 9, 9: 4, 10: 5, 11: 6} {
+		if got := legacyAlignment(legacy); got != want {
+			t.Errorf("legacyAlignment(%d) = %d, want %d", legacy, got, want)
+		}
+	}
+}
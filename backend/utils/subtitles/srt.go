@@ -0,0 +1,217 @@
+package subtitles
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Subtitle file formats accepted by ToVTT.
+const (
+	FormatSRT = "srt"
+	FormatASS = "ass"
+	FormatSSA = "ssa"
+	FormatVTT = "vtt"
+)
+
+// ErrUnknownFormat is returned when a subtitle file is not SRT, ASS/SSA or WebVTT.
+var ErrUnknownFormat = errors.New("unrecognised subtitle format")
+
+var (
+	srtTiming   = regexp.MustCompile(`^\s*(\d+):(\d{1,2}):(\d{1,2})[,.](\d{1,3})\s*-->\s*(\d+):(\d{1,2}):(\d{1,2})[,.](\d{1,3})`)
+	srtFontTag  = regexp.MustCompile(`(?i)</?font[^>]*>`)
+	srtBraceTag = regexp.MustCompile(`\{\\[^}]*\}`)
+	srtStyleTag = regexp.MustCompile(`(?i)^</?[biu]>`)
+)
+
+// DetectFormat guesses a subtitle file's format from its name and content.
+func DetectFormat(filename, text string) string {
+	head := strings.TrimSpace(strings.TrimPrefix(text, "\ufeff"))
+	switch {
+	case strings.HasPrefix(head, "WEBVTT"):
+		return FormatVTT
+	case strings.HasPrefix(head, "[Script Info]"), strings.Contains(head, "\n[Events]"):
+		return FormatASS
+	}
+
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".srt"):
+		return FormatSRT
+	case strings.HasSuffix(name, ".ass"):
+		return FormatASS
+	case strings.HasSuffix(name, ".ssa"):
+		return FormatSSA
+	case strings.HasSuffix(name, ".vtt"):
+		return FormatVTT
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(head))
+	for i := 0; i < 5 && scanner.Scan(); i++ {
+		if srtTiming.MatchString(scanner.Text()) {
+			return FormatSRT
+		}
+	}
+	return ""
+}
+
+// DecodeText returns subtitle file bytes as UTF-8. UTF-16 files are detected
+// by their byte order mark and anything else that isn't valid UTF-8 is read
+// as Windows-1252, which most older SRT files use.
+func DecodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], false)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], true)
+	case utf8.Valid(data):
+		return strings.TrimPrefix(string(data), "\ufeff")
+	}
+
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if c >= 0x80 && c < 0xA0 {
+			b.WriteRune(cp1252[c-0x80])
+			continue
+		}
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// ToVTT converts an SRT, ASS/SSA or WebVTT file to WebVTT.
+func ToVTT(filename string, data []byte) ([]byte, string, error) {
+	text := DecodeText(data)
+	format := DetectFormat(filename, text)
+
+	var out bytes.Buffer
+	var err error
+	switch format {
+	case FormatSRT:
+		err = ConvertSRT(strings.NewReader(text), &out)
+	case FormatASS, FormatSSA:
+		err = ConvertASS(strings.NewReader(text), &out)
+	case FormatVTT:
+		out.WriteString(strings.ReplaceAll(text, "\r\n", "\n"))
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return out.Bytes(), format, nil
+}
+
+// ConvertSRT converts a SubRip script to WebVTT. Cue numbers are dropped,
+// <b>, <i> and <u> are kept and other markup is removed.
+func ConvertSRT(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n\n")
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var cue []string
+	inCue := false
+	flush := func() {
+		if inCue && len(cue) > 1 {
+			bw.WriteString(strings.Join(cue, "\n"))
+			bw.WriteString("\n\n")
+		}
+		cue = cue[:0]
+		inCue = false
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r")
+		if m := srtTiming.FindStringSubmatch(line); m != nil {
+			flush()
+			inCue = true
+			cue = append(cue, srtTimestamp(m[1:5])+" --> "+srtTimestamp(m[5:9]))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if !inCue {
+			// Cue number or stray text before the timing line
+			continue
+		}
+		cue = append(cue, cleanSRTText(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read srt: %w", err)
+	}
+	flush()
+	return bw.Flush()
+}
+
+// cleanSRTText strips markup WebVTT doesn't support, escapes the characters
+// WebVTT reserves and keeps a cue line from ending the cue early.
+func cleanSRTText(line string) string {
+	line = srtFontTag.ReplaceAllString(line, "")
+	line = srtBraceTag.ReplaceAllString(line, "")
+	line = strings.ReplaceAll(line, "-->", "->")
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '<':
+			if m := srtStyleTag.FindString(line[i:]); m != "" {
+				b.WriteString(strings.ToLower(m))
+				i += len(m) - 1
+				continue
+			}
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '&':
+			b.WriteString("&amp;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// srtTimestamp renders SRT hours, minutes, seconds and milliseconds fields
+// as a WebVTT timestamp. Short millisecond fields are fractions: ",5" is 500ms.
+func srtTimestamp(fields []string) string {
+	ms := fields[3]
+	for len(ms) < 3 {
+		ms += "0"
+	}
+	h, _ := strconv.Atoi(fields[0])
+	m, _ := strconv.Atoi(fields[1])
+	sec, _ := strconv.Atoi(fields[2])
+	millis, _ := strconv.Atoi(ms)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, sec, millis)
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// cp1252 maps Windows-1252 bytes 0x80-0x9F to runes; the rest of the code
+// page matches Latin-1.
+var cp1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
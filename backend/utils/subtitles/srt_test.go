@@ -0,0 +1,44 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertSRT(t *testing.T) {
+	script := "\ufeff1\r\n00:00:01,000 --> 00:00:03,5\r\n<i>Hello</i> <font color=\"#ff0000\">Tom & Jerry</font>\r\n{\\an8}a < b\r\n\r\n2\r\n00:00:04,250 --> 00:00:05,000\r\nSecond\r\n\r\n3\r\n00:00:06,000 --> 00:00:07,000\r\n\r\n"
+
+	var out strings.Builder
+	if err := ConvertSRT(strings.NewReader(script), &out); err != nil {
+		t.Fatalf("ConvertSRT() error = %v", err)
+	}
+
+	want := "WEBVTT\n\n" +
+		"00:00:01.000 --> 00:00:03.500\n<i>Hello</i> Tom &amp; Jerry\na &lt; b\n\n" +
+		"00:00:04.250 --> 00:00:05.000\nSecond\n\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
+
+func TestToVTTDetectsFormatAndEncoding(t *testing.T) {
+	// Windows-1252 SRT with a curly apostrophe and an accented letter
+	srt := []byte("1\n00:00:01,000 --> 00:00:02,000\nIt\x92s caf\xe9\n")
+	vtt, format, err := ToVTT("movie.srt", srt)
+	if err != nil {
+		t.Fatalf("ToVTT(srt) error = %v", err)
+	}
+	if format != FormatSRT || !strings.Contains(string(vtt), "It’s café") {
+		t.Fatalf("unexpected conversion %q (%s)", vtt, format)
+	}
+
+	if _, format, err := ToVTT("upload", []byte(testScript)); err != nil || format != FormatASS {
+		t.Fatalf("expected ASS detected from content, got %s, %v", format, err)
+	}
+	if _, format, err := ToVTT("upload.txt", []byte("WEBVTT\n\n00:00.000 --> 00:01.000\nHi\n")); err != nil || format != FormatVTT {
+		t.Fatalf("expected WebVTT passthrough, got %s, %v", format, err)
+	}
+	if _, _, err := ToVTT("notes.txt", []byte("just some text")); err != ErrUnknownFormat {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}